@@ -0,0 +1,176 @@
+// Package client is a Go client for the docksmith HTTP API.
+//
+// The methods of Client are generated from the API's OpenAPI document, which
+// the server serves at /api/openapi.json. Do, Send, and Fetch call endpoints
+// by path, e.g. to decode responses into other types.
+package client
+
+//go:generate go run ../internal/api/clientgen -o client_gen.go
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// DefaultTimeout bounds a single request of clients without WithHTTPClient
+const DefaultTimeout = 60 * time.Second
+
+// Client calls the API of a docksmith server
+type Client struct {
+	baseURL string
+	apiKey  string
+	http    *http.Client
+}
+
+// Option configures a Client
+type Option func(*Client)
+
+// WithAPIKey authenticates requests with an API key
+func WithAPIKey(key string) Option {
+	return func(c *Client) { c.apiKey = key }
+}
+
+// WithHTTPClient sends requests with the given HTTP client
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) { c.http = httpClient }
+}
+
+// New creates a client for the server at baseURL, e.g. "http://docksmith:3000"
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		http:    &http.Client{Timeout: DefaultTimeout},
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// BaseURL returns the URL of the server
+func (c *Client) BaseURL() string {
+	return c.baseURL
+}
+
+// APIError is an error response of the API
+type APIError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	return e.Message
+}
+
+// NewRequest creates an authenticated request for an API path
+func (c *Client) NewRequest(ctx context.Context, method, path string, query url.Values, contentType string, body []byte) (*http.Request, error) {
+	target := c.baseURL + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, target, reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if c.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	}
+	return req, nil
+}
+
+// Do sends a request with a JSON body, if body is not nil, and decodes the data
+// of the API's response envelope into out
+func (c *Client) Do(ctx context.Context, method, path string, query url.Values, body, out any) error {
+	var data []byte
+	if body != nil {
+		var err error
+		if data, err = json.Marshal(body); err != nil {
+			return fmt.Errorf("failed to encode request: %w", err)
+		}
+	}
+	return c.Send(ctx, method, path, query, "application/json", data, out)
+}
+
+// Send sends a request body of any content type and decodes the data of the
+// API's response envelope into out
+func (c *Client) Send(ctx context.Context, method, path string, query url.Values, contentType string, body []byte, out any) error {
+	resp, err := c.send(ctx, method, path, query, contentType, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return c.decode(resp, out)
+}
+
+// Fetch returns the body of a successful request, for endpoints that respond
+// with files instead of the JSON envelope
+func (c *Client) Fetch(ctx context.Context, method, path string, query url.Values) ([]byte, error) {
+	resp, err := c.send(ctx, method, path, query, "", nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return nil, c.decode(resp, nil)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	return data, nil
+}
+
+// send sends a request
+func (c *Client) send(ctx context.Context, method, path string, query url.Values, contentType string, body []byte) (*http.Response, error) {
+	req, err := c.NewRequest(ctx, method, path, query, contentType, body)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach %s: %w", c.baseURL, err)
+	}
+	return resp, nil
+}
+
+// decode reads the API's response envelope, returning its error or decoding its data into out
+func (c *Client) decode(resp *http.Response, out any) error {
+	var envelope struct {
+		Success bool            `json:"success"`
+		Data    json.RawMessage `json:"data"`
+		Error   string          `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&envelope); err != nil {
+		return fmt.Errorf("unexpected response from %s (status %d)", c.baseURL, resp.StatusCode)
+	}
+	if !envelope.Success || resp.StatusCode >= 300 {
+		if envelope.Error == "" {
+			envelope.Error = resp.Status
+		}
+		return &APIError{StatusCode: resp.StatusCode, Message: envelope.Error}
+	}
+
+	if out == nil || len(envelope.Data) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(envelope.Data, out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testClient(t *testing.T, handler http.HandlerFunc) *Client {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	return New(server.URL+"/", WithAPIKey("dsk_test"))
+}
+
+func TestClient_TypedMethods(t *testing.T) {
+	c := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer dsk_test", r.Header.Get("Authorization"))
+		switch r.Method + " " + r.URL.Path {
+		case "GET /api/operations/op 1":
+			fmt.Fprint(w, `{"success":true,"data":{"operation_id":"op 1","status":"complete","created_at":"2026-10-15T09:00:00Z"}}`)
+		case "GET /api/operations":
+			assert.Equal(t, "web", r.URL.Query().Get("container"))
+			fmt.Fprint(w, `{"success":true,"data":{"operations":[{"operation_id":"op-1"}],"count":1,"has_more":false}}`)
+		case "POST /api/rollback":
+			body, _ := io.ReadAll(r.Body)
+			assert.JSONEq(t, `{"operation_id":"op-1","force":true}`, string(body))
+			fmt.Fprint(w, `{"success":true,"data":{"operation_id":"op-2","original_operation_id":"op-1","message":"Rollback initiated"}}`)
+		case "POST /api/config/import":
+			assert.Equal(t, "application/yaml", r.Header.Get("Content-Type"))
+			fmt.Fprint(w, `{"success":true,"data":{"imported":1}}`)
+		case "GET /api/config/export":
+			fmt.Fprint(w, "version: 1\n")
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"success":false,"error":"not found"}`)
+		}
+	})
+	ctx := context.Background()
+
+	op, err := c.GetOperation(ctx, "op 1")
+	require.NoError(t, err)
+	assert.Equal(t, "complete", op.Status)
+	assert.Equal(t, 2026, op.CreatedAt.Year())
+
+	ops, err := c.ListOperations(ctx, url.Values{"container": {"web"}})
+	require.NoError(t, err)
+	require.Len(t, ops.Operations, 1)
+	assert.Equal(t, "op-1", ops.Operations[0].OperationID)
+
+	started, err := c.Rollback(ctx, RollbackRequest{OperationID: "op-1", Force: true})
+	require.NoError(t, err)
+	assert.Equal(t, "op-2", started.OperationID)
+
+	result, err := c.ImportConfig(ctx, []byte("version: 1\n"))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"imported":1}`, string(result))
+
+	data, err := c.ExportConfig(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "version: 1\n", string(data))
+}
+
+func TestClient_Errors(t *testing.T) {
+	c := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/secrets":
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, `{"success":false,"error":"admin role required"}`)
+		case "/api/db/backup":
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"success":false,"error":"authentication required"}`)
+		default:
+			w.WriteHeader(http.StatusBadGateway)
+			fmt.Fprint(w, "<html>bad gateway</html>")
+		}
+	})
+	ctx := context.Background()
+
+	_, err := c.ListSecrets(ctx)
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusForbidden, apiErr.StatusCode)
+	assert.EqualError(t, err, "admin role required")
+
+	_, err = c.BackupDB(ctx)
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusUnauthorized, apiErr.StatusCode)
+
+	_, err = c.GetStatus(ctx)
+	assert.ErrorContains(t, err, "unexpected response")
+}
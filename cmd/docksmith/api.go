@@ -13,9 +13,9 @@ import (
 
 	"github.com/chis/docksmith/internal/api"
 	"github.com/chis/docksmith/internal/docker"
-	"github.com/chis/docksmith/internal/registry"
 	"github.com/chis/docksmith/internal/selfupdate"
 	"github.com/chis/docksmith/internal/storage"
+	"github.com/chis/docksmith/ui"
 )
 
 // APICommand implements the API server command
@@ -38,54 +38,57 @@ func NewAPICommand() *APICommand {
 	}
 }
 
-// ParseFlags parses command-line flags for the API command
-func (c *APICommand) ParseFlags(args []string) error {
-	fs := flag.NewFlagSet("api", flag.ExitOnError)
+// flagSet returns the server flags
+func (c *APICommand) flagSet(action string) *flag.FlagSet {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	fs.Usage = printServeUsage
 
 	fs.IntVar(&c.port, "port", c.port, "Port to listen on")
 	fs.IntVar(&c.port, "p", c.port, "Shorthand for --port")
-	fs.StringVar(&c.staticDir, "static-dir", c.staticDir, "Directory containing static UI files (empty to disable)")
+	fs.StringVar(&c.staticDir, "static-dir", c.staticDir, "Directory containing static UI files, the built-in UI is used when it does not exist (empty to disable)")
 	fs.StringVar(&c.staticDir, "s", c.staticDir, "Shorthand for --static-dir")
+	return fs
+}
 
-	if err := fs.Parse(args); err != nil {
-		return err
-	}
-
-	return nil
+// ParseFlags parses command-line flags for the API command
+func (c *APICommand) ParseFlags(args []string) error {
+	return c.flagSet("").Parse(args)
 }
 
 // validateStartup performs pre-flight checks and prints helpful error messages
 func (c *APICommand) validateStartup(ctx context.Context) error {
 	log.Println("Running startup validation...")
 
-	// Check database directory is writable
-	dbPath := os.Getenv("DB_PATH")
-	if dbPath == "" {
-		dbPath = "/data/docksmith.db"
-	}
-	dbDir := filepath.Dir(dbPath)
-
-	// Check if directory exists
-	if info, err := os.Stat(dbDir); err != nil {
-		if os.IsNotExist(err) {
-			log.Printf("WARNING: Database directory does not exist: %s", dbDir)
-			log.Println("  - Create it with: mkdir -p " + dbDir)
-			log.Println("  - Or mount a volume: -v ./data:/data")
-		} else {
-			log.Printf("WARNING: Cannot access database directory %s: %v", dbDir, err)
+	// Check database directory is writable. Other drivers do not use it.
+	if driver := os.Getenv("DB_DRIVER"); driver == "" || driver == "sqlite" {
+		dbPath := os.Getenv("DB_PATH")
+		if dbPath == "" {
+			dbPath = "/data/docksmith.db"
 		}
-	} else if !info.IsDir() {
-		log.Printf("WARNING: Database path parent is not a directory: %s", dbDir)
-	} else {
-		// Try to create a test file to verify write access
-		testFile := filepath.Join(dbDir, ".docksmith-write-test")
-		if f, err := os.Create(testFile); err != nil {
-			log.Printf("WARNING: Database directory is not writable: %s", dbDir)
-			log.Println("  - Check volume mount permissions")
-			log.Println("  - Docksmith runs as UID 1000, ensure the directory is writable")
+		dbDir := filepath.Dir(dbPath)
+
+		// Check if directory exists
+		if info, err := os.Stat(dbDir); err != nil {
+			if os.IsNotExist(err) {
+				log.Printf("WARNING: Database directory does not exist: %s", dbDir)
+				log.Println("  - Create it with: mkdir -p " + dbDir)
+				log.Println("  - Or mount a volume: -v ./data:/data")
+			} else {
+				log.Printf("WARNING: Cannot access database directory %s: %v", dbDir, err)
+			}
+		} else if !info.IsDir() {
+			log.Printf("WARNING: Database path parent is not a directory: %s", dbDir)
 		} else {
-			f.Close()
-			os.Remove(testFile)
+			// Try to create a test file to verify write access
+			testFile := filepath.Join(dbDir, ".docksmith-write-test")
+			if f, err := os.Create(testFile); err != nil {
+				log.Printf("WARNING: Database directory is not writable: %s", dbDir)
+				log.Println("  - Check volume mount permissions")
+				log.Println("  - Docksmith runs as UID 1000, ensure the directory is writable")
+			} else {
+				f.Close()
+				os.Remove(testFile)
+			}
 		}
 	}
 
@@ -93,8 +96,12 @@ func (c *APICommand) validateStartup(ctx context.Context) error {
 	return nil
 }
 
-// Run starts the API server
-func (c *APICommand) Run(ctx context.Context) error {
+// Run parses the server flags and starts the API server
+func (c *APICommand) Run(ctx context.Context, args []string) error {
+	if err := c.ParseFlags(args); err != nil {
+		return fmt.Errorf("failed to parse flags: %w", err)
+	}
+
 	// Initialize self-detection early (captures container ID from hostname)
 	selfupdate.Init()
 
@@ -157,8 +164,7 @@ func (c *APICommand) Run(ctx context.Context) error {
 	}
 
 	// Initialize registry manager
-	token := os.Getenv("GITHUB_TOKEN")
-	registryManager := registry.NewManager(token)
+	registryManager := InitializeRegistryManager(storageService)
 	log.Println("Registry manager initialized")
 
 	// Create API server
@@ -168,6 +174,7 @@ func (c *APICommand) Run(ctx context.Context) error {
 		RegistryManager: registryManager,
 		StorageService:  storageService,
 		StaticDir:       c.staticDir,
+		StaticFS:        ui.Dist,
 	})
 
 	// Handle graceful shutdown
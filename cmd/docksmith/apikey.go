@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/chis/docksmith/internal/auth"
+)
+
+// APIKeyCommand implements the `docksmith apikey` subcommands
+type APIKeyCommand struct {
+	name  string
+	scope string
+}
+
+// NewAPIKeyCommand creates a new apikey command
+func NewAPIKeyCommand() *APIKeyCommand {
+	return &APIKeyCommand{
+		scope: auth.ScopeRead,
+	}
+}
+
+// Run dispatches to the create, revoke, or list action
+func (c *APIKeyCommand) Run(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		printAPIKeyUsage()
+		return fmt.Errorf("missing apikey action")
+	}
+
+	action, rest := args[0], args[1:]
+
+	store, err := InitializeStorage()
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	keys := auth.NewKeyStore(store)
+
+	switch action {
+	case "create":
+		return c.create(ctx, keys, rest)
+	case "revoke":
+		return c.revoke(ctx, keys, rest)
+	case "list", "ls":
+		return c.list(ctx, keys)
+	default:
+		printAPIKeyUsage()
+		return fmt.Errorf("unknown apikey action: %s", action)
+	}
+}
+
+// flagSet returns the flags of an apikey action
+func (c *APIKeyCommand) flagSet(action string) *flag.FlagSet {
+	fs := flag.NewFlagSet("apikey "+action, flag.ExitOnError)
+	fs.Usage = printAPIKeyUsage
+	if action == "create" {
+		fs.StringVar(&c.name, "name", c.name, "Descriptive name for the key")
+		fs.StringVar(&c.scope, "scope", c.scope, "Key scope: read or update")
+	}
+	return fs
+}
+
+func (c *APIKeyCommand) create(ctx context.Context, keys *auth.KeyStore, args []string) error {
+	if err := c.flagSet("create").Parse(args); err != nil {
+		return err
+	}
+
+	plaintext, key, err := keys.Create(ctx, c.name, c.scope)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Created API key %s (%s, scope=%s)\n", key.ID, key.Name, key.Scope)
+	fmt.Println("")
+	fmt.Printf("  %s\n", plaintext)
+	fmt.Println("")
+	fmt.Println("Store this key now - it cannot be shown again.")
+	return nil
+}
+
+func (c *APIKeyCommand) revoke(ctx context.Context, keys *auth.KeyStore, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: docksmith apikey revoke <id>")
+	}
+
+	if err := keys.Revoke(ctx, args[0]); err != nil {
+		if errors.Is(err, auth.ErrKeyNotFound) {
+			return fmt.Errorf("no API key with id %s", args[0])
+		}
+		return err
+	}
+
+	fmt.Printf("Revoked API key %s\n", args[0])
+	return nil
+}
+
+func (c *APIKeyCommand) list(ctx context.Context, keys *auth.KeyStore) error {
+	list, err := keys.List(ctx)
+	if err != nil {
+		return err
+	}
+
+	if jsonOutput() {
+		return writeJSON(map[string]any{"keys": list, "count": len(list)})
+	}
+
+	if len(list) == 0 {
+		fmt.Println("No API keys configured")
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tNAME\tSCOPE\tCREATED")
+	for _, k := range list {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", k.ID, k.Name, k.Scope, k.CreatedAt.Local().Format("2006-01-02 15:04"))
+	}
+	return tw.Flush()
+}
+
+func printAPIKeyUsage() {
+	fmt.Println(`Usage:
+  docksmith apikey create --name <name> [--scope read|update]
+  docksmith apikey revoke <id>
+  docksmith apikey list`)
+}
@@ -0,0 +1,223 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/chis/docksmith/internal/approval"
+)
+
+// ApprovalsCommand implements the `docksmith approvals` subcommands
+type ApprovalsCommand struct {
+	status string
+	limit  int
+	by     string
+}
+
+// NewApprovalsCommand creates a new approvals command
+func NewApprovalsCommand() *ApprovalsCommand {
+	return &ApprovalsCommand{
+		status: "pending",
+		limit:  50,
+	}
+}
+
+// Run dispatches to the list, approve, or reject action.
+// Approvals made here are applied by the running server on its next check.
+func (c *ApprovalsCommand) Run(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		printApprovalsUsage()
+		return fmt.Errorf("missing approvals action")
+	}
+
+	action, rest := args[0], args[1:]
+
+	store, err := InitializeStorage()
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	approvals := approval.NewManager(store, nil, nil)
+
+	switch action {
+	case "list", "ls":
+		if err := c.flagSet("list").Parse(rest); err != nil {
+			return err
+		}
+		return c.list(ctx, approvals)
+	case "approve", "reject":
+		if len(rest) == 0 {
+			return fmt.Errorf("usage: docksmith approvals %s <id> [--by name]", action)
+		}
+		id := rest[0]
+
+		if err := c.flagSet(action).Parse(rest[1:]); err != nil {
+			return err
+		}
+		actor := "cli"
+		if c.by != "" {
+			actor = "cli:" + c.by
+		}
+
+		if action == "approve" {
+			result, err := approvals.Approve(ctx, id, actor)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Approved update of %s to %s; it will be applied on the server's next check\n", result.ContainerName, targetOrLatest(result.TargetVersion))
+			return nil
+		}
+
+		result, err := approvals.Reject(ctx, id, actor)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Rejected update of %s to %s\n", result.ContainerName, targetOrLatest(result.TargetVersion))
+		return nil
+	case "policies":
+		return c.listPolicies(ctx, approvals)
+	case "set-policy":
+		scope, name, rest, err := policyEntity(action, rest, 1)
+		if err != nil {
+			return err
+		}
+		policy, err := approvals.SetPolicy(ctx, scope, name, rest[0])
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Set %s approval policy to %s\n", describePolicyEntity(policy.EntityType, policy.EntityID), policy.Mode)
+		return nil
+	case "unset-policy":
+		scope, name, _, err := policyEntity(action, rest, 0)
+		if err != nil {
+			return err
+		}
+		if err := approvals.DeletePolicy(ctx, scope, name); err != nil {
+			return err
+		}
+		fmt.Printf("Removed %s approval policy\n", describePolicyEntity(scope, name))
+		return nil
+	default:
+		printApprovalsUsage()
+		return fmt.Errorf("unknown approvals action: %s", action)
+	}
+}
+
+// flagSet returns the flags of an approvals action
+func (c *ApprovalsCommand) flagSet(action string) *flag.FlagSet {
+	fs := flag.NewFlagSet("approvals "+action, flag.ExitOnError)
+	fs.Usage = printApprovalsUsage
+	switch action {
+	case "list", "ls":
+		fs.StringVar(&c.status, "status", c.status, "Filter by status (pending, approved, rejected, expired, superseded, or empty for all)")
+		fs.IntVar(&c.limit, "limit", c.limit, "Maximum number of approvals to show")
+	case "approve", "reject":
+		fs.StringVar(&c.by, "by", os.Getenv("USER"), "Name recorded as the approver")
+	}
+	return fs
+}
+
+func (c *ApprovalsCommand) list(ctx context.Context, approvals *approval.Manager) error {
+	list, err := approvals.List(ctx, c.status, c.limit)
+	if err != nil {
+		return err
+	}
+
+	if jsonOutput() {
+		return writeJSON(map[string]any{"approvals": list, "count": len(list)})
+	}
+
+	if len(list) == 0 {
+		fmt.Println("No approvals found")
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tCONTAINER\tCURRENT\tTARGET\tSTATUS\tEXPIRES\tDECIDED BY")
+	for _, a := range list {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			a.ID, a.ContainerName, a.CurrentVersion, targetOrLatest(a.TargetVersion), a.Status,
+			a.ExpiresAt.Local().Format("2006-01-02 15:04"), a.DecidedBy)
+	}
+	return tw.Flush()
+}
+
+func (c *ApprovalsCommand) listPolicies(ctx context.Context, approvals *approval.Manager) error {
+	policies, err := approvals.Policies(ctx)
+	if err != nil {
+		return err
+	}
+
+	if jsonOutput() {
+		return writeJSON(map[string]any{"policies": policies, "count": len(policies)})
+	}
+
+	if len(policies) == 0 {
+		fmt.Println("No approval policies set")
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "SCOPE\tNAME\tMODE\tUPDATED")
+	for _, p := range policies {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", p.EntityType, p.EntityID, p.Mode, p.UpdatedAt.Local().Format("2006-01-02 15:04"))
+	}
+	return tw.Flush()
+}
+
+// policyEntity splits the scope and name of a policy action from its arguments,
+// followed by want further arguments. The global scope takes no name.
+func policyEntity(action string, args []string, want int) (string, string, []string, error) {
+	usage := fmt.Errorf("usage: docksmith approvals %s <global|stack NAME|container NAME>%s", action, strings.Repeat(" <none|major|all>", want))
+	if len(args) == 0 {
+		return "", "", nil, usage
+	}
+	scope, name := args[0], ""
+	args = args[1:]
+	if scope != "global" {
+		if len(args) == 0 {
+			return "", "", nil, usage
+		}
+		name, args = args[0], args[1:]
+	}
+	if len(args) != want {
+		return "", "", nil, usage
+	}
+	return scope, name, args, nil
+}
+
+// describePolicyEntity names the target of a policy in messages.
+func describePolicyEntity(scope, name string) string {
+	if name == "" {
+		return scope
+	}
+	return scope + " " + name
+}
+
+// targetOrLatest labels digest-only updates, which have no target tag.
+func targetOrLatest(target string) string {
+	if target == "" {
+		return "latest digest"
+	}
+	return target
+}
+
+func printApprovalsUsage() {
+	fmt.Println(`Usage:
+  docksmith approvals list [--status pending|approved|rejected|expired|superseded] [--limit N]
+  docksmith approvals approve <id> [--by name]
+  docksmith approvals reject <id> [--by name]
+  docksmith approvals policies
+  docksmith approvals set-policy <global|stack NAME|container NAME> <none|major|all>
+  docksmith approvals unset-policy <global|stack NAME|container NAME>
+
+Policy modes: "all" holds every update for approval, "major" applies patch and
+minor updates automatically and holds major version changes, "none" applies
+updates without approval. Container policies override stack policies, which
+override the global policy.`)
+}
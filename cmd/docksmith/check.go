@@ -0,0 +1,277 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/chis/docksmith/internal/docker"
+	"github.com/chis/docksmith/internal/eol"
+	"github.com/chis/docksmith/internal/graph"
+	"github.com/chis/docksmith/internal/update"
+)
+
+// CheckCommand implements the `docksmith check` subcommand
+type CheckCommand struct {
+	updatesOnly  bool
+	cached       bool
+	differential bool
+	group        string
+	timeout      time.Duration
+}
+
+// NewCheckCommand creates a new check command
+func NewCheckCommand() *CheckCommand {
+	return &CheckCommand{
+		timeout: 10 * time.Minute,
+	}
+}
+
+// flagSet returns the check flags
+func (c *CheckCommand) flagSet(action string) *flag.FlagSet {
+	fs := flag.NewFlagSet("check", flag.ExitOnError)
+	fs.BoolVar(&c.updatesOnly, "updates", false, "Only show containers with an update available")
+	fs.BoolVar(&c.cached, "cached", false, "Show the server's last check result instead of checking again (with --server)")
+	fs.BoolVar(&c.differential, "differential", false, "Reuse the last result of containers whose registry digest and tags are unchanged")
+	fs.StringVar(&c.group, "group", "", "Only show containers in this docksmith.group")
+	fs.DurationVar(&c.timeout, "timeout", c.timeout, "How long to wait for the check to finish")
+	fs.Usage = printCheckUsage
+	return fs
+}
+
+// Run checks containers for updates and prints the result. Locally the check runs
+// in this process; with --server the server runs it and its result is printed.
+func (c *CheckCommand) Run(ctx context.Context, args []string) error {
+	names, err := parseInterspersed(c.flagSet(""), args)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	var result *update.DiscoveryResult
+	if isRemote() {
+		result, err = c.checkRemote(ctx, newRemoteClient())
+	} else {
+		result, err = c.checkLocal(ctx)
+	}
+	if err != nil {
+		return err
+	}
+
+	containers := filterContainers(result.Containers, names, c.updatesOnly)
+	if c.group != "" {
+		containers = filterGroup(containers, c.group)
+	}
+	if jsonOutput() {
+		return writeJSON(map[string]any{
+			"containers":        containers,
+			"count":             len(containers),
+			"updates_found":     countUpdates(containers),
+			"dependency_cycles": result.DependencyCycles,
+		})
+	}
+	return printCheckResult(containers, result.DependencyCycles)
+}
+
+// checkLocal runs a check against the local Docker socket
+func (c *CheckCommand) checkLocal(ctx context.Context) (*update.DiscoveryResult, error) {
+	dockerService, err := docker.NewService()
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to Docker: %w", err)
+	}
+	defer dockerService.Close()
+
+	store, err := InitializeStorage()
+	if err != nil {
+		return nil, err
+	}
+	defer store.Close()
+
+	orchestrator := update.NewOrchestrator(dockerService, InitializeRegistryManager(store))
+	orchestrator.SetStorage(store)
+	orchestrator.SetArchFallback(update.ArchFallbackFromEnv())
+	orchestrator.SetDifferentialCheck(c.differential || update.DifferentialCheckFromEnv())
+	orchestrator.SetCheckTimeout(update.CheckTimeoutFromEnv())
+	orchestrator.SetEOLClient(eol.NewClientFromEnv())
+
+	result, err := orchestrator.DiscoverAndCheck(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check containers: %w", err)
+	}
+	return result, nil
+}
+
+// checkRemote triggers a check on the server and waits for its result
+func (c *CheckCommand) checkRemote(ctx context.Context, client *remoteClient) (*update.DiscoveryResult, error) {
+	var result update.DiscoveryResult
+	if c.cached {
+		if err := client.do(ctx, http.MethodGet, "/api/status", nil, &result); err != nil {
+			return nil, err
+		}
+		return &result, nil
+	}
+
+	if err := client.do(ctx, http.MethodGet, "/api/check", nil, &result); err != nil {
+		return nil, err
+	}
+
+	// The server checks in the background; poll until the check has finished
+	ticker := time.NewTicker(operationPollInterval)
+	defer ticker.Stop()
+	for result.Checking {
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return nil, fmt.Errorf("timed out waiting for the check to finish")
+		}
+		result = update.DiscoveryResult{}
+		if err := client.do(ctx, http.MethodGet, "/api/status", nil, &result); err != nil {
+			return nil, err
+		}
+	}
+	return &result, nil
+}
+
+// filterContainers returns the containers named in names (all when empty),
+// optionally only those with an update available.
+func filterContainers(containers []update.ContainerInfo, names []string, updatesOnly bool) []update.ContainerInfo {
+	wanted := make(map[string]bool, len(names))
+	for _, name := range names {
+		wanted[name] = true
+	}
+
+	result := []update.ContainerInfo{}
+	for _, c := range containers {
+		if len(wanted) > 0 && !wanted[c.ContainerName] {
+			continue
+		}
+		if updatesOnly && !hasUpdate(c) {
+			continue
+		}
+		result = append(result, c)
+	}
+	return result
+}
+
+// filterGroup returns the containers belonging to group
+func filterGroup(containers []update.ContainerInfo, group string) []update.ContainerInfo {
+	result := []update.ContainerInfo{}
+	for _, c := range containers {
+		if c.InGroup(group) {
+			result = append(result, c)
+		}
+	}
+	return result
+}
+
+// hasUpdate reports whether a checked container has a newer version
+func hasUpdate(c update.ContainerInfo) bool {
+	return c.Status == update.UpdateAvailable || c.Status == update.UpdateAvailableBlocked
+}
+
+// countUpdates returns how many containers have an update available
+func countUpdates(containers []update.ContainerInfo) int {
+	count := 0
+	for _, c := range containers {
+		if hasUpdate(c) {
+			count++
+		}
+	}
+	return count
+}
+
+// printCheckResult prints checked containers as a table
+func printCheckResult(containers []update.ContainerInfo, cycles []graph.Cycle) error {
+	if len(containers) == 0 {
+		fmt.Println("No containers found")
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "CONTAINER\tSTACK\tCURRENT\tLATEST\tSTATUS")
+	for _, c := range containers {
+		current := c.CurrentVersion
+		if current == "" {
+			current = c.CurrentTag
+		}
+		status := string(c.Status)
+		if c.Error != "" {
+			status += ": " + c.Error
+		} else if c.PreUpdateCheckFail != "" {
+			status += ": " + c.PreUpdateCheckFail
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", c.ContainerName, valueOrDash(c.Stack), valueOrDash(current), valueOrDash(c.LatestVersion), status)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Printf("\n%d containers, %d updates available\n", len(containers), countUpdates(containers))
+	printEndOfLife(containers)
+	printDependencyCycles(cycles)
+	return nil
+}
+
+// printEndOfLife warns about containers running a release cycle past its end of life
+func printEndOfLife(containers []update.ContainerInfo) {
+	for _, c := range containers {
+		lifecycle := c.EndOfLife
+		if lifecycle == nil || !lifecycle.EOL {
+			continue
+		}
+		since := ""
+		if lifecycle.EOLDate != "" {
+			since = " since " + lifecycle.EOLDate
+		}
+		fmt.Printf("Warning: %s runs %s %s, end of life%s (newest: %s)\n", c.ContainerName, lifecycle.Product, lifecycle.Cycle, since, lifecycle.LatestCycle)
+	}
+}
+
+// printDependencyCycles warns about circular dependencies. Cycles through
+// docksmith.restart-after labels make restarts trigger each other endlessly.
+func printDependencyCycles(cycles []graph.Cycle) {
+	for _, cycle := range cycles {
+		kind := "circular dependency"
+		if cycle.RestartLoop {
+			kind = "restart loop"
+		}
+		fmt.Printf("Warning: %s between %s (%s)\n", kind, strings.Join(cycle.Containers, ", "), strings.Join(cycle.Sources, ", "))
+	}
+}
+
+// valueOrDash returns "-" for empty table cells
+func valueOrDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
+
+func printCheckUsage() {
+	fmt.Println(`Usage:
+  docksmith check [container...] [--updates] [--cached] [--differential] [--group name]
+
+Checks containers for updates and prints their status.
+
+Options:
+  --updates          Only show containers with an update available
+  --cached           With --server, show the server's last result without checking again
+  --differential     Only check containers in full whose registry digest or tag list
+                     changed since their last check; the others reuse its result
+                     (DIFFERENTIAL_CHECK=true on the server)
+  --group <name>     Only show containers in this group (docksmith.group label)
+  --timeout D        How long to wait for the check to finish (default 10m)
+
+Examples:
+  docksmith check
+  docksmith check plex sonarr
+  docksmith check --group media --updates
+  docksmith --server https://nas:3000 --api-key $KEY check --updates`)
+}
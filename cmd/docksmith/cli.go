@@ -0,0 +1,414 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/chis/docksmith/internal/output"
+)
+
+// Output formats accepted by --output
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+)
+
+// globalOptions holds the flags accepted by every command
+type globalOptions struct {
+	host   string // docksmith server to talk to instead of the local database and Docker socket
+	db     string // database path, overrides DB_PATH
+	output string // table or json
+	token  string // API key for the server
+}
+
+// globals is set from the command line before a command runs
+var globals = globalOptions{output: outputTable}
+
+// globalFlags lists the global flags in the order they are shown in help
+var globalFlags = []struct {
+	name  string
+	alias string
+	value *string
+	env   string
+	usage string
+}{
+	{"server", "host", &globals.host, "DOCKSMITH_HOST", "URL of a docksmith server to manage instead of the local host"},
+	{"api-key", "token", &globals.token, "DOCKSMITH_TOKEN", "API key used with --server"},
+	{"db", "", &globals.db, "DB_PATH", "Path to the SQLite database"},
+	{"output", "", &globals.output, "DOCKSMITH_OUTPUT", "Output format: table or json"},
+}
+
+// commandRunner is implemented by every subcommand
+type commandRunner interface {
+	// Run executes the command with the arguments following its name
+	Run(ctx context.Context, args []string) error
+
+	// flagSet returns the flags of an action ("" for commands without actions).
+	// It is used both to parse arguments and to offer shell completions.
+	flagSet(action string) *flag.FlagSet
+}
+
+// Command describes a subcommand for dispatch, help, and completion
+type Command struct {
+	Name    string
+	Aliases []string
+	Short   string   // one-line description
+	Actions []string // sub-actions such as create or list
+	Local   bool     // needs the local database or Docker socket, so --server is not supported
+	Help    func()   // detailed usage for `docksmith help <command>`
+	New     func() commandRunner
+}
+
+// commands returns every subcommand in the order they are listed in help
+func commands() []*Command {
+	return []*Command{
+		{
+			Name:    "serve",
+			Aliases: []string{"api"},
+			Short:   "Start the API server and web UI (the default)",
+			Local:   true,
+			Help:    printServeUsage,
+			New:     func() commandRunner { return NewAPICommand() },
+		},
+		{
+			Name:  "tui",
+			Short: "Interactive dashboard to review and apply updates",
+			Local: true,
+			Help:  printTUIUsage,
+			New:   func() commandRunner { return NewTUICommand() },
+		},
+		{
+			Name:  "check",
+			Short: "Check containers for updates",
+			Help:  printCheckUsage,
+			New:   func() commandRunner { return NewCheckCommand() },
+		},
+		{
+			Name:  "update",
+			Short: "Update containers and follow the progress",
+			Help:  printUpdateUsage,
+			New:   func() commandRunner { return NewUpdateCommand() },
+		},
+		{
+			Name:  "variant",
+			Short: "List or switch image variants such as -alpine",
+			Help:  printVariantUsage,
+			New:   func() commandRunner { return NewVariantCommand() },
+		},
+		{
+			Name:  "history",
+			Short: "Show the check and update timeline",
+			Help:  printHistoryUsage,
+			New:   func() commandRunner { return NewHistoryCommand() },
+		},
+		{
+			Name:  "rollback",
+			Short: "List or roll back completed updates",
+			Help:  printRollbackUsage,
+			New:   func() commandRunner { return NewRollbackCommand() },
+		},
+		{
+			Name:    "group",
+			Short:   "List, ignore, or schedule container groups",
+			Actions: []string{"list", "ignore", "unignore", "schedule", "unschedule"},
+			Help:    printGroupUsage,
+			New:     func() commandRunner { return NewGroupCommand() },
+		},
+		{
+			Name:    "config",
+			Short:   "Export or import the configuration as YAML",
+			Actions: []string{"export", "import"},
+			Help:    printConfigUsage,
+			New:     func() commandRunner { return NewConfigCommand() },
+		},
+		{
+			Name:    "db",
+			Short:   "Back up, vacuum, or prune the database",
+			Actions: []string{"backup", "vacuum", "prune", "retention"},
+			Help:    printDBUsage,
+			New:     func() commandRunner { return NewDBCommand() },
+		},
+		{
+			Name:    "approvals",
+			Short:   "Review updates waiting for approval",
+			Actions: []string{"list", "approve", "reject", "policies", "set-policy", "unset-policy"},
+			Local:   true,
+			Help:    printApprovalsUsage,
+			New:     func() commandRunner { return NewApprovalsCommand() },
+		},
+		{
+			Name:    "ignore",
+			Short:   "Manage image ignore rules",
+			Actions: []string{"list", "add", "remove"},
+			Help:    printIgnoreUsage,
+			New:     func() commandRunner { return NewIgnoreCommand() },
+		},
+		{
+			Name:    "secret",
+			Short:   "Manage encrypted secrets",
+			Actions: []string{"list", "set", "remove"},
+			Help:    printSecretUsage,
+			New:     func() commandRunner { return NewSecretCommand() },
+		},
+		{
+			Name:    "apikey",
+			Short:   "Manage API keys",
+			Actions: []string{"create", "revoke", "list"},
+			Local:   true,
+			Help:    printAPIKeyUsage,
+			New:     func() commandRunner { return NewAPIKeyCommand() },
+		},
+		{
+			Name:    "hook",
+			Short:   "Manage incoming webhooks",
+			Actions: []string{"create", "delete", "list"},
+			Local:   true,
+			Help:    printHookUsage,
+			New:     func() commandRunner { return NewHookCommand() },
+		},
+		{
+			Name:    "user",
+			Short:   "Manage web UI users",
+			Actions: []string{"create", "passwd", "set-role", "delete", "list"},
+			Local:   true,
+			Help:    printUserUsage,
+			New:     func() commandRunner { return NewUserCommand() },
+		},
+		{
+			Name:  "install",
+			Short: "Install docksmith as a systemd service",
+			Local: true,
+			Help:  printInstallUsage,
+			New:   func() commandRunner { return NewInstallCommand() },
+		},
+		{
+			Name:    "completion",
+			Short:   "Generate a shell completion script",
+			Actions: []string{"bash", "zsh", "fish"},
+			Help:    printCompletionUsage,
+			New:     func() commandRunner { return &CompletionCommand{} },
+		},
+	}
+}
+
+// findCommand returns the command with the given name or alias, or nil
+func findCommand(name string) *Command {
+	for _, cmd := range commands() {
+		if cmd.Name == name {
+			return cmd
+		}
+		for _, alias := range cmd.Aliases {
+			if alias == name {
+				return cmd
+			}
+		}
+	}
+	return nil
+}
+
+// execute parses global flags and runs the selected command.
+// Without a command, or when the first argument is a flag, the API server is started.
+func execute(ctx context.Context, args []string) error {
+	// Completion requests see the raw words, including partially typed global flags
+	if len(args) > 0 && args[0] == "__complete" {
+		for _, candidate := range complete(args[1:]) {
+			fmt.Println(candidate)
+		}
+		return nil
+	}
+
+	args, err := parseGlobalFlags(args)
+	if err != nil {
+		return err
+	}
+
+	name := "serve"
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		name, args = args[0], args[1:]
+	}
+
+	switch name {
+	case "help":
+		return runHelp(args)
+	case "version":
+		fmt.Printf("docksmith %s\n", output.Version)
+		return nil
+	}
+
+	// Top-level --help and --version, e.g. `docksmith --help`
+	if name == "serve" {
+		for _, arg := range args {
+			switch arg {
+			case "-h", "--help":
+				printUsage()
+				return nil
+			case "-v", "--version":
+				fmt.Printf("docksmith %s\n", output.Version)
+				return nil
+			}
+		}
+	}
+
+	cmd := findCommand(name)
+	if cmd == nil {
+		printUsage()
+		return fmt.Errorf("unknown command: %s", name)
+	}
+	if cmd.Local && globals.host != "" {
+		return fmt.Errorf("docksmith %s does not support --server; run it on the docksmith host", cmd.Name)
+	}
+	return cmd.New().Run(ctx, args)
+}
+
+// parseGlobalFlags removes the global flags from args, wherever they appear before "--",
+// and stores them in globals. Flags that are not given fall back to their environment variable.
+func parseGlobalFlags(args []string) ([]string, error) {
+	for _, f := range globalFlags {
+		if v := os.Getenv(f.env); v != "" {
+			*f.value = v
+		}
+	}
+
+	var rest []string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			rest = append(rest, args[i:]...)
+			break
+		}
+
+		name, value, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		var target *string
+		if strings.HasPrefix(arg, "-") {
+			for _, f := range globalFlags {
+				if f.name == name || (f.alias != "" && f.alias == name) {
+					target = f.value
+				}
+			}
+		}
+		if target == nil {
+			rest = append(rest, arg)
+			continue
+		}
+
+		if !hasValue {
+			if i+1 >= len(args) {
+				return nil, fmt.Errorf("flag needs an argument: --%s", name)
+			}
+			i++
+			value = args[i]
+		}
+		*target = value
+	}
+
+	if globals.output != outputTable && globals.output != outputJSON {
+		return nil, fmt.Errorf("invalid --output %q (must be table or json)", globals.output)
+	}
+	globals.host = strings.TrimRight(globals.host, "/")
+	return rest, nil
+}
+
+// parseInterspersed parses flags that may appear before, between, or after
+// positional arguments and returns the positional arguments.
+func parseInterspersed(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		if args[0] == "--" {
+			return append(positional, args[1:]...), nil
+		}
+		positional, args = append(positional, args[0]), args[1:]
+	}
+}
+
+// jsonOutput reports whether commands should print JSON instead of tables
+func jsonOutput() bool {
+	return globals.output == outputJSON
+}
+
+// writeJSON prints data in the same envelope the API uses
+func writeJSON(data any) error {
+	return output.WriteJSONData(os.Stdout, data)
+}
+
+// cliTrigger returns the trigger recorded on operations started by local commands
+func cliTrigger() string {
+	if user := os.Getenv("USER"); user != "" {
+		return "cli:" + user
+	}
+	return "cli"
+}
+
+// runHelp prints general help, or the usage of one command
+func runHelp(args []string) error {
+	if len(args) == 0 {
+		printUsage()
+		return nil
+	}
+	cmd := findCommand(args[0])
+	if cmd == nil {
+		return fmt.Errorf("unknown command: %s", args[0])
+	}
+	cmd.Help()
+	return nil
+}
+
+func printUsage() {
+	var b strings.Builder
+	b.WriteString("docksmith - Docker container update manager\n\n")
+	b.WriteString("Usage:\n  docksmith [global flags] <command> [arguments]\n\nCommands:\n")
+	for _, cmd := range commands() {
+		fmt.Fprintf(&b, "  %-12s %s\n", cmd.Name, cmd.Short)
+	}
+	fmt.Fprintf(&b, "  %-12s %s\n", "help", "Show help for a command")
+	fmt.Fprintf(&b, "  %-12s %s\n", "version", "Show version information")
+
+	b.WriteString("\nGlobal Flags:\n")
+	for _, f := range globalFlags {
+		fmt.Fprintf(&b, "  --%-10s %s (%s)\n", f.name, f.usage, f.env)
+		if f.alias != "" {
+			fmt.Fprintf(&b, "  --%-10s Alias of --%s\n", f.alias, f.name)
+		}
+	}
+
+	b.WriteString(`
+Environment Variables:
+  DB_PATH        Path to SQLite database (default: /data/docksmith.db)
+  DB_DRIVER      Storage backend: sqlite (default), postgres, or memory
+  DB_DSN         PostgreSQL connection string when DB_DRIVER=postgres
+  STATIC_DIR     Directory containing static UI files (default: /app/ui/dist)
+  GITHUB_TOKEN   GitHub token for accessing private registries
+  DOCKSMITH_AUTH API authentication: optional (default), required, or disabled
+
+Examples:
+  docksmith                  # Start server on port 3000
+  docksmith --port 8080      # Start server on port 8080
+  docksmith check --updates
+  docksmith --server https://nas:3000 --api-key $KEY update plex
+  docksmith history --since 7d --output json
+  docksmith apikey create --name ci --scope update
+  docksmith help rollback
+
+Run 'docksmith help <command>' for details on a command.`)
+	fmt.Println(b.String())
+}
+
+func printServeUsage() {
+	fmt.Println(`Usage:
+  docksmith [serve] [--port N] [--static-dir dir]
+
+Options:
+  --port, -p <port>          Port to listen on (default: 3000)
+  --static-dir, -s <dir>     Directory containing static UI files (empty to disable).
+                             Binaries built with the embedui tag serve their
+                             built-in UI when the directory does not exist.`)
+}
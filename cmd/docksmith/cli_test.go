@@ -0,0 +1,88 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func resetGlobals(t *testing.T) {
+	t.Helper()
+	for _, env := range []string{"DOCKSMITH_HOST", "DB_PATH", "DOCKSMITH_OUTPUT", "DOCKSMITH_TOKEN"} {
+		t.Setenv(env, "")
+	}
+	globals = globalOptions{output: outputTable}
+	t.Cleanup(func() { globals = globalOptions{output: outputTable} })
+}
+
+func TestParseGlobalFlags(t *testing.T) {
+	resetGlobals(t)
+
+	rest, err := parseGlobalFlags([]string{"--db", "/tmp/test.db", "history", "--output=json", "--since", "7d", "--", "--token", "x"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"history", "--since", "7d", "--", "--token", "x"}, rest)
+	assert.Equal(t, "/tmp/test.db", globals.db)
+	assert.True(t, jsonOutput())
+	assert.Empty(t, globals.token)
+
+	_, err = parseGlobalFlags([]string{"--output", "yaml"})
+	assert.Error(t, err)
+	_, err = parseGlobalFlags([]string{"history", "--host"})
+	assert.Error(t, err)
+}
+
+func TestParseGlobalFlags_Aliases(t *testing.T) {
+	resetGlobals(t)
+
+	rest, err := parseGlobalFlags([]string{"--server", "nas:3000", "--api-key=dsk_1", "check"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"check"}, rest)
+	assert.Equal(t, "nas:3000", globals.host)
+	assert.Equal(t, "dsk_1", globals.token)
+
+	_, err = parseGlobalFlags([]string{"--host", "other:3000", "--token", "dsk_2", "check"})
+	require.NoError(t, err)
+	assert.Equal(t, "other:3000", globals.host)
+	assert.Equal(t, "dsk_2", globals.token)
+}
+
+func TestParseInterspersed(t *testing.T) {
+	cmd := NewCheckCommand()
+	names, err := parseInterspersed(cmd.flagSet(""), []string{"plex", "--updates", "sonarr", "--", "--odd"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"plex", "sonarr", "--odd"}, names)
+	assert.True(t, cmd.updatesOnly)
+}
+
+func TestParseDays(t *testing.T) {
+	for value, want := range map[string]int{"90d": 90, "30": 30, "2w": 14, "2160h": 90} {
+		days, err := parseDays(value)
+		require.NoError(t, err, value)
+		assert.Equal(t, want, days, value)
+	}
+	for _, value := range []string{"", "soon", "0d", "12h", "-1d"} {
+		_, err := parseDays(value)
+		assert.Error(t, err, value)
+	}
+}
+
+func TestParseGlobalFlags_Environment(t *testing.T) {
+	resetGlobals(t)
+	t.Setenv("DOCKSMITH_HOST", "https://docksmith.lan/")
+
+	_, err := parseGlobalFlags([]string{"history"})
+	require.NoError(t, err)
+	assert.Equal(t, "https://docksmith.lan", globals.host)
+}
+
+func TestComplete(t *testing.T) {
+	assert.Equal(t, []string{"apikey", "approvals"}, complete([]string{"ap"}))
+	assert.Equal(t, []string{"create", "list", "revoke"}, complete([]string{"apikey", ""}))
+	assert.Equal(t, []string{"--scope", "--server"}, complete([]string{"apikey", "create", "--s"}))
+	assert.Equal(t, []string{"json", "table"}, complete([]string{"history", "--output", ""}))
+	assert.Equal(t, []string{"rollback"}, complete([]string{"--db", "x.db", "rol"}))
+	assert.Equal(t, []string{"update"}, complete([]string{"--server", "nas:3000", "up"}))
+	assert.Equal(t, []string{"--port"}, complete([]string{"--po"}))
+	assert.Empty(t, complete([]string{"unknown", "--"}))
+}
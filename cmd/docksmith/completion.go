@@ -0,0 +1,172 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// CompletionCommand implements `docksmith completion`, which prints a shell completion script.
+// The scripts call back into `docksmith __complete` so completions always match the binary.
+type CompletionCommand struct{}
+
+// Run prints the completion script for the requested shell
+func (c *CompletionCommand) Run(ctx context.Context, args []string) error {
+	if len(args) != 1 {
+		printCompletionUsage()
+		return fmt.Errorf("missing shell")
+	}
+
+	switch args[0] {
+	case "bash":
+		fmt.Print(bashCompletion)
+	case "zsh":
+		fmt.Print(zshCompletion)
+	case "fish":
+		fmt.Print(fishCompletion)
+	default:
+		printCompletionUsage()
+		return fmt.Errorf("unsupported shell: %s", args[0])
+	}
+	return nil
+}
+
+func (c *CompletionCommand) flagSet(action string) *flag.FlagSet {
+	return flag.NewFlagSet("completion", flag.ExitOnError)
+}
+
+// complete returns the candidates for the last word of args, the words typed after "docksmith".
+func complete(args []string) []string {
+	if len(args) == 0 {
+		args = []string{""}
+	}
+	words, current := args[:len(args)-1], args[len(args)-1]
+
+	// Skip global flags and their values to find the command and its action
+	var positional []string
+	var previousFlag string
+	for i := 0; i < len(words); i++ {
+		word := words[i]
+		if isGlobalFlag(word) && !strings.Contains(word, "=") {
+			if i == len(words)-1 {
+				previousFlag = strings.TrimLeft(word, "-")
+			}
+			i++
+			continue
+		}
+		if !strings.HasPrefix(word, "-") {
+			positional = append(positional, word)
+		}
+	}
+
+	var candidates []string
+	switch {
+	case previousFlag == "output":
+		candidates = []string{outputTable, outputJSON}
+	case previousFlag != "":
+		return nil
+	case len(positional) == 0 && !strings.HasPrefix(current, "-"):
+		candidates = append(candidates, "help", "version")
+		for _, cmd := range commands() {
+			candidates = append(candidates, cmd.Name)
+		}
+	case len(positional) == 1 && positional[0] == "help":
+		for _, cmd := range commands() {
+			candidates = append(candidates, cmd.Name)
+		}
+	default:
+		var cmd *Command
+		if len(positional) > 0 {
+			cmd = findCommand(positional[0])
+		}
+		if cmd == nil && len(positional) > 0 {
+			return nil
+		}
+
+		if strings.HasPrefix(current, "-") {
+			for _, f := range globalFlags {
+				candidates = append(candidates, "--"+f.name)
+			}
+			if cmd == nil {
+				cmd = findCommand("serve")
+			}
+			action := ""
+			if len(cmd.Actions) > 0 && len(positional) > 1 {
+				action = positional[1]
+			}
+			cmd.New().flagSet(action).VisitAll(func(f *flag.Flag) {
+				candidates = append(candidates, "--"+f.Name)
+			})
+		} else if len(cmd.Actions) > 0 && len(positional) == 1 {
+			candidates = cmd.Actions
+		}
+	}
+
+	var matches []string
+	for _, candidate := range candidates {
+		if strings.HasPrefix(candidate, current) {
+			matches = append(matches, candidate)
+		}
+	}
+	sort.Strings(matches)
+	return matches
+}
+
+// isGlobalFlag reports whether word is one of the global flags, with or without a value
+func isGlobalFlag(word string) bool {
+	if !strings.HasPrefix(word, "-") {
+		return false
+	}
+	name, _, _ := strings.Cut(strings.TrimLeft(word, "-"), "=")
+	for _, f := range globalFlags {
+		if f.name == name || (f.alias != "" && f.alias == name) {
+			return true
+		}
+	}
+	return false
+}
+
+func printCompletionUsage() {
+	fmt.Println(`Usage:
+  docksmith completion bash|zsh|fish
+
+Examples:
+  source <(docksmith completion bash)
+  docksmith completion zsh > "${fpath[1]}/_docksmith"
+  docksmith completion fish > ~/.config/fish/completions/docksmith.fish`)
+}
+
+const bashCompletion = `# bash completion for docksmith
+_docksmith() {
+    local cur="${COMP_WORDS[COMP_CWORD]}"
+    local IFS=$'\n'
+    COMPREPLY=($(docksmith __complete "${COMP_WORDS[@]:1:COMP_CWORD}" 2>/dev/null))
+    [[ ${#COMPREPLY[@]} -eq 0 && $cur != -* ]] && COMPREPLY=($(compgen -f -- "$cur"))
+}
+complete -F _docksmith docksmith
+`
+
+const zshCompletion = `#compdef docksmith
+# zsh completion for docksmith
+_docksmith() {
+    local -a candidates
+    candidates=(${(f)"$(docksmith __complete "${(@)words[2,CURRENT]}" 2>/dev/null)"})
+    if (( ${#candidates} )); then
+        compadd -a candidates
+    else
+        _files
+    fi
+}
+compdef _docksmith docksmith
+`
+
+const fishCompletion = `# fish completion for docksmith
+function __docksmith_complete
+    set -l words (commandline -opc)
+    set -e words[1]
+    docksmith __complete $words (commandline -ct) 2>/dev/null
+end
+complete -c docksmith -f -a '(__docksmith_complete)'
+`
@@ -1,9 +1,14 @@
 package main
 
 import (
+	"context"
 	"fmt"
+	"log"
 	"os"
+	"strconv"
 
+	"github.com/chis/docksmith/internal/registry"
+	"github.com/chis/docksmith/internal/secrets"
 	"github.com/chis/docksmith/internal/storage"
 )
 
@@ -12,19 +17,102 @@ const (
 	DefaultDBPath = "/data/docksmith.db"
 )
 
-// getDBPath returns the database path from environment variable or default
+// getDBPath returns the database path from --db, the environment variable, or the default
 func getDBPath() string {
+	if globals.db != "" {
+		return globals.db
+	}
 	if path := os.Getenv("DB_PATH"); path != "" {
 		return path
 	}
 	return DefaultDBPath
 }
 
-// InitializeStorage initializes and returns a storage service using the configured database path
-func InitializeStorage() (*storage.SQLiteStorage, error) {
-	storageService, err := storage.NewSQLiteStorage(getDBPath())
+// InitializeStorage initializes and returns the storage service selected by DB_DRIVER:
+// SQLite at the configured database path (default), PostgreSQL at DB_DSN, or
+// in-memory storage that is discarded on exit
+func InitializeStorage() (storage.Storage, error) {
+	var storageService storage.Storage
+	var err error
+	switch driver := os.Getenv("DB_DRIVER"); driver {
+	case "", "sqlite":
+		storageService, err = storage.NewSQLiteStorage(getDBPath())
+	case "postgres":
+		storageService, err = storage.NewPostgresStorage(os.Getenv("DB_DSN"))
+	case "memory":
+		storageService = storage.NewMemoryStorage()
+	default:
+		return nil, fmt.Errorf("unknown DB_DRIVER %q (must be sqlite, postgres, or memory)", driver)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize storage: %w", err)
 	}
 	return storageService, nil
 }
+
+// InitializeRegistryManager creates a registry manager configured from GITHUB_TOKEN,
+// GITLAB_TOKEN, QUAY_TOKEN, HARBOR_ROBOT, GITEA_TOKEN, REGISTRY_PROVIDERS,
+// REGISTRY_RATE_LIMIT, TAG_LIST_MAX_PAGES and the proxy environment variables. The tokens may refer
+// to secrets in store ("secret:NAME"); store may be nil
+func InitializeRegistryManager(store storage.Storage) *registry.Manager {
+	secretStore := secrets.NewStoreFromEnv(store)
+	githubToken, err := secretStore.Resolve(context.Background(), os.Getenv("GITHUB_TOKEN"))
+	if err != nil {
+		log.Printf("Warning: Invalid GITHUB_TOKEN, using Docker config credentials for GHCR: %v", err)
+	}
+	registryManager := registry.NewManager(githubToken)
+	if gitlabToken, err := secretStore.Resolve(context.Background(), os.Getenv("GITLAB_TOKEN")); err != nil {
+		log.Printf("Warning: Invalid GITLAB_TOKEN, using Docker config credentials for GitLab: %v", err)
+	} else if gitlabToken != "" {
+		registryManager.SetGitLabToken(gitlabToken)
+	}
+	if quayToken, err := secretStore.Resolve(context.Background(), os.Getenv("QUAY_TOKEN")); err != nil {
+		log.Printf("Warning: Invalid QUAY_TOKEN, listing Quay tags anonymously: %v", err)
+	} else if quayToken != "" {
+		registryManager.SetQuayToken(quayToken)
+	}
+	if harborRobot, err := secretStore.Resolve(context.Background(), os.Getenv("HARBOR_ROBOT")); err != nil {
+		log.Printf("Warning: Invalid HARBOR_ROBOT, using Docker config credentials for Harbor: %v", err)
+	} else if harborRobot != "" {
+		registryManager.SetHarborRobot(harborRobot)
+	}
+	if giteaToken, err := secretStore.Resolve(context.Background(), os.Getenv("GITEA_TOKEN")); err != nil {
+		log.Printf("Warning: Invalid GITEA_TOKEN, using Docker config credentials for Gitea: %v", err)
+	} else if giteaToken != "" {
+		registryManager.SetGiteaToken(giteaToken)
+	}
+	if providersStr := os.Getenv("REGISTRY_PROVIDERS"); providersStr != "" {
+		if providers, err := registry.ParseRegistryProviders(providersStr); err == nil {
+			registryManager.SetRegistryProviders(providers)
+			log.Printf("Using REGISTRY_PROVIDERS: %s", providersStr)
+		} else {
+			log.Printf("Warning: Invalid REGISTRY_PROVIDERS, selecting providers by hostname only: %v", err)
+		}
+	}
+	if rateStr := os.Getenv("REGISTRY_RATE_LIMIT"); rateStr != "" {
+		if rate, err := strconv.ParseFloat(rateStr, 64); err == nil {
+			registryManager.SetRateLimit(rate)
+			log.Printf("Using REGISTRY_RATE_LIMIT: %v requests/sec per registry", rate)
+		} else {
+			log.Printf("Warning: Invalid REGISTRY_RATE_LIMIT '%s', using default %v", rateStr, registry.DefaultRegistryRateLimit)
+		}
+	}
+	if pagesStr := os.Getenv("TAG_LIST_MAX_PAGES"); pagesStr != "" {
+		if limits, err := registry.ParseTagPageLimits(pagesStr); err == nil {
+			registryManager.SetTagPageLimits(limits)
+			log.Printf("Using TAG_LIST_MAX_PAGES: %s", pagesStr)
+		} else {
+			log.Printf("Warning: Invalid TAG_LIST_MAX_PAGES, using default page limits: %v", err)
+		}
+	}
+	if proxyConfig, err := registry.ProxyConfigFromEnv(); err != nil {
+		log.Printf("Warning: Invalid REGISTRY_PROXIES, using global proxy settings only: %v", err)
+	} else {
+		registryManager.SetProxyConfig(proxyConfig)
+		if proxies := proxyConfig.Describe(); proxies != "" {
+			log.Printf("Using registry proxies: %s", proxies)
+			log.Println("Note: image pulls are performed by the Docker daemon and use its own proxy settings")
+		}
+	}
+	return registryManager
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+
+	"github.com/chis/docksmith/internal/settings"
+)
+
+// ConfigCommand implements the `docksmith config` subcommands
+type ConfigCommand struct {
+	file string
+}
+
+// NewConfigCommand creates a new config command
+func NewConfigCommand() *ConfigCommand {
+	return &ConfigCommand{}
+}
+
+// flagSet returns the flags of a config action
+func (c *ConfigCommand) flagSet(action string) *flag.FlagSet {
+	fs := flag.NewFlagSet("config "+action, flag.ExitOnError)
+	fs.Usage = printConfigUsage
+	if action == "export" {
+		fs.StringVar(&c.file, "file", "", "Write to this file instead of stdout")
+	}
+	return fs
+}
+
+// Run dispatches to the export or import action
+func (c *ConfigCommand) Run(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		printConfigUsage()
+		return fmt.Errorf("missing config action")
+	}
+
+	action, rest := args[0], args[1:]
+	switch action {
+	case "export":
+		if err := c.flagSet(action).Parse(rest); err != nil {
+			return err
+		}
+		return c.export(ctx)
+	case "import":
+		if len(rest) != 1 {
+			return fmt.Errorf("usage: docksmith config import <file|->")
+		}
+		return c.importFile(ctx, rest[0])
+	default:
+		printConfigUsage()
+		return fmt.Errorf("unknown config action: %s", action)
+	}
+}
+
+// export writes the configuration as YAML
+func (c *ConfigCommand) export(ctx context.Context) error {
+	var data []byte
+	if isRemote() {
+		var err error
+		if data, err = newRemoteClient().fetch(ctx, "/api/config/export"); err != nil {
+			return err
+		}
+	} else {
+		store, err := InitializeStorage()
+		if err != nil {
+			return err
+		}
+		defer store.Close()
+
+		export, err := settings.Collect(ctx, store)
+		if err != nil {
+			return err
+		}
+		if data, err = settings.Marshal(export); err != nil {
+			return err
+		}
+	}
+
+	if c.file == "" {
+		_, err := os.Stdout.Write(data)
+		return err
+	}
+	// The export can contain notification webhook URLs
+	if err := os.WriteFile(c.file, data, 0600); err != nil {
+		return fmt.Errorf("failed to write %s: %w", c.file, err)
+	}
+	fmt.Printf("Exported configuration to %s\n", c.file)
+	return nil
+}
+
+// importFile applies a configuration file, or stdin for "-"
+func (c *ConfigCommand) importFile(ctx context.Context, path string) error {
+	var data []byte
+	var err error
+	if path == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read configuration: %w", err)
+	}
+
+	// Validate before sending so mistakes are reported the same way locally and remotely
+	export, err := settings.Parse(data)
+	if err != nil {
+		return err
+	}
+
+	var summary settings.Summary
+	if isRemote() {
+		var result struct {
+			Imported settings.Summary `json:"imported"`
+		}
+		if err := newRemoteClient().send(ctx, http.MethodPost, "/api/config/import", "application/yaml", data, &result); err != nil {
+			return err
+		}
+		summary = result.Imported
+	} else {
+		store, err := InitializeStorage()
+		if err != nil {
+			return err
+		}
+		defer store.Close()
+
+		if summary, err = settings.Apply(ctx, store, export); err != nil {
+			return err
+		}
+		if _, err := settings.RecordSnapshot(ctx, store, cliTrigger()); err != nil {
+			log.Printf("Warning: Failed to record configuration change: %v", err)
+		}
+	}
+
+	fmt.Printf("Imported %d container settings, %d rollback policies, %d ignore rules, and %d settings\n",
+		summary.Containers, summary.RollbackPolicies, summary.IgnoreRules, summary.Settings)
+	fmt.Println("Restart docksmith to apply schedule and notification changes.")
+	return nil
+}
+
+func printConfigUsage() {
+	fmt.Println(`Usage:
+  docksmith config export [--file path]     Export the configuration as YAML
+  docksmith config import <file|->          Import a configuration file
+
+The export contains per-container script, ignore, and allow-latest settings,
+rollback policies, UI settings, the check schedule, and the notification config.
+Importing creates or replaces the entries in the file and leaves others alone.
+Environment variables such as CHECK_INTERVAL and NOTIFY_MODE take precedence
+over imported values.
+
+Examples:
+  docksmith config export --file docksmith.yaml
+  docksmith --server https://nas:3000 --api-key $KEY config import docksmith.yaml`)
+}
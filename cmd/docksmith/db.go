@@ -0,0 +1,306 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/chis/docksmith/internal/storage"
+)
+
+// DBCommand implements the `docksmith db` maintenance subcommands
+type DBCommand struct {
+	file         string
+	olderThan    string
+	checkHistory string
+	updateLog    string
+}
+
+// NewDBCommand creates a new db command
+func NewDBCommand() *DBCommand {
+	return &DBCommand{}
+}
+
+// flagSet returns the flags of a db action
+func (c *DBCommand) flagSet(action string) *flag.FlagSet {
+	fs := flag.NewFlagSet("db "+action, flag.ExitOnError)
+	fs.Usage = printDBUsage
+	switch action {
+	case "backup":
+		fs.StringVar(&c.file, "file", "", "Backup file (default docksmith-<timestamp>.db)")
+	case "prune":
+		fs.StringVar(&c.olderThan, "older-than", "", "Delete rows older than this (e.g. 90d); defaults to the retention policy")
+	case "retention":
+		fs.StringVar(&c.checkHistory, "check-history", "", "Days of check history to keep (0 keeps everything)")
+		fs.StringVar(&c.updateLog, "update-log", "", "Days of update log to keep (0 keeps everything)")
+	}
+	return fs
+}
+
+// Run dispatches to the backup, vacuum, prune, or retention action
+func (c *DBCommand) Run(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		printDBUsage()
+		return fmt.Errorf("missing db action")
+	}
+
+	action, rest := args[0], args[1:]
+	switch action {
+	case "backup", "vacuum", "prune", "retention":
+	default:
+		printDBUsage()
+		return fmt.Errorf("unknown db action: %s", action)
+	}
+	if err := c.flagSet(action).Parse(rest); err != nil {
+		return err
+	}
+
+	switch action {
+	case "backup":
+		return c.backup(ctx)
+	case "vacuum":
+		return c.vacuum(ctx)
+	case "prune":
+		return c.prune(ctx)
+	default:
+		return c.retention(ctx)
+	}
+}
+
+// backup writes a snapshot of the database to a file
+func (c *DBCommand) backup(ctx context.Context) error {
+	path := c.file
+	if path == "" {
+		path = "docksmith-" + time.Now().Format("20060102-150405") + ".db"
+	}
+	if _, err := os.Stat(path); err == nil {
+		return fmt.Errorf("backup file already exists: %s", path)
+	}
+
+	if isRemote() {
+		data, err := newRemoteClient().fetch(ctx, "/api/db/backup")
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, data, 0600); err != nil {
+			return fmt.Errorf("failed to write %s: %w", path, err)
+		}
+	} else {
+		store, err := InitializeStorage()
+		if err != nil {
+			return err
+		}
+		defer store.Close()
+
+		if err := store.Backup(ctx, path); err != nil {
+			return err
+		}
+	}
+
+	fmt.Printf("Backed up database to %s\n", path)
+	return nil
+}
+
+// vacuum rebuilds the database to reclaim free space
+func (c *DBCommand) vacuum(ctx context.Context) error {
+	var result storage.VacuumResult
+	if isRemote() {
+		if err := newRemoteClient().do(ctx, http.MethodPost, "/api/db/vacuum", nil, &result); err != nil {
+			return err
+		}
+	} else {
+		store, err := InitializeStorage()
+		if err != nil {
+			return err
+		}
+		defer store.Close()
+
+		if result, err = store.Vacuum(ctx); err != nil {
+			return err
+		}
+	}
+
+	if jsonOutput() {
+		return writeJSON(result)
+	}
+	fmt.Printf("Vacuumed database: %s -> %s\n", formatMB(result.SizeBefore), formatMB(result.SizeAfter))
+	return nil
+}
+
+// prune deletes old check history and update log rows
+func (c *DBCommand) prune(ctx context.Context) error {
+	days := 0
+	if c.olderThan != "" {
+		var err error
+		if days, err = parseDays(c.olderThan); err != nil {
+			return fmt.Errorf("invalid --older-than: %w", err)
+		}
+	}
+
+	var result struct {
+		Deleted storage.PruneResult     `json:"deleted"`
+		Policy  storage.RetentionPolicy `json:"policy"`
+	}
+	if isRemote() {
+		req := map[string]int{"older_than_days": days}
+		if err := newRemoteClient().do(ctx, http.MethodPost, "/api/db/prune", req, &result); err != nil {
+			return err
+		}
+	} else {
+		store, err := InitializeStorage()
+		if err != nil {
+			return err
+		}
+		defer store.Close()
+
+		result.Policy = storage.RetentionPolicy{CheckHistoryDays: days, UpdateLogDays: days}
+		if days == 0 {
+			if result.Policy, err = storage.GetRetentionPolicy(ctx, store); err != nil {
+				return err
+			}
+			if !result.Policy.IsSet() {
+				return fmt.Errorf("no retention policy is configured; pass --older-than or run `docksmith db retention`")
+			}
+		}
+		if result.Deleted, err = store.PruneHistory(ctx, result.Policy.PruneOptions(time.Now())); err != nil {
+			return err
+		}
+	}
+
+	if jsonOutput() {
+		return writeJSON(result)
+	}
+	fmt.Printf("Deleted %d check history rows and %d update log rows\n", result.Deleted.CheckHistory, result.Deleted.UpdateLog)
+	return nil
+}
+
+// retention shows the retention policy, or changes it when flags are given
+func (c *DBCommand) retention(ctx context.Context) error {
+	updates := make(map[string]string)
+	for key, value := range map[string]string{
+		storage.CheckHistoryRetentionConfigKey: c.checkHistory,
+		storage.UpdateLogRetentionConfigKey:    c.updateLog,
+	} {
+		if value == "" {
+			continue
+		}
+		days := 0
+		if value != "0" {
+			var err error
+			if days, err = parseDays(value); err != nil {
+				return fmt.Errorf("invalid retention: %w", err)
+			}
+		}
+		updates[key] = strconv.Itoa(days)
+	}
+
+	var policy storage.RetentionPolicy
+	if isRemote() {
+		client := newRemoteClient()
+		for key, value := range updates {
+			if err := client.do(ctx, http.MethodPut, "/api/settings/"+key, map[string]string{"value": value}, nil); err != nil {
+				return err
+			}
+		}
+		for key, days := range map[string]*int{
+			storage.CheckHistoryRetentionConfigKey: &policy.CheckHistoryDays,
+			storage.UpdateLogRetentionConfigKey:    &policy.UpdateLogDays,
+		} {
+			var setting struct {
+				Value string `json:"value"`
+			}
+			if err := client.do(ctx, http.MethodGet, "/api/settings/"+key, nil, &setting); err != nil {
+				return err
+			}
+			*days, _ = strconv.Atoi(setting.Value)
+		}
+	} else {
+		store, err := InitializeStorage()
+		if err != nil {
+			return err
+		}
+		defer store.Close()
+
+		for key, value := range updates {
+			if err := store.SetConfig(ctx, key, value); err != nil {
+				return fmt.Errorf("failed to save %s: %w", key, err)
+			}
+		}
+		if policy, err = storage.GetRetentionPolicy(ctx, store); err != nil {
+			return err
+		}
+	}
+
+	if jsonOutput() {
+		return writeJSON(policy)
+	}
+	fmt.Printf("Check history: %s\n", formatRetention(policy.CheckHistoryDays))
+	fmt.Printf("Update log:    %s\n", formatRetention(policy.UpdateLogDays))
+	return nil
+}
+
+// parseDays parses an age given as days ("90d" or "90"), weeks ("12w"),
+// or a duration ("2160h") and returns whole days
+func parseDays(value string) (int, error) {
+	var n int
+	var err error
+	switch {
+	case strings.HasSuffix(value, "d"):
+		n, err = strconv.Atoi(strings.TrimSuffix(value, "d"))
+	case strings.HasSuffix(value, "w"):
+		n, err = strconv.Atoi(strings.TrimSuffix(value, "w"))
+		n *= 7
+	default:
+		if n, err = strconv.Atoi(value); err != nil {
+			var d time.Duration
+			if d, err = time.ParseDuration(value); err == nil {
+				n = int(d / (24 * time.Hour))
+			}
+		}
+	}
+	if err != nil {
+		return 0, fmt.Errorf("%q is not an age like 90d", value)
+	}
+	if n < 1 {
+		return 0, fmt.Errorf("%q is less than a day", value)
+	}
+	return n, nil
+}
+
+// formatRetention describes how long rows are kept
+func formatRetention(days int) string {
+	if days == 0 {
+		return "kept forever"
+	}
+	return fmt.Sprintf("%d days", days)
+}
+
+// formatMB formats a size in bytes as megabytes
+func formatMB(size int64) string {
+	return fmt.Sprintf("%.1f MB", float64(size)/(1024*1024))
+}
+
+func printDBUsage() {
+	fmt.Println(`Usage:
+  docksmith db backup [--file path]        Write a snapshot of the database
+  docksmith db vacuum                      Rebuild the database to reclaim free space
+  docksmith db prune [--older-than age]    Delete old check history and update log rows
+  docksmith db retention [--check-history days] [--update-log days]
+                                           Show or set how long rows are kept
+
+Backups are taken with the SQLite backup API and are safe while the server
+is running; with DB_DRIVER=postgres use pg_dump instead. Without
+--older-than, prune applies the retention policy, which the server also
+applies after each background check. Ages are days (90d), weeks (12w), or
+durations (2160h); a retention of 0 keeps rows forever.
+
+Examples:
+  docksmith db backup --file /backups/docksmith.db
+  docksmith db prune --older-than 90d && docksmith db vacuum
+  docksmith db retention --check-history 30d --update-log 365d`)
+}
@@ -0,0 +1,212 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/chis/docksmith/internal/update"
+)
+
+// GroupCommand implements the `docksmith group` subcommands
+type GroupCommand struct {
+	period  string
+	at      string
+	weekday string
+}
+
+// NewGroupCommand creates a new group command
+func NewGroupCommand() *GroupCommand {
+	return &GroupCommand{
+		period: "daily",
+	}
+}
+
+// flagSet returns the flags of a group action
+func (c *GroupCommand) flagSet(action string) *flag.FlagSet {
+	fs := flag.NewFlagSet("group "+action, flag.ExitOnError)
+	fs.Usage = printGroupUsage
+	if action == "schedule" {
+		fs.StringVar(&c.period, "period", c.period, "How often to update the group (daily or weekly)")
+		fs.StringVar(&c.at, "at", "", "Time of day to update the group (HH:MM, default 09:00)")
+		fs.StringVar(&c.weekday, "weekday", "", "Day of weekly updates (default monday)")
+	}
+	return fs
+}
+
+// groupListEntry is a group as returned by GET /api/groups
+type groupListEntry struct {
+	update.Group
+	Schedule *struct {
+		Period  string `json:"period"`
+		At      string `json:"at"`
+		Weekday string `json:"weekday"`
+	} `json:"schedule,omitempty"`
+	NextRun string `json:"next_run,omitempty"`
+}
+
+// Run dispatches to the list, ignore, unignore, schedule, or unschedule action.
+// Actions other than list change compose labels or server schedules and need --server.
+func (c *GroupCommand) Run(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		printGroupUsage()
+		return fmt.Errorf("missing group action")
+	}
+
+	action, rest := args[0], args[1:]
+	if action == "list" || action == "ls" {
+		if err := c.flagSet("list").Parse(rest); err != nil {
+			return err
+		}
+		return c.list(ctx)
+	}
+
+	switch action {
+	case "ignore", "unignore", "schedule", "unschedule":
+	default:
+		printGroupUsage()
+		return fmt.Errorf("unknown group action: %s", action)
+	}
+
+	names, err := parseInterspersed(c.flagSet(action), rest)
+	if err != nil {
+		return err
+	}
+	if len(names) != 1 {
+		return fmt.Errorf("usage: docksmith group %s <group>", action)
+	}
+	if !isRemote() {
+		return fmt.Errorf("group %s runs on the server; use --server", action)
+	}
+
+	client := newRemoteClient()
+	group := names[0]
+	path := url.PathEscape(group)
+
+	switch action {
+	case "ignore", "unignore":
+		var result struct {
+			Results []struct {
+				Container string `json:"container"`
+				Success   bool   `json:"success"`
+				Error     string `json:"error"`
+			} `json:"results"`
+		}
+		body := map[string]bool{"ignore": action == "ignore"}
+		if err := client.do(ctx, http.MethodPost, "/api/groups/ignore/"+path, body, &result); err != nil {
+			return err
+		}
+		if jsonOutput() {
+			return writeJSON(result)
+		}
+		var failed []string
+		for _, r := range result.Results {
+			if !r.Success {
+				failed = append(failed, fmt.Sprintf("%s: %s", r.Container, r.Error))
+			}
+		}
+		verb := "Ignoring"
+		if action == "unignore" {
+			verb = "No longer ignoring"
+		}
+		fmt.Printf("%s updates for %d containers in group %s\n", verb, len(result.Results)-len(failed), group)
+		if len(failed) > 0 {
+			return fmt.Errorf("failed to update labels:\n  %s", strings.Join(failed, "\n  "))
+		}
+		return nil
+	case "schedule":
+		var result struct {
+			Summary string `json:"summary"`
+			NextRun string `json:"next_run"`
+		}
+		body := map[string]string{"period": c.period, "at": c.at, "weekday": c.weekday}
+		if err := client.do(ctx, http.MethodPut, "/api/groups/schedule/"+path, body, &result); err != nil {
+			return err
+		}
+		if jsonOutput() {
+			return writeJSON(result)
+		}
+		fmt.Printf("Group %s will be updated %s (next run %s)\n", group, result.Summary, result.NextRun)
+		return nil
+	default: // unschedule
+		if err := client.do(ctx, http.MethodDelete, "/api/groups/schedule/"+path, nil, nil); err != nil {
+			return err
+		}
+		fmt.Printf("Removed the update schedule of group %s\n", group)
+		return nil
+	}
+}
+
+// list prints every group with its check summary. Schedules are only known to the server.
+func (c *GroupCommand) list(ctx context.Context) error {
+	var groups []groupListEntry
+	if isRemote() {
+		var result struct {
+			Groups []groupListEntry `json:"groups"`
+		}
+		if err := newRemoteClient().do(ctx, http.MethodGet, "/api/groups", nil, &result); err != nil {
+			return err
+		}
+		groups = result.Groups
+	} else {
+		result, err := NewCheckCommand().checkLocal(ctx)
+		if err != nil {
+			return err
+		}
+		for _, group := range update.BuildGroups(result.Containers) {
+			groups = append(groups, groupListEntry{Group: *group})
+		}
+		sort.Slice(groups, func(i, j int) bool { return groups[i].Name < groups[j].Name })
+	}
+
+	if jsonOutput() {
+		return writeJSON(map[string]any{"groups": groups, "count": len(groups)})
+	}
+
+	if len(groups) == 0 {
+		fmt.Println("No groups found (label containers with docksmith.group)")
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "GROUP\tCONTAINERS\tUPDATES\tUP TO DATE\tFAILED\tIGNORED\tSCHEDULE")
+	for _, g := range groups {
+		schedule := ""
+		if g.Schedule != nil {
+			schedule = g.Schedule.Period
+			if g.Schedule.Period == "weekly" && g.Schedule.Weekday != "" {
+				schedule += " " + g.Schedule.Weekday
+			}
+			if g.Schedule.At != "" {
+				schedule += " " + g.Schedule.At
+			}
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%d\t%d\t%s\n", g.Name, len(g.Containers), g.UpdatesAvailable, g.UpToDate, g.Failed, g.Ignored, valueOrDash(schedule))
+	}
+	return tw.Flush()
+}
+
+func printGroupUsage() {
+	fmt.Println(`Usage:
+  docksmith group list                          List groups and their update status
+  docksmith group ignore <group>                Ignore updates for every container in the group
+  docksmith group unignore <group>              Stop ignoring updates for the group
+  docksmith group schedule <group> [--period daily|weekly] [--at HH:MM] [--weekday day]
+                                                Update the group automatically on a schedule
+  docksmith group unschedule <group>            Remove the group's update schedule
+
+Groups are set with the docksmith.group label (comma-separated for several
+groups). Use 'docksmith check --group' and 'docksmith update --group' to check
+or update a whole group. All actions except list need --server.
+
+Examples:
+  docksmith group list
+  docksmith --server https://nas:3000 --api-key $KEY group ignore media
+  docksmith --server https://nas:3000 --api-key $KEY group schedule media --period weekly --weekday sun --at 03:00`)
+}
@@ -0,0 +1,250 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/chis/docksmith/internal/storage"
+)
+
+// HistoryCommand implements the `docksmith history` subcommand
+type HistoryCommand struct {
+	container string
+	stack     string
+	since     string
+	until     string
+	status    string
+	entryType string
+	limit     int
+	json      bool
+	export    string
+}
+
+// NewHistoryCommand creates a new history command
+func NewHistoryCommand() *HistoryCommand {
+	return &HistoryCommand{
+		limit: 50,
+	}
+}
+
+// flagSet returns the history flags
+func (c *HistoryCommand) flagSet(action string) *flag.FlagSet {
+	fs := flag.NewFlagSet("history", flag.ExitOnError)
+	fs.StringVar(&c.container, "container", "", "Only show this container")
+	fs.StringVar(&c.stack, "stack", "", "Only show containers of this stack")
+	fs.StringVar(&c.since, "since", "", "Start of the time range (2006-01-02, RFC3339, or a duration like 24h or 7d)")
+	fs.StringVar(&c.until, "until", "", "End of the time range (same formats as --since)")
+	fs.StringVar(&c.status, "status", "", "Filter by status (e.g. update_available, failed, complete)")
+	fs.StringVar(&c.entryType, "type", "", "Only show check or update entries")
+	fs.IntVar(&c.limit, "limit", c.limit, "Maximum number of entries to show (0 for no limit)")
+	fs.BoolVar(&c.json, "json", false, "Output JSON instead of a table (same as --output json)")
+	fs.StringVar(&c.export, "export", "", "Write the update audit log as csv or jsonl instead of the timeline")
+	fs.Usage = printHistoryUsage
+	return fs
+}
+
+// Run prints the check and update timeline read from the database.
+func (c *HistoryCommand) Run(ctx context.Context, args []string) error {
+	if err := c.flagSet("").Parse(args); err != nil {
+		return err
+	}
+
+	if c.entryType != "" && c.entryType != "check" && c.entryType != "update" {
+		return fmt.Errorf("invalid --type %q (must be check or update)", c.entryType)
+	}
+
+	now := time.Now()
+	from, err := parseTimeFlag(c.since, now)
+	if err != nil {
+		return fmt.Errorf("invalid --since: %w", err)
+	}
+	to, err := parseTimeFlag(c.until, now)
+	if err != nil {
+		return fmt.Errorf("invalid --until: %w", err)
+	}
+
+	if c.export != "" {
+		return c.exportAuditLog(ctx, from, to)
+	}
+
+	opts := storage.TimelineQueryOptions{
+		Container: c.container,
+		Stack:     c.stack,
+		Status:    c.status,
+		Type:      c.entryType,
+		DateFrom:  from,
+		DateTo:    to,
+		Limit:     c.limit,
+	}
+
+	var entries []storage.TimelineEntry
+	if isRemote() {
+		entries, err = remoteTimeline(ctx, newRemoteClient(), opts)
+	} else {
+		entries, err = localTimeline(ctx, opts)
+	}
+	if err != nil {
+		return err
+	}
+
+	if c.json || jsonOutput() {
+		return writeJSON(map[string]any{
+			"history": entries,
+			"count":   len(entries),
+		})
+	}
+	return printTimeline(entries)
+}
+
+// exportAuditLog writes the update operations between from and to, with who or
+// what triggered them, to stdout as CSV or JSON lines.
+func (c *HistoryCommand) exportAuditLog(ctx context.Context, from, to *time.Time) error {
+	if c.export != storage.AuditFormatCSV && c.export != storage.AuditFormatJSONL {
+		return fmt.Errorf("invalid --export %q (must be csv or jsonl)", c.export)
+	}
+
+	if isRemote() {
+		query := url.Values{"format": {c.export}}
+		if from != nil {
+			query.Set("date_from", from.Format(time.RFC3339))
+		}
+		if to != nil {
+			query.Set("date_to", to.Format(time.RFC3339))
+		}
+		data, err := newRemoteClient().fetch(ctx, "/api/history/export?"+query.Encode())
+		if err != nil {
+			return err
+		}
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+
+	store, err := InitializeStorage()
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	records, err := storage.QueryAuditLog(ctx, store, from, to)
+	if err != nil {
+		return err
+	}
+	return storage.WriteAuditLog(os.Stdout, c.export, records)
+}
+
+// localTimeline reads the timeline from the local database.
+func localTimeline(ctx context.Context, opts storage.TimelineQueryOptions) ([]storage.TimelineEntry, error) {
+	store, err := InitializeStorage()
+	if err != nil {
+		return nil, err
+	}
+	defer store.Close()
+
+	return storage.QueryTimeline(ctx, store, opts)
+}
+
+// remoteTimeline reads the timeline from the API of the server given by --server.
+func remoteTimeline(ctx context.Context, client *remoteClient, opts storage.TimelineQueryOptions) ([]storage.TimelineEntry, error) {
+	query := url.Values{"limit": {strconv.Itoa(opts.Limit)}}
+	for param, value := range map[string]string{"container": opts.Container, "stack": opts.Stack, "status": opts.Status, "type": opts.Type} {
+		if value != "" {
+			query.Set(param, value)
+		}
+	}
+	if opts.DateFrom != nil {
+		query.Set("date_from", opts.DateFrom.Format(time.RFC3339))
+	}
+	if opts.DateTo != nil {
+		query.Set("date_to", opts.DateTo.Format(time.RFC3339))
+	}
+
+	var result struct {
+		History []storage.TimelineEntry `json:"history"`
+	}
+	if err := client.do(ctx, http.MethodGet, "/api/history/timeline?"+query.Encode(), nil, &result); err != nil {
+		return nil, err
+	}
+	return result.History, nil
+}
+
+// printTimeline prints entries as a table.
+func printTimeline(entries []storage.TimelineEntry) error {
+	if len(entries) == 0 {
+		fmt.Println("No history found")
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "TIME\tTYPE\tCONTAINER\tSTACK\tVERSION\tSTATUS\tTRIGGERED BY\tDETAILS")
+	for _, e := range entries {
+		kind := e.Type
+		if e.Operation != "" {
+			kind += " (" + e.Operation + ")"
+		}
+		versions := e.FromVersion
+		if e.ToVersion != "" && e.ToVersion != e.FromVersion {
+			versions = fmt.Sprintf("%s -> %s", e.FromVersion, e.ToVersion)
+		}
+		details := e.Error
+		if details == "" {
+			details = e.OperationID
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			e.Timestamp.Local().Format("2006-01-02 15:04"), kind, e.ContainerName, e.Stack, versions, e.Status, e.TriggeredBy, details)
+	}
+	return tw.Flush()
+}
+
+// parseTimeFlag parses a date (2006-01-02), an RFC3339 timestamp, or a duration
+// before now ("24h", "7d"). An empty value returns nil.
+func parseTimeFlag(value string, now time.Time) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil {
+			t := now.AddDate(0, 0, -n)
+			return &t, nil
+		}
+	}
+	if d, err := time.ParseDuration(value); err == nil {
+		t := now.Add(-d)
+		return &t, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return &t, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		return &t, nil
+	}
+	return nil, fmt.Errorf("%q is not a date, timestamp, or duration", value)
+}
+
+func printHistoryUsage() {
+	fmt.Println(`Usage:
+  docksmith history [--container name] [--stack name] [--since time] [--until time]
+                    [--status status] [--type check|update] [--limit N] [--json]
+  docksmith history --export csv|jsonl [--since time] [--until time]
+
+Times are dates (2024-01-15), RFC3339 timestamps, or durations ago (24h, 7d).
+Update entries are finished operations (complete or failed).
+
+--export writes the update audit log for change-management reviews: one row
+per container changed by a finished operation, with who or what triggered it
+(user:NAME, api_key:NAME, schedule:GROUP, approval, auto-rollback, cli:USER).
+Only --since and --until apply to exports.
+
+Examples:
+  docksmith history --container plex --since 7d
+  docksmith history --stack media --type update --status failed
+  docksmith history --since 2024-01-01 --until 2024-02-01 --json
+  docksmith history --export csv --since 2024-01-01 --until 2024-04-01 > audit-q1.csv`)
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/chis/docksmith/internal/hooks"
+)
+
+// HookCommand implements the `docksmith hook` subcommands
+type HookCommand struct {
+	name      string
+	action    string
+	container string
+	image     string
+}
+
+// NewHookCommand creates a new hook command
+func NewHookCommand() *HookCommand {
+	return &HookCommand{
+		action: hooks.ActionCheck,
+	}
+}
+
+// Run dispatches to the create, delete, or list action
+func (c *HookCommand) Run(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		printHookUsage()
+		return fmt.Errorf("missing hook action")
+	}
+
+	action, rest := args[0], args[1:]
+
+	store, err := InitializeStorage()
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	hookStore := hooks.NewStore(store)
+
+	switch action {
+	case "create":
+		return c.create(ctx, hookStore, rest)
+	case "delete", "rm":
+		return c.delete(ctx, hookStore, rest)
+	case "list", "ls":
+		return c.list(ctx, hookStore)
+	default:
+		printHookUsage()
+		return fmt.Errorf("unknown hook action: %s", action)
+	}
+}
+
+// flagSet returns the flags of a hook action
+func (c *HookCommand) flagSet(action string) *flag.FlagSet {
+	fs := flag.NewFlagSet("hook "+action, flag.ExitOnError)
+	fs.Usage = printHookUsage
+	if action == "create" {
+		fs.StringVar(&c.name, "name", c.name, "Descriptive name for the hook")
+		fs.StringVar(&c.action, "action", c.action, "What the hook does: check or update")
+		fs.StringVar(&c.container, "container", c.container, "Only target this container")
+		fs.StringVar(&c.image, "image", c.image, "Only accept images matching this pattern")
+	}
+	return fs
+}
+
+func (c *HookCommand) create(ctx context.Context, hookStore *hooks.Store, args []string) error {
+	if err := c.flagSet("create").Parse(args); err != nil {
+		return err
+	}
+
+	token, hook, err := hookStore.Create(ctx, hooks.Hook{
+		Name:         c.name,
+		Action:       c.action,
+		Container:    c.container,
+		ImagePattern: c.image,
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Created hook %s (%s, action=%s)\n", hook.ID, hook.Name, hook.Action)
+	fmt.Println("")
+	fmt.Printf("  POST /api/hooks/%s\n", token)
+	fmt.Println("")
+	fmt.Println("Store this URL now - the token cannot be shown again.")
+	return nil
+}
+
+func (c *HookCommand) delete(ctx context.Context, hookStore *hooks.Store, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: docksmith hook delete <id>")
+	}
+
+	if err := hookStore.Delete(ctx, args[0]); err != nil {
+		if errors.Is(err, hooks.ErrHookNotFound) {
+			return fmt.Errorf("no hook with id %s", args[0])
+		}
+		return err
+	}
+
+	fmt.Printf("Deleted hook %s\n", args[0])
+	return nil
+}
+
+func (c *HookCommand) list(ctx context.Context, hookStore *hooks.Store) error {
+	list, err := hookStore.List(ctx)
+	if err != nil {
+		return err
+	}
+
+	if jsonOutput() {
+		return writeJSON(map[string]any{"hooks": list, "count": len(list)})
+	}
+
+	if len(list) == 0 {
+		fmt.Println("No hooks configured")
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tNAME\tACTION\tCONTAINER\tIMAGE\tLAST TRIGGERED")
+	for _, h := range list {
+		lastTriggered := "never"
+		if h.LastTriggeredAt != nil {
+			lastTriggered = h.LastTriggeredAt.Local().Format("2006-01-02 15:04")
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n", h.ID, h.Name, h.Action, h.Container, h.ImagePattern, lastTriggered)
+	}
+	return tw.Flush()
+}
+
+func printHookUsage() {
+	fmt.Println(`Usage:
+  docksmith hook create --name <name> [--action check|update] [--container name] [--image pattern]
+  docksmith hook delete <id>
+  docksmith hook list
+
+Hooks let CI pipelines and registry notifications trigger a check or an update
+with POST /api/hooks/<token>. The pushed image is read from ?image= or from the
+payload of Docker Hub, GitHub package, Harbor, and Diun webhooks, or a generic
+{"image": "..."} body. Containers running any tag of the image are targeted,
+or only --container. With --image, pushes of other images are ignored.
+
+Examples:
+  docksmith hook create --name ci --action update --image 'ghcr.io/org/*'
+  docksmith hook create --name dockerhub --container plex
+  curl -X POST "http://localhost:3000/api/hooks/$TOKEN?image=ghcr.io/org/app:1.2"`)
+}
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/chis/docksmith/internal/storage"
+	"github.com/chis/docksmith/internal/update"
+)
+
+// IgnoreCommand implements the `docksmith ignore` subcommands
+type IgnoreCommand struct {
+	reason string
+}
+
+// NewIgnoreCommand creates a new ignore command
+func NewIgnoreCommand() *IgnoreCommand {
+	return &IgnoreCommand{}
+}
+
+// ignoreRuleEntry is an ignore rule with the containers it matches, as returned by the API
+type ignoreRuleEntry struct {
+	storage.IgnoreRule
+	Containers []string `json:"containers,omitempty"`
+}
+
+// flagSet returns the flags of an ignore action
+func (c *IgnoreCommand) flagSet(action string) *flag.FlagSet {
+	fs := flag.NewFlagSet("ignore "+action, flag.ExitOnError)
+	fs.Usage = printIgnoreUsage
+	if action == "add" {
+		fs.StringVar(&c.reason, "reason", "", "Why images matching the pattern are ignored")
+	}
+	return fs
+}
+
+// Run dispatches to the list, add, or remove action
+func (c *IgnoreCommand) Run(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		printIgnoreUsage()
+		return fmt.Errorf("missing ignore action")
+	}
+
+	action, rest := args[0], args[1:]
+	switch action {
+	case "list", "ls":
+		return c.list(ctx)
+	case "add":
+		if len(rest) == 0 {
+			return fmt.Errorf("usage: docksmith ignore add <pattern> [--reason text]")
+		}
+		if err := c.flagSet(action).Parse(rest[1:]); err != nil {
+			return err
+		}
+		return c.add(ctx, rest[0])
+	case "remove", "rm":
+		if len(rest) != 1 {
+			return fmt.Errorf("usage: docksmith ignore remove <id>")
+		}
+		id, err := strconv.ParseInt(rest[0], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid ignore rule id %q", rest[0])
+		}
+		return c.remove(ctx, id)
+	default:
+		printIgnoreUsage()
+		return fmt.Errorf("unknown ignore action: %s", action)
+	}
+}
+
+// list prints the ignore rules, with the containers they match when talking to a server
+func (c *IgnoreCommand) list(ctx context.Context) error {
+	var rules []ignoreRuleEntry
+	if isRemote() {
+		var result struct {
+			Rules []ignoreRuleEntry `json:"rules"`
+		}
+		if err := newRemoteClient().do(ctx, http.MethodGet, "/api/ignore-rules", nil, &result); err != nil {
+			return err
+		}
+		rules = result.Rules
+	} else {
+		store, err := InitializeStorage()
+		if err != nil {
+			return err
+		}
+		defer store.Close()
+
+		list, err := store.ListIgnoreRules(ctx)
+		if err != nil {
+			return err
+		}
+		for _, rule := range list {
+			rules = append(rules, ignoreRuleEntry{IgnoreRule: rule})
+		}
+	}
+
+	if jsonOutput() {
+		return writeJSON(map[string]any{"rules": rules, "count": len(rules)})
+	}
+
+	if len(rules) == 0 {
+		fmt.Println("No ignore rules")
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	if isRemote() {
+		fmt.Fprintln(tw, "ID\tPATTERN\tREASON\tCONTAINERS")
+	} else {
+		fmt.Fprintln(tw, "ID\tPATTERN\tREASON")
+	}
+	for _, rule := range rules {
+		if isRemote() {
+			fmt.Fprintf(tw, "%d\t%s\t%s\t%s\n", rule.ID, rule.Pattern, rule.Reason, strings.Join(rule.Containers, ", "))
+		} else {
+			fmt.Fprintf(tw, "%d\t%s\t%s\n", rule.ID, rule.Pattern, rule.Reason)
+		}
+	}
+	return tw.Flush()
+}
+
+// add saves an ignore rule, updating the reason when the pattern already exists
+func (c *IgnoreCommand) add(ctx context.Context, pattern string) error {
+	if err := update.ValidateIgnorePattern(pattern); err != nil {
+		return err
+	}
+
+	var rule storage.IgnoreRule
+	if isRemote() {
+		body := map[string]string{"pattern": pattern, "reason": c.reason}
+		if err := newRemoteClient().do(ctx, http.MethodPost, "/api/ignore-rules", body, &rule); err != nil {
+			return err
+		}
+	} else {
+		store, err := InitializeStorage()
+		if err != nil {
+			return err
+		}
+		defer store.Close()
+
+		if rule, err = store.SaveIgnoreRule(ctx, storage.IgnoreRule{Pattern: pattern, Reason: c.reason}); err != nil {
+			return err
+		}
+	}
+
+	if jsonOutput() {
+		return writeJSON(rule)
+	}
+	fmt.Printf("Ignoring images matching %s (rule %d)\n", rule.Pattern, rule.ID)
+	return nil
+}
+
+// remove deletes an ignore rule
+func (c *IgnoreCommand) remove(ctx context.Context, id int64) error {
+	if isRemote() {
+		if err := newRemoteClient().do(ctx, http.MethodDelete, fmt.Sprintf("/api/ignore-rules/%d", id), nil, nil); err != nil {
+			return err
+		}
+	} else {
+		store, err := InitializeStorage()
+		if err != nil {
+			return err
+		}
+		defer store.Close()
+
+		deleted, err := store.DeleteIgnoreRule(ctx, id)
+		if err != nil {
+			return err
+		}
+		if !deleted {
+			return fmt.Errorf("ignore rule %d not found", id)
+		}
+	}
+
+	fmt.Printf("Removed ignore rule %d\n", id)
+	return nil
+}
+
+func printIgnoreUsage() {
+	fmt.Println(`Usage:
+  docksmith ignore list                              List image ignore rules
+  docksmith ignore add <pattern> [--reason text]     Ignore images matching a pattern
+  docksmith ignore remove <id>                       Remove an ignore rule
+
+Patterns match image references: * matches any characters and ? a single
+character. A pattern without a tag matches every tag of the image, and
+Docker Hub images match with or without the docker.io/library/ prefix.
+Matching containers are skipped by checks and shown as ignored, like the
+docksmith.ignore label. Rules added locally apply on the server's next check.
+
+Examples:
+  docksmith ignore add 'ghcr.io/home-assistant/*' --reason "updated by HA"
+  docksmith ignore add '*:nightly'
+  docksmith ignore remove 3`)
+}
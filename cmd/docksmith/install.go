@@ -0,0 +1,302 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// InstallCommand implements `docksmith install`, which sets up docksmith as a
+// native binary managed by systemd instead of a container.
+type InstallCommand struct {
+	binary        string
+	dataDir       string
+	configFile    string
+	unitDir       string
+	port          int
+	staticDir     string
+	checkInterval time.Duration
+	force         bool
+	dryRun        bool
+	enable        bool
+}
+
+// NewInstallCommand creates a new install command
+func NewInstallCommand() *InstallCommand {
+	return &InstallCommand{
+		dataDir:       "/var/lib/docksmith",
+		configFile:    "/etc/docksmith/docksmith.env",
+		unitDir:       "/etc/systemd/system",
+		port:          3000,
+		checkInterval: time.Hour,
+	}
+}
+
+// flagSet returns the install flags
+func (c *InstallCommand) flagSet(action string) *flag.FlagSet {
+	fs := flag.NewFlagSet("install", flag.ExitOnError)
+	fs.StringVar(&c.binary, "binary", "", "Path of the docksmith binary the units run (default: this binary)")
+	fs.StringVar(&c.dataDir, "data-dir", c.dataDir, "Directory for the database and other state")
+	fs.StringVar(&c.configFile, "config", c.configFile, "Environment file the units load")
+	fs.StringVar(&c.unitDir, "unit-dir", c.unitDir, "Directory to write the systemd units to")
+	fs.IntVar(&c.port, "port", c.port, "Port the server listens on")
+	fs.StringVar(&c.staticDir, "static-dir", "", "Directory containing the web UI files (default: the UI built into the binary)")
+	fs.DurationVar(&c.checkInterval, "check-interval", c.checkInterval, "How often the check timer runs")
+	fs.BoolVar(&c.force, "force", false, "Overwrite an existing environment file")
+	fs.BoolVar(&c.dryRun, "dry-run", false, "Print the files instead of writing them")
+	fs.BoolVar(&c.enable, "enable", false, "Reload systemd and start the server after installing")
+	fs.Usage = printInstallUsage
+	return fs
+}
+
+// installFile is a file written by the install command
+type installFile struct {
+	path    string
+	content string
+	mode    os.FileMode
+	// keep leaves an existing file alone, unless --force is given
+	keep bool
+}
+
+// Run writes the environment file and systemd units and creates the data directory
+func (c *InstallCommand) Run(ctx context.Context, args []string) error {
+	fs := c.flagSet("")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		printInstallUsage()
+		return fmt.Errorf("unexpected arguments: %s", strings.Join(fs.Args(), " "))
+	}
+	if c.checkInterval < time.Minute {
+		return fmt.Errorf("--check-interval must be at least 1m")
+	}
+
+	if c.binary == "" {
+		binary, err := os.Executable()
+		if err != nil {
+			return fmt.Errorf("failed to find the docksmith binary (use --binary): %w", err)
+		}
+		if resolved, err := filepath.EvalSymlinks(binary); err == nil {
+			binary = resolved
+		}
+		c.binary = binary
+	}
+	for _, path := range []*string{&c.binary, &c.dataDir, &c.configFile, &c.unitDir} {
+		abs, err := filepath.Abs(*path)
+		if err != nil {
+			return fmt.Errorf("failed to resolve %s: %w", *path, err)
+		}
+		*path = abs
+	}
+
+	files := c.files()
+	if c.dryRun {
+		for _, file := range files {
+			fmt.Printf("# %s\n%s\n", file.path, file.content)
+		}
+		return nil
+	}
+
+	if err := os.MkdirAll(c.dataDir, 0750); err != nil {
+		return fmt.Errorf("failed to create data directory: %w", err)
+	}
+	fmt.Printf("Created %s\n", c.dataDir)
+	for _, file := range files {
+		if file.keep && !c.force {
+			if _, err := os.Stat(file.path); err == nil {
+				fmt.Printf("Kept existing %s (use --force to overwrite)\n", file.path)
+				continue
+			}
+		}
+		if err := os.MkdirAll(filepath.Dir(file.path), 0755); err != nil {
+			return fmt.Errorf("failed to create %s: %w", filepath.Dir(file.path), err)
+		}
+		if err := os.WriteFile(file.path, []byte(file.content), file.mode); err != nil {
+			return fmt.Errorf("failed to write %s: %w", file.path, err)
+		}
+		fmt.Printf("Wrote %s\n", file.path)
+	}
+
+	if !c.enable {
+		fmt.Println()
+		fmt.Println("Start docksmith with:")
+		fmt.Println("  systemctl daemon-reload")
+		fmt.Println("  systemctl enable --now docksmith.service")
+		fmt.Println()
+		fmt.Println("Or, to only check for updates on a schedule without the server:")
+		fmt.Println("  systemctl enable --now docksmith-check.timer")
+		return nil
+	}
+	for _, args := range [][]string{{"daemon-reload"}, {"enable", "--now", "docksmith.service"}} {
+		cmd := exec.CommandContext(ctx, "systemctl", args...)
+		cmd.Stdout, cmd.Stderr = os.Stdout, os.Stderr
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("failed to run systemctl %s: %w", strings.Join(args, " "), err)
+		}
+	}
+	fmt.Printf("docksmith is running on port %d\n", c.port)
+	return nil
+}
+
+// files returns the environment file and systemd units to install
+func (c *InstallCommand) files() []installFile {
+	return []installFile{
+		{path: c.configFile, content: c.envFile(), mode: 0640, keep: true},
+		{path: filepath.Join(c.unitDir, "docksmith.service"), content: c.serviceUnit(), mode: 0644},
+		{path: filepath.Join(c.unitDir, "docksmith-check.service"), content: c.checkUnit(), mode: 0644},
+		{path: filepath.Join(c.unitDir, "docksmith-check.timer"), content: c.checkTimer(), mode: 0644},
+	}
+}
+
+// envFile returns the default environment file. Only the database location is
+// set; the other settings are listed with their defaults for reference.
+func (c *InstallCommand) envFile() string {
+	return fmt.Sprintf(`# docksmith configuration, loaded by the docksmith systemd units.
+# See the Configuration section of the README for all settings.
+
+DB_PATH=%s
+
+# CHECK_INTERVAL=5m
+# CACHE_TTL=1h
+# LOG_LEVEL=info
+# GITHUB_TOKEN=
+# DOCKSMITH_AUTH=optional
+# NOTIFY_WEBHOOK_URL=
+`, filepath.Join(c.dataDir, "docksmith.db"))
+}
+
+// serviceUnit returns the unit running the server
+func (c *InstallCommand) serviceUnit() string {
+	return fmt.Sprintf(`[Unit]
+Description=docksmith container update server
+Documentation=https://github.com/chrisae9/docksmith
+After=network-online.target docker.service
+Wants=network-online.target
+Requires=docker.service
+
+[Service]
+Type=simple
+EnvironmentFile=-%s
+ExecStart=%s serve --port %d%s
+WorkingDirectory=%s
+SupplementaryGroups=docker
+Restart=on-failure
+RestartSec=5s
+
+[Install]
+WantedBy=multi-user.target
+`, c.configFile, systemdQuote(c.binary), c.port, c.staticDirArg(), c.dataDir)
+}
+
+// staticDirArg returns the --static-dir argument of the server. Without one the
+// server uses the UI built into the binary.
+func (c *InstallCommand) staticDirArg() string {
+	if c.staticDir == "" {
+		return ""
+	}
+	return " --static-dir " + systemdQuote(c.staticDir)
+}
+
+// checkUnit returns the unit running a single check, started by the check timer
+func (c *InstallCommand) checkUnit() string {
+	return fmt.Sprintf(`[Unit]
+Description=docksmith update check
+Documentation=https://github.com/chrisae9/docksmith
+After=network-online.target docker.service
+Wants=network-online.target
+Requires=docker.service
+
+[Service]
+Type=oneshot
+EnvironmentFile=-%s
+ExecStart=%s check --updates
+WorkingDirectory=%s
+SupplementaryGroups=docker
+`, c.configFile, systemdQuote(c.binary), c.dataDir)
+}
+
+// checkTimer returns the timer starting the check unit every check interval
+func (c *InstallCommand) checkTimer() string {
+	return fmt.Sprintf(`[Unit]
+Description=Run docksmith update checks every %s
+
+[Timer]
+OnBootSec=5min
+OnUnitActiveSec=%s
+RandomizedDelaySec=%s
+Persistent=true
+
+[Install]
+WantedBy=timers.target
+`, c.checkInterval, systemdDuration(c.checkInterval), systemdDuration(c.checkInterval/10))
+}
+
+// systemdQuote quotes an ExecStart argument when it contains spaces or quotes
+func systemdQuote(arg string) string {
+	if arg == "" {
+		return `""`
+	}
+	if !strings.ContainsAny(arg, " \t\"'\\") {
+		return arg
+	}
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(arg) + `"`
+}
+
+// systemdDuration formats a duration as a systemd time span, e.g. 1h30min
+func systemdDuration(d time.Duration) string {
+	seconds := int64(d.Round(time.Second) / time.Second)
+	if seconds <= 0 {
+		return "0"
+	}
+	var parts []string
+	for _, unit := range []struct {
+		name    string
+		seconds int64
+	}{{"d", 86400}, {"h", 3600}, {"min", 60}, {"s", 1}} {
+		if n := seconds / unit.seconds; n > 0 {
+			parts = append(parts, fmt.Sprintf("%d%s", n, unit.name))
+			seconds %= unit.seconds
+		}
+	}
+	return strings.Join(parts, "")
+}
+
+func printInstallUsage() {
+	fmt.Println(`Usage:
+  docksmith install [flags]
+
+Sets up docksmith to run as a native binary instead of a container: creates the
+data directory, writes a default environment file (kept if it already exists),
+and writes systemd units for the server and a timer that checks for updates.
+Run it as root. The units run docksmith with the docker group so it can reach
+the Docker socket.
+
+Files:
+  <config>                              Environment file (DB_PATH and other settings)
+  <unit-dir>/docksmith.service          The API server and web UI
+  <unit-dir>/docksmith-check.service    A single update check
+  <unit-dir>/docksmith-check.timer      Runs the check every --check-interval, for
+                                        hosts that check without running the server
+
+Flags:
+  --binary path           Binary the units run (default: this binary)
+  --data-dir dir          Database and state directory (default: /var/lib/docksmith)
+  --config file           Environment file (default: /etc/docksmith/docksmith.env)
+  --unit-dir dir          systemd unit directory (default: /etc/systemd/system)
+  --port n                Server port (default: 3000)
+  --static-dir dir        Web UI files (default: the UI built into the binary)
+  --check-interval d      Check timer interval (default: 1h)
+  --force                 Overwrite an existing environment file
+  --dry-run               Print the files instead of writing them
+  --enable                Run systemctl daemon-reload and enable --now docksmith.service
+
+Examples:
+  sudo docksmith install --dry-run
+  sudo docksmith install --port 8080 --enable`)
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInstallCommand_Run(t *testing.T) {
+	dir := t.TempDir()
+	configFile := filepath.Join(dir, "etc", "docksmith.env")
+	unitDir := filepath.Join(dir, "units")
+
+	cmd := NewInstallCommand()
+	require.NoError(t, cmd.Run(context.Background(), []string{
+		"--binary", "/opt/docksmith/docksmith",
+		"--data-dir", filepath.Join(dir, "data"),
+		"--config", configFile,
+		"--unit-dir", unitDir,
+		"--port", "8080",
+		"--check-interval", "90m",
+	}))
+
+	assert.DirExists(t, filepath.Join(dir, "data"))
+	env, err := os.ReadFile(configFile)
+	require.NoError(t, err)
+	assert.Contains(t, string(env), "DB_PATH="+filepath.Join(dir, "data", "docksmith.db")+"\n")
+
+	service, err := os.ReadFile(filepath.Join(unitDir, "docksmith.service"))
+	require.NoError(t, err)
+	assert.Contains(t, string(service), "EnvironmentFile=-"+configFile+"\n")
+	assert.Contains(t, string(service), "ExecStart=/opt/docksmith/docksmith serve --port 8080\n")
+
+	check, err := os.ReadFile(filepath.Join(unitDir, "docksmith-check.service"))
+	require.NoError(t, err)
+	assert.Contains(t, string(check), "ExecStart=/opt/docksmith/docksmith check --updates\n")
+
+	timer, err := os.ReadFile(filepath.Join(unitDir, "docksmith-check.timer"))
+	require.NoError(t, err)
+	assert.Contains(t, string(timer), "OnUnitActiveSec=1h30min\n")
+	assert.Contains(t, string(timer), "RandomizedDelaySec=9min\n")
+
+	// An edited environment file is kept unless --force is given
+	require.NoError(t, os.WriteFile(configFile, []byte("DB_PATH=/custom.db\n"), 0640))
+	require.NoError(t, NewInstallCommand().Run(context.Background(), []string{"--binary", "/opt/docksmith/docksmith", "--data-dir", filepath.Join(dir, "data"), "--config", configFile, "--unit-dir", unitDir}))
+	env, err = os.ReadFile(configFile)
+	require.NoError(t, err)
+	assert.Equal(t, "DB_PATH=/custom.db\n", string(env))
+}
+
+func TestSystemdDuration(t *testing.T) {
+	assert.Equal(t, "1h", systemdDuration(time.Hour))
+	assert.Equal(t, "1d2h5min", systemdDuration(26*time.Hour+5*time.Minute))
+	assert.Equal(t, "6min", systemdDuration(6*time.Minute))
+	assert.Equal(t, "0", systemdDuration(0))
+}
+
+func TestSystemdQuote(t *testing.T) {
+	assert.Equal(t, "/usr/bin/docksmith", systemdQuote("/usr/bin/docksmith"))
+	assert.Equal(t, `"/opt/my apps/docksmith"`, systemdQuote("/opt/my apps/docksmith"))
+	assert.Equal(t, `""`, systemdQuote(""))
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/chis/docksmith/internal/events"
+	"github.com/chis/docksmith/internal/storage"
+)
+
+// operationPollInterval is how often the stored status of followed operations is
+// checked, in case a terminal progress event is missed
+const operationPollInterval = 2 * time.Second
+
+// operationProgress is one update.progress event
+type operationProgress struct {
+	OperationID   string
+	ContainerName string
+	Stage         string
+	Message       string
+	Percent       int
+}
+
+// progressFromPayload reads an update.progress payload. The percentage is an int
+// when read from the local bus and a float64 when decoded from the API's JSON.
+func progressFromPayload(payload map[string]any) operationProgress {
+	p := operationProgress{}
+	p.OperationID, _ = payload["operation_id"].(string)
+	p.ContainerName, _ = payload["container_name"].(string)
+	p.Stage, _ = payload["stage"].(string)
+	p.Message, _ = payload["message"].(string)
+	switch v := payload["progress"].(type) {
+	case int:
+		p.Percent = v
+	case float64:
+		p.Percent = int(v)
+	}
+	return p
+}
+
+// operationTracker prints the progress of a set of operations and records how they finish
+type operationTracker struct {
+	what    string          // "Update" or "Rollback"
+	pending map[string]bool // operation ID -> whether it is a batch operation
+	failed  []string
+}
+
+// newOperationTracker tracks the given operations, keyed by ID with whether each is a batch
+func newOperationTracker(what string, operations map[string]bool) *operationTracker {
+	t := &operationTracker{what: what, pending: make(map[string]bool, len(operations))}
+	for id, batch := range operations {
+		t.pending[id] = batch
+	}
+	return t
+}
+
+// progress prints an event of a followed operation and finishes it on a terminal stage
+func (t *operationTracker) progress(p operationProgress) {
+	batch, ok := t.pending[p.OperationID]
+	if !ok {
+		return
+	}
+	subject := p.Stage
+	if p.ContainerName != "" {
+		subject = p.ContainerName + " " + p.Stage
+	}
+	fmt.Printf("[%3d%%] %s: %s\n", p.Percent, subject, p.Message)
+
+	// A batch reports each container before finishing with an operation-level event
+	if !batch || p.ContainerName == "" {
+		t.finish(p.OperationID, p.Stage, p.Message)
+	}
+}
+
+// finish records an operation's status if it is complete or failed
+func (t *operationTracker) finish(operationID, status, message string) {
+	if _, ok := t.pending[operationID]; !ok || (status != "complete" && status != "failed") {
+		return
+	}
+	delete(t.pending, operationID)
+	if status == "failed" {
+		t.failed = append(t.failed, message)
+	}
+}
+
+// done reports whether every operation has finished
+func (t *operationTracker) done() bool {
+	return len(t.pending) == 0
+}
+
+// result prints the outcome and returns an error if any operation failed
+func (t *operationTracker) result() error {
+	if len(t.failed) > 0 {
+		return fmt.Errorf("%s failed: %s", strings.ToLower(t.what), strings.Join(t.failed, "; "))
+	}
+	fmt.Printf("%s completed\n", t.what)
+	return nil
+}
+
+// timeoutError reports the operations still running when the wait timed out
+func (t *operationTracker) timeoutError() error {
+	ids := make([]string, 0, len(t.pending))
+	for id := range t.pending {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return errors.New("timed out waiting for operation " + strings.Join(ids, ", ") + "; check `docksmith history` for the result")
+}
+
+// followLocalOperations prints the progress of operations running in this process
+// until they complete or fail, or timeout passes.
+func followLocalOperations(ctx context.Context, progress events.Subscriber, store storage.Storage, what string, operations map[string]bool, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	tracker := newOperationTracker(what, operations)
+	ticker := time.NewTicker(operationPollInterval)
+	defer ticker.Stop()
+
+	for !tracker.done() {
+		select {
+		case event := <-progress:
+			tracker.progress(progressFromPayload(event.Payload))
+		case <-ticker.C:
+			for id := range tracker.pending {
+				if op, found, err := store.GetUpdateOperation(ctx, id); err == nil && found {
+					tracker.finish(id, op.Status, op.ErrorMessage)
+				}
+			}
+		case <-ctx.Done():
+			return tracker.timeoutError()
+		}
+	}
+	return tracker.result()
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/chis/docksmith/client"
+	"github.com/chis/docksmith/internal/events"
+)
+
+// remoteClient talks to a docksmith server's HTTP API for --server
+type remoteClient struct {
+	api *client.Client
+}
+
+// newRemoteClient creates a client for the server given by --server and --api-key
+func newRemoteClient() *remoteClient {
+	baseURL := globals.host
+	if !strings.Contains(baseURL, "://") {
+		baseURL = "http://" + baseURL
+	}
+	return &remoteClient{api: client.New(baseURL, client.WithAPIKey(globals.token))}
+}
+
+// isRemote reports whether commands should use the API of a remote server
+func isRemote() bool {
+	return globals.host != ""
+}
+
+// do sends a JSON request and decodes the data of the API's response envelope into out
+func (c *remoteClient) do(ctx context.Context, method, path string, body, out any) error {
+	return remoteError(c.api.Do(ctx, method, path, nil, body, out))
+}
+
+// send sends a request body of any type and decodes the data of the API's response envelope into out
+func (c *remoteClient) send(ctx context.Context, method, path, contentType string, body []byte, out any) error {
+	return remoteError(c.api.Send(ctx, method, path, nil, contentType, body, out))
+}
+
+// fetch returns the raw body of a successful GET request, for endpoints that
+// return files instead of the JSON envelope
+func (c *remoteClient) fetch(ctx context.Context, path string) ([]byte, error) {
+	data, err := c.api.Fetch(ctx, http.MethodGet, path, nil)
+	return data, remoteError(err)
+}
+
+// remoteError hints at --api-key when the server rejects a request as unauthenticated
+func remoteError(err error) error {
+	var apiErr *client.APIError
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusUnauthorized {
+		return fmt.Errorf("%s (pass an API key with --api-key)", apiErr.Message)
+	}
+	return err
+}
+
+// streamEvents sends the server's events to ch until ctx is cancelled or the stream ends
+func (c *remoteClient) streamEvents(ctx context.Context, ch chan<- events.Event) {
+	req, err := c.api.NewRequest(ctx, http.MethodGet, "/api/events", nil, "", nil)
+	if err != nil {
+		return
+	}
+
+	// No client timeout: the stream stays open for the whole operation
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		data, ok := strings.CutPrefix(scanner.Text(), "data: ")
+		if !ok {
+			continue
+		}
+		var event events.Event
+		if err := json.Unmarshal([]byte(data), &event); err != nil || event.Type == "" {
+			continue
+		}
+		select {
+		case ch <- event:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// followOperations prints the progress of operations running on the server
+// until they complete or fail, or timeout passes. operations maps each
+// operation ID to whether it is a batch operation.
+func (c *remoteClient) followOperations(ctx context.Context, what string, operations map[string]bool, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	stream := make(chan events.Event, 100)
+	go c.streamEvents(ctx, stream)
+
+	tracker := newOperationTracker(what, operations)
+	ticker := time.NewTicker(operationPollInterval)
+	defer ticker.Stop()
+
+	for !tracker.done() {
+		select {
+		case event := <-stream:
+			if event.Type == events.EventUpdateProgress {
+				tracker.progress(progressFromPayload(event.Payload))
+			}
+		case <-ticker.C:
+			for id := range tracker.pending {
+				if op, err := c.api.GetOperation(ctx, id); err == nil {
+					tracker.finish(id, op.Status, op.ErrorMessage)
+				}
+			}
+		case <-ctx.Done():
+			return tracker.timeoutError()
+		}
+	}
+	return tracker.result()
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testServer(t *testing.T, handler http.HandlerFunc) *remoteClient {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	resetGlobals(t)
+	globals.host = server.URL
+	globals.token = "dsk_test"
+	return newRemoteClient()
+}
+
+func TestRemoteClient_Do(t *testing.T) {
+	client := testServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer dsk_test" {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"success":false,"error":"authentication required"}`)
+			return
+		}
+		switch r.URL.Path {
+		case "/api/operations/op-1":
+			fmt.Fprint(w, `{"success":true,"data":{"operation_id":"op-1","status":"complete"}}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"success":false,"error":"operation not found"}`)
+		}
+	})
+
+	var op struct {
+		Status string `json:"status"`
+	}
+	require.NoError(t, client.do(context.Background(), http.MethodGet, "/api/operations/op-1", nil, &op))
+	assert.Equal(t, "complete", op.Status)
+
+	err := client.do(context.Background(), http.MethodGet, "/api/operations/missing", nil, &op)
+	assert.EqualError(t, err, "operation not found")
+
+	globals.token = ""
+	err = newRemoteClient().do(context.Background(), http.MethodGet, "/api/operations/op-1", nil, &op)
+	assert.ErrorContains(t, err, "--api-key")
+}
+
+func TestRemoteClient_FollowOperations(t *testing.T) {
+	client := testServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/events":
+			w.Header().Set("Content-Type", "text/event-stream")
+			for _, payload := range []map[string]any{
+				{"operation_id": "op-1", "container_name": "web", "stage": "complete", "progress": 100},
+				{"operation_id": "op-2", "container_name": "db", "stage": "failed", "progress": 60, "message": "pull failed"},
+			} {
+				data, _ := json.Marshal(map[string]any{"type": "update.progress", "payload": payload})
+				fmt.Fprintf(w, "data: %s\n\n", data)
+			}
+			w.(http.Flusher).Flush()
+			<-r.Context().Done()
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"success":false,"error":"not found"}`)
+		}
+	})
+
+	err := client.followOperations(context.Background(), "Update", map[string]bool{"op-1": false, "op-2": false}, 10*time.Second)
+	assert.EqualError(t, err, "update failed: pull failed")
+}
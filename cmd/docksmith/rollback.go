@@ -0,0 +1,294 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/chis/docksmith/client"
+	"github.com/chis/docksmith/internal/api"
+	"github.com/chis/docksmith/internal/docker"
+	"github.com/chis/docksmith/internal/events"
+	"github.com/chis/docksmith/internal/storage"
+	"github.com/chis/docksmith/internal/update"
+)
+
+// RollbackCommand implements the `docksmith rollback` subcommand
+type RollbackCommand struct {
+	operationID string
+	to          string
+	force       bool
+	limit       int
+	timeout     time.Duration
+}
+
+// NewRollbackCommand creates a new rollback command
+func NewRollbackCommand() *RollbackCommand {
+	return &RollbackCommand{
+		limit:   10,
+		timeout: 10 * time.Minute,
+	}
+}
+
+// flagSet returns the rollback flags
+func (c *RollbackCommand) flagSet(action string) *flag.FlagSet {
+	fs := flag.NewFlagSet("rollback", flag.ExitOnError)
+	fs.StringVar(&c.operationID, "operation", "", "ID of the update operation to roll back")
+	fs.StringVar(&c.to, "to", "", "Roll back the container's most recent update from this version")
+	fs.BoolVar(&c.force, "force", false, "Skip pre-update checks of dependent containers")
+	fs.IntVar(&c.limit, "limit", c.limit, "Maximum number of operations to list")
+	fs.DurationVar(&c.timeout, "timeout", c.timeout, "How long to wait for the rollback to finish")
+	fs.Usage = printRollbackUsage
+	return fs
+}
+
+// Run lists a container's rollback candidates or rolls back an operation.
+// With only a container name it prints recent completed updates; with --operation
+// or --to it starts the rollback and follows its progress until it finishes.
+func (c *RollbackCommand) Run(ctx context.Context, args []string) error {
+	fs := c.flagSet("")
+
+	// Allow the container name before the flags
+	var containerName string
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		containerName, args = args[0], args[1:]
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if containerName == "" && fs.NArg() > 0 {
+		containerName = fs.Arg(0)
+	}
+
+	if containerName == "" && c.operationID == "" {
+		printRollbackUsage()
+		return fmt.Errorf("missing container name or --operation")
+	}
+	if c.to != "" && containerName == "" {
+		return fmt.Errorf("--to requires a container name")
+	}
+
+	if isRemote() {
+		return c.runRemote(ctx, newRemoteClient(), containerName)
+	}
+
+	store, err := InitializeStorage()
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	operations := func(limit int) ([]storage.UpdateOperation, error) {
+		ops, err := store.GetUpdateOperationsByContainer(ctx, containerName, limit)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get operations: %w", err)
+		}
+		return ops, nil
+	}
+
+	operationID := c.operationID
+	switch {
+	case operationID != "":
+	case c.to != "":
+		op, err := c.findByVersion(operations, containerName)
+		if err != nil {
+			return err
+		}
+		operationID = op.OperationID
+	default:
+		return c.list(operations, containerName)
+	}
+
+	return c.rollback(ctx, store, operationID)
+}
+
+// runRemote lists or rolls back operations through the API of the server given by --server.
+func (c *RollbackCommand) runRemote(ctx context.Context, remote *remoteClient, containerName string) error {
+	operations := func(limit int) ([]storage.UpdateOperation, error) {
+		query := url.Values{"container": {containerName}, "status": {"complete"}, "limit": {strconv.Itoa(limit)}}
+		var result api.OperationsResponse
+		if err := remote.do(ctx, http.MethodGet, "/api/operations?"+query.Encode(), nil, &result); err != nil {
+			return nil, err
+		}
+		return result.Operations, nil
+	}
+
+	operationID := c.operationID
+	switch {
+	case operationID != "":
+	case c.to != "":
+		op, err := c.findByVersion(operations, containerName)
+		if err != nil {
+			return err
+		}
+		operationID = op.OperationID
+	default:
+		return c.list(operations, containerName)
+	}
+
+	// Batch operations are rolled back as a batch, which finishes with an operation-level event
+	original, err := remote.api.GetOperation(ctx, operationID)
+	if err != nil {
+		return remoteError(err)
+	}
+
+	started, err := remote.api.Rollback(ctx, client.RollbackRequest{OperationID: operationID, Force: c.force})
+	if err != nil {
+		return remoteError(err)
+	}
+	fmt.Printf("Rollback started (operation %s)\n", started.OperationID)
+
+	return remote.followOperations(ctx, "Rollback", map[string]bool{started.OperationID: len(original.BatchDetails) > 0}, c.timeout)
+}
+
+// candidates returns the completed updates that can still be rolled back, newest first.
+func candidates(ops []storage.UpdateOperation, containerName string) []storage.UpdateOperation {
+	result := []storage.UpdateOperation{}
+	for _, op := range ops {
+		if op.Status != "complete" || op.RollbackOccurred {
+			continue
+		}
+		switch op.OperationType {
+		case "single", "batch", "stack":
+		default:
+			continue
+		}
+		if from, _ := rollbackVersions(op, containerName); from == "" {
+			continue
+		}
+		result = append(result, op)
+	}
+	return result
+}
+
+// list prints the rollback candidates for a container.
+func (c *RollbackCommand) list(operations func(limit int) ([]storage.UpdateOperation, error), containerName string) error {
+	// Fetch extra operations since restarts, label changes and failures are filtered out
+	all, err := operations(c.limit * 5)
+	if err != nil {
+		return err
+	}
+	ops := candidates(all, containerName)
+	if len(ops) > c.limit {
+		ops = ops[:c.limit]
+	}
+
+	if jsonOutput() {
+		return writeJSON(map[string]any{
+			"container_name": containerName,
+			"operations":     ops,
+			"count":          len(ops),
+		})
+	}
+
+	if len(ops) == 0 {
+		fmt.Printf("No completed updates to roll back for %s\n", containerName)
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "OPERATION\tTYPE\tUPDATED\tFROM\tTO")
+	for _, op := range ops {
+		from, to := rollbackVersions(op, containerName)
+		updated := op.CreatedAt
+		if op.CompletedAt != nil {
+			updated = *op.CompletedAt
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", op.OperationID, op.OperationType, updated.Local().Format("2006-01-02 15:04"), from, to)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Printf("\nRoll back with: docksmith rollback --operation <id>  or  docksmith rollback %s --to <from-version>\n", containerName)
+	return nil
+}
+
+// findByVersion returns the most recent rollback candidate that updated the container from c.to.
+func (c *RollbackCommand) findByVersion(operations func(limit int) ([]storage.UpdateOperation, error), containerName string) (storage.UpdateOperation, error) {
+	all, err := operations(200)
+	if err != nil {
+		return storage.UpdateOperation{}, err
+	}
+	for _, op := range candidates(all, containerName) {
+		if from, _ := rollbackVersions(op, containerName); from == c.to {
+			return op, nil
+		}
+	}
+	return storage.UpdateOperation{}, fmt.Errorf("no completed update of %s from version %s found", containerName, c.to)
+}
+
+// rollbackVersions returns the version an operation updated containerName from and to.
+// Batch operations record versions per container.
+func rollbackVersions(op storage.UpdateOperation, containerName string) (from, to string) {
+	for _, detail := range op.BatchDetails {
+		if detail.ContainerName == containerName {
+			return detail.OldVersion, detail.NewVersion
+		}
+	}
+	return op.OldVersion, op.NewVersion
+}
+
+// rollback triggers the rollback and streams its progress until it completes or fails.
+func (c *RollbackCommand) rollback(ctx context.Context, store storage.Storage, operationID string) error {
+	original, found, err := store.GetUpdateOperation(ctx, operationID)
+	if err != nil {
+		return fmt.Errorf("failed to get operation: %w", err)
+	}
+	if !found {
+		return fmt.Errorf("operation not found: %s", operationID)
+	}
+
+	dockerService, err := docker.NewService()
+	if err != nil {
+		return fmt.Errorf("failed to connect to Docker: %w", err)
+	}
+	defer dockerService.Close()
+
+	bus := events.NewBus()
+	orchestrator := update.NewUpdateOrchestrator(
+		dockerService,
+		dockerService.GetClient(),
+		store,
+		bus,
+		InitializeRegistryManager(store),
+		dockerService.GetPathTranslator(),
+	)
+	defer orchestrator.Shutdown()
+
+	// Subscribe before starting so no early progress events are missed
+	progress, unsubscribe := bus.Subscribe(events.EventUpdateProgress)
+	defer unsubscribe()
+
+	rollbackID, err := orchestrator.RollbackOperation(update.WithTrigger(ctx, cliTrigger()), operationID, c.force)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Rollback started (operation %s)\n", rollbackID)
+
+	return followLocalOperations(ctx, progress, store, "Rollback", map[string]bool{rollbackID: len(original.BatchDetails) > 0}, c.timeout)
+}
+
+func printRollbackUsage() {
+	fmt.Println(`Usage:
+  docksmith rollback <container>                    List completed updates that can be rolled back
+  docksmith rollback <container> --to <version>     Roll back the update from <version>
+  docksmith rollback --operation <id>               Roll back an update operation
+
+Options:
+  --force            Skip pre-update checks of dependent containers
+  --limit N          Maximum number of operations to list (default 10)
+  --timeout D        How long to wait for the rollback to finish (default 10m)
+
+Examples:
+  docksmith rollback plex
+  docksmith rollback plex --to 1.40.0
+  docksmith rollback --operation 3f2a9c1e-... --force`)
+}
@@ -0,0 +1,201 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/chis/docksmith/internal/secrets"
+)
+
+// SecretCommand implements the `docksmith secret` subcommands
+type SecretCommand struct{}
+
+// NewSecretCommand creates a new secret command
+func NewSecretCommand() *SecretCommand {
+	return &SecretCommand{}
+}
+
+// flagSet returns the flags of a secret action; they have none
+func (c *SecretCommand) flagSet(action string) *flag.FlagSet {
+	fs := flag.NewFlagSet("secret "+action, flag.ExitOnError)
+	fs.Usage = printSecretUsage
+	return fs
+}
+
+// Run dispatches to the list, set, or remove action
+func (c *SecretCommand) Run(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		printSecretUsage()
+		return fmt.Errorf("missing secret action")
+	}
+
+	action, rest := args[0], args[1:]
+	switch action {
+	case "list", "ls":
+		return c.list(ctx)
+	case "set":
+		if len(rest) != 1 {
+			return fmt.Errorf("usage: docksmith secret set <name> < value")
+		}
+		return c.set(ctx, rest[0])
+	case "remove", "rm":
+		if len(rest) != 1 {
+			return fmt.Errorf("usage: docksmith secret remove <name>")
+		}
+		return c.remove(ctx, rest[0])
+	default:
+		printSecretUsage()
+		return fmt.Errorf("unknown secret action: %s", action)
+	}
+}
+
+// localSecretStore opens the secrets store of the local database
+func localSecretStore() (*secrets.Store, func(), error) {
+	store, err := InitializeStorage()
+	if err != nil {
+		return nil, nil, err
+	}
+	secretStore := secrets.NewStoreFromEnv(store)
+	if !secretStore.Enabled() {
+		store.Close()
+		return nil, nil, secrets.ErrDisabled
+	}
+	return secretStore, func() { store.Close() }, nil
+}
+
+// list prints the stored secrets and their references
+func (c *SecretCommand) list(ctx context.Context) error {
+	var list []secrets.Secret
+	if isRemote() {
+		var result struct {
+			Secrets []secrets.Secret `json:"secrets"`
+		}
+		if err := newRemoteClient().do(ctx, http.MethodGet, "/api/secrets", nil, &result); err != nil {
+			return err
+		}
+		list = result.Secrets
+	} else {
+		secretStore, closeStore, err := localSecretStore()
+		if err != nil {
+			return err
+		}
+		defer closeStore()
+
+		if list, err = secretStore.List(ctx); err != nil {
+			return err
+		}
+	}
+
+	if jsonOutput() {
+		return writeJSON(map[string]any{"secrets": list, "count": len(list)})
+	}
+
+	if len(list) == 0 {
+		fmt.Println("No secrets")
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tREFERENCE\tUPDATED")
+	for _, secret := range list {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", secret.Name, secret.Reference, secret.UpdatedAt.Local().Format(time.DateTime))
+	}
+	return tw.Flush()
+}
+
+// set stores a secret read from stdin, so its value stays out of the shell history
+func (c *SecretCommand) set(ctx context.Context, name string) error {
+	if err := secrets.ValidateName(name); err != nil {
+		return err
+	}
+	value, err := readSecretValue()
+	if err != nil {
+		return err
+	}
+
+	var secret secrets.Secret
+	if isRemote() {
+		body := map[string]string{"value": value}
+		if err := newRemoteClient().do(ctx, http.MethodPut, "/api/secrets/"+name, body, &secret); err != nil {
+			return err
+		}
+	} else {
+		secretStore, closeStore, err := localSecretStore()
+		if err != nil {
+			return err
+		}
+		defer closeStore()
+
+		if secret, err = secretStore.Set(ctx, name, value); err != nil {
+			return err
+		}
+	}
+
+	if jsonOutput() {
+		return writeJSON(secret)
+	}
+	fmt.Printf("Stored secret %s; use %s in settings in place of the value\n", secret.Name, secret.Reference)
+	return nil
+}
+
+// remove deletes a secret
+func (c *SecretCommand) remove(ctx context.Context, name string) error {
+	if isRemote() {
+		if err := newRemoteClient().do(ctx, http.MethodDelete, "/api/secrets/"+name, nil, nil); err != nil {
+			return err
+		}
+	} else {
+		secretStore, closeStore, err := localSecretStore()
+		if err != nil {
+			return err
+		}
+		defer closeStore()
+
+		if err := secretStore.Delete(ctx, name); err != nil {
+			return err
+		}
+	}
+
+	fmt.Printf("Removed secret %s\n", name)
+	return nil
+}
+
+// readSecretValue reads a secret value from the first line of stdin
+func readSecretValue() (string, error) {
+	if info, err := os.Stdin.Stat(); err == nil && info.Mode()&os.ModeCharDevice != 0 {
+		fmt.Fprint(os.Stderr, "Value: ")
+	}
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	value := strings.TrimRight(line, "\r\n")
+	if value == "" {
+		if err != nil {
+			return "", fmt.Errorf("failed to read secret value: %w", err)
+		}
+		return "", fmt.Errorf("secret value is required")
+	}
+	return value, nil
+}
+
+func printSecretUsage() {
+	fmt.Println(`Usage:
+  docksmith secret list                   List stored secrets and their references
+  docksmith secret set <name> < value     Store a secret read from stdin
+  docksmith secret remove <name>          Remove a secret
+
+Secrets are encrypted with the key in SECRETS_KEY or SECRETS_KEY_FILE. Settings
+such as GITHUB_TOKEN, GITLAB_TOKEN, QUAY_TOKEN, HARBOR_ROBOT, GITEA_TOKEN, and the
+notification tokens and webhook URLs can refer to a secret as secret:<name>
+instead of holding the value in plaintext. Values are never shown again once stored.
+
+Examples:
+  docksmith secret set gotify_token
+  echo "$GITHUB_TOKEN" | docksmith secret set github_token
+  docksmith secret remove gotify_token`)
+}
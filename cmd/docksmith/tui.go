@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	"github.com/chis/docksmith/internal/docker"
+	"github.com/chis/docksmith/internal/events"
+	"github.com/chis/docksmith/internal/tui"
+	"github.com/chis/docksmith/internal/update"
+)
+
+// TUICommand implements the `docksmith tui` dashboard
+type TUICommand struct {
+	logFile string
+}
+
+// NewTUICommand creates a new tui command
+func NewTUICommand() *TUICommand {
+	return &TUICommand{}
+}
+
+// flagSet returns the tui flags
+func (c *TUICommand) flagSet(action string) *flag.FlagSet {
+	fs := flag.NewFlagSet("tui", flag.ExitOnError)
+	fs.StringVar(&c.logFile, "log", "", "Write logs to this file (logs are discarded by default)")
+	fs.Usage = printTUIUsage
+	return fs
+}
+
+// Run checks all containers and shows the interactive dashboard.
+// Checks and updates run in this process against the local Docker socket.
+func (c *TUICommand) Run(ctx context.Context, args []string) error {
+	if err := c.flagSet("").Parse(args); err != nil {
+		return err
+	}
+
+	// Log output would corrupt the dashboard
+	var logOutput io.Writer = io.Discard
+	if c.logFile != "" {
+		f, err := os.OpenFile(c.logFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			return fmt.Errorf("failed to open log file: %w", err)
+		}
+		defer f.Close()
+		logOutput = f
+	}
+	log.SetOutput(logOutput)
+	defer log.SetOutput(os.Stderr)
+
+	dockerService, err := docker.NewService()
+	if err != nil {
+		return fmt.Errorf("failed to connect to Docker: %w", err)
+	}
+	defer dockerService.Close()
+
+	store, err := InitializeStorage()
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	registryManager := InitializeRegistryManager(store)
+	bus := events.NewBus()
+
+	checker := update.NewOrchestrator(dockerService, registryManager)
+	checker.SetEventBus(bus)
+	checker.SetStorage(store)
+	checker.SetArchFallback(update.ArchFallbackFromEnv())
+
+	updater := update.NewUpdateOrchestrator(
+		dockerService,
+		dockerService.GetClient(),
+		store,
+		bus,
+		registryManager,
+		dockerService.GetPathTranslator(),
+	)
+	defer updater.Shutdown()
+
+	return tui.NewApp(checker, updater, bus).Run(ctx)
+}
+
+func printTUIUsage() {
+	fmt.Println(`Usage:
+  docksmith tui [--log file]
+
+Shows a live table of containers and their update status.
+
+Keys:
+  up/down, j/k       Move
+  space              Select a container with an update
+  a                  Select all containers with updates
+  t                  Choose the tag to update the highlighted container to
+  enter              Review the compose file changes of the selected containers
+                     (or the highlighted one), then press enter again to update
+                     or esc to cancel
+  r                  Check again
+  q                  Quit
+
+In the tag picker:
+  up/down, j/k       Move
+  a                  Show all available tags instead of the update candidates
+  enter, space       Update to the highlighted tag and select the container
+  esc, q             Cancel`)
+}
@@ -0,0 +1,458 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/chis/docksmith/client"
+	"github.com/chis/docksmith/internal/api"
+	"github.com/chis/docksmith/internal/approval"
+	"github.com/chis/docksmith/internal/docker"
+	"github.com/chis/docksmith/internal/events"
+	"github.com/chis/docksmith/internal/storage"
+	"github.com/chis/docksmith/internal/update"
+)
+
+// UpdateCommand implements the `docksmith update` subcommand
+type UpdateCommand struct {
+	to       string
+	group    string
+	dryRun   bool
+	simulate bool
+	timeout  time.Duration
+}
+
+// NewUpdateCommand creates a new update command
+func NewUpdateCommand() *UpdateCommand {
+	return &UpdateCommand{
+		timeout: 30 * time.Minute,
+	}
+}
+
+// flagSet returns the update flags
+func (c *UpdateCommand) flagSet(action string) *flag.FlagSet {
+	fs := flag.NewFlagSet("update", flag.ExitOnError)
+	fs.StringVar(&c.to, "to", "", "Version to update to (one container only; default: the latest version found by the check)")
+	fs.StringVar(&c.group, "group", "", "Update the containers of this docksmith.group that have an update available")
+	fs.BoolVar(&c.dryRun, "dry-run", false, "Show the compose file changes without updating")
+	fs.BoolVar(&c.simulate, "simulate", false, "Try the update on a temporary clone of each container without updating")
+	fs.DurationVar(&c.timeout, "timeout", c.timeout, "How long to wait for the update to finish")
+	fs.Usage = printUpdateUsage
+	return fs
+}
+
+// updateTarget is a container to update and the version to update it to
+type updateTarget struct {
+	name    string
+	stack   string
+	version string
+}
+
+// Run updates containers and follows the progress until every update finishes.
+// Containers of the same stack are updated together in one batch operation.
+func (c *UpdateCommand) Run(ctx context.Context, args []string) error {
+	names, err := parseInterspersed(c.flagSet(""), args)
+	if err != nil {
+		return err
+	}
+	if c.group != "" {
+		if len(names) > 0 || c.to != "" {
+			return fmt.Errorf("--group cannot be combined with container names or --to")
+		}
+		if names, err = c.groupNames(ctx); err != nil {
+			return err
+		}
+		if len(names) == 0 {
+			fmt.Printf("No updates available in group %s\n", c.group)
+			return nil
+		}
+	}
+	if len(names) == 0 {
+		printUpdateUsage()
+		return fmt.Errorf("missing container name")
+	}
+	if c.to != "" && len(names) > 1 {
+		return fmt.Errorf("--to can only be used with a single container")
+	}
+	if c.dryRun && c.simulate {
+		return fmt.Errorf("--dry-run cannot be combined with --simulate")
+	}
+
+	if isRemote() {
+		return c.runRemote(ctx, newRemoteClient(), names)
+	}
+	return c.runLocal(ctx, names)
+}
+
+// groupNames returns the containers of the --group that have an update available,
+// from the server's last check with --server or a local check otherwise.
+// Blocked updates are skipped since they need a version picked with --to.
+func (c *UpdateCommand) groupNames(ctx context.Context) ([]string, error) {
+	var result *update.DiscoveryResult
+	if isRemote() {
+		result = &update.DiscoveryResult{}
+		if err := newRemoteClient().do(ctx, http.MethodGet, "/api/status", nil, result); err != nil {
+			return nil, err
+		}
+	} else {
+		var err error
+		if result, err = NewCheckCommand().checkLocal(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	members := filterGroup(result.Containers, c.group)
+	if len(members) == 0 {
+		return nil, fmt.Errorf("group not found: %s", c.group)
+	}
+	var names []string
+	for _, info := range members {
+		switch info.Status {
+		case update.UpdateAvailable:
+			names = append(names, info.ContainerName)
+		case update.UpdateAvailableBlocked:
+			fmt.Printf("Skipping %s: update blocked by its pre-update check\n", info.ContainerName)
+		case update.UpdateUnavailableArch:
+			fmt.Printf("Skipping %s: %s\n", info.ContainerName, info.Error)
+		}
+	}
+	return names, nil
+}
+
+// target checks that a container can be updated and returns what to update it to
+func (c *UpdateCommand) target(info update.ContainerInfo) (updateTarget, error) {
+	if c.to == "" && !hasUpdate(info) {
+		return updateTarget{}, fmt.Errorf("%s has no update available (status %s); use --to to pick a version", info.ContainerName, info.Status)
+	}
+	version := c.to
+	if version == "" {
+		version = info.LatestVersion
+	}
+	return updateTarget{name: info.ContainerName, stack: info.Stack, version: version}, nil
+}
+
+// runLocal checks and updates the containers in this process against the local Docker socket.
+func (c *UpdateCommand) runLocal(ctx context.Context, names []string) error {
+	dockerService, err := docker.NewService()
+	if err != nil {
+		return fmt.Errorf("failed to connect to Docker: %w", err)
+	}
+	defer dockerService.Close()
+
+	store, err := InitializeStorage()
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	registryManager := InitializeRegistryManager(store)
+	checker := update.NewOrchestrator(dockerService, registryManager)
+	checker.SetStorage(store)
+	checker.SetArchFallback(update.ArchFallbackFromEnv())
+	approvals := approval.NewManager(store, nil, nil)
+
+	var targets []updateTarget
+	for _, name := range names {
+		info, err := checker.DiscoverAndCheckSingle(ctx, name)
+		if err != nil {
+			return fmt.Errorf("failed to check %s: %w", name, err)
+		}
+		if info == nil {
+			return fmt.Errorf("container not found: %s", name)
+		}
+		if !c.dryRun && !c.simulate && approvals.RequiredFor(ctx, *info) {
+			return fmt.Errorf("updates to %s require approval; see `docksmith approvals list`", name)
+		}
+		target, err := c.target(*info)
+		if err != nil {
+			return err
+		}
+		targets = append(targets, target)
+	}
+
+	bus := events.NewBus()
+	orchestrator := update.NewUpdateOrchestrator(
+		dockerService,
+		dockerService.GetClient(),
+		store,
+		bus,
+		registryManager,
+		dockerService.GetPathTranslator(),
+	)
+	defer orchestrator.Shutdown()
+
+	if c.dryRun {
+		previews := make([]update.ComposePreview, 0, len(targets))
+		for _, t := range targets {
+			previews = append(previews, orchestrator.PreviewComposeChange(ctx, t.name, t.version))
+		}
+		printComposePreviews(previews)
+		return nil
+	}
+
+	orchestrator.SetLevelDelay(update.LevelDelayFromEnv())
+	orchestrator.SetMaxConcurrent(update.MaxConcurrentFromEnv())
+	orchestrator.SetSignatureVerification(update.SignatureConfigFromEnv())
+	orchestrator.SetLoadDeferral(update.LoadDeferralFromEnv())
+
+	// Subscribe before starting so no early progress events are missed
+	progress, unsubscribe := bus.Subscribe(events.EventUpdateProgress)
+	defer unsubscribe()
+
+	ctx = update.WithTrigger(ctx, cliTrigger())
+	if c.simulate {
+		return c.simulateLocal(ctx, orchestrator, progress, store, targets)
+	}
+
+	operations := make(map[string]bool)
+	var startErrs []error
+	for _, group := range groupByStack(targets) {
+		var operationID string
+		var err error
+		if len(group) == 1 {
+			operationID, err = orchestrator.UpdateSingleContainer(ctx, group[0].name, group[0].version)
+		} else {
+			containerNames := make([]string, 0, len(group))
+			versions := make(map[string]string, len(group))
+			for _, t := range group {
+				containerNames = append(containerNames, t.name)
+				versions[t.name] = t.version
+			}
+			operationID, err = orchestrator.UpdateBatchContainers(ctx, containerNames, versions)
+		}
+		if err != nil {
+			startErrs = append(startErrs, fmt.Errorf("failed to start update of %s: %w", targetNames(group), err))
+			continue
+		}
+		fmt.Printf("Update of %s started (operation %s)\n", targetNames(group), operationID)
+		operations[operationID] = len(group) > 1
+	}
+
+	return finishUpdates(startErrs, operations, func(operations map[string]bool) error {
+		return followLocalOperations(ctx, progress, store, "Update", operations, c.timeout)
+	})
+}
+
+// runRemote starts the updates through the API of the server given by --server.
+// The server applies its approval policy and groups the containers by stack.
+func (c *UpdateCommand) runRemote(ctx context.Context, remote *remoteClient, names []string) error {
+	var status update.DiscoveryResult
+	if err := remote.do(ctx, http.MethodGet, "/api/status", nil, &status); err != nil {
+		return err
+	}
+	checked := make(map[string]update.ContainerInfo, len(status.Containers))
+	for _, info := range status.Containers {
+		checked[info.ContainerName] = info
+	}
+
+	var containers []client.BatchUpdateContainer
+	for _, name := range names {
+		info, ok := checked[name]
+		if !ok {
+			return fmt.Errorf("container not found: %s (run `docksmith check` if it was created since the last check)", name)
+		}
+		target, err := c.target(info)
+		if err != nil {
+			return err
+		}
+		containers = append(containers, client.BatchUpdateContainer{Name: target.name, TargetVersion: target.version, Stack: target.stack})
+	}
+
+	if c.dryRun {
+		var preview api.UpdatePreviewResponse
+		if err := remote.do(ctx, http.MethodPost, "/api/update/preview", client.UpdatePreviewRequest{Containers: containers}, &preview); err != nil {
+			return err
+		}
+		printComposePreviews(preview.Previews)
+		return nil
+	}
+
+	if c.simulate {
+		return c.simulateRemote(ctx, remote, containers)
+	}
+
+	started, err := remote.api.BatchUpdate(ctx, client.BatchUpdateRequest{Containers: containers})
+	if err != nil {
+		return remoteError(err)
+	}
+
+	operations := make(map[string]bool)
+	var startErrs []error
+	for _, op := range started.Operations {
+		containerNames := strings.Join(op.Containers, ", ")
+		if op.OperationID == "" {
+			startErrs = append(startErrs, fmt.Errorf("failed to start update of %s: %s", containerNames, op.Error))
+			continue
+		}
+		fmt.Printf("Update of %s started (operation %s)\n", containerNames, op.OperationID)
+		operations[op.OperationID] = len(op.Containers) > 1
+	}
+
+	return finishUpdates(startErrs, operations, func(operations map[string]bool) error {
+		return remote.followOperations(ctx, "Update", operations, c.timeout)
+	})
+}
+
+// simulateLocal starts a simulation of each target's update and prints their
+// reports once they finish.
+func (c *UpdateCommand) simulateLocal(ctx context.Context, orchestrator *update.UpdateOrchestrator, progress events.Subscriber, store storage.Storage, targets []updateTarget) error {
+	operations := make(map[string]bool)
+	var order []string
+	var startErrs []error
+	for _, t := range targets {
+		operationID, err := orchestrator.SimulateUpdate(ctx, t.name, t.version)
+		if err != nil {
+			startErrs = append(startErrs, fmt.Errorf("failed to start simulation of %s: %w", t.name, err))
+			continue
+		}
+		fmt.Printf("Simulation of %s %s started (operation %s)\n", t.name, t.version, operationID)
+		operations[operationID] = false
+		order = append(order, operationID)
+	}
+
+	return finishUpdates(startErrs, operations, func(operations map[string]bool) error {
+		err := followLocalOperations(ctx, progress, store, "Simulation", operations, c.timeout)
+		for _, id := range order {
+			if op, found, _ := store.GetUpdateOperation(ctx, id); found {
+				printSimulationReport(op)
+			}
+		}
+		return err
+	})
+}
+
+// simulateRemote starts a simulation of each container's update on the server
+// and prints their reports once they finish.
+func (c *UpdateCommand) simulateRemote(ctx context.Context, remote *remoteClient, containers []client.BatchUpdateContainer) error {
+	operations := make(map[string]bool)
+	var order []string
+	var startErrs []error
+	for _, container := range containers {
+		started, err := remote.api.SimulateUpdate(ctx, client.UpdateRequest{ContainerName: container.Name, TargetVersion: container.TargetVersion})
+		if err != nil {
+			startErrs = append(startErrs, fmt.Errorf("failed to start simulation of %s: %w", container.Name, remoteError(err)))
+			continue
+		}
+		fmt.Printf("Simulation of %s %s started (operation %s)\n", container.Name, container.TargetVersion, started.OperationID)
+		operations[started.OperationID] = false
+		order = append(order, started.OperationID)
+	}
+
+	return finishUpdates(startErrs, operations, func(operations map[string]bool) error {
+		err := remote.followOperations(ctx, "Simulation", operations, c.timeout)
+		for _, id := range order {
+			var op storage.UpdateOperation
+			if remote.do(ctx, http.MethodGet, "/api/operations/"+id, nil, &op) == nil {
+				printSimulationReport(op)
+			}
+		}
+		return err
+	})
+}
+
+// printSimulationReport prints the steps of a finished simulation
+func printSimulationReport(op storage.UpdateOperation) {
+	if op.CheckOutput == "" {
+		return
+	}
+	fmt.Printf("\n%s -> %s:\n", op.ContainerName, op.NewVersion)
+	for _, line := range strings.Split(op.CheckOutput, "\n") {
+		fmt.Printf("  %s\n", line)
+	}
+}
+
+// printComposePreviews prints the compose file diff of each update.
+func printComposePreviews(previews []update.ComposePreview) {
+	for i, p := range previews {
+		if i > 0 {
+			fmt.Println()
+		}
+		fmt.Printf("%s -> %s\n", p.ContainerName, p.TargetVersion)
+		if p.Error != "" {
+			fmt.Printf("No compose file change: %s\n", p.Error)
+			continue
+		}
+		fmt.Print(p.Diff)
+	}
+}
+
+// finishUpdates follows the started operations and combines their result with
+// the errors of updates that could not be started.
+func finishUpdates(startErrs []error, operations map[string]bool, follow func(map[string]bool) error) error {
+	if len(operations) > 0 {
+		if err := follow(operations); err != nil {
+			startErrs = append(startErrs, err)
+		}
+	}
+	return errors.Join(startErrs...)
+}
+
+// groupByStack groups targets by stack, keeping standalone containers separate,
+// in a stable order.
+func groupByStack(targets []updateTarget) [][]updateTarget {
+	stacks := make(map[string][]updateTarget)
+	var groups [][]updateTarget
+	for _, t := range targets {
+		if t.stack == "" {
+			groups = append(groups, []updateTarget{t})
+			continue
+		}
+		stacks[t.stack] = append(stacks[t.stack], t)
+	}
+
+	stackNames := make([]string, 0, len(stacks))
+	for name := range stacks {
+		stackNames = append(stackNames, name)
+	}
+	sort.Strings(stackNames)
+	for _, name := range stackNames {
+		groups = append(groups, stacks[name])
+	}
+	return groups
+}
+
+// targetNames lists the container names of a group
+func targetNames(group []updateTarget) string {
+	names := make([]string, 0, len(group))
+	for _, t := range group {
+		names = append(names, t.name)
+	}
+	return strings.Join(names, ", ")
+}
+
+func printUpdateUsage() {
+	fmt.Println(`Usage:
+  docksmith update <container>... [--to version]
+  docksmith update --group <name>
+
+Updates containers to the latest version found by the check and follows the
+progress until the update finishes. Containers of the same stack are updated
+together. Containers that require approval must be approved first.
+
+Options:
+  --to <version>     Version to update to (one container only)
+  --dry-run          Show the diff of the compose file changes and exit
+                     without updating
+  --simulate         Start a temporary clone of each container on the new
+                     version (no published ports, volumes read-only), check
+                     that it becomes healthy, and remove it. The containers
+                     themselves are not touched
+  --group <name>     Update every container in the group (docksmith.group label)
+                     that has an update available
+  --timeout D        How long to wait for the update to finish (default 30m)
+
+Examples:
+  docksmith update plex
+  docksmith update sonarr radarr
+  docksmith update postgres --to 16.4
+  docksmith update postgres --to 16.4 --dry-run
+  docksmith update nextcloud --simulate
+  docksmith update --group media
+  docksmith --server https://nas:3000 --api-key $KEY update plex`)
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/chis/docksmith/internal/auth"
+)
+
+// UserCommand implements the `docksmith user` subcommands
+type UserCommand struct {
+	password string
+	role     string
+}
+
+// NewUserCommand creates a new user command
+func NewUserCommand() *UserCommand {
+	return &UserCommand{
+		role: string(auth.RoleViewer),
+	}
+}
+
+// Run dispatches to the create, delete, set-role, passwd, or list action
+func (c *UserCommand) Run(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		printUserUsage()
+		return fmt.Errorf("missing user action")
+	}
+
+	action, rest := args[0], args[1:]
+
+	store, err := InitializeStorage()
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	users := auth.NewUserService(store)
+
+	switch action {
+	case "create":
+		return c.create(ctx, users, rest)
+	case "delete", "rm":
+		if len(rest) != 1 {
+			return fmt.Errorf("usage: docksmith user delete <username>")
+		}
+		if err := users.DeleteUser(ctx, rest[0]); err != nil {
+			return err
+		}
+		fmt.Printf("Deleted user %s\n", rest[0])
+		return nil
+	case "set-role":
+		if len(rest) != 2 {
+			return fmt.Errorf("usage: docksmith user set-role <username> <viewer|operator|admin>")
+		}
+		role, err := auth.ParseRole(rest[1])
+		if err != nil {
+			return err
+		}
+		if err := users.SetRole(ctx, rest[0], role); err != nil {
+			return err
+		}
+		fmt.Printf("Set role of %s to %s\n", rest[0], role)
+		return nil
+	case "passwd":
+		return c.passwd(ctx, users, rest)
+	case "list", "ls":
+		return c.list(ctx, users)
+	default:
+		printUserUsage()
+		return fmt.Errorf("unknown user action: %s", action)
+	}
+}
+
+// flagSet returns the flags of a user action
+func (c *UserCommand) flagSet(action string) *flag.FlagSet {
+	fs := flag.NewFlagSet("user "+action, flag.ExitOnError)
+	fs.Usage = printUserUsage
+	switch action {
+	case "create":
+		fs.StringVar(&c.password, "password", os.Getenv("DOCKSMITH_PASSWORD"), "Login password (or DOCKSMITH_PASSWORD)")
+		fs.StringVar(&c.role, "role", c.role, "Role: viewer, operator, or admin")
+	case "passwd":
+		fs.StringVar(&c.password, "password", os.Getenv("DOCKSMITH_PASSWORD"), "New password (or DOCKSMITH_PASSWORD)")
+	}
+	return fs
+}
+
+func (c *UserCommand) create(ctx context.Context, users *auth.UserService, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: docksmith user create <username> --password <password> [--role viewer|operator|admin]")
+	}
+	username := args[0]
+
+	if err := c.flagSet("create").Parse(args[1:]); err != nil {
+		return err
+	}
+
+	role, err := auth.ParseRole(c.role)
+	if err != nil {
+		return err
+	}
+
+	user, err := users.CreateUser(ctx, username, c.password, role)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Created user %s (role=%s)\n", user.Username, user.Role)
+	return nil
+}
+
+func (c *UserCommand) passwd(ctx context.Context, users *auth.UserService, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: docksmith user passwd <username> --password <password>")
+	}
+	username := args[0]
+
+	if err := c.flagSet("passwd").Parse(args[1:]); err != nil {
+		return err
+	}
+
+	if err := users.SetPassword(ctx, username, c.password); err != nil {
+		return err
+	}
+
+	fmt.Printf("Updated password for %s\n", username)
+	return nil
+}
+
+func (c *UserCommand) list(ctx context.Context, users *auth.UserService) error {
+	list, err := users.ListUsers(ctx)
+	if err != nil {
+		return err
+	}
+
+	if jsonOutput() {
+		return writeJSON(map[string]any{"users": list, "count": len(list)})
+	}
+
+	if len(list) == 0 {
+		fmt.Println("No users configured")
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "USERNAME\tROLE\tCREATED")
+	for _, u := range list {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", u.Username, u.Role, u.CreatedAt.Local().Format("2006-01-02 15:04"))
+	}
+	return tw.Flush()
+}
+
+func printUserUsage() {
+	fmt.Println(`Usage:
+  docksmith user create <username> --password <password> [--role viewer|operator|admin]
+  docksmith user passwd <username> --password <password>
+  docksmith user set-role <username> <viewer|operator|admin>
+  docksmith user delete <username>
+  docksmith user list`)
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/chis/docksmith/internal/docker"
+	"github.com/chis/docksmith/internal/events"
+	"github.com/chis/docksmith/internal/update"
+)
+
+// VariantCommand implements the `docksmith variant` subcommand
+type VariantCommand struct {
+	timeout time.Duration
+}
+
+// NewVariantCommand creates a new variant command
+func NewVariantCommand() *VariantCommand {
+	return &VariantCommand{
+		timeout: 30 * time.Minute,
+	}
+}
+
+// flagSet returns the variant flags
+func (c *VariantCommand) flagSet(action string) *flag.FlagSet {
+	fs := flag.NewFlagSet("variant", flag.ExitOnError)
+	fs.DurationVar(&c.timeout, "timeout", c.timeout, "How long to wait for the switch to finish")
+	fs.Usage = printVariantUsage
+	return fs
+}
+
+// Run lists the variants of a container's version or switches it to one.
+// With only a container name it prints the variant tags found by the check; with a
+// tag it switches the container to it and follows the progress until it finishes.
+func (c *VariantCommand) Run(ctx context.Context, args []string) error {
+	names, err := parseInterspersed(c.flagSet(""), args)
+	if err != nil {
+		return err
+	}
+	if len(names) == 0 || len(names) > 2 {
+		printVariantUsage()
+		return fmt.Errorf("expected a container name and an optional tag")
+	}
+	containerName, tag := names[0], ""
+	if len(names) == 2 {
+		tag = names[1]
+	}
+
+	if isRemote() {
+		return c.runRemote(ctx, newRemoteClient(), containerName, tag)
+	}
+	return c.runLocal(ctx, containerName, tag)
+}
+
+// runLocal checks the container and switches it in this process against the local Docker socket.
+func (c *VariantCommand) runLocal(ctx context.Context, containerName, tag string) error {
+	dockerService, err := docker.NewService()
+	if err != nil {
+		return fmt.Errorf("failed to connect to Docker: %w", err)
+	}
+	defer dockerService.Close()
+
+	store, err := InitializeStorage()
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	registryManager := InitializeRegistryManager(store)
+	checker := update.NewOrchestrator(dockerService, registryManager)
+	checker.SetStorage(store)
+	checker.SetArchFallback(update.ArchFallbackFromEnv())
+	info, err := checker.DiscoverAndCheckSingle(ctx, containerName)
+	if err != nil {
+		return fmt.Errorf("failed to check %s: %w", containerName, err)
+	}
+	if info == nil {
+		return fmt.Errorf("container not found: %s", containerName)
+	}
+	if tag == "" {
+		return printVariants(*info)
+	}
+
+	bus := events.NewBus()
+	orchestrator := update.NewUpdateOrchestrator(
+		dockerService,
+		dockerService.GetClient(),
+		store,
+		bus,
+		registryManager,
+		dockerService.GetPathTranslator(),
+	)
+	defer orchestrator.Shutdown()
+	orchestrator.SetSignatureVerification(update.SignatureConfigFromEnv())
+
+	// Subscribe before starting so no early progress events are missed
+	progress, unsubscribe := bus.Subscribe(events.EventUpdateProgress)
+	defer unsubscribe()
+
+	operationID, err := orchestrator.SwitchVariant(update.WithTrigger(ctx, cliTrigger()), *info, tag)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Switch of %s to %s started (operation %s)\n", containerName, tag, operationID)
+
+	return followLocalOperations(ctx, progress, store, "Switch", map[string]bool{operationID: true}, c.timeout)
+}
+
+// runRemote lists the variants from the server's last check, or switches the
+// container through the API of the server given by --server.
+func (c *VariantCommand) runRemote(ctx context.Context, client *remoteClient, containerName, tag string) error {
+	if tag == "" {
+		var status update.DiscoveryResult
+		if err := client.do(ctx, http.MethodGet, "/api/status", nil, &status); err != nil {
+			return err
+		}
+		for _, info := range status.Containers {
+			if info.ContainerName == containerName {
+				return printVariants(info)
+			}
+		}
+		return fmt.Errorf("container not found: %s", containerName)
+	}
+
+	var started struct {
+		OperationID string `json:"operation_id"`
+	}
+	path := "/api/variant/" + url.PathEscape(containerName)
+	if err := client.do(ctx, http.MethodPost, path, map[string]string{"tag": tag}, &started); err != nil {
+		return err
+	}
+	fmt.Printf("Switch of %s to %s started (operation %s)\n", containerName, tag, started.OperationID)
+
+	return client.followOperations(ctx, "Switch", map[string]bool{started.OperationID: true}, c.timeout)
+}
+
+// printVariants prints the variant tags of a container's current version.
+func printVariants(info update.ContainerInfo) error {
+	if jsonOutput() {
+		return writeJSON(map[string]any{
+			"container_name": info.ContainerName,
+			"image":          info.Image,
+			"current_tag":    info.CurrentTag,
+			"variants":       info.Variants,
+		})
+	}
+
+	if len(info.Variants) == 0 {
+		fmt.Printf("No other variants of %s found for %s\n", valueOrDash(info.CurrentTag), info.ContainerName)
+		return nil
+	}
+	fmt.Printf("%s runs %s. Variants of the same version:\n", info.ContainerName, info.Image)
+	for _, variant := range info.Variants {
+		fmt.Printf("  %s\n", variant)
+	}
+	fmt.Printf("\nSwitch with: docksmith variant %s <tag>\n", info.ContainerName)
+	return nil
+}
+
+func printVariantUsage() {
+	fmt.Println(`Usage:
+  docksmith variant <container>          List the variants of the container's version
+  docksmith variant <container> <tag>    Switch the container to a variant
+
+A variant is the same version under another tag suffix, such as nginx:1.25
+for nginx:1.25-alpine. The compose file is changed to the new tag and the
+container recreated like an update, with its health checks and rollback.
+
+Options:
+  --timeout D        How long to wait for the switch to finish (default 30m)
+
+Examples:
+  docksmith variant nginx
+  docksmith variant nginx 1.25`)
+}
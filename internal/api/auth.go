@@ -0,0 +1,204 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/chis/docksmith/internal/auth"
+	"github.com/chis/docksmith/internal/logging"
+)
+
+var (
+	errAuthRequired     = errors.New("authentication required")
+	errInsufficientRole = errors.New("insufficient permissions for this action")
+)
+
+// routeRule assigns a minimum role to requests matching a method and path prefix.
+// An empty method matches any method.
+type routeRule struct {
+	method string
+	prefix string
+	role   auth.Role
+}
+
+// routeRules are checked in order; the first match wins. Requests that match no
+// rule need RoleViewer for safe methods and RoleOperator for everything else.
+var routeRules = []routeRule{
+	// Policies, scripts, labels, ignore rules, group ignore and schedules, stack
+	// environments, secrets, settings, notification templates, stack lock
+	// releases, users, and stack ownership are admin-only.
+	// Configuration exports include notification webhook URLs, and database
+	// backups include everything.
+	{"", "/api/users", auth.RoleAdmin},
+	{"", "/api/ownership", auth.RoleAdmin},
+	{"", "/api/teams", auth.RoleAdmin},
+	{"", "/api/config/", auth.RoleAdmin},
+	{"", "/api/db/", auth.RoleAdmin},
+	{"", "/api/notifications/", auth.RoleAdmin},
+	{http.MethodPut, "/api/settings/", auth.RoleAdmin},
+	{http.MethodPut, "/api/policies/", auth.RoleAdmin},
+	{http.MethodDelete, "/api/policies/", auth.RoleAdmin},
+	{http.MethodPost, "/api/scripts/", auth.RoleAdmin},
+	{http.MethodPut, "/api/scripts/", auth.RoleAdmin},
+	{http.MethodDelete, "/api/scripts/", auth.RoleAdmin},
+	{http.MethodPost, "/api/labels/", auth.RoleAdmin},
+	{http.MethodPost, "/api/ignore-rules", auth.RoleAdmin},
+	{http.MethodDelete, "/api/ignore-rules/", auth.RoleAdmin},
+	{http.MethodPost, "/api/groups/ignore/", auth.RoleAdmin},
+	{"", "/api/groups/schedule/", auth.RoleAdmin},
+	{"", "/api/stack-env", auth.RoleAdmin},
+	{"", "/api/secrets", auth.RoleAdmin},
+	{http.MethodDelete, "/api/history/", auth.RoleAdmin},
+	{http.MethodPost, "/api/locks/", auth.RoleAdmin},
+
+	// Container logs and inspect output can contain secrets
+	{http.MethodGet, "/api/containers/", auth.RoleOperator},
+	{http.MethodGet, "/api/docker-config", auth.RoleOperator},
+}
+
+// publicPaths never require authentication.
+var publicPaths = map[string]bool{
+	"/api/health":       true,
+	"/api/ready":        true,
+	"/api/openapi.json": true,
+	"/api/auth/login":   true,
+	"/api/auth/logout":  true,
+
+	"/api/auth/oidc/login":    true,
+	"/api/auth/oidc/callback": true,
+}
+
+// AuthMiddleware authenticates requests to /api/ and enforces role-based access.
+// Credentials are accepted as an API key (Authorization: Bearer, X-API-Key, or the
+// api_key query parameter for EventSource) or as a login session cookie.
+// Anonymous requests are rejected in ModeRequired. Otherwise they have full access
+// until the first user or API key is created, and are viewers after that, so
+// leaving out credentials never grants more than presenting them.
+func AuthMiddleware(keys *auth.KeyStore, users *auth.UserService, mode auth.Mode) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if mode == auth.ModeDisabled || !requiresAuth(r.URL.Path) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			principal, err := authenticateRequest(r, keys, users)
+			if err != nil {
+				if !errors.Is(err, auth.ErrInvalidKey) && !errors.Is(err, auth.ErrInvalidSession) {
+					logging.WarnContext(r.Context(), "Credential lookup failed: %v", err)
+				}
+				w.Header().Set("WWW-Authenticate", `Bearer realm="docksmith", error="invalid_token"`)
+				RespondError(w, http.StatusUnauthorized, err)
+				return
+			}
+
+			if principal == nil {
+				if mode == auth.ModeRequired {
+					w.Header().Set("WWW-Authenticate", `Bearer realm="docksmith"`)
+					RespondError(w, http.StatusUnauthorized, errAuthRequired)
+					return
+				}
+				if principal = anonymousPrincipal(r.Context(), keys, users); principal == nil {
+					next.ServeHTTP(w, r)
+					return
+				}
+			}
+
+			if !principal.Role.Allows(requiredRole(r.Method, r.URL.Path)) {
+				if principal.Anonymous() {
+					w.Header().Set("WWW-Authenticate", `Bearer realm="docksmith"`)
+					RespondError(w, http.StatusUnauthorized, errAuthRequired)
+					return
+				}
+				RespondError(w, http.StatusForbidden, errInsufficientRole)
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(auth.WithPrincipal(r.Context(), principal)))
+		})
+	}
+}
+
+// authenticateRequest resolves request credentials to a principal.
+// Returns (nil, nil) when no credentials were presented.
+func authenticateRequest(r *http.Request, keys *auth.KeyStore, users *auth.UserService) (*auth.Principal, error) {
+	if plaintext := extractAPIKey(r); plaintext != "" {
+		key, err := keys.Authenticate(r.Context(), plaintext)
+		if err != nil {
+			return nil, auth.ErrInvalidKey
+		}
+		return key.Principal(), nil
+	}
+
+	if cookie, err := r.Cookie(auth.SessionCookieName); err == nil && cookie.Value != "" && users != nil {
+		return users.Authenticate(r.Context(), cookie.Value)
+	}
+
+	return nil, nil
+}
+
+// anonymousPrincipal returns the principal of a request without credentials in
+// ModeOptional: none (full access) while there are no users or API keys, and
+// auth.AnonymousPrincipal once there are. Lookup failures count as existing
+// credentials, so they never widen access.
+func anonymousPrincipal(ctx context.Context, keys *auth.KeyStore, users *auth.UserService) *auth.Principal {
+	if users == nil {
+		return nil // No storage, so no users or API keys either
+	}
+	if list, err := keys.List(ctx); err != nil || len(list) > 0 {
+		return auth.AnonymousPrincipal()
+	}
+	if list, err := users.ListUsers(ctx); err != nil || len(list) > 0 {
+		return auth.AnonymousPrincipal()
+	}
+	return nil
+}
+
+// requiresAuth returns true for API paths that must be authenticated.
+// Static UI assets, the health endpoint, the OpenAPI document, login/logout/OIDC, approval
+// webhooks, and incoming webhooks stay public.
+func requiresAuth(path string) bool {
+	if !strings.HasPrefix(path, "/api/") {
+		return false
+	}
+	// Approval webhooks authenticate with their own HMAC signature
+	if strings.HasPrefix(path, "/api/approvals/") && strings.HasSuffix(path, "/webhook") {
+		return false
+	}
+	// Incoming webhooks authenticate with the hook token in the path
+	if strings.HasPrefix(path, "/api/hooks/") {
+		return false
+	}
+	return !publicPaths[path]
+}
+
+// requiredRole returns the minimum role needed for a request.
+func requiredRole(method, path string) auth.Role {
+	for _, rule := range routeRules {
+		if (rule.method == "" || rule.method == method) && strings.HasPrefix(path, rule.prefix) {
+			return rule.role
+		}
+	}
+
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return auth.RoleViewer
+	default:
+		return auth.RoleOperator
+	}
+}
+
+// extractAPIKey returns the API key presented with the request, if any.
+func extractAPIKey(r *http.Request) string {
+	if header := r.Header.Get("Authorization"); header != "" {
+		if token, ok := strings.CutPrefix(header, "Bearer "); ok {
+			return strings.TrimSpace(token)
+		}
+	}
+	if key := r.Header.Get("X-API-Key"); key != "" {
+		return strings.TrimSpace(key)
+	}
+	return r.URL.Query().Get("api_key")
+}
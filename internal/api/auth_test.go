@@ -0,0 +1,201 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/chis/docksmith/internal/auth"
+	"github.com/chis/docksmith/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type authTestKeys struct {
+	read, update, admin string
+}
+
+func newAuthTestHandler(t *testing.T, mode auth.Mode) (http.Handler, authTestKeys, *auth.UserService) {
+	t.Helper()
+	ctx := context.Background()
+	store := NewMockStorage()
+	keys := auth.NewKeyStore(store)
+	users := auth.NewUserService(store)
+
+	var k authTestKeys
+	var err error
+	k.read, _, err = keys.Create(ctx, "reader", auth.ScopeRead)
+	require.NoError(t, err)
+	k.update, _, err = keys.Create(ctx, "writer", auth.ScopeUpdate)
+	require.NoError(t, err)
+	k.admin, _, err = keys.Create(ctx, "root", auth.ScopeAdmin)
+	require.NoError(t, err)
+
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	return AuthMiddleware(keys, users, mode)(ok), k, users
+}
+
+func TestAuthMiddleware_Required(t *testing.T) {
+	handler, keys, _ := newAuthTestHandler(t, auth.ModeRequired)
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		header string
+		value  string
+		want   int
+	}{
+		{"anonymous rejected", "GET", "/api/status", "", "", http.StatusUnauthorized},
+		{"health is public", "GET", "/api/health", "", "", http.StatusOK},
+		{"openapi document is public", "GET", "/api/openapi.json", "", "", http.StatusOK},
+		{"login is public", "POST", "/api/auth/login", "", "", http.StatusOK},
+		{"static UI is public", "GET", "/index.html", "", "", http.StatusOK},
+		{"incoming webhooks are public", "POST", "/api/hooks/dsh_token", "", "", http.StatusOK},
+		{"invalid key rejected", "GET", "/api/status", "Authorization", "Bearer dsk_bogus", http.StatusUnauthorized},
+		{"viewer can read", "GET", "/api/status", "Authorization", "Bearer " + keys.read, http.StatusOK},
+		{"viewer cannot update", "POST", "/api/update", "Authorization", "Bearer " + keys.read, http.StatusForbidden},
+		{"viewer cannot read logs", "GET", "/api/containers/web/logs", "X-API-Key", keys.read, http.StatusForbidden},
+		{"operator can update", "POST", "/api/update", "X-API-Key", keys.update, http.StatusOK},
+		{"operator can read logs", "GET", "/api/containers/web/logs", "X-API-Key", keys.update, http.StatusOK},
+		{"operator cannot change settings", "PUT", "/api/settings/check_interval", "X-API-Key", keys.update, http.StatusForbidden},
+		{"operator cannot manage users", "GET", "/api/users", "X-API-Key", keys.update, http.StatusForbidden},
+		{"admin can change settings", "PUT", "/api/settings/check_interval", "X-API-Key", keys.admin, http.StatusOK},
+		{"admin can manage users", "POST", "/api/users", "X-API-Key", keys.admin, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(tt.method, tt.path, nil)
+			if tt.header != "" {
+				r.Header.Set(tt.header, tt.value)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+			assert.Equal(t, tt.want, w.Code)
+		})
+	}
+}
+
+func TestAuthMiddleware_QueryParamForSSE(t *testing.T) {
+	handler, keys, _ := newAuthTestHandler(t, auth.ModeRequired)
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/api/events?api_key="+keys.read, nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestAuthMiddleware_Optional(t *testing.T) {
+	handler, keys, _ := newAuthTestHandler(t, auth.ModeOptional)
+
+	// Once API keys exist, anonymous requests are limited to viewing
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/api/status", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("POST", "/api/update", nil))
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/api/containers/web/logs", nil))
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	// Presented keys are still validated and role-checked
+	r := httptest.NewRequest("POST", "/api/update", nil)
+	r.Header.Set("Authorization", "Bearer "+keys.read)
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	r = httptest.NewRequest("GET", "/api/status", nil)
+	r.Header.Set("Authorization", "Bearer dsk_bogus")
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestAuthMiddleware_OptionalWithoutCredentials(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewMemoryStorage()
+	keys := auth.NewKeyStore(store)
+	users := auth.NewUserService(store)
+
+	var principal *auth.Principal
+	handler := AuthMiddleware(keys, users, auth.ModeOptional)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		principal = auth.PrincipalFromContext(r.Context())
+		w.WriteHeader(http.StatusOK)
+	}))
+	request := func(method, path string) int {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(method, path, nil))
+		return w.Code
+	}
+
+	// Without users or API keys, anonymous requests have full access
+	assert.Equal(t, http.StatusOK, request("PUT", "/api/settings/check_interval"))
+	assert.Nil(t, principal)
+
+	// Creating a user limits them to viewing, and to what nobody owns
+	_, err := users.CreateUser(ctx, "alice", "password123", auth.RoleAdmin)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusUnauthorized, request("PUT", "/api/settings/check_interval"))
+	assert.Equal(t, http.StatusOK, request("GET", "/api/status"))
+	require.NotNil(t, principal)
+	assert.True(t, principal.Anonymous())
+
+	require.NoError(t, store.AddOwnership(ctx, storage.Ownership{EntityType: storage.OwnershipStack, EntityID: "media", Owner: "user:alice"}))
+	scope, err := auth.NewOwnershipService(store).ScopeFor(ctx, principal)
+	require.NoError(t, err)
+	assert.False(t, scope.Allows("media", "plex"))
+	assert.True(t, scope.Allows("web", "nginx"))
+}
+
+func TestAuthMiddleware_SessionCookie(t *testing.T) {
+	ctx := context.Background()
+	handler, _, users := newAuthTestHandler(t, auth.ModeRequired)
+
+	_, err := users.CreateUser(ctx, "alice", "password123", auth.RoleOperator)
+	require.NoError(t, err)
+	token, _, _, err := users.Login(ctx, "alice", "password123")
+	require.NoError(t, err)
+
+	request := func(method, path, token string) int {
+		r := httptest.NewRequest(method, path, nil)
+		r.AddCookie(&http.Cookie{Name: auth.SessionCookieName, Value: token})
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusOK, request("POST", "/api/update", token))
+	assert.Equal(t, http.StatusForbidden, request("DELETE", "/api/users/bob", token))
+	assert.Equal(t, http.StatusUnauthorized, request("GET", "/api/status", "bogus"))
+
+	require.NoError(t, users.Logout(ctx, token))
+	assert.Equal(t, http.StatusUnauthorized, request("GET", "/api/status", token))
+}
+
+func TestRequiredRole(t *testing.T) {
+	assert.Equal(t, auth.RoleViewer, requiredRole("GET", "/api/status"))
+	assert.Equal(t, auth.RoleOperator, requiredRole("POST", "/api/restart/web"))
+	assert.Equal(t, auth.RoleOperator, requiredRole("GET", "/api/containers/web/inspect"))
+	assert.Equal(t, auth.RoleAdmin, requiredRole("DELETE", "/api/scripts/assign/web"))
+	assert.Equal(t, auth.RoleAdmin, requiredRole("PUT", "/api/scripts/check.sh"))
+	assert.Equal(t, auth.RoleAdmin, requiredRole("POST", "/api/locks/media/release"))
+	assert.Equal(t, auth.RoleViewer, requiredRole("GET", "/api/locks"))
+	assert.Equal(t, auth.RoleAdmin, requiredRole("PUT", "/api/policies/approval/global"))
+	assert.Equal(t, auth.RoleViewer, requiredRole("GET", "/api/policies"))
+	assert.Equal(t, auth.RoleAdmin, requiredRole("GET", "/api/users"))
+	assert.Equal(t, auth.RoleAdmin, requiredRole("GET", "/api/ownership"))
+	assert.Equal(t, auth.RoleAdmin, requiredRole("PUT", "/api/teams/media/members/alice"))
+	assert.Equal(t, auth.RoleAdmin, requiredRole("GET", "/api/config/export"))
+	assert.Equal(t, auth.RoleAdmin, requiredRole("GET", "/api/db/backup"))
+	assert.Equal(t, auth.RoleAdmin, requiredRole("GET", "/api/notifications/templates"))
+	assert.Equal(t, auth.RoleOperator, requiredRole("POST", "/api/groups/update/media"))
+	assert.Equal(t, auth.RoleAdmin, requiredRole("POST", "/api/groups/ignore/media"))
+	assert.Equal(t, auth.RoleAdmin, requiredRole("PUT", "/api/groups/schedule/media"))
+}
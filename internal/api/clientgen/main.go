@@ -0,0 +1,330 @@
+// Command clientgen generates the methods and types of the Go client package
+// from the API's OpenAPI document.
+//
+//	go run ./internal/api/clientgen -o client/client_gen.go
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"log"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/chis/docksmith/internal/api"
+	"github.com/chis/docksmith/internal/openapi"
+)
+
+func main() {
+	out := flag.String("o", "client_gen.go", "file to write the generated code to")
+	flag.Parse()
+
+	src, err := generate(api.OpenAPI())
+	if err != nil {
+		log.Fatalf("clientgen: %v", err)
+	}
+	if err := os.WriteFile(*out, src, 0644); err != nil {
+		log.Fatalf("clientgen: %v", err)
+	}
+}
+
+// initialisms are written in upper case in Go names
+var initialisms = map[string]bool{
+	"API": true, "CPU": true, "CSV": true, "DNS": true, "EOL": true, "HTTP": true,
+	"ID": true, "IP": true, "JSON": true, "OIDC": true, "OS": true, "SHA": true,
+	"TTL": true, "UI": true, "URL": true, "UUID": true, "YAML": true,
+}
+
+// goKeywords cannot be used as parameter names
+var goKeywords = map[string]bool{
+	"break": true, "case": true, "chan": true, "const": true, "continue": true,
+	"default": true, "defer": true, "else": true, "fallthrough": true, "for": true,
+	"func": true, "go": true, "goto": true, "if": true, "import": true,
+	"interface": true, "map": true, "package": true, "range": true, "return": true,
+	"select": true, "struct": true, "switch": true, "type": true, "var": true,
+}
+
+// generator writes the Go source of a client
+type generator struct {
+	buf     bytes.Buffer
+	schemas map[string]*openapi.Schema
+}
+
+// generate returns the formatted source of the client of doc
+func generate(doc *openapi.Document) ([]byte, error) {
+	g := &generator{schemas: doc.Components.Schemas}
+
+	type method struct {
+		path, verb string
+		op         *openapi.Operation
+	}
+	var methods []method
+	for _, path := range slices.Sorted(maps.Keys(doc.Paths)) {
+		for _, verb := range slices.Sorted(maps.Keys(doc.Paths[path])) {
+			if op := doc.Paths[path][verb]; !op.NoClient {
+				methods = append(methods, method{path, verb, op})
+			}
+		}
+	}
+	slices.SortFunc(methods, func(a, b method) int { return strings.Compare(a.op.OperationID, b.op.OperationID) })
+	for _, m := range methods {
+		if err := g.method(m.path, strings.ToUpper(m.verb), m.op); err != nil {
+			return nil, fmt.Errorf("%s %s: %w", m.verb, m.path, err)
+		}
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(doc.Components.Schemas)) {
+		if name == "Envelope" {
+			continue
+		}
+		g.printf("// %s is the %s schema of the API\n", name, name)
+		typ, err := g.goType(doc.Components.Schemas[name], true)
+		if err != nil {
+			return nil, fmt.Errorf("schema %s: %w", name, err)
+		}
+		g.printf("type %s %s\n\n", name, typ)
+	}
+
+	var src bytes.Buffer
+	src.WriteString("// Code generated by clientgen from the OpenAPI document. DO NOT EDIT.\n\n")
+	src.WriteString("package client\n\nimport (\n")
+	for _, imp := range []struct{ pkg, use string }{
+		{"context", "context.Context"},
+		{"encoding/json", "json.RawMessage"},
+		{"net/url", "url."},
+		{"time", "time.Time"},
+	} {
+		if bytes.Contains(g.buf.Bytes(), []byte(imp.use)) {
+			fmt.Fprintf(&src, "%q\n", imp.pkg)
+		}
+	}
+	src.WriteString(")\n\n")
+	src.Write(g.buf.Bytes())
+
+	formatted, err := format.Source(src.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("failed to format generated code: %w", err)
+	}
+	return formatted, nil
+}
+
+func (g *generator) printf(format string, args ...any) {
+	fmt.Fprintf(&g.buf, format, args...)
+}
+
+// method writes the client method of an operation
+func (g *generator) method(path, verb string, op *openapi.Operation) error {
+	params := []string{"ctx context.Context"}
+	pathExpr := `"` + path + `"`
+	var query bool
+	for _, p := range op.Parameters {
+		switch p.In {
+		case "path":
+			name := paramName(p.Name)
+			params = append(params, name+" string")
+			pathExpr = strings.Replace(pathExpr, "{"+p.Name+"}", `" + url.PathEscape(`+name+`) + "`, 1)
+		case "query":
+			query = true
+		}
+	}
+	pathExpr = strings.TrimSuffix(pathExpr, ` + ""`)
+	queryArg := "nil"
+	if query {
+		params = append(params, "query url.Values")
+		queryArg = "query"
+	}
+
+	var send string
+	if op.RequestBody != nil {
+		contentType, media := single(op.RequestBody.Content)
+		switch {
+		case contentType != "application/json":
+			params = append(params, "body []byte")
+			send = fmt.Sprintf("c.Send(ctx, %q, %s, %s, %q, body, %%s)", verb, pathExpr, queryArg, contentType)
+		case media.Schema.Ref != "":
+			params = append(params, "req "+openapi.RefName(media.Schema.Ref))
+			send = fmt.Sprintf("c.Do(ctx, %q, %s, %s, req, %%s)", verb, pathExpr, queryArg)
+		default:
+			params = append(params, "body any")
+			send = fmt.Sprintf("c.Do(ctx, %q, %s, %s, body, %%s)", verb, pathExpr, queryArg)
+		}
+	} else {
+		send = fmt.Sprintf("c.Do(ctx, %q, %s, %s, nil, %%s)", verb, pathExpr, queryArg)
+	}
+
+	g.printf("// %s calls %s %s.\n//\n// %s.", op.OperationID, verb, path, strings.TrimSuffix(op.Summary, "."))
+	if op.Role != "" {
+		g.printf(" Requires the %s role.", op.Role)
+	}
+	g.printf("\n")
+	signature := fmt.Sprintf("func (c *Client) %s(%s)", op.OperationID, strings.Join(params, ", "))
+
+	content := op.Responses["200"].Content
+	if media, ok := content["application/json"]; ok && len(media.Schema.AllOf) == 2 {
+		data := media.Schema.AllOf[1].Properties["data"]
+		if name := openapi.RefName(data.Ref); name != "" {
+			g.printf("%s (*%s, error) {\nvar out %s\nif err := %s; err != nil {\nreturn nil, err\n}\nreturn &out, nil\n}\n\n",
+				signature, name, name, fmt.Sprintf(send, "&out"))
+			return nil
+		}
+		g.printf("%s (json.RawMessage, error) {\nvar out json.RawMessage\nerr := %s\nreturn out, err\n}\n\n",
+			signature, fmt.Sprintf(send, "&out"))
+		return nil
+	}
+	if op.RequestBody != nil {
+		return fmt.Errorf("raw responses to requests with a body are not supported")
+	}
+	g.printf("%s ([]byte, error) {\nreturn c.Fetch(ctx, %q, %s, %s)\n}\n\n", signature, verb, pathExpr, queryArg)
+	return nil
+}
+
+// single returns the only content type of a body
+func single(content map[string]openapi.MediaType) (string, openapi.MediaType) {
+	for contentType, media := range content {
+		return contentType, media
+	}
+	return "", openapi.MediaType{}
+}
+
+// goType returns the Go type of a schema. Required is false for optional
+// object properties, which refer to other schemas and times by pointer.
+func (g *generator) goType(s *openapi.Schema, required bool) (string, error) {
+	if s.Ref != "" {
+		name := openapi.RefName(s.Ref)
+		if _, ok := g.schemas[name]; !ok {
+			return "", fmt.Errorf("unknown schema %s", s.Ref)
+		}
+		if !required {
+			return "*" + name, nil
+		}
+		return name, nil
+	}
+
+	switch s.Type {
+	case "boolean":
+		return "bool", nil
+	case "number":
+		return "float64", nil
+	case "integer":
+		switch s.Format {
+		case "int64":
+			return "int64", nil
+		case "uint64":
+			return "uint64", nil
+		default:
+			return "int", nil
+		}
+	case "string":
+		switch s.Format {
+		case "date-time":
+			if !required {
+				return "*time.Time", nil
+			}
+			return "time.Time", nil
+		case "byte":
+			return "[]byte", nil
+		default:
+			return "string", nil
+		}
+	case "array":
+		elem, err := g.goType(s.Items, true)
+		return "[]" + elem, err
+	case "object":
+		if s.AdditionalProperties != nil {
+			elem, err := g.goType(s.AdditionalProperties, true)
+			return "map[string]" + elem, err
+		}
+		return g.structType(s)
+	case "":
+		return "json.RawMessage", nil
+	default:
+		return "", fmt.Errorf("unsupported schema type %q", s.Type)
+	}
+}
+
+// structType returns the Go struct type of an object schema
+func (g *generator) structType(s *openapi.Schema) (string, error) {
+	var b strings.Builder
+	b.WriteString("struct {\n")
+	fields := make(map[string]string)
+	for _, name := range slices.Sorted(maps.Keys(s.Properties)) {
+		required := slices.Contains(s.Required, name)
+		typ, err := g.goType(s.Properties[name], required)
+		if err != nil {
+			return "", fmt.Errorf("property %s: %w", name, err)
+		}
+		field := fieldName(name)
+		if other, ok := fields[field]; ok {
+			return "", fmt.Errorf("properties %s and %s are both field %s", other, name, field)
+		}
+		fields[field] = name
+
+		tag := name
+		if !required {
+			tag += ",omitempty"
+		}
+		fmt.Fprintf(&b, "%s %s `json:%q`\n", field, typ, tag)
+	}
+	b.WriteString("}")
+	return b.String(), nil
+}
+
+// words splits a JSON or parameter name into words, e.g. "container_name" and
+// "groupId" into "container", "name" and "group", "Id".
+func words(name string) []string {
+	var parts []string
+	var current []rune
+	for i, r := range name {
+		switch {
+		case !unicode.IsLetter(r) && !unicode.IsDigit(r):
+			if len(current) > 0 {
+				parts = append(parts, string(current))
+			}
+			current = nil
+			continue
+		case i > 0 && unicode.IsUpper(r) && len(current) > 0 && !unicode.IsUpper(current[len(current)-1]):
+			parts = append(parts, string(current))
+			current = nil
+		}
+		current = append(current, r)
+	}
+	if len(current) > 0 {
+		parts = append(parts, string(current))
+	}
+	return parts
+}
+
+// fieldName returns the exported Go name of a JSON name
+func fieldName(name string) string {
+	var b strings.Builder
+	for _, word := range words(name) {
+		if upper := strings.ToUpper(word); initialisms[upper] {
+			b.WriteString(upper)
+			continue
+		}
+		b.WriteString(strings.ToUpper(word[:1]) + word[1:])
+	}
+	field := b.String()
+	if field == "" || unicode.IsDigit(rune(field[0])) {
+		field = "X" + field
+	}
+	return field
+}
+
+// paramName returns the Go parameter name of a path parameter
+func paramName(name string) string {
+	field := fieldName(name)
+	param := strings.ToLower(field[:1]) + field[1:]
+	if upper := strings.ToUpper(field); initialisms[upper] {
+		param = strings.ToLower(field)
+	}
+	if goKeywords[param] {
+		param += "Name"
+	}
+	return param
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"os"
+	"testing"
+
+	"github.com/chis/docksmith/internal/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGeneratedClientIsUpToDate(t *testing.T) {
+	src, err := generate(api.OpenAPI())
+	require.NoError(t, err)
+
+	existing, err := os.ReadFile("../../../client/client_gen.go")
+	require.NoError(t, err)
+	assert.True(t, string(src) == string(existing), "client/client_gen.go is out of date; run go generate ./client")
+}
+
+func TestFieldName(t *testing.T) {
+	tests := map[string]string{
+		"container_name":        "ContainerName",
+		"id":                    "ID",
+		"batch_group_id":        "BatchGroupID",
+		"groupId":               "GroupID",
+		"cpu_percent":           "CPUPercent",
+		"dependents_restarted":  "DependentsRestarted",
+		"webhook_url":           "WebhookURL",
+		"X-Docksmith-Signature": "XDocksmithSignature",
+	}
+	for name, want := range tests {
+		assert.Equal(t, want, fieldName(name), name)
+	}
+}
+
+func TestParamName(t *testing.T) {
+	assert.Equal(t, "id", paramName("id"))
+	assert.Equal(t, "groupID", paramName("groupId"))
+	assert.Equal(t, "imageRef", paramName("imageRef"))
+	assert.Equal(t, "typeName", paramName("type"))
+}
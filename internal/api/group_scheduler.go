@@ -0,0 +1,334 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/chis/docksmith/internal/notify"
+	"github.com/chis/docksmith/internal/settings"
+	"github.com/chis/docksmith/internal/storage"
+)
+
+// groupSchedulesConfigKey stores group update schedules as a JSON object keyed by group name.
+const groupSchedulesConfigKey = settings.GroupSchedulesConfigKey
+
+// GroupSchedule is when a group's available updates are applied automatically.
+// Fields follow notify.ParseSchedule: period "daily" or "weekly", a time of day
+// ("03:00"), and a weekday for weekly schedules.
+type GroupSchedule struct {
+	Period  string `json:"period"`
+	At      string `json:"at,omitempty"`
+	Weekday string `json:"weekday,omitempty"`
+}
+
+// groupScheduler runs scheduled group updates. Schedules are persisted in
+// storage when it is available and kept in memory otherwise.
+type groupScheduler struct {
+	store storage.Storage
+	run   func(ctx context.Context, group string)
+	now   func() time.Time
+
+	mu        sync.Mutex
+	schedules map[string]GroupSchedule
+	parsed    map[string]notify.Schedule
+	next      map[string]time.Time
+	stopChan  chan struct{}
+	wake      chan struct{}
+
+	// Pre-pulls of the images of upcoming scheduled updates, off when prepullRun is nil
+	prepull     notify.Schedule
+	prepullRun  func(ctx context.Context, groups []string)
+	nextPrepull time.Time
+}
+
+// newGroupScheduler loads saved schedules. Invalid entries are logged and dropped.
+func newGroupScheduler(store storage.Storage, run func(ctx context.Context, group string)) *groupScheduler {
+	g := &groupScheduler{
+		store:     store,
+		run:       run,
+		now:       time.Now,
+		schedules: make(map[string]GroupSchedule),
+		parsed:    make(map[string]notify.Schedule),
+		next:      make(map[string]time.Time),
+		wake:      make(chan struct{}, 1),
+	}
+
+	g.Reload(context.Background())
+	return g
+}
+
+// Reload replaces the schedules with those saved in storage, e.g. after a
+// configuration revert. Invalid entries are logged and dropped.
+func (g *groupScheduler) Reload(ctx context.Context) {
+	if g.store == nil {
+		return
+	}
+	value, found, err := g.store.GetConfig(ctx, groupSchedulesConfigKey)
+	if err != nil {
+		log.Printf("GROUP: Failed to load group schedules: %v", err)
+		return
+	}
+
+	var saved map[string]GroupSchedule
+	if found && value != "" {
+		if err := json.Unmarshal([]byte(value), &saved); err != nil {
+			log.Printf("GROUP: Ignoring invalid group schedules: %v", err)
+			return
+		}
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.schedules = make(map[string]GroupSchedule)
+	g.parsed = make(map[string]notify.Schedule)
+	g.next = make(map[string]time.Time)
+	for group, sched := range saved {
+		parsed, err := notify.ParseSchedule(sched.Period, sched.At, sched.Weekday)
+		if err != nil {
+			log.Printf("GROUP: Ignoring schedule for group %s: %v", group, err)
+			continue
+		}
+		g.schedules[group] = sched
+		g.parsed[group] = parsed
+		g.next[group] = parsed.Next(g.now())
+	}
+	g.notify()
+}
+
+// Schedules returns a copy of the configured schedules.
+func (g *groupScheduler) Schedules() map[string]GroupSchedule {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	schedules := make(map[string]GroupSchedule, len(g.schedules))
+	for group, sched := range g.schedules {
+		schedules[group] = sched
+	}
+	return schedules
+}
+
+// NextRun returns when a group's next scheduled update runs.
+func (g *groupScheduler) NextRun(group string) (time.Time, bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	next, ok := g.next[group]
+	return next, ok
+}
+
+// Set validates and saves a group's schedule, replacing any existing one.
+func (g *groupScheduler) Set(ctx context.Context, group string, sched GroupSchedule) (notify.Schedule, error) {
+	parsed, err := notify.ParseSchedule(sched.Period, sched.At, sched.Weekday)
+	if err != nil {
+		return notify.Schedule{}, err
+	}
+
+	g.mu.Lock()
+	previous, existed := g.schedules[group]
+	g.schedules[group] = sched
+	if err := g.saveLocked(ctx); err != nil {
+		if existed {
+			g.schedules[group] = previous
+		} else {
+			delete(g.schedules, group)
+		}
+		g.mu.Unlock()
+		return notify.Schedule{}, err
+	}
+	g.parsed[group] = parsed
+	g.next[group] = parsed.Next(g.now())
+	g.mu.Unlock()
+
+	g.notify()
+	return parsed, nil
+}
+
+// Delete removes a group's schedule. Returns false if the group had none.
+func (g *groupScheduler) Delete(ctx context.Context, group string) (bool, error) {
+	g.mu.Lock()
+	previous, existed := g.schedules[group]
+	if !existed {
+		g.mu.Unlock()
+		return false, nil
+	}
+	delete(g.schedules, group)
+	if err := g.saveLocked(ctx); err != nil {
+		g.schedules[group] = previous
+		g.mu.Unlock()
+		return false, err
+	}
+	delete(g.parsed, group)
+	delete(g.next, group)
+	g.mu.Unlock()
+
+	g.notify()
+	return true, nil
+}
+
+// saveLocked persists the schedules. Caller must hold g.mu.
+func (g *groupScheduler) saveLocked(ctx context.Context) error {
+	if g.store == nil {
+		return nil
+	}
+	data, err := json.Marshal(g.schedules)
+	if err != nil {
+		return fmt.Errorf("failed to encode group schedules: %w", err)
+	}
+	if err := g.store.SetConfig(ctx, groupSchedulesConfigKey, string(data)); err != nil {
+		return fmt.Errorf("failed to save group schedules: %w", err)
+	}
+	return nil
+}
+
+// notify wakes the scheduler loop so it picks up changed schedules.
+func (g *groupScheduler) notify() {
+	select {
+	case g.wake <- struct{}{}:
+	default:
+	}
+}
+
+// SetPrepull pre-pulls the images of scheduled updates at the time of schedule.
+// Each pre-pull covers the groups whose update runs before the following pre-pull.
+// Must be called before Start.
+func (g *groupScheduler) SetPrepull(schedule notify.Schedule, run func(ctx context.Context, groups []string)) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.prepull = schedule
+	g.prepullRun = run
+	g.nextPrepull = schedule.Next(g.now())
+}
+
+// NextPrepull returns when the images of upcoming scheduled updates are next pre-pulled.
+func (g *groupScheduler) NextPrepull() (time.Time, bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.nextPrepull, g.prepullRun != nil
+}
+
+// Upcoming returns the groups whose scheduled update runs after now and no later
+// than until, in name order.
+func (g *groupScheduler) Upcoming(now, until time.Time) []string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	var groups []string
+	for group, next := range g.next {
+		if next.After(now) && !next.After(until) {
+			groups = append(groups, group)
+		}
+	}
+	sort.Strings(groups)
+	return groups
+}
+
+// Start begins running scheduled group updates.
+func (g *groupScheduler) Start() {
+	g.mu.Lock()
+	if g.stopChan != nil {
+		g.mu.Unlock()
+		return
+	}
+	stopChan := make(chan struct{})
+	g.stopChan = stopChan
+	g.mu.Unlock()
+
+	go g.loop(stopChan)
+}
+
+// Stop stops the scheduler.
+func (g *groupScheduler) Stop() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.stopChan != nil {
+		close(g.stopChan)
+		g.stopChan = nil
+	}
+}
+
+// loop sleeps until the earliest scheduled group is due and runs every due group.
+func (g *groupScheduler) loop(stopChan chan struct{}) {
+	for {
+		var timerC <-chan time.Time
+		var timer *time.Timer
+		if next, ok := g.nextWake(); ok {
+			timer = time.NewTimer(time.Until(next))
+			timerC = timer.C
+		}
+
+		select {
+		case <-stopChan:
+			if timer != nil {
+				timer.Stop()
+			}
+			return
+		case <-g.wake:
+			if timer != nil {
+				timer.Stop()
+			}
+		case <-timerC:
+			for _, group := range g.due(g.now()) {
+				log.Printf("GROUP: Running scheduled update for group %s", group)
+				g.run(context.Background(), group)
+			}
+			if groups, ok := g.duePrepull(g.now()); ok && len(groups) > 0 {
+				log.Printf("GROUP: Pre-pulling images for the scheduled updates of %v", groups)
+				g.prepullRun(context.Background(), groups)
+			}
+		}
+	}
+}
+
+// nextWake returns the earliest next run across all schedules.
+func (g *groupScheduler) nextWake() (time.Time, bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	var earliest time.Time
+	for _, next := range g.next {
+		if earliest.IsZero() || next.Before(earliest) {
+			earliest = next
+		}
+	}
+	if g.prepullRun != nil && (earliest.IsZero() || g.nextPrepull.Before(earliest)) {
+		earliest = g.nextPrepull
+	}
+	return earliest, !earliest.IsZero()
+}
+
+// due returns the groups whose next run is at or before now, in name order,
+// and advances each to its following run.
+func (g *groupScheduler) due(now time.Time) []string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	var groups []string
+	for group, next := range g.next {
+		if next.After(now) {
+			continue
+		}
+		groups = append(groups, group)
+		g.next[group] = g.parsed[group].Next(now)
+	}
+	sort.Strings(groups)
+	return groups
+}
+
+// duePrepull reports whether a pre-pull is due at now and advances it to the
+// following run. The groups returned are those updated before that run.
+func (g *groupScheduler) duePrepull(now time.Time) ([]string, bool) {
+	g.mu.Lock()
+	if g.prepullRun == nil || g.nextPrepull.After(now) {
+		g.mu.Unlock()
+		return nil, false
+	}
+	g.nextPrepull = g.prepull.Next(now)
+	until := g.nextPrepull
+	g.mu.Unlock()
+
+	return g.Upcoming(now, until), true
+}
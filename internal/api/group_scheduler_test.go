@@ -0,0 +1,103 @@
+package api
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/chis/docksmith/internal/notify"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGroupScheduler_SetAndDelete(t *testing.T) {
+	ctx := context.Background()
+	g := newGroupScheduler(nil, func(context.Context, string) {})
+
+	_, err := g.Set(ctx, "media", GroupSchedule{Period: "hourly"})
+	assert.Error(t, err)
+	assert.Empty(t, g.Schedules())
+
+	schedule, err := g.Set(ctx, "media", GroupSchedule{Period: "weekly", At: "03:00", Weekday: "sun"})
+	require.NoError(t, err)
+	assert.Equal(t, "weekly on Sunday at 03:00", schedule.String())
+	assert.Contains(t, g.Schedules(), "media")
+
+	next, ok := g.NextRun("media")
+	require.True(t, ok)
+	assert.Equal(t, time.Sunday, next.Weekday())
+
+	deleted, err := g.Delete(ctx, "media")
+	require.NoError(t, err)
+	assert.True(t, deleted)
+
+	deleted, err = g.Delete(ctx, "media")
+	require.NoError(t, err)
+	assert.False(t, deleted)
+}
+
+func TestGroupScheduler_Due(t *testing.T) {
+	ctx := context.Background()
+	start := time.Date(2024, 6, 3, 8, 0, 0, 0, time.UTC) // Monday
+	g := newGroupScheduler(nil, func(context.Context, string) {})
+	g.now = func() time.Time { return start }
+
+	_, err := g.Set(ctx, "media", GroupSchedule{Period: "daily", At: "09:00"})
+	require.NoError(t, err)
+	_, err = g.Set(ctx, "infra", GroupSchedule{Period: "daily", At: "09:00"})
+	require.NoError(t, err)
+	_, err = g.Set(ctx, "backup", GroupSchedule{Period: "daily", At: "10:00"})
+	require.NoError(t, err)
+
+	wake, ok := g.nextWake()
+	require.True(t, ok)
+	assert.Equal(t, start.Add(time.Hour), wake)
+
+	assert.Empty(t, g.due(start))
+
+	// Groups due at the same time all run, and move on to the next day
+	at := start.Add(time.Hour)
+	assert.Equal(t, []string{"infra", "media"}, g.due(at))
+	next, _ := g.NextRun("media")
+	assert.Equal(t, at.AddDate(0, 0, 1), next)
+
+	assert.Equal(t, []string{"backup"}, g.due(start.Add(2*time.Hour)))
+}
+
+func TestGroupScheduler_Prepull(t *testing.T) {
+	ctx := context.Background()
+	start := time.Date(2024, 6, 3, 1, 0, 0, 0, time.UTC) // Monday
+	g := newGroupScheduler(nil, func(context.Context, string) {})
+	g.now = func() time.Time { return start }
+
+	_, err := g.Set(ctx, "media", GroupSchedule{Period: "daily", At: "04:00"})
+	require.NoError(t, err)
+	_, err = g.Set(ctx, "infra", GroupSchedule{Period: "weekly", At: "04:00", Weekday: "sunday"})
+	require.NoError(t, err)
+
+	_, ok := g.NextPrepull()
+	assert.False(t, ok, "off until set")
+	_, ok = g.duePrepull(start)
+	assert.False(t, ok)
+
+	schedule, err := notify.ParseSchedule("daily", "02:00", "")
+	require.NoError(t, err)
+	g.SetPrepull(schedule, func(context.Context, []string) {})
+
+	wake, ok := g.nextWake()
+	require.True(t, ok)
+	assert.Equal(t, start.Add(time.Hour), wake, "the pre-pull runs before the updates")
+
+	_, ok = g.duePrepull(start)
+	assert.False(t, ok)
+
+	// Only groups updated before the next pre-pull are covered
+	at := start.Add(time.Hour)
+	groups, ok := g.duePrepull(at)
+	require.True(t, ok)
+	assert.Equal(t, []string{"media"}, groups)
+	next, _ := g.NextPrepull()
+	assert.Equal(t, at.AddDate(0, 0, 1), next)
+
+	assert.Equal(t, []string{"infra", "media"}, g.Upcoming(at, at.AddDate(0, 0, 7)))
+}
@@ -0,0 +1,537 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/chis/docksmith/internal/auth"
+	"github.com/chis/docksmith/internal/events"
+	"github.com/chis/docksmith/internal/logging"
+	"github.com/chis/docksmith/internal/storage"
+	"github.com/chis/docksmith/internal/update"
+	pb "github.com/chis/docksmith/proto/docksmith/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// grpcOperationPollInterval is how often StreamEvents looks up the status of the
+// operation it follows, in case its final event was dropped
+const grpcOperationPollInterval = 5 * time.Second
+
+// grpcRoles are the minimum roles of the gRPC methods, the same as for their
+// HTTP endpoints. Methods that need more than RoleViewer change containers.
+var grpcRoles = map[string]auth.Role{
+	pb.Docksmith_Check_FullMethodName:          auth.RoleViewer,
+	pb.Docksmith_ListOperations_FullMethodName: auth.RoleViewer,
+	pb.Docksmith_GetOperation_FullMethodName:   auth.RoleViewer,
+	pb.Docksmith_StreamEvents_FullMethodName:   auth.RoleViewer,
+	pb.Docksmith_Update_FullMethodName:         auth.RoleOperator,
+	pb.Docksmith_Rollback_FullMethodName:       auth.RoleOperator,
+}
+
+// grpcPortFromEnv reads the port of the gRPC API from GRPC_PORT. Returns 0,
+// which disables the gRPC API, when unset or invalid.
+func grpcPortFromEnv() int {
+	value := os.Getenv("GRPC_PORT")
+	if value == "" {
+		return 0
+	}
+	port, err := strconv.Atoi(value)
+	if err != nil || port <= 0 || port > 65535 {
+		log.Printf("Warning: Invalid GRPC_PORT '%s', gRPC API disabled", value)
+		return 0
+	}
+	log.Printf("Using GRPC_PORT: %d", port)
+	return port
+}
+
+// grpcService implements the gRPC API with the same services as the HTTP handlers
+type grpcService struct {
+	pb.UnimplementedDocksmithServer
+	s *Server
+}
+
+// newGRPCServer creates the gRPC server of the API, authenticating calls like
+// AuthMiddleware does requests
+func (s *Server) newGRPCServer() *grpc.Server {
+	server := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			ctx, err := s.authenticateGRPC(ctx, info.FullMethod)
+			if err != nil {
+				return nil, err
+			}
+			return handler(ctx, req)
+		}),
+		grpc.ChainStreamInterceptor(func(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			ctx, err := s.authenticateGRPC(stream.Context(), info.FullMethod)
+			if err != nil {
+				return err
+			}
+			return handler(srv, &grpcContextStream{ServerStream: stream, ctx: ctx})
+		}),
+	)
+	pb.RegisterDocksmithServer(server, &grpcService{s: s})
+	return server
+}
+
+// startGRPC listens on the gRPC port and serves the gRPC API in the background
+func (s *Server) startGRPC() error {
+	listener, err := net.Listen("tcp", s.grpcAddr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", s.grpcAddr, err)
+	}
+	log.Printf("Starting gRPC server on %s", s.grpcAddr)
+	go func() {
+		if err := s.grpcServer.Serve(listener); err != nil {
+			log.Printf("GRPC: Server stopped: %v", err)
+		}
+	}()
+	return nil
+}
+
+// stopGRPC waits for running calls to finish, and cancels them once ctx is done.
+// Event streams only end when cancelled.
+func (s *Server) stopGRPC(ctx context.Context) {
+	stopped := make(chan struct{})
+	go func() {
+		s.grpcServer.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		s.grpcServer.Stop()
+	}
+}
+
+// grpcContextStream is a server stream with the context of its authenticated caller
+type grpcContextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *grpcContextStream) Context() context.Context {
+	return s.ctx
+}
+
+// authenticateGRPC resolves the API key in the metadata of a call to a principal
+// and checks its role. Returns the context to handle the call with.
+func (s *Server) authenticateGRPC(ctx context.Context, method string) (context.Context, error) {
+	role, ok := grpcRoles[method]
+	if !ok {
+		role = auth.RoleAdmin
+	}
+	if s.readOnly && role != auth.RoleViewer {
+		return nil, status.Error(codes.PermissionDenied, update.ErrReadOnly.Error())
+	}
+	if s.authMode == auth.ModeDisabled {
+		return update.WithTrigger(ctx, contextActor(ctx)), nil
+	}
+
+	var principal *auth.Principal
+	if plaintext := grpcAPIKey(ctx); plaintext != "" {
+		key, err := s.apiKeys.Authenticate(ctx, plaintext)
+		if err != nil {
+			if !errors.Is(err, auth.ErrInvalidKey) {
+				logging.WarnContext(ctx, "Credential lookup failed: %v", err)
+			}
+			return nil, status.Error(codes.Unauthenticated, auth.ErrInvalidKey.Error())
+		}
+		principal = key.Principal()
+	}
+
+	if principal == nil && s.authMode == auth.ModeOptional {
+		principal = anonymousPrincipal(ctx, s.apiKeys, s.users)
+	}
+
+	switch {
+	case principal == nil && s.authMode == auth.ModeRequired:
+		return nil, status.Error(codes.Unauthenticated, errAuthRequired.Error())
+	case principal != nil && !principal.Role.Allows(role) && principal.Anonymous():
+		return nil, status.Error(codes.Unauthenticated, errAuthRequired.Error())
+	case principal != nil && !principal.Role.Allows(role):
+		return nil, status.Error(codes.PermissionDenied, errInsufficientRole.Error())
+	case principal != nil:
+		ctx = auth.WithPrincipal(ctx, principal)
+	}
+	return update.WithTrigger(ctx, contextActor(ctx)), nil
+}
+
+// grpcAPIKey returns the API key in the metadata of a call, if any
+func grpcAPIKey(ctx context.Context) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+	for _, value := range md.Get("authorization") {
+		if token, ok := strings.CutPrefix(value, "Bearer "); ok {
+			return strings.TrimSpace(token)
+		}
+	}
+	for _, value := range md.Get("x-api-key") {
+		return strings.TrimSpace(value)
+	}
+	return ""
+}
+
+// scope returns the ownership scope of the caller, nil when unrestricted
+func (g *grpcService) scope(ctx context.Context) (*auth.OwnershipScope, error) {
+	scope, err := g.s.ownership.ScopeFor(ctx, auth.PrincipalFromContext(ctx))
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return scope, nil
+}
+
+// operation returns an update operation the caller may access
+func (g *grpcService) operation(ctx context.Context, id string) (storage.UpdateOperation, error) {
+	if g.s.storageService == nil {
+		return storage.UpdateOperation{}, status.Error(codes.Unavailable, errNoStorage.Error())
+	}
+	if id == "" {
+		return storage.UpdateOperation{}, status.Error(codes.InvalidArgument, "operation_id is required")
+	}
+	scope, err := g.scope(ctx)
+	if err != nil {
+		return storage.UpdateOperation{}, err
+	}
+	op, found, err := g.s.storageService.GetUpdateOperation(ctx, id)
+	if err != nil {
+		return storage.UpdateOperation{}, status.Error(codes.Internal, err.Error())
+	}
+	if !found || (scope != nil && !allowsOperation(scope, g.s.containerStacks(ctx), op)) {
+		return storage.UpdateOperation{}, status.Error(codes.NotFound, "operation not found")
+	}
+	return op, nil
+}
+
+// changesAllowed returns an error when containers cannot be changed through the API
+func (g *grpcService) changesAllowed(ctx context.Context) error {
+	if g.s.updateOrchestrator == nil {
+		return status.Error(codes.Unavailable, errNoUpdateOrchestrator.Error())
+	}
+	if g.s.proposals != nil && g.s.proposals.Enabled(ctx) {
+		return status.Error(codes.FailedPrecondition, errProposeOnly.Error())
+	}
+	return nil
+}
+
+// Check returns the cached check result, or runs a new check
+func (g *grpcService) Check(ctx context.Context, req *pb.CheckRequest) (*pb.CheckResponse, error) {
+	resp := &pb.CheckResponse{}
+	var result *update.DiscoveryResult
+	checkedAt := time.Now()
+	if g.s.backgroundChecker != nil {
+		cached, _, lastRun, checking := g.s.backgroundChecker.GetCachedResults()
+		resp.Checking = checking
+		if !req.GetRefresh() && cached != nil {
+			result, checkedAt = cached, lastRun
+		}
+	}
+	if result == nil {
+		var err error
+		if result, err = g.s.checkResult(ctx, true); err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+	}
+
+	scope, err := g.scope(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result = scopeResult(scope, result)
+
+	for _, c := range result.Containers {
+		resp.Containers = append(resp.Containers, &pb.Container{
+			Name:           c.ContainerName,
+			Id:             c.ID,
+			Image:          c.Image,
+			Stack:          c.Stack,
+			Service:        c.Service,
+			CurrentVersion: c.CurrentVersion,
+			LatestVersion:  c.LatestVersion,
+			Status:         string(c.Status),
+			ChangeType:     c.ChangeType.String(),
+			Error:          c.Error,
+			RecommendedTag: c.RecommendedTag,
+			HealthStatus:   c.HealthStatus,
+			Groups:         c.Groups,
+		})
+	}
+	resp.TotalChecked = int32(result.TotalChecked)
+	resp.UpdatesFound = int32(result.UpdatesFound)
+	resp.UpToDate = int32(result.UpToDate)
+	resp.LocalImages = int32(result.LocalImages)
+	resp.Failed = int32(result.Failed)
+	resp.Ignored = int32(result.Ignored)
+	if !checkedAt.IsZero() {
+		resp.CheckedAt = timestamppb.New(checkedAt)
+	}
+	return resp, nil
+}
+
+// Update starts the update of a container, like POST /api/update
+func (g *grpcService) Update(ctx context.Context, req *pb.UpdateRequest) (*pb.UpdateResponse, error) {
+	if err := g.changesAllowed(ctx); err != nil {
+		return nil, err
+	}
+	name := req.GetContainerName()
+	if name == "" {
+		return nil, status.Error(codes.InvalidArgument, "container_name is required")
+	}
+	scope, err := g.scope(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if scope != nil && !scope.Allows(g.s.containerStacks(ctx)[name], name) {
+		return nil, status.Errorf(codes.NotFound, "container '%s' not found", name)
+	}
+	if g.s.approvals != nil && g.s.approvals.Required(ctx, name, req.GetTargetVersion()) {
+		return nil, status.Error(codes.FailedPrecondition, errApprovalRequired(name).Error())
+	}
+
+	operationID, err := g.s.updateOrchestrator.UpdateSingleContainer(ctx, name, req.GetTargetVersion())
+	if err != nil {
+		return nil, grpcOrchestratorError(err)
+	}
+	return &pb.UpdateResponse{OperationId: operationID}, nil
+}
+
+// Rollback starts the rollback of an update operation, like POST /api/rollback
+func (g *grpcService) Rollback(ctx context.Context, req *pb.RollbackRequest) (*pb.RollbackResponse, error) {
+	if err := g.changesAllowed(ctx); err != nil {
+		return nil, err
+	}
+	if _, err := g.operation(ctx, req.GetOperationId()); err != nil {
+		return nil, err
+	}
+
+	operationID, err := g.s.updateOrchestrator.RollbackOperation(ctx, req.GetOperationId(), req.GetForce())
+	if err != nil {
+		log.Printf("Rollback failed: %v", err)
+		return nil, grpcOrchestratorError(err)
+	}
+	return &pb.RollbackResponse{OperationId: operationID}, nil
+}
+
+// ListOperations returns a page of update operations, like GET /api/operations
+func (g *grpcService) ListOperations(ctx context.Context, req *pb.ListOperationsRequest) (*pb.ListOperationsResponse, error) {
+	if g.s.storageService == nil {
+		return nil, status.Error(codes.Unavailable, errNoStorage.Error())
+	}
+	limit := int(req.GetLimit())
+	if limit <= 0 {
+		limit = 20
+	}
+	result, err := g.s.storageService.QueryUpdateOperations(ctx, storage.OperationQueryOptions{
+		Limit:     limit,
+		Cursor:    req.GetCursor(),
+		Status:    req.GetStatus(),
+		Container: req.GetContainer(),
+		Type:      req.GetType(),
+	})
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	scope, err := g.scope(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &pb.ListOperationsResponse{HasMore: result.HasMore, NextCursor: result.NextCursor}
+	for _, op := range g.s.scopeOperations(ctx, scope, result.Operations) {
+		resp.Operations = append(resp.Operations, operationProto(op))
+	}
+	return resp, nil
+}
+
+// GetOperation returns an update operation, like GET /api/operations/{id}
+func (g *grpcService) GetOperation(ctx context.Context, req *pb.GetOperationRequest) (*pb.Operation, error) {
+	op, err := g.operation(ctx, req.GetOperationId())
+	if err != nil {
+		return nil, err
+	}
+	return operationProto(op), nil
+}
+
+// StreamEvents sends events like GET /api/events. When following an operation,
+// the stream ends once the operation completes or fails.
+func (g *grpcService) StreamEvents(req *pb.StreamEventsRequest, stream grpc.ServerStreamingServer[pb.Event]) error {
+	ctx := stream.Context()
+	scope, err := g.scope(ctx)
+	if err != nil {
+		return err
+	}
+
+	operationID := req.GetOperationId()
+	finished := func() bool { return false }
+	if operationID != "" {
+		if _, err := g.operation(ctx, operationID); err != nil {
+			return err
+		}
+		finished = func() bool {
+			op, found, err := g.s.storageService.GetUpdateOperation(ctx, operationID)
+			return err == nil && (!found || operationFinished(op.Status))
+		}
+	}
+
+	types := make(map[string]bool, len(req.GetTypes()))
+	for _, t := range req.GetTypes() {
+		types[t] = true
+	}
+	var stacks map[string]string
+	if scope != nil {
+		stacks = g.s.containerStacks(ctx)
+	}
+	send := func(event events.Event) error {
+		if len(types) > 0 && !types[event.Type] {
+			return nil
+		}
+		if id, _ := event.Payload["operation_id"].(string); operationID != "" && id != operationID {
+			return nil
+		}
+		if !eventAllowed(scope, stacks, event) {
+			return nil
+		}
+		msg, err := eventProto(event)
+		if err != nil {
+			log.Printf("GRPC: Failed to convert %s event: %v", event.Type, err)
+			return nil
+		}
+		return stream.Send(msg)
+	}
+
+	// Subscribe before replaying, so nothing published meanwhile is lost
+	eventChan, unsubscribe := g.s.eventBus.Subscribe("*")
+	defer unsubscribe()
+
+	sentID := req.GetLastEventId()
+	if sentID > 0 {
+		missed, _ := g.s.eventBus.Since(sentID)
+		for _, event := range missed {
+			if err := send(event); err != nil {
+				return err
+			}
+			sentID = event.ID
+		}
+	}
+	if finished() {
+		return nil
+	}
+
+	poll := time.NewTicker(grpcOperationPollInterval)
+	defer poll.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-poll.C:
+			if finished() {
+				return nil
+			}
+		case event, ok := <-eventChan:
+			if !ok {
+				return nil
+			}
+			if event.ID != 0 && event.ID <= sentID {
+				continue // Already replayed
+			}
+			if err := send(event); err != nil {
+				return err
+			}
+			id, _ := event.Payload["operation_id"].(string)
+			if stage, _ := event.Payload["stage"].(string); id == operationID && operationFinished(stage) && finished() {
+				return nil
+			}
+		}
+	}
+}
+
+// operationFinished reports whether an operation status or progress stage is final
+func operationFinished(status string) bool {
+	return status == storage.StatusComplete || status == storage.StatusFailed || status == "cancelled"
+}
+
+// grpcOrchestratorError maps orchestrator errors to gRPC status codes, like
+// RespondOrchestratorError does to HTTP status codes
+func grpcOrchestratorError(err error) error {
+	var notFoundErr *update.NotFoundError
+	var badReqErr *update.BadRequestError
+	switch {
+	case errors.Is(err, update.ErrReadOnly):
+		return status.Error(codes.PermissionDenied, err.Error())
+	case errors.As(err, &notFoundErr):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.As(err, &badReqErr):
+		return status.Error(codes.InvalidArgument, err.Error())
+	default:
+		return status.Error(codes.Internal, err.Error())
+	}
+}
+
+// operationProto converts an update operation to its gRPC message
+func operationProto(op storage.UpdateOperation) *pb.Operation {
+	msg := &pb.Operation{
+		OperationId:      op.OperationID,
+		ContainerName:    op.ContainerName,
+		StackName:        op.StackName,
+		OperationType:    op.OperationType,
+		Status:           op.Status,
+		OldVersion:       op.OldVersion,
+		NewVersion:       op.NewVersion,
+		ErrorMessage:     op.ErrorMessage,
+		RollbackOccurred: op.RollbackOccurred,
+		BatchGroupId:     op.BatchGroupID,
+		TriggeredBy:      op.TriggeredBy,
+		CreatedAt:        timestamppb.New(op.CreatedAt),
+	}
+	for _, detail := range op.BatchDetails {
+		msg.Containers = append(msg.Containers, detail.ContainerName)
+	}
+	if op.StartedAt != nil {
+		msg.StartedAt = timestamppb.New(*op.StartedAt)
+	}
+	if op.CompletedAt != nil {
+		msg.CompletedAt = timestamppb.New(*op.CompletedAt)
+	}
+	return msg
+}
+
+// eventProto converts an event to its gRPC message. The payload goes through
+// JSON, as it does for the HTTP API, since it may hold any JSON-encodable value.
+func eventProto(event events.Event) (*pb.Event, error) {
+	data, err := json.Marshal(event.Payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode payload: %w", err)
+	}
+	payload := &structpb.Struct{}
+	if err := payload.UnmarshalJSON(data); err != nil {
+		return nil, fmt.Errorf("failed to decode payload: %w", err)
+	}
+
+	msg := &pb.Event{Id: event.ID, Type: event.Type, Payload: payload}
+	if event.Type == events.EventUpdateProgress {
+		fields := payload.GetFields()
+		percent := fields["progress"]
+		if percent == nil {
+			percent = fields["percent"]
+		}
+		msg.Progress = &pb.UpdateProgress{
+			OperationId:   fields["operation_id"].GetStringValue(),
+			ContainerName: fields["container_name"].GetStringValue(),
+			Stage:         fields["stage"].GetStringValue(),
+			Percent:       int32(percent.GetNumberValue()),
+			Message:       fields["message"].GetStringValue(),
+		}
+	}
+	return msg, nil
+}
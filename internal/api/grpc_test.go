@@ -0,0 +1,244 @@
+package api
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/chis/docksmith/internal/auth"
+	"github.com/chis/docksmith/internal/events"
+	"github.com/chis/docksmith/internal/storage"
+	pb "github.com/chis/docksmith/proto/docksmith/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// newGRPCTestClient serves the gRPC API of s in memory and returns a client for it
+func newGRPCTestClient(t *testing.T, s *Server) pb.DocksmithClient {
+	t.Helper()
+	listener := bufconn.Listen(1 << 20)
+	server := s.newGRPCServer()
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return pb.NewDocksmithClient(conn)
+}
+
+func withAPIKey(ctx context.Context, key string) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+key)
+}
+
+func TestGRPC_Auth(t *testing.T) {
+	ctx := context.Background()
+	store := NewMockStorage()
+	keys := auth.NewKeyStore(store)
+	reader, _, err := keys.Create(ctx, "reader", auth.ScopeRead)
+	require.NoError(t, err)
+	writer, _, err := keys.Create(ctx, "writer", auth.ScopeUpdate)
+	require.NoError(t, err)
+	require.NoError(t, store.SaveUpdateOperation(ctx, storage.UpdateOperation{OperationID: "op-1", ContainerName: "web", Status: storage.StatusComplete}))
+
+	c := newGRPCTestClient(t, &Server{storageService: store, apiKeys: keys, authMode: auth.ModeRequired, eventBus: events.NewBus()})
+
+	tests := []struct {
+		name string
+		call func(ctx context.Context) error
+		key  string
+		want codes.Code
+	}{
+		{"anonymous rejected", func(ctx context.Context) error {
+			_, err := c.GetOperation(ctx, &pb.GetOperationRequest{OperationId: "op-1"})
+			return err
+		}, "", codes.Unauthenticated},
+		{"invalid key rejected", func(ctx context.Context) error {
+			_, err := c.GetOperation(ctx, &pb.GetOperationRequest{OperationId: "op-1"})
+			return err
+		}, "dsk_bogus", codes.Unauthenticated},
+		{"viewer can read", func(ctx context.Context) error {
+			_, err := c.GetOperation(ctx, &pb.GetOperationRequest{OperationId: "op-1"})
+			return err
+		}, reader, codes.OK},
+		{"viewer cannot update", func(ctx context.Context) error {
+			_, err := c.Update(ctx, &pb.UpdateRequest{ContainerName: "web"})
+			return err
+		}, reader, codes.PermissionDenied},
+		{"viewer cannot roll back", func(ctx context.Context) error {
+			_, err := c.Rollback(ctx, &pb.RollbackRequest{OperationId: "op-1"})
+			return err
+		}, reader, codes.PermissionDenied},
+		{"operator can update", func(ctx context.Context) error {
+			_, err := c.Update(ctx, &pb.UpdateRequest{ContainerName: "web"})
+			return err
+		}, writer, codes.Unavailable}, // No orchestrator in this test
+		{"viewer cannot stream unknown operations", func(ctx context.Context) error {
+			stream, err := c.StreamEvents(ctx, &pb.StreamEventsRequest{OperationId: "missing"})
+			if err != nil {
+				return err
+			}
+			_, err = stream.Recv()
+			return err
+		}, reader, codes.NotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.key != "" {
+				ctx = withAPIKey(ctx, tt.key)
+			}
+			assert.Equal(t, tt.want, status.Code(tt.call(ctx)))
+		})
+	}
+}
+
+func TestGRPC_AnonymousViewer(t *testing.T) {
+	ctx := context.Background()
+	store := NewMockStorage()
+	keys := auth.NewKeyStore(store)
+	_, _, err := keys.Create(ctx, "reader", auth.ScopeRead)
+	require.NoError(t, err)
+	require.NoError(t, store.SaveUpdateOperation(ctx, storage.UpdateOperation{OperationID: "op-1", ContainerName: "web", Status: storage.StatusComplete}))
+
+	c := newGRPCTestClient(t, &Server{storageService: store, apiKeys: keys, users: auth.NewUserService(store), authMode: auth.ModeOptional, eventBus: events.NewBus()})
+
+	// Once API keys exist, calls without one are limited to viewing
+	_, err = c.GetOperation(ctx, &pb.GetOperationRequest{OperationId: "op-1"})
+	assert.Equal(t, codes.OK, status.Code(err))
+	_, err = c.Update(ctx, &pb.UpdateRequest{ContainerName: "web"})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
+
+func TestGRPC_ReadOnly(t *testing.T) {
+	c := newGRPCTestClient(t, &Server{readOnly: true, eventBus: events.NewBus()})
+
+	_, err := c.Update(context.Background(), &pb.UpdateRequest{ContainerName: "web"})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+}
+
+func TestGRPC_Operations(t *testing.T) {
+	ctx := context.Background()
+	store := NewMockStorage()
+	started := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	require.NoError(t, store.SaveUpdateOperation(ctx, storage.UpdateOperation{
+		OperationID:   "op-1",
+		ContainerName: "web",
+		OperationType: "single",
+		Status:        storage.StatusComplete,
+		OldVersion:    "1.0.0",
+		NewVersion:    "1.1.0",
+		TriggeredBy:   "api_key:ci",
+		StartedAt:     &started,
+	}))
+	require.NoError(t, store.SaveUpdateOperation(ctx, storage.UpdateOperation{OperationID: "op-2", ContainerName: "db", Status: storage.StatusFailed}))
+
+	c := newGRPCTestClient(t, &Server{storageService: store, eventBus: events.NewBus()})
+
+	op, err := c.GetOperation(ctx, &pb.GetOperationRequest{OperationId: "op-1"})
+	require.NoError(t, err)
+	assert.Equal(t, "web", op.ContainerName)
+	assert.Equal(t, "1.1.0", op.NewVersion)
+	assert.Equal(t, "api_key:ci", op.TriggeredBy)
+	assert.Equal(t, started, op.StartedAt.AsTime())
+	assert.Nil(t, op.CompletedAt)
+
+	_, err = c.GetOperation(ctx, &pb.GetOperationRequest{OperationId: "missing"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	_, err = c.GetOperation(ctx, &pb.GetOperationRequest{})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	list, err := c.ListOperations(ctx, &pb.ListOperationsRequest{Status: storage.StatusFailed})
+	require.NoError(t, err)
+	require.Len(t, list.Operations, 1)
+	assert.Equal(t, "op-2", list.Operations[0].OperationId)
+	assert.False(t, list.HasMore)
+}
+
+func TestGRPC_StreamEvents_FollowsOperation(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	store := NewMockStorage()
+	op := storage.UpdateOperation{OperationID: "op-1", ContainerName: "web", Status: "pulling_image"}
+	require.NoError(t, store.SaveUpdateOperation(ctx, op))
+
+	bus := events.NewBus()
+	bus.Publish(events.Event{Type: events.EventUpdateProgress, Payload: map[string]interface{}{
+		"operation_id": "op-1", "container_name": "web", "stage": "pulling_image", "progress": 20, "message": "Pulling image",
+	}})
+	since := bus.LastID() - 1
+
+	c := newGRPCTestClient(t, &Server{storageService: store, eventBus: bus})
+	stream, err := c.StreamEvents(ctx, &pb.StreamEventsRequest{OperationId: "op-1", LastEventId: since})
+	require.NoError(t, err)
+
+	// The missed event is replayed
+	event, err := stream.Recv()
+	require.NoError(t, err)
+	require.NotNil(t, event.Progress)
+	assert.Equal(t, "pulling_image", event.Progress.Stage)
+	assert.Equal(t, int32(20), event.Progress.Percent)
+	assert.Equal(t, "web", event.Payload.GetFields()["container_name"].GetStringValue())
+
+	// Events of other operations are skipped, and the stream ends with the operation
+	bus.Publish(events.Event{Type: events.EventUpdateProgress, Payload: map[string]interface{}{"operation_id": "op-2", "stage": "complete"}})
+	op.Status = storage.StatusComplete
+	require.NoError(t, store.SaveUpdateOperation(ctx, op))
+	bus.Publish(events.Event{Type: events.EventUpdateProgress, Payload: map[string]interface{}{
+		"operation_id": "op-1", "container_name": "web", "stage": "complete", "progress": 100,
+	}})
+
+	event, err = stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, "complete", event.Progress.Stage)
+	assert.Equal(t, "op-1", event.Progress.OperationId)
+
+	_, err = stream.Recv()
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestGRPC_StreamEvents_FiltersTypes(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	bus := events.NewBus()
+
+	c := newGRPCTestClient(t, &Server{eventBus: bus})
+	stream, err := c.StreamEvents(ctx, &pb.StreamEventsRequest{Types: []string{events.EventContainerUpdated}})
+	require.NoError(t, err)
+
+	// The subscription starts with the call, so publish until the event arrives
+	received := make(chan *pb.Event, 1)
+	go func() {
+		event, err := stream.Recv()
+		if err == nil {
+			received <- event
+		}
+	}()
+	for {
+		bus.Publish(events.Event{Type: events.EventUpdateProgress, Payload: map[string]interface{}{"stage": "pulling_image"}})
+		bus.Publish(events.Event{Type: events.EventContainerUpdated, Payload: map[string]interface{}{"container_name": "web"}})
+		select {
+		case event := <-received:
+			assert.Equal(t, events.EventContainerUpdated, event.Type)
+			assert.Nil(t, event.Progress)
+			return
+		case <-time.After(20 * time.Millisecond):
+		case <-ctx.Done():
+			t.Fatal("no event received")
+		}
+	}
+}
@@ -1,25 +1,56 @@
 package api
 
 import (
+	"cmp"
+	"context"
+	"encoding/json"
 	"fmt"
 	"log"
 	"net/http"
+	"slices"
+	"strconv"
 	"time"
 
+	"github.com/chis/docksmith/internal/approval"
 	"github.com/chis/docksmith/internal/events"
+	"github.com/chis/docksmith/internal/proposal"
+	"github.com/chis/docksmith/internal/registry"
 	"github.com/chis/docksmith/internal/storage"
+	"github.com/chis/docksmith/internal/update"
 	"github.com/google/uuid"
 )
 
-// handleHealth returns server health status
+// handleHealth returns server health status. The server is alive whenever it
+// answers; with ?deep=true the readiness checks are included as well.
 func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
-	RespondSuccess(w, map[string]any{
+	health := map[string]any{
 		"status": "healthy",
 		"services": map[string]bool{
 			"docker":  s.dockerService != nil,
 			"storage": s.storageService != nil,
 		},
-	})
+		"auth_mode":    s.authMode,
+		"oidc":         s.oidc != nil,
+		"propose_only": s.proposals != nil && s.proposals.Enabled(r.Context()),
+		"read_only":    s.readOnly,
+	}
+
+	// Docker Hub quota, once Docker Hub has reported one
+	if s.registryManager != nil {
+		if quota, ok := s.registryManager.DockerHubQuota(); ok {
+			health["registry_quota"] = map[string]registry.RateLimitStatus{"docker.io": quota}
+		}
+	}
+
+	if r.URL.Query().Get("deep") == "true" {
+		checks := s.runHealthChecks(r.Context())
+		health["checks"] = checks
+		if checksStatus(checks) != checkOK {
+			health["status"] = "degraded"
+		}
+	}
+
+	RespondSuccess(w, health)
 }
 
 // handleCheck performs container discovery and update checking
@@ -31,6 +62,9 @@ func (s *Server) handleCheck(w http.ResponseWriter, r *http.Request) {
 	if s.backgroundChecker != nil {
 		// Clear cache to force fresh registry queries
 		s.discoveryOrchestrator.ClearCache()
+		if s.registryManager != nil {
+			s.registryManager.InvalidateTagCache()
+		}
 		// Mark that cache was cleared so timestamp gets updated
 		s.backgroundChecker.MarkCacheCleared()
 		s.backgroundChecker.TriggerCheck()
@@ -47,9 +81,13 @@ func (s *Server) handleCheck(w http.ResponseWriter, r *http.Request) {
 		RespondInternalError(w, err)
 		return
 	}
+	scope, ok := s.requestScope(w, r)
+	if !ok {
+		return
+	}
 
 	// Return identical JSON structure as CLI
-	RespondSuccess(w, result)
+	RespondSuccess(w, scopeResult(scope, result))
 }
 
 // handleTriggerCheck triggers a background check without clearing cache
@@ -133,17 +171,26 @@ func (s *Server) handleOperations(w http.ResponseWriter, r *http.Request) {
 		RespondInternalError(w, err)
 		return
 	}
-
-	response := map[string]any{
-		"operations": result.Operations,
-		"count":      len(result.Operations),
-		"has_more":   result.HasMore,
-	}
-	if result.NextCursor != "" {
-		response["next_cursor"] = result.NextCursor
+	scope, ok := s.requestScope(w, r)
+	if !ok {
+		return
 	}
+	result.Operations = s.scopeOperations(ctx, scope, result.Operations)
+
+	RespondSuccess(w, OperationsResponse{
+		Operations: result.Operations,
+		Count:      len(result.Operations),
+		HasMore:    result.HasMore,
+		NextCursor: result.NextCursor,
+	})
+}
 
-	RespondSuccess(w, response)
+// OperationsResponse is a page of update operations
+type OperationsResponse struct {
+	Operations []storage.UpdateOperation `json:"operations"`
+	Count      int                       `json:"count"`
+	HasMore    bool                      `json:"has_more"`
+	NextCursor string                    `json:"next_cursor,omitempty"` // Cursor of the next page, if HasMore
 }
 
 // handleClearHistory deletes operation history
@@ -205,6 +252,209 @@ func (s *Server) handleOperationByID(w http.ResponseWriter, r *http.Request) {
 	RespondSuccess(w, operation)
 }
 
+// handlePauseOperation requests that a running update pause after pulling
+// images and before recreating containers
+// POST /api/operations/{id}/pause
+func (s *Server) handlePauseOperation(w http.ResponseWriter, r *http.Request) {
+	if !s.requireUpdateOrchestrator(w) {
+		return
+	}
+
+	operationID := r.PathValue("id")
+	if err := s.updateOrchestrator.PauseOperation(r.Context(), operationID); err != nil {
+		RespondOrchestratorError(w, err)
+		return
+	}
+
+	RespondSuccess(w, map[string]any{
+		"operation_id": operationID,
+		"status":       "pause_requested",
+		"message":      "Operation will pause after pulling images",
+	})
+}
+
+// handleResumeOperation resumes a paused update
+// POST /api/operations/{id}/resume
+func (s *Server) handleResumeOperation(w http.ResponseWriter, r *http.Request) {
+	if !s.requireUpdateOrchestrator(w) {
+		return
+	}
+
+	operationID := r.PathValue("id")
+	if err := s.updateOrchestrator.ResumeOperation(r.Context(), operationID); err != nil {
+		log.Printf("Resume failed for operation %s: %v", operationID, err)
+		RespondOrchestratorError(w, err)
+		return
+	}
+
+	RespondSuccess(w, map[string]any{
+		"operation_id": operationID,
+		"status":       "in_progress",
+		"message":      "Operation resumed",
+	})
+}
+
+// handleVolumeSnapshots returns the volume snapshots taken by an operation
+// GET /api/operations/{id}/volume-snapshots
+func (s *Server) handleVolumeSnapshots(w http.ResponseWriter, r *http.Request) {
+	if !s.requireStorage(w) {
+		return
+	}
+
+	snapshots, err := s.storageService.GetVolumeSnapshots(r.Context(), r.PathValue("id"))
+	if err != nil {
+		RespondInternalError(w, err)
+		return
+	}
+
+	RespondSuccess(w, map[string]any{
+		"snapshots": snapshots,
+		"count":     len(snapshots),
+	})
+}
+
+// handleOperationLogs streams the step log of an operation as SSE: the stored lines
+// first, then new lines until the operation completes or fails. ?follow=false
+// returns the stored lines as JSON instead.
+// GET /api/operations/{id}/logs
+func (s *Server) handleOperationLogs(w http.ResponseWriter, r *http.Request) {
+	if !s.requireStorage(w) {
+		return
+	}
+
+	ctx := r.Context()
+	operationID := r.PathValue("id")
+	operation, found, err := s.storageService.GetUpdateOperation(ctx, operationID)
+	if err != nil {
+		RespondInternalError(w, err)
+		return
+	}
+	if !found {
+		RespondNotFound(w, fmt.Errorf("operation not found"))
+		return
+	}
+
+	var afterID int64
+	if value := cmp.Or(r.Header.Get("Last-Event-ID"), r.URL.Query().Get("after")); value != "" {
+		afterID, err = strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			RespondBadRequest(w, fmt.Errorf("invalid log ID: %s", value))
+			return
+		}
+	}
+
+	if r.URL.Query().Get("follow") == "false" {
+		logs, err := s.storageService.GetOperationLogs(ctx, operationID, afterID)
+		if err != nil {
+			RespondInternalError(w, err)
+			return
+		}
+		RespondSuccess(w, map[string]any{
+			"operation_id": operationID,
+			"status":       operation.Status,
+			"logs":         logs,
+			"count":        len(logs),
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "SSE not supported", http.StatusInternalServerError)
+		return
+	}
+	http.NewResponseController(w).SetWriteDeadline(time.Time{})
+
+	// Subscribe before reading the stored lines so none are missed in between
+	var eventChan events.Subscriber
+	if s.eventBus != nil {
+		var unsubscribe func()
+		eventChan, unsubscribe = s.eventBus.Subscribe(events.EventOperationLog)
+		defer unsubscribe()
+	}
+
+	// sendLogs writes the lines stored since the last one sent, and ends the
+	// stream with a done event once the operation has finished
+	sendLogs := func() bool {
+		logs, err := s.storageService.GetOperationLogs(ctx, operationID, afterID)
+		if err != nil {
+			log.Printf("Failed to read logs of operation %s: %v", operationID, err)
+			return false
+		}
+		for _, entry := range logs {
+			data, _ := json.Marshal(entry)
+			fmt.Fprintf(w, "id: %d\nevent: log\ndata: %s\n\n", entry.ID, data)
+			afterID = entry.ID
+		}
+
+		done := false
+		if op, found, err := s.storageService.GetUpdateOperation(ctx, operationID); err == nil && found &&
+			(op.Status == storage.StatusComplete || op.Status == storage.StatusFailed) {
+			fmt.Fprintf(w, "event: done\ndata: {\"status\":%q}\n\n", op.Status)
+			done = true
+		}
+		flusher.Flush()
+		return done
+	}
+	if sendLogs() {
+		return
+	}
+
+	heartbeat := time.NewTicker(15 * time.Second)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-heartbeat.C:
+			// Also catches operations finished without a final log line
+			if sendLogs() {
+				return
+			}
+			fmt.Fprintf(w, ": keepalive\n\n")
+			flusher.Flush()
+		case event, ok := <-eventChan:
+			if !ok {
+				return
+			}
+			if event.Payload["operation_id"] != operationID {
+				continue
+			}
+			if sendLogs() {
+				return
+			}
+		}
+	}
+}
+
+// handleRestoreVolumes restores the volume snapshots taken by an operation
+// POST /api/operations/{id}/restore-volumes
+func (s *Server) handleRestoreVolumes(w http.ResponseWriter, r *http.Request) {
+	if !s.requireUpdateOrchestrator(w) {
+		return
+	}
+
+	operationID := r.PathValue("id")
+	restoreOpID, err := s.updateOrchestrator.RestoreVolumes(r.Context(), operationID)
+	if err != nil {
+		log.Printf("Volume restore failed for operation %s: %v", operationID, err)
+		RespondOrchestratorError(w, err)
+		return
+	}
+
+	RespondSuccess(w, map[string]any{
+		"operation_id":          restoreOpID,
+		"snapshot_operation_id": operationID,
+		"status":                "started",
+	})
+}
+
 // handleHistory returns unified check and update history
 // This is the EXACT same logic as: docksmith history --json
 func (s *Server) handleHistory(w http.ResponseWriter, r *http.Request) {
@@ -241,12 +491,135 @@ func (s *Server) handleHistory(w http.ResponseWriter, r *http.Request) {
 	// Convert to unified format - same as CLI history command
 	entries := mergeHistory(checkHistory, updateLog)
 
+	scope, ok := s.requestScope(w, r)
+	if !ok {
+		return
+	}
+	if scope != nil {
+		stacks := s.containerStacks(ctx)
+		entries = slices.DeleteFunc(entries, func(e HistoryEntry) bool {
+			return !scope.Allows(stacks[e.ContainerName], e.ContainerName)
+		})
+	}
+
 	RespondSuccess(w, map[string]any{
 		"history": entries,
 		"count":   len(entries),
 	})
 }
 
+// handleHistoryTimeline returns the filtered check and update timeline
+// This is the same query as: docksmith history
+func (s *Server) handleHistoryTimeline(w http.ResponseWriter, r *http.Request) {
+	if !s.requireStorage(w) {
+		return
+	}
+
+	query := r.URL.Query()
+	opts := storage.TimelineQueryOptions{
+		Container: query.Get("container"),
+		Stack:     query.Get("stack"),
+		Status:    query.Get("status"),
+		Type:      query.Get("type"),
+		Limit:     parseIntParam(r, "limit", 50),
+	}
+
+	var err error
+	if opts.DateFrom, opts.DateTo, err = parseDateRange(r); err != nil {
+		RespondBadRequest(w, err)
+		return
+	}
+
+	entries, err := storage.QueryTimeline(r.Context(), s.storageService, opts)
+	if err != nil {
+		RespondInternalError(w, err)
+		return
+	}
+	scope, ok := s.requestScope(w, r)
+	if !ok {
+		return
+	}
+	if scope != nil {
+		stacks := s.containerStacks(r.Context())
+		entries = slices.DeleteFunc(entries, func(e storage.TimelineEntry) bool {
+			return !scope.Allows(cmp.Or(e.Stack, stacks[e.ContainerName]), e.ContainerName)
+		})
+	}
+
+	RespondSuccess(w, TimelineResponse{History: entries, Count: len(entries)})
+}
+
+// TimelineResponse is the check and update timeline, newest first
+type TimelineResponse struct {
+	History []storage.TimelineEntry `json:"history"`
+	Count   int                     `json:"count"`
+}
+
+// handleHistoryExport downloads the update audit log for a date range as CSV or JSON lines
+// GET /api/history/export?format=csv|jsonl&date_from=...&date_to=...
+func (s *Server) handleHistoryExport(w http.ResponseWriter, r *http.Request) {
+	if !s.requireStorage(w) {
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = storage.AuditFormatCSV
+	}
+	contentType := map[string]string{
+		storage.AuditFormatCSV:   "text/csv; charset=utf-8",
+		storage.AuditFormatJSONL: "application/x-ndjson",
+	}[format]
+	if contentType == "" {
+		RespondBadRequest(w, fmt.Errorf("invalid format %q: expected csv or jsonl", format))
+		return
+	}
+
+	from, to, err := parseDateRange(r)
+	if err != nil {
+		RespondBadRequest(w, err)
+		return
+	}
+
+	records, err := storage.QueryAuditLog(r.Context(), s.storageService, from, to)
+	if err != nil {
+		RespondInternalError(w, err)
+		return
+	}
+	scope, ok := s.requestScope(w, r)
+	if !ok {
+		return
+	}
+	if scope != nil {
+		stacks := s.containerStacks(r.Context())
+		records = slices.DeleteFunc(records, func(rec storage.AuditRecord) bool {
+			return !scope.Allows(cmp.Or(rec.Stack, stacks[rec.Container]), rec.Container)
+		})
+	}
+
+	filename := "docksmith-audit-" + time.Now().Format("2006-01-02") + "." + format
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	if err := storage.WriteAuditLog(w, format, records); err != nil {
+		log.Printf("Failed to write audit log export: %v", err)
+	}
+}
+
+// parseDateRange reads the optional date_from and date_to RFC3339 query parameters
+func parseDateRange(r *http.Request) (*time.Time, *time.Time, error) {
+	var bounds [2]*time.Time
+	for i, param := range []string{"date_from", "date_to"} {
+		if value := r.URL.Query().Get(param); value != "" {
+			t, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				return nil, nil, fmt.Errorf("invalid %s: expected RFC3339 timestamp", param)
+			}
+			bounds[i] = &t
+		}
+	}
+	return bounds[0], bounds[1], nil
+}
+
 // handlePolicies returns rollback policies
 func (s *Server) handlePolicies(w http.ResponseWriter, r *http.Request) {
 	if !s.requireStorage(w) {
@@ -262,8 +635,31 @@ func (s *Server) handlePolicies(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	approvalPolicies, err := s.storageService.ListApprovalPolicies(ctx)
+	if err != nil {
+		RespondInternalError(w, err)
+		return
+	}
+	scope, ok := s.requestScope(w, r)
+	if !ok {
+		return
+	}
+	if scope != nil {
+		stacks := s.containerStacks(ctx)
+		approvalPolicies = slices.DeleteFunc(approvalPolicies, func(p storage.ApprovalPolicy) bool {
+			switch p.EntityType {
+			case "stack":
+				return !scope.Allows(p.EntityID, "")
+			case "container":
+				return !scope.Allows(stacks[p.EntityID], p.EntityID)
+			}
+			return false
+		})
+	}
+
 	RespondSuccess(w, map[string]any{
-		"global_policy": globalPolicy,
+		"global_policy":     globalPolicy,
+		"approval_policies": approvalPolicies,
 	})
 }
 
@@ -277,11 +673,7 @@ func (s *Server) handleUpdate(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
 	// Parse request body
-	var req struct {
-		ContainerName string `json:"container_name"`
-		TargetVersion string `json:"target_version"`
-	}
-
+	var req UpdateRequest
 	if !decodeJSONRequest(w, r, &req) {
 		return
 	}
@@ -289,6 +681,14 @@ func (s *Server) handleUpdate(w http.ResponseWriter, r *http.Request) {
 	if !validateRequired(w, "container_name", req.ContainerName) {
 		return
 	}
+	if !s.allowContainers(w, r, req.ContainerName) {
+		return
+	}
+
+	if s.approvals != nil && s.approvals.Required(ctx, req.ContainerName, req.TargetVersion) {
+		RespondError(w, http.StatusConflict, errApprovalRequired(req.ContainerName))
+		return
+	}
 
 	// Start update - same function as CLI
 	operationID, err := s.updateOrchestrator.UpdateSingleContainer(ctx, req.ContainerName, req.TargetVersion)
@@ -297,14 +697,54 @@ func (s *Server) handleUpdate(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	RespondSuccess(w, map[string]any{
-		"operation_id":   operationID,
-		"container_name": req.ContainerName,
-		"target_version": req.TargetVersion,
-		"status":         "started",
+	RespondSuccess(w, UpdateResponse{
+		OperationID:   operationID,
+		ContainerName: req.ContainerName,
+		TargetVersion: req.TargetVersion,
+		Status:        "started",
 	})
 }
 
+// UpdateRequest is the body of an update or simulation of a single container
+type UpdateRequest struct {
+	ContainerName string `json:"container_name"`
+	TargetVersion string `json:"target_version"` // Defaults to the latest version
+}
+
+// UpdateResponse reports a started update or simulation of a single container
+type UpdateResponse struct {
+	OperationID   string `json:"operation_id"`
+	ContainerName string `json:"container_name"`
+	TargetVersion string `json:"target_version"`
+	Status        string `json:"status"`
+}
+
+// errApprovalRequired is returned when a direct update targets a container
+// whose updates must go through the approval workflow.
+func errApprovalRequired(containerName string) error {
+	return fmt.Errorf("updates to %s require approval; approve the pending update via /api/approvals", containerName)
+}
+
+// BatchUpdateContainer is one container in a batch update request
+type BatchUpdateContainer struct {
+	Name               string `json:"name"`
+	TargetVersion      string `json:"target_version"`
+	Stack              string `json:"stack"`
+	Force              bool   `json:"force,omitempty"`
+	ChangeType         *int   `json:"change_type,omitempty"`
+	OldResolvedVersion string `json:"old_resolved_version"`
+	NewResolvedVersion string `json:"new_resolved_version"`
+}
+
+// batchContainerNames returns the names of the containers of a batch request
+func batchContainerNames(containers []BatchUpdateContainer) []string {
+	names := make([]string, len(containers))
+	for i, c := range containers {
+		names[i] = c.Name
+	}
+	return names
+}
+
 // handleBatchUpdate triggers updates for multiple containers, grouped by stack
 // Containers in the same stack are updated together to respect dependencies
 // Different stacks run in parallel
@@ -313,37 +753,151 @@ func (s *Server) handleBatchUpdate(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	ctx := r.Context()
-
 	// Parse request body
-	var req struct {
-		Containers []struct {
-			Name               string `json:"name"`
-			TargetVersion      string `json:"target_version"`
-			Stack              string `json:"stack"`
-			Force              bool   `json:"force,omitempty"`
-			ChangeType         *int   `json:"change_type,omitempty"`
-			OldResolvedVersion string `json:"old_resolved_version"`
-			NewResolvedVersion string `json:"new_resolved_version"`
-		} `json:"containers"`
+	var req BatchUpdateRequest
+	if !decodeJSONRequest(w, r, &req) {
+		return
 	}
 
-	if !decodeJSONRequest(w, r, &req) {
+	if len(req.Containers) == 0 {
+		RespondBadRequest(w, fmt.Errorf("containers array is required"))
+		return
+	}
+	if !s.allowContainers(w, r, batchContainerNames(req.Containers)...) {
+		return
+	}
+
+	operations, batchGroupID := s.startBatchUpdates(r.Context(), req.Containers, req.AllOrNothing)
+
+	RespondSuccess(w, BatchUpdateResponse{
+		Operations:   operations,
+		BatchGroupID: batchGroupID,
+		Status:       "started",
+	})
+}
+
+// BatchUpdateRequest is the body of a batch update
+type BatchUpdateRequest struct {
+	Containers []BatchUpdateContainer `json:"containers"`
+	// AllOrNothing rolls back every container in a stack's batch if any of them fails
+	AllOrNothing bool `json:"all_or_nothing,omitempty"`
+}
+
+// BatchUpdateResponse reports the operations a batch update started, one per stack
+type BatchUpdateResponse struct {
+	Operations   []BatchOperation `json:"operations"`
+	BatchGroupID string           `json:"batch_group_id"`
+	Status       string           `json:"status"`
+}
+
+// BatchOperation is the update of a stack's containers started by a batch update
+type BatchOperation struct {
+	Stack       string   `json:"stack"`
+	Containers  []string `json:"containers"`
+	OperationID string   `json:"operation_id,omitempty"`
+	Status      string   `json:"status"`          // "started" or "failed"
+	Error       string   `json:"error,omitempty"` // Why the update could not be started
+}
+
+// handleUpdatePreview returns the compose file diffs updating containers would
+// write, without applying anything
+// POST /api/update/preview
+func (s *Server) handleUpdatePreview(w http.ResponseWriter, r *http.Request) {
+	if !s.requireUpdateOrchestrator(w) {
 		return
 	}
 
+	var req UpdatePreviewRequest
+	if !decodeJSONRequest(w, r, &req) {
+		return
+	}
 	if len(req.Containers) == 0 {
 		RespondBadRequest(w, fmt.Errorf("containers array is required"))
 		return
 	}
+	if !s.allowContainers(w, r, batchContainerNames(req.Containers)...) {
+		return
+	}
+
+	previews := make([]update.ComposePreview, 0, len(req.Containers))
+	for _, c := range req.Containers {
+		if c.Name == "" || c.TargetVersion == "" {
+			RespondBadRequest(w, fmt.Errorf("every container needs a name and target_version"))
+			return
+		}
+		previews = append(previews, s.updateOrchestrator.PreviewComposeChange(r.Context(), c.Name, c.TargetVersion))
+	}
+
+	RespondSuccess(w, UpdatePreviewResponse{Previews: previews, Count: len(previews)})
+}
+
+// UpdatePreviewRequest selects the updates to preview
+type UpdatePreviewRequest struct {
+	Containers []BatchUpdateContainer `json:"containers"`
+}
+
+// UpdatePreviewResponse is the compose file changes of the previewed updates
+type UpdatePreviewResponse struct {
+	Previews []update.ComposePreview `json:"previews"`
+	Count    int                     `json:"count"`
+}
+
+// handleUpdateSimulate tries an update on a temporary clone of a container,
+// without touching the container
+// POST /api/update/simulate
+func (s *Server) handleUpdateSimulate(w http.ResponseWriter, r *http.Request) {
+	if !s.requireUpdateOrchestrator(w) {
+		return
+	}
+
+	var req UpdateRequest
+	if !decodeJSONRequest(w, r, &req) {
+		return
+	}
+	if !validateRequired(w, "container_name", req.ContainerName) {
+		return
+	}
+	if !s.allowContainers(w, r, req.ContainerName) {
+		return
+	}
 
+	operationID, err := s.updateOrchestrator.SimulateUpdate(r.Context(), req.ContainerName, req.TargetVersion)
+	if err != nil {
+		RespondOrchestratorError(w, err)
+		return
+	}
+
+	RespondSuccess(w, UpdateResponse{
+		OperationID:   operationID,
+		ContainerName: req.ContainerName,
+		TargetVersion: req.TargetVersion,
+		Status:        "started",
+	})
+}
+
+// startBatchUpdates starts one update operation per stack, all linked by a new
+// batch group ID. Failures to start are reported per stack in the returned operations.
+func (s *Server) startBatchUpdates(ctx context.Context, containers []BatchUpdateContainer, allOrNothing bool) ([]BatchOperation, string) {
 	// Group containers by stack
 	stackGroups := make(map[string][]string)
 	targetVersions := make(map[string]string)
 	containerMeta := make(map[string]storage.BatchContainerDetail)
 	forceContainers := make(map[string]bool)
 
-	for _, c := range req.Containers {
+	// Containers gated by the approval policy are updated via /api/approvals instead
+	operations := make([]BatchOperation, 0)
+
+	for _, c := range containers {
+		if s.approvals != nil && s.approvals.Required(ctx, c.Name, c.TargetVersion) {
+			operations = append(operations, BatchOperation{
+				Stack:      c.Stack,
+				Containers: []string{c.Name},
+				Status:     "failed",
+				Error:      errApprovalRequired(c.Name).Error(),
+			})
+			continue
+		}
+
 		stack := c.Stack
 		if stack == "" {
 			stack = "__standalone__"
@@ -366,7 +920,6 @@ func (s *Server) handleBatchUpdate(w http.ResponseWriter, r *http.Request) {
 	batchGroupID := uuid.New().String()
 
 	// For each stack group, start an update operation
-	operations := make([]map[string]any, 0)
 
 	for stack, containerNames := range stackGroups {
 		if len(containerNames) == 1 {
@@ -374,44 +927,105 @@ func (s *Server) handleBatchUpdate(w http.ResponseWriter, r *http.Request) {
 			opID, err := s.updateOrchestrator.UpdateSingleContainerInGroup(ctx, containerNames[0], targetVersions[containerNames[0]], batchGroupID, containerMeta, forceContainers)
 			if err != nil {
 				log.Printf("Failed to start update for %s: %v", containerNames[0], err)
-				operations = append(operations, map[string]any{
-					"stack":      stack,
-					"containers": containerNames,
-					"status":     "failed",
-					"error":      err.Error(),
+				operations = append(operations, BatchOperation{
+					Stack:      stack,
+					Containers: containerNames,
+					Status:     "failed",
+					Error:      err.Error(),
 				})
 			} else {
-				operations = append(operations, map[string]any{
-					"stack":        stack,
-					"containers":   containerNames,
-					"operation_id": opID,
-					"status":       "started",
+				operations = append(operations, BatchOperation{
+					Stack:       stack,
+					Containers:  containerNames,
+					OperationID: opID,
+					Status:      "started",
 				})
 			}
 		} else {
 			// Multiple containers in same stack - use batch update with group ID
-			opID, err := s.updateOrchestrator.UpdateBatchContainersInGroup(ctx, containerNames, targetVersions, batchGroupID, containerMeta, forceContainers)
+			opID, err := s.updateOrchestrator.UpdateBatchContainersInGroup(ctx, containerNames, targetVersions, batchGroupID, containerMeta, forceContainers, allOrNothing)
 			if err != nil {
 				log.Printf("Failed to start batch update for stack %s: %v", stack, err)
-				operations = append(operations, map[string]any{
-					"stack":      stack,
-					"containers": containerNames,
-					"status":     "failed",
-					"error":      err.Error(),
+				operations = append(operations, BatchOperation{
+					Stack:      stack,
+					Containers: containerNames,
+					Status:     "failed",
+					Error:      err.Error(),
 				})
 			} else {
-				operations = append(operations, map[string]any{
-					"stack":        stack,
-					"containers":   containerNames,
-					"operation_id": opID,
-					"status":       "started",
+				operations = append(operations, BatchOperation{
+					Stack:       stack,
+					Containers:  containerNames,
+					OperationID: opID,
+					Status:      "started",
 				})
 			}
 		}
 	}
 
+	return operations, batchGroupID
+}
+
+// handlePin pins containers from :latest to their recommended versioned tag
+// POST /api/pin
+// Body: {"containers": ["plex"]}, or {"all": true} for every pinnable container
+func (s *Server) handlePin(w http.ResponseWriter, r *http.Request) {
+	if !s.requireUpdateOrchestrator(w) {
+		return
+	}
+
+	ctx := r.Context()
+
+	var req struct {
+		Containers []string `json:"containers"`
+		All        bool     `json:"all"`
+	}
+	if !decodeJSONRequest(w, r, &req) {
+		return
+	}
+	if len(req.Containers) == 0 && !req.All {
+		RespondBadRequest(w, fmt.Errorf("containers array or all is required"))
+		return
+	}
+	if len(req.Containers) > 0 && req.All {
+		RespondBadRequest(w, fmt.Errorf("containers and all cannot be combined"))
+		return
+	}
+
+	result, err := s.checkResult(ctx, false)
+	if err != nil {
+		RespondInternalError(w, err)
+		return
+	}
+	scope, ok := s.requestScope(w, r)
+	if !ok {
+		return
+	}
+	tags, err := update.PinTags(scopeResult(scope, result).Containers, req.Containers)
+	if err != nil {
+		RespondOrchestratorError(w, err)
+		return
+	}
+	if len(tags) == 0 {
+		RespondSuccess(w, map[string]any{
+			"operations": []update.PinResult{},
+			"status":     "nothing_to_pin",
+		})
+		return
+	}
+
+	// Generate a batch group ID to link the pin operations of all stacks
+	batchGroupID := uuid.New().String()
+
+	operations, err := s.updateOrchestrator.PinContainers(ctx, tags, batchGroupID)
+	if err != nil {
+		RespondOrchestratorError(w, err)
+		return
+	}
+
 	RespondSuccess(w, map[string]any{
 		"operations":     operations,
+		"tags":           tags,
 		"batch_group_id": batchGroupID,
 		"status":         "started",
 	})
@@ -426,11 +1040,7 @@ func (s *Server) handleRollback(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
 	// Parse request body
-	var req struct {
-		OperationID string `json:"operation_id"`
-		Force       bool   `json:"force"`
-	}
-
+	var req RollbackRequest
 	if !decodeJSONRequest(w, r, &req) {
 		return
 	}
@@ -438,6 +1048,9 @@ func (s *Server) handleRollback(w http.ResponseWriter, r *http.Request) {
 	if !validateRequired(w, "operation_id", req.OperationID) {
 		return
 	}
+	if !s.allowOperation(w, r, req.OperationID) {
+		return
+	}
 
 	// Trigger the rollback operation
 	rollbackOpID, err := s.updateOrchestrator.RollbackOperation(ctx, req.OperationID, req.Force)
@@ -447,13 +1060,26 @@ func (s *Server) handleRollback(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	RespondSuccess(w, map[string]any{
-		"operation_id":          rollbackOpID,
-		"original_operation_id": req.OperationID,
-		"message":               "Rollback initiated",
+	RespondSuccess(w, RollbackResponse{
+		OperationID:         rollbackOpID,
+		OriginalOperationID: req.OperationID,
+		Message:             "Rollback initiated",
 	})
 }
 
+// RollbackRequest is the body of a rollback of an update operation
+type RollbackRequest struct {
+	OperationID string `json:"operation_id"`
+	Force       bool   `json:"force"` // Skip pre-update checks
+}
+
+// RollbackResponse reports a started rollback
+type RollbackResponse struct {
+	OperationID         string `json:"operation_id"` // The rollback operation
+	OriginalOperationID string `json:"original_operation_id"`
+	Message             string `json:"message"`
+}
+
 // handleFixComposeMismatch triggers a fix for containers where the running image
 // doesn't match what's specified in the compose file
 func (s *Server) handleFixComposeMismatch(w http.ResponseWriter, r *http.Request) {
@@ -483,6 +1109,84 @@ func (s *Server) handleFixComposeMismatch(w http.ResponseWriter, r *http.Request
 	})
 }
 
+// handleRebuild rebuilds the image of a locally built service and recreates its container
+func (s *Server) handleRebuild(w http.ResponseWriter, r *http.Request) {
+	if !s.requireUpdateOrchestrator(w) {
+		return
+	}
+
+	containerName := r.PathValue("name")
+	if !validateRequired(w, "container name", containerName) {
+		return
+	}
+	force := parseBoolParam(r, "force")
+
+	operationID, err := s.updateOrchestrator.RebuildContainer(r.Context(), containerName, force)
+	if err != nil {
+		log.Printf("Rebuild failed for %s: %v", containerName, err)
+		RespondOrchestratorError(w, err)
+		return
+	}
+
+	RespondSuccess(w, map[string]any{
+		"operation_id":   operationID,
+		"container_name": containerName,
+		"force":          force,
+		"status":         "started",
+	})
+}
+
+// handleSwitchVariant switches a container to another variant of its version
+// POST /api/variant/{name}
+// Body: {"tag": "1.25"}, one of the container's variants from the last check
+func (s *Server) handleSwitchVariant(w http.ResponseWriter, r *http.Request) {
+	if !s.requireUpdateOrchestrator(w) {
+		return
+	}
+
+	ctx := r.Context()
+	containerName := r.PathValue("name")
+	if !validateRequired(w, "container name", containerName) {
+		return
+	}
+
+	var req struct {
+		Tag string `json:"tag"`
+	}
+	if !decodeJSONRequest(w, r, &req) {
+		return
+	}
+	if !validateRequired(w, "tag", req.Tag) {
+		return
+	}
+
+	result, err := s.checkResult(ctx, false)
+	if err != nil {
+		RespondInternalError(w, err)
+		return
+	}
+	idx := slices.IndexFunc(result.Containers, func(c update.ContainerInfo) bool { return c.ContainerName == containerName })
+	if idx < 0 {
+		RespondNotFound(w, fmt.Errorf("container '%s' not found", containerName))
+		return
+	}
+	info := result.Containers[idx]
+
+	operationID, err := s.updateOrchestrator.SwitchVariant(ctx, info, req.Tag)
+	if err != nil {
+		RespondOrchestratorError(w, err)
+		return
+	}
+
+	RespondSuccess(w, map[string]any{
+		"operation_id":   operationID,
+		"container_name": containerName,
+		"from_tag":       info.CurrentTag,
+		"tag":            req.Tag,
+		"status":         "started",
+	})
+}
+
 // handleOperationsByGroup returns all operations in a batch group
 func (s *Server) handleOperationsByGroup(w http.ResponseWriter, r *http.Request) {
 	if !s.requireStorage(w) {
@@ -501,6 +1205,11 @@ func (s *Server) handleOperationsByGroup(w http.ResponseWriter, r *http.Request)
 		RespondInternalError(w, err)
 		return
 	}
+	scope, ok := s.requestScope(w, r)
+	if !ok {
+		return
+	}
+	operations = s.scopeOperations(ctx, scope, operations)
 
 	RespondSuccess(w, map[string]any{
 		"batch_group_id": groupID,
@@ -535,6 +1244,9 @@ func (s *Server) handleRollbackContainers(w http.ResponseWriter, r *http.Request
 		RespondBadRequest(w, fmt.Errorf("container_names array is required"))
 		return
 	}
+	if !s.allowOperation(w, r, req.OperationID) || !s.allowContainers(w, r, req.ContainerNames...) {
+		return
+	}
 
 	rollbackOpID, err := s.updateOrchestrator.RollbackContainers(ctx, req.OperationID, req.ContainerNames, req.Force)
 	if err != nil {
@@ -551,7 +1263,11 @@ func (s *Server) handleRollbackContainers(w http.ResponseWriter, r *http.Request
 
 // Allowed setting keys (whitelist)
 var allowedSettingKeys = map[string]bool{
-	"history_retention_days": true,
+	"history_retention_days":               true,
+	storage.CheckHistoryRetentionConfigKey: true,
+	storage.UpdateLogRetentionConfigKey:    true,
+	approval.RequiredConfigKey:             true,
+	proposal.EnabledConfigKey:              true,
 }
 
 // handleGetSetting returns a single setting value by key
@@ -602,6 +1318,13 @@ func (s *Server) handleSetSetting(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if key == storage.CheckHistoryRetentionConfigKey || key == storage.UpdateLogRetentionConfigKey {
+		if days, err := strconv.Atoi(req.Value); err != nil || days < 0 {
+			RespondBadRequest(w, fmt.Errorf("%s must be a number of days", key))
+			return
+		}
+	}
+
 	ctx := r.Context()
 	if err := s.storageService.SetConfig(ctx, key, req.Value); err != nil {
 		RespondInternalError(w, err)
@@ -630,6 +1353,8 @@ type HistoryEntry struct {
 	Operation     string    `json:"operation,omitempty"`
 	Success       bool      `json:"success,omitempty"`
 	Error         string    `json:"error,omitempty"`
+	LatestSize    int64     `json:"latest_size,omitempty"`
+	SizeDelta     int64     `json:"size_delta,omitempty"`
 }
 
 // mergeHistory merges check and update history - same as CLI history command
@@ -646,6 +1371,8 @@ func mergeHistory(checks []storage.CheckHistoryEntry, updates []storage.UpdateLo
 			LatestVer:     check.LatestVersion,
 			Status:        check.Status,
 			Error:         check.Error,
+			LatestSize:    check.LatestSize,
+			SizeDelta:     check.SizeDelta,
 		})
 	}
 
@@ -670,8 +1397,31 @@ func mergeHistory(checks []storage.CheckHistoryEntry, updates []storage.UpdateLo
 	return entries
 }
 
-// handleEvents provides Server-Sent Events for real-time update progress
+// handleEvents provides Server-Sent Events for real-time update progress.
+// Clients reconnecting with Last-Event-ID (or ?last_event_id=) first receive the
+// events they missed. With ?since= it returns the events after that ID as JSON
+// instead, for clients that poll.
 func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Query().Has("since") {
+		s.handleEventsSince(w, r)
+		return
+	}
+
+	scope, ok := s.requestScope(w, r)
+	if !ok {
+		return
+	}
+
+	var lastEventID int64
+	if value := cmp.Or(r.Header.Get("Last-Event-ID"), r.URL.Query().Get("last_event_id")); value != "" {
+		id, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			RespondBadRequest(w, fmt.Errorf("invalid last event ID: %s", value))
+			return
+		}
+		lastEventID = id
+	}
+
 	// Set SSE headers (CORS is handled by the middleware)
 	w.Header().Set("Content-Type", "text/event-stream")
 	w.Header().Set("Cache-Control", "no-cache")
@@ -696,8 +1446,30 @@ func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
 
 	log.Printf("SSE client connected")
 
-	// Send initial connection event
-	fmt.Fprintf(w, "event: connected\ndata: {\"status\":\"connected\"}\n\n")
+	// Replay the events missed since the client's last event. Subscribing first
+	// means nothing published meanwhile is lost; duplicates are skipped below.
+	var missed []events.Event
+	complete := true
+	if lastEventID > 0 {
+		missed, complete = s.eventBus.Since(lastEventID)
+	}
+
+	// Principals limited to the stacks they own only see events about those
+	var stacks map[string]string
+	if scope != nil {
+		stacks = s.containerStacks(r.Context())
+	}
+
+	// Send initial connection event. missed_events tells a reconnecting client that
+	// some events could not be replayed, so it should reload its state.
+	fmt.Fprintf(w, "event: connected\ndata: {\"status\":\"connected\",\"replayed\":%d,\"missed_events\":%t}\n\n", len(missed), !complete)
+	sentID := lastEventID
+	for _, event := range missed {
+		if eventAllowed(scope, stacks, event) {
+			writeSSEEvent(w, event)
+		}
+		sentID = event.ID
+	}
 	flusher.Flush()
 
 	// Heartbeat keeps connection alive through proxies (Traefik idle timeout ~30s)
@@ -718,19 +1490,58 @@ func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
 			if !ok {
 				return
 			}
-
-			// Convert event to JSON
-			eventData, err := events.MarshalEvent(event)
-			if err != nil {
-				log.Printf("Error marshaling event: %v", err)
+			if event.ID != 0 && event.ID <= sentID {
+				continue // Already replayed
+			}
+			if !eventAllowed(scope, stacks, event) {
 				continue
 			}
 
 			// Send as SSE, reset heartbeat since we just sent data
-			fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, eventData)
+			writeSSEEvent(w, event)
 			flusher.Flush()
 			heartbeat.Reset(15 * time.Second)
 		}
 	}
 }
 
+// writeSSEEvent writes an event in SSE format, with its ID so the browser sends
+// it back as Last-Event-ID when it reconnects.
+func writeSSEEvent(w http.ResponseWriter, event events.Event) {
+	eventData, err := events.MarshalEvent(event)
+	if err != nil {
+		log.Printf("Error marshaling event: %v", err)
+		return
+	}
+	if event.ID != 0 {
+		fmt.Fprintf(w, "id: %d\n", event.ID)
+	}
+	fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, eventData)
+}
+
+// handleEventsSince returns the events published after an event ID, oldest first
+// GET /api/events?since=<id>
+func (s *Server) handleEventsSince(w http.ResponseWriter, r *http.Request) {
+	since, err := strconv.ParseInt(r.URL.Query().Get("since"), 10, 64)
+	if err != nil {
+		RespondBadRequest(w, fmt.Errorf("invalid since: %s", r.URL.Query().Get("since")))
+		return
+	}
+
+	scope, ok := s.requestScope(w, r)
+	if !ok {
+		return
+	}
+	missed, complete := s.eventBus.Since(since)
+	if scope != nil {
+		stacks := s.containerStacks(r.Context())
+		missed = slices.DeleteFunc(missed, func(event events.Event) bool { return !eventAllowed(scope, stacks, event) })
+	}
+	RespondSuccess(w, map[string]any{
+		"events":        missed,
+		"count":         len(missed),
+		"last_event_id": s.eventBus.LastID(),
+		"complete":      complete,
+	})
+}
+
@@ -0,0 +1,228 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+
+	"github.com/chis/docksmith/internal/approval"
+	"github.com/chis/docksmith/internal/auth"
+	"github.com/chis/docksmith/internal/storage"
+)
+
+// maxWebhookBodySize bounds approval webhook payloads.
+const maxWebhookBodySize = 64 * 1024
+
+// handleApprovalsList returns approvals, newest first
+// GET /api/approvals?status=pending&limit=50
+func (s *Server) handleApprovalsList(w http.ResponseWriter, r *http.Request) {
+	if !s.requireApprovals(w) {
+		return
+	}
+
+	limit := 100
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		if parsed, err := strconv.Atoi(limitStr); err == nil && parsed > 0 {
+			limit = parsed
+		}
+	}
+
+	approvals, err := s.approvals.List(r.Context(), r.URL.Query().Get("status"), limit)
+	if err != nil {
+		RespondInternalError(w, err)
+		return
+	}
+	scope, ok := s.requestScope(w, r)
+	if !ok {
+		return
+	}
+	approvals = slices.DeleteFunc(approvals, func(a storage.Approval) bool { return !scope.Allows(a.StackName, a.ContainerName) })
+
+	RespondSuccess(w, map[string]any{
+		"approvals": approvals,
+		"count":     len(approvals),
+	})
+}
+
+// handleApprovalGet returns a single approval
+// GET /api/approvals/{id}
+func (s *Server) handleApprovalGet(w http.ResponseWriter, r *http.Request) {
+	if !s.requireApprovals(w) {
+		return
+	}
+
+	result, err := s.approvals.Get(r.Context(), r.PathValue("id"))
+	if err != nil {
+		respondApprovalError(w, err)
+		return
+	}
+
+	RespondSuccess(w, result)
+}
+
+// handleApprovalApprove approves a pending update and starts it
+// POST /api/approvals/{id}/approve
+func (s *Server) handleApprovalApprove(w http.ResponseWriter, r *http.Request) {
+	if !s.requireApprovals(w) {
+		return
+	}
+
+	result, err := s.approvals.Approve(r.Context(), r.PathValue("id"), requestActor(r))
+	if err != nil {
+		respondApprovalError(w, err)
+		return
+	}
+
+	RespondSuccess(w, result)
+}
+
+// handleApprovalReject rejects a pending update
+// POST /api/approvals/{id}/reject
+func (s *Server) handleApprovalReject(w http.ResponseWriter, r *http.Request) {
+	if !s.requireApprovals(w) {
+		return
+	}
+
+	result, err := s.approvals.Reject(r.Context(), r.PathValue("id"), requestActor(r))
+	if err != nil {
+		respondApprovalError(w, err)
+		return
+	}
+
+	RespondSuccess(w, result)
+}
+
+// handleApprovalWebhook approves or rejects via a signed callback from an
+// external system (chat bot, ticketing). Authenticated by the
+// X-Docksmith-Signature HMAC header, over the approval ID, the
+// X-Docksmith-Timestamp header, and the body, rather than a session or API key.
+// POST /api/approvals/{id}/webhook
+func (s *Server) handleApprovalWebhook(w http.ResponseWriter, r *http.Request) {
+	if !s.requireApprovals(w) {
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxWebhookBodySize))
+	if err != nil {
+		RespondBadRequest(w, fmt.Errorf("failed to read request body"))
+		return
+	}
+
+	var req struct {
+		Action string `json:"action"`
+		Actor  string `json:"actor,omitempty"`
+	}
+	if err := json.Unmarshal(body, &req); err != nil {
+		RespondBadRequest(w, fmt.Errorf("invalid request body"))
+		return
+	}
+
+	result, err := s.approvals.HandleWebhook(r.Context(), r.PathValue("id"), r.Header.Get("X-Docksmith-Signature"), r.Header.Get("X-Docksmith-Timestamp"), body, req.Action, req.Actor)
+	if err != nil {
+		respondApprovalError(w, err)
+		return
+	}
+
+	RespondSuccess(w, result)
+}
+
+// handleApprovalPolicySet sets which updates of the host, a stack, or a container need approval
+// PUT /api/policies/approval/{scope} or /api/policies/approval/{scope}/{name}
+func (s *Server) handleApprovalPolicySet(w http.ResponseWriter, r *http.Request) {
+	if !s.requireApprovals(w) {
+		return
+	}
+
+	var req struct {
+		Mode string `json:"mode"`
+	}
+	if !decodeJSONRequest(w, r, &req) {
+		return
+	}
+	if !validateRequired(w, "mode", req.Mode) {
+		return
+	}
+
+	policy, err := s.approvals.SetPolicy(r.Context(), r.PathValue("scope"), r.PathValue("name"), req.Mode)
+	if err != nil {
+		respondApprovalPolicyError(w, err)
+		return
+	}
+	log.Printf("APPROVAL: Set %s approval policy %s to %s", policy.EntityType, policy.EntityID, policy.Mode)
+
+	RespondSuccess(w, policy)
+}
+
+// handleApprovalPolicyDelete removes an approval policy, so the next level applies
+// DELETE /api/policies/approval/{scope} or /api/policies/approval/{scope}/{name}
+func (s *Server) handleApprovalPolicyDelete(w http.ResponseWriter, r *http.Request) {
+	if !s.requireApprovals(w) {
+		return
+	}
+
+	scope, name := r.PathValue("scope"), r.PathValue("name")
+	if err := s.approvals.DeletePolicy(r.Context(), scope, name); err != nil {
+		respondApprovalPolicyError(w, err)
+		return
+	}
+	log.Printf("APPROVAL: Removed %s approval policy %s", scope, name)
+
+	RespondSuccess(w, map[string]any{
+		"entity_type": scope,
+		"entity_id":   name,
+		"deleted":     true,
+	})
+}
+
+// respondApprovalPolicyError maps approval policy errors to HTTP status codes.
+func respondApprovalPolicyError(w http.ResponseWriter, err error) {
+	if errors.Is(err, approval.ErrInvalidPolicy) {
+		RespondBadRequest(w, err)
+		return
+	}
+	RespondInternalError(w, err)
+}
+
+// requireApprovals checks if the approval manager is available
+func (s *Server) requireApprovals(w http.ResponseWriter) bool {
+	if s.approvals == nil {
+		RespondInternalError(w, errNoStorage)
+		return false
+	}
+	return true
+}
+
+// requestActor returns the name recorded as the decision maker for a request.
+func requestActor(r *http.Request) string {
+	return contextActor(r.Context())
+}
+
+// contextActor returns the name of the principal of a context, or "anonymous".
+func contextActor(ctx context.Context) string {
+	if principal := auth.PrincipalFromContext(ctx); !principal.Anonymous() {
+		return principal.Kind + ":" + principal.Name
+	}
+	return auth.KindAnonymous
+}
+
+// respondApprovalError maps approval errors to HTTP status codes.
+func respondApprovalError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, approval.ErrNotFound):
+		RespondNotFound(w, err)
+	case errors.Is(err, approval.ErrNotPending):
+		RespondError(w, http.StatusConflict, err)
+	case errors.Is(err, approval.ErrInvalidSignature):
+		RespondError(w, http.StatusUnauthorized, err)
+	case errors.Is(err, approval.ErrWebhookDisabled):
+		RespondNotFound(w, err)
+	default:
+		RespondBadRequest(w, err)
+	}
+}
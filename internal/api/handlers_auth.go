@@ -0,0 +1,357 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/chis/docksmith/internal/auth"
+)
+
+// handleLogin verifies username/password and sets the session cookie
+// POST /api/auth/login
+func (s *Server) handleLogin(w http.ResponseWriter, r *http.Request) {
+	if !s.requireStorage(w) {
+		return
+	}
+
+	var req struct {
+		Username string `json:"username"`
+		Password string `json:"password"`
+	}
+	if !decodeJSONRequest(w, r, &req) {
+		return
+	}
+
+	token, expires, user, err := s.users.Login(r.Context(), req.Username, req.Password)
+	if err != nil {
+		if errors.Is(err, auth.ErrInvalidCredentials) {
+			RespondError(w, http.StatusUnauthorized, err)
+			return
+		}
+		RespondInternalError(w, err)
+		return
+	}
+
+	setSessionCookie(w, r, token, expires)
+
+	RespondSuccess(w, map[string]any{
+		"user":       auth.UserPrincipal(user),
+		"expires_at": expires.Format(time.RFC3339),
+	})
+}
+
+// handleLogout ends the current session and clears the cookie
+// POST /api/auth/logout
+func (s *Server) handleLogout(w http.ResponseWriter, r *http.Request) {
+	if cookie, err := r.Cookie(auth.SessionCookieName); err == nil && cookie.Value != "" && s.users != nil {
+		if err := s.users.Logout(r.Context(), cookie.Value); err != nil {
+			RespondInternalError(w, err)
+			return
+		}
+	}
+
+	clearSessionCookie(w, r)
+
+	RespondSuccess(w, map[string]any{
+		"message": "Logged out",
+	})
+}
+
+// handleAuthMe returns the authenticated principal for the current request
+// GET /api/auth/me
+func (s *Server) handleAuthMe(w http.ResponseWriter, r *http.Request) {
+	principal := auth.PrincipalFromContext(r.Context())
+
+	RespondSuccess(w, map[string]any{
+		"authenticated": !principal.Anonymous(),
+		"principal":     principal,
+		"auth_mode":     s.authMode,
+	})
+}
+
+// handleUsersList returns all users
+// GET /api/users
+func (s *Server) handleUsersList(w http.ResponseWriter, r *http.Request) {
+	if !s.requireStorage(w) {
+		return
+	}
+
+	users, err := s.users.ListUsers(r.Context())
+	if err != nil {
+		RespondInternalError(w, err)
+		return
+	}
+
+	RespondSuccess(w, map[string]any{
+		"users": users,
+		"count": len(users),
+	})
+}
+
+// handleUsersCreate creates a new user
+// POST /api/users
+func (s *Server) handleUsersCreate(w http.ResponseWriter, r *http.Request) {
+	if !s.requireStorage(w) {
+		return
+	}
+
+	var req struct {
+		Username string `json:"username"`
+		Password string `json:"password"`
+		Role     string `json:"role"`
+	}
+	if !decodeJSONRequest(w, r, &req) {
+		return
+	}
+
+	role, err := auth.ParseRole(req.Role)
+	if err != nil {
+		RespondBadRequest(w, err)
+		return
+	}
+
+	user, err := s.users.CreateUser(r.Context(), req.Username, req.Password, role)
+	if err != nil {
+		respondUserError(w, err)
+		return
+	}
+
+	RespondSuccess(w, user)
+}
+
+// handleUsersUpdate changes a user's role and/or password
+// PUT /api/users/{username}
+func (s *Server) handleUsersUpdate(w http.ResponseWriter, r *http.Request) {
+	if !s.requireStorage(w) {
+		return
+	}
+
+	username := r.PathValue("username")
+	if !validateRequired(w, "username", username) {
+		return
+	}
+
+	var req struct {
+		Password string `json:"password,omitempty"`
+		Role     string `json:"role,omitempty"`
+	}
+	if !decodeJSONRequest(w, r, &req) {
+		return
+	}
+
+	if req.Role == "" && req.Password == "" {
+		RespondBadRequest(w, fmt.Errorf("role or password is required"))
+		return
+	}
+
+	ctx := r.Context()
+	if req.Role != "" {
+		role, err := auth.ParseRole(req.Role)
+		if err != nil {
+			RespondBadRequest(w, err)
+			return
+		}
+		if err := s.users.SetRole(ctx, username, role); err != nil {
+			respondUserError(w, err)
+			return
+		}
+	}
+	if req.Password != "" {
+		if err := s.users.SetPassword(ctx, username, req.Password); err != nil {
+			respondUserError(w, err)
+			return
+		}
+	}
+
+	RespondSuccess(w, map[string]any{
+		"username": username,
+		"message":  "User updated",
+	})
+}
+
+// handleUsersDelete removes a user
+// DELETE /api/users/{username}
+func (s *Server) handleUsersDelete(w http.ResponseWriter, r *http.Request) {
+	if !s.requireStorage(w) {
+		return
+	}
+
+	username := r.PathValue("username")
+	if !validateRequired(w, "username", username) {
+		return
+	}
+
+	if err := s.users.DeleteUser(r.Context(), username); err != nil {
+		respondUserError(w, err)
+		return
+	}
+
+	RespondSuccess(w, map[string]any{
+		"username": username,
+		"message":  "User deleted",
+	})
+}
+
+// respondUserError maps user management errors to HTTP status codes.
+func respondUserError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, auth.ErrUserNotFound):
+		RespondNotFound(w, err)
+	case errors.Is(err, auth.ErrUserExists):
+		RespondError(w, http.StatusConflict, err)
+	default:
+		RespondBadRequest(w, err)
+	}
+}
+
+// setSessionCookie writes the login session cookie.
+func setSessionCookie(w http.ResponseWriter, r *http.Request, token string, expires time.Time) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     auth.SessionCookieName,
+		Value:    token,
+		Path:     "/",
+		Expires:  expires,
+		HttpOnly: true,
+		Secure:   isSecureRequest(r),
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// clearSessionCookie expires the login session cookie.
+func clearSessionCookie(w http.ResponseWriter, r *http.Request) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     auth.SessionCookieName,
+		Value:    "",
+		Path:     "/",
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   isSecureRequest(r),
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// isSecureRequest reports whether the client connection uses HTTPS,
+// directly or through a TLS-terminating reverse proxy.
+func isSecureRequest(r *http.Request) bool {
+	return r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https"
+}
+
+// oidcCallbackPath is where the identity provider redirects after login.
+const oidcCallbackPath = "/api/auth/oidc/callback"
+
+// handleOIDCLogin redirects the browser to the OIDC provider
+// GET /api/auth/oidc/login
+func (s *Server) handleOIDCLogin(w http.ResponseWriter, r *http.Request) {
+	if s.oidc == nil || s.users == nil {
+		RespondNotFound(w, fmt.Errorf("OIDC login is not configured"))
+		return
+	}
+
+	authURL, flow, err := s.oidc.BeginLogin(r.Context(), oidcRedirectURL(r))
+	if err != nil {
+		RespondError(w, http.StatusBadGateway, err)
+		return
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     auth.OIDCStateCookieName,
+		Value:    flow.Encode(),
+		Path:     oidcCallbackPath,
+		MaxAge:   600,
+		HttpOnly: true,
+		Secure:   isSecureRequest(r),
+		SameSite: http.SameSiteLaxMode,
+	})
+	http.Redirect(w, r, authURL, http.StatusFound)
+}
+
+// handleOIDCCallback completes the OIDC login, maps groups to a role,
+// starts a session, and redirects to the dashboard
+// GET /api/auth/oidc/callback
+func (s *Server) handleOIDCCallback(w http.ResponseWriter, r *http.Request) {
+	if s.oidc == nil || s.users == nil {
+		RespondNotFound(w, fmt.Errorf("OIDC login is not configured"))
+		return
+	}
+
+	// The state cookie is single-use
+	http.SetCookie(w, &http.Cookie{
+		Name:     auth.OIDCStateCookieName,
+		Value:    "",
+		Path:     oidcCallbackPath,
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   isSecureRequest(r),
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	query := r.URL.Query()
+	if providerErr := query.Get("error"); providerErr != "" {
+		RespondError(w, http.StatusUnauthorized, fmt.Errorf("OIDC login failed: %s %s", providerErr, query.Get("error_description")))
+		return
+	}
+
+	cookie, err := r.Cookie(auth.OIDCStateCookieName)
+	if err != nil {
+		RespondBadRequest(w, auth.ErrOIDCState)
+		return
+	}
+	flow, err := auth.ParseOIDCFlow(cookie.Value)
+	if err != nil || !flow.MatchesState(query.Get("state")) {
+		RespondBadRequest(w, auth.ErrOIDCState)
+		return
+	}
+
+	code := query.Get("code")
+	if !validateRequired(w, "code", code) {
+		return
+	}
+
+	ctx := r.Context()
+	identity, err := s.oidc.CompleteLogin(ctx, flow, code, oidcRedirectURL(r))
+	if err != nil {
+		log.Printf("OIDC login failed: %v", err)
+		RespondError(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	role := s.oidc.Config().RoleForGroups(identity.Groups)
+	if role == "" {
+		log.Printf("OIDC login denied for %s: no role mapped for groups %v", identity.Username, identity.Groups)
+		RespondError(w, http.StatusForbidden, auth.ErrOIDCNoRole)
+		return
+	}
+
+	user, err := s.users.SyncExternalUser(ctx, identity.Username, role)
+	if err != nil {
+		if errors.Is(err, auth.ErrUserExists) {
+			RespondError(w, http.StatusConflict, err)
+			return
+		}
+		RespondInternalError(w, err)
+		return
+	}
+
+	token, expires, err := s.users.StartSession(ctx, user)
+	if err != nil {
+		RespondInternalError(w, err)
+		return
+	}
+
+	log.Printf("OIDC login: %s (role=%s)", user.Username, user.Role)
+	setSessionCookie(w, r, token, expires)
+	http.Redirect(w, r, "/", http.StatusFound)
+}
+
+// oidcRedirectURL derives the callback URL from the request, used when
+// OIDC_REDIRECT_URL is not set.
+func oidcRedirectURL(r *http.Request) string {
+	scheme := "http"
+	if isSecureRequest(r) {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host + oidcCallbackPath
+}
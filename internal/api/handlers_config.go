@@ -0,0 +1,77 @@
+package api
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/chis/docksmith/internal/settings"
+)
+
+// maxConfigImportSize bounds the size of an imported configuration file
+const maxConfigImportSize = 1 << 20
+
+// handleConfigExport returns the configuration as a YAML file
+// GET /api/config/export
+func (s *Server) handleConfigExport(w http.ResponseWriter, r *http.Request) {
+	if !s.requireStorage(w) {
+		return
+	}
+
+	export, err := settings.Collect(r.Context(), s.storageService)
+	if err != nil {
+		RespondInternalError(w, err)
+		return
+	}
+	data, err := settings.Marshal(export)
+	if err != nil {
+		RespondInternalError(w, err)
+		return
+	}
+
+	filename := "docksmith-config-" + time.Now().Format("2006-01-02") + ".yaml"
+	w.Header().Set("Content-Type", "application/yaml; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	w.Write(data)
+}
+
+// handleConfigImport applies a YAML configuration file sent as the request body
+// POST /api/config/import
+func (s *Server) handleConfigImport(w http.ResponseWriter, r *http.Request) {
+	if !s.requireStorage(w) {
+		return
+	}
+
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxConfigImportSize))
+	if err != nil {
+		RespondBadRequest(w, fmt.Errorf("failed to read configuration: %w", err))
+		return
+	}
+	export, err := settings.Parse(data)
+	if err != nil {
+		RespondBadRequest(w, err)
+		return
+	}
+
+	ctx := r.Context()
+	summary, err := settings.Apply(ctx, s.storageService, export)
+	if err != nil {
+		RespondInternalError(w, err)
+		return
+	}
+
+	// The paused state applies right away; other schedule and notification changes need a restart
+	if s.backgroundChecker != nil {
+		if export.Schedule.Paused {
+			s.backgroundChecker.Pause()
+		} else {
+			s.backgroundChecker.Resume()
+		}
+	}
+
+	RespondSuccess(w, map[string]any{
+		"imported":         summary,
+		"restart_required": true,
+	})
+}
@@ -7,11 +7,13 @@ import (
 	"log"
 	"net/http"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/chis/docksmith/internal/docker"
 	"github.com/chis/docksmith/internal/events"
 	"github.com/chis/docksmith/internal/storage"
+	"github.com/chis/docksmith/internal/update"
 	"github.com/docker/docker/api/types/container"
 	"github.com/google/uuid"
 )
@@ -483,6 +485,7 @@ func (s *Server) runSingleContainerOp(
 	now := time.Now()
 	op := storage.UpdateOperation{
 		OperationID:   operationID,
+		TriggeredBy:   update.TriggerFromContext(ctx),
 		ContainerID:   ctr.ID,
 		ContainerName: containerName,
 		StackName:     ctr.Stack,
@@ -630,12 +633,16 @@ func (s *Server) runBatchContainerOp(
 	execute func(ctx context.Context, ctrID string) error,
 ) {
 	ctx := r.Context()
+	scope, ok := s.requestScope(w, r)
+	if !ok {
+		return
+	}
 	batchGroupID := uuid.New().String()
 	results := make([]BatchContainerResult, 0, len(req.Containers))
 
 	for _, containerName := range req.Containers {
 		ctr, err := s.findContainerByName(ctx, containerName)
-		if err != nil {
+		if err != nil || !scope.Allows(ctr.Stack, ctr.Name) {
 			results = append(results, BatchContainerResult{Container: containerName, Success: false, Error: "container not found"})
 			continue
 		}
@@ -651,6 +658,7 @@ func (s *Server) runBatchContainerOp(
 		now := time.Now()
 		op := storage.UpdateOperation{
 			OperationID:   operationID,
+			TriggeredBy:   update.TriggerFromContext(ctx),
 			ContainerID:   ctr.ID,
 			ContainerName: containerName,
 			StackName:     ctr.Stack,
@@ -835,3 +843,51 @@ func (s *Server) handleContainerStats(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	w.Write(statsBytes)
 }
+
+// handleContainersUsage returns the CPU and memory usage of every running container
+// GET /api/containers/usage
+// Containers whose stats cannot be sampled are omitted.
+func (s *Server) handleContainersUsage(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	containers, err := s.dockerService.ListContainers(ctx)
+	if err != nil {
+		RespondInternalError(w, fmt.Errorf("failed to list containers: %w", err))
+		return
+	}
+	scope, ok := s.requestScope(w, r)
+	if !ok {
+		return
+	}
+	containers = scopeDockerContainers(scope, containers)
+
+	// Each sample takes about a second, so containers are sampled concurrently
+	statsCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	usage := make(map[string]*docker.ResourceUsage)
+	for _, ctr := range containers {
+		if ctr.State != "running" {
+			continue
+		}
+		wg.Add(1)
+		go func(ctr docker.Container) {
+			defer wg.Done()
+			u, err := s.dockerService.GetResourceUsage(statsCtx, ctr.ID)
+			if err != nil {
+				log.Printf("Failed to get resource usage of %s: %v", ctr.Name, err)
+				return
+			}
+			mu.Lock()
+			usage[ctr.Name] = u
+			mu.Unlock()
+		}(ctr)
+	}
+	wg.Wait()
+
+	RespondSuccess(w, map[string]any{
+		"containers": usage,
+		"count":      len(usage),
+	})
+}
@@ -0,0 +1,133 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/chis/docksmith/internal/storage"
+)
+
+// handleDBBackup snapshots the database and returns it as a file download
+// GET /api/db/backup
+func (s *Server) handleDBBackup(w http.ResponseWriter, r *http.Request) {
+	if !s.requireStorage(w) {
+		return
+	}
+
+	dir, err := os.MkdirTemp("", "docksmith-backup-")
+	if err != nil {
+		RespondInternalError(w, fmt.Errorf("failed to create backup directory: %w", err))
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	filename := "docksmith-" + time.Now().Format("20060102-150405") + ".db"
+	path := filepath.Join(dir, filename)
+	if err := s.storageService.Backup(r.Context(), path); err != nil {
+		RespondInternalError(w, err)
+		return
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		RespondInternalError(w, fmt.Errorf("failed to open backup: %w", err))
+		return
+	}
+	defer f.Close()
+
+	w.Header().Set("Content-Type", "application/vnd.sqlite3")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	http.ServeContent(w, r, filename, time.Now(), f)
+}
+
+// handleDBVacuum rebuilds the database to reclaim free space
+// POST /api/db/vacuum
+func (s *Server) handleDBVacuum(w http.ResponseWriter, r *http.Request) {
+	if !s.requireStorage(w) {
+		return
+	}
+
+	result, err := s.storageService.Vacuum(r.Context())
+	if err != nil {
+		RespondInternalError(w, err)
+		return
+	}
+
+	RespondSuccess(w, result)
+}
+
+// handleDBPrune deletes old check history and update log rows. Without
+// older_than_days the retention policy stored in config is applied.
+// POST /api/db/prune
+func (s *Server) handleDBPrune(w http.ResponseWriter, r *http.Request) {
+	if !s.requireStorage(w) {
+		return
+	}
+
+	var req struct {
+		OlderThanDays int `json:"older_than_days"`
+	}
+	if !decodeJSONRequest(w, r, &req) {
+		return
+	}
+	if req.OlderThanDays < 0 {
+		RespondBadRequest(w, fmt.Errorf("older_than_days must not be negative"))
+		return
+	}
+
+	ctx := r.Context()
+	policy := storage.RetentionPolicy{CheckHistoryDays: req.OlderThanDays, UpdateLogDays: req.OlderThanDays}
+	if req.OlderThanDays == 0 {
+		var err error
+		if policy, err = storage.GetRetentionPolicy(ctx, s.storageService); err != nil {
+			RespondInternalError(w, err)
+			return
+		}
+		if !policy.IsSet() {
+			RespondBadRequest(w, fmt.Errorf("no retention policy is configured; pass older_than_days"))
+			return
+		}
+	}
+
+	result, err := s.storageService.PruneHistory(ctx, policy.PruneOptions(time.Now()))
+	if err != nil {
+		RespondInternalError(w, err)
+		return
+	}
+
+	RespondSuccess(w, map[string]any{
+		"deleted": result,
+		"policy":  policy,
+	})
+}
+
+// handleDBReconcileReport returns the report of the last reconciliation of
+// orphaned operations, queue entries, and compose backup records
+// GET /api/db/reconcile
+func (s *Server) handleDBReconcileReport(w http.ResponseWriter, r *http.Request) {
+	if !s.requireUpdateOrchestrator(w) {
+		return
+	}
+
+	report, found := s.updateOrchestrator.LastReconcile()
+	if !found {
+		RespondNotFound(w, fmt.Errorf("no reconciliation has run yet"))
+		return
+	}
+
+	RespondSuccess(w, report)
+}
+
+// handleDBReconcile reconciles orphaned operations, queue entries, and compose
+// backup records now
+// POST /api/db/reconcile
+func (s *Server) handleDBReconcile(w http.ResponseWriter, r *http.Request) {
+	if !s.requireUpdateOrchestrator(w) {
+		return
+	}
+
+	RespondSuccess(w, s.updateOrchestrator.Reconcile(r.Context()))
+}
@@ -84,11 +84,19 @@ func (s *Server) handleExplorer(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	scope, ok := s.requestScope(w, r)
+	if !ok {
+		return
+	}
+
 	// Group containers by stack
 	containerStacks := make(map[string][]docker.ContainerExplorerItem)
 	var standaloneContainers []docker.ContainerExplorerItem
 
 	for _, c := range containers {
+		if !scope.Allows(c.Stack, c.Name) {
+			continue
+		}
 		if c.Stack != "" {
 			containerStacks[c.Stack] = append(containerStacks[c.Stack], c)
 		} else {
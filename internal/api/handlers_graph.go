@@ -0,0 +1,38 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/chis/docksmith/internal/graph"
+)
+
+// handleGraph returns the container dependency graph: nodes with their blast
+// radius, dependency edges, dependency cycles, and stacks.
+// GET /api/graph?format=json|dot
+func (s *Server) handleGraph(w http.ResponseWriter, r *http.Request) {
+	format := r.URL.Query().Get("format")
+	if format != "" && format != "json" && format != "dot" {
+		RespondBadRequest(w, fmt.Errorf("invalid format '%s' (expected json or dot)", format))
+		return
+	}
+
+	containers, err := s.dockerService.ListContainers(r.Context())
+	if err != nil {
+		RespondInternalError(w, err)
+		return
+	}
+	scope, ok := s.requestScope(w, r)
+	if !ok {
+		return
+	}
+	g := graph.NewBuilder().BuildFromContainers(scopeDockerContainers(scope, containers))
+
+	if format == "dot" {
+		w.Header().Set("Content-Type", "text/vnd.graphviz; charset=utf-8")
+		w.Write([]byte(g.DOT()))
+		return
+	}
+
+	RespondSuccess(w, g.Export())
+}
@@ -0,0 +1,403 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/chis/docksmith/internal/update"
+	"github.com/google/uuid"
+)
+
+// groupSummary is a group's check summary with its update schedule, if any
+type groupSummary struct {
+	*update.Group
+	Schedule *GroupSchedule `json:"schedule,omitempty"`
+	NextRun  string         `json:"next_run,omitempty"`
+}
+
+// handleGroupsList returns a summary of every container group
+// GET /api/groups
+func (s *Server) handleGroupsList(w http.ResponseWriter, r *http.Request) {
+	result, err := s.checkResult(r.Context(), false)
+	if err != nil {
+		RespondInternalError(w, err)
+		return
+	}
+	scope, ok := s.requestScope(w, r)
+	if !ok {
+		return
+	}
+
+	groups := update.BuildGroups(scopeResult(scope, result).Containers)
+	var schedules map[string]GroupSchedule
+	if s.groupScheduler != nil {
+		schedules = s.groupScheduler.Schedules()
+	}
+	// Scheduled groups are listed even when no container carries the label right now
+	for name := range schedules {
+		if groups[name] == nil {
+			groups[name] = &update.Group{Name: name, Containers: []string{}}
+		}
+	}
+
+	names := make([]string, 0, len(groups))
+	for name := range groups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	summaries := make([]groupSummary, 0, len(names))
+	for _, name := range names {
+		summary := groupSummary{Group: groups[name]}
+		if sched, ok := schedules[name]; ok {
+			summary.Schedule = &sched
+			if next, ok := s.groupScheduler.NextRun(name); ok {
+				summary.NextRun = next.Format(time.RFC3339)
+			}
+		}
+		summaries = append(summaries, summary)
+	}
+
+	RespondSuccess(w, map[string]any{
+		"groups": summaries,
+		"count":  len(summaries),
+	})
+}
+
+// handleGroupCheck re-checks every container in a group
+// POST /api/groups/check/{name}
+func (s *Server) handleGroupCheck(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	group := r.PathValue("name")
+	if !validateRequired(w, "group", group) {
+		return
+	}
+
+	result, err := s.checkResult(ctx, false)
+	if err != nil {
+		RespondInternalError(w, err)
+		return
+	}
+	scope, ok := s.requestScope(w, r)
+	if !ok {
+		return
+	}
+	members := groupMembers(scopeResult(scope, result), group)
+	if len(members) == 0 {
+		RespondNotFound(w, fmt.Errorf("group '%s' not found", group))
+		return
+	}
+
+	containers := make([]update.ContainerInfo, 0, len(members))
+	for _, member := range members {
+		info, err := s.discoveryOrchestrator.DiscoverAndCheckSingle(ctx, member.ContainerName)
+		if err != nil {
+			RespondInternalError(w, err)
+			return
+		}
+		if info != nil {
+			containers = append(containers, *info)
+		}
+	}
+
+	RespondSuccess(w, map[string]any{
+		"group":      update.BuildGroups(containers)[group],
+		"containers": containers,
+	})
+}
+
+// handleGroupUpdate updates every container in a group that has an update available
+// POST /api/groups/update/{name}
+// Body (optional): {"all_or_nothing": true}
+func (s *Server) handleGroupUpdate(w http.ResponseWriter, r *http.Request) {
+	if !s.requireUpdateOrchestrator(w) {
+		return
+	}
+
+	ctx := r.Context()
+	group := r.PathValue("name")
+	if !validateRequired(w, "group", group) {
+		return
+	}
+
+	var req struct {
+		// AllOrNothing rolls back every container in a stack's batch if any of them fails
+		AllOrNothing bool `json:"all_or_nothing,omitempty"`
+	}
+	if r.ContentLength != 0 && !decodeJSONRequest(w, r, &req) {
+		return
+	}
+
+	result, err := s.checkResult(ctx, false)
+	if err != nil {
+		RespondInternalError(w, err)
+		return
+	}
+	scope, ok := s.requestScope(w, r)
+	if !ok {
+		return
+	}
+	members := groupMembers(scopeResult(scope, result), group)
+	if len(members) == 0 {
+		RespondNotFound(w, fmt.Errorf("group '%s' not found", group))
+		return
+	}
+
+	containers := groupUpdateTargets(members)
+	if len(containers) == 0 {
+		RespondSuccess(w, map[string]any{
+			"group":      group,
+			"operations": []map[string]any{},
+			"status":     "up_to_date",
+		})
+		return
+	}
+
+	operations, batchGroupID := s.startBatchUpdates(ctx, containers, req.AllOrNothing)
+
+	RespondSuccess(w, map[string]any{
+		"group":          group,
+		"operations":     operations,
+		"batch_group_id": batchGroupID,
+		"status":         "started",
+	})
+}
+
+// handleGroupIgnore sets or clears the ignore label on every container in a group
+// POST /api/groups/ignore/{name}
+// Body: {"ignore": true}
+func (s *Server) handleGroupIgnore(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	group := r.PathValue("name")
+	if !validateRequired(w, "group", group) {
+		return
+	}
+
+	var req struct {
+		Ignore *bool `json:"ignore"`
+	}
+	if !decodeJSONRequest(w, r, &req) {
+		return
+	}
+	if req.Ignore == nil {
+		RespondBadRequest(w, fmt.Errorf("ignore is required"))
+		return
+	}
+
+	result, err := s.checkResult(ctx, false)
+	if err != nil {
+		RespondInternalError(w, err)
+		return
+	}
+	members := groupMembers(result, group)
+	if len(members) == 0 {
+		RespondNotFound(w, fmt.Errorf("group '%s' not found", group))
+		return
+	}
+
+	// Generate a batch group ID to link all label operations for this group
+	batchGroupID := uuid.New().String()
+
+	results := make([]BatchLabelResult, 0, len(members))
+	for _, member := range members {
+		labelCtx, cancel := context.WithTimeout(ctx, LabelOperationTimeout)
+		opResult, err := s.setLabelsInGroup(labelCtx, &SetLabelsRequest{
+			Container: member.ContainerName,
+			Ignore:    req.Ignore,
+		}, batchGroupID)
+		cancel()
+
+		if err != nil {
+			results = append(results, BatchLabelResult{
+				Container: member.ContainerName,
+				Success:   false,
+				Error:     err.Error(),
+			})
+			continue
+		}
+		results = append(results, BatchLabelResult{
+			Container:   member.ContainerName,
+			Success:     opResult.Success,
+			OperationID: opResult.OperationID,
+		})
+	}
+
+	// Trigger background check once after all label changes
+	if s.backgroundChecker != nil {
+		s.backgroundChecker.TriggerCheck()
+	}
+
+	RespondSuccess(w, map[string]any{
+		"group":          group,
+		"results":        results,
+		"batch_group_id": batchGroupID,
+	})
+}
+
+// handleGroupScheduleSet sets when a group's available updates are applied automatically
+// PUT /api/groups/schedule/{name}
+// Body: {"period": "weekly", "at": "03:00", "weekday": "sunday"}
+func (s *Server) handleGroupScheduleSet(w http.ResponseWriter, r *http.Request) {
+	if !s.requireGroupScheduler(w) {
+		return
+	}
+
+	group := r.PathValue("name")
+	if !validateRequired(w, "group", group) {
+		return
+	}
+
+	var req GroupSchedule
+	if !decodeJSONRequest(w, r, &req) {
+		return
+	}
+
+	schedule, err := s.groupScheduler.Set(r.Context(), group, req)
+	if err != nil {
+		RespondBadRequest(w, err)
+		return
+	}
+	log.Printf("GROUP: Scheduled updates for group %s %s", group, schedule)
+
+	response := map[string]any{
+		"group":    group,
+		"schedule": req,
+		"summary":  schedule.String(),
+	}
+	if next, ok := s.groupScheduler.NextRun(group); ok {
+		response["next_run"] = next.Format(time.RFC3339)
+	}
+	RespondSuccess(w, response)
+}
+
+// handleGroupScheduleDelete removes a group's update schedule
+// DELETE /api/groups/schedule/{name}
+func (s *Server) handleGroupScheduleDelete(w http.ResponseWriter, r *http.Request) {
+	if !s.requireGroupScheduler(w) {
+		return
+	}
+
+	group := r.PathValue("name")
+	if !validateRequired(w, "group", group) {
+		return
+	}
+
+	deleted, err := s.groupScheduler.Delete(r.Context(), group)
+	if err != nil {
+		RespondInternalError(w, err)
+		return
+	}
+	if !deleted {
+		RespondNotFound(w, fmt.Errorf("group '%s' has no schedule", group))
+		return
+	}
+	log.Printf("GROUP: Removed update schedule for group %s", group)
+
+	RespondSuccess(w, map[string]any{
+		"group":   group,
+		"deleted": true,
+	})
+}
+
+// runScheduledGroupUpdate checks for updates and updates a group's containers,
+// then pings the update monitor. Called by the group scheduler.
+func (s *Server) runScheduledGroupUpdate(ctx context.Context, group string) {
+	err := s.scheduledGroupUpdate(ctx, group)
+	if s.heartbeat != nil {
+		s.heartbeat.UpdateRun(ctx, group, err)
+	}
+}
+
+// scheduledGroupUpdate starts the update of a group's containers with an update
+// available. Returns an error when the check or starting an update failed.
+func (s *Server) scheduledGroupUpdate(ctx context.Context, group string) error {
+	if s.updateOrchestrator == nil {
+		log.Printf("GROUP: Skipping scheduled update for group %s, update orchestrator not available", group)
+		return errNoUpdateOrchestrator
+	}
+	if s.proposals != nil && s.proposals.Enabled(ctx) {
+		log.Printf("GROUP: Skipping scheduled update for group %s in propose-only mode", group)
+		return nil
+	}
+
+	result, err := s.checkResult(ctx, true)
+	if err != nil {
+		log.Printf("GROUP: Scheduled update for group %s failed to check for updates: %v", group, err)
+		return fmt.Errorf("failed to check for updates: %w", err)
+	}
+
+	containers := groupUpdateTargets(groupMembers(result, group))
+	if len(containers) == 0 {
+		log.Printf("GROUP: No updates available for group %s", group)
+		return nil
+	}
+
+	operations, batchGroupID := s.startBatchUpdates(update.WithTrigger(ctx, "schedule:"+group), containers, false)
+	failed := 0
+	for _, op := range operations {
+		if op.Status == "failed" {
+			failed++
+			log.Printf("GROUP: Failed to start scheduled update of %v in group %s: %v", op.Containers, group, op.Error)
+		}
+	}
+	log.Printf("GROUP: Started scheduled update of %d containers in group %s (batch %s)", len(containers), group, batchGroupID)
+	if failed > 0 {
+		return fmt.Errorf("failed to start %d of %d updates", failed, len(operations))
+	}
+	return nil
+}
+
+// requireGroupScheduler checks that group schedules are available
+func (s *Server) requireGroupScheduler(w http.ResponseWriter) bool {
+	if s.groupScheduler == nil {
+		RespondInternalError(w, fmt.Errorf("group scheduler not available"))
+		return false
+	}
+	return true
+}
+
+// groupMembers returns the checked containers belonging to group.
+func groupMembers(result *update.DiscoveryResult, group string) []update.ContainerInfo {
+	var members []update.ContainerInfo
+	for _, c := range result.Containers {
+		if c.InGroup(group) {
+			members = append(members, c)
+		}
+	}
+	return members
+}
+
+// groupUpdateTargets builds batch update entries for the members with an update
+// available. Blocked updates are left out, as they would need force.
+func groupUpdateTargets(members []update.ContainerInfo) []BatchUpdateContainer {
+	var containers []BatchUpdateContainer
+	for _, c := range members {
+		if c.Status != update.UpdateAvailable {
+			continue
+		}
+
+		target := c.RecommendedTag
+		if target == "" {
+			target = c.LatestVersion
+		}
+		newResolved := c.LatestResolvedVersion
+		if newResolved == "" {
+			newResolved = c.LatestVersion
+		}
+		changeType := int(c.ChangeType)
+
+		containers = append(containers, BatchUpdateContainer{
+			Name:               c.ContainerName,
+			TargetVersion:      target,
+			Stack:              c.Stack,
+			ChangeType:         &changeType,
+			OldResolvedVersion: c.CurrentVersion,
+			NewResolvedVersion: newResolved,
+		})
+	}
+	return containers
+}
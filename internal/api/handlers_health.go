@@ -0,0 +1,211 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/chis/docksmith/internal/update"
+)
+
+// Health check statuses. Only failures make the server not ready.
+const (
+	checkOK   = "ok"
+	checkWarn = "warn"
+	checkFail = "fail"
+)
+
+const (
+	// healthCheckTimeout bounds each health check.
+	healthCheckTimeout = 5 * time.Second
+	// queueBacklogWarn is the number of queued updates reported as a backlog.
+	queueBacklogWarn = 10
+)
+
+// errNotReady is returned by /api/ready when a health check fails.
+var errNotReady = errors.New("not ready")
+
+// healthCheck is the result of one health check.
+type healthCheck struct {
+	Name       string  `json:"name"`
+	Status     string  `json:"status"`
+	Message    string  `json:"message,omitempty"`
+	DurationMS float64 `json:"duration_ms"`
+}
+
+// handleReady reports whether the server can do its work: Docker and the
+// database answer and the background checker keeps running. Responds 503 when a
+// check fails. Registry and queue problems are reported as warnings.
+// GET /api/ready
+func (s *Server) handleReady(w http.ResponseWriter, r *http.Request) {
+	checks := s.runHealthChecks(r.Context())
+	ready := checksStatus(checks) != checkFail
+	data := map[string]any{
+		"ready":  ready,
+		"checks": checks,
+	}
+	if !ready {
+		RespondErrorWithData(w, http.StatusServiceUnavailable, errNotReady, data)
+		return
+	}
+	RespondSuccess(w, data)
+}
+
+// runHealthChecks runs the health checks concurrently and returns them ordered by name.
+func (s *Server) runHealthChecks(ctx context.Context) []healthCheck {
+	probes := map[string]func(context.Context) (string, string){
+		"docker":             s.checkDocker,
+		"storage":            s.checkStorage,
+		"queue":              s.checkQueue,
+		"background_checker": s.checkBackgroundChecker,
+	}
+	for _, registry := range s.checkedRegistries() {
+		probes["registry:"+registry] = func(ctx context.Context) (string, string) {
+			if err := s.registryManager.Ping(ctx, registry); err != nil {
+				return checkWarn, err.Error()
+			}
+			return checkOK, ""
+		}
+	}
+
+	var (
+		mu     sync.Mutex
+		wg     sync.WaitGroup
+		checks = make([]healthCheck, 0, len(probes))
+	)
+	for name, probe := range probes {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			checkCtx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+			defer cancel()
+
+			start := time.Now()
+			status, message := probe(checkCtx)
+			check := healthCheck{
+				Name:       name,
+				Status:     status,
+				Message:    message,
+				DurationMS: float64(time.Since(start).Microseconds()) / 1000,
+			}
+			mu.Lock()
+			checks = append(checks, check)
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	sort.Slice(checks, func(i, j int) bool { return checks[i].Name < checks[j].Name })
+	return checks
+}
+
+// checksStatus returns the worst status of checks.
+func checksStatus(checks []healthCheck) string {
+	status := checkOK
+	for _, c := range checks {
+		switch c.Status {
+		case checkFail:
+			return checkFail
+		case checkWarn:
+			status = checkWarn
+		}
+	}
+	return status
+}
+
+// checkDocker pings the Docker daemon.
+func (s *Server) checkDocker(ctx context.Context) (string, string) {
+	if s.dockerService == nil {
+		return checkFail, "docker not connected"
+	}
+	ping, err := s.dockerService.GetClient().Ping(ctx)
+	if err != nil {
+		return checkFail, err.Error()
+	}
+	return checkOK, "API version " + ping.APIVersion
+}
+
+// checkStorage verifies the database accepts writes.
+func (s *Server) checkStorage(ctx context.Context) (string, string) {
+	if s.storageService == nil {
+		return checkWarn, "storage unavailable, history and settings are not persisted"
+	}
+	if err := s.storageService.CheckWritable(ctx); err != nil {
+		return checkFail, err.Error()
+	}
+	return checkOK, ""
+}
+
+// checkQueue reports the number of updates waiting for a stack lock.
+func (s *Server) checkQueue(ctx context.Context) (string, string) {
+	if s.storageService == nil {
+		return checkOK, "queue unavailable"
+	}
+	queued, err := s.storageService.GetQueuedUpdates(ctx)
+	if err != nil {
+		return checkWarn, err.Error()
+	}
+	message := fmt.Sprintf("%d queued updates", len(queued))
+	if len(queued) >= queueBacklogWarn {
+		return checkWarn, message
+	}
+	return checkOK, message
+}
+
+// checkBackgroundChecker fails when no background check completed for more than
+// two intervals plus jitter, counting from startup before the first check.
+func (s *Server) checkBackgroundChecker(ctx context.Context) (string, string) {
+	if s.backgroundChecker == nil {
+		return checkOK, "background checks disabled"
+	}
+	status := s.backgroundChecker.Status()
+	if status.Paused {
+		return checkOK, "paused"
+	}
+	if !status.Running {
+		return checkFail, "not running"
+	}
+
+	lastRun := s.startedAt
+	message := "no check completed yet"
+	if status.LastRun != "" {
+		if t, err := time.Parse(time.RFC3339, status.LastRun); err == nil {
+			lastRun = t
+			message = "last check " + status.LastRun
+		}
+	}
+	jitter, _ := time.ParseDuration(status.Jitter)
+	if since := time.Since(lastRun); since > 2*s.checkInterval+jitter {
+		return checkFail, fmt.Sprintf("stale: %s, %s ago", message, since.Round(time.Second))
+	}
+	return checkOK, message
+}
+
+// checkedRegistries returns the registries of the checked containers' images.
+func (s *Server) checkedRegistries() []string {
+	if s.registryManager == nil || s.backgroundChecker == nil {
+		return nil
+	}
+	result, _, _, _ := s.backgroundChecker.GetCachedResults()
+	if result == nil {
+		return nil
+	}
+
+	seen := make(map[string]bool)
+	var registries []string
+	for _, c := range result.Containers {
+		if c.Image == "" || c.Status == update.LocalImage {
+			continue
+		}
+		registry := s.registryManager.Registry(c.Image)
+		if !seen[registry] {
+			seen[registry] = true
+			registries = append(registries, registry)
+		}
+	}
+	return registries
+}
@@ -9,6 +9,7 @@ import (
 	"strconv"
 
 	"github.com/chis/docksmith/internal/docker"
+	"github.com/chis/docksmith/internal/version"
 )
 
 // Sentinel errors for missing services
@@ -80,6 +81,15 @@ func validateRegexPattern(pattern string) error {
 	return nil
 }
 
+// validateVersionConstraint validates a semver range for the version-constraint label.
+func validateVersionConstraint(constraint string) error {
+	if constraint == "" {
+		return nil // Empty is valid (no constraint)
+	}
+	_, err := version.ParseConstraint(constraint)
+	return err
+}
+
 // findContainerByName searches for a container by name.
 // This is a convenience wrapper around docker.Service.GetContainerByName.
 func (s *Server) findContainerByName(ctx context.Context, containerName string) (*docker.Container, error) {
@@ -0,0 +1,133 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/chis/docksmith/internal/settings"
+	"github.com/chis/docksmith/internal/storage"
+	"github.com/chis/docksmith/internal/update"
+)
+
+// audited records a configuration history snapshot after a successful change,
+// attributed to the user or API key of the request.
+func (s *Server) audited(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		rw := &responseWriter{ResponseWriter: w, statusCode: http.StatusOK}
+		next(rw, r)
+		if s.storageService == nil || rw.statusCode >= http.StatusMultipleChoices {
+			return
+		}
+		if _, err := settings.RecordSnapshot(r.Context(), s.storageService, requestActor(r)); err != nil {
+			log.Printf("CONFIG: Failed to record configuration change: %v", err)
+		}
+	}
+}
+
+// configHistoryEntry is a configuration snapshot with its changes from the previous one
+type configHistoryEntry struct {
+	storage.ConfigSnapshot
+	Changes []settings.Change `json:"changes"`
+}
+
+// handleConfigHistory returns configuration snapshots, newest first, each with
+// the changes it recorded
+// GET /api/config/history?limit=50
+func (s *Server) handleConfigHistory(w http.ResponseWriter, r *http.Request) {
+	if !s.requireStorage(w) {
+		return
+	}
+
+	limit := 50
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		if parsed, err := strconv.Atoi(limitStr); err == nil && parsed > 0 {
+			limit = parsed
+		}
+	}
+
+	// Fetch one more snapshot to diff the oldest one returned against
+	snapshots, err := s.storageService.GetConfigHistory(r.Context(), limit+1)
+	if err != nil {
+		RespondInternalError(w, err)
+		return
+	}
+
+	entries := make([]configHistoryEntry, 0, limit)
+	for i, snapshot := range snapshots {
+		if i == limit {
+			break
+		}
+		var previous map[string]string
+		if i+1 < len(snapshots) {
+			previous = snapshots[i+1].ConfigData
+		}
+		changes := settings.Diff(previous, snapshot.ConfigData)
+		if changes == nil {
+			changes = []settings.Change{}
+		}
+		entries = append(entries, configHistoryEntry{ConfigSnapshot: snapshot, Changes: changes})
+	}
+
+	RespondSuccess(w, map[string]any{
+		"snapshots": entries,
+		"count":     len(entries),
+	})
+}
+
+// handleConfigRevert restores the configuration recorded in a snapshot
+// POST /api/config/history/{id}/revert
+func (s *Server) handleConfigRevert(w http.ResponseWriter, r *http.Request) {
+	if !s.requireStorage(w) {
+		return
+	}
+
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		RespondBadRequest(w, fmt.Errorf("invalid snapshot id: %s", r.PathValue("id")))
+		return
+	}
+
+	ctx := r.Context()
+	if _, found, err := s.storageService.GetConfigSnapshotByID(ctx, id); err != nil {
+		RespondInternalError(w, err)
+		return
+	} else if !found {
+		RespondNotFound(w, fmt.Errorf("snapshot %d not found", id))
+		return
+	}
+
+	result, err := settings.Revert(ctx, s.storageService, id, requestActor(r))
+	if errors.Is(err, settings.ErrNotRevertible) {
+		RespondBadRequest(w, err)
+		return
+	} else if err != nil {
+		RespondInternalError(w, err)
+		return
+	}
+
+	// The paused state and group schedules apply right away; other schedule and
+	// notification changes need a restart, as with an import
+	if s.backgroundChecker != nil {
+		paused, _, _ := s.storageService.GetConfig(ctx, update.CheckerPausedConfigKey)
+		switch wantPaused := paused == "true"; {
+		case wantPaused && !s.backgroundChecker.IsPaused():
+			s.backgroundChecker.Pause()
+		case !wantPaused && s.backgroundChecker.IsPaused():
+			s.backgroundChecker.Resume()
+		}
+	}
+	if s.groupScheduler != nil {
+		s.groupScheduler.Reload(ctx)
+	}
+
+	log.Printf("CONFIG: Reverted configuration to snapshot %d (%d changes)", id, len(result.Changes))
+	RespondSuccess(w, map[string]any{
+		"snapshot_id":      id,
+		"changes":          result.Changes,
+		"skipped":          result.Skipped,
+		"restart_required": true,
+	})
+}
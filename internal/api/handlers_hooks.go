@@ -0,0 +1,145 @@
+package api
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/chis/docksmith/internal/hooks"
+	"github.com/chis/docksmith/internal/update"
+)
+
+// handleHookTrigger checks or updates the containers of an incoming webhook, called
+// by CI after an image push or by a registry notification. Authenticated by the
+// hook token in the path rather than a session or API key. The check runs in the
+// background; the response lists the targeted containers.
+// POST /api/hooks/{token}?image=ghcr.io/org/app:1.2
+func (s *Server) handleHookTrigger(w http.ResponseWriter, r *http.Request) {
+	if s.hooks == nil {
+		RespondInternalError(w, fmt.Errorf("hooks not available"))
+		return
+	}
+	ctx := r.Context()
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxWebhookBodySize))
+	if err != nil {
+		RespondBadRequest(w, fmt.Errorf("failed to read request body"))
+		return
+	}
+
+	hook, err := s.hooks.Authenticate(ctx, r.PathValue("token"))
+	if err != nil {
+		if errors.Is(err, hooks.ErrInvalidToken) {
+			RespondError(w, http.StatusUnauthorized, err)
+		} else {
+			RespondInternalError(w, err)
+		}
+		return
+	}
+
+	event := hooks.ParseEvent(r, body)
+	image := event.Image
+
+	// Events that push nothing, and pushes filtered out by the image pattern,
+	// succeed so senders do not retry them
+	reason := event.Skip
+	if reason == "" && !hook.Accepts(image) {
+		reason = fmt.Sprintf("image does not match %s", hook.ImagePattern)
+	}
+	if reason != "" {
+		log.Printf("HOOK: Ignoring %s request for hook %s: %s", event.Source, hook.Name, reason)
+		RespondSuccess(w, map[string]any{
+			"hook":    hook.Name,
+			"action":  hook.Action,
+			"source":  event.Source,
+			"image":   image,
+			"skipped": true,
+			"reason":  reason,
+		})
+		return
+	}
+
+	if hook.Action == hooks.ActionUpdate {
+		if s.readOnly {
+			RespondError(w, http.StatusForbidden, update.ErrReadOnly)
+			return
+		}
+		if s.proposals != nil && s.proposals.Enabled(ctx) {
+			RespondError(w, http.StatusConflict, errProposeOnly)
+			return
+		}
+	}
+
+	if image == "" && hook.Container == "" && hook.ImagePattern == "" {
+		RespondBadRequest(w, fmt.Errorf("no image in request: pass ?image= or a supported payload"))
+		return
+	}
+
+	containers, err := s.dockerService.ListContainers(ctx)
+	if err != nil {
+		RespondInternalError(w, err)
+		return
+	}
+	targets := hook.Targets(containers, image)
+	if len(targets) == 0 {
+		RespondNotFound(w, fmt.Errorf("no containers match hook %s", hook.Name))
+		return
+	}
+
+	log.Printf("HOOK: %s push of %s triggered %s of %v by hook %s", event.Source, cmp.Or(image, "any image"), hook.Action, targets, hook.Name)
+	go s.runHook(update.WithTrigger(context.Background(), hook.Trigger()), *hook, targets)
+
+	RespondSuccess(w, map[string]any{
+		"hook":       hook.Name,
+		"action":     hook.Action,
+		"source":     event.Source,
+		"image":      image,
+		"containers": targets,
+		"status":     "started",
+	})
+}
+
+// runHook checks the target containers against fresh registry data and, for
+// update hooks, updates those with an update available.
+func (s *Server) runHook(ctx context.Context, hook hooks.Hook, targets []string) {
+	// A hook announces a push, so cached tags are stale
+	s.discoveryOrchestrator.ClearCache()
+	if s.registryManager != nil {
+		s.registryManager.InvalidateTagCache()
+	}
+
+	var checked []update.ContainerInfo
+	for _, name := range targets {
+		info, err := s.discoveryOrchestrator.DiscoverAndCheckSingle(ctx, name)
+		if err != nil {
+			log.Printf("HOOK: Failed to check %s for hook %s: %v", name, hook.Name, err)
+			continue
+		}
+		if info != nil {
+			checked = append(checked, *info)
+		}
+	}
+
+	if hook.Action == hooks.ActionUpdate && s.updateOrchestrator != nil {
+		if containers := groupUpdateTargets(checked); len(containers) > 0 {
+			operations, batchGroupID := s.startBatchUpdates(ctx, containers, false)
+			for _, op := range operations {
+				if op.Status == "failed" {
+					log.Printf("HOOK: Failed to start update of %v for hook %s: %v", op.Containers, hook.Name, op.Error)
+				}
+			}
+			log.Printf("HOOK: Started update of %d containers for hook %s (batch %s)", len(containers), hook.Name, batchGroupID)
+		} else {
+			log.Printf("HOOK: No updates available for hook %s", hook.Name)
+		}
+	}
+
+	// Refresh the dashboard with the new results
+	if s.backgroundChecker != nil {
+		s.backgroundChecker.TriggerCheck()
+	}
+}
@@ -0,0 +1,126 @@
+package api
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/chis/docksmith/internal/storage"
+	"github.com/chis/docksmith/internal/update"
+)
+
+// ignoreRuleResponse is an image ignore rule with the containers it currently ignores
+type ignoreRuleResponse struct {
+	storage.IgnoreRule
+	Containers []string `json:"containers"`
+}
+
+// handleIgnoreRulesList returns the image ignore rules and the containers each one matches
+// GET /api/ignore-rules
+func (s *Server) handleIgnoreRulesList(w http.ResponseWriter, r *http.Request) {
+	if !s.requireStorage(w) {
+		return
+	}
+
+	rules, err := s.storageService.ListIgnoreRules(r.Context())
+	if err != nil {
+		RespondInternalError(w, err)
+		return
+	}
+
+	var containers []string
+	var images []string
+	if s.dockerService != nil {
+		if list, err := s.dockerService.ListContainers(r.Context()); err == nil {
+			for _, c := range list {
+				containers = append(containers, c.Name)
+				images = append(images, c.Image)
+			}
+		}
+	}
+
+	result := make([]ignoreRuleResponse, 0, len(rules))
+	for _, rule := range rules {
+		resp := ignoreRuleResponse{IgnoreRule: rule, Containers: []string{}}
+		for i, image := range images {
+			if update.MatchImagePattern(rule.Pattern, image) {
+				resp.Containers = append(resp.Containers, containers[i])
+			}
+		}
+		result = append(result, resp)
+	}
+
+	RespondSuccess(w, map[string]any{
+		"rules": result,
+		"count": len(result),
+	})
+}
+
+// handleIgnoreRuleCreate adds an image ignore rule, or updates the reason of an existing one
+// POST /api/ignore-rules
+func (s *Server) handleIgnoreRuleCreate(w http.ResponseWriter, r *http.Request) {
+	if !s.requireStorage(w) {
+		return
+	}
+
+	var req struct {
+		Pattern string `json:"pattern"`
+		Reason  string `json:"reason"`
+	}
+	if !decodeJSONRequest(w, r, &req) {
+		return
+	}
+	if err := update.ValidateIgnorePattern(req.Pattern); err != nil {
+		RespondBadRequest(w, err)
+		return
+	}
+
+	rule, err := s.storageService.SaveIgnoreRule(r.Context(), storage.IgnoreRule{Pattern: req.Pattern, Reason: req.Reason})
+	if err != nil {
+		RespondInternalError(w, err)
+		return
+	}
+	log.Printf("IGNORE: Added image ignore rule %q", rule.Pattern)
+
+	// Re-check so matching containers show as ignored
+	if s.backgroundChecker != nil {
+		s.backgroundChecker.TriggerCheck()
+	}
+
+	RespondSuccess(w, rule)
+}
+
+// handleIgnoreRuleDelete removes an image ignore rule
+// DELETE /api/ignore-rules/{id}
+func (s *Server) handleIgnoreRuleDelete(w http.ResponseWriter, r *http.Request) {
+	if !s.requireStorage(w) {
+		return
+	}
+
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		RespondBadRequest(w, fmt.Errorf("invalid ignore rule id %q", r.PathValue("id")))
+		return
+	}
+
+	deleted, err := s.storageService.DeleteIgnoreRule(r.Context(), id)
+	if err != nil {
+		RespondInternalError(w, err)
+		return
+	}
+	if !deleted {
+		RespondNotFound(w, fmt.Errorf("ignore rule %d not found", id))
+		return
+	}
+	log.Printf("IGNORE: Removed image ignore rule %d", id)
+
+	if s.backgroundChecker != nil {
+		s.backgroundChecker.TriggerCheck()
+	}
+
+	RespondSuccess(w, map[string]any{
+		"id":      id,
+		"deleted": true,
+	})
+}
@@ -12,7 +12,10 @@ import (
 	"github.com/chis/docksmith/internal/docker"
 	"github.com/chis/docksmith/internal/events"
 	"github.com/chis/docksmith/internal/scripts"
+	"github.com/chis/docksmith/internal/scripts/builtin"
 	"github.com/chis/docksmith/internal/storage"
+	"github.com/chis/docksmith/internal/update"
+	"github.com/chis/docksmith/internal/version"
 	"github.com/google/uuid"
 )
 
@@ -27,11 +30,15 @@ type SetLabelsRequest struct {
 	VersionPinMajor  *bool   `json:"version_pin_major,omitempty"`
 	VersionPinMinor  *bool   `json:"version_pin_minor,omitempty"`
 	VersionPinPatch  *bool   `json:"version_pin_patch,omitempty"`
+	RequireApproval  *bool   `json:"require_approval,omitempty"`
 	TagRegex         *string `json:"tag_regex,omitempty"`
 	VersionMin       *string `json:"version_min,omitempty"`
 	VersionMax       *string `json:"version_max,omitempty"`
+	VersionConstraint *string `json:"version_constraint,omitempty"`
+	TagPattern        *string `json:"tag_pattern,omitempty"`
 	Script           *string `json:"script,omitempty"`
 	RestartAfter *string `json:"restart_after,omitempty"`
+	Group            *string `json:"group,omitempty"`
 	NoRestart        bool    `json:"no_restart,omitempty"`
 	Force            bool    `json:"force,omitempty"`
 }
@@ -64,14 +71,18 @@ var docksmithLabels = []string{
 	scripts.IgnoreLabel,
 	scripts.AllowLatestLabel,
 	scripts.AllowPrereleaseLabel,
+	scripts.RequireApprovalLabel,
 	scripts.VersionPinMajorLabel,
 	scripts.VersionPinMinorLabel,
 	scripts.VersionPinPatchLabel,
 	scripts.TagRegexLabel,
 	scripts.VersionMinLabel,
 	scripts.VersionMaxLabel,
+	scripts.VersionConstraintLabel,
+	scripts.TagPatternLabel,
 	scripts.PreUpdateCheckLabel,
 	scripts.RestartAfterLabel,
+	scripts.GroupLabel,
 }
 
 // getDocksmithLabels extracts all docksmith labels from a container's labels
@@ -124,9 +135,9 @@ func (s *Server) handleLabelsSet(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if req.Ignore == nil && req.AllowLatest == nil && req.AllowPrerelease == nil && req.VersionPinMajor == nil && req.VersionPinMinor == nil && req.VersionPinPatch == nil &&
-		req.TagRegex == nil && req.VersionMin == nil && req.VersionMax == nil &&
-		req.Script == nil && req.RestartAfter == nil {
+	if req.Ignore == nil && req.AllowLatest == nil && req.AllowPrerelease == nil && req.RequireApproval == nil && req.VersionPinMajor == nil && req.VersionPinMinor == nil && req.VersionPinPatch == nil &&
+		req.TagRegex == nil && req.VersionMin == nil && req.VersionMax == nil && req.VersionConstraint == nil && req.TagPattern == nil &&
+		req.Script == nil && req.RestartAfter == nil && req.Group == nil {
 		RespondBadRequest(w, fmt.Errorf("no labels specified"))
 		return
 	}
@@ -138,6 +149,30 @@ func (s *Server) handleLabelsSet(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 	}
+	if req.Script != nil {
+		if err := builtin.Validate(*req.Script); err != nil {
+			RespondBadRequest(w, err)
+			return
+		}
+		if scripts.IsManagedScript(*req.Script) {
+			if err := scripts.ValidateManagedRef(*req.Script); err != nil {
+				RespondBadRequest(w, err)
+				return
+			}
+		}
+	}
+	if req.VersionConstraint != nil {
+		if err := validateVersionConstraint(*req.VersionConstraint); err != nil {
+			RespondBadRequest(w, err)
+			return
+		}
+	}
+	if req.TagPattern != nil {
+		if err := version.ValidateTagPattern(*req.TagPattern); err != nil {
+			RespondBadRequest(w, err)
+			return
+		}
+	}
 
 	ctx, cancel := context.WithTimeout(r.Context(), LabelOperationTimeout)
 	defer cancel()
@@ -200,7 +235,40 @@ type labelOperationConfig struct {
 }
 
 // labelModifier is a function that applies label changes to a service and returns the modified/removed labels
-type labelModifier func(service *compose.Service) (modified map[string]string, removed []string, err error)
+type labelModifier func(service labelEditor) (modified map[string]string, removed []string, err error)
+
+// labelEditor edits the labels of a compose service, or the stored settings of a
+// container not managed by docker compose.
+type labelEditor interface {
+	SetLabel(key, value string) error
+	RemoveLabel(key string) error
+}
+
+// assignmentLabels edits the settings of a container not managed by docker compose.
+// Its labels cannot be changed without recreating it by hand, so the settings are
+// stored in the script_assignments table and applied from the next check. Only the
+// ignore, allow-latest, and pre-update check settings can be stored.
+type assignmentLabels struct {
+	assignment *storage.ScriptAssignment
+}
+
+func (a assignmentLabels) SetLabel(key, value string) error {
+	switch key {
+	case scripts.IgnoreLabel:
+		a.assignment.Ignore = value == "true"
+	case scripts.AllowLatestLabel:
+		a.assignment.AllowLatest = value == "true"
+	case scripts.PreUpdateCheckLabel:
+		a.assignment.ScriptPath = value
+	default:
+		return fmt.Errorf("%s can only be changed on containers managed by docker compose", key)
+	}
+	return nil
+}
+
+func (a assignmentLabels) RemoveLabel(key string) error {
+	return a.SetLabel(key, "")
+}
 
 // executeLabelOperation is the common workflow for both set and remove operations.
 // It validates inputs, creates an operation record, and starts the work in a background
@@ -231,15 +299,16 @@ func (s *Server) executeLabelOperation(ctx context.Context, cfg labelOperationCo
 		return nil, fmt.Errorf("failed to marshal old labels: %w", err)
 	}
 
-	// Get compose file path (validation - must complete before returning)
-	composeFilePath, ok := container.Labels[ComposeConfigFilesLabel]
-	if !ok || composeFilePath == "" {
+	// Get compose file path (validation - must complete before returning).
+	// Settings of other containers are stored in the database instead.
+	composeFilePath := container.Labels[ComposeConfigFilesLabel]
+	if composeFilePath != "" {
+		// Translate host path to container path
+		composeFilePath = s.pathTranslator.TranslateToContainer(composeFilePath)
+	} else if s.storageService == nil {
 		return nil, fmt.Errorf("container %s is not managed by docker compose", container.Name)
 	}
 
-	// Translate host path to container path
-	composeFilePath = s.pathTranslator.TranslateToContainer(composeFilePath)
-
 	result.ComposeFile = composeFilePath
 	serviceName := container.Labels[ComposeServiceLabel]
 	stackName := container.Labels[ComposeProjectLabel]
@@ -248,6 +317,7 @@ func (s *Server) executeLabelOperation(ctx context.Context, cfg labelOperationCo
 	now := time.Now()
 	op := storage.UpdateOperation{
 		OperationID:   operationID,
+		TriggeredBy:   update.TriggerFromContext(ctx),
 		ContainerID:   container.ID,
 		ContainerName: container.Name,
 		StackName:     stackName,
@@ -290,28 +360,19 @@ func (s *Server) executeLabelOperationAsync(ctx context.Context, operationID str
 	}
 
 	// Stage 2: Load and modify compose file (10-30%)
-	s.publishLabelProgress(operationID, cfg.containerName, stackName, "updating_compose", 10, "Saving settings to compose file")
-
-	composeFile, err := compose.LoadComposeFileOrIncluded(composeFilePath, cfg.containerName)
-	if err != nil {
-		s.failLabelOperationWithEvent(ctx, operationID, cfg.containerName, stackName, fmt.Sprintf("failed to load compose file: %v", err))
-		return
-	}
-
-	service, err := composeFile.FindServiceByContainerName(serviceName)
-	if err != nil {
-		s.failLabelOperationWithEvent(ctx, operationID, cfg.containerName, stackName, fmt.Sprintf("failed to find service: %v", err))
-		return
+	var modified map[string]string
+	var removed []string
+	if composeFilePath == "" {
+		s.publishLabelProgress(operationID, cfg.containerName, stackName, "updating_compose", 10, "Saving settings to database")
+		modified, removed, err = s.saveStoredLabels(ctx, container, modify)
+		// Stored settings apply from the next check without recreating the container
+		cfg.noRestart = true
+	} else {
+		s.publishLabelProgress(operationID, cfg.containerName, stackName, "updating_compose", 10, "Saving settings to compose file")
+		modified, removed, err = saveComposeLabels(composeFilePath, cfg.containerName, serviceName, modify)
 	}
-
-	modified, removed, err := modify(service)
 	if err != nil {
-		s.failLabelOperationWithEvent(ctx, operationID, cfg.containerName, stackName, fmt.Sprintf("failed to apply labels: %v", err))
-		return
-	}
-
-	if err := composeFile.Save(); err != nil {
-		s.failLabelOperationWithEvent(ctx, operationID, cfg.containerName, stackName, fmt.Sprintf("failed to save compose file: %v", err))
+		s.failLabelOperationWithEvent(ctx, operationID, cfg.containerName, stackName, err.Error())
 		return
 	}
 
@@ -361,6 +422,61 @@ func (s *Server) executeLabelOperationAsync(ctx context.Context, operationID str
 	log.Printf("LABEL_OP: Completed label operation %s for container %s", operationID, cfg.containerName)
 }
 
+// saveComposeLabels applies label changes to the service of a container in its compose file.
+func saveComposeLabels(composeFilePath, containerName, serviceName string, modify labelModifier) (map[string]string, []string, error) {
+	composeFile, err := compose.LoadComposeFileOrIncluded(composeFilePath, containerName)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load compose file: %w", err)
+	}
+
+	service, err := composeFile.FindServiceByContainerName(serviceName)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to find service: %w", err)
+	}
+
+	modified, removed, err := modify(service)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to apply labels: %w", err)
+	}
+
+	if err := composeFile.Save(); err != nil {
+		return nil, nil, fmt.Errorf("failed to save compose file: %w", err)
+	}
+	return modified, removed, nil
+}
+
+// saveStoredLabels applies label changes to the stored settings of a container not
+// managed by docker compose. The first change starts from the container's own labels.
+func (s *Server) saveStoredLabels(ctx context.Context, container *docker.Container, modify labelModifier) (map[string]string, []string, error) {
+	assignment, found, err := s.storageService.GetScriptAssignment(ctx, container.Name)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load stored settings: %w", err)
+	}
+	now := time.Now()
+	if !found {
+		assignment = storage.ScriptAssignment{
+			ContainerName: container.Name,
+			ScriptPath:    container.Labels[scripts.PreUpdateCheckLabel],
+			Ignore:        container.Labels[scripts.IgnoreLabel] == "true",
+			AllowLatest:   container.Labels[scripts.AllowLatestLabel] == "true",
+			AssignedAt:    now,
+		}
+	}
+	assignment.Enabled = true
+	assignment.AssignedBy = "ui"
+	assignment.UpdatedAt = now
+
+	modified, removed, err := modify(assignmentLabels{&assignment})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to apply labels: %w", err)
+	}
+
+	if err := s.storageService.SaveScriptAssignment(ctx, assignment); err != nil {
+		return nil, nil, fmt.Errorf("failed to save settings: %w", err)
+	}
+	return modified, removed, nil
+}
+
 // setLabels implements the label setting logic (atomic: compose update + restart)
 func (s *Server) setLabels(ctx context.Context, req *SetLabelsRequest) (*LabelOperationResult, error) {
 	return s.setLabelsWithConfig(ctx, req, "")
@@ -379,7 +495,7 @@ func (s *Server) setLabelsWithConfig(ctx context.Context, req *SetLabelsRequest,
 		noRestart:     req.NoRestart,
 		force:         req.Force,
 		batchGroupID:  batchGroupID,
-	}, func(service *compose.Service) (map[string]string, []string, error) {
+	}, func(service labelEditor) (map[string]string, []string, error) {
 		modified := make(map[string]string)
 
 		// Apply boolean label updates
@@ -390,6 +506,7 @@ func (s *Server) setLabelsWithConfig(ctx context.Context, req *SetLabelsRequest,
 			{req.Ignore, scripts.IgnoreLabel},
 			{req.AllowLatest, scripts.AllowLatestLabel},
 			{req.AllowPrerelease, scripts.AllowPrereleaseLabel},
+			{req.RequireApproval, scripts.RequireApprovalLabel},
 			{req.VersionPinMajor, scripts.VersionPinMajorLabel},
 			{req.VersionPinMinor, scripts.VersionPinMinorLabel},
 			{req.VersionPinPatch, scripts.VersionPinPatchLabel},
@@ -405,12 +522,22 @@ func (s *Server) setLabelsWithConfig(ctx context.Context, req *SetLabelsRequest,
 			}
 		}
 
-		// Validate tag regex before applying
+		// Validate tag regex and version constraint before applying
 		if req.TagRegex != nil && *req.TagRegex != "" {
 			if err := validateRegexPattern(*req.TagRegex); err != nil {
 				return nil, nil, fmt.Errorf("invalid tag regex: %w", err)
 			}
 		}
+		if req.VersionConstraint != nil {
+			if err := validateVersionConstraint(*req.VersionConstraint); err != nil {
+				return nil, nil, err
+			}
+		}
+		if req.TagPattern != nil {
+			if err := version.ValidateTagPattern(*req.TagPattern); err != nil {
+				return nil, nil, err
+			}
+		}
 
 		// Apply string label updates
 		stringLabels := []struct {
@@ -420,8 +547,11 @@ func (s *Server) setLabelsWithConfig(ctx context.Context, req *SetLabelsRequest,
 			{req.TagRegex, scripts.TagRegexLabel},
 			{req.VersionMin, scripts.VersionMinLabel},
 			{req.VersionMax, scripts.VersionMaxLabel},
+			{req.VersionConstraint, scripts.VersionConstraintLabel},
+			{req.TagPattern, scripts.TagPatternLabel},
 			{req.Script, scripts.PreUpdateCheckLabel},
 			{req.RestartAfter, scripts.RestartAfterLabel},
+			{req.Group, scripts.GroupLabel},
 		}
 
 		for _, sl := range stringLabels {
@@ -445,7 +575,7 @@ func (s *Server) removeLabels(ctx context.Context, req *RemoveLabelsRequest) (*L
 		operationType: "remove",
 		noRestart:     req.NoRestart,
 		force:         req.Force,
-	}, func(service *compose.Service) (map[string]string, []string, error) {
+	}, func(service labelEditor) (map[string]string, []string, error) {
 		// Remove all specified labels
 		for _, labelName := range req.LabelNames {
 			if err := service.RemoveLabel(labelName); err != nil {
@@ -582,7 +712,7 @@ func (s *Server) restartContainerByService(ctx context.Context, composeFilePath,
 	// Use compose-based recreation (preferred method)
 	// This handles all dependencies, network modes, hostname conflicts, etc. automatically
 	recreator := compose.NewRecreator(s.dockerService)
-	if err := recreator.RecreateWithCompose(ctx, targetContainer, hostComposePath, composeFilePath); err != nil {
+	if err := recreator.RecreateWithCompose(update.WithStackEnv(ctx, s.storageService), targetContainer, hostComposePath, composeFilePath); err != nil {
 		return fmt.Errorf("failed to recreate container with compose: %w", err)
 	}
 
@@ -592,7 +722,7 @@ func (s *Server) restartContainerByService(ctx context.Context, composeFilePath,
 // applyBoolLabel applies a boolean label change to a service.
 // If value is true, sets the label to "true". If false, removes the label.
 // Returns the value to store in LabelsModified ("true" or "").
-func applyBoolLabel(service *compose.Service, labelKey string, value *bool) (string, error) {
+func applyBoolLabel(service labelEditor, labelKey string, value *bool) (string, error) {
 	if value == nil {
 		return "", nil // No change requested
 	}
@@ -613,7 +743,7 @@ func applyBoolLabel(service *compose.Service, labelKey string, value *bool) (str
 // applyStringLabel applies a string label change to a service.
 // If value is empty, removes the label. Otherwise sets it.
 // Returns the value to store in LabelsModified.
-func applyStringLabel(service *compose.Service, labelKey string, value *string) (string, error) {
+func applyStringLabel(service labelEditor, labelKey string, value *string) (string, error) {
 	if value == nil {
 		return "", nil // No change requested
 	}
@@ -822,6 +952,9 @@ func (s *Server) setRollbackLabel(req *SetLabelsRequest, labelKey, value string)
 	case scripts.AllowPrereleaseLabel:
 		v := value == "true"
 		req.AllowPrerelease = &v
+	case scripts.RequireApprovalLabel:
+		v := value == "true"
+		req.RequireApproval = &v
 	case scripts.VersionPinMajorLabel:
 		v := value == "true"
 		req.VersionPinMajor = &v
@@ -837,10 +970,16 @@ func (s *Server) setRollbackLabel(req *SetLabelsRequest, labelKey, value string)
 		req.VersionMin = &value
 	case scripts.VersionMaxLabel:
 		req.VersionMax = &value
+	case scripts.VersionConstraintLabel:
+		req.VersionConstraint = &value
+	case scripts.TagPatternLabel:
+		req.TagPattern = &value
 	case scripts.PreUpdateCheckLabel:
 		req.Script = &value
 	case scripts.RestartAfterLabel:
 		req.RestartAfter = &value
+	case scripts.GroupLabel:
+		req.Group = &value
 	}
 }
 
@@ -896,6 +1035,26 @@ func (s *Server) handleBatchLabels(w http.ResponseWriter, r *http.Request) {
 				continue
 			}
 		}
+		if op.VersionConstraint != nil {
+			if err := validateVersionConstraint(*op.VersionConstraint); err != nil {
+				results = append(results, BatchLabelResult{
+					Container: op.Container,
+					Success:   false,
+					Error:     err.Error(),
+				})
+				continue
+			}
+		}
+		if op.TagPattern != nil {
+			if err := version.ValidateTagPattern(*op.TagPattern); err != nil {
+				results = append(results, BatchLabelResult{
+					Container: op.Container,
+					Success:   false,
+					Error:     err.Error(),
+				})
+				continue
+			}
+		}
 
 		// Reuse the existing setLabels logic with batch group ID
 		opCopy := op
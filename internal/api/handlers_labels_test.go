@@ -1,9 +1,13 @@
 package api
 
 import (
+	"context"
+	"strings"
 	"testing"
 
+	"github.com/chis/docksmith/internal/docker"
 	"github.com/chis/docksmith/internal/scripts"
+	"github.com/chis/docksmith/internal/storage"
 )
 
 // TestSetRollbackLabelCompleteness verifies that setRollbackLabel handles all known docksmith labels.
@@ -27,6 +31,10 @@ func TestSetRollbackLabelCompleteness(t *testing.T) {
 		{scripts.VersionMaxLabel, "9.0", func(r *SetLabelsRequest) bool { return r.VersionMax != nil }},
 		{scripts.PreUpdateCheckLabel, "/path/to/script", func(r *SetLabelsRequest) bool { return r.Script != nil }},
 		{scripts.RestartAfterLabel, "some-container", func(r *SetLabelsRequest) bool { return r.RestartAfter != nil }},
+		{scripts.RequireApprovalLabel, "true", func(r *SetLabelsRequest) bool { return r.RequireApproval != nil }},
+		{scripts.VersionConstraintLabel, "^2.4", func(r *SetLabelsRequest) bool { return r.VersionConstraint != nil }},
+		{scripts.TagPatternLabel, "linuxserver", func(r *SetLabelsRequest) bool { return r.TagPattern != nil }},
+		{scripts.GroupLabel, "media", func(r *SetLabelsRequest) bool { return r.Group != nil }},
 	}
 
 	s := &Server{}
@@ -54,3 +62,38 @@ func TestSetRollbackLabelCompleteness(t *testing.T) {
 		}
 	}
 }
+
+func TestSaveStoredLabels(t *testing.T) {
+	ctx := context.Background()
+	s := &Server{storageService: storage.NewMemoryStorage()}
+	cont := &docker.Container{Name: "standalone", Labels: map[string]string{scripts.AllowLatestLabel: "true"}}
+
+	ignore := true
+	modified, _, err := s.saveStoredLabels(ctx, cont, func(service labelEditor) (map[string]string, []string, error) {
+		value, err := applyBoolLabel(service, scripts.IgnoreLabel, &ignore)
+		return map[string]string{scripts.IgnoreLabel: value}, nil, err
+	})
+	if err != nil {
+		t.Fatalf("saveStoredLabels failed: %v", err)
+	}
+	if modified[scripts.IgnoreLabel] != "true" {
+		t.Errorf("expected ignore to be modified, got %v", modified)
+	}
+
+	assignment, found, err := s.storageService.GetScriptAssignment(ctx, "standalone")
+	if err != nil || !found {
+		t.Fatalf("expected stored settings, found=%v err=%v", found, err)
+	}
+	if !assignment.Ignore || !assignment.AllowLatest || !assignment.Enabled {
+		t.Errorf("expected ignore and the container's allow-latest label to be stored, got %+v", assignment)
+	}
+
+	regex := "^v1"
+	_, _, err = s.saveStoredLabels(ctx, cont, func(service labelEditor) (map[string]string, []string, error) {
+		_, err := applyStringLabel(service, scripts.TagRegexLabel, &regex)
+		return nil, nil, err
+	})
+	if err == nil || !strings.Contains(err.Error(), "docker compose") {
+		t.Errorf("expected tag regex to be rejected for a container not managed by docker compose, got %v", err)
+	}
+}
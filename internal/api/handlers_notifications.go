@@ -0,0 +1,148 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/chis/docksmith/internal/notify"
+)
+
+// errNotificationsDisabled is returned when no notification channel is configured.
+var errNotificationsDisabled = errors.New("notifications are not configured; set NOTIFY_WEBHOOK_URL or another NOTIFY_* channel")
+
+// handleNotificationTemplates returns the message templates by channel
+// GET /api/notifications/templates
+func (s *Server) handleNotificationTemplates(w http.ResponseWriter, r *http.Request) {
+	if !s.requireNotifier(w) {
+		return
+	}
+
+	RespondSuccess(w, map[string]any{
+		"templates":          s.notifier.Templates(),
+		"channels":           s.notifier.Channels(),
+		"available_channels": notify.TemplateChannels,
+	})
+}
+
+// handleNotificationTemplateSet replaces the message template of a channel
+// PUT /api/notifications/templates/{channel}
+func (s *Server) handleNotificationTemplateSet(w http.ResponseWriter, r *http.Request) {
+	if !s.requireNotifier(w) {
+		return
+	}
+
+	var tmpl notify.Template
+	if !decodeJSONRequest(w, r, &tmpl) {
+		return
+	}
+
+	channel := r.PathValue("channel")
+	if err := s.notifier.SetTemplate(r.Context(), channel, tmpl); err != nil {
+		RespondBadRequest(w, err)
+		return
+	}
+
+	RespondSuccess(w, map[string]any{
+		"channel":  channel,
+		"template": tmpl,
+	})
+}
+
+// handleNotificationTemplateDelete restores the default messages of a channel
+// DELETE /api/notifications/templates/{channel}
+func (s *Server) handleNotificationTemplateDelete(w http.ResponseWriter, r *http.Request) {
+	if !s.requireNotifier(w) {
+		return
+	}
+
+	channel := r.PathValue("channel")
+	if err := s.notifier.SetTemplate(r.Context(), channel, notify.Template{}); err != nil {
+		RespondBadRequest(w, err)
+		return
+	}
+
+	RespondSuccess(w, map[string]any{
+		"channel": channel,
+		"deleted": true,
+	})
+}
+
+// notificationSampleRequest selects a sample message and, for previews, the template to render it with.
+type notificationSampleRequest struct {
+	Channel string  `json:"channel"`
+	Kind    string  `json:"kind"`  // update (default), digest, or failure
+	Title   *string `json:"title"` // Template to preview instead of the stored one
+	Text    *string `json:"text"`
+}
+
+// handleNotificationPreview renders a sample message with a template, without sending it.
+// Without title and text the channel's stored template is used.
+// POST /api/notifications/preview
+func (s *Server) handleNotificationPreview(w http.ResponseWriter, r *http.Request) {
+	if !s.requireNotifier(w) {
+		return
+	}
+
+	var req notificationSampleRequest
+	if !decodeJSONRequest(w, r, &req) {
+		return
+	}
+
+	var tmpl *notify.Template
+	if req.Title != nil || req.Text != nil {
+		tmpl = &notify.Template{}
+		if req.Title != nil {
+			tmpl.Title = *req.Title
+		}
+		if req.Text != nil {
+			tmpl.Text = *req.Text
+		}
+	}
+
+	msg, err := s.notifier.Preview(req.Channel, req.Kind, tmpl)
+	if err != nil {
+		RespondBadRequest(w, err)
+		return
+	}
+	RespondSuccess(w, msg)
+}
+
+// handleNotificationTest sends a sample message through a channel, or through
+// every channel when none is given, using the stored templates.
+// POST /api/notifications/test
+func (s *Server) handleNotificationTest(w http.ResponseWriter, r *http.Request) {
+	if !s.requireNotifier(w) {
+		return
+	}
+
+	var req notificationSampleRequest
+	if !decodeJSONRequest(w, r, &req) {
+		return
+	}
+
+	sent, err := s.notifier.SendTest(r.Context(), req.Channel, req.Kind)
+	switch {
+	case errors.Is(err, notify.ErrInvalidKind):
+		RespondBadRequest(w, err)
+		return
+	case errors.Is(err, notify.ErrChannelNotConfigured):
+		RespondNotFound(w, err)
+		return
+	case err != nil:
+		RespondError(w, http.StatusBadGateway, err)
+		return
+	}
+
+	RespondSuccess(w, map[string]any{
+		"sent": sent,
+	})
+}
+
+// requireNotifier responds with 404 and returns false when notifications are not configured.
+func (s *Server) requireNotifier(w http.ResponseWriter) bool {
+	if s.notifier == nil {
+		RespondNotFound(w, errNotificationsDisabled)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,452 @@
+package api
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+
+	"github.com/chis/docksmith/internal/auth"
+	"github.com/chis/docksmith/internal/docker"
+	"github.com/chis/docksmith/internal/events"
+	"github.com/chis/docksmith/internal/storage"
+	"github.com/chis/docksmith/internal/update"
+)
+
+// errHostWideScoped is returned for host-wide operations by principals whose
+// access is limited to the stacks they own.
+var errHostWideScoped = errors.New("host-wide operations are limited to admins while stacks have owners")
+
+// requestScope returns the ownership scope of the request's principal, nil when
+// unrestricted. Responds with an error and returns false if it cannot be resolved.
+func (s *Server) requestScope(w http.ResponseWriter, r *http.Request) (*auth.OwnershipScope, bool) {
+	scope, err := s.ownership.ScopeFor(r.Context(), auth.PrincipalFromContext(r.Context()))
+	if err != nil {
+		RespondInternalError(w, err)
+		return nil, false
+	}
+	return scope, true
+}
+
+// containerStacks maps the names of the current containers to their stacks.
+// Containers that no longer exist are missing, so only their own owners apply.
+func (s *Server) containerStacks(ctx context.Context) map[string]string {
+	stacks := make(map[string]string)
+	if s.dockerService == nil {
+		return stacks
+	}
+	containers, err := s.dockerService.ListContainers(ctx)
+	if err != nil {
+		log.Printf("Failed to list containers for ownership checks: %v", err)
+		return stacks
+	}
+	for _, c := range containers {
+		stacks[c.Name] = c.Stack
+	}
+	return stacks
+}
+
+// allowContainers responds 404 and returns false unless the request may access
+// every named container.
+func (s *Server) allowContainers(w http.ResponseWriter, r *http.Request, names ...string) bool {
+	scope, ok := s.requestScope(w, r)
+	if !ok {
+		return false
+	}
+	if scope == nil {
+		return true
+	}
+	stacks := s.containerStacks(r.Context())
+	for _, name := range names {
+		if !scope.Allows(stacks[name], name) {
+			RespondNotFound(w, fmt.Errorf("container '%s' not found", name))
+			return false
+		}
+	}
+	return true
+}
+
+// allowStack responds 404 and returns false unless the request may access stack.
+func (s *Server) allowStack(w http.ResponseWriter, r *http.Request, stack string) bool {
+	scope, ok := s.requestScope(w, r)
+	if !ok {
+		return false
+	}
+	if !scope.Allows(stack, "") {
+		RespondNotFound(w, fmt.Errorf("stack '%s' not found", stack))
+		return false
+	}
+	return true
+}
+
+// ownedContainer wraps a handler whose path parameter param names a container,
+// responding 404 for containers outside the scope of the request
+func (s *Server) ownedContainer(param string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if name := r.PathValue(param); name != "" && !s.allowContainers(w, r, name) {
+			return
+		}
+		next(w, r)
+	}
+}
+
+// ownedStack wraps a handler whose path parameter param names a stack,
+// responding 404 for stacks outside the scope of the request
+func (s *Server) ownedStack(param string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if name := r.PathValue(param); name != "" && !s.allowStack(w, r, name) {
+			return
+		}
+		next(w, r)
+	}
+}
+
+// unlessScoped wraps a host-wide handler, such as pruning images, that only
+// principals with unrestricted access may use
+func (s *Server) unlessScoped(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		scope, ok := s.requestScope(w, r)
+		if !ok {
+			return
+		}
+		if scope != nil {
+			RespondError(w, http.StatusForbidden, errHostWideScoped)
+			return
+		}
+		next(w, r)
+	}
+}
+
+// ownedResource wraps a handler whose {id} path parameter names a resource of
+// the given kind. lookup reports whether a scope includes the resource, and
+// returns nil for resources that do not exist, which the handler reports.
+func (s *Server) ownedResource(kind string, lookup func(ctx context.Context, id string) (func(*auth.OwnershipScope) bool, error), next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		scope, ok := s.requestScope(w, r)
+		if !ok {
+			return
+		}
+		if scope != nil && s.storageService != nil {
+			allows, err := lookup(r.Context(), r.PathValue("id"))
+			if err != nil {
+				RespondInternalError(w, err)
+				return
+			}
+			if allows != nil && !allows(scope) {
+				RespondNotFound(w, fmt.Errorf("%s not found", kind))
+				return
+			}
+		}
+		next(w, r)
+	}
+}
+
+// ownedOperation wraps a handler of an update operation's {id}
+func (s *Server) ownedOperation(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !s.allowOperation(w, r, r.PathValue("id")) {
+			return
+		}
+		next(w, r)
+	}
+}
+
+// allowOperation responds 404 and returns false unless the request may access
+// the update operation with the given ID. Unknown operations are left to the caller.
+func (s *Server) allowOperation(w http.ResponseWriter, r *http.Request, id string) bool {
+	scope, ok := s.requestScope(w, r)
+	if !ok {
+		return false
+	}
+	if scope == nil || s.storageService == nil {
+		return true
+	}
+	op, found, err := s.storageService.GetUpdateOperation(r.Context(), id)
+	if err != nil {
+		RespondInternalError(w, err)
+		return false
+	}
+	if found && !allowsOperation(scope, s.containerStacks(r.Context()), op) {
+		RespondNotFound(w, fmt.Errorf("operation not found"))
+		return false
+	}
+	return true
+}
+
+// ownedApproval wraps a handler of an approval's {id}
+func (s *Server) ownedApproval(next http.HandlerFunc) http.HandlerFunc {
+	return s.ownedResource("approval", func(ctx context.Context, id string) (func(*auth.OwnershipScope) bool, error) {
+		a, found, err := s.storageService.GetApproval(ctx, id)
+		if err != nil || !found {
+			return nil, err
+		}
+		return func(scope *auth.OwnershipScope) bool { return scope.Allows(a.StackName, a.ContainerName) }, nil
+	}, next)
+}
+
+// ownedProposal wraps a handler of a proposal's {id}
+func (s *Server) ownedProposal(next http.HandlerFunc) http.HandlerFunc {
+	return s.ownedResource("proposal", func(ctx context.Context, id string) (func(*auth.OwnershipScope) bool, error) {
+		p, found, err := s.storageService.GetProposal(ctx, id)
+		if err != nil || !found {
+			return nil, err
+		}
+		return func(scope *auth.OwnershipScope) bool { return scope.Allows(p.StackName, p.ContainerName) }, nil
+	}, next)
+}
+
+// allowsOperation reports whether a scope includes every container an
+// operation touched. stacks resolves containers recorded without their stack.
+func allowsOperation(scope *auth.OwnershipScope, stacks map[string]string, op storage.UpdateOperation) bool {
+	if scope == nil {
+		return true
+	}
+	if op.ContainerName == "" && len(op.BatchDetails) == 0 {
+		return scope.Allows(op.StackName, "")
+	}
+	if op.ContainerName != "" && !scope.Allows(cmp.Or(op.StackName, stacks[op.ContainerName]), op.ContainerName) {
+		return false
+	}
+	for _, detail := range op.BatchDetails {
+		if !scope.Allows(cmp.Or(detail.StackName, stacks[detail.ContainerName]), detail.ContainerName) {
+			return false
+		}
+	}
+	return true
+}
+
+// scopeOperations returns the operations a scope includes
+func (s *Server) scopeOperations(ctx context.Context, scope *auth.OwnershipScope, ops []storage.UpdateOperation) []storage.UpdateOperation {
+	if scope == nil {
+		return ops
+	}
+	stacks := s.containerStacks(ctx)
+	return slices.DeleteFunc(ops, func(op storage.UpdateOperation) bool {
+		return !allowsOperation(scope, stacks, op)
+	})
+}
+
+// eventAllowed reports whether a scope includes the container or stack an event
+// is about. Events about neither, like check progress, are shared.
+func eventAllowed(scope *auth.OwnershipScope, stacks map[string]string, event events.Event) bool {
+	if scope == nil {
+		return true
+	}
+	container, _ := event.Payload["container_name"].(string)
+	stack, _ := event.Payload["stack_name"].(string)
+	if stack == "" {
+		stack, _ = event.Payload["stack"].(string)
+	}
+	return scope.Allows(cmp.Or(stack, stacks[container]), container)
+}
+
+// scopeResult returns the part of a check result a scope includes, with its
+// counts and groups recomputed. The result itself is not modified, so cached
+// results can be passed.
+func scopeResult(scope *auth.OwnershipScope, result *update.DiscoveryResult) *update.DiscoveryResult {
+	if scope == nil || result == nil {
+		return result
+	}
+
+	allowed := func(c update.ContainerInfo) bool { return scope.Allows(c.Stack, c.ContainerName) }
+	scoped := *result
+	scoped.Containers = filterContainers(result.Containers, allowed)
+	scoped.StandaloneContainers = filterContainers(result.StandaloneContainers, allowed)
+
+	names := make(map[string]bool, len(scoped.Containers))
+	scoped.TotalChecked = len(scoped.Containers)
+	scoped.UpdatesFound, scoped.UpToDate, scoped.LocalImages, scoped.Failed, scoped.Ignored = 0, 0, 0, 0, 0
+	for _, c := range scoped.Containers {
+		names[c.ContainerName] = true
+		switch c.Status {
+		case update.UpdateAvailable:
+			scoped.UpdatesFound++
+		case update.UpToDate:
+			scoped.UpToDate++
+		case update.LocalImage:
+			scoped.LocalImages++
+		case update.CheckFailed:
+			scoped.Failed++
+		case update.Ignored:
+			scoped.Ignored++
+		}
+	}
+
+	scoped.Stacks = make(map[string]*update.Stack)
+	for name, stack := range result.Stacks {
+		containers := filterContainers(stack.Containers, allowed)
+		if len(containers) == 0 {
+			continue
+		}
+		stackCopy := *stack
+		stackCopy.Containers = containers
+		scoped.Stacks[name] = &stackCopy
+	}
+
+	scoped.UpdateOrder = make([]string, 0, len(result.UpdateOrder))
+	for _, name := range result.UpdateOrder {
+		if names[name] {
+			scoped.UpdateOrder = append(scoped.UpdateOrder, name)
+		}
+	}
+
+	scoped.DependencyCycles = nil
+	for _, cycle := range result.DependencyCycles {
+		if !slices.ContainsFunc(cycle.Containers, func(name string) bool { return !names[name] }) {
+			scoped.DependencyCycles = append(scoped.DependencyCycles, cycle)
+		}
+	}
+
+	scoped.Groups = update.BuildGroups(scoped.Containers)
+	return &scoped
+}
+
+// filterContainers returns a new slice of the containers keep returns true for
+func filterContainers(containers []update.ContainerInfo, keep func(update.ContainerInfo) bool) []update.ContainerInfo {
+	filtered := make([]update.ContainerInfo, 0, len(containers))
+	for _, c := range containers {
+		if keep(c) {
+			filtered = append(filtered, c)
+		}
+	}
+	return filtered
+}
+
+// scopeDockerContainers returns the Docker containers a scope includes
+func scopeDockerContainers(scope *auth.OwnershipScope, containers []docker.Container) []docker.Container {
+	if scope == nil {
+		return containers
+	}
+	return slices.DeleteFunc(containers, func(c docker.Container) bool { return !scope.Allows(c.Stack, c.Name) })
+}
+
+// OwnershipResponse is the ownership of stacks and containers with the team members
+type OwnershipResponse struct {
+	Ownership []storage.Ownership `json:"ownership"`
+	Teams     map[string][]string `json:"teams"`
+	Count     int                 `json:"count"`
+}
+
+// handleOwnershipList returns the owners of stacks and containers and the members of each team
+// GET /api/ownership
+func (s *Server) handleOwnershipList(w http.ResponseWriter, r *http.Request) {
+	if !s.requireOwnership(w) {
+		return
+	}
+
+	ownership, err := s.ownership.List(r.Context())
+	if err != nil {
+		RespondInternalError(w, err)
+		return
+	}
+	teams, err := s.ownership.Teams(r.Context())
+	if err != nil {
+		RespondInternalError(w, err)
+		return
+	}
+
+	RespondSuccess(w, OwnershipResponse{Ownership: ownership, Teams: teams, Count: len(ownership)})
+}
+
+// handleOwnerAdd assigns a stack or container to an owner
+// PUT /api/ownership/{type}/{name}/{owner}
+func (s *Server) handleOwnerAdd(w http.ResponseWriter, r *http.Request) {
+	if !s.requireOwnership(w) {
+		return
+	}
+
+	entityType, entityID, owner := r.PathValue("type"), r.PathValue("name"), r.PathValue("owner")
+	if err := s.ownership.Assign(r.Context(), entityType, entityID, owner); err != nil {
+		respondOwnershipError(w, err)
+		return
+	}
+
+	log.Printf("OWNERSHIP: %s assigned %s %s to %s", requestActor(r), entityType, entityID, owner)
+	RespondSuccess(w, map[string]any{
+		"entity_type": entityType,
+		"entity_id":   entityID,
+		"owner":       owner,
+	})
+}
+
+// handleOwnerRemove removes an owner from a stack or container
+// DELETE /api/ownership/{type}/{name}/{owner}
+func (s *Server) handleOwnerRemove(w http.ResponseWriter, r *http.Request) {
+	if !s.requireOwnership(w) {
+		return
+	}
+
+	entityType, entityID, owner := r.PathValue("type"), r.PathValue("name"), r.PathValue("owner")
+	removed, err := s.ownership.Unassign(r.Context(), entityType, entityID, owner)
+	if err != nil {
+		RespondInternalError(w, err)
+		return
+	}
+	if !removed {
+		RespondNotFound(w, fmt.Errorf("%s is not an owner of %s %s", owner, entityType, entityID))
+		return
+	}
+
+	log.Printf("OWNERSHIP: %s removed %s from %s %s", requestActor(r), owner, entityType, entityID)
+	RespondSuccess(w, map[string]any{"removed": true})
+}
+
+// handleTeamMemberAdd adds a user to a team
+// PUT /api/teams/{team}/members/{username}
+func (s *Server) handleTeamMemberAdd(w http.ResponseWriter, r *http.Request) {
+	if !s.requireOwnership(w) {
+		return
+	}
+
+	team, username := r.PathValue("team"), r.PathValue("username")
+	if err := s.ownership.AddTeamMember(r.Context(), team, username); err != nil {
+		respondOwnershipError(w, err)
+		return
+	}
+
+	log.Printf("OWNERSHIP: %s added %s to team %s", requestActor(r), username, team)
+	RespondSuccess(w, map[string]any{"team": team, "username": username})
+}
+
+// handleTeamMemberRemove removes a user from a team
+// DELETE /api/teams/{team}/members/{username}
+func (s *Server) handleTeamMemberRemove(w http.ResponseWriter, r *http.Request) {
+	if !s.requireOwnership(w) {
+		return
+	}
+
+	team, username := r.PathValue("team"), r.PathValue("username")
+	removed, err := s.ownership.RemoveTeamMember(r.Context(), team, username)
+	if err != nil {
+		RespondInternalError(w, err)
+		return
+	}
+	if !removed {
+		RespondNotFound(w, fmt.Errorf("%s is not a member of team %s", username, team))
+		return
+	}
+
+	log.Printf("OWNERSHIP: %s removed %s from team %s", requestActor(r), username, team)
+	RespondSuccess(w, map[string]any{"removed": true})
+}
+
+// requireOwnership checks that ownership can be managed, which needs storage
+func (s *Server) requireOwnership(w http.ResponseWriter) bool {
+	if s.ownership == nil {
+		RespondInternalError(w, errNoStorage)
+		return false
+	}
+	return true
+}
+
+// respondOwnershipError maps ownership errors to HTTP status codes.
+func respondOwnershipError(w http.ResponseWriter, err error) {
+	if errors.Is(err, auth.ErrUserNotFound) {
+		RespondNotFound(w, err)
+		return
+	}
+	RespondBadRequest(w, err)
+}
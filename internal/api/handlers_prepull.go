@@ -0,0 +1,154 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/chis/docksmith/internal/auth"
+	"github.com/chis/docksmith/internal/notify"
+	"github.com/chis/docksmith/internal/update"
+)
+
+// prepullScheduleFromEnv reads the time of day ("02:00") the images of upcoming
+// scheduled group updates are pre-pulled from PREPULL_AT. Returns false when unset or invalid.
+func prepullScheduleFromEnv() (notify.Schedule, bool) {
+	value := os.Getenv("PREPULL_AT")
+	if value == "" {
+		return notify.Schedule{}, false
+	}
+	schedule, err := notify.ParseSchedule("daily", value, "")
+	if err != nil {
+		log.Printf("Warning: Invalid PREPULL_AT '%s', not pre-pulling images: %v", value, err)
+		return notify.Schedule{}, false
+	}
+	log.Printf("Using PREPULL_AT: %s", value)
+	return schedule, true
+}
+
+// prepullRequest selects the groups whose update images are pre-pulled
+type prepullRequest struct {
+	Groups []string `json:"groups,omitempty"` // Defaults to every group with an update schedule
+}
+
+// handlePrepullList returns the pre-pulled images and when the next pre-pull runs
+// GET /api/prepull
+func (s *Server) handlePrepullList(w http.ResponseWriter, r *http.Request) {
+	if s.updateOrchestrator == nil {
+		RespondInternalError(w, errNoUpdateOrchestrator)
+		return
+	}
+
+	images, err := s.updateOrchestrator.PrepulledImages(r.Context())
+	if err != nil {
+		RespondInternalError(w, err)
+		return
+	}
+
+	response := map[string]any{
+		"images": images,
+		"count":  len(images),
+	}
+	if s.groupScheduler != nil {
+		if next, ok := s.groupScheduler.NextPrepull(); ok {
+			response["next_prepull"] = next.Format(time.RFC3339)
+		}
+	}
+	RespondSuccess(w, response)
+}
+
+// handlePrepull pre-pulls the images of the available updates of groups now
+// POST /api/prepull
+// Body (optional): {"groups": ["media"]}
+func (s *Server) handlePrepull(w http.ResponseWriter, r *http.Request) {
+	if s.updateOrchestrator == nil {
+		RespondInternalError(w, errNoUpdateOrchestrator)
+		return
+	}
+
+	var req prepullRequest
+	if r.ContentLength > 0 && !decodeJSONRequest(w, r, &req) {
+		return
+	}
+	groups := req.Groups
+	if len(groups) == 0 && s.groupScheduler != nil {
+		for group := range s.groupScheduler.Schedules() {
+			groups = append(groups, group)
+		}
+		sort.Strings(groups)
+	}
+	if len(groups) == 0 {
+		RespondBadRequest(w, fmt.Errorf("no groups given and no group has an update schedule"))
+		return
+	}
+
+	scope, ok := s.requestScope(w, r)
+	if !ok {
+		return
+	}
+
+	images, err := s.prepullGroups(r.Context(), groups, scope)
+	if err != nil {
+		RespondInternalError(w, err)
+		return
+	}
+	RespondSuccess(w, map[string]any{
+		"groups": groups,
+		"images": images,
+		"count":  len(images),
+	})
+}
+
+// runScheduledPrepull pre-pulls the images of the upcoming scheduled updates of groups
+func (s *Server) runScheduledPrepull(ctx context.Context, groups []string) {
+	if s.proposals != nil && s.proposals.Enabled(ctx) {
+		log.Printf("GROUP: Skipping pre-pull for groups %v in propose-only mode", groups)
+		return
+	}
+	if _, err := s.prepullGroups(ctx, groups, nil); err != nil {
+		log.Printf("GROUP: Pre-pull for groups %v failed: %v", groups, err)
+	}
+}
+
+// prepullGroups pulls the target images of the available updates of groups,
+// checking for updates first so the images match what the update will install.
+// Only the containers in scope are pulled for; nil means all.
+func (s *Server) prepullGroups(ctx context.Context, groups []string, scope *auth.OwnershipScope) ([]update.PrepulledImage, error) {
+	if s.updateOrchestrator == nil {
+		return nil, errNoUpdateOrchestrator
+	}
+
+	result, err := s.checkResult(ctx, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check for updates: %w", err)
+	}
+	result = scopeResult(scope, result)
+
+	targets := make(map[string]string)
+	for _, group := range groups {
+		for _, c := range groupUpdateTargets(groupMembers(result, group)) {
+			targets[c.Name] = c.TargetVersion
+		}
+	}
+	if len(targets) == 0 {
+		log.Printf("GROUP: No updates to pre-pull for groups %v", groups)
+		return []update.PrepulledImage{}, nil
+	}
+
+	images, err := s.updateOrchestrator.PrepullImages(ctx, targets)
+	if err != nil {
+		return nil, err
+	}
+	failed := 0
+	for _, image := range images {
+		if image.Error != "" {
+			failed++
+		}
+	}
+	log.Printf("GROUP: Pre-pulled %d images for groups %v (%d failed)", len(images)-failed, groups, failed)
+	return images, nil
+}
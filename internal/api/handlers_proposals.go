@@ -0,0 +1,112 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+
+	"github.com/chis/docksmith/internal/proposal"
+	"github.com/chis/docksmith/internal/storage"
+)
+
+// errProposeOnly is returned for requests that would change running containers
+// while propose-only mode is enabled.
+var errProposeOnly = fmt.Errorf("propose-only mode is enabled; docksmith only proposes compose changes (see /api/proposals)")
+
+// unlessProposeOnly rejects requests that would change running containers
+// while propose-only mode is enabled.
+func (s *Server) unlessProposeOnly(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.proposals != nil && s.proposals.Enabled(r.Context()) {
+			RespondError(w, http.StatusConflict, errProposeOnly)
+			return
+		}
+		next(w, r)
+	}
+}
+
+// handleProposalsList returns compose change proposals, newest first
+// GET /api/proposals?status=open&limit=50
+func (s *Server) handleProposalsList(w http.ResponseWriter, r *http.Request) {
+	if !s.requireProposals(w) {
+		return
+	}
+
+	limit := 100
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		if parsed, err := strconv.Atoi(limitStr); err == nil && parsed > 0 {
+			limit = parsed
+		}
+	}
+
+	proposals, err := s.proposals.List(r.Context(), r.URL.Query().Get("status"), limit)
+	if err != nil {
+		RespondInternalError(w, err)
+		return
+	}
+	scope, ok := s.requestScope(w, r)
+	if !ok {
+		return
+	}
+	proposals = slices.DeleteFunc(proposals, func(p storage.Proposal) bool { return !scope.Allows(p.StackName, p.ContainerName) })
+
+	RespondSuccess(w, map[string]any{
+		"proposals":    proposals,
+		"count":        len(proposals),
+		"propose_only": s.proposals.Enabled(r.Context()),
+	})
+}
+
+// handleProposalGet returns a single proposal
+// GET /api/proposals/{id}
+func (s *Server) handleProposalGet(w http.ResponseWriter, r *http.Request) {
+	if !s.requireProposals(w) {
+		return
+	}
+
+	result, err := s.proposals.Get(r.Context(), r.PathValue("id"))
+	if err != nil {
+		respondProposalError(w, err)
+		return
+	}
+
+	RespondSuccess(w, result)
+}
+
+// handleProposalPatch returns the proposal as a raw patch file
+// GET /api/proposals/{id}/patch
+func (s *Server) handleProposalPatch(w http.ResponseWriter, r *http.Request) {
+	if !s.requireProposals(w) {
+		return
+	}
+
+	result, err := s.proposals.Get(r.Context(), r.PathValue("id"))
+	if err != nil {
+		respondProposalError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/x-diff; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", result.ContainerName+"-"+result.TargetVersion+".patch"))
+	w.Write([]byte(result.Patch))
+}
+
+// requireProposals checks if the proposal manager is available
+func (s *Server) requireProposals(w http.ResponseWriter) bool {
+	if s.proposals == nil {
+		RespondInternalError(w, errNoStorage)
+		return false
+	}
+	return true
+}
+
+// respondProposalError maps proposal errors to HTTP status codes.
+func respondProposalError(w http.ResponseWriter, err error) {
+	if errors.Is(err, proposal.ErrNotFound) {
+		RespondNotFound(w, err)
+		return
+	}
+	RespondInternalError(w, err)
+}
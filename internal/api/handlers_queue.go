@@ -0,0 +1,125 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+
+	"github.com/chis/docksmith/internal/update"
+)
+
+// handleQueue returns the queued operations in the order they will start, with
+// their position in their stack's queue and an estimated start time
+// GET /api/queue
+func (s *Server) handleQueue(w http.ResponseWriter, r *http.Request) {
+	if s.updateOrchestrator == nil {
+		RespondInternalError(w, errNoUpdateOrchestrator)
+		return
+	}
+
+	queue, err := s.updateOrchestrator.Queue(r.Context())
+	if err != nil {
+		RespondInternalError(w, err)
+		return
+	}
+	scope, ok := s.requestScope(w, r)
+	if !ok {
+		return
+	}
+	queue = slices.DeleteFunc(queue, func(q update.QueuedOperation) bool {
+		if len(q.Containers) == 0 {
+			return !scope.Allows(q.StackName, "")
+		}
+		return slices.ContainsFunc(q.Containers, func(name string) bool { return !scope.Allows(q.StackName, name) })
+	})
+	if queue == nil {
+		queue = []update.QueuedOperation{}
+	}
+	RespondSuccess(w, map[string]any{
+		"queue": queue,
+		"count": len(queue),
+	})
+}
+
+// handleQueuePriority changes the priority of a queued operation
+// POST /api/queue/{id}/priority
+func (s *Server) handleQueuePriority(w http.ResponseWriter, r *http.Request) {
+	if s.updateOrchestrator == nil {
+		RespondInternalError(w, errNoUpdateOrchestrator)
+		return
+	}
+
+	var req struct {
+		Priority *int `json:"priority"`
+	}
+	if !decodeJSONRequest(w, r, &req) {
+		return
+	}
+	if req.Priority == nil {
+		RespondBadRequest(w, errors.New("priority is required"))
+		return
+	}
+
+	operationID := r.PathValue("id")
+	if err := s.updateOrchestrator.SetQueuePriority(r.Context(), operationID, *req.Priority); err != nil {
+		RespondOrchestratorError(w, err)
+		return
+	}
+	RespondSuccess(w, map[string]any{
+		"operation_id": operationID,
+		"priority":     *req.Priority,
+	})
+}
+
+// handleQueueReorder reorders the queue of a stack. The listed operations move to
+// the front in the given order.
+// POST /api/queue/reorder
+func (s *Server) handleQueueReorder(w http.ResponseWriter, r *http.Request) {
+	if s.updateOrchestrator == nil {
+		RespondInternalError(w, errNoUpdateOrchestrator)
+		return
+	}
+
+	var req struct {
+		Stack        string   `json:"stack"`
+		OperationIDs []string `json:"operation_ids"`
+	}
+	if !decodeJSONRequest(w, r, &req) {
+		return
+	}
+	if req.Stack == "" {
+		RespondBadRequest(w, errors.New("stack is required"))
+		return
+	}
+	if !s.allowStack(w, r, req.Stack) {
+		return
+	}
+
+	if err := s.updateOrchestrator.ReorderQueue(r.Context(), req.Stack, req.OperationIDs); err != nil {
+		RespondOrchestratorError(w, err)
+		return
+	}
+	RespondSuccess(w, map[string]any{
+		"reordered": true,
+		"stack":     req.Stack,
+	})
+}
+
+// handleQueueRemove removes an operation from the queue and marks it cancelled
+// DELETE /api/queue/{id}
+func (s *Server) handleQueueRemove(w http.ResponseWriter, r *http.Request) {
+	if s.updateOrchestrator == nil {
+		RespondInternalError(w, errNoUpdateOrchestrator)
+		return
+	}
+
+	operationID := r.PathValue("id")
+	if err := s.updateOrchestrator.CancelQueuedOperation(r.Context(), operationID); err != nil {
+		RespondOrchestratorError(w, err)
+		return
+	}
+	RespondSuccess(w, map[string]any{
+		"removed":      true,
+		"operation_id": operationID,
+	})
+}
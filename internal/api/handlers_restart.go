@@ -223,10 +223,15 @@ func (s *Server) handleRestartStack(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Filter containers by stack
+	scope, ok := s.requestScope(w, r)
+	if !ok {
+		return
+	}
+
+	// Filter containers by stack, leaving out containers assigned to other owners
 	var stackContainers []string
 	for _, cont := range containers {
-		if stack, ok := cont.Labels[ComposeProjectLabel]; ok && stack == stackName {
+		if stack, ok := cont.Labels[ComposeProjectLabel]; ok && stack == stackName && scope.Allows(stack, cont.Name) {
 			stackContainers = append(stackContainers, cont.Name)
 		}
 	}
@@ -323,6 +328,9 @@ func (s *Server) handleRestartContainerBody(w http.ResponseWriter, r *http.Reque
 	if !validateRequired(w, "container_name", req.ContainerName) {
 		return
 	}
+	if !s.allowContainers(w, r, req.ContainerName) {
+		return
+	}
 
 	// Body endpoint doesn't support force parameter and doesn't include detailed error data
 	s.executeContainerRestart(w, r.Context(), req.ContainerName, false, false)
@@ -430,6 +438,9 @@ func (s *Server) handleStartStackRestart(w http.ResponseWriter, r *http.Request)
 		RespondBadRequest(w, fmt.Errorf("at least one container name is required"))
 		return
 	}
+	if !s.allowContainers(w, r, req.Containers...) {
+		return
+	}
 
 	log.Printf("Starting stack restart for %s with %d container(s) (force=%v)", stackName, len(req.Containers), force)
 
@@ -1,8 +1,15 @@
 package api
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
+	"slices"
+	"strconv"
+
+	"github.com/chis/docksmith/internal/scripts"
+	"github.com/chis/docksmith/internal/scripts/builtin"
+	"github.com/chis/docksmith/internal/storage"
 )
 
 // handleScriptsList returns available scripts in /scripts folder
@@ -18,10 +25,18 @@ func (s *Server) handleScriptsList(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Same JSON structure as CLI
+	managed, err := s.scriptManager.ListManagedScripts(r.Context())
+	if err != nil {
+		RespondInternalError(w, err)
+		return
+	}
+
+	// Same JSON structure as CLI, plus the managed scripts and built-in checks for the script picker
 	RespondSuccess(w, map[string]any{
 		"scripts": scripts,
 		"count":   len(scripts),
+		"managed": managed,
+		"builtin": builtin.Names(),
 	})
 }
 
@@ -39,6 +54,16 @@ func (s *Server) handleScriptsAssigned(w http.ResponseWriter, r *http.Request) {
 		RespondInternalError(w, err)
 		return
 	}
+	scope, ok := s.requestScope(w, r)
+	if !ok {
+		return
+	}
+	if scope != nil {
+		stacks := s.containerStacks(ctx)
+		assignments = slices.DeleteFunc(assignments, func(a scripts.Assignment) bool {
+			return !scope.Allows(stacks[a.ContainerName], a.ContainerName)
+		})
+	}
 
 	// Same JSON structure as CLI
 	RespondSuccess(w, map[string]any{
@@ -116,3 +141,115 @@ func (s *Server) handleScriptsUnassign(w http.ResponseWriter, r *http.Request) {
 }
 
 
+
+// handleScriptsTest runs a pre-update check against a container immediately and returns
+// its exit code, output, and duration, so scripts can be validated before assigning them.
+func (s *Server) handleScriptsTest(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Container string `json:"container"`
+		Script    string `json:"script"`
+	}
+	if !decodeJSONRequest(w, r, &req) {
+		return
+	}
+	if !validateRequired(w, "container", req.Container) || !validateRequired(w, "script", req.Script) {
+		return
+	}
+
+	ctx := r.Context()
+	ctr, err := s.findContainerByName(ctx, req.Container)
+	if err != nil {
+		RespondNotFound(w, fmt.Errorf("container not found: %s", req.Container))
+		return
+	}
+
+	RespondSuccess(w, scripts.TestPreUpdateCheck(ctx, ctr, req.Script))
+}
+
+// handleScriptGet returns a managed script's content and its revisions.
+// ?revision=N returns the content of an earlier revision.
+func (s *Server) handleScriptGet(w http.ResponseWriter, r *http.Request) {
+	if !s.requireScriptManager(w) {
+		return
+	}
+
+	ctx := r.Context()
+	name := r.PathValue("name")
+
+	revision := 0
+	if value := r.URL.Query().Get("revision"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 {
+			RespondBadRequest(w, fmt.Errorf("invalid revision: %s", value))
+			return
+		}
+		revision = n
+	}
+
+	script, err := s.scriptManager.GetManagedScript(ctx, name, revision)
+	if errors.Is(err, scripts.ErrManagedScriptNotFound) {
+		RespondNotFound(w, err)
+		return
+	}
+	if err != nil {
+		RespondInternalError(w, err)
+		return
+	}
+
+	revisions, err := s.scriptManager.ListScriptRevisions(ctx, name)
+	if err != nil {
+		RespondInternalError(w, err)
+		return
+	}
+
+	RespondSuccess(w, map[string]any{
+		"script":    script,
+		"revisions": revisions,
+	})
+}
+
+// handleScriptPut creates a managed script or saves a new revision of it.
+// Assignments keep running the revision they were pinned to.
+func (s *Server) handleScriptPut(w http.ResponseWriter, r *http.Request) {
+	if !s.requireScriptManager(w) {
+		return
+	}
+
+	var req struct {
+		Content        string `json:"content"`
+		TimeoutSeconds int    `json:"timeout_seconds"`
+		NoNetwork      bool   `json:"no_network"`
+		MemoryLimitMB  int    `json:"memory_limit_mb"`
+		CPUSeconds     int    `json:"cpu_seconds"`
+	}
+	if !decodeJSONRequest(w, r, &req) {
+		return
+	}
+	if !validateRequired(w, "content", req.Content) {
+		return
+	}
+
+	name := r.PathValue("name")
+	if err := scripts.ValidateScriptName(name); err != nil {
+		RespondBadRequest(w, err)
+		return
+	}
+
+	revision, err := s.scriptManager.SaveManagedScript(r.Context(), storage.ScriptRevision{
+		Name:           name,
+		TimeoutSeconds: req.TimeoutSeconds,
+		NoNetwork:      req.NoNetwork,
+		MemoryLimitMB:  req.MemoryLimitMB,
+		CPUSeconds:     req.CPUSeconds,
+		CreatedBy:      requestActor(r),
+	}, []byte(req.Content))
+	if err != nil {
+		RespondBadRequest(w, err)
+		return
+	}
+
+	RespondSuccess(w, map[string]any{
+		"script": revision,
+		"ref":    scripts.ManagedScriptRef(revision.Name, revision.Revision),
+	})
+}
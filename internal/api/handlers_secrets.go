@@ -0,0 +1,89 @@
+package api
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/chis/docksmith/internal/secrets"
+)
+
+// requireSecrets checks that the secrets store has an encryption key
+func (s *Server) requireSecrets(w http.ResponseWriter) bool {
+	if !s.secrets.Enabled() {
+		RespondError(w, http.StatusServiceUnavailable, secrets.ErrDisabled)
+		return false
+	}
+	return true
+}
+
+// handleSecretsList returns the stored secrets and their references, never their values
+// GET /api/secrets
+func (s *Server) handleSecretsList(w http.ResponseWriter, r *http.Request) {
+	if !s.requireStorage(w) || !s.requireSecrets(w) {
+		return
+	}
+
+	list, err := s.secrets.List(r.Context())
+	if err != nil {
+		RespondInternalError(w, err)
+		return
+	}
+	RespondSuccess(w, map[string]any{
+		"secrets": list,
+		"count":   len(list),
+	})
+}
+
+// handleSecretSet encrypts and stores a secret, returning the reference settings use
+// PUT /api/secrets/{name}
+// Body: {"value": "..."}
+func (s *Server) handleSecretSet(w http.ResponseWriter, r *http.Request) {
+	if !s.requireStorage(w) || !s.requireSecrets(w) {
+		return
+	}
+
+	var req struct {
+		Value string `json:"value"`
+	}
+	if !decodeJSONRequest(w, r, &req) {
+		return
+	}
+	name := r.PathValue("name")
+	if err := secrets.ValidateName(name); err != nil {
+		RespondBadRequest(w, err)
+		return
+	}
+	if req.Value == "" {
+		RespondBadRequest(w, errors.New("value is required"))
+		return
+	}
+
+	secret, err := s.secrets.Set(r.Context(), name, req.Value)
+	if err != nil {
+		RespondInternalError(w, err)
+		return
+	}
+	log.Printf("SECRETS: Stored secret %s", name)
+	RespondSuccess(w, secret)
+}
+
+// handleSecretDelete removes a secret
+// DELETE /api/secrets/{name}
+func (s *Server) handleSecretDelete(w http.ResponseWriter, r *http.Request) {
+	if !s.requireStorage(w) || !s.requireSecrets(w) {
+		return
+	}
+
+	name := r.PathValue("name")
+	if err := s.secrets.Delete(r.Context(), name); err != nil {
+		if errors.Is(err, secrets.ErrNotFound) {
+			RespondNotFound(w, err)
+			return
+		}
+		RespondInternalError(w, err)
+		return
+	}
+	log.Printf("SECRETS: Removed secret %s", name)
+	RespondSuccess(w, map[string]any{"name": name, "deleted": true})
+}
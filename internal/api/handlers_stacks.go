@@ -0,0 +1,237 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/chis/docksmith/internal/storage"
+	"github.com/chis/docksmith/internal/update"
+)
+
+// stackSummary describes a compose project found among the checked containers
+type stackSummary struct {
+	Name             string   `json:"name"`
+	Containers       []string `json:"containers"`
+	UpdatesAvailable int      `json:"updates_available"`
+	UpdatePriority   string   `json:"update_priority,omitempty"`
+	ComposeFiles     []string `json:"compose_files"`
+	LastCheck        string   `json:"last_check,omitempty"`
+	Locked           bool     `json:"locked"`            // An update operation holds the stack lock
+	QueuedOperations int      `json:"queued_operations"` // Operations waiting for the stack lock
+}
+
+// handleStacks returns every compose stack with its update counts, compose files,
+// lock state, and queued operations
+// GET /api/stacks
+func (s *Server) handleStacks(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// Cached results carry the time of the background check that produced them
+	var result *update.DiscoveryResult
+	var lastCheck time.Time
+	if s.backgroundChecker != nil {
+		result, _, lastCheck, _ = s.backgroundChecker.GetCachedResults()
+	}
+	if result == nil || lastCheck.IsZero() {
+		var err error
+		if result, err = s.checkResult(ctx, true); err != nil {
+			RespondInternalError(w, err)
+			return
+		}
+		lastCheck = time.Now()
+	}
+	scope, ok := s.requestScope(w, r)
+	if !ok {
+		return
+	}
+	result = scopeResult(scope, result)
+
+	queued := make(map[string]int)
+	if s.storageService != nil {
+		queue, err := s.storageService.GetQueuedUpdates(ctx)
+		if err != nil {
+			RespondInternalError(w, err)
+			return
+		}
+		for _, q := range queue {
+			queued[q.StackName]++
+		}
+	}
+
+	stacks := make([]stackSummary, 0, len(result.Stacks))
+	for name, stack := range result.Stacks {
+		summary := stackSummary{
+			Name:             name,
+			Containers:       make([]string, 0, len(stack.Containers)),
+			UpdatePriority:   stack.UpdatePriority,
+			ComposeFiles:     stackComposeFiles(stack),
+			LastCheck:        lastCheck.Format(time.RFC3339),
+			QueuedOperations: queued[name],
+		}
+		for _, c := range stack.Containers {
+			summary.Containers = append(summary.Containers, c.ContainerName)
+			if c.Status == update.UpdateAvailable || c.Status == update.UpdateAvailableBlocked {
+				summary.UpdatesAvailable++
+			}
+		}
+		sort.Strings(summary.Containers)
+		if s.updateOrchestrator != nil {
+			summary.Locked = s.updateOrchestrator.IsStackLocked(name)
+		}
+		stacks = append(stacks, summary)
+	}
+	sort.Slice(stacks, func(i, j int) bool { return stacks[i].Name < stacks[j].Name })
+
+	RespondSuccess(w, map[string]any{
+		"stacks": stacks,
+		"count":  len(stacks),
+	})
+}
+
+// stackComposeFiles returns the compose files of a stack's containers, without duplicates
+func stackComposeFiles(stack *update.Stack) []string {
+	files := []string{}
+	seen := make(map[string]bool)
+	for _, c := range stack.Containers {
+		for _, file := range strings.Split(c.Labels[ComposeConfigFilesLabel], ",") {
+			file = strings.TrimSpace(file)
+			if file == "" || seen[file] {
+				continue
+			}
+			seen[file] = true
+			files = append(files, file)
+		}
+	}
+	return files
+}
+
+// handleLocks returns the held stack locks with their operations
+// GET /api/locks
+func (s *Server) handleLocks(w http.ResponseWriter, r *http.Request) {
+	if s.updateOrchestrator == nil {
+		RespondInternalError(w, errNoUpdateOrchestrator)
+		return
+	}
+
+	scope, ok := s.requestScope(w, r)
+	if !ok {
+		return
+	}
+	locks := slices.DeleteFunc(s.updateOrchestrator.StackLocks(r.Context()), func(lock update.StackLock) bool {
+		return !scope.Allows(lock.Stack, "")
+	})
+	if locks == nil {
+		locks = []update.StackLock{}
+	}
+	RespondSuccess(w, map[string]any{
+		"locks": locks,
+		"count": len(locks),
+	})
+}
+
+// handleLockRelease releases a stack lock whose operation has finished, or with
+// ?force=true a lock held by a running operation
+// POST /api/locks/{stack}/release
+func (s *Server) handleLockRelease(w http.ResponseWriter, r *http.Request) {
+	if s.updateOrchestrator == nil {
+		RespondInternalError(w, errNoUpdateOrchestrator)
+		return
+	}
+
+	stack := r.PathValue("stack")
+	force := r.URL.Query().Get("force") == "true"
+	lock, err := s.updateOrchestrator.ReleaseStackLock(r.Context(), stack, force)
+	if errors.Is(err, update.ErrStackLockInUse) {
+		RespondErrorWithData(w, http.StatusConflict, err, lock)
+		return
+	}
+	if err != nil {
+		RespondOrchestratorError(w, err)
+		return
+	}
+
+	RespondSuccess(w, map[string]any{
+		"released": true,
+		"lock":     lock,
+	})
+}
+
+// handleStackEnvList returns the environment settings of every stack
+// GET /api/stack-env
+func (s *Server) handleStackEnvList(w http.ResponseWriter, r *http.Request) {
+	if !s.requireStorage(w) {
+		return
+	}
+
+	envs, err := s.storageService.ListStackEnvs(r.Context())
+	if err != nil {
+		RespondInternalError(w, err)
+		return
+	}
+	RespondSuccess(w, map[string]any{
+		"stacks": envs,
+		"count":  len(envs),
+	})
+}
+
+// handleStackEnvSet sets the env file and variables a stack's compose commands run with
+// PUT /api/stack-env/{stack}
+// Body: {"env_file": "/stacks/media/.env.prod", "env": {"TZ": "Europe/Berlin"}}
+func (s *Server) handleStackEnvSet(w http.ResponseWriter, r *http.Request) {
+	if !s.requireStorage(w) {
+		return
+	}
+
+	var req struct {
+		EnvFile string            `json:"env_file"`
+		Env     map[string]string `json:"env"`
+	}
+	if !decodeJSONRequest(w, r, &req) {
+		return
+	}
+	env := storage.StackEnv{Stack: r.PathValue("stack"), EnvFile: strings.TrimSpace(req.EnvFile), Env: req.Env}
+	if err := update.ValidateStackEnv(env); err != nil {
+		RespondOrchestratorError(w, err)
+		return
+	}
+
+	if err := s.storageService.SetStackEnv(r.Context(), env); err != nil {
+		RespondInternalError(w, err)
+		return
+	}
+	log.Printf("STACK: Set compose environment of stack %s (env file %q, %d variables)", env.Stack, env.EnvFile, len(env.Env))
+
+	saved, _, err := s.storageService.GetStackEnv(r.Context(), env.Stack)
+	if err != nil {
+		RespondInternalError(w, err)
+		return
+	}
+	RespondSuccess(w, saved)
+}
+
+// handleStackEnvDelete removes a stack's environment settings
+// DELETE /api/stack-env/{stack}
+func (s *Server) handleStackEnvDelete(w http.ResponseWriter, r *http.Request) {
+	if !s.requireStorage(w) {
+		return
+	}
+
+	stack := r.PathValue("stack")
+	deleted, err := s.storageService.DeleteStackEnv(r.Context(), stack)
+	if err != nil {
+		RespondInternalError(w, err)
+		return
+	}
+	if !deleted {
+		RespondNotFound(w, fmt.Errorf("stack %s has no environment settings", stack))
+		return
+	}
+	log.Printf("STACK: Removed compose environment of stack %s", stack)
+	RespondSuccess(w, map[string]any{"stack": stack, "deleted": true})
+}
@@ -1,6 +1,8 @@
 package api
 
 import (
+	"context"
+	"fmt"
 	"net/http"
 	"time"
 
@@ -59,7 +61,16 @@ func (s *Server) handleGetStatus(w http.ResponseWriter, r *http.Request) {
 			copy(stackCopy.Containers, stack.Containers)
 			result.Stacks[name] = stackCopy
 		}
+
+		// Rebuild group summaries from the copied containers
+		result.Groups = update.BuildGroups(result.Containers)
+	}
+
+	scope, ok := s.requestScope(w, r)
+	if !ok {
+		return
 	}
+	result = scopeResult(scope, result)
 
 	// Add status-specific fields to the result
 	if !lastCheck.IsZero() {
@@ -69,12 +80,59 @@ func (s *Server) handleGetStatus(w http.ResponseWriter, r *http.Request) {
 		result.LastBackgroundRun = lastBackgroundRun.Format(time.RFC3339)
 	}
 	result.Checking = checking
-	if !lastBackgroundRun.IsZero() && s.checkInterval > 0 {
-		nextCheck := lastBackgroundRun.Add(s.checkInterval)
-		result.NextCheck = nextCheck.Format(time.RFC3339)
-	}
+	result.NextCheck = s.backgroundChecker.Status().NextRun
 	result.CheckInterval = s.checkInterval.String()
 	result.CacheTTL = s.cacheTTL.String()
 
 	RespondSuccess(w, result)
 }
+
+// checkResult returns the background checker's cached check results, or runs a
+// live check when fresh is set or no cached results exist. Callers must not
+// modify the cached result.
+func (s *Server) checkResult(ctx context.Context, fresh bool) (*update.DiscoveryResult, error) {
+	if !fresh && s.backgroundChecker != nil {
+		if cached, _, _, _ := s.backgroundChecker.GetCachedResults(); cached != nil {
+			return cached, nil
+		}
+	}
+	if s.discoveryOrchestrator == nil {
+		return nil, fmt.Errorf("discovery orchestrator not available")
+	}
+	return s.discoveryOrchestrator.DiscoverAndCheck(ctx)
+}
+
+// handleCheckerStatus returns the background checker's schedule
+// GET /api/checker
+func (s *Server) handleCheckerStatus(w http.ResponseWriter, r *http.Request) {
+	if s.backgroundChecker == nil {
+		RespondInternalError(w, fmt.Errorf("background checker not available"))
+		return
+	}
+
+	RespondSuccess(w, s.backgroundChecker.Status())
+}
+
+// handleCheckerPause pauses scheduled background checks
+// POST /api/checker/pause
+func (s *Server) handleCheckerPause(w http.ResponseWriter, r *http.Request) {
+	if s.backgroundChecker == nil {
+		RespondInternalError(w, fmt.Errorf("background checker not available"))
+		return
+	}
+
+	s.backgroundChecker.Pause()
+	RespondSuccess(w, s.backgroundChecker.Status())
+}
+
+// handleCheckerResume resumes scheduled background checks
+// POST /api/checker/resume
+func (s *Server) handleCheckerResume(w http.ResponseWriter, r *http.Request) {
+	if s.backgroundChecker == nil {
+		RespondInternalError(w, fmt.Errorf("background checker not available"))
+		return
+	}
+
+	s.backgroundChecker.Resume()
+	RespondSuccess(w, s.backgroundChecker.Status())
+}
@@ -7,10 +7,19 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/chis/docksmith/internal/approval"
+	"github.com/chis/docksmith/internal/auth"
+	"github.com/chis/docksmith/internal/events"
+	"github.com/chis/docksmith/internal/hooks"
+	"github.com/chis/docksmith/internal/notify"
+	"github.com/chis/docksmith/internal/proposal"
+	"github.com/chis/docksmith/internal/registry"
+	"github.com/chis/docksmith/internal/scripts"
 	"github.com/chis/docksmith/internal/storage"
 	"github.com/chis/docksmith/internal/update"
 	"github.com/stretchr/testify/assert"
@@ -130,6 +139,35 @@ func TestValidateRegexPattern(t *testing.T) {
 	}
 }
 
+func TestValidateVersionConstraint(t *testing.T) {
+	tests := []struct {
+		name       string
+		constraint string
+		wantErr    bool
+	}{
+		{"empty is valid", "", false},
+		{"caret", "^2.4", false},
+		{"tilde", "~1.20", false},
+		{"range", ">=3 <4", false},
+		{"or", "16.x || 18.x", false},
+		{"hyphen", "1.2 - 1.4", false},
+		{"garbage", "latest", true},
+		{"dangling operator", ">=", true},
+		{"empty or branch", "^2 ||", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateVersionConstraint(tt.constraint)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
 // ============================================================================
 // Response Function Tests
 // ============================================================================
@@ -441,6 +479,56 @@ func TestHandleHealth(t *testing.T) {
 	})
 }
 
+func TestHandleReady(t *testing.T) {
+	checks := func(t *testing.T, body []byte) map[string]any {
+		t.Helper()
+		var response map[string]any
+		require.NoError(t, json.Unmarshal(body, &response))
+		byName := make(map[string]any)
+		for _, c := range response["data"].(map[string]any)["checks"].([]any) {
+			check := c.(map[string]any)
+			byName[check["name"].(string)] = check["status"]
+		}
+		return byName
+	}
+
+	t.Run("not ready without docker", func(t *testing.T) {
+		s := &Server{}
+		w := httptest.NewRecorder()
+		s.handleReady(w, httptest.NewRequest("GET", "/api/ready", nil))
+
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+		assert.Contains(t, w.Body.String(), `"ready": false`)
+		assert.Equal(t, map[string]any{
+			"docker":             "fail",
+			"storage":            "warn",
+			"queue":              "ok",
+			"background_checker": "ok",
+		}, checks(t, w.Body.Bytes()))
+	})
+
+	t.Run("storage not writable", func(t *testing.T) {
+		store := NewMockStorage()
+		store.SaveError = errors.New("attempt to write a readonly database")
+		s := &Server{storageService: store}
+		w := httptest.NewRecorder()
+		s.handleReady(w, httptest.NewRequest("GET", "/api/ready", nil))
+
+		assert.Equal(t, "fail", checks(t, w.Body.Bytes())["storage"])
+		assert.Contains(t, w.Body.String(), "readonly database")
+	})
+
+	t.Run("deep health stays healthy for liveness", func(t *testing.T) {
+		s := &Server{storageService: NewMockStorage()}
+		w := httptest.NewRecorder()
+		s.handleHealth(w, httptest.NewRequest("GET", "/api/health?deep=true", nil))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"status": "degraded"`)
+		assert.Equal(t, "ok", checks(t, w.Body.Bytes())["storage"])
+	})
+}
+
 // ============================================================================
 // Handler Tests - handleTriggerCheck
 // ============================================================================
@@ -510,6 +598,107 @@ func TestHandleHistory_NoStorage(t *testing.T) {
 	})
 }
 
+func TestHandleHistoryTimeline(t *testing.T) {
+	t.Run("returns error when storage unavailable", func(t *testing.T) {
+		s := &Server{storageService: nil}
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("GET", "/api/history/timeline", nil)
+
+		s.handleHistoryTimeline(w, r)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+
+	t.Run("rejects invalid dates", func(t *testing.T) {
+		s := &Server{storageService: NewMockStorage()}
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("GET", "/api/history/timeline?date_from=yesterday", nil)
+
+		s.handleHistoryTimeline(w, r)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "invalid date_from")
+	})
+}
+
+func TestHandleConfigExportImport(t *testing.T) {
+	t.Setenv("CHECK_INTERVAL", "")
+
+	source := NewMockStorage()
+	source.SaveScriptAssignment(context.Background(), storage.ScriptAssignment{ContainerName: "plex", Ignore: true})
+	source.SetConfig(context.Background(), "history_retention_days", "30")
+
+	s := &Server{storageService: source}
+	w := httptest.NewRecorder()
+	s.handleConfigExport(w, httptest.NewRequest("GET", "/api/config/export", nil))
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Header().Get("Content-Type"), "application/yaml")
+	assert.Contains(t, w.Body.String(), "name: plex")
+
+	target := NewMockStorage()
+	s = &Server{storageService: target}
+	importW := httptest.NewRecorder()
+	s.handleConfigImport(importW, httptest.NewRequest("POST", "/api/config/import", strings.NewReader(w.Body.String())))
+
+	require.Equal(t, http.StatusOK, importW.Code, importW.Body.String())
+	assignment, found, _ := target.GetScriptAssignment(context.Background(), "plex")
+	assert.True(t, found)
+	assert.True(t, assignment.Ignore)
+	value, _, _ := target.GetConfig(context.Background(), "history_retention_days")
+	assert.Equal(t, "30", value)
+
+	t.Run("rejects invalid files", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		s.handleConfigImport(w, httptest.NewRequest("POST", "/api/config/import", strings.NewReader("version: 9\n")))
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "unsupported configuration version")
+	})
+}
+
+func TestHandleDBPrune(t *testing.T) {
+	store := NewMockStorage()
+	s := &Server{storageService: store}
+
+	prune := func(body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		s.handleDBPrune(w, httptest.NewRequest("POST", "/api/db/prune", strings.NewReader(body)))
+		return w
+	}
+	policyOf := func(w *httptest.ResponseRecorder) storage.RetentionPolicy {
+		var response struct {
+			Data struct {
+				Policy storage.RetentionPolicy `json:"policy"`
+			} `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		return response.Data.Policy
+	}
+
+	t.Run("requires a policy without older_than_days", func(t *testing.T) {
+		w := prune(`{}`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "no retention policy")
+	})
+
+	t.Run("rejects negative ages", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, prune(`{"older_than_days": -1}`).Code)
+	})
+
+	t.Run("uses older_than_days for both tables", func(t *testing.T) {
+		w := prune(`{"older_than_days": 90}`)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		assert.Equal(t, storage.RetentionPolicy{CheckHistoryDays: 90, UpdateLogDays: 90}, policyOf(w))
+	})
+
+	t.Run("applies the configured policy", func(t *testing.T) {
+		store.SetConfig(context.Background(), storage.UpdateLogRetentionConfigKey, "365")
+		w := prune(`{}`)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		assert.Equal(t, storage.RetentionPolicy{UpdateLogDays: 365}, policyOf(w))
+	})
+}
+
 // ============================================================================
 // Handler Tests - handlePolicies
 // ============================================================================
@@ -559,6 +748,188 @@ func TestHandleUpdate_Validation(t *testing.T) {
 	})
 }
 
+func TestHandleLocks(t *testing.T) {
+	t.Run("returns error when update orchestrator unavailable", func(t *testing.T) {
+		s := &Server{}
+		w := httptest.NewRecorder()
+		s.handleLocks(w, httptest.NewRequest("GET", "/api/locks", nil))
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+
+	t.Run("lists no locks", func(t *testing.T) {
+		s := &Server{updateOrchestrator: &update.UpdateOrchestrator{}}
+		w := httptest.NewRecorder()
+		s.handleLocks(w, httptest.NewRequest("GET", "/api/locks", nil))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"locks": []`)
+		assert.Contains(t, w.Body.String(), `"count": 0`)
+	})
+
+	t.Run("release of an unlocked stack is not found", func(t *testing.T) {
+		s := &Server{updateOrchestrator: &update.UpdateOrchestrator{}}
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("POST", "/api/locks/media/release?force=true", nil)
+		r.SetPathValue("stack", "media")
+		s.handleLockRelease(w, r)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Contains(t, w.Body.String(), "stack media is not locked")
+	})
+}
+
+func TestHandleQueue(t *testing.T) {
+	t.Run("lists an empty queue", func(t *testing.T) {
+		s := &Server{updateOrchestrator: &update.UpdateOrchestrator{}}
+		w := httptest.NewRecorder()
+		s.handleQueue(w, httptest.NewRequest("GET", "/api/queue", nil))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"queue": []`)
+		assert.Contains(t, w.Body.String(), `"count": 0`)
+	})
+
+	t.Run("priority is required", func(t *testing.T) {
+		s := &Server{updateOrchestrator: &update.UpdateOrchestrator{}}
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("POST", "/api/queue/op-1/priority", strings.NewReader(`{}`))
+		r.SetPathValue("id", "op-1")
+		s.handleQueuePriority(w, r)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "priority is required")
+	})
+
+	t.Run("removing an operation that is not queued is not found", func(t *testing.T) {
+		s := &Server{updateOrchestrator: &update.UpdateOrchestrator{}}
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("DELETE", "/api/queue/op-1", nil)
+		r.SetPathValue("id", "op-1")
+		s.handleQueueRemove(w, r)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Contains(t, w.Body.String(), "operation op-1 is not queued")
+	})
+}
+
+func TestHandleUpdatePreview(t *testing.T) {
+	s := &Server{updateOrchestrator: &update.UpdateOrchestrator{}}
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/api/update/preview", strings.NewReader(`{"containers": [{"name": "web"}]}`))
+	s.handleUpdatePreview(w, r)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "needs a name and target_version")
+}
+
+func TestHandleUpdate_RequiresApproval(t *testing.T) {
+	mockStorage := NewMockStorage()
+	require.NoError(t, mockStorage.SetConfig(context.Background(), approval.RequiredConfigKey, "true"))
+
+	s := &Server{
+		updateOrchestrator: &update.UpdateOrchestrator{},
+		approvals:          approval.NewManager(mockStorage, nil, nil),
+	}
+	w := httptest.NewRecorder()
+	body := `{"container_name": "nginx", "target_version": "1.25.0"}`
+	r := httptest.NewRequest("POST", "/api/update", strings.NewReader(body))
+	r.Header.Set("Content-Type", "application/json")
+
+	s.handleUpdate(w, r)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Contains(t, w.Body.String(), "require approval")
+}
+
+func TestHandleApprovals_NotFound(t *testing.T) {
+	s := &Server{approvals: approval.NewManager(NewMockStorage(), nil, nil)}
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/api/approvals/missing/approve", nil)
+	r.SetPathValue("id", "missing")
+
+	s.handleApprovalApprove(w, r)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestHandleApprovalPolicySet_InvalidMode(t *testing.T) {
+	s := &Server{approvals: approval.NewManager(NewMockStorage(), nil, nil)}
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("PUT", "/api/policies/approval/stack/db", strings.NewReader(`{"mode": "minor"}`))
+	r.SetPathValue("scope", "stack")
+	r.SetPathValue("name", "db")
+
+	s.handleApprovalPolicySet(w, r)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "none, major, or all")
+}
+
+func TestHandleEvents_Since(t *testing.T) {
+	bus := events.NewBus()
+	start := bus.LastID()
+	bus.Publish(events.Event{Type: events.EventUpdateProgress, Payload: map[string]interface{}{"stage": "pulling"}})
+	bus.Publish(events.Event{Type: events.EventUpdateProgress, Payload: map[string]interface{}{"stage": "complete"}})
+
+	s := &Server{eventBus: bus}
+	w := httptest.NewRecorder()
+	s.handleEvents(w, httptest.NewRequest("GET", fmt.Sprintf("/api/events?since=%d", start+1), nil))
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var resp struct {
+		Data struct {
+			Events      []events.Event `json:"events"`
+			LastEventID int64          `json:"last_event_id"`
+			Complete    bool           `json:"complete"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Len(t, resp.Data.Events, 1)
+	assert.Equal(t, "complete", resp.Data.Events[0].Payload["stage"])
+	assert.Equal(t, start+2, resp.Data.LastEventID)
+	assert.True(t, resp.Data.Complete)
+}
+
+func TestHandleEvents_ReplaysMissedEvents(t *testing.T) {
+	bus := events.NewBus()
+	start := bus.LastID()
+	bus.Publish(events.Event{Type: events.EventUpdateProgress, Payload: map[string]interface{}{"stage": "pulling"}})
+	bus.Publish(events.Event{Type: events.EventContainerUpdated, Payload: map[string]interface{}{"container_name": "web"}})
+
+	s := &Server{eventBus: bus}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	r := httptest.NewRequest("GET", "/api/events", nil).WithContext(ctx)
+	r.Header.Set("Last-Event-ID", fmt.Sprint(start+1))
+	w := httptest.NewRecorder()
+
+	s.handleEvents(w, r)
+
+	body := w.Body.String()
+	assert.Contains(t, body, `"replayed":1,"missed_events":false`)
+	assert.NotContains(t, body, "pulling")
+	assert.Contains(t, body, fmt.Sprintf("id: %d\nevent: container.updated\n", start+2))
+}
+
+func TestUnlessProposeOnly(t *testing.T) {
+	store := NewMockStorage()
+	s := &Server{proposals: proposal.NewManager(store, nil, nil)}
+	called := false
+	handler := s.unlessProposeOnly(func(w http.ResponseWriter, r *http.Request) { called = true })
+
+	w := httptest.NewRecorder()
+	handler(w, httptest.NewRequest("POST", "/api/update", nil))
+	assert.True(t, called)
+
+	require.NoError(t, store.SetConfig(context.Background(), proposal.EnabledConfigKey, "true"))
+	called = false
+	w = httptest.NewRecorder()
+	handler(w, httptest.NewRequest("POST", "/api/update", nil))
+	assert.False(t, called)
+	assert.Equal(t, http.StatusConflict, w.Code)
+}
+
 // ============================================================================
 // Handler Tests - handleBatchUpdate
 // ============================================================================
@@ -621,6 +992,154 @@ func TestHandleRollback_Validation(t *testing.T) {
 	})
 }
 
+// ============================================================================
+// Handler Tests - handlePauseOperation / handleResumeOperation
+// ============================================================================
+
+func TestHandlePauseResumeOperation_Validation(t *testing.T) {
+	t.Run("pause returns error when update orchestrator unavailable", func(t *testing.T) {
+		s := &Server{updateOrchestrator: nil}
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("POST", "/api/operations/op-123/pause", nil)
+		r.SetPathValue("id", "op-123")
+
+		s.handlePauseOperation(w, r)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+
+	t.Run("resume returns error when update orchestrator unavailable", func(t *testing.T) {
+		s := &Server{updateOrchestrator: nil}
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("POST", "/api/operations/op-123/resume", nil)
+		r.SetPathValue("id", "op-123")
+
+		s.handleResumeOperation(w, r)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+}
+
+func TestStackComposeFiles(t *testing.T) {
+	stack := &update.Stack{
+		Name: "media",
+		Containers: []update.ContainerInfo{
+			{Labels: map[string]string{ComposeConfigFilesLabel: "/srv/media/compose.yml,/srv/media/compose.override.yml"}},
+			{Labels: map[string]string{ComposeConfigFilesLabel: "/srv/media/compose.yml"}},
+			{Labels: map[string]string{}},
+		},
+	}
+
+	assert.Equal(t, []string{"/srv/media/compose.yml", "/srv/media/compose.override.yml"}, stackComposeFiles(stack))
+	assert.Equal(t, []string{}, stackComposeFiles(&update.Stack{}))
+}
+
+func TestHandlePin_Validation(t *testing.T) {
+	t.Run("returns error when update orchestrator unavailable", func(t *testing.T) {
+		s := &Server{updateOrchestrator: nil}
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("POST", "/api/pin", strings.NewReader(`{"all":true}`))
+
+		s.handlePin(w, r)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+
+	t.Run("requires containers or all", func(t *testing.T) {
+		s := &Server{updateOrchestrator: &update.UpdateOrchestrator{}}
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("POST", "/api/pin", strings.NewReader(`{}`))
+
+		s.handlePin(w, r)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "containers array or all is required")
+	})
+
+	t.Run("rejects containers combined with all", func(t *testing.T) {
+		s := &Server{updateOrchestrator: &update.UpdateOrchestrator{}}
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("POST", "/api/pin", strings.NewReader(`{"containers":["plex"],"all":true}`))
+
+		s.handlePin(w, r)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestHandleSwitchVariant_Validation(t *testing.T) {
+	t.Run("returns error when update orchestrator unavailable", func(t *testing.T) {
+		s := &Server{updateOrchestrator: nil}
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("POST", "/api/variant/nginx", strings.NewReader(`{"tag":"1.25"}`))
+		r.SetPathValue("name", "nginx")
+
+		s.handleSwitchVariant(w, r)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+
+	t.Run("requires a tag", func(t *testing.T) {
+		s := &Server{updateOrchestrator: &update.UpdateOrchestrator{}}
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("POST", "/api/variant/nginx", strings.NewReader(`{}`))
+		r.SetPathValue("name", "nginx")
+
+		s.handleSwitchVariant(w, r)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "tag")
+	})
+}
+
+func TestHandleGroups_Validation(t *testing.T) {
+	t.Run("update returns error when update orchestrator unavailable", func(t *testing.T) {
+		s := &Server{updateOrchestrator: nil}
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("POST", "/api/groups/update/media", nil)
+		r.SetPathValue("name", "media")
+
+		s.handleGroupUpdate(w, r)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+
+	t.Run("ignore requires the ignore field", func(t *testing.T) {
+		s := &Server{}
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("POST", "/api/groups/ignore/media", strings.NewReader(`{}`))
+		r.SetPathValue("name", "media")
+
+		s.handleGroupIgnore(w, r)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "ignore is required")
+	})
+
+	t.Run("schedule rejects an invalid period", func(t *testing.T) {
+		s := &Server{groupScheduler: newGroupScheduler(nil, func(context.Context, string) {})}
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("PUT", "/api/groups/schedule/media", strings.NewReader(`{"period":"hourly"}`))
+		r.SetPathValue("name", "media")
+
+		s.handleGroupScheduleSet(w, r)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "invalid schedule period")
+	})
+
+	t.Run("deleting a missing schedule returns not found", func(t *testing.T) {
+		s := &Server{groupScheduler: newGroupScheduler(nil, func(context.Context, string) {})}
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("DELETE", "/api/groups/schedule/media", nil)
+		r.SetPathValue("name", "media")
+
+		s.handleGroupScheduleDelete(w, r)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
 // ============================================================================
 // Handler Tests - Scripts Handlers
 // ============================================================================
@@ -691,6 +1210,54 @@ func TestHandleScriptsUnassign_Validation(t *testing.T) {
 	})
 }
 
+func TestHandleScriptsTest_Validation(t *testing.T) {
+	s := &Server{}
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/api/scripts/test", strings.NewReader(`{"container": "nginx"}`))
+
+	s.handleScriptsTest(w, r)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "script is required")
+}
+
+func TestHandleScriptPut(t *testing.T) {
+	dir := scripts.ManagedScriptsDir
+	scripts.ManagedScriptsDir = t.TempDir()
+	defer func() { scripts.ManagedScriptsDir = dir }()
+	s := &Server{scriptManager: scripts.NewManager(storage.NewMemoryStorage(), nil)}
+
+	t.Run("rejects names that are not plain .sh files", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("PUT", "/api/scripts/..", strings.NewReader(`{"content": "#!/bin/sh"}`))
+		r.SetPathValue("name", "..")
+
+		s.handleScriptPut(w, r)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("saves a revision and returns its reference", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("PUT", "/api/scripts/check.sh", strings.NewReader(`{"content": "#!/bin/sh\nexit 0\n", "no_network": true}`))
+		r.SetPathValue("name", "check.sh")
+
+		s.handleScriptPut(w, r)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"ref": "managed:check.sh@1"`)
+
+		w = httptest.NewRecorder()
+		r = httptest.NewRequest("GET", "/api/scripts/check.sh", nil)
+		r.SetPathValue("name", "check.sh")
+
+		s.handleScriptGet(w, r)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"no_network": true`)
+	})
+}
+
 // ============================================================================
 // Handler Tests - Labels Handlers
 // ============================================================================
@@ -1213,3 +1780,395 @@ func TestConstants(t *testing.T) {
 		assert.Equal(t, 500, MaxRegexPatternLength)
 	})
 }
+
+func TestHandleGraph_InvalidFormat(t *testing.T) {
+	s := &Server{}
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/api/graph?format=svg", nil)
+
+	s.handleGraph(w, r)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "invalid format")
+}
+
+func TestHandleRegistryRepositories_Unsupported(t *testing.T) {
+	manager := registry.NewManager("")
+	defer manager.Close()
+	s := &Server{registryManager: manager}
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/api/registry/repositories/docker.io", nil)
+	r.SetPathValue("registry", "docker.io")
+
+	s.handleRegistryRepositories(w, r)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "does not support listing repositories")
+}
+
+func TestHandleOperationLogs(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewMemoryStorage()
+	bus := events.NewBus()
+	s := &Server{storageService: store, eventBus: bus}
+	require.NoError(t, store.SaveUpdateOperation(ctx, storage.UpdateOperation{OperationID: "op-1", Status: storage.StatusPullingImage}))
+	first, _ := store.AppendOperationLog(ctx, storage.OperationLogEntry{OperationID: "op-1", ContainerName: "web", Source: "progress", Message: "Pulling nginx:1.27"})
+
+	request := func(target string) *http.Request {
+		r := httptest.NewRequest("GET", target, nil)
+		r.SetPathValue("id", strings.Split(target, "/")[3])
+		return r
+	}
+
+	t.Run("streams lines until the operation finishes", func(t *testing.T) {
+		go func() {
+			time.Sleep(20 * time.Millisecond)
+			store.AppendOperationLog(ctx, storage.OperationLogEntry{OperationID: "op-1", Source: "compose", Message: "$ docker compose up -d web"})
+			store.UpdateOperationStatus(ctx, "op-1", storage.StatusComplete, "")
+			bus.Publish(events.Event{Type: events.EventOperationLog, Payload: map[string]interface{}{"operation_id": "op-1"}})
+		}()
+		timeout, cancel := context.WithTimeout(ctx, 2*time.Second)
+		defer cancel()
+		w := httptest.NewRecorder()
+
+		s.handleOperationLogs(w, request("/api/operations/op-1/logs").WithContext(timeout))
+
+		body := w.Body.String()
+		assert.Equal(t, "text/event-stream", w.Header().Get("Content-Type"))
+		assert.Contains(t, body, fmt.Sprintf("id: %d\nevent: log\n", first))
+		assert.Contains(t, body, "Pulling nginx:1.27")
+		assert.Contains(t, body, "$ docker compose up -d web")
+		assert.True(t, strings.HasSuffix(body, "event: done\ndata: {\"status\":\"complete\"}\n\n"), body)
+		assert.NoError(t, timeout.Err(), "the stream ends when the operation completes")
+	})
+
+	t.Run("returns stored lines as JSON without follow", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		s.handleOperationLogs(w, request(fmt.Sprintf("/api/operations/op-1/logs?follow=false&after=%d", first)))
+
+		require.Equal(t, http.StatusOK, w.Code)
+		var resp struct {
+			Data struct {
+				Status string                      `json:"status"`
+				Logs   []storage.OperationLogEntry `json:"logs"`
+			} `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, storage.StatusComplete, resp.Data.Status)
+		require.Len(t, resp.Data.Logs, 1)
+		assert.Equal(t, "compose", resp.Data.Logs[0].Source)
+	})
+
+	t.Run("unknown operation", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		s.handleOperationLogs(w, request("/api/operations/missing/logs"))
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestHandleIgnoreRules(t *testing.T) {
+	s := &Server{storageService: storage.NewMemoryStorage()}
+
+	w := httptest.NewRecorder()
+	s.handleIgnoreRuleCreate(w, httptest.NewRequest("POST", "/api/ignore-rules", strings.NewReader(`{"pattern":"*"}`)))
+	assert.Equal(t, http.StatusBadRequest, w.Code, "a pattern matching every image is rejected")
+
+	w = httptest.NewRecorder()
+	s.handleIgnoreRuleCreate(w, httptest.NewRequest("POST", "/api/ignore-rules", strings.NewReader(`{"pattern":"*:nightly","reason":"unstable"}`)))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var created struct {
+		Data storage.IgnoreRule `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	assert.Equal(t, "*:nightly", created.Data.Pattern)
+
+	w = httptest.NewRecorder()
+	s.handleIgnoreRulesList(w, httptest.NewRequest("GET", "/api/ignore-rules", nil))
+	assert.Contains(t, w.Body.String(), `"count": 1`)
+
+	remove := func(id string) int {
+		r := httptest.NewRequest("DELETE", "/api/ignore-rules/"+id, nil)
+		r.SetPathValue("id", id)
+		w := httptest.NewRecorder()
+		s.handleIgnoreRuleDelete(w, r)
+		return w.Code
+	}
+	id := fmt.Sprint(created.Data.ID)
+	assert.Equal(t, http.StatusOK, remove(id))
+	assert.Equal(t, http.StatusNotFound, remove(id))
+	assert.Equal(t, http.StatusBadRequest, remove("abc"))
+}
+
+func TestBuildVersionTimeline(t *testing.T) {
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	at := func(hours int) time.Time { return base.Add(time.Duration(hours) * time.Hour) }
+	completed := func(hours int) *time.Time { t := at(hours); return &t }
+
+	checks := []storage.CheckHistoryEntry{
+		{ContainerName: "db", CheckTime: at(0), CurrentVersion: "16.1", LatestVersion: "16.1"},
+		{ContainerName: "db", CheckTime: at(2), CurrentVersion: "16.1", LatestVersion: "16.2"},
+		{ContainerName: "db", CheckTime: at(5), CurrentVersion: "16.2", LatestVersion: "16.2"},
+		{ContainerName: "db", CheckTime: at(9), CurrentVersion: "16.1", LatestVersion: "17.0"},
+		{ContainerName: "db", CheckTime: at(12), CurrentVersion: "17.0"},
+	}
+	ops := []storage.UpdateOperation{
+		{OperationID: "up", ContainerName: "db", OperationType: "single", Status: "complete", OldVersion: "16.1", NewVersion: "16.2", CompletedAt: completed(4)},
+		{OperationID: "rb", ContainerName: "db", OperationType: "rollback", Status: "complete", OldVersion: "16.2", NewVersion: "16.1 (digest)", CompletedAt: completed(8)},
+		{OperationID: "failed", ContainerName: "db", OperationType: "single", Status: "failed", OldVersion: "16.1", NewVersion: "17.0", CompletedAt: completed(10)},
+		{OperationID: "other", ContainerName: "2 containers", OperationType: "batch", Status: "complete", CompletedAt: completed(3),
+			BatchDetails: []storage.BatchContainerDetail{{ContainerName: "web", OldVersion: "1", NewVersion: "2"}}},
+	}
+
+	runs := buildVersionTimeline("db", checks, ops, at(14))
+	require.Len(t, runs, 4)
+
+	assert.Equal(t, "16.1", runs[0].Version)
+	assert.Equal(t, "observed", runs[0].Source)
+	assert.Equal(t, int64(4*3600), runs[0].DurationSeconds)
+
+	assert.Equal(t, "16.2", runs[1].Version)
+	assert.Equal(t, "update", runs[1].Source)
+	assert.Equal(t, "minor", runs[1].ChangeType)
+	assert.Equal(t, at(2), runs[1].FirstSeen, "first seen as an available update")
+	assert.Equal(t, at(4), *runs[1].DeployedAt)
+	assert.True(t, runs[1].RolledBack)
+
+	assert.Equal(t, "rollback", runs[2].Source)
+	assert.Equal(t, "downgrade", runs[2].ChangeType)
+	assert.Equal(t, at(0), runs[2].FirstSeen)
+
+	assert.Equal(t, "17.0", runs[3].Version)
+	assert.Equal(t, "observed", runs[3].Source, "the failed update did not deploy 17.0")
+	assert.Equal(t, "major", runs[3].ChangeType)
+	assert.True(t, runs[3].Current)
+	assert.Nil(t, runs[3].EndedAt)
+	assert.Equal(t, int64(2*3600), runs[3].DurationSeconds)
+}
+
+func TestHandleHistoryExport(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	started := time.Now().Add(-time.Hour)
+	require.NoError(t, store.SaveUpdateOperation(context.Background(), storage.UpdateOperation{
+		OperationID: "op-1", ContainerName: "web", OperationType: "single", Status: "complete",
+		OldVersion: "1.0", NewVersion: "1.1", StartedAt: &started, TriggeredBy: "api_key:ci",
+	}))
+	s := &Server{storageService: store}
+
+	w := httptest.NewRecorder()
+	s.handleHistoryExport(w, httptest.NewRequest("GET", "/api/history/export?format=jsonl", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/x-ndjson", w.Header().Get("Content-Type"))
+	assert.Contains(t, w.Body.String(), `"triggered_by":"api_key:ci"`)
+
+	w = httptest.NewRecorder()
+	s.handleHistoryExport(w, httptest.NewRequest("GET", "/api/history/export?date_from="+url.QueryEscape(time.Now().Format(time.RFC3339)), nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "timestamp,source,operation_id", strings.Join(strings.Split(strings.SplitN(w.Body.String(), "\n", 2)[0], ",")[:3], ","))
+	assert.Equal(t, 1, strings.Count(w.Body.String(), "\n"), "operations before date_from are left out")
+
+	for _, query := range []string{"format=xml", "date_to=yesterday"} {
+		w = httptest.NewRecorder()
+		s.handleHistoryExport(w, httptest.NewRequest("GET", "/api/history/export?"+query, nil))
+		assert.Equal(t, http.StatusBadRequest, w.Code, query)
+	}
+}
+
+func TestTriggerMiddleware(t *testing.T) {
+	var trigger string
+	handler := TriggerMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		trigger = update.TriggerFromContext(r.Context())
+	}))
+
+	r := httptest.NewRequest("POST", "/api/update", nil)
+	r = r.WithContext(auth.WithPrincipal(r.Context(), &auth.Principal{Kind: "api_key", Name: "ci"}))
+	handler.ServeHTTP(httptest.NewRecorder(), r)
+	assert.Equal(t, "api_key:ci", trigger)
+}
+
+func TestHandleHookTrigger(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	hookStore := hooks.NewStore(store)
+	token, _, err := hookStore.Create(context.Background(), hooks.Hook{Name: "ci", Action: hooks.ActionUpdate, ImagePattern: "ghcr.io/org/*"})
+	require.NoError(t, err)
+	s := &Server{storageService: store, hooks: hookStore, readOnly: true}
+
+	trigger := func(token, body string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest("POST", "/api/hooks/"+token, strings.NewReader(body))
+		r.SetPathValue("token", token)
+		w := httptest.NewRecorder()
+		s.handleHookTrigger(w, r)
+		return w
+	}
+
+	w := trigger("dsh_bogus", `{}`)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	// Pushes of images outside the pattern are acknowledged but skipped
+	w = trigger(token, `{"push_data": {"tag": "16"}, "repository": {"repo_name": "library/postgres"}}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"skipped": true`)
+	assert.Contains(t, w.Body.String(), `"image": "library/postgres:16"`)
+
+	assert.Contains(t, w.Body.String(), `"source": "dockerhub"`)
+
+	w = trigger(token, `{"action": "updated", "package": {"name": "app", "namespace": "org", "package_type": "container"}}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"reason": "package action \"updated\""`)
+
+	w = trigger(token, `{"image": "ghcr.io/org/app:1.2"}`)
+	assert.Equal(t, http.StatusForbidden, w.Code, "update hooks are refused in read-only mode")
+
+	list, err := hookStore.List(context.Background())
+	require.NoError(t, err)
+	assert.NotNil(t, list[0].LastTriggeredAt)
+}
+
+func TestHandleNotificationTemplates(t *testing.T) {
+	var received map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+	}))
+	defer srv.Close()
+
+	store := storage.NewMemoryStorage()
+	s := &Server{storageService: store, notifier: notify.NewManager(store, []notify.Channel{notify.NewWebhookChannel(srv.URL)}, notify.Config{})}
+
+	call := func(method, path, channel, body string, handler http.HandlerFunc) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(method, path, strings.NewReader(body))
+		r.SetPathValue("channel", channel)
+		w := httptest.NewRecorder()
+		handler(w, r)
+		return w
+	}
+
+	w := call("PUT", "/api/notifications/templates/webhook", "webhook", `{"title": "{{.Kind}}"}`, s.handleNotificationTemplateSet)
+	require.Equal(t, http.StatusOK, w.Code)
+	w = call("PUT", "/api/notifications/templates/webhook", "webhook", `{"text": "{{.Findings"}`, s.handleNotificationTemplateSet)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = call("PUT", "/api/notifications/templates/email", "email", `{"text": "hi"}`, s.handleNotificationTemplateSet)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = call("GET", "/api/notifications/templates", "", "", s.handleNotificationTemplates)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"title": "{{.Kind}}"`)
+
+	// Previews render a sample message with the given template or the stored one
+	w = call("POST", "/api/notifications/preview", "", `{"kind": "failure", "text": "{{.Failure.ContainerName}}: {{.Failure.Error}}"}`, s.handleNotificationPreview)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"text": "plex: failed to pull image: manifest unknown"`)
+	w = call("POST", "/api/notifications/preview", "", `{"channel": "webhook", "kind": "digest"}`, s.handleNotificationPreview)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"title": "digest"`)
+
+	w = call("POST", "/api/notifications/test", "", `{"channel": "webhook"}`, s.handleNotificationTest)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "update", received["title"])
+	w = call("POST", "/api/notifications/test", "", `{"channel": "slack"}`, s.handleNotificationTest)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	w = call("POST", "/api/notifications/test", "", `{"kind": "weekly"}`, s.handleNotificationTest)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = call("DELETE", "/api/notifications/templates/webhook", "webhook", "", s.handleNotificationTemplateDelete)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, s.notifier.Templates())
+
+	s.notifier = nil
+	w = call("GET", "/api/notifications/templates", "", "", s.handleNotificationTemplates)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestOwnershipScoping(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewMemoryStorage()
+	s := &Server{storageService: store, ownership: auth.NewOwnershipService(store)}
+
+	_, err := store.CreateUser(ctx, storage.User{Username: "alice", Role: string(auth.RoleOperator)})
+	require.NoError(t, err)
+	for _, op := range []storage.UpdateOperation{
+		{OperationID: "op-plex", ContainerName: "plex", StackName: "media", OperationType: "single", Status: "complete"},
+		{OperationID: "op-web", ContainerName: "web", StackName: "infra", OperationType: "single", Status: "complete"},
+		{OperationID: "op-db", ContainerName: "db", OperationType: "single", Status: "complete"},
+	} {
+		require.NoError(t, store.SaveUpdateOperation(ctx, op))
+	}
+
+	// Admins assign stacks to owners
+	admin := func(method, path string, values ...string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(method, path, nil)
+		for i := 0; i+1 < len(values); i += 2 {
+			r.SetPathValue(values[i], values[i+1])
+		}
+		w := httptest.NewRecorder()
+		switch {
+		case strings.HasPrefix(path, "/api/teams/") && method == "PUT":
+			s.handleTeamMemberAdd(w, r)
+		case method == "PUT":
+			s.handleOwnerAdd(w, r)
+		default:
+			s.handleOwnershipList(w, r)
+		}
+		return w
+	}
+	require.Equal(t, http.StatusOK, admin("PUT", "/api/teams/media/members/alice", "team", "media", "username", "alice").Code)
+	require.Equal(t, http.StatusOK, admin("PUT", "/api/ownership/stack/media/team:media", "type", "stack", "name", "media", "owner", "team:media").Code)
+	require.Equal(t, http.StatusOK, admin("PUT", "/api/ownership/stack/infra/api_key:ci", "type", "stack", "name", "infra", "owner", "api_key:ci").Code)
+	assert.Equal(t, http.StatusBadRequest, admin("PUT", "/api/ownership/volume/data/team:media", "type", "volume", "name", "data", "owner", "team:media").Code)
+	assert.Equal(t, http.StatusNotFound, admin("PUT", "/api/ownership/stack/media/user:nobody", "type", "stack", "name", "media", "owner", "user:nobody").Code)
+	w := admin("GET", "/api/ownership")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"count": 2`)
+	assert.Contains(t, w.Body.String(), `"alice"`)
+
+	alice := &auth.Principal{Kind: "user", Name: "alice", Role: auth.RoleOperator}
+	request := func(method, path string) *http.Request {
+		r := httptest.NewRequest(method, path, nil)
+		return r.WithContext(auth.WithPrincipal(r.Context(), alice))
+	}
+
+	// Owned and unassigned operations are listed, other owners' are not
+	w = httptest.NewRecorder()
+	s.handleOperations(w, request("GET", "/api/operations"))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "op-plex")
+	assert.Contains(t, w.Body.String(), "op-db")
+	assert.NotContains(t, w.Body.String(), "op-web")
+
+	reached := false
+	next := func(w http.ResponseWriter, r *http.Request) { reached = true }
+	r := request("GET", "/api/operations/op-web")
+	r.SetPathValue("id", "op-web")
+	w = httptest.NewRecorder()
+	s.ownedOperation(next)(w, r)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.False(t, reached)
+
+	w = httptest.NewRecorder()
+	s.unlessScoped(next)(w, request("POST", "/api/prune/images"))
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	// Check results are cut down to the owned containers
+	result := &update.DiscoveryResult{
+		Containers: []update.ContainerInfo{
+			{ContainerUpdate: update.ContainerUpdate{ContainerName: "plex", Status: update.UpdateAvailable}, Stack: "media"},
+			{ContainerUpdate: update.ContainerUpdate{ContainerName: "web", Status: update.UpdateAvailable}, Stack: "infra"},
+		},
+		Stacks: map[string]*update.Stack{
+			"media": {Name: "media"},
+			"infra": {Name: "infra"},
+		},
+		UpdateOrder:  []string{"plex", "web"},
+		TotalChecked: 2,
+		UpdatesFound: 2,
+	}
+	result.Stacks["media"].Containers = result.Containers[:1]
+	result.Stacks["infra"].Containers = result.Containers[1:]
+	scope, err := s.ownership.ScopeFor(ctx, alice)
+	require.NoError(t, err)
+	scoped := scopeResult(scope, result)
+	assert.Equal(t, 1, scoped.TotalChecked)
+	assert.Equal(t, 1, scoped.UpdatesFound)
+	assert.Equal(t, []string{"plex"}, scoped.UpdateOrder)
+	assert.Contains(t, scoped.Stacks, "media")
+	assert.NotContains(t, scoped.Stacks, "infra")
+	assert.Len(t, result.Containers, 2, "the cached result is left alone")
+}
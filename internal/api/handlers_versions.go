@@ -0,0 +1,209 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/chis/docksmith/internal/storage"
+	"github.com/chis/docksmith/internal/version"
+)
+
+// versionRun is a period during which a container ran one version
+type versionRun struct {
+	Version         string     `json:"version"`
+	PreviousVersion string     `json:"previous_version,omitempty"`
+	ChangeType      string     `json:"change_type,omitempty"` // patch, minor, major, downgrade, or unknown versus the previous version
+	FirstSeen       time.Time  `json:"first_seen"`            // When the version was first seen, as an available update or running
+	StartedAt       time.Time  `json:"started_at"`            // When the container started running the version
+	DeployedAt      *time.Time `json:"deployed_at,omitempty"` // Set when docksmith deployed the version
+	EndedAt         *time.Time `json:"ended_at,omitempty"`    // Unset while the version is running
+	DurationSeconds int64      `json:"duration_seconds"`      // How long the version ran, until now for the running version
+	Source          string     `json:"source"`                // update, rollback, or observed for changes made outside docksmith
+	OperationID     string     `json:"operation_id,omitempty"`
+	RolledBack      bool       `json:"rolled_back"` // Replaced by a rollback
+	Current         bool       `json:"current"`
+}
+
+// handleContainerVersions returns the version timeline of a container, reconstructed
+// from its check history and update operations
+// GET /api/containers/{name}/versions
+func (s *Server) handleContainerVersions(w http.ResponseWriter, r *http.Request) {
+	if !s.requireStorage(w) {
+		return
+	}
+	ctx := r.Context()
+	containerName := r.PathValue("name")
+
+	checks, err := s.storageService.GetCheckHistory(ctx, containerName, 0)
+	if err != nil {
+		RespondInternalError(w, err)
+		return
+	}
+	ops, err := s.storageService.GetUpdateOperations(ctx, 0)
+	if err != nil {
+		RespondInternalError(w, err)
+		return
+	}
+
+	runs := buildVersionTimeline(containerName, checks, ops, time.Now())
+	if len(runs) == 0 {
+		RespondNotFound(w, fmt.Errorf("no version history for container '%s'", containerName))
+		return
+	}
+
+	rollbacks := 0
+	for _, run := range runs {
+		if run.Source == "rollback" {
+			rollbacks++
+		}
+	}
+
+	RespondSuccess(w, map[string]any{
+		"container":       containerName,
+		"current_version": runs[len(runs)-1].Version,
+		"versions":        runs,
+		"rollbacks":       rollbacks,
+		"count":           len(runs),
+	})
+}
+
+// versionChange is a point in time at which a container was seen running a version,
+// either by a check or by completing an operation that deployed it
+type versionChange struct {
+	at   time.Time
+	from string
+	to   string
+	op   *storage.UpdateOperation // Unset for checks
+}
+
+// buildVersionTimeline reconstructs the versions a container ran, oldest first.
+// Completed operations mark deployments; a change of the current version between
+// checks that no operation explains is recorded as observed.
+func buildVersionTimeline(containerName string, checks []storage.CheckHistoryEntry, ops []storage.UpdateOperation, now time.Time) []versionRun {
+	var changes []versionChange
+	firstSeen := make(map[string]time.Time)
+	seen := func(v string, at time.Time) {
+		if v == "" {
+			return
+		}
+		if first, ok := firstSeen[v]; !ok || at.Before(first) {
+			firstSeen[v] = at
+		}
+	}
+
+	for _, check := range checks {
+		seen(check.CurrentVersion, check.CheckTime)
+		seen(check.LatestVersion, check.CheckTime)
+		if check.CurrentVersion != "" {
+			changes = append(changes, versionChange{at: check.CheckTime, to: check.CurrentVersion})
+		}
+	}
+
+	for i := range ops {
+		op := &ops[i]
+		if op.Status != "complete" {
+			continue
+		}
+		from, to, ok := operationVersions(op, containerName)
+		if !ok {
+			continue
+		}
+		at := op.UpdatedAt
+		if op.CompletedAt != nil {
+			at = *op.CompletedAt
+		}
+		seen(from, at)
+		seen(to, at)
+		changes = append(changes, versionChange{at: at, from: from, to: to, op: op})
+	}
+
+	// Operations sort before checks made at the same time
+	sort.SliceStable(changes, func(i, j int) bool {
+		if changes[i].at.Equal(changes[j].at) {
+			return changes[i].op != nil && changes[j].op == nil
+		}
+		return changes[i].at.Before(changes[j].at)
+	})
+
+	var runs []versionRun
+	for _, change := range changes {
+		var previous string
+		if len(runs) > 0 {
+			last := &runs[len(runs)-1]
+			if change.op == nil && last.Version == change.to {
+				continue
+			}
+			at := change.at
+			last.EndedAt = &at
+			if change.op != nil && change.op.OperationType == "rollback" {
+				last.RolledBack = true
+			}
+			previous = last.Version
+		} else {
+			previous = change.from
+		}
+
+		run := versionRun{Version: change.to, PreviousVersion: previous, StartedAt: change.at, Source: "observed"}
+		if change.op != nil {
+			at := change.at
+			run.DeployedAt = &at
+			run.OperationID = change.op.OperationID
+			run.Source = "update"
+			if change.op.OperationType == "rollback" {
+				run.Source = "rollback"
+			}
+		}
+		runs = append(runs, run)
+	}
+
+	for i := range runs {
+		run := &runs[i]
+		run.FirstSeen = firstSeen[run.Version]
+		if run.PreviousVersion != "" {
+			run.ChangeType = versionChangeType(run.PreviousVersion, run.Version)
+		}
+		end := now
+		if run.EndedAt != nil {
+			end = *run.EndedAt
+		} else {
+			run.Current = true
+		}
+		run.DurationSeconds = int64(end.Sub(run.StartedAt).Seconds())
+	}
+	return runs
+}
+
+// operationVersions returns the versions an operation moved a container between.
+// Returns false when the operation did not change the container's version.
+func operationVersions(op *storage.UpdateOperation, containerName string) (string, string, bool) {
+	from, to := op.OldVersion, op.NewVersion
+	if op.ContainerName != containerName {
+		found := false
+		for _, detail := range op.BatchDetails {
+			if detail.ContainerName == containerName && detail.Status != "failed" {
+				from, to, found = detail.OldVersion, detail.NewVersion, true
+				break
+			}
+		}
+		if !found {
+			return "", "", false
+		}
+	}
+
+	// Digest rollbacks mark the restored version
+	to = strings.TrimSuffix(to, " (digest)")
+	return from, to, to != "" && from != to
+}
+
+// versionChangeType describes the change between two versions
+func versionChangeType(from, to string) string {
+	parser := version.NewParser()
+	fromVersion, toVersion := parser.ParseTag(from), parser.ParseTag(to)
+	if fromVersion == nil || toVersion == nil {
+		return version.UnknownChange.String()
+	}
+	return version.NewComparator().GetChangeType(fromVersion, toVersion).String()
+}
@@ -6,9 +6,11 @@ import (
 	"encoding/hex"
 	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/chis/docksmith/internal/logging"
+	"github.com/chis/docksmith/internal/update"
 )
 
 // contextKey is used for storing values in request context.
@@ -40,6 +42,14 @@ func CorrelationIDMiddleware(next http.Handler) http.Handler {
 	})
 }
 
+// TriggerMiddleware records the caller of a request as the trigger of the operations
+// it starts, for the audit log. Must run after AuthMiddleware.
+func TriggerMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r.WithContext(update.WithTrigger(r.Context(), requestActor(r))))
+	})
+}
+
 // RequestLoggingMiddleware logs incoming requests and their duration.
 func RequestLoggingMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -53,12 +63,13 @@ func RequestLoggingMiddleware(next http.Handler) http.Handler {
 
 		// Get correlation ID
 		correlationID := GetCorrelationID(r.Context())
+		path := redactPath(r.URL.Path)
 
 		// Log request start (debug level for high-frequency endpoints)
-		if isHighFrequencyEndpoint(r.URL.Path) {
-			logging.DebugContext(ctx, "Request started: %s %s", r.Method, r.URL.Path)
+		if isHighFrequencyEndpoint(path) {
+			logging.DebugContext(ctx, "Request started: %s %s", r.Method, path)
 		} else {
-			logging.InfoContext(ctx, "Request started: %s %s", r.Method, r.URL.Path)
+			logging.InfoContext(ctx, "Request started: %s %s", r.Method, path)
 		}
 
 		next.ServeHTTP(wrapped, r.WithContext(ctx))
@@ -69,7 +80,7 @@ func RequestLoggingMiddleware(next http.Handler) http.Handler {
 
 		fields := map[string]interface{}{
 			"method":      r.Method,
-			"path":        r.URL.Path,
+			"path":        path,
 			"status":      statusCode,
 			"duration_ms": duration.Milliseconds(),
 			"client_ip":   getClientIP(r),
@@ -81,17 +92,25 @@ func RequestLoggingMiddleware(next http.Handler) http.Handler {
 		logger := logging.Default().WithFields(fields)
 
 		if statusCode >= 500 {
-			logger.Error("Request failed: %s %s - %d", r.Method, r.URL.Path, statusCode)
+			logger.Error("Request failed: %s %s - %d", r.Method, path, statusCode)
 		} else if statusCode >= 400 {
-			logger.Warn("Request error: %s %s - %d", r.Method, r.URL.Path, statusCode)
-		} else if isHighFrequencyEndpoint(r.URL.Path) {
-			logger.Debug("Request completed: %s %s - %d (%dms)", r.Method, r.URL.Path, statusCode, duration.Milliseconds())
+			logger.Warn("Request error: %s %s - %d", r.Method, path, statusCode)
+		} else if isHighFrequencyEndpoint(path) {
+			logger.Debug("Request completed: %s %s - %d (%dms)", r.Method, path, statusCode, duration.Milliseconds())
 		} else {
-			logger.Info("Request completed: %s %s - %d (%dms)", r.Method, r.URL.Path, statusCode, duration.Milliseconds())
+			logger.Info("Request completed: %s %s - %d (%dms)", r.Method, path, statusCode, duration.Milliseconds())
 		}
 	})
 }
 
+// redactPath hides the token of incoming webhook paths (/api/hooks/{token}) in logs.
+func redactPath(path string) string {
+	if strings.HasPrefix(path, "/api/hooks/") {
+		return "/api/hooks/[REDACTED]"
+	}
+	return path
+}
+
 // responseWriter wraps http.ResponseWriter to capture the status code.
 type responseWriter struct {
 	http.ResponseWriter
@@ -108,6 +127,7 @@ func (rw *responseWriter) WriteHeader(code int) {
 func isHighFrequencyEndpoint(path string) bool {
 	highFrequencyPaths := []string{
 		"/api/health",
+		"/api/ready",
 		"/api/events",
 		"/api/status",
 	}
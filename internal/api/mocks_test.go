@@ -2,6 +2,7 @@ package api
 
 import (
 	"context"
+	"fmt"
 	"sync"
 	"time"
 
@@ -20,6 +21,10 @@ type MockStorage struct {
 	policies          map[string]storage.RollbackPolicy
 	configs           map[string]string
 	scriptAssignments map[string]storage.ScriptAssignment
+	users             map[string]storage.User
+	sessions          map[string]storage.Session
+	approvals         []storage.Approval
+	proposals         []storage.Proposal
 
 	// Error injection
 	GetError  error
@@ -35,6 +40,8 @@ func NewMockStorage() *MockStorage {
 		policies:          make(map[string]storage.RollbackPolicy),
 		configs:           make(map[string]string),
 		scriptAssignments: make(map[string]storage.ScriptAssignment),
+		users:             make(map[string]storage.User),
+		sessions:          make(map[string]storage.Session),
 	}
 }
 
@@ -280,6 +287,24 @@ func (m *MockStorage) GetUpdateOperationsByStatus(ctx context.Context, status st
 	return result, nil
 }
 
+func (m *MockStorage) GetUnfinishedOperations(ctx context.Context) ([]storage.UpdateOperation, error) {
+	if m.GetError != nil {
+		return nil, m.GetError
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var result []storage.UpdateOperation
+	for _, op := range m.operations {
+		switch op.Status {
+		case storage.StatusComplete, storage.StatusFailed, "cancelled":
+		default:
+			result = append(result, op)
+		}
+	}
+	return result, nil
+}
+
 func (m *MockStorage) UpdateOperationStatus(ctx context.Context, operationID string, status string, errorMsg string) error {
 	if m.SaveError != nil {
 		return m.SaveError
@@ -383,7 +408,32 @@ func (m *MockStorage) DeleteScriptAssignment(ctx context.Context, containerName
 }
 
 func (m *MockStorage) QueryUpdateOperations(ctx context.Context, opts storage.OperationQueryOptions) (storage.OperationQueryResult, error) {
-	return storage.OperationQueryResult{}, nil
+	if m.GetError != nil {
+		return storage.OperationQueryResult{}, m.GetError
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	result := make([]storage.UpdateOperation, 0)
+	for _, op := range m.operations {
+		if opts.Status != "" && op.Status != opts.Status {
+			continue
+		}
+		if opts.Container != "" && op.ContainerName != opts.Container {
+			continue
+		}
+		if opts.Type != "" && op.OperationType != opts.Type {
+			continue
+		}
+		result = append(result, op)
+	}
+
+	hasMore := false
+	if opts.Limit > 0 && len(result) > opts.Limit {
+		result = result[:opts.Limit]
+		hasMore = true
+	}
+	return storage.OperationQueryResult{Operations: result, HasMore: hasMore}, nil
 }
 
 func (m *MockStorage) DeleteAllHistory(ctx context.Context) (int64, error) {
@@ -398,6 +448,466 @@ func (m *MockStorage) Close() error {
 	return nil
 }
 
+func (m *MockStorage) CreateUser(ctx context.Context, user storage.User) (storage.User, error) {
+	if m.SaveError != nil {
+		return storage.User{}, m.SaveError
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, exists := m.users[user.Username]; exists {
+		return storage.User{}, fmt.Errorf("user %s already exists", user.Username)
+	}
+	user.ID = int64(len(m.users) + 1)
+	user.CreatedAt = time.Now()
+	user.UpdatedAt = user.CreatedAt
+	m.users[user.Username] = user
+	return user, nil
+}
+
+func (m *MockStorage) GetUser(ctx context.Context, username string) (storage.User, bool, error) {
+	if m.GetError != nil {
+		return storage.User{}, false, m.GetError
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	user, ok := m.users[username]
+	return user, ok, nil
+}
+
+func (m *MockStorage) GetUserByID(ctx context.Context, id int64) (storage.User, bool, error) {
+	if m.GetError != nil {
+		return storage.User{}, false, m.GetError
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, user := range m.users {
+		if user.ID == id {
+			return user, true, nil
+		}
+	}
+	return storage.User{}, false, nil
+}
+
+func (m *MockStorage) ListUsers(ctx context.Context) ([]storage.User, error) {
+	if m.GetError != nil {
+		return nil, m.GetError
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	users := make([]storage.User, 0, len(m.users))
+	for _, user := range m.users {
+		users = append(users, user)
+	}
+	return users, nil
+}
+
+func (m *MockStorage) UpdateUser(ctx context.Context, user storage.User) error {
+	if m.SaveError != nil {
+		return m.SaveError
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	existing, ok := m.users[user.Username]
+	if !ok {
+		return fmt.Errorf("user %s not found", user.Username)
+	}
+	existing.Role = user.Role
+	existing.PasswordHash = user.PasswordHash
+	existing.UpdatedAt = time.Now()
+	m.users[user.Username] = existing
+	return nil
+}
+
+func (m *MockStorage) DeleteUser(ctx context.Context, username string) error {
+	if m.SaveError != nil {
+		return m.SaveError
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	user, ok := m.users[username]
+	if !ok {
+		return fmt.Errorf("user %s not found", username)
+	}
+	delete(m.users, username)
+	for hash, session := range m.sessions {
+		if session.UserID == user.ID {
+			delete(m.sessions, hash)
+		}
+	}
+	return nil
+}
+
+func (m *MockStorage) SaveSession(ctx context.Context, session storage.Session) error {
+	if m.SaveError != nil {
+		return m.SaveError
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	session.CreatedAt = time.Now()
+	m.sessions[session.TokenHash] = session
+	return nil
+}
+
+func (m *MockStorage) GetSession(ctx context.Context, tokenHash string) (storage.Session, bool, error) {
+	if m.GetError != nil {
+		return storage.Session{}, false, m.GetError
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	session, ok := m.sessions[tokenHash]
+	if !ok || time.Now().After(session.ExpiresAt) {
+		return storage.Session{}, false, nil
+	}
+	return session, true, nil
+}
+
+func (m *MockStorage) DeleteSession(ctx context.Context, tokenHash string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.sessions, tokenHash)
+	return nil
+}
+
+func (m *MockStorage) DeleteExpiredSessions(ctx context.Context, now time.Time) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var deleted int64
+	for hash, session := range m.sessions {
+		if session.ExpiresAt.Before(now) {
+			delete(m.sessions, hash)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+func (m *MockStorage) SaveApproval(ctx context.Context, approval storage.Approval) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.SaveError != nil {
+		return m.SaveError
+	}
+	for i, existing := range m.approvals {
+		if existing.ID == approval.ID {
+			m.approvals[i] = approval
+			return nil
+		}
+	}
+	m.approvals = append(m.approvals, approval)
+	return nil
+}
+
+func (m *MockStorage) GetApproval(ctx context.Context, id string) (storage.Approval, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.GetError != nil {
+		return storage.Approval{}, false, m.GetError
+	}
+	for _, approval := range m.approvals {
+		if approval.ID == id {
+			return approval, true, nil
+		}
+	}
+	return storage.Approval{}, false, nil
+}
+
+func (m *MockStorage) GetLatestApproval(ctx context.Context, containerName string) (storage.Approval, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i := len(m.approvals) - 1; i >= 0; i-- {
+		if m.approvals[i].ContainerName == containerName {
+			return m.approvals[i], true, nil
+		}
+	}
+	return storage.Approval{}, false, nil
+}
+
+func (m *MockStorage) ListApprovals(ctx context.Context, status string, limit int) ([]storage.Approval, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.GetError != nil {
+		return nil, m.GetError
+	}
+	result := make([]storage.Approval, 0)
+	for i := len(m.approvals) - 1; i >= 0; i-- {
+		if status == "" || m.approvals[i].Status == status {
+			result = append(result, m.approvals[i])
+		}
+	}
+	if limit > 0 && len(result) > limit {
+		result = result[:limit]
+	}
+	return result, nil
+}
+
+func (m *MockStorage) ExpireApprovals(ctx context.Context, now time.Time) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var expired int64
+	for i, approval := range m.approvals {
+		if approval.Status == storage.ApprovalPending && approval.ExpiresAt.Before(now) {
+			m.approvals[i].Status = storage.ApprovalExpired
+			expired++
+		}
+	}
+	return expired, nil
+}
+
+func (m *MockStorage) SaveProposal(ctx context.Context, proposal storage.Proposal) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.SaveError != nil {
+		return m.SaveError
+	}
+	for i, existing := range m.proposals {
+		if existing.ID == proposal.ID {
+			m.proposals[i] = proposal
+			return nil
+		}
+	}
+	m.proposals = append(m.proposals, proposal)
+	return nil
+}
+
+func (m *MockStorage) GetProposal(ctx context.Context, id string) (storage.Proposal, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.GetError != nil {
+		return storage.Proposal{}, false, m.GetError
+	}
+	for _, proposal := range m.proposals {
+		if proposal.ID == id {
+			return proposal, true, nil
+		}
+	}
+	return storage.Proposal{}, false, nil
+}
+
+func (m *MockStorage) GetLatestProposal(ctx context.Context, containerName string) (storage.Proposal, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i := len(m.proposals) - 1; i >= 0; i-- {
+		if m.proposals[i].ContainerName == containerName {
+			return m.proposals[i], true, nil
+		}
+	}
+	return storage.Proposal{}, false, nil
+}
+
+func (m *MockStorage) ListProposals(ctx context.Context, status string, limit int) ([]storage.Proposal, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.GetError != nil {
+		return nil, m.GetError
+	}
+	result := make([]storage.Proposal, 0)
+	for i := len(m.proposals) - 1; i >= 0; i-- {
+		if status == "" || m.proposals[i].Status == status {
+			result = append(result, m.proposals[i])
+		}
+	}
+	if limit > 0 && len(result) > limit {
+		result = result[:limit]
+	}
+	return result, nil
+}
+
+func (m *MockStorage) GetTagCache(ctx context.Context, imageRef string) (storage.TagCacheEntry, bool, error) {
+	return storage.TagCacheEntry{}, false, nil
+}
+
+func (m *MockStorage) SaveTagCache(ctx context.Context, entry storage.TagCacheEntry) error {
+	return nil
+}
+
+func (m *MockStorage) GetCheckFingerprint(ctx context.Context, containerName string) (storage.CheckFingerprint, bool, error) {
+	return storage.CheckFingerprint{}, false, nil
+}
+
+func (m *MockStorage) SaveCheckFingerprint(ctx context.Context, fingerprint storage.CheckFingerprint) error {
+	return nil
+}
+
+func (m *MockStorage) QueryCheckHistory(ctx context.Context, opts storage.CheckHistoryQueryOptions) ([]storage.CheckHistoryEntry, error) {
+	return nil, nil
+}
+
+func (m *MockStorage) ListRollbackPolicies(ctx context.Context) ([]storage.RollbackPolicy, error) {
+	if m.GetError != nil {
+		return nil, m.GetError
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var result []storage.RollbackPolicy
+	for _, p := range m.policies {
+		result = append(result, p)
+	}
+	return result, nil
+}
+
+func (m *MockStorage) Backup(ctx context.Context, destPath string) error {
+	return nil
+}
+
+func (m *MockStorage) Vacuum(ctx context.Context) (storage.VacuumResult, error) {
+	return storage.VacuumResult{}, nil
+}
+
+func (m *MockStorage) PruneHistory(ctx context.Context, opts storage.PruneOptions) (storage.PruneResult, error) {
+	return storage.PruneResult{}, nil
+}
+
+func (m *MockStorage) ListComposeBackups(ctx context.Context) ([]storage.ComposeBackup, error) {
+	return nil, nil
+}
+
+func (m *MockStorage) DeleteComposeBackups(ctx context.Context, ids []int64) (int64, error) {
+	return 0, nil
+}
+
+func (m *MockStorage) SaveScriptRevision(ctx context.Context, revision storage.ScriptRevision) error {
+	return nil
+}
+
+func (m *MockStorage) GetScriptRevision(ctx context.Context, name string, revision int) (storage.ScriptRevision, bool, error) {
+	return storage.ScriptRevision{}, false, nil
+}
+
+func (m *MockStorage) ListScriptRevisions(ctx context.Context, name string) ([]storage.ScriptRevision, error) {
+	return nil, nil
+}
+
+func (m *MockStorage) SaveVolumeSnapshot(ctx context.Context, snapshot storage.VolumeSnapshot) error {
+	return nil
+}
+
+func (m *MockStorage) GetVolumeSnapshots(ctx context.Context, operationID string) ([]storage.VolumeSnapshot, error) {
+	return nil, nil
+}
+
+func (m *MockStorage) ListVolumeSnapshots(ctx context.Context, volumeName string) ([]storage.VolumeSnapshot, error) {
+	return nil, nil
+}
+
+func (m *MockStorage) DeleteVolumeSnapshot(ctx context.Context, id int64) error {
+	return nil
+}
+
+func (m *MockStorage) GetApprovalPolicy(ctx context.Context, entityType, entityID string) (storage.ApprovalPolicy, bool, error) {
+	return storage.ApprovalPolicy{}, false, nil
+}
+
+func (m *MockStorage) SetApprovalPolicy(ctx context.Context, policy storage.ApprovalPolicy) error {
+	return nil
+}
+
+func (m *MockStorage) ListApprovalPolicies(ctx context.Context) ([]storage.ApprovalPolicy, error) {
+	return nil, nil
+}
+
+func (m *MockStorage) DeleteApprovalPolicy(ctx context.Context, entityType, entityID string) error {
+	return nil
+}
+
+func (m *MockStorage) AppendOperationLog(ctx context.Context, entry storage.OperationLogEntry) (int64, error) {
+	return 0, nil
+}
+
+func (m *MockStorage) GetOperationLogs(ctx context.Context, operationID string, afterID int64) ([]storage.OperationLogEntry, error) {
+	return nil, nil
+}
+
+func (m *MockStorage) AppendComposeOutput(ctx context.Context, operationID string, output string) error {
+	return nil
+}
+
+func (m *MockStorage) ListIgnoreRules(ctx context.Context) ([]storage.IgnoreRule, error) {
+	return nil, nil
+}
+
+func (m *MockStorage) SaveIgnoreRule(ctx context.Context, rule storage.IgnoreRule) (storage.IgnoreRule, error) {
+	return rule, nil
+}
+
+func (m *MockStorage) DeleteIgnoreRule(ctx context.Context, id int64) (bool, error) {
+	return false, nil
+}
+
+func (m *MockStorage) GetStackEnv(ctx context.Context, stack string) (storage.StackEnv, bool, error) {
+	return storage.StackEnv{}, false, nil
+}
+
+func (m *MockStorage) SetStackEnv(ctx context.Context, env storage.StackEnv) error {
+	return nil
+}
+
+func (m *MockStorage) ListStackEnvs(ctx context.Context) ([]storage.StackEnv, error) {
+	return nil, nil
+}
+
+func (m *MockStorage) DeleteStackEnv(ctx context.Context, stack string) (bool, error) {
+	return false, nil
+}
+
+func (m *MockStorage) ListOwnerships(ctx context.Context) ([]storage.Ownership, error) {
+	return nil, nil
+}
+
+func (m *MockStorage) AddOwnership(ctx context.Context, ownership storage.Ownership) error {
+	return nil
+}
+
+func (m *MockStorage) RemoveOwnership(ctx context.Context, entityType, entityID, owner string) (bool, error) {
+	return false, nil
+}
+
+func (m *MockStorage) ListTeamMembers(ctx context.Context) ([]storage.TeamMember, error) {
+	return nil, nil
+}
+
+func (m *MockStorage) AddTeamMember(ctx context.Context, team, username string) error {
+	return nil
+}
+
+func (m *MockStorage) RemoveTeamMember(ctx context.Context, team, username string) (bool, error) {
+	return false, nil
+}
+
+func (m *MockStorage) CheckWritable(ctx context.Context) error {
+	return m.SaveError
+}
+
+func (m *MockStorage) SaveStackLock(ctx context.Context, lock storage.StackLock) error {
+	return nil
+}
+
+func (m *MockStorage) DeleteStackLock(ctx context.Context, stackName, operationID string) error {
+	return nil
+}
+
+func (m *MockStorage) ListStackLocks(ctx context.Context) ([]storage.StackLock, error) {
+	return nil, nil
+}
+
+func (m *MockStorage) SetQueuePriorities(ctx context.Context, priorities map[string]int) error {
+	return nil
+}
+
+func (m *MockStorage) DeleteQueuedUpdate(ctx context.Context, operationID string) (bool, error) {
+	return false, nil
+}
+
 // MockBackgroundChecker simulates the background checker for testing
 type MockBackgroundChecker struct {
 	mu           sync.RWMutex
@@ -0,0 +1,25 @@
+package api
+
+import (
+	"context"
+
+	"github.com/chis/docksmith/internal/update"
+)
+
+// mqttUpdate starts an update installed from Home Assistant, subject to the same
+// checks as POST /api/update.
+func (s *Server) mqttUpdate(ctx context.Context, containerName string) (string, error) {
+	if s.updateOrchestrator == nil {
+		return "", errNoUpdateOrchestrator
+	}
+	if s.readOnly {
+		return "", update.ErrReadOnly
+	}
+	if s.proposals != nil && s.proposals.Enabled(ctx) {
+		return "", errProposeOnly
+	}
+	if s.approvals != nil && s.approvals.Required(ctx, containerName, "") {
+		return "", errApprovalRequired(containerName)
+	}
+	return s.updateOrchestrator.UpdateSingleContainer(ctx, containerName, "")
+}
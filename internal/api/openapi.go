@@ -0,0 +1,186 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/chis/docksmith/internal/graph"
+	"github.com/chis/docksmith/internal/openapi"
+	"github.com/chis/docksmith/internal/output"
+)
+
+// apiOperation is an API endpoint as described by the OpenAPI document
+type apiOperation struct {
+	Method  string
+	Path    string // Pattern registered with the mux
+	ID      string // operationId, and the method name in the generated client
+	Tag     string
+	Summary string
+	Query   []string // Query parameters
+
+	Request     any    // Value of the JSON request body type; nil for no body
+	RequestType string // Content type of a non-JSON request body
+	Response    any    // Value of the envelope's data type; nil for free-form data
+	Raw         string // Content type of a response not wrapped in the envelope
+	Alternate   string // Content type of a response selected by a query parameter
+	NoClient    bool   // Streams and redirects are left out of the generated client
+}
+
+// freeForm is the Request of operations with a JSON body of no Go type
+var freeForm json.RawMessage
+
+// Component names of the response envelope schema and the error response
+const (
+	envelopeSchema = "Envelope"
+	errorResponse  = "Error"
+)
+
+// schemaNames overrides the component names of types whose own name is ambiguous
+var schemaNames = map[reflect.Type]string{
+	reflect.TypeFor[output.Response](): envelopeSchema,
+	reflect.TypeFor[graph.Export]():    "Graph",
+	reflect.TypeFor[NetworkInfo]():     "ContainerNetworkInfo",
+}
+
+// OpenAPI returns the OpenAPI document of the API. The document is shared and
+// must not be modified.
+func OpenAPI() *openapi.Document {
+	return openAPIDocument()
+}
+
+var openAPIDocument = sync.OnceValue(buildOpenAPI)
+
+// buildOpenAPI describes apiOperations as an OpenAPI document
+func buildOpenAPI() *openapi.Document {
+	types := []reflect.Type{reflect.TypeFor[output.Response]()}
+	for _, op := range apiOperations {
+		for _, v := range []any{op.Request, op.Response} {
+			if v != nil {
+				types = append(types, reflect.TypeOf(v))
+			}
+		}
+	}
+	registry := openapi.NewRegistry(schemaNames, types...)
+	envelope := registry.Schema(reflect.TypeFor[output.Response]())
+
+	doc := &openapi.Document{
+		OpenAPI: openapi.Version,
+		Info: openapi.Info{
+			Title:       "Docksmith API",
+			Description: "JSON responses are wrapped in an envelope with the result in data, or the failure in error.",
+			Version:     output.Version,
+		},
+		Security: []openapi.SecurityRequirement{{"bearer": {}}, {"apiKey": {}}},
+		Paths:    make(map[string]openapi.PathItem),
+	}
+	tags := make(map[string]bool)
+	for _, op := range apiOperations {
+		if !tags[op.Tag] {
+			tags[op.Tag] = true
+			doc.Tags = append(doc.Tags, openapi.Tag{Name: op.Tag})
+		}
+		path := strings.ReplaceAll(op.Path, "...}", "}")
+		if doc.Paths[path] == nil {
+			doc.Paths[path] = make(openapi.PathItem)
+		}
+		doc.Paths[path][strings.ToLower(op.Method)] = op.spec(registry, envelope)
+	}
+
+	doc.Components = openapi.Components{
+		Schemas: registry.Schemas(),
+		Responses: map[string]openapi.Response{
+			errorResponse: {
+				Description: "The request failed; error says why",
+				Content:     map[string]openapi.MediaType{contentJSON: {Schema: envelope}},
+			},
+		},
+		SecuritySchemes: map[string]openapi.SecurityScheme{
+			"bearer": {Type: "http", Scheme: "bearer"},
+			"apiKey": {Type: "apiKey", In: "header", Name: "X-API-Key"},
+		},
+	}
+	return doc
+}
+
+// spec describes the operation in an OpenAPI document
+func (op apiOperation) spec(registry *openapi.Registry, envelope *openapi.Schema) *openapi.Operation {
+	spec := &openapi.Operation{
+		OperationID: op.ID,
+		Summary:     op.Summary,
+		Tags:        []string{op.Tag},
+		Responses: map[string]openapi.Response{
+			"default": {Ref: "#/components/responses/" + errorResponse},
+		},
+		NoClient: op.NoClient,
+	}
+	if requiresAuth(op.Path) {
+		spec.Role = string(requiredRole(op.Method, op.Path))
+	} else {
+		spec.Security = &[]openapi.SecurityRequirement{}
+	}
+
+	for _, name := range pathParams(op.Path) {
+		spec.Parameters = append(spec.Parameters, openapi.Parameter{Name: name, In: "path", Required: true, Schema: &openapi.Schema{Type: "string"}})
+	}
+	for _, name := range op.Query {
+		spec.Parameters = append(spec.Parameters, openapi.Parameter{Name: name, In: "query", Schema: &openapi.Schema{Type: "string"}})
+	}
+
+	switch {
+	case op.RequestType != "":
+		spec.RequestBody = &openapi.RequestBody{Required: true, Content: map[string]openapi.MediaType{
+			op.RequestType: {Schema: &openapi.Schema{Type: "string"}},
+		}}
+	case op.Request != nil:
+		spec.RequestBody = &openapi.RequestBody{Required: true, Content: map[string]openapi.MediaType{
+			contentJSON: {Schema: registry.Schema(reflect.TypeOf(op.Request))},
+		}}
+	}
+
+	if op.NoClient && op.Raw == "" {
+		spec.Responses["302"] = openapi.Response{Description: "Redirect"}
+		return spec
+	}
+	content := make(map[string]openapi.MediaType)
+	switch op.Raw {
+	case "":
+		data := &openapi.Schema{}
+		if op.Response != nil {
+			data = registry.Schema(reflect.TypeOf(op.Response))
+		}
+		content[contentJSON] = openapi.MediaType{Schema: &openapi.Schema{AllOf: []*openapi.Schema{
+			envelope,
+			{Type: "object", Properties: map[string]*openapi.Schema{"data": data}},
+		}}}
+	case contentJSON:
+		content[op.Raw] = openapi.MediaType{Schema: &openapi.Schema{}}
+	default:
+		content[op.Raw] = openapi.MediaType{Schema: &openapi.Schema{Type: "string"}}
+	}
+	if op.Alternate != "" {
+		content[op.Alternate] = openapi.MediaType{Schema: &openapi.Schema{Type: "string"}}
+	}
+	spec.Responses["200"] = openapi.Response{Description: "OK", Content: content}
+	return spec
+}
+
+// pathParams returns the names of the wildcards of a mux pattern
+func pathParams(path string) []string {
+	var names []string
+	for _, segment := range strings.Split(path, "/") {
+		if name, ok := strings.CutPrefix(segment, "{"); ok {
+			names = append(names, strings.TrimSuffix(strings.TrimSuffix(name, "}"), "..."))
+		}
+	}
+	return names
+}
+
+// handleOpenAPI serves the OpenAPI document of the API
+// GET /api/openapi.json
+func (s *Server) handleOpenAPI(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", contentJSON)
+	json.NewEncoder(w).Encode(OpenAPI())
+}
@@ -0,0 +1,224 @@
+package api
+
+import (
+	"github.com/chis/docksmith/internal/graph"
+	"github.com/chis/docksmith/internal/secrets"
+	"github.com/chis/docksmith/internal/storage"
+	"github.com/chis/docksmith/internal/update"
+)
+
+// Content types of responses that are not wrapped in the JSON envelope
+const (
+	contentJSON   = "application/json"
+	contentYAML   = "application/yaml"
+	contentText   = "text/plain"
+	contentEvents = "text/event-stream"
+)
+
+// apiOperations is the API served by registerRoutes, in the same order. Tags and
+// summaries follow docs/api.md.
+var apiOperations = []apiOperation{
+	// Health check
+	{Method: "GET", Path: "/api/health", ID: "Health", Tag: "Health & Status", Summary: "Server health check (liveness)", Query: []string{"deep"}},
+	{Method: "GET", Path: "/api/ready", ID: "Ready", Tag: "Health & Status", Summary: "Readiness checks: Docker, database, registries, queue, background checker"},
+	{Method: "GET", Path: "/api/openapi.json", ID: "GetOpenAPI", Tag: "Health & Status", Summary: "This OpenAPI document", Raw: contentJSON},
+
+	// Authentication
+	{Method: "POST", Path: "/api/auth/login", ID: "Login", Tag: "Auth & Users", Summary: "Log in with username/password (sets session cookie)", Request: freeForm},
+	{Method: "POST", Path: "/api/auth/logout", ID: "Logout", Tag: "Auth & Users", Summary: "End the current session"},
+	{Method: "GET", Path: "/api/auth/me", ID: "GetAuthMe", Tag: "Auth & Users", Summary: "Current principal and role"},
+	{Method: "GET", Path: "/api/auth/oidc/login", ID: "OIDCLogin", Tag: "Auth & Users", Summary: "Redirect to the OIDC provider", NoClient: true},
+	{Method: "GET", Path: "/api/auth/oidc/callback", ID: "OIDCCallback", Tag: "Auth & Users", Summary: "OIDC redirect target (starts a session)", NoClient: true},
+
+	// User management
+	{Method: "GET", Path: "/api/users", ID: "ListUsers", Tag: "Auth & Users", Summary: "List users"},
+	{Method: "POST", Path: "/api/users", ID: "CreateUser", Tag: "Auth & Users", Summary: "Create a user", Request: freeForm, Response: storage.User{}},
+	{Method: "PUT", Path: "/api/users/{username}", ID: "UpdateUser", Tag: "Auth & Users", Summary: "Change a user's role or password", Request: freeForm},
+	{Method: "DELETE", Path: "/api/users/{username}", ID: "DeleteUser", Tag: "Auth & Users", Summary: "Delete a user"},
+
+	// Stack ownership
+	{Method: "GET", Path: "/api/ownership", ID: "ListOwnership", Tag: "Auth & Users", Summary: "List stack and container owners and team members", Response: OwnershipResponse{}},
+	{Method: "PUT", Path: "/api/ownership/{type}/{name}/{owner}", ID: "AddOwner", Tag: "Auth & Users", Summary: "Assign a stack or container to an owner"},
+	{Method: "DELETE", Path: "/api/ownership/{type}/{name}/{owner}", ID: "RemoveOwner", Tag: "Auth & Users", Summary: "Remove an owner"},
+	{Method: "PUT", Path: "/api/teams/{team}/members/{username}", ID: "AddTeamMember", Tag: "Auth & Users", Summary: "Add a user to a team"},
+	{Method: "DELETE", Path: "/api/teams/{team}/members/{username}", ID: "RemoveTeamMember", Tag: "Auth & Users", Summary: "Remove a user from a team"},
+
+	// Docker configuration
+	{Method: "GET", Path: "/api/docker-config", ID: "GetDockerConfig", Tag: "Health & Status", Summary: "Docker configuration info", Response: DockerConfig{}},
+
+	// Discovery and checking
+	{Method: "GET", Path: "/api/check", ID: "Check", Tag: "Discovery & Checking", Summary: "Check all containers (clears cache)", Response: update.DiscoveryResult{}},
+	{Method: "GET", Path: "/api/status", ID: "GetStatus", Tag: "Health & Status", Summary: "System status with last check time", Response: update.DiscoveryResult{}},
+	{Method: "POST", Path: "/api/trigger-check", ID: "TriggerCheck", Tag: "Discovery & Checking", Summary: "Background check (uses cache)"},
+	{Method: "GET", Path: "/api/container/{name}/recheck", ID: "RecheckContainer", Tag: "Discovery & Checking", Summary: "Recheck single container", Response: update.ContainerInfo{}},
+	{Method: "GET", Path: "/api/stacks", ID: "ListStacks", Tag: "Discovery & Checking", Summary: "Compose stacks with update counts, compose files, and lock state"},
+	{Method: "GET", Path: "/api/locks", ID: "ListLocks", Tag: "Discovery & Checking", Summary: "Held stack locks with their operations"},
+	{Method: "POST", Path: "/api/locks/{stack}/release", ID: "ReleaseLock", Tag: "Discovery & Checking", Summary: "Release a stack lock", Query: []string{"force"}},
+	{Method: "GET", Path: "/api/queue", ID: "GetQueue", Tag: "Discovery & Checking", Summary: "Queued operations with positions and estimated start times"},
+	{Method: "POST", Path: "/api/queue/reorder", ID: "ReorderQueue", Tag: "Discovery & Checking", Summary: "Reorder the queue of a stack", Request: freeForm},
+	{Method: "POST", Path: "/api/queue/{id}/priority", ID: "SetQueuePriority", Tag: "Discovery & Checking", Summary: "Change the priority of a queued operation", Request: freeForm},
+	{Method: "DELETE", Path: "/api/queue/{id}", ID: "RemoveFromQueue", Tag: "Discovery & Checking", Summary: "Remove an operation from the queue"},
+	{Method: "GET", Path: "/api/graph", ID: "GetGraph", Tag: "Discovery & Checking", Summary: "Container dependency graph (JSON, or Graphviz DOT with format=dot)", Query: []string{"format"}, Response: graph.Export{}, Alternate: "text/vnd.graphviz"},
+
+	// Background checker
+	{Method: "GET", Path: "/api/checker", ID: "GetChecker", Tag: "Discovery & Checking", Summary: "Background checker schedule (interval, jitter, last/next run)", Response: update.CheckerStatus{}},
+	{Method: "POST", Path: "/api/checker/pause", ID: "PauseChecker", Tag: "Discovery & Checking", Summary: "Pause scheduled background checks", Response: update.CheckerStatus{}},
+	{Method: "POST", Path: "/api/checker/resume", ID: "ResumeChecker", Tag: "Discovery & Checking", Summary: "Resume scheduled background checks", Response: update.CheckerStatus{}},
+
+	// Operations
+	{Method: "GET", Path: "/api/operations", ID: "ListOperations", Tag: "History & Operations", Summary: "List operations with filtering", Query: []string{"limit", "cursor", "status", "container", "type", "date_from", "date_to"}, Response: OperationsResponse{}},
+	{Method: "GET", Path: "/api/operations/{id}", ID: "GetOperation", Tag: "History & Operations", Summary: "Get operation by ID", Response: storage.UpdateOperation{}},
+	{Method: "POST", Path: "/api/operations/{id}/pause", ID: "PauseOperation", Tag: "History & Operations", Summary: "Pause a running update before containers are recreated"},
+	{Method: "POST", Path: "/api/operations/{id}/resume", ID: "ResumeOperation", Tag: "History & Operations", Summary: "Resume a paused update"},
+	{Method: "GET", Path: "/api/operations/{id}/volume-snapshots", ID: "ListVolumeSnapshots", Tag: "History & Operations", Summary: "Volume snapshots taken by an update"},
+	{Method: "GET", Path: "/api/operations/{id}/logs", ID: "StreamOperationLogs", Tag: "History & Operations", Summary: "Stream the step log of an operation (SSE)", Query: []string{"after", "follow"}, Raw: contentEvents, NoClient: true},
+	{Method: "POST", Path: "/api/operations/{id}/restore-volumes", ID: "RestoreVolumes", Tag: "History & Operations", Summary: "Restore the volume snapshots taken by an update"},
+	{Method: "GET", Path: "/api/operations/group/{groupId}", ID: "ListOperationsByGroup", Tag: "History & Operations", Summary: "Operations of a batch"},
+
+	// Settings
+	{Method: "GET", Path: "/api/settings/{key}", ID: "GetSetting", Tag: "Configuration", Summary: "Get a setting"},
+	{Method: "PUT", Path: "/api/settings/{key}", ID: "SetSetting", Tag: "Configuration", Summary: "Change a setting", Request: freeForm},
+
+	// History
+	{Method: "GET", Path: "/api/history", ID: "GetHistory", Tag: "History & Operations", Summary: "Check and update history", Query: []string{"limit", "type"}},
+	{Method: "GET", Path: "/api/history/timeline", ID: "GetTimeline", Tag: "History & Operations", Summary: "Merged check and update timeline", Query: []string{"container", "stack", "status", "type", "limit", "date_from", "date_to"}, Response: TimelineResponse{}},
+	{Method: "GET", Path: "/api/history/export", ID: "ExportHistory", Tag: "History & Operations", Summary: "Download the update audit log as CSV or JSON lines", Query: []string{"format", "date_from", "date_to"}, Raw: "text/csv", Alternate: "application/x-ndjson"},
+	{Method: "DELETE", Path: "/api/history/clear", ID: "ClearHistory", Tag: "History & Operations", Summary: "Delete old operation history", Request: freeForm},
+
+	// Policies
+	{Method: "GET", Path: "/api/policies", ID: "GetPolicies", Tag: "History & Operations", Summary: "Get rollback and approval policies"},
+	{Method: "PUT", Path: "/api/policies/approval/{scope}", ID: "SetApprovalPolicy", Tag: "Approvals", Summary: "Set the global approval policy", Request: freeForm, Response: storage.ApprovalPolicy{}},
+	{Method: "PUT", Path: "/api/policies/approval/{scope}/{name}", ID: "SetEntityApprovalPolicy", Tag: "Approvals", Summary: "Set the approval policy of a stack or container", Request: freeForm, Response: storage.ApprovalPolicy{}},
+	{Method: "DELETE", Path: "/api/policies/approval/{scope}", ID: "DeleteApprovalPolicy", Tag: "Approvals", Summary: "Remove the global approval policy"},
+	{Method: "DELETE", Path: "/api/policies/approval/{scope}/{name}", ID: "DeleteEntityApprovalPolicy", Tag: "Approvals", Summary: "Remove the approval policy of a stack or container"},
+
+	// Ignore rules, stack environments, and secrets
+	{Method: "GET", Path: "/api/ignore-rules", ID: "ListIgnoreRules", Tag: "Ignore Rules", Summary: "List image ignore rules and the containers they match"},
+	{Method: "POST", Path: "/api/ignore-rules", ID: "CreateIgnoreRule", Tag: "Ignore Rules", Summary: "Add an ignore rule", Request: freeForm, Response: storage.IgnoreRule{}},
+	{Method: "DELETE", Path: "/api/ignore-rules/{id}", ID: "DeleteIgnoreRule", Tag: "Ignore Rules", Summary: "Remove an ignore rule"},
+	{Method: "GET", Path: "/api/stack-env", ID: "ListStackEnv", Tag: "Discovery & Checking", Summary: "Environment settings of the stacks' compose commands"},
+	{Method: "PUT", Path: "/api/stack-env/{stack}", ID: "SetStackEnv", Tag: "Discovery & Checking", Summary: "Set a stack's env file and variables", Request: freeForm, Response: storage.StackEnv{}},
+	{Method: "DELETE", Path: "/api/stack-env/{stack}", ID: "DeleteStackEnv", Tag: "Discovery & Checking", Summary: "Remove a stack's environment settings"},
+	{Method: "GET", Path: "/api/secrets", ID: "ListSecrets", Tag: "Secrets", Summary: "Stored secrets and their references, without values"},
+	{Method: "PUT", Path: "/api/secrets/{name}", ID: "SetSecret", Tag: "Secrets", Summary: "Encrypt and store a secret", Request: freeForm, Response: secrets.Secret{}},
+	{Method: "DELETE", Path: "/api/secrets/{name}", ID: "DeleteSecret", Tag: "Secrets", Summary: "Remove a secret"},
+
+	// Configuration import/export
+	{Method: "GET", Path: "/api/config/export", ID: "ExportConfig", Tag: "Configuration", Summary: "Download the configuration as YAML", Raw: contentYAML},
+	{Method: "POST", Path: "/api/config/import", ID: "ImportConfig", Tag: "Configuration", Summary: "Import a YAML configuration", RequestType: contentYAML},
+	{Method: "GET", Path: "/api/config/history", ID: "ListConfigHistory", Tag: "Configuration", Summary: "Recorded configuration changes, newest first", Query: []string{"limit"}},
+	{Method: "POST", Path: "/api/config/history/{id}/revert", ID: "RevertConfig", Tag: "Configuration", Summary: "Restore the configuration recorded in a snapshot"},
+
+	// Database maintenance
+	{Method: "GET", Path: "/api/db/backup", ID: "BackupDB", Tag: "Database Maintenance", Summary: "Download a snapshot of the SQLite database", Raw: "application/vnd.sqlite3"},
+	{Method: "POST", Path: "/api/db/vacuum", ID: "VacuumDB", Tag: "Database Maintenance", Summary: "Rebuild the database to reclaim free space", Response: storage.VacuumResult{}},
+	{Method: "POST", Path: "/api/db/prune", ID: "PruneDB", Tag: "Database Maintenance", Summary: "Delete old check history and update log rows", Request: freeForm},
+	{Method: "GET", Path: "/api/db/reconcile", ID: "GetReconcileReport", Tag: "Database Maintenance", Summary: "Report of the last reconciliation of orphaned records", Response: update.ReconcileReport{}},
+	{Method: "POST", Path: "/api/db/reconcile", ID: "ReconcileDB", Tag: "Database Maintenance", Summary: "Reconcile orphaned operations, queue entries, and compose backup records now", Response: update.ReconcileReport{}},
+
+	// Scripts
+	{Method: "GET", Path: "/api/scripts", ID: "ListScripts", Tag: "Scripts", Summary: "List available scripts and built-in checks"},
+	{Method: "GET", Path: "/api/scripts/assigned", ID: "ListScriptAssignments", Tag: "Scripts", Summary: "List script assignments"},
+	{Method: "POST", Path: "/api/scripts/assign", ID: "AssignScript", Tag: "Scripts", Summary: "Assign script to container", Request: freeForm},
+	{Method: "DELETE", Path: "/api/scripts/assign/{container}", ID: "UnassignScript", Tag: "Scripts", Summary: "Remove assignment"},
+	{Method: "POST", Path: "/api/scripts/test", ID: "TestScript", Tag: "Scripts", Summary: "Run a pre-update check against a container now", Request: freeForm},
+	{Method: "GET", Path: "/api/scripts/{name}", ID: "GetScript", Tag: "Scripts", Summary: "Get a managed script and its revisions", Query: []string{"revision"}},
+	{Method: "PUT", Path: "/api/scripts/{name}", ID: "PutScript", Tag: "Scripts", Summary: "Create or edit a managed script", Request: freeForm},
+
+	// Labels
+	{Method: "GET", Path: "/api/labels/{container}", ID: "GetLabels", Tag: "Labels", Summary: "Get container labels"},
+	{Method: "POST", Path: "/api/labels/set", ID: "SetLabels", Tag: "Labels", Summary: "Set labels (restarts container)", Request: SetLabelsRequest{}, Response: LabelOperationResult{}},
+	{Method: "POST", Path: "/api/labels/remove", ID: "RemoveLabels", Tag: "Labels", Summary: "Remove labels (restarts container)", Request: RemoveLabelsRequest{}, Response: LabelOperationResult{}},
+	{Method: "POST", Path: "/api/labels/batch", ID: "BatchLabels", Tag: "Labels", Summary: "Set labels on several containers", Request: BatchLabelsRequest{}},
+	{Method: "POST", Path: "/api/labels/rollback", ID: "RollbackLabels", Tag: "Labels", Summary: "Restore the labels changed by an operation", Request: LabelRollbackRequest{}},
+
+	// Groups
+	{Method: "GET", Path: "/api/groups", ID: "ListGroups", Tag: "Groups", Summary: "List docksmith.group groups with update counts and schedules"},
+	{Method: "POST", Path: "/api/groups/check/{name}", ID: "CheckGroup", Tag: "Groups", Summary: "Re-check every container in a group"},
+	{Method: "POST", Path: "/api/groups/update/{name}", ID: "UpdateGroup", Tag: "Groups", Summary: "Update the group's containers that have an update available"},
+	{Method: "POST", Path: "/api/groups/ignore/{name}", ID: "IgnoreGroup", Tag: "Groups", Summary: "Set or clear docksmith.ignore on the group", Request: freeForm},
+	{Method: "PUT", Path: "/api/groups/schedule/{name}", ID: "SetGroupSchedule", Tag: "Groups", Summary: "Update the group automatically on a schedule", Request: GroupSchedule{}},
+	{Method: "DELETE", Path: "/api/groups/schedule/{name}", ID: "DeleteGroupSchedule", Tag: "Groups", Summary: "Remove the group's schedule"},
+	{Method: "GET", Path: "/api/prepull", ID: "ListPrepulled", Tag: "Groups", Summary: "List images pre-pulled for upcoming updates and the next pre-pull time"},
+	{Method: "POST", Path: "/api/prepull", ID: "Prepull", Tag: "Groups", Summary: "Pre-pull the update images of groups now", Request: freeForm},
+
+	// Registry
+	{Method: "GET", Path: "/api/registry/tags/{imageRef...}", ID: "ListTags", Tag: "Registry", Summary: "Get tags for image", Query: []string{"refresh"}},
+	{Method: "GET", Path: "/api/registry/repositories/{registry}", ID: "ListRepositories", Tag: "Registry", Summary: "List repositories of a registry", Query: []string{"refresh"}},
+	{Method: "GET", Path: "/api/registry/manifest/{imageRef...}", ID: "GetManifest", Tag: "Registry", Summary: "Get manifest details of a tag", Query: []string{"reference"}},
+
+	// Approvals
+	{Method: "GET", Path: "/api/approvals", ID: "ListApprovals", Tag: "Approvals", Summary: "List approvals", Query: []string{"status", "limit"}},
+	{Method: "GET", Path: "/api/approvals/{id}", ID: "GetApproval", Tag: "Approvals", Summary: "Get a single approval", Response: storage.Approval{}},
+	{Method: "POST", Path: "/api/approvals/{id}/approve", ID: "Approve", Tag: "Approvals", Summary: "Approve and start the update", Response: storage.Approval{}},
+	{Method: "POST", Path: "/api/approvals/{id}/reject", ID: "Reject", Tag: "Approvals", Summary: "Reject the update", Response: storage.Approval{}},
+	{Method: "POST", Path: "/api/approvals/{id}/webhook", ID: "ApprovalWebhook", Tag: "Approvals", Summary: "Signed approve/reject callback from an external system", Request: freeForm, Response: storage.Approval{}},
+
+	// Notifications
+	{Method: "GET", Path: "/api/notifications/templates", ID: "ListNotificationTemplates", Tag: "Notifications", Summary: "Message templates by channel, and the configured channels"},
+	{Method: "PUT", Path: "/api/notifications/templates/{channel}", ID: "SetNotificationTemplate", Tag: "Notifications", Summary: "Set a channel's template", Request: freeForm},
+	{Method: "DELETE", Path: "/api/notifications/templates/{channel}", ID: "DeleteNotificationTemplate", Tag: "Notifications", Summary: "Restore a channel's default messages"},
+	{Method: "POST", Path: "/api/notifications/preview", ID: "PreviewNotification", Tag: "Notifications", Summary: "Render a sample message", Request: freeForm},
+	{Method: "POST", Path: "/api/notifications/test", ID: "TestNotification", Tag: "Notifications", Summary: "Send a sample message", Request: freeForm},
+
+	// Incoming webhooks
+	{Method: "POST", Path: "/api/hooks/{token}", ID: "TriggerHook", Tag: "Incoming Webhooks", Summary: "Check or update the containers of a pushed image (authenticated by the hook token)", Request: freeForm},
+
+	// Compose change proposals
+	{Method: "GET", Path: "/api/proposals", ID: "ListProposals", Tag: "Proposals", Summary: "List compose change proposals", Query: []string{"status", "limit"}},
+	{Method: "GET", Path: "/api/proposals/{id}", ID: "GetProposal", Tag: "Proposals", Summary: "Get a single proposal", Response: storage.Proposal{}},
+	{Method: "GET", Path: "/api/proposals/{id}/patch", ID: "GetProposalPatch", Tag: "Proposals", Summary: "Download the proposal as a unified diff", Raw: "text/x-diff"},
+
+	// Updates
+	{Method: "POST", Path: "/api/update", ID: "Update", Tag: "Updates", Summary: "Update single container", Request: UpdateRequest{}, Response: UpdateResponse{}},
+	{Method: "POST", Path: "/api/update/batch", ID: "BatchUpdate", Tag: "Updates", Summary: "Batch update multiple containers", Request: BatchUpdateRequest{}, Response: BatchUpdateResponse{}},
+	{Method: "POST", Path: "/api/update/preview", ID: "PreviewUpdate", Tag: "Updates", Summary: "Compose file diffs of updates, without applying them", Request: UpdatePreviewRequest{}, Response: UpdatePreviewResponse{}},
+	{Method: "POST", Path: "/api/update/simulate", ID: "SimulateUpdate", Tag: "Updates", Summary: "Try an update on a temporary clone of a container", Request: UpdateRequest{}, Response: UpdateResponse{}},
+	{Method: "POST", Path: "/api/pin", ID: "Pin", Tag: "Updates", Summary: "Pin :latest containers to their recommended versioned tag", Request: freeForm},
+	{Method: "POST", Path: "/api/rollback", ID: "Rollback", Tag: "Updates", Summary: "Rollback to previous version", Request: RollbackRequest{}, Response: RollbackResponse{}},
+	{Method: "POST", Path: "/api/rollback/containers", ID: "RollbackContainers", Tag: "Updates", Summary: "Roll back some containers of an operation", Request: freeForm},
+	{Method: "POST", Path: "/api/fix-compose-mismatch/{name}", ID: "FixComposeMismatch", Tag: "Compose Mismatch", Summary: "Fix container where running image differs from compose file"},
+	{Method: "POST", Path: "/api/rebuild/{name}", ID: "Rebuild", Tag: "Compose Mismatch", Summary: "Rebuild a locally built service and recreate its container", Query: []string{"force"}},
+	{Method: "POST", Path: "/api/variant/{name}", ID: "SwitchVariant", Tag: "Updates", Summary: "Switch a container to another variant of its version, e.g. -alpine", Request: freeForm},
+
+	// Restart
+	{Method: "POST", Path: "/api/restart/start/{name}", ID: "StartRestart", Tag: "Restart", Summary: "Restart a container as an operation, with progress events", Query: []string{"force"}},
+	{Method: "POST", Path: "/api/restart/container/{name}", ID: "RestartWithDependents", Tag: "Restart", Summary: "Restart container and its dependents by name", Query: []string{"force"}, Response: RestartResponse{}},
+	{Method: "POST", Path: "/api/restart/stack/{name}", ID: "RestartStack", Tag: "Restart", Summary: "Restart entire stack", Query: []string{"force"}, Response: RestartResponse{}},
+	{Method: "POST", Path: "/api/restart/stack/start/{name}", ID: "StartStackRestart", Tag: "Restart", Summary: "Restart a stack as an operation, with progress events", Query: []string{"force"}},
+	{Method: "POST", Path: "/api/restart", ID: "Restart", Tag: "Restart", Summary: "Restart container (name in body)", Request: RestartContainerRequest{}, Response: RestartResponse{}},
+
+	// Events
+	{Method: "GET", Path: "/api/events", ID: "StreamEvents", Tag: "Events", Summary: "Event stream (SSE), or the events published after an event ID as JSON with since", Query: []string{"since", "last_event_id"}, Raw: contentEvents, NoClient: true},
+
+	// Explorer
+	{Method: "GET", Path: "/api/explorer", ID: "GetExplorer", Tag: "Explorer", Summary: "Get all Docker resources (containers, images, networks, volumes)", Response: ExplorerData{}},
+	{Method: "GET", Path: "/api/images", ID: "ListImages", Tag: "Explorer", Summary: "List all images"},
+	{Method: "GET", Path: "/api/networks", ID: "ListNetworks", Tag: "Explorer", Summary: "List all networks"},
+	{Method: "GET", Path: "/api/volumes", ID: "ListVolumes", Tag: "Explorer", Summary: "List all volumes"},
+	{Method: "DELETE", Path: "/api/images/{id}", ID: "RemoveImage", Tag: "Explorer", Summary: "Remove an image", Query: []string{"force"}},
+	{Method: "DELETE", Path: "/api/networks/{id}", ID: "RemoveNetwork", Tag: "Explorer", Summary: "Remove a network"},
+	{Method: "DELETE", Path: "/api/volumes/{name}", ID: "RemoveVolume", Tag: "Explorer", Summary: "Remove a volume", Query: []string{"force"}},
+
+	// Prune
+	{Method: "POST", Path: "/api/prune/containers", ID: "PruneContainers", Tag: "Prune", Summary: "Remove stopped containers"},
+	{Method: "POST", Path: "/api/prune/images", ID: "PruneImages", Tag: "Prune", Summary: "Remove unused images", Query: []string{"all"}},
+	{Method: "POST", Path: "/api/prune/networks", ID: "PruneNetworks", Tag: "Prune", Summary: "Remove unused networks"},
+	{Method: "POST", Path: "/api/prune/volumes", ID: "PruneVolumes", Tag: "Prune", Summary: "Remove unused volumes"},
+	{Method: "POST", Path: "/api/prune/system", ID: "PruneSystem", Tag: "Prune", Summary: "Remove all unused resources", Query: []string{"volumes"}},
+
+	// Container operations
+	{Method: "GET", Path: "/api/containers/{name}/logs", ID: "GetContainerLogs", Tag: "Container Operations", Summary: "Get container logs (streamed as text with follow=true)", Query: []string{"tail", "timestamps", "follow"}, Alternate: contentText},
+	{Method: "GET", Path: "/api/containers/{name}/inspect", ID: "InspectContainer", Tag: "Container Operations", Summary: "Inspect container details", Response: ContainerInspectResponse{}},
+	{Method: "GET", Path: "/api/containers/{name}/stats", ID: "GetContainerStats", Tag: "Container Operations", Summary: "Get container resource stats, as reported by Docker", Raw: contentJSON},
+	{Method: "GET", Path: "/api/containers/{name}/versions", ID: "GetContainerVersions", Tag: "Container Operations", Summary: "Version timeline of a container"},
+	{Method: "GET", Path: "/api/containers/usage", ID: "GetContainersUsage", Tag: "Container Operations", Summary: "CPU and memory usage of running containers"},
+	{Method: "POST", Path: "/api/containers/batch/start", ID: "BatchStart", Tag: "Container Operations", Summary: "Start several containers", Request: BatchContainerRequest{}},
+	{Method: "POST", Path: "/api/containers/batch/stop", ID: "BatchStop", Tag: "Container Operations", Summary: "Stop several containers", Request: BatchContainerRequest{}},
+	{Method: "POST", Path: "/api/containers/batch/restart", ID: "BatchRestart", Tag: "Container Operations", Summary: "Restart several containers", Request: BatchContainerRequest{}},
+	{Method: "POST", Path: "/api/containers/batch/remove", ID: "BatchRemove", Tag: "Container Operations", Summary: "Remove several containers", Request: BatchContainerRequest{}},
+	{Method: "POST", Path: "/api/containers/{name}/stop", ID: "StopContainer", Tag: "Container Operations", Summary: "Stop a container", Query: []string{"timeout"}},
+	{Method: "POST", Path: "/api/containers/{name}/start", ID: "StartContainer", Tag: "Container Operations", Summary: "Start a container"},
+	{Method: "POST", Path: "/api/containers/{name}/restart", ID: "RestartContainer", Tag: "Container Operations", Summary: "Restart a container", Query: []string{"timeout"}},
+	{Method: "DELETE", Path: "/api/containers/{name}", ID: "RemoveContainer", Tag: "Container Operations", Summary: "Remove a container", Query: []string{"force", "volumes"}},
+}
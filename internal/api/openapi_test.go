@@ -0,0 +1,105 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingMux records the patterns routes are registered with
+type recordingMux struct {
+	patterns []string
+}
+
+func (m *recordingMux) HandleFunc(pattern string, handler func(http.ResponseWriter, *http.Request)) {
+	m.patterns = append(m.patterns, pattern)
+}
+
+func (m *recordingMux) Handle(pattern string, handler http.Handler) {
+	m.patterns = append(m.patterns, pattern)
+}
+
+func TestOpenAPIOperationsMatchRoutes(t *testing.T) {
+	mux := &recordingMux{}
+	(&Server{}).registerRoutes(mux, "", nil)
+
+	var routes []string
+	for _, pattern := range mux.patterns {
+		if _, path, ok := strings.Cut(pattern, " "); ok && strings.HasPrefix(path, "/api/") {
+			routes = append(routes, pattern)
+		}
+	}
+
+	var described []string
+	ids := make(map[string]bool)
+	for _, op := range apiOperations {
+		described = append(described, op.Method+" "+op.Path)
+		assert.False(t, ids[op.ID], "duplicate operation ID %s", op.ID)
+		ids[op.ID] = true
+		assert.NotEmpty(t, op.Tag, op.ID)
+		assert.NotEmpty(t, op.Summary, op.ID)
+	}
+	assert.Equal(t, routes, described, "apiOperations must list the routes of registerRoutes in order")
+}
+
+func TestOpenAPIDocument(t *testing.T) {
+	w := httptest.NewRecorder()
+	(&Server{}).handleOpenAPI(w, httptest.NewRequest("GET", "/api/openapi.json", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+
+	var doc map[string]any
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &doc))
+	assert.Equal(t, "3.1.0", doc["openapi"])
+
+	paths := doc["paths"].(map[string]any)
+	operation := paths["/api/operations/{id}"].(map[string]any)["get"].(map[string]any)
+	assert.Equal(t, "GetOperation", operation["operationId"])
+	assert.Equal(t, "viewer", operation["x-docksmith-role"])
+	data := operation["responses"].(map[string]any)["200"].(map[string]any)["content"].(map[string]any)["application/json"].(map[string]any)["schema"].(map[string]any)["allOf"].([]any)[1]
+	assert.Equal(t, "#/components/schemas/UpdateOperation", data.(map[string]any)["properties"].(map[string]any)["data"].(map[string]any)["$ref"])
+
+	// Public operations need no credentials
+	health := paths["/api/health"].(map[string]any)["get"].(map[string]any)
+	assert.Equal(t, []any{}, health["security"])
+	assert.Nil(t, health["x-docksmith-role"])
+	assert.Equal(t, "admin", paths["/api/secrets"].(map[string]any)["get"].(map[string]any)["x-docksmith-role"])
+
+	// Rest wildcards are plain path parameters
+	assert.Contains(t, paths, "/api/registry/tags/{imageRef}")
+
+	// Every reference resolves
+	schemas := doc["components"].(map[string]any)["schemas"].(map[string]any)
+	var refs []string
+	collectRefs(doc, &refs)
+	require.NotEmpty(t, refs)
+	for _, ref := range refs {
+		if name, ok := strings.CutPrefix(ref, "#/components/schemas/"); ok {
+			assert.Contains(t, schemas, name, ref)
+		} else {
+			assert.Equal(t, "#/components/responses/Error", ref)
+		}
+	}
+}
+
+// collectRefs appends the $ref values in a decoded JSON document to refs
+func collectRefs(v any, refs *[]string) {
+	switch v := v.(type) {
+	case map[string]any:
+		for key, value := range v {
+			if ref, ok := value.(string); ok && key == "$ref" {
+				*refs = append(*refs, ref)
+			}
+			collectRefs(value, refs)
+		}
+	case []any:
+		for _, value := range v {
+			collectRefs(value, refs)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/chis/docksmith/internal/update"
+)
+
+// readOnlyAllowed are the non-GET requests still served in read-only mode: signing
+// in and out, update checks, which only refresh what the dashboard shows, and
+// notification and update previews, which change nothing.
+// Incoming webhooks are served too; update hooks are refused by their handler.
+var readOnlyAllowed = map[string]bool{
+	"/api/auth/login":    true,
+	"/api/auth/logout":   true,
+	"/api/trigger-check": true,
+
+	"/api/notifications/preview": true,
+	"/api/update/preview":        true,
+}
+
+// ReadOnlyMiddleware rejects every request to /api/ that could change containers,
+// labels, scripts, or settings with 403 Forbidden, so the dashboard can be shared
+// for visibility only (DOCKSMITH_READ_ONLY=true).
+func ReadOnlyMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if readOnlyAllows(r.Method, r.URL.Path) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		RespondError(w, http.StatusForbidden, update.ErrReadOnly)
+	})
+}
+
+// readOnlyAllows reports whether a request is served in read-only mode.
+func readOnlyAllows(method, path string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	}
+	if !strings.HasPrefix(path, "/api/") || readOnlyAllowed[path] {
+		return true
+	}
+	return strings.HasPrefix(path, "/api/groups/check/") || strings.HasPrefix(path, "/api/hooks/")
+}
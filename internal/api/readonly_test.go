@@ -0,0 +1,44 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestReadOnlyMiddleware(t *testing.T) {
+	handler := ReadOnlyMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	tests := []struct {
+		method, path string
+		want         int
+	}{
+		{http.MethodGet, "/api/status", http.StatusOK},
+		{http.MethodGet, "/api/operations/op-1/logs", http.StatusOK},
+		{http.MethodPost, "/api/auth/login", http.StatusOK},
+		{http.MethodPost, "/api/trigger-check", http.StatusOK},
+		{http.MethodPost, "/api/groups/check/media", http.StatusOK},
+		{http.MethodPost, "/api/hooks/dsh_token", http.StatusOK},
+		{http.MethodPost, "/api/notifications/preview", http.StatusOK},
+		{http.MethodPost, "/api/notifications/test", http.StatusForbidden},
+		{http.MethodPost, "/api/update", http.StatusForbidden},
+		{http.MethodPost, "/api/update/batch", http.StatusForbidden},
+		{http.MethodPost, "/api/update/preview", http.StatusOK},
+		{http.MethodPost, "/api/rollback", http.StatusForbidden},
+		{http.MethodPost, "/api/restart/container/web", http.StatusForbidden},
+		{http.MethodPost, "/api/labels/set", http.StatusForbidden},
+		{http.MethodPut, "/api/scripts/check.sh", http.StatusForbidden},
+		{http.MethodPost, "/api/scripts/test", http.StatusForbidden},
+		{http.MethodPut, "/api/settings/check_interval", http.StatusForbidden},
+		{http.MethodDelete, "/api/containers/web", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.path, nil))
+		if rec.Code != tt.want {
+			t.Errorf("%s %s = %d, want %d", tt.method, tt.path, rec.Code, tt.want)
+		}
+	}
+}
@@ -54,6 +54,8 @@ func RespondOrchestratorError(w http.ResponseWriter, err error) {
 	var notFoundErr *update.NotFoundError
 	var badReqErr *update.BadRequestError
 	switch {
+	case errors.Is(err, update.ErrReadOnly):
+		RespondError(w, http.StatusForbidden, err)
 	case errors.As(err, &notFoundErr):
 		RespondNotFound(w, err)
 	case errors.As(err, &badReqErr):
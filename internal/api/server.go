@@ -3,22 +3,33 @@ package api
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/fs"
 	"log"
 	"net/http"
 	"os"
-	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/chis/docksmith/internal/approval"
+	"github.com/chis/docksmith/internal/auth"
 	"github.com/chis/docksmith/internal/config"
 	"github.com/chis/docksmith/internal/docker"
+	"github.com/chis/docksmith/internal/eol"
 	"github.com/chis/docksmith/internal/events"
+	"github.com/chis/docksmith/internal/heartbeat"
+	"github.com/chis/docksmith/internal/hooks"
+	"github.com/chis/docksmith/internal/mqtt"
+	"github.com/chis/docksmith/internal/notify"
+	"github.com/chis/docksmith/internal/proposal"
 	"github.com/chis/docksmith/internal/registry"
 	"github.com/chis/docksmith/internal/scripts"
+	"github.com/chis/docksmith/internal/secrets"
 	"github.com/chis/docksmith/internal/storage"
 	"github.com/chis/docksmith/internal/update"
+	"google.golang.org/grpc"
 )
 
 // Server represents the HTTP API server
@@ -31,11 +42,31 @@ type Server struct {
 	scriptManager         *scripts.Manager
 	eventBus              *events.Bus
 	httpServer            *http.Server
+	grpcServer            *grpc.Server
+	grpcAddr              string
 	pathTranslator        *docker.PathTranslator
 	backgroundChecker     *update.BackgroundChecker
+	composeWatcher        *update.ComposeWatcher
+	containerEvents       *update.ContainerEventWatcher
 	checkInterval         time.Duration
+	reconcileInterval     time.Duration // How often orphaned operation records are reconciled, 0 = startup only
 	cacheTTL              time.Duration
 	rateLimiter           *PathRateLimiter
+	apiKeys               *auth.KeyStore
+	users                 *auth.UserService
+	ownership             *auth.OwnershipService
+	oidc                  *auth.OIDCProvider
+	approvals             *approval.Manager
+	hooks                 *hooks.Store
+	secrets               *secrets.Store
+	proposals             *proposal.Manager
+	notifier              *notify.Manager
+	mqtt                  *mqtt.Bridge
+	heartbeat             *heartbeat.Pinger
+	startedAt             time.Time
+	groupScheduler        *groupScheduler
+	authMode              auth.Mode
+	readOnly              bool
 }
 
 // Config holds configuration for the API server
@@ -45,6 +76,7 @@ type Config struct {
 	RegistryManager *registry.Manager
 	StorageService  storage.Storage
 	StaticDir       string // Directory containing static UI files (optional)
+	StaticFS        fs.FS  // UI files built into the binary, served when StaticDir does not exist (optional)
 }
 
 // NewServer creates a new API server with the given configuration
@@ -53,6 +85,10 @@ func NewServer(cfg Config) *Server {
 
 	discoveryOrchestrator := update.NewOrchestrator(cfg.DockerService, cfg.RegistryManager)
 	discoveryOrchestrator.SetEventBus(eventBus) // Enable check progress events
+	discoveryOrchestrator.SetArchFallback(update.ArchFallbackFromEnv())
+	discoveryOrchestrator.SetDifferentialCheck(update.DifferentialCheckFromEnv())
+	discoveryOrchestrator.SetCheckTimeout(update.CheckTimeoutFromEnv())
+	discoveryOrchestrator.SetEOLClient(eol.NewClientFromEnv())
 
 	// Parse cache TTL from environment variable
 	cacheTTL := 1 * time.Hour // Default to 1 hour
@@ -70,6 +106,27 @@ func NewServer(cfg Config) *Server {
 		discoveryOrchestrator.SetStorage(cfg.StorageService)
 	}
 
+	// Persist registry tag lists so they survive restarts and are revalidated with
+	// conditional requests. TAG_CACHE_TTL defaults to CACHE_TTL.
+	if cfg.StorageService != nil && cfg.RegistryManager != nil {
+		tagCacheTTL := cacheTTL
+		if ttlStr := os.Getenv("TAG_CACHE_TTL"); ttlStr != "" {
+			if parsed, err := time.ParseDuration(ttlStr); err == nil && parsed > 0 {
+				tagCacheTTL = parsed
+				log.Printf("Using TAG_CACHE_TTL: %v", tagCacheTTL)
+			} else {
+				log.Printf("Warning: Invalid TAG_CACHE_TTL '%s', using default %v", ttlStr, tagCacheTTL)
+			}
+		}
+		cfg.RegistryManager.SetTagCacheStore(cfg.StorageService, tagCacheTTL)
+
+		// Restore the last known Docker Hub quota so an exhausted budget survives restarts
+		cfg.RegistryManager.SetQuotaStore(context.Background(), cfg.StorageService)
+	}
+
+	// DOCKSMITH_READ_ONLY=true shares the dashboard without allowing any changes
+	readOnly := update.ReadOnlyFromEnv()
+
 	var updateOrchestrator *update.UpdateOrchestrator
 	if cfg.StorageService != nil {
 		updateOrchestrator = update.NewUpdateOrchestrator(
@@ -80,6 +137,22 @@ func NewServer(cfg Config) *Server {
 			cfg.RegistryManager,
 			cfg.DockerService.GetPathTranslator(),
 		)
+		updateOrchestrator.SetLevelDelay(update.LevelDelayFromEnv())
+		updateOrchestrator.SetMaxConcurrent(update.MaxConcurrentFromEnv())
+		updateOrchestrator.SetSignatureVerification(update.SignatureConfigFromEnv())
+		updateOrchestrator.SetLoadDeferral(update.LoadDeferralFromEnv())
+		updateOrchestrator.SetObservation(update.ObservationFromEnv())
+		updateOrchestrator.SetVolumeBackup(update.VolumeBackupFromEnv())
+		updateOrchestrator.SetDatabaseDumpDir(update.DatabaseDumpDirFromEnv())
+		updateOrchestrator.SetReadOnly(readOnly)
+		updateOrchestrator.SetLockTimeout(update.LockTimeoutFromEnv())
+	}
+
+	// Orphaned operations, queue entries, and compose backup records are
+	// reconciled at startup and every RECONCILE_INTERVAL
+	var reconcileInterval time.Duration
+	if updateOrchestrator != nil {
+		reconcileInterval = update.ReconcileIntervalFromEnv()
 	}
 
 	// Initialize script manager if storage is available
@@ -111,12 +184,17 @@ func NewServer(cfg Config) *Server {
 		}
 
 		scriptManager = scripts.NewManager(cfg.StorageService, appConfig)
+
+		// Settings of containers not managed by docker compose are stored in the database
+		if cfg.DockerService != nil {
+			cfg.DockerService.SetLabelOverrides(scriptManager.StoredLabels)
+		}
 	}
 
-	// Parse check interval from environment variable
+	// Parse check interval from environment variable, or the imported setting
 	checkInterval := 5 * time.Minute // Default to 5 minutes
-	if intervalStr := os.Getenv("CHECK_INTERVAL"); intervalStr != "" {
-		if parsed, err := time.ParseDuration(intervalStr); err == nil {
+	if intervalStr := storage.EnvOrConfig(context.Background(), cfg.StorageService, "CHECK_INTERVAL", update.CheckIntervalConfigKey); intervalStr != "" {
+		if parsed, err := time.ParseDuration(intervalStr); err == nil && parsed > 0 {
 			checkInterval = parsed
 			log.Printf("Using CHECK_INTERVAL: %v", checkInterval)
 		} else {
@@ -124,13 +202,122 @@ func NewServer(cfg Config) *Server {
 		}
 	}
 
+	// Random jitter added to each interval (default 10% of the interval, 0 disables)
+	checkJitter := checkInterval / 10
+	if jitterStr := storage.EnvOrConfig(context.Background(), cfg.StorageService, "CHECK_JITTER", update.CheckJitterConfigKey); jitterStr != "" {
+		if parsed, err := time.ParseDuration(jitterStr); err == nil && parsed >= 0 {
+			checkJitter = parsed
+			log.Printf("Using CHECK_JITTER: %v", checkJitter)
+		} else {
+			log.Printf("Warning: Invalid CHECK_JITTER '%s', using default %v", jitterStr, checkJitter)
+		}
+	}
+
 	// Create background checker using the discovery orchestrator
 	backgroundChecker := update.NewBackgroundChecker(discoveryOrchestrator, cfg.DockerService, eventBus, cfg.StorageService, checkInterval)
+	backgroundChecker.SetJitter(checkJitter)
+
+	// Re-check stacks when their compose files are edited outside Docksmith (COMPOSE_WATCH=false disables)
+	var composeWatcher *update.ComposeWatcher
+	watchCompose := true
+	if watchStr := os.Getenv("COMPOSE_WATCH"); watchStr != "" {
+		if parsed, err := strconv.ParseBool(watchStr); err == nil {
+			watchCompose = parsed
+			log.Printf("Using COMPOSE_WATCH: %v", watchCompose)
+		} else {
+			log.Printf("Warning: Invalid COMPOSE_WATCH '%s', using default %v", watchStr, watchCompose)
+		}
+	}
+	if watchCompose {
+		composeWatcher = update.NewComposeWatcher(cfg.DockerService, discoveryOrchestrator, backgroundChecker, eventBus, cfg.DockerService.GetPathTranslator())
+	}
+
+	// Follow Docker container events to refresh state between checks (CONTAINER_EVENTS=false disables)
+	var containerEvents *update.ContainerEventWatcher
+	watchEvents := true
+	if eventsStr := os.Getenv("CONTAINER_EVENTS"); eventsStr != "" {
+		if parsed, err := strconv.ParseBool(eventsStr); err == nil {
+			watchEvents = parsed
+			log.Printf("Using CONTAINER_EVENTS: %v", watchEvents)
+		} else {
+			log.Printf("Warning: Invalid CONTAINER_EVENTS '%s', using default %v", eventsStr, watchEvents)
+		}
+	}
+	if watchEvents && cfg.DockerService.GetClient() != nil {
+		containerEvents = update.NewContainerEventWatcher(cfg.DockerService.GetClient(), discoveryOrchestrator, backgroundChecker, updateOrchestrator, eventBus)
+	}
+
+	// Update approval workflow and propose-only mode (both require storage).
+	// In propose-only mode updates become compose change proposals and are never applied.
+	var approvals *approval.Manager
+	var proposals *proposal.Manager
+	if updateOrchestrator != nil {
+		approvals = approval.NewManager(cfg.StorageService, updateOrchestrator, eventBus)
+		proposals = proposal.NewManager(cfg.StorageService, updateOrchestrator, eventBus)
+		backgroundChecker.AddResultHandler(func(ctx context.Context, result *update.DiscoveryResult) {
+			if !proposals.Enabled(ctx) {
+				approvals.Sync(ctx, result)
+			}
+		})
+		backgroundChecker.AddResultHandler(proposals.Sync)
+	}
+
+	// Encrypted secrets that settings refer to (SECRETS_KEY or SECRETS_KEY_FILE)
+	secretStore := secrets.NewStoreFromEnv(cfg.StorageService)
+	if secretStore.Enabled() {
+		log.Println("Secrets store enabled")
+	}
+
+	// Update notifications (NOTIFY_WEBHOOK_URL, NOTIFY_SLACK_WEBHOOK_URL, Gotify, ntfy, Pushover)
+	notifier, err := notify.NewManagerFromEnv(cfg.StorageService, secretStore)
+	if err != nil {
+		log.Printf("Warning: Update notifications disabled: %v", err)
+	} else if notifier != nil {
+		notifier.SetEventBus(eventBus)
+		backgroundChecker.AddResultHandler(notifier.Sync)
+		log.Printf("Update notifications enabled (mode: %s)", notifier.Mode())
+	}
+
+	// Dead man's switch pings (HEALTHCHECK_PING_URL, HEALTHCHECK_UPDATE_PING_URL)
+	pinger, err := heartbeat.NewPingerFromEnv()
+	if err != nil {
+		log.Printf("Warning: Healthcheck pings disabled: %v", err)
+	} else if pinger != nil {
+		backgroundChecker.AddResultHandler(pinger.Sync)
+		log.Printf("Healthcheck pings enabled")
+	}
 
 	// Rate limiting disabled — this is a self-hosted app, not a public API.
 	// The internal rate limiter was blocking normal usage with many containers.
 	var rateLimiter *PathRateLimiter
 
+	// API key and user authentication (DOCKSMITH_AUTH=required rejects anonymous requests)
+	authMode := auth.ModeFromEnv()
+	apiKeys := auth.NewKeyStore(cfg.StorageService)
+	var users *auth.UserService
+	var ownership *auth.OwnershipService
+	if cfg.StorageService != nil {
+		users = auth.NewUserService(cfg.StorageService)
+		ownership = auth.NewOwnershipService(cfg.StorageService)
+	}
+	if authMode == auth.ModeRequired && cfg.StorageService == nil {
+		log.Println("Warning: DOCKSMITH_AUTH=required but storage is unavailable; all API requests will be rejected")
+	}
+	log.Printf("API authentication mode: %s", authMode)
+
+	// Optional OIDC single sign-on (OIDC_ISSUER + OIDC_CLIENT_ID)
+	var oidcProvider *auth.OIDCProvider
+	if oidcCfg, ok := auth.OIDCConfigFromEnv(); ok {
+		if users == nil {
+			log.Println("Warning: OIDC is configured but storage is unavailable; SSO login disabled")
+		} else if err := oidcCfg.Validate(); err != nil {
+			log.Printf("Warning: %v; SSO login disabled", err)
+		} else {
+			oidcProvider = auth.NewOIDCProvider(oidcCfg)
+			log.Printf("OIDC login enabled (issuer: %s)", oidcCfg.IssuerURL)
+		}
+	}
+
 	s := &Server{
 		dockerService:         cfg.DockerService,
 		registryManager:       cfg.RegistryManager,
@@ -141,19 +328,54 @@ func NewServer(cfg Config) *Server {
 		eventBus:              eventBus,
 		pathTranslator:        cfg.DockerService.GetPathTranslator(),
 		backgroundChecker:     backgroundChecker,
+		composeWatcher:        composeWatcher,
+		containerEvents:       containerEvents,
 		checkInterval:         checkInterval,
+		reconcileInterval:     reconcileInterval,
 		cacheTTL:              cacheTTL,
 		rateLimiter:           rateLimiter,
+		apiKeys:               apiKeys,
+		users:                 users,
+		ownership:             ownership,
+		oidc:                  oidcProvider,
+		approvals:             approvals,
+		hooks:                 hooks.NewStore(cfg.StorageService),
+		secrets:               secretStore,
+		proposals:             proposals,
+		notifier:              notifier,
+		heartbeat:             pinger,
+		authMode:              authMode,
+		readOnly:              readOnly,
+		startedAt:             time.Now(),
+	}
+	s.groupScheduler = newGroupScheduler(cfg.StorageService, s.runScheduledGroupUpdate)
+	if schedule, ok := prepullScheduleFromEnv(); ok && updateOrchestrator != nil {
+		s.groupScheduler.SetPrepull(schedule, s.runScheduledPrepull)
+	}
+
+	// Home Assistant integration over MQTT (MQTT_BROKER)
+	bridge, err := mqtt.NewBridgeFromEnv(s.mqttUpdate)
+	if err != nil {
+		log.Printf("Warning: MQTT integration disabled: %v", err)
+	} else if bridge != nil {
+		s.mqtt = bridge
+		backgroundChecker.AddResultHandler(bridge.Sync)
+		log.Printf("MQTT integration enabled (broker: %s)", bridge.Broker())
 	}
 
 	// Setup HTTP server with middleware chain
 	mux := http.NewServeMux()
-	s.registerRoutes(mux, cfg.StaticDir)
+	s.registerRoutes(mux, cfg.StaticDir, cfg.StaticFS)
 
-	// Apply middleware: CORS -> Correlation ID -> Rate Limit (optional) -> Request Logging -> Handler
+	// Apply middleware: CORS -> Correlation ID -> Auth -> Trigger -> Read-only (optional) -> Rate Limit (optional) -> Request Logging -> Handler
 	middlewares := []func(http.Handler) http.Handler{
 		corsMiddleware,
 		CorrelationIDMiddleware,
+		AuthMiddleware(apiKeys, users, authMode),
+		TriggerMiddleware,
+	}
+	if readOnly {
+		middlewares = append(middlewares, ReadOnlyMiddleware)
 	}
 	if rateLimiter != nil {
 		middlewares = append(middlewares, PathRateLimitMiddleware(rateLimiter))
@@ -170,13 +392,48 @@ func NewServer(cfg Config) *Server {
 		IdleTimeout:  60 * time.Second,
 	}
 
+	// gRPC API alongside HTTP (GRPC_PORT)
+	if port := grpcPortFromEnv(); port > 0 {
+		s.grpcServer = s.newGRPCServer()
+		s.grpcAddr = fmt.Sprintf(":%d", port)
+	}
+
 	return s
 }
 
-// registerRoutes sets up all API routes
-func (s *Server) registerRoutes(mux *http.ServeMux, staticDir string) {
+// routeMux is the part of http.ServeMux that routes are registered with
+type routeMux interface {
+	HandleFunc(pattern string, handler func(http.ResponseWriter, *http.Request))
+	Handle(pattern string, handler http.Handler)
+}
+
+// registerRoutes sets up all API routes. Routes under /api/ are described in
+// apiOperations too.
+func (s *Server) registerRoutes(mux routeMux, staticDir string, staticFS fs.FS) {
 	// Health check
 	mux.HandleFunc("GET /api/health", s.handleHealth)
+	mux.HandleFunc("GET /api/ready", s.handleReady)
+	mux.HandleFunc("GET /api/openapi.json", s.handleOpenAPI)
+
+	// Authentication
+	mux.HandleFunc("POST /api/auth/login", s.handleLogin)
+	mux.HandleFunc("POST /api/auth/logout", s.handleLogout)
+	mux.HandleFunc("GET /api/auth/me", s.handleAuthMe)
+	mux.HandleFunc("GET /api/auth/oidc/login", s.handleOIDCLogin)
+	mux.HandleFunc("GET /api/auth/oidc/callback", s.handleOIDCCallback)
+
+	// User management (admin only)
+	mux.HandleFunc("GET /api/users", s.handleUsersList)
+	mux.HandleFunc("POST /api/users", s.handleUsersCreate)
+	mux.HandleFunc("PUT /api/users/{username}", s.handleUsersUpdate)
+	mux.HandleFunc("DELETE /api/users/{username}", s.handleUsersDelete)
+
+	// Stack and container ownership (admin only)
+	mux.HandleFunc("GET /api/ownership", s.handleOwnershipList)
+	mux.HandleFunc("PUT /api/ownership/{type}/{name}/{owner}", s.handleOwnerAdd)
+	mux.HandleFunc("DELETE /api/ownership/{type}/{name}/{owner}", s.handleOwnerRemove)
+	mux.HandleFunc("PUT /api/teams/{team}/members/{username}", s.handleTeamMemberAdd)
+	mux.HandleFunc("DELETE /api/teams/{team}/members/{username}", s.handleTeamMemberRemove)
 
 	// Docker configuration
 	mux.HandleFunc("GET /api/docker-config", s.handleDockerConfig)
@@ -185,52 +442,144 @@ func (s *Server) registerRoutes(mux *http.ServeMux, staticDir string) {
 	mux.HandleFunc("GET /api/check", s.handleCheck)
 	mux.HandleFunc("GET /api/status", s.handleGetStatus)
 	mux.HandleFunc("POST /api/trigger-check", s.handleTriggerCheck)
-	mux.HandleFunc("GET /api/container/{name}/recheck", s.handleContainerRecheck)
+	mux.HandleFunc("GET /api/container/{name}/recheck", s.ownedContainer("name", s.handleContainerRecheck))
+	mux.HandleFunc("GET /api/stacks", s.handleStacks)
+	mux.HandleFunc("GET /api/locks", s.handleLocks)
+	mux.HandleFunc("POST /api/locks/{stack}/release", s.ownedStack("stack", s.handleLockRelease))
+	mux.HandleFunc("GET /api/queue", s.handleQueue)
+	mux.HandleFunc("POST /api/queue/reorder", s.handleQueueReorder)
+	mux.HandleFunc("POST /api/queue/{id}/priority", s.ownedOperation(s.handleQueuePriority))
+	mux.HandleFunc("DELETE /api/queue/{id}", s.ownedOperation(s.handleQueueRemove))
+	mux.HandleFunc("GET /api/graph", s.handleGraph)
+
+	// Background checker schedule
+	mux.HandleFunc("GET /api/checker", s.handleCheckerStatus)
+	mux.HandleFunc("POST /api/checker/pause", s.audited(s.handleCheckerPause))
+	mux.HandleFunc("POST /api/checker/resume", s.audited(s.handleCheckerResume))
 
 	// Operations history
 	mux.HandleFunc("GET /api/operations", s.handleOperations)
-	mux.HandleFunc("GET /api/operations/{id}", s.handleOperationByID)
+	mux.HandleFunc("GET /api/operations/{id}", s.ownedOperation(s.handleOperationByID))
+	mux.HandleFunc("POST /api/operations/{id}/pause", s.ownedOperation(s.handlePauseOperation))
+	mux.HandleFunc("POST /api/operations/{id}/resume", s.ownedOperation(s.handleResumeOperation))
+	mux.HandleFunc("GET /api/operations/{id}/volume-snapshots", s.ownedOperation(s.handleVolumeSnapshots))
+	mux.HandleFunc("GET /api/operations/{id}/logs", s.ownedOperation(s.handleOperationLogs))
+	mux.HandleFunc("POST /api/operations/{id}/restore-volumes", s.ownedOperation(s.unlessProposeOnly(s.handleRestoreVolumes)))
 	mux.HandleFunc("GET /api/operations/group/{groupId}", s.handleOperationsByGroup)
 
 	// Settings
 	mux.HandleFunc("GET /api/settings/{key}", s.handleGetSetting)
-	mux.HandleFunc("PUT /api/settings/{key}", s.handleSetSetting)
+	mux.HandleFunc("PUT /api/settings/{key}", s.audited(s.handleSetSetting))
 
 	// Check and update history
 	mux.HandleFunc("GET /api/history", s.handleHistory)
+	mux.HandleFunc("GET /api/history/timeline", s.handleHistoryTimeline)
+	mux.HandleFunc("GET /api/history/export", s.handleHistoryExport)
 	mux.HandleFunc("DELETE /api/history/clear", s.handleClearHistory)
 
-	// Rollback policies
+	// Rollback and approval policies
 	mux.HandleFunc("GET /api/policies", s.handlePolicies)
+	mux.HandleFunc("PUT /api/policies/approval/{scope}", s.audited(s.handleApprovalPolicySet))
+	mux.HandleFunc("PUT /api/policies/approval/{scope}/{name}", s.audited(s.handleApprovalPolicySet))
+	mux.HandleFunc("DELETE /api/policies/approval/{scope}", s.audited(s.handleApprovalPolicyDelete))
+	mux.HandleFunc("DELETE /api/policies/approval/{scope}/{name}", s.audited(s.handleApprovalPolicyDelete))
+
+	// Image ignore rules
+	mux.HandleFunc("GET /api/ignore-rules", s.handleIgnoreRulesList)
+	mux.HandleFunc("POST /api/ignore-rules", s.audited(s.handleIgnoreRuleCreate))
+	mux.HandleFunc("DELETE /api/ignore-rules/{id}", s.audited(s.handleIgnoreRuleDelete))
+	mux.HandleFunc("GET /api/stack-env", s.handleStackEnvList)
+	mux.HandleFunc("PUT /api/stack-env/{stack}", s.handleStackEnvSet)
+	mux.HandleFunc("DELETE /api/stack-env/{stack}", s.handleStackEnvDelete)
+	mux.HandleFunc("GET /api/secrets", s.handleSecretsList)
+	mux.HandleFunc("PUT /api/secrets/{name}", s.handleSecretSet)
+	mux.HandleFunc("DELETE /api/secrets/{name}", s.handleSecretDelete)
+
+	// Configuration export/import and change history
+	mux.HandleFunc("GET /api/config/export", s.handleConfigExport)
+	mux.HandleFunc("POST /api/config/import", s.audited(s.handleConfigImport))
+	mux.HandleFunc("GET /api/config/history", s.handleConfigHistory)
+	mux.HandleFunc("POST /api/config/history/{id}/revert", s.handleConfigRevert)
+
+	// Database maintenance
+	mux.HandleFunc("GET /api/db/backup", s.handleDBBackup)
+	mux.HandleFunc("POST /api/db/vacuum", s.handleDBVacuum)
+	mux.HandleFunc("POST /api/db/prune", s.handleDBPrune)
+	mux.HandleFunc("GET /api/db/reconcile", s.handleDBReconcileReport)
+	mux.HandleFunc("POST /api/db/reconcile", s.handleDBReconcile)
 
 	// Script management
 	mux.HandleFunc("GET /api/scripts", s.handleScriptsList)
 	mux.HandleFunc("GET /api/scripts/assigned", s.handleScriptsAssigned)
-	mux.HandleFunc("POST /api/scripts/assign", s.handleScriptsAssign)
-	mux.HandleFunc("DELETE /api/scripts/assign/{container}", s.handleScriptsUnassign)
+	mux.HandleFunc("POST /api/scripts/assign", s.audited(s.handleScriptsAssign))
+	mux.HandleFunc("DELETE /api/scripts/assign/{container}", s.ownedContainer("container", s.audited(s.handleScriptsUnassign)))
+	mux.HandleFunc("POST /api/scripts/test", s.handleScriptsTest)
+	mux.HandleFunc("GET /api/scripts/{name}", s.handleScriptGet)
+	mux.HandleFunc("PUT /api/scripts/{name}", s.handleScriptPut)
 
 	// Label management (atomic: compose + restart)
-	mux.HandleFunc("GET /api/labels/{container}", s.handleLabelsGet)
-	mux.HandleFunc("POST /api/labels/set", s.handleLabelsSet)
-	mux.HandleFunc("POST /api/labels/remove", s.handleLabelsRemove)
-	mux.HandleFunc("POST /api/labels/batch", s.handleBatchLabels)
-	mux.HandleFunc("POST /api/labels/rollback", s.handleLabelRollback)
+	mux.HandleFunc("GET /api/labels/{container}", s.ownedContainer("container", s.handleLabelsGet))
+	mux.HandleFunc("POST /api/labels/set", s.unlessProposeOnly(s.handleLabelsSet))
+	mux.HandleFunc("POST /api/labels/remove", s.unlessProposeOnly(s.handleLabelsRemove))
+	mux.HandleFunc("POST /api/labels/batch", s.unlessProposeOnly(s.handleBatchLabels))
+	mux.HandleFunc("POST /api/labels/rollback", s.unlessProposeOnly(s.handleLabelRollback))
+
+	// Container groups (docksmith.group label)
+	mux.HandleFunc("GET /api/groups", s.handleGroupsList)
+	mux.HandleFunc("POST /api/groups/check/{name}", s.handleGroupCheck)
+	mux.HandleFunc("POST /api/groups/update/{name}", s.unlessProposeOnly(s.handleGroupUpdate))
+	mux.HandleFunc("POST /api/groups/ignore/{name}", s.unlessProposeOnly(s.handleGroupIgnore))
+	mux.HandleFunc("PUT /api/groups/schedule/{name}", s.audited(s.handleGroupScheduleSet))
+	mux.HandleFunc("DELETE /api/groups/schedule/{name}", s.audited(s.handleGroupScheduleDelete))
+	mux.HandleFunc("GET /api/prepull", s.handlePrepullList)
+	mux.HandleFunc("POST /api/prepull", s.unlessProposeOnly(s.handlePrepull))
 
 	// Registry tags (for regex testing UI)
 	mux.HandleFunc("GET /api/registry/tags/{imageRef...}", s.handleRegistryTags)
 
+	// Registry browsing (for the change image/tag picker)
+	mux.HandleFunc("GET /api/registry/repositories/{registry}", s.handleRegistryRepositories)
+	mux.HandleFunc("GET /api/registry/manifest/{imageRef...}", s.handleRegistryManifest)
+
+	// Update approvals
+	mux.HandleFunc("GET /api/approvals", s.handleApprovalsList)
+	mux.HandleFunc("GET /api/approvals/{id}", s.ownedApproval(s.handleApprovalGet))
+	mux.HandleFunc("POST /api/approvals/{id}/approve", s.ownedApproval(s.unlessProposeOnly(s.handleApprovalApprove)))
+	mux.HandleFunc("POST /api/approvals/{id}/reject", s.ownedApproval(s.handleApprovalReject))
+	mux.HandleFunc("POST /api/approvals/{id}/webhook", s.unlessProposeOnly(s.handleApprovalWebhook))
+
+	// Notification message templates
+	mux.HandleFunc("GET /api/notifications/templates", s.handleNotificationTemplates)
+	mux.HandleFunc("PUT /api/notifications/templates/{channel}", s.audited(s.handleNotificationTemplateSet))
+	mux.HandleFunc("DELETE /api/notifications/templates/{channel}", s.audited(s.handleNotificationTemplateDelete))
+	mux.HandleFunc("POST /api/notifications/preview", s.handleNotificationPreview)
+	mux.HandleFunc("POST /api/notifications/test", s.handleNotificationTest)
+
+	// Incoming webhooks (tokens managed with `docksmith hook`)
+	mux.HandleFunc("POST /api/hooks/{token}", s.handleHookTrigger)
+
+	// Compose change proposals (propose-only mode)
+	mux.HandleFunc("GET /api/proposals", s.handleProposalsList)
+	mux.HandleFunc("GET /api/proposals/{id}", s.ownedProposal(s.handleProposalGet))
+	mux.HandleFunc("GET /api/proposals/{id}/patch", s.ownedProposal(s.handleProposalPatch))
+
 	// Mutations (POST/PUT/DELETE)
-	mux.HandleFunc("POST /api/update", s.handleUpdate)
-	mux.HandleFunc("POST /api/update/batch", s.handleBatchUpdate)
-	mux.HandleFunc("POST /api/rollback", s.handleRollback)
-	mux.HandleFunc("POST /api/rollback/containers", s.handleRollbackContainers)
-	mux.HandleFunc("POST /api/fix-compose-mismatch/{name}", s.handleFixComposeMismatch)
+	mux.HandleFunc("POST /api/update", s.unlessProposeOnly(s.handleUpdate))
+	mux.HandleFunc("POST /api/update/batch", s.unlessProposeOnly(s.handleBatchUpdate))
+	mux.HandleFunc("POST /api/update/preview", s.handleUpdatePreview)
+	mux.HandleFunc("POST /api/update/simulate", s.handleUpdateSimulate)
+	mux.HandleFunc("POST /api/pin", s.unlessProposeOnly(s.handlePin))
+	mux.HandleFunc("POST /api/rollback", s.unlessProposeOnly(s.handleRollback))
+	mux.HandleFunc("POST /api/rollback/containers", s.unlessProposeOnly(s.handleRollbackContainers))
+	mux.HandleFunc("POST /api/fix-compose-mismatch/{name}", s.ownedContainer("name", s.unlessProposeOnly(s.handleFixComposeMismatch)))
+	mux.HandleFunc("POST /api/rebuild/{name}", s.ownedContainer("name", s.unlessProposeOnly(s.handleRebuild)))
+	mux.HandleFunc("POST /api/variant/{name}", s.ownedContainer("name", s.unlessProposeOnly(s.handleSwitchVariant)))
 
 	// Restart operations
-	mux.HandleFunc("POST /api/restart/start/{name}", s.handleStartRestart) // New SSE-based restart
-	mux.HandleFunc("POST /api/restart/container/{name}", s.handleRestartContainer)
-	mux.HandleFunc("POST /api/restart/stack/{name}", s.handleRestartStack)
-	mux.HandleFunc("POST /api/restart/stack/start/{name}", s.handleStartStackRestart) // Stack restart via orchestrator
+	mux.HandleFunc("POST /api/restart/start/{name}", s.ownedContainer("name", s.handleStartRestart)) // New SSE-based restart
+	mux.HandleFunc("POST /api/restart/container/{name}", s.ownedContainer("name", s.handleRestartContainer))
+	mux.HandleFunc("POST /api/restart/stack/{name}", s.ownedStack("name", s.handleRestartStack))
+	mux.HandleFunc("POST /api/restart/stack/start/{name}", s.ownedStack("name", s.handleStartStackRestart)) // Stack restart via orchestrator
 	mux.HandleFunc("POST /api/restart", s.handleRestartContainerBody)
 
 	// Server-Sent Events for real-time updates
@@ -241,38 +590,35 @@ func (s *Server) registerRoutes(mux *http.ServeMux, staticDir string) {
 	mux.HandleFunc("GET /api/images", s.handleImages)
 	mux.HandleFunc("GET /api/networks", s.handleNetworks)
 	mux.HandleFunc("GET /api/volumes", s.handleVolumes)
-	mux.HandleFunc("DELETE /api/images/{id}", s.handleRemoveImage)
-	mux.HandleFunc("DELETE /api/networks/{id}", s.handleRemoveNetwork)
-	mux.HandleFunc("DELETE /api/volumes/{name}", s.handleRemoveVolume)
+	mux.HandleFunc("DELETE /api/images/{id}", s.unlessScoped(s.handleRemoveImage))
+	mux.HandleFunc("DELETE /api/networks/{id}", s.unlessScoped(s.handleRemoveNetwork))
+	mux.HandleFunc("DELETE /api/volumes/{name}", s.unlessScoped(s.handleRemoveVolume))
 
 	// Prune endpoints
-	mux.HandleFunc("POST /api/prune/containers", s.handlePruneContainers)
-	mux.HandleFunc("POST /api/prune/images", s.handlePruneImages)
-	mux.HandleFunc("POST /api/prune/networks", s.handlePruneNetworks)
-	mux.HandleFunc("POST /api/prune/volumes", s.handlePruneVolumes)
-	mux.HandleFunc("POST /api/prune/system", s.handleSystemPrune)
+	mux.HandleFunc("POST /api/prune/containers", s.unlessScoped(s.handlePruneContainers))
+	mux.HandleFunc("POST /api/prune/images", s.unlessScoped(s.handlePruneImages))
+	mux.HandleFunc("POST /api/prune/networks", s.unlessScoped(s.handlePruneNetworks))
+	mux.HandleFunc("POST /api/prune/volumes", s.unlessScoped(s.handlePruneVolumes))
+	mux.HandleFunc("POST /api/prune/system", s.unlessScoped(s.handleSystemPrune))
 
 	// Container operations
-	mux.HandleFunc("GET /api/containers/{name}/logs", s.handleContainerLogs)
-	mux.HandleFunc("GET /api/containers/{name}/inspect", s.handleContainerInspect)
-	mux.HandleFunc("GET /api/containers/{name}/stats", s.handleContainerStats)
+	mux.HandleFunc("GET /api/containers/{name}/logs", s.ownedContainer("name", s.handleContainerLogs))
+	mux.HandleFunc("GET /api/containers/{name}/inspect", s.ownedContainer("name", s.handleContainerInspect))
+	mux.HandleFunc("GET /api/containers/{name}/stats", s.ownedContainer("name", s.handleContainerStats))
+	mux.HandleFunc("GET /api/containers/{name}/versions", s.ownedContainer("name", s.handleContainerVersions))
+	mux.HandleFunc("GET /api/containers/usage", s.handleContainersUsage)
 	mux.HandleFunc("POST /api/containers/batch/start", s.handleBatchStart)
 	mux.HandleFunc("POST /api/containers/batch/stop", s.handleBatchStop)
 	mux.HandleFunc("POST /api/containers/batch/restart", s.handleBatchRestart)
 	mux.HandleFunc("POST /api/containers/batch/remove", s.handleBatchRemove)
-	mux.HandleFunc("POST /api/containers/{name}/stop", s.handleContainerStop)
-	mux.HandleFunc("POST /api/containers/{name}/start", s.handleContainerStart)
-	mux.HandleFunc("POST /api/containers/{name}/restart", s.handleContainerRestart)
-	mux.HandleFunc("DELETE /api/containers/{name}", s.handleContainerRemove)
-
-	// Serve static UI files if directory is configured
-	if staticDir != "" {
-		if _, err := os.Stat(staticDir); err == nil {
-			log.Printf("Serving static UI from %s", staticDir)
-			mux.Handle("/", spaHandler(staticDir))
-		} else {
-			log.Printf("Static directory %s not found, UI will not be served", staticDir)
-		}
+	mux.HandleFunc("POST /api/containers/{name}/stop", s.ownedContainer("name", s.handleContainerStop))
+	mux.HandleFunc("POST /api/containers/{name}/start", s.ownedContainer("name", s.handleContainerStart))
+	mux.HandleFunc("POST /api/containers/{name}/restart", s.ownedContainer("name", s.handleContainerRestart))
+	mux.HandleFunc("DELETE /api/containers/{name}", s.ownedContainer("name", s.handleContainerRemove))
+
+	// Serve the web UI from the static directory or the binary
+	if files := uiFiles(staticDir, staticFS); files != nil {
+		mux.Handle("/", spaHandler(files))
 	}
 }
 
@@ -283,6 +629,44 @@ func (s *Server) Start() error {
 		s.backgroundChecker.Start()
 	}
 
+	// Reconcile the operation records left by the previous run
+	if s.updateOrchestrator != nil {
+		s.updateOrchestrator.StartReconciler(s.reconcileInterval)
+	}
+
+	// Start watching compose files
+	if s.composeWatcher != nil {
+		if err := s.composeWatcher.Start(); err != nil {
+			log.Printf("Warning: Compose file watching disabled: %v", err)
+		}
+	}
+
+	// Follow container events
+	if s.containerEvents != nil {
+		s.containerEvents.Start()
+	}
+
+	// Start digest notification scheduler and failed update notifications
+	if s.notifier != nil {
+		s.notifier.Start()
+	}
+
+	// Connect to the MQTT broker
+	if s.mqtt != nil {
+		s.mqtt.Start(s.eventBus)
+	}
+
+	// Start scheduled group updates
+	if s.groupScheduler != nil {
+		s.groupScheduler.Start()
+	}
+
+	if s.grpcServer != nil {
+		if err := s.startGRPC(); err != nil {
+			return err
+		}
+	}
+
 	log.Printf("Starting API server on %s", s.httpServer.Addr)
 	return s.httpServer.ListenAndServe()
 }
@@ -296,11 +680,35 @@ func (s *Server) Shutdown(ctx context.Context) error {
 		s.backgroundChecker.Stop()
 	}
 
+	if s.composeWatcher != nil {
+		s.composeWatcher.Stop()
+	}
+
+	if s.containerEvents != nil {
+		s.containerEvents.Stop()
+	}
+
+	if s.notifier != nil {
+		s.notifier.Stop()
+	}
+
+	if s.mqtt != nil {
+		s.mqtt.Stop()
+	}
+
+	if s.groupScheduler != nil {
+		s.groupScheduler.Stop()
+	}
+
 	// Stop rate limiter cleanup goroutines
 	if s.rateLimiter != nil {
 		s.rateLimiter.Stop()
 	}
 
+	if s.grpcServer != nil {
+		s.stopGRPC(ctx)
+	}
+
 	return s.httpServer.Shutdown(ctx)
 }
 
@@ -319,7 +727,7 @@ func corsMiddleware(next http.Handler) http.Handler {
 		}
 
 		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-API-Key")
 		w.Header().Set("Access-Control-Max-Age", "86400")
 
 		// Handle preflight
@@ -339,109 +747,87 @@ func setNoCacheHeaders(w http.ResponseWriter) {
 	w.Header().Set("Expires", "0")
 }
 
-// serveIndexHTML serves index.html without Last-Modified/ETag headers.
-// http.ServeFile adds Last-Modified which can cause browsers to cache
-// via conditional requests (If-Modified-Since → 304), defeating no-store.
-// By reading and writing bytes directly, we ensure no conditional caching.
-func serveIndexHTML(w http.ResponseWriter, indexPath string) {
-	data, err := os.ReadFile(indexPath)
-	if err != nil {
-		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+// handleRegistryTags returns the list of available tags for an image from the registry cache
+// GET /api/registry/tags/{imageRef...}?refresh=true
+// refresh=true bypasses cached tag lists.
+func (s *Server) handleRegistryTags(w http.ResponseWriter, r *http.Request) {
+	imageRef := r.PathValue("imageRef")
+	if !validateRequired(w, "image reference", imageRef) {
 		return
 	}
-	setNoCacheHeaders(w)
-	w.Header().Set("Content-Type", "text/html; charset=utf-8")
-	w.Write(data)
-}
 
-// spaHandler serves static files and falls back to index.html for SPA routing
-func spaHandler(staticDir string) http.Handler {
-	fileServer := http.FileServer(http.Dir(staticDir))
-
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// Don't serve API routes through static handler
-		if strings.HasPrefix(r.URL.Path, "/api/") {
-			http.NotFound(w, r)
-			return
-		}
+	ctx := r.Context()
+	if r.URL.Query().Get("refresh") == "true" {
+		ctx = registry.WithCacheBypass(ctx)
+	}
 
-		// Clean the path
-		path := filepath.Clean(r.URL.Path)
-		if path == "/" {
-			path = "/index.html"
-		}
+	// Fetch tags from registry (uses cached data if available)
+	tags, err := s.registryManager.ListTags(ctx, imageRef)
+	if err != nil {
+		RespondInternalError(w, fmt.Errorf("failed to fetch tags: %w", err))
+		return
+	}
 
-		// Check if file exists
-		fullPath := filepath.Join(staticDir, path)
-		_, err := os.Stat(fullPath)
+	RespondSuccess(w, map[string]any{
+		"image_ref": imageRef,
+		"tags":      tags,
+		"count":     len(tags),
+	})
+}
 
-		if os.IsNotExist(err) {
-			// File doesn't exist, serve index.html for SPA routing
-			indexPath := filepath.Join(staticDir, "index.html")
-			if _, indexErr := os.Stat(indexPath); indexErr == nil {
-				serveIndexHTML(w, indexPath)
-				return
-			}
-			http.NotFound(w, r)
-			return
-		} else if err != nil {
-			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
-			return
-		}
+// handleRegistryRepositories lists the repositories of a registry from its catalog
+// GET /api/registry/repositories/{registry}?refresh=true
+// Docker Hub, GHCR, and registries with the catalog disabled return 400.
+func (s *Server) handleRegistryRepositories(w http.ResponseWriter, r *http.Request) {
+	registryHost := r.PathValue("registry")
+	if !validateRequired(w, "registry", registryHost) {
+		return
+	}
 
-		// Check if it's a directory
-		info, _ := os.Stat(fullPath)
-		if info.IsDir() {
-			// Try to serve index.html in the directory
-			indexPath := filepath.Join(fullPath, "index.html")
-			if _, indexErr := os.Stat(indexPath); indexErr == nil {
-				serveIndexHTML(w, indexPath)
-				return
-			}
-			// Otherwise serve root index.html for SPA routing
-			rootIndex := filepath.Join(staticDir, "index.html")
-			serveIndexHTML(w, rootIndex)
-			return
-		}
+	ctx := r.Context()
+	if r.URL.Query().Get("refresh") == "true" {
+		ctx = registry.WithCacheBypass(ctx)
+	}
 
-		// Set cache headers based on file type
-		if strings.HasSuffix(path, ".html") {
-			serveIndexHTML(w, fullPath)
-			return
-		} else if strings.HasPrefix(path, "/assets/") {
-			// Versioned assets (JS/CSS with hashes): cache forever
-			w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
-		} else {
-			// Other static files (images, fonts): cache for 1 hour
-			w.Header().Set("Cache-Control", "public, max-age=3600")
-		}
+	repositories, err := s.registryManager.ListRepositories(ctx, registryHost)
+	if errors.Is(err, registry.ErrCatalogUnsupported) {
+		RespondBadRequest(w, err)
+		return
+	}
+	if err != nil {
+		RespondInternalError(w, fmt.Errorf("failed to list repositories: %w", err))
+		return
+	}
 
-		// Serve the file
-		fileServer.ServeHTTP(w, r)
+	RespondSuccess(w, map[string]any{
+		"registry":     registryHost,
+		"repositories": repositories,
+		"count":        len(repositories),
 	})
 }
 
-// handleRegistryTags returns the list of available tags for an image from the registry cache
-// GET /api/registry/tags/{imageRef...}
-func (s *Server) handleRegistryTags(w http.ResponseWriter, r *http.Request) {
+// handleRegistryManifest returns the digest, platforms, and layers of an image tag or digest
+// GET /api/registry/manifest/{imageRef...}?reference=1.27.0
+// reference defaults to latest.
+func (s *Server) handleRegistryManifest(w http.ResponseWriter, r *http.Request) {
 	imageRef := r.PathValue("imageRef")
 	if !validateRequired(w, "image reference", imageRef) {
 		return
 	}
+	reference := r.URL.Query().Get("reference")
+	if reference == "" {
+		reference = "latest"
+	}
 
-	ctx := r.Context()
-
-	// Fetch tags from registry (uses cached data if available)
-	tags, err := s.registryManager.ListTags(ctx, imageRef)
+	details, err := s.registryManager.GetManifestDetails(r.Context(), imageRef, reference)
 	if err != nil {
-		RespondInternalError(w, fmt.Errorf("failed to fetch tags: %w", err))
+		RespondInternalError(w, fmt.Errorf("failed to fetch manifest: %w", err))
 		return
 	}
 
 	RespondSuccess(w, map[string]any{
 		"image_ref": imageRef,
-		"tags":      tags,
-		"count":     len(tags),
+		"manifest":  details,
 	})
 }
 
@@ -0,0 +1,253 @@
+package api
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"io/fs"
+	"log"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"time"
+)
+
+// minGzipSize is the smallest static file compressed on the fly; smaller responses
+// are not worth the gzip overhead.
+const minGzipSize = 1024
+
+// precompressedEncodings are the content encodings of precompressed static files,
+// e.g. index-abc123.js.br next to index-abc123.js, in order of preference.
+var precompressedEncodings = []struct {
+	name string
+	ext  string
+}{
+	{"br", ".br"},
+	{"gzip", ".gz"},
+}
+
+// uiFiles returns the web UI files to serve: staticDir when it exists, otherwise
+// embedded (the UI built into the binary, nil if it was built without it).
+// An empty staticDir disables the UI.
+func uiFiles(staticDir string, embedded fs.FS) fs.FS {
+	if staticDir == "" {
+		return nil
+	}
+	if info, err := os.Stat(staticDir); err == nil && info.IsDir() {
+		log.Printf("Serving static UI from %s", staticDir)
+		return os.DirFS(staticDir)
+	}
+	if embedded != nil {
+		if _, err := fs.Stat(embedded, "index.html"); err == nil {
+			log.Printf("Static directory %s not found, serving the embedded UI", staticDir)
+			return embedded
+		}
+	}
+	log.Printf("Static directory %s not found, UI will not be served", staticDir)
+	return nil
+}
+
+// spaHandler serves static files and falls back to index.html for SPA routing.
+// Files are sent precompressed (.br or .gz next to them) or gzipped on the fly
+// when the client accepts it.
+func spaHandler(files fs.FS) http.Handler {
+	cache := &gzipCache{entries: make(map[gzipCacheKey][]byte)}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Don't serve API routes through static handler
+		if strings.HasPrefix(r.URL.Path, "/api/") {
+			http.NotFound(w, r)
+			return
+		}
+
+		name := strings.TrimPrefix(path.Clean("/"+r.URL.Path), "/")
+		if name == "" {
+			name = "index.html"
+		}
+
+		info, err := fs.Stat(files, name)
+		switch {
+		case errors.Is(err, fs.ErrNotExist):
+			// File doesn't exist, serve index.html for SPA routing
+			name = "index.html"
+		case err != nil:
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		case info.IsDir():
+			// Serve the directory's index.html, or the root one for SPA routing
+			name = path.Join(name, "index.html")
+			if _, err := fs.Stat(files, name); err != nil {
+				name = "index.html"
+			}
+		}
+
+		if strings.HasSuffix(name, ".html") {
+			serveIndexHTML(w, r, files, name)
+			return
+		}
+
+		// Set cache headers based on file type
+		if strings.HasPrefix(name, "assets/") {
+			// Versioned assets (JS/CSS with hashes): cache forever
+			w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+		} else {
+			// Other static files (images, fonts): cache for 1 hour
+			w.Header().Set("Cache-Control", "public, max-age=3600")
+		}
+		serveStatic(w, r, files, name, cache)
+	})
+}
+
+// serveIndexHTML serves index.html without Last-Modified/ETag headers.
+// http.ServeFile adds Last-Modified which can cause browsers to cache
+// via conditional requests (If-Modified-Since → 304), defeating no-store.
+// By reading and writing bytes directly, we ensure no conditional caching.
+func serveIndexHTML(w http.ResponseWriter, r *http.Request, files fs.FS, name string) {
+	data, err := fs.ReadFile(files, name)
+	if errors.Is(err, fs.ErrNotExist) {
+		http.NotFound(w, r)
+		return
+	} else if err != nil {
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	setNoCacheHeaders(w)
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write(data)
+}
+
+// serveStatic serves a static file, compressed if the client accepts it
+func serveStatic(w http.ResponseWriter, r *http.Request, files fs.FS, name string, cache *gzipCache) {
+	contentType := mime.TypeByExtension(path.Ext(name))
+	if contentType != "" {
+		w.Header().Set("Content-Type", contentType)
+	}
+	w.Header().Add("Vary", "Accept-Encoding")
+
+	for _, encoding := range precompressedEncodings {
+		if acceptsEncoding(r, encoding.name) && serveFile(w, r, files, name+encoding.ext, encoding.name) {
+			return
+		}
+	}
+
+	info, err := fs.Stat(files, name)
+	if err != nil {
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	if r.Header.Get("Range") == "" && acceptsEncoding(r, "gzip") && compressible(contentType) && info.Size() >= minGzipSize {
+		data, err := cache.get(files, name, info)
+		if err == nil {
+			w.Header().Set("Content-Encoding", "gzip")
+			http.ServeContent(w, r, name, info.ModTime(), bytes.NewReader(data))
+			return
+		}
+		log.Printf("Failed to compress %s: %v", name, err)
+	}
+
+	if !serveFile(w, r, files, name, "") {
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	}
+}
+
+// serveFile serves a file with the given content encoding. It reports false,
+// without writing a response, if the file cannot be opened.
+func serveFile(w http.ResponseWriter, r *http.Request, files fs.FS, name, encoding string) bool {
+	f, err := files.Open(name)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil || info.IsDir() {
+		return false
+	}
+	content, ok := f.(io.ReadSeeker)
+	if !ok {
+		data, err := io.ReadAll(f)
+		if err != nil {
+			return false
+		}
+		content = bytes.NewReader(data)
+	}
+	if encoding != "" {
+		w.Header().Set("Content-Encoding", encoding)
+	}
+	http.ServeContent(w, r, name, info.ModTime(), content)
+	return true
+}
+
+// acceptsEncoding reports whether the request's Accept-Encoding allows encoding
+func acceptsEncoding(r *http.Request, encoding string) bool {
+	for _, part := range strings.Split(r.Header.Get("Accept-Encoding"), ",") {
+		token, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if !strings.EqualFold(strings.TrimSpace(token), encoding) {
+			continue
+		}
+		q := strings.ReplaceAll(params, " ", "")
+		return q != "q=0" && q != "q=0.0" && q != "q=0.00" && q != "q=0.000"
+	}
+	return false
+}
+
+// compressible reports whether files of a content type shrink when gzipped
+func compressible(contentType string) bool {
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	switch mediaType {
+	case "application/javascript", "application/json", "application/manifest+json", "application/wasm", "image/svg+xml":
+		return true
+	}
+	return strings.HasPrefix(mediaType, "text/")
+}
+
+// gzipCacheKey identifies a version of a static file
+type gzipCacheKey struct {
+	name    string
+	size    int64
+	modTime time.Time
+}
+
+// gzipCache keeps the gzipped content of static files so each is compressed once.
+// A file changed on disk gets a new entry.
+type gzipCache struct {
+	mu      sync.Mutex
+	entries map[gzipCacheKey][]byte
+}
+
+// get returns the gzipped content of a file
+func (c *gzipCache) get(files fs.FS, name string, info fs.FileInfo) ([]byte, error) {
+	key := gzipCacheKey{name: name, size: info.Size(), modTime: info.ModTime()}
+	c.mu.Lock()
+	data, ok := c.entries[key]
+	c.mu.Unlock()
+	if ok {
+		return data, nil
+	}
+
+	raw, err := fs.ReadFile(files, name)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	gz, _ := gzip.NewWriterLevel(&buf, gzip.BestCompression)
+	if _, err := gz.Write(raw); err != nil {
+		return nil, err
+	}
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	for existing := range c.entries {
+		if existing.name == name {
+			delete(c.entries, existing)
+		}
+	}
+	c.entries[key] = buf.Bytes()
+	c.mu.Unlock()
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,92 @@
+package api
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSPAHandler(t *testing.T) {
+	script := strings.Repeat("console.log('docksmith');\n", 100)
+	files := fstest.MapFS{
+		"index.html":                 {Data: []byte("<html>app</html>")},
+		"assets/index-abc123.js":     {Data: []byte(script)},
+		"assets/index-abc123.css":    {Data: []byte(strings.Repeat("body{margin:0}", 100))},
+		"assets/index-abc123.css.br": {Data: []byte("brotli")},
+		"favicon.png":                {Data: []byte(strings.Repeat("\x89PNG", 500))},
+	}
+	handler := spaHandler(files)
+
+	get := func(path, acceptEncoding string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if acceptEncoding != "" {
+			req.Header.Set("Accept-Encoding", acceptEncoding)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("index is never cached", func(t *testing.T) {
+		for _, path := range []string{"/", "/containers/plex", "/index.html"} {
+			rec := get(path, "gzip")
+			assert.Equal(t, http.StatusOK, rec.Code, path)
+			assert.Equal(t, "<html>app</html>", rec.Body.String(), path)
+			assert.Contains(t, rec.Header().Get("Cache-Control"), "no-store", path)
+		}
+	})
+
+	t.Run("hashed assets are gzipped and cached forever", func(t *testing.T) {
+		rec := get("/assets/index-abc123.js", "br;q=0, gzip")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "public, max-age=31536000, immutable", rec.Header().Get("Cache-Control"))
+		assert.Equal(t, "gzip", rec.Header().Get("Content-Encoding"))
+		assert.Equal(t, "Accept-Encoding", rec.Header().Get("Vary"))
+		gz, err := gzip.NewReader(rec.Body)
+		require.NoError(t, err)
+		body, err := io.ReadAll(gz)
+		require.NoError(t, err)
+		assert.Equal(t, script, string(body))
+	})
+
+	t.Run("precompressed files are preferred", func(t *testing.T) {
+		rec := get("/assets/index-abc123.css", "gzip, br")
+		assert.Equal(t, "br", rec.Header().Get("Content-Encoding"))
+		assert.Equal(t, "brotli", rec.Body.String())
+		assert.Contains(t, rec.Header().Get("Content-Type"), "text/css")
+
+		rec = get("/assets/index-abc123.css", "")
+		assert.Empty(t, rec.Header().Get("Content-Encoding"))
+		assert.Equal(t, strings.Repeat("body{margin:0}", 100), rec.Body.String())
+	})
+
+	t.Run("images are not compressed", func(t *testing.T) {
+		rec := get("/favicon.png", "gzip")
+		assert.Empty(t, rec.Header().Get("Content-Encoding"))
+		assert.Equal(t, "public, max-age=3600", rec.Header().Get("Cache-Control"))
+	})
+
+	t.Run("api routes are not served", func(t *testing.T) {
+		assert.Equal(t, http.StatusNotFound, get("/api/unknown", "").Code)
+	})
+}
+
+func TestUIFiles(t *testing.T) {
+	embedded := fstest.MapFS{"index.html": {Data: []byte("<html>embedded</html>")}}
+
+	assert.Nil(t, uiFiles("", embedded), "an empty static directory disables the UI")
+	assert.Equal(t, embedded, uiFiles(t.TempDir()+"/missing", embedded))
+	assert.Nil(t, uiFiles(t.TempDir()+"/missing", nil))
+
+	dir := t.TempDir()
+	files := uiFiles(dir, embedded)
+	require.NotNil(t, files)
+	assert.NotEqual(t, embedded, files, "an existing static directory takes precedence")
+}
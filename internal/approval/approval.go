@@ -0,0 +1,440 @@
+// Package approval implements the update approval workflow. Containers with the
+// approval-required policy never update unattended: each detected update is
+// recorded as a pending approval and only applied after an operator approves
+// it through the API, CLI, dashboard, or a signed webhook callback. The major
+// approval policy gates only major version changes and applies patch and minor
+// updates automatically.
+package approval
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chis/docksmith/internal/events"
+	"github.com/chis/docksmith/internal/storage"
+	"github.com/chis/docksmith/internal/update"
+	"github.com/chis/docksmith/internal/version"
+	"github.com/google/uuid"
+)
+
+// RequiredConfigKey is the config key enabling approvals for every container.
+const RequiredConfigKey = "approval_required"
+
+// autoApprover is recorded as the approver of updates applied automatically under
+// the major approval policy.
+const autoApprover = "policy:major"
+
+// defaultTTL is how long a pending approval waits for a decision.
+const defaultTTL = 72 * time.Hour
+
+// webhookMaxSkew is how far a webhook timestamp may be from the current time,
+// bounding how long a captured callback can be replayed.
+const webhookMaxSkew = 5 * time.Minute
+
+// Sentinel errors
+var (
+	ErrNotFound         = errors.New("approval not found")
+	ErrNotPending       = errors.New("approval is no longer pending")
+	ErrInvalidSignature = errors.New("invalid webhook signature")
+	ErrWebhookDisabled  = errors.New("approval webhook is not configured")
+	ErrInvalidPolicy    = errors.New("invalid approval policy")
+)
+
+// Updater starts a container update. Implemented by update.UpdateOrchestrator.
+type Updater interface {
+	UpdateSingleContainer(ctx context.Context, containerName, targetVersion string) (string, error)
+}
+
+// Manager tracks pending approvals and applies approved updates.
+type Manager struct {
+	storage       storage.Storage
+	updater       Updater
+	eventBus      *events.Bus
+	ttl           time.Duration
+	webhookSecret string
+
+	mu      sync.Mutex
+	checked map[string]update.ContainerInfo // containers from the last check
+}
+
+// NewManager creates an approval manager. updater may be nil (e.g. in the CLI),
+// in which case approved updates are applied by the server on its next check.
+// The decision window can be set with APPROVAL_TTL (default 72h) and webhook
+// callbacks are enabled by APPROVAL_WEBHOOK_SECRET.
+func NewManager(store storage.Storage, updater Updater, eventBus *events.Bus) *Manager {
+	ttl := defaultTTL
+	if ttlStr := os.Getenv("APPROVAL_TTL"); ttlStr != "" {
+		if parsed, err := time.ParseDuration(ttlStr); err == nil && parsed > 0 {
+			ttl = parsed
+		} else {
+			log.Printf("Warning: Invalid APPROVAL_TTL '%s', using default %v", ttlStr, ttl)
+		}
+	}
+
+	return &Manager{
+		storage:       store,
+		updater:       updater,
+		eventBus:      eventBus,
+		ttl:           ttl,
+		webhookSecret: os.Getenv("APPROVAL_WEBHOOK_SECRET"),
+		checked:       make(map[string]update.ContainerInfo),
+	}
+}
+
+// TTL returns how long pending approvals remain valid.
+func (m *Manager) TTL() time.Duration {
+	return m.ttl
+}
+
+// Required reports whether updating the container to targetVersion must be
+// approved first. The change type is judged against the container's version in
+// the last check; an empty target means the update found by that check.
+func (m *Manager) Required(ctx context.Context, containerName, targetVersion string) bool {
+	m.mu.Lock()
+	c, found := m.checked[containerName]
+	m.mu.Unlock()
+	if !found {
+		c.ContainerName = containerName
+		c.ChangeType = version.UnknownChange
+	}
+	if targetVersion != "" && targetVersion != c.LatestVersion {
+		c.ChangeType = version.UnknownChange
+		parser := version.NewParser()
+		if current, target := parser.ParseTag(c.CurrentVersion), parser.ParseTag(targetVersion); current != nil && target != nil {
+			c.ChangeType = version.NewComparator().GetChangeType(current, target)
+		}
+	}
+	return m.RequiredFor(ctx, c)
+}
+
+// RequiredFor reports whether updates to a checked container must be approved
+// first. Unlike Required it reads the container's labels, so it works without a
+// prior Sync (e.g. in the CLI).
+func (m *Manager) RequiredFor(ctx context.Context, c update.ContainerInfo) bool {
+	return gated(m.ModeFor(ctx, c), c.ChangeType)
+}
+
+// Sync records pending approvals for newly detected updates and applies
+// approvals decided outside the server. Registered as a background checker
+// result handler.
+func (m *Manager) Sync(ctx context.Context, result *update.DiscoveryResult) {
+	if result == nil {
+		return
+	}
+
+	if _, err := m.storage.ExpireApprovals(ctx, time.Now()); err != nil {
+		log.Printf("APPROVAL: Failed to expire approvals: %v", err)
+	}
+
+	checked := make(map[string]update.ContainerInfo, len(result.Containers))
+
+	for _, c := range result.Containers {
+		checked[c.ContainerName] = c
+
+		mode := m.ModeFor(ctx, c)
+		if mode == storage.ApprovalModeNone {
+			continue
+		}
+		// Under the major policy, other updates are approved automatically and
+		// applied below like any approved update
+		if err := m.syncContainer(ctx, c, !gated(mode, c.ChangeType)); err != nil {
+			log.Printf("APPROVAL: Failed to sync approval for %s: %v", c.ContainerName, err)
+		}
+	}
+
+	m.mu.Lock()
+	m.checked = checked
+	m.mu.Unlock()
+
+	m.applyApproved(ctx)
+}
+
+// syncContainer creates, keeps, or supersedes the approval for one container.
+// With autoApprove the new approval is recorded as approved by the policy.
+func (m *Manager) syncContainer(ctx context.Context, c update.ContainerInfo, autoApprove bool) error {
+	latest, found, err := m.storage.GetLatestApproval(ctx, c.ContainerName)
+	if err != nil {
+		return err
+	}
+
+	if c.Status != update.UpdateAvailable {
+		// Update no longer applies (e.g. container changed outside docksmith)
+		if found && latest.Status == storage.ApprovalPending {
+			latest.Status = storage.ApprovalSuperseded
+			return m.storage.SaveApproval(ctx, latest)
+		}
+		return nil
+	}
+
+	current := c.CurrentVersion
+	if current == "" {
+		current = c.CurrentDigest
+	}
+
+	if found && latest.CurrentVersion == current && latest.TargetVersion == c.LatestVersion {
+		switch latest.Status {
+		case storage.ApprovalPending, storage.ApprovalApproved, storage.ApprovalRejected:
+			return nil // Already requested or decided for this exact update
+		}
+	}
+
+	if found && latest.Status == storage.ApprovalPending {
+		latest.Status = storage.ApprovalSuperseded
+		if err := m.storage.SaveApproval(ctx, latest); err != nil {
+			return err
+		}
+	}
+
+	now := time.Now()
+	approval := storage.Approval{
+		ID:             uuid.New().String(),
+		ContainerName:  c.ContainerName,
+		StackName:      c.Stack,
+		CurrentVersion: current,
+		TargetVersion:  c.LatestVersion,
+		Status:         storage.ApprovalPending,
+		RequestedAt:    now,
+		ExpiresAt:      now.Add(m.ttl),
+	}
+	if autoApprove {
+		approval.Status = storage.ApprovalApproved
+		approval.DecidedBy = autoApprover
+		approval.DecidedAt = &now
+	}
+	if err := m.storage.SaveApproval(ctx, approval); err != nil {
+		return err
+	}
+
+	if autoApprove {
+		log.Printf("APPROVAL: %s update for %s (%s -> %s) approved by the major approval policy",
+			c.ChangeType, approval.ContainerName, approval.CurrentVersion, displayTarget(approval))
+		m.publish(events.EventApprovalDecided, approval)
+		return nil
+	}
+	log.Printf("APPROVAL: Update for %s (%s -> %s) awaiting approval until %s",
+		approval.ContainerName, approval.CurrentVersion, displayTarget(approval), approval.ExpiresAt.Format(time.RFC3339))
+	m.publish(events.EventApprovalRequested, approval)
+	return nil
+}
+
+// List returns approvals newest first, optionally filtered by status.
+func (m *Manager) List(ctx context.Context, status string, limit int) ([]storage.Approval, error) {
+	return m.storage.ListApprovals(ctx, status, limit)
+}
+
+// Get returns a single approval.
+func (m *Manager) Get(ctx context.Context, id string) (storage.Approval, error) {
+	approval, found, err := m.storage.GetApproval(ctx, id)
+	if err != nil {
+		return storage.Approval{}, err
+	}
+	if !found {
+		return storage.Approval{}, ErrNotFound
+	}
+	return approval, nil
+}
+
+// Approve records the approval and starts the update when an updater is available.
+func (m *Manager) Approve(ctx context.Context, id, actor string) (storage.Approval, error) {
+	approval, err := m.decide(ctx, id, actor, storage.ApprovalApproved)
+	if err != nil {
+		return storage.Approval{}, err
+	}
+	if m.updater == nil {
+		return approval, nil
+	}
+	return m.apply(ctx, approval)
+}
+
+// Reject records that the update must not be applied.
+func (m *Manager) Reject(ctx context.Context, id, actor string) (storage.Approval, error) {
+	return m.decide(ctx, id, actor, storage.ApprovalRejected)
+}
+
+// HandleWebhook verifies an HMAC-SHA256 signature ("sha256=<hex>") over the
+// approval ID, the Unix timestamp, and body, each separated by a newline, and
+// applies the requested action ("approve" or "reject"). Signatures are bound to
+// one approval, and timestamps more than five minutes from now are rejected, so
+// a captured callback cannot be replayed against other approvals or later on.
+func (m *Manager) HandleWebhook(ctx context.Context, id, signature, timestamp string, body []byte, action, actor string) (storage.Approval, error) {
+	if m.webhookSecret == "" {
+		return storage.Approval{}, ErrWebhookDisabled
+	}
+	if !VerifySignature(m.webhookSecret, id, timestamp, body, signature) {
+		return storage.Approval{}, ErrInvalidSignature
+	}
+	sent, err := strconv.ParseInt(strings.TrimSpace(timestamp), 10, 64)
+	if err != nil {
+		return storage.Approval{}, fmt.Errorf("%w: invalid timestamp %q", ErrInvalidSignature, timestamp)
+	}
+	if skew := time.Since(time.Unix(sent, 0)); skew > webhookMaxSkew || skew < -webhookMaxSkew {
+		return storage.Approval{}, fmt.Errorf("%w: timestamp is more than %s from the current time", ErrInvalidSignature, webhookMaxSkew)
+	}
+
+	if actor == "" {
+		actor = "webhook"
+	} else {
+		actor = "webhook:" + actor
+	}
+
+	switch action {
+	case "approve":
+		return m.Approve(ctx, id, actor)
+	case "reject":
+		return m.Reject(ctx, id, actor)
+	default:
+		return storage.Approval{}, fmt.Errorf("invalid action %q (must be 'approve' or 'reject')", action)
+	}
+}
+
+// Sign returns the webhook signature header value for a callback deciding the
+// approval id, sent at timestamp (Unix seconds) with body.
+func Sign(secret, id, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(id + "\n" + timestamp + "\n"))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifySignature reports whether signature matches the callback under secret.
+func VerifySignature(secret, id, timestamp string, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, id, timestamp, body)), []byte(strings.TrimSpace(signature)))
+}
+
+// decide transitions a pending approval to approved or rejected.
+func (m *Manager) decide(ctx context.Context, id, actor, status string) (storage.Approval, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	approval, err := m.Get(ctx, id)
+	if err != nil {
+		return storage.Approval{}, err
+	}
+
+	now := time.Now()
+	if approval.Status == storage.ApprovalPending && now.After(approval.ExpiresAt) {
+		approval.Status = storage.ApprovalExpired
+		if err := m.storage.SaveApproval(ctx, approval); err != nil {
+			return storage.Approval{}, err
+		}
+	}
+	if approval.Status != storage.ApprovalPending {
+		return approval, fmt.Errorf("%w (status: %s)", ErrNotPending, approval.Status)
+	}
+
+	if actor == "" {
+		actor = "anonymous"
+	}
+	approval.Status = status
+	approval.DecidedBy = actor
+	approval.DecidedAt = &now
+	if err := m.storage.SaveApproval(ctx, approval); err != nil {
+		return storage.Approval{}, err
+	}
+
+	log.Printf("APPROVAL: Update for %s -> %s %s by %s", approval.ContainerName, displayTarget(approval), status, actor)
+	m.publish(events.EventApprovalDecided, approval)
+	return approval, nil
+}
+
+// apply starts the update for an approved approval and records the operation ID.
+func (m *Manager) apply(ctx context.Context, approval storage.Approval) (storage.Approval, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// Re-read so a concurrent Sync does not start the same update twice
+	current, err := m.Get(ctx, approval.ID)
+	if err != nil {
+		return approval, err
+	}
+	if current.OperationID != "" {
+		return current, nil
+	}
+
+	trigger := "approval"
+	if current.DecidedBy != "" {
+		trigger += ":" + current.DecidedBy
+	}
+	ctx = update.WithTrigger(ctx, trigger)
+	operationID, err := m.updater.UpdateSingleContainer(ctx, current.ContainerName, current.TargetVersion)
+	if err != nil {
+		return current, fmt.Errorf("approved, but failed to start update: %w", err)
+	}
+
+	current.OperationID = operationID
+	if err := m.storage.SaveApproval(ctx, current); err != nil {
+		return current, err
+	}
+	log.Printf("APPROVAL: Started update %s for %s", operationID, current.ContainerName)
+	return current, nil
+}
+
+// applyApproved starts updates approved outside the server (e.g. via the CLI).
+func (m *Manager) applyApproved(ctx context.Context) {
+	if m.updater == nil {
+		return
+	}
+
+	approved, err := m.storage.ListApprovals(ctx, storage.ApprovalApproved, 0)
+	if err != nil {
+		log.Printf("APPROVAL: Failed to list approved updates: %v", err)
+		return
+	}
+	for _, approval := range approved {
+		if approval.OperationID != "" {
+			continue
+		}
+		if _, err := m.apply(ctx, approval); err != nil {
+			log.Printf("APPROVAL: %v", err)
+		}
+	}
+}
+
+// globallyRequired reports whether the approval_required setting is enabled.
+func (m *Manager) globallyRequired(ctx context.Context) bool {
+	if m.storage == nil {
+		return false
+	}
+	value, found, err := m.storage.GetConfig(ctx, RequiredConfigKey)
+	return err == nil && found && isTrue(value)
+}
+
+// publish notifies event bus subscribers (the dashboard) about an approval change.
+func (m *Manager) publish(eventType string, approval storage.Approval) {
+	if m.eventBus == nil {
+		return
+	}
+	m.eventBus.Publish(events.Event{
+		Type: eventType,
+		Payload: map[string]interface{}{
+			"approval_id":    approval.ID,
+			"container_name": approval.ContainerName,
+			"target_version": displayTarget(approval),
+			"status":         approval.Status,
+			"decided_by":     approval.DecidedBy,
+		},
+	})
+}
+
+// displayTarget returns a human-readable target version.
+func displayTarget(approval storage.Approval) string {
+	if approval.TargetVersion == "" {
+		return "latest digest"
+	}
+	return approval.TargetVersion
+}
+
+// isTrue parses the boolean label/config forms accepted elsewhere in docksmith.
+func isTrue(value string) bool {
+	return value == "true" || value == "1" || value == "yes"
+}
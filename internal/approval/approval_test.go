@@ -0,0 +1,226 @@
+package approval
+
+import (
+	"context"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/chis/docksmith/internal/scripts"
+	"github.com/chis/docksmith/internal/storage"
+	"github.com/chis/docksmith/internal/update"
+	"github.com/chis/docksmith/internal/version"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeUpdater records the updates started by the manager.
+type fakeUpdater struct {
+	started []string
+}
+
+func (f *fakeUpdater) UpdateSingleContainer(ctx context.Context, containerName, targetVersion string) (string, error) {
+	f.started = append(f.started, containerName+"@"+targetVersion)
+	return "op-" + containerName, nil
+}
+
+func newTestManager(t *testing.T, updater Updater) (*Manager, storage.Storage) {
+	t.Helper()
+	store, err := storage.NewSQLiteStorage(filepath.Join(t.TempDir(), "test.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { store.Close() })
+	return NewManager(store, updater, nil), store
+}
+
+func gatedResult(current, target string) *update.DiscoveryResult {
+	c := update.ContainerInfo{
+		Labels: map[string]string{scripts.RequireApprovalLabel: "true"},
+	}
+	c.ContainerName = "web"
+	c.CurrentVersion = current
+	c.LatestVersion = target
+	c.Status = update.UpdateAvailable
+	return &update.DiscoveryResult{Containers: []update.ContainerInfo{c}}
+}
+
+func TestManager_SyncCreatesAndDedupsApproval(t *testing.T) {
+	ctx := context.Background()
+	m, _ := newTestManager(t, nil)
+
+	m.Sync(ctx, gatedResult("1.0", "1.1"))
+	m.Sync(ctx, gatedResult("1.0", "1.1"))
+
+	pending, err := m.List(ctx, storage.ApprovalPending, 0)
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	assert.Equal(t, "web", pending[0].ContainerName)
+	assert.Equal(t, "1.1", pending[0].TargetVersion)
+	assert.True(t, m.Required(ctx, "web", ""))
+	assert.False(t, m.Required(ctx, "db", ""))
+}
+
+func TestManager_SyncSupersedesOnNewTarget(t *testing.T) {
+	ctx := context.Background()
+	m, _ := newTestManager(t, nil)
+
+	m.Sync(ctx, gatedResult("1.0", "1.1"))
+	m.Sync(ctx, gatedResult("1.0", "1.2"))
+
+	superseded, err := m.List(ctx, storage.ApprovalSuperseded, 0)
+	require.NoError(t, err)
+	require.Len(t, superseded, 1)
+	assert.Equal(t, "1.1", superseded[0].TargetVersion)
+
+	pending, err := m.List(ctx, storage.ApprovalPending, 0)
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	assert.Equal(t, "1.2", pending[0].TargetVersion)
+}
+
+func TestManager_ApproveStartsUpdate(t *testing.T) {
+	ctx := context.Background()
+	updater := &fakeUpdater{}
+	m, _ := newTestManager(t, updater)
+
+	m.Sync(ctx, gatedResult("1.0", "1.1"))
+	pending, err := m.List(ctx, storage.ApprovalPending, 0)
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+
+	approved, err := m.Approve(ctx, pending[0].ID, "user:alice")
+	require.NoError(t, err)
+	assert.Equal(t, storage.ApprovalApproved, approved.Status)
+	assert.Equal(t, "user:alice", approved.DecidedBy)
+	assert.Equal(t, "op-web", approved.OperationID)
+	assert.Equal(t, []string{"web@1.1"}, updater.started)
+
+	// A decided approval cannot be decided again
+	_, err = m.Reject(ctx, pending[0].ID, "user:bob")
+	assert.ErrorIs(t, err, ErrNotPending)
+}
+
+func TestManager_SyncAppliesApprovalsFromCLI(t *testing.T) {
+	ctx := context.Background()
+	m, store := newTestManager(t, nil)
+
+	m.Sync(ctx, gatedResult("1.0", "1.1"))
+	pending, err := m.List(ctx, storage.ApprovalPending, 0)
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+
+	approved, err := m.Approve(ctx, pending[0].ID, "cli")
+	require.NoError(t, err)
+	assert.Empty(t, approved.OperationID)
+
+	// The server-side manager applies it on the next check
+	updater := &fakeUpdater{}
+	server := NewManager(store, updater, nil)
+	server.Sync(ctx, gatedResult("1.0", "1.1"))
+	assert.Equal(t, []string{"web@1.1"}, updater.started)
+
+	server.Sync(ctx, gatedResult("1.0", "1.1"))
+	assert.Len(t, updater.started, 1, "approved update must only start once")
+}
+
+func TestManager_ExpiredApprovalCannotBeApproved(t *testing.T) {
+	t.Setenv("APPROVAL_TTL", "1ms")
+	ctx := context.Background()
+	m, _ := newTestManager(t, &fakeUpdater{})
+
+	m.Sync(ctx, gatedResult("1.0", "1.1"))
+	pending, err := m.List(ctx, storage.ApprovalPending, 0)
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+
+	time.Sleep(5 * time.Millisecond)
+	_, err = m.Approve(ctx, pending[0].ID, "user:alice")
+	assert.ErrorIs(t, err, ErrNotPending)
+
+	got, err := m.Get(ctx, pending[0].ID)
+	require.NoError(t, err)
+	assert.Equal(t, storage.ApprovalExpired, got.Status)
+}
+
+func TestManager_GlobalRequirement(t *testing.T) {
+	ctx := context.Background()
+	m, store := newTestManager(t, nil)
+
+	assert.False(t, m.Required(ctx, "anything", ""))
+	require.NoError(t, store.SetConfig(ctx, RequiredConfigKey, "true"))
+	assert.True(t, m.Required(ctx, "anything", ""))
+}
+
+// policyResult is a check result for web in the app stack with a change of the given type.
+func policyResult(current, target string, changeType version.ChangeType) *update.DiscoveryResult {
+	result := gatedResult(current, target)
+	result.Containers[0].Labels = nil
+	result.Containers[0].Stack = "app"
+	result.Containers[0].ChangeType = changeType
+	return result
+}
+
+func TestManager_MajorPolicyAppliesMinorUpdates(t *testing.T) {
+	ctx := context.Background()
+	updater := &fakeUpdater{}
+	m, store := newTestManager(t, updater)
+	require.NoError(t, store.SetApprovalPolicy(ctx, storage.ApprovalPolicy{EntityType: "stack", EntityID: "app", Mode: storage.ApprovalModeMajor}))
+
+	m.Sync(ctx, policyResult("1.0", "1.1", version.MinorChange))
+	m.Sync(ctx, policyResult("1.0", "1.1", version.MinorChange))
+
+	assert.Equal(t, []string{"web@1.1"}, updater.started, "minor updates are applied once without approval")
+	approved, err := m.List(ctx, storage.ApprovalApproved, 0)
+	require.NoError(t, err)
+	require.Len(t, approved, 1)
+	assert.Equal(t, autoApprover, approved[0].DecidedBy)
+	assert.False(t, m.Required(ctx, "web", ""))
+	assert.True(t, m.Required(ctx, "web", "2.0"), "a major target chosen by hand needs approval")
+}
+
+func TestManager_MajorPolicyGatesMajorUpdates(t *testing.T) {
+	ctx := context.Background()
+	updater := &fakeUpdater{}
+	m, store := newTestManager(t, updater)
+	require.NoError(t, store.SetApprovalPolicy(ctx, storage.ApprovalPolicy{EntityType: "global", Mode: storage.ApprovalModeMajor}))
+
+	m.Sync(ctx, policyResult("1.0", "2.0", version.MajorChange))
+
+	assert.Empty(t, updater.started)
+	pending, err := m.List(ctx, storage.ApprovalPending, 0)
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	assert.True(t, m.Required(ctx, "web", ""))
+}
+
+func TestManager_HandleWebhook(t *testing.T) {
+	t.Setenv("APPROVAL_WEBHOOK_SECRET", "s3cret")
+	ctx := context.Background()
+	m, _ := newTestManager(t, nil)
+
+	m.Sync(ctx, gatedResult("1.0", "1.1"))
+	pending, err := m.List(ctx, storage.ApprovalPending, 0)
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+
+	body := []byte(`{"action":"reject","actor":"chatops"}`)
+	id := pending[0].ID
+	now := strconv.FormatInt(time.Now().Unix(), 10)
+
+	_, err = m.HandleWebhook(ctx, id, Sign("wrong", id, now, body), now, body, "reject", "chatops")
+	assert.ErrorIs(t, err, ErrInvalidSignature)
+
+	// A signature is only valid for the approval it names
+	_, err = m.HandleWebhook(ctx, id, Sign("s3cret", "other-id", now, body), now, body, "reject", "chatops")
+	assert.ErrorIs(t, err, ErrInvalidSignature)
+
+	// and only close to the time it was signed
+	stale := strconv.FormatInt(time.Now().Add(-10*time.Minute).Unix(), 10)
+	_, err = m.HandleWebhook(ctx, id, Sign("s3cret", id, stale, body), stale, body, "reject", "chatops")
+	assert.ErrorIs(t, err, ErrInvalidSignature)
+
+	rejected, err := m.HandleWebhook(ctx, id, Sign("s3cret", id, now, body), now, body, "reject", "chatops")
+	require.NoError(t, err)
+	assert.Equal(t, storage.ApprovalRejected, rejected.Status)
+	assert.Equal(t, "webhook:chatops", rejected.DecidedBy)
+}
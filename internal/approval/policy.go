@@ -0,0 +1,124 @@
+package approval
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/chis/docksmith/internal/scripts"
+	"github.com/chis/docksmith/internal/storage"
+	"github.com/chis/docksmith/internal/update"
+	"github.com/chis/docksmith/internal/version"
+)
+
+// ModeFor resolves the approval policy mode of a checked container: the
+// docksmith.require-approval label, then the container, stack, and global
+// approval policies, then the approval_required setting.
+func (m *Manager) ModeFor(ctx context.Context, c update.ContainerInfo) string {
+	label := strings.ToLower(strings.TrimSpace(c.Labels[scripts.RequireApprovalLabel]))
+	if isTrue(label) {
+		return storage.ApprovalModeAll
+	}
+	if label == storage.ApprovalModeMajor {
+		return storage.ApprovalModeMajor
+	}
+	if m.storage == nil {
+		return storage.ApprovalModeNone
+	}
+
+	for _, level := range [][2]string{{"container", c.ContainerName}, {"stack", c.Stack}, {"global", ""}} {
+		if level[0] != "global" && level[1] == "" {
+			continue
+		}
+		policy, found, err := m.storage.GetApprovalPolicy(ctx, level[0], level[1])
+		if err != nil {
+			log.Printf("APPROVAL: Failed to read %s approval policy: %v", level[0], err)
+			continue
+		}
+		if found {
+			return policy.Mode
+		}
+	}
+
+	if m.globallyRequired(ctx) {
+		return storage.ApprovalModeAll
+	}
+	return storage.ApprovalModeNone
+}
+
+// gated reports whether an update of the given change type needs approval under
+// a policy mode. The major policy lets through patch and minor updates and
+// rebuilds of the same version; major, unknown (e.g. digest-only), and downgrade
+// changes need approval.
+func gated(mode string, changeType version.ChangeType) bool {
+	switch mode {
+	case storage.ApprovalModeAll:
+		return true
+	case storage.ApprovalModeMajor:
+		switch changeType {
+		case version.NoChange, version.PatchChange, version.MinorChange:
+			return false
+		}
+		return true
+	}
+	return false
+}
+
+// ValidatePolicy checks the scope, name, and mode of an approval policy.
+func ValidatePolicy(entityType, entityID, mode string) error {
+	if err := validatePolicyEntity(entityType, entityID); err != nil {
+		return err
+	}
+	switch mode {
+	case storage.ApprovalModeNone, storage.ApprovalModeMajor, storage.ApprovalModeAll:
+		return nil
+	}
+	return fmt.Errorf("%w: mode %q must be none, major, or all", ErrInvalidPolicy, mode)
+}
+
+// Policies returns the approval policies, global first.
+func (m *Manager) Policies(ctx context.Context) ([]storage.ApprovalPolicy, error) {
+	return m.storage.ListApprovalPolicies(ctx)
+}
+
+// SetPolicy sets the approval policy mode of the host (entityType "global"), a
+// stack, or a container.
+func (m *Manager) SetPolicy(ctx context.Context, entityType, entityID, mode string) (storage.ApprovalPolicy, error) {
+	if err := ValidatePolicy(entityType, entityID, mode); err != nil {
+		return storage.ApprovalPolicy{}, err
+	}
+
+	policy := storage.ApprovalPolicy{EntityType: entityType, EntityID: entityID, Mode: mode}
+	if err := m.storage.SetApprovalPolicy(ctx, policy); err != nil {
+		return storage.ApprovalPolicy{}, err
+	}
+	policy, _, err := m.storage.GetApprovalPolicy(ctx, entityType, entityID)
+	return policy, err
+}
+
+// DeletePolicy removes an approval policy, so the next level of the hierarchy applies.
+func (m *Manager) DeletePolicy(ctx context.Context, entityType, entityID string) error {
+	if err := validatePolicyEntity(entityType, entityID); err != nil {
+		return err
+	}
+	return m.storage.DeleteApprovalPolicy(ctx, entityType, entityID)
+}
+
+// validatePolicyEntity checks that stack and container policies name their entity
+// and the global policy does not.
+func validatePolicyEntity(entityType, entityID string) error {
+	switch entityType {
+	case "global":
+		if entityID != "" {
+			return fmt.Errorf("%w: the global policy takes no name", ErrInvalidPolicy)
+		}
+	case "stack", "container":
+		if entityID == "" {
+			return fmt.Errorf("%w: a %s policy needs a %s name", ErrInvalidPolicy, entityType, entityType)
+		}
+	default:
+		return fmt.Errorf("%w: scope %q must be global, stack, or container", ErrInvalidPolicy, entityType)
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package approval
+
+import (
+	"context"
+	"testing"
+
+	"github.com/chis/docksmith/internal/scripts"
+	"github.com/chis/docksmith/internal/storage"
+	"github.com/chis/docksmith/internal/version"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestManager_ModeFor(t *testing.T) {
+	ctx := context.Background()
+	m, store := newTestManager(t, nil)
+	c := policyResult("1.0", "2.0", version.MajorChange).Containers[0]
+
+	assert.Equal(t, storage.ApprovalModeNone, m.ModeFor(ctx, c))
+
+	require.NoError(t, store.SetConfig(ctx, RequiredConfigKey, "true"))
+	assert.Equal(t, storage.ApprovalModeAll, m.ModeFor(ctx, c), "the approval_required setting applies without policies")
+
+	require.NoError(t, store.SetApprovalPolicy(ctx, storage.ApprovalPolicy{EntityType: "global", Mode: storage.ApprovalModeMajor}))
+	assert.Equal(t, storage.ApprovalModeMajor, m.ModeFor(ctx, c))
+
+	require.NoError(t, store.SetApprovalPolicy(ctx, storage.ApprovalPolicy{EntityType: "stack", EntityID: "app", Mode: storage.ApprovalModeAll}))
+	assert.Equal(t, storage.ApprovalModeAll, m.ModeFor(ctx, c))
+
+	require.NoError(t, store.SetApprovalPolicy(ctx, storage.ApprovalPolicy{EntityType: "container", EntityID: "web", Mode: storage.ApprovalModeNone}))
+	assert.Equal(t, storage.ApprovalModeNone, m.ModeFor(ctx, c))
+
+	c.Labels = map[string]string{scripts.RequireApprovalLabel: "major"}
+	assert.Equal(t, storage.ApprovalModeMajor, m.ModeFor(ctx, c), "labels override policies")
+}
+
+func TestManager_SetPolicy(t *testing.T) {
+	ctx := context.Background()
+	m, _ := newTestManager(t, nil)
+
+	policy, err := m.SetPolicy(ctx, "stack", "app", storage.ApprovalModeMajor)
+	require.NoError(t, err)
+	assert.Equal(t, storage.ApprovalModeMajor, policy.Mode)
+	assert.NotZero(t, policy.ID)
+
+	_, err = m.SetPolicy(ctx, "stack", "", storage.ApprovalModeMajor)
+	assert.ErrorIs(t, err, ErrInvalidPolicy)
+	_, err = m.SetPolicy(ctx, "global", "app", storage.ApprovalModeMajor)
+	assert.ErrorIs(t, err, ErrInvalidPolicy)
+	_, err = m.SetPolicy(ctx, "global", "", "minor")
+	assert.ErrorIs(t, err, ErrInvalidPolicy)
+
+	require.NoError(t, m.DeletePolicy(ctx, "stack", "app"))
+	policies, err := m.Policies(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, policies)
+}
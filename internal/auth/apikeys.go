@@ -0,0 +1,287 @@
+// Package auth provides API key management and request authentication for the
+// docksmith HTTP API.
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chis/docksmith/internal/storage"
+)
+
+// Key scopes. Each scope maps to a role: read -> viewer, update -> operator, admin -> admin.
+const (
+	ScopeRead   = "read"
+	ScopeUpdate = "update"
+	ScopeAdmin  = "admin"
+)
+
+// apiKeysConfigKey is the config table key holding the JSON list of hashed API keys.
+const apiKeysConfigKey = "api_keys"
+
+// keyPrefix marks docksmith API keys so they are recognizable in configs and logs.
+const keyPrefix = "dsk_"
+
+// defaultKeyCacheTTL bounds how long the server trusts its in-memory key list.
+// Keys are managed by a separate CLI process, so the list must be re-read periodically.
+const defaultKeyCacheTTL = 30 * time.Second
+
+// Sentinel errors
+var (
+	ErrInvalidKey   = errors.New("invalid API key")
+	ErrKeyNotFound  = errors.New("API key not found")
+	ErrInvalidScope = errors.New("invalid scope (must be 'read', 'update', or 'admin')")
+)
+
+// Mode controls how unauthenticated requests are treated.
+type Mode string
+
+const (
+	// ModeDisabled skips authentication entirely.
+	ModeDisabled Mode = "disabled"
+	// ModeOptional allows unauthenticated requests but validates any credentials that are presented.
+	ModeOptional Mode = "optional"
+	// ModeRequired rejects requests that do not carry a valid API key or session.
+	ModeRequired Mode = "required"
+)
+
+// ModeFromEnv reads the authentication mode from DOCKSMITH_AUTH.
+// Unset or unrecognized values fall back to ModeOptional.
+func ModeFromEnv() Mode {
+	switch strings.ToLower(strings.TrimSpace(os.Getenv("DOCKSMITH_AUTH"))) {
+	case "required", "true", "on":
+		return ModeRequired
+	case "disabled", "off", "false":
+		return ModeDisabled
+	default:
+		return ModeOptional
+	}
+}
+
+// APIKey is a stored API key. Only the SHA-256 hash of the secret is persisted.
+type APIKey struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Hash      string    `json:"hash"`
+	Scope     string    `json:"scope"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Role returns the role granted by the key's scope.
+func (k APIKey) Role() Role {
+	switch k.Scope {
+	case ScopeRead:
+		return RoleViewer
+	case ScopeUpdate:
+		return RoleOperator
+	case ScopeAdmin:
+		return RoleAdmin
+	default:
+		return ""
+	}
+}
+
+// Principal returns the request principal for the key.
+func (k APIKey) Principal() *Principal {
+	return &Principal{Kind: "api_key", ID: k.ID, Name: k.Name, Role: k.Role()}
+}
+
+// ValidScope reports whether scope is a known key scope.
+func ValidScope(scope string) bool {
+	return scope == ScopeRead || scope == ScopeUpdate || scope == ScopeAdmin
+}
+
+// HashKey returns the hex-encoded SHA-256 hash of a plaintext key.
+func HashKey(plaintext string) string {
+	sum := sha256.Sum256([]byte(plaintext))
+	return hex.EncodeToString(sum[:])
+}
+
+// KeyStore manages API keys persisted in the config table.
+type KeyStore struct {
+	storage  storage.Storage
+	cacheTTL time.Duration
+
+	mu       sync.Mutex
+	cached   []APIKey
+	loadedAt time.Time
+}
+
+// NewKeyStore creates a key store backed by the given storage.
+func NewKeyStore(store storage.Storage) *KeyStore {
+	return &KeyStore{
+		storage:  store,
+		cacheTTL: defaultKeyCacheTTL,
+	}
+}
+
+// Create generates a new API key, persists its hash, and returns the plaintext
+// secret. The plaintext is never stored and cannot be recovered later.
+func (ks *KeyStore) Create(ctx context.Context, name, scope string) (string, APIKey, error) {
+	if !ValidScope(scope) {
+		return "", APIKey{}, ErrInvalidScope
+	}
+	if strings.TrimSpace(name) == "" {
+		return "", APIKey{}, fmt.Errorf("key name is required")
+	}
+
+	id, err := randomHex(4)
+	if err != nil {
+		return "", APIKey{}, fmt.Errorf("failed to generate key id: %w", err)
+	}
+	secret, err := randomHex(24)
+	if err != nil {
+		return "", APIKey{}, fmt.Errorf("failed to generate key secret: %w", err)
+	}
+	plaintext := keyPrefix + id + "_" + secret
+
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+
+	keys, err := ks.loadLocked(ctx)
+	if err != nil {
+		return "", APIKey{}, err
+	}
+
+	key := APIKey{
+		ID:        id,
+		Name:      name,
+		Hash:      HashKey(plaintext),
+		Scope:     scope,
+		CreatedAt: time.Now().UTC(),
+	}
+	keys = append(keys, key)
+
+	if err := ks.saveLocked(ctx, keys); err != nil {
+		return "", APIKey{}, err
+	}
+	return plaintext, key, nil
+}
+
+// Revoke deletes the key with the given ID.
+func (ks *KeyStore) Revoke(ctx context.Context, id string) error {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+
+	keys, err := ks.loadLocked(ctx)
+	if err != nil {
+		return err
+	}
+
+	remaining := make([]APIKey, 0, len(keys))
+	for _, k := range keys {
+		if k.ID != id {
+			remaining = append(remaining, k)
+		}
+	}
+	if len(remaining) == len(keys) {
+		return ErrKeyNotFound
+	}
+
+	return ks.saveLocked(ctx, remaining)
+}
+
+// List returns all stored keys (hashes included, secrets never).
+func (ks *KeyStore) List(ctx context.Context) ([]APIKey, error) {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+
+	keys, err := ks.loadLocked(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]APIKey, len(keys))
+	copy(out, keys)
+	return out, nil
+}
+
+// Authenticate resolves a plaintext key to its stored record.
+// Uses the cached key list when fresh to avoid a database read per request.
+func (ks *KeyStore) Authenticate(ctx context.Context, plaintext string) (*APIKey, error) {
+	if !strings.HasPrefix(plaintext, keyPrefix) {
+		return nil, ErrInvalidKey
+	}
+
+	ks.mu.Lock()
+	keys := ks.cached
+	if keys == nil || time.Since(ks.loadedAt) > ks.cacheTTL {
+		var err error
+		keys, err = ks.loadLocked(ctx)
+		if err != nil {
+			ks.mu.Unlock()
+			return nil, err
+		}
+	}
+	ks.mu.Unlock()
+
+	hash := []byte(HashKey(plaintext))
+	for i := range keys {
+		if subtle.ConstantTimeCompare(hash, []byte(keys[i].Hash)) == 1 {
+			key := keys[i]
+			return &key, nil
+		}
+	}
+	return nil, ErrInvalidKey
+}
+
+// HasKeys reports whether any API keys are configured.
+func (ks *KeyStore) HasKeys(ctx context.Context) bool {
+	keys, err := ks.List(ctx)
+	return err == nil && len(keys) > 0
+}
+
+// loadLocked reads keys from storage and refreshes the cache. Caller must hold ks.mu.
+func (ks *KeyStore) loadLocked(ctx context.Context) ([]APIKey, error) {
+	if ks.storage == nil {
+		return nil, fmt.Errorf("storage not available")
+	}
+
+	value, found, err := ks.storage.GetConfig(ctx, apiKeysConfigKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load API keys: %w", err)
+	}
+
+	keys := []APIKey{}
+	if found && value != "" {
+		if err := json.Unmarshal([]byte(value), &keys); err != nil {
+			return nil, fmt.Errorf("failed to parse API keys: %w", err)
+		}
+	}
+
+	ks.cached = keys
+	ks.loadedAt = time.Now()
+	return keys, nil
+}
+
+// saveLocked persists keys and refreshes the cache. Caller must hold ks.mu.
+func (ks *KeyStore) saveLocked(ctx context.Context, keys []APIKey) error {
+	data, err := json.Marshal(keys)
+	if err != nil {
+		return fmt.Errorf("failed to serialize API keys: %w", err)
+	}
+	if err := ks.storage.SetConfig(ctx, apiKeysConfigKey, string(data)); err != nil {
+		return fmt.Errorf("failed to save API keys: %w", err)
+	}
+	ks.cached = keys
+	ks.loadedAt = time.Now()
+	return nil
+}
+
+// randomHex returns n random bytes encoded as hex.
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
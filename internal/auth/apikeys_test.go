@@ -0,0 +1,106 @@
+package auth
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/chis/docksmith/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestKeyStore(t *testing.T) *KeyStore {
+	t.Helper()
+	store, err := storage.NewSQLiteStorage(filepath.Join(t.TempDir(), "test.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { store.Close() })
+	return NewKeyStore(store)
+}
+
+func TestKeyStore_CreateAndAuthenticate(t *testing.T) {
+	ctx := context.Background()
+	ks := newTestKeyStore(t)
+
+	plaintext, key, err := ks.Create(ctx, "ci", ScopeUpdate)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(plaintext, keyPrefix))
+	assert.NotContains(t, key.Hash, plaintext)
+	assert.Equal(t, HashKey(plaintext), key.Hash)
+
+	got, err := ks.Authenticate(ctx, plaintext)
+	require.NoError(t, err)
+	assert.Equal(t, key.ID, got.ID)
+	assert.Equal(t, ScopeUpdate, got.Scope)
+
+	_, err = ks.Authenticate(ctx, plaintext+"x")
+	assert.ErrorIs(t, err, ErrInvalidKey)
+
+	_, err = ks.Authenticate(ctx, "not-a-key")
+	assert.ErrorIs(t, err, ErrInvalidKey)
+}
+
+func TestKeyStore_Revoke(t *testing.T) {
+	ctx := context.Background()
+	ks := newTestKeyStore(t)
+
+	plaintext, key, err := ks.Create(ctx, "dashboard", ScopeRead)
+	require.NoError(t, err)
+
+	require.NoError(t, ks.Revoke(ctx, key.ID))
+
+	_, err = ks.Authenticate(ctx, plaintext)
+	assert.ErrorIs(t, err, ErrInvalidKey)
+
+	assert.ErrorIs(t, ks.Revoke(ctx, key.ID), ErrKeyNotFound)
+}
+
+func TestKeyStore_ListAcrossInstances(t *testing.T) {
+	ctx := context.Background()
+	store, err := storage.NewSQLiteStorage(filepath.Join(t.TempDir(), "test.db"))
+	require.NoError(t, err)
+	defer store.Close()
+
+	// The CLI and server use separate KeyStore instances over the same database
+	_, _, err = NewKeyStore(store).Create(ctx, "a", ScopeRead)
+	require.NoError(t, err)
+	_, _, err = NewKeyStore(store).Create(ctx, "b", ScopeUpdate)
+	require.NoError(t, err)
+
+	keys, err := NewKeyStore(store).List(ctx)
+	require.NoError(t, err)
+	assert.Len(t, keys, 2)
+}
+
+func TestKeyStore_CreateValidation(t *testing.T) {
+	ctx := context.Background()
+	ks := newTestKeyStore(t)
+
+	_, _, err := ks.Create(ctx, "x", "superuser")
+	assert.ErrorIs(t, err, ErrInvalidScope)
+
+	_, _, err = ks.Create(ctx, " ", ScopeRead)
+	assert.Error(t, err)
+}
+
+func TestAPIKey_Role(t *testing.T) {
+	assert.Equal(t, RoleViewer, APIKey{Scope: ScopeRead}.Role())
+	assert.Equal(t, RoleOperator, APIKey{Scope: ScopeUpdate}.Role())
+	assert.Equal(t, RoleAdmin, APIKey{Scope: ScopeAdmin}.Role())
+	assert.False(t, APIKey{}.Role().Allows(RoleViewer))
+}
+
+func TestModeFromEnv(t *testing.T) {
+	tests := map[string]Mode{
+		"":         ModeOptional,
+		"required": ModeRequired,
+		"REQUIRED": ModeRequired,
+		"disabled": ModeDisabled,
+		"bogus":    ModeOptional,
+	}
+	for value, want := range tests {
+		t.Setenv("DOCKSMITH_AUTH", value)
+		assert.Equal(t, want, ModeFromEnv(), "DOCKSMITH_AUTH=%q", value)
+	}
+}
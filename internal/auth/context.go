@@ -0,0 +1,20 @@
+package auth
+
+import "context"
+
+type contextKey string
+
+const principalContextKey contextKey = "principal"
+
+// WithPrincipal returns a copy of ctx carrying the authenticated principal.
+func WithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalContextKey, p)
+}
+
+// PrincipalFromContext returns the authenticated principal, or nil for anonymous requests.
+func PrincipalFromContext(ctx context.Context) *Principal {
+	if p, ok := ctx.Value(principalContextKey).(*Principal); ok {
+		return p
+	}
+	return nil
+}
@@ -0,0 +1,177 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// errJWKSNoKey is returned when no key of the provider's key set matches a token.
+var errJWKSNoKey = errors.New("no matching key in the OIDC provider's key set")
+
+// jwk is a public key of a provider's JSON Web Key Set (RFC 7517).
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	Crv string `json:"crv"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// jwkSet is the document served at a provider's jwks_uri.
+type jwkSet struct {
+	Keys []jwk `json:"keys"`
+}
+
+// publicKey decodes the key. RSA, EC (P-256, P-384, P-521), and Ed25519 keys
+// are supported.
+func (k jwk) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, fmt.Errorf("invalid RSA modulus: %w", err)
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil || len(e) == 0 || len(e) > 4 {
+			return nil, fmt.Errorf("invalid RSA exponent")
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported EC curve %q", k.Crv)
+		}
+		x, errX := base64.RawURLEncoding.DecodeString(k.X)
+		y, errY := base64.RawURLEncoding.DecodeString(k.Y)
+		size := (curve.Params().BitSize + 7) / 8
+		if errX != nil || errY != nil || len(x) != size || len(y) != size {
+			return nil, fmt.Errorf("invalid EC point")
+		}
+		return ecdsa.ParseUncompressedPublicKey(curve, append(append([]byte{4}, x...), y...))
+	case "OKP":
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if k.Crv != "Ed25519" || err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("unsupported OKP key")
+		}
+		return ed25519.PublicKey(x), nil
+	}
+	return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+}
+
+// verifyJWS checks the signature of a compact JWS against the keys of a key set.
+// Only asymmetric algorithms are accepted, so "none" and HMAC tokens are rejected.
+func verifyJWS(token string, keys []jwk) error {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return fmt.Errorf("malformed JWS")
+	}
+	rawHeader, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return fmt.Errorf("malformed JWS header: %w", err)
+	}
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := json.Unmarshal(rawHeader, &header); err != nil {
+		return fmt.Errorf("malformed JWS header: %w", err)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return fmt.Errorf("malformed JWS signature: %w", err)
+	}
+	signed := []byte(parts[0] + "." + parts[1])
+
+	if _, ok := jwsHashes[header.Alg]; !ok && header.Alg != "EdDSA" {
+		return fmt.Errorf("unsupported JWS algorithm %q", header.Alg)
+	}
+
+	matched := false
+	for _, key := range keys {
+		if (header.Kid != "" && key.Kid != header.Kid) || (key.Alg != "" && key.Alg != header.Alg) || key.Use == "enc" {
+			continue
+		}
+		pub, err := key.publicKey()
+		if err != nil {
+			continue
+		}
+		matched = true
+		if verifySignature(header.Alg, pub, signed, signature) {
+			return nil
+		}
+	}
+	if !matched {
+		return errJWKSNoKey
+	}
+	return fmt.Errorf("invalid JWS signature")
+}
+
+// jwsHashes maps the JWS algorithms using a hash function to it.
+var jwsHashes = map[string]crypto.Hash{
+	"RS256": crypto.SHA256, "RS384": crypto.SHA384, "RS512": crypto.SHA512,
+	"PS256": crypto.SHA256, "PS384": crypto.SHA384, "PS512": crypto.SHA512,
+	"ES256": crypto.SHA256, "ES384": crypto.SHA384, "ES512": crypto.SHA512,
+}
+
+// jwsCurves maps the ECDSA JWS algorithms to their curve.
+var jwsCurves = map[string]elliptic.Curve{
+	"ES256": elliptic.P256(), "ES384": elliptic.P384(), "ES512": elliptic.P521(),
+}
+
+// verifySignature reports whether signature is a valid alg signature of signed by pub.
+func verifySignature(alg string, pub crypto.PublicKey, signed, signature []byte) bool {
+	if alg == "EdDSA" {
+		key, ok := pub.(ed25519.PublicKey)
+		return ok && ed25519.Verify(key, signed, signature)
+	}
+
+	hash := jwsHashes[alg]
+	h := hash.New()
+	h.Write(signed)
+	digest := h.Sum(nil)
+
+	switch alg[:2] {
+	case "RS":
+		key, ok := pub.(*rsa.PublicKey)
+		return ok && rsa.VerifyPKCS1v15(key, hash, digest, signature) == nil
+	case "PS":
+		key, ok := pub.(*rsa.PublicKey)
+		return ok && rsa.VerifyPSS(key, hash, digest, signature, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash}) == nil
+	case "ES":
+		key, ok := pub.(*ecdsa.PublicKey)
+		if !ok || key.Curve != jwsCurves[alg] {
+			return false
+		}
+		// The signature is r and s, each padded to the size of the curve
+		size := (key.Curve.Params().BitSize + 7) / 8
+		if len(signature) != 2*size {
+			return false
+		}
+		r := new(big.Int).SetBytes(signature[:size])
+		s := new(big.Int).SetBytes(signature[size:])
+		return ecdsa.Verify(key, digest, r, s)
+	}
+	return false
+}
@@ -0,0 +1,519 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// OIDCStateCookieName is the short-lived cookie holding the login flow state
+// between the redirect to the identity provider and the callback.
+const OIDCStateCookieName = "docksmith_oidc"
+
+// oidcHTTPTimeout bounds discovery, token, and userinfo requests.
+const oidcHTTPTimeout = 10 * time.Second
+
+// Sentinel errors
+var (
+	ErrOIDCState  = errors.New("invalid or expired OIDC login state")
+	ErrOIDCNoRole = errors.New("no docksmith role is mapped to this account's groups")
+)
+
+// OIDCConfig configures OpenID Connect login.
+type OIDCConfig struct {
+	IssuerURL    string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+
+	// UsernameClaim selects the docksmith username (falls back to email, then sub)
+	UsernameClaim string
+	// GroupsClaim names the claim listing the user's groups
+	GroupsClaim string
+
+	// Group to role mapping; the highest matching role wins
+	AdminGroups    []string
+	OperatorGroups []string
+	ViewerGroups   []string
+	// DefaultRole is assigned when no group matches (empty denies login)
+	DefaultRole Role
+}
+
+// OIDCConfigFromEnv reads OIDC settings from the environment.
+// Returns false when OIDC_ISSUER or OIDC_CLIENT_ID is unset.
+func OIDCConfigFromEnv() (OIDCConfig, bool) {
+	cfg := OIDCConfig{
+		IssuerURL:      strings.TrimSpace(os.Getenv("OIDC_ISSUER")),
+		ClientID:       strings.TrimSpace(os.Getenv("OIDC_CLIENT_ID")),
+		ClientSecret:   os.Getenv("OIDC_CLIENT_SECRET"),
+		RedirectURL:    strings.TrimSpace(os.Getenv("OIDC_REDIRECT_URL")),
+		Scopes:         splitList(os.Getenv("OIDC_SCOPES"), " ,"),
+		UsernameClaim:  strings.TrimSpace(os.Getenv("OIDC_USERNAME_CLAIM")),
+		GroupsClaim:    strings.TrimSpace(os.Getenv("OIDC_GROUPS_CLAIM")),
+		AdminGroups:    splitList(os.Getenv("OIDC_ADMIN_GROUPS"), ","),
+		OperatorGroups: splitList(os.Getenv("OIDC_OPERATOR_GROUPS"), ","),
+		ViewerGroups:   splitList(os.Getenv("OIDC_VIEWER_GROUPS"), ","),
+		DefaultRole:    Role(strings.TrimSpace(os.Getenv("OIDC_DEFAULT_ROLE"))),
+	}
+	if cfg.IssuerURL == "" || cfg.ClientID == "" {
+		return OIDCConfig{}, false
+	}
+	return cfg, true
+}
+
+// Validate checks that the issuer URL uses https. Plain http is accepted only
+// for loopback hosts, so ID tokens cannot be forged on the network path.
+func (c OIDCConfig) Validate() error {
+	if err := requireHTTPS(c.IssuerURL); err != nil {
+		return fmt.Errorf("invalid OIDC issuer: %w", err)
+	}
+	return nil
+}
+
+// RoleForGroups returns the highest role mapped to any of the groups,
+// or DefaultRole when none match.
+func (c OIDCConfig) RoleForGroups(groups []string) Role {
+	matches := func(mapped []string) bool {
+		for _, g := range groups {
+			if slices.Contains(mapped, g) {
+				return true
+			}
+		}
+		return false
+	}
+
+	switch {
+	case matches(c.AdminGroups):
+		return RoleAdmin
+	case matches(c.OperatorGroups):
+		return RoleOperator
+	case matches(c.ViewerGroups):
+		return RoleViewer
+	}
+	if c.DefaultRole.rank() > 0 {
+		return c.DefaultRole
+	}
+	return ""
+}
+
+// OIDCIdentity is the verified identity returned by the provider.
+type OIDCIdentity struct {
+	Subject  string
+	Username string
+	Email    string
+	Groups   []string
+}
+
+// OIDCFlow carries the per-login secrets that must survive the redirect to
+// the identity provider. It is stored in an HTTP-only cookie.
+type OIDCFlow struct {
+	State    string
+	Nonce    string
+	Verifier string
+}
+
+// Encode serializes the flow for the state cookie.
+func (f OIDCFlow) Encode() string {
+	return f.State + "." + f.Nonce + "." + f.Verifier
+}
+
+// ParseOIDCFlow parses a state cookie value produced by Encode.
+func ParseOIDCFlow(value string) (OIDCFlow, error) {
+	parts := strings.Split(value, ".")
+	if len(parts) != 3 || parts[0] == "" || parts[1] == "" || parts[2] == "" {
+		return OIDCFlow{}, ErrOIDCState
+	}
+	return OIDCFlow{State: parts[0], Nonce: parts[1], Verifier: parts[2]}, nil
+}
+
+// MatchesState reports whether state equals the flow's state.
+func (f OIDCFlow) MatchesState(state string) bool {
+	return subtle.ConstantTimeCompare([]byte(f.State), []byte(state)) == 1
+}
+
+// oidcDiscovery holds the provider metadata used by docksmith.
+type oidcDiscovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	UserinfoEndpoint      string `json:"userinfo_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// OIDCProvider performs the authorization code flow (with PKCE) against an
+// OpenID Connect provider such as Authelia, Keycloak, or Google.
+type OIDCProvider struct {
+	cfg    OIDCConfig
+	client *http.Client
+
+	mu        sync.Mutex
+	discovery *oidcDiscovery
+	keys      []jwk
+}
+
+// NewOIDCProvider creates a provider. Discovery happens lazily on first use so
+// an unreachable identity provider does not block startup.
+func NewOIDCProvider(cfg OIDCConfig) *OIDCProvider {
+	if len(cfg.Scopes) == 0 {
+		cfg.Scopes = []string{"openid", "profile", "email", "groups"}
+	}
+	if !slices.Contains(cfg.Scopes, "openid") {
+		cfg.Scopes = append([]string{"openid"}, cfg.Scopes...)
+	}
+	if cfg.UsernameClaim == "" {
+		cfg.UsernameClaim = "preferred_username"
+	}
+	if cfg.GroupsClaim == "" {
+		cfg.GroupsClaim = "groups"
+	}
+	cfg.IssuerURL = strings.TrimSuffix(cfg.IssuerURL, "/")
+
+	return &OIDCProvider{
+		cfg:    cfg,
+		client: &http.Client{Timeout: oidcHTTPTimeout},
+	}
+}
+
+// Config returns the provider configuration.
+func (p *OIDCProvider) Config() OIDCConfig {
+	return p.cfg
+}
+
+// BeginLogin starts a login flow and returns the provider URL to redirect to.
+// redirectURL is used when OIDC_REDIRECT_URL is not configured.
+func (p *OIDCProvider) BeginLogin(ctx context.Context, redirectURL string) (string, OIDCFlow, error) {
+	disc, err := p.discover(ctx)
+	if err != nil {
+		return "", OIDCFlow{}, err
+	}
+
+	var flow OIDCFlow
+	for _, field := range []*string{&flow.State, &flow.Nonce, &flow.Verifier} {
+		if *field, err = randomHex(32); err != nil {
+			return "", OIDCFlow{}, fmt.Errorf("failed to generate OIDC state: %w", err)
+		}
+	}
+
+	challenge := sha256.Sum256([]byte(flow.Verifier))
+	params := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {p.cfg.ClientID},
+		"redirect_uri":          {p.redirectURL(redirectURL)},
+		"scope":                 {strings.Join(p.cfg.Scopes, " ")},
+		"state":                 {flow.State},
+		"nonce":                 {flow.Nonce},
+		"code_challenge":        {base64.RawURLEncoding.EncodeToString(challenge[:])},
+		"code_challenge_method": {"S256"},
+	}
+
+	sep := "?"
+	if strings.Contains(disc.AuthorizationEndpoint, "?") {
+		sep = "&"
+	}
+	return disc.AuthorizationEndpoint + sep + params.Encode(), flow, nil
+}
+
+// CompleteLogin exchanges the authorization code and validates the ID token.
+// redirectURL must match the value passed to BeginLogin.
+func (p *OIDCProvider) CompleteLogin(ctx context.Context, flow OIDCFlow, code, redirectURL string) (*OIDCIdentity, error) {
+	disc, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {p.redirectURL(redirectURL)},
+		"client_id":     {p.cfg.ClientID},
+		"code_verifier": {flow.Verifier},
+	}
+	if p.cfg.ClientSecret != "" {
+		form.Set("client_secret", p.cfg.ClientSecret)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, disc.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	var token struct {
+		AccessToken string `json:"access_token"`
+		IDToken     string `json:"id_token"`
+	}
+	if err := p.doJSON(req, &token); err != nil {
+		return nil, fmt.Errorf("OIDC token exchange failed: %w", err)
+	}
+	if token.IDToken == "" {
+		return nil, fmt.Errorf("OIDC token response did not include an id_token")
+	}
+
+	if err := p.verifyIDTokenSignature(ctx, disc, token.IDToken); err != nil {
+		return nil, err
+	}
+	claims, err := p.validateIDToken(token.IDToken, disc.Issuer, flow.Nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	// Many providers only release groups and profile claims via userinfo
+	if disc.UserinfoEndpoint != "" && token.AccessToken != "" &&
+		(claims[p.cfg.GroupsClaim] == nil || claims[p.cfg.UsernameClaim] == nil) {
+		userinfo, err := p.fetchUserinfo(ctx, disc.UserinfoEndpoint, token.AccessToken)
+		if err != nil {
+			return nil, err
+		}
+		if sub, _ := userinfo["sub"].(string); sub != claims["sub"] {
+			return nil, fmt.Errorf("OIDC userinfo subject does not match ID token")
+		}
+		for k, v := range userinfo {
+			if _, exists := claims[k]; !exists {
+				claims[k] = v
+			}
+		}
+	}
+
+	return p.identityFromClaims(claims), nil
+}
+
+// verifyIDTokenSignature checks the ID token's signature against the provider's
+// key set. The key set is fetched again when no key matches, as after a key rotation.
+func (p *OIDCProvider) verifyIDTokenSignature(ctx context.Context, disc *oidcDiscovery, idToken string) error {
+	keys, err := p.signingKeys(ctx, disc, false)
+	if err != nil {
+		return err
+	}
+	err = verifyJWS(idToken, keys)
+	if errors.Is(err, errJWKSNoKey) {
+		if keys, err = p.signingKeys(ctx, disc, true); err != nil {
+			return err
+		}
+		err = verifyJWS(idToken, keys)
+	}
+	if err != nil {
+		return fmt.Errorf("OIDC id_token signature verification failed: %w", err)
+	}
+	return nil
+}
+
+// signingKeys returns the provider's key set, fetching it when not cached or refresh is set.
+func (p *OIDCProvider) signingKeys(ctx context.Context, disc *oidcDiscovery, refresh bool) ([]jwk, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.keys != nil && !refresh {
+		return p.keys, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, disc.JWKSURI, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	var set jwkSet
+	if err := p.doJSON(req, &set); err != nil {
+		return nil, fmt.Errorf("OIDC key set request failed: %w", err)
+	}
+	p.keys = set.Keys
+	return p.keys, nil
+}
+
+// validateIDToken checks the ID token's issuer, audience, expiry, and nonce.
+// The signature is checked by verifyIDTokenSignature.
+func (p *OIDCProvider) validateIDToken(idToken, issuer, nonce string) (map[string]any, error) {
+	parts := strings.Split(idToken, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("malformed OIDC id_token")
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("malformed OIDC id_token payload: %w", err)
+	}
+
+	claims := map[string]any{}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, fmt.Errorf("malformed OIDC id_token claims: %w", err)
+	}
+
+	if iss, _ := claims["iss"].(string); iss != issuer {
+		return nil, fmt.Errorf("OIDC id_token issuer %q does not match %q", iss, issuer)
+	}
+	if !slices.Contains(stringsClaim(claims["aud"]), p.cfg.ClientID) {
+		return nil, fmt.Errorf("OIDC id_token audience does not include client %q", p.cfg.ClientID)
+	}
+	exp, _ := claims["exp"].(float64)
+	if time.Now().After(time.Unix(int64(exp), 0)) {
+		return nil, fmt.Errorf("OIDC id_token has expired")
+	}
+	if got, _ := claims["nonce"].(string); subtle.ConstantTimeCompare([]byte(got), []byte(nonce)) != 1 {
+		return nil, fmt.Errorf("OIDC id_token nonce mismatch")
+	}
+	if sub, _ := claims["sub"].(string); sub == "" {
+		return nil, fmt.Errorf("OIDC id_token has no subject")
+	}
+
+	return claims, nil
+}
+
+// identityFromClaims extracts the docksmith identity from validated claims.
+func (p *OIDCProvider) identityFromClaims(claims map[string]any) *OIDCIdentity {
+	identity := &OIDCIdentity{
+		Groups: stringsClaim(claims[p.cfg.GroupsClaim]),
+	}
+	identity.Subject, _ = claims["sub"].(string)
+	identity.Email, _ = claims["email"].(string)
+	identity.Username, _ = claims[p.cfg.UsernameClaim].(string)
+
+	if identity.Username == "" {
+		identity.Username = identity.Email
+	}
+	if identity.Username == "" {
+		identity.Username = identity.Subject
+	}
+	return identity
+}
+
+// fetchUserinfo retrieves claims from the userinfo endpoint.
+func (p *OIDCProvider) fetchUserinfo(ctx context.Context, endpoint, accessToken string) (map[string]any, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	req.Header.Set("Accept", "application/json")
+
+	claims := map[string]any{}
+	if err := p.doJSON(req, &claims); err != nil {
+		return nil, fmt.Errorf("OIDC userinfo request failed: %w", err)
+	}
+	return claims, nil
+}
+
+// discover fetches and caches the provider's metadata document.
+func (p *OIDCProvider) discover(ctx context.Context) (*oidcDiscovery, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.discovery != nil {
+		return p.discovery, nil
+	}
+	if err := p.cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.cfg.IssuerURL+"/.well-known/openid-configuration", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var disc oidcDiscovery
+	if err := p.doJSON(req, &disc); err != nil {
+		return nil, fmt.Errorf("OIDC discovery failed: %w", err)
+	}
+	if strings.TrimSuffix(disc.Issuer, "/") != p.cfg.IssuerURL {
+		return nil, fmt.Errorf("OIDC discovery issuer %q does not match configured issuer %q", disc.Issuer, p.cfg.IssuerURL)
+	}
+	if disc.AuthorizationEndpoint == "" || disc.TokenEndpoint == "" || disc.JWKSURI == "" {
+		return nil, fmt.Errorf("OIDC discovery document is missing required endpoints")
+	}
+	for _, endpoint := range []string{disc.AuthorizationEndpoint, disc.TokenEndpoint, disc.UserinfoEndpoint, disc.JWKSURI} {
+		if endpoint == "" {
+			continue
+		}
+		if err := requireHTTPS(endpoint); err != nil {
+			return nil, fmt.Errorf("invalid OIDC discovery endpoint: %w", err)
+		}
+	}
+
+	p.discovery = &disc
+	return p.discovery, nil
+}
+
+// doJSON performs req and decodes a JSON response body into out.
+func (p *OIDCProvider) doJSON(req *http.Request, out any) error {
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// redirectURL returns the configured callback URL, or fallback when unset.
+func (p *OIDCProvider) redirectURL(fallback string) string {
+	if p.cfg.RedirectURL != "" {
+		return p.cfg.RedirectURL
+	}
+	return fallback
+}
+
+// requireHTTPS returns an error unless raw is an https URL, or an http URL of a
+// loopback host (a provider on the same machine, or in tests).
+func requireHTTPS(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return fmt.Errorf("%q is not an absolute URL", raw)
+	}
+	switch u.Scheme {
+	case "https":
+		return nil
+	case "http":
+		host := u.Hostname()
+		if ip := net.ParseIP(host); host == "localhost" || (ip != nil && ip.IsLoopback()) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%s must use https", raw)
+}
+
+// stringsClaim normalizes a claim that may be a string or a list of strings.
+func stringsClaim(v any) []string {
+	switch val := v.(type) {
+	case string:
+		return []string{val}
+	case []any:
+		out := make([]string, 0, len(val))
+		for _, item := range val {
+			if s, ok := item.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	default:
+		return nil
+	}
+}
+
+// splitList splits s on any of the separator characters, dropping empty items.
+func splitList(s, seps string) []string {
+	fields := strings.FieldsFunc(s, func(r rune) bool {
+		return strings.ContainsRune(seps, r)
+	})
+	out := make([]string, 0, len(fields))
+	for _, f := range fields {
+		if f = strings.TrimSpace(f); f != "" {
+			out = append(out, f)
+		}
+	}
+	return out
+}
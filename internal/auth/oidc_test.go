@@ -0,0 +1,351 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeIdP is a minimal OIDC provider issuing ES256-signed ID tokens.
+type fakeIdP struct {
+	server   *httptest.Server
+	claims   map[string]any
+	userinfo map[string]any
+	verifier string
+
+	// key signs ID tokens and is published as kid; sign overrides the signing
+	kid  string
+	key  *ecdsa.PrivateKey
+	sign func(header, payload string) string
+}
+
+func newFakeIdP(t *testing.T) *fakeIdP {
+	t.Helper()
+	idp := &fakeIdP{kid: "key-1", key: newECKey(t)}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 idp.server.URL,
+			"authorization_endpoint": idp.server.URL + "/authorize",
+			"token_endpoint":         idp.server.URL + "/token",
+			"userinfo_endpoint":      idp.server.URL + "/userinfo",
+			"jwks_uri":               idp.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("GET /jwks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]any{"keys": []jwk{ecJWK(idp.kid, &idp.key.PublicKey)}})
+	})
+	mux.HandleFunc("POST /token", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		idp.verifier = r.PostForm.Get("code_verifier")
+		claims, _ := json.Marshal(idp.claims)
+		header, _ := json.Marshal(map[string]string{"alg": "ES256", "kid": idp.kid})
+		h, payload := base64.RawURLEncoding.EncodeToString(header), base64.RawURLEncoding.EncodeToString(claims)
+		signature := signES256(idp.key, h+"."+payload)
+		if idp.sign != nil {
+			signature = idp.sign(h, payload)
+		}
+		json.NewEncoder(w).Encode(map[string]string{
+			"access_token": "access",
+			"id_token":     h + "." + payload + "." + signature,
+		})
+	})
+	mux.HandleFunc("GET /userinfo", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(idp.userinfo)
+	})
+	idp.server = httptest.NewServer(mux)
+	t.Cleanup(idp.server.Close)
+	return idp
+}
+
+func newECKey(t *testing.T) *ecdsa.PrivateKey {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	return key
+}
+
+func ecJWK(kid string, pub *ecdsa.PublicKey) jwk {
+	return jwk{
+		Kty: "EC", Kid: kid, Crv: "P-256",
+		X: base64.RawURLEncoding.EncodeToString(pub.X.FillBytes(make([]byte, 32))),
+		Y: base64.RawURLEncoding.EncodeToString(pub.Y.FillBytes(make([]byte, 32))),
+	}
+}
+
+// signES256 returns the base64url ES256 signature of signed.
+func signES256(key *ecdsa.PrivateKey, signed string) string {
+	digest := sha256.Sum256([]byte(signed))
+	r, s, _ := ecdsa.Sign(rand.Reader, key, digest[:])
+	signature := append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+	return base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func (idp *fakeIdP) provider() *OIDCProvider {
+	return NewOIDCProvider(OIDCConfig{
+		IssuerURL:      idp.server.URL,
+		ClientID:       "docksmith",
+		AdminGroups:    []string{"ops-admins"},
+		OperatorGroups: []string{"ops"},
+	})
+}
+
+func (idp *fakeIdP) setClaims(nonce string, extra map[string]any) {
+	idp.claims = map[string]any{
+		"iss":   idp.server.URL,
+		"aud":   "docksmith",
+		"sub":   "user-123",
+		"exp":   time.Now().Add(time.Hour).Unix(),
+		"nonce": nonce,
+	}
+	for k, v := range extra {
+		idp.claims[k] = v
+	}
+}
+
+func TestOIDCProvider_LoginFlow(t *testing.T) {
+	ctx := context.Background()
+	idp := newFakeIdP(t)
+	p := idp.provider()
+
+	authURL, flow, err := p.BeginLogin(ctx, "http://localhost:8080/api/auth/oidc/callback")
+	require.NoError(t, err)
+
+	u, err := url.Parse(authURL)
+	require.NoError(t, err)
+	assert.Equal(t, flow.State, u.Query().Get("state"))
+	assert.Equal(t, flow.Nonce, u.Query().Get("nonce"))
+	assert.Equal(t, "S256", u.Query().Get("code_challenge_method"))
+	assert.Contains(t, u.Query().Get("scope"), "openid")
+
+	idp.setClaims(flow.Nonce, map[string]any{
+		"preferred_username": "alice",
+		"groups":             []string{"ops"},
+	})
+
+	identity, err := p.CompleteLogin(ctx, flow, "code", "http://localhost:8080/api/auth/oidc/callback")
+	require.NoError(t, err)
+	assert.Equal(t, flow.Verifier, idp.verifier)
+	assert.Equal(t, "alice", identity.Username)
+	assert.Equal(t, []string{"ops"}, identity.Groups)
+	assert.Equal(t, RoleOperator, p.Config().RoleForGroups(identity.Groups))
+}
+
+func TestOIDCProvider_UserinfoFallback(t *testing.T) {
+	ctx := context.Background()
+	idp := newFakeIdP(t)
+	p := idp.provider()
+
+	_, flow, err := p.BeginLogin(ctx, "http://localhost/cb")
+	require.NoError(t, err)
+
+	idp.setClaims(flow.Nonce, nil)
+	idp.userinfo = map[string]any{
+		"sub":    "user-123",
+		"email":  "bob@example.com",
+		"groups": []string{"ops-admins"},
+	}
+
+	identity, err := p.CompleteLogin(ctx, flow, "code", "http://localhost/cb")
+	require.NoError(t, err)
+	assert.Equal(t, "bob@example.com", identity.Username)
+	assert.Equal(t, RoleAdmin, p.Config().RoleForGroups(identity.Groups))
+}
+
+func TestOIDCProvider_RejectsInvalidIDToken(t *testing.T) {
+	ctx := context.Background()
+	idp := newFakeIdP(t)
+	p := idp.provider()
+
+	tests := map[string]map[string]any{
+		"wrong nonce":    {"nonce": "other"},
+		"wrong audience": {"aud": []string{"someone-else"}},
+		"wrong issuer":   {"iss": "https://evil.example.com"},
+		"expired":        {"exp": time.Now().Add(-time.Minute).Unix()},
+	}
+
+	for name, override := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, flow, err := p.BeginLogin(ctx, "http://localhost/cb")
+			require.NoError(t, err)
+			idp.setClaims(flow.Nonce, override)
+
+			_, err = p.CompleteLogin(ctx, flow, "code", "http://localhost/cb")
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestOIDCProvider_RejectsUnverifiedIDToken(t *testing.T) {
+	ctx := context.Background()
+
+	tests := map[string]func(idp *fakeIdP) func(header, payload string) string{
+		"unsigned": func(idp *fakeIdP) func(header, payload string) string {
+			return func(header, payload string) string { return "" }
+		},
+		"tampered": func(idp *fakeIdP) func(header, payload string) string {
+			return func(header, payload string) string {
+				forged, _ := json.Marshal(map[string]any{"sub": "admin"})
+				return signES256(idp.key, header+"."+base64.RawURLEncoding.EncodeToString(forged))
+			}
+		},
+		"other key": func(idp *fakeIdP) func(header, payload string) string {
+			other := newECKey(t)
+			return func(header, payload string) string { return signES256(other, header+"."+payload) }
+		},
+	}
+
+	for name, sign := range tests {
+		t.Run(name, func(t *testing.T) {
+			idp := newFakeIdP(t)
+			idp.sign = sign(idp)
+			p := idp.provider()
+			_, flow, err := p.BeginLogin(ctx, "http://localhost/cb")
+			require.NoError(t, err)
+			idp.setClaims(flow.Nonce, nil)
+
+			_, err = p.CompleteLogin(ctx, flow, "code", "http://localhost/cb")
+			assert.ErrorContains(t, err, "signature")
+		})
+	}
+}
+
+func TestOIDCProvider_KeyRotation(t *testing.T) {
+	ctx := context.Background()
+	idp := newFakeIdP(t)
+	p := idp.provider()
+
+	login := func() error {
+		_, flow, err := p.BeginLogin(ctx, "http://localhost/cb")
+		require.NoError(t, err)
+		idp.setClaims(flow.Nonce, map[string]any{"preferred_username": "alice", "groups": []string{"ops"}})
+		_, err = p.CompleteLogin(ctx, flow, "code", "http://localhost/cb")
+		return err
+	}
+	require.NoError(t, login())
+
+	// A token signed with a new key fetches the key set again
+	idp.kid, idp.key = "key-2", newECKey(t)
+	assert.NoError(t, login())
+}
+
+func TestOIDCProvider_RequiresHTTPS(t *testing.T) {
+	ctx := context.Background()
+
+	cfg := OIDCConfig{IssuerURL: "http://idp.example.com", ClientID: "docksmith"}
+	assert.Error(t, cfg.Validate())
+	_, _, err := NewOIDCProvider(cfg).BeginLogin(ctx, "http://localhost/cb")
+	assert.ErrorContains(t, err, "must use https")
+
+	for _, issuer := range []string{"https://idp.example.com", "http://localhost:9091", "http://127.0.0.1:9091"} {
+		cfg.IssuerURL = issuer
+		assert.NoError(t, cfg.Validate(), issuer)
+	}
+
+	// Endpoints from discovery must use https too
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 "http://" + r.Host,
+			"authorization_endpoint": "http://" + r.Host + "/authorize",
+			"token_endpoint":         "http://idp.example.com/token",
+			"jwks_uri":               "http://" + r.Host + "/jwks",
+		})
+	}))
+	defer server.Close()
+	_, _, err = NewOIDCProvider(OIDCConfig{IssuerURL: server.URL, ClientID: "docksmith"}).BeginLogin(ctx, "http://localhost/cb")
+	assert.ErrorContains(t, err, "must use https")
+}
+
+func TestVerifyJWS_Algorithms(t *testing.T) {
+	header := func(alg string) string {
+		h, _ := json.Marshal(map[string]string{"alg": alg, "kid": "k"})
+		return base64.RawURLEncoding.EncodeToString(h) + ".e30"
+	}
+	b64 := base64.RawURLEncoding.EncodeToString
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	rsaJWK := jwk{Kty: "RSA", Kid: "k", N: b64(rsaKey.N.Bytes()), E: b64(big.NewInt(int64(rsaKey.E)).Bytes())}
+	signed := header("RS256")
+	digest := sha256.Sum256([]byte(signed))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, rsaKey, crypto.SHA256, digest[:])
+	require.NoError(t, err)
+	assert.NoError(t, verifyJWS(signed+"."+b64(signature), []jwk{rsaJWK}))
+
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	edJWK := jwk{Kty: "OKP", Kid: "k", Crv: "Ed25519", X: b64(pub)}
+	signed = header("EdDSA")
+	assert.NoError(t, verifyJWS(signed+"."+b64(ed25519.Sign(priv, []byte(signed))), []jwk{edJWK}))
+
+	// An RSA signature is not accepted under another algorithm or key type
+	assert.Error(t, verifyJWS(header("PS256")+"."+b64(signature), []jwk{rsaJWK}))
+	assert.Error(t, verifyJWS(header("RS256")+"."+b64(signature), []jwk{edJWK}))
+
+	// Symmetric and unsigned tokens are rejected
+	assert.ErrorContains(t, verifyJWS(header("HS256")+".c2ln", []jwk{rsaJWK}), "unsupported")
+	assert.ErrorContains(t, verifyJWS(header("none")+".", []jwk{rsaJWK}), "unsupported")
+}
+
+func TestOIDCConfig_RoleForGroups(t *testing.T) {
+	cfg := OIDCConfig{
+		AdminGroups:    []string{"admins"},
+		OperatorGroups: []string{"ops"},
+		ViewerGroups:   []string{"staff"},
+	}
+
+	assert.Equal(t, RoleAdmin, cfg.RoleForGroups([]string{"staff", "admins"}))
+	assert.Equal(t, RoleViewer, cfg.RoleForGroups([]string{"staff"}))
+	assert.Equal(t, Role(""), cfg.RoleForGroups([]string{"guests"}))
+
+	cfg.DefaultRole = RoleViewer
+	assert.Equal(t, RoleViewer, cfg.RoleForGroups(nil))
+}
+
+func TestOIDCFlow_EncodeParse(t *testing.T) {
+	flow := OIDCFlow{State: "s", Nonce: "n", Verifier: "v"}
+	parsed, err := ParseOIDCFlow(flow.Encode())
+	require.NoError(t, err)
+	assert.Equal(t, flow, parsed)
+	assert.True(t, parsed.MatchesState("s"))
+	assert.False(t, parsed.MatchesState("x"))
+
+	_, err = ParseOIDCFlow("garbage")
+	assert.ErrorIs(t, err, ErrOIDCState)
+}
+
+func TestUserService_SyncExternalUser(t *testing.T) {
+	ctx := context.Background()
+	users := newTestUserService(t)
+
+	user, err := users.SyncExternalUser(ctx, "sso-user", RoleViewer)
+	require.NoError(t, err)
+	assert.Empty(t, user.PasswordHash)
+
+	// Role follows the identity provider on each login
+	user, err = users.SyncExternalUser(ctx, "sso-user", RoleAdmin)
+	require.NoError(t, err)
+	assert.Equal(t, string(RoleAdmin), user.Role)
+
+	// Password users cannot be claimed through SSO
+	_, err = users.CreateUser(ctx, "local", "password123", RoleAdmin)
+	require.NoError(t, err)
+	_, err = users.SyncExternalUser(ctx, "local", RoleAdmin)
+	assert.ErrorIs(t, err, ErrUserExists)
+}
@@ -0,0 +1,207 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/chis/docksmith/internal/storage"
+)
+
+// Owner kinds, the prefixes of an owner such as "team:media"
+const (
+	OwnerUser   = "user"
+	OwnerAPIKey = "api_key"
+	OwnerTeam   = "team"
+)
+
+// ErrInvalidOwner is returned for owners not of the form kind:name.
+var ErrInvalidOwner = errors.New(`owner must be "user:<name>", "api_key:<name>", or "team:<name>"`)
+
+// teamNamePattern restricts team names to what fits in a URL path segment.
+var teamNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// ParseOwner validates an owner and returns its kind and name.
+func ParseOwner(owner string) (kind, name string, err error) {
+	kind, name, ok := strings.Cut(strings.TrimSpace(owner), ":")
+	if !ok || name == "" {
+		return "", "", ErrInvalidOwner
+	}
+	switch kind {
+	case OwnerUser, OwnerAPIKey:
+		return kind, name, nil
+	case OwnerTeam:
+		if err := ValidateTeamName(name); err != nil {
+			return "", "", err
+		}
+		return kind, name, nil
+	default:
+		return "", "", ErrInvalidOwner
+	}
+}
+
+// ValidateTeamName checks that a team name is usable in owners and URLs.
+func ValidateTeamName(team string) error {
+	if !teamNamePattern.MatchString(team) {
+		return fmt.Errorf("invalid team name %q (letters, digits, '.', '_', and '-' only)", team)
+	}
+	return nil
+}
+
+// OwnershipScope is the set of stacks and containers a principal may see and
+// operate on. A nil OwnershipScope allows everything.
+type OwnershipScope struct {
+	owned    map[string]bool // Owned entities, keyed by entityKey
+	assigned map[string]bool // Entities with any owner, keyed by entityKey
+}
+
+// entityKey identifies a stack or container in an OwnershipScope.
+func entityKey(entityType, entityID string) string {
+	return entityType + "/" + entityID
+}
+
+// Allows reports whether a container of a stack is in the scope. Either may be
+// empty, e.g. for a standalone container or a whole stack. An owner of a
+// container takes precedence over the owners of its stack, and stacks and
+// containers without owners are shared.
+func (s *OwnershipScope) Allows(stack, container string) bool {
+	if s == nil {
+		return true
+	}
+	if container != "" {
+		if key := entityKey(storage.OwnershipContainer, container); s.assigned[key] {
+			return s.owned[key]
+		}
+	}
+	if stack != "" {
+		if key := entityKey(storage.OwnershipStack, stack); s.assigned[key] {
+			return s.owned[key]
+		}
+	}
+	return true
+}
+
+// OwnershipService assigns stacks and containers to owners and resolves the
+// scope of a principal.
+type OwnershipService struct {
+	storage storage.Storage
+}
+
+// NewOwnershipService creates an ownership service backed by the given storage.
+func NewOwnershipService(store storage.Storage) *OwnershipService {
+	return &OwnershipService{storage: store}
+}
+
+// ScopeFor returns the scope of a principal. Admins, requests without a
+// principal (authentication disabled, or no users or API keys yet), and everyone
+// while nothing has an owner are unrestricted (a nil scope). Users own what is
+// assigned to them and to their teams, API keys what is assigned to them, and
+// AnonymousPrincipal nothing, so it only sees what is shared.
+func (o *OwnershipService) ScopeFor(ctx context.Context, p *Principal) (*OwnershipScope, error) {
+	if o == nil || o.storage == nil || p == nil || p.Role.Allows(RoleAdmin) {
+		return nil, nil
+	}
+
+	ownerships, err := o.storage.ListOwnerships(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load ownership: %w", err)
+	}
+	if len(ownerships) == 0 {
+		return nil, nil
+	}
+
+	owners := map[string]bool{p.Kind + ":" + p.Name: true}
+	if p.Kind == OwnerUser {
+		members, err := o.storage.ListTeamMembers(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load teams: %w", err)
+		}
+		for _, member := range members {
+			if member.Username == p.Name {
+				owners[OwnerTeam+":"+member.Team] = true
+			}
+		}
+	}
+
+	scope := &OwnershipScope{owned: make(map[string]bool), assigned: make(map[string]bool)}
+	for _, ownership := range ownerships {
+		key := entityKey(ownership.EntityType, ownership.EntityID)
+		scope.assigned[key] = true
+		if owners[ownership.Owner] {
+			scope.owned[key] = true
+		}
+	}
+	return scope, nil
+}
+
+// List returns all ownership assignments.
+func (o *OwnershipService) List(ctx context.Context) ([]storage.Ownership, error) {
+	return o.storage.ListOwnerships(ctx)
+}
+
+// Assign adds an owner to a stack or container.
+func (o *OwnershipService) Assign(ctx context.Context, entityType, entityID, owner string) error {
+	if entityType != storage.OwnershipStack && entityType != storage.OwnershipContainer {
+		return fmt.Errorf("invalid entity type %q (must be stack or container)", entityType)
+	}
+	if strings.TrimSpace(entityID) == "" {
+		return fmt.Errorf("%s name is required", entityType)
+	}
+	kind, name, err := ParseOwner(owner)
+	if err != nil {
+		return err
+	}
+	if kind == OwnerUser {
+		if _, found, err := o.storage.GetUser(ctx, name); err != nil {
+			return err
+		} else if !found {
+			return ErrUserNotFound
+		}
+	}
+
+	return o.storage.AddOwnership(ctx, storage.Ownership{
+		EntityType: entityType,
+		EntityID:   entityID,
+		Owner:      kind + ":" + name,
+	})
+}
+
+// Unassign removes an owner from a stack or container.
+// Returns false if it was not an owner.
+func (o *OwnershipService) Unassign(ctx context.Context, entityType, entityID, owner string) (bool, error) {
+	return o.storage.RemoveOwnership(ctx, entityType, entityID, strings.TrimSpace(owner))
+}
+
+// Teams returns the members of each team, keyed by team name.
+func (o *OwnershipService) Teams(ctx context.Context) (map[string][]string, error) {
+	members, err := o.storage.ListTeamMembers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	teams := make(map[string][]string)
+	for _, member := range members {
+		teams[member.Team] = append(teams[member.Team], member.Username)
+	}
+	return teams, nil
+}
+
+// AddTeamMember adds an existing user to a team.
+func (o *OwnershipService) AddTeamMember(ctx context.Context, team, username string) error {
+	if err := ValidateTeamName(team); err != nil {
+		return err
+	}
+	if _, found, err := o.storage.GetUser(ctx, username); err != nil {
+		return err
+	} else if !found {
+		return ErrUserNotFound
+	}
+	return o.storage.AddTeamMember(ctx, team, username)
+}
+
+// RemoveTeamMember removes a user from a team.
+// Returns false if the user was not a member.
+func (o *OwnershipService) RemoveTeamMember(ctx context.Context, team, username string) (bool, error) {
+	return o.storage.RemoveTeamMember(ctx, team, username)
+}
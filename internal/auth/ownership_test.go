@@ -0,0 +1,84 @@
+package auth
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/chis/docksmith/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseOwner(t *testing.T) {
+	kind, name, err := ParseOwner("team:media-admins")
+	require.NoError(t, err)
+	assert.Equal(t, OwnerTeam, kind)
+	assert.Equal(t, "media-admins", name)
+
+	for _, owner := range []string{"", "alice", "user:", "group:media", "team:has space"} {
+		_, _, err := ParseOwner(owner)
+		assert.Error(t, err, owner)
+	}
+}
+
+func TestOwnershipService_ScopeFor(t *testing.T) {
+	ctx := context.Background()
+	store, err := storage.NewSQLiteStorage(filepath.Join(t.TempDir(), "test.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { store.Close() })
+	users := NewUserService(store)
+	ownership := NewOwnershipService(store)
+
+	alice := &Principal{Kind: OwnerUser, Name: "alice", Role: RoleOperator}
+	bob := &Principal{Kind: OwnerUser, Name: "bob", Role: RoleViewer}
+	ci := &Principal{Kind: OwnerAPIKey, Name: "ci", Role: RoleOperator}
+	admin := &Principal{Kind: OwnerUser, Name: "root", Role: RoleAdmin}
+
+	// Nothing is restricted until something has an owner
+	scope, err := ownership.ScopeFor(ctx, alice)
+	require.NoError(t, err)
+	assert.Nil(t, scope)
+
+	for _, name := range []string{"alice", "bob"} {
+		_, err := users.CreateUser(ctx, name, "password123", RoleViewer)
+		require.NoError(t, err)
+	}
+	require.NoError(t, ownership.AddTeamMember(ctx, "family", "bob"))
+	assert.ErrorIs(t, ownership.AddTeamMember(ctx, "family", "nobody"), ErrUserNotFound)
+
+	require.NoError(t, ownership.Assign(ctx, storage.OwnershipStack, "media", "user:alice"))
+	require.NoError(t, ownership.Assign(ctx, storage.OwnershipStack, "media", "team:family"))
+	require.NoError(t, ownership.Assign(ctx, storage.OwnershipContainer, "plex", "api_key:ci"))
+	assert.ErrorIs(t, ownership.Assign(ctx, storage.OwnershipStack, "infra", "user:nobody"), ErrUserNotFound)
+	assert.Error(t, ownership.Assign(ctx, "host", "infra", "user:alice"))
+
+	scope, err = ownership.ScopeFor(ctx, alice)
+	require.NoError(t, err)
+	assert.True(t, scope.Allows("media", "sonarr"))
+	assert.True(t, scope.Allows("media", ""))
+	assert.False(t, scope.Allows("media", "plex"), "container owners take precedence over stack owners")
+	assert.True(t, scope.Allows("infra", "traefik"), "unassigned stacks are shared")
+	assert.True(t, scope.Allows("", "watchtower"))
+
+	scope, err = ownership.ScopeFor(ctx, bob)
+	require.NoError(t, err)
+	assert.True(t, scope.Allows("media", "sonarr"), "team members own the team's stacks")
+
+	scope, err = ownership.ScopeFor(ctx, ci)
+	require.NoError(t, err)
+	assert.True(t, scope.Allows("media", "plex"))
+	assert.False(t, scope.Allows("media", "sonarr"))
+
+	scope, err = ownership.ScopeFor(ctx, admin)
+	require.NoError(t, err)
+	assert.Nil(t, scope)
+	assert.True(t, scope.Allows("media", "plex"))
+
+	removed, err := ownership.RemoveTeamMember(ctx, "family", "bob")
+	require.NoError(t, err)
+	assert.True(t, removed)
+	scope, err = ownership.ScopeFor(ctx, bob)
+	require.NoError(t, err)
+	assert.False(t, scope.Allows("media", "sonarr"))
+}
@@ -0,0 +1,66 @@
+package auth
+
+import "fmt"
+
+// Role is a user's access level. Roles are ordered: each role includes the
+// permissions of the roles below it.
+type Role string
+
+const (
+	// RoleViewer can see check results and history.
+	RoleViewer Role = "viewer"
+	// RoleOperator can additionally trigger updates, rollbacks, and restarts.
+	RoleOperator Role = "operator"
+	// RoleAdmin can additionally change policies, scripts, labels, settings, and users.
+	RoleAdmin Role = "admin"
+)
+
+// rank returns the role's position in the hierarchy (0 for unknown roles).
+func (r Role) rank() int {
+	switch r {
+	case RoleViewer:
+		return 1
+	case RoleOperator:
+		return 2
+	case RoleAdmin:
+		return 3
+	default:
+		return 0
+	}
+}
+
+// Allows reports whether r grants at least the required role.
+func (r Role) Allows(required Role) bool {
+	return r.rank() > 0 && r.rank() >= required.rank()
+}
+
+// ParseRole validates a role name.
+func ParseRole(s string) (Role, error) {
+	role := Role(s)
+	if role.rank() == 0 {
+		return "", fmt.Errorf("invalid role %q (must be viewer, operator, or admin)", s)
+	}
+	return role, nil
+}
+
+// KindAnonymous is the principal kind of requests without credentials.
+const KindAnonymous = "anonymous"
+
+// Principal identifies the caller of an authenticated request.
+type Principal struct {
+	Kind string `json:"kind"` // "api_key", "user", or "anonymous"
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	Role Role   `json:"role"`
+}
+
+// AnonymousPrincipal returns the principal of requests without credentials
+// once users or API keys exist: a viewer that owns nothing.
+func AnonymousPrincipal() *Principal {
+	return &Principal{Kind: KindAnonymous, Name: KindAnonymous, Role: RoleViewer}
+}
+
+// Anonymous reports whether p is a request without credentials.
+func (p *Principal) Anonymous() bool {
+	return p == nil || p.Kind == KindAnonymous
+}
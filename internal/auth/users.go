@@ -0,0 +1,295 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/chis/docksmith/internal/storage"
+)
+
+// SessionCookieName is the cookie carrying the browser session token.
+const SessionCookieName = "docksmith_session"
+
+// defaultSessionTTL is how long a login session stays valid.
+const defaultSessionTTL = 24 * time.Hour
+
+// minPasswordLength is the shortest accepted password.
+const minPasswordLength = 8
+
+// Sentinel errors
+var (
+	ErrInvalidCredentials = errors.New("invalid username or password")
+	ErrInvalidSession     = errors.New("invalid or expired session")
+	ErrUserNotFound       = errors.New("user not found")
+	ErrUserExists         = errors.New("user already exists")
+	ErrLastAdmin          = errors.New("cannot remove or demote the last admin")
+)
+
+// UserService manages users and their login sessions.
+type UserService struct {
+	storage    storage.Storage
+	sessionTTL time.Duration
+}
+
+// NewUserService creates a user service backed by the given storage.
+// The session lifetime can be overridden with SESSION_TTL (e.g. "12h").
+func NewUserService(store storage.Storage) *UserService {
+	ttl := defaultSessionTTL
+	if ttlStr := os.Getenv("SESSION_TTL"); ttlStr != "" {
+		if parsed, err := time.ParseDuration(ttlStr); err == nil && parsed > 0 {
+			ttl = parsed
+		} else {
+			log.Printf("Warning: Invalid SESSION_TTL '%s', using default %v", ttlStr, ttl)
+		}
+	}
+
+	return &UserService{
+		storage:    store,
+		sessionTTL: ttl,
+	}
+}
+
+// SessionTTL returns the configured session lifetime.
+func (u *UserService) SessionTTL() time.Duration {
+	return u.sessionTTL
+}
+
+// CreateUser creates a user with the given role. An empty password creates a
+// user that can only log in through an external identity provider.
+func (u *UserService) CreateUser(ctx context.Context, username, password string, role Role) (storage.User, error) {
+	username = strings.TrimSpace(username)
+	if username == "" {
+		return storage.User{}, fmt.Errorf("username is required")
+	}
+	if _, err := ParseRole(string(role)); err != nil {
+		return storage.User{}, err
+	}
+
+	if _, found, err := u.storage.GetUser(ctx, username); err != nil {
+		return storage.User{}, err
+	} else if found {
+		return storage.User{}, ErrUserExists
+	}
+
+	var hash string
+	if password != "" {
+		if len(password) < minPasswordLength {
+			return storage.User{}, fmt.Errorf("password must be at least %d characters", minPasswordLength)
+		}
+		var err error
+		hash, err = HashPassword(password)
+		if err != nil {
+			return storage.User{}, err
+		}
+	}
+
+	return u.storage.CreateUser(ctx, storage.User{
+		Username:     username,
+		PasswordHash: hash,
+		Role:         string(role),
+	})
+}
+
+// SetRole changes a user's role. The last admin cannot be demoted.
+func (u *UserService) SetRole(ctx context.Context, username string, role Role) error {
+	if _, err := ParseRole(string(role)); err != nil {
+		return err
+	}
+
+	user, found, err := u.storage.GetUser(ctx, username)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return ErrUserNotFound
+	}
+
+	if Role(user.Role) == RoleAdmin && role != RoleAdmin {
+		if err := u.ensureAnotherAdmin(ctx, username); err != nil {
+			return err
+		}
+	}
+
+	user.Role = string(role)
+	return u.storage.UpdateUser(ctx, user)
+}
+
+// SetPassword replaces a user's password.
+func (u *UserService) SetPassword(ctx context.Context, username, password string) error {
+	if len(password) < minPasswordLength {
+		return fmt.Errorf("password must be at least %d characters", minPasswordLength)
+	}
+
+	user, found, err := u.storage.GetUser(ctx, username)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return ErrUserNotFound
+	}
+
+	user.PasswordHash, err = HashPassword(password)
+	if err != nil {
+		return err
+	}
+	return u.storage.UpdateUser(ctx, user)
+}
+
+// DeleteUser removes a user and their sessions. The last admin cannot be deleted.
+func (u *UserService) DeleteUser(ctx context.Context, username string) error {
+	user, found, err := u.storage.GetUser(ctx, username)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return ErrUserNotFound
+	}
+
+	if Role(user.Role) == RoleAdmin {
+		if err := u.ensureAnotherAdmin(ctx, username); err != nil {
+			return err
+		}
+	}
+
+	return u.storage.DeleteUser(ctx, username)
+}
+
+// ListUsers returns all users.
+func (u *UserService) ListUsers(ctx context.Context) ([]storage.User, error) {
+	return u.storage.ListUsers(ctx)
+}
+
+// HasUsers reports whether any users exist.
+func (u *UserService) HasUsers(ctx context.Context) bool {
+	users, err := u.storage.ListUsers(ctx)
+	return err == nil && len(users) > 0
+}
+
+// SyncExternalUser creates or updates a user authenticated by an external
+// identity provider. The provider's role assignment is authoritative, so the
+// last-admin check does not apply. Local password accounts are never taken over.
+func (u *UserService) SyncExternalUser(ctx context.Context, username string, role Role) (storage.User, error) {
+	if _, err := ParseRole(string(role)); err != nil {
+		return storage.User{}, err
+	}
+
+	user, found, err := u.storage.GetUser(ctx, username)
+	if err != nil {
+		return storage.User{}, err
+	}
+	if !found {
+		return u.storage.CreateUser(ctx, storage.User{
+			Username: username,
+			Role:     string(role),
+		})
+	}
+
+	if user.PasswordHash != "" {
+		return storage.User{}, fmt.Errorf("%w: %s is a local account", ErrUserExists, username)
+	}
+	if user.Role != string(role) {
+		user.Role = string(role)
+		if err := u.storage.UpdateUser(ctx, user); err != nil {
+			return storage.User{}, err
+		}
+	}
+	return user, nil
+}
+
+// Login verifies a username/password pair and starts a session.
+// Returns the plaintext session token to place in the session cookie.
+func (u *UserService) Login(ctx context.Context, username, password string) (string, time.Time, storage.User, error) {
+	user, found, err := u.storage.GetUser(ctx, username)
+	if err != nil {
+		return "", time.Time{}, storage.User{}, err
+	}
+	if !found || user.PasswordHash == "" || !VerifyPassword(password, user.PasswordHash) {
+		return "", time.Time{}, storage.User{}, ErrInvalidCredentials
+	}
+
+	token, expires, err := u.StartSession(ctx, user)
+	if err != nil {
+		return "", time.Time{}, storage.User{}, err
+	}
+	return token, expires, user, nil
+}
+
+// StartSession creates a new session for an already-authenticated user.
+func (u *UserService) StartSession(ctx context.Context, user storage.User) (string, time.Time, error) {
+	token, err := randomHex(32)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to generate session token: %w", err)
+	}
+
+	expires := time.Now().Add(u.sessionTTL)
+	if err := u.storage.SaveSession(ctx, storage.Session{
+		TokenHash: HashKey(token),
+		UserID:    user.ID,
+		ExpiresAt: expires,
+	}); err != nil {
+		return "", time.Time{}, err
+	}
+
+	// Opportunistically prune expired sessions
+	if _, err := u.storage.DeleteExpiredSessions(ctx, time.Now()); err != nil {
+		log.Printf("Warning: Failed to prune expired sessions: %v", err)
+	}
+
+	return token, expires, nil
+}
+
+// Logout ends the session identified by token.
+func (u *UserService) Logout(ctx context.Context, token string) error {
+	return u.storage.DeleteSession(ctx, HashKey(token))
+}
+
+// Authenticate resolves a session token to the logged-in user's principal.
+func (u *UserService) Authenticate(ctx context.Context, token string) (*Principal, error) {
+	session, found, err := u.storage.GetSession(ctx, HashKey(token))
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, ErrInvalidSession
+	}
+
+	user, found, err := u.storage.GetUserByID(ctx, session.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, ErrInvalidSession
+	}
+
+	return UserPrincipal(user), nil
+}
+
+// UserPrincipal returns the request principal for a user.
+func UserPrincipal(user storage.User) *Principal {
+	return &Principal{
+		Kind: "user",
+		ID:   strconv.FormatInt(user.ID, 10),
+		Name: user.Username,
+		Role: Role(user.Role),
+	}
+}
+
+// ensureAnotherAdmin returns ErrLastAdmin if username is the only admin.
+func (u *UserService) ensureAnotherAdmin(ctx context.Context, username string) error {
+	users, err := u.storage.ListUsers(ctx)
+	if err != nil {
+		return err
+	}
+	for _, other := range users {
+		if other.Username != username && Role(other.Role) == RoleAdmin {
+			return nil
+		}
+	}
+	return ErrLastAdmin
+}
@@ -0,0 +1,114 @@
+package auth
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/chis/docksmith/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestUserService(t *testing.T) *UserService {
+	t.Helper()
+	store, err := storage.NewSQLiteStorage(filepath.Join(t.TempDir(), "test.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { store.Close() })
+	return NewUserService(store)
+}
+
+func TestPassword_HashAndVerify(t *testing.T) {
+	hash, err := HashPassword("correct horse")
+	require.NoError(t, err)
+	assert.NotContains(t, hash, "correct horse")
+
+	assert.True(t, VerifyPassword("correct horse", hash))
+	assert.False(t, VerifyPassword("wrong horse", hash))
+	assert.False(t, VerifyPassword("correct horse", "not-a-hash"))
+}
+
+func TestUserService_LoginAndAuthenticate(t *testing.T) {
+	ctx := context.Background()
+	users := newTestUserService(t)
+
+	_, err := users.CreateUser(ctx, "alice", "password123", RoleOperator)
+	require.NoError(t, err)
+
+	_, _, _, err = users.Login(ctx, "alice", "wrong-password")
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+	_, _, _, err = users.Login(ctx, "nobody", "password123")
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+
+	token, expires, user, err := users.Login(ctx, "alice", "password123")
+	require.NoError(t, err)
+	assert.Equal(t, "alice", user.Username)
+	assert.WithinDuration(t, time.Now().Add(defaultSessionTTL), expires, time.Minute)
+
+	principal, err := users.Authenticate(ctx, token)
+	require.NoError(t, err)
+	assert.Equal(t, "user", principal.Kind)
+	assert.Equal(t, "alice", principal.Name)
+	assert.Equal(t, RoleOperator, principal.Role)
+
+	require.NoError(t, users.Logout(ctx, token))
+	_, err = users.Authenticate(ctx, token)
+	assert.ErrorIs(t, err, ErrInvalidSession)
+}
+
+func TestUserService_CreateValidation(t *testing.T) {
+	ctx := context.Background()
+	users := newTestUserService(t)
+
+	_, err := users.CreateUser(ctx, "bob", "short", RoleViewer)
+	assert.Error(t, err)
+	_, err = users.CreateUser(ctx, "bob", "password123", Role("root"))
+	assert.Error(t, err)
+
+	_, err = users.CreateUser(ctx, "bob", "password123", RoleViewer)
+	require.NoError(t, err)
+	_, err = users.CreateUser(ctx, "bob", "password123", RoleViewer)
+	assert.ErrorIs(t, err, ErrUserExists)
+}
+
+func TestUserService_LastAdminProtected(t *testing.T) {
+	ctx := context.Background()
+	users := newTestUserService(t)
+
+	_, err := users.CreateUser(ctx, "admin", "password123", RoleAdmin)
+	require.NoError(t, err)
+
+	assert.ErrorIs(t, users.SetRole(ctx, "admin", RoleViewer), ErrLastAdmin)
+	assert.ErrorIs(t, users.DeleteUser(ctx, "admin"), ErrLastAdmin)
+
+	_, err = users.CreateUser(ctx, "admin2", "password123", RoleAdmin)
+	require.NoError(t, err)
+	require.NoError(t, users.SetRole(ctx, "admin", RoleViewer))
+	assert.ErrorIs(t, users.DeleteUser(ctx, "admin2"), ErrLastAdmin)
+}
+
+func TestUserService_DeleteEndsSessions(t *testing.T) {
+	ctx := context.Background()
+	users := newTestUserService(t)
+
+	_, err := users.CreateUser(ctx, "carol", "password123", RoleViewer)
+	require.NoError(t, err)
+	token, _, _, err := users.Login(ctx, "carol", "password123")
+	require.NoError(t, err)
+
+	require.NoError(t, users.DeleteUser(ctx, "carol"))
+	_, err = users.Authenticate(ctx, token)
+	assert.ErrorIs(t, err, ErrInvalidSession)
+	assert.ErrorIs(t, users.DeleteUser(ctx, "carol"), ErrUserNotFound)
+}
+
+func TestRole_Allows(t *testing.T) {
+	assert.True(t, RoleAdmin.Allows(RoleOperator))
+	assert.True(t, RoleOperator.Allows(RoleViewer))
+	assert.False(t, RoleViewer.Allows(RoleOperator))
+	assert.False(t, RoleOperator.Allows(RoleAdmin))
+
+	_, err := ParseRole("superuser")
+	assert.Error(t, err)
+}
@@ -0,0 +1,69 @@
+package compose
+
+import (
+	"fmt"
+	"strings"
+)
+
+// diffContext is the number of unchanged lines shown around each change.
+const diffContext = 3
+
+// UnifiedDiff renders a unified diff (as read by git apply and patch -p1)
+// between two versions of a file whose lines were edited in place, which is
+// the only kind of change SetImageInPlace makes.
+func UnifiedDiff(path string, before, after []byte) (string, error) {
+	a := strings.SplitAfter(string(before), "\n")
+	b := strings.SplitAfter(string(after), "\n")
+	if len(a) != len(b) {
+		return "", fmt.Errorf("line count changed (%d -> %d)", len(a), len(b))
+	}
+	// SplitAfter leaves an empty element after a trailing newline
+	if n := len(a); n > 0 && a[n-1] == "" && b[n-1] == "" {
+		a, b = a[:n-1], b[:n-1]
+	}
+
+	var changed []int
+	for i := range a {
+		if a[i] != b[i] {
+			changed = append(changed, i)
+		}
+	}
+	if len(changed) == 0 {
+		return "", fmt.Errorf("no changes")
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "--- a/%s\n+++ b/%s\n", path, path)
+
+	for i := 0; i < len(changed); {
+		// Grow the hunk while the next change is close enough to share context
+		j := i
+		for j+1 < len(changed) && changed[j+1]-changed[j] <= 2*diffContext {
+			j++
+		}
+		start := max(changed[i]-diffContext, 0)
+		end := min(changed[j]+diffContext+1, len(a))
+
+		fmt.Fprintf(&sb, "@@ -%d,%d +%d,%d @@\n", start+1, end-start, start+1, end-start)
+		for k := start; k < end; k++ {
+			if a[k] == b[k] {
+				writeDiffLine(&sb, " ", a[k])
+				continue
+			}
+			writeDiffLine(&sb, "-", a[k])
+			writeDiffLine(&sb, "+", b[k])
+		}
+		i = j + 1
+	}
+
+	return sb.String(), nil
+}
+
+// writeDiffLine writes one diff line, marking a missing final newline.
+func writeDiffLine(sb *strings.Builder, prefix, line string) {
+	sb.WriteString(prefix)
+	sb.WriteString(line)
+	if !strings.HasSuffix(line, "\n") {
+		sb.WriteString("\n\\ No newline at end of file\n")
+	}
+}
@@ -0,0 +1,54 @@
+package compose
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	before := "services:\n  web:\n    image: nginx:1.25\n    restart: always\n"
+	after := "services:\n  web:\n    image: nginx:1.27\n    restart: always\n"
+
+	patch, err := UnifiedDiff("docker-compose.yml", []byte(before), []byte(after))
+	require.NoError(t, err)
+	assert.Equal(t, `--- a/docker-compose.yml
++++ b/docker-compose.yml
+@@ -1,4 +1,4 @@
+ services:
+   web:
+-    image: nginx:1.25
++    image: nginx:1.27
+     restart: always
+`, patch)
+}
+
+func TestUnifiedDiff_SeparateHunks(t *testing.T) {
+	lines := []string{"a\n", "b\n", "c\n", "d\n", "e\n", "f\n", "g\n", "h\n", "i\n", "j\n"}
+	before, after := "", ""
+	for i, l := range lines {
+		before += l
+		if i == 0 || i == 9 {
+			after += "X" + l
+		} else {
+			after += l
+		}
+	}
+
+	patch, err := UnifiedDiff("f", []byte(before), []byte(after))
+	require.NoError(t, err)
+	assert.Contains(t, patch, "@@ -1,4 +1,4 @@\n")
+	assert.Contains(t, patch, "@@ -7,4 +7,4 @@\n")
+}
+
+func TestUnifiedDiff_NoTrailingNewline(t *testing.T) {
+	patch, err := UnifiedDiff("f", []byte("image: a:1"), []byte("image: a:2"))
+	require.NoError(t, err)
+	assert.Contains(t, patch, "-image: a:1\n\\ No newline at end of file\n+image: a:2\n\\ No newline at end of file\n")
+}
+
+func TestUnifiedDiff_RejectsUnchanged(t *testing.T) {
+	_, err := UnifiedDiff("f", []byte("a\n"), []byte("a\n"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,179 @@
+package compose
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ServiceBuild returns the Dockerfile a service with a build section is built from.
+// Relative paths are resolved against the directory of the compose file. ok is
+// false if the service has no build section.
+func (cf *ComposeFile) ServiceBuild(svc *Service) (dockerfile string, ok bool) {
+	if svc.Node == nil || svc.Node.Kind != yaml.MappingNode {
+		return "", false
+	}
+
+	var buildNode *yaml.Node
+	for i := 0; i < len(svc.Node.Content)-1; i += 2 {
+		if svc.Node.Content[i].Value == "build" {
+			buildNode = svc.Node.Content[i+1]
+			break
+		}
+	}
+	if buildNode == nil {
+		return "", false
+	}
+
+	// build: ./dir, or build: {context: ./dir, dockerfile: Dockerfile.prod}
+	buildContext, file := ".", "Dockerfile"
+	switch buildNode.Kind {
+	case yaml.ScalarNode:
+		buildContext = buildNode.Value
+	case yaml.MappingNode:
+		for i := 0; i < len(buildNode.Content)-1; i += 2 {
+			switch buildNode.Content[i].Value {
+			case "context":
+				buildContext = buildNode.Content[i+1].Value
+			case "dockerfile":
+				file = buildNode.Content[i+1].Value
+			}
+		}
+	}
+
+	if !filepath.IsAbs(buildContext) {
+		buildContext = filepath.Join(filepath.Dir(cf.Path), buildContext)
+	}
+	if !filepath.IsAbs(file) {
+		file = filepath.Join(buildContext, file)
+	}
+	return file, true
+}
+
+// dockerfileStage is a FROM instruction of a Dockerfile.
+type dockerfileStage struct {
+	image string // Image reference as written, possibly with build arguments
+	name  string // Stage name from "AS name"
+	line  int    // Index of the line holding the instruction
+}
+
+// argRef matches $NAME, ${NAME}, and ${NAME:-default} build argument references.
+var argRef = regexp.MustCompile(`\$(?:\{([A-Za-z_][A-Za-z0-9_]*)(?::?-([^}]*))?\}|([A-Za-z_][A-Za-z0-9_]*))`)
+
+// parseDockerfile returns the lines of a Dockerfile, its stages, and the default
+// values of the build arguments declared before the first stage.
+func parseDockerfile(path string) ([]string, []dockerfileStage, map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to read Dockerfile: %w", err)
+	}
+
+	lines := strings.SplitAfter(string(data), "\n")
+	var stages []dockerfileStage
+	args := make(map[string]string)
+	for i, line := range lines {
+		fields := strings.Fields(line)
+		if len(fields) < 2 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		switch strings.ToUpper(fields[0]) {
+		case "ARG":
+			if len(stages) == 0 {
+				name, value, _ := strings.Cut(fields[1], "=")
+				args[name] = strings.Trim(value, `"'`)
+			}
+		case "FROM":
+			fields = fields[1:]
+			for len(fields) > 0 && strings.HasPrefix(fields[0], "--") {
+				fields = fields[1:] // --platform=...
+			}
+			if len(fields) == 0 {
+				continue
+			}
+			stage := dockerfileStage{image: fields[0], line: i}
+			if len(fields) >= 3 && strings.EqualFold(fields[1], "AS") {
+				stage.name = fields[2]
+			}
+			stages = append(stages, stage)
+		}
+	}
+	if len(stages) == 0 {
+		return nil, nil, nil, fmt.Errorf("no FROM instruction in %s", path)
+	}
+	return lines, stages, args, nil
+}
+
+// baseStage returns the stage whose image the final stage of a Dockerfile is
+// built on, following references to earlier stages.
+func baseStage(stages []dockerfileStage) dockerfileStage {
+	stage := stages[len(stages)-1]
+	for i := len(stages) - 2; i >= 0; i-- {
+		if stages[i].name != "" && strings.EqualFold(stages[i].name, stage.image) {
+			stage = stages[i]
+		}
+	}
+	return stage
+}
+
+// ReadBaseImage returns the image the final stage of a Dockerfile is built on.
+// Build arguments in the FROM instruction are replaced by their default values.
+func ReadBaseImage(dockerfile string) (string, error) {
+	_, stages, args, err := parseDockerfile(dockerfile)
+	if err != nil {
+		return "", err
+	}
+
+	image := baseStage(stages).image
+	var missing string
+	image = argRef.ReplaceAllStringFunc(image, func(ref string) string {
+		m := argRef.FindStringSubmatch(ref)
+		name := m[1] + m[3]
+		if value := args[name]; value != "" {
+			return value
+		}
+		if m[2] != "" {
+			return m[2]
+		}
+		missing = name
+		return ""
+	})
+	if missing != "" {
+		return "", fmt.Errorf("base image uses build argument %s without a default value", missing)
+	}
+	if image == "scratch" {
+		return "", fmt.Errorf("final stage is built from scratch")
+	}
+	return image, nil
+}
+
+// SetBaseImage changes the image the final stage of a Dockerfile is built on,
+// leaving the rest of the file untouched. FROM instructions using build arguments
+// are not changed.
+func SetBaseImage(dockerfile, newImage string) error {
+	lines, stages, _, err := parseDockerfile(dockerfile)
+	if err != nil {
+		return err
+	}
+
+	stage := baseStage(stages)
+	if strings.Contains(stage.image, "$") {
+		return fmt.Errorf("base image %s is set by a build argument", stage.image)
+	}
+	line := lines[stage.line]
+	start := strings.Index(strings.ToUpper(line), "FROM") + len("FROM")
+	idx := strings.Index(line[start:], stage.image)
+	if idx < 0 {
+		return fmt.Errorf("could not locate base image %s", stage.image)
+	}
+	idx += start
+	lines[stage.line] = line[:idx] + newImage + line[idx+len(stage.image):]
+
+	if err := os.WriteFile(dockerfile, []byte(strings.Join(lines, "")), 0644); err != nil {
+		return fmt.Errorf("failed to write Dockerfile: %w", err)
+	}
+	return nil
+}
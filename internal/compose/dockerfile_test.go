@@ -0,0 +1,96 @@
+package compose
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeDockerfile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "Dockerfile")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	return path
+}
+
+func TestServiceBuild(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "docker-compose.yml")
+	require.NoError(t, os.WriteFile(path, []byte(`services:
+  api:
+    build: ./api
+  web:
+    build:
+      context: web
+      dockerfile: docker/Dockerfile.prod
+  db:
+    image: postgres:16
+`), 0644))
+
+	cf, err := LoadComposeFile(path)
+	require.NoError(t, err)
+
+	tests := map[string]string{
+		"api": filepath.Join(dir, "api", "Dockerfile"),
+		"web": filepath.Join(dir, "web", "docker", "Dockerfile.prod"),
+	}
+	for service, expected := range tests {
+		svc, err := cf.FindServiceByContainerName(service)
+		require.NoError(t, err)
+		dockerfile, ok := cf.ServiceBuild(svc)
+		assert.True(t, ok, service)
+		assert.Equal(t, expected, dockerfile, service)
+	}
+
+	svc, err := cf.FindServiceByContainerName("db")
+	require.NoError(t, err)
+	_, ok := cf.ServiceBuild(svc)
+	assert.False(t, ok)
+}
+
+func TestReadBaseImage(t *testing.T) {
+	tests := []struct {
+		name       string
+		dockerfile string
+		expected   string
+		wantErr    bool
+	}{
+		{"single stage", "FROM python:3.12-slim\nCOPY . /app\n", "python:3.12-slim", false},
+		{"platform flag", "FROM --platform=$BUILDPLATFORM golang:1.22 AS build\nFROM alpine:3.19\n", "alpine:3.19", false},
+		{"stage reference", "FROM node:20-alpine AS base\nFROM base AS deps\nRUN npm ci\nFROM deps\n", "node:20-alpine", false},
+		{"build argument", "ARG VERSION=3.19\nFROM alpine:${VERSION}\n", "alpine:3.19", false},
+		{"argument fallback", "ARG VERSION\nFROM alpine:${VERSION:-3.18}\n", "alpine:3.18", false},
+		{"argument without default", "ARG VERSION\nFROM alpine:$VERSION\n", "", true},
+		{"lowercase and comments", "# syntax=docker/dockerfile:1\nfrom debian:12 as final\n", "debian:12", false},
+		{"scratch", "FROM golang:1.22 AS build\nFROM scratch\n", "", true},
+		{"no FROM", "RUN true\n", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			image, err := ReadBaseImage(writeDockerfile(t, tt.dockerfile))
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, image)
+		})
+	}
+}
+
+func TestSetBaseImage(t *testing.T) {
+	content := "# Build stage\nFROM --platform=$BUILDPLATFORM node:20.11-alpine AS build   # pinned\nRUN npm ci\n\nFROM build\nCMD [\"node\"]\n"
+	path := writeDockerfile(t, content)
+
+	require.NoError(t, SetBaseImage(path, "node:20.12-alpine"))
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "# Build stage\nFROM --platform=$BUILDPLATFORM node:20.12-alpine AS build   # pinned\nRUN npm ci\n\nFROM build\nCMD [\"node\"]\n", string(data))
+
+	path = writeDockerfile(t, "ARG VERSION=3.19\nFROM alpine:${VERSION}\n")
+	assert.ErrorContains(t, SetBaseImage(path, "alpine:3.20"), "build argument")
+}
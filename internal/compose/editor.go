@@ -1,11 +1,15 @@
 package compose
 
 import (
+	"bytes"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"time"
+	"unicode/utf8"
 
 	"gopkg.in/yaml.v3"
 )
@@ -18,39 +22,47 @@ func LoadComposeFile(path string) (*ComposeFile, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to read compose file: %w", err)
 	}
+	return parseComposeFile(path, data)
+}
+
+// parseComposeFile parses compose file content. In a file with several YAML
+// documents, the first document with a services section is used.
+func parseComposeFile(path string, data []byte) (*ComposeFile, error) {
+	cf := &ComposeFile{Path: path}
 
 	// Parse YAML while preserving structure
-	var root yaml.Node
-	if err := yaml.Unmarshal(data, &root); err != nil {
-		return nil, fmt.Errorf("failed to parse compose file: %w", err)
-	}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	for {
+		var root yaml.Node
+		if err := decoder.Decode(&root); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to parse compose file: %w", err)
+		}
+		cf.documents = append(cf.documents, &root)
 
-	// Find the services node
-	var servicesNode *yaml.Node
-	if root.Kind == yaml.DocumentNode && len(root.Content) > 0 {
-		// Root is typically a document node containing a mapping node
+		// Root is a document node typically containing a mapping node
+		if cf.Services != nil || root.Kind != yaml.DocumentNode || len(root.Content) == 0 {
+			continue
+		}
 		mappingNode := root.Content[0]
-		if mappingNode.Kind == yaml.MappingNode {
-			// Find "services" key
-			for i := 0; i < len(mappingNode.Content); i += 2 {
-				keyNode := mappingNode.Content[i]
-				if keyNode.Value == "services" {
-					servicesNode = mappingNode.Content[i+1]
-					break
-				}
+		if mappingNode.Kind != yaml.MappingNode {
+			continue
+		}
+		// Find "services" key
+		for i := 0; i < len(mappingNode.Content)-1; i += 2 {
+			if mappingNode.Content[i].Value == "services" {
+				cf.Root = &root
+				cf.Services = mappingNode.Content[i+1]
+				break
 			}
 		}
 	}
 
-	if servicesNode == nil {
+	if cf.Services == nil {
 		return nil, fmt.Errorf("no services section found in compose file")
 	}
-
-	return &ComposeFile{
-		Path:     path,
-		Root:     &root,
-		Services: servicesNode,
-	}, nil
+	return cf, nil
 }
 
 // FindServiceByContainerName finds a service by its container_name label.
@@ -82,15 +94,186 @@ func (cf *ComposeFile) FindServiceByContainerName(containerName string) (*Servic
 
 // GetServiceImage extracts the image value from a Service's YAML node.
 func GetServiceImage(svc *Service) string {
-	if svc.Node == nil || svc.Node.Kind != yaml.MappingNode {
+	node := serviceImageNode(svc)
+	if node == nil {
 		return ""
 	}
+	if node.Kind == yaml.AliasNode && node.Alias != nil {
+		return node.Alias.Value
+	}
+	return node.Value
+}
+
+// serviceImageNode returns the value node of a service's image key, or nil.
+func serviceImageNode(svc *Service) *yaml.Node {
+	if svc.Node == nil || svc.Node.Kind != yaml.MappingNode {
+		return nil
+	}
 	for i := 0; i < len(svc.Node.Content)-1; i += 2 {
 		if svc.Node.Content[i].Value == "image" {
-			return svc.Node.Content[i+1].Value
+			return svc.Node.Content[i+1]
 		}
 	}
-	return ""
+	return nil
+}
+
+// SetImageInPlace returns the compose file contents before and after changing
+// the service's image to newImage. Only the image scalar is edited, so comments,
+// anchors, key order, quoting, and other documents are preserved exactly. An image
+// set with an alias is replaced for this service only; the anchor is left alone.
+// The file itself is not written.
+func (cf *ComposeFile) SetImageInPlace(svc *Service, newImage string) ([]byte, []byte, error) {
+	if svc.Node == nil || svc.Node.Kind != yaml.MappingNode {
+		return nil, nil, fmt.Errorf("service node is not a mapping")
+	}
+	imageNode := serviceImageNode(svc)
+	if imageNode == nil {
+		return nil, nil, fmt.Errorf("service %s has no image field", svc.Name)
+	}
+
+	before, err := os.ReadFile(cf.Path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read compose file: %w", err)
+	}
+
+	start, end, err := scalarSpan(before, imageNode)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not locate image of service %s: %w", svc.Name, err)
+	}
+
+	// Keep the scalar's quoting; plain values that would not read back as
+	// newImage (e.g. containing ": ") are double-quoted instead
+	candidates := []string{newImage, strconv.Quote(newImage)}
+	switch {
+	case imageNode.Style&yaml.DoubleQuotedStyle != 0:
+		candidates = []string{strconv.Quote(newImage)}
+	case imageNode.Style&yaml.SingleQuotedStyle != 0:
+		candidates = []string{"'" + strings.ReplaceAll(newImage, "'", "''") + "'"}
+	}
+	for _, token := range candidates {
+		after := make([]byte, 0, len(before)-(end-start)+len(token))
+		after = append(after, before[:start]...)
+		after = append(after, token...)
+		after = append(after, before[end:]...)
+
+		if imageReadsBack(cf.Path, after, svc.Name, newImage) {
+			return before, after, nil
+		}
+	}
+	return nil, nil, fmt.Errorf("failed to set image of service %s to %q", svc.Name, newImage)
+}
+
+// SetImage changes the service's image to newImage in the compose file on disk,
+// editing only the image scalar (see SetImageInPlace).
+func (cf *ComposeFile) SetImage(svc *Service, newImage string) error {
+	_, after, err := cf.SetImageInPlace(svc, newImage)
+	if err != nil {
+		return err
+	}
+	if err := writeComposeFile(cf.Path, after); err != nil {
+		return fmt.Errorf("failed to write compose file: %w", err)
+	}
+	return nil
+}
+
+// imageReadsBack reports whether the service in edited compose file content has image newImage.
+func imageReadsBack(path string, data []byte, serviceName, newImage string) bool {
+	cf, err := parseComposeFile(path, data)
+	if err != nil {
+		return false
+	}
+	for i := 0; i < len(cf.Services.Content)-1; i += 2 {
+		if cf.Services.Content[i].Value == serviceName {
+			return GetServiceImage(&Service{Name: serviceName, Node: cf.Services.Content[i+1]}) == newImage
+		}
+	}
+	return false
+}
+
+// scalarSpan returns the byte range of a scalar or alias node's value in data,
+// excluding its anchor and tag. Block scalars (| and >) are not supported.
+func scalarSpan(data []byte, node *yaml.Node) (start, end int, err error) {
+	if node.Kind != yaml.ScalarNode && node.Kind != yaml.AliasNode {
+		return 0, 0, fmt.Errorf("not a scalar")
+	}
+	if node.Style&(yaml.LiteralStyle|yaml.FoldedStyle) != 0 {
+		return 0, 0, fmt.Errorf("block scalars are not supported")
+	}
+
+	// Line and Column are 1-based, and Column counts characters
+	lineStart := 0
+	for line := 1; line < node.Line; line++ {
+		i := bytes.IndexByte(data[lineStart:], '\n')
+		if i < 0 {
+			return 0, 0, fmt.Errorf("line %d is out of range", node.Line)
+		}
+		lineStart += i + 1
+	}
+	start = lineStart
+	for col := 1; col < node.Column; col++ {
+		if start >= len(data) || data[start] == '\n' {
+			return 0, 0, fmt.Errorf("column %d is out of range on line %d", node.Column, node.Line)
+		}
+		_, size := utf8.DecodeRune(data[start:])
+		start += size
+	}
+
+	// The node's position includes its properties: skip "&anchor" and "!tag"
+	for start < len(data) && (data[start] == '&' || data[start] == '!') {
+		for start < len(data) && !isYAMLSpace(data[start]) {
+			start++
+		}
+		for start < len(data) && isYAMLSpace(data[start]) {
+			start++
+		}
+	}
+
+	rest := data[start:]
+	switch {
+	case node.Kind == yaml.AliasNode:
+		end = len("*" + node.Value)
+		if !bytes.HasPrefix(rest, []byte("*"+node.Value)) {
+			return 0, 0, fmt.Errorf("alias not found on line %d", node.Line)
+		}
+	case node.Style&yaml.DoubleQuotedStyle != 0:
+		end = quotedEnd(rest, '"')
+	case node.Style&yaml.SingleQuotedStyle != 0:
+		end = quotedEnd(rest, '\'')
+	default:
+		end = len(node.Value)
+		if !bytes.HasPrefix(rest, []byte(node.Value)) {
+			return 0, 0, fmt.Errorf("value %q not found on line %d", node.Value, node.Line)
+		}
+	}
+	if end < 0 {
+		return 0, 0, fmt.Errorf("unterminated quoted value on line %d", node.Line)
+	}
+	return start, start + end, nil
+}
+
+// quotedEnd returns the length of the quoted scalar at the start of data, including
+// its quotes, or -1. Double-quoted scalars escape with backslashes, single-quoted
+// scalars by doubling the quote.
+func quotedEnd(data []byte, quote byte) int {
+	if len(data) == 0 || data[0] != quote {
+		return -1
+	}
+	for i := 1; i < len(data); i++ {
+		switch {
+		case quote == '"' && data[i] == '\\':
+			i++
+		case data[i] == quote && quote == '\'' && i+1 < len(data) && data[i+1] == '\'':
+			i++
+		case data[i] == quote:
+			return i + 1
+		}
+	}
+	return -1
+}
+
+// isYAMLSpace reports whether b separates tokens on a line.
+func isYAMLSpace(b byte) bool {
+	return b == ' ' || b == '\t'
 }
 
 // getContainerName extracts the container_name value from a service definition node.
@@ -351,16 +534,24 @@ func (s *Service) GetAllLabels() (map[string]string, error) {
 	return labels, nil
 }
 
-// Save saves the compose file with preserved formatting and comments.
+// Save saves the compose file with preserved comments. The YAML is re-encoded, so
+// formatting may change; use SetImage to change only an image.
 // Overwrites the original file.
 func (cf *ComposeFile) Save() error {
+	documents := cf.documents
+	if len(documents) == 0 {
+		documents = []*yaml.Node{cf.Root}
+	}
+
 	// Encode YAML
 	var buf []byte
 	encoder := yaml.NewEncoder(&writeBuffer{data: &buf})
 	encoder.SetIndent(2)
 
-	if err := encoder.Encode(cf.Root); err != nil {
-		return fmt.Errorf("failed to encode YAML: %w", err)
+	for _, doc := range documents {
+		if err := encoder.Encode(doc); err != nil {
+			return fmt.Errorf("failed to encode YAML: %w", err)
+		}
 	}
 
 	if err := encoder.Close(); err != nil {
@@ -368,7 +559,7 @@ func (cf *ComposeFile) Save() error {
 	}
 
 	// Write to file
-	if err := os.WriteFile(cf.Path, buf, 0644); err != nil {
+	if err := writeComposeFile(cf.Path, buf); err != nil {
 		return fmt.Errorf("failed to write compose file: %w", err)
 	}
 
@@ -417,7 +608,7 @@ func RestoreFromBackup(composePath, backupPath string) error {
 	}
 
 	// Write to compose file
-	if err := os.WriteFile(composePath, data, 0644); err != nil {
+	if err := writeComposeFile(composePath, data); err != nil {
 		return fmt.Errorf("failed to restore compose file: %w", err)
 	}
 
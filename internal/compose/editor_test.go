@@ -595,3 +595,179 @@ func createTempComposeFile(t *testing.T, content string) string {
 	require.NoError(t, err)
 	return tmpFile
 }
+
+func TestSetImageInPlace(t *testing.T) {
+	content := `# Production stack
+services:
+  web:
+    image: "nginx:1.25"   # pinned
+    container_name: my-nginx
+  db:
+    image: postgres:15
+`
+	path := filepath.Join(t.TempDir(), "docker-compose.yml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+
+	cf, err := LoadComposeFile(path)
+	require.NoError(t, err)
+	svc, err := cf.FindServiceByContainerName("my-nginx")
+	require.NoError(t, err)
+
+	before, after, err := cf.SetImageInPlace(svc, "nginx:1.27")
+	require.NoError(t, err)
+	assert.Equal(t, content, string(before))
+	assert.Equal(t, strings.Replace(content, "nginx:1.25", "nginx:1.27", 1), string(after))
+
+	// The file on disk is untouched
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, content, string(data))
+}
+
+func TestSetImage_PreservesFile(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		service  string
+		newImage string
+		expected string
+	}{
+		{
+			name: "comments, key order, and quoting",
+			content: `# Production stack
+services:
+  # The web frontend
+  web:
+    container_name: my-nginx
+    image: 'nginx:1.25'   # pinned
+    ports: ["80:80"]
+
+  db:
+    image: postgres:15
+`,
+			service:  "web",
+			newImage: "nginx:1.27",
+			expected: `    image: 'nginx:1.27'   # pinned`,
+		},
+		{
+			name: "anchors and merge keys",
+			content: `x-defaults: &defaults
+  restart: unless-stopped
+  logging:
+    driver: json-file
+
+services:
+  web:
+    <<: *defaults
+    image: &web-image nginx:1.25
+  worker:
+    <<: *defaults
+    image: *web-image
+`,
+			service:  "web",
+			newImage: "nginx:1.27",
+			expected: `    image: &web-image nginx:1.27`,
+		},
+		{
+			name: "alias is replaced for the service only",
+			content: `services:
+  web:
+    image: &web-image nginx:1.25
+  worker:
+    image: *web-image   # same as web
+`,
+			service:  "worker",
+			newImage: "nginx:1.27",
+			expected: `    image: nginx:1.27   # same as web`,
+		},
+		{
+			name: "multiple documents",
+			content: `# metadata
+name: site
+---
+services:
+  web:
+    image: "ghcr.io/org/web:1.0"
+---
+x-notes: kept
+`,
+			service:  "web",
+			newImage: "ghcr.io/org/web:1.1",
+			expected: `    image: "ghcr.io/org/web:1.1"`,
+		},
+		{
+			name: "tagged scalar",
+			content: `services:
+  web:
+    image: !!str nginx:1.25
+`,
+			service:  "web",
+			newImage: "nginx:1.27",
+			expected: `    image: !!str nginx:1.27`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "docker-compose.yml")
+			require.NoError(t, os.WriteFile(path, []byte(tt.content), 0644))
+
+			cf, err := LoadComposeFile(path)
+			require.NoError(t, err)
+			svc, err := cf.FindServiceByContainerName(tt.service)
+			require.NoError(t, err)
+			require.NoError(t, cf.SetImage(svc, tt.newImage))
+
+			data, err := os.ReadFile(path)
+			require.NoError(t, err)
+
+			// Only the image line changes
+			wantLines := strings.Split(tt.content, "\n")
+			gotLines := strings.Split(string(data), "\n")
+			require.Len(t, gotLines, len(wantLines))
+			changed := 0
+			for i := range wantLines {
+				if gotLines[i] != wantLines[i] {
+					changed++
+					assert.Equal(t, tt.expected, gotLines[i])
+				}
+			}
+			assert.Equal(t, 1, changed, "exactly one line should change:\n%s", data)
+
+			reloaded, err := LoadComposeFile(path)
+			require.NoError(t, err)
+			svc, err = reloaded.FindServiceByContainerName(tt.service)
+			require.NoError(t, err)
+			assert.Equal(t, tt.newImage, GetServiceImage(svc))
+			assert.True(t, WrittenByDocksmith(path, data))
+		})
+	}
+}
+
+func TestSetImageInPlace_QuotesWhenNeeded(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "docker-compose.yml")
+	require.NoError(t, os.WriteFile(path, []byte("services:\n  web:\n    image: nginx:1.25\n"), 0644))
+
+	cf, err := LoadComposeFile(path)
+	require.NoError(t, err)
+	svc, err := cf.FindServiceByContainerName("web")
+	require.NoError(t, err)
+
+	// A plain scalar cannot contain ": "
+	_, after, err := cf.SetImageInPlace(svc, "nginx: odd")
+	require.NoError(t, err)
+	assert.Equal(t, "services:\n  web:\n    image: \"nginx: odd\"\n", string(after))
+}
+
+func TestSetImageInPlace_BlockScalar(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "docker-compose.yml")
+	require.NoError(t, os.WriteFile(path, []byte("services:\n  web:\n    image: >-\n      nginx:1.25\n"), 0644))
+
+	cf, err := LoadComposeFile(path)
+	require.NoError(t, err)
+	svc, err := cf.FindServiceByContainerName("web")
+	require.NoError(t, err)
+
+	_, _, err = cf.SetImageInPlace(svc, "nginx:1.27")
+	assert.ErrorContains(t, err, "block scalars")
+}
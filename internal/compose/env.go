@@ -0,0 +1,35 @@
+package compose
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/chis/docksmith/internal/docker"
+)
+
+// EnvFunc returns the variables added to the environment of the docker compose
+// commands run for a container, as KEY=value entries.
+type EnvFunc func(ctx context.Context, container *docker.Container) ([]string, error)
+
+type envKey struct{}
+
+// WithEnv returns a context whose docker compose commands run with the variables
+// fn returns added to docksmith's environment, so compose files can interpolate
+// variables docksmith was not started with.
+func WithEnv(ctx context.Context, fn EnvFunc) context.Context {
+	return context.WithValue(ctx, envKey{}, fn)
+}
+
+// runCompose runs a docker compose command for a container with the environment
+// of ctx, reporting its output like runDocker.
+func runCompose(ctx context.Context, container *docker.Container, args ...string) ([]byte, error) {
+	var extra []string
+	if fn, ok := ctx.Value(envKey{}).(EnvFunc); ok {
+		var err error
+		if extra, err = fn(ctx, container); err != nil {
+			return nil, fmt.Errorf("failed to load compose environment of %s: %w", container.Name, err)
+		}
+	}
+
+	return runDockerEnv(ctx, container.Name, extra, args...)
+}
@@ -35,11 +35,21 @@ func ExtractEnvVarName(s string) string {
 // LoadDotEnv reads a .env file and returns a map of key=value pairs.
 // Returns an empty map if the file doesn't exist or can't be read.
 func LoadDotEnv(dir string) map[string]string {
-	result := make(map[string]string)
-	data, err := os.ReadFile(dir + "/.env")
+	result, err := LoadEnvFile(dir + "/.env")
+	if err != nil {
+		return make(map[string]string)
+	}
+	return result
+}
+
+// LoadEnvFile reads a file of KEY=value lines, skipping blank lines and comments.
+// Values may be wrapped in single or double quotes.
+func LoadEnvFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
 	if err != nil {
-		return result
+		return nil, err
 	}
+	result := make(map[string]string)
 	for _, line := range strings.Split(string(data), "\n") {
 		line = strings.TrimSpace(line)
 		if line == "" || strings.HasPrefix(line, "#") {
@@ -55,7 +65,7 @@ func LoadDotEnv(dir string) map[string]string {
 			result[key] = val
 		}
 	}
-	return result
+	return result, nil
 }
 
 // ReplaceTagInEnvVar updates the image tag inside a Docker Compose env var expression.
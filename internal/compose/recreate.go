@@ -4,7 +4,6 @@ import (
 	"context"
 	"fmt"
 	"log"
-	"os/exec"
 	"path/filepath"
 	"strings"
 	"time"
@@ -12,6 +11,17 @@ import (
 	"github.com/chis/docksmith/internal/docker"
 )
 
+// OutputFunc receives the redacted output of a docker command run for a container.
+type OutputFunc func(containerName, command string, output []byte, err error)
+
+type outputKey struct{}
+
+// WithOutput returns a context that reports the output of the docker commands
+// run with it to fn, so callers can keep a log of what compose did.
+func WithOutput(ctx context.Context, fn OutputFunc) context.Context {
+	return context.WithValue(ctx, outputKey{}, fn)
+}
+
 // Recreator handles compose-based container recreation
 type Recreator struct {
 	dockerClient docker.Client
@@ -47,12 +57,11 @@ func (r *Recreator) RecreateWithCompose(ctx context.Context, container *docker.C
 	// This is necessary because docker compose up --force-recreate doesn't work when
 	// the container was created via docker run instead of docker compose
 	log.Printf("COMPOSE: Stopping and removing existing container %s", container.Name)
-	stopCmd := exec.CommandContext(ctx, "docker", "stop", container.Name)
-	stopOutput, _ := stopCmd.CombinedOutput() // Ignore errors if already stopped
+	stop := StopSettingsFor(container, containerComposeFilePath)
+	stopOutput, _ := runDocker(ctx, container.Name, stop.StopArgs(container.Name)...) // Ignore errors if already stopped
 	log.Printf("COMPOSE: Stop output: %s", stopOutput)
 
-	rmCmd := exec.CommandContext(ctx, "docker", "rm", container.Name)
-	rmOutput, _ := rmCmd.CombinedOutput() // Ignore errors if doesn't exist
+	rmOutput, _ := runDocker(ctx, container.Name, "rm", container.Name) // Ignore errors if doesn't exist
 	log.Printf("COMPOSE: Remove output: %s", rmOutput)
 
 	// Build the docker compose up command
@@ -60,8 +69,7 @@ func (r *Recreator) RecreateWithCompose(ctx context.Context, container *docker.C
 	// Use --project-directory with HOST path so volume mounts resolve correctly for Docker daemon
 	// NOTE: For env_file to work, docksmith must have mirror mounts (same dir at host path)
 	// Use --force-recreate to avoid Docker volume mount corruption issues
-	args := []string{
-		"compose",
+	args := append(composeCommand(container),
 		"--project-directory", hostComposeDir,  // HOST path for volume mount resolution
 		"-f", containerComposeFilePath,         // CONTAINER path for reading the file
 		"up",
@@ -69,16 +77,15 @@ func (r *Recreator) RecreateWithCompose(ctx context.Context, container *docker.C
 		"--force-recreate", // Force clean recreation to avoid volume mount issues
 		"--no-deps",        // Don't start linked services (we'll handle dependencies ourselves)
 		serviceName,
-	}
+	)
 
-	cmd := exec.CommandContext(ctx, "docker", args...)
-	// Note: Don't set cmd.Dir here - composeDir is a host path that doesn't exist in the container.
+	// Note: Don't set the working directory here - composeDir is a host path that doesn't exist in the container.
 	// The --project-directory flag tells Docker Compose where to resolve relative paths.
 
 	log.Printf("COMPOSE: Executing: docker %s", strings.Join(args, " "))
 
 	// Capture output for logging
-	output, err := cmd.CombinedOutput()
+	output, err := runCompose(ctx, container, args...)
 	if err != nil {
 		return fmt.Errorf("docker compose up failed: %w\nOutput: %s", err, output)
 	}
@@ -89,6 +96,45 @@ func (r *Recreator) RecreateWithCompose(ctx context.Context, container *docker.C
 	return nil
 }
 
+// BuildWithCompose rebuilds a service's image using docker compose build --pull, so
+// the build uses the newest version of its base image.
+// The build context must be readable by docksmith at its host path.
+func (r *Recreator) BuildWithCompose(ctx context.Context, container *docker.Container, hostComposeFilePath, containerComposeFilePath string) error {
+	if hostComposeFilePath == "" || containerComposeFilePath == "" {
+		return fmt.Errorf("no compose file path available for container %s", container.Name)
+	}
+
+	// Get service name from compose labels
+	serviceName, ok := container.Labels["com.docker.compose.service"]
+	if !ok || serviceName == "" {
+		return fmt.Errorf("container %s has no com.docker.compose.service label", container.Name)
+	}
+
+	hostComposeDir := filepath.Dir(hostComposeFilePath)
+	log.Printf("COMPOSE: Building service %s using compose file (host: %s, container: %s)",
+		serviceName, hostComposeFilePath, containerComposeFilePath)
+
+	args := append(composeCommand(container),
+		"--project-directory", hostComposeDir,
+		"-f", containerComposeFilePath,
+		"build",
+		"--pull",
+		serviceName,
+	)
+
+	log.Printf("COMPOSE: Executing: docker %s", strings.Join(args, " "))
+
+	output, err := runCompose(ctx, container, args...)
+	if err != nil {
+		return fmt.Errorf("docker compose build failed: %w\nOutput: %s", err, output)
+	}
+
+	log.Printf("COMPOSE: Output: %s", output)
+	log.Printf("COMPOSE: Successfully built service %s", serviceName)
+
+	return nil
+}
+
 // RestartWithCompose restarts a container using docker compose restart
 // This is simpler and faster than RecreateWithCompose when no config changes are needed.
 // It stops and starts the same container without creating a new one.
@@ -108,18 +154,17 @@ func (r *Recreator) RestartWithCompose(ctx context.Context, container *docker.Co
 		serviceName, hostComposeFilePath, containerComposeFilePath)
 
 	// Build the docker compose restart command
-	args := []string{
-		"compose",
+	args := append(composeCommand(container),
 		"--project-directory", hostComposeDir,
 		"-f", containerComposeFilePath,
 		"restart",
-		serviceName,
-	}
+	)
+	args = append(args, StopSettingsFor(container, containerComposeFilePath).composeTimeoutArgs()...)
+	args = append(args, serviceName)
 
-	cmd := exec.CommandContext(ctx, "docker", args...)
 	log.Printf("COMPOSE: Executing: docker %s", strings.Join(args, " "))
 
-	output, err := cmd.CombinedOutput()
+	output, err := runCompose(ctx, container, args...)
 	if err != nil {
 		return fmt.Errorf("docker compose restart failed: %w\nOutput: %s", err, output)
 	}
@@ -144,18 +189,17 @@ func (r *Recreator) StopWithCompose(ctx context.Context, container *docker.Conta
 	hostComposeDir := filepath.Dir(hostComposeFilePath)
 	log.Printf("COMPOSE: Stopping service %s", serviceName)
 
-	args := []string{
-		"compose",
+	args := append(composeCommand(container),
 		"--project-directory", hostComposeDir,
 		"-f", containerComposeFilePath,
 		"stop",
-		serviceName,
-	}
+	)
+	args = append(args, StopSettingsFor(container, containerComposeFilePath).composeTimeoutArgs()...)
+	args = append(args, serviceName)
 
-	cmd := exec.CommandContext(ctx, "docker", args...)
 	log.Printf("COMPOSE: Executing: docker %s", strings.Join(args, " "))
 
-	output, err := cmd.CombinedOutput()
+	output, err := runCompose(ctx, container, args...)
 	if err != nil {
 		return fmt.Errorf("docker compose stop failed: %w\nOutput: %s", err, output)
 	}
@@ -178,18 +222,16 @@ func (r *Recreator) StartWithCompose(ctx context.Context, container *docker.Cont
 	hostComposeDir := filepath.Dir(hostComposeFilePath)
 	log.Printf("COMPOSE: Starting service %s", serviceName)
 
-	args := []string{
-		"compose",
+	args := append(composeCommand(container),
 		"--project-directory", hostComposeDir,
 		"-f", containerComposeFilePath,
 		"start",
 		serviceName,
-	}
+	)
 
-	cmd := exec.CommandContext(ctx, "docker", args...)
 	log.Printf("COMPOSE: Executing: docker %s", strings.Join(args, " "))
 
-	output, err := cmd.CombinedOutput()
+	output, err := runCompose(ctx, container, args...)
 	if err != nil {
 		return fmt.Errorf("docker compose start failed: %w\nOutput: %s", err, output)
 	}
@@ -229,14 +271,13 @@ func (r *Recreator) RecreateMultipleServices(ctx context.Context, hostComposeFil
 	}
 	args = append(args, serviceNames...)
 
-	cmd := exec.CommandContext(ctx, "docker", args...)
-	// Note: Don't set cmd.Dir here - composeDir is a host path that doesn't exist in the container.
+	// Note: Don't set the working directory here - composeDir is a host path that doesn't exist in the container.
 	// The --project-directory flag tells Docker Compose where to resolve relative paths.
 
 	log.Printf("COMPOSE: Executing: docker %s", strings.Join(args, " "))
 
 	// Capture output for logging
-	output, err := cmd.CombinedOutput()
+	output, err := runDocker(ctx, "", args...)
 	if err != nil {
 		return fmt.Errorf("docker compose up failed: %w\nOutput: %s", err, output)
 	}
@@ -247,6 +288,65 @@ func (r *Recreator) RecreateMultipleServices(ctx context.Context, hostComposeFil
 	return nil
 }
 
+// ReplaceReplica recreates one replica of a scaled service from the current compose file.
+// The replica is stopped and removed, then the service is scaled back to replicas with
+// --no-recreate so compose creates a single new container and leaves the others running.
+func (r *Recreator) ReplaceReplica(ctx context.Context, container *docker.Container, hostComposeFilePath, containerComposeFilePath string, replicas int) error {
+	if hostComposeFilePath == "" || containerComposeFilePath == "" {
+		return fmt.Errorf("no compose file path available for container %s", container.Name)
+	}
+
+	log.Printf("COMPOSE: Stopping and removing replica %s", container.Name)
+	stop := StopSettingsFor(container, containerComposeFilePath)
+	stopOutput, _ := runDocker(ctx, container.Name, stop.StopArgs(container.Name)...) // Ignore errors if already stopped
+	log.Printf("COMPOSE: Stop output: %s", stopOutput)
+	rmOutput, _ := runDocker(ctx, container.Name, "rm", container.Name) // Ignore errors if doesn't exist
+	log.Printf("COMPOSE: Remove output: %s", rmOutput)
+
+	return r.ScaleWithCompose(ctx, container, hostComposeFilePath, containerComposeFilePath, replicas, true)
+}
+
+// ScaleWithCompose runs docker compose up -d --scale for the container's service.
+// Without noRecreate, compose recreates every replica whose configuration no longer
+// matches the compose file and keeps the ones that already do.
+func (r *Recreator) ScaleWithCompose(ctx context.Context, container *docker.Container, hostComposeFilePath, containerComposeFilePath string, replicas int, noRecreate bool) error {
+	if hostComposeFilePath == "" || containerComposeFilePath == "" {
+		return fmt.Errorf("no compose file path available for container %s", container.Name)
+	}
+
+	serviceName, ok := container.Labels["com.docker.compose.service"]
+	if !ok || serviceName == "" {
+		return fmt.Errorf("container %s has no com.docker.compose.service label", container.Name)
+	}
+
+	args := append(composeCommand(container),
+		"--project-directory", filepath.Dir(hostComposeFilePath),
+		"-f", containerComposeFilePath,
+		"up",
+		"-d",
+		"--no-deps",
+		"--scale", fmt.Sprintf("%s=%d", serviceName, replicas),
+	)
+	if noRecreate {
+		args = append(args, "--no-recreate")
+	} else {
+		args = append(args, StopSettingsFor(container, containerComposeFilePath).composeTimeoutArgs()...)
+	}
+	args = append(args, serviceName)
+
+	log.Printf("COMPOSE: Executing: docker %s", strings.Join(args, " "))
+
+	output, err := runCompose(ctx, container, args...)
+	if err != nil {
+		return fmt.Errorf("docker compose up failed: %w\nOutput: %s", err, output)
+	}
+
+	log.Printf("COMPOSE: Output: %s", output)
+	log.Printf("COMPOSE: Successfully scaled service %s to %d replicas", serviceName, replicas)
+
+	return nil
+}
+
 // FindNetworkModeDependents finds containers that use network_mode: service:xxx
 // pointing to the given container name
 func (r *Recreator) FindNetworkModeDependents(ctx context.Context, containerName string) ([]string, error) {
@@ -293,7 +393,7 @@ func (r *Recreator) WaitForHealthy(ctx context.Context, containerName string, ti
 
 		case <-ticker.C:
 			// Use docker inspect to check container state
-			cmd := exec.CommandContext(ctx, "docker", "inspect", "--format", "{{.State.Status}}", containerName)
+			cmd := dockerCommand(ctx, nil, "inspect", "--format", "{{.State.Status}}", containerName)
 			output, err := cmd.CombinedOutput()
 			if err != nil {
 				log.Printf("COMPOSE: Failed to inspect container %s: %v", containerName, err)
@@ -305,7 +405,7 @@ func (r *Recreator) WaitForHealthy(ctx context.Context, containerName string, ti
 
 			if status == "running" {
 				// Check health if available
-				cmd = exec.CommandContext(ctx, "docker", "inspect", "--format", "{{if .State.Health}}{{.State.Health.Status}}{{else}}none{{end}}", containerName)
+				cmd = dockerCommand(ctx, nil, "inspect", "--format", "{{if .State.Health}}{{.State.Health.Status}}{{else}}none{{end}}", containerName)
 				healthOutput, err := cmd.CombinedOutput()
 				if err == nil {
 					healthStatus := strings.TrimSpace(string(healthOutput))
@@ -329,3 +429,32 @@ func (r *Recreator) WaitForHealthy(ctx context.Context, containerName string, ti
 	}
 }
 
+// runDocker runs a docker command for a container ("" for several) and returns its
+// output with secrets redacted, so it can be logged and stored. The output is also
+// reported to the OutputFunc of ctx.
+func runDocker(ctx context.Context, containerName string, args ...string) ([]byte, error) {
+	return runDockerEnv(ctx, containerName, nil, args...)
+}
+
+// runDockerEnv runs a docker command like runDocker with the extra KEY=value
+// variables added to its environment.
+func runDockerEnv(ctx context.Context, containerName string, extra []string, args ...string) ([]byte, error) {
+	cmd := dockerCommand(ctx, extra, args...)
+	output, err := cmd.CombinedOutput()
+	output = []byte(Redact(string(output)))
+	if fn, ok := ctx.Value(outputKey{}).(OutputFunc); ok {
+		fn(containerName, Redact("docker "+strings.Join(args, " ")), output, err)
+	}
+	return output, err
+}
+
+// composeCommand returns the start of a docker compose command for a container's
+// project. docker-compose v1 projects are named explicitly, as docker compose v2
+// would derive a different name from the directory, and run in compatibility mode
+// so recreated containers keep their underscore names.
+func composeCommand(container *docker.Container) []string {
+	if !docker.IsComposeV1(container.Labels) {
+		return []string{"compose"}
+	}
+	return []string{"compose", "--compatibility", "-p", container.Labels["com.docker.compose.project"]}
+}
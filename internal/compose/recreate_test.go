@@ -102,6 +102,29 @@ func TestRecreateWithCompose_Validation(t *testing.T) {
 	})
 }
 
+// TestScaleWithCompose_Validation tests validation in ScaleWithCompose and ReplaceReplica
+func TestScaleWithCompose_Validation(t *testing.T) {
+	recreator := NewRecreator(&mockDockerClient{})
+	ctx := context.Background()
+
+	container := &docker.Container{
+		Name: "web-web-1",
+		Labels: map[string]string{
+			"com.docker.compose.service": "web",
+		},
+	}
+
+	err := recreator.ScaleWithCompose(ctx, container, "", "/container/path/docker-compose.yml", 3, true)
+	assert.ErrorContains(t, err, "no compose file path available")
+
+	err = recreator.ReplaceReplica(ctx, container, "/host/path/docker-compose.yml", "", 3)
+	assert.ErrorContains(t, err, "no compose file path available")
+
+	noService := &docker.Container{Name: "web-web-1", Labels: map[string]string{}}
+	err = recreator.ScaleWithCompose(ctx, noService, "/host/path/docker-compose.yml", "/container/path/docker-compose.yml", 3, false)
+	assert.ErrorContains(t, err, "no com.docker.compose.service label")
+}
+
 // TestRecreateMultipleServices_Validation tests validation in RecreateMultipleServices
 func TestRecreateMultipleServices_Validation(t *testing.T) {
 	mock := &mockDockerClient{}
@@ -245,3 +268,18 @@ func TestWaitForHealthy_ContextCancellation(t *testing.T) {
 		assert.Error(t, err)
 	})
 }
+
+// TestComposeCommand tests the project flags of docker-compose v1 containers
+func TestComposeCommand(t *testing.T) {
+	v2 := &docker.Container{Labels: map[string]string{
+		"com.docker.compose.project": "my-app",
+		"com.docker.compose.version": "2.24.0",
+	}}
+	assert.Equal(t, []string{"compose"}, composeCommand(v2))
+
+	v1 := &docker.Container{Labels: map[string]string{
+		"com.docker.compose.project": "myapp",
+		"com.docker.compose.version": "1.29.2",
+	}}
+	assert.Equal(t, []string{"compose", "--compatibility", "-p", "myapp"}, composeCommand(v1))
+}
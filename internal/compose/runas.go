@@ -0,0 +1,99 @@
+package compose
+
+import (
+	"context"
+	"log"
+	"os"
+	"os/exec"
+	"os/user"
+	"strings"
+	"sync"
+)
+
+// Ways of running docker commands as another user.
+const (
+	RunAsSudo       = "sudo"
+	RunAsMachinectl = "machinectl"
+)
+
+// RunAs is the user docker CLI commands run as, for hosts where the compose
+// project files and a rootless Docker daemon belong to a non-root user.
+type RunAs struct {
+	User   string // Empty runs commands as docksmith's own user
+	Method string // RunAsSudo or RunAsMachinectl
+	UID    string // Numeric ID of User, for its runtime directory
+}
+
+// RunAsFromEnv reads COMPOSE_RUN_AS and COMPOSE_RUN_AS_METHOD (sudo by default).
+// sudo needs passwordless rights to run docker as the user; machinectl starts a
+// login session of the user, which sets up its runtime directory on its own.
+func RunAsFromEnv() RunAs {
+	name := strings.TrimSpace(os.Getenv("COMPOSE_RUN_AS"))
+	if name == "" {
+		return RunAs{}
+	}
+	account, err := user.Lookup(name)
+	if err != nil {
+		log.Printf("Warning: Invalid COMPOSE_RUN_AS '%s', running docker commands as docksmith's user: %v", name, err)
+		return RunAs{}
+	}
+
+	value := os.Getenv("COMPOSE_RUN_AS_METHOD")
+	method := strings.ToLower(strings.TrimSpace(value))
+	switch method {
+	case "":
+		method = RunAsSudo
+	case RunAsSudo, RunAsMachinectl:
+	default:
+		log.Printf("Warning: Invalid COMPOSE_RUN_AS_METHOD '%s', using %s", value, RunAsSudo)
+		method = RunAsSudo
+	}
+
+	log.Printf("Using COMPOSE_RUN_AS: %s (via %s)", name, method)
+	return RunAs{User: name, Method: method, UID: account.Uid}
+}
+
+// runAs is read from the environment the first time a docker command runs.
+var runAs = sync.OnceValue(RunAsFromEnv)
+
+// dockerCommand returns a docker CLI command with the extra KEY=value variables
+// added to docksmith's environment, run as the user of runAs if one is set.
+func dockerCommand(ctx context.Context, extra []string, args ...string) *exec.Cmd {
+	return runAs().Command(ctx, extra, args...)
+}
+
+// Command returns a docker CLI command run as r.User. sudo resets the environment,
+// so DOCKER_HOST, the user's runtime directory, and the extra variables are passed
+// to docker explicitly.
+func (r RunAs) Command(ctx context.Context, extra []string, args ...string) *exec.Cmd {
+	if r.User == "" {
+		cmd := exec.CommandContext(ctx, "docker", args...)
+		if len(extra) > 0 {
+			cmd.Env = append(os.Environ(), extra...)
+		}
+		return cmd
+	}
+
+	var env []string
+	if host := os.Getenv("DOCKER_HOST"); host != "" {
+		env = append(env, "DOCKER_HOST="+host)
+	}
+
+	if r.Method == RunAsMachinectl {
+		shellArgs := []string{"shell", "--quiet", "--uid=" + r.User}
+		for _, v := range append(env, extra...) {
+			shellArgs = append(shellArgs, "--setenv="+v)
+		}
+		shellArgs = append(shellArgs, ".host", "/usr/bin/env", "docker")
+		return exec.CommandContext(ctx, "machinectl", append(shellArgs, args...)...)
+	}
+
+	if r.UID != "" {
+		env = append(env, "XDG_RUNTIME_DIR=/run/user/"+r.UID)
+	}
+	sudoArgs := []string{"-n", "-H", "-u", r.User, "--", "env"}
+	sudoArgs = append(sudoArgs, env...)
+	sudoArgs = append(sudoArgs, extra...)
+	sudoArgs = append(sudoArgs, "docker")
+	return exec.CommandContext(ctx, "sudo", append(sudoArgs, args...)...)
+}
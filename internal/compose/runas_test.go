@@ -0,0 +1,55 @@
+package compose
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestRunAsCommand tests wrapping docker commands to run as another user
+func TestRunAsCommand(t *testing.T) {
+	t.Setenv("DOCKER_HOST", "unix:///run/user/1000/docker.sock")
+	ctx := context.Background()
+	extra := []string{"TZ=UTC"}
+
+	cmd := RunAs{}.Command(ctx, extra, "compose", "up", "-d")
+	assert.Equal(t, []string{"docker", "compose", "up", "-d"}, cmd.Args)
+	assert.Contains(t, cmd.Env, "TZ=UTC")
+
+	cmd = RunAs{}.Command(ctx, nil, "ps")
+	assert.Nil(t, cmd.Env, "inherits docksmith's environment")
+
+	cmd = RunAs{User: "deploy", Method: RunAsSudo, UID: "1000"}.Command(ctx, extra, "compose", "up", "-d")
+	assert.Equal(t, []string{
+		"sudo", "-n", "-H", "-u", "deploy", "--", "env",
+		"DOCKER_HOST=unix:///run/user/1000/docker.sock", "XDG_RUNTIME_DIR=/run/user/1000", "TZ=UTC",
+		"docker", "compose", "up", "-d",
+	}, cmd.Args)
+
+	cmd = RunAs{User: "deploy", Method: RunAsMachinectl, UID: "1000"}.Command(ctx, extra, "compose", "up", "-d")
+	assert.Equal(t, []string{
+		"machinectl", "shell", "--quiet", "--uid=deploy",
+		"--setenv=DOCKER_HOST=unix:///run/user/1000/docker.sock", "--setenv=TZ=UTC",
+		".host", "/usr/bin/env", "docker", "compose", "up", "-d",
+	}, cmd.Args)
+}
+
+// TestRunAsFromEnv tests reading the user docker commands run as
+func TestRunAsFromEnv(t *testing.T) {
+	t.Setenv("COMPOSE_RUN_AS", "")
+	assert.Equal(t, RunAs{}, RunAsFromEnv())
+
+	t.Setenv("COMPOSE_RUN_AS", "root")
+	t.Setenv("COMPOSE_RUN_AS_METHOD", "")
+	assert.Equal(t, RunAs{User: "root", Method: RunAsSudo, UID: "0"}, RunAsFromEnv())
+
+	t.Setenv("COMPOSE_RUN_AS_METHOD", "Machinectl")
+	assert.Equal(t, RunAsMachinectl, RunAsFromEnv().Method)
+
+	t.Setenv("COMPOSE_RUN_AS_METHOD", "su")
+	assert.Equal(t, RunAsSudo, RunAsFromEnv().Method)
+
+	t.Setenv("COMPOSE_RUN_AS", "no-such-user-docksmith")
+	assert.Equal(t, RunAs{}, RunAsFromEnv())
+}
@@ -0,0 +1,118 @@
+package compose
+
+import (
+	"log"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/chis/docksmith/internal/docker"
+	"github.com/chis/docksmith/internal/scripts"
+)
+
+// StopSettings is how a container is stopped: how long it gets to exit after the
+// stop signal before it is killed, and the signal. Zero values leave Docker's
+// defaults (the container's own configuration, or 10 seconds and SIGTERM).
+type StopSettings struct {
+	Timeout time.Duration
+	Signal  string
+
+	labelTimeout bool // Timeout comes from the docksmith.stop-timeout label
+}
+
+// StopSettingsFor returns how a container is stopped. The stop_grace_period and
+// stop_signal of its service are read from the compose file at composeFilePath, so
+// changes to them apply before the container has been recreated with them. The
+// docksmith.stop-timeout label overrides stop_grace_period.
+func StopSettingsFor(container *docker.Container, composeFilePath string) StopSettings {
+	var settings StopSettings
+	if svc := stopService(container, composeFilePath); svc != nil {
+		if value := serviceScalar(svc, "stop_grace_period"); value != "" {
+			if timeout, ok := parseStopTimeout(value); ok {
+				settings.Timeout = timeout
+			} else {
+				log.Printf("COMPOSE: Invalid stop_grace_period %q of service %s, using Docker's default", value, svc.Name)
+			}
+		}
+		settings.Signal = serviceScalar(svc, "stop_signal")
+	}
+
+	if value := strings.TrimSpace(container.Labels[scripts.StopTimeoutLabel]); value != "" {
+		if timeout, ok := parseStopTimeout(value); ok {
+			settings.Timeout = timeout
+			settings.labelTimeout = true
+		} else {
+			log.Printf("COMPOSE: Invalid %s %q on %s, ignoring it", scripts.StopTimeoutLabel, value, container.Name)
+		}
+	}
+	return settings
+}
+
+// StopArgs returns the arguments of the docker stop command for a container.
+func (s StopSettings) StopArgs(containerName string) []string {
+	args := []string{"stop"}
+	if s.Timeout > 0 {
+		args = append(args, "-t", timeoutSeconds(s.Timeout))
+	}
+	if s.Signal != "" {
+		args = append(args, "--signal", s.Signal)
+	}
+	return append(args, containerName)
+}
+
+// composeTimeoutArgs returns the timeout flag of docker compose commands that stop
+// the container. Compose applies stop_grace_period itself, so only the label is passed.
+func (s StopSettings) composeTimeoutArgs() []string {
+	if !s.labelTimeout {
+		return nil
+	}
+	return []string{"--timeout", timeoutSeconds(s.Timeout)}
+}
+
+// stopService returns the compose service of a container, or nil when the compose
+// file cannot be read or does not define it.
+func stopService(container *docker.Container, composeFilePath string) *Service {
+	serviceName := container.Labels["com.docker.compose.service"]
+	if composeFilePath == "" || serviceName == "" {
+		return nil
+	}
+	cf, err := LoadComposeFileOrIncluded(composeFilePath, serviceName)
+	if err != nil {
+		return nil
+	}
+	svc, err := cf.FindServiceByContainerName(serviceName)
+	if err != nil {
+		return nil
+	}
+	return svc
+}
+
+// serviceScalar returns the value of a scalar key of a service, or "".
+func serviceScalar(svc *Service, key string) string {
+	if svc.Node == nil || svc.Node.Kind != yaml.MappingNode {
+		return ""
+	}
+	for i := 0; i < len(svc.Node.Content)-1; i += 2 {
+		if svc.Node.Content[i].Value == key && svc.Node.Content[i+1].Kind == yaml.ScalarNode {
+			return strings.TrimSpace(svc.Node.Content[i+1].Value)
+		}
+	}
+	return ""
+}
+
+// parseStopTimeout parses a stop timeout given as a duration ("1m30s") or seconds ("90").
+func parseStopTimeout(value string) (time.Duration, bool) {
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(seconds) * time.Second, seconds > 0
+	}
+	timeout, err := time.ParseDuration(value)
+	return timeout, err == nil && timeout > 0
+}
+
+// timeoutSeconds formats a stop timeout as whole seconds, rounded up.
+func timeoutSeconds(timeout time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(timeout.Seconds())))
+}
@@ -0,0 +1,63 @@
+package compose
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/chis/docksmith/internal/docker"
+)
+
+// TestStopSettingsFor tests reading stop settings from the compose file and label
+func TestStopSettingsFor(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "docker-compose.yml")
+	require.NoError(t, os.WriteFile(path, []byte(`services:
+  db:
+    image: postgres:16
+    stop_grace_period: 1m30s
+    stop_signal: SIGINT
+  web:
+    image: nginx:1.25
+`), 0644))
+
+	service := func(name string, labels map[string]string) *docker.Container {
+		if labels == nil {
+			labels = map[string]string{}
+		}
+		labels["com.docker.compose.service"] = name
+		return &docker.Container{Name: name, Labels: labels}
+	}
+
+	t.Run("reads stop_grace_period and stop_signal", func(t *testing.T) {
+		settings := StopSettingsFor(service("db", nil), path)
+		assert.Equal(t, 90*time.Second, settings.Timeout)
+		assert.Equal(t, "SIGINT", settings.Signal)
+		assert.Equal(t, []string{"stop", "-t", "90", "--signal", "SIGINT", "db"}, settings.StopArgs("db"))
+		assert.Nil(t, settings.composeTimeoutArgs(), "compose applies stop_grace_period itself")
+	})
+
+	t.Run("label overrides stop_grace_period", func(t *testing.T) {
+		settings := StopSettingsFor(service("db", map[string]string{"docksmith.stop-timeout": "5m"}), path)
+		assert.Equal(t, 5*time.Minute, settings.Timeout)
+		assert.Equal(t, []string{"--timeout", "300"}, settings.composeTimeoutArgs())
+	})
+
+	t.Run("label accepts seconds", func(t *testing.T) {
+		settings := StopSettingsFor(service("web", map[string]string{"docksmith.stop-timeout": "45"}), path)
+		assert.Equal(t, []string{"stop", "-t", "45", "web"}, settings.StopArgs("web"))
+	})
+
+	t.Run("invalid label is ignored", func(t *testing.T) {
+		settings := StopSettingsFor(service("db", map[string]string{"docksmith.stop-timeout": "soon"}), path)
+		assert.Equal(t, 90*time.Second, settings.Timeout)
+	})
+
+	t.Run("defaults without settings", func(t *testing.T) {
+		assert.Equal(t, []string{"stop", "web"}, StopSettingsFor(service("web", nil), path).StopArgs("web"))
+		assert.Equal(t, []string{"stop", "db"}, StopSettingsFor(service("db", nil), "").StopArgs("db"))
+	})
+}
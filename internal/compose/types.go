@@ -8,11 +8,14 @@ type ComposeFile struct {
 	// Path is the absolute path to the compose file
 	Path string
 
-	// Root is the root YAML node containing the entire document
+	// Root is the root YAML node of the document containing the services
 	Root *yaml.Node
 
 	// Services is a reference to the services node for quick access
 	Services *yaml.Node
+
+	// documents holds every YAML document in the file, including Root
+	documents []*yaml.Node
 }
 
 // Service represents a service definition within a compose file.
@@ -0,0 +1,26 @@
+package compose
+
+import (
+	"crypto/sha256"
+	"os"
+	"sync"
+)
+
+// lastWrites maps a compose file path to the SHA-256 of the content Docksmith last
+// wrote to it, so edits made outside Docksmith can be told apart from its own.
+var lastWrites sync.Map
+
+// writeComposeFile writes a compose file and records the content as Docksmith's own edit.
+func writeComposeFile(path string, data []byte) error {
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return err
+	}
+	lastWrites.Store(path, sha256.Sum256(data))
+	return nil
+}
+
+// WrittenByDocksmith reports whether data is the content Docksmith last wrote to path.
+func WrittenByDocksmith(path string, data []byte) bool {
+	sum, ok := lastWrites.Load(path)
+	return ok && sum.([sha256.Size]byte) == sha256.Sum256(data)
+}
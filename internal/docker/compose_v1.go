@@ -0,0 +1,87 @@
+package docker
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// ComposeVersionLabel is the version of docker compose that created a container
+	ComposeVersionLabel = "com.docker.compose.version"
+
+	// ComposeConfigFilesLabel lists the compose files of a container's project
+	ComposeConfigFilesLabel = "com.docker.compose.project.config_files"
+
+	// ComposeWorkingDirLabel is the directory a container's project was started from
+	ComposeWorkingDirLabel = "com.docker.compose.project.working_dir"
+)
+
+// defaultComposeFiles are the compose file names docker-compose looks for in the
+// working directory, in order.
+var defaultComposeFiles = []string{"docker-compose.yml", "docker-compose.yaml", "compose.yml", "compose.yaml"}
+
+// IsComposeV1 reports whether a container was created by docker-compose v1 (the
+// Python implementation). Its project names are normalized differently than by
+// docker compose v2 and its container names use underscores.
+func IsComposeV1(labels map[string]string) bool {
+	return strings.HasPrefix(labels[ComposeVersionLabel], "1.")
+}
+
+// normalizeComposeLabels returns the labels of a compose container with absolute
+// compose file paths in com.docker.compose.project.config_files. docker-compose v1
+// records the files relative to the working directory, and before 1.25 not at all;
+// then the default compose file of the working directory is used. exists reports
+// whether a compose file (a host path) exists. Labels are returned unchanged when
+// nothing needs to be filled in.
+func normalizeComposeLabels(labels map[string]string, exists func(hostPath string) bool) map[string]string {
+	if labels["com.docker.compose.project"] == "" {
+		return labels
+	}
+	workingDir := labels[ComposeWorkingDirLabel]
+	configFiles := labels[ComposeConfigFilesLabel]
+
+	var files []string
+	switch {
+	case configFiles != "":
+		for _, file := range strings.Split(configFiles, ",") {
+			file = strings.TrimSpace(file)
+			if file != "" && !filepath.IsAbs(file) && workingDir != "" {
+				file = filepath.Join(workingDir, file)
+			}
+			files = append(files, file)
+		}
+	case workingDir != "":
+		files = []string{filepath.Join(workingDir, defaultComposeFiles[0])}
+		for _, name := range defaultComposeFiles {
+			if path := filepath.Join(workingDir, name); exists(path) {
+				files = []string{path}
+				break
+			}
+		}
+	default:
+		return labels
+	}
+
+	normalized := strings.Join(files, ",")
+	if normalized == configFiles {
+		return labels
+	}
+	result := make(map[string]string, len(labels)+1)
+	for key, value := range labels {
+		result[key] = value
+	}
+	result[ComposeConfigFilesLabel] = normalized
+	return result
+}
+
+// composeFileExists reports whether a compose file on the host exists, looking it
+// up through the mounts of the docksmith container when it runs in one.
+func (s *Service) composeFileExists(hostPath string) bool {
+	path := hostPath
+	if s.pathTranslator != nil {
+		path = s.pathTranslator.TranslateToContainer(hostPath)
+	}
+	_, err := os.Stat(path)
+	return err == nil
+}
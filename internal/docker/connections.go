@@ -0,0 +1,143 @@
+package docker
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/client"
+	"github.com/docker/docker/pkg/stdcopy"
+)
+
+// TCP socket states in /proc/net/tcp
+const (
+	tcpEstablished = "01"
+	tcpListen      = "0A"
+)
+
+// CountConnections returns the number of established TCP connections to a running
+// container's published ports, or to its listening ports when it publishes none
+// (host networking). The socket tables are read from /proc/<pid>/net when Docksmith
+// shares the host PID namespace, otherwise with `docker exec cat` in the container.
+func CountConnections(ctx context.Context, cli *client.Client, containerID string) (int, error) {
+	inspect, err := cli.ContainerInspect(ctx, containerID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to inspect container: %w", err)
+	}
+	if inspect.State == nil || !inspect.State.Running {
+		return 0, nil
+	}
+
+	tables, err := readProcNetTCP(inspect.State.Pid)
+	if err != nil {
+		if tables, err = execProcNetTCP(ctx, cli, containerID); err != nil {
+			return 0, err
+		}
+	}
+
+	ports := make(map[uint16]bool)
+	if inspect.NetworkSettings != nil {
+		for port, bindings := range inspect.NetworkSettings.Ports {
+			if port.Proto() == "tcp" && len(bindings) > 0 {
+				ports[uint16(port.Int())] = true
+			}
+		}
+	}
+	return countEstablished(tables, ports), nil
+}
+
+// readProcNetTCP reads the TCP socket tables of a process's network namespace.
+func readProcNetTCP(pid int) (string, error) {
+	if pid <= 0 {
+		return "", fmt.Errorf("container has no process")
+	}
+	var tables strings.Builder
+	for _, name := range []string{"tcp", "tcp6"} {
+		data, err := os.ReadFile(fmt.Sprintf("/proc/%d/net/%s", pid, name))
+		if err != nil {
+			if name == "tcp6" && os.IsNotExist(err) {
+				continue // IPv6 disabled
+			}
+			return "", err
+		}
+		tables.Write(data)
+	}
+	return tables.String(), nil
+}
+
+// execProcNetTCP reads the TCP socket tables by running cat in the container.
+func execProcNetTCP(ctx context.Context, cli *client.Client, containerID string) (string, error) {
+	exec, err := cli.ContainerExecCreate(ctx, containerID, container.ExecOptions{
+		Cmd:          []string{"cat", "/proc/net/tcp", "/proc/net/tcp6"},
+		AttachStdout: true,
+		AttachStderr: true,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to create exec: %w", err)
+	}
+	resp, err := cli.ContainerExecAttach(ctx, exec.ID, container.ExecAttachOptions{})
+	if err != nil {
+		return "", fmt.Errorf("failed to attach to exec: %w", err)
+	}
+	defer resp.Close()
+
+	// tcp6 is missing when IPv6 is disabled, so a failing exit code is not an error
+	var stdout, stderr bytes.Buffer
+	if _, err := stdcopy.StdCopy(&stdout, &stderr, resp.Reader); err != nil {
+		return "", fmt.Errorf("failed to read exec output: %w", err)
+	}
+	if stdout.Len() == 0 {
+		return "", fmt.Errorf("failed to read socket tables: %s", strings.TrimSpace(stderr.String()))
+	}
+	return stdout.String(), nil
+}
+
+// countEstablished counts the established connections in /proc/net/tcp tables whose
+// local port is in ports. With no ports, the listening ports in the tables are used,
+// so outgoing connections are not counted.
+func countEstablished(tables string, ports map[uint16]bool) int {
+	type socket struct {
+		port  uint16
+		state string
+	}
+	var sockets []socket
+	scanner := bufio.NewScanner(strings.NewReader(tables))
+	for scanner.Scan() {
+		// sl local_address rem_address st ...; addresses are hex ip:port
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 4 || fields[0] == "sl" {
+			continue
+		}
+		_, hexPort, ok := strings.Cut(fields[1], ":")
+		if !ok {
+			continue
+		}
+		port, err := strconv.ParseUint(hexPort, 16, 16)
+		if err != nil {
+			continue
+		}
+		sockets = append(sockets, socket{port: uint16(port), state: fields[3]})
+	}
+
+	if len(ports) == 0 {
+		ports = make(map[uint16]bool)
+		for _, s := range sockets {
+			if s.state == tcpListen {
+				ports[s.port] = true
+			}
+		}
+	}
+
+	count := 0
+	for _, s := range sockets {
+		if s.state == tcpEstablished && ports[s.port] {
+			count++
+		}
+	}
+	return count
+}
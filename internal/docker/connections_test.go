@@ -0,0 +1,36 @@
+package docker
+
+import "testing"
+
+// Sockets of a server on port 32400 (0x7E90): listening, two clients connected to
+// it, one connection in TIME_WAIT, and an outgoing connection to port 443.
+const testProcNetTCP = `  sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode
+   0: 00000000:7E90 00000000:0000 0A 00000000:00000000 00:00000000 00000000     0        0 1 1 0000000000000000 100 0 0 10 0
+   1: 0200A8C0:7E90 0300A8C0:D431 01 00000000:00000000 00:00000000 00000000     0        0 2 1 0000000000000000 20 4 30 10 -1
+   2: 0200A8C0:7E90 0400A8C0:C350 01 00000000:00000000 00:00000000 00000000     0        0 3 1 0000000000000000 20 4 30 10 -1
+   3: 0200A8C0:7E90 0500A8C0:C351 06 00000000:00000000 00:00000000 00000000     0        0 0 3 0000000000000000
+   4: 0200A8C0:9C40 08080808:01BB 01 00000000:00000000 00:00000000 00000000     0        0 4 1 0000000000000000 20 4 30 10 -1
+  sl  local_address                         remote_address                        st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode
+   0: 00000000000000000000000000000000:1F90 00000000000000000000000000000000:0000 0A 00000000:00000000 00:00000000 00000000     0        0 5 1 0000000000000000 100 0 0 10 0
+   1: 0000000000000000FFFF00000200A8C0:1F90 0000000000000000FFFF00000600A8C0:D000 01 00000000:00000000 00:00000000 00000000     0        0 6 1 0000000000000000 20 4 30 10 -1
+`
+
+func TestCountEstablished(t *testing.T) {
+	tests := []struct {
+		name  string
+		ports map[uint16]bool
+		want  int
+	}{
+		{"published port", map[uint16]bool{32400: true}, 2},
+		{"published IPv6 port", map[uint16]bool{8080: true}, 1},
+		{"unused published port", map[uint16]bool{9000: true}, 0},
+		{"listening ports without published ports", nil, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := countEstablished(testProcNetTCP, tt.ports); got != tt.want {
+				t.Errorf("countEstablished() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
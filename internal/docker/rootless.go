@@ -0,0 +1,45 @@
+package docker
+
+import (
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// rootfulSocket is where a Docker daemon running as root listens.
+const rootfulSocket = "/var/run/docker.sock"
+
+// useRootlessHost points DOCKER_HOST at a rootless Docker daemon when it is not
+// set and no daemon listens on the rootful socket, so both the SDK client and the
+// docker CLI commands docksmith runs reach the same daemon.
+func useRootlessHost() {
+	host, ok := rootlessHost(os.Getenv, rootfulSocket, os.Getuid())
+	if !ok {
+		return
+	}
+	os.Setenv("DOCKER_HOST", host)
+	log.Printf("Using rootless Docker daemon: %s", host)
+}
+
+// rootlessHost returns the socket of a rootless Docker daemon: the docker.sock
+// in XDG_RUNTIME_DIR, or in the runtime directory of uid when it is unset.
+func rootlessHost(getenv func(string) string, rootful string, uid int) (string, bool) {
+	if getenv("DOCKER_HOST") != "" || exists(rootful) {
+		return "", false
+	}
+	runtimeDir := getenv("XDG_RUNTIME_DIR")
+	if runtimeDir == "" {
+		runtimeDir = filepath.Join("/run/user", strconv.Itoa(uid))
+	}
+	socket := filepath.Join(runtimeDir, "docker.sock")
+	if !exists(socket) {
+		return "", false
+	}
+	return "unix://" + socket, true
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
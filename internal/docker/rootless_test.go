@@ -0,0 +1,49 @@
+package docker
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRootlessHost(t *testing.T) {
+	dir := t.TempDir()
+	rootful := filepath.Join(dir, "rootful.sock")
+	runtimeDir := filepath.Join(dir, "runtime")
+	if err := os.MkdirAll(runtimeDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(runtimeDir, "docker.sock"), nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	env := map[string]string{"XDG_RUNTIME_DIR": runtimeDir}
+	getenv := func(key string) string { return env[key] }
+
+	host, ok := rootlessHost(getenv, rootful, 1000)
+	if !ok || host != "unix://"+filepath.Join(runtimeDir, "docker.sock") {
+		t.Errorf("Expected the rootless socket, got %q (%v)", host, ok)
+	}
+
+	// An explicit DOCKER_HOST wins
+	env["DOCKER_HOST"] = "tcp://docker:2375"
+	if host, ok := rootlessHost(getenv, rootful, 1000); ok {
+		t.Errorf("Expected DOCKER_HOST to be kept, got %q", host)
+	}
+	delete(env, "DOCKER_HOST")
+
+	// So does a daemon on the rootful socket
+	if err := os.WriteFile(rootful, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if host, ok := rootlessHost(getenv, rootful, 1000); ok {
+		t.Errorf("Expected the rootful socket to be used, got %q", host)
+	}
+	os.Remove(rootful)
+
+	// No rootless daemon
+	env["XDG_RUNTIME_DIR"] = filepath.Join(dir, "missing")
+	if host, ok := rootlessHost(getenv, rootful, 1000); ok {
+		t.Errorf("Expected no rootless socket, got %q", host)
+	}
+}
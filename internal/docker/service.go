@@ -3,6 +3,7 @@ package docker
 import (
 	"context"
 	"fmt"
+	"log"
 	"strings"
 	"sync"
 	"time"
@@ -20,12 +21,26 @@ import (
 type Service struct {
 	cli            *client.Client
 	pathTranslator *PathTranslator
+	labelOverrides LabelOverrideFunc
+}
+
+// LabelOverrideFunc returns labels stored outside Docker, by container name.
+// An empty value removes the label.
+type LabelOverrideFunc func(ctx context.Context) (map[string]map[string]string, error)
+
+// SetLabelOverrides sets where the labels of containers not managed by docker compose
+// are looked up, since their labels cannot be changed without recreating them by hand.
+// Labels of compose containers are edited in their compose file instead.
+func (s *Service) SetLabelOverrides(fn LabelOverrideFunc) {
+	s.labelOverrides = fn
 }
 
 // NewService creates a new Docker service that connects to the Docker socket.
-// It uses the default Docker host from environment variables or defaults to
-// unix:///var/run/docker.sock on Unix systems.
+// It uses the default Docker host from environment variables, the socket of a
+// rootless daemon in XDG_RUNTIME_DIR, or defaults to unix:///var/run/docker.sock
+// on Unix systems.
 func NewService() (*Service, error) {
+	useRootlessHost()
 	cli, err := client.NewClientWithOpts(
 		client.FromEnv,
 		client.WithAPIVersionNegotiation(),
@@ -57,10 +72,41 @@ func (s *Service) ListContainers(ctx context.Context) ([]Container, error) {
 	for _, c := range containers {
 		result = append(result, s.convertContainer(c))
 	}
+	s.applyLabelOverrides(ctx, result)
 
 	return result, nil
 }
 
+// applyLabelOverrides applies stored labels to containers not managed by docker compose.
+func (s *Service) applyLabelOverrides(ctx context.Context, containers []Container) {
+	if s.labelOverrides == nil {
+		return
+	}
+	overrides, err := s.labelOverrides(ctx)
+	if err != nil {
+		log.Printf("Warning: Failed to load stored container labels: %v", err)
+		return
+	}
+	for i := range containers {
+		labels, ok := overrides[containers[i].Name]
+		if !ok || containers[i].Stack != "" {
+			continue
+		}
+		merged := make(map[string]string, len(containers[i].Labels)+len(labels))
+		for key, value := range containers[i].Labels {
+			merged[key] = value
+		}
+		for key, value := range labels {
+			if value == "" {
+				delete(merged, key)
+			} else {
+				merged[key] = value
+			}
+		}
+		containers[i].Labels = merged
+	}
+}
+
 // IsLocalImage checks if an image was built locally by inspecting its RepoDigests.
 // Images pulled from a registry will have RepoDigests populated with the registry digest.
 // Locally built images will have empty RepoDigests.
@@ -177,7 +223,7 @@ func (s *Service) convertContainer(c container.Summary) Container {
 		Image:        c.Image,
 		State:        c.State,
 		HealthStatus: healthStatus,
-		Labels:       c.Labels,
+		Labels:       normalizeComposeLabels(c.Labels, s.composeFileExists),
 		Created:      c.Created,
 		Stack:        stack,
 	}
@@ -252,8 +298,9 @@ func (s *Service) GetContainerByName(ctx context.Context, containerName string)
 	for _, c := range containers {
 		for _, name := range c.Names {
 			if strings.TrimPrefix(name, "/") == containerName {
-				result := s.convertContainer(c)
-				return &result, nil
+				result := []Container{s.convertContainer(c)}
+				s.applyLabelOverrides(ctx, result)
+				return &result[0], nil
 			}
 		}
 	}
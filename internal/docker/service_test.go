@@ -2,6 +2,7 @@ package docker
 
 import (
 	"context"
+	"reflect"
 	"testing"
 
 	"github.com/docker/docker/api/types/container"
@@ -389,4 +390,92 @@ func TestCombinedStackGrouping(t *testing.T) {
 				container.Name, stack, expectedStack)
 		}
 	}
-}
\ No newline at end of file
+}
+func TestApplyLabelOverrides(t *testing.T) {
+	s := &Service{}
+	s.SetLabelOverrides(func(ctx context.Context) (map[string]map[string]string, error) {
+		return map[string]map[string]string{
+			"standalone": {"docksmith.ignore": "true", "docksmith.pre-update-check": ""},
+			"composed":   {"docksmith.ignore": "true"},
+		}, nil
+	})
+
+	original := map[string]string{"docksmith.pre-update-check": "check.sh", "app": "web"}
+	containers := []Container{
+		{Name: "standalone", Labels: original},
+		{Name: "composed", Stack: "media", Labels: map[string]string{}},
+		{Name: "other", Labels: map[string]string{"app": "db"}},
+	}
+	s.applyLabelOverrides(context.Background(), containers)
+
+	if want := map[string]string{"docksmith.ignore": "true", "app": "web"}; !reflect.DeepEqual(containers[0].Labels, want) {
+		t.Errorf("standalone labels = %v, want %v", containers[0].Labels, want)
+	}
+	if original["docksmith.pre-update-check"] != "check.sh" {
+		t.Error("labels returned by Docker must not be modified")
+	}
+	if len(containers[1].Labels) != 0 {
+		t.Errorf("compose containers keep their labels, got %v", containers[1].Labels)
+	}
+	if containers[2].Labels["app"] != "db" {
+		t.Errorf("containers without stored labels are unchanged, got %v", containers[2].Labels)
+	}
+}
+
+func TestNormalizeComposeLabels(t *testing.T) {
+	existing := map[string]bool{"/srv/legacy/docker-compose.yaml": true}
+	exists := func(path string) bool { return existing[path] }
+
+	tests := []struct {
+		name     string
+		labels   map[string]string
+		expected string
+	}{
+		{
+			name: "v2 absolute paths are kept",
+			labels: map[string]string{
+				"com.docker.compose.project": "media",
+				ComposeConfigFilesLabel:      "/srv/media/compose.yaml",
+				ComposeWorkingDirLabel:       "/srv/media",
+			},
+			expected: "/srv/media/compose.yaml",
+		},
+		{
+			name: "v1 relative paths are joined with the working directory",
+			labels: map[string]string{
+				"com.docker.compose.project": "myapp",
+				ComposeVersionLabel:          "1.29.2",
+				ComposeConfigFilesLabel:      "docker-compose.yml,docker-compose.override.yml",
+				ComposeWorkingDirLabel:       "/srv/my-app",
+			},
+			expected: "/srv/my-app/docker-compose.yml,/srv/my-app/docker-compose.override.yml",
+		},
+		{
+			name: "missing config files use the working directory's compose file",
+			labels: map[string]string{
+				"com.docker.compose.project": "legacy",
+				ComposeVersionLabel:          "1.24.1",
+				ComposeWorkingDirLabel:       "/srv/legacy",
+			},
+			expected: "/srv/legacy/docker-compose.yaml",
+		},
+		{
+			name:     "non-compose containers are left alone",
+			labels:   map[string]string{"app": "nginx"},
+			expected: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := normalizeComposeLabels(tt.labels, exists)
+			if result[ComposeConfigFilesLabel] != tt.expected {
+				t.Errorf("Expected config files %q, got %q", tt.expected, result[ComposeConfigFilesLabel])
+			}
+		})
+	}
+
+	if !IsComposeV1(map[string]string{ComposeVersionLabel: "1.29.2"}) || IsComposeV1(map[string]string{ComposeVersionLabel: "2.24.0"}) {
+		t.Error("Expected only compose 1.x containers to be detected as docker-compose v1")
+	}
+}
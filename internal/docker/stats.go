@@ -0,0 +1,72 @@
+package docker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/client"
+)
+
+// ResourceUsage is a container's CPU and memory usage, computed like `docker stats`.
+type ResourceUsage struct {
+	CPUPercent    float64 `json:"cpu_percent"` // Percent of one CPU; can exceed 100 on multiple CPUs
+	OnlineCPUs    uint32  `json:"online_cpus"`
+	MemoryUsage   uint64  `json:"memory_usage"`   // Bytes, excluding the page cache
+	MemoryLimit   uint64  `json:"memory_limit"`   // Bytes; the host memory when the container has no limit
+	MemoryPercent float64 `json:"memory_percent"` // Percent of MemoryLimit
+}
+
+// GetResourceUsage samples the resource usage of a running container.
+func GetResourceUsage(ctx context.Context, cli *client.Client, containerID string) (*ResourceUsage, error) {
+	// Without streaming, the daemon takes two samples so the CPU delta is populated
+	resp, err := cli.ContainerStats(ctx, containerID, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get container stats: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var stats container.StatsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&stats); err != nil {
+		return nil, fmt.Errorf("failed to decode container stats: %w", err)
+	}
+	usage := CalculateResourceUsage(stats)
+	return &usage, nil
+}
+
+// GetResourceUsage samples the resource usage of a running container.
+func (s *Service) GetResourceUsage(ctx context.Context, containerID string) (*ResourceUsage, error) {
+	return GetResourceUsage(ctx, s.cli, containerID)
+}
+
+// CalculateResourceUsage computes usage from a stats sample the way the Docker CLI does.
+func CalculateResourceUsage(stats container.StatsResponse) ResourceUsage {
+	usage := ResourceUsage{
+		OnlineCPUs:  stats.CPUStats.OnlineCPUs,
+		MemoryLimit: stats.MemoryStats.Limit,
+	}
+	if usage.OnlineCPUs == 0 {
+		usage.OnlineCPUs = uint32(len(stats.CPUStats.CPUUsage.PercpuUsage))
+	}
+
+	cpuDelta := float64(stats.CPUStats.CPUUsage.TotalUsage) - float64(stats.PreCPUStats.CPUUsage.TotalUsage)
+	systemDelta := float64(stats.CPUStats.SystemUsage) - float64(stats.PreCPUStats.SystemUsage)
+	if cpuDelta > 0 && systemDelta > 0 {
+		usage.CPUPercent = cpuDelta / systemDelta * float64(usage.OnlineCPUs) * 100
+	}
+
+	// The page cache is reclaimable, so it is not counted (cgroup v2 then v1 keys)
+	usage.MemoryUsage = stats.MemoryStats.Usage
+	cache, ok := stats.MemoryStats.Stats["inactive_file"]
+	if !ok {
+		cache = stats.MemoryStats.Stats["total_inactive_file"]
+	}
+	if cache < usage.MemoryUsage {
+		usage.MemoryUsage -= cache
+	}
+	if usage.MemoryLimit > 0 {
+		usage.MemoryPercent = float64(usage.MemoryUsage) / float64(usage.MemoryLimit) * 100
+	}
+	return usage
+}
@@ -0,0 +1,36 @@
+package docker
+
+import (
+	"testing"
+
+	"github.com/docker/docker/api/types/container"
+)
+
+func TestCalculateResourceUsage(t *testing.T) {
+	var stats container.StatsResponse
+	stats.CPUStats.CPUUsage.TotalUsage = 3_000_000
+	stats.CPUStats.SystemUsage = 20_000_000
+	stats.CPUStats.OnlineCPUs = 4
+	stats.PreCPUStats.CPUUsage.TotalUsage = 1_000_000
+	stats.PreCPUStats.SystemUsage = 10_000_000
+	stats.MemoryStats.Usage = 600 << 20
+	stats.MemoryStats.Limit = 1 << 30
+	stats.MemoryStats.Stats = map[string]uint64{"inactive_file": 88 << 20}
+
+	usage := CalculateResourceUsage(stats)
+	if usage.CPUPercent != 80 {
+		t.Errorf("expected 80%% CPU, got %v", usage.CPUPercent)
+	}
+	if usage.MemoryUsage != 512<<20 {
+		t.Errorf("expected 512 MiB memory without the page cache, got %d", usage.MemoryUsage)
+	}
+	if usage.MemoryPercent != 50 {
+		t.Errorf("expected 50%% memory, got %v", usage.MemoryPercent)
+	}
+
+	// The first sample of a container has no previous CPU reading
+	usage = CalculateResourceUsage(container.StatsResponse{})
+	if usage.CPUPercent != 0 || usage.MemoryPercent != 0 {
+		t.Errorf("expected no usage from an empty sample, got %+v", usage)
+	}
+}
@@ -0,0 +1,248 @@
+// Package eol looks up the end-of-life dates of release cycles on endoflife.date,
+// so checks can flag containers running a version that no longer receives fixes
+// even when their pinned line has no newer tag.
+package eol
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultBaseURL is the endoflife.date API.
+const DefaultBaseURL = "https://endoflife.date/api"
+
+const (
+	requestTimeout = 10 * time.Second
+	cacheTTL       = 24 * time.Hour // Lifecycle data changes a few times a year
+	errorTTL       = time.Hour      // Failed lookups are retried after this
+)
+
+// imageProducts maps image names (the last path segment of the repository) to
+// endoflife.date products, for the images whose versions follow the product's.
+var imageProducts = map[string]string{
+	"postgres":        "postgresql",
+	"postgresql":      "postgresql",
+	"mysql":           "mysql",
+	"mariadb":         "mariadb",
+	"mongo":           "mongodb",
+	"mongodb":         "mongodb",
+	"redis":           "redis",
+	"valkey":          "valkey",
+	"elasticsearch":   "elasticsearch",
+	"rabbitmq":        "rabbitmq",
+	"nginx":           "nginx",
+	"haproxy":         "haproxy",
+	"traefik":         "traefik",
+	"tomcat":          "tomcat",
+	"node":            "nodejs",
+	"python":          "python",
+	"php":             "php",
+	"golang":          "go",
+	"ruby":            "ruby",
+	"eclipse-temurin": "eclipse-temurin",
+	"debian":          "debian",
+	"ubuntu":          "ubuntu",
+	"nextcloud":       "nextcloud",
+}
+
+// ProductForImage returns the endoflife.date product of an image repository such
+// as "library/postgres" or "bitnami/postgresql".
+func ProductForImage(repository string) (string, bool) {
+	name := strings.ToLower(repository[strings.LastIndex(repository, "/")+1:])
+	product, ok := imageProducts[name]
+	return product, ok
+}
+
+// Cycle is a release cycle of a product, e.g. PostgreSQL 16 or nginx 1.26.
+type Cycle struct {
+	Cycle       string `json:"cycle"`
+	ReleaseDate string `json:"releaseDate,omitempty"`
+	EOL         Date   `json:"eol"`
+	Latest      string `json:"latest,omitempty"` // Newest release of the cycle
+}
+
+// Date is an endoflife.date date field, which is either a date or a boolean
+// when the date is unknown.
+type Date struct {
+	Time    time.Time // Zero when no date is published
+	Reached bool      // The date passed, or the field is true
+}
+
+// UnmarshalJSON accepts "2026-11-12", true, and false.
+func (d *Date) UnmarshalJSON(data []byte) error {
+	var flag bool
+	if err := json.Unmarshal(data, &flag); err == nil {
+		*d = Date{Reached: flag}
+		return nil
+	}
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return fmt.Errorf("invalid date %s", data)
+	}
+	t, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		return fmt.Errorf("invalid date %q: %w", value, err)
+	}
+	*d = Date{Time: t, Reached: !t.After(time.Now())}
+	return nil
+}
+
+// MarshalJSON writes the date, or the boolean when there is none.
+func (d Date) MarshalJSON() ([]byte, error) {
+	if d.Time.IsZero() {
+		return json.Marshal(d.Reached)
+	}
+	return json.Marshal(d.Time.Format(time.DateOnly))
+}
+
+// MatchCycle returns the cycle a version belongs to: the first cycle whose
+// components match the leading components of the version, so "16.4" is in
+// cycle "16" and "1.26.2" in cycle "1.26". Cycles are listed newest first.
+func MatchCycle(cycles []Cycle, version string) (Cycle, bool) {
+	parts := versionParts(version)
+	if len(parts) == 0 {
+		return Cycle{}, false
+	}
+	for _, cycle := range cycles {
+		cycleParts := versionParts(cycle.Cycle)
+		if len(cycleParts) == 0 || len(cycleParts) > len(parts) {
+			continue
+		}
+		matched := true
+		for i, part := range cycleParts {
+			if part != parts[i] {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return cycle, true
+		}
+	}
+	return Cycle{}, false
+}
+
+// versionParts splits a version into its numeric components, ignoring a "v"
+// prefix and anything after the first non-numeric component.
+func versionParts(version string) []int {
+	version = strings.TrimPrefix(strings.TrimPrefix(version, "v"), "V")
+	var parts []int
+	for _, field := range strings.Split(version, ".") {
+		end := 0
+		for end < len(field) && field[end] >= '0' && field[end] <= '9' {
+			end++
+		}
+		if end == 0 {
+			break
+		}
+		n, err := strconv.Atoi(field[:end])
+		if err != nil {
+			break
+		}
+		parts = append(parts, n)
+		if end < len(field) {
+			break
+		}
+	}
+	return parts
+}
+
+// cachedCycles is the result of a product lookup.
+type cachedCycles struct {
+	cycles  []Cycle
+	err     error
+	expires time.Time
+}
+
+// Client looks up product release cycles, caching them for a day.
+type Client struct {
+	baseURL string
+	client  *http.Client
+
+	mu    sync.Mutex
+	cache map[string]cachedCycles
+}
+
+// NewClient creates a client for the endoflife.date API at baseURL.
+func NewClient(baseURL string) *Client {
+	return &Client{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		client:  &http.Client{Timeout: requestTimeout},
+		cache:   make(map[string]cachedCycles),
+	}
+}
+
+// NewClientFromEnv creates a client unless EOL_CHECK is false. EOL_API_URL
+// points it at a mirror of the API. Returns nil when lookups are disabled.
+func NewClientFromEnv() *Client {
+	if value := os.Getenv("EOL_CHECK"); value != "" {
+		enabled, err := strconv.ParseBool(value)
+		if err != nil {
+			log.Printf("Warning: Invalid EOL_CHECK '%s', checking end-of-life dates", value)
+		} else if !enabled {
+			log.Printf("Using EOL_CHECK: %v", enabled)
+			return nil
+		}
+	}
+	baseURL := DefaultBaseURL
+	if value := os.Getenv("EOL_API_URL"); value != "" {
+		log.Printf("Using EOL_API_URL: %s", value)
+		baseURL = value
+	}
+	return NewClient(baseURL)
+}
+
+// Cycles returns the release cycles of a product, newest first.
+func (c *Client) Cycles(ctx context.Context, product string) ([]Cycle, error) {
+	c.mu.Lock()
+	cached, ok := c.cache[product]
+	c.mu.Unlock()
+	if ok && time.Now().Before(cached.expires) {
+		return cached.cycles, cached.err
+	}
+
+	cycles, err := c.fetch(ctx, product)
+	ttl := cacheTTL
+	if err != nil {
+		ttl = errorTTL
+	}
+	c.mu.Lock()
+	c.cache[product] = cachedCycles{cycles: cycles, err: err, expires: time.Now().Add(ttl)}
+	c.mu.Unlock()
+	return cycles, err
+}
+
+// fetch requests the release cycles of a product.
+func (c *Client) fetch(ctx context.Context, product string) ([]Cycle, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/"+url.PathEscape(product)+".json", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s lifecycle: %w", product, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+		return nil, fmt.Errorf("failed to fetch %s lifecycle: status %d", product, resp.StatusCode)
+	}
+
+	var cycles []Cycle
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 4<<20)).Decode(&cycles); err != nil {
+		return nil, fmt.Errorf("failed to decode %s lifecycle: %w", product, err)
+	}
+	return cycles, nil
+}
@@ -0,0 +1,109 @@
+package eol
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const postgresCycles = `[
+	{"cycle": "17", "releaseDate": "2024-09-26", "eol": "2029-11-08", "latest": "17.2"},
+	{"cycle": "16", "releaseDate": "2023-09-14", "eol": "2028-11-09", "latest": "16.6"},
+	{"cycle": "12", "releaseDate": "2019-10-03", "eol": "2024-11-21", "latest": "12.22"},
+	{"cycle": "9.6", "releaseDate": "2016-09-29", "eol": true, "latest": "9.6.24"}
+]`
+
+func TestMatchCycle(t *testing.T) {
+	var cycles []Cycle
+	require.NoError(t, json.Unmarshal([]byte(postgresCycles), &cycles))
+
+	tests := []struct {
+		version string
+		cycle   string
+		eol     bool
+	}{
+		{"16.4", "16", false},
+		{"v17", "17", false},
+		{"12.22", "12", true},
+		{"9.6.24", "9.6", true},
+		{"16.4-bookworm", "16", false},
+	}
+	for _, tt := range tests {
+		cycle, ok := MatchCycle(cycles, tt.version)
+		require.True(t, ok, tt.version)
+		assert.Equal(t, tt.cycle, cycle.Cycle, tt.version)
+		assert.Equal(t, tt.eol, cycle.EOL.Reached, tt.version)
+	}
+
+	for _, version := range []string{"9", "13.1", "latest", ""} {
+		_, ok := MatchCycle(cycles, version)
+		assert.False(t, ok, version)
+	}
+}
+
+func TestDate(t *testing.T) {
+	var cycle Cycle
+	require.NoError(t, json.Unmarshal([]byte(`{"cycle": "1.26", "eol": false}`), &cycle))
+	assert.False(t, cycle.EOL.Reached)
+	assert.True(t, cycle.EOL.Time.IsZero())
+
+	require.NoError(t, json.Unmarshal([]byte(`{"cycle": "12", "eol": "2024-11-21"}`), &cycle))
+	assert.True(t, cycle.EOL.Reached)
+	data, err := json.Marshal(cycle.EOL)
+	require.NoError(t, err)
+	assert.JSONEq(t, `"2024-11-21"`, string(data))
+
+	assert.Error(t, json.Unmarshal([]byte(`{"eol": "soon"}`), &cycle))
+}
+
+func TestProductForImage(t *testing.T) {
+	for repository, want := range map[string]string{
+		"library/postgres":   "postgresql",
+		"bitnami/postgresql": "postgresql",
+		"library/node":       "nodejs",
+		"library/nginx":      "nginx",
+	} {
+		product, ok := ProductForImage(repository)
+		assert.True(t, ok, repository)
+		assert.Equal(t, want, product, repository)
+	}
+	_, ok := ProductForImage("linuxserver/sonarr")
+	assert.False(t, ok)
+}
+
+func TestClient_Cycles(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if r.URL.Path != "/api/postgresql.json" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(postgresCycles))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL + "/api/")
+	ctx := context.Background()
+
+	cycles, err := client.Cycles(ctx, "postgresql")
+	require.NoError(t, err)
+	assert.Len(t, cycles, 4)
+	assert.Equal(t, "17", cycles[0].Cycle)
+
+	_, err = client.Cycles(ctx, "postgresql")
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), requests.Load(), "lookups are cached")
+
+	_, err = client.Cycles(ctx, "unknown")
+	assert.ErrorContains(t, err, "status 404")
+	_, err = client.Cycles(ctx, "unknown")
+	assert.Error(t, err)
+	assert.Equal(t, int32(2), requests.Load(), "failed lookups are cached too")
+}
@@ -10,14 +10,27 @@ import (
 
 // Event types for the update workflow
 const (
-	EventUpdateProgress   = "update.progress"
-	EventContainerUpdated = "container.updated"
-	EventCheckProgress    = "check.progress"
-	EventDroppedWarning   = "system.events_dropped" // Published when events are being dropped
+	EventUpdateProgress    = "update.progress"
+	EventContainerUpdated  = "container.updated"
+	EventCheckProgress     = "check.progress"
+	EventDroppedWarning    = "system.events_dropped" // Published when events are being dropped
+	EventApprovalRequested = "approval.requested"    // An update is waiting for operator approval
+	EventApprovalDecided   = "approval.decided"      // An approval was approved or rejected
+	EventProposalCreated   = "proposal.created"      // A compose change was proposed (propose-only mode)
+	EventComposeChanged    = "compose.changed"       // A compose file was edited outside Docksmith
+	EventCrashLoop         = "container.crash_loop"  // An updated container restarted too often in its observation window
+	EventOperationLog      = "operation.log"         // A line was added to the step log of an operation
+	EventQueueChanged      = "queue.changed"         // An operation was queued, started, removed from the queue, or reprioritized
+	EventContainerChanged  = "container.changed"     // A container was created, started, stopped, or removed (Docker events)
 )
 
+// historySize is how many published events are kept for replay to clients that
+// reconnect or poll
+const historySize = 1000
+
 // Event represents an event in the system
 type Event struct {
+	ID      int64                  `json:"id,omitempty"` // Assigned by Publish, increases with every event
 	Type    string                 `json:"type"`
 	Payload map[string]interface{} `json:"payload"`
 }
@@ -32,12 +45,21 @@ type Bus struct {
 	droppedCount    atomic.Int64  // Total dropped events for monitoring
 	lastDropWarning time.Time     // Rate limit drop warnings
 	dropWarningMu   sync.Mutex
+
+	historyMu sync.Mutex
+	history   []Event // Ring buffer of the most recently published events
+	next      int     // Index in history the next event is written to
+	lastID    int64
 }
 
-// NewBus creates a new event bus
+// NewBus creates a new event bus. Event IDs start at the creation time in
+// microseconds, so they keep increasing across restarts and a client holding an
+// ID from before a restart is not mistaken for being up to date.
 func NewBus() *Bus {
 	return &Bus{
 		subscribers: make(map[string][]Subscriber),
+		history:     make([]Event, 0, historySize),
+		lastID:      time.Now().UnixMicro(),
 	}
 }
 
@@ -71,6 +93,8 @@ func (b *Bus) Subscribe(eventType string) (Subscriber, func()) {
 // Publish sends an event to all subscribers of that event type.
 // Uses a brief retry with backoff before dropping events to handle transient congestion.
 func (b *Bus) Publish(event Event) {
+	b.record(&event)
+
 	// Snapshot subscribers under lock, then release before sending.
 	// This avoids deadlock: sendWithRetry -> recordDroppedEvent -> RLock (reentrant).
 	b.mu.RLock()
@@ -97,6 +121,53 @@ func (b *Bus) Publish(event Event) {
 	}
 }
 
+// record assigns the event its ID and keeps it for replay.
+func (b *Bus) record(event *Event) {
+	b.historyMu.Lock()
+	defer b.historyMu.Unlock()
+
+	b.lastID++
+	event.ID = b.lastID
+	if len(b.history) < historySize {
+		b.history = append(b.history, *event)
+	} else {
+		b.history[b.next] = *event
+	}
+	b.next = (b.next + 1) % historySize
+}
+
+// Since returns the kept events published after the event with ID afterID, oldest
+// first. complete is false when older events were discarded or afterID is unknown,
+// so some events after it can no longer be replayed.
+func (b *Bus) Since(afterID int64) (events []Event, complete bool) {
+	b.historyMu.Lock()
+	defer b.historyMu.Unlock()
+
+	events = []Event{}
+	if afterID >= b.lastID {
+		return events, afterID == b.lastID
+	}
+
+	oldest := 0
+	if len(b.history) == historySize {
+		oldest = b.next
+	}
+	for i := range b.history {
+		if event := b.history[(oldest+i)%len(b.history)]; event.ID > afterID {
+			events = append(events, event)
+		}
+	}
+	complete = len(events) > 0 && events[0].ID == afterID+1
+	return events, complete
+}
+
+// LastID returns the ID of the most recently published event.
+func (b *Bus) LastID() int64 {
+	b.historyMu.Lock()
+	defer b.historyMu.Unlock()
+	return b.lastID
+}
+
 // sendWithRetry attempts to send an event to a channel with brief retries.
 // Returns true if sent successfully, false if dropped.
 func (b *Bus) sendWithRetry(ch Subscriber, event Event, maxRetries int) bool {
@@ -439,3 +439,48 @@ func TestDropWarningEvent(t *testing.T) {
 	drainChannel(testCh)
 	drainChannel(wildcardCh)
 }
+
+func TestSinceReplaysPublishedEvents(t *testing.T) {
+	bus := NewBus()
+	start := bus.LastID()
+
+	for i := 0; i < 3; i++ {
+		bus.Publish(Event{Type: EventUpdateProgress, Payload: map[string]interface{}{"n": i}})
+	}
+
+	events, complete := bus.Since(start + 1)
+	if !complete || len(events) != 2 {
+		t.Fatalf("expected 2 events without a gap, got %d (complete=%v)", len(events), complete)
+	}
+	if events[0].ID != start+2 || events[0].Payload["n"] != 1 {
+		t.Errorf("unexpected first event: %+v", events[0])
+	}
+
+	if events, complete := bus.Since(bus.LastID()); !complete || len(events) != 0 {
+		t.Errorf("expected no events after the last ID, got %d (complete=%v)", len(events), complete)
+	}
+	if events, complete := bus.Since(0); complete || len(events) != 3 {
+		t.Errorf("expected all 3 events with a gap for an unknown ID, got %d (complete=%v)", len(events), complete)
+	}
+}
+
+func TestSinceDiscardsOldestEvents(t *testing.T) {
+	bus := NewBus()
+	start := bus.LastID()
+
+	for i := 0; i < historySize+10; i++ {
+		bus.Publish(Event{Type: EventCheckProgress})
+	}
+
+	events, complete := bus.Since(start)
+	if complete {
+		t.Error("expected a gap after the oldest events were discarded")
+	}
+	if len(events) != historySize || events[0].ID != start+11 || events[len(events)-1].ID != bus.LastID() {
+		t.Errorf("expected the newest %d events in order, got %d from %d", historySize, len(events), events[0].ID)
+	}
+
+	if _, complete := bus.Since(start + 10); !complete {
+		t.Error("expected no gap right before the oldest kept event")
+	}
+}
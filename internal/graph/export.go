@@ -0,0 +1,330 @@
+package graph
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Export is a JSON-friendly view of the graph for visualization.
+// Dependencies that name a compose service are resolved to the container
+// running that service in the same project.
+type Export struct {
+	Nodes  []ExportNode        `json:"nodes"`
+	Edges  []Edge              `json:"edges"`
+	Cycles []Cycle             `json:"cycles"`
+	Stacks map[string][]string `json:"stacks"`
+}
+
+// ExportNode is a container in an exported graph.
+type ExportNode struct {
+	ID                  string   `json:"id"`
+	Stack               string   `json:"stack,omitempty"`
+	Service             string   `json:"service,omitempty"`
+	Image               string   `json:"image,omitempty"`
+	State               string   `json:"state,omitempty"`
+	Dependencies        []string `json:"dependencies"`
+	MissingDependencies []string `json:"missing_dependencies,omitempty"` // Dependencies with no matching container
+	BlastRadius         []string `json:"blast_radius"`                   // Containers that directly or transitively depend on this one
+}
+
+// Edge types
+const (
+	EdgeDependsOn    = "depends_on"
+	EdgeNetworkMode  = "network_mode"
+	EdgeRestartAfter = "restart_after"
+)
+
+// Edge is a dependency: From depends on To, so To is updated or restarted first.
+type Edge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	Type string `json:"type"` // depends_on, network_mode, or restart_after
+	// Condition is the compose depends_on condition of depends_on edges
+	// (service_started, service_healthy, or service_completed_successfully).
+	Condition string `json:"condition,omitempty"`
+}
+
+// Cycle is a group of containers that depend on each other in a circle.
+type Cycle struct {
+	Containers []string `json:"containers"`
+	Sources    []string `json:"sources"` // Edge types forming the cycle
+	// RestartLoop is set when a docksmith.restart-after label is part of the cycle.
+	// Restarting any of the containers would restart the others endlessly.
+	RestartLoop bool `json:"restart_loop"`
+}
+
+// Export builds the visualization view of the graph. Nodes, edges, and cycles are sorted.
+func (g *Graph) Export() *Export {
+	ids := g.sortedIDs()
+	edges, missing := g.dependencyEdges(ids)
+	deps := edgeTargets(edges)
+
+	export := &Export{
+		Nodes:  make([]ExportNode, 0, len(ids)),
+		Edges:  edges,
+		Cycles: findCycles(ids, edges),
+		Stacks: make(map[string][]string),
+	}
+	for _, id := range ids {
+		node := g.Nodes[id]
+		exportNode := ExportNode{
+			ID:                  id,
+			Stack:               node.Metadata["project"],
+			Service:             node.Metadata["service"],
+			Image:               node.Metadata["image"],
+			State:               node.Metadata["state"],
+			Dependencies:        []string{},
+			MissingDependencies: missing[id],
+			BlastRadius:         blastRadius(deps, id),
+		}
+		for _, target := range deps[id] {
+			if !slices.Contains(exportNode.Dependencies, target) {
+				exportNode.Dependencies = append(exportNode.Dependencies, target)
+			}
+		}
+
+		if exportNode.Stack != "" {
+			export.Stacks[exportNode.Stack] = append(export.Stacks[exportNode.Stack], id)
+		}
+		export.Nodes = append(export.Nodes, exportNode)
+	}
+	return export
+}
+
+// Cycles returns every dependency cycle, combining compose dependencies with
+// docksmith.restart-after labels.
+func (g *Graph) Cycles() []Cycle {
+	ids := g.sortedIDs()
+	edges, _ := g.dependencyEdges(ids)
+	return findCycles(ids, edges)
+}
+
+// BlastRadius returns every container that directly or transitively depends on
+// id, and so is affected when it is updated or restarted.
+func (g *Graph) BlastRadius(id string) []string {
+	edges, _ := g.dependencyEdges(g.sortedIDs())
+	return blastRadius(edgeTargets(edges), id)
+}
+
+// DOT renders the graph in Graphviz DOT format, with containers clustered by stack.
+// Edges point from a container to the container it depends on; network_mode
+// edges are dashed and restart_after edges dotted.
+func (g *Graph) DOT() string {
+	export := g.Export()
+
+	var b strings.Builder
+	b.WriteString("digraph docksmith {\n")
+	b.WriteString("  rankdir=LR;\n")
+
+	stacks := make([]string, 0, len(export.Stacks))
+	for stack := range export.Stacks {
+		stacks = append(stacks, stack)
+	}
+	sort.Strings(stacks)
+	for i, stack := range stacks {
+		fmt.Fprintf(&b, "  subgraph cluster_%d {\n    label=%q;\n", i, stack)
+		for _, id := range export.Stacks[stack] {
+			fmt.Fprintf(&b, "    %q;\n", id)
+		}
+		b.WriteString("  }\n")
+	}
+	for _, node := range export.Nodes {
+		if node.Stack == "" {
+			fmt.Fprintf(&b, "  %q;\n", node.ID)
+		}
+	}
+
+	for _, edge := range export.Edges {
+		switch edge.Type {
+		case EdgeNetworkMode:
+			fmt.Fprintf(&b, "  %q -> %q [style=dashed];\n", edge.From, edge.To)
+		case EdgeRestartAfter:
+			fmt.Fprintf(&b, "  %q -> %q [style=dotted];\n", edge.From, edge.To)
+		default:
+			fmt.Fprintf(&b, "  %q -> %q;\n", edge.From, edge.To)
+		}
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// resolve maps a dependency of node to a container in the graph: the container
+// itself if dep is a container name, otherwise the container running service
+// dep in node's compose project.
+func (g *Graph) resolve(node *Node, dep string) (string, bool) {
+	if _, ok := g.Nodes[dep]; ok {
+		return dep, true
+	}
+	project := node.Metadata["project"]
+	if project == "" {
+		return "", false
+	}
+	for id, other := range g.Nodes {
+		if other.Metadata["project"] == project && other.Metadata["service"] == dep {
+			return id, true
+		}
+	}
+	return "", false
+}
+
+// sortedIDs returns the node IDs in order.
+func (g *Graph) sortedIDs() []string {
+	ids := make([]string, 0, len(g.Nodes))
+	for id := range g.Nodes {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// dependencyEdges resolves the compose dependencies and restart-after containers
+// of each node to edges between containers. Dependencies with no matching
+// container are returned per node.
+func (g *Graph) dependencyEdges(ids []string) ([]Edge, map[string][]string) {
+	edges := []Edge{}
+	missing := make(map[string][]string)
+	for _, id := range ids {
+		node := g.Nodes[id]
+
+		networkDep := strings.TrimPrefix(node.Metadata["network_mode"], "service:")
+		for _, dep := range node.Dependencies {
+			target, ok := g.resolve(node, dep)
+			if !ok {
+				missing[id] = append(missing[id], dep)
+				continue
+			}
+			edge := Edge{From: id, To: target, Type: EdgeDependsOn, Condition: node.Conditions[dep]}
+			if dep == networkDep {
+				edge = Edge{From: id, To: target, Type: EdgeNetworkMode}
+			}
+			edges = append(edges, edge)
+		}
+
+		for _, dep := range node.RestartAfter {
+			if _, ok := g.Nodes[dep]; !ok {
+				missing[id] = append(missing[id], dep)
+				continue
+			}
+			edges = append(edges, Edge{From: id, To: dep, Type: EdgeRestartAfter})
+		}
+	}
+	return edges, missing
+}
+
+// edgeTargets maps each container to the containers it depends on.
+func edgeTargets(edges []Edge) map[string][]string {
+	deps := make(map[string][]string)
+	for _, edge := range edges {
+		deps[edge.From] = append(deps[edge.From], edge.To)
+	}
+	return deps
+}
+
+// blastRadius walks the reverse dependency edges from id.
+func blastRadius(deps map[string][]string, id string) []string {
+	dependents := make(map[string][]string)
+	for node, targets := range deps {
+		for _, target := range targets {
+			dependents[target] = append(dependents[target], node)
+		}
+	}
+
+	seen := map[string]bool{id: true}
+	queue := []string{id}
+	affected := []string{}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, dependent := range dependents[current] {
+			if seen[dependent] {
+				continue
+			}
+			seen[dependent] = true
+			affected = append(affected, dependent)
+			queue = append(queue, dependent)
+		}
+	}
+	sort.Strings(affected)
+	return affected
+}
+
+// findCycles returns the groups of containers that depend on each other in a
+// circle (strongly connected components with more than one container, or a
+// container depending on itself), using Tarjan's algorithm.
+func findCycles(ids []string, edges []Edge) []Cycle {
+	deps := edgeTargets(edges)
+	index := 0
+	indices := make(map[string]int)
+	lowlink := make(map[string]int)
+	onStack := make(map[string]bool)
+	var stack []string
+	cycles := []Cycle{}
+
+	var connect func(id string)
+	connect = func(id string) {
+		indices[id] = index
+		lowlink[id] = index
+		index++
+		stack = append(stack, id)
+		onStack[id] = true
+
+		selfLoop := false
+		for _, dep := range deps[id] {
+			if dep == id {
+				selfLoop = true
+			}
+			if _, visited := indices[dep]; !visited {
+				connect(dep)
+				lowlink[id] = min(lowlink[id], lowlink[dep])
+			} else if onStack[dep] {
+				lowlink[id] = min(lowlink[id], indices[dep])
+			}
+		}
+
+		if lowlink[id] != indices[id] {
+			return
+		}
+		var component []string
+		for {
+			top := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[top] = false
+			component = append(component, top)
+			if top == id {
+				break
+			}
+		}
+		if len(component) > 1 || selfLoop {
+			sort.Strings(component)
+			cycles = append(cycles, newCycle(component, edges))
+		}
+	}
+
+	for _, id := range ids {
+		if _, visited := indices[id]; !visited {
+			connect(id)
+		}
+	}
+	sort.Slice(cycles, func(i, j int) bool { return cycles[i].Containers[0] < cycles[j].Containers[0] })
+	return cycles
+}
+
+// newCycle describes a cycle by the types of the edges between its containers.
+func newCycle(containers []string, edges []Edge) Cycle {
+	cycle := Cycle{Containers: containers, Sources: []string{}}
+	for _, edge := range edges {
+		if !slices.Contains(containers, edge.From) || !slices.Contains(containers, edge.To) {
+			continue
+		}
+		if !slices.Contains(cycle.Sources, edge.Type) {
+			cycle.Sources = append(cycle.Sources, edge.Type)
+		}
+		if edge.Type == EdgeRestartAfter {
+			cycle.RestartLoop = true
+		}
+	}
+	sort.Strings(cycle.Sources)
+	return cycle
+}
@@ -0,0 +1,117 @@
+package graph
+
+import (
+	"strings"
+	"testing"
+)
+
+func newTestGraph() *Graph {
+	g := NewGraph()
+	g.AddNode(&Node{ID: "media-vpn-1", Metadata: map[string]string{"project": "media", "service": "vpn"}})
+	g.AddNode(&Node{ID: "media-torrent-1", Dependencies: []string{"vpn"}, Metadata: map[string]string{"project": "media", "service": "torrent", "network_mode": "service:vpn"}})
+	g.AddNode(&Node{ID: "media-sonarr-1", Dependencies: []string{"torrent", "postgres"}, Metadata: map[string]string{"project": "media", "service": "sonarr"}})
+	g.AddNode(&Node{ID: "standalone", Metadata: map[string]string{}})
+	return g
+}
+
+func TestExport(t *testing.T) {
+	export := newTestGraph().Export()
+
+	if len(export.Nodes) != 4 {
+		t.Fatalf("expected 4 nodes, got %d", len(export.Nodes))
+	}
+	if len(export.Edges) != 2 {
+		t.Fatalf("expected 2 edges, got %v", export.Edges)
+	}
+	if export.Edges[0] != (Edge{From: "media-sonarr-1", To: "media-torrent-1", Type: "depends_on"}) {
+		t.Errorf("unexpected edge %v", export.Edges[0])
+	}
+	if export.Edges[1] != (Edge{From: "media-torrent-1", To: "media-vpn-1", Type: "network_mode"}) {
+		t.Errorf("unexpected edge %v", export.Edges[1])
+	}
+
+	for _, node := range export.Nodes {
+		if node.ID == "media-sonarr-1" && (len(node.MissingDependencies) != 1 || node.MissingDependencies[0] != "postgres") {
+			t.Errorf("expected postgres to be a missing dependency, got %v", node.MissingDependencies)
+		}
+		if node.ID == "media-vpn-1" && strings.Join(node.BlastRadius, ",") != "media-sonarr-1,media-torrent-1" {
+			t.Errorf("unexpected blast radius of vpn: %v", node.BlastRadius)
+		}
+	}
+
+	if len(export.Stacks["media"]) != 3 {
+		t.Errorf("expected 3 containers in media stack, got %v", export.Stacks["media"])
+	}
+	if len(export.Cycles) != 0 {
+		t.Errorf("expected no cycles, got %v", export.Cycles)
+	}
+}
+
+func TestExport_Cycles(t *testing.T) {
+	g := NewGraph()
+	g.AddNode(&Node{ID: "a", Dependencies: []string{"b"}})
+	g.AddNode(&Node{ID: "b", Dependencies: []string{"c"}})
+	g.AddNode(&Node{ID: "c", Dependencies: []string{"a"}})
+	g.AddNode(&Node{ID: "d", Dependencies: []string{"d"}})
+	g.AddNode(&Node{ID: "e", Dependencies: []string{"a"}})
+
+	cycles := g.Export().Cycles
+	if len(cycles) != 2 {
+		t.Fatalf("expected 2 cycles, got %v", cycles)
+	}
+	if strings.Join(cycles[0].Containers, ",") != "a,b,c" || strings.Join(cycles[1].Containers, ",") != "d" {
+		t.Errorf("unexpected cycles %v", cycles)
+	}
+	if cycles[0].RestartLoop || strings.Join(cycles[0].Sources, ",") != "depends_on" {
+		t.Errorf("expected a depends_on cycle, got %+v", cycles[0])
+	}
+}
+
+func TestCycles_RestartAfter(t *testing.T) {
+	// torrent depends on vpn in compose, while vpn is labeled to restart after torrent
+	g := newTestGraph()
+	g.Nodes["media-vpn-1"].RestartAfter = []string{"media-torrent-1"}
+	g.Nodes["standalone"].RestartAfter = []string{"missing"}
+
+	cycles := g.Cycles()
+	if len(cycles) != 1 {
+		t.Fatalf("expected 1 cycle, got %v", cycles)
+	}
+	cycle := cycles[0]
+	if strings.Join(cycle.Containers, ",") != "media-torrent-1,media-vpn-1" {
+		t.Errorf("unexpected cycle containers %v", cycle.Containers)
+	}
+	if strings.Join(cycle.Sources, ",") != "network_mode,restart_after" {
+		t.Errorf("unexpected cycle sources %v", cycle.Sources)
+	}
+	if !cycle.RestartLoop {
+		t.Error("expected the cycle to be a restart loop")
+	}
+
+	// The compose-only graph has no cycle
+	if g.HasCycles() {
+		t.Error("restart-after labels should not create compose cycles")
+	}
+
+	for _, node := range g.Export().Nodes {
+		if node.ID == "standalone" && strings.Join(node.MissingDependencies, ",") != "missing" {
+			t.Errorf("expected missing restart-after container, got %v", node.MissingDependencies)
+		}
+	}
+}
+
+func TestDOT(t *testing.T) {
+	dot := newTestGraph().DOT()
+
+	for _, want := range []string{
+		"digraph docksmith {",
+		`label="media";`,
+		`"media-sonarr-1" -> "media-torrent-1";`,
+		`"media-torrent-1" -> "media-vpn-1" [style=dashed];`,
+		`  "standalone";`,
+	} {
+		if !strings.Contains(dot, want) {
+			t.Errorf("DOT output missing %q:\n%s", want, dot)
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"strings"
 
 	"github.com/chis/docksmith/internal/docker"
+	"github.com/chis/docksmith/internal/scripts"
 )
 
 const (
@@ -20,6 +21,18 @@ const (
 	NetworkModeLabel = "com.docker.compose.network_mode"
 )
 
+// Compose depends_on conditions
+const (
+	// ConditionServiceStarted waits for the dependency to start (short-form depends_on)
+	ConditionServiceStarted = "service_started"
+
+	// ConditionServiceHealthy waits for the dependency's health check to pass
+	ConditionServiceHealthy = "service_healthy"
+
+	// ConditionServiceCompletedSuccessfully waits for the dependency to exit with status 0
+	ConditionServiceCompletedSuccessfully = "service_completed_successfully"
+)
+
 // Builder constructs dependency graphs from container data.
 type Builder struct{}
 
@@ -47,6 +60,8 @@ func (b *Builder) containerToNode(container docker.Container) *Node {
 	node := &Node{
 		ID:           container.Name,
 		Dependencies: b.parseDependencies(container.Labels),
+		Conditions:   ParseDependsOnConditions(container.Labels[DependsOnLabel]),
+		RestartAfter: ParseRestartAfter(container.Labels[scripts.RestartAfterLabel]),
 		Metadata: map[string]string{
 			"id":           container.ID,
 			"image":        container.Image,
@@ -102,6 +117,18 @@ func (b *Builder) parseNetworkModeDependency(labels map[string]string) string {
 	return ""
 }
 
+// ParseRestartAfter parses a docksmith.restart-after label value into container names.
+// Format: "container1,container2"
+func ParseRestartAfter(restartAfter string) []string {
+	names := []string{}
+	for _, name := range strings.Split(restartAfter, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
 // ParseDependsOn parses a depends_on label value into service names.
 // Format: "service1:condition:value,service2:condition:value"
 // Example: "vpn:service_started:false,torrent:service_started:false"
@@ -125,4 +152,24 @@ func ParseDependsOn(dependsOn string) []string {
 	}
 
 	return dependencies
-}
\ No newline at end of file
+}
+
+// ParseDependsOnConditions parses a depends_on label value into the condition of
+// each service. Compose labels short-form depends_on as service_started.
+// Example: "db:service_healthy:false,cache:service_started:false"
+func ParseDependsOnConditions(dependsOn string) map[string]string {
+	conditions := make(map[string]string)
+	for _, part := range strings.Split(dependsOn, ",") {
+		subParts := strings.Split(strings.TrimSpace(part), ":")
+		serviceName := strings.TrimSpace(subParts[0])
+		if serviceName == "" {
+			continue
+		}
+		condition := ConditionServiceStarted
+		if len(subParts) > 1 && strings.TrimSpace(subParts[1]) != "" {
+			condition = strings.TrimSpace(subParts[1])
+		}
+		conditions[serviceName] = condition
+	}
+	return conditions
+}
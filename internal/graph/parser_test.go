@@ -1,9 +1,11 @@
 package graph
 
 import (
+	"reflect"
 	"testing"
 
 	"github.com/chis/docksmith/internal/docker"
+	"github.com/chis/docksmith/internal/scripts"
 )
 
 func TestParseDependencies(t *testing.T) {
@@ -244,3 +246,50 @@ func TestBuildFromContainersWithNetworkMode(t *testing.T) {
 		t.Errorf("tailscale should be updated before traefik-ts in update order, got tailscale at %d, traefik-ts at %d", tailscaleIdx, traefikTsIdx)
 	}
 }
+
+func TestBuildFromContainersWithRestartAfter(t *testing.T) {
+	builder := NewBuilder()
+
+	containers := []docker.Container{
+		{Name: "gluetun", Labels: map[string]string{}},
+		{Name: "torrent", Labels: map[string]string{scripts.RestartAfterLabel: "gluetun, ,sabnzbd"}},
+	}
+
+	graph := builder.BuildFromContainers(containers)
+
+	node, exists := graph.GetNode("torrent")
+	if !exists {
+		t.Fatal("torrent node not found")
+	}
+	if len(node.RestartAfter) != 2 || node.RestartAfter[0] != "gluetun" || node.RestartAfter[1] != "sabnzbd" {
+		t.Errorf("torrent should restart after gluetun and sabnzbd, got %v", node.RestartAfter)
+	}
+	// Restart dependencies do not affect the update order
+	if len(node.Dependencies) != 0 {
+		t.Errorf("torrent should have 0 dependencies, got %v", node.Dependencies)
+	}
+}
+
+func TestParseDependsOnConditions(t *testing.T) {
+	conditions := ParseDependsOnConditions("db:service_healthy:false, cache:service_started:true,migrate:service_completed_successfully:false,legacy")
+	expected := map[string]string{
+		"db":      ConditionServiceHealthy,
+		"cache":   ConditionServiceStarted,
+		"migrate": ConditionServiceCompletedSuccessfully,
+		"legacy":  ConditionServiceStarted,
+	}
+	if !reflect.DeepEqual(conditions, expected) {
+		t.Errorf("Expected conditions %v, got %v", expected, conditions)
+	}
+	if len(ParseDependsOnConditions("")) != 0 {
+		t.Error("Expected no conditions for an empty label")
+	}
+
+	node := NewBuilder().containerToNode(docker.Container{
+		Name:   "app",
+		Labels: map[string]string{DependsOnLabel: "db:service_healthy:false"},
+	})
+	if node.Conditions["db"] != ConditionServiceHealthy {
+		t.Errorf("Expected node condition %s for db, got %q", ConditionServiceHealthy, node.Conditions["db"])
+	}
+}
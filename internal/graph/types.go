@@ -9,6 +9,15 @@ type Node struct {
 	// For example, if torrent depends on VPN, VPN is in torrent's Dependencies.
 	Dependencies []string
 
+	// Conditions are the compose depends_on conditions of the dependencies, keyed
+	// by service name (e.g. "db" -> "service_healthy").
+	Conditions map[string]string
+
+	// RestartAfter are the containers named in the docksmith.restart-after label.
+	// When any of them restarts, this node is restarted too. They are kept apart
+	// from Dependencies so they do not affect the update order.
+	RestartAfter []string
+
 	// Metadata stores additional information about the container
 	Metadata map[string]string
 }
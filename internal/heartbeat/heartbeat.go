@@ -0,0 +1,128 @@
+// Package heartbeat pings dead man's switch monitors such as healthchecks.io and
+// Uptime Kuma push monitors after background checks and scheduled updates, so an
+// alert fires when Docksmith stops running them.
+package heartbeat
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/chis/docksmith/internal/update"
+)
+
+// pingTimeout bounds a single ping.
+const pingTimeout = 10 * time.Second
+
+// Pinger pings the monitors of background checks and scheduled updates.
+type Pinger struct {
+	checkURL  string // Pinged after each successful background check
+	updateURL string // Pinged after each scheduled group update run
+	client    *http.Client
+}
+
+// NewPinger creates a pinger. Either URL may be empty to leave that monitor out.
+func NewPinger(checkURL, updateURL string) *Pinger {
+	return &Pinger{
+		checkURL:  checkURL,
+		updateURL: updateURL,
+		client:    &http.Client{Timeout: pingTimeout},
+	}
+}
+
+// NewPingerFromEnv creates a pinger for HEALTHCHECK_PING_URL (background checks)
+// and HEALTHCHECK_UPDATE_PING_URL (scheduled updates). Returns nil when neither is set.
+func NewPingerFromEnv() (*Pinger, error) {
+	checkURL := strings.TrimSpace(os.Getenv("HEALTHCHECK_PING_URL"))
+	updateURL := strings.TrimSpace(os.Getenv("HEALTHCHECK_UPDATE_PING_URL"))
+	if checkURL == "" && updateURL == "" {
+		return nil, nil
+	}
+	for env, raw := range map[string]string{"HEALTHCHECK_PING_URL": checkURL, "HEALTHCHECK_UPDATE_PING_URL": updateURL} {
+		if raw == "" {
+			continue
+		}
+		if u, err := url.Parse(raw); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, fmt.Errorf("invalid %s %q (must be an http or https URL)", env, raw)
+		}
+	}
+	return NewPinger(checkURL, updateURL), nil
+}
+
+// Sync pings the check monitor after a successful background check.
+func (p *Pinger) Sync(ctx context.Context, result *update.DiscoveryResult) {
+	if p.checkURL == "" {
+		return
+	}
+	msg := fmt.Sprintf("Checked %d containers, %d updates available", result.TotalChecked, result.UpdatesFound)
+	if err := p.ping(ctx, p.checkURL, nil, msg); err != nil {
+		log.Printf("HEARTBEAT: Failed to ping check monitor: %v", err)
+	}
+}
+
+// UpdateRun pings the update monitor after a scheduled update of group. A run
+// that failed with err is reported as a failure, so the monitor alerts right away.
+func (p *Pinger) UpdateRun(ctx context.Context, group string, err error) {
+	if p.updateURL == "" {
+		return
+	}
+	msg := fmt.Sprintf("Scheduled update of group %s", group)
+	if err != nil {
+		msg = fmt.Sprintf("Scheduled update of group %s failed: %v", group, err)
+	}
+	if pingErr := p.ping(ctx, p.updateURL, err, msg); pingErr != nil {
+		log.Printf("HEARTBEAT: Failed to ping update monitor: %v", pingErr)
+	}
+}
+
+// ping reports success, or failure when failed is not nil, to the monitor at
+// rawURL. Uptime Kuma push URLs (/api/push/...) carry the status and message in
+// the query; other URLs are treated as healthchecks.io checks, which take
+// failures at /fail and the message as the body.
+func (p *Pinger) ping(ctx context.Context, rawURL string, failed error, msg string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("invalid ping URL: %w", err)
+	}
+
+	method := http.MethodPost
+	var body io.Reader = strings.NewReader(msg)
+	if strings.Contains(u.Path, "/api/push/") {
+		status := "up"
+		if failed != nil {
+			status = "down"
+		}
+		q := u.Query()
+		q.Set("status", status)
+		q.Set("msg", msg)
+		u.RawQuery = q.Encode()
+		method, body = http.MethodGet, nil
+	} else if failed != nil {
+		u.Path = strings.TrimSuffix(u.Path, "/") + "/fail"
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), body)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to ping %s: %w", u.Host, err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("ping to %s returned status %d", u.Host, resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package heartbeat
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/chis/docksmith/internal/update"
+)
+
+// ping is a request received by the monitor.
+type ping struct {
+	method, path, status, msg, body string
+}
+
+func newMonitor(t *testing.T) (*httptest.Server, chan ping) {
+	t.Helper()
+	pings := make(chan ping, 4)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		pings <- ping{r.Method, r.URL.Path, r.URL.Query().Get("status"), r.URL.Query().Get("msg"), string(body)}
+	}))
+	t.Cleanup(srv.Close)
+	return srv, pings
+}
+
+func TestPinger_Healthchecks(t *testing.T) {
+	srv, pings := newMonitor(t)
+	p := NewPinger(srv.URL+"/ping/check-uuid", srv.URL+"/ping/update-uuid/")
+
+	p.Sync(context.Background(), &update.DiscoveryResult{TotalChecked: 12, UpdatesFound: 3})
+	got := <-pings
+	assert.Equal(t, http.MethodPost, got.method)
+	assert.Equal(t, "/ping/check-uuid", got.path)
+	assert.Equal(t, "Checked 12 containers, 3 updates available", got.body)
+
+	p.UpdateRun(context.Background(), "media", nil)
+	assert.Equal(t, "/ping/update-uuid/", (<-pings).path)
+
+	p.UpdateRun(context.Background(), "media", errors.New("failed to check for updates"))
+	got = <-pings
+	assert.Equal(t, "/ping/update-uuid/fail", got.path)
+	assert.Equal(t, "Scheduled update of group media failed: failed to check for updates", got.body)
+}
+
+func TestPinger_UptimeKuma(t *testing.T) {
+	srv, pings := newMonitor(t)
+	p := NewPinger("", srv.URL+"/api/push/abc123?status=up&msg=OK")
+
+	// No check monitor configured
+	p.Sync(context.Background(), &update.DiscoveryResult{})
+
+	p.UpdateRun(context.Background(), "media", nil)
+	got := <-pings
+	assert.Equal(t, http.MethodGet, got.method)
+	assert.Equal(t, "/api/push/abc123", got.path)
+	assert.Equal(t, "up", got.status)
+	assert.Equal(t, "Scheduled update of group media", got.msg)
+
+	p.UpdateRun(context.Background(), "media", errors.New("failed to start 1 of 2 updates"))
+	got = <-pings
+	assert.Equal(t, "down", got.status)
+	assert.Equal(t, "Scheduled update of group media failed: failed to start 1 of 2 updates", got.msg)
+	assert.Empty(t, pings)
+}
+
+func TestNewPingerFromEnv(t *testing.T) {
+	t.Setenv("HEALTHCHECK_PING_URL", "")
+	t.Setenv("HEALTHCHECK_UPDATE_PING_URL", "")
+	p, err := NewPingerFromEnv()
+	require.NoError(t, err)
+	assert.Nil(t, p)
+
+	t.Setenv("HEALTHCHECK_PING_URL", "https://hc-ping.com/uuid")
+	p, err = NewPingerFromEnv()
+	require.NoError(t, err)
+	require.NotNil(t, p)
+	assert.Equal(t, "https://hc-ping.com/uuid", p.checkURL)
+
+	t.Setenv("HEALTHCHECK_UPDATE_PING_URL", "hc-ping.com/uuid")
+	_, err = NewPingerFromEnv()
+	assert.ErrorContains(t, err, "HEALTHCHECK_UPDATE_PING_URL")
+}
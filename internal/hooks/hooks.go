@@ -0,0 +1,265 @@
+// Package hooks manages incoming webhooks, which let external systems such as CI
+// pipelines and registry notifications trigger an update check or an update.
+package hooks
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chis/docksmith/internal/docker"
+	"github.com/chis/docksmith/internal/storage"
+	"github.com/chis/docksmith/internal/update"
+)
+
+// Hook actions
+const (
+	ActionCheck  = "check"  // Check the target containers for updates
+	ActionUpdate = "update" // Check the target containers and update those with an update available
+)
+
+// hooksConfigKey is the config table key holding the JSON list of hooks.
+const hooksConfigKey = "hooks"
+
+// tokenPrefix marks hook tokens so they are recognizable in CI configs and logs.
+const tokenPrefix = "dsh_"
+
+// Sentinel errors
+var (
+	ErrInvalidToken  = errors.New("invalid hook token")
+	ErrHookNotFound  = errors.New("hook not found")
+	ErrInvalidAction = errors.New("invalid action (must be 'check' or 'update')")
+)
+
+// Hook is a stored incoming webhook. Only the SHA-256 hash of its token is persisted.
+type Hook struct {
+	ID              string     `json:"id"`
+	Name            string     `json:"name"`
+	Hash            string     `json:"hash"`
+	Action          string     `json:"action"`
+	Container       string     `json:"container,omitempty"`     // Only this container is targeted
+	ImagePattern    string     `json:"image_pattern,omitempty"` // Payload images must match, see update.MatchImagePattern
+	CreatedAt       time.Time  `json:"created_at"`
+	LastTriggeredAt *time.Time `json:"last_triggered_at,omitempty"`
+}
+
+// Trigger returns the trigger recorded on operations started by the hook.
+func (h Hook) Trigger() string {
+	return "hook:" + h.Name
+}
+
+// Accepts reports whether a trigger for image passes the hook's image pattern.
+// Triggers without an image always pass; the pattern then selects the targets.
+func (h Hook) Accepts(image string) bool {
+	return h.ImagePattern == "" || image == "" || update.MatchImagePattern(h.ImagePattern, image)
+}
+
+// Targets returns the names of the containers a trigger for image applies to: the
+// containers running any tag of image, or without an image the containers matching
+// the hook's image pattern. A hook for one container only targets that container.
+func (h Hook) Targets(containers []docker.Container, image string) []string {
+	var pattern string
+	switch {
+	case image != "":
+		pattern = repository(image)
+	case h.ImagePattern != "":
+		pattern = h.ImagePattern
+	case h.Container == "":
+		return nil
+	}
+
+	var targets []string
+	for _, c := range containers {
+		if h.Container != "" && c.Name != h.Container {
+			continue
+		}
+		if pattern != "" && !update.MatchImagePattern(pattern, c.Image) {
+			continue
+		}
+		targets = append(targets, c.Name)
+	}
+	return targets
+}
+
+// repository strips the tag and digest from an image reference.
+func repository(image string) string {
+	image, _, _ = strings.Cut(image, "@")
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		image = image[:i]
+	}
+	return image
+}
+
+// ValidAction reports whether action is a known hook action.
+func ValidAction(action string) bool {
+	return action == ActionCheck || action == ActionUpdate
+}
+
+// HashToken returns the hex-encoded SHA-256 hash of a plaintext token.
+func HashToken(plaintext string) string {
+	sum := sha256.Sum256([]byte(plaintext))
+	return hex.EncodeToString(sum[:])
+}
+
+// Store manages hooks persisted in the config table.
+type Store struct {
+	storage storage.Storage
+	mu      sync.Mutex
+}
+
+// NewStore creates a hook store backed by the given storage.
+func NewStore(store storage.Storage) *Store {
+	return &Store{storage: store}
+}
+
+// Create generates a token for hook, persists the hook with the token's hash, and
+// returns the plaintext token. The plaintext is never stored and cannot be recovered later.
+func (hs *Store) Create(ctx context.Context, hook Hook) (string, Hook, error) {
+	if strings.TrimSpace(hook.Name) == "" {
+		return "", Hook{}, fmt.Errorf("hook name is required")
+	}
+	if !ValidAction(hook.Action) {
+		return "", Hook{}, ErrInvalidAction
+	}
+	if strings.ContainsAny(hook.ImagePattern, " \t\n") {
+		return "", Hook{}, fmt.Errorf("image pattern %q cannot contain whitespace", hook.ImagePattern)
+	}
+
+	id, err := randomHex(4)
+	if err != nil {
+		return "", Hook{}, fmt.Errorf("failed to generate hook id: %w", err)
+	}
+	secret, err := randomHex(24)
+	if err != nil {
+		return "", Hook{}, fmt.Errorf("failed to generate hook token: %w", err)
+	}
+	plaintext := tokenPrefix + id + "_" + secret
+
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+
+	hooks, err := hs.load(ctx)
+	if err != nil {
+		return "", Hook{}, err
+	}
+
+	hook.ID = id
+	hook.Hash = HashToken(plaintext)
+	hook.CreatedAt = time.Now().UTC()
+	hook.LastTriggeredAt = nil
+	hooks = append(hooks, hook)
+
+	if err := hs.save(ctx, hooks); err != nil {
+		return "", Hook{}, err
+	}
+	return plaintext, hook, nil
+}
+
+// Delete removes the hook with the given ID.
+func (hs *Store) Delete(ctx context.Context, id string) error {
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+
+	hooks, err := hs.load(ctx)
+	if err != nil {
+		return err
+	}
+
+	remaining := make([]Hook, 0, len(hooks))
+	for _, h := range hooks {
+		if h.ID != id {
+			remaining = append(remaining, h)
+		}
+	}
+	if len(remaining) == len(hooks) {
+		return ErrHookNotFound
+	}
+
+	return hs.save(ctx, remaining)
+}
+
+// List returns all stored hooks (hashes included, tokens never).
+func (hs *Store) List(ctx context.Context) ([]Hook, error) {
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+
+	return hs.load(ctx)
+}
+
+// Authenticate resolves a plaintext token to its hook and records the trigger time.
+func (hs *Store) Authenticate(ctx context.Context, plaintext string) (*Hook, error) {
+	if !strings.HasPrefix(plaintext, tokenPrefix) {
+		return nil, ErrInvalidToken
+	}
+
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+
+	hooks, err := hs.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	hash := []byte(HashToken(plaintext))
+	for i := range hooks {
+		if subtle.ConstantTimeCompare(hash, []byte(hooks[i].Hash)) == 1 {
+			now := time.Now().UTC()
+			hooks[i].LastTriggeredAt = &now
+			if err := hs.save(ctx, hooks); err != nil {
+				return nil, err
+			}
+			hook := hooks[i]
+			return &hook, nil
+		}
+	}
+	return nil, ErrInvalidToken
+}
+
+// load reads hooks from storage. Caller must hold hs.mu.
+func (hs *Store) load(ctx context.Context) ([]Hook, error) {
+	if hs.storage == nil {
+		return nil, fmt.Errorf("storage not available")
+	}
+
+	value, found, err := hs.storage.GetConfig(ctx, hooksConfigKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load hooks: %w", err)
+	}
+
+	hooks := []Hook{}
+	if found && value != "" {
+		if err := json.Unmarshal([]byte(value), &hooks); err != nil {
+			return nil, fmt.Errorf("failed to parse hooks: %w", err)
+		}
+	}
+	return hooks, nil
+}
+
+// save persists hooks. Caller must hold hs.mu.
+func (hs *Store) save(ctx context.Context, hooks []Hook) error {
+	data, err := json.Marshal(hooks)
+	if err != nil {
+		return fmt.Errorf("failed to serialize hooks: %w", err)
+	}
+	if err := hs.storage.SetConfig(ctx, hooksConfigKey, string(data)); err != nil {
+		return fmt.Errorf("failed to save hooks: %w", err)
+	}
+	return nil
+}
+
+// randomHex returns n random bytes encoded as hex.
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,129 @@
+package hooks
+
+import (
+	"context"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/chis/docksmith/internal/docker"
+	"github.com/chis/docksmith/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestStore(t *testing.T) *Store {
+	t.Helper()
+	store, err := storage.NewSQLiteStorage(filepath.Join(t.TempDir(), "test.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { store.Close() })
+	return NewStore(store)
+}
+
+func TestStore_CreateAndAuthenticate(t *testing.T) {
+	ctx := context.Background()
+	hs := newTestStore(t)
+
+	token, hook, err := hs.Create(ctx, Hook{Name: "ci", Action: ActionUpdate, ImagePattern: "ghcr.io/org/*"})
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(token, tokenPrefix))
+	assert.Equal(t, HashToken(token), hook.Hash)
+	assert.Nil(t, hook.LastTriggeredAt)
+
+	got, err := hs.Authenticate(ctx, token)
+	require.NoError(t, err)
+	assert.Equal(t, hook.ID, got.ID)
+	assert.Equal(t, "hook:ci", got.Trigger())
+	require.NotNil(t, got.LastTriggeredAt)
+
+	list, err := hs.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.NotNil(t, list[0].LastTriggeredAt, "the trigger time is persisted")
+
+	_, err = hs.Authenticate(ctx, token+"x")
+	assert.ErrorIs(t, err, ErrInvalidToken)
+
+	require.NoError(t, hs.Delete(ctx, hook.ID))
+	_, err = hs.Authenticate(ctx, token)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+	assert.ErrorIs(t, hs.Delete(ctx, hook.ID), ErrHookNotFound)
+}
+
+func TestStore_CreateValidation(t *testing.T) {
+	ctx := context.Background()
+	hs := newTestStore(t)
+
+	_, _, err := hs.Create(ctx, Hook{Name: "ci", Action: "deploy"})
+	assert.ErrorIs(t, err, ErrInvalidAction)
+
+	_, _, err = hs.Create(ctx, Hook{Action: ActionCheck})
+	assert.Error(t, err)
+
+	_, _, err = hs.Create(ctx, Hook{Name: "ci", Action: ActionCheck, ImagePattern: "ghcr.io/org app"})
+	assert.Error(t, err)
+}
+
+func TestParseEvent(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		github  string
+		payload string
+		want    Event
+	}{
+		{"query", "?image=ghcr.io/org/app:1.2", "", `{"image": "other"}`, Event{Source: SourceQuery, Image: "ghcr.io/org/app:1.2"}},
+		{"generic", "", "", `{"image": "ghcr.io/org/app:1.2"}`, Event{Source: SourceGeneric, Image: "ghcr.io/org/app:1.2"}},
+		{"generic with tag", "", "", `{"image": "ghcr.io/org/app", "tag": "1.2"}`, Event{Source: SourceGeneric, Image: "ghcr.io/org/app:1.2"}},
+		{"diun", "", "", `{"diun_version": "4.28.0", "status": "update", "image": "docker.io/crazymax/diun:latest"}`, Event{Source: SourceDiun, Image: "docker.io/crazymax/diun:latest"}},
+		{"diun unchanged", "", "", `{"diun_version": "4.28.0", "status": "unchange", "image": "docker.io/crazymax/diun:latest"}`, Event{Source: SourceDiun, Image: "docker.io/crazymax/diun:latest", Skip: `image status "unchange"`}},
+		{"docker hub", "", "", `{"push_data": {"tag": "latest"}, "repository": {"repo_name": "linuxserver/plex"}}`, Event{Source: SourceDockerHub, Image: "linuxserver/plex:latest"}},
+		{"github package url", "", "registry_package", `{"action": "published", "registry_package": {"name": "app", "namespace": "org", "package_type": "CONTAINER", "package_version": {"package_url": "ghcr.io/org/app:1.2"}}}`, Event{Source: SourceGitHub, Image: "ghcr.io/org/app:1.2"}},
+		{"github package tag", "", "package", `{"action": "published", "package": {"name": "App", "namespace": "Org", "package_type": "container", "package_version": {"container_metadata": {"tag": {"name": "1.2"}}}}}`, Event{Source: SourceGitHub, Image: "ghcr.io/org/app:1.2"}},
+		{"github package without header", "", "", `{"action": "published", "package": {"name": "app", "namespace": "org", "package_type": "container"}}`, Event{Source: SourceGitHub, Image: "ghcr.io/org/app"}},
+		{"github package updated", "", "package", `{"action": "updated", "package": {"name": "app", "namespace": "org", "package_type": "container"}}`, Event{Source: SourceGitHub, Image: "ghcr.io/org/app", Skip: `package action "updated"`}},
+		{"github npm package", "", "package", `{"action": "published", "package": {"name": "app", "namespace": "org", "package_type": "npm"}}`, Event{Source: SourceGitHub, Skip: "not a container package"}},
+		{"github ping", "", "ping", `{"zen": "Keep it logically awesome."}`, Event{Source: SourceGitHub, Skip: `event "ping"`}},
+		{"harbor", "", "", `{"type": "PUSH_ARTIFACT", "event_data": {"resources": [{"resource_url": "harbor.example.com/org/app:1.2"}]}}`, Event{Source: SourceHarbor, Image: "harbor.example.com/org/app:1.2"}},
+		{"harbor delete", "", "", `{"type": "DELETE_ARTIFACT", "event_data": {"resources": [{"resource_url": "harbor.example.com/org/app:1.2"}]}}`, Event{Source: SourceHarbor, Image: "harbor.example.com/org/app:1.2", Skip: `event "DELETE_ARTIFACT"`}},
+		{"empty", "", "", ``, Event{Source: SourceGeneric}},
+		{"not json", "", "", `image=app`, Event{Source: SourceGeneric}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("POST", "/api/hooks/dsh_token"+tt.query, strings.NewReader(tt.payload))
+			if tt.github != "" {
+				r.Header.Set("X-GitHub-Event", tt.github)
+			}
+			assert.Equal(t, tt.want, ParseEvent(r, []byte(tt.payload)))
+		})
+	}
+}
+
+func TestHookTargets(t *testing.T) {
+	containers := []docker.Container{
+		{Name: "app", Image: "ghcr.io/org/app:1.1"},
+		{Name: "app-worker", Image: "ghcr.io/org/app:latest"},
+		{Name: "plex", Image: "linuxserver/plex:latest"},
+		{Name: "db", Image: "postgres:16"},
+	}
+
+	hook := Hook{Name: "ci", Action: ActionUpdate}
+	assert.Equal(t, []string{"app", "app-worker"}, hook.Targets(containers, "ghcr.io/org/app:1.2"), "any tag of the pushed image")
+	assert.Equal(t, []string{"plex"}, hook.Targets(containers, "docker.io/linuxserver/plex:latest"))
+	assert.Equal(t, []string{"db"}, hook.Targets(containers, "library/postgres"))
+	assert.Empty(t, hook.Targets(containers, ""), "no image and no pattern")
+
+	hook.Container = "app"
+	assert.Equal(t, []string{"app"}, hook.Targets(containers, "ghcr.io/org/app:1.2"))
+	assert.Equal(t, []string{"app"}, hook.Targets(containers, ""))
+	assert.Empty(t, hook.Targets(containers, "postgres:17"), "the pushed image must be the container's")
+
+	hook = Hook{Name: "org", Action: ActionCheck, ImagePattern: "ghcr.io/org/*"}
+	assert.Equal(t, []string{"app", "app-worker"}, hook.Targets(containers, ""))
+	assert.True(t, hook.Accepts("ghcr.io/org/app:1.2"))
+	assert.True(t, hook.Accepts(""))
+	assert.False(t, hook.Accepts("postgres:17"))
+}
@@ -0,0 +1,157 @@
+package hooks
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Webhook senders recognized by ParseEvent
+const (
+	SourceQuery     = "query" // ?image= on the hook URL
+	SourceGeneric   = "generic"
+	SourceDiun      = "diun"
+	SourceDockerHub = "dockerhub"
+	SourceGitHub    = "github"
+	SourceHarbor    = "harbor"
+)
+
+// Event is the image push announced by a webhook request.
+type Event struct {
+	Source string `json:"source"`
+	Image  string `json:"image,omitempty"` // With its tag when the sender reports one
+	Skip   string `json:"skip,omitempty"`  // Why the request announces no push, such as a GitHub ping
+}
+
+// payload holds the fields that identify the pushed image in the webhook payloads
+// of the supported senders.
+type payload struct {
+	// Generic: {"image": "ghcr.io/org/app:1.2"}, optionally with "tag".
+	// Diun sends the same field with its version and the image status.
+	Image       string `json:"image"`
+	Tag         string `json:"tag"`
+	DiunVersion string `json:"diun_version"`
+	Status      string `json:"status"`
+
+	// Docker Hub: {"repository": {"repo_name": "org/app"}, "push_data": {"tag": "1.2"}}
+	Repository struct {
+		RepoName string `json:"repo_name"`
+	} `json:"repository"`
+	PushData *struct {
+		Tag string `json:"tag"`
+	} `json:"push_data"`
+
+	// GitHub registry_package and package events
+	Action          string         `json:"action"`
+	RegistryPackage *githubPackage `json:"registry_package"`
+	Package         *githubPackage `json:"package"`
+
+	// Harbor: {"type": "PUSH_ARTIFACT", "event_data": {"resources": [{"resource_url": "harbor.example.com/org/app:1.2"}]}}
+	Type      string `json:"type"`
+	EventData *struct {
+		Resources []struct {
+			ResourceURL string `json:"resource_url"`
+		} `json:"resources"`
+	} `json:"event_data"`
+}
+
+// githubPackage is the package of a GitHub package event.
+type githubPackage struct {
+	Name           string `json:"name"`
+	Namespace      string `json:"namespace"`
+	PackageType    string `json:"package_type"`
+	PackageVersion struct {
+		PackageURL        string `json:"package_url"`
+		ContainerMetadata struct {
+			Tag struct {
+				Name string `json:"name"`
+			} `json:"tag"`
+		} `json:"container_metadata"`
+	} `json:"package_version"`
+}
+
+// ParseEvent reads the pushed image from a webhook request: the image query
+// parameter, or the payload of Docker Hub, GitHub package events (GHCR), Harbor,
+// Diun, or a generic {"image": ...} body. Events that announce no new image, like
+// GitHub pings or Harbor deletions, are returned with Skip set. Unrecognized
+// payloads return a generic event without an image.
+func ParseEvent(r *http.Request, body []byte) Event {
+	if image := r.URL.Query().Get("image"); image != "" {
+		return Event{Source: SourceQuery, Image: image}
+	}
+
+	if event := r.Header.Get("X-GitHub-Event"); event != "" {
+		return parseGitHubEvent(event, body)
+	}
+
+	var p payload
+	if len(body) == 0 || json.Unmarshal(body, &p) != nil {
+		return Event{Source: SourceGeneric}
+	}
+
+	switch {
+	case p.DiunVersion != "":
+		e := Event{Source: SourceDiun, Image: p.Image}
+		if p.Status != "new" && p.Status != "update" {
+			e.Skip = fmt.Sprintf("image status %q", p.Status)
+		}
+		return e
+	case p.Image != "":
+		return Event{Source: SourceGeneric, Image: withTag(p.Image, p.Tag)}
+	case p.PushData != nil && p.Repository.RepoName != "":
+		return Event{Source: SourceDockerHub, Image: withTag(p.Repository.RepoName, p.PushData.Tag)}
+	case p.EventData != nil:
+		e := Event{Source: SourceHarbor}
+		if len(p.EventData.Resources) > 0 {
+			e.Image = p.EventData.Resources[0].ResourceURL
+		}
+		if p.Type != "PUSH_ARTIFACT" {
+			e.Skip = fmt.Sprintf("event %q", p.Type)
+		}
+		return e
+	case p.RegistryPackage != nil || p.Package != nil:
+		return githubPackageEvent(p)
+	}
+	return Event{Source: SourceGeneric}
+}
+
+// parseGitHubEvent reads a GitHub webhook delivery of the given X-GitHub-Event type.
+func parseGitHubEvent(event string, body []byte) Event {
+	if event != "package" && event != "registry_package" {
+		return Event{Source: SourceGitHub, Skip: fmt.Sprintf("event %q", event)}
+	}
+	var p payload
+	if err := json.Unmarshal(body, &p); err != nil {
+		return Event{Source: SourceGitHub, Skip: "invalid payload"}
+	}
+	return githubPackageEvent(p)
+}
+
+// githubPackageEvent returns the container image published by a GitHub package event.
+func githubPackageEvent(p payload) Event {
+	pkg := p.RegistryPackage
+	if pkg == nil {
+		pkg = p.Package
+	}
+	if pkg == nil || !strings.EqualFold(pkg.PackageType, "container") {
+		return Event{Source: SourceGitHub, Skip: "not a container package"}
+	}
+
+	e := Event{Source: SourceGitHub, Image: pkg.PackageVersion.PackageURL}
+	if e.Image == "" && pkg.Namespace != "" && pkg.Name != "" {
+		e.Image = withTag("ghcr.io/"+strings.ToLower(pkg.Namespace+"/"+pkg.Name), pkg.PackageVersion.ContainerMetadata.Tag.Name)
+	}
+	if p.Action != "published" {
+		e.Skip = fmt.Sprintf("package action %q", p.Action)
+	}
+	return e
+}
+
+// withTag appends tag to an image reference that has no tag or digest.
+func withTag(image, tag string) string {
+	if tag == "" || repository(image) != image {
+		return image
+	}
+	return image + ":" + tag
+}
@@ -0,0 +1,411 @@
+// Package mqtt publishes container update status to an MQTT broker, with Home
+// Assistant discovery so each container appears as an update entity, and
+// starts updates requested on command topics.
+package mqtt
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MQTT 3.1.1 control packet types, shifted into the high nibble of the fixed header.
+const (
+	packetConnect    = 0x10
+	packetConnack    = 0x20
+	packetPublish    = 0x30
+	packetPuback     = 0x40
+	packetSubscribe  = 0x82 // Reserved flags 0010
+	packetSuback     = 0x90
+	packetPingreq    = 0xC0
+	packetPingresp   = 0xD0
+	packetDisconnect = 0xE0
+)
+
+// maxPacketSize bounds incoming packets; commands and retained messages are small.
+const maxPacketSize = 1 << 20
+
+// Message is an MQTT application message. Only QoS 0 is published.
+type Message struct {
+	Topic   string
+	Payload []byte
+	Retain  bool
+}
+
+// Options configures a client connection.
+type Options struct {
+	Broker    string // tcp://host:1883, mqtt://, ssl://, mqtts://, or host:port
+	ClientID  string
+	Username  string
+	Password  string
+	KeepAlive time.Duration
+	Will      *Message                                                          // Published by the broker when the connection drops
+	OnMessage func(msg Message)                                                 // Called for messages on subscribed topics, from the read loop
+	TLSConfig *tls.Config                                                       // Used for ssl:// and mqtts:// brokers
+	Dialer    func(ctx context.Context, network, addr string) (net.Conn, error) // Defaults to net.Dialer
+}
+
+// Client is a minimal MQTT 3.1.1 client: QoS 0 publishes and subscriptions,
+// which is all Home Assistant discovery and commands need.
+type Client struct {
+	opts Options
+	conn net.Conn
+
+	writeMu  sync.Mutex
+	packetID uint16
+
+	done    chan struct{}
+	errMu   sync.Mutex
+	err     error
+	closing sync.Once
+}
+
+// Connect dials the broker and completes the MQTT handshake.
+func Connect(ctx context.Context, opts Options) (*Client, error) {
+	network, addr, useTLS, err := parseBroker(opts.Broker)
+	if err != nil {
+		return nil, err
+	}
+	if opts.KeepAlive <= 0 {
+		opts.KeepAlive = 60 * time.Second
+	}
+
+	dial := opts.Dialer
+	if dial == nil {
+		dial = (&net.Dialer{Timeout: 10 * time.Second}).DialContext
+	}
+	conn, err := dial(ctx, network, addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to MQTT broker %s: %w", addr, err)
+	}
+	if useTLS {
+		cfg := opts.TLSConfig
+		if cfg == nil {
+			cfg = &tls.Config{}
+		}
+		if cfg.ServerName == "" {
+			cfg = cfg.Clone()
+			cfg.ServerName, _, _ = net.SplitHostPort(addr)
+		}
+		tlsConn := tls.Client(conn, cfg)
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("failed TLS handshake with MQTT broker %s: %w", addr, err)
+		}
+		conn = tlsConn
+	}
+
+	c := &Client{opts: opts, conn: conn, done: make(chan struct{})}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	reader := bufio.NewReader(conn)
+	if err := c.handshake(reader); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	conn.SetDeadline(time.Time{})
+
+	go c.readLoop(reader)
+	go c.pingLoop()
+	return c, nil
+}
+
+// parseBroker returns the network address of a broker URL and whether it uses TLS.
+func parseBroker(broker string) (network, addr string, useTLS bool, err error) {
+	if broker == "" {
+		return "", "", false, fmt.Errorf("MQTT broker is required")
+	}
+	if !strings.Contains(broker, "://") {
+		broker = "tcp://" + broker
+	}
+	u, err := url.Parse(broker)
+	if err != nil {
+		return "", "", false, fmt.Errorf("invalid MQTT broker %q: %w", broker, err)
+	}
+
+	port := "1883"
+	switch u.Scheme {
+	case "tcp", "mqtt":
+	case "ssl", "tls", "mqtts":
+		useTLS, port = true, "8883"
+	default:
+		return "", "", false, fmt.Errorf("invalid MQTT broker %q: unsupported scheme %s", broker, u.Scheme)
+	}
+	if u.Port() != "" {
+		port = u.Port()
+	}
+	if u.Hostname() == "" {
+		return "", "", false, fmt.Errorf("invalid MQTT broker %q: missing host", broker)
+	}
+	return "tcp", net.JoinHostPort(u.Hostname(), port), useTLS, nil
+}
+
+// handshake sends CONNECT and waits for the broker's CONNACK.
+func (c *Client) handshake(r *bufio.Reader) error {
+	var flags byte = 0x02 // Clean session
+	var payload []byte
+	payload = appendString(payload, c.opts.ClientID)
+	if will := c.opts.Will; will != nil {
+		flags |= 0x04
+		if will.Retain {
+			flags |= 0x20
+		}
+		payload = appendString(payload, will.Topic)
+		payload = appendBytes(payload, will.Payload)
+	}
+	if c.opts.Username != "" {
+		flags |= 0x80
+		payload = appendString(payload, c.opts.Username)
+		if c.opts.Password != "" {
+			flags |= 0x40
+			payload = appendString(payload, c.opts.Password)
+		}
+	}
+
+	body := appendString(nil, "MQTT")
+	body = append(body, 4, flags) // Protocol level 4 is MQTT 3.1.1
+	body = binary.BigEndian.AppendUint16(body, uint16(c.opts.KeepAlive/time.Second))
+	body = append(body, payload...)
+	if err := c.writePacket(packetConnect, body); err != nil {
+		return fmt.Errorf("failed to send MQTT connect: %w", err)
+	}
+
+	header, ack, err := readPacket(r)
+	if err != nil {
+		return fmt.Errorf("failed to read MQTT connect acknowledgement: %w", err)
+	}
+	if header&0xF0 != packetConnack || len(ack) != 2 {
+		return fmt.Errorf("unexpected MQTT packet 0x%02x during connect", header)
+	}
+	if ack[1] != 0 {
+		return fmt.Errorf("MQTT broker refused connection: %s", connackReason(ack[1]))
+	}
+	return nil
+}
+
+// connackReason describes a CONNACK return code.
+func connackReason(code byte) string {
+	switch code {
+	case 1:
+		return "unacceptable protocol version"
+	case 2:
+		return "client identifier rejected"
+	case 3:
+		return "server unavailable"
+	case 4:
+		return "bad username or password"
+	case 5:
+		return "not authorized"
+	default:
+		return fmt.Sprintf("return code %d", code)
+	}
+}
+
+// Publish sends a QoS 0 message.
+func (c *Client) Publish(topic string, payload []byte, retain bool) error {
+	var header byte = packetPublish
+	if retain {
+		header |= 0x01
+	}
+	body := appendString(nil, topic)
+	body = append(body, payload...)
+	return c.writePacket(header, body)
+}
+
+// Subscribe subscribes to a topic filter at QoS 0. Messages are passed to OnMessage.
+func (c *Client) Subscribe(filter string) error {
+	c.writeMu.Lock()
+	c.packetID++
+	if c.packetID == 0 {
+		c.packetID = 1
+	}
+	id := c.packetID
+	c.writeMu.Unlock()
+
+	body := binary.BigEndian.AppendUint16(nil, id)
+	body = appendString(body, filter)
+	body = append(body, 0) // Requested QoS
+	return c.writePacket(packetSubscribe, body)
+}
+
+// Done is closed when the connection is lost or closed.
+func (c *Client) Done() <-chan struct{} {
+	return c.done
+}
+
+// Err returns why the connection ended, or nil while it is open or after Close.
+func (c *Client) Err() error {
+	c.errMu.Lock()
+	defer c.errMu.Unlock()
+	return c.err
+}
+
+// Close disconnects cleanly. The broker does not publish the will.
+func (c *Client) Close() error {
+	c.writePacket(packetDisconnect, nil)
+	c.shutdown(nil)
+	return nil
+}
+
+// shutdown closes the connection once, recording err as the reason.
+func (c *Client) shutdown(err error) {
+	c.closing.Do(func() {
+		c.errMu.Lock()
+		c.err = err
+		c.errMu.Unlock()
+		c.conn.Close()
+		close(c.done)
+	})
+}
+
+// readLoop dispatches incoming packets until the connection ends.
+func (c *Client) readLoop(r *bufio.Reader) {
+	for {
+		header, body, err := readPacket(r)
+		if err != nil {
+			c.shutdown(fmt.Errorf("MQTT connection lost: %w", err))
+			return
+		}
+
+		switch header & 0xF0 {
+		case packetPublish:
+			msg, id, err := parsePublish(header, body)
+			if err != nil {
+				c.shutdown(err)
+				return
+			}
+			if qos := (header >> 1) & 0x03; qos == 1 {
+				c.writePacket(packetPuback, binary.BigEndian.AppendUint16(nil, id))
+			}
+			if c.opts.OnMessage != nil {
+				c.opts.OnMessage(msg)
+			}
+		case packetSuback, packetPingresp, packetPuback:
+		default:
+			c.shutdown(fmt.Errorf("unexpected MQTT packet 0x%02x", header))
+			return
+		}
+	}
+}
+
+// pingLoop keeps the connection alive while nothing else is sent.
+func (c *Client) pingLoop() {
+	ticker := time.NewTicker(c.opts.KeepAlive / 2)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.done:
+			return
+		case <-ticker.C:
+			if err := c.writePacket(packetPingreq, nil); err != nil {
+				c.shutdown(fmt.Errorf("MQTT ping failed: %w", err))
+				return
+			}
+		}
+	}
+}
+
+// writePacket writes one control packet.
+func (c *Client) writePacket(header byte, body []byte) error {
+	packet := append([]byte{header}, appendLength(nil, len(body))...)
+	packet = append(packet, body...)
+
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	c.conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+	_, err := c.conn.Write(packet)
+	return err
+}
+
+// readPacket reads one control packet, returning its fixed header byte and body.
+func readPacket(r *bufio.Reader) (byte, []byte, error) {
+	header, err := r.ReadByte()
+	if err != nil {
+		return 0, nil, err
+	}
+
+	length, multiplier := 0, 1
+	for i := 0; ; i++ {
+		if i == 4 {
+			return 0, nil, errors.New("malformed MQTT remaining length")
+		}
+		b, err := r.ReadByte()
+		if err != nil {
+			return 0, nil, err
+		}
+		length += int(b&0x7F) * multiplier
+		if b&0x80 == 0 {
+			break
+		}
+		multiplier *= 128
+	}
+	if length > maxPacketSize {
+		return 0, nil, fmt.Errorf("MQTT packet of %d bytes exceeds the limit", length)
+	}
+
+	body := make([]byte, length)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return 0, nil, err
+	}
+	return header, body, nil
+}
+
+// parsePublish decodes the body of a PUBLISH packet.
+func parsePublish(header byte, body []byte) (Message, uint16, error) {
+	if len(body) < 2 {
+		return Message{}, 0, errors.New("malformed MQTT publish")
+	}
+	n := int(binary.BigEndian.Uint16(body))
+	if len(body) < 2+n {
+		return Message{}, 0, errors.New("malformed MQTT publish topic")
+	}
+	msg := Message{Topic: string(body[2 : 2+n]), Retain: header&0x01 != 0}
+	rest := body[2+n:]
+
+	var id uint16
+	if (header>>1)&0x03 > 0 {
+		if len(rest) < 2 {
+			return Message{}, 0, errors.New("malformed MQTT publish packet id")
+		}
+		id = binary.BigEndian.Uint16(rest)
+		rest = rest[2:]
+	}
+	msg.Payload = rest
+	return msg, id, nil
+}
+
+// appendLength appends the MQTT variable-length encoding of n.
+func appendLength(b []byte, n int) []byte {
+	for {
+		digit := byte(n % 128)
+		n /= 128
+		if n > 0 {
+			digit |= 0x80
+		}
+		b = append(b, digit)
+		if n == 0 {
+			return b
+		}
+	}
+}
+
+// appendString appends a length-prefixed UTF-8 string.
+func appendString(b []byte, s string) []byte {
+	return appendBytes(b, []byte(s))
+}
+
+// appendBytes appends length-prefixed binary data.
+func appendBytes(b, data []byte) []byte {
+	b = binary.BigEndian.AppendUint16(b, uint16(len(data)))
+	return append(b, data...)
+}
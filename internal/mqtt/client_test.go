@@ -0,0 +1,192 @@
+package mqtt
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeBroker accepts one client connection over an in-memory pipe.
+type fakeBroker struct {
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+// newFakeBroker returns a broker and an Options.Dialer connected to it.
+func newFakeBroker(t *testing.T) (*fakeBroker, func(context.Context, string, string) (net.Conn, error)) {
+	t.Helper()
+	server, client := net.Pipe()
+	t.Cleanup(func() { server.Close() })
+	broker := &fakeBroker{conn: server, reader: bufio.NewReader(server)}
+	return broker, func(context.Context, string, string) (net.Conn, error) { return client, nil }
+}
+
+func (fb *fakeBroker) read(t *testing.T) (byte, []byte) {
+	t.Helper()
+	fb.conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	header, body, err := readPacket(fb.reader)
+	require.NoError(t, err)
+	return header, body
+}
+
+func (fb *fakeBroker) write(t *testing.T, header byte, body []byte) {
+	t.Helper()
+	packet := append([]byte{header}, appendLength(nil, len(body))...)
+	_, err := fb.conn.Write(append(packet, body...))
+	require.NoError(t, err)
+}
+
+func TestClient_ConnectPublishSubscribe(t *testing.T) {
+	broker, dial := newFakeBroker(t)
+	received := make(chan Message, 1)
+
+	connected := make(chan *Client, 1)
+	go func() {
+		client, err := Connect(context.Background(), Options{
+			Broker:    "mqtt://broker.local",
+			ClientID:  "docksmith",
+			Username:  "user",
+			Password:  "secret",
+			Will:      &Message{Topic: "docksmith/status", Payload: []byte("offline"), Retain: true},
+			OnMessage: func(msg Message) { received <- msg },
+			Dialer:    dial,
+		})
+		assert.NoError(t, err)
+		connected <- client
+	}()
+
+	header, body := broker.read(t)
+	assert.Equal(t, byte(packetConnect), header)
+	want := appendString(nil, "MQTT")
+	want = append(want, 4, 0x80|0x40|0x20|0x04|0x02, 0, 60)
+	want = appendString(want, "docksmith")
+	want = appendString(want, "docksmith/status")
+	want = appendString(want, "offline")
+	want = appendString(want, "user")
+	want = appendString(want, "secret")
+	assert.Equal(t, want, body)
+	broker.write(t, packetConnack, []byte{0, 0})
+
+	client := <-connected
+	require.NotNil(t, client)
+	defer client.Close()
+
+	go client.Publish("docksmith/app/state", []byte(`{"in_progress":false}`), true)
+	header, body = broker.read(t)
+	assert.Equal(t, byte(packetPublish|0x01), header)
+	msg, _, err := parsePublish(header, body)
+	require.NoError(t, err)
+	assert.Equal(t, "docksmith/app/state", msg.Topic)
+	assert.Equal(t, `{"in_progress":false}`, string(msg.Payload))
+
+	go client.Subscribe("docksmith/+/install")
+	header, body = broker.read(t)
+	assert.Equal(t, byte(packetSubscribe), header)
+	assert.Equal(t, uint16(1), binary.BigEndian.Uint16(body))
+	assert.Equal(t, appendString(nil, "docksmith/+/install"), body[2:len(body)-1])
+	broker.write(t, packetSuback, []byte{0, 1, 0})
+
+	// A QoS 1 delivery is acknowledged and passed to OnMessage
+	publish := appendString(nil, "docksmith/app/install")
+	publish = append(publish, 0, 7)
+	publish = append(publish, "install"...)
+	broker.write(t, packetPublish|0x02, publish)
+	header, body = broker.read(t)
+	assert.Equal(t, byte(packetPuback), header)
+	assert.Equal(t, []byte{0, 7}, body)
+
+	select {
+	case msg := <-received:
+		assert.Equal(t, "docksmith/app/install", msg.Topic)
+		assert.Equal(t, "install", string(msg.Payload))
+	case <-time.After(2 * time.Second):
+		t.Fatal("message not delivered")
+	}
+
+	broker.conn.Close()
+	select {
+	case <-client.Done():
+		assert.Error(t, client.Err())
+	case <-time.After(2 * time.Second):
+		t.Fatal("lost connection not detected")
+	}
+}
+
+func TestClient_ConnectRefused(t *testing.T) {
+	broker, dial := newFakeBroker(t)
+	go func() {
+		broker.read(t)
+		broker.write(t, packetConnack, []byte{0, 4})
+	}()
+
+	_, err := Connect(context.Background(), Options{Broker: "broker.local", Dialer: dial})
+	assert.ErrorContains(t, err, "bad username or password")
+}
+
+func TestParseBroker(t *testing.T) {
+	tests := []struct {
+		broker  string
+		addr    string
+		useTLS  bool
+		wantErr bool
+	}{
+		{"mosquitto", "mosquitto:1883", false, false},
+		{"mosquitto:1884", "mosquitto:1884", false, false},
+		{"tcp://10.0.0.2", "10.0.0.2:1883", false, false},
+		{"mqtts://broker.example.com", "broker.example.com:8883", true, false},
+		{"ssl://broker.example.com:8884", "broker.example.com:8884", true, false},
+		{"ws://broker.example.com", "", false, true},
+		{"", "", false, true},
+	}
+
+	for _, tt := range tests {
+		_, addr, useTLS, err := parseBroker(tt.broker)
+		if tt.wantErr {
+			assert.Error(t, err, tt.broker)
+			continue
+		}
+		require.NoError(t, err, tt.broker)
+		assert.Equal(t, tt.addr, addr, tt.broker)
+		assert.Equal(t, tt.useTLS, useTLS, tt.broker)
+	}
+}
+
+func TestRemainingLength(t *testing.T) {
+	for _, n := range []int{0, 127, 128, 16383, 16384, maxPacketSize} {
+		encoded := appendLength([]byte{packetPingresp}, n)
+		header, body, err := readPacket(bufio.NewReader(&lengthOnly{data: encoded, size: n}))
+		require.NoError(t, err, n)
+		assert.Equal(t, byte(packetPingresp), header)
+		assert.Len(t, body, n)
+	}
+
+	_, _, err := readPacket(bufio.NewReader(&lengthOnly{data: appendLength([]byte{packetPublish}, maxPacketSize+1)}))
+	assert.ErrorContains(t, err, "exceeds the limit")
+}
+
+// lengthOnly serves a fixed header followed by size zero bytes.
+type lengthOnly struct {
+	data []byte
+	size int
+}
+
+func (l *lengthOnly) Read(p []byte) (int, error) {
+	if len(l.data) > 0 {
+		n := copy(p, l.data)
+		l.data = l.data[n:]
+		return n, nil
+	}
+	if l.size == 0 {
+		return 0, net.ErrClosed
+	}
+	n := min(len(p), l.size)
+	clear(p[:n])
+	l.size -= n
+	return n, nil
+}
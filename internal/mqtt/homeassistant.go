@@ -0,0 +1,462 @@
+package mqtt
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chis/docksmith/internal/events"
+	"github.com/chis/docksmith/internal/update"
+)
+
+// Trigger is recorded on operations started from an MQTT command.
+const Trigger = "mqtt"
+
+// payloadInstall is the command Home Assistant sends to install an update.
+const payloadInstall = "install"
+
+// Reconnect backoff after a failed or lost broker connection
+const (
+	minReconnectDelay = 5 * time.Second
+	maxReconnectDelay = 5 * time.Minute
+)
+
+// UpdateFunc starts the update of a container to its latest version and returns
+// the operation ID. It applies the same policy checks as an update requested via the API.
+type UpdateFunc func(ctx context.Context, containerName string) (string, error)
+
+// Config configures the Home Assistant bridge.
+type Config struct {
+	Options
+	TopicPrefix     string // Docksmith state, command and event topics (default "docksmith")
+	DiscoveryPrefix string // Home Assistant discovery topics (default "homeassistant")
+}
+
+// entity is the Home Assistant update entity of a container.
+type entity struct {
+	Container        string
+	Image            string
+	InstalledVersion string
+	LatestVersion    string
+	InProgress       bool
+	Percentage       *int
+}
+
+// publisher is the part of Client the bridge publishes through.
+type publisher interface {
+	Publish(topic string, payload []byte, retain bool) error
+}
+
+// Bridge publishes the update status of each container as a Home Assistant update
+// entity, forwards operation events, and starts updates installed from Home Assistant.
+type Bridge struct {
+	cfg    Config
+	update UpdateFunc
+
+	mu         sync.Mutex
+	client     publisher
+	entities   map[string]*entity // Entity ID -> entity
+	operations map[string]string  // Operation ID -> entity ID, for operations in progress
+
+	stopChan chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+}
+
+// NewBridge creates a bridge. Start connects it to the broker.
+func NewBridge(cfg Config, updateFn UpdateFunc) *Bridge {
+	if cfg.TopicPrefix == "" {
+		cfg.TopicPrefix = "docksmith"
+	}
+	if cfg.DiscoveryPrefix == "" {
+		cfg.DiscoveryPrefix = "homeassistant"
+	}
+	if cfg.ClientID == "" {
+		cfg.ClientID = "docksmith"
+	}
+	cfg.TopicPrefix = strings.TrimSuffix(cfg.TopicPrefix, "/")
+	cfg.DiscoveryPrefix = strings.TrimSuffix(cfg.DiscoveryPrefix, "/")
+
+	return &Bridge{
+		cfg:        cfg,
+		update:     updateFn,
+		entities:   make(map[string]*entity),
+		operations: make(map[string]string),
+		stopChan:   make(chan struct{}),
+	}
+}
+
+// NewBridgeFromEnv creates a bridge for the broker in MQTT_BROKER, authenticated
+// with MQTT_USERNAME and MQTT_PASSWORD. MQTT_CLIENT_ID, MQTT_TOPIC_PREFIX and
+// MQTT_DISCOVERY_PREFIX override the defaults. Returns nil when MQTT_BROKER is not set.
+func NewBridgeFromEnv(updateFn UpdateFunc) (*Bridge, error) {
+	broker := strings.TrimSpace(os.Getenv("MQTT_BROKER"))
+	if broker == "" {
+		return nil, nil
+	}
+	if _, _, _, err := parseBroker(broker); err != nil {
+		return nil, err
+	}
+
+	return NewBridge(Config{
+		Options: Options{
+			Broker:   broker,
+			ClientID: os.Getenv("MQTT_CLIENT_ID"),
+			Username: os.Getenv("MQTT_USERNAME"),
+			Password: os.Getenv("MQTT_PASSWORD"),
+		},
+		TopicPrefix:     os.Getenv("MQTT_TOPIC_PREFIX"),
+		DiscoveryPrefix: os.Getenv("MQTT_DISCOVERY_PREFIX"),
+	}, updateFn), nil
+}
+
+// Broker returns the configured broker address.
+func (b *Bridge) Broker() string {
+	return b.cfg.Broker
+}
+
+// Start connects to the broker in the background, reconnecting when the connection
+// drops, and forwards operation events from bus.
+func (b *Bridge) Start(bus *events.Bus) {
+	b.wg.Add(1)
+	go b.run()
+
+	if bus != nil {
+		sub, unsubscribe := bus.Subscribe("*")
+		b.wg.Add(1)
+		go func() {
+			defer b.wg.Done()
+			defer unsubscribe()
+			for {
+				select {
+				case <-b.stopChan:
+					return
+				case event, ok := <-sub:
+					if !ok {
+						return
+					}
+					b.handleEvent(event)
+				}
+			}
+		}()
+	}
+}
+
+// Stop marks Docksmith offline in Home Assistant and disconnects.
+func (b *Bridge) Stop() {
+	b.stopOnce.Do(func() { close(b.stopChan) })
+	b.wg.Wait()
+}
+
+// run keeps a broker connection open until Stop.
+func (b *Bridge) run() {
+	defer b.wg.Done()
+
+	delay := minReconnectDelay
+	for {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		client, err := b.connect(ctx)
+		cancel()
+		if err != nil {
+			log.Printf("MQTT: %v (retrying in %v)", err, delay)
+			select {
+			case <-b.stopChan:
+				return
+			case <-time.After(delay):
+			}
+			delay = min(delay*2, maxReconnectDelay)
+			continue
+		}
+		delay = minReconnectDelay
+		log.Printf("MQTT: Connected to %s", b.cfg.Broker)
+
+		select {
+		case <-b.stopChan:
+			b.setClient(nil)
+			client.Publish(b.availabilityTopic(), []byte("offline"), true)
+			client.Close()
+			return
+		case <-client.Done():
+			b.setClient(nil)
+			log.Printf("MQTT: %v", client.Err())
+		}
+	}
+}
+
+// connect opens a broker connection, announces availability, subscribes to the
+// install commands and publishes the known entities.
+func (b *Bridge) connect(ctx context.Context) (*Client, error) {
+	opts := b.cfg.Options
+	opts.Will = &Message{Topic: b.availabilityTopic(), Payload: []byte("offline"), Retain: true}
+	opts.OnMessage = b.handleMessage
+
+	client, err := Connect(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	if err := client.Subscribe(b.cfg.TopicPrefix + "/+/install"); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("failed to subscribe to MQTT commands: %w", err)
+	}
+	if err := client.Publish(b.availabilityTopic(), []byte("online"), true); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("failed to publish MQTT availability: %w", err)
+	}
+
+	b.mu.Lock()
+	b.client = client
+	for id, e := range b.entities {
+		b.publishEntity(id, e)
+	}
+	b.mu.Unlock()
+	return client, nil
+}
+
+// setClient replaces the connection used for publishing.
+func (b *Bridge) setClient(client publisher) {
+	b.mu.Lock()
+	b.client = client
+	b.mu.Unlock()
+}
+
+// Sync publishes the update status of every checked container and removes the
+// entities of containers that are gone. Registered as a background checker result handler.
+func (b *Bridge) Sync(ctx context.Context, result *update.DiscoveryResult) {
+	if result == nil {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	seen := make(map[string]bool)
+	for _, c := range result.Containers {
+		if c.Status == update.Ignored || c.Status == update.LocalImage {
+			continue
+		}
+		id := entityID(c.ContainerName)
+		seen[id] = true
+
+		e := newEntity(c)
+		if prev, ok := b.entities[id]; ok {
+			e.InProgress, e.Percentage = prev.InProgress, prev.Percentage
+		}
+		b.entities[id] = e
+		b.publishEntity(id, e)
+	}
+
+	for id := range b.entities {
+		if !seen[id] {
+			delete(b.entities, id)
+			b.publish(b.discoveryTopic(id), nil, true)
+			b.publish(b.stateTopic(id), nil, true)
+		}
+	}
+}
+
+// newEntity returns the update entity state of a checked container.
+func newEntity(c update.ContainerInfo) *entity {
+	installed := c.CurrentVersion
+	if installed == "" {
+		installed = c.CurrentTag
+	}
+	if installed == "" {
+		installed = shortDigest(c.CurrentDigest)
+	}
+
+	latest := installed
+	if c.Status == update.UpdateAvailable {
+		latest = c.LatestVersion
+		if latest == "" || latest == installed {
+			// Same tag with a new digest, e.g. :latest
+			installed, latest = shortDigest(c.CurrentDigest), shortDigest(c.LatestDigest)
+		}
+	}
+
+	return &entity{
+		Container:        c.ContainerName,
+		Image:            c.Image,
+		InstalledVersion: installed,
+		LatestVersion:    latest,
+	}
+}
+
+// publishEntity publishes the discovery config and state of an entity. Caller must hold b.mu.
+func (b *Bridge) publishEntity(id string, e *entity) {
+	config, err := json.Marshal(b.discoveryConfig(id, e))
+	if err != nil {
+		return
+	}
+	b.publish(b.discoveryTopic(id), config, true)
+	b.publishState(id, e)
+}
+
+// publishState publishes the state of an entity. Caller must hold b.mu.
+func (b *Bridge) publishState(id string, e *entity) {
+	state, err := json.Marshal(map[string]any{
+		"installed_version": e.InstalledVersion,
+		"latest_version":    e.LatestVersion,
+		"title":             e.Image,
+		"in_progress":       e.InProgress,
+		"update_percentage": e.Percentage,
+	})
+	if err != nil {
+		return
+	}
+	b.publish(b.stateTopic(id), state, true)
+}
+
+// discoveryConfig returns the Home Assistant MQTT discovery config of an update entity.
+func (b *Bridge) discoveryConfig(id string, e *entity) map[string]any {
+	return map[string]any{
+		"name":               e.Container,
+		"unique_id":          "docksmith_" + id,
+		"state_topic":        b.stateTopic(id),
+		"command_topic":      b.commandTopic(id),
+		"payload_install":    payloadInstall,
+		"availability_topic": b.availabilityTopic(),
+		"device": map[string]any{
+			"identifiers":  []string{b.cfg.ClientID},
+			"name":         "Docksmith",
+			"manufacturer": "Docksmith",
+		},
+	}
+}
+
+// publish sends a message when connected. Caller must hold b.mu.
+func (b *Bridge) publish(topic string, payload []byte, retain bool) {
+	if b.client == nil {
+		return
+	}
+	if err := b.client.Publish(topic, payload, retain); err != nil {
+		log.Printf("MQTT: Failed to publish to %s: %v", topic, err)
+	}
+}
+
+// handleMessage starts the update of the container whose install command was received.
+func (b *Bridge) handleMessage(msg Message) {
+	id, ok := strings.CutPrefix(msg.Topic, b.cfg.TopicPrefix+"/")
+	if !ok {
+		return
+	}
+	id, ok = strings.CutSuffix(id, "/install")
+	if !ok || strings.TrimSpace(string(msg.Payload)) != payloadInstall {
+		return
+	}
+
+	b.mu.Lock()
+	e, found := b.entities[id]
+	var name string
+	if found {
+		name = e.Container
+	}
+	b.mu.Unlock()
+	if !found {
+		log.Printf("MQTT: Ignoring install command for unknown entity %s", id)
+		return
+	}
+	if b.update == nil {
+		return
+	}
+
+	go func() {
+		ctx := update.WithTrigger(context.Background(), Trigger)
+		operationID, err := b.update(ctx, name)
+		if err != nil {
+			log.Printf("MQTT: Failed to start update of %s: %v", name, err)
+			return
+		}
+		log.Printf("MQTT: Started update of %s from Home Assistant (operation %s)", name, operationID)
+	}()
+}
+
+// handleEvent tracks update progress on the entities and forwards operation events.
+func (b *Bridge) handleEvent(event events.Event) {
+	switch event.Type {
+	case events.EventCheckProgress, events.EventOperationLog, events.EventDroppedWarning:
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if data, err := json.Marshal(event); err == nil {
+		b.publish(b.cfg.TopicPrefix+"/events", data, false)
+	}
+
+	operationID, _ := event.Payload["operation_id"].(string)
+	containerName, _ := event.Payload["container_name"].(string)
+	id := b.operations[operationID]
+	if id == "" && containerName != "" {
+		id = entityID(containerName)
+	}
+	e, found := b.entities[id]
+	if !found {
+		return
+	}
+
+	switch event.Type {
+	case events.EventUpdateProgress:
+		stage, _ := event.Payload["stage"].(string)
+		if stage == "complete" || stage == "failed" {
+			delete(b.operations, operationID)
+			e.InProgress, e.Percentage = false, nil
+			break
+		}
+		if operationID != "" {
+			b.operations[operationID] = id
+		}
+		e.InProgress = true
+		if progress, ok := event.Payload["progress"].(int); ok {
+			e.Percentage = &progress
+		}
+	case events.EventContainerUpdated:
+		delete(b.operations, operationID)
+		e.InProgress, e.Percentage = false, nil
+	default:
+		return
+	}
+	b.publishState(id, e)
+}
+
+func (b *Bridge) availabilityTopic() string {
+	return b.cfg.TopicPrefix + "/status"
+}
+
+func (b *Bridge) stateTopic(id string) string {
+	return b.cfg.TopicPrefix + "/" + id + "/state"
+}
+
+func (b *Bridge) commandTopic(id string) string {
+	return b.cfg.TopicPrefix + "/" + id + "/install"
+}
+
+func (b *Bridge) discoveryTopic(id string) string {
+	return b.cfg.DiscoveryPrefix + "/update/docksmith_" + id + "/config"
+}
+
+// invalidEntityChars matches characters not allowed in discovery topics and unique IDs.
+var invalidEntityChars = regexp.MustCompile(`[^a-z0-9_-]+`)
+
+// entityID returns the topic-safe ID of a container's entity.
+func entityID(containerName string) string {
+	return invalidEntityChars.ReplaceAllString(strings.ToLower(containerName), "_")
+}
+
+// shortDigest abbreviates an image digest for display as a version.
+func shortDigest(digest string) string {
+	digest = strings.TrimPrefix(digest, "sha256:")
+	if len(digest) > 12 {
+		digest = digest[:12]
+	}
+	if digest == "" {
+		return "latest"
+	}
+	return "sha256:" + digest
+}
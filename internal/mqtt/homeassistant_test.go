@@ -0,0 +1,155 @@
+package mqtt
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/chis/docksmith/internal/events"
+	"github.com/chis/docksmith/internal/update"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recorder records published messages, keeping the last one per topic.
+type recorder struct {
+	mu       sync.Mutex
+	messages map[string]Message
+}
+
+func (r *recorder) Publish(topic string, payload []byte, retain bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.messages[topic] = Message{Topic: topic, Payload: payload, Retain: retain}
+	return nil
+}
+
+func (r *recorder) json(t *testing.T, topic string) map[string]any {
+	t.Helper()
+	r.mu.Lock()
+	msg, ok := r.messages[topic]
+	r.mu.Unlock()
+	require.True(t, ok, "nothing published to %s", topic)
+	var v map[string]any
+	require.NoError(t, json.Unmarshal(msg.Payload, &v))
+	return v
+}
+
+func newTestBridge(updateFn UpdateFunc) (*Bridge, *recorder) {
+	rec := &recorder{messages: make(map[string]Message)}
+	b := NewBridge(Config{}, updateFn)
+	b.client = rec
+	return b, rec
+}
+
+func container(name string, status update.UpdateStatus, current, latest string) update.ContainerInfo {
+	return update.ContainerInfo{ContainerUpdate: update.ContainerUpdate{
+		ContainerName:  name,
+		Image:          "ghcr.io/org/" + name + ":" + current,
+		CurrentVersion: current,
+		LatestVersion:  latest,
+		Status:         status,
+	}}
+}
+
+func TestBridge_Sync(t *testing.T) {
+	b, rec := newTestBridge(nil)
+
+	b.Sync(context.Background(), &update.DiscoveryResult{Containers: []update.ContainerInfo{
+		container("My.App", update.UpdateAvailable, "1.1.0", "1.2.0"),
+		container("db", update.UpToDate, "16.1", ""),
+		container("ignored", update.Ignored, "1.0", ""),
+	}})
+
+	config := rec.json(t, "homeassistant/update/docksmith_my_app/config")
+	assert.Equal(t, "My.App", config["name"])
+	assert.Equal(t, "docksmith_my_app", config["unique_id"])
+	assert.Equal(t, "docksmith/my_app/state", config["state_topic"])
+	assert.Equal(t, "docksmith/my_app/install", config["command_topic"])
+	assert.Equal(t, "install", config["payload_install"])
+	assert.Equal(t, "docksmith/status", config["availability_topic"])
+	assert.True(t, rec.messages["homeassistant/update/docksmith_my_app/config"].Retain)
+
+	state := rec.json(t, "docksmith/my_app/state")
+	assert.Equal(t, "1.1.0", state["installed_version"])
+	assert.Equal(t, "1.2.0", state["latest_version"])
+	assert.Equal(t, false, state["in_progress"])
+
+	state = rec.json(t, "docksmith/db/state")
+	assert.Equal(t, "16.1", state["installed_version"])
+	assert.Equal(t, "16.1", state["latest_version"], "no update shows the installed version as latest")
+	assert.NotContains(t, rec.messages, "homeassistant/update/docksmith_ignored/config")
+
+	// A removed container's entity is cleared
+	b.Sync(context.Background(), &update.DiscoveryResult{Containers: []update.ContainerInfo{
+		container("db", update.UpToDate, "16.1", ""),
+	}})
+	assert.Empty(t, rec.messages["homeassistant/update/docksmith_my_app/config"].Payload)
+}
+
+func TestBridge_DigestUpdate(t *testing.T) {
+	c := container("app", update.UpdateAvailable, "", "")
+	c.CurrentTag = "latest"
+	c.CurrentDigest = "sha256:1111111111111111"
+	c.LatestDigest = "sha256:2222222222222222"
+
+	e := newEntity(c)
+	assert.Equal(t, "sha256:111111111111", e.InstalledVersion)
+	assert.Equal(t, "sha256:222222222222", e.LatestVersion)
+}
+
+func TestBridge_InstallCommand(t *testing.T) {
+	started := make(chan string, 1)
+	b, _ := newTestBridge(func(ctx context.Context, name string) (string, error) {
+		assert.Equal(t, Trigger, update.TriggerFromContext(ctx))
+		started <- name
+		return "op-1", nil
+	})
+	b.Sync(context.Background(), &update.DiscoveryResult{Containers: []update.ContainerInfo{
+		container("My.App", update.UpdateAvailable, "1.1.0", "1.2.0"),
+	}})
+
+	b.handleMessage(Message{Topic: "docksmith/unknown/install", Payload: []byte("install")})
+	b.handleMessage(Message{Topic: "docksmith/my_app/install", Payload: []byte("something")})
+	b.handleMessage(Message{Topic: "docksmith/my_app/install", Payload: []byte("install")})
+
+	select {
+	case name := <-started:
+		assert.Equal(t, "My.App", name)
+	case <-time.After(2 * time.Second):
+		t.Fatal("update not started")
+	}
+	select {
+	case name := <-started:
+		t.Fatalf("unexpected update of %s", name)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestBridge_Progress(t *testing.T) {
+	b, rec := newTestBridge(nil)
+	b.Sync(context.Background(), &update.DiscoveryResult{Containers: []update.ContainerInfo{
+		container("app", update.UpdateAvailable, "1.1.0", "1.2.0"),
+	}})
+
+	b.handleEvent(events.Event{Type: events.EventUpdateProgress, Payload: map[string]any{
+		"operation_id": "op-1", "container_name": "app", "stage": "pulling_image", "progress": 40,
+	}})
+	state := rec.json(t, "docksmith/app/state")
+	assert.Equal(t, true, state["in_progress"])
+	assert.Equal(t, float64(40), state["update_percentage"])
+
+	event := rec.json(t, "docksmith/events")
+	assert.Equal(t, events.EventUpdateProgress, event["type"])
+	assert.False(t, rec.messages["docksmith/events"].Retain)
+
+	// Failures may not name the container; the operation identifies it
+	b.handleEvent(events.Event{Type: events.EventUpdateProgress, Payload: map[string]any{
+		"operation_id": "op-1", "stage": "failed", "progress": 0,
+	}})
+	state = rec.json(t, "docksmith/app/state")
+	assert.Equal(t, false, state["in_progress"])
+	assert.Nil(t, state["update_percentage"])
+}
@@ -0,0 +1,133 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// sendTimeout bounds a single notification delivery.
+const sendTimeout = 15 * time.Second
+
+// Message kinds, which push channels map to priorities
+const (
+	KindUpdate  = "update"  // Updates found by a check
+	KindDigest  = "digest"  // Scheduled digest of the updates found
+	KindFailure = "failure" // An update failed
+)
+
+// Message is one notification sent to a channel.
+type Message struct {
+	Kind     string    `json:"kind"`
+	Title    string    `json:"title"`
+	Text     string    `json:"text"`
+	Period   string    `json:"period,omitempty"` // Digest period, for digests
+	Findings []Finding `json:"findings,omitempty"`
+	Failure  *Failure  `json:"failure,omitempty"`
+}
+
+// Failure is the failed operation announced by a failure message.
+type Failure struct {
+	ContainerName string `json:"container_name"`
+	Operation     string `json:"operation"` // update or rollback
+	Error         string `json:"error,omitempty"`
+}
+
+// Channel delivers notifications to an external service.
+type Channel interface {
+	// Name identifies the channel in logs.
+	Name() string
+
+	// Send delivers msg, returning an error if the service rejected it.
+	Send(ctx context.Context, msg Message) error
+}
+
+// WebhookChannel posts messages as JSON to a generic webhook endpoint.
+type WebhookChannel struct {
+	url    string
+	client *http.Client
+}
+
+// NewWebhookChannel creates a channel posting to url.
+func NewWebhookChannel(url string) *WebhookChannel {
+	return &WebhookChannel{url: url, client: &http.Client{Timeout: sendTimeout}}
+}
+
+// Name returns "webhook".
+func (c *WebhookChannel) Name() string {
+	return "webhook"
+}
+
+// Send posts msg as JSON.
+func (c *WebhookChannel) Send(ctx context.Context, msg Message) error {
+	return postJSON(ctx, c.client, c.url, msg)
+}
+
+// SlackChannel posts messages to a Slack incoming webhook.
+type SlackChannel struct {
+	url     string
+	channel string // Overrides the webhook's channel when set
+	client  *http.Client
+}
+
+// NewSlackChannel creates a channel posting to a Slack incoming webhook url.
+func NewSlackChannel(url string) *SlackChannel {
+	return &SlackChannel{url: url, client: &http.Client{Timeout: sendTimeout}}
+}
+
+// Name returns "slack".
+func (c *SlackChannel) Name() string {
+	return "slack"
+}
+
+// Send posts msg as a Slack message with the title in bold.
+func (c *SlackChannel) Send(ctx context.Context, msg Message) error {
+	body := map[string]string{
+		"text": fmt.Sprintf("*%s*\n%s", msg.Title, msg.Text),
+	}
+	if c.channel != "" {
+		body["channel"] = c.channel
+	}
+	return postJSON(ctx, c.client, c.url, body)
+}
+
+// postJSON posts body as JSON to url and treats any non-2xx status as a failure.
+func postJSON(ctx context.Context, client *http.Client, url string, body any) error {
+	req, err := newJSONRequest(ctx, url, body)
+	if err != nil {
+		return err
+	}
+	return do(client, req)
+}
+
+// newJSONRequest creates a POST request with body encoded as JSON.
+func newJSONRequest(ctx context.Context, url string, body any) (*http.Request, error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode notification: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return req, nil
+}
+
+// do sends req and treats any non-2xx status as a failure.
+func do(client *http.Client, req *http.Request) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send notification: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("notification rejected with status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,651 @@
+// Package notify sends update notifications to webhook, Slack, Gotify, ntfy and
+// Pushover channels. In immediate mode each newly detected update is announced once, right after
+// the check that found it. In digest mode findings are collected and sent as a
+// single message per channel on a daily or weekly schedule, so frequent
+// background checks do not flood the channels. Failed updates are announced
+// right away in both modes. A container's docksmith.notify label routes its
+// notifications to specific channels or destinations instead of every channel.
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chis/docksmith/internal/events"
+	"github.com/chis/docksmith/internal/scripts"
+	"github.com/chis/docksmith/internal/secrets"
+	"github.com/chis/docksmith/internal/storage"
+	"github.com/chis/docksmith/internal/update"
+	"github.com/chis/docksmith/internal/version"
+)
+
+// Notification modes
+const (
+	ModeImmediate = "immediate"
+	ModeDigest    = "digest"
+)
+
+// stateConfigKey persists announced updates and the pending digest across restarts.
+const stateConfigKey = "notify_state"
+
+// Config keys holding the notification settings used when the corresponding
+// NOTIFY_* environment variable is not set, e.g. after a configuration import.
+const (
+	WebhookURLConfigKey       = "notify_webhook_url"
+	SlackWebhookURLConfigKey  = "notify_slack_webhook_url"
+	ModeConfigKey             = "notify_mode"
+	DigestPeriodConfigKey     = "notify_digest_period"
+	DigestTimeConfigKey       = "notify_digest_time"
+	DigestWeekdayConfigKey    = "notify_digest_weekday"
+	GotifyURLConfigKey        = "notify_gotify_url"
+	GotifyTokenConfigKey      = "notify_gotify_token"
+	GotifyPriorityConfigKey   = "notify_gotify_priority"
+	NtfyURLConfigKey          = "notify_ntfy_url"
+	NtfyTokenConfigKey        = "notify_ntfy_token"
+	NtfyPriorityConfigKey     = "notify_ntfy_priority"
+	PushoverTokenConfigKey    = "notify_pushover_token"
+	PushoverUserConfigKey     = "notify_pushover_user"
+	PushoverPriorityConfigKey = "notify_pushover_priority"
+)
+
+// Finding is a detected update included in a notification.
+type Finding struct {
+	ContainerName  string    `json:"container_name"`
+	Stack          string    `json:"stack,omitempty"`
+	Image          string    `json:"image"`
+	CurrentVersion string    `json:"current_version,omitempty"`
+	LatestVersion  string    `json:"latest_version"`
+	ChangeType     string    `json:"change_type,omitempty"`
+	ChangelogURL   string    `json:"changelog_url,omitempty"` // From the image's OCI source or url label
+	DetectedAt     time.Time `json:"detected_at"`
+}
+
+// Config selects how findings are delivered.
+type Config struct {
+	Mode     string
+	Schedule Schedule // digest schedule, used in digest mode
+}
+
+// state is the persisted notification state.
+type state struct {
+	Notified   map[string]string `json:"notified"`              // container -> announced target version
+	Pending    []Finding         `json:"pending,omitempty"`     // findings waiting for the next digest
+	LastDigest time.Time         `json:"last_digest,omitempty"` // when the last digest was sent
+	Routes     map[string]string `json:"routes,omitempty"`      // container -> docksmith.notify label
+}
+
+// Manager announces detected updates on its channels.
+type Manager struct {
+	store    storage.Storage
+	channels []Channel
+	mode     string
+	schedule Schedule
+	now      func() time.Time
+
+	mu        sync.Mutex
+	state     state
+	templates map[string]Template // Channel name -> message template
+	routes    map[string]route    // docksmith.notify label -> its channels
+	bus       *events.Bus
+	stopChan  chan struct{}
+}
+
+// NewManager creates a notification manager. store is optional; without it
+// announced updates and the pending digest are lost on restart.
+func NewManager(store storage.Storage, channels []Channel, cfg Config) *Manager {
+	if cfg.Mode == "" {
+		cfg.Mode = ModeImmediate
+	}
+	if cfg.Schedule.Period == "" {
+		cfg.Schedule, _ = ParseSchedule("", "", "")
+	}
+
+	m := &Manager{
+		store:    store,
+		channels: channels,
+		mode:     cfg.Mode,
+		schedule: cfg.Schedule,
+		now:      time.Now,
+		state:    state{Notified: make(map[string]string)},
+	}
+	m.load(context.Background())
+	m.loadTemplates(context.Background())
+	return m
+}
+
+// NewManagerFromEnv creates a manager for the channels configured by
+// NOTIFY_WEBHOOK_URL, NOTIFY_SLACK_WEBHOOK_URL, NOTIFY_GOTIFY_URL and
+// NOTIFY_GOTIFY_TOKEN, NOTIFY_NTFY_URL (with the optional NOTIFY_NTFY_TOKEN), and
+// NOTIFY_PUSHOVER_TOKEN and NOTIFY_PUSHOVER_USER. NOTIFY_GOTIFY_PRIORITY,
+// NOTIFY_NTFY_PRIORITY and NOTIFY_PUSHOVER_PRIORITY map message kinds to the
+// service's priorities, see ParsePriorities. NOTIFY_MODE selects
+// immediate (default) or digest delivery, and NOTIFY_DIGEST_PERIOD,
+// NOTIFY_DIGEST_TIME and NOTIFY_DIGEST_WEEKDAY set the digest schedule.
+// Unset variables fall back to the imported settings in the database. Settings
+// may refer to a secret of secretStore ("secret:NAME") instead of holding a
+// token or URL in plaintext.
+// Returns nil when no channel is configured.
+func NewManagerFromEnv(store storage.Storage, secretStore *secrets.Store) (*Manager, error) {
+	ctx := context.Background()
+	var resolveErr error
+	setting := func(env, key string) string {
+		value, err := secretStore.Resolve(ctx, storage.EnvOrConfig(ctx, store, env, key))
+		if err != nil && resolveErr == nil {
+			resolveErr = fmt.Errorf("invalid %s: %w", env, err)
+		}
+		return value
+	}
+
+	var channels []Channel
+	if url := setting("NOTIFY_WEBHOOK_URL", WebhookURLConfigKey); url != "" {
+		channels = append(channels, NewWebhookChannel(url))
+	}
+	if url := setting("NOTIFY_SLACK_WEBHOOK_URL", SlackWebhookURLConfigKey); url != "" {
+		channels = append(channels, NewSlackChannel(url))
+	}
+	if url, token := setting("NOTIFY_GOTIFY_URL", GotifyURLConfigKey), setting("NOTIFY_GOTIFY_TOKEN", GotifyTokenConfigKey); url != "" && token != "" {
+		priorities, err := ParsePriorities("gotify", setting("NOTIFY_GOTIFY_PRIORITY", GotifyPriorityConfigKey))
+		if err != nil {
+			return nil, err
+		}
+		channels = append(channels, NewGotifyChannel(url, token, priorities))
+	}
+	if url := setting("NOTIFY_NTFY_URL", NtfyURLConfigKey); url != "" {
+		priorities, err := ParsePriorities("ntfy", setting("NOTIFY_NTFY_PRIORITY", NtfyPriorityConfigKey))
+		if err != nil {
+			return nil, err
+		}
+		channels = append(channels, NewNtfyChannel(url, setting("NOTIFY_NTFY_TOKEN", NtfyTokenConfigKey), priorities))
+	}
+	if token, user := setting("NOTIFY_PUSHOVER_TOKEN", PushoverTokenConfigKey), setting("NOTIFY_PUSHOVER_USER", PushoverUserConfigKey); token != "" && user != "" {
+		priorities, err := ParsePriorities("pushover", setting("NOTIFY_PUSHOVER_PRIORITY", PushoverPriorityConfigKey))
+		if err != nil {
+			return nil, err
+		}
+		channels = append(channels, NewPushoverChannel(token, user, priorities))
+	}
+	if resolveErr != nil {
+		return nil, resolveErr
+	}
+	if len(channels) == 0 {
+		return nil, nil
+	}
+
+	cfg := Config{Mode: strings.ToLower(strings.TrimSpace(setting("NOTIFY_MODE", ModeConfigKey)))}
+	switch cfg.Mode {
+	case "", ModeImmediate, ModeDigest:
+	default:
+		return nil, fmt.Errorf("invalid NOTIFY_MODE %q (must be immediate or digest)", cfg.Mode)
+	}
+
+	schedule, err := ParseSchedule(
+		setting("NOTIFY_DIGEST_PERIOD", DigestPeriodConfigKey),
+		setting("NOTIFY_DIGEST_TIME", DigestTimeConfigKey),
+		setting("NOTIFY_DIGEST_WEEKDAY", DigestWeekdayConfigKey),
+	)
+	if err != nil {
+		return nil, err
+	}
+	cfg.Schedule = schedule
+
+	return NewManager(store, channels, cfg), nil
+}
+
+// Mode returns the delivery mode.
+func (m *Manager) Mode() string {
+	return m.mode
+}
+
+// Schedule returns the digest schedule.
+func (m *Manager) Schedule() Schedule {
+	return m.schedule
+}
+
+// Sync records the updates found by a check. New updates are sent right away in
+// immediate mode and queued for the next digest in digest mode. Each update is
+// announced once per target version. Registered as a background checker result handler.
+func (m *Manager) Sync(ctx context.Context, result *update.DiscoveryResult) {
+	if result == nil {
+		return
+	}
+
+	m.mu.Lock()
+	now := m.now()
+	available := make(map[string]bool)
+	var found []Finding
+
+	routes := make(map[string]string)
+	for _, c := range result.Containers {
+		if spec := strings.TrimSpace(c.Labels[scripts.NotifyLabel]); spec != "" {
+			routes[c.ContainerName] = spec
+		}
+		if c.Status != update.UpdateAvailable {
+			continue
+		}
+		finding := newFinding(c, now)
+		available[finding.ContainerName] = true
+		if m.state.Notified[finding.ContainerName] == finding.LatestVersion {
+			continue
+		}
+		m.state.Notified[finding.ContainerName] = finding.LatestVersion
+		found = append(found, finding)
+	}
+
+	m.state.Routes = routes
+
+	// Forget updates that were applied or withdrawn so a later one is announced again
+	for name := range m.state.Notified {
+		if !available[name] {
+			delete(m.state.Notified, name)
+		}
+	}
+
+	if m.mode == ModeDigest {
+		m.state.Pending = mergePending(m.state.Pending, found, available)
+		found = nil
+	}
+	m.save(ctx)
+	m.mu.Unlock()
+
+	undelivered := m.deliver(ctx, found, "")
+	if len(undelivered) == 0 {
+		return
+	}
+
+	// Not delivered anywhere: announce again after the next check
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, f := range undelivered {
+		if m.state.Notified[f.ContainerName] == f.LatestVersion {
+			delete(m.state.Notified, f.ContainerName)
+		}
+	}
+	m.save(ctx)
+}
+
+// SendDigest sends the pending findings as one message per channel, split by the
+// channels of the containers' docksmith.notify labels. Findings are kept for the
+// next digest if every channel of theirs fails. Does nothing when no findings are pending.
+func (m *Manager) SendDigest(ctx context.Context) error {
+	m.mu.Lock()
+	pending := m.state.Pending
+	m.mu.Unlock()
+
+	if len(pending) == 0 {
+		return nil
+	}
+
+	undelivered := m.deliver(ctx, pending, m.schedule.Period)
+	if len(undelivered) == len(pending) {
+		return fmt.Errorf("digest could not be delivered to any channel")
+	}
+
+	kept := make(map[Finding]bool, len(undelivered))
+	for _, f := range undelivered {
+		kept[f] = true
+	}
+	sent := make(map[Finding]bool, len(pending))
+	for _, f := range pending {
+		sent[f] = !kept[f]
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	// Keep findings queued while the digest was being sent
+	remaining := m.state.Pending[:0]
+	for _, f := range m.state.Pending {
+		if !sent[f] {
+			remaining = append(remaining, f)
+		}
+	}
+	m.state.Pending = remaining
+	m.state.LastDigest = m.now()
+	m.save(ctx)
+	if len(undelivered) > 0 {
+		return fmt.Errorf("digest of %d updates could not be delivered, keeping them for the next digest", len(undelivered))
+	}
+	return nil
+}
+
+// SetEventBus enables failed update notifications for the updates published on bus.
+// Must be called before Start.
+func (m *Manager) SetEventBus(bus *events.Bus) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.bus = bus
+}
+
+// NotifyFailure announces a failed update or rollback right away, in both modes,
+// on the channels of the container. operation is "update" or "rollback".
+func (m *Manager) NotifyFailure(ctx context.Context, containerName, operation, reason string) {
+	m.send(ctx, m.channelsFor(containerName), failureMessage(containerName, operation, reason))
+}
+
+// Start runs the digest scheduler in digest mode and watches for failed updates
+// when an event bus is set.
+func (m *Manager) Start() {
+	m.mu.Lock()
+	if m.stopChan != nil {
+		m.mu.Unlock()
+		return
+	}
+	stopChan := make(chan struct{})
+	m.stopChan = stopChan
+	bus := m.bus
+	m.mu.Unlock()
+
+	if bus != nil {
+		sub, unsubscribe := bus.Subscribe(events.EventContainerUpdated)
+		go m.watchFailures(stopChan, sub, unsubscribe)
+	}
+
+	if m.mode == ModeDigest {
+		log.Printf("NOTIFY: Sending update digests %s to %s", m.schedule, m.channelNames())
+		go m.run(stopChan)
+	}
+}
+
+// watchFailures announces the failed updates and rollbacks published on sub until
+// stopChan is closed. Other failed operations, such as restarts, are not announced.
+func (m *Manager) watchFailures(stopChan chan struct{}, sub events.Subscriber, unsubscribe func()) {
+	defer unsubscribe()
+	for {
+		select {
+		case <-stopChan:
+			return
+		case event, ok := <-sub:
+			if !ok {
+				return
+			}
+			if status, _ := event.Payload["status"].(string); status != "failed" {
+				continue
+			}
+			name, _ := event.Payload["container_name"].(string)
+			reason, _ := event.Payload["error"].(string)
+			operation := ""
+			switch opType, _ := event.Payload["operation_type"].(string); opType {
+			case "single", "batch", "stack":
+				operation = "update"
+			case "rollback":
+				operation = "rollback"
+			}
+			if name != "" && operation != "" {
+				m.NotifyFailure(context.Background(), name, operation, reason)
+			}
+		}
+	}
+}
+
+// Stop stops the digest scheduler and the failure watcher.
+func (m *Manager) Stop() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.stopChan != nil {
+		close(m.stopChan)
+		m.stopChan = nil
+	}
+}
+
+// run sends a digest at every scheduled time until stopChan is closed.
+func (m *Manager) run(stopChan chan struct{}) {
+	for {
+		next := m.schedule.Next(m.now())
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-stopChan:
+			timer.Stop()
+			return
+		case <-timer.C:
+			if err := m.SendDigest(context.Background()); err != nil {
+				log.Printf("NOTIFY: %v", err)
+			}
+		}
+	}
+}
+
+// deliver sends findings as one message per set of channels their containers
+// are routed to, and returns the findings no channel accepted. Findings of
+// containers whose notifications are off count as delivered.
+func (m *Manager) deliver(ctx context.Context, findings []Finding, period string) []Finding {
+	type group struct {
+		channels []Channel
+		findings []Finding
+	}
+	var specs []string
+	groups := make(map[string]*group)
+
+	m.mu.Lock()
+	for _, f := range findings {
+		spec := m.state.Routes[f.ContainerName]
+		if m.resolveLocked(spec).err != nil {
+			spec = ""
+		}
+		g, ok := groups[spec]
+		if !ok {
+			g = &group{channels: m.channelsForLocked(spec)}
+			groups[spec] = g
+			specs = append(specs, spec)
+		}
+		g.findings = append(g.findings, f)
+	}
+	m.mu.Unlock()
+
+	var undelivered []Finding
+	for _, spec := range specs {
+		g := groups[spec]
+		if len(g.channels) == 0 {
+			continue
+		}
+		if !m.send(ctx, g.channels, buildMessage(g.findings, period)) {
+			undelivered = append(undelivered, g.findings...)
+		}
+	}
+	return undelivered
+}
+
+// send delivers msg to channels and reports whether any accepted it.
+func (m *Manager) send(ctx context.Context, channels []Channel, msg Message) bool {
+	delivered := false
+	for _, ch := range channels {
+		sendCtx, cancel := context.WithTimeout(ctx, sendTimeout)
+		err := ch.Send(sendCtx, m.render(ch.Name(), msg))
+		cancel()
+		if err != nil {
+			log.Printf("NOTIFY: Failed to send to %s: %v", ch.Name(), err)
+			continue
+		}
+		delivered = true
+	}
+	if delivered {
+		log.Printf("NOTIFY: Sent \"%s\"", msg.Title)
+	}
+	return delivered
+}
+
+// channelNames lists the configured channels for logging.
+func (m *Manager) channelNames() string {
+	return strings.Join(m.Channels(), ", ")
+}
+
+// load restores the persisted state. Caller must not hold m.mu.
+func (m *Manager) load(ctx context.Context) {
+	if m.store == nil {
+		return
+	}
+	value, found, err := m.store.GetConfig(ctx, stateConfigKey)
+	if err != nil || !found {
+		return
+	}
+	var s state
+	if err := json.Unmarshal([]byte(value), &s); err != nil {
+		log.Printf("NOTIFY: Ignoring invalid persisted state: %v", err)
+		return
+	}
+	if s.Notified == nil {
+		s.Notified = make(map[string]string)
+	}
+	m.state = s
+}
+
+// loadTemplates restores the message templates. Caller must not hold m.mu.
+func (m *Manager) loadTemplates(ctx context.Context) {
+	if m.store == nil {
+		return
+	}
+	value, found, err := m.store.GetConfig(ctx, TemplatesConfigKey)
+	if err != nil || !found || value == "" {
+		return
+	}
+	var templates map[string]Template
+	if err := json.Unmarshal([]byte(value), &templates); err != nil {
+		log.Printf("NOTIFY: Ignoring invalid message templates: %v", err)
+		return
+	}
+	m.templates = templates
+}
+
+// save persists the state. Caller must hold m.mu.
+func (m *Manager) save(ctx context.Context) {
+	if m.store == nil {
+		return
+	}
+	data, err := json.Marshal(m.state)
+	if err != nil {
+		return
+	}
+	if err := m.store.SetConfig(ctx, stateConfigKey, string(data)); err != nil {
+		log.Printf("NOTIFY: Failed to persist state: %v", err)
+	}
+}
+
+// newFinding describes the update available for c.
+func newFinding(c update.ContainerInfo, now time.Time) Finding {
+	target := c.LatestVersion
+	if target == "" {
+		target = shortDigest(c.LatestDigest)
+	}
+	changeType := ""
+	if c.ChangeType != version.NoChange && c.ChangeType != version.UnknownChange {
+		changeType = c.ChangeType.String()
+	}
+	return Finding{
+		ContainerName:  c.ContainerName,
+		Stack:          c.Stack,
+		Image:          c.Image,
+		CurrentVersion: c.CurrentVersion,
+		LatestVersion:  target,
+		ChangeType:     changeType,
+		ChangelogURL:   changelogURL(c.Labels),
+		DetectedAt:     now,
+	}
+}
+
+// changelogURL links to the release notes of an image from its OCI source or url
+// label: the releases page for GitHub repositories, otherwise the labeled URL.
+func changelogURL(labels map[string]string) string {
+	source := labels["org.opencontainers.image.source"]
+	if source == "" {
+		source = labels["org.opencontainers.image.url"]
+	}
+	if !strings.HasPrefix(source, "https://") && !strings.HasPrefix(source, "http://") {
+		return ""
+	}
+	source = strings.TrimSuffix(strings.TrimSuffix(source, "/"), ".git")
+	if strings.HasPrefix(source, "https://github.com/") && strings.Count(source, "/") == 4 {
+		return source + "/releases"
+	}
+	return source
+}
+
+// mergePending adds new findings to the digest queue, replacing older findings for the
+// same container and dropping containers whose update is no longer available.
+func mergePending(pending, found []Finding, available map[string]bool) []Finding {
+	replaced := make(map[string]bool, len(found))
+	for _, f := range found {
+		replaced[f.ContainerName] = true
+	}
+
+	merged := make([]Finding, 0, len(pending)+len(found))
+	for _, f := range pending {
+		if available[f.ContainerName] && !replaced[f.ContainerName] {
+			merged = append(merged, f)
+		}
+	}
+	return append(merged, found...)
+}
+
+// buildMessage formats findings as one message. period is set for digests.
+func buildMessage(findings []Finding, period string) Message {
+	sorted := make([]Finding, len(findings))
+	copy(sorted, findings)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Stack != sorted[j].Stack {
+			return sorted[i].Stack < sorted[j].Stack
+		}
+		return sorted[i].ContainerName < sorted[j].ContainerName
+	})
+
+	noun := "update"
+	if len(sorted) != 1 {
+		noun = "updates"
+	}
+	title := fmt.Sprintf("Docksmith: %d %s available", len(sorted), noun)
+	if period != "" {
+		title = fmt.Sprintf("Docksmith %s digest: %d %s available", period, len(sorted), noun)
+	}
+
+	lines := make([]string, len(sorted))
+	for i, f := range sorted {
+		name := f.ContainerName
+		if f.Stack != "" {
+			name = f.Stack + "/" + f.ContainerName
+		}
+		current := f.CurrentVersion
+		if current == "" {
+			current = "current"
+		}
+		line := fmt.Sprintf("• %s: %s → %s", name, current, f.LatestVersion)
+		if f.ChangeType != "" {
+			line += " (" + f.ChangeType + ")"
+		}
+		lines[i] = line
+	}
+
+	kind := KindUpdate
+	if period != "" {
+		kind = KindDigest
+	}
+	return Message{Kind: kind, Title: title, Text: strings.Join(lines, "\n"), Period: period, Findings: sorted}
+}
+
+// failureMessage announces a failed update or rollback.
+func failureMessage(containerName, operation, reason string) Message {
+	text := reason
+	if text == "" {
+		text = "See the operation history for details."
+	}
+	return Message{
+		Kind:    KindFailure,
+		Title:   fmt.Sprintf("Docksmith: %s of %s failed", operation, containerName),
+		Text:    text,
+		Failure: &Failure{ContainerName: containerName, Operation: operation, Error: reason},
+	}
+}
+
+// shortDigest shortens a sha256 digest for display.
+func shortDigest(digest string) string {
+	digest = strings.TrimPrefix(digest, "sha256:")
+	if len(digest) > 12 {
+		digest = digest[:12]
+	}
+	if digest == "" {
+		return "latest"
+	}
+	return "sha256:" + digest
+}
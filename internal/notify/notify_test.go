@@ -0,0 +1,463 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/chis/docksmith/internal/events"
+	"github.com/chis/docksmith/internal/scripts"
+	"github.com/chis/docksmith/internal/secrets"
+	"github.com/chis/docksmith/internal/storage"
+	"github.com/chis/docksmith/internal/update"
+	"github.com/chis/docksmith/internal/version"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeChannel records the messages sent to it.
+type fakeChannel struct {
+	name     string
+	messages []Message
+	err      error
+}
+
+func (f *fakeChannel) Name() string {
+	if f.name != "" {
+		return f.name
+	}
+	return "fake"
+}
+
+func (f *fakeChannel) Send(ctx context.Context, msg Message) error {
+	if f.err != nil {
+		return f.err
+	}
+	f.messages = append(f.messages, msg)
+	return nil
+}
+
+func newTestStore(t *testing.T) storage.Storage {
+	t.Helper()
+	store, err := storage.NewSQLiteStorage(filepath.Join(t.TempDir(), "test.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+func container(name, current, latest string) update.ContainerInfo {
+	c := update.ContainerInfo{Stack: "media"}
+	c.ContainerName = name
+	c.Image = "nginx:" + current
+	c.CurrentVersion = current
+	c.LatestVersion = latest
+	c.ChangeType = version.MinorChange
+	c.Status = update.UpdateAvailable
+	return c
+}
+
+func result(containers ...update.ContainerInfo) *update.DiscoveryResult {
+	return &update.DiscoveryResult{Containers: containers}
+}
+
+func TestManager_ImmediateAnnouncesEachUpdateOnce(t *testing.T) {
+	ctx := context.Background()
+	ch := &fakeChannel{}
+	m := NewManager(nil, []Channel{ch}, Config{Mode: ModeImmediate})
+
+	m.Sync(ctx, result(container("web", "1.0", "1.1")))
+	m.Sync(ctx, result(container("web", "1.0", "1.1")))
+	require.Len(t, ch.messages, 1)
+	assert.Equal(t, "Docksmith: 1 update available", ch.messages[0].Title)
+	assert.Contains(t, ch.messages[0].Text, "media/web: 1.0 → 1.1 (minor)")
+
+	// A newer target version is announced again
+	m.Sync(ctx, result(container("web", "1.0", "1.2")))
+	require.Len(t, ch.messages, 2)
+}
+
+func TestManager_ImmediateRetriesUndeliveredUpdates(t *testing.T) {
+	ctx := context.Background()
+	ch := &fakeChannel{err: errors.New("unreachable")}
+	m := NewManager(nil, []Channel{ch}, Config{Mode: ModeImmediate})
+
+	m.Sync(ctx, result(container("web", "1.0", "1.1")))
+	ch.err = nil
+	m.Sync(ctx, result(container("web", "1.0", "1.1")))
+	assert.Len(t, ch.messages, 1)
+}
+
+func TestManager_DigestAggregatesFindings(t *testing.T) {
+	ctx := context.Background()
+	slack, webhook := &fakeChannel{}, &fakeChannel{}
+	m := NewManager(nil, []Channel{slack, webhook}, Config{Mode: ModeDigest})
+
+	// Several checks find updates, nothing is sent until the digest
+	m.Sync(ctx, result(container("web", "1.0", "1.1")))
+	m.Sync(ctx, result(container("web", "1.0", "1.2"), container("db", "15.1", "15.2")))
+	m.Sync(ctx, result(container("web", "1.0", "1.2"), container("db", "15.1", "15.2"), container("cache", "7.0", "7.2")))
+	assert.Empty(t, slack.messages)
+
+	// The cache update was applied before the digest went out
+	cache := container("cache", "7.2", "")
+	cache.Status = update.UpToDate
+	m.Sync(ctx, result(container("web", "1.0", "1.2"), container("db", "15.1", "15.2"), cache))
+
+	require.NoError(t, m.SendDigest(ctx))
+	require.Len(t, slack.messages, 1)
+	require.Len(t, webhook.messages, 1)
+
+	msg := slack.messages[0]
+	assert.Equal(t, "Docksmith daily digest: 2 updates available", msg.Title)
+	require.Len(t, msg.Findings, 2)
+	assert.Equal(t, "db", msg.Findings[0].ContainerName)
+	assert.Equal(t, "1.2", msg.Findings[1].LatestVersion)
+
+	// Nothing new: no empty digest
+	require.NoError(t, m.SendDigest(ctx))
+	assert.Len(t, slack.messages, 1)
+}
+
+func TestManager_DigestKeptWhenUndelivered(t *testing.T) {
+	ctx := context.Background()
+	ch := &fakeChannel{err: errors.New("unreachable")}
+	m := NewManager(nil, []Channel{ch}, Config{Mode: ModeDigest})
+
+	m.Sync(ctx, result(container("web", "1.0", "1.1")))
+	assert.Error(t, m.SendDigest(ctx))
+
+	ch.err = nil
+	require.NoError(t, m.SendDigest(ctx))
+	require.Len(t, ch.messages, 1)
+	assert.Len(t, ch.messages[0].Findings, 1)
+}
+
+func TestManager_DigestSurvivesRestart(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStore(t)
+
+	first := NewManager(store, []Channel{&fakeChannel{}}, Config{Mode: ModeDigest})
+	first.Sync(ctx, result(container("web", "1.0", "1.1")))
+
+	ch := &fakeChannel{}
+	restarted := NewManager(store, []Channel{ch}, Config{Mode: ModeDigest})
+	restarted.Sync(ctx, result(container("web", "1.0", "1.1")))
+	require.NoError(t, restarted.SendDigest(ctx))
+	require.Len(t, ch.messages, 1)
+	assert.Len(t, ch.messages[0].Findings, 1)
+}
+
+func TestNewManagerFromEnv_SecretReferences(t *testing.T) {
+	for _, env := range []string{"NOTIFY_WEBHOOK_URL", "NOTIFY_SLACK_WEBHOOK_URL", "NOTIFY_GOTIFY_URL", "NOTIFY_GOTIFY_TOKEN", "NOTIFY_NTFY_URL", "NOTIFY_PUSHOVER_TOKEN", "NOTIFY_PUSHOVER_USER", "NOTIFY_MODE"} {
+		t.Setenv(env, "")
+	}
+	ctx := context.Background()
+	store := storage.NewMemoryStorage()
+	secretStore, err := secrets.NewStore(store, bytes.Repeat([]byte{1}, secrets.KeySize))
+	require.NoError(t, err)
+	_, err = secretStore.Set(ctx, "gotify", "A1b2C3")
+	require.NoError(t, err)
+
+	require.NoError(t, store.SetConfig(ctx, GotifyURLConfigKey, "https://gotify.example.com"))
+	t.Setenv("NOTIFY_GOTIFY_TOKEN", "secret:gotify")
+	m, err := NewManagerFromEnv(store, secretStore)
+	require.NoError(t, err)
+	require.Len(t, m.channels, 1)
+	assert.Equal(t, "A1b2C3", m.channels[0].(*GotifyChannel).token)
+
+	t.Setenv("NOTIFY_GOTIFY_TOKEN", "secret:missing")
+	_, err = NewManagerFromEnv(store, secretStore)
+	assert.ErrorContains(t, err, "invalid NOTIFY_GOTIFY_TOKEN")
+
+	// Without a key, references cannot be resolved
+	_, err = NewManagerFromEnv(store, nil)
+	assert.ErrorIs(t, err, secrets.ErrDisabled)
+}
+
+func TestSlackChannel_Send(t *testing.T) {
+	var payload map[string]string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+	}))
+	defer srv.Close()
+
+	msg := buildMessage([]Finding{{ContainerName: "web", CurrentVersion: "1.0", LatestVersion: "1.1"}}, PeriodWeekly)
+	require.NoError(t, NewSlackChannel(srv.URL).Send(context.Background(), msg))
+	assert.Equal(t, "*Docksmith weekly digest: 1 update available*\n• web: 1.0 → 1.1", payload["text"])
+}
+
+func TestWebhookChannel_RejectedStatus(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer srv.Close()
+
+	err := NewWebhookChannel(srv.URL).Send(context.Background(), Message{Title: "test"})
+	assert.ErrorContains(t, err, "502")
+}
+
+func TestParsePriorities(t *testing.T) {
+	p, err := ParsePriorities("ntfy", "")
+	require.NoError(t, err)
+	assert.Equal(t, Priorities{KindUpdate: 3, KindDigest: 2, KindFailure: 4}, p)
+
+	p, err = ParsePriorities("pushover", "failure=emergency, digest=-2")
+	require.NoError(t, err)
+	assert.Equal(t, Priorities{KindUpdate: 0, KindDigest: -2, KindFailure: 2}, p)
+
+	for _, spec := range []string{"failure", "failure=9", "failure=loud", "rollback=high"} {
+		_, err := ParsePriorities("gotify", spec+",update=20")
+		assert.Error(t, err, spec)
+	}
+	_, err = ParsePriorities("email", "")
+	assert.Error(t, err)
+}
+
+func TestPushChannels_Priority(t *testing.T) {
+	var gotify map[string]any
+	var ntfyHeader http.Header
+	var ntfyBody string
+	var pushover map[string][]string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/gotify/message":
+			assert.Equal(t, "app-token", r.Header.Get("X-Gotify-Key"))
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&gotify))
+		case "/docksmith":
+			ntfyHeader = r.Header
+			body, _ := io.ReadAll(r.Body)
+			ntfyBody = string(body)
+		case "/pushover":
+			require.NoError(t, r.ParseForm())
+			pushover = r.PostForm
+		}
+	}))
+	defer srv.Close()
+
+	ctx := context.Background()
+	failure := Message{Kind: KindFailure, Title: "Docksmith: update of web failed", Text: "pull failed"}
+	digest := buildMessage([]Finding{{ContainerName: "web", CurrentVersion: "1.0", LatestVersion: "1.1"}}, PeriodDaily)
+
+	require.NoError(t, NewGotifyChannel(srv.URL+"/gotify/", "app-token", nil).Send(ctx, failure))
+	assert.Equal(t, "pull failed", gotify["message"])
+	assert.Equal(t, float64(8), gotify["priority"])
+	require.NoError(t, NewGotifyChannel(srv.URL+"/gotify", "app-token", nil).Send(ctx, digest))
+	assert.Equal(t, float64(2), gotify["priority"])
+
+	priorities, err := ParsePriorities("ntfy", "failure=urgent")
+	require.NoError(t, err)
+	require.NoError(t, NewNtfyChannel(srv.URL+"/docksmith", "tk_secret", priorities).Send(ctx, failure))
+	assert.Equal(t, "5", ntfyHeader.Get("Priority"))
+	assert.Equal(t, "failure", ntfyHeader.Get("Tags"))
+	assert.Equal(t, "Bearer tk_secret", ntfyHeader.Get("Authorization"))
+	assert.Equal(t, "Docksmith: update of web failed", ntfyHeader.Get("Title"))
+	assert.Equal(t, "pull failed", ntfyBody)
+
+	ch := NewPushoverChannel("app", "user", Priorities{KindFailure: 2})
+	ch.url = srv.URL + "/pushover"
+	require.NoError(t, ch.Send(ctx, failure))
+	assert.Equal(t, []string{"2"}, pushover["priority"])
+	assert.Equal(t, []string{"60"}, pushover["retry"], "emergency messages need a retry interval")
+	assert.Equal(t, []string{"user"}, pushover["user"])
+}
+
+func TestParseRoute(t *testing.T) {
+	slack := NewSlackChannel("https://hooks.slack.com/services/T0/B0/x")
+	ntfy := NewNtfyChannel("https://ntfy.example.com/docksmith", "tk_secret", nil)
+	gotify := NewGotifyChannel("https://gotify.example.com", "app-token", nil)
+	configured := []Channel{slack, ntfy, gotify}
+
+	channels, err := ParseRoute("", configured)
+	require.NoError(t, err)
+	assert.Len(t, channels, 3)
+
+	channels, err = ParseRoute("none", configured)
+	require.NoError(t, err)
+	assert.Empty(t, channels)
+
+	channels, err = ParseRoute("slack:#homelab, ntfy:media-team, gotify", configured)
+	require.NoError(t, err)
+	require.Len(t, channels, 3)
+	assert.Equal(t, "#homelab", channels[0].(*SlackChannel).channel)
+	assert.Equal(t, slack.url, channels[0].(*SlackChannel).url)
+	assert.Equal(t, "https://ntfy.example.com/media-team", channels[1].(*NtfyChannel).url)
+	assert.Equal(t, "tk_secret", channels[1].(*NtfyChannel).token)
+	assert.Same(t, gotify, channels[2])
+
+	// The access token is not sent to other ntfy servers
+	channels, err = ParseRoute("ntfy:https://ntfy.sh/alerts", configured)
+	require.NoError(t, err)
+	assert.Empty(t, channels[0].(*NtfyChannel).token)
+
+	_, err = ParseRoute("pushover:uQiRzpo4DXghDmr9QzzfQu27cmVRsG", configured)
+	assert.ErrorIs(t, err, ErrChannelNotConfigured)
+	_, err = ParseRoute("gotify:other", configured)
+	assert.ErrorContains(t, err, "does not take a destination")
+	_, err = ParseRoute("slack:homelab", configured)
+	assert.ErrorContains(t, err, "invalid slack destination")
+}
+
+func TestManager_RoutesByLabel(t *testing.T) {
+	ctx := context.Background()
+	team := &fakeChannel{name: "team"}
+	ops := &fakeChannel{name: "ops"}
+	m := NewManager(nil, []Channel{team, ops}, Config{Mode: ModeImmediate})
+
+	web := container("web", "1.0", "1.1")
+	web.Labels = map[string]string{scripts.NotifyLabel: "team"}
+	db := container("db", "15.1", "15.2")
+	quiet := container("quiet", "2.0", "2.1")
+	quiet.Labels = map[string]string{scripts.NotifyLabel: "none"}
+	broken := container("broken", "3.0", "3.1")
+	broken.Labels = map[string]string{scripts.NotifyLabel: "email:me@example.com"}
+
+	m.Sync(ctx, result(web, db, quiet, broken))
+
+	// web only goes to its team, the others to every channel, quiet nowhere
+	require.Len(t, team.messages, 2)
+	require.Len(t, ops.messages, 1)
+	assert.Contains(t, team.messages[0].Text, "web")
+	assert.NotContains(t, ops.messages[0].Text, "web")
+	assert.NotContains(t, ops.messages[0].Text, "quiet")
+	assert.Contains(t, ops.messages[0].Text, "broken")
+
+	m.NotifyFailure(ctx, "web", "update", "pull failed")
+	require.Len(t, team.messages, 3)
+	assert.Len(t, ops.messages, 1)
+	assert.Equal(t, KindFailure, team.messages[2].Kind)
+}
+
+func TestManager_NotifiesFailedUpdates(t *testing.T) {
+	bus := events.NewBus()
+	ch := &syncChannel{sent: make(chan Message, 4)}
+	m := NewManager(nil, []Channel{ch}, Config{})
+	m.SetEventBus(bus)
+	m.Start()
+	defer m.Stop()
+
+	bus.Publish(events.Event{Type: events.EventContainerUpdated, Payload: map[string]any{
+		"container_name": "web", "operation_type": "restart", "status": "failed", "error": "ignored",
+	}})
+	bus.Publish(events.Event{Type: events.EventContainerUpdated, Payload: map[string]any{
+		"container_name": "web", "operation_type": "single", "status": "complete",
+	}})
+	bus.Publish(events.Event{Type: events.EventContainerUpdated, Payload: map[string]any{
+		"container_name": "web", "operation_type": "single", "status": "failed", "error": "pull failed",
+	}})
+
+	select {
+	case msg := <-ch.sent:
+		assert.Equal(t, KindFailure, msg.Kind)
+		assert.Equal(t, "Docksmith: update of web failed", msg.Title)
+		assert.Equal(t, "pull failed", msg.Text)
+	case <-time.After(2 * time.Second):
+		t.Fatal("failure not announced")
+	}
+	assert.Empty(t, ch.sent, "only failed updates are announced")
+}
+
+// syncChannel passes sent messages to a channel for tests with background delivery.
+type syncChannel struct {
+	sent chan Message
+}
+
+func (c *syncChannel) Name() string { return "sync" }
+
+func (c *syncChannel) Send(ctx context.Context, msg Message) error {
+	c.sent <- msg
+	return nil
+}
+
+func TestManager_Templates(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStore(t)
+	slack, webhook := &namedChannel{name: "slack"}, &namedChannel{name: "webhook"}
+	m := NewManager(store, []Channel{slack, webhook}, Config{})
+
+	require.NoError(t, m.SetTemplate(ctx, "slack", Template{
+		Title: "{{len .Findings}} new",
+		Text:  "{{range .Findings}}{{.ContainerName}} {{.LatestVersion}} {{.ChangelogURL}}{{end}}",
+	}))
+	assert.Error(t, m.SetTemplate(ctx, "slack", Template{Text: "{{.Findings"}))
+	assert.Error(t, m.SetTemplate(ctx, "email", Template{Text: "hi"}))
+
+	c := container("web", "1.0", "1.1")
+	c.Labels = map[string]string{"org.opencontainers.image.source": "https://github.com/org/web.git"}
+	m.Sync(ctx, result(c))
+
+	require.Len(t, slack.messages, 1)
+	assert.Equal(t, "1 new", slack.messages[0].Title)
+	assert.Equal(t, "web 1.1 https://github.com/org/web/releases", slack.messages[0].Text)
+	require.Len(t, webhook.messages, 1)
+	assert.Equal(t, "Docksmith: 1 update available", webhook.messages[0].Title, "other channels keep the default message")
+
+	// Templates are persisted, and an empty template restores the default
+	restarted := NewManager(store, nil, Config{})
+	assert.Contains(t, restarted.Templates(), "slack")
+	require.NoError(t, restarted.SetTemplate(ctx, "slack", Template{}))
+	assert.Empty(t, restarted.Templates())
+
+	// A template that fails to render falls back to the default message
+	_, err := m.Preview("", KindDigest, &Template{Title: "{{.Failure.Error}}"})
+	assert.Error(t, err)
+	require.NoError(t, m.SetTemplate(ctx, "webhook", Template{Title: "{{.Failure.Error}}"}))
+	assert.Equal(t, "boom", m.render("webhook", failureMessage("web", "update", "boom")).Title)
+	assert.Equal(t, "Docksmith: 1 update available", m.render("webhook", buildMessage([]Finding{{ContainerName: "web"}}, "")).Title)
+
+	msg, err := m.Preview("slack", KindFailure, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "plex", msg.Failure.ContainerName)
+	_, err = m.Preview("slack", "weekly", nil)
+	assert.ErrorIs(t, err, ErrInvalidKind)
+}
+
+func TestChangelogURL(t *testing.T) {
+	assert.Equal(t, "https://github.com/org/app/releases", changelogURL(map[string]string{"org.opencontainers.image.source": "https://github.com/org/app"}))
+	assert.Equal(t, "https://gitlab.com/org/app", changelogURL(map[string]string{"org.opencontainers.image.url": "https://gitlab.com/org/app/"}))
+	assert.Equal(t, "https://github.com/org/app/tree/main", changelogURL(map[string]string{"org.opencontainers.image.source": "https://github.com/org/app/tree/main"}))
+	assert.Empty(t, changelogURL(map[string]string{"org.opencontainers.image.source": "git@github.com:org/app"}))
+	assert.Empty(t, changelogURL(nil))
+}
+
+// namedChannel records the messages sent to it under a channel name.
+type namedChannel struct {
+	fakeChannel
+	name string
+}
+
+func (c *namedChannel) Name() string { return c.name }
+
+func TestSchedule_Next(t *testing.T) {
+	loc := time.UTC
+	// Wednesday
+	now := time.Date(2024, 1, 17, 10, 0, 0, 0, loc)
+
+	daily, err := ParseSchedule("daily", "09:30", "")
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 1, 18, 9, 30, 0, 0, loc), daily.Next(now))
+	assert.Equal(t, time.Date(2024, 1, 17, 9, 30, 0, 0, loc), daily.Next(now.Add(-time.Hour)))
+
+	weekly, err := ParseSchedule("weekly", "08:00", "mon")
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 1, 22, 8, 0, 0, 0, loc), weekly.Next(now))
+	assert.Equal(t, "weekly on Monday at 08:00", weekly.String())
+
+	sameDay, err := ParseSchedule("weekly", "12:00", "wednesday")
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 1, 17, 12, 0, 0, 0, loc), sameDay.Next(now))
+	assert.Equal(t, time.Date(2024, 1, 24, 12, 0, 0, 0, loc), sameDay.Next(time.Date(2024, 1, 17, 12, 0, 0, 0, loc)))
+
+	_, err = ParseSchedule("hourly", "", "")
+	assert.Error(t, err)
+	_, err = ParseSchedule("daily", "25:00", "")
+	assert.Error(t, err)
+	_, err = ParseSchedule("weekly", "", "someday")
+	assert.Error(t, err)
+}
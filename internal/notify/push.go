@@ -0,0 +1,249 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// pushoverURL is the Pushover message API endpoint.
+const pushoverURL = "https://api.pushover.net/1/messages.json"
+
+// priorityScale maps priority names to the native priorities of a push service.
+type priorityScale struct {
+	names    map[string]int
+	min, max int
+}
+
+// Native priority scales. Each has low, normal and high, plus the service's own names.
+var priorityScales = map[string]priorityScale{
+	"gotify": {
+		names: map[string]int{"min": 0, "low": 2, "normal": 5, "default": 5, "high": 8, "max": 10},
+		min:   0, max: 10,
+	},
+	"ntfy": {
+		names: map[string]int{"min": 1, "low": 2, "normal": 3, "default": 3, "high": 4, "urgent": 5, "max": 5},
+		min:   1, max: 5,
+	},
+	"pushover": {
+		names: map[string]int{"lowest": -2, "low": -1, "normal": 0, "default": 0, "high": 1, "emergency": 2},
+		min:   -2, max: 2,
+	},
+}
+
+// Priorities maps message kinds to a push service's native priorities.
+type Priorities map[string]int
+
+// ParsePriorities reads a priority mapping for service ("gotify", "ntfy" or
+// "pushover") such as "failure=urgent,digest=low". Values are priority names
+// (low, normal, high, or the service's own) or native numbers. Kinds that are
+// not listed keep the defaults: failures high, updates normal, digests low.
+func ParsePriorities(service, spec string) (Priorities, error) {
+	scale, ok := priorityScales[service]
+	if !ok {
+		return nil, fmt.Errorf("unknown notification service %q", service)
+	}
+
+	p := Priorities{
+		KindUpdate:  scale.names["normal"],
+		KindDigest:  scale.names["low"],
+		KindFailure: scale.names["high"],
+	}
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		kind, value, found := strings.Cut(entry, "=")
+		kind, value = strings.TrimSpace(kind), strings.ToLower(strings.TrimSpace(value))
+		if !found || value == "" {
+			return nil, fmt.Errorf("invalid %s priority %q (expected kind=priority)", service, entry)
+		}
+		if _, known := p[kind]; !known {
+			return nil, fmt.Errorf("invalid %s priority %q: kind must be update, digest or failure", service, entry)
+		}
+
+		n, named := scale.names[value]
+		if !named {
+			var err error
+			n, err = strconv.Atoi(value)
+			if err != nil || n < scale.min || n > scale.max {
+				return nil, fmt.Errorf("invalid %s priority %q: must be %s or %d to %d", service, value, scaleNames(scale), scale.min, scale.max)
+			}
+		}
+		p[kind] = n
+	}
+	return p, nil
+}
+
+// scaleNames lists the priority names of a scale for error messages.
+func scaleNames(scale priorityScale) string {
+	names := make([]string, 0, len(scale.names))
+	for name := range scale.names {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if scale.names[names[i]] != scale.names[names[j]] {
+			return scale.names[names[i]] < scale.names[names[j]]
+		}
+		return names[i] < names[j]
+	})
+	return strings.Join(names, ", ")
+}
+
+// priorityFor returns the priority of msg, falling back to the update priority.
+func (p Priorities) priorityFor(msg Message) int {
+	if n, ok := p[msg.Kind]; ok {
+		return n
+	}
+	return p[KindUpdate]
+}
+
+// defaultPriorities returns the default mapping of a service.
+func defaultPriorities(service string) Priorities {
+	p, _ := ParsePriorities(service, "")
+	return p
+}
+
+// GotifyChannel pushes messages to a Gotify server.
+type GotifyChannel struct {
+	url        string
+	token      string
+	priorities Priorities
+	client     *http.Client
+}
+
+// NewGotifyChannel creates a channel pushing to the Gotify server at serverURL
+// with an application token. nil priorities use the defaults.
+func NewGotifyChannel(serverURL, token string, priorities Priorities) *GotifyChannel {
+	if priorities == nil {
+		priorities = defaultPriorities("gotify")
+	}
+	return &GotifyChannel{
+		url:        strings.TrimSuffix(serverURL, "/") + "/message",
+		token:      token,
+		priorities: priorities,
+		client:     &http.Client{Timeout: sendTimeout},
+	}
+}
+
+// Name returns "gotify".
+func (c *GotifyChannel) Name() string {
+	return "gotify"
+}
+
+// Send pushes msg with the priority of its kind.
+func (c *GotifyChannel) Send(ctx context.Context, msg Message) error {
+	body := map[string]any{
+		"title":    msg.Title,
+		"message":  msg.Text,
+		"priority": c.priorities.priorityFor(msg),
+	}
+	req, err := newJSONRequest(ctx, c.url, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("X-Gotify-Key", c.token)
+	return do(c.client, req)
+}
+
+// NtfyChannel publishes messages to an ntfy topic.
+type NtfyChannel struct {
+	url        string
+	token      string
+	priorities Priorities
+	client     *http.Client
+}
+
+// NewNtfyChannel creates a channel publishing to the ntfy topic URL, e.g.
+// https://ntfy.sh/docksmith. token is an optional access token. nil priorities use the defaults.
+func NewNtfyChannel(topicURL, token string, priorities Priorities) *NtfyChannel {
+	if priorities == nil {
+		priorities = defaultPriorities("ntfy")
+	}
+	return &NtfyChannel{
+		url:        topicURL,
+		token:      token,
+		priorities: priorities,
+		client:     &http.Client{Timeout: sendTimeout},
+	}
+}
+
+// Name returns "ntfy".
+func (c *NtfyChannel) Name() string {
+	return "ntfy"
+}
+
+// Send publishes msg with the priority of its kind, tagged with the kind.
+func (c *NtfyChannel) Send(ctx context.Context, msg Message) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, strings.NewReader(msg.Text))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Title", msg.Title)
+	req.Header.Set("Priority", strconv.Itoa(c.priorities.priorityFor(msg)))
+	if msg.Kind != "" {
+		req.Header.Set("Tags", msg.Kind)
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	return do(c.client, req)
+}
+
+// PushoverChannel sends messages through Pushover.
+type PushoverChannel struct {
+	url        string
+	token      string
+	user       string
+	priorities Priorities
+	client     *http.Client
+}
+
+// NewPushoverChannel creates a channel sending with an application token to a
+// user or group key. nil priorities use the defaults.
+func NewPushoverChannel(token, user string, priorities Priorities) *PushoverChannel {
+	if priorities == nil {
+		priorities = defaultPriorities("pushover")
+	}
+	return &PushoverChannel{
+		url:        pushoverURL,
+		token:      token,
+		user:       user,
+		priorities: priorities,
+		client:     &http.Client{Timeout: sendTimeout},
+	}
+}
+
+// Name returns "pushover".
+func (c *PushoverChannel) Name() string {
+	return "pushover"
+}
+
+// Send sends msg with the priority of its kind. Emergency messages are repeated
+// every minute for an hour until acknowledged.
+func (c *PushoverChannel) Send(ctx context.Context, msg Message) error {
+	priority := c.priorities.priorityFor(msg)
+	form := url.Values{
+		"token":    {c.token},
+		"user":     {c.user},
+		"title":    {msg.Title},
+		"message":  {msg.Text},
+		"priority": {strconv.Itoa(priority)},
+	}
+	if priority == 2 {
+		form.Set("retry", "60")
+		form.Set("expire", "3600")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, strings.NewReader(form.Encode()))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return do(c.client, req)
+}
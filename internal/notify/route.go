@@ -0,0 +1,185 @@
+package notify
+
+import (
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+
+	"github.com/chis/docksmith/internal/scripts"
+)
+
+// RouteNone is the docksmith.notify label value that turns off a container's notifications.
+const RouteNone = "none"
+
+// Router is implemented by channels that can deliver to another destination of
+// the same service, as selected by a container's docksmith.notify label.
+type Router interface {
+	// Route returns a channel like this one delivering to target.
+	Route(target string) (Channel, error)
+}
+
+// route is the resolved docksmith.notify label of a container.
+type route struct {
+	channels []Channel
+	err      error
+}
+
+// ParseRoute resolves a docksmith.notify label value, e.g. "slack:#homelab,ntfy",
+// against the configured channels. Each entry names a configured channel,
+// optionally followed by a destination for its Router. "none" resolves to no
+// channels and an empty value to every configured channel.
+func ParseRoute(spec string, configured []Channel) ([]Channel, error) {
+	spec = strings.TrimSpace(spec)
+	switch strings.ToLower(spec) {
+	case "":
+		return configured, nil
+	case RouteNone:
+		return []Channel{}, nil
+	}
+
+	var channels []Channel
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		name, target, _ := strings.Cut(entry, ":")
+		name, target = strings.ToLower(strings.TrimSpace(name)), strings.TrimSpace(target)
+
+		var ch Channel
+		for _, c := range configured {
+			if c.Name() == name {
+				ch = c
+				break
+			}
+		}
+		if ch == nil {
+			return nil, fmt.Errorf("%w: %s", ErrChannelNotConfigured, name)
+		}
+		if target != "" {
+			router, ok := ch.(Router)
+			if !ok {
+				return nil, fmt.Errorf("%s does not take a destination (%q)", name, entry)
+			}
+			var err error
+			if ch, err = router.Route(target); err != nil {
+				return nil, fmt.Errorf("invalid %s destination %q: %w", name, target, err)
+			}
+		}
+		channels = append(channels, ch)
+	}
+	if len(channels) == 0 {
+		return nil, fmt.Errorf("no channels in %q", spec)
+	}
+	return channels, nil
+}
+
+// channelsFor returns the channels of a container: those of its docksmith.notify
+// label, or every configured channel. Invalid labels are logged and fall back
+// to every configured channel. Caller must not hold m.mu.
+func (m *Manager) channelsFor(containerName string) []Channel {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.channelsForLocked(m.state.Routes[containerName])
+}
+
+// channelsForLocked returns the channels of a docksmith.notify label value.
+// Caller must hold m.mu.
+func (m *Manager) channelsForLocked(spec string) []Channel {
+	if r := m.resolveLocked(spec); r.err == nil {
+		return r.channels
+	}
+	return m.channels
+}
+
+// resolveLocked parses a docksmith.notify label value, caching the result.
+// Invalid values are logged once and resolve to every configured channel.
+// Caller must hold m.mu.
+func (m *Manager) resolveLocked(spec string) route {
+	if spec == "" {
+		return route{channels: m.channels}
+	}
+	r, ok := m.routes[spec]
+	if !ok {
+		channels, err := ParseRoute(spec, m.channels)
+		r = route{channels: channels, err: err}
+		if m.routes == nil {
+			m.routes = make(map[string]route)
+		}
+		m.routes[spec] = r
+		if err != nil {
+			log.Printf("NOTIFY: Invalid %s label %q, using every channel: %v", scripts.NotifyLabel, spec, err)
+		}
+	}
+	return r
+}
+
+// Route returns a webhook channel posting to target, an http(s) URL.
+func (c *WebhookChannel) Route(target string) (Channel, error) {
+	if err := validateURL(target); err != nil {
+		return nil, err
+	}
+	return &WebhookChannel{url: target, client: c.client}, nil
+}
+
+// Route returns a Slack channel posting to target: another incoming webhook URL,
+// or a #channel or @user, where the webhook allows overriding its channel.
+func (c *SlackChannel) Route(target string) (Channel, error) {
+	if strings.HasPrefix(target, "#") || strings.HasPrefix(target, "@") {
+		return &SlackChannel{url: c.url, channel: target, client: c.client}, nil
+	}
+	if err := validateURL(target); err != nil {
+		return nil, fmt.Errorf("must be a webhook URL, #channel or @user")
+	}
+	return &SlackChannel{url: target, client: c.client}, nil
+}
+
+// Route returns an ntfy channel publishing to target: a topic on the configured
+// server, or a topic URL. The access token is only sent to the configured server.
+func (c *NtfyChannel) Route(target string) (Channel, error) {
+	routed := *c
+	if !strings.Contains(target, "://") {
+		if strings.Contains(target, "/") {
+			return nil, fmt.Errorf("must be a topic or topic URL")
+		}
+		base := c.url[:strings.LastIndex(c.url, "/")+1]
+		routed.url = base + target
+		return &routed, nil
+	}
+
+	if err := validateURL(target); err != nil {
+		return nil, err
+	}
+	routed.url = target
+	if !sameHost(c.url, target) {
+		routed.token = ""
+	}
+	return &routed, nil
+}
+
+// Route returns a Pushover channel sending to target, a user or group key.
+func (c *PushoverChannel) Route(target string) (Channel, error) {
+	if strings.ContainsAny(target, " /:") {
+		return nil, fmt.Errorf("must be a user or group key")
+	}
+	routed := *c
+	routed.user = target
+	return &routed, nil
+}
+
+// validateURL checks that value is an http(s) URL.
+func validateURL(value string) error {
+	u, err := url.Parse(value)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("must be an http or https URL")
+	}
+	return nil
+}
+
+// sameHost reports whether two URLs point to the same host.
+func sameHost(a, b string) bool {
+	ua, errA := url.Parse(a)
+	ub, errB := url.Parse(b)
+	return errA == nil && errB == nil && strings.EqualFold(ua.Host, ub.Host)
+}
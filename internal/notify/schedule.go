@@ -0,0 +1,87 @@
+package notify
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Digest periods
+const (
+	PeriodDaily  = "daily"
+	PeriodWeekly = "weekly"
+)
+
+// Schedule is when digests are sent: every day, or once a week, at a local time of day.
+type Schedule struct {
+	Period  string
+	Hour    int
+	Minute  int
+	Weekday time.Weekday // used by weekly schedules
+}
+
+// ParseSchedule parses a period ("daily" or "weekly"), a time of day ("09:00")
+// and, for weekly schedules, a weekday ("monday" or "mon"). Empty values
+// default to daily at 09:00 and Monday.
+func ParseSchedule(period, at, weekday string) (Schedule, error) {
+	s := Schedule{Period: PeriodDaily, Hour: 9, Weekday: time.Monday}
+
+	switch p := strings.ToLower(strings.TrimSpace(period)); p {
+	case "", PeriodDaily:
+	case PeriodWeekly:
+		s.Period = p
+	default:
+		return Schedule{}, fmt.Errorf("invalid schedule period %q (must be daily or weekly)", period)
+	}
+
+	if at = strings.TrimSpace(at); at != "" {
+		t, err := time.Parse("15:04", at)
+		if err != nil {
+			return Schedule{}, fmt.Errorf("invalid schedule time %q (expected HH:MM)", at)
+		}
+		s.Hour, s.Minute = t.Hour(), t.Minute()
+	}
+
+	if weekday = strings.ToLower(strings.TrimSpace(weekday)); weekday != "" {
+		found := false
+		for d := time.Sunday; d <= time.Saturday; d++ {
+			name := strings.ToLower(d.String())
+			if weekday == name || weekday == name[:3] {
+				s.Weekday = d
+				found = true
+				break
+			}
+		}
+		if !found {
+			return Schedule{}, fmt.Errorf("invalid schedule weekday %q", weekday)
+		}
+	}
+
+	return s, nil
+}
+
+// Next returns the first digest time strictly after t, in t's location.
+func (s Schedule) Next(t time.Time) time.Time {
+	next := time.Date(t.Year(), t.Month(), t.Day(), s.Hour, s.Minute, 0, 0, t.Location())
+
+	if s.Period == PeriodWeekly {
+		next = next.AddDate(0, 0, (int(s.Weekday)-int(next.Weekday())+7)%7)
+		if !next.After(t) {
+			next = next.AddDate(0, 0, 7)
+		}
+		return next
+	}
+
+	if !next.After(t) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next
+}
+
+// String describes the schedule, e.g. "daily at 09:00" or "weekly on Monday at 09:00".
+func (s Schedule) String() string {
+	if s.Period == PeriodWeekly {
+		return fmt.Sprintf("weekly on %s at %02d:%02d", s.Weekday, s.Hour, s.Minute)
+	}
+	return fmt.Sprintf("daily at %02d:%02d", s.Hour, s.Minute)
+}
@@ -0,0 +1,255 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// TemplatesConfigKey is the config table key holding the message templates by channel name.
+const TemplatesConfigKey = "notify_templates"
+
+// TemplateChannels lists the channels that templates can be set for.
+var TemplateChannels = []string{"webhook", "slack", "gotify", "ntfy", "pushover"}
+
+// Sentinel errors for previews and test sends
+var (
+	ErrInvalidKind          = errors.New("invalid message kind (must be update, digest or failure)")
+	ErrChannelNotConfigured = errors.New("notification channel is not configured")
+)
+
+// Template customizes the messages sent to a channel. Title and Text are Go
+// text/template strings executed with TemplateData. An empty field keeps the
+// default title or text.
+type Template struct {
+	Title string `json:"title,omitempty"`
+	Text  string `json:"text,omitempty"`
+}
+
+// TemplateData is the data a template is executed with.
+type TemplateData struct {
+	Kind     string    // update, digest, or failure
+	Title    string    // Default title
+	Text     string    // Default text
+	Period   string    // Digest period, for digests
+	Findings []Finding // Available updates, for update and digest messages
+	Failure  *Failure  // Failed operation, for failure messages
+}
+
+// templateFuncs are the functions available to templates besides the builtins.
+var templateFuncs = template.FuncMap{
+	"join":  strings.Join,
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+}
+
+// Validate reports whether the title and text templates parse.
+func (t Template) Validate() error {
+	if _, err := parseTemplate("title", t.Title); err != nil {
+		return err
+	}
+	_, err := parseTemplate("text", t.Text)
+	return err
+}
+
+// Render returns msg with the title and text replaced by the executed templates.
+func (t Template) Render(msg Message) (Message, error) {
+	data := TemplateData{
+		Kind:     msg.Kind,
+		Title:    msg.Title,
+		Text:     msg.Text,
+		Period:   msg.Period,
+		Findings: msg.Findings,
+		Failure:  msg.Failure,
+	}
+
+	out := msg
+	for _, field := range []struct {
+		name string
+		text string
+		dst  *string
+	}{{"title", t.Title, &out.Title}, {"text", t.Text, &out.Text}} {
+		tmpl, err := parseTemplate(field.name, field.text)
+		if err != nil {
+			return msg, err
+		}
+		if tmpl == nil {
+			continue
+		}
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, data); err != nil {
+			return msg, fmt.Errorf("failed to render %s template: %w", field.name, err)
+		}
+		*field.dst = strings.TrimSpace(buf.String())
+	}
+	return out, nil
+}
+
+// parseTemplate parses a template field. Returns nil for an empty field.
+func parseTemplate(name, text string) (*template.Template, error) {
+	if strings.TrimSpace(text) == "" {
+		return nil, nil
+	}
+	tmpl, err := template.New(name).Funcs(templateFuncs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s template: %w", name, err)
+	}
+	return tmpl, nil
+}
+
+// Templates returns the message templates by channel name.
+func (m *Manager) Templates() map[string]Template {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	templates := make(map[string]Template, len(m.templates))
+	for name, t := range m.templates {
+		templates[name] = t
+	}
+	return templates
+}
+
+// SetTemplate stores the template of a channel and uses it for the next messages.
+// An empty template restores the default messages.
+func (m *Manager) SetTemplate(ctx context.Context, channel string, t Template) error {
+	if !slices.Contains(TemplateChannels, channel) {
+		return fmt.Errorf("unknown channel %q (must be one of %s)", channel, strings.Join(TemplateChannels, ", "))
+	}
+	if err := t.Validate(); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	templates := make(map[string]Template, len(m.templates)+1)
+	for name, existing := range m.templates {
+		templates[name] = existing
+	}
+	if t == (Template{}) {
+		delete(templates, channel)
+	} else {
+		templates[channel] = t
+	}
+
+	if m.store != nil {
+		data, err := json.Marshal(templates)
+		if err != nil {
+			return fmt.Errorf("failed to serialize templates: %w", err)
+		}
+		if err := m.store.SetConfig(ctx, TemplatesConfigKey, string(data)); err != nil {
+			return fmt.Errorf("failed to save templates: %w", err)
+		}
+	}
+	m.templates = templates
+	return nil
+}
+
+// Preview renders a sample message of kind with t, or with the channel's stored
+// template when t is nil.
+func (m *Manager) Preview(channel, kind string, t *Template) (Message, error) {
+	msg, err := m.sampleMessage(kind)
+	if err != nil {
+		return Message{}, err
+	}
+	if t == nil {
+		m.mu.Lock()
+		stored := m.templates[channel]
+		m.mu.Unlock()
+		t = &stored
+	}
+	return t.Render(msg)
+}
+
+// SendTest sends a sample message of kind to the named channel, or to every
+// channel when channel is empty, and returns the names of the channels it was sent to.
+func (m *Manager) SendTest(ctx context.Context, channel, kind string) ([]string, error) {
+	msg, err := m.sampleMessage(kind)
+	if err != nil {
+		return nil, err
+	}
+
+	var sent []string
+	for _, ch := range m.channels {
+		if channel != "" && ch.Name() != channel {
+			continue
+		}
+		sendCtx, cancel := context.WithTimeout(ctx, sendTimeout)
+		err := ch.Send(sendCtx, m.render(ch.Name(), msg))
+		cancel()
+		if err != nil {
+			return sent, fmt.Errorf("failed to send to %s: %w", ch.Name(), err)
+		}
+		sent = append(sent, ch.Name())
+	}
+	if len(sent) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrChannelNotConfigured, channel)
+	}
+	return sent, nil
+}
+
+// Channels returns the names of the configured channels.
+func (m *Manager) Channels() []string {
+	names := make([]string, len(m.channels))
+	for i, ch := range m.channels {
+		names[i] = ch.Name()
+	}
+	return names
+}
+
+// render applies the channel's template to msg. Messages whose template fails
+// are sent with the default title and text.
+func (m *Manager) render(channel string, msg Message) Message {
+	m.mu.Lock()
+	t, ok := m.templates[channel]
+	m.mu.Unlock()
+	if !ok {
+		return msg
+	}
+	out, err := t.Render(msg)
+	if err != nil {
+		log.Printf("NOTIFY: Sending the default %s message: %v", channel, err)
+		return msg
+	}
+	return out
+}
+
+// sampleMessage returns an example message of kind for previews and test sends.
+func (m *Manager) sampleMessage(kind string) (Message, error) {
+	now := m.now().UTC().Truncate(time.Second)
+	plex := Finding{
+		ContainerName:  "plex",
+		Stack:          "media",
+		Image:          "linuxserver/plex:1.40.0",
+		CurrentVersion: "1.40.0",
+		LatestVersion:  "1.41.0",
+		ChangeType:     "minor",
+		ChangelogURL:   "https://github.com/linuxserver/docker-plex/releases",
+		DetectedAt:     now,
+	}
+	sonarr := Finding{
+		ContainerName:  "sonarr",
+		Stack:          "media",
+		Image:          "linuxserver/sonarr:4.0.1",
+		CurrentVersion: "4.0.1",
+		LatestVersion:  "4.0.2",
+		ChangeType:     "patch",
+		DetectedAt:     now,
+	}
+
+	switch kind {
+	case "", KindUpdate:
+		return buildMessage([]Finding{plex}, ""), nil
+	case KindDigest:
+		return buildMessage([]Finding{plex, sonarr}, m.schedule.Period), nil
+	case KindFailure:
+		return failureMessage("plex", "update", "failed to pull image: manifest unknown"), nil
+	default:
+		return Message{}, fmt.Errorf("%w: %q", ErrInvalidKind, kind)
+	}
+}
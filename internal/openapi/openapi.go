@@ -0,0 +1,124 @@
+// Package openapi describes HTTP APIs as OpenAPI 3.1 documents, with schemas
+// derived from the Go types the handlers encode.
+package openapi
+
+import "strings"
+
+// Version is the OpenAPI version of the documents
+const Version = "3.1.0"
+
+// Document is an OpenAPI document
+type Document struct {
+	OpenAPI    string                `json:"openapi"`
+	Info       Info                  `json:"info"`
+	Tags       []Tag                 `json:"tags,omitempty"`
+	Security   []SecurityRequirement `json:"security,omitempty"`
+	Paths      map[string]PathItem   `json:"paths"`
+	Components Components            `json:"components"`
+}
+
+// Info is the title and version of an API
+type Info struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+// Tag groups operations
+type Tag struct {
+	Name string `json:"name"`
+}
+
+// SecurityRequirement names the security schemes a request may authenticate with.
+// An empty requirement list makes an operation public.
+type SecurityRequirement map[string][]string
+
+// PathItem holds the operations of a path, keyed by lowercase HTTP method
+type PathItem map[string]*Operation
+
+// Operation is a single API operation
+type Operation struct {
+	OperationID string                 `json:"operationId"`
+	Summary     string                 `json:"summary,omitempty"`
+	Tags        []string               `json:"tags,omitempty"`
+	Parameters  []Parameter            `json:"parameters,omitempty"`
+	RequestBody *RequestBody           `json:"requestBody,omitempty"`
+	Responses   map[string]Response    `json:"responses"`
+	Security    *[]SecurityRequirement `json:"security,omitempty"`
+
+	// Role is the minimum role of the caller, for authenticated operations
+	Role string `json:"x-docksmith-role,omitempty"`
+	// NoClient leaves the operation, a stream or redirect, out of generated clients
+	NoClient bool `json:"x-docksmith-no-client,omitempty"`
+}
+
+// Parameter is a path or query parameter
+type Parameter struct {
+	Name     string  `json:"name"`
+	In       string  `json:"in"` // "path" or "query"
+	Required bool    `json:"required,omitempty"`
+	Schema   *Schema `json:"schema"`
+}
+
+// RequestBody is the body of a request, keyed by content type
+type RequestBody struct {
+	Required bool                 `json:"required,omitempty"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+// Response is a response, keyed by content type. Responses without content
+// reference a shared response instead.
+type Response struct {
+	Ref         string               `json:"$ref,omitempty"`
+	Description string               `json:"description,omitempty"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+// MediaType is the schema of a body of one content type
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Components holds the shared parts of a document
+type Components struct {
+	Schemas         map[string]*Schema        `json:"schemas"`
+	Responses       map[string]Response       `json:"responses,omitempty"`
+	SecuritySchemes map[string]SecurityScheme `json:"securitySchemes,omitempty"`
+}
+
+// SecurityScheme is a way of authenticating requests
+type SecurityScheme struct {
+	Type   string `json:"type"`             // "http" or "apiKey"
+	Scheme string `json:"scheme,omitempty"` // For "http", e.g. "bearer"
+	In     string `json:"in,omitempty"`     // For "apiKey", e.g. "header"
+	Name   string `json:"name,omitempty"`   // For "apiKey", the header name
+}
+
+// Schema is a JSON schema. The zero Schema allows any value.
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AllOf                []*Schema          `json:"allOf,omitempty"`
+}
+
+// schemaPrefix starts the references to component schemas
+const schemaPrefix = "#/components/schemas/"
+
+// SchemaRef returns the reference to a component schema
+func SchemaRef(name string) string {
+	return schemaPrefix + name
+}
+
+// RefName returns the component name of a schema reference, or "" if ref is
+// not one.
+func RefName(ref string) string {
+	if name, ok := strings.CutPrefix(ref, schemaPrefix); ok {
+		return name
+	}
+	return ""
+}
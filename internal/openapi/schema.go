@@ -0,0 +1,199 @@
+package openapi
+
+import (
+	"encoding/json"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+)
+
+var (
+	timeType      = reflect.TypeFor[time.Time]()
+	marshalerType = reflect.TypeFor[json.Marshaler]()
+)
+
+// Registry derives schemas from Go types as encoding/json encodes them. Named
+// struct types become component schemas, named after the type, or after its
+// package and the type when two packages use the same name.
+type Registry struct {
+	names   map[reflect.Type]string
+	schemas map[string]*Schema
+}
+
+// NewRegistry creates a registry for the given types and the types they
+// contain. Names overrides the component names of some types.
+func NewRegistry(names map[reflect.Type]string, types ...reflect.Type) *Registry {
+	seen := make(map[reflect.Type]bool)
+	for _, t := range types {
+		collect(t, seen)
+	}
+
+	byName := make(map[string][]reflect.Type)
+	for t := range seen {
+		if _, ok := names[t]; !ok {
+			byName[t.Name()] = append(byName[t.Name()], t)
+		}
+	}
+
+	r := &Registry{names: make(map[reflect.Type]string), schemas: make(map[string]*Schema)}
+	for t, name := range names {
+		r.names[t] = name
+	}
+	for name, types := range byName {
+		for _, t := range types {
+			if len(types) > 1 {
+				pkg := t.PkgPath()[strings.LastIndex(t.PkgPath(), "/")+1:]
+				r.names[t] = capitalize(pkg) + capitalize(name)
+			} else {
+				r.names[t] = capitalize(name)
+			}
+		}
+	}
+	return r
+}
+
+// collect records the named struct types t contains.
+func collect(t reflect.Type, seen map[reflect.Type]bool) {
+	switch t.Kind() {
+	case reflect.Pointer, reflect.Slice, reflect.Array, reflect.Map:
+		collect(t.Elem(), seen)
+	case reflect.Struct:
+		if opaque(t) || seen[t] {
+			return
+		}
+		if t.Name() != "" {
+			seen[t] = true
+		}
+		for _, f := range Fields(t) {
+			collect(f.Type, seen)
+		}
+	}
+}
+
+// Schema returns the schema of a type, adding the component schemas it refers to.
+func (r *Registry) Schema(t reflect.Type) *Schema {
+	switch {
+	case t.Kind() == reflect.Pointer:
+		return r.Schema(t.Elem())
+	case t == timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case opaque(t):
+		return &Schema{}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32:
+		return &Schema{Type: "integer"}
+	case reflect.Int64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return &Schema{Type: "integer", Format: "uint64"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: r.Schema(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: r.Schema(t.Elem())}
+	case reflect.Struct:
+		name, ok := r.names[t]
+		if !ok {
+			return r.object(t)
+		}
+		if _, built := r.schemas[name]; !built {
+			r.schemas[name] = &Schema{} // Placeholder for recursive types
+			r.schemas[name] = r.object(t)
+		}
+		return &Schema{Ref: SchemaRef(name)}
+	default:
+		return &Schema{}
+	}
+}
+
+// object returns the schema of a struct type.
+func (r *Registry) object(t reflect.Type) *Schema {
+	s := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	for _, f := range Fields(t) {
+		s.Properties[f.Name] = r.Schema(f.Type)
+		if !f.OmitEmpty && f.Type.Kind() != reflect.Pointer {
+			s.Required = append(s.Required, f.Name)
+		}
+	}
+	slices.Sort(s.Required)
+	return s
+}
+
+// Schemas returns the component schemas added so far, keyed by name.
+func (r *Registry) Schemas() map[string]*Schema {
+	return r.schemas
+}
+
+// Field is a JSON object field of a struct type
+type Field struct {
+	Name      string // JSON name
+	Type      reflect.Type
+	OmitEmpty bool
+}
+
+// Fields returns the JSON fields of a struct type, including those of embedded
+// structs, as encoding/json encodes them.
+func Fields(t reflect.Type) []Field {
+	var fields []Field
+	for i := range t.NumField() {
+		sf := t.Field(i)
+		tag := sf.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		omitEmpty := slices.ContainsFunc(strings.Split(opts, ","), func(opt string) bool {
+			return opt == "omitempty" || opt == "omitzero"
+		})
+
+		ft := sf.Type
+		if sf.Anonymous && name == "" {
+			embedded := ft
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				fields = append(fields, Fields(embedded)...)
+				continue
+			}
+		}
+		if !sf.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = sf.Name
+		}
+		fields = append(fields, Field{Name: name, Type: ft, OmitEmpty: omitEmpty})
+	}
+	return fields
+}
+
+// opaque reports whether a type encodes itself, so its schema is unknown.
+func opaque(t reflect.Type) bool {
+	if t == timeType {
+		return false
+	}
+	return t.Implements(marshalerType) || reflect.PointerTo(t).Implements(marshalerType)
+}
+
+// capitalize upper-cases the first letter of a type name.
+func capitalize(name string) string {
+	if name == "" {
+		return name
+	}
+	r := []rune(name)
+	r[0] = unicode.ToUpper(r[0])
+	return string(r)
+}
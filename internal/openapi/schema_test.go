@@ -0,0 +1,79 @@
+package openapi
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testBase struct {
+	ID int64 `json:"id"`
+}
+
+type testNode struct {
+	testBase
+	Name     string            `json:"name"`
+	Note     string            `json:"note,omitempty"`
+	Created  time.Time         `json:"created_at"`
+	Finished *time.Time        `json:"finished_at,omitempty"`
+	Parent   *testNode         `json:"parent,omitempty"`
+	Children []testNode        `json:"children"`
+	Labels   map[string]string `json:"labels"`
+	Raw      json.RawMessage   `json:"raw"`
+	Payload  any               `json:"payload"`
+	Secret   string            `json:"-"`
+	internal string
+}
+
+func TestRegistrySchema(t *testing.T) {
+	r := NewRegistry(nil, reflect.TypeFor[testNode]())
+	assert.Equal(t, &Schema{Ref: "#/components/schemas/TestNode"}, r.Schema(reflect.TypeFor[*testNode]()))
+
+	node := r.Schemas()["TestNode"]
+	require.NotNil(t, node)
+	assert.Equal(t, "object", node.Type)
+	assert.Equal(t, []string{"children", "created_at", "id", "labels", "name", "payload", "raw"}, node.Required)
+	assert.Len(t, node.Properties, 10)
+
+	assert.Equal(t, &Schema{Type: "integer", Format: "int64"}, node.Properties["id"])
+	assert.Equal(t, &Schema{Type: "string", Format: "date-time"}, node.Properties["created_at"])
+	assert.Equal(t, &Schema{Type: "string", Format: "date-time"}, node.Properties["finished_at"])
+	assert.Equal(t, &Schema{Ref: "#/components/schemas/TestNode"}, node.Properties["parent"])
+	assert.Equal(t, &Schema{Type: "array", Items: &Schema{Ref: "#/components/schemas/TestNode"}}, node.Properties["children"])
+	assert.Equal(t, &Schema{Type: "object", AdditionalProperties: &Schema{Type: "string"}}, node.Properties["labels"])
+	assert.Equal(t, &Schema{}, node.Properties["raw"])
+	assert.Equal(t, &Schema{}, node.Properties["payload"])
+	assert.NotContains(t, node.Properties, "Secret")
+	assert.NotContains(t, node.Properties, "internal")
+}
+
+type Detail struct {
+	Message string `json:"message"`
+}
+
+func TestRegistryNames(t *testing.T) {
+	// Anonymous structs are inlined, named structs are components
+	type response struct {
+		Result struct {
+			OK bool `json:"ok"`
+		} `json:"result"`
+		Detail Detail `json:"detail"`
+	}
+	r := NewRegistry(map[reflect.Type]string{reflect.TypeFor[response](): "Response"}, reflect.TypeFor[response]())
+	assert.Equal(t, &Schema{Ref: "#/components/schemas/Response"}, r.Schema(reflect.TypeFor[response]()))
+
+	schema := r.Schemas()["Response"]
+	require.NotNil(t, schema)
+	assert.Equal(t, "object", schema.Properties["result"].Type)
+	assert.Equal(t, &Schema{Ref: "#/components/schemas/Detail"}, schema.Properties["detail"])
+	assert.Contains(t, r.Schemas(), "Detail")
+}
+
+func TestRefName(t *testing.T) {
+	assert.Equal(t, "Detail", RefName(SchemaRef("Detail")))
+	assert.Equal(t, "", RefName("#/components/responses/Error"))
+}
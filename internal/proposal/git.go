@@ -0,0 +1,190 @@
+package proposal
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// defaultGitHubAPI is the GitHub REST API base URL.
+const defaultGitHubAPI = "https://api.github.com"
+
+// gitPublisher commits proposals to a branch and pushes it, opening a GitHub
+// pull request when the remote is on github.com and a token is available.
+// Commits are made in a temporary worktree so the live checkout is never touched.
+type gitPublisher struct {
+	remote string
+	token  string
+	apiURL string
+	client *http.Client
+}
+
+// newGitPublisher creates a publisher pushing to remote.
+func newGitPublisher(remote, token string) *gitPublisher {
+	return &gitPublisher{
+		remote: remote,
+		token:  token,
+		apiURL: defaultGitHubAPI,
+		client: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// repoRoot returns the top level of the git repository containing dir.
+func (g *gitPublisher) repoRoot(ctx context.Context, dir string) (string, error) {
+	out, err := runGit(ctx, dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(out), nil
+}
+
+// Publish commits content at relPath on branch, pushes it, and opens a pull
+// request when possible. Returns the pull request URL, or "" if none was opened.
+func (g *gitPublisher) Publish(ctx context.Context, root, relPath string, content []byte, branch, title, body string) (string, error) {
+	base, err := runGit(ctx, root, "rev-parse", "--abbrev-ref", "HEAD")
+	if err != nil {
+		return "", err
+	}
+	base = strings.TrimSpace(base)
+
+	worktree, err := os.MkdirTemp("", "docksmith-proposal-")
+	if err != nil {
+		return "", fmt.Errorf("failed to create worktree directory: %w", err)
+	}
+	defer os.RemoveAll(worktree)
+
+	if _, err := runGit(ctx, root, "worktree", "add", "--force", "-B", branch, worktree, "HEAD"); err != nil {
+		return "", err
+	}
+	defer runGit(context.Background(), root, "worktree", "remove", "--force", worktree)
+
+	if err := os.WriteFile(filepath.Join(worktree, relPath), content, 0644); err != nil {
+		return "", fmt.Errorf("failed to write %s: %w", relPath, err)
+	}
+	if _, err := runGit(ctx, worktree, "add", relPath); err != nil {
+		return "", err
+	}
+	if _, err := runGit(ctx, worktree, "commit", "-m", title, "-m", body); err != nil {
+		return "", err
+	}
+	if _, err := runGit(ctx, worktree, "push", "--force", g.remote, branch); err != nil {
+		return "", err
+	}
+
+	if g.token == "" {
+		return "", nil
+	}
+	remoteURL, err := runGit(ctx, root, "remote", "get-url", g.remote)
+	if err != nil {
+		return "", err
+	}
+	owner, repo, ok := parseGitHubRemote(strings.TrimSpace(remoteURL))
+	if !ok {
+		return "", nil
+	}
+	return g.openPullRequest(ctx, owner, repo, branch, base, title, body)
+}
+
+// openPullRequest opens a pull request, or returns the existing open one for branch.
+func (g *gitPublisher) openPullRequest(ctx context.Context, owner, repo, branch, base, title, body string) (string, error) {
+	payload, _ := json.Marshal(map[string]string{
+		"title": title,
+		"head":  branch,
+		"base":  base,
+		"body":  body,
+	})
+
+	var pr struct {
+		HTMLURL string `json:"html_url"`
+	}
+	status, err := g.githubRequest(ctx, http.MethodPost, fmt.Sprintf("/repos/%s/%s/pulls", owner, repo), payload, &pr)
+	if err != nil {
+		return "", err
+	}
+	if status == http.StatusCreated {
+		return pr.HTMLURL, nil
+	}
+
+	// 422 means a pull request for this branch already exists
+	if status != http.StatusUnprocessableEntity {
+		return "", fmt.Errorf("GitHub returned status %d creating pull request", status)
+	}
+	var existing []struct {
+		HTMLURL string `json:"html_url"`
+	}
+	path := fmt.Sprintf("/repos/%s/%s/pulls?state=open&head=%s:%s", owner, repo, owner, branch)
+	if _, err := g.githubRequest(ctx, http.MethodGet, path, nil, &existing); err != nil {
+		return "", err
+	}
+	if len(existing) == 0 {
+		return "", fmt.Errorf("GitHub rejected pull request for %s", branch)
+	}
+	return existing[0].HTMLURL, nil
+}
+
+// githubRequest sends an authenticated GitHub API request and decodes a 2xx JSON response into out.
+func (g *gitPublisher) githubRequest(ctx context.Context, method, path string, body []byte, out any) (int, error) {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, g.apiURL+path, reader)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Authorization", "Bearer "+g.token)
+	req.Header.Set("Accept", "application/vnd.github+json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("GitHub request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return resp.StatusCode, fmt.Errorf("failed to decode GitHub response: %w", err)
+		}
+	}
+	return resp.StatusCode, nil
+}
+
+// githubRemotePattern matches SSH and HTTPS github.com remote URLs.
+var githubRemotePattern = regexp.MustCompile(`github\.com[:/]([^/]+)/([^/]+?)(?:\.git)?/?$`)
+
+// parseGitHubRemote extracts owner and repository from a github.com remote URL.
+func parseGitHubRemote(url string) (owner, repo string, ok bool) {
+	m := githubRemotePattern.FindStringSubmatch(url)
+	if m == nil {
+		return "", "", false
+	}
+	return m[1], m[2], true
+}
+
+// runGit runs a git command in dir and returns its output.
+// Commits are attributed to Docksmith.
+func runGit(ctx context.Context, dir string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(),
+		"GIT_AUTHOR_NAME=Docksmith", "GIT_AUTHOR_EMAIL=docksmith@localhost",
+		"GIT_COMMITTER_NAME=Docksmith", "GIT_COMMITTER_EMAIL=docksmith@localhost",
+	)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("git %s failed: %w: %s", args[0], err, strings.TrimSpace(string(out)))
+	}
+	return string(out), nil
+}
@@ -0,0 +1,104 @@
+package proposal
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseGitHubRemote(t *testing.T) {
+	tests := map[string][2]string{
+		"git@github.com:chis/homelab.git":     {"chis", "homelab"},
+		"https://github.com/chis/homelab.git": {"chis", "homelab"},
+		"https://github.com/chis/homelab":     {"chis", "homelab"},
+	}
+	for url, want := range tests {
+		owner, repo, ok := parseGitHubRemote(url)
+		assert.True(t, ok, url)
+		assert.Equal(t, want[0], owner, url)
+		assert.Equal(t, want[1], repo, url)
+	}
+
+	_, _, ok := parseGitHubRemote("https://gitlab.com/chis/homelab.git")
+	assert.False(t, ok)
+}
+
+func TestGitPublisher_PushesBranch(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	ctx := context.Background()
+
+	remote := filepath.Join(t.TempDir(), "remote.git")
+	_, err := runGit(ctx, t.TempDir(), "init", "--bare", remote)
+	require.NoError(t, err)
+
+	repo := t.TempDir()
+	_, err = runGit(ctx, repo, "init")
+	require.NoError(t, err)
+	require.NoError(t, os.MkdirAll(filepath.Join(repo, "web"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(repo, "web", "compose.yml"), []byte("image: nginx:1.25\n"), 0644))
+	for _, args := range [][]string{{"add", "."}, {"commit", "-m", "init"}, {"remote", "add", "origin", remote}} {
+		_, err = runGit(ctx, repo, args...)
+		require.NoError(t, err)
+	}
+
+	g := newGitPublisher("origin", "")
+	root, err := g.repoRoot(ctx, filepath.Join(repo, "web"))
+	require.NoError(t, err)
+
+	url, err := g.Publish(ctx, root, "web/compose.yml", []byte("image: nginx:1.27\n"), "docksmith/web-1.27", "Update web to 1.27", "body")
+	require.NoError(t, err)
+	assert.Empty(t, url, "no pull request without a token")
+
+	out, err := runGit(ctx, remote, "show", "docksmith/web-1.27:web/compose.yml")
+	require.NoError(t, err)
+	assert.Equal(t, "image: nginx:1.27\n", out)
+
+	// The live checkout is untouched
+	data, err := os.ReadFile(filepath.Join(repo, "web", "compose.yml"))
+	require.NoError(t, err)
+	assert.Equal(t, "image: nginx:1.25\n", string(data))
+}
+
+func TestGitPublisher_OpenPullRequest(t *testing.T) {
+	var created map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		switch r.Method {
+		case http.MethodPost:
+			if created != nil {
+				w.WriteHeader(http.StatusUnprocessableEntity)
+				return
+			}
+			json.NewDecoder(r.Body).Decode(&created)
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(map[string]string{"html_url": "https://github.com/chis/homelab/pull/1"})
+		case http.MethodGet:
+			assert.Equal(t, "chis:docksmith/web-1.27", r.URL.Query().Get("head"))
+			json.NewEncoder(w).Encode([]map[string]string{{"html_url": "https://github.com/chis/homelab/pull/1"}})
+		}
+	}))
+	defer server.Close()
+
+	g := newGitPublisher("origin", "token")
+	g.apiURL = server.URL
+
+	url, err := g.openPullRequest(context.Background(), "chis", "homelab", "docksmith/web-1.27", "main", "Update web", "body")
+	require.NoError(t, err)
+	assert.Equal(t, "https://github.com/chis/homelab/pull/1", url)
+	assert.Equal(t, "main", created["base"])
+
+	// A second attempt finds the existing pull request
+	url, err = g.openPullRequest(context.Background(), "chis", "homelab", "docksmith/web-1.27", "main", "Update web", "body")
+	require.NoError(t, err)
+	assert.Equal(t, "https://github.com/chis/homelab/pull/1", url)
+}
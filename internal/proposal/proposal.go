@@ -0,0 +1,252 @@
+// Package proposal implements propose-only mode. Instead of updating running
+// containers, docksmith turns each available update into a compose file change
+// proposal: a patch file on disk and, optionally, a pushed Git branch with a
+// pull request, so deployments can stay driven by CI.
+package proposal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/chis/docksmith/internal/compose"
+	"github.com/chis/docksmith/internal/events"
+	"github.com/chis/docksmith/internal/storage"
+	"github.com/chis/docksmith/internal/update"
+	"github.com/google/uuid"
+)
+
+// EnabledConfigKey is the config key enabling propose-only mode.
+const EnabledConfigKey = "propose_only"
+
+// defaultDir is where patch files are written when PROPOSAL_DIR is unset.
+const defaultDir = "/data/proposals"
+
+// ErrNotFound is returned when a proposal does not exist.
+var ErrNotFound = errors.New("proposal not found")
+
+// Proposer computes compose file changes. Implemented by update.UpdateOrchestrator.
+type Proposer interface {
+	ProposeComposeChange(ctx context.Context, containerName, targetVersion string) (*update.ComposeChange, error)
+}
+
+// Manager turns available updates into compose change proposals.
+type Manager struct {
+	storage  storage.Storage
+	proposer Proposer
+	eventBus *events.Bus
+	dir      string
+	forced   bool
+	git      *gitPublisher
+}
+
+// NewManager creates a proposal manager. Propose-only mode is enabled by the
+// propose_only setting or forced with PROPOSE_ONLY=true. Patches are written to
+// PROPOSAL_DIR (default /data/proposals); PROPOSAL_GIT_PUSH=true also pushes a
+// branch to PROPOSAL_GIT_REMOTE (default origin) and opens a GitHub pull request
+// when PROPOSAL_GITHUB_TOKEN is set.
+func NewManager(store storage.Storage, proposer Proposer, eventBus *events.Bus) *Manager {
+	dir := os.Getenv("PROPOSAL_DIR")
+	if dir == "" {
+		dir = defaultDir
+	}
+
+	m := &Manager{
+		storage:  store,
+		proposer: proposer,
+		eventBus: eventBus,
+		dir:      dir,
+		forced:   isTrue(os.Getenv("PROPOSE_ONLY")),
+	}
+
+	if isTrue(os.Getenv("PROPOSAL_GIT_PUSH")) {
+		remote := os.Getenv("PROPOSAL_GIT_REMOTE")
+		if remote == "" {
+			remote = "origin"
+		}
+		m.git = newGitPublisher(remote, os.Getenv("PROPOSAL_GITHUB_TOKEN"))
+	}
+
+	return m
+}
+
+// Enabled reports whether docksmith must only propose changes.
+func (m *Manager) Enabled(ctx context.Context) bool {
+	if m.forced {
+		return true
+	}
+	if m.storage == nil {
+		return false
+	}
+	value, found, err := m.storage.GetConfig(ctx, EnabledConfigKey)
+	return err == nil && found && isTrue(value)
+}
+
+// Sync creates proposals for newly detected updates and closes proposals whose
+// update went away. Registered as a background checker result handler.
+func (m *Manager) Sync(ctx context.Context, result *update.DiscoveryResult) {
+	if result == nil || !m.Enabled(ctx) {
+		return
+	}
+
+	for _, c := range result.Containers {
+		if err := m.syncContainer(ctx, c); err != nil {
+			log.Printf("PROPOSAL: Failed to propose update for %s: %v", c.ContainerName, err)
+		}
+	}
+}
+
+// syncContainer creates, keeps, or closes the proposal for one container.
+func (m *Manager) syncContainer(ctx context.Context, c update.ContainerInfo) error {
+	latest, found, err := m.storage.GetLatestProposal(ctx, c.ContainerName)
+	if err != nil {
+		return err
+	}
+	open := found && latest.Status == storage.ProposalOpen
+
+	if c.Status != update.UpdateAvailable || c.LatestVersion == "" {
+		// Digest-only updates (no new tag) cannot be expressed as a compose change
+		if open {
+			latest.Status = storage.ProposalSuperseded
+			if c.CurrentVersion == latest.TargetVersion {
+				latest.Status = storage.ProposalApplied
+			}
+			return m.storage.SaveProposal(ctx, latest)
+		}
+		return nil
+	}
+
+	if open && latest.CurrentVersion == c.CurrentVersion && latest.TargetVersion == c.LatestVersion {
+		return nil // Already proposed
+	}
+
+	change, err := m.proposer.ProposeComposeChange(ctx, c.ContainerName, c.LatestVersion)
+	if err != nil {
+		return err
+	}
+
+	if open {
+		latest.Status = storage.ProposalSuperseded
+		if err := m.storage.SaveProposal(ctx, latest); err != nil {
+			return err
+		}
+	}
+
+	relPath := filepath.Base(change.Path)
+	var repoRoot string
+	if m.git != nil {
+		if root, err := m.git.repoRoot(ctx, filepath.Dir(change.Path)); err != nil {
+			log.Printf("PROPOSAL: %s is not in a git repository, skipping push: %v", change.Path, err)
+		} else if rel, err := filepath.Rel(root, change.Path); err == nil {
+			repoRoot, relPath = root, filepath.ToSlash(rel)
+		}
+	}
+
+	patch, err := compose.UnifiedDiff(relPath, change.Before, change.After)
+	if err != nil {
+		return fmt.Errorf("failed to build patch: %w", err)
+	}
+
+	proposal := storage.Proposal{
+		ID:             uuid.New().String(),
+		ContainerName:  c.ContainerName,
+		StackName:      c.Stack,
+		CurrentVersion: c.CurrentVersion,
+		TargetVersion:  c.LatestVersion,
+		ComposeFile:    change.Path,
+		Patch:          patch,
+		Status:         storage.ProposalOpen,
+		CreatedAt:      time.Now(),
+	}
+
+	if path, err := m.writePatch(proposal); err != nil {
+		log.Printf("PROPOSAL: Failed to write patch for %s: %v", c.ContainerName, err)
+	} else {
+		proposal.PatchFile = path
+	}
+
+	if repoRoot != "" {
+		proposal.Branch = "docksmith/" + sanitize(c.ContainerName+"-"+c.LatestVersion)
+		title := fmt.Sprintf("Update %s to %s", c.ContainerName, c.LatestVersion)
+		body := fmt.Sprintf("Docksmith detected an update for `%s`: `%s` -> `%s`.", c.ContainerName, change.OldImage, change.NewImage)
+		url, err := m.git.Publish(ctx, repoRoot, relPath, change.After, proposal.Branch, title, body)
+		if err != nil {
+			log.Printf("PROPOSAL: Failed to push %s: %v", proposal.Branch, err)
+			proposal.Branch = ""
+		} else {
+			proposal.PullRequestURL = url
+		}
+	}
+
+	if err := m.storage.SaveProposal(ctx, proposal); err != nil {
+		return err
+	}
+
+	log.Printf("PROPOSAL: Proposed %s -> %s for %s", change.OldImage, change.NewImage, c.ContainerName)
+	m.publish(proposal)
+	return nil
+}
+
+// List returns proposals newest first, optionally filtered by status.
+func (m *Manager) List(ctx context.Context, status string, limit int) ([]storage.Proposal, error) {
+	return m.storage.ListProposals(ctx, status, limit)
+}
+
+// Get returns a single proposal.
+func (m *Manager) Get(ctx context.Context, id string) (storage.Proposal, error) {
+	proposal, found, err := m.storage.GetProposal(ctx, id)
+	if err != nil {
+		return storage.Proposal{}, err
+	}
+	if !found {
+		return storage.Proposal{}, ErrNotFound
+	}
+	return proposal, nil
+}
+
+// writePatch saves the proposal's patch under the proposal directory.
+func (m *Manager) writePatch(proposal storage.Proposal) (string, error) {
+	if err := os.MkdirAll(m.dir, 0755); err != nil {
+		return "", err
+	}
+	path := filepath.Join(m.dir, sanitize(proposal.ContainerName+"-"+proposal.TargetVersion)+".patch")
+	if err := os.WriteFile(path, []byte(proposal.Patch), 0644); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// publish notifies event bus subscribers (the dashboard) about a new proposal.
+func (m *Manager) publish(proposal storage.Proposal) {
+	if m.eventBus == nil {
+		return
+	}
+	m.eventBus.Publish(events.Event{
+		Type: events.EventProposalCreated,
+		Payload: map[string]interface{}{
+			"proposal_id":      proposal.ID,
+			"container_name":   proposal.ContainerName,
+			"target_version":   proposal.TargetVersion,
+			"pull_request_url": proposal.PullRequestURL,
+		},
+	})
+}
+
+// unsafeNameChars matches characters not allowed in patch file and branch names.
+var unsafeNameChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// sanitize makes a container/version pair safe for file and branch names.
+func sanitize(name string) string {
+	return strings.Trim(unsafeNameChars.ReplaceAllString(name, "-"), "-.")
+}
+
+// isTrue parses the boolean label/config forms accepted elsewhere in docksmith.
+func isTrue(value string) bool {
+	return value == "true" || value == "1" || value == "yes"
+}
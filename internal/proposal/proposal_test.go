@@ -0,0 +1,115 @@
+package proposal
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/chis/docksmith/internal/storage"
+	"github.com/chis/docksmith/internal/update"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeProposer edits a fixed compose file in memory.
+type fakeProposer struct {
+	path  string
+	calls int
+}
+
+func (f *fakeProposer) ProposeComposeChange(ctx context.Context, containerName, targetVersion string) (*update.ComposeChange, error) {
+	f.calls++
+	before := "services:\n  web:\n    image: nginx:1.25\n"
+	return &update.ComposeChange{
+		ContainerName: containerName,
+		Service:       "web",
+		Path:          f.path,
+		OldImage:      "nginx:1.25",
+		NewImage:      "nginx:" + targetVersion,
+		Before:        []byte(before),
+		After:         []byte(strings.Replace(before, "1.25", targetVersion, 1)),
+	}, nil
+}
+
+func newTestManager(t *testing.T) (*Manager, *fakeProposer, storage.Storage) {
+	t.Helper()
+	t.Setenv("PROPOSAL_DIR", filepath.Join(t.TempDir(), "proposals"))
+	store, err := storage.NewSQLiteStorage(filepath.Join(t.TempDir(), "test.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { store.Close() })
+
+	proposer := &fakeProposer{path: "/stacks/web/docker-compose.yml"}
+	return NewManager(store, proposer, nil), proposer, store
+}
+
+func updateResult(current, target string) *update.DiscoveryResult {
+	var c update.ContainerInfo
+	c.ContainerName = "web"
+	c.CurrentVersion = current
+	c.LatestVersion = target
+	c.Status = update.UpdateAvailable
+	return &update.DiscoveryResult{Containers: []update.ContainerInfo{c}}
+}
+
+func TestManager_DisabledByDefault(t *testing.T) {
+	ctx := context.Background()
+	m, proposer, _ := newTestManager(t)
+
+	assert.False(t, m.Enabled(ctx))
+	m.Sync(ctx, updateResult("1.25", "1.27"))
+	assert.Zero(t, proposer.calls)
+}
+
+func TestManager_SyncCreatesProposal(t *testing.T) {
+	ctx := context.Background()
+	m, proposer, store := newTestManager(t)
+	require.NoError(t, store.SetConfig(ctx, EnabledConfigKey, "true"))
+
+	m.Sync(ctx, updateResult("1.25", "1.27"))
+	m.Sync(ctx, updateResult("1.25", "1.27"))
+	assert.Equal(t, 1, proposer.calls, "unchanged update must not be proposed twice")
+
+	open, err := m.List(ctx, storage.ProposalOpen, 0)
+	require.NoError(t, err)
+	require.Len(t, open, 1)
+	assert.Equal(t, "1.27", open[0].TargetVersion)
+	assert.Contains(t, open[0].Patch, "--- a/docker-compose.yml\n")
+	assert.Contains(t, open[0].Patch, "+    image: nginx:1.27\n")
+
+	data, err := os.ReadFile(open[0].PatchFile)
+	require.NoError(t, err)
+	assert.Equal(t, open[0].Patch, string(data))
+	assert.Equal(t, "web-1.27.patch", filepath.Base(open[0].PatchFile))
+}
+
+func TestManager_SyncSupersedesAndApplies(t *testing.T) {
+	t.Setenv("PROPOSE_ONLY", "true")
+	ctx := context.Background()
+	m, _, _ := newTestManager(t)
+
+	m.Sync(ctx, updateResult("1.25", "1.27"))
+	m.Sync(ctx, updateResult("1.25", "1.28"))
+
+	superseded, err := m.List(ctx, storage.ProposalSuperseded, 0)
+	require.NoError(t, err)
+	require.Len(t, superseded, 1)
+	assert.Equal(t, "1.27", superseded[0].TargetVersion)
+
+	// CI deployed the proposed version
+	deployed := updateResult("1.28", "")
+	deployed.Containers[0].Status = update.UpToDate
+	m.Sync(ctx, deployed)
+
+	applied, err := m.List(ctx, storage.ProposalApplied, 0)
+	require.NoError(t, err)
+	require.Len(t, applied, 1)
+	assert.Equal(t, "1.28", applied[0].TargetVersion)
+}
+
+func TestSanitize(t *testing.T) {
+	assert.Equal(t, "web-1.27", sanitize("web-1.27"))
+	assert.Equal(t, "web-v1.0-alpine", sanitize("web-v1.0+alpine"))
+	assert.Equal(t, "a-b", sanitize("../a/b"))
+}
@@ -0,0 +1,388 @@
+package registry
+
+import (
+	"bufio"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// awsCredentials are AWS access keys, temporary ones when SessionToken is set.
+type awsCredentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+}
+
+// awsCredentialChain resolves AWS credentials like the AWS SDKs, from the first
+// source configured: AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY, a web identity
+// token (IAM roles for service accounts on EKS), the shared credentials file, the
+// ECS task role, or the EC2 instance profile.
+type awsCredentialChain struct {
+	region         string
+	domain         string       // amazonaws.com, or amazonaws.com.cn in China
+	httpClient     *http.Client // For STS
+	metadataClient *http.Client // For the ECS and EC2 metadata endpoints
+}
+
+// newAWSCredentialChain creates a credential chain for a region and its domain.
+func newAWSCredentialChain(region, domain string, httpClient *http.Client) *awsCredentialChain {
+	return &awsCredentialChain{
+		region:         region,
+		domain:         domain,
+		httpClient:     httpClient,
+		metadataClient: newMetadataClient(),
+	}
+}
+
+// retrieve returns the credentials of the first source configured.
+func (a *awsCredentialChain) retrieve(ctx context.Context) (awsCredentials, error) {
+	if id := os.Getenv("AWS_ACCESS_KEY_ID"); id != "" {
+		return awsCredentials{
+			AccessKeyID:     id,
+			SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+			SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+		}, nil
+	}
+	if roleARN, tokenFile := os.Getenv("AWS_ROLE_ARN"), os.Getenv("AWS_WEB_IDENTITY_TOKEN_FILE"); roleARN != "" && tokenFile != "" {
+		return a.assumeRoleWithWebIdentity(ctx, roleARN, tokenFile)
+	}
+	if creds, ok, err := sharedAWSCredentials(); ok || err != nil {
+		return creds, err
+	}
+	if os.Getenv("AWS_CONTAINER_CREDENTIALS_RELATIVE_URI") != "" || os.Getenv("AWS_CONTAINER_CREDENTIALS_FULL_URI") != "" {
+		return a.containerCredentials(ctx)
+	}
+	if !strings.EqualFold(os.Getenv("AWS_EC2_METADATA_DISABLED"), "true") {
+		creds, err := a.instanceCredentials(ctx)
+		if err != nil {
+			return awsCredentials{}, fmt.Errorf("no AWS credentials found (environment, web identity, shared credentials file, ECS, or EC2 instance profile): %w", err)
+		}
+		return creds, nil
+	}
+	return awsCredentials{}, fmt.Errorf("no AWS credentials found (environment, web identity, shared credentials file, ECS, or EC2 instance profile)")
+}
+
+// assumeRoleWithWebIdentity exchanges a web identity token, such as the service
+// account token EKS projects for IAM roles for service accounts, for role credentials.
+func (a *awsCredentialChain) assumeRoleWithWebIdentity(ctx context.Context, roleARN, tokenFile string) (awsCredentials, error) {
+	token, err := os.ReadFile(tokenFile)
+	if err != nil {
+		return awsCredentials{}, fmt.Errorf("failed to read web identity token: %w", err)
+	}
+	sessionName := os.Getenv("AWS_ROLE_SESSION_NAME")
+	if sessionName == "" {
+		sessionName = fmt.Sprintf("docksmith-%d", time.Now().Unix())
+	}
+
+	endpoint := os.Getenv("AWS_ENDPOINT_URL_STS")
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://sts.%s.%s", a.region, a.domain)
+	}
+	form := url.Values{
+		"Action":           {"AssumeRoleWithWebIdentity"},
+		"Version":          {"2011-06-15"},
+		"RoleArn":          {roleARN},
+		"RoleSessionName":  {sessionName},
+		"WebIdentityToken": {strings.TrimSpace(string(token))},
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return awsCredentials{}, fmt.Errorf("failed to create STS request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := a.httpClient.Do(req)
+	if err != nil {
+		return awsCredentials{}, fmt.Errorf("failed to assume role %s: %w", roleARN, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return awsCredentials{}, handleHTTPError(resp, "STS AssumeRoleWithWebIdentity")
+	}
+
+	var result struct {
+		Credentials struct {
+			AccessKeyID     string `xml:"AccessKeyId"`
+			SecretAccessKey string `xml:"SecretAccessKey"`
+			SessionToken    string `xml:"SessionToken"`
+		} `xml:"AssumeRoleWithWebIdentityResult>Credentials"`
+	}
+	if err := xml.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return awsCredentials{}, fmt.Errorf("failed to decode STS response: %w", err)
+	}
+	return awsCredentials(result.Credentials), nil
+}
+
+// sharedAWSCredentials reads the access keys of the AWS_PROFILE profile (default
+// "default") from the shared credentials file, AWS_SHARED_CREDENTIALS_FILE or
+// ~/.aws/credentials. ok is false when the file or profile does not exist.
+func sharedAWSCredentials() (creds awsCredentials, ok bool, err error) {
+	path := os.Getenv("AWS_SHARED_CREDENTIALS_FILE")
+	if path == "" {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return awsCredentials{}, false, nil
+		}
+		path = filepath.Join(homeDir, ".aws", "credentials")
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return awsCredentials{}, false, nil
+	}
+	defer file.Close()
+
+	profile := os.Getenv("AWS_PROFILE")
+	if profile == "" {
+		profile = "default"
+	}
+	var section string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section = strings.TrimSpace(line[1 : len(line)-1])
+			continue
+		}
+		key, value, found := strings.Cut(line, "=")
+		if !found || section != profile {
+			continue
+		}
+		ok = true
+		switch strings.TrimSpace(key) {
+		case "aws_access_key_id":
+			creds.AccessKeyID = strings.TrimSpace(value)
+		case "aws_secret_access_key":
+			creds.SecretAccessKey = strings.TrimSpace(value)
+		case "aws_session_token":
+			creds.SessionToken = strings.TrimSpace(value)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return awsCredentials{}, false, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	if ok && (creds.AccessKeyID == "" || creds.SecretAccessKey == "") {
+		return awsCredentials{}, false, fmt.Errorf("profile %s in %s has no access keys", profile, path)
+	}
+	return creds, ok, nil
+}
+
+// awsMetadataCredentials are the credentials served by the ECS and EC2 metadata endpoints.
+type awsMetadataCredentials struct {
+	AccessKeyID     string `json:"AccessKeyId"`
+	SecretAccessKey string `json:"SecretAccessKey"`
+	Token           string `json:"Token"`
+}
+
+// containerCredentials fetches the ECS task role's credentials from the container
+// credentials endpoint.
+func (a *awsCredentialChain) containerCredentials(ctx context.Context) (awsCredentials, error) {
+	endpoint := os.Getenv("AWS_CONTAINER_CREDENTIALS_FULL_URI")
+	if relative := os.Getenv("AWS_CONTAINER_CREDENTIALS_RELATIVE_URI"); relative != "" {
+		endpoint = "http://169.254.170.2" + relative
+	}
+	req, err := http.NewRequestWithContext(ctx, "GET", endpoint, nil)
+	if err != nil {
+		return awsCredentials{}, fmt.Errorf("failed to create container credentials request: %w", err)
+	}
+	token := os.Getenv("AWS_CONTAINER_AUTHORIZATION_TOKEN")
+	if tokenFile := os.Getenv("AWS_CONTAINER_AUTHORIZATION_TOKEN_FILE"); tokenFile != "" {
+		data, err := os.ReadFile(tokenFile)
+		if err != nil {
+			return awsCredentials{}, fmt.Errorf("failed to read container authorization token: %w", err)
+		}
+		token = strings.TrimSpace(string(data))
+	}
+	if token != "" {
+		req.Header.Set("Authorization", token)
+	}
+	return a.getMetadataCredentials(req, "ECS container credentials")
+}
+
+// instanceCredentials fetches the EC2 instance profile's credentials from the
+// instance metadata service, with an IMDSv2 session token.
+func (a *awsCredentialChain) instanceCredentials(ctx context.Context) (awsCredentials, error) {
+	endpoint := strings.TrimSuffix(os.Getenv("AWS_EC2_METADATA_SERVICE_ENDPOINT"), "/")
+	if endpoint == "" {
+		endpoint = "http://169.254.169.254"
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "PUT", endpoint+"/latest/api/token", nil)
+	if err != nil {
+		return awsCredentials{}, fmt.Errorf("failed to create metadata token request: %w", err)
+	}
+	req.Header.Set("X-aws-ec2-metadata-token-ttl-seconds", "21600")
+	token, err := a.getMetadata(req, "EC2 metadata token")
+	if err != nil {
+		return awsCredentials{}, err
+	}
+
+	rolesURL := endpoint + "/latest/meta-data/iam/security-credentials/"
+	req, err = http.NewRequestWithContext(ctx, "GET", rolesURL, nil)
+	if err != nil {
+		return awsCredentials{}, fmt.Errorf("failed to create instance profile request: %w", err)
+	}
+	req.Header.Set("X-aws-ec2-metadata-token", token)
+	roles, err := a.getMetadata(req, "EC2 instance profile")
+	if err != nil {
+		return awsCredentials{}, err
+	}
+	role, _, _ := strings.Cut(strings.TrimSpace(roles), "\n")
+	if role == "" {
+		return awsCredentials{}, fmt.Errorf("EC2 instance has no instance profile")
+	}
+
+	req, err = http.NewRequestWithContext(ctx, "GET", rolesURL+role, nil)
+	if err != nil {
+		return awsCredentials{}, fmt.Errorf("failed to create instance credentials request: %w", err)
+	}
+	req.Header.Set("X-aws-ec2-metadata-token", token)
+	return a.getMetadataCredentials(req, "EC2 instance credentials")
+}
+
+// getMetadata returns the body of a metadata endpoint's response.
+func (a *awsCredentialChain) getMetadata(req *http.Request, operation string) (string, error) {
+	resp, err := a.metadataClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch %s: %w", operation, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", handleHTTPError(resp, operation)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", operation, err)
+	}
+	return string(body), nil
+}
+
+// getMetadataCredentials decodes the credentials a metadata endpoint serves.
+func (a *awsCredentialChain) getMetadataCredentials(req *http.Request, operation string) (awsCredentials, error) {
+	body, err := a.getMetadata(req, operation)
+	if err != nil {
+		return awsCredentials{}, err
+	}
+	var creds awsMetadataCredentials
+	if err := json.Unmarshal([]byte(body), &creds); err != nil {
+		return awsCredentials{}, fmt.Errorf("failed to decode %s: %w", operation, err)
+	}
+	if creds.AccessKeyID == "" || creds.SecretAccessKey == "" {
+		return awsCredentials{}, fmt.Errorf("%s has no access keys", operation)
+	}
+	return awsCredentials{
+		AccessKeyID:     creds.AccessKeyID,
+		SecretAccessKey: creds.SecretAccessKey,
+		SessionToken:    creds.Token,
+	}, nil
+}
+
+// signV4 signs req and its body with AWS Signature Version 4, for a service in a
+// region. All headers set on req are signed.
+func signV4(req *http.Request, body []byte, creds awsCredentials, region, service string, now time.Time) {
+	amzDate := now.UTC().Format("20060102T150405Z")
+	date := amzDate[:8]
+	req.Header.Set("X-Amz-Date", amzDate)
+	if creds.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", creds.SessionToken)
+	}
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		trimmed := make([]string, len(values))
+		for i, value := range values {
+			trimmed[i] = strings.Join(strings.Fields(value), " ")
+		}
+		headers[strings.ToLower(name)] = strings.Join(trimmed, ",")
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	path := req.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		path,
+		canonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		sha256Hex(body),
+	}, "\n")
+
+	scope := date + "/" + region + "/" + service + "/aws4_request"
+	stringToSign := strings.Join([]string{"AWS4-HMAC-SHA256", amzDate, scope, sha256Hex([]byte(canonicalRequest))}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+creds.SecretAccessKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		creds.AccessKeyID, scope, signedHeaders, signature))
+}
+
+// canonicalQuery encodes a query string for signing: sorted by key and value,
+// with spaces as %20.
+func canonicalQuery(query url.Values) string {
+	escaped := make(map[string][]string, len(query))
+	keys := make([]string, 0, len(query))
+	for key, values := range query {
+		key = awsEscape(key)
+		keys = append(keys, key)
+		for _, value := range values {
+			escaped[key] = append(escaped[key], awsEscape(value))
+		}
+		sort.Strings(escaped[key])
+	}
+	sort.Strings(keys)
+
+	var pairs []string
+	for _, key := range keys {
+		for _, value := range escaped[key] {
+			pairs = append(pairs, key+"="+value)
+		}
+	}
+	return strings.Join(pairs, "&")
+}
+
+// awsEscape percent-encodes s as AWS signing requires.
+func awsEscape(s string) string {
+	return strings.ReplaceAll(url.QueryEscape(s), "+", "%20")
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
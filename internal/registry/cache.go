@@ -91,6 +91,13 @@ func (c *RegistryCache) SetWithTTL(key string, value interface{}, ttl time.Durat
 	}
 }
 
+// Delete removes an item from cache
+func (c *RegistryCache) Delete(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, key)
+}
+
 // Clear removes all items from cache
 func (c *RegistryCache) Clear() {
 	c.mu.Lock()
@@ -0,0 +1,205 @@
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// ErrCatalogUnsupported is returned for registries that do not allow listing repositories.
+var ErrCatalogUnsupported = errors.New("registry does not support listing repositories")
+
+const (
+	catalogPageSize = 100
+	maxCatalogPages = 50
+)
+
+// linkNextPattern matches the next page URL of a paginated registry response: <url>; rel="next"
+var linkNextPattern = regexp.MustCompile(`<([^>]+)>\s*;\s*rel="?next"?`)
+
+// catalogResponse is the response of the registry catalog endpoint.
+type catalogResponse struct {
+	Repositories []string `json:"repositories"`
+}
+
+// ListRepositories returns the repositories of the registry from the /v2/_catalog
+// endpoint, following pagination. Registries that disable the catalog or deny
+// access to it return ErrCatalogUnsupported.
+func (c *HTTPClient) ListRepositories(ctx context.Context) ([]string, error) {
+	registry, _ := c.parseRepository("")
+	protocol := "https"
+	if c.config.Insecure {
+		protocol = "http"
+	}
+	base := fmt.Sprintf("%s://%s", protocol, registry)
+	next := fmt.Sprintf("%s/v2/_catalog?n=%d", base, catalogPageSize)
+
+	var repositories []string
+	token := ""
+	for page := 0; next != "" && page < maxCatalogPages; page++ {
+		resp, err := c.getCatalogPage(ctx, next, token)
+		if err != nil {
+			return nil, err
+		}
+
+		if resp.StatusCode == http.StatusUnauthorized && token == "" {
+			token, err = c.getAuthToken(ctx, resp, "")
+			resp.Body.Close()
+			if err != nil {
+				return nil, fmt.Errorf("failed to authenticate: %w", err)
+			}
+			if resp, err = c.getCatalogPage(ctx, next, token); err != nil {
+				return nil, err
+			}
+		}
+
+		switch resp.StatusCode {
+		case http.StatusOK:
+		case http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound:
+			resp.Body.Close()
+			return nil, fmt.Errorf("%w: %s returned %d", ErrCatalogUnsupported, registry, resp.StatusCode)
+		default:
+			err := handleHTTPError(resp, "list repositories")
+			resp.Body.Close()
+			return nil, err
+		}
+
+		var catalog catalogResponse
+		err = json.NewDecoder(resp.Body).Decode(&catalog)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode response: %w", err)
+		}
+		repositories = append(repositories, catalog.Repositories...)
+
+		next = nextPageURL(base, resp.Header.Get("Link"))
+	}
+
+	return repositories, nil
+}
+
+// getCatalogPage requests a page of the catalog, with a bearer token or the configured credentials.
+func (c *HTTPClient) getCatalogPage(ctx context.Context, pageURL, token string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", pageURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	} else if err := c.setBasicAuth(req); err != nil {
+		return nil, err
+	}
+
+	resp, err := c.doWithRetry(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list repositories: %w", err)
+	}
+	return resp, nil
+}
+
+// nextPageURL returns the absolute next page URL from a Link header, or "" on the last page.
+func nextPageURL(base, link string) string {
+	matches := linkNextPattern.FindStringSubmatch(link)
+	if len(matches) < 2 {
+		return ""
+	}
+	baseURL, err := url.Parse(base)
+	if err != nil {
+		return ""
+	}
+	next, err := baseURL.Parse(matches[1])
+	if err != nil {
+		return ""
+	}
+	return next.String()
+}
+
+// repositoryLister is a provider of registries with a catalog.
+type repositoryLister interface {
+	ListRepositories(ctx context.Context) ([]string, error)
+}
+
+// ListRepositories returns the repositories of a registry ("registry.example.com")
+// with caching support. Docker Hub and GHCR do not offer a catalog and return
+// ErrCatalogUnsupported. Contexts from WithCacheBypass skip cached data.
+func (m *Manager) ListRepositories(ctx context.Context, registry string) ([]string, error) {
+	if registry == "" || registry == "docker.io" || registry == "ghcr.io" {
+		return nil, fmt.Errorf("%w: %s", ErrCatalogUnsupported, registry)
+	}
+	client, ok := m.getOrCreateGenericClient(registry).(repositoryLister)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrCatalogUnsupported, registry)
+	}
+
+	cacheKey := fmt.Sprintf("catalog:%s", registry)
+	if cacheBypassed(ctx) {
+		m.cache.Delete(cacheKey)
+	}
+
+	var unsupported error
+	repositories, err := withCache(m, cacheKey, 5*time.Minute,
+		func(repositories []string) bool { return len(repositories) == 0 },
+		func() ([]string, error) {
+			return withCircuitBreaker(ctx, m, registry, func() ([]string, error) {
+				repositories, err := client.ListRepositories(ctx)
+				if errors.Is(err, ErrCatalogUnsupported) {
+					// A disabled catalog is not a registry failure
+					unsupported = err
+					return nil, nil
+				}
+				return repositories, err
+			})
+		},
+	)
+	if unsupported != nil {
+		return nil, unsupported
+	}
+	return repositories, err
+}
+
+// ManifestDetails describes the image at a tag or digest.
+type ManifestDetails struct {
+	Reference      string       `json:"reference"`
+	Digest         string       `json:"digest"`
+	PlatformDigest string       `json:"platform_digest,omitempty"`
+	Platforms      []string     `json:"platforms,omitempty"`
+	Layers         []ImageLayer `json:"layers"`
+	Size           int64        `json:"size"`
+}
+
+// GetManifestDetails returns the digest, platforms, and host platform layers of an
+// image tag or digest. Size is the compressed size of the layers.
+func (m *Manager) GetManifestDetails(ctx context.Context, imageRef, reference string) (*ManifestDetails, error) {
+	details := &ManifestDetails{Reference: reference, Digest: reference}
+	if !strings.HasPrefix(reference, "sha256:") {
+		digest, err := m.GetTagDigest(ctx, imageRef, reference)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get digest: %w", err)
+		}
+		details.Digest = digest
+	}
+
+	platforms, err := m.GetImagePlatforms(ctx, imageRef, details.Digest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get platforms: %w", err)
+	}
+	details.Platforms = platforms
+
+	if details.PlatformDigest, err = m.GetPlatformDigest(ctx, imageRef, details.Digest); err != nil {
+		return nil, fmt.Errorf("failed to get platform digest: %w", err)
+	}
+
+	if details.Layers, err = m.GetImageLayers(ctx, imageRef, details.Digest); err != nil {
+		return nil, fmt.Errorf("failed to get layers: %w", err)
+	}
+	for _, layer := range details.Layers {
+		details.Size += layer.Size
+	}
+	return details, nil
+}
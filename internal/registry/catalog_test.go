@@ -0,0 +1,85 @@
+package registry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHTTPClientListRepositories(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/token":
+			if r.URL.Query().Get("scope") != "registry:catalog:*" {
+				t.Errorf("unexpected token scope %q", r.URL.Query().Get("scope"))
+			}
+			w.Write([]byte(`{"token": "secret"}`))
+		case "/v2/_catalog":
+			if r.Header.Get("Authorization") != "Bearer secret" {
+				w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="%s/token",service="test",scope="registry:catalog:*"`, server.URL))
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			if r.URL.Query().Get("last") == "" {
+				w.Header().Set("Link", `</v2/_catalog?last=org%2Fapi&n=100>; rel="next"`)
+				w.Write([]byte(`{"repositories": ["org/api"]}`))
+				return
+			}
+			w.Write([]byte(`{"repositories": ["org/web"]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewHTTPClientForRegistry(&RegistryConfig{Insecure: true}, strings.TrimPrefix(server.URL, "http://"))
+	repositories, err := client.ListRepositories(context.Background())
+	if err != nil {
+		t.Fatalf("ListRepositories failed: %v", err)
+	}
+	if strings.Join(repositories, ",") != "org/api,org/web" {
+		t.Errorf("unexpected repositories: %v", repositories)
+	}
+}
+
+func TestHTTPClientListRepositories_Unsupported(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	client := NewHTTPClientForRegistry(&RegistryConfig{Insecure: true}, strings.TrimPrefix(server.URL, "http://"))
+	if _, err := client.ListRepositories(context.Background()); !errors.Is(err, ErrCatalogUnsupported) {
+		t.Errorf("expected ErrCatalogUnsupported, got %v", err)
+	}
+
+	manager := NewManager("")
+	defer manager.Close()
+	for _, registry := range []string{"docker.io", "ghcr.io"} {
+		if _, err := manager.ListRepositories(context.Background(), registry); !errors.Is(err, ErrCatalogUnsupported) {
+			t.Errorf("expected ErrCatalogUnsupported for %s, got %v", registry, err)
+		}
+	}
+}
+
+func TestNextPageURL(t *testing.T) {
+	tests := []struct {
+		link string
+		want string
+	}{
+		{"", ""},
+		{`</v2/_catalog?last=b&n=2>; rel="next"`, "https://reg.example.com/v2/_catalog?last=b&n=2"},
+		{`<https://other.example.com/v2/_catalog?last=b>; rel=next`, "https://other.example.com/v2/_catalog?last=b"},
+		{`</v2/_catalog?last=b>; rel="prev"`, ""},
+	}
+	for _, tt := range tests {
+		if got := nextPageURL("https://reg.example.com", tt.link); got != tt.want {
+			t.Errorf("nextPageURL(%q) = %q, want %q", tt.link, got, tt.want)
+		}
+	}
+}
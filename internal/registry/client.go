@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"regexp"
 	"strings"
 	"time"
@@ -21,6 +22,9 @@ type HTTPClient struct {
 	config     *RegistryConfig
 	httpClient *http.Client
 	registry   string // The registry this client is configured for (e.g., "lscr.io")
+	pageLimits TagPageLimits
+	pageSize   int // Tags requested per page (n), 0 for the registry's default
+	rebasePages bool // Resolve tag list Link headers against the registry (see nextTagsPage)
 }
 
 // NewHTTPClient creates a new registry client.
@@ -40,15 +44,97 @@ func NewHTTPClientForRegistry(config *RegistryConfig, registry string) *HTTPClie
 		config.TimeoutSeconds = DefaultTimeoutSeconds
 	}
 
+	httpClient := &http.Client{
+		Timeout: time.Duration(config.TimeoutSeconds) * time.Second,
+	}
+	if config.Proxy != nil {
+		httpClient.Transport = newProxyTransport(config.Proxy)
+	}
+
 	return &HTTPClient{
-		config: config,
-		httpClient: &http.Client{
-			Timeout: time.Duration(config.TimeoutSeconds) * time.Second,
-		},
-		registry: registry,
+		config:     config,
+		httpClient: httpClient,
+		registry:   registry,
 	}
 }
 
+// SetPageLimits caps the pages of tags fetched per repository.
+// Must be called before the client is used.
+func (c *HTTPClient) SetPageLimits(limits TagPageLimits) {
+	c.pageLimits = limits
+}
+
+// Name returns ProviderOCI.
+func (c *HTTPClient) Name() string {
+	return ProviderOCI
+}
+
+// Authenticate returns the Authorization header for pulling from repository: a
+// bearer token when the registry asks for one, the configured credentials when it
+// asks for basic auth, or "" when it serves the repository anonymously.
+func (c *HTTPClient) Authenticate(ctx context.Context, repository string) (string, error) {
+	registry, repo := c.parseRepository(repository)
+	resp, err := c.getTagsPage(ctx, c.buildTagsURL(registry, repo), "", CacheValidators{})
+	if err != nil {
+		return "", err
+	}
+	resp.Body.Close()
+
+	username, password, err := c.credentials(ctx)
+	if err != nil {
+		return "", err
+	}
+	hasCredentials := username != "" && password != ""
+	challenge := resp.Header.Get("WWW-Authenticate")
+	switch {
+	case resp.StatusCode != http.StatusUnauthorized && hasCredentials:
+		return basicAuth(username, password), nil
+	case resp.StatusCode != http.StatusUnauthorized:
+		return "", nil
+	case strings.HasPrefix(strings.ToLower(challenge), "basic"):
+		if !hasCredentials {
+			return "", fmt.Errorf("%s requires credentials", registry)
+		}
+		return basicAuth(username, password), nil
+	}
+
+	token, err := c.getAuthToken(ctx, resp, repo)
+	if err != nil {
+		return "", fmt.Errorf("failed to authenticate: %w", err)
+	}
+	return "Bearer " + token, nil
+}
+
+// credentials returns the username and password for registry requests: those of
+// the config's credential source when set, or its static credentials. Static
+// credentials also stand in when the source fails, e.g. a Docker config login to
+// ECR on a host without AWS credentials.
+func (c *HTTPClient) credentials(ctx context.Context) (username, password string, err error) {
+	if c.config.Credentials == nil {
+		return c.config.Username, c.config.Password, nil
+	}
+	username, password, err = c.config.Credentials.Credentials(ctx)
+	if err != nil && c.config.Username != "" {
+		return c.config.Username, c.config.Password, nil
+	}
+	if err != nil {
+		return "", "", fmt.Errorf("failed to get credentials for %s: %w", c.registry, err)
+	}
+	return username, password, nil
+}
+
+// setBasicAuth sets the client's credentials on req, if it has any.
+func (c *HTTPClient) setBasicAuth(req *http.Request) error {
+	username, password, err := c.credentials(req.Context())
+	if err != nil {
+		return err
+	}
+	if username != "" && password != "" {
+		req.SetBasicAuth(username, password)
+	}
+	return nil
+}
+
 // doWithRetry executes an HTTP request with exponential backoff retry on transient errors.
 // It retries network errors (connection refused, timeout) but not HTTP error responses.
 func (c *HTTPClient) doWithRetry(req *http.Request) (*http.Response, error) {
@@ -89,6 +175,15 @@ type tagsResponse struct {
 
 // ListTags returns all available tags for an image from a registry.
 func (c *HTTPClient) ListTags(ctx context.Context, repository string) ([]string, error) {
+	tags, _, err := c.ListTagsConditional(ctx, repository, CacheValidators{})
+	return tags, err
+}
+
+// ListTagsConditional lists tags unless the registry reports the list unchanged since
+// validators, in which case it returns ErrNotModified. Returns the new validators.
+// Paginated lists are followed through their Link headers, up to the repository's
+// page limit. V2 registries list tags in lexical order, so they never stop early.
+func (c *HTTPClient) ListTagsConditional(ctx context.Context, repository string, validators CacheValidators) ([]string, CacheValidators, error) {
 	// Parse repository to determine registry
 	registry, repo := c.parseRepository(repository)
 
@@ -96,58 +191,113 @@ func (c *HTTPClient) ListTags(ctx context.Context, repository string) ([]string,
 	url := c.buildTagsURL(registry, repo)
 
 	// Make initial request
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	resp, err := c.getTagsPage(ctx, url, "", validators)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
-
-	// Add authentication if configured
-	if c.config.Username != "" && c.config.Password != "" {
-		req.SetBasicAuth(c.config.Username, c.config.Password)
-	}
-
-	resp, err := c.doWithRetry(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to fetch tags: %w", err)
+		return nil, validators, err
 	}
 	defer resp.Body.Close()
 
 	// Handle 401 Unauthorized - try to get a token
+	token := ""
 	if resp.StatusCode == http.StatusUnauthorized {
-		token, err := c.getAuthToken(ctx, resp, repo)
+		token, err = c.getAuthToken(ctx, resp, repo)
 		if err != nil {
-			return nil, fmt.Errorf("failed to authenticate: %w", err)
+			return nil, validators, fmt.Errorf("failed to authenticate: %w", err)
 		}
 
 		// Retry with token
-		req, err = http.NewRequestWithContext(ctx, "GET", url, nil)
+		resp, err = c.getTagsPage(ctx, url, token, validators)
 		if err != nil {
-			return nil, fmt.Errorf("failed to create request: %w", err)
-		}
-		req.Header.Set("Authorization", "Bearer "+token)
-
-		resp, err = c.doWithRetry(req)
-		if err != nil {
-			return nil, fmt.Errorf("failed to fetch tags: %w", err)
+			return nil, validators, err
 		}
 		defer resp.Body.Close()
 	}
 
+	if resp.StatusCode == http.StatusNotModified {
+		return nil, validators, ErrNotModified
+	}
 	if resp.StatusCode != http.StatusOK {
-		return nil, handleHTTPError(resp, "fetch tags")
+		return nil, validators, handleHTTPError(resp, "fetch tags")
 	}
 
 	// Parse response
 	var tagsResp tagsResponse
 	if err := json.NewDecoder(resp.Body).Decode(&tagsResp); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
+		return nil, validators, fmt.Errorf("failed to decode response: %w", err)
+	}
+	tags := tagsResp.Tags
+	newValidators := responseValidators(resp)
+
+	maxPages := c.pageLimits.pages(registry, repo, defaultMaxTagPages)
+	next := c.nextTagsPage(url, resp.Header.Get("Link"))
+	for page := 1; next != "" && page < maxPages; page++ {
+		pageResp, err := c.getTagsPage(ctx, next, token, CacheValidators{})
+		if err != nil {
+			return nil, validators, err
+		}
+		if pageResp.StatusCode != http.StatusOK {
+			err := handleHTTPError(pageResp, "fetch tags")
+			pageResp.Body.Close()
+			return nil, validators, err
+		}
+		var pageTags tagsResponse
+		err = json.NewDecoder(pageResp.Body).Decode(&pageTags)
+		pageResp.Body.Close()
+		if err != nil {
+			return nil, validators, fmt.Errorf("failed to decode response: %w", err)
+		}
+		tags = append(tags, pageTags.Tags...)
+		next = c.nextTagsPage(url, pageResp.Header.Get("Link"))
+	}
+
+	return tags, newValidators, nil
+}
+
+// nextTagsPage returns the URL of a tag list's next page from a Link header, or
+// "" on the last page. With rebasePages set, only the link's path and query are
+// kept: Harbor builds links from its configured external URL, which behind a
+// reverse proxy is often an internal hostname.
+func (c *HTTPClient) nextTagsPage(pageURL, link string) string {
+	next := nextPageURL(pageURL, link)
+	if next == "" || !c.rebasePages {
+		return next
+	}
+	nextURL, err := url.Parse(next)
+	if err != nil {
+		return ""
+	}
+	baseURL, err := url.Parse(pageURL)
+	if err != nil {
+		return ""
+	}
+	nextURL.Scheme, nextURL.Host = baseURL.Scheme, baseURL.Host
+	return nextURL.String()
+}
+
+// getTagsPage requests a page of a tag list, with a bearer token or the configured
+// credentials.
+func (c *HTTPClient) getTagsPage(ctx context.Context, pageURL, token string, validators CacheValidators) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", pageURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	validators.apply(req.Header)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	} else if err := c.setBasicAuth(req); err != nil {
+		return nil, err
 	}
 
-	return tagsResp.Tags, nil
+	resp, err := c.doWithRetry(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch tags: %w", err)
+	}
+	return resp, nil
 }
 
-// getAuthToken obtains a bearer token from a registry's token service.
-// It parses the WWW-Authenticate header from a 401 response to find the token endpoint.
+// getAuthToken obtains a bearer token from a registry's token service, with the
+// configured credentials if any. It parses the WWW-Authenticate header from a 401
+// response to find the token endpoint.
 func (c *HTTPClient) getAuthToken(ctx context.Context, resp *http.Response, repository string) (string, error) {
 	authHeader := resp.Header.Get("WWW-Authenticate")
 	if authHeader == "" {
@@ -186,6 +336,9 @@ func (c *HTTPClient) getAuthToken(ctx context.Context, resp *http.Response, repo
 	if err != nil {
 		return "", fmt.Errorf("failed to create token request: %w", err)
 	}
+	if err := c.setBasicAuth(req); err != nil {
+		return "", err
+	}
 
 	tokenResp, err := c.doWithRetry(req)
 	if err != nil {
@@ -276,8 +429,8 @@ func (c *HTTPClient) GetTagDigest(ctx context.Context, repository, tag string) (
 		"application/vnd.docker.distribution.manifest.v2+json",
 	}, ", "))
 
-	if c.config.Username != "" && c.config.Password != "" {
-		req.SetBasicAuth(c.config.Username, c.config.Password)
+	if err := c.setBasicAuth(req); err != nil {
+		return "", err
 	}
 
 	resp, err := c.doWithRetry(req)
@@ -323,8 +476,124 @@ func (c *HTTPClient) GetTagDigest(ctx context.Context, repository, tag string) (
 	return digest, nil
 }
 
+// GetImageSize returns the compressed size in bytes of the image at reference (tag or digest).
+func (c *HTTPClient) GetImageSize(ctx context.Context, repository, reference string) (int64, error) {
+	fetch, err := c.newManifestFetcher(ctx, repository)
+	if err != nil {
+		return 0, err
+	}
+	return compressedImageSize(ctx, fetch, reference)
+}
+
+// GetImageLayers returns the compressed layers of the image at reference (tag or digest).
+func (c *HTTPClient) GetImageLayers(ctx context.Context, repository, reference string) ([]ImageLayer, error) {
+	fetch, err := c.newManifestFetcher(ctx, repository)
+	if err != nil {
+		return nil, err
+	}
+	return imageLayers(ctx, fetch, reference)
+}
+
+// GetImagePlatforms returns the platforms of a multi-arch image at reference (tag or digest).
+func (c *HTTPClient) GetImagePlatforms(ctx context.Context, repository, reference string) ([]string, error) {
+	fetch, err := c.newManifestFetcher(ctx, repository)
+	if err != nil {
+		return nil, err
+	}
+	return imagePlatforms(ctx, fetch, reference)
+}
+
+// GetPlatformDigest returns the digest of the manifest for the host platform at reference (tag or digest).
+func (c *HTTPClient) GetPlatformDigest(ctx context.Context, repository, reference string) (string, error) {
+	fetch, err := c.newManifestFetcher(ctx, repository)
+	if err != nil {
+		return "", err
+	}
+	return platformDigest(ctx, fetch, reference)
+}
+
+// GetSignatures returns the cosign signatures and attestations of the image at digest.
+func (c *HTTPClient) GetSignatures(ctx context.Context, repository, digest string) (*ImageSignatures, error) {
+	fetch, err := c.newRegistryFetcher(ctx, repository)
+	if err != nil {
+		return nil, err
+	}
+	return imageSignatures(ctx, fetch, digest)
+}
+
+// newManifestFetcher returns a manifestFetcher for repository.
+func (c *HTTPClient) newManifestFetcher(ctx context.Context, repository string) (manifestFetcher, error) {
+	fetch, err := c.newRegistryFetcher(ctx, repository)
+	if err != nil {
+		return nil, err
+	}
+	return fetch.manifests, nil
+}
+
+// newRegistryFetcher returns a registryFetcher for repository.
+func (c *HTTPClient) newRegistryFetcher(ctx context.Context, repository string) (registryFetcher, error) {
+	registry, repo := c.parseRepository(repository)
+
+	protocol := "https"
+	if c.config.Insecure {
+		protocol = "http"
+	}
+
+	var token string
+	fetch := func(ctx context.Context, endpoint, ref string) ([]byte, error) {
+		url := fmt.Sprintf("%s://%s/v2/%s/%s/%s", protocol, registry, repo, endpoint, ref)
+
+		resp, err := c.getManifest(ctx, url, token)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+
+		// Handle 401 — get bearer token and retry
+		if resp.StatusCode == http.StatusUnauthorized && token == "" {
+			token, err = c.getAuthToken(ctx, resp, repo)
+			if err != nil {
+				return nil, fmt.Errorf("failed to authenticate for manifest: %w", err)
+			}
+			resp, err = c.getManifest(ctx, url, token)
+			if err != nil {
+				return nil, err
+			}
+			defer resp.Body.Close()
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			return nil, handleHTTPError(resp, "manifest request")
+		}
+		return io.ReadAll(resp.Body)
+	}
+
+	return fetch, nil
+}
+
+// getManifest issues a manifest GET, using the bearer token when set and basic auth otherwise.
+func (c *HTTPClient) getManifest(ctx context.Context, url, token string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create manifest request: %w", err)
+	}
+	req.Header.Set("Accept", manifestAccept)
+
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	} else if err := c.setBasicAuth(req); err != nil {
+		return nil, err
+	}
+
+	resp, err := c.doWithRetry(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch manifest: %w", err)
+	}
+	return resp, nil
+}
+
 // ListTagsWithDigests is not implemented for generic HTTP client.
-// This method is only efficiently supported by Docker Hub and GHCR clients.
+// This method is only efficiently supported by Docker Hub, GHCR, and Quay clients.
 func (c *HTTPClient) ListTagsWithDigests(ctx context.Context, repository string) (map[string][]string, error) {
 	return nil, fmt.Errorf("ListTagsWithDigests not implemented for generic registry client")
 }
@@ -359,6 +628,10 @@ func (c *HTTPClient) buildTagsURL(registry, repository string) string {
 		return fmt.Sprintf("%s://%s/v2/%s/tags/list", protocol, registry, repository)
 	default:
 		// Standard Docker Registry V2 API
-		return fmt.Sprintf("%s://%s/v2/%s/tags/list", protocol, registry, repository)
+		url := fmt.Sprintf("%s://%s/v2/%s/tags/list", protocol, registry, repository)
+		if c.pageSize > 0 {
+			url += fmt.Sprintf("?n=%d", c.pageSize)
+		}
+		return url
 	}
 }
@@ -0,0 +1,64 @@
+package registry
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// CredentialSource supplies registry credentials that expire, such as those of
+// ECR and Artifact Registry, renewing them as needed.
+type CredentialSource interface {
+	Credentials(ctx context.Context) (username, password string, err error)
+}
+
+// credentialRefreshMargin is how long before they expire credentials are renewed,
+// so requests in flight never present expired ones.
+const credentialRefreshMargin = 5 * time.Minute
+
+// metadataTimeout bounds requests to cloud metadata endpoints, which only answer
+// on their own cloud: elsewhere they time out.
+const metadataTimeout = 2 * time.Second
+
+// expiringCredentials is a CredentialSource that caches the credentials fetch
+// returns until shortly before they expire.
+type expiringCredentials struct {
+	fetch func(ctx context.Context) (username, password string, expiresAt time.Time, err error)
+	now   func() time.Time
+
+	mu        sync.Mutex
+	username  string
+	password  string
+	expiresAt time.Time
+}
+
+// newExpiringCredentials creates a cached credential source.
+func newExpiringCredentials(fetch func(ctx context.Context) (username, password string, expiresAt time.Time, err error)) *expiringCredentials {
+	return &expiringCredentials{fetch: fetch, now: time.Now}
+}
+
+// Credentials returns the cached credentials, fetching new ones when they are
+// missing or about to expire. Concurrent callers wait for a single fetch.
+func (e *expiringCredentials) Credentials(ctx context.Context) (username, password string, err error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.password != "" && e.now().Before(e.expiresAt.Add(-credentialRefreshMargin)) {
+		return e.username, e.password, nil
+	}
+	username, password, expiresAt, err := e.fetch(ctx)
+	if err != nil {
+		return "", "", err
+	}
+	e.username, e.password, e.expiresAt = username, password, expiresAt
+	return username, password, nil
+}
+
+// newMetadataClient returns an HTTP client for link-local metadata endpoints,
+// which are never reached through a proxy.
+func newMetadataClient() *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	return &http.Client{Timeout: metadataTimeout, Transport: transport}
+}
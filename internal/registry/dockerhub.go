@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"strings"
 	"sync"
@@ -14,9 +15,15 @@ import (
 type DockerHubClient struct {
 	httpClient  *http.Client
 	rateLimiter *time.Ticker
+	quota       *QuotaTracker
 	ghostTags   sync.Map // repository -> []string (tags with no published images)
+	hubURL      string
+	pageLimits  TagPageLimits
 }
 
+// dockerHubPageSize is the number of tags requested per page of the Docker Hub API.
+const dockerHubPageSize = 100
+
 // NewDockerHubClient creates a new Docker Hub client.
 func NewDockerHubClient() *DockerHubClient {
 	return &DockerHubClient{
@@ -24,14 +31,47 @@ func NewDockerHubClient() *DockerHubClient {
 			Timeout: DefaultHTTPTimeout,
 		},
 		rateLimiter: time.NewTicker(DefaultRateLimitInterval), // 10 requests per second max
+		quota:       NewQuotaTracker(DefaultQuotaThreshold),
+		hubURL:      "https://hub.docker.com",
 	}
 }
 
+// SetProxy routes the client's requests through proxy.
+// Must be called before the client is used.
+func (c *DockerHubClient) SetProxy(proxy ProxyFunc) {
+	c.httpClient.Transport = newProxyTransport(proxy)
+}
+
+// SetPageLimits caps the pages of tags fetched per repository.
+// Must be called before the client is used.
+func (c *DockerHubClient) SetPageLimits(limits TagPageLimits) {
+	c.pageLimits = limits
+}
+
+// Quota returns the tracker of the Docker Hub rate limit quota reported on responses.
+func (c *DockerHubClient) Quota() *QuotaTracker {
+	return c.quota
+}
+
 // Close stops the rate limiter ticker and releases resources.
 func (c *DockerHubClient) Close() {
 	c.rateLimiter.Stop()
 }
 
+// Name returns ProviderDockerHub.
+func (c *DockerHubClient) Name() string {
+	return ProviderDockerHub
+}
+
+// Authenticate returns a bearer token for pulling from repository.
+func (c *DockerHubClient) Authenticate(ctx context.Context, repository string) (string, error) {
+	token, err := c.getRegistryToken(ctx, repository)
+	if err != nil {
+		return "", err
+	}
+	return "Bearer " + token, nil
+}
+
 // doWithRetry executes an HTTP request with exponential backoff retry on transient errors.
 func (c *DockerHubClient) doWithRetry(req *http.Request) (*http.Response, error) {
 	var lastErr error
@@ -48,6 +88,7 @@ func (c *DockerHubClient) doWithRetry(req *http.Request) (*http.Response, error)
 
 		resp, err := c.httpClient.Do(req)
 		if err == nil {
+			c.quota.Record(resp)
 			return resp, nil
 		}
 
@@ -61,6 +102,12 @@ func (c *DockerHubClient) doWithRetry(req *http.Request) (*http.Response, error)
 	return nil, fmt.Errorf("after %d retries: %w", maxRetries, lastErr)
 }
 
+// maxPages returns the page limit of a repository: the configured limit, or the
+// adaptive default of getMaxPages.
+func (c *DockerHubClient) maxPages(repository string) int {
+	return c.pageLimits.pages("docker.io", repository, c.getMaxPages(repository))
+}
+
 // getMaxPages determines optimal page limit based on repository type
 // Official images (library/*) tend to have many tags, user images have fewer
 func (c *DockerHubClient) getMaxPages(repository string) int {
@@ -110,58 +157,71 @@ type dockerHubImage struct {
 // ListTags returns all available tags for a Docker Hub image.
 // Repository format: "namespace/repository" (e.g., "library/nginx", "linuxserver/plex")
 func (c *DockerHubClient) ListTags(ctx context.Context, repository string) ([]string, error) {
-	// Docker Hub API endpoint
-	url := fmt.Sprintf("https://hub.docker.com/v2/repositories/%s/tags?page_size=100", repository)
-
-	tags := []string{}
-	var ghosts []string
-	// Adaptive maxPages based on repository type (reduces API calls for smaller repos)
-	maxPages := c.getMaxPages(repository)
-	pageCount := 0
-
-	for url != "" && pageCount < maxPages {
-		// Rate limiting
-		<-c.rateLimiter.C
+	tags, _, err := c.ListTagsConditional(ctx, repository, CacheValidators{})
+	return tags, err
+}
 
-		req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
-		if err != nil {
-			return nil, fmt.Errorf("failed to create request: %w", err)
-		}
+// ListTagsConditional lists tags unless the first page is unchanged since validators,
+// in which case it returns ErrNotModified. Docker Hub orders tags by last update, so
+// any pushed tag changes the first page. The first page gives the number of tags;
+// the remaining pages are fetched tagPageParallelism at a time, and the listing stops
+// early once the context's tag list stop condition (see WithTagListStop) is met.
+func (c *DockerHubClient) ListTagsConditional(ctx context.Context, repository string, validators CacheValidators) ([]string, CacheValidators, error) {
+	first, resp, err := c.getTagsPage(ctx, repository, 1, validators)
+	if err != nil {
+		return nil, validators, err
+	}
+	if resp.StatusCode == http.StatusNotModified {
+		return nil, validators, ErrNotModified
+	}
+	newValidators := responseValidators(resp)
 
-		resp, err := c.doWithRetry(req)
-		if err != nil {
-			return nil, fmt.Errorf("failed to fetch tags: %w", err)
+	// Adaptive maxPages based on repository type (reduces API calls for smaller repos)
+	pages := 1
+	if len(first.Results) == dockerHubPageSize {
+		pages = c.maxPages(repository)
+		if first.Count > 0 {
+			pages = min(pages, (first.Count+dockerHubPageSize-1)/dockerHubPageSize)
 		}
+	}
 
-		if resp.StatusCode != http.StatusOK {
-			err := handleHTTPError(resp, "docker hub tags request")
-			resp.Body.Close()
-			return nil, err
+	tags, ghosts := splitGhostTags(first.Results)
+	stop := tagListStopFrom(ctx)
+	for next := 2; next <= pages && !stop.done(tags); {
+		wave := min(tagPageParallelism, pages-next+1)
+		results := make([]*dockerHubTagsResponse, wave)
+		errs := make([]error, wave)
+		var wg sync.WaitGroup
+		for i := range wave {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				results[i], _, errs[i] = c.getTagsPage(ctx, repository, next+i, CacheValidators{})
+			}()
 		}
+		wg.Wait()
 
-		var tagsResp dockerHubTagsResponse
-		if err := json.NewDecoder(resp.Body).Decode(&tagsResp); err != nil {
-			resp.Body.Close()
-			return nil, fmt.Errorf("failed to decode response: %w", err)
-		}
-		resp.Body.Close()
-
-		for _, tag := range tagsResp.Results {
-			if len(tag.Images) == 0 {
-				ghosts = append(ghosts, tag.Name)
-				continue // Skip ghost tags with no manifests
+		last := false
+		for i, page := range results {
+			if errs[i] != nil {
+				return nil, validators, errs[i]
+			}
+			if page == nil {
+				last = true // Past the last page, tags were deleted since the first page
+				break
+			}
+			pageTags, pageGhosts := splitGhostTags(page.Results)
+			tags = append(tags, pageTags...)
+			ghosts = append(ghosts, pageGhosts...)
+			if len(page.Results) < dockerHubPageSize {
+				last = true
+				break
 			}
-			tags = append(tags, tag.Name)
 		}
-
-		// Handle pagination
-		url = tagsResp.Next
-		pageCount++
-
-		// If we got less than 100 results, we've reached the end
-		if len(tagsResp.Results) < 100 {
+		if last {
 			break
 		}
+		next += wave
 	}
 
 	// Store ghost tags for this repository so callers can check for unpublished newer versions
@@ -171,7 +231,57 @@ func (c *DockerHubClient) ListTags(ctx context.Context, repository string) ([]st
 		c.ghostTags.Delete(repository)
 	}
 
-	return tags, nil
+	return tags, newValidators, nil
+}
+
+// getTagsPage fetches a page of a repository's tags. The response is returned for
+// its status and headers; a 304 (with validators) has no page, and neither has a 404
+// past the first page, which means the page no longer exists.
+func (c *DockerHubClient) getTagsPage(ctx context.Context, repository string, page int, validators CacheValidators) (*dockerHubTagsResponse, *http.Response, error) {
+	// Rate limiting
+	<-c.rateLimiter.C
+
+	url := fmt.Sprintf("%s/v2/repositories/%s/tags?page_size=%d&page=%d", c.hubURL, repository, dockerHubPageSize, page)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	validators.apply(req.Header)
+
+	resp, err := c.doWithRetry(req)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to fetch tags: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotModified:
+		return nil, resp, nil
+	case resp.StatusCode == http.StatusNotFound && page > 1:
+		return nil, resp, nil
+	case resp.StatusCode != http.StatusOK:
+		return nil, resp, handleHTTPError(resp, "docker hub tags request")
+	}
+
+	var tagsResp dockerHubTagsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&tagsResp); err != nil {
+		return nil, resp, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return &tagsResp, resp, nil
+}
+
+// splitGhostTags separates the tags of a page from its ghost tags, which have no
+// published images.
+func splitGhostTags(results []dockerHubTag) (tags, ghosts []string) {
+	tags = []string{}
+	for _, tag := range results {
+		if len(tag.Images) == 0 {
+			ghosts = append(ghosts, tag.Name)
+			continue // Skip ghost tags with no manifests
+		}
+		tags = append(tags, tag.Name)
+	}
+	return tags, ghosts
 }
 
 // GetGhostTags returns tags that were filtered out because they have no published images.
@@ -222,31 +332,9 @@ func (c *DockerHubClient) GetTagDigest(ctx context.Context, repository, tag stri
 
 	// Docker Hub v2 API for manifest
 	// First we need to get a token for the repository
-	tokenURL := fmt.Sprintf("https://auth.docker.io/token?service=registry.docker.io&scope=repository:%s:pull", repository)
-
-	// Rate limiting for token request
-	<-c.rateLimiter.C
-
-	tokenReq, err := http.NewRequestWithContext(ctx, "GET", tokenURL, nil)
-	if err != nil {
-		return "", fmt.Errorf("failed to create token request: %w", err)
-	}
-
-	tokenResp, err := c.doWithRetry(tokenReq)
+	token, err := c.getRegistryToken(ctx, repository)
 	if err != nil {
-		return "", fmt.Errorf("failed to get auth token: %w", err)
-	}
-	defer tokenResp.Body.Close()
-
-	if tokenResp.StatusCode != http.StatusOK {
-		return "", handleHTTPError(tokenResp, "docker hub auth token request")
-	}
-
-	var tokenData struct {
-		Token string `json:"token"`
-	}
-	if err := json.NewDecoder(tokenResp.Body).Decode(&tokenData); err != nil {
-		return "", fmt.Errorf("failed to decode token response: %w", err)
+		return "", err
 	}
 
 	// Now fetch the manifest with the token
@@ -260,7 +348,7 @@ func (c *DockerHubClient) GetTagDigest(ctx context.Context, repository, tag stri
 		return "", fmt.Errorf("failed to create manifest request: %w", err)
 	}
 
-	manifestReq.Header.Set("Authorization", "Bearer "+tokenData.Token)
+	manifestReq.Header.Set("Authorization", "Bearer "+token)
 	manifestReq.Header.Set("Accept", "application/vnd.docker.distribution.manifest.v2+json")
 
 	manifestResp, err := c.doWithRetry(manifestReq)
@@ -282,15 +370,138 @@ func (c *DockerHubClient) GetTagDigest(ctx context.Context, repository, tag stri
 	return digest, nil
 }
 
+// GetImageSize returns the compressed size in bytes of the image at reference (tag or digest).
+func (c *DockerHubClient) GetImageSize(ctx context.Context, repository, reference string) (int64, error) {
+	fetch, err := c.newManifestFetcher(ctx, repository)
+	if err != nil {
+		return 0, err
+	}
+	return compressedImageSize(ctx, fetch, reference)
+}
+
+// GetImageLayers returns the compressed layers of the image at reference (tag or digest).
+func (c *DockerHubClient) GetImageLayers(ctx context.Context, repository, reference string) ([]ImageLayer, error) {
+	fetch, err := c.newManifestFetcher(ctx, repository)
+	if err != nil {
+		return nil, err
+	}
+	return imageLayers(ctx, fetch, reference)
+}
+
+// GetImagePlatforms returns the platforms of a multi-arch image at reference (tag or digest).
+func (c *DockerHubClient) GetImagePlatforms(ctx context.Context, repository, reference string) ([]string, error) {
+	fetch, err := c.newManifestFetcher(ctx, repository)
+	if err != nil {
+		return nil, err
+	}
+	return imagePlatforms(ctx, fetch, reference)
+}
+
+// GetPlatformDigest returns the digest of the manifest for the host platform at reference (tag or digest).
+func (c *DockerHubClient) GetPlatformDigest(ctx context.Context, repository, reference string) (string, error) {
+	fetch, err := c.newManifestFetcher(ctx, repository)
+	if err != nil {
+		return "", err
+	}
+	return platformDigest(ctx, fetch, reference)
+}
+
+// GetSignatures returns the cosign signatures and attestations of the image at digest.
+func (c *DockerHubClient) GetSignatures(ctx context.Context, repository, digest string) (*ImageSignatures, error) {
+	fetch, err := c.newRegistryFetcher(ctx, repository)
+	if err != nil {
+		return nil, err
+	}
+	return imageSignatures(ctx, fetch, digest)
+}
+
+// newManifestFetcher returns a manifestFetcher for repository.
+func (c *DockerHubClient) newManifestFetcher(ctx context.Context, repository string) (manifestFetcher, error) {
+	fetch, err := c.newRegistryFetcher(ctx, repository)
+	if err != nil {
+		return nil, err
+	}
+	return fetch.manifests, nil
+}
+
+// newRegistryFetcher returns a registryFetcher for repository.
+func (c *DockerHubClient) newRegistryFetcher(ctx context.Context, repository string) (registryFetcher, error) {
+	if !strings.Contains(repository, "/") {
+		repository = "library/" + repository
+	}
+
+	token, err := c.getRegistryToken(ctx, repository)
+	if err != nil {
+		return nil, err
+	}
+
+	fetch := func(ctx context.Context, endpoint, ref string) ([]byte, error) {
+		// Rate limiting for manifest request
+		<-c.rateLimiter.C
+
+		url := fmt.Sprintf("https://registry-1.docker.io/v2/%s/%s/%s", repository, endpoint, ref)
+		req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create manifest request: %w", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("Accept", manifestAccept)
+
+		resp, err := c.doWithRetry(req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch manifest: %w", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			return nil, handleHTTPError(resp, "docker hub manifest request")
+		}
+		return io.ReadAll(resp.Body)
+	}
+
+	return fetch, nil
+}
+
+// getRegistryToken obtains an anonymous pull token for a Docker Hub repository.
+func (c *DockerHubClient) getRegistryToken(ctx context.Context, repository string) (string, error) {
+	tokenURL := fmt.Sprintf("https://auth.docker.io/token?service=registry.docker.io&scope=repository:%s:pull", repository)
+
+	// Rate limiting for token request
+	<-c.rateLimiter.C
+
+	tokenReq, err := http.NewRequestWithContext(ctx, "GET", tokenURL, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create token request: %w", err)
+	}
+
+	tokenResp, err := c.doWithRetry(tokenReq)
+	if err != nil {
+		return "", fmt.Errorf("failed to get auth token: %w", err)
+	}
+	defer tokenResp.Body.Close()
+
+	if tokenResp.StatusCode != http.StatusOK {
+		return "", handleHTTPError(tokenResp, "docker hub auth token request")
+	}
+
+	var tokenData struct {
+		Token string `json:"token"`
+	}
+	if err := json.NewDecoder(tokenResp.Body).Decode(&tokenData); err != nil {
+		return "", fmt.Errorf("failed to decode token response: %w", err)
+	}
+	return tokenData.Token, nil
+}
+
 // ListTagsWithDigests returns a mapping of tags to their digests.
 // This is more efficient than calling GetTagDigest for each tag individually.
 func (c *DockerHubClient) ListTagsWithDigests(ctx context.Context, repository string) (map[string][]string, error) {
 	// Docker Hub API endpoint
-	url := fmt.Sprintf("https://hub.docker.com/v2/repositories/%s/tags?page_size=100", repository)
+	url := fmt.Sprintf("%s/v2/repositories/%s/tags?page_size=%d", c.hubURL, repository, dockerHubPageSize)
 
 	tagDigests := make(map[string][]string)
 	// Adaptive maxPages based on repository type
-	maxPages := c.getMaxPages(repository)
+	maxPages := c.maxPages(repository)
 	pageCount := 0
 
 	for url != "" && pageCount < maxPages {
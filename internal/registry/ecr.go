@@ -0,0 +1,139 @@
+package registry
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// ecrTagPageSize is the number of tags requested per page, ECR's maximum.
+const ecrTagPageSize = 1000
+
+// ecrHostPattern matches ECR registry hostnames, capturing the region and domain,
+// e.g. 123456789012.dkr.ecr.us-east-1.amazonaws.com.
+var ecrHostPattern = regexp.MustCompile(`^\d{12}\.dkr(?:-fips)?\.ecr(?:-fips)?\.([a-z0-9-]+)\.(amazonaws\.com(?:\.cn)?)$`)
+
+// ECRClient implements the Client interface for AWS Elastic Container Registry.
+// ECR speaks the OCI Distribution API with basic auth, the password being a token
+// that expires after 12 hours. ECRClient exchanges AWS credentials for new ones
+// as they expire, so no docker login has to be repeated.
+type ECRClient struct {
+	*HTTPClient
+}
+
+// NewECRClient creates a client for an ECR registry. AWS credentials are resolved
+// like the AWS SDKs do: from the environment, a web identity token (IAM roles for
+// service accounts), the shared credentials file, or the ECS or EC2 role. Docker
+// config credentials for the registry, if any, are used when none are found.
+func NewECRClient(config *RegistryConfig, registry string) *ECRClient {
+	if config == nil {
+		config = &RegistryConfig{}
+	}
+	if config.Username == "" {
+		config.Username, config.Password = dockerConfigCredentials(registry)
+	}
+
+	client := NewHTTPClientForRegistry(config, registry)
+	client.pageSize = ecrTagPageSize
+	config.Credentials = newECRCredentials(registry, client.httpClient)
+	return &ECRClient{HTTPClient: client}
+}
+
+// Name returns ProviderECR.
+func (c *ECRClient) Name() string {
+	return ProviderECR
+}
+
+// isECRRegistry reports whether registry is an ECR registry's hostname.
+func isECRRegistry(registry string) bool {
+	return ecrHostPattern.MatchString(registry)
+}
+
+// ecrCredentials exchanges AWS credentials for an ECR registry's password.
+type ecrCredentials struct {
+	aws        *awsCredentialChain
+	httpClient *http.Client
+	region     string
+	endpoint   string // ECR API URL
+	now        func() time.Time
+}
+
+// newECRCredentials returns a credential source for an ECR registry, renewing its
+// password shortly before it expires.
+func newECRCredentials(registry string, httpClient *http.Client) CredentialSource {
+	var region, domain string
+	if match := ecrHostPattern.FindStringSubmatch(registry); match != nil {
+		region, domain = match[1], match[2]
+	}
+	endpoint := os.Getenv("AWS_ENDPOINT_URL_ECR")
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://api.ecr.%s.%s", region, domain)
+	}
+	ecr := &ecrCredentials{
+		aws:        newAWSCredentialChain(region, domain, httpClient),
+		httpClient: httpClient,
+		region:     region,
+		endpoint:   endpoint,
+		now:        time.Now,
+	}
+	return newExpiringCredentials(ecr.fetch)
+}
+
+// ecrAuthorizationResponse is the response of GetAuthorizationToken.
+type ecrAuthorizationResponse struct {
+	AuthorizationData []struct {
+		AuthorizationToken string  `json:"authorizationToken"` // base64 of "AWS:password"
+		ExpiresAt          float64 `json:"expiresAt"`          // Unix time
+	} `json:"authorizationData"`
+}
+
+// fetch calls GetAuthorizationToken, signed with the chain's AWS credentials.
+func (e *ecrCredentials) fetch(ctx context.Context) (username, password string, expiresAt time.Time, err error) {
+	creds, err := e.aws.retrieve(ctx)
+	if err != nil {
+		return "", "", time.Time{}, err
+	}
+
+	body := []byte("{}")
+	req, err := http.NewRequestWithContext(ctx, "POST", e.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return "", "", time.Time{}, fmt.Errorf("failed to create ECR request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "AmazonEC2ContainerRegistry_V20150921.GetAuthorizationToken")
+	signV4(req, body, creds, e.region, "ecr", e.now())
+
+	resp, err := e.httpClient.Do(req)
+	if err != nil {
+		return "", "", time.Time{}, fmt.Errorf("failed to get ECR authorization token: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", "", time.Time{}, handleHTTPError(resp, "ECR GetAuthorizationToken")
+	}
+
+	var auth ecrAuthorizationResponse
+	if err := json.NewDecoder(resp.Body).Decode(&auth); err != nil {
+		return "", "", time.Time{}, fmt.Errorf("failed to decode ECR authorization token: %w", err)
+	}
+	if len(auth.AuthorizationData) == 0 {
+		return "", "", time.Time{}, fmt.Errorf("ECR returned no authorization token")
+	}
+	data := auth.AuthorizationData[0]
+	decoded, err := base64.StdEncoding.DecodeString(data.AuthorizationToken)
+	if err != nil {
+		return "", "", time.Time{}, fmt.Errorf("failed to decode ECR authorization token: %w", err)
+	}
+	username, password, ok := strings.Cut(string(decoded), ":")
+	if !ok {
+		return "", "", time.Time{}, fmt.Errorf("malformed ECR authorization token")
+	}
+	return username, password, time.Unix(int64(data.ExpiresAt), 0), nil
+}
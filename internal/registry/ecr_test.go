@@ -0,0 +1,262 @@
+package registry
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// clearAWSEnvironment unsets the AWS credential sources, so tests only see those they set.
+func clearAWSEnvironment(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	for _, name := range []string{
+		"AWS_ACCESS_KEY_ID", "AWS_SECRET_ACCESS_KEY", "AWS_SESSION_TOKEN",
+		"AWS_ROLE_ARN", "AWS_WEB_IDENTITY_TOKEN_FILE", "AWS_SHARED_CREDENTIALS_FILE", "AWS_PROFILE",
+		"AWS_CONTAINER_CREDENTIALS_RELATIVE_URI", "AWS_CONTAINER_CREDENTIALS_FULL_URI",
+	} {
+		t.Setenv(name, "")
+	}
+	t.Setenv("AWS_EC2_METADATA_DISABLED", "true")
+}
+
+func TestSignV4(t *testing.T) {
+	// Examples from the AWS Signature Version 4 documentation and test suite
+	creds := awsCredentials{AccessKeyID: "AKIDEXAMPLE", SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY"}
+	now := time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC)
+
+	req, _ := http.NewRequest("GET", "https://iam.amazonaws.com/?Version=2010-05-08&Action=ListUsers", nil)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=utf-8")
+	signV4(req, nil, creds, "us-east-1", "iam", now)
+	want := "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/iam/aws4_request, " +
+		"SignedHeaders=content-type;host;x-amz-date, " +
+		"Signature=5d672d79c15b13162d9279b0855cfba6789a8edb4c82c400e06b5924a6f2b5d7"
+	if got := req.Header.Get("Authorization"); got != want {
+		t.Errorf("Authorization =\n%s\nwant\n%s", got, want)
+	}
+
+	req, _ = http.NewRequest("GET", "https://example.amazonaws.com/", nil)
+	signV4(req, nil, creds, "us-east-1", "service", now)
+	if got := req.Header.Get("Authorization"); !strings.HasSuffix(got, "Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31") {
+		t.Errorf("Authorization = %s, want the get-vanilla signature", got)
+	}
+}
+
+func TestCanonicalQuery(t *testing.T) {
+	req, _ := http.NewRequest("GET", "https://example.com/?b=2&a-b=3&a=z&a=y&c=x%20y", nil)
+	if got, want := canonicalQuery(req.URL.Query()), "a=y&a=z&a-b=3&b=2&c=x%20y"; got != want {
+		t.Errorf("canonicalQuery = %q, want %q", got, want)
+	}
+}
+
+func TestIsECRRegistry(t *testing.T) {
+	for registry, want := range map[string]bool{
+		"123456789012.dkr.ecr.us-east-1.amazonaws.com":          true,
+		"123456789012.dkr.ecr-fips.us-gov-west-1.amazonaws.com": true,
+		"123456789012.dkr.ecr.cn-north-1.amazonaws.com.cn":      true,
+		"public.ecr.aws":                                        false,
+		"12345.dkr.ecr.us-east-1.amazonaws.com":                 false,
+		"123456789012.dkr.ecr.us-east-1.amazonaws.com.evil.com": false,
+	} {
+		if got := isECRRegistry(registry); got != want {
+			t.Errorf("isECRRegistry(%s) = %v, want %v", registry, got, want)
+		}
+	}
+}
+
+// newECRAPI serves GetAuthorizationToken for requests signed by access key
+// AKIDTEST, issuing the password "ecr-password-N" on the Nth call, valid for ttl.
+func newECRAPI(t *testing.T, ttl time.Duration) (*httptest.Server, *atomic.Int32) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth := r.Header.Get("Authorization")
+		if !strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=AKIDTEST/") || !strings.Contains(auth, "/us-east-1/ecr/aws4_request") {
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, `{"__type":"UnrecognizedClientException","message":"The security token included in the request is invalid."}`)
+			return
+		}
+		if r.Header.Get("X-Amz-Target") != "AmazonEC2ContainerRegistry_V20150921.GetAuthorizationToken" {
+			t.Errorf("X-Amz-Target = %q", r.Header.Get("X-Amz-Target"))
+		}
+		n := calls.Add(1)
+		token := base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf("AWS:ecr-password-%d", n)))
+		json.NewEncoder(w).Encode(map[string]any{
+			"authorizationData": []map[string]any{{
+				"authorizationToken": token,
+				"expiresAt":          float64(time.Now().Add(ttl).Unix()),
+				"proxyEndpoint":      "https://123456789012.dkr.ecr.us-east-1.amazonaws.com",
+			}},
+		})
+	}))
+	t.Cleanup(server.Close)
+	return server, &calls
+}
+
+func TestECRCredentials_TokenExchange(t *testing.T) {
+	clearAWSEnvironment(t)
+	api, calls := newECRAPI(t, 12*time.Hour)
+	t.Setenv("AWS_ENDPOINT_URL_ECR", api.URL)
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKIDTEST")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+
+	source := newECRCredentials("123456789012.dkr.ecr.us-east-1.amazonaws.com", http.DefaultClient)
+	for range 3 {
+		username, password, err := source.Credentials(context.Background())
+		if err != nil {
+			t.Fatalf("Credentials failed: %v", err)
+		}
+		if username != "AWS" || password != "ecr-password-1" {
+			t.Errorf("credentials = %s:%s, want AWS:ecr-password-1", username, password)
+		}
+	}
+	if calls.Load() != 1 {
+		t.Errorf("GetAuthorizationToken called %d times, want the token cached", calls.Load())
+	}
+}
+
+func TestECRCredentials_RenewsBeforeExpiry(t *testing.T) {
+	clearAWSEnvironment(t)
+	api, calls := newECRAPI(t, 12*time.Hour)
+	t.Setenv("AWS_ENDPOINT_URL_ECR", api.URL)
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKIDTEST")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+
+	source := newECRCredentials("123456789012.dkr.ecr.us-east-1.amazonaws.com", http.DefaultClient).(*expiringCredentials)
+	now := time.Now()
+	source.now = func() time.Time { return now }
+	if _, _, err := source.Credentials(context.Background()); err != nil {
+		t.Fatalf("Credentials failed: %v", err)
+	}
+
+	now = now.Add(12*time.Hour - credentialRefreshMargin + time.Second)
+	_, password, err := source.Credentials(context.Background())
+	if err != nil {
+		t.Fatalf("Credentials failed: %v", err)
+	}
+	if password != "ecr-password-2" || calls.Load() != 2 {
+		t.Errorf("password = %s after %d calls, want a renewed token", password, calls.Load())
+	}
+}
+
+func TestECRCredentials_InstanceProfile(t *testing.T) {
+	clearAWSEnvironment(t)
+	api, _ := newECRAPI(t, 12*time.Hour)
+	t.Setenv("AWS_ENDPOINT_URL_ECR", api.URL)
+
+	imds := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "PUT" && r.URL.Path == "/latest/api/token":
+			fmt.Fprint(w, "imds-token")
+		case r.Header.Get("X-aws-ec2-metadata-token") != "imds-token":
+			w.WriteHeader(http.StatusUnauthorized)
+		case r.URL.Path == "/latest/meta-data/iam/security-credentials/":
+			fmt.Fprint(w, "docksmith-role")
+		case r.URL.Path == "/latest/meta-data/iam/security-credentials/docksmith-role":
+			json.NewEncoder(w).Encode(map[string]string{
+				"Code": "Success", "AccessKeyId": "AKIDTEST", "SecretAccessKey": "secret", "Token": "session",
+			})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer imds.Close()
+	t.Setenv("AWS_EC2_METADATA_DISABLED", "")
+	t.Setenv("AWS_EC2_METADATA_SERVICE_ENDPOINT", imds.URL)
+
+	source := newECRCredentials("123456789012.dkr.ecr.us-east-1.amazonaws.com", http.DefaultClient)
+	if _, password, err := source.Credentials(context.Background()); err != nil || password != "ecr-password-1" {
+		t.Errorf("Credentials = %q, %v, want a token for the instance profile", password, err)
+	}
+}
+
+func TestECRCredentials_WebIdentity(t *testing.T) {
+	clearAWSEnvironment(t)
+	api, _ := newECRAPI(t, 12*time.Hour)
+	t.Setenv("AWS_ENDPOINT_URL_ECR", api.URL)
+
+	sts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		if r.Form.Get("Action") != "AssumeRoleWithWebIdentity" || r.Form.Get("WebIdentityToken") != "projected-token" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		fmt.Fprint(w, `<AssumeRoleWithWebIdentityResponse xmlns="https://sts.amazonaws.com/doc/2011-06-15/">
+  <AssumeRoleWithWebIdentityResult>
+    <Credentials>
+      <AccessKeyId>AKIDTEST</AccessKeyId>
+      <SecretAccessKey>secret</SecretAccessKey>
+      <SessionToken>session</SessionToken>
+      <Expiration>2030-01-01T00:00:00Z</Expiration>
+    </Credentials>
+  </AssumeRoleWithWebIdentityResult>
+</AssumeRoleWithWebIdentityResponse>`)
+	}))
+	defer sts.Close()
+	tokenFile := t.TempDir() + "/token"
+	if err := os.WriteFile(tokenFile, []byte("projected-token\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("AWS_ENDPOINT_URL_STS", sts.URL)
+	t.Setenv("AWS_ROLE_ARN", "arn:aws:iam::123456789012:role/docksmith")
+	t.Setenv("AWS_WEB_IDENTITY_TOKEN_FILE", tokenFile)
+
+	source := newECRCredentials("123456789012.dkr.ecr.us-east-1.amazonaws.com", http.DefaultClient)
+	if _, password, err := source.Credentials(context.Background()); err != nil || password != "ecr-password-1" {
+		t.Errorf("Credentials = %q, %v, want a token for the assumed role", password, err)
+	}
+}
+
+func TestECRCredentials_NoAWSCredentials(t *testing.T) {
+	clearAWSEnvironment(t)
+	source := newECRCredentials("123456789012.dkr.ecr.us-east-1.amazonaws.com", http.DefaultClient)
+	if _, _, err := source.Credentials(context.Background()); err == nil || !strings.Contains(err.Error(), "no AWS credentials") {
+		t.Errorf("Credentials error = %v, want no AWS credentials", err)
+	}
+}
+
+func TestECRClient_ListTags(t *testing.T) {
+	clearAWSEnvironment(t)
+	api, _ := newECRAPI(t, 12*time.Hour)
+	t.Setenv("AWS_ENDPOINT_URL_ECR", api.URL)
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKIDTEST")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+
+	registry := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, pass, ok := r.BasicAuth(); !ok || user != "AWS" || pass != "ecr-password-1" {
+			w.Header().Set("WWW-Authenticate", `Basic realm="https://123456789012.dkr.ecr.us-east-1.amazonaws.com/",service="ecr.amazonaws.com"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.URL.Query().Get("n") != "1000" {
+			t.Errorf("page size = %q, want 1000", r.URL.Query().Get("n"))
+		}
+		json.NewEncoder(w).Encode(tagsResponse{Name: "app", Tags: []string{"1.0.0", "1.1.0"}})
+	}))
+	defer registry.Close()
+
+	client := NewECRClient(&RegistryConfig{Insecure: true}, strings.TrimPrefix(registry.URL, "http://"))
+	// The test registry's hostname is not an ECR one, so point the source at us-east-1 explicitly
+	client.config.Credentials = newECRCredentials("123456789012.dkr.ecr.us-east-1.amazonaws.com", http.DefaultClient)
+
+	tags, err := client.ListTags(context.Background(), "app")
+	if err != nil {
+		t.Fatalf("ListTags failed: %v", err)
+	}
+	if !slices.Equal(tags, []string{"1.0.0", "1.1.0"}) {
+		t.Errorf("tags = %v", tags)
+	}
+	if auth, err := client.Authenticate(context.Background(), "app"); err != nil || auth != basicAuth("AWS", "ecr-password-1") {
+		t.Errorf("Authenticate = %q, %v, want the ECR password", auth, err)
+	}
+	if client.Name() != ProviderECR {
+		t.Errorf("Name = %q, want ecr", client.Name())
+	}
+}
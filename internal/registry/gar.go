@@ -0,0 +1,269 @@
+package registry
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	// googleTokenURL is Google's OAuth token endpoint, unless a credentials file names another.
+	googleTokenURL = "https://oauth2.googleapis.com/token"
+
+	// googleScope is the OAuth scope requested for registry access.
+	googleScope = "https://www.googleapis.com/auth/cloud-platform"
+
+	// googleRegistryUsername is the username Google registries expect with an OAuth access token.
+	googleRegistryUsername = "oauth2accesstoken"
+)
+
+// garHostPattern matches the hostnames of Artifact Registry
+// (us-docker.pkg.dev, europe-west1-docker.pkg.dev) and Container Registry
+// (gcr.io, eu.gcr.io).
+var garHostPattern = regexp.MustCompile(`^(?:[a-z0-9-]+-docker\.pkg\.dev|(?:[a-z]+\.)?gcr\.io)$`)
+
+// GARClient implements the Client interface for Google Artifact Registry and
+// Container Registry. Both speak the OCI Distribution API, authenticated with
+// short-lived OAuth access tokens, which GARClient renews as they expire.
+type GARClient struct {
+	*HTTPClient
+}
+
+// NewGARClient creates a client for a Google registry. Access tokens are obtained
+// with Application Default Credentials: the GOOGLE_APPLICATION_CREDENTIALS file
+// (a service account key or gcloud user credentials), gcloud's application default
+// credentials, or the metadata server of GCE, GKE, and Cloud Run. Docker config
+// credentials for the registry, if any, are used when none are found.
+func NewGARClient(config *RegistryConfig, registry string) *GARClient {
+	if config == nil {
+		config = &RegistryConfig{}
+	}
+	if config.Username == "" {
+		config.Username, config.Password = dockerConfigCredentials(registry)
+	}
+
+	client := NewHTTPClientForRegistry(config, registry)
+	config.Credentials = newGoogleCredentials(client.httpClient)
+	return &GARClient{HTTPClient: client}
+}
+
+// Name returns ProviderGAR.
+func (c *GARClient) Name() string {
+	return ProviderGAR
+}
+
+// isGoogleRegistry reports whether registry is an Artifact Registry or Container
+// Registry hostname.
+func isGoogleRegistry(registry string) bool {
+	return garHostPattern.MatchString(registry)
+}
+
+// googleCredentials obtains OAuth access tokens with Application Default Credentials.
+type googleCredentials struct {
+	httpClient     *http.Client // For the token endpoint
+	metadataClient *http.Client // For the metadata server
+	now            func() time.Time
+}
+
+// newGoogleCredentials returns a credential source of Google registry access
+// tokens, renewing them shortly before they expire.
+func newGoogleCredentials(httpClient *http.Client) CredentialSource {
+	google := &googleCredentials{
+		httpClient:     httpClient,
+		metadataClient: newMetadataClient(),
+		now:            time.Now,
+	}
+	return newExpiringCredentials(google.fetch)
+}
+
+// googleCredentialsFile is a service account key or gcloud user credentials file.
+type googleCredentialsFile struct {
+	Type string `json:"type"` // "service_account" or "authorized_user"
+
+	// Service account keys
+	ClientEmail  string `json:"client_email"`
+	PrivateKey   string `json:"private_key"`
+	PrivateKeyID string `json:"private_key_id"`
+	TokenURI     string `json:"token_uri"`
+
+	// User credentials
+	ClientID     string `json:"client_id"`
+	ClientSecret string `json:"client_secret"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+// googleTokenResponse is the response of the token endpoint and metadata server.
+type googleTokenResponse struct {
+	AccessToken string `json:"access_token"`
+	ExpiresIn   int    `json:"expires_in"` // Seconds
+}
+
+// fetch returns a new access token from the first source of Application Default
+// Credentials found.
+func (g *googleCredentials) fetch(ctx context.Context) (username, password string, expiresAt time.Time, err error) {
+	path := os.Getenv("GOOGLE_APPLICATION_CREDENTIALS")
+	if path == "" {
+		if configDir, err := os.UserConfigDir(); err == nil {
+			wellKnown := filepath.Join(configDir, "gcloud", "application_default_credentials.json")
+			if _, err := os.Stat(wellKnown); err == nil {
+				path = wellKnown
+			}
+		}
+	}
+
+	var token *googleTokenResponse
+	if path != "" {
+		token, err = g.fileToken(ctx, path)
+	} else {
+		token, err = g.metadataToken(ctx)
+	}
+	if err != nil {
+		return "", "", time.Time{}, err
+	}
+	return googleRegistryUsername, token.AccessToken, g.now().Add(time.Duration(token.ExpiresIn) * time.Second), nil
+}
+
+// fileToken obtains an access token with the credentials of a file: a JWT signed
+// with a service account's key, or a user's refresh token.
+func (g *googleCredentials) fileToken(ctx context.Context, path string) (*googleTokenResponse, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Google credentials: %w", err)
+	}
+	var file googleCredentialsFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse Google credentials %s: %w", path, err)
+	}
+	tokenURL := file.TokenURI
+	if tokenURL == "" {
+		tokenURL = googleTokenURL
+	}
+
+	var form url.Values
+	switch file.Type {
+	case "service_account":
+		assertion, err := g.serviceAccountJWT(file, tokenURL)
+		if err != nil {
+			return nil, err
+		}
+		form = url.Values{
+			"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+			"assertion":  {assertion},
+		}
+	case "authorized_user":
+		form = url.Values{
+			"grant_type":    {"refresh_token"},
+			"client_id":     {file.ClientID},
+			"client_secret": {file.ClientSecret},
+			"refresh_token": {file.RefreshToken},
+		}
+	default:
+		return nil, fmt.Errorf("unsupported Google credentials type %q in %s (must be service_account or authorized_user)", file.Type, path)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return g.getToken(g.httpClient, req)
+}
+
+// serviceAccountJWT returns a JWT asserting a service account's identity, signed
+// with its private key, to exchange for an access token.
+func (g *googleCredentials) serviceAccountJWT(file googleCredentialsFile, tokenURL string) (string, error) {
+	block, _ := pem.Decode([]byte(file.PrivateKey))
+	if block == nil {
+		return "", fmt.Errorf("invalid private key for service account %s", file.ClientEmail)
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		if parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes); err != nil {
+			return "", fmt.Errorf("failed to parse private key for service account %s: %w", file.ClientEmail, err)
+		}
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return "", fmt.Errorf("private key for service account %s is not an RSA key", file.ClientEmail)
+	}
+
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT", "kid": file.PrivateKeyID})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode JWT header: %w", err)
+	}
+	now := g.now()
+	claims, err := json.Marshal(map[string]any{
+		"iss":   file.ClientEmail,
+		"scope": googleScope,
+		"aud":   tokenURL,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode JWT claims: %w", err)
+	}
+
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(signingInput))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("failed to sign JWT: %w", err)
+	}
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// metadataToken obtains the access token of the instance's service account from
+// the metadata server, GCE_METADATA_HOST or metadata.google.internal.
+func (g *googleCredentials) metadataToken(ctx context.Context) (*googleTokenResponse, error) {
+	host := os.Getenv("GCE_METADATA_HOST")
+	if host == "" {
+		host = "metadata.google.internal"
+	}
+	tokenURL := fmt.Sprintf("http://%s/computeMetadata/v1/instance/service-accounts/default/token?scopes=%s", host, googleScope)
+	req, err := http.NewRequestWithContext(ctx, "GET", tokenURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create metadata request: %w", err)
+	}
+	req.Header.Set("Metadata-Flavor", "Google")
+
+	token, err := g.getToken(g.metadataClient, req)
+	if err != nil {
+		return nil, fmt.Errorf("no Google credentials found (GOOGLE_APPLICATION_CREDENTIALS, gcloud, or metadata server): %w", err)
+	}
+	return token, nil
+}
+
+// getToken sends a token request and decodes the access token.
+func (g *googleCredentials) getToken(client *http.Client, req *http.Request) (*googleTokenResponse, error) {
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch access token: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, handleHTTPError(resp, "Google access token request")
+	}
+
+	var token googleTokenResponse
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return nil, fmt.Errorf("failed to decode access token: %w", err)
+	}
+	if token.AccessToken == "" {
+		return nil, fmt.Errorf("no access token in response")
+	}
+	return &token, nil
+}
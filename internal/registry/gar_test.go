@@ -0,0 +1,177 @@
+package registry
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// clearGoogleEnvironment unsets the Application Default Credentials sources.
+func clearGoogleEnvironment(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+	t.Setenv("GOOGLE_APPLICATION_CREDENTIALS", "")
+	t.Setenv("GCE_METADATA_HOST", "127.0.0.1:1") // Refuses connections
+}
+
+func TestIsGoogleRegistry(t *testing.T) {
+	for registry, want := range map[string]bool{
+		"us-docker.pkg.dev":           true,
+		"europe-west1-docker.pkg.dev": true,
+		"gcr.io":                      true,
+		"eu.gcr.io":                   true,
+		"us-python.pkg.dev":           false,
+		"gcr.io.example.com":          false,
+		"docker.io":                   false,
+	} {
+		if got := isGoogleRegistry(registry); got != want {
+			t.Errorf("isGoogleRegistry(%s) = %v, want %v", registry, got, want)
+		}
+	}
+}
+
+func TestGoogleCredentials_ServiceAccount(t *testing.T) {
+	clearGoogleEnvironment(t)
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		if r.Form.Get("grant_type") != "urn:ietf:params:oauth:grant-type:jwt-bearer" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		// Verify the assertion's signature and claims
+		parts := strings.Split(r.Form.Get("assertion"), ".")
+		if len(parts) != 3 {
+			t.Fatalf("malformed assertion %q", r.Form.Get("assertion"))
+		}
+		signature, _ := base64.RawURLEncoding.DecodeString(parts[2])
+		digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+		if err := rsa.VerifyPKCS1v15(&key.PublicKey, crypto.SHA256, digest[:], signature); err != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		var claims map[string]any
+		payload, _ := base64.RawURLEncoding.DecodeString(parts[1])
+		json.Unmarshal(payload, &claims)
+		if claims["iss"] != "docksmith@project.iam.gserviceaccount.com" || claims["scope"] != googleScope {
+			t.Errorf("claims = %v", claims)
+		}
+		json.NewEncoder(w).Encode(map[string]any{"access_token": "sa-token", "expires_in": 3599, "token_type": "Bearer"})
+	}))
+	defer server.Close()
+
+	keyFile := filepath.Join(t.TempDir(), "key.json")
+	data, _ := json.Marshal(map[string]string{
+		"type":           "service_account",
+		"client_email":   "docksmith@project.iam.gserviceaccount.com",
+		"private_key_id": "key-1",
+		"private_key":    string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})),
+		"token_uri":      server.URL,
+	})
+	if err := os.WriteFile(keyFile, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("GOOGLE_APPLICATION_CREDENTIALS", keyFile)
+
+	username, password, err := newGoogleCredentials(http.DefaultClient).Credentials(context.Background())
+	if err != nil {
+		t.Fatalf("Credentials failed: %v", err)
+	}
+	if username != googleRegistryUsername || password != "sa-token" {
+		t.Errorf("credentials = %s:%s, want oauth2accesstoken:sa-token", username, password)
+	}
+}
+
+func TestGoogleCredentials_AuthorizedUser(t *testing.T) {
+	clearGoogleEnvironment(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		if r.Form.Get("grant_type") != "refresh_token" || r.Form.Get("refresh_token") != "refresh" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{"access_token": "user-token", "expires_in": 3599})
+	}))
+	defer server.Close()
+
+	// gcloud auth application-default login stores user credentials in the config directory
+	configDir, _ := os.UserConfigDir()
+	path := filepath.Join(configDir, "gcloud", "application_default_credentials.json")
+	os.MkdirAll(filepath.Dir(path), 0o700)
+	data, _ := json.Marshal(map[string]string{
+		"type": "authorized_user", "client_id": "id", "client_secret": "secret", "refresh_token": "refresh", "token_uri": server.URL,
+	})
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, password, err := newGoogleCredentials(http.DefaultClient).Credentials(context.Background()); err != nil || password != "user-token" {
+		t.Errorf("Credentials = %q, %v, want the user's access token", password, err)
+	}
+}
+
+func TestGoogleCredentials_MetadataServer(t *testing.T) {
+	clearGoogleEnvironment(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Metadata-Flavor") != "Google" || r.URL.Path != "/computeMetadata/v1/instance/service-accounts/default/token" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{"access_token": "metadata-token", "expires_in": 3599})
+	}))
+	defer server.Close()
+	t.Setenv("GCE_METADATA_HOST", strings.TrimPrefix(server.URL, "http://"))
+
+	if _, password, err := newGoogleCredentials(http.DefaultClient).Credentials(context.Background()); err != nil || password != "metadata-token" {
+		t.Errorf("Credentials = %q, %v, want the instance's access token", password, err)
+	}
+}
+
+func TestGARClient_FallsBackToDockerConfig(t *testing.T) {
+	clearGoogleEnvironment(t)
+	registry := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, _, ok := r.BasicAuth(); !ok || user != "_json_key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		json.NewEncoder(w).Encode(tagsResponse{Name: "project/repo/app", Tags: []string{"1.0.0"}})
+	}))
+	defer registry.Close()
+
+	// Without Application Default Credentials, a static docker login still works
+	client := NewGARClient(&RegistryConfig{Insecure: true, Username: "_json_key", Password: "{}"}, strings.TrimPrefix(registry.URL, "http://"))
+	tags, err := client.ListTags(context.Background(), "project/repo/app")
+	if err != nil {
+		t.Fatalf("ListTags failed: %v", err)
+	}
+	if !slices.Equal(tags, []string{"1.0.0"}) {
+		t.Errorf("tags = %v", tags)
+	}
+
+	anonymous := NewGARClient(&RegistryConfig{Insecure: true}, strings.TrimPrefix(registry.URL, "http://"))
+	if _, err := anonymous.ListTags(context.Background(), "project/repo/app"); err == nil || !strings.Contains(err.Error(), "no Google credentials") {
+		t.Errorf("ListTags error = %v, want no Google credentials", err)
+	}
+}
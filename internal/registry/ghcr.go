@@ -2,13 +2,10 @@ package registry
 
 import (
 	"context"
-	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
-	"os"
-	"path/filepath"
 	"sort"
 	"strings"
 	"sync"
@@ -22,6 +19,7 @@ type GHCRClient struct {
 	tokenCache  map[string]tokenCacheEntry
 	tokenMutex  sync.RWMutex
 	rateLimiter *time.Ticker
+	pageLimits  TagPageLimits
 }
 
 // tokenCacheEntry stores a cached token with expiry.
@@ -48,11 +46,38 @@ func NewGHCRClient(githubPAT string) *GHCRClient {
 	}
 }
 
+// SetProxy routes the client's requests through proxy.
+// Must be called before the client is used.
+func (c *GHCRClient) SetProxy(proxy ProxyFunc) {
+	c.httpClient.Transport = newProxyTransport(proxy)
+}
+
+// SetPageLimits caps the pages of tags fetched per repository.
+// Must be called before the client is used.
+func (c *GHCRClient) SetPageLimits(limits TagPageLimits) {
+	c.pageLimits = limits
+}
+
 // Close stops the rate limiter ticker and releases resources.
 func (c *GHCRClient) Close() {
 	c.rateLimiter.Stop()
 }
 
+// Name returns ProviderGHCR.
+func (c *GHCRClient) Name() string {
+	return ProviderGHCR
+}
+
+// Authenticate returns a bearer token for pulling from repository, or "" when
+// GHCR serves it without one.
+func (c *GHCRClient) Authenticate(ctx context.Context, repository string) (string, error) {
+	token, err := c.getRegistryToken(ctx, repository)
+	if err != nil || token == "" {
+		return "", err
+	}
+	return "Bearer " + token, nil
+}
+
 // doWithRetry executes an HTTP request with exponential backoff retry on transient errors.
 func (c *GHCRClient) doWithRetry(req *http.Request) (*http.Response, error) {
 	var lastErr error
@@ -82,15 +107,14 @@ func (c *GHCRClient) doWithRetry(req *http.Request) (*http.Response, error) {
 	return nil, fmt.Errorf("after %d retries: %w", maxRetries, lastErr)
 }
 
-// getMaxPages returns the page limit for tag fetching.
-// We use conservative limits since GitHub Releases API now supplements
+// getMaxPages returns the page limit for tag fetching: the configured limit, or
+// conservative defaults since GitHub Releases API now supplements
 // version tags directly, reducing the need to paginate through many container tags.
 func (c *GHCRClient) getMaxPages(repository string, isV2API bool) int {
-	_ = repository // unused, kept for potential future use
 	if isV2API {
-		return 3 // 300 tags from V2 API
+		return c.pageLimits.pages("ghcr.io", repository, 3) // 300 tags from V2 API
 	}
-	return 2 // 200 versions from Packages API
+	return c.pageLimits.pages("ghcr.io", repository, 2) // 200 versions from Packages API
 }
 
 // dockerConfigAuth represents auth entry in Docker config
@@ -105,42 +129,9 @@ type dockerConfig struct {
 
 // readGHCRCredsFromDockerConfig reads GHCR credentials from ~/.docker/config.json
 func readGHCRCredsFromDockerConfig() string {
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return ""
-	}
-
-	configPath := filepath.Join(homeDir, ".docker", "config.json")
-	data, err := os.ReadFile(configPath)
-	if err != nil {
-		return ""
-	}
-
-	var config dockerConfig
-	if err := json.Unmarshal(data, &config); err != nil {
-		return ""
-	}
-
-	// Look for ghcr.io credentials
-	auth, found := config.Auths["ghcr.io"]
-	if !found {
-		return ""
-	}
-
-	// Decode base64 auth (format: username:password)
-	decoded, err := base64.StdEncoding.DecodeString(auth.Auth)
-	if err != nil {
-		return ""
-	}
-
-	// Split username:password
-	parts := strings.SplitN(string(decoded), ":", 2)
-	if len(parts) != 2 {
-		return ""
-	}
-
 	// For GHCR, the password is the PAT
-	return parts[1]
+	_, pat := dockerConfigCredentials("ghcr.io")
+	return pat
 }
 
 // ghcrTagList represents the tag list response.
@@ -619,6 +610,97 @@ func (c *GHCRClient) GetTagDigest(ctx context.Context, repository, tag string) (
 	return digest, nil
 }
 
+// GetImageSize returns the compressed size in bytes of the image at reference (tag or digest).
+func (c *GHCRClient) GetImageSize(ctx context.Context, repository, reference string) (int64, error) {
+	fetch, err := c.newManifestFetcher(ctx, repository)
+	if err != nil {
+		return 0, err
+	}
+	return compressedImageSize(ctx, fetch, reference)
+}
+
+// GetImageLayers returns the compressed layers of the image at reference (tag or digest).
+func (c *GHCRClient) GetImageLayers(ctx context.Context, repository, reference string) ([]ImageLayer, error) {
+	fetch, err := c.newManifestFetcher(ctx, repository)
+	if err != nil {
+		return nil, err
+	}
+	return imageLayers(ctx, fetch, reference)
+}
+
+// GetImagePlatforms returns the platforms of a multi-arch image at reference (tag or digest).
+func (c *GHCRClient) GetImagePlatforms(ctx context.Context, repository, reference string) ([]string, error) {
+	fetch, err := c.newManifestFetcher(ctx, repository)
+	if err != nil {
+		return nil, err
+	}
+	return imagePlatforms(ctx, fetch, reference)
+}
+
+// GetPlatformDigest returns the digest of the manifest for the host platform at reference (tag or digest).
+func (c *GHCRClient) GetPlatformDigest(ctx context.Context, repository, reference string) (string, error) {
+	fetch, err := c.newManifestFetcher(ctx, repository)
+	if err != nil {
+		return "", err
+	}
+	return platformDigest(ctx, fetch, reference)
+}
+
+// GetSignatures returns the cosign signatures and attestations of the image at digest.
+func (c *GHCRClient) GetSignatures(ctx context.Context, repository, digest string) (*ImageSignatures, error) {
+	fetch, err := c.newRegistryFetcher(ctx, repository)
+	if err != nil {
+		return nil, err
+	}
+	return imageSignatures(ctx, fetch, digest)
+}
+
+// newManifestFetcher returns a manifestFetcher for repository.
+func (c *GHCRClient) newManifestFetcher(ctx context.Context, repository string) (manifestFetcher, error) {
+	fetch, err := c.newRegistryFetcher(ctx, repository)
+	if err != nil {
+		return nil, err
+	}
+	return fetch.manifests, nil
+}
+
+// newRegistryFetcher returns a registryFetcher for repository.
+func (c *GHCRClient) newRegistryFetcher(ctx context.Context, repository string) (registryFetcher, error) {
+	token, err := c.getRegistryToken(ctx, repository)
+	if err != nil {
+		// Continue without token for public repos
+		token = ""
+	}
+
+	fetch := func(ctx context.Context, endpoint, ref string) ([]byte, error) {
+		// Rate limiting
+		<-c.rateLimiter.C
+
+		url := fmt.Sprintf("https://ghcr.io/v2/%s/%s/%s", repository, endpoint, ref)
+		req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %w", err)
+		}
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		req.Header.Set("Accept", manifestAccept)
+
+		resp, err := c.doWithRetry(req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query GHCR: %w", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			return nil, handleHTTPError(resp, fmt.Sprintf("GHCR manifest request for %s", ref))
+		}
+		return io.ReadAll(resp.Body)
+	}
+
+	return fetch, nil
+}
+
 // githubPackageVersion represents a version from GitHub Packages API
 type githubPackageVersion struct {
 	ID       int64  `json:"id"`
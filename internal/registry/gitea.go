@@ -0,0 +1,199 @@
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	// giteaPageSize is the number of packages requested per page of Gitea's API,
+	// its default maximum (MAX_RESPONSE_ITEMS).
+	giteaPageSize = 50
+
+	// giteaDigestTags is how many of the most recent tags ListTagsWithDigests resolves.
+	giteaDigestTags = 50
+)
+
+// GiteaClient implements the Client interface for the container registries of
+// Gitea and Forgejo, such as codeberg.org. Tags are listed through the packages
+// API, which orders them by creation; manifests are read through the OCI
+// Distribution API.
+type GiteaClient struct {
+	*HTTPClient
+}
+
+// NewGiteaClient creates a client for a Gitea or Forgejo registry. token is
+// optional: an access token with the read:package scope, or "username:password".
+// Without one, Docker config credentials for the registry are used, if any, and
+// public packages are read anonymously.
+func NewGiteaClient(config *RegistryConfig, registry, token string) *GiteaClient {
+	if config == nil {
+		config = &RegistryConfig{}
+	}
+	if token != "" {
+		username, password, ok := strings.Cut(token, ":")
+		if !ok {
+			// Gitea identifies the user by the access token alone
+			username, password = "docksmith", token
+		}
+		config.Username, config.Password = username, password
+	} else if config.Username == "" {
+		config.Username, config.Password = dockerConfigCredentials(registry)
+	}
+	return &GiteaClient{HTTPClient: NewHTTPClientForRegistry(config, registry)}
+}
+
+// Name returns ProviderGitea.
+func (c *GiteaClient) Name() string {
+	return ProviderGitea
+}
+
+// giteaPackage is a package version of Gitea's packages API.
+type giteaPackage struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// ListTags returns the tags of a repository, most recently created first.
+func (c *GiteaClient) ListTags(ctx context.Context, repository string) ([]string, error) {
+	tags, _, err := c.ListTagsConditional(ctx, repository, CacheValidators{})
+	return tags, err
+}
+
+// ListTagsConditional lists tags through Gitea's packages API, which does not
+// support conditional requests, so the list is always fetched. The listing stops
+// early once the context's tag list stop condition is met. When the API refuses
+// the repository, e.g. for a token without the read:package scope or on versions
+// without the API, tags are listed through the registry instead.
+func (c *GiteaClient) ListTagsConditional(ctx context.Context, repository string, validators CacheValidators) ([]string, CacheValidators, error) {
+	tags, err := c.listVersions(ctx, repository, 0, tagListStopFrom(ctx).done)
+	if apiRefused(err) {
+		return c.HTTPClient.ListTagsConditional(ctx, repository, validators)
+	}
+	if err != nil {
+		return nil, validators, err
+	}
+	return tags, CacheValidators{}, nil
+}
+
+// GetLatestTag returns the "latest" tag if there is one, or the most recently created tag.
+func (c *GiteaClient) GetLatestTag(ctx context.Context, repository string) (string, error) {
+	tags, err := c.ListTags(ctx, repository)
+	if err != nil {
+		return "", err
+	}
+	for _, tag := range tags {
+		if tag == "latest" {
+			return tag, nil
+		}
+	}
+	if len(tags) > 0 {
+		return tags[0], nil
+	}
+	return "", fmt.Errorf("no tags found for repository %s", repository)
+}
+
+// ListTagsWithDigests returns the digests of the most recently created tags. The
+// packages API has no digests, so they are resolved with manifest requests, a few
+// at a time; digest-pinned containers almost always run a recent tag.
+func (c *GiteaClient) ListTagsWithDigests(ctx context.Context, repository string) (map[string][]string, error) {
+	tags, err := c.listVersions(ctx, repository, giteaDigestTags, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var mu sync.Mutex
+	tagDigests := make(map[string][]string, len(tags))
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(4)
+	for _, tag := range tags {
+		g.Go(func() error {
+			digest, err := c.GetTagDigest(gctx, repository, tag)
+			if err != nil {
+				return fmt.Errorf("failed to resolve digest of %s: %w", tag, err)
+			}
+			mu.Lock()
+			tagDigests[tag] = []string{digest}
+			mu.Unlock()
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+	return tagDigests, nil
+}
+
+// listVersions returns the tags of a repository from the packages API, newest
+// first, until the last page, the page limit, max tags (0 for no limit), or until
+// stop (optional) reports the tags listed so far suffice.
+func (c *GiteaClient) listVersions(ctx context.Context, repository string, max int, stop func(tags []string) bool) ([]string, error) {
+	registry, repo := c.parseRepository(repository)
+	owner, name, ok := strings.Cut(repo, "/")
+	if !ok {
+		return nil, &statusError{StatusCode: http.StatusNotFound, message: fmt.Sprintf("%s has no owner", repo)}
+	}
+	protocol := "https"
+	if c.config.Insecure {
+		protocol = "http"
+	}
+
+	var tags []string
+	maxPages := c.pageLimits.pages(registry, repo, defaultMaxTagPages)
+	for page := 1; page <= maxPages; page++ {
+		// q matches names containing it, so other packages are filtered out below
+		pageURL := fmt.Sprintf("%s://%s/api/v1/packages/%s?type=container&q=%s&page=%d&limit=%d",
+			protocol, registry, url.PathEscape(owner), url.QueryEscape(name), page, giteaPageSize)
+		packages, err := c.getPackagesPage(ctx, pageURL)
+		if err != nil {
+			return nil, err
+		}
+		for _, pkg := range packages {
+			// Manifests of multi-arch images are stored as versions named by their digest
+			if !strings.EqualFold(pkg.Name, name) || strings.HasPrefix(pkg.Version, "sha256:") {
+				continue
+			}
+			tags = append(tags, pkg.Version)
+			if max > 0 && len(tags) == max {
+				return tags, nil
+			}
+		}
+		if len(packages) < giteaPageSize || (stop != nil && stop(tags)) {
+			return tags, nil
+		}
+	}
+	return tags, nil
+}
+
+// getPackagesPage requests a page of Gitea's packages API.
+func (c *GiteaClient) getPackagesPage(ctx context.Context, pageURL string) ([]giteaPackage, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", pageURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if err := c.setBasicAuth(req); err != nil {
+		return nil, err
+	}
+
+	resp, err := c.doWithRetry(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch packages: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, handleHTTPError(resp, "gitea packages request")
+	}
+	var packages []giteaPackage
+	if err := json.NewDecoder(resp.Body).Decode(&packages); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return packages, nil
+}
@@ -0,0 +1,117 @@
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// newGiteaRegistry serves the container package "owner/app" of a Gitea instance
+// with total tags, newest first (1.0.N down to 1.0.0), mixed with the digest
+// versions of multi-arch manifests and the package "owner/app-cli", which the
+// name search also matches. With private set, only the token "gitea-token" may
+// read the packages.
+func newGiteaRegistry(t *testing.T, total int, private bool) *httptest.Server {
+	var versions []giteaPackage
+	for i := total - 1; i >= 0; i-- {
+		versions = append(versions,
+			giteaPackage{Name: "app", Version: fmt.Sprintf("1.0.%d", i)},
+			giteaPackage{Name: "app", Version: fmt.Sprintf("sha256:%064d", i)},
+			giteaPackage{Name: "app-cli", Version: fmt.Sprintf("2.0.%d", i)})
+	}
+	authorized := func(r *http.Request) bool {
+		_, pass, ok := r.BasicAuth()
+		return !private || (ok && pass == "gitea-token")
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/api/v1/packages/owner":
+			if !authorized(r) {
+				w.WriteHeader(http.StatusNotFound) // Gitea hides private owners
+				return
+			}
+			if r.URL.Query().Get("type") != "container" || r.URL.Query().Get("q") != "app" {
+				t.Errorf("unexpected packages query %s", r.URL.RawQuery)
+			}
+			page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+			start := min((page-1)*giteaPageSize, len(versions))
+			json.NewEncoder(w).Encode(versions[start:min(start+giteaPageSize, len(versions))])
+		case strings.HasPrefix(r.URL.Path, "/v2/owner/app/manifests/"):
+			tag := strings.TrimPrefix(r.URL.Path, "/v2/owner/app/manifests/")
+			w.Header().Set("Docker-Content-Digest", "sha256:digest-of-"+tag)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestGiteaClient_ListTags(t *testing.T) {
+	server := newGiteaRegistry(t, 40, false)
+	client := NewGiteaClient(&RegistryConfig{Insecure: true}, strings.TrimPrefix(server.URL, "http://"), "")
+
+	tags, err := client.ListTags(context.Background(), "owner/app")
+	if err != nil {
+		t.Fatalf("ListTags failed: %v", err)
+	}
+	if len(tags) != 40 || tags[0] != "1.0.39" || tags[39] != "1.0.0" {
+		t.Errorf("got %d tags from %v to %v, want the 40 tags of app newest first", len(tags), tags[0], tags[len(tags)-1])
+	}
+
+	latest, err := client.GetLatestTag(context.Background(), "owner/app")
+	if err != nil || latest != "1.0.39" {
+		t.Errorf("GetLatestTag = %q, %v, want the most recent tag", latest, err)
+	}
+	if client.Name() != ProviderGitea {
+		t.Errorf("Name = %q, want gitea", client.Name())
+	}
+}
+
+func TestGiteaClient_ListTagsWithDigests(t *testing.T) {
+	server := newGiteaRegistry(t, 60, true)
+	client := NewGiteaClient(&RegistryConfig{Insecure: true}, strings.TrimPrefix(server.URL, "http://"), "gitea-token")
+
+	tagDigests, err := client.ListTagsWithDigests(context.Background(), "owner/app")
+	if err != nil {
+		t.Fatalf("ListTagsWithDigests failed: %v", err)
+	}
+	if len(tagDigests) != giteaDigestTags {
+		t.Errorf("got digests of %d tags, want the %d most recent", len(tagDigests), giteaDigestTags)
+	}
+	if got := tagDigests["1.0.59"]; !slices.Equal(got, []string{"sha256:digest-of-1.0.59"}) {
+		t.Errorf("digests of 1.0.59 = %v", got)
+	}
+	if _, ok := tagDigests["1.0.0"]; ok {
+		t.Error("expected the oldest tags to be left unresolved")
+	}
+}
+
+func TestGiteaClient_FallsBackToRegistry(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	server := newGiteaRegistry(t, 3, true)
+	registry := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v2/owner/app/tags/list" {
+			json.NewEncoder(w).Encode(tagsResponse{Name: "owner/app", Tags: []string{"1.0.0"}})
+			return
+		}
+		server.Config.Handler.ServeHTTP(w, r)
+	}))
+	defer registry.Close()
+
+	// Without a token the packages API hides the private owner
+	client := NewGiteaClient(&RegistryConfig{Insecure: true}, strings.TrimPrefix(registry.URL, "http://"), "")
+	tags, err := client.ListTags(context.Background(), "owner/app")
+	if err != nil {
+		t.Fatalf("ListTags failed: %v", err)
+	}
+	if !slices.Equal(tags, []string{"1.0.0"}) {
+		t.Errorf("tags = %v, want those of the registry", tags)
+	}
+}
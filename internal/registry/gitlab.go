@@ -0,0 +1,44 @@
+package registry
+
+import "strings"
+
+// gitlabTagPageSize is the number of tags requested per page. GitLab serves up
+// to 1000, where registries default to 100.
+const gitlabTagPageSize = 1000
+
+// GitLabClient implements the Client interface for GitLab container registries,
+// on gitlab.com (registry.gitlab.com) or self-hosted. GitLab speaks the OCI
+// Distribution API, with registry tokens issued by the instance's /jwt/auth for
+// the credentials of a user or deploy token.
+type GitLabClient struct {
+	*HTTPClient
+}
+
+// NewGitLabClient creates a client for a GitLab registry. token is optional: a
+// personal, project, or group access token, or "username:token" for deploy
+// tokens. Without one, Docker config credentials for the registry are used, if
+// any, and public projects are read anonymously.
+func NewGitLabClient(config *RegistryConfig, registry, token string) *GitLabClient {
+	if config == nil {
+		config = &RegistryConfig{}
+	}
+	if token != "" {
+		username, password, ok := strings.Cut(token, ":")
+		if !ok {
+			// GitLab ignores the username of access tokens
+			username, password = "docksmith", token
+		}
+		config.Username, config.Password = username, password
+	} else if config.Username == "" {
+		config.Username, config.Password = dockerConfigCredentials(registry)
+	}
+
+	client := NewHTTPClientForRegistry(config, registry)
+	client.pageSize = gitlabTagPageSize
+	return &GitLabClient{HTTPClient: client}
+}
+
+// Name returns ProviderGitLab.
+func (c *GitLabClient) Name() string {
+	return ProviderGitLab
+}
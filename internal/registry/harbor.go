@@ -0,0 +1,173 @@
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// harborPageSize is the number of artifacts requested per page of Harbor's API, its maximum.
+const harborPageSize = 100
+
+// HarborClient implements the Client interface for Harbor registries. Tags are
+// listed through Harbor's artifact API, which orders them by last push and
+// includes their digests; manifests are read through the OCI Distribution API.
+type HarborClient struct {
+	*HTTPClient
+}
+
+// NewHarborClient creates a client for a Harbor registry. robot is optional: the
+// "name:secret" of a robot account, e.g. "robot$project+docksmith:secret". Without
+// one, Docker config credentials for the registry are used, if any, and public
+// projects are read anonymously.
+func NewHarborClient(config *RegistryConfig, registry, robot string) *HarborClient {
+	if config == nil {
+		config = &RegistryConfig{}
+	}
+	if username, secret, ok := strings.Cut(robot, ":"); ok {
+		config.Username, config.Password = username, secret
+	} else if config.Username == "" {
+		config.Username, config.Password = dockerConfigCredentials(registry)
+	}
+
+	client := NewHTTPClientForRegistry(config, registry)
+	client.pageSize = harborPageSize
+	client.rebasePages = true
+	return &HarborClient{HTTPClient: client}
+}
+
+// Name returns ProviderHarbor.
+func (c *HarborClient) Name() string {
+	return ProviderHarbor
+}
+
+// harborArtifact is an artifact of Harbor's artifact API.
+type harborArtifact struct {
+	Digest string `json:"digest"`
+	Tags   []struct {
+		Name string `json:"name"`
+	} `json:"tags"`
+}
+
+// ListTags returns the tags of a repository, most recently pushed first.
+func (c *HarborClient) ListTags(ctx context.Context, repository string) ([]string, error) {
+	tags, _, err := c.ListTagsConditional(ctx, repository, CacheValidators{})
+	return tags, err
+}
+
+// ListTagsConditional lists tags through Harbor's artifact API, which does not
+// support conditional requests, so the list is always fetched. The listing stops
+// early once the context's tag list stop condition is met. When the API refuses
+// the repository, e.g. to a robot account without the artifact list permission,
+// tags are listed through the registry instead.
+func (c *HarborClient) ListTagsConditional(ctx context.Context, repository string, validators CacheValidators) ([]string, CacheValidators, error) {
+	var tags []string
+	err := c.listArtifacts(ctx, repository, func(tag, _ string) {
+		tags = append(tags, tag)
+	}, tagListStopFrom(ctx).done)
+	if apiRefused(err) {
+		return c.HTTPClient.ListTagsConditional(ctx, repository, validators)
+	}
+	if err != nil {
+		return nil, validators, err
+	}
+	return tags, CacheValidators{}, nil
+}
+
+// GetLatestTag returns the "latest" tag if there is one, or the most recently pushed tag.
+func (c *HarborClient) GetLatestTag(ctx context.Context, repository string) (string, error) {
+	tags, err := c.ListTags(ctx, repository)
+	if err != nil {
+		return "", err
+	}
+	for _, tag := range tags {
+		if tag == "latest" {
+			return tag, nil
+		}
+	}
+	if len(tags) > 0 {
+		return tags[0], nil
+	}
+	return "", fmt.Errorf("no tags found for repository %s", repository)
+}
+
+// ListTagsWithDigests returns the digest of each tag, from Harbor's artifact API.
+// Multi-arch tags map to the digest of their index, as in RepoDigests.
+func (c *HarborClient) ListTagsWithDigests(ctx context.Context, repository string) (map[string][]string, error) {
+	tagDigests := make(map[string][]string)
+	err := c.listArtifacts(ctx, repository, func(tag, digest string) {
+		tagDigests[tag] = []string{digest}
+	}, nil)
+	if err != nil {
+		return nil, err
+	}
+	return tagDigests, nil
+}
+
+// listArtifacts calls add for each tag of a repository's artifacts, until the last
+// page, the page limit, or until stop (optional) reports the tags listed so far
+// suffice. Untagged artifacts are skipped.
+func (c *HarborClient) listArtifacts(ctx context.Context, repository string, add func(tag, digest string), stop func(tags []string) bool) error {
+	registry, repo := c.parseRepository(repository)
+	project, name, ok := strings.Cut(repo, "/")
+	if !ok {
+		return &statusError{StatusCode: http.StatusNotFound, message: fmt.Sprintf("%s is not in a Harbor project", repo)}
+	}
+	protocol := "https"
+	if c.config.Insecure {
+		protocol = "http"
+	}
+
+	// Harbor decodes repository names once before routing, so the slashes of
+	// nested names must be encoded twice
+	escaped := url.PathEscape(url.PathEscape(name))
+	var names []string
+	maxPages := c.pageLimits.pages(registry, repo, defaultMaxTagPages)
+	for page := 1; page <= maxPages; page++ {
+		pageURL := fmt.Sprintf("%s://%s/api/v2.0/projects/%s/repositories/%s/artifacts?with_tag=true&with_label=false&with_scan_overview=false&sort=-push_time&page=%d&page_size=%d",
+			protocol, registry, url.PathEscape(project), escaped, page, harborPageSize)
+		artifacts, err := c.getArtifactsPage(ctx, pageURL)
+		if err != nil {
+			return err
+		}
+		for _, artifact := range artifacts {
+			for _, tag := range artifact.Tags {
+				add(tag.Name, artifact.Digest)
+				names = append(names, tag.Name)
+			}
+		}
+		if len(artifacts) < harborPageSize || (stop != nil && stop(names)) {
+			return nil
+		}
+	}
+	return nil
+}
+
+// getArtifactsPage requests a page of Harbor's artifact API.
+func (c *HarborClient) getArtifactsPage(ctx context.Context, pageURL string) ([]harborArtifact, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", pageURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if err := c.setBasicAuth(req); err != nil {
+		return nil, err
+	}
+
+	resp, err := c.doWithRetry(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch artifacts: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, handleHTTPError(resp, "harbor artifacts request")
+	}
+	var artifacts []harborArtifact
+	if err := json.NewDecoder(resp.Body).Decode(&artifacts); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return artifacts, nil
+}
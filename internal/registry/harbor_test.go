@@ -0,0 +1,135 @@
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// newHarborRegistry serves the project "proj" of a Harbor instance with the nested
+// repository "team/app": total artifacts, newest first (1.0.N down to 1.0.0), then
+// an untagged one, and records the API pages requested. Only the robot account
+// "robot$proj+docksmith" may list artifacts; with apiDenied, the robot lacks that
+// permission and can only pull, through a registry whose Link headers name
+// Harbor's internal hostname.
+func newHarborRegistry(t *testing.T, total int, apiDenied bool) (*httptest.Server, func() []int) {
+	var mu sync.Mutex
+	var requested []int
+	robot := func(r *http.Request) bool {
+		user, pass, ok := r.BasicAuth()
+		return ok && user == "robot$proj+docksmith" && pass == "secret"
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.EscapedPath() == "/api/v2.0/projects/proj/repositories/team%252Fapp/artifacts":
+			if !robot(r) || apiDenied {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			if r.URL.Query().Get("with_tag") != "true" || r.URL.Query().Get("sort") != "-push_time" {
+				t.Errorf("unexpected artifact query %s", r.URL.RawQuery)
+			}
+			page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+			mu.Lock()
+			requested = append(requested, page)
+			mu.Unlock()
+
+			var artifacts []map[string]any
+			for i := total - 1 - (page-1)*harborPageSize; i >= 0 && i > total-1-page*harborPageSize; i-- {
+				artifacts = append(artifacts, map[string]any{
+					"digest": fmt.Sprintf("sha256:%064d", i),
+					"tags":   []map[string]string{{"name": fmt.Sprintf("1.0.%d", i)}},
+				})
+			}
+			if len(artifacts) < harborPageSize {
+				artifacts = append(artifacts, map[string]any{"digest": "sha256:untagged", "tags": nil})
+			}
+			json.NewEncoder(w).Encode(artifacts)
+		case r.URL.Path == "/v2/proj/team/app/tags/list":
+			if !robot(r) {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			if r.URL.Query().Get("last") == "" {
+				w.Header().Set("Link", `<https://harbor-core.internal/v2/proj/team/app/tags/list?last=1.0.1&n=100>; rel="next"`)
+				json.NewEncoder(w).Encode(tagsResponse{Name: "proj/team/app", Tags: []string{"1.0.0", "1.0.1"}})
+				return
+			}
+			json.NewEncoder(w).Encode(tagsResponse{Name: "proj/team/app", Tags: []string{"1.0.2"}})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server, func() []int {
+		mu.Lock()
+		defer mu.Unlock()
+		return slices.Clone(requested)
+	}
+}
+
+func TestHarborClient_ListTags(t *testing.T) {
+	server, requested := newHarborRegistry(t, 150, false)
+	client := NewHarborClient(&RegistryConfig{Insecure: true}, strings.TrimPrefix(server.URL, "http://"), "robot$proj+docksmith:secret")
+
+	tags, err := client.ListTags(context.Background(), "proj/team/app")
+	if err != nil {
+		t.Fatalf("ListTags failed: %v", err)
+	}
+	if len(tags) != 150 || tags[0] != "1.0.149" || tags[149] != "1.0.0" {
+		t.Errorf("got %d tags from %v to %v, want 150 newest first", len(tags), tags[0], tags[len(tags)-1])
+	}
+	if !slices.Equal(requested(), []int{1, 2}) {
+		t.Errorf("pages requested = %v, want [1 2]", requested())
+	}
+
+	tagDigests, err := client.ListTagsWithDigests(context.Background(), "proj/team/app")
+	if err != nil {
+		t.Fatalf("ListTagsWithDigests failed: %v", err)
+	}
+	if got := tagDigests["1.0.7"]; !slices.Equal(got, []string{fmt.Sprintf("sha256:%064d", 7)}) {
+		t.Errorf("digests of 1.0.7 = %v", got)
+	}
+	if client.Name() != ProviderHarbor {
+		t.Errorf("Name = %q, want harbor", client.Name())
+	}
+}
+
+func TestHarborClient_ListTagsStopsEarly(t *testing.T) {
+	server, requested := newHarborRegistry(t, 500, false)
+	client := NewHarborClient(&RegistryConfig{Insecure: true}, strings.TrimPrefix(server.URL, "http://"), "robot$proj+docksmith:secret")
+
+	ctx, _ := withOwnTagListStop(WithTagListStop(context.Background(), func(tags []string) bool {
+		return slices.Contains(tags, "1.0.450")
+	}))
+	if _, _, err := client.ListTagsConditional(ctx, "proj/team/app", CacheValidators{}); err != nil {
+		t.Fatalf("ListTagsConditional failed: %v", err)
+	}
+	if !slices.Equal(requested(), []int{1}) {
+		t.Errorf("pages requested = %v, want [1]", requested())
+	}
+}
+
+func TestHarborClient_RobotWithoutAPIAccess(t *testing.T) {
+	server, _ := newHarborRegistry(t, 3, true)
+	client := NewHarborClient(&RegistryConfig{Insecure: true}, strings.TrimPrefix(server.URL, "http://"), "robot$proj+docksmith:secret")
+
+	// Tags are listed through the registry, following its pages on the registry's own host
+	tags, err := client.ListTags(context.Background(), "proj/team/app")
+	if err != nil {
+		t.Fatalf("ListTags failed: %v", err)
+	}
+	if !slices.Equal(tags, []string{"1.0.0", "1.0.1", "1.0.2"}) {
+		t.Errorf("tags = %v, want both registry pages", tags)
+	}
+	if _, err := client.ListTagsWithDigests(context.Background(), "proj/team/app"); err == nil {
+		t.Error("expected ListTagsWithDigests to fail without API access")
+	}
+}
@@ -1,14 +1,34 @@
 package registry
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 )
 
+// statusError is a non-200 response from a registry API.
+type statusError struct {
+	StatusCode int
+	message    string
+}
+
+func (e *statusError) Error() string {
+	return e.message
+}
+
 // handleHTTPError reads the response body and returns a formatted error
 // for non-200 HTTP responses from registry APIs.
 func handleHTTPError(resp *http.Response, operation string) error {
 	body, _ := io.ReadAll(resp.Body)
-	return fmt.Errorf("%s: registry returned %d: %s", operation, resp.StatusCode, string(body))
+	return &statusError{
+		StatusCode: resp.StatusCode,
+		message:    fmt.Sprintf("%s: registry returned %d: %s", operation, resp.StatusCode, string(body)),
+	}
+}
+
+// isNotFound reports whether err is a 404 response from a registry API.
+func isNotFound(err error) bool {
+	var statusErr *statusError
+	return errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusNotFound
 }
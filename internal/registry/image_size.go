@@ -0,0 +1,118 @@
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// manifestAccept lists the manifest formats accepted when fetching manifests for sizing.
+var manifestAccept = strings.Join([]string{
+	"application/vnd.docker.distribution.manifest.list.v2+json",
+	"application/vnd.oci.image.index.v1+json",
+	"application/vnd.docker.distribution.manifest.v2+json",
+	"application/vnd.oci.image.manifest.v1+json",
+}, ", ")
+
+// ImageLayer is a compressed layer blob referenced by an image manifest.
+type ImageLayer struct {
+	Digest string `json:"digest"`
+	Size   int64  `json:"size"`
+}
+
+// imageManifest is the subset of Docker v2 / OCI manifests and indexes needed for sizing.
+type imageManifest struct {
+	Layers    []ImageLayer         `json:"layers"`
+	Manifests []manifestDescriptor `json:"manifests"`
+}
+
+// manifestDescriptor references a platform-specific manifest in an index.
+type manifestDescriptor struct {
+	Digest   string `json:"digest"`
+	Platform struct {
+		OS           string `json:"os"`
+		Architecture string `json:"architecture"`
+		Variant      string `json:"variant"`
+	} `json:"platform"`
+}
+
+// manifestFetcher returns the raw manifest for a tag or digest reference.
+type manifestFetcher func(ctx context.Context, reference string) ([]byte, error)
+
+// registryFetcher returns the raw content at /v2/<repository>/<endpoint>/<reference>,
+// where endpoint is "manifests", "blobs", or "referrers".
+type registryFetcher func(ctx context.Context, endpoint, reference string) ([]byte, error)
+
+// manifests fetches manifests, as a manifestFetcher.
+func (f registryFetcher) manifests(ctx context.Context, reference string) ([]byte, error) {
+	return f(ctx, "manifests", reference)
+}
+
+// compressedImageSize returns the total compressed layer size of the image at reference.
+func compressedImageSize(ctx context.Context, fetch manifestFetcher, reference string) (int64, error) {
+	layers, err := imageLayers(ctx, fetch, reference)
+	if err != nil {
+		return 0, err
+	}
+
+	var size int64
+	for _, layer := range layers {
+		size += layer.Size
+	}
+	if size == 0 {
+		return 0, fmt.Errorf("manifest for %s has no layers", reference)
+	}
+	return size, nil
+}
+
+// imageLayers returns the layers of the image at reference.
+// Multi-arch indexes are resolved to the manifest for the platform docksmith runs on,
+// which is the one Docker would pull on this host.
+func imageLayers(ctx context.Context, fetch manifestFetcher, reference string) ([]ImageLayer, error) {
+	m, err := fetchImageManifest(ctx, fetch, reference)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(m.Manifests) > 0 {
+		digest := selectPlatformManifest(m, HostArchitecture)
+		if digest == "" {
+			return nil, fmt.Errorf("no manifest for linux/%s in %s", HostArchitecture, reference)
+		}
+		if m, err = fetchImageManifest(ctx, fetch, digest); err != nil {
+			return nil, err
+		}
+	}
+
+	return m.Layers, nil
+}
+
+// fetchImageManifest fetches and decodes a manifest.
+func fetchImageManifest(ctx context.Context, fetch manifestFetcher, reference string) (*imageManifest, error) {
+	body, err := fetch(ctx, reference)
+	if err != nil {
+		return nil, err
+	}
+	return decodeImageManifest(body)
+}
+
+// decodeImageManifest decodes a raw manifest or index.
+func decodeImageManifest(body []byte) (*imageManifest, error) {
+	var m imageManifest
+	if err := json.Unmarshal(body, &m); err != nil {
+		return nil, fmt.Errorf("failed to decode manifest: %w", err)
+	}
+	return &m, nil
+}
+
+// selectPlatformManifest returns the digest of the linux manifest for arch,
+// or an empty string if the index has none.
+func selectPlatformManifest(m *imageManifest, arch string) string {
+	for _, d := range m.Manifests {
+		if d.Platform.OS == "linux" && d.Platform.Architecture == arch {
+			return d.Digest
+		}
+	}
+	return ""
+}
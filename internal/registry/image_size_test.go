@@ -0,0 +1,101 @@
+package registry
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestHTTPClientGetImageSize(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Accept") != manifestAccept {
+			t.Errorf("unexpected Accept header: %s", r.Header.Get("Accept"))
+		}
+		switch r.URL.Path {
+		case "/v2/org/app/manifests/1.2.0":
+			// Multi-arch index with an attestation manifest
+			fmt.Fprintf(w, `{"manifests": [
+				{"digest": "sha256:other", "platform": {"os": "linux", "architecture": "s390x"}},
+				{"digest": "sha256:attestation", "platform": {"os": "unknown", "architecture": "unknown"}},
+				{"digest": "sha256:native", "platform": {"os": "linux", "architecture": "%s"}}
+			]}`, runtime.GOARCH)
+		case "/v2/org/app/manifests/sha256:native":
+			w.Write([]byte(`{"config": {"size": 1000}, "layers": [{"size": 3000000}, {"size": 1500000}]}`))
+		case "/v2/org/app/manifests/1.1.0":
+			w.Write([]byte(`{"layers": [{"size": 4000000}]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewHTTPClientForRegistry(&RegistryConfig{Insecure: true}, strings.TrimPrefix(server.URL, "http://"))
+	ctx := context.Background()
+
+	size, err := client.GetImageSize(ctx, "org/app", "1.2.0")
+	if err != nil {
+		t.Fatalf("GetImageSize failed: %v", err)
+	}
+	if size != 4500000 {
+		t.Errorf("expected index to resolve to 4500000 bytes, got %d", size)
+	}
+
+	size, err = client.GetImageSize(ctx, "org/app", "1.1.0")
+	if err != nil {
+		t.Fatalf("GetImageSize failed: %v", err)
+	}
+	if size != 4000000 {
+		t.Errorf("expected 4000000 bytes, got %d", size)
+	}
+
+	if _, err := client.GetImageSize(ctx, "org/app", "missing"); err == nil {
+		t.Error("expected error for missing manifest")
+	}
+}
+
+func TestHTTPClientGetImageLayers(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/org/app/manifests/1.2.0":
+			fmt.Fprintf(w, `{"manifests": [{"digest": "sha256:native", "platform": {"os": "linux", "architecture": "%s"}}]}`, runtime.GOARCH)
+		case "/v2/org/app/manifests/sha256:native":
+			w.Write([]byte(`{"layers": [{"digest": "sha256:base", "size": 3000000}, {"digest": "sha256:app", "size": 1500000}]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewHTTPClientForRegistry(&RegistryConfig{Insecure: true}, strings.TrimPrefix(server.URL, "http://"))
+
+	layers, err := client.GetImageLayers(context.Background(), "org/app", "1.2.0")
+	if err != nil {
+		t.Fatalf("GetImageLayers failed: %v", err)
+	}
+	if len(layers) != 2 || layers[0].Digest != "sha256:base" || layers[1].Size != 1500000 {
+		t.Errorf("unexpected layers: %+v", layers)
+	}
+}
+
+func TestSelectPlatformManifest(t *testing.T) {
+	m := &imageManifest{}
+	if digest := selectPlatformManifest(m, "amd64"); digest != "" {
+		t.Errorf("expected no digest for empty index, got %s", digest)
+	}
+
+	arm := manifestDescriptor{Digest: "sha256:arm"}
+	arm.Platform.OS = "linux"
+	arm.Platform.Architecture = "arm64"
+	m.Manifests = append(m.Manifests, arm)
+
+	if digest := selectPlatformManifest(m, "arm64"); digest != "sha256:arm" {
+		t.Errorf("expected sha256:arm, got %s", digest)
+	}
+	if digest := selectPlatformManifest(m, "amd64"); digest != "" {
+		t.Errorf("expected no amd64 manifest, got %s", digest)
+	}
+}
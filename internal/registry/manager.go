@@ -3,20 +3,31 @@ package registry
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"strings"
 	"sync"
 	"time"
 )
 
-// Manager routes registry requests to the appropriate client with caching support.
+// Manager routes registry requests to the provider of each registry, selected by
+// hostname, with caching support.
 type Manager struct {
 	dockerHubClient *DockerHubClient
 	ghcrClient      *GHCRClient
-	genericClients  map[string]*HTTPClient // registry -> client
+	genericClients  map[string]Provider // registry -> provider other than Docker Hub and GHCR
 	genericClientMu sync.RWMutex
+	proxy           *ProxyConfig      // guarded by genericClientMu
+	providerNames   map[string]string // registry -> provider, overriding defaultProviders
+	gitlabToken     string
+	quayToken       string
+	harborRobot     string
+	giteaToken      string
+	tagCache        *tagCache // optional persistent tag list cache
+	pageLimits      TagPageLimits
 	cache           *RegistryCache
 	cacheEnabled    bool
 	circuitBreaker  *CircuitBreaker
+	rateLimiter     *RateLimiter
 }
 
 // NewManager creates a new registry manager.
@@ -25,10 +36,11 @@ func NewManager(githubToken string) *Manager {
 	return &Manager{
 		dockerHubClient: NewDockerHubClient(),
 		ghcrClient:      NewGHCRClient(githubToken),
-		genericClients:  make(map[string]*HTTPClient),
+		genericClients:  make(map[string]Provider),
 		cache:           NewRegistryCache(15 * time.Minute),
 		cacheEnabled:    true, // Enable caching by default
 		circuitBreaker:  NewCircuitBreaker(),
+		rateLimiter:     NewRateLimiter(DefaultRegistryRateLimit),
 	}
 }
 
@@ -54,6 +66,127 @@ func (m *Manager) ClearCache() {
 	m.cache.Clear()
 }
 
+// SetRateLimit sets the maximum requests per second sent to each registry.
+// Zero or less disables per-registry rate limiting.
+func (m *Manager) SetRateLimit(perSecond float64) {
+	m.rateLimiter.SetRate(perSecond)
+}
+
+// SetTagPageLimits caps the pages of tags fetched per repository.
+// Must be called before the manager is used.
+func (m *Manager) SetTagPageLimits(limits TagPageLimits) {
+	m.dockerHubClient.SetPageLimits(limits)
+	m.ghcrClient.SetPageLimits(limits)
+
+	m.genericClientMu.Lock()
+	defer m.genericClientMu.Unlock()
+	m.pageLimits = limits
+	for _, client := range m.genericClients {
+		if limiter, ok := client.(interface{ SetPageLimits(TagPageLimits) }); ok {
+			limiter.SetPageLimits(limits)
+		}
+	}
+}
+
+// SetRegistryProviders selects the providers of registries by hostname, e.g. of
+// self-hosted GitLab and Quay registries (see ParseRegistryProviders).
+// Must be called before the manager is used.
+func (m *Manager) SetRegistryProviders(providers map[string]string) {
+	m.genericClientMu.Lock()
+	defer m.genericClientMu.Unlock()
+	m.providerNames = providers
+	m.genericClients = make(map[string]Provider)
+}
+
+// SetGitLabToken authenticates requests to GitLab registries with an access token,
+// or "username:token" for deploy tokens. Must be called before the manager is used.
+func (m *Manager) SetGitLabToken(token string) {
+	m.genericClientMu.Lock()
+	defer m.genericClientMu.Unlock()
+	m.gitlabToken = token
+	m.genericClients = make(map[string]Provider)
+}
+
+// SetQuayToken authenticates requests to Quay's API with an OAuth application token.
+// Must be called before the manager is used.
+func (m *Manager) SetQuayToken(token string) {
+	m.genericClientMu.Lock()
+	defer m.genericClientMu.Unlock()
+	m.quayToken = token
+	m.genericClients = make(map[string]Provider)
+}
+
+// SetHarborRobot authenticates requests to Harbor registries as a robot account,
+// "name:secret". Must be called before the manager is used.
+func (m *Manager) SetHarborRobot(robot string) {
+	m.genericClientMu.Lock()
+	defer m.genericClientMu.Unlock()
+	m.harborRobot = robot
+	m.genericClients = make(map[string]Provider)
+}
+
+// SetGiteaToken authenticates requests to Gitea and Forgejo registries with an
+// access token, or "username:password". Must be called before the manager is used.
+func (m *Manager) SetGiteaToken(token string) {
+	m.genericClientMu.Lock()
+	defer m.genericClientMu.Unlock()
+	m.giteaToken = token
+	m.genericClients = make(map[string]Provider)
+}
+
+// ProviderName returns the provider of a registry, e.g. ProviderQuay for "quay.io".
+func (m *Manager) ProviderName(registry string) string {
+	return m.getClient(registry).Name()
+}
+
+// SetTagCacheStore persists tag lists in store, so they survive restarts and are
+// revalidated with conditional requests (ETag / Last-Modified) once older than ttl.
+// Must be called before the manager is used.
+func (m *Manager) SetTagCacheStore(store TagCacheStore, ttl time.Duration) {
+	if ttl <= 0 {
+		ttl = DefaultTagCacheTTL
+	}
+	m.tagCache = &tagCache{store: store, ttl: ttl}
+}
+
+// InvalidateTagCache makes the next lookup of every tag list revalidate with the
+// registry, as for a forced check.
+func (m *Manager) InvalidateTagCache() {
+	if m.tagCache != nil {
+		m.tagCache.invalidate()
+	}
+}
+
+// SetQuotaStore persists the Docker Hub rate limit quota in store, restoring the
+// last known quota so an exhausted budget is respected across restarts.
+func (m *Manager) SetQuotaStore(ctx context.Context, store QuotaStore) {
+	m.dockerHubClient.Quota().SetStore(ctx, store)
+}
+
+// DockerHubQuota returns the last rate limit quota reported by Docker Hub.
+func (m *Manager) DockerHubQuota() (RateLimitStatus, bool) {
+	return m.dockerHubClient.Quota().Status()
+}
+
+// QuotaLow reports whether the registry serving imageRef is close to its rate limit,
+// so callers can defer checks that are not urgent.
+func (m *Manager) QuotaLow(imageRef string) bool {
+	registry, _ := m.parseImageRef(imageRef)
+	return registry == "docker.io" && m.dockerHubClient.Quota().Low()
+}
+
+// SetProxyConfig routes registry requests through the configured proxies.
+// Must be called before the manager is used; existing generic clients are replaced.
+func (m *Manager) SetProxyConfig(cfg *ProxyConfig) {
+	m.dockerHubClient.SetProxy(cfg.ProxyFunc("docker.io"))
+	m.ghcrClient.SetProxy(cfg.ProxyFunc("ghcr.io"))
+
+	m.genericClientMu.Lock()
+	defer m.genericClientMu.Unlock()
+	m.proxy = cfg
+	m.genericClients = make(map[string]Provider)
+}
+
 // GetCircuitBreakerState returns the current state of the circuit breaker for a registry.
 func (m *Manager) GetCircuitBreakerState(registry string) CircuitState {
 	return m.circuitBreaker.GetState(registry)
@@ -70,6 +203,60 @@ func (m *Manager) ResetAllCircuitBreakers() {
 	m.circuitBreaker.ResetAll()
 }
 
+// Registry returns the registry an image reference is pulled from, e.g. "docker.io" for "nginx:latest".
+func (m *Manager) Registry(imageRef string) string {
+	registry, _ := m.parseImageRef(imageRef)
+	return registry
+}
+
+// PullCredentials returns the credentials for pulling an image from a registry
+// whose logins expire, ECR or a Google registry, for the Docker daemon, which
+// cannot renew them itself. It returns none for other registries.
+func (m *Manager) PullCredentials(ctx context.Context, imageRef string) (username, password string, err error) {
+	registry, _ := m.parseImageRef(imageRef)
+	switch client := m.getClient(registry).(type) {
+	case *ECRClient:
+		return client.credentials(ctx)
+	case *GARClient:
+		return client.credentials(ctx)
+	}
+	return "", "", nil
+}
+
+// Ping checks that a registry's V2 API answers, through the registry's proxy.
+// Any response below 500 counts, since most registries answer 401 until a token is presented.
+func (m *Manager) Ping(ctx context.Context, registry string) error {
+	m.genericClientMu.RLock()
+	proxy := m.proxy.ProxyFunc(registry)
+	m.genericClientMu.RUnlock()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, pingURL(registry), nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	client := &http.Client{
+		Timeout:   DefaultTimeoutSeconds * time.Second,
+		Transport: newProxyTransport(proxy),
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach %s: %w", registry, err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 500 {
+		return fmt.Errorf("%s returned status %d", registry, resp.StatusCode)
+	}
+	return nil
+}
+
+// pingURL returns the V2 API base URL of a registry.
+func pingURL(registry string) string {
+	if registry == "docker.io" {
+		registry = "registry-1.docker.io"
+	}
+	return "https://" + registry + "/v2/"
+}
+
 // withCache is a generic cache wrapper that handles the check-fetch-store pattern.
 // It checks the cache first, calls the fetch function if not found, and stores the result.
 func withCache[T any](m *Manager, cacheKey string, ttl time.Duration, isEmpty func(T) bool, fetch func() (T, error)) (T, error) {
@@ -102,11 +289,23 @@ func withCache[T any](m *Manager, cacheKey string, ttl time.Duration, isEmpty fu
 	return result, nil
 }
 
-// withCircuitBreaker wraps a registry call with circuit breaker protection.
-// It checks if the circuit allows the request, executes it, and records the result.
-func withCircuitBreaker[T any](m *Manager, registry string, fetch func() (T, error)) (T, error) {
+// withCircuitBreaker wraps a registry call with circuit breaker protection and
+// per-registry rate limiting. It waits for a rate limit slot, checks if the circuit
+// allows the request, executes it, and records the result. Docker Hub requests
+// fail fast while its quota is exhausted.
+func withCircuitBreaker[T any](ctx context.Context, m *Manager, registry string, fetch func() (T, error)) (T, error) {
 	var zero T
 
+	if registry == "docker.io" {
+		if err := m.dockerHubClient.Quota().checkExhausted(); err != nil {
+			return zero, err
+		}
+	}
+
+	if err := m.rateLimiter.Wait(ctx, registry); err != nil {
+		return zero, err
+	}
+
 	// Check if circuit breaker allows this request
 	if !m.circuitBreaker.Allow(registry) {
 		return zero, fmt.Errorf("%w: %s", ErrCircuitOpen, registry)
@@ -129,14 +328,31 @@ func withCircuitBreaker[T any](m *Manager, registry string, fetch func() (T, err
 //   - "docker.io/library/nginx"
 //   - "ghcr.io/linuxserver/plex"
 //   - "linuxserver/plex" (assumes docker.io)
+//
+// With a tag cache store, lists are persisted and revalidated with conditional requests,
+// and contexts from WithTagListStop may stop paginating early. Without one, lists
+// are always fetched in full. Contexts from WithCacheBypass skip cached data.
 func (m *Manager) ListTags(ctx context.Context, imageRef string) ([]string, error) {
 	registry, repository := m.parseImageRef(imageRef)
 	client := m.getClient(registry)
 
+	if m.tagCache != nil && m.cacheEnabled {
+		return m.tagCache.listTags(ctx, imageRef, func(validators CacheValidators) (tagListing, error) {
+			return withCircuitBreaker(ctx, m, registry, func() (tagListing, error) {
+				return listTagsConditional(ctx, client, repository, validators)
+			})
+		})
+	}
+	ctx = withoutTagListStop(ctx)
+
+	if cacheBypassed(ctx) {
+		m.cache.Delete(fmt.Sprintf("tags:%s", imageRef))
+	}
+
 	return withCache(m, fmt.Sprintf("tags:%s", imageRef), 0,
 		func(tags []string) bool { return len(tags) == 0 },
 		func() ([]string, error) {
-			return withCircuitBreaker(m, registry, func() ([]string, error) {
+			return withCircuitBreaker(ctx, m, registry, func() ([]string, error) {
 				return client.ListTags(ctx, repository)
 			})
 		},
@@ -151,7 +367,7 @@ func (m *Manager) GetLatestTag(ctx context.Context, imageRef string) (string, er
 	return withCache(m, fmt.Sprintf("latest:%s", imageRef), 0,
 		func(tag string) bool { return tag == "" },
 		func() (string, error) {
-			return withCircuitBreaker(m, registry, func() (string, error) {
+			return withCircuitBreaker(ctx, m, registry, func() (string, error) {
 				return client.GetLatestTag(ctx, repository)
 			})
 		},
@@ -168,7 +384,7 @@ func (m *Manager) GetTagDigest(ctx context.Context, imageRef, tag string) (strin
 	return withCache(m, fmt.Sprintf("digest:%s:%s", imageRef, tag), 5*time.Minute,
 		func(digest string) bool { return digest == "" },
 		func() (string, error) {
-			return withCircuitBreaker(m, registry, func() (string, error) {
+			return withCircuitBreaker(ctx, m, registry, func() (string, error) {
 				return client.GetTagDigest(ctx, repo, tag)
 			})
 		},
@@ -176,7 +392,7 @@ func (m *Manager) GetTagDigest(ctx context.Context, imageRef, tag string) (strin
 }
 
 // ListTagsWithDigests returns a mapping of tags to their digests.
-// Uses efficient APIs (Docker Hub includes digests in tag list, GHCR uses Packages API).
+// Uses efficient APIs (Docker Hub and Quay include digests in tag lists, GHCR uses Packages API).
 func (m *Manager) ListTagsWithDigests(ctx context.Context, imageRef string) (map[string][]string, error) {
 	registry, repository := m.parseImageRef(imageRef)
 	client := m.getClient(registry)
@@ -184,13 +400,110 @@ func (m *Manager) ListTagsWithDigests(ctx context.Context, imageRef string) (map
 	return withCache(m, fmt.Sprintf("tags-digests:%s", imageRef), 0,
 		func(tagDigests map[string][]string) bool { return len(tagDigests) == 0 },
 		func() (map[string][]string, error) {
-			return withCircuitBreaker(m, registry, func() (map[string][]string, error) {
+			return withCircuitBreaker(ctx, m, registry, func() (map[string][]string, error) {
 				return client.ListTagsWithDigests(ctx, repository)
 			})
 		},
 	)
 }
 
+// GetImageSize returns the compressed size in bytes of an image tag or digest with caching support.
+func (m *Manager) GetImageSize(ctx context.Context, imageRef, reference string) (int64, error) {
+	registry, repo := m.parseImageRef(imageRef)
+	client := m.getClient(registry)
+
+	// Digests are immutable; tags use the shorter digest TTL since they can move
+	ttl := 5 * time.Minute
+	if strings.HasPrefix(reference, "sha256:") {
+		ttl = 0
+	}
+
+	return withCache(m, fmt.Sprintf("size:%s:%s", imageRef, reference), ttl,
+		func(size int64) bool { return size == 0 },
+		func() (int64, error) {
+			return withCircuitBreaker(ctx, m, registry, func() (int64, error) {
+				return client.GetImageSize(ctx, repo, reference)
+			})
+		},
+	)
+}
+
+// GetImageLayers returns the compressed layers of an image tag or digest with caching support.
+func (m *Manager) GetImageLayers(ctx context.Context, imageRef, reference string) ([]ImageLayer, error) {
+	registry, repo := m.parseImageRef(imageRef)
+	client := m.getClient(registry)
+
+	ttl := 5 * time.Minute
+	if strings.HasPrefix(reference, "sha256:") {
+		ttl = 0
+	}
+
+	return withCache(m, fmt.Sprintf("layers:%s:%s", imageRef, reference), ttl,
+		func(layers []ImageLayer) bool { return len(layers) == 0 },
+		func() ([]ImageLayer, error) {
+			return withCircuitBreaker(ctx, m, registry, func() ([]ImageLayer, error) {
+				return client.GetImageLayers(ctx, repo, reference)
+			})
+		},
+	)
+}
+
+// GetImagePlatforms returns the platforms of a multi-arch image tag or digest with caching support.
+func (m *Manager) GetImagePlatforms(ctx context.Context, imageRef, reference string) ([]string, error) {
+	registry, repo := m.parseImageRef(imageRef)
+	client := m.getClient(registry)
+
+	ttl := 5 * time.Minute
+	if strings.HasPrefix(reference, "sha256:") {
+		ttl = 0
+	}
+
+	return withCache(m, fmt.Sprintf("platforms:%s:%s", imageRef, reference), ttl,
+		func(platforms []string) bool { return len(platforms) == 0 },
+		func() ([]string, error) {
+			return withCircuitBreaker(ctx, m, registry, func() ([]string, error) {
+				return client.GetImagePlatforms(ctx, repo, reference)
+			})
+		},
+	)
+}
+
+// GetPlatformDigest returns the host platform's manifest digest for a tag or digest with caching support.
+func (m *Manager) GetPlatformDigest(ctx context.Context, imageRef, reference string) (string, error) {
+	registry, repo := m.parseImageRef(imageRef)
+	client := m.getClient(registry)
+
+	ttl := 5 * time.Minute
+	if strings.HasPrefix(reference, "sha256:") {
+		ttl = 0
+	}
+
+	return withCache(m, fmt.Sprintf("platform-digest:%s:%s:%s", imageRef, reference, HostArchitecture), ttl,
+		func(digest string) bool { return digest == "" },
+		func() (string, error) {
+			return withCircuitBreaker(ctx, m, registry, func() (string, error) {
+				return client.GetPlatformDigest(ctx, repo, reference)
+			})
+		},
+	)
+}
+
+// GetSignatures returns the cosign signatures and attestations of an image digest with caching support.
+func (m *Manager) GetSignatures(ctx context.Context, imageRef, digest string) (*ImageSignatures, error) {
+	registry, repo := m.parseImageRef(imageRef)
+	client := m.getClient(registry)
+
+	// Signatures can be added to an existing digest, so they are cached briefly
+	return withCache(m, fmt.Sprintf("signatures:%s:%s", imageRef, digest), 5*time.Minute,
+		func(sigs *ImageSignatures) bool { return sigs == nil },
+		func() (*ImageSignatures, error) {
+			return withCircuitBreaker(ctx, m, registry, func() (*ImageSignatures, error) {
+				return client.GetSignatures(ctx, repo, digest)
+			})
+		},
+	)
+}
+
 // GetGhostTags returns Docker Hub tags that have no published images for a given image.
 // Returns nil for non-Docker Hub images (GHCR, etc. don't have ghost tags).
 func (m *Manager) GetGhostTags(imageRef string) []string {
@@ -223,21 +536,22 @@ func (m *Manager) parseImageRef(imageRef string) (registry, repository string) {
 	}
 }
 
-// getClient returns the appropriate client for the given registry.
-func (m *Manager) getClient(registry string) Client {
+// getClient returns the provider of the given registry.
+func (m *Manager) getClient(registry string) Provider {
 	switch registry {
 	case "docker.io":
 		return m.dockerHubClient
 	case "ghcr.io":
 		return m.ghcrClient
 	default:
-		// Use registry-specific generic V2 API client
+		// Provider selected by hostname or REGISTRY_PROVIDERS
 		return m.getOrCreateGenericClient(registry)
 	}
 }
 
-// getOrCreateGenericClient returns or creates a generic client for the specified registry.
-func (m *Manager) getOrCreateGenericClient(registry string) *HTTPClient {
+// getOrCreateGenericClient returns or creates the client of a registry other than
+// Docker Hub and GHCR.
+func (m *Manager) getOrCreateGenericClient(registry string) Provider {
 	// Fast path: check if client exists
 	m.genericClientMu.RLock()
 	client, exists := m.genericClients[registry]
@@ -256,7 +570,45 @@ func (m *Manager) getOrCreateGenericClient(registry string) *HTTPClient {
 	}
 
 	// Create new registry-specific client
-	client = NewHTTPClientForRegistry(nil, registry)
+	config := &RegistryConfig{TimeoutSeconds: DefaultTimeoutSeconds}
+	if m.proxy != nil {
+		config.Proxy = m.proxy.ProxyFunc(registry)
+	}
+	provider, ok := m.providerNames[registry]
+	if !ok {
+		provider = providerForHost(registry)
+	}
+	switch provider {
+	case ProviderGitLab:
+		gitlab := NewGitLabClient(config, registry, m.gitlabToken)
+		gitlab.SetPageLimits(m.pageLimits)
+		client = gitlab
+	case ProviderQuay:
+		quay := NewQuayClient(config, registry, m.quayToken)
+		quay.SetPageLimits(m.pageLimits)
+		client = quay
+	case ProviderHarbor:
+		harbor := NewHarborClient(config, registry, m.harborRobot)
+		harbor.SetPageLimits(m.pageLimits)
+		client = harbor
+	case ProviderGitea:
+		gitea := NewGiteaClient(config, registry, m.giteaToken)
+		gitea.SetPageLimits(m.pageLimits)
+		client = gitea
+	case ProviderECR:
+		ecr := NewECRClient(config, registry)
+		ecr.SetPageLimits(m.pageLimits)
+		client = ecr
+	case ProviderGAR:
+		gar := NewGARClient(config, registry)
+		gar.SetPageLimits(m.pageLimits)
+		client = gar
+	default:
+		config.Username, config.Password = dockerConfigCredentials(registry)
+		oci := NewHTTPClientForRegistry(config, registry)
+		oci.SetPageLimits(m.pageLimits)
+		client = oci
+	}
 	m.genericClients[registry] = client
 	return client
 }
\ No newline at end of file
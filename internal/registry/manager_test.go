@@ -123,3 +123,16 @@ func TestListTagsGHCR(t *testing.T) {
 
 	t.Logf("Found %d tags for linuxserver/plex", len(tags))
 }
+
+func TestPingURL(t *testing.T) {
+	tests := map[string]string{
+		"docker.io":           "https://registry-1.docker.io/v2/",
+		"ghcr.io":             "https://ghcr.io/v2/",
+		"registry.local:5000": "https://registry.local:5000/v2/",
+	}
+	for registry, want := range tests {
+		if got := pingURL(registry); got != want {
+			t.Errorf("pingURL(%q) = %q, want %q", registry, got, want)
+		}
+	}
+}
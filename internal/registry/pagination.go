@@ -0,0 +1,132 @@
+package registry
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	// tagPageParallelism is how many pages of a Docker Hub tag list are fetched at once.
+	tagPageParallelism = 3
+
+	// defaultMaxTagPages bounds the pages followed for generic V2 registries.
+	defaultMaxTagPages = 50
+)
+
+// TagPageLimits caps how many pages of tags are fetched per repository, for
+// repositories with thousands of tags.
+type TagPageLimits struct {
+	// Default applies to every repository; 0 keeps each registry's own limit.
+	Default int
+
+	// Repositories maps "registry/repository" (e.g., "docker.io/library/postgres")
+	// to the pages fetched for it, overriding Default.
+	Repositories map[string]int
+}
+
+// ParseTagPageLimits parses a comma-separated list of page limits: a plain number
+// sets the default, registry/repository=number entries set the limit of one
+// repository ("10,docker.io/library/postgres=3").
+func ParseTagPageLimits(s string) (TagPageLimits, error) {
+	limits := TagPageLimits{Repositories: make(map[string]int)}
+	for _, entry := range splitList(s) {
+		repository, value, ok := strings.Cut(entry, "=")
+		if !ok {
+			repository, value = "", entry
+		}
+		pages, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil || pages < 1 {
+			return TagPageLimits{}, fmt.Errorf("invalid page limit %q: must be a positive number", entry)
+		}
+		repository = strings.ToLower(strings.TrimSpace(repository))
+		if !ok {
+			limits.Default = pages
+			continue
+		}
+		if !strings.Contains(repository, "/") {
+			return TagPageLimits{}, fmt.Errorf("invalid page limit %q: expected registry/repository=pages", entry)
+		}
+		limits.Repositories[repository] = pages
+	}
+	return limits, nil
+}
+
+// pages returns the page limit of repository on registry, or fallback when none is set.
+func (l TagPageLimits) pages(registry, repository string, fallback int) int {
+	if pages, ok := l.Repositories[strings.ToLower(registry+"/"+repository)]; ok {
+		return pages
+	}
+	if l.Default > 0 {
+		return l.Default
+	}
+	return fallback
+}
+
+type tagListStopKey struct{}
+
+// tagListStop decides when a paginated tag listing has enough tags, and records
+// whether it stopped before the last page.
+type tagListStop struct {
+	enough  func(tags []string) bool
+	partial bool
+}
+
+// WithTagListStop returns a context whose tag listings stop paginating once enough
+// reports that the tags listed so far suffice, e.g. because they include the running
+// tag and several newer versions. Only Docker Hub and Quay, which list the most
+// recently pushed tags first, stop early, and only with the persistent tag cache:
+// lists cut short are stored as partial and reused only by listings they are
+// enough for.
+func WithTagListStop(ctx context.Context, enough func(tags []string) bool) context.Context {
+	return context.WithValue(ctx, tagListStopKey{}, &tagListStop{enough: enough})
+}
+
+// HasTagListStop reports whether tag listings of ctx may stop paginating early.
+func HasTagListStop(ctx context.Context) bool {
+	stop := tagListStopFrom(ctx)
+	return stop != nil && stop.enough != nil
+}
+
+// tagListStopFrom returns the stop condition of ctx, or nil if it has none.
+func tagListStopFrom(ctx context.Context) *tagListStop {
+	stop, _ := ctx.Value(tagListStopKey{}).(*tagListStop)
+	return stop
+}
+
+// withOwnTagListStop gives a listing its own copy of the stop condition of ctx,
+// so whether it stopped early is not shared with other listings using ctx.
+func withOwnTagListStop(ctx context.Context) (context.Context, *tagListStop) {
+	stop := tagListStopFrom(ctx)
+	if stop == nil {
+		return ctx, nil
+	}
+	own := &tagListStop{enough: stop.enough}
+	return context.WithValue(ctx, tagListStopKey{}, own), own
+}
+
+// withoutTagListStop removes the stop condition of ctx, for listings whose result
+// is cached where partial lists cannot be told apart.
+func withoutTagListStop(ctx context.Context) context.Context {
+	if tagListStopFrom(ctx) == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, tagListStopKey{}, (*tagListStop)(nil))
+}
+
+// done reports whether a listing with more pages left can stop with tags, marking
+// it partial if so. A nil stop never stops.
+func (s *tagListStop) done(tags []string) bool {
+	if s == nil || s.enough == nil || !s.enough(tags) {
+		return false
+	}
+	s.partial = true
+	return true
+}
+
+// satisfiedBy reports whether a partial list is enough for the listing of ctx.
+func satisfiedBy(ctx context.Context, tags []string) bool {
+	stop := tagListStopFrom(ctx)
+	return stop != nil && stop.enough != nil && stop.enough(tags)
+}
@@ -0,0 +1,217 @@
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/chis/docksmith/internal/storage"
+)
+
+func TestParseTagPageLimits(t *testing.T) {
+	limits, err := ParseTagPageLimits("10, docker.io/library/postgres=3,ghcr.io/org/app=1")
+	if err != nil {
+		t.Fatalf("ParseTagPageLimits failed: %v", err)
+	}
+	if got := limits.pages("docker.io", "library/postgres", 5); got != 3 {
+		t.Errorf("postgres pages = %d, want 3", got)
+	}
+	if got := limits.pages("ghcr.io", "org/app", 2); got != 1 {
+		t.Errorf("org/app pages = %d, want 1", got)
+	}
+	if got := limits.pages("docker.io", "library/nginx", 5); got != 10 {
+		t.Errorf("nginx pages = %d, want the default 10", got)
+	}
+	if got := (TagPageLimits{}).pages("docker.io", "library/nginx", 5); got != 5 {
+		t.Errorf("unset pages = %d, want the fallback 5", got)
+	}
+
+	for _, invalid := range []string{"0", "abc", "postgres=3", "docker.io/library/postgres=-1"} {
+		if _, err := ParseTagPageLimits(invalid); err == nil {
+			t.Errorf("expected %q to be rejected", invalid)
+		}
+	}
+}
+
+// newPagedDockerHub serves a Docker Hub repository with total tags, named 1.0.0,
+// 1.0.1, ..., 100 per page, and records the pages requested.
+func newPagedDockerHub(t *testing.T, total int) (*DockerHubClient, func() []int) {
+	var mu sync.Mutex
+	var requested []int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		mu.Lock()
+		requested = append(requested, page)
+		mu.Unlock()
+
+		resp := dockerHubTagsResponse{Count: total}
+		for i := (page - 1) * dockerHubPageSize; i < min(page*dockerHubPageSize, total); i++ {
+			resp.Results = append(resp.Results, dockerHubTag{
+				Name:   fmt.Sprintf("1.0.%d", i),
+				Images: []dockerHubImage{{Architecture: "amd64"}},
+			})
+		}
+		if len(resp.Results) == 0 {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode(resp)
+	}))
+	t.Cleanup(server.Close)
+
+	client := NewDockerHubClient()
+	client.rateLimiter.Reset(time.Millisecond)
+	client.hubURL = server.URL
+	client.SetPageLimits(TagPageLimits{Default: 10})
+	t.Cleanup(client.Close)
+
+	return client, func() []int {
+		mu.Lock()
+		defer mu.Unlock()
+		pages := slices.Clone(requested)
+		slices.Sort(pages)
+		return pages
+	}
+}
+
+func TestDockerHubListTags_FetchesPagesInParallel(t *testing.T) {
+	client, requested := newPagedDockerHub(t, 450)
+
+	tags, err := client.ListTags(context.Background(), "library/postgres")
+	if err != nil {
+		t.Fatalf("ListTags failed: %v", err)
+	}
+	if len(tags) != 450 || tags[0] != "1.0.0" || tags[449] != "1.0.449" {
+		t.Errorf("expected 450 tags in page order, got %d (%s ... %s)", len(tags), tags[0], tags[len(tags)-1])
+	}
+	if got := requested(); !slices.Equal(got, []int{1, 2, 3, 4, 5}) {
+		t.Errorf("requested pages %v, want 1-5", got)
+	}
+}
+
+func TestDockerHubListTags_PageLimit(t *testing.T) {
+	client, requested := newPagedDockerHub(t, 450)
+	client.SetPageLimits(TagPageLimits{Repositories: map[string]int{"docker.io/library/postgres": 2}})
+
+	tags, err := client.ListTags(context.Background(), "library/postgres")
+	if err != nil {
+		t.Fatalf("ListTags failed: %v", err)
+	}
+	if len(tags) != 200 {
+		t.Errorf("expected 200 tags from 2 pages, got %d", len(tags))
+	}
+	if got := requested(); !slices.Equal(got, []int{1, 2}) {
+		t.Errorf("requested pages %v, want 1-2", got)
+	}
+}
+
+func TestDockerHubListTags_StopsEarly(t *testing.T) {
+	client, requested := newPagedDockerHub(t, 900)
+	ctx := WithTagListStop(context.Background(), func(tags []string) bool {
+		return slices.Contains(tags, "1.0.150")
+	})
+
+	listing, err := listTagsConditional(ctx, client, "library/postgres", CacheValidators{})
+	if err != nil {
+		t.Fatalf("listTagsConditional failed: %v", err)
+	}
+	// Page 1 lacks 1.0.150, the wave of pages 2-4 has it
+	if len(listing.tags) != 400 || !listing.partial {
+		t.Errorf("expected a partial list of 400 tags, got %d (partial=%v)", len(listing.tags), listing.partial)
+	}
+	if got := requested(); !slices.Equal(got, []int{1, 2, 3, 4}) {
+		t.Errorf("requested pages %v, want 1-4", got)
+	}
+
+	// A stop condition met only by the complete list is not partial
+	ctx = WithTagListStop(context.Background(), func(tags []string) bool { return false })
+	listing, err = listTagsConditional(ctx, client, "library/postgres", CacheValidators{})
+	if err != nil {
+		t.Fatalf("listTagsConditional failed: %v", err)
+	}
+	if len(listing.tags) != 900 || listing.partial {
+		t.Errorf("expected the complete list, got %d tags (partial=%v)", len(listing.tags), listing.partial)
+	}
+}
+
+func TestHTTPClientListTags_FollowsLinkPages(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("last") {
+		case "":
+			w.Header().Set("Link", `</v2/org/app/tags/list?last=1.1.0&n=2>; rel="next"`)
+			w.Write([]byte(`{"name": "org/app", "tags": ["1.0.0", "1.1.0"]}`))
+		case "1.1.0":
+			w.Header().Set("Link", `</v2/org/app/tags/list?last=1.3.0&n=2>; rel="next"`)
+			w.Write([]byte(`{"name": "org/app", "tags": ["1.2.0", "1.3.0"]}`))
+		default:
+			w.Write([]byte(`{"name": "org/app", "tags": ["1.4.0"]}`))
+		}
+	}))
+	defer server.Close()
+
+	host := strings.TrimPrefix(server.URL, "http://")
+	client := NewHTTPClientForRegistry(&RegistryConfig{Insecure: true}, host)
+	tags, err := client.ListTags(context.Background(), "org/app")
+	if err != nil {
+		t.Fatalf("ListTags failed: %v", err)
+	}
+	if strings.Join(tags, ",") != "1.0.0,1.1.0,1.2.0,1.3.0,1.4.0" {
+		t.Errorf("unexpected tags: %v", tags)
+	}
+
+	client.SetPageLimits(TagPageLimits{Repositories: map[string]int{host + "/org/app": 2}})
+	tags, err = client.ListTags(context.Background(), "org/app")
+	if err != nil {
+		t.Fatalf("ListTags failed: %v", err)
+	}
+	if len(tags) != 4 {
+		t.Errorf("expected 4 tags from 2 pages, got %v", tags)
+	}
+}
+
+func TestTagCacheListTags_PartialLists(t *testing.T) {
+	store := newMemoryTagStore()
+	cache := &tagCache{store: store, ttl: time.Hour}
+	store.entries["docker.io/library/postgres"] = storage.TagCacheEntry{
+		ImageRef:  "docker.io/library/postgres",
+		Tags:      []string{"17.1", "17.0", "16.5"},
+		ETag:      `"v1"`,
+		Partial:   true,
+		FetchedAt: time.Now(),
+	}
+
+	var calls int
+	var gotValidators CacheValidators
+	fetch := func(v CacheValidators) (tagListing, error) {
+		calls++
+		gotValidators = v
+		return tagListing{tags: []string{"17.1", "17.0", "16.5", "16.4", "15.9"}}, nil
+	}
+
+	// A partial list enough for the listing is used
+	enough := WithTagListStop(context.Background(), func(tags []string) bool { return slices.Contains(tags, "16.5") })
+	tags, err := cache.listTags(enough, "docker.io/library/postgres", fetch)
+	if err != nil || len(tags) != 3 || calls != 0 {
+		t.Fatalf("expected the partial list without fetching, got %v (calls=%d, err=%v)", tags, calls, err)
+	}
+
+	// Without a stop condition, or one the list does not meet, it is refetched in full
+	tags, err = cache.listTags(context.Background(), "docker.io/library/postgres", fetch)
+	if err != nil || len(tags) != 5 || calls != 1 {
+		t.Fatalf("expected the full list to be fetched, got %v (calls=%d, err=%v)", tags, calls, err)
+	}
+	if gotValidators.ETag != "" {
+		t.Errorf("a partial list must not be revalidated, got validators %+v", gotValidators)
+	}
+	if entry := store.entries["docker.io/library/postgres"]; entry.Partial || len(entry.Tags) != 5 {
+		t.Errorf("expected the full list to replace the partial one, got %+v", entry)
+	}
+}
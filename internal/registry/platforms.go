@@ -0,0 +1,76 @@
+package registry
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"runtime"
+	"strings"
+)
+
+// HostArchitecture is the architecture images must support to run on this host.
+// Docksmith runs on the same host as the containers it updates.
+var HostArchitecture = runtime.GOARCH
+
+// imagePlatforms returns the platforms ("os/arch" or "os/arch/variant") of a
+// multi-arch index. Single-platform manifests do not name their platform, so
+// they return nil.
+func imagePlatforms(ctx context.Context, fetch manifestFetcher, reference string) ([]string, error) {
+	m, err := fetchImageManifest(ctx, fetch, reference)
+	if err != nil {
+		return nil, err
+	}
+
+	var platforms []string
+	for _, d := range m.Manifests {
+		// Attestation manifests are listed with an unknown platform
+		if d.Platform.OS == "" || d.Platform.OS == "unknown" {
+			continue
+		}
+		platform := d.Platform.OS + "/" + d.Platform.Architecture
+		if d.Platform.Variant != "" {
+			platform += "/" + d.Platform.Variant
+		}
+		platforms = append(platforms, platform)
+	}
+	return platforms, nil
+}
+
+// platformDigest returns the digest of the manifest Docker pulls for reference on
+// this host: the child manifest for the host architecture of a multi-arch index,
+// or the manifest itself for a single-platform image. A manifest's digest is the
+// SHA256 of its content.
+func platformDigest(ctx context.Context, fetch manifestFetcher, reference string) (string, error) {
+	body, err := fetch(ctx, reference)
+	if err != nil {
+		return "", err
+	}
+	m, err := decodeImageManifest(body)
+	if err != nil {
+		return "", err
+	}
+
+	if len(m.Manifests) > 0 {
+		digest := selectPlatformManifest(m, HostArchitecture)
+		if digest == "" {
+			return "", fmt.Errorf("no manifest for linux/%s in %s", HostArchitecture, reference)
+		}
+		return digest, nil
+	}
+	return fmt.Sprintf("sha256:%x", sha256.Sum256(body)), nil
+}
+
+// SupportsArchitecture reports whether platforms include a linux image for arch.
+// An empty list means the platform is unknown and is assumed to be supported.
+func SupportsArchitecture(platforms []string, arch string) bool {
+	if len(platforms) == 0 {
+		return true
+	}
+	for _, platform := range platforms {
+		parts := strings.Split(platform, "/")
+		if len(parts) >= 2 && parts[0] == "linux" && parts[1] == arch {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,118 @@
+package registry
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHTTPClientGetImagePlatforms(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/org/app/manifests/1.2.0":
+			w.Write([]byte(`{"manifests": [
+				{"digest": "sha256:amd", "platform": {"os": "linux", "architecture": "amd64"}},
+				{"digest": "sha256:arm", "platform": {"os": "linux", "architecture": "arm", "variant": "v7"}},
+				{"digest": "sha256:attestation", "platform": {"os": "unknown", "architecture": "unknown"}}
+			]}`))
+		case "/v2/org/app/manifests/1.1.0":
+			w.Write([]byte(`{"layers": [{"size": 4000000}]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewHTTPClientForRegistry(&RegistryConfig{Insecure: true}, strings.TrimPrefix(server.URL, "http://"))
+	ctx := context.Background()
+
+	platforms, err := client.GetImagePlatforms(ctx, "org/app", "1.2.0")
+	if err != nil {
+		t.Fatalf("GetImagePlatforms failed: %v", err)
+	}
+	if strings.Join(platforms, ",") != "linux/amd64,linux/arm/v7" {
+		t.Errorf("unexpected platforms: %v", platforms)
+	}
+
+	platforms, err = client.GetImagePlatforms(ctx, "org/app", "1.1.0")
+	if err != nil {
+		t.Fatalf("GetImagePlatforms failed: %v", err)
+	}
+	if platforms != nil {
+		t.Errorf("expected no platforms for a single-platform manifest, got %v", platforms)
+	}
+}
+
+func TestSupportsArchitecture(t *testing.T) {
+	platforms := []string{"linux/amd64", "linux/arm/v7", "windows/arm64"}
+
+	tests := []struct {
+		arch string
+		want bool
+	}{
+		{"amd64", true},
+		{"arm", true},
+		{"arm64", false}, // Only a windows image
+		{"s390x", false},
+	}
+	for _, tt := range tests {
+		if got := SupportsArchitecture(platforms, tt.arch); got != tt.want {
+			t.Errorf("SupportsArchitecture(%q) = %v, want %v", tt.arch, got, tt.want)
+		}
+	}
+
+	if !SupportsArchitecture(nil, "arm64") {
+		t.Error("unknown platforms should be assumed supported")
+	}
+}
+
+func TestHTTPClientGetPlatformDigest(t *testing.T) {
+	single := `{"layers": [{"size": 4000000}]}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/org/app/manifests/1.2.0":
+			w.Write([]byte(`{"manifests": [
+				{"digest": "sha256:amd", "platform": {"os": "linux", "architecture": "amd64"}},
+				{"digest": "sha256:arm", "platform": {"os": "linux", "architecture": "arm64", "variant": "v8"}}
+			]}`))
+		case "/v2/org/app/manifests/1.1.0":
+			w.Write([]byte(single))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	previous := HostArchitecture
+	HostArchitecture = "arm64"
+	defer func() { HostArchitecture = previous }()
+
+	client := NewHTTPClientForRegistry(&RegistryConfig{Insecure: true}, strings.TrimPrefix(server.URL, "http://"))
+	ctx := context.Background()
+
+	digest, err := client.GetPlatformDigest(ctx, "org/app", "1.2.0")
+	if err != nil {
+		t.Fatalf("GetPlatformDigest failed: %v", err)
+	}
+	if digest != "sha256:arm" {
+		t.Errorf("expected the arm64 child manifest, got %s", digest)
+	}
+
+	// Single-platform manifests are addressed by the digest of their content
+	digest, err = client.GetPlatformDigest(ctx, "org/app", "1.1.0")
+	if err != nil {
+		t.Fatalf("GetPlatformDigest failed: %v", err)
+	}
+	if want := fmt.Sprintf("sha256:%x", sha256.Sum256([]byte(single))); digest != want {
+		t.Errorf("expected %s, got %s", want, digest)
+	}
+
+	HostArchitecture = "s390x"
+	if _, err := client.GetPlatformDigest(ctx, "org/app", "1.2.0"); err == nil {
+		t.Error("expected error when the index has no manifest for the host")
+	}
+}
@@ -0,0 +1,146 @@
+package registry
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Registry providers, the implementations Manager selects by registry hostname.
+const (
+	ProviderDockerHub = "dockerhub"
+	ProviderGHCR      = "ghcr"
+	ProviderGitLab    = "gitlab"
+	ProviderQuay      = "quay"
+	ProviderHarbor    = "harbor"
+	ProviderGitea     = "gitea" // Gitea and Forgejo
+	ProviderECR       = "ecr"   // AWS Elastic Container Registry
+	ProviderGAR       = "gar"   // Google Artifact Registry and Container Registry
+	ProviderOCI       = "oci"   // Plain OCI Distribution (Docker Registry V2) API
+)
+
+// defaultProviders maps the hostnames of public registries to their providers.
+// ECR and Google registries are recognized by hostname pattern (see
+// providerForHost); others not listed, or set with SetRegistryProviders, use
+// ProviderOCI.
+var defaultProviders = map[string]string{
+	"docker.io":           ProviderDockerHub,
+	"ghcr.io":             ProviderGHCR,
+	"registry.gitlab.com": ProviderGitLab,
+	"quay.io":             ProviderQuay,
+	"codeberg.org":        ProviderGitea,
+	"gitea.com":           ProviderGitea,
+}
+
+// Provider is the implementation of a registry's API: listing tags, resolving
+// tag digests, reading manifest metadata, and authenticating.
+type Provider interface {
+	Client
+
+	// Name returns the provider's name, e.g. ProviderQuay
+	Name() string
+
+	// Authenticate returns the Authorization header value for pulling from a
+	// repository, or "" when the registry serves it anonymously
+	Authenticate(ctx context.Context, repository string) (string, error)
+}
+
+// providerForHost returns the default provider of a registry hostname.
+func providerForHost(registry string) string {
+	switch {
+	case defaultProviders[registry] != "":
+		return defaultProviders[registry]
+	case isECRRegistry(registry):
+		return ProviderECR
+	case isGoogleRegistry(registry):
+		return ProviderGAR
+	}
+	return ProviderOCI
+}
+
+// ParseRegistryProviders parses a comma-separated list of registry=provider
+// entries, for self-hosted registries ("registry.example.com=gitlab,harbor.local=harbor").
+// "forgejo" is accepted as an alias of gitea.
+// Docker Hub, GHCR, ECR, and Google registries only serve their own hostnames
+// and cannot be assigned.
+func ParseRegistryProviders(s string) (map[string]string, error) {
+	providers := make(map[string]string)
+	for _, entry := range splitList(s) {
+		registry, provider, ok := strings.Cut(entry, "=")
+		registry = strings.ToLower(strings.TrimSpace(registry))
+		provider = strings.ToLower(strings.TrimSpace(provider))
+		if !ok || registry == "" || provider == "" {
+			return nil, fmt.Errorf("invalid registry provider %q (expected registry=provider)", entry)
+		}
+		if provider == "forgejo" {
+			provider = ProviderGitea
+		}
+		switch provider {
+		case ProviderGitLab, ProviderQuay, ProviderHarbor, ProviderGitea, ProviderOCI:
+		default:
+			return nil, fmt.Errorf("unsupported provider %q for %s (must be gitlab, quay, harbor, gitea, forgejo, or oci)", provider, registry)
+		}
+		providers[registry] = provider
+	}
+	return providers, nil
+}
+
+// apiRefused reports whether a provider's API refused a request for lack of
+// access, which the registry may still grant, e.g. to robot account credentials.
+func apiRefused(err error) bool {
+	var statusErr *statusError
+	if !errors.As(err, &statusErr) {
+		return false
+	}
+	switch statusErr.StatusCode {
+	case http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound:
+		return true
+	}
+	return false
+}
+
+// basicAuth returns the value of a basic Authorization header.
+func basicAuth(username, password string) string {
+	return "Basic " + base64.StdEncoding.EncodeToString([]byte(username+":"+password))
+}
+
+// dockerConfigCredentials reads the credentials of a registry from
+// ~/.docker/config.json, as stored by docker login.
+func dockerConfigCredentials(registry string) (username, password string) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", ""
+	}
+
+	data, err := os.ReadFile(filepath.Join(homeDir, ".docker", "config.json"))
+	if err != nil {
+		return "", ""
+	}
+
+	var config dockerConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		return "", ""
+	}
+
+	auth, found := config.Auths[registry]
+	if !found {
+		return "", ""
+	}
+
+	// Decode base64 auth (format: username:password)
+	decoded, err := base64.StdEncoding.DecodeString(auth.Auth)
+	if err != nil {
+		return "", ""
+	}
+	username, password, ok := strings.Cut(string(decoded), ":")
+	if !ok {
+		return "", ""
+	}
+	return username, password
+}
@@ -0,0 +1,155 @@
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParseRegistryProviders(t *testing.T) {
+	providers, err := ParseRegistryProviders("Registry.Example.com=gitlab, quay.local = quay,harbor.local=harbor,git.local=forgejo,mirror.local=oci")
+	if err != nil {
+		t.Fatalf("ParseRegistryProviders failed: %v", err)
+	}
+	want := map[string]string{
+		"registry.example.com": ProviderGitLab,
+		"quay.local":           ProviderQuay,
+		"harbor.local":         ProviderHarbor,
+		"git.local":            ProviderGitea,
+		"mirror.local":         ProviderOCI,
+	}
+	if len(providers) != len(want) {
+		t.Fatalf("providers = %v, want %v", providers, want)
+	}
+	for registry, provider := range want {
+		if providers[registry] != provider {
+			t.Errorf("provider of %s = %q, want %q", registry, providers[registry], provider)
+		}
+	}
+
+	for _, invalid := range []string{"registry.example.com", "=gitlab", "registry.example.com=", "mirror.local=dockerhub", "registry.local=ecr"} {
+		if _, err := ParseRegistryProviders(invalid); err == nil {
+			t.Errorf("expected %q to be rejected", invalid)
+		}
+	}
+}
+
+func TestManager_ProviderName(t *testing.T) {
+	t.Setenv("HOME", t.TempDir()) // No Docker config credentials
+	m := NewManager("")
+	defer m.Close()
+	m.SetRegistryProviders(map[string]string{"registry.example.com": ProviderGitLab, "quay.io": ProviderOCI})
+
+	tests := map[string]string{
+		"docker.io":            ProviderDockerHub,
+		"ghcr.io":              ProviderGHCR,
+		"registry.gitlab.com":  ProviderGitLab,
+		"registry.example.com": ProviderGitLab,
+		"quay.io":              ProviderOCI, // Overridden
+		"lscr.io":              ProviderOCI,
+		"codeberg.org":         ProviderGitea,
+		"123456789012.dkr.ecr.eu-west-1.amazonaws.com": ProviderECR,
+		"europe-west1-docker.pkg.dev":                  ProviderGAR,
+		"gcr.io":                                       ProviderGAR,
+	}
+	for registry, want := range tests {
+		if got := m.ProviderName(registry); got != want {
+			t.Errorf("ProviderName(%s) = %q, want %q", registry, got, want)
+		}
+	}
+	if got := m.ProviderName(m.Registry("registry.example.com/group/project/app")); got != ProviderGitLab {
+		t.Errorf("provider of a nested GitLab image = %q, want gitlab", got)
+	}
+}
+
+// newGitLabRegistry serves a private GitLab project's tags behind GitLab's token
+// flow: the registry challenges for a bearer token, which /jwt/auth issues for
+// the deploy token "deploy:secret".
+func newGitLabRegistry(t *testing.T) *httptest.Server {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/jwt/auth":
+			if user, pass, ok := r.BasicAuth(); !ok || user != "deploy" || pass != "secret" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			if r.URL.Query().Get("scope") != "repository:group/app:pull" {
+				t.Errorf("token scope = %q", r.URL.Query().Get("scope"))
+			}
+			json.NewEncoder(w).Encode(map[string]string{"token": "registry-token"})
+		case "/v2/group/app/tags/list":
+			if r.Header.Get("Authorization") != "Bearer registry-token" {
+				w.Header().Set("WWW-Authenticate", fmt.Sprintf(
+					`Bearer realm="%s/jwt/auth",service="container_registry",scope="repository:group/app:pull"`, server.URL))
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			if r.URL.Query().Get("n") != "1000" {
+				t.Errorf("page size = %q, want 1000", r.URL.Query().Get("n"))
+			}
+			json.NewEncoder(w).Encode(tagsResponse{Name: "group/app", Tags: []string{"1.0.0", "1.1.0"}})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestGitLabClient_DeployToken(t *testing.T) {
+	server := newGitLabRegistry(t)
+	host := strings.TrimPrefix(server.URL, "http://")
+	client := NewGitLabClient(&RegistryConfig{Insecure: true}, host, "deploy:secret")
+
+	tags, err := client.ListTags(context.Background(), "group/app")
+	if err != nil {
+		t.Fatalf("ListTags failed: %v", err)
+	}
+	if !slices.Equal(tags, []string{"1.0.0", "1.1.0"}) {
+		t.Errorf("tags = %v", tags)
+	}
+
+	auth, err := client.Authenticate(context.Background(), "group/app")
+	if err != nil {
+		t.Fatalf("Authenticate failed: %v", err)
+	}
+	if auth != "Bearer registry-token" {
+		t.Errorf("Authenticate = %q, want the registry token", auth)
+	}
+}
+
+func TestGitLabClient_WithoutCredentials(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	server := newGitLabRegistry(t)
+	host := strings.TrimPrefix(server.URL, "http://")
+	client := NewGitLabClient(&RegistryConfig{Insecure: true}, host, "")
+
+	if _, err := client.ListTags(context.Background(), "group/app"); err == nil {
+		t.Fatal("expected listing a private project anonymously to fail")
+	}
+}
+
+func TestHTTPClient_AuthenticateAnonymous(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(tagsResponse{Tags: []string{"latest"}})
+	}))
+	defer server.Close()
+	client := NewHTTPClientForRegistry(&RegistryConfig{Insecure: true}, strings.TrimPrefix(server.URL, "http://"))
+
+	auth, err := client.Authenticate(context.Background(), "library/app")
+	if err != nil {
+		t.Fatalf("Authenticate failed: %v", err)
+	}
+	if auth != "" {
+		t.Errorf("Authenticate = %q, want none for a public registry", auth)
+	}
+	if client.Name() != ProviderOCI {
+		t.Errorf("Name = %q, want oci", client.Name())
+	}
+}
@@ -0,0 +1,162 @@
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// quayPageSize is the number of tags requested per page of Quay's API, its maximum.
+const quayPageSize = 100
+
+// QuayClient implements the Client interface for Quay, on quay.io or self-hosted.
+// Tags are listed through Quay's API, which orders them by last push and includes
+// their digests; manifests are read through the OCI Distribution API.
+type QuayClient struct {
+	*HTTPClient
+	token string // OAuth application token for the API (optional)
+}
+
+// NewQuayClient creates a client for a Quay registry. token is optional: an OAuth
+// application token with repo:read, for listing the tags of private repositories.
+// Registry requests use the Docker config credentials of the registry, if any,
+// such as those of a robot account.
+func NewQuayClient(config *RegistryConfig, registry, token string) *QuayClient {
+	if config == nil {
+		config = &RegistryConfig{}
+	}
+	if config.Username == "" {
+		config.Username, config.Password = dockerConfigCredentials(registry)
+	}
+	return &QuayClient{
+		HTTPClient: NewHTTPClientForRegistry(config, registry),
+		token:      token,
+	}
+}
+
+// Name returns ProviderQuay.
+func (c *QuayClient) Name() string {
+	return ProviderQuay
+}
+
+// quayTagsResponse is a page of Quay's repository tag API.
+type quayTagsResponse struct {
+	Tags []struct {
+		Name           string `json:"name"`
+		ManifestDigest string `json:"manifest_digest"`
+	} `json:"tags"`
+	HasAdditional bool `json:"has_additional"`
+}
+
+// ListTags returns the tags of a repository, most recently pushed first.
+func (c *QuayClient) ListTags(ctx context.Context, repository string) ([]string, error) {
+	tags, _, err := c.ListTagsConditional(ctx, repository, CacheValidators{})
+	return tags, err
+}
+
+// ListTagsConditional lists tags through Quay's API, which does not support
+// conditional requests, so the list is always fetched. Like Docker Hub's, the
+// listing stops early once the context's tag list stop condition is met. When the
+// API refuses the repository, e.g. a private one without an OAuth token, tags are
+// listed through the registry instead.
+func (c *QuayClient) ListTagsConditional(ctx context.Context, repository string, validators CacheValidators) ([]string, CacheValidators, error) {
+	var tags []string
+	err := c.listTags(ctx, repository, func(name, _ string) {
+		tags = append(tags, name)
+	}, tagListStopFrom(ctx).done)
+	if apiRefused(err) {
+		return c.HTTPClient.ListTagsConditional(ctx, repository, validators)
+	}
+	if err != nil {
+		return nil, validators, err
+	}
+	return tags, CacheValidators{}, nil
+}
+
+// GetLatestTag returns the "latest" tag if there is one, or the most recently pushed tag.
+func (c *QuayClient) GetLatestTag(ctx context.Context, repository string) (string, error) {
+	tags, err := c.ListTags(ctx, repository)
+	if err != nil {
+		return "", err
+	}
+	for _, tag := range tags {
+		if tag == "latest" {
+			return tag, nil
+		}
+	}
+	if len(tags) > 0 {
+		return tags[0], nil
+	}
+	return "", fmt.Errorf("no tags found for repository %s", repository)
+}
+
+// ListTagsWithDigests returns the manifest digest of each tag, from Quay's API.
+// Multi-arch tags map to the digest of their index, as in RepoDigests.
+func (c *QuayClient) ListTagsWithDigests(ctx context.Context, repository string) (map[string][]string, error) {
+	tagDigests := make(map[string][]string)
+	err := c.listTags(ctx, repository, func(name, digest string) {
+		if digest != "" {
+			tagDigests[name] = []string{digest}
+		}
+	}, nil)
+	if err != nil {
+		return nil, err
+	}
+	return tagDigests, nil
+}
+
+// listTags calls add for each active tag of a repository, until the last page,
+// the page limit, or until stop (optional) reports the tags listed so far suffice.
+func (c *QuayClient) listTags(ctx context.Context, repository string, add func(name, digest string), stop func(tags []string) bool) error {
+	registry, repo := c.parseRepository(repository)
+	protocol := "https"
+	if c.config.Insecure {
+		protocol = "http"
+	}
+
+	var names []string
+	maxPages := c.pageLimits.pages(registry, repo, defaultMaxTagPages)
+	for page := 1; page <= maxPages; page++ {
+		url := fmt.Sprintf("%s://%s/api/v1/repository/%s/tag/?onlyActiveTags=true&limit=%d&page=%d",
+			protocol, registry, repo, quayPageSize, page)
+		resp, err := c.getAPIPage(ctx, url)
+		if err != nil {
+			return err
+		}
+		for _, tag := range resp.Tags {
+			add(tag.Name, tag.ManifestDigest)
+			names = append(names, tag.Name)
+		}
+		if !resp.HasAdditional || (stop != nil && stop(names)) {
+			return nil
+		}
+	}
+	return nil
+}
+
+// getAPIPage requests a page of Quay's tag API.
+func (c *QuayClient) getAPIPage(ctx context.Context, url string) (*quayTagsResponse, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.doWithRetry(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch tags: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, handleHTTPError(resp, "quay tags request")
+	}
+	var page quayTagsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&page); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return &page, nil
+}
@@ -0,0 +1,146 @@
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// newQuayRegistry serves a Quay repository with total tags, newest first (1.0.N
+// down to 1.0.0) and 100 per page of the tag API, and records the API pages
+// requested. With private set, the API refuses requests without the OAuth token
+// "oauth", and the registry serves tags to the robot account "org+bot".
+func newQuayRegistry(t *testing.T, total int, private bool) (*httptest.Server, func() []int) {
+	var mu sync.Mutex
+	var requested []int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/repository/org/app/tag/":
+			if private && r.Header.Get("Authorization") != "Bearer oauth" {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			if r.URL.Query().Get("onlyActiveTags") != "true" {
+				t.Errorf("expected only active tags to be requested")
+			}
+			page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+			mu.Lock()
+			requested = append(requested, page)
+			mu.Unlock()
+
+			var resp quayTagsResponse
+			for i := total - 1 - (page-1)*quayPageSize; i >= 0 && i > total-1-page*quayPageSize; i-- {
+				resp.Tags = append(resp.Tags, struct {
+					Name           string `json:"name"`
+					ManifestDigest string `json:"manifest_digest"`
+				}{fmt.Sprintf("1.0.%d", i), fmt.Sprintf("sha256:%064d", i)})
+			}
+			resp.HasAdditional = page*quayPageSize < total
+			json.NewEncoder(w).Encode(resp)
+		case "/v2/org/app/tags/list":
+			if user, _, ok := r.BasicAuth(); private && (!ok || user != "org+bot") {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			json.NewEncoder(w).Encode(tagsResponse{Name: "org/app", Tags: []string{"1.0.0", "1.0.1"}})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server, func() []int {
+		mu.Lock()
+		defer mu.Unlock()
+		return slices.Clone(requested)
+	}
+}
+
+func TestQuayClient_ListTags(t *testing.T) {
+	server, requested := newQuayRegistry(t, 250, false)
+	client := NewQuayClient(&RegistryConfig{Insecure: true}, strings.TrimPrefix(server.URL, "http://"), "")
+
+	tags, err := client.ListTags(context.Background(), "org/app")
+	if err != nil {
+		t.Fatalf("ListTags failed: %v", err)
+	}
+	if len(tags) != 250 || tags[0] != "1.0.249" || tags[249] != "1.0.0" {
+		t.Errorf("got %d tags from %v to %v, want 250 newest first", len(tags), tags[0], tags[len(tags)-1])
+	}
+	if !slices.Equal(requested(), []int{1, 2, 3}) {
+		t.Errorf("pages requested = %v, want [1 2 3]", requested())
+	}
+
+	latest, err := client.GetLatestTag(context.Background(), "org/app")
+	if err != nil || latest != "1.0.249" {
+		t.Errorf("GetLatestTag = %q, %v, want the most recent tag", latest, err)
+	}
+}
+
+func TestQuayClient_ListTagsStopsEarly(t *testing.T) {
+	server, requested := newQuayRegistry(t, 500, false)
+	client := NewQuayClient(&RegistryConfig{Insecure: true}, strings.TrimPrefix(server.URL, "http://"), "")
+
+	ctx, stop := withOwnTagListStop(WithTagListStop(context.Background(), func(tags []string) bool {
+		return slices.Contains(tags, "1.0.350")
+	}))
+	tags, _, err := client.ListTagsConditional(ctx, "org/app", CacheValidators{})
+	if err != nil {
+		t.Fatalf("ListTagsConditional failed: %v", err)
+	}
+	if len(tags) != 200 || !stop.partial {
+		t.Errorf("got %d tags (partial %v), want 200 from the first two pages", len(tags), stop.partial)
+	}
+	if !slices.Equal(requested(), []int{1, 2}) {
+		t.Errorf("pages requested = %v, want [1 2]", requested())
+	}
+}
+
+func TestQuayClient_ListTagsWithDigests(t *testing.T) {
+	server, _ := newQuayRegistry(t, 120, false)
+	client := NewQuayClient(&RegistryConfig{Insecure: true}, strings.TrimPrefix(server.URL, "http://"), "")
+
+	tagDigests, err := client.ListTagsWithDigests(context.Background(), "org/app")
+	if err != nil {
+		t.Fatalf("ListTagsWithDigests failed: %v", err)
+	}
+	if len(tagDigests) != 120 {
+		t.Errorf("got digests of %d tags, want 120", len(tagDigests))
+	}
+	if got := tagDigests["1.0.7"]; !slices.Equal(got, []string{fmt.Sprintf("sha256:%064d", 7)}) {
+		t.Errorf("digests of 1.0.7 = %v", got)
+	}
+}
+
+func TestQuayClient_PrivateRepository(t *testing.T) {
+	server, _ := newQuayRegistry(t, 3, true)
+	host := strings.TrimPrefix(server.URL, "http://")
+
+	// Without an OAuth token, the tags are listed through the registry with robot credentials
+	robot := NewQuayClient(&RegistryConfig{Insecure: true, Username: "org+bot", Password: "robot-token"}, host, "")
+	tags, err := robot.ListTags(context.Background(), "org/app")
+	if err != nil {
+		t.Fatalf("ListTags with robot credentials failed: %v", err)
+	}
+	if !slices.Equal(tags, []string{"1.0.0", "1.0.1"}) {
+		t.Errorf("tags = %v, want those of the registry", tags)
+	}
+	if _, err := robot.ListTagsWithDigests(context.Background(), "org/app"); err == nil {
+		t.Error("expected ListTagsWithDigests to fail without API access")
+	}
+
+	withToken := NewQuayClient(&RegistryConfig{Insecure: true}, host, "oauth")
+	tagDigests, err := withToken.ListTagsWithDigests(context.Background(), "org/app")
+	if err != nil {
+		t.Fatalf("ListTagsWithDigests with an OAuth token failed: %v", err)
+	}
+	if len(tagDigests) != 3 {
+		t.Errorf("got digests of %d tags, want 3", len(tagDigests))
+	}
+}
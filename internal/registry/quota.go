@@ -0,0 +1,248 @@
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultQuotaThreshold is the remaining Docker Hub requests below which
+// low-priority checks are deferred.
+const DefaultQuotaThreshold = 10
+
+// quotaConfigKey persists the last known Docker Hub quota across restarts
+const quotaConfigKey = "dockerhub_rate_limit"
+
+// quotaStaleAfter is how long a quota without a reset time is trusted.
+const quotaStaleAfter = time.Hour
+
+// quotaPersistInterval limits how often an unchanged-severity quota is written to storage.
+const quotaPersistInterval = time.Minute
+
+// ErrQuotaExhausted is returned without contacting Docker Hub while its
+// rate limit quota is exhausted.
+var ErrQuotaExhausted = errors.New("docker hub rate limit exhausted")
+
+// QuotaStore persists the Docker Hub quota. Implemented by storage.Storage.
+type QuotaStore interface {
+	GetConfig(ctx context.Context, key string) (string, bool, error)
+	SetConfig(ctx context.Context, key, value string) error
+}
+
+// RateLimitStatus is the last known Docker Hub rate limit quota.
+type RateLimitStatus struct {
+	Limit     int       `json:"limit"`
+	Remaining int       `json:"remaining"`
+	Window    int       `json:"window_seconds,omitempty"`
+	Source    string    `json:"source,omitempty"` // IP or account the quota applies to
+	ResetAt   time.Time `json:"reset_at,omitempty"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Low       bool      `json:"low"`
+	Exhausted bool      `json:"exhausted"`
+}
+
+// parseRateLimitHeaders reads Docker Hub quota headers. The registry sends
+// "RateLimit-Remaining: 76;w=21600" on manifest responses; the Hub API sends
+// X-RateLimit-Remaining with a Unix X-RateLimit-Reset.
+func parseRateLimitHeaders(header http.Header, now time.Time) (RateLimitStatus, bool) {
+	remaining, window, ok := parseQuotaValue(header.Get("RateLimit-Remaining"))
+	limit, _, _ := parseQuotaValue(header.Get("RateLimit-Limit"))
+	var resetAt time.Time
+
+	if !ok {
+		remaining, _, ok = parseQuotaValue(header.Get("X-RateLimit-Remaining"))
+		if !ok {
+			return RateLimitStatus{}, false
+		}
+		limit, _, _ = parseQuotaValue(header.Get("X-RateLimit-Limit"))
+		if reset, err := strconv.ParseInt(header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+			resetAt = time.Unix(reset, 0)
+		}
+	}
+
+	// The registry quota is a sliding window, so the full budget is back one window from now
+	if resetAt.IsZero() && window > 0 {
+		resetAt = now.Add(time.Duration(window) * time.Second)
+	}
+
+	source, _, _ := strings.Cut(header.Get("Docker-RateLimit-Source"), ";")
+	return RateLimitStatus{
+		Limit:     limit,
+		Remaining: remaining,
+		Window:    window,
+		Source:    source,
+		ResetAt:   resetAt,
+		UpdatedAt: now,
+	}, true
+}
+
+// parseQuotaValue parses "76" or "76;w=21600" into the count and window seconds.
+func parseQuotaValue(value string) (count, window int, ok bool) {
+	if value == "" {
+		return 0, 0, false
+	}
+	parts := strings.Split(value, ";")
+	count, err := strconv.Atoi(strings.TrimSpace(parts[0]))
+	if err != nil {
+		return 0, 0, false
+	}
+	for _, param := range parts[1:] {
+		if w, found := strings.CutPrefix(strings.TrimSpace(param), "w="); found {
+			window, _ = strconv.Atoi(w)
+		}
+	}
+	return count, window, true
+}
+
+// QuotaTracker records the Docker Hub quota reported on each response.
+type QuotaTracker struct {
+	mu          sync.RWMutex
+	status      *RateLimitStatus
+	threshold   int
+	store       QuotaStore
+	persistedAt time.Time
+	persistedLo bool // whether the persisted quota was low
+}
+
+// NewQuotaTracker creates a tracker that reports the quota as low at or below threshold.
+func NewQuotaTracker(threshold int) *QuotaTracker {
+	if threshold < 0 {
+		threshold = 0
+	}
+	return &QuotaTracker{threshold: threshold}
+}
+
+// SetStore persists the quota in store and loads the last persisted value, so an
+// exhausted quota is still respected after a restart.
+func (t *QuotaTracker) SetStore(ctx context.Context, store QuotaStore) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.store = store
+
+	value, found, err := store.GetConfig(ctx, quotaConfigKey)
+	if err != nil || !found {
+		return
+	}
+	var status RateLimitStatus
+	if err := json.Unmarshal([]byte(value), &status); err != nil {
+		log.Printf("Ignoring invalid persisted Docker Hub quota: %v", err)
+		return
+	}
+	if t.status == nil || status.UpdatedAt.After(t.status.UpdatedAt) {
+		t.status = &status
+	}
+}
+
+// Record updates the quota from a Docker Hub response. A 429 response marks
+// the quota exhausted until its Retry-After.
+func (t *QuotaTracker) Record(resp *http.Response) {
+	now := time.Now()
+	status, ok := parseRateLimitHeaders(resp.Header, now)
+	if resp.StatusCode == http.StatusTooManyRequests {
+		if !ok {
+			t.mu.RLock()
+			if t.status != nil {
+				status = *t.status
+			}
+			t.mu.RUnlock()
+			status.UpdatedAt = now
+		}
+		status.Remaining = 0
+		if retry, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+			status.ResetAt = now.Add(time.Duration(retry) * time.Second)
+		}
+		ok = true
+	}
+	if !ok {
+		return
+	}
+
+	t.mu.Lock()
+	t.status = &status
+	low := t.lowLocked(now)
+	persist := t.store != nil && (low != t.persistedLo || now.Sub(t.persistedAt) >= quotaPersistInterval)
+	if persist {
+		t.persistedAt = now
+		t.persistedLo = low
+	}
+	store := t.store
+	t.mu.Unlock()
+
+	if persist {
+		if low {
+			log.Printf("Docker Hub rate limit low: %d of %d requests remaining, deferring low-priority checks", status.Remaining, status.Limit)
+		}
+		t.persist(store, status)
+	}
+}
+
+// persist writes status to store.
+func (t *QuotaTracker) persist(store QuotaStore, status RateLimitStatus) {
+	data, err := json.Marshal(status)
+	if err != nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := store.SetConfig(ctx, quotaConfigKey, string(data)); err != nil {
+		log.Printf("Failed to persist Docker Hub quota: %v", err)
+	}
+}
+
+// lowLocked reports whether the quota is at or below the threshold and has not
+// yet reset. Caller must hold t.mu.
+func (t *QuotaTracker) lowLocked(now time.Time) bool {
+	if t.status == nil {
+		return false
+	}
+	if now.After(t.status.resetTime()) {
+		return false
+	}
+	return t.status.Remaining <= t.threshold
+}
+
+// resetTime returns when the quota is expected to be restored.
+func (s *RateLimitStatus) resetTime() time.Time {
+	if s.ResetAt.IsZero() {
+		return s.UpdatedAt.Add(quotaStaleAfter)
+	}
+	return s.ResetAt
+}
+
+// Status returns the last known quota, or false if Docker Hub has not reported one.
+func (t *QuotaTracker) Status() (RateLimitStatus, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	if t.status == nil {
+		return RateLimitStatus{}, false
+	}
+	now := time.Now()
+	status := *t.status
+	status.ResetAt = status.resetTime()
+	status.Low = t.lowLocked(now)
+	status.Exhausted = status.Low && status.Remaining == 0
+	return status, true
+}
+
+// Low reports whether the quota is nearly exhausted.
+func (t *QuotaTracker) Low() bool {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.lowLocked(time.Now())
+}
+
+// checkExhausted returns ErrQuotaExhausted while no requests remain.
+func (t *QuotaTracker) checkExhausted() error {
+	status, ok := t.Status()
+	if !ok || !status.Exhausted {
+		return nil
+	}
+	return fmt.Errorf("%w until %s", ErrQuotaExhausted, status.ResetAt.Format(time.RFC3339))
+}
@@ -0,0 +1,140 @@
+package registry
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// memoryQuotaStore is an in-memory QuotaStore
+type memoryQuotaStore struct {
+	values map[string]string
+}
+
+func (s *memoryQuotaStore) GetConfig(ctx context.Context, key string) (string, bool, error) {
+	v, ok := s.values[key]
+	return v, ok, nil
+}
+
+func (s *memoryQuotaStore) SetConfig(ctx context.Context, key, value string) error {
+	s.values[key] = value
+	return nil
+}
+
+func quotaResponse(status int, headers map[string]string) *http.Response {
+	resp := &http.Response{StatusCode: status, Header: http.Header{}}
+	for k, v := range headers {
+		resp.Header.Set(k, v)
+	}
+	return resp
+}
+
+func TestParseRateLimitHeaders(t *testing.T) {
+	now := time.Now()
+
+	status, ok := parseRateLimitHeaders(quotaResponse(200, map[string]string{
+		"RateLimit-Limit":         "100;w=21600",
+		"RateLimit-Remaining":     "76;w=21600",
+		"Docker-RateLimit-Source": "203.0.113.7",
+	}).Header, now)
+	if !ok {
+		t.Fatal("Expected registry quota headers to parse")
+	}
+	if status.Limit != 100 || status.Remaining != 76 || status.Window != 21600 || status.Source != "203.0.113.7" {
+		t.Errorf("Unexpected status: %+v", status)
+	}
+	if !status.ResetAt.Equal(now.Add(6 * time.Hour)) {
+		t.Errorf("Expected reset one window from now, got %v", status.ResetAt)
+	}
+
+	status, ok = parseRateLimitHeaders(quotaResponse(200, map[string]string{
+		"X-RateLimit-Limit":     "180",
+		"X-RateLimit-Remaining": "3",
+		"X-RateLimit-Reset":     "1700000000",
+	}).Header, now)
+	if !ok || status.Remaining != 3 || !status.ResetAt.Equal(time.Unix(1700000000, 0)) {
+		t.Errorf("Unexpected Hub API status: %+v (ok=%v)", status, ok)
+	}
+
+	if _, ok := parseRateLimitHeaders(http.Header{}, now); ok {
+		t.Error("Expected no quota without headers")
+	}
+}
+
+func TestQuotaTracker(t *testing.T) {
+	tracker := NewQuotaTracker(10)
+	if _, ok := tracker.Status(); ok {
+		t.Fatal("Expected no quota before any response")
+	}
+
+	tracker.Record(quotaResponse(200, map[string]string{"RateLimit-Remaining": "50;w=21600"}))
+	if tracker.Low() {
+		t.Error("Expected quota with 50 remaining not to be low")
+	}
+
+	tracker.Record(quotaResponse(200, map[string]string{"RateLimit-Remaining": "5;w=21600"}))
+	if !tracker.Low() {
+		t.Error("Expected quota with 5 remaining to be low")
+	}
+	if err := tracker.checkExhausted(); err != nil {
+		t.Errorf("Expected requests to be allowed while quota remains: %v", err)
+	}
+
+	tracker.Record(quotaResponse(http.StatusTooManyRequests, map[string]string{"Retry-After": "60"}))
+	status, _ := tracker.Status()
+	if !status.Exhausted || status.Remaining != 0 {
+		t.Errorf("Expected 429 to exhaust quota, got %+v", status)
+	}
+	if err := tracker.checkExhausted(); !errors.Is(err, ErrQuotaExhausted) {
+		t.Errorf("Expected ErrQuotaExhausted, got %v", err)
+	}
+
+	// An exhausted quota stops applying once its reset time passes
+	tracker.Record(quotaResponse(http.StatusTooManyRequests, map[string]string{"Retry-After": "-1"}))
+	if tracker.Low() {
+		t.Error("Expected quota past its reset time not to be low")
+	}
+}
+
+func TestQuotaTrackerPersists(t *testing.T) {
+	store := &memoryQuotaStore{values: map[string]string{}}
+
+	tracker := NewQuotaTracker(10)
+	tracker.SetStore(context.Background(), store)
+	tracker.Record(quotaResponse(http.StatusTooManyRequests, map[string]string{"Retry-After": "3600"}))
+
+	if _, ok := store.values[quotaConfigKey]; !ok {
+		t.Fatal("Expected quota to be persisted")
+	}
+
+	restored := NewQuotaTracker(10)
+	restored.SetStore(context.Background(), store)
+	status, ok := restored.Status()
+	if !ok || !status.Exhausted {
+		t.Errorf("Expected exhausted quota after restart, got %+v (ok=%v)", status, ok)
+	}
+}
+
+func TestManagerFailsFastWhenQuotaExhausted(t *testing.T) {
+	m := NewManager("")
+	defer m.Close()
+
+	m.dockerHubClient.Quota().Record(quotaResponse(http.StatusTooManyRequests, map[string]string{"Retry-After": "3600"}))
+
+	if !m.QuotaLow("docker.io/library/nginx") {
+		t.Error("Expected Docker Hub quota to be low")
+	}
+	if m.QuotaLow("ghcr.io/linuxserver/plex") {
+		t.Error("Expected GHCR to be unaffected by the Docker Hub quota")
+	}
+
+	_, err := m.ListTags(context.Background(), "docker.io/library/nginx")
+	if !errors.Is(err, ErrQuotaExhausted) {
+		t.Errorf("Expected ErrQuotaExhausted, got %v", err)
+	}
+	if state := m.GetCircuitBreakerState("docker.io"); state != CircuitClosed {
+		t.Errorf("Expected quota deferral not to trip the circuit breaker, got %v", state)
+	}
+}
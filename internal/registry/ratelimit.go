@@ -0,0 +1,70 @@
+package registry
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// DefaultRegistryRateLimit is the default maximum requests per second sent to a single registry.
+const DefaultRegistryRateLimit = 10.0
+
+// RateLimiter spaces out requests per registry so a full check of many
+// containers does not burst against a single registry.
+type RateLimiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	next     map[string]time.Time // registry -> earliest time the next request may start
+}
+
+// NewRateLimiter creates a rate limiter allowing perSecond requests per registry.
+// A rate of zero or less disables limiting.
+func NewRateLimiter(perSecond float64) *RateLimiter {
+	l := &RateLimiter{next: make(map[string]time.Time)}
+	l.SetRate(perSecond)
+	return l
+}
+
+// SetRate changes the allowed requests per second per registry.
+func (l *RateLimiter) SetRate(perSecond float64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if perSecond <= 0 {
+		l.interval = 0
+		return
+	}
+	l.interval = time.Duration(float64(time.Second) / perSecond)
+}
+
+// Wait blocks until a request to registry is allowed or ctx is done.
+func (l *RateLimiter) Wait(ctx context.Context, registry string) error {
+	l.mu.Lock()
+	if l.interval == 0 {
+		l.mu.Unlock()
+		return nil
+	}
+
+	// Reserve the next slot for this registry
+	now := time.Now()
+	slot := l.next[registry]
+	if slot.Before(now) {
+		slot = now
+	}
+	l.next[registry] = slot.Add(l.interval)
+	l.mu.Unlock()
+
+	delay := time.Until(slot)
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
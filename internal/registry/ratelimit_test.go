@@ -0,0 +1,54 @@
+package registry
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestRateLimiter_SpacesRequestsPerRegistry(t *testing.T) {
+	limiter := NewRateLimiter(20) // 50ms between requests
+	ctx := context.Background()
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if err := limiter.Wait(ctx, "docker.io"); err != nil {
+			t.Fatalf("Wait returned error: %v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+		t.Errorf("expected requests to be spaced out, took %v", elapsed)
+	}
+
+	// A different registry has its own budget
+	start = time.Now()
+	if err := limiter.Wait(ctx, "ghcr.io"); err != nil {
+		t.Fatalf("Wait returned error: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 20*time.Millisecond {
+		t.Errorf("expected first request to another registry to be immediate, took %v", elapsed)
+	}
+}
+
+func TestRateLimiter_Disabled(t *testing.T) {
+	limiter := NewRateLimiter(0)
+
+	start := time.Now()
+	for i := 0; i < 100; i++ {
+		limiter.Wait(context.Background(), "docker.io")
+	}
+	if elapsed := time.Since(start); elapsed > 20*time.Millisecond {
+		t.Errorf("expected no delay when disabled, took %v", elapsed)
+	}
+}
+
+func TestRateLimiter_ContextCancelled(t *testing.T) {
+	limiter := NewRateLimiter(1)
+	limiter.Wait(context.Background(), "docker.io")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := limiter.Wait(ctx, "docker.io"); err == nil {
+		t.Error("expected error for cancelled context")
+	}
+}
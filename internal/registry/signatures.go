@@ -0,0 +1,112 @@
+package registry
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Cosign layer annotations
+const (
+	cosignSignatureAnnotation   = "dev.cosignproject.cosign/signature"
+	cosignCertificateAnnotation = "dev.sigstore.cosign/certificate"
+	cosignChainAnnotation       = "dev.sigstore.cosign/chain"
+	cosignBundleAnnotation      = "dev.sigstore.cosign/bundle"
+)
+
+// CosignAttestationType is reported for attestations stored under a cosign ".att" tag.
+const CosignAttestationType = "application/vnd.dsse.envelope.v1+json"
+
+// Signature is a cosign signature of an image digest.
+type Signature struct {
+	Payload     []byte // Simple signing payload that was signed
+	Signature   string // Base64 signature of Payload
+	Certificate string // PEM signing certificate of keyless signatures
+	Chain       string // PEM intermediate and root certificates of keyless signatures
+	Bundle      string // Transparency log bundle (JSON), if the signature was uploaded to Rekor
+}
+
+// ImageSignatures are the signatures and attestations published for an image digest.
+type ImageSignatures struct {
+	Signatures []Signature
+	// Attestations lists the artifact types of provenance, SBOM, and other
+	// attestations found as OCI referrers or under the cosign ".att" tag
+	Attestations []string
+}
+
+// signatureManifest is the subset of a cosign signature manifest needed to read its signatures.
+type signatureManifest struct {
+	Layers []struct {
+		Digest      string            `json:"digest"`
+		Annotations map[string]string `json:"annotations"`
+	} `json:"layers"`
+}
+
+// referrersIndex is the OCI referrers API response.
+type referrersIndex struct {
+	Manifests []struct {
+		ArtifactType string `json:"artifactType"`
+	} `json:"manifests"`
+}
+
+// imageSignatures returns the cosign signatures of the image at digest, which cosign
+// stores under the tag "sha256-<hex>.sig", and the attestations referring to it.
+// An image without signatures returns no error and no signatures.
+func imageSignatures(ctx context.Context, fetch registryFetcher, digest string) (*ImageSignatures, error) {
+	if !strings.HasPrefix(digest, "sha256:") {
+		return nil, fmt.Errorf("signatures are looked up by sha256 digest, got %q", digest)
+	}
+	tag := strings.Replace(digest, ":", "-", 1)
+	result := &ImageSignatures{}
+
+	body, err := fetch(ctx, "manifests", tag+".sig")
+	if err != nil && !isNotFound(err) {
+		return nil, fmt.Errorf("failed to fetch signatures: %w", err)
+	}
+	if err == nil {
+		var m signatureManifest
+		if err := json.Unmarshal(body, &m); err != nil {
+			return nil, fmt.Errorf("failed to decode signature manifest: %w", err)
+		}
+		for _, layer := range m.Layers {
+			signature := layer.Annotations[cosignSignatureAnnotation]
+			if signature == "" {
+				continue
+			}
+			payload, err := fetch(ctx, "blobs", layer.Digest)
+			if err != nil {
+				return nil, fmt.Errorf("failed to fetch signature payload: %w", err)
+			}
+			if fmt.Sprintf("sha256:%x", sha256.Sum256(payload)) != layer.Digest {
+				return nil, fmt.Errorf("signature payload does not match digest %s", layer.Digest)
+			}
+			result.Signatures = append(result.Signatures, Signature{
+				Payload:     payload,
+				Signature:   signature,
+				Certificate: layer.Annotations[cosignCertificateAnnotation],
+				Chain:       layer.Annotations[cosignChainAnnotation],
+				Bundle:      layer.Annotations[cosignBundleAnnotation],
+			})
+		}
+	}
+
+	// Attestations are informational, so lookup errors are ignored.
+	// Registries without the referrers API answer 404.
+	if _, err := fetch(ctx, "manifests", tag+".att"); err == nil {
+		result.Attestations = append(result.Attestations, CosignAttestationType)
+	}
+	if body, err := fetch(ctx, "referrers", digest); err == nil {
+		var index referrersIndex
+		if json.Unmarshal(body, &index) == nil {
+			for _, m := range index.Manifests {
+				if m.ArtifactType != "" && !slices.Contains(result.Attestations, m.ArtifactType) {
+					result.Attestations = append(result.Attestations, m.ArtifactType)
+				}
+			}
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,65 @@
+package registry
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHTTPClientGetSignatures(t *testing.T) {
+	payload := `{"critical":{"image":{"docker-manifest-digest":"sha256:abc"},"type":"cosign container image signature"}}`
+	payloadDigest := fmt.Sprintf("sha256:%x", sha256.Sum256([]byte(payload)))
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/org/app/manifests/sha256-abc.sig":
+			fmt.Fprintf(w, `{"layers": [
+				{"digest": %q, "annotations": {"dev.cosignproject.cosign/signature": "MEUCIQ=="}},
+				{"digest": "sha256:other", "annotations": {}}
+			]}`, payloadDigest)
+		case "/v2/org/app/blobs/" + payloadDigest:
+			w.Write([]byte(payload))
+		case "/v2/org/app/referrers/sha256:abc":
+			w.Write([]byte(`{"manifests": [
+				{"artifactType": "application/vnd.in-toto+json"},
+				{"artifactType": "application/vnd.in-toto+json"}
+			]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewHTTPClientForRegistry(&RegistryConfig{Insecure: true}, strings.TrimPrefix(server.URL, "http://"))
+	ctx := context.Background()
+
+	sigs, err := client.GetSignatures(ctx, "org/app", "sha256:abc")
+	if err != nil {
+		t.Fatalf("GetSignatures failed: %v", err)
+	}
+	if len(sigs.Signatures) != 1 {
+		t.Fatalf("expected 1 signature, got %d", len(sigs.Signatures))
+	}
+	if string(sigs.Signatures[0].Payload) != payload || sigs.Signatures[0].Signature != "MEUCIQ==" {
+		t.Errorf("unexpected signature: %+v", sigs.Signatures[0])
+	}
+	if strings.Join(sigs.Attestations, ",") != "application/vnd.in-toto+json" {
+		t.Errorf("unexpected attestations: %v", sigs.Attestations)
+	}
+
+	sigs, err = client.GetSignatures(ctx, "org/app", "sha256:unsigned")
+	if err != nil {
+		t.Fatalf("GetSignatures of an unsigned image failed: %v", err)
+	}
+	if len(sigs.Signatures) != 0 || len(sigs.Attestations) != 0 {
+		t.Errorf("expected no signatures or attestations, got %+v", sigs)
+	}
+
+	if _, err := client.GetSignatures(ctx, "org/app", "1.2.0"); err == nil {
+		t.Error("expected an error for a tag reference")
+	}
+}
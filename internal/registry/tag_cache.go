@@ -0,0 +1,174 @@
+package registry
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/chis/docksmith/internal/storage"
+)
+
+// DefaultTagCacheTTL is how long a persisted tag list is used without asking the registry.
+const DefaultTagCacheTTL = time.Hour
+
+// ErrNotModified is returned by conditional tag listings when the registry
+// reports that the tag list has not changed since the given validators.
+var ErrNotModified = errors.New("tag list not modified")
+
+// TagCacheStore persists tag lists across checks and restarts.
+// Implemented by storage.Storage.
+type TagCacheStore interface {
+	GetTagCache(ctx context.Context, imageRef string) (storage.TagCacheEntry, bool, error)
+	SaveTagCache(ctx context.Context, entry storage.TagCacheEntry) error
+}
+
+// CacheValidators are the HTTP validators of a cached tag list.
+type CacheValidators struct {
+	ETag         string
+	LastModified string
+}
+
+// apply sets the conditional request headers for v.
+func (v CacheValidators) apply(header http.Header) {
+	if v.ETag != "" {
+		header.Set("If-None-Match", v.ETag)
+	}
+	if v.LastModified != "" {
+		header.Set("If-Modified-Since", v.LastModified)
+	}
+}
+
+// conditionalTagLister is implemented by clients that support conditional tag list requests.
+// ListTagsConditional returns ErrNotModified when validators still match.
+type conditionalTagLister interface {
+	ListTagsConditional(ctx context.Context, repository string, validators CacheValidators) ([]string, CacheValidators, error)
+}
+
+type cacheBypassKey struct{}
+
+// WithCacheBypass returns a context whose tag list lookups skip cached data.
+// Persisted lists are still revalidated with conditional requests, so an
+// unchanged list costs the registry a 304 instead of a full listing.
+func WithCacheBypass(ctx context.Context) context.Context {
+	return context.WithValue(ctx, cacheBypassKey{}, true)
+}
+
+// cacheBypassed reports whether ctx was created by WithCacheBypass.
+func cacheBypassed(ctx context.Context) bool {
+	bypass, _ := ctx.Value(cacheBypassKey{}).(bool)
+	return bypass
+}
+
+// tagListing is the outcome of a (possibly conditional) tag list request.
+type tagListing struct {
+	tags        []string
+	validators  CacheValidators
+	notModified bool
+	partial     bool // The listing stopped before its last page
+}
+
+// tagCache holds the persistent tag cache settings of a Manager.
+type tagCache struct {
+	store TagCacheStore
+	ttl   time.Duration
+
+	mu          sync.RWMutex
+	staleBefore time.Time // entries fetched before this are revalidated
+}
+
+// fresh reports whether entry can be used without contacting the registry.
+func (c *tagCache) fresh(entry storage.TagCacheEntry) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return time.Since(entry.FetchedAt) < c.ttl && entry.FetchedAt.After(c.staleBefore)
+}
+
+// invalidate marks every persisted entry as needing revalidation.
+func (c *tagCache) invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.staleBefore = time.Now()
+}
+
+// listTags returns the tags for imageRef from the persistent cache, revalidating
+// or refetching them through fetch when they are stale or bypassed. A partial list,
+// from a listing that stopped early, is only used when it satisfies the stop
+// condition of ctx. When the registry cannot be reached, a stale list is returned
+// rather than failing the check.
+func (c *tagCache) listTags(ctx context.Context, imageRef string, fetch func(CacheValidators) (tagListing, error)) ([]string, error) {
+	entry, found, err := c.store.GetTagCache(ctx, imageRef)
+	if err != nil {
+		log.Printf("Tag cache lookup failed for %s: %v", imageRef, err)
+		found = false
+	}
+	usable := found && (!entry.Partial || satisfiedBy(ctx, entry.Tags))
+
+	if usable && !cacheBypassed(ctx) && c.fresh(entry) {
+		return entry.Tags, nil
+	}
+
+	// A list that is not usable is refetched rather than revalidated
+	var validators CacheValidators
+	if usable {
+		validators = CacheValidators{ETag: entry.ETag, LastModified: entry.LastModified}
+	}
+
+	listing, err := fetch(validators)
+	if err != nil {
+		if found {
+			log.Printf("Using stale tag list for %s (fetched %s): %v", imageRef, entry.FetchedAt.Format(time.RFC3339), err)
+			return entry.Tags, nil
+		}
+		return nil, err
+	}
+
+	if listing.notModified {
+		entry.FetchedAt = time.Now()
+	} else {
+		entry = storage.TagCacheEntry{
+			ImageRef:     imageRef,
+			Tags:         listing.tags,
+			ETag:         listing.validators.ETag,
+			LastModified: listing.validators.LastModified,
+			Partial:      listing.partial,
+			FetchedAt:    time.Now(),
+		}
+	}
+
+	if len(entry.Tags) > 0 {
+		if err := c.store.SaveTagCache(ctx, entry); err != nil {
+			log.Printf("Failed to persist tag list for %s: %v", imageRef, err)
+		}
+	}
+	return entry.Tags, nil
+}
+
+// listTagsConditional lists tags with a conditional request when the client supports it.
+func listTagsConditional(ctx context.Context, client Client, repository string, validators CacheValidators) (tagListing, error) {
+	ctx, stop := withOwnTagListStop(ctx)
+	lister, ok := client.(conditionalTagLister)
+	if !ok {
+		tags, err := client.ListTags(ctx, repository)
+		return tagListing{tags: tags, partial: stop != nil && stop.partial}, err
+	}
+
+	tags, newValidators, err := lister.ListTagsConditional(ctx, repository, validators)
+	if errors.Is(err, ErrNotModified) {
+		return tagListing{notModified: true}, nil
+	}
+	if err != nil {
+		return tagListing{}, err
+	}
+	return tagListing{tags: tags, validators: newValidators, partial: stop != nil && stop.partial}, nil
+}
+
+// responseValidators extracts the cache validators from a tag list response.
+func responseValidators(resp *http.Response) CacheValidators {
+	return CacheValidators{
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+	}
+}
@@ -0,0 +1,148 @@
+package registry
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/chis/docksmith/internal/storage"
+)
+
+// memoryTagStore is an in-memory TagCacheStore.
+type memoryTagStore struct {
+	mu      sync.Mutex
+	entries map[string]storage.TagCacheEntry
+}
+
+func newMemoryTagStore() *memoryTagStore {
+	return &memoryTagStore{entries: make(map[string]storage.TagCacheEntry)}
+}
+
+func (s *memoryTagStore) GetTagCache(ctx context.Context, imageRef string) (storage.TagCacheEntry, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry, ok := s.entries[imageRef]
+	return entry, ok, nil
+}
+
+func (s *memoryTagStore) SaveTagCache(ctx context.Context, entry storage.TagCacheEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries[entry.ImageRef] = entry
+	return nil
+}
+
+func TestHTTPClientListTagsConditional(t *testing.T) {
+	const etag = `"v1"`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		w.Header().Set("Last-Modified", "Wed, 14 Oct 2026 10:00:00 GMT")
+		w.Write([]byte(`{"name": "org/app", "tags": ["1.0.0", "1.1.0"]}`))
+	}))
+	defer server.Close()
+
+	client := NewHTTPClientForRegistry(&RegistryConfig{Insecure: true}, strings.TrimPrefix(server.URL, "http://"))
+	ctx := context.Background()
+
+	tags, validators, err := client.ListTagsConditional(ctx, "org/app", CacheValidators{})
+	if err != nil {
+		t.Fatalf("ListTagsConditional failed: %v", err)
+	}
+	if len(tags) != 2 || validators.ETag != etag || validators.LastModified == "" {
+		t.Fatalf("unexpected result: tags=%v validators=%+v", tags, validators)
+	}
+
+	if _, _, err := client.ListTagsConditional(ctx, "org/app", validators); !errors.Is(err, ErrNotModified) {
+		t.Errorf("expected ErrNotModified, got %v", err)
+	}
+}
+
+func TestTagCacheListTags(t *testing.T) {
+	store := newMemoryTagStore()
+	cache := &tagCache{store: store, ttl: time.Hour}
+	ctx := context.Background()
+
+	var calls int
+	var gotValidators CacheValidators
+	var result tagListing
+	var fetchErr error
+	fetch := func(v CacheValidators) (tagListing, error) {
+		calls++
+		gotValidators = v
+		return result, fetchErr
+	}
+
+	// Miss: fetched and persisted with validators
+	result = tagListing{tags: []string{"1.0.0"}, validators: CacheValidators{ETag: `"a"`}}
+	tags, err := cache.listTags(ctx, "org/app", fetch)
+	if err != nil || len(tags) != 1 || calls != 1 {
+		t.Fatalf("miss: tags=%v err=%v calls=%d", tags, err, calls)
+	}
+
+	// Fresh hit: registry not contacted
+	if tags, _ = cache.listTags(ctx, "org/app", fetch); calls != 1 || tags[0] != "1.0.0" {
+		t.Fatalf("expected fresh cache hit, calls=%d tags=%v", calls, tags)
+	}
+
+	// Bypass: revalidated with the stored ETag; 304 keeps the list and refreshes FetchedAt
+	entry := store.entries["org/app"]
+	entry.FetchedAt = time.Now().Add(-time.Minute)
+	store.entries["org/app"] = entry
+	result = tagListing{notModified: true}
+	tags, err = cache.listTags(WithCacheBypass(ctx), "org/app", fetch)
+	if err != nil || calls != 2 || gotValidators.ETag != `"a"` || tags[0] != "1.0.0" {
+		t.Fatalf("bypass: tags=%v err=%v calls=%d validators=%+v", tags, err, calls, gotValidators)
+	}
+	if time.Since(store.entries["org/app"].FetchedAt) > time.Second {
+		t.Error("expected 304 to refresh FetchedAt")
+	}
+
+	// Invalidation: next lookup revalidates and picks up the changed list
+	time.Sleep(time.Millisecond)
+	cache.invalidate()
+	result = tagListing{tags: []string{"1.0.0", "1.1.0"}, validators: CacheValidators{ETag: `"b"`}}
+	if tags, _ = cache.listTags(ctx, "org/app", fetch); calls != 3 || len(tags) != 2 {
+		t.Fatalf("invalidate: calls=%d tags=%v", calls, tags)
+	}
+	if store.entries["org/app"].ETag != `"b"` {
+		t.Errorf("expected new ETag to be persisted, got %q", store.entries["org/app"].ETag)
+	}
+
+	// Registry failure: stale list served instead of failing
+	cache.invalidate()
+	fetchErr = errors.New("rate limited")
+	if tags, err = cache.listTags(ctx, "org/app", fetch); err != nil || len(tags) != 2 {
+		t.Errorf("expected stale list on error, tags=%v err=%v", tags, err)
+	}
+	if _, err = cache.listTags(ctx, "other/app", fetch); err == nil {
+		t.Error("expected error without a cached list")
+	}
+}
+
+func TestManagerListTagsBypassesMemoryCache(t *testing.T) {
+	m := NewManager("")
+	defer m.Close()
+
+	m.cache.Set("tags:example.com/org/app", []string{"stale"})
+	ctx := WithCacheBypass(context.Background())
+	// Open the circuit so the bypassed lookup fails without network access
+	for range 10 {
+		m.circuitBreaker.RecordFailure("example.com")
+	}
+
+	if _, err := m.ListTags(ctx, "example.com/org/app"); err == nil {
+		t.Fatal("expected bypassed lookup to reach the (open) circuit breaker")
+	}
+	if _, found := m.cache.Get("tags:example.com/org/app"); found {
+		t.Error("expected bypass to drop the in-memory entry")
+	}
+}
@@ -17,6 +17,27 @@ type Client interface {
 	// This allows efficient reverse-lookup to find which tag corresponds to a digest.
 	// The map key is the tag name, and the value is a slice of digests (one per architecture).
 	ListTagsWithDigests(ctx context.Context, repository string) (map[string][]string, error)
+
+	// GetImageSize returns the compressed size in bytes of the image at a tag or digest.
+	// Multi-arch images are resolved to the platform docksmith runs on.
+	GetImageSize(ctx context.Context, repository, reference string) (int64, error)
+
+	// GetImageLayers returns the compressed layers of the image at a tag or digest.
+	// Multi-arch images are resolved to the platform docksmith runs on.
+	GetImageLayers(ctx context.Context, repository, reference string) ([]ImageLayer, error)
+
+	// GetImagePlatforms returns the platforms ("linux/arm64") of a multi-arch image
+	// at a tag or digest. Single-platform images return nil.
+	GetImagePlatforms(ctx context.Context, repository, reference string) ([]string, error)
+
+	// GetPlatformDigest returns the digest of the manifest Docker pulls on this host
+	// for a tag or digest. For multi-arch images this is the child manifest for the
+	// host platform, not the digest of the index.
+	GetPlatformDigest(ctx context.Context, repository, reference string) (string, error)
+
+	// GetSignatures returns the cosign signatures and attestations published for an
+	// image digest. Unsigned images return no signatures and no error.
+	GetSignatures(ctx context.Context, repository, digest string) (*ImageSignatures, error)
 }
 
 // ImageReference contains information about a Docker image.
@@ -56,4 +77,12 @@ type RegistryConfig struct {
 
 	// Timeout for registry requests in seconds
 	TimeoutSeconds int
+
+	// Proxy selects the HTTP proxy for requests (default: proxy environment variables)
+	Proxy ProxyFunc
+
+	// Credentials supplies short-lived credentials in place of Username and
+	// Password, such as cloud registry tokens; Username and Password remain the
+	// fallback when it fails (optional)
+	Credentials CredentialSource
 }
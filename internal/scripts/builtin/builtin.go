@@ -0,0 +1,239 @@
+// Package builtin provides pre-update checks for common self-hosted apps, selected
+// with docksmith.pre-update-check=builtin:<name> instead of a script. Each check asks
+// the app's API whether it is busy (streaming, downloading) and blocks the update if so.
+package builtin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Prefix marks a pre-update check value as a built-in check.
+const Prefix = "builtin:"
+
+// requestTimeout bounds each API request of a check.
+const requestTimeout = 10 * time.Second
+
+// Target is the app instance a check queries.
+type Target struct {
+	Host   string // Hostname used when URL is empty, normally the container name
+	URL    string // Base URL of the app; defaults to http://<Host>:<default port>
+	APIKey string // API key or token; "username:password" for qBittorrent
+}
+
+// check is a built-in check and the port its app listens on by default.
+type check struct {
+	port int
+	run  func(ctx context.Context, c *http.Client, baseURL, apiKey string) error
+}
+
+var checks = map[string]check{
+	"plex":        {32400, checkPlex},
+	"jellyfin":    {8096, checkJellyfin},
+	"sonarr":      {8989, checkArrQueue("Sonarr")},
+	"radarr":      {7878, checkArrQueue("Radarr")},
+	"qbittorrent": {8080, checkQBittorrent},
+}
+
+// IsCheck reports whether a pre-update check value selects a built-in check.
+func IsCheck(value string) bool {
+	return strings.HasPrefix(value, Prefix)
+}
+
+// Parse returns the name of the built-in check a pre-update check value selects.
+// ok is false for script paths.
+func Parse(value string) (name string, ok bool) {
+	if !IsCheck(value) {
+		return "", false
+	}
+	return strings.ToLower(strings.TrimSpace(strings.TrimPrefix(value, Prefix))), true
+}
+
+// Names returns the names of the built-in checks.
+func Names() []string {
+	names := make([]string, 0, len(checks))
+	for name := range checks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Validate returns an error if a pre-update check value names an unknown built-in check.
+func Validate(value string) error {
+	name, ok := Parse(value)
+	if !ok {
+		return nil
+	}
+	if _, known := checks[name]; !known {
+		return fmt.Errorf("unknown built-in check %q (available: %s)", name, strings.Join(Names(), ", "))
+	}
+	return nil
+}
+
+// Run runs the built-in check name against target. It returns an error describing
+// the activity when the app is busy, or why the app could not be queried.
+func Run(ctx context.Context, name string, target Target) error {
+	if err := Validate(Prefix + name); err != nil {
+		return err
+	}
+	chk := checks[name]
+
+	baseURL := strings.TrimRight(target.URL, "/")
+	if baseURL == "" {
+		if target.Host == "" {
+			return fmt.Errorf("no URL for built-in check %s", name)
+		}
+		baseURL = fmt.Sprintf("http://%s:%d", target.Host, chk.port)
+	}
+	return chk.run(ctx, &http.Client{Timeout: requestTimeout}, baseURL, target.APIKey)
+}
+
+// getJSON decodes the JSON response of a GET request into v.
+func getJSON(ctx context.Context, c *http.Client, rawURL string, header http.Header, v any) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", rawURL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to query %s: %w", req.URL.Host, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s returned %d: %s", req.URL.Path, resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+// checkPlex blocks while anyone is streaming from Plex.
+func checkPlex(ctx context.Context, c *http.Client, baseURL, token string) error {
+	var sessions struct {
+		MediaContainer struct {
+			Size int `json:"size"`
+		} `json:"MediaContainer"`
+	}
+	header := http.Header{"X-Plex-Token": {token}}
+	if err := getJSON(ctx, c, baseURL+"/status/sessions", header, &sessions); err != nil {
+		return err
+	}
+	if n := sessions.MediaContainer.Size; n > 0 {
+		return fmt.Errorf("%d active Plex %s", n, plural(n, "stream", "streams"))
+	}
+	return nil
+}
+
+// checkJellyfin blocks while a Jellyfin session is playing something.
+func checkJellyfin(ctx context.Context, c *http.Client, baseURL, apiKey string) error {
+	var sessions []struct {
+		NowPlayingItem *struct {
+			Name string `json:"Name"`
+		} `json:"NowPlayingItem"`
+	}
+	header := http.Header{"Authorization": {fmt.Sprintf(`MediaBrowser Token="%s"`, apiKey)}}
+	if err := getJSON(ctx, c, baseURL+"/Sessions?activeWithinSeconds=960", header, &sessions); err != nil {
+		return err
+	}
+	playing := 0
+	for _, s := range sessions {
+		if s.NowPlayingItem != nil {
+			playing++
+		}
+	}
+	if playing > 0 {
+		return fmt.Errorf("%d active Jellyfin %s", playing, plural(playing, "session", "sessions"))
+	}
+	return nil
+}
+
+// checkArrQueue returns a check that blocks while a Sonarr or Radarr download
+// queue has items, so imports are not interrupted.
+func checkArrQueue(app string) func(ctx context.Context, c *http.Client, baseURL, apiKey string) error {
+	return func(ctx context.Context, c *http.Client, baseURL, apiKey string) error {
+		var status struct {
+			TotalCount int `json:"totalCount"`
+		}
+		header := http.Header{"X-Api-Key": {apiKey}}
+		if err := getJSON(ctx, c, baseURL+"/api/v3/queue/status", header, &status); err != nil {
+			return err
+		}
+		if n := status.TotalCount; n > 0 {
+			return fmt.Errorf("%d %s in the %s queue", n, plural(n, "item", "items"), app)
+		}
+		return nil
+	}
+}
+
+// checkQBittorrent blocks while qBittorrent is downloading. Without credentials the
+// Web UI must allow the Docksmith container through its authentication bypass.
+func checkQBittorrent(ctx context.Context, c *http.Client, baseURL, credentials string) error {
+	header := http.Header{"Referer": {baseURL}}
+	if credentials != "" {
+		username, password, _ := strings.Cut(credentials, ":")
+		sid, err := qbittorrentLogin(ctx, c, baseURL, username, password)
+		if err != nil {
+			return err
+		}
+		header.Set("Cookie", "SID="+sid)
+	}
+
+	var torrents []struct {
+		Name string `json:"name"`
+	}
+	if err := getJSON(ctx, c, baseURL+"/api/v2/torrents/info?filter=downloading", header, &torrents); err != nil {
+		return err
+	}
+	if n := len(torrents); n > 0 {
+		return fmt.Errorf("%d %s downloading in qBittorrent", n, plural(n, "torrent", "torrents"))
+	}
+	return nil
+}
+
+// qbittorrentLogin logs in to the qBittorrent Web API and returns the session ID.
+func qbittorrentLogin(ctx context.Context, c *http.Client, baseURL, username, password string) (string, error) {
+	form := url.Values{"username": {username}, "password": {password}}
+	req, err := http.NewRequestWithContext(ctx, "POST", baseURL+"/api/v2/auth/login", strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Referer", baseURL)
+
+	resp, err := c.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to log in to qBittorrent: %w", err)
+	}
+	defer resp.Body.Close()
+
+	for _, cookie := range resp.Cookies() {
+		if cookie.Name == "SID" {
+			return cookie.Value, nil
+		}
+	}
+	return "", fmt.Errorf("qBittorrent login failed (status %d)", resp.StatusCode)
+}
+
+// plural returns one or many depending on n.
+func plural(n int, one, many string) string {
+	if n == 1 {
+		return one
+	}
+	return many
+}
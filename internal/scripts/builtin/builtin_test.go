@@ -0,0 +1,114 @@
+package builtin
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	if name, ok := Parse("builtin:Plex"); !ok || name != "plex" {
+		t.Errorf("Parse(builtin:Plex) = %q, %v", name, ok)
+	}
+	if _, ok := Parse("/scripts/check.sh"); ok {
+		t.Error("expected a script path not to be a built-in check")
+	}
+}
+
+func TestRun(t *testing.T) {
+	// Each app reports activity when busy is true
+	busy := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		count := 0
+		if busy {
+			count = 2
+		}
+		switch r.URL.Path {
+		case "/status/sessions":
+			if r.Header.Get("X-Plex-Token") != "plex-token" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			fmt.Fprintf(w, `{"MediaContainer": {"size": %d}}`, count)
+		case "/Sessions":
+			if r.Header.Get("Authorization") != `MediaBrowser Token="jf-key"` {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			if busy {
+				w.Write([]byte(`[{"NowPlayingItem": {"Name": "Film"}}, {"NowPlayingItem": null}]`))
+			} else {
+				w.Write([]byte(`[{"NowPlayingItem": null}]`))
+			}
+		case "/api/v3/queue/status":
+			if r.Header.Get("X-Api-Key") != "arr-key" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			fmt.Fprintf(w, `{"totalCount": %d}`, count)
+		case "/api/v2/auth/login":
+			r.ParseForm()
+			if r.PostForm.Get("username") == "admin" && r.PostForm.Get("password") == "secret" {
+				http.SetCookie(w, &http.Cookie{Name: "SID", Value: "session"})
+			}
+			w.Write([]byte("Ok."))
+		case "/api/v2/torrents/info":
+			if c, err := r.Cookie("SID"); err != nil || c.Value != "session" {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			if busy {
+				w.Write([]byte(`[{"name": "a"}]`))
+			} else {
+				w.Write([]byte(`[]`))
+			}
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name   string
+		apiKey string
+		want   string
+	}{
+		{"plex", "plex-token", "2 active Plex streams"},
+		{"jellyfin", "jf-key", "1 active Jellyfin session"},
+		{"sonarr", "arr-key", "2 items in the Sonarr queue"},
+		{"radarr", "arr-key", "2 items in the Radarr queue"},
+		{"qbittorrent", "admin:secret", "1 torrent downloading in qBittorrent"},
+	}
+	ctx := context.Background()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target := Target{URL: server.URL + "/", APIKey: tt.apiKey}
+
+			busy = false
+			if err := Run(ctx, tt.name, target); err != nil {
+				t.Errorf("expected an idle app to pass, got %v", err)
+			}
+
+			busy = true
+			if err := Run(ctx, tt.name, target); err == nil || err.Error() != tt.want {
+				t.Errorf("expected %q, got %v", tt.want, err)
+			}
+
+			// Wrong credentials fail the check rather than passing it
+			busy = false
+			if err := Run(ctx, tt.name, Target{URL: server.URL, APIKey: "wrong:key"}); err == nil {
+				t.Error("expected wrong credentials to fail")
+			}
+		})
+	}
+}
+
+func TestRun_Unknown(t *testing.T) {
+	err := Run(context.Background(), "emby", Target{Host: "emby"})
+	if err == nil || !strings.Contains(err.Error(), "available: jellyfin, plex, qbittorrent, radarr, sonarr") {
+		t.Errorf("expected an unknown check error listing the checks, got %v", err)
+	}
+}
@@ -10,17 +10,27 @@ import (
 	"time"
 
 	"github.com/chis/docksmith/internal/docker"
+	"github.com/chis/docksmith/internal/scripts/builtin"
 )
 
 // ExecutePreUpdateCheck runs a pre-update check script with validation and timeout.
 // If translatePaths is true, it will translate container paths (e.g., /scripts/xxx.sh)
 // to host paths (e.g., $PWD/scripts/xxx.sh) for CLI usage.
 func ExecutePreUpdateCheck(ctx context.Context, container *docker.Container, scriptPath string, translatePaths bool) error {
-	// Normalize relative paths to absolute paths under /scripts/
-	if !filepath.IsAbs(scriptPath) {
-		scriptPath = filepath.Join("/scripts", scriptPath)
+	if builtin.IsCheck(scriptPath) {
+		return RunBuiltinCheck(ctx, container, scriptPath)
+	}
+	if IsManagedScript(scriptPath) {
+		output, err := RunManagedScript(ctx, container, scriptPath, PreUpdateCheckTimeout)
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			return fmt.Errorf("script exited with code %d: %s", exitErr.ExitCode(), string(output))
+		}
+		return err
 	}
 
+	// Normalize relative paths to absolute paths under /scripts/
+	scriptPath = normalizeScriptPath(scriptPath)
+
 	// Validate script path
 	if !docker.ValidatePreUpdateScript(scriptPath) {
 		return fmt.Errorf("invalid pre-update script path: %s", scriptPath)
@@ -43,7 +53,7 @@ func ExecutePreUpdateCheck(ctx context.Context, container *docker.Container, scr
 	}
 
 	// Execute the check script with timeout
-	checkCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	checkCtx, cancel := context.WithTimeout(ctx, PreUpdateCheckTimeout)
 	defer cancel()
 
 	cmd := exec.CommandContext(checkCtx, translatedPath, container.ID, container.Name)
@@ -59,3 +69,135 @@ func ExecutePreUpdateCheck(ctx context.Context, container *docker.Container, scr
 
 	return nil
 }
+
+// RunBuiltinCheck runs a "builtin:<name>" pre-update check against a container's app,
+// configured by its docksmith.builtin.url and docksmith.builtin.api-key labels.
+// Returns an error describing the activity when the app is busy.
+func RunBuiltinCheck(ctx context.Context, container *docker.Container, check string) error {
+	name, _ := builtin.Parse(check)
+	checkCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	return builtin.Run(checkCtx, name, builtin.Target{
+		Host:   container.Name,
+		URL:    container.Labels[BuiltinURLLabel],
+		APIKey: container.Labels[BuiltinAPIKeyLabel],
+	})
+}
+
+// PreUpdateCheckTimeout bounds pre-update check scripts
+const PreUpdateCheckTimeout = 30 * time.Second
+
+// postUpdateCheckTimeout bounds post-update scripts, which may exercise the
+// updated service and so get longer than pre-update checks
+const postUpdateCheckTimeout = 2 * time.Minute
+
+// maxCheckOutput caps how much script output is kept on the operation record
+const maxCheckOutput = 64 * 1024
+
+// ExecutePostUpdateCheck runs a post-update verification script with validation and timeout.
+// Returns the script's combined stdout/stderr, truncated to the last 64 KiB, along with
+// an error if the script could not run or exited non-zero.
+func ExecutePostUpdateCheck(ctx context.Context, container *docker.Container, scriptPath string) (string, error) {
+	var output []byte
+	var err error
+	managed := IsManagedScript(scriptPath)
+	if managed {
+		output, err = RunManagedScript(ctx, container, scriptPath, postUpdateCheckTimeout)
+	} else {
+		scriptPath = normalizeScriptPath(scriptPath)
+		if !docker.ValidatePreUpdateScript(scriptPath) {
+			return "", fmt.Errorf("invalid post-update script path: %s", scriptPath)
+		}
+
+		checkCtx, cancel := context.WithTimeout(ctx, postUpdateCheckTimeout)
+		defer cancel()
+
+		cmd := exec.CommandContext(checkCtx, scriptPath, container.ID, container.Name)
+		output, err = cmd.CombinedOutput()
+	}
+	if len(output) > maxCheckOutput {
+		output = output[len(output)-maxCheckOutput:]
+	}
+
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			return string(output), fmt.Errorf("script exited with code %d", exitErr.ExitCode())
+		}
+		if managed {
+			return string(output), err // Already describes the failure
+		}
+		return string(output), fmt.Errorf("failed to execute script: %w", err)
+	}
+
+	return string(output), nil
+}
+
+// normalizeScriptPath resolves relative script paths against /scripts/
+func normalizeScriptPath(scriptPath string) string {
+	if !filepath.IsAbs(scriptPath) {
+		return filepath.Join(ScriptsDir, scriptPath)
+	}
+	return scriptPath
+}
+
+// TestResult is the outcome of running a pre-update check on demand.
+type TestResult struct {
+	Container  string `json:"container"`
+	Script     string `json:"script"`
+	Passed     bool   `json:"passed"`
+	ExitCode   int    `json:"exit_code"` // -1 when the script could not be run
+	Output     string `json:"output"`    // Combined stdout/stderr, truncated to the last 64 KiB
+	Error      string `json:"error,omitempty"`
+	DurationMs int64  `json:"duration_ms"`
+}
+
+// TestPreUpdateCheck runs a pre-update check against a container the way an update
+// would, but reports the exit code, output, and duration instead of only pass or fail.
+// Built-in checks exit 1 when the app is busy, with the activity as their output.
+func TestPreUpdateCheck(ctx context.Context, container *docker.Container, scriptPath string) TestResult {
+	result := TestResult{Container: container.Name, Script: scriptPath}
+	start := time.Now()
+
+	var output []byte
+	var err error
+	switch {
+	case builtin.IsCheck(scriptPath):
+		if err = builtin.Validate(scriptPath); err != nil {
+			break
+		}
+		if checkErr := RunBuiltinCheck(ctx, container, scriptPath); checkErr != nil {
+			result.ExitCode = 1
+			output = []byte(checkErr.Error())
+		}
+	case IsManagedScript(scriptPath):
+		output, err = RunManagedScript(ctx, container, scriptPath, PreUpdateCheckTimeout)
+	default:
+		scriptPath = normalizeScriptPath(scriptPath)
+		if !docker.ValidatePreUpdateScript(scriptPath) {
+			err = fmt.Errorf("invalid pre-update script path: %s", scriptPath)
+			break
+		}
+		checkCtx, cancel := context.WithTimeout(ctx, PreUpdateCheckTimeout)
+		defer cancel()
+		output, err = exec.CommandContext(checkCtx, scriptPath, container.ID, container.Name).CombinedOutput()
+	}
+
+	result.DurationMs = time.Since(start).Milliseconds()
+	if len(output) > maxCheckOutput {
+		output = output[len(output)-maxCheckOutput:]
+	}
+	result.Output = string(output)
+
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		result.ExitCode = exitErr.ExitCode()
+		if result.ExitCode == -1 {
+			result.Error = fmt.Sprintf("script was killed: %v", err) // Timeout or signal
+		}
+	} else if err != nil {
+		result.ExitCode = -1
+		result.Error = err.Error()
+	}
+	result.Passed = result.ExitCode == 0
+	return result
+}
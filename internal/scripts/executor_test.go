@@ -0,0 +1,101 @@
+package scripts
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/chis/docksmith/internal/docker"
+)
+
+func writeScript(t *testing.T, body string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "check.sh")
+	require.NoError(t, os.WriteFile(path, []byte("#!/bin/sh\n"+body), 0755))
+	return path
+}
+
+func TestExecutePostUpdateCheck(t *testing.T) {
+	container := &docker.Container{ID: "abc123", Name: "web"}
+
+	t.Run("passing script returns output", func(t *testing.T) {
+		script := writeScript(t, `echo "checking $2"; echo "warn" >&2`)
+		output, err := ExecutePostUpdateCheck(context.Background(), container, script)
+		require.NoError(t, err)
+		assert.Contains(t, output, "checking web")
+		assert.Contains(t, output, "warn")
+	})
+
+	t.Run("failing script returns output and exit code", func(t *testing.T) {
+		script := writeScript(t, `echo "GET /login returned 500" >&2; exit 3`)
+		output, err := ExecutePostUpdateCheck(context.Background(), container, script)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "exited with code 3")
+		assert.Contains(t, output, "GET /login returned 500")
+	})
+
+	t.Run("invalid path is rejected", func(t *testing.T) {
+		_, err := ExecutePostUpdateCheck(context.Background(), container, "/scripts/check.sh; rm -rf /")
+		assert.ErrorContains(t, err, "invalid post-update script path")
+	})
+}
+
+func TestExecutePreUpdateCheck_Builtin(t *testing.T) {
+	queued := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v3/queue/status", r.URL.Path)
+		assert.Equal(t, "key", r.Header.Get("X-Api-Key"))
+		fmt.Fprintf(w, `{"totalCount": %d}`, queued)
+	}))
+	defer server.Close()
+
+	container := &docker.Container{Name: "sonarr", Labels: map[string]string{
+		PreUpdateCheckLabel: "builtin:sonarr",
+		BuiltinURLLabel:     server.URL,
+		BuiltinAPIKeyLabel:  "key",
+	}}
+	require.NoError(t, ExecutePreUpdateCheck(context.Background(), container, "builtin:sonarr", false))
+
+	queued = 3
+	err := ExecutePreUpdateCheck(context.Background(), container, "builtin:sonarr", false)
+	assert.EqualError(t, err, "3 items in the Sonarr queue")
+}
+
+func TestTestPreUpdateCheck(t *testing.T) {
+	container := &docker.Container{ID: "abc123", Name: "web"}
+
+	t.Run("passing script", func(t *testing.T) {
+		result := TestPreUpdateCheck(context.Background(), container, writeScript(t, `echo "checked $2"`))
+		assert.True(t, result.Passed)
+		assert.Equal(t, 0, result.ExitCode)
+		assert.Equal(t, "checked web\n", result.Output)
+		assert.Empty(t, result.Error)
+	})
+
+	t.Run("failing script reports its exit code", func(t *testing.T) {
+		result := TestPreUpdateCheck(context.Background(), container, writeScript(t, `echo "2 active streams"; exit 4`))
+		assert.False(t, result.Passed)
+		assert.Equal(t, 4, result.ExitCode)
+		assert.Equal(t, "2 active streams\n", result.Output)
+	})
+
+	t.Run("missing script cannot run", func(t *testing.T) {
+		result := TestPreUpdateCheck(context.Background(), container, filepath.Join(t.TempDir(), "missing.sh"))
+		assert.False(t, result.Passed)
+		assert.Equal(t, -1, result.ExitCode)
+		assert.NotEmpty(t, result.Error)
+	})
+
+	t.Run("unknown built-in check", func(t *testing.T) {
+		result := TestPreUpdateCheck(context.Background(), container, "builtin:nope")
+		assert.Equal(t, -1, result.ExitCode)
+		assert.Contains(t, result.Error, "unknown built-in check")
+	})
+}
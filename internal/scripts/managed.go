@@ -0,0 +1,291 @@
+package scripts
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/chis/docksmith/internal/docker"
+	"github.com/chis/docksmith/internal/storage"
+)
+
+// ManagedPrefix marks a script reference as a script managed through the API:
+// "managed:<name>@<revision>", or "managed:<name>" for its latest revision.
+const ManagedPrefix = "managed:"
+
+// maxManagedScriptSize caps the content of a managed script
+const maxManagedScriptSize = 1 << 20
+
+// ManagedScriptsDir holds the content of managed scripts, a directory per script with
+// an executable file and a metadata file per revision. It is under /data so the
+// scripts persist with the database.
+var ManagedScriptsDir = "/data/scripts"
+
+// ErrManagedScriptNotFound is returned for managed scripts or revisions that do not exist.
+var ErrManagedScriptNotFound = errors.New("managed script not found")
+
+// scriptNamePattern matches managed script names; they cannot contain path separators.
+var scriptNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{0,63}\.sh$`)
+
+// ManagedScript is a revision of a managed script with its content.
+type ManagedScript struct {
+	storage.ScriptRevision
+	Ref     string `json:"ref"`
+	Content string `json:"content"`
+}
+
+// IsManagedScript reports whether a script reference selects a managed script.
+func IsManagedScript(ref string) bool {
+	return strings.HasPrefix(ref, ManagedPrefix)
+}
+
+// ManagedScriptRef returns the reference to a revision of a managed script.
+func ManagedScriptRef(name string, revision int) string {
+	return fmt.Sprintf("%s%s@%d", ManagedPrefix, name, revision)
+}
+
+// ParseManagedRef returns the script name and revision of a managed script reference.
+// The revision is 0 when the reference selects the latest revision.
+func ParseManagedRef(ref string) (name string, revision int, err error) {
+	name, rev, pinned := strings.Cut(strings.TrimPrefix(ref, ManagedPrefix), "@")
+	if err := ValidateScriptName(name); err != nil {
+		return "", 0, err
+	}
+	if pinned {
+		if revision, err = strconv.Atoi(rev); err != nil || revision < 1 {
+			return "", 0, fmt.Errorf("invalid revision in %q", ref)
+		}
+	}
+	return name, revision, nil
+}
+
+// ValidateScriptName returns an error if name cannot be used for a managed script.
+func ValidateScriptName(name string) error {
+	if !scriptNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid script name %q: use letters, digits, '.', '_' and '-', ending in .sh", name)
+	}
+	return nil
+}
+
+// managedRevisionPath returns the path of the executable of a managed script revision.
+func managedRevisionPath(name string, revision int) string {
+	return filepath.Join(ManagedScriptsDir, name, strconv.Itoa(revision))
+}
+
+// SaveManagedScript stores content as the next revision of a managed script, with the
+// name, sandbox limits, and creator of revision. Saving the same content and limits as
+// the latest revision returns that revision instead of creating a new one.
+func (m *Manager) SaveManagedScript(ctx context.Context, revision storage.ScriptRevision, content []byte) (storage.ScriptRevision, error) {
+	if err := ValidateScriptName(revision.Name); err != nil {
+		return storage.ScriptRevision{}, err
+	}
+	if len(content) > maxManagedScriptSize {
+		return storage.ScriptRevision{}, fmt.Errorf("script is larger than %d bytes", maxManagedScriptSize)
+	}
+	if !bytes.HasPrefix(content, []byte("#!")) {
+		return storage.ScriptRevision{}, fmt.Errorf("script must start with an interpreter line such as #!/bin/sh")
+	}
+	if revision.TimeoutSeconds < 0 || revision.MemoryLimitMB < 0 || revision.CPUSeconds < 0 {
+		return storage.ScriptRevision{}, fmt.Errorf("sandbox limits cannot be negative")
+	}
+
+	sum := sha256.Sum256(content)
+	revision.SHA256 = hex.EncodeToString(sum[:])
+	revision.Size = int64(len(content))
+
+	latest, found, err := m.storage.GetScriptRevision(ctx, revision.Name, 0)
+	if err != nil {
+		return storage.ScriptRevision{}, fmt.Errorf("failed to get latest revision: %w", err)
+	}
+	if found && latest.SHA256 == revision.SHA256 && latest.TimeoutSeconds == revision.TimeoutSeconds &&
+		latest.NoNetwork == revision.NoNetwork && latest.MemoryLimitMB == revision.MemoryLimitMB &&
+		latest.CPUSeconds == revision.CPUSeconds {
+		return latest, nil
+	}
+	revision.Revision = latest.Revision + 1
+	revision.CreatedAt = time.Now()
+
+	// The metadata is also written next to the content so a revision can run without the database
+	metadata, err := json.Marshal(revision)
+	if err != nil {
+		return storage.ScriptRevision{}, fmt.Errorf("failed to encode metadata: %w", err)
+	}
+	path := managedRevisionPath(revision.Name, revision.Revision)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return storage.ScriptRevision{}, fmt.Errorf("failed to create script directory: %w", err)
+	}
+	if err := os.WriteFile(path, content, 0755); err != nil {
+		return storage.ScriptRevision{}, fmt.Errorf("failed to write script: %w", err)
+	}
+	if err := os.WriteFile(path+".json", metadata, 0644); err != nil {
+		os.Remove(path)
+		return storage.ScriptRevision{}, fmt.Errorf("failed to write script metadata: %w", err)
+	}
+
+	if err := m.storage.SaveScriptRevision(ctx, revision); err != nil {
+		os.Remove(path)
+		os.Remove(path + ".json")
+		return storage.ScriptRevision{}, err
+	}
+	return revision, nil
+}
+
+// GetManagedScript returns a revision of a managed script with its content.
+// A revision of 0 returns the latest revision.
+func (m *Manager) GetManagedScript(ctx context.Context, name string, revision int) (*ManagedScript, error) {
+	rev, found, err := m.storage.GetScriptRevision(ctx, name, revision)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, fmt.Errorf("%w: %s", ErrManagedScriptNotFound, name)
+	}
+	content, err := os.ReadFile(managedRevisionPath(rev.Name, rev.Revision))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read script: %w", err)
+	}
+	return &ManagedScript{ScriptRevision: rev, Ref: ManagedScriptRef(rev.Name, rev.Revision), Content: string(content)}, nil
+}
+
+// ListManagedScripts returns the latest revision of each managed script, ordered by name.
+func (m *Manager) ListManagedScripts(ctx context.Context) ([]storage.ScriptRevision, error) {
+	revisions, err := m.storage.ListScriptRevisions(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	latest := make([]storage.ScriptRevision, 0)
+	for _, rev := range revisions {
+		if len(latest) == 0 || latest[len(latest)-1].Name != rev.Name {
+			latest = append(latest, rev)
+		}
+	}
+	return latest, nil
+}
+
+// ListScriptRevisions returns the revisions of a managed script, newest first.
+func (m *Manager) ListScriptRevisions(ctx context.Context, name string) ([]storage.ScriptRevision, error) {
+	return m.storage.ListScriptRevisions(ctx, name)
+}
+
+// pinManagedScript returns the reference to the revision a managed script reference
+// selects, so an assignment keeps running that revision after the script is edited.
+func (m *Manager) pinManagedScript(ctx context.Context, ref string) (string, error) {
+	name, revision, err := ParseManagedRef(ref)
+	if err != nil {
+		return "", err
+	}
+	rev, found, err := m.storage.GetScriptRevision(ctx, name, revision)
+	if err != nil {
+		return "", fmt.Errorf("failed to get script revision: %w", err)
+	}
+	if !found {
+		return "", fmt.Errorf("%w: %s", ErrManagedScriptNotFound, strings.TrimPrefix(ref, ManagedPrefix))
+	}
+	return ManagedScriptRef(rev.Name, rev.Revision), nil
+}
+
+// loadManagedRevision reads the metadata of the revision a managed script reference
+// selects from disk. The latest revision is the highest numbered one.
+func loadManagedRevision(ref string) (storage.ScriptRevision, error) {
+	name, revision, err := ParseManagedRef(ref)
+	if err != nil {
+		return storage.ScriptRevision{}, err
+	}
+	if revision == 0 {
+		entries, err := os.ReadDir(filepath.Join(ManagedScriptsDir, name))
+		if err != nil && !os.IsNotExist(err) {
+			return storage.ScriptRevision{}, fmt.Errorf("failed to read script directory: %w", err)
+		}
+		for _, entry := range entries {
+			if n, err := strconv.Atoi(entry.Name()); err == nil && n > revision {
+				revision = n
+			}
+		}
+	}
+
+	var rev storage.ScriptRevision
+	data, err := os.ReadFile(managedRevisionPath(name, revision) + ".json")
+	if os.IsNotExist(err) {
+		return rev, fmt.Errorf("%w: %s", ErrManagedScriptNotFound, strings.TrimPrefix(ref, ManagedPrefix))
+	}
+	if err != nil {
+		return rev, fmt.Errorf("failed to read script metadata: %w", err)
+	}
+	if err := json.Unmarshal(data, &rev); err != nil {
+		return rev, fmt.Errorf("failed to decode script metadata: %w", err)
+	}
+	return rev, nil
+}
+
+// ValidateManagedRef returns an error if a managed script reference does not select
+// an existing revision.
+func ValidateManagedRef(ref string) error {
+	_, err := loadManagedRevision(ref)
+	return err
+}
+
+// RunManagedScript runs a managed script revision against a container in its sandbox:
+// with only PATH, HOME, CONTAINER_ID, and CONTAINER_NAME in the environment, its
+// timeout (defaultTimeout when unset), and optionally without network access and with
+// memory and CPU time limits. Returns the combined output; a non-zero exit is an *exec.ExitError.
+func RunManagedScript(ctx context.Context, container *docker.Container, ref string, defaultTimeout time.Duration) ([]byte, error) {
+	rev, err := loadManagedRevision(ref)
+	if err != nil {
+		return nil, err
+	}
+	path := managedRevisionPath(rev.Name, rev.Revision)
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read script: %w", err)
+	}
+	if sum := sha256.Sum256(content); hex.EncodeToString(sum[:]) != rev.SHA256 {
+		return nil, fmt.Errorf("managed script %s@%d was modified on disk", rev.Name, rev.Revision)
+	}
+
+	timeout := defaultTimeout
+	if rev.TimeoutSeconds > 0 {
+		timeout = time.Duration(rev.TimeoutSeconds) * time.Second
+	}
+	runCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(runCtx, path, container.ID, container.Name)
+	cmd.Dir = os.TempDir()
+	cmd.Env = []string{
+		"PATH=/usr/local/sbin:/usr/local/bin:/usr/sbin:/usr/bin:/sbin:/bin",
+		"HOME=" + os.TempDir(),
+		"CONTAINER_ID=" + container.ID,
+		"CONTAINER_NAME=" + container.Name,
+	}
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+
+	if rev.NoNetwork {
+		if err := isolateNetwork(cmd); err != nil {
+			return nil, err
+		}
+	}
+	if err := limitResources(cmd, rev.MemoryLimitMB, rev.CPUSeconds); err != nil {
+		return nil, fmt.Errorf("failed to apply resource limits: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		if rev.NoNetwork {
+			return nil, fmt.Errorf("failed to execute script without network access: %w", err)
+		}
+		return nil, fmt.Errorf("failed to execute script: %w", err)
+	}
+	err = cmd.Wait()
+	return output.Bytes(), err
+}
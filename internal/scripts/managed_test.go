@@ -0,0 +1,117 @@
+package scripts
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/chis/docksmith/internal/docker"
+	"github.com/chis/docksmith/internal/storage"
+)
+
+func newManagedScriptManager(t *testing.T) *Manager {
+	t.Helper()
+	dir := ManagedScriptsDir
+	ManagedScriptsDir = t.TempDir()
+	t.Cleanup(func() { ManagedScriptsDir = dir })
+	return NewManager(storage.NewMemoryStorage(), nil)
+}
+
+func TestParseManagedRef(t *testing.T) {
+	name, revision, err := ParseManagedRef("managed:check.sh@3")
+	require.NoError(t, err)
+	assert.Equal(t, "check.sh", name)
+	assert.Equal(t, 3, revision)
+
+	_, revision, err = ParseManagedRef("managed:check.sh")
+	require.NoError(t, err)
+	assert.Zero(t, revision)
+
+	for _, ref := range []string{"managed:../etc/passwd.sh", "managed:check", "managed:check.sh@0", "managed:check.sh@x"} {
+		_, _, err := ParseManagedRef(ref)
+		assert.Error(t, err, ref)
+	}
+}
+
+func TestSaveManagedScript(t *testing.T) {
+	ctx := context.Background()
+	manager := newManagedScriptManager(t)
+
+	first, err := manager.SaveManagedScript(ctx, storage.ScriptRevision{Name: "check.sh"}, []byte("#!/bin/sh\nexit 0\n"))
+	require.NoError(t, err)
+	assert.Equal(t, 1, first.Revision)
+
+	same, err := manager.SaveManagedScript(ctx, storage.ScriptRevision{Name: "check.sh"}, []byte("#!/bin/sh\nexit 0\n"))
+	require.NoError(t, err)
+	assert.Equal(t, 1, same.Revision, "unchanged content should not create a revision")
+
+	second, err := manager.SaveManagedScript(ctx, storage.ScriptRevision{Name: "check.sh", TimeoutSeconds: 5}, []byte("#!/bin/sh\nexit 1\n"))
+	require.NoError(t, err)
+	assert.Equal(t, 2, second.Revision)
+
+	script, err := manager.GetManagedScript(ctx, "check.sh", 1)
+	require.NoError(t, err)
+	assert.Equal(t, "#!/bin/sh\nexit 0\n", script.Content)
+	assert.Equal(t, "managed:check.sh@1", script.Ref)
+
+	_, err = manager.SaveManagedScript(ctx, storage.ScriptRevision{Name: "check.sh"}, []byte("exit 0\n"))
+	assert.ErrorContains(t, err, "interpreter line")
+	_, err = manager.GetManagedScript(ctx, "missing.sh", 0)
+	assert.ErrorIs(t, err, ErrManagedScriptNotFound)
+
+	// Assigning without a revision pins the current one
+	require.NoError(t, manager.AssignScript(ctx, "web", "managed:check.sh", "test"))
+	assignment, found, err := manager.storage.GetScriptAssignment(ctx, "web")
+	require.NoError(t, err)
+	require.True(t, found)
+	assert.Equal(t, "managed:check.sh@2", assignment.ScriptPath)
+}
+
+func TestRunManagedScript(t *testing.T) {
+	ctx := context.Background()
+	manager := newManagedScriptManager(t)
+	container := &docker.Container{ID: "abc123", Name: "web"}
+	t.Setenv("DOCKSMITH_SECRET", "hunter2")
+
+	_, err := manager.SaveManagedScript(ctx, storage.ScriptRevision{Name: "env.sh"},
+		[]byte("#!/bin/sh\necho \"$CONTAINER_NAME $1 secret=$DOCKSMITH_SECRET\"\n"))
+	require.NoError(t, err)
+
+	output, err := RunManagedScript(ctx, container, "managed:env.sh", PreUpdateCheckTimeout)
+	require.NoError(t, err)
+	assert.Equal(t, "web abc123 secret=\n", string(output), "scripts must not see Docksmith's environment")
+
+	t.Run("pre-update check fails on non-zero exit", func(t *testing.T) {
+		_, err := manager.SaveManagedScript(ctx, storage.ScriptRevision{Name: "busy.sh"}, []byte("#!/bin/sh\necho busy\nexit 2\n"))
+		require.NoError(t, err)
+		err = ExecutePreUpdateCheck(ctx, container, "managed:busy.sh@1", false)
+		assert.ErrorContains(t, err, "exited with code 2: busy")
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		_, err := manager.SaveManagedScript(ctx, storage.ScriptRevision{Name: "slow.sh", TimeoutSeconds: 1}, []byte("#!/bin/sh\nexec sleep 5\n"))
+		require.NoError(t, err)
+		_, err = RunManagedScript(ctx, container, "managed:slow.sh", PreUpdateCheckTimeout)
+		assert.Error(t, err)
+	})
+
+	t.Run("resource limits apply from the start", func(t *testing.T) {
+		_, err := manager.SaveManagedScript(ctx, storage.ScriptRevision{Name: "limits.sh", MemoryLimitMB: 512, CPUSeconds: 5},
+			[]byte("#!/bin/sh\necho \"$(ulimit -v) $(ulimit -t) $1 $2\"\n"))
+		require.NoError(t, err)
+		output, err := RunManagedScript(ctx, container, "managed:limits.sh", PreUpdateCheckTimeout)
+		require.NoError(t, err)
+		assert.Equal(t, "524288 5 abc123 web\n", string(output))
+	})
+
+	t.Run("modified on disk", func(t *testing.T) {
+		path := filepath.Join(ManagedScriptsDir, "env.sh", "1")
+		require.NoError(t, os.WriteFile(path, []byte("#!/bin/sh\nexit 0\n"), 0755))
+		_, err := RunManagedScript(ctx, container, "managed:env.sh@1", PreUpdateCheckTimeout)
+		assert.ErrorContains(t, err, "modified on disk")
+	})
+}
@@ -18,8 +18,27 @@ const (
 	ScriptsDir = "/scripts"
 
 	// PreUpdateCheckLabel is the Docker label key for pre-update checks
+	// A script path, "managed:<name>[@<revision>]" for a script managed through the API,
+	// or "builtin:<name>" for a built-in check (see package builtin).
+	// Example: "/scripts/check-backups.sh", "managed:check-backups.sh@2", or "builtin:plex"
 	PreUpdateCheckLabel = "docksmith.pre-update-check"
 
+	// BuiltinURLLabel is the Docker label key for the base URL a built-in pre-update check queries
+	// Example: "http://192.168.1.10:32400" for Plex on host networking
+	// Default: http://<container name>:<the app's default port>
+	BuiltinURLLabel = "docksmith.builtin.url"
+
+	// BuiltinAPIKeyLabel is the Docker label key for the API key or token of a built-in
+	// pre-update check; "username:password" for qBittorrent
+	// Example: "0123456789abcdef" (Sonarr API key)
+	BuiltinAPIKeyLabel = "docksmith.builtin.api-key"
+
+	// PostUpdateCheckLabel is the Docker label key for post-update verification scripts
+	// The script runs after the container passes its health check. A non-zero exit fails
+	// the update and triggers the rollback policy; its output is kept on the operation.
+	// Example: "/scripts/smoke-test.sh"
+	PostUpdateCheckLabel = "docksmith.post-update-check"
+
 	// IgnoreLabel is the Docker label key to ignore containers from update checks
 	IgnoreLabel = "docksmith.ignore"
 
@@ -75,12 +94,179 @@ const (
 	// Default: "" (no maximum)
 	VersionMaxLabel = "docksmith.version-max"
 
+	// VersionConstraintLabel is the Docker label key to restrict updates to a semver range
+	// Accepts caret, tilde, comparison, wildcard and hyphen ranges combined with "||".
+	// Example: "^2.4" to stay on 2.x at or above 2.4
+	//          ">=3 <4" to stay on 3.x
+	// Default: "" (no constraint)
+	VersionConstraintLabel = "docksmith.version-constraint"
+
+	// TagPatternLabel is the Docker label key to select a publisher tag convention
+	// Defaults to "auto", which detects known conventions from the image repository.
+	// Example: "linuxserver" to order "1.32.3-ls123" builds and track "version-1.32.3" aliases
+	//          "none" to disable publisher-specific tag handling
+	TagPatternLabel = "docksmith.tag-pattern"
+
 	// AllowPrereleaseLabel is the Docker label key to allow prerelease versions
 	// When set to "true", prerelease versions (alpha, beta, rc, pre, etc.) will be considered for updates.
 	// By default, prereleases are skipped unless you're already running a prerelease version.
 	// Example: Set to "true" on a container where you want to track beta releases
 	// Default: false (skip prerelease versions)
 	AllowPrereleaseLabel = "docksmith.allow-prerelease"
+
+	// RequireApprovalLabel is the Docker label key to gate updates behind operator approval.
+	// Detected updates are queued as pending approvals and only applied once approved.
+	// "major" gates only major version changes and applies patch and minor updates automatically.
+	// Example: Set to "true" on a database container that must not update unattended
+	// Default: false (the container, stack, or global approval policy applies)
+	RequireApprovalLabel = "docksmith.require-approval"
+
+	// GroupLabel is the Docker label key for custom groups a container belongs to
+	// Groups can be checked, updated, ignored, or scheduled together regardless of
+	// compose project. Separate multiple groups with commas.
+	// Example: "media" or "media,critical"
+	// Default: "" (no group)
+	GroupLabel = "docksmith.group"
+
+	// ArchFallbackLabel is the Docker label key to fall back to the newest tag with an
+	// image for the host architecture when the latest tag has none
+	// Example: Set to "true" on a Raspberry Pi when new releases ship amd64 images first
+	// Default: ARCH_FALLBACK (false, the update is reported as unavailable)
+	ArchFallbackLabel = "docksmith.arch-fallback"
+
+	// CheckTimeoutLabel is the Docker label key for how long an update check of this
+	// container may take before it is reported as failed, so a slow registry does not
+	// hold up the checks of other containers
+	// Example: "1m" for an image on a slow self-hosted registry, or "0" for no limit
+	// Default: CHECK_TIMEOUT (20s)
+	CheckTimeoutLabel = "docksmith.check-timeout"
+
+	// SignaturePolicyLabel is the Docker label key for what happens when the signature of
+	// an update's image cannot be verified: "off", "warn" (log and record), or "block"
+	// Example: "block" on containers that must only run signed images
+	// Default: SIGNATURE_POLICY (off)
+	SignaturePolicyLabel = "docksmith.signature-policy"
+
+	// SignatureKeyLabel is the Docker label key for the cosign public key (PEM file)
+	// this container's images are verified with, instead of SIGNATURE_PUBLIC_KEY
+	// Example: "/keys/vendor.pub"
+	// Default: SIGNATURE_PUBLIC_KEY, or keyless verification when unset
+	SignatureKeyLabel = "docksmith.signature-key"
+
+	// UpdateDelayLabel is the Docker label key for how long a batch update waits after this
+	// container is healthy before updating containers in the next dependency level
+	// Example: "30s" on a database so its apps wait for it to warm up
+	// Default: STACK_LEVEL_DELAY (0, no wait)
+	UpdateDelayLabel = "docksmith.update-delay"
+
+	// UpdateStrategyLabel is the Docker label key for how a scaled service is updated
+	// "canary" updates one replica first and only rolls the rest once it passes its
+	// health and post-update checks. Ignored for services with a single replica.
+	// Example: "canary"
+	// Default: "recreate" (all replicas are recreated at once)
+	UpdateStrategyLabel = "docksmith.update-strategy"
+
+	// DeferUnderLoadLabel is the Docker label key for whether updates of this container
+	// wait while its CPU or memory usage is above the DEFER_UPDATE_* thresholds
+	// Example: "false" for a container that is always busy
+	// Default: "true" when a threshold is configured
+	DeferUnderLoadLabel = "docksmith.defer-under-load"
+
+	// DeferConnectionsLabel is the Docker label key for how many established TCP connections
+	// to this container's published ports are tolerated; updates wait while there are more
+	// Example: "0" on a media server so updates wait until no one is streaming
+	// Default: "" (connections are not checked)
+	DeferConnectionsLabel = "docksmith.defer-connections"
+
+	// ObserveWindowLabel is the Docker label key for how long this container is watched
+	// for restarts after an update; restarting more than POST_UPDATE_MAX_RESTARTS times
+	// within it flags a crash loop
+	// Example: "1h" for a service that fails slowly, or "0" to not watch it
+	// Default: POST_UPDATE_OBSERVE_WINDOW (0, not watched)
+	ObserveWindowLabel = "docksmith.observe-window"
+
+	// StopTimeoutLabel is the Docker label key for how long this container gets to shut
+	// down after the stop signal before it is killed when updates stop or recreate it;
+	// overrides the compose stop_grace_period
+	// Example: "2m" for a database or game server that saves on shutdown, or "120" (seconds)
+	// Default: the compose stop_grace_period, or Docker's 10 seconds
+	StopTimeoutLabel = "docksmith.stop-timeout"
+
+	// BackupVolumesLabel is the Docker label key for snapshotting this container's named
+	// volumes before updates; rolling the update back restores the snapshots
+	// Example: "true" on a database whose migrations cannot be undone
+	// Default: "false"
+	BackupVolumesLabel = "docksmith.backup-volumes"
+
+	// DatabaseLabel is the Docker label key for the database engine of this container;
+	// its databases are dumped to DB_DUMP_DIR before major version updates, which are
+	// blocked if the dump fails
+	// Example: "postgres", "mysql", "mariadb", or "true" to detect from the image name
+	// Default: "" (not dumped)
+	DatabaseLabel = "docksmith.database"
+
+	// EOLLabel is the Docker label key for the endoflife.date product whose lifecycle
+	// this container's version is checked against, flagging versions past end of life.
+	// "false" opts the container out; "true" or unset detects known images by name.
+	// Example: "postgresql" on a custom image built from postgres, or "false"
+	// Default: "" (detected from the image name, e.g. postgres, nginx, node, mariadb)
+	EOLLabel = "docksmith.eol"
+
+	// BaseImageLabel is the Docker label key for the base image tracked for a service
+	// built locally (build: in compose). Checks report updates of the base image instead
+	// of skipping the service. "dockerfile" reads the base image from the final FROM
+	// instruction of the service's Dockerfile.
+	// Example: "node:20.11-alpine" or "dockerfile"
+	// Default: "" (locally built images are not checked)
+	BaseImageLabel = "docksmith.base-image"
+
+	// RebuildOnBaseUpdateLabel is the Docker label key for whether updating a service
+	// tracked with docksmith.base-image rebuilds it (docker compose build --pull and up).
+	// A newer base image tag is written to the FROM instruction of the Dockerfile first.
+	// Example: "true"
+	// Default: "false" (base image updates are only reported)
+	RebuildOnBaseUpdateLabel = "docksmith.rebuild-on-base-update"
+
+	// NotifyLabel is the Docker label key for the notification channels this container's
+	// updates and failures are sent to, instead of every configured channel.
+	// Comma-separated channel names, each optionally followed by a destination of that
+	// service: a webhook URL, a Slack webhook URL or #channel, an ntfy topic or topic
+	// URL, or a Pushover user key. "none" turns the container's notifications off.
+	// Example: "slack:#homelab,ntfy:media-team" or "pushover:uQiRzpo4DXghDmr9QzzfQu27cmVRsG"
+	// Default: "" (every configured channel)
+	NotifyLabel = "docksmith.notify"
+
+	// HealthcheckHTTPLabel is the Docker label key for an HTTP probe run after an update
+	// The update only succeeds once a GET to the URL returns an expected status.
+	// Example: "https://vaultwarden:8443/alive" or "http://localhost:8080/ready"
+	// Default: "" (no HTTP probe)
+	HealthcheckHTTPLabel = "docksmith.healthcheck.http"
+
+	// HealthcheckTCPLabel is the Docker label key for a TCP probe run after an update
+	// Accepts a port, probed on the container's IP address, or a host:port address.
+	// Example: "5432" for a database container
+	// Default: "" (no TCP probe)
+	HealthcheckTCPLabel = "docksmith.healthcheck.tcp"
+
+	// HealthcheckStatusLabel is the Docker label key for the HTTP statuses the probe accepts
+	// Comma-separated codes or ranges.
+	// Example: "200,204" or "200-399"
+	// Default: "200-299"
+	HealthcheckStatusLabel = "docksmith.healthcheck.status"
+
+	// HealthcheckBodyLabel is the Docker label key for a regular expression the HTTP response body must match
+	// Example: "\"status\":\s*\"ok\""
+	// Default: "" (body is not checked)
+	HealthcheckBodyLabel = "docksmith.healthcheck.body"
+
+	// HealthcheckTimeoutLabel is the Docker label key for the timeout of each probe attempt
+	// Default: "5s"
+	HealthcheckTimeoutLabel = "docksmith.healthcheck.timeout"
+
+	// HealthcheckRetriesLabel is the Docker label key for how many times a failing probe is retried
+	// Attempts are two seconds apart.
+	// Default: "3"
+	HealthcheckRetriesLabel = "docksmith.healthcheck.retries"
 )
 
 // Manager handles script discovery, validation, and assignment operations.
@@ -191,8 +377,14 @@ func (m *Manager) ValidateScript(scriptPath string) error {
 // AssignScript assigns a script to a container.
 // Database-only, no compose file modifications. Changes apply on next check.
 func (m *Manager) AssignScript(ctx context.Context, containerName, scriptPath, assignedBy string) error {
-	// Validate script if provided
-	if scriptPath != "" {
+	// Validate script if provided; managed scripts are pinned to their current revision
+	if IsManagedScript(scriptPath) {
+		pinned, err := m.pinManagedScript(ctx, scriptPath)
+		if err != nil {
+			return fmt.Errorf("script validation failed: %w", err)
+		}
+		scriptPath = pinned
+	} else if scriptPath != "" {
 		if err := m.ValidateScript(scriptPath); err != nil {
 			return fmt.Errorf("script validation failed: %w", err)
 		}
@@ -373,6 +565,38 @@ func (m *Manager) MigrateLabels(ctx context.Context, containers []docker.Contain
 	return migrated, nil
 }
 
+// StoredLabels returns the labels set by enabled assignments, by container name.
+// Unset settings have an empty value so they override labels the container was
+// created with.
+func (m *Manager) StoredLabels(ctx context.Context) (map[string]map[string]string, error) {
+	assignments, err := m.storage.ListScriptAssignments(ctx, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list assignments: %w", err)
+	}
+
+	labels := make(map[string]map[string]string, len(assignments))
+	for _, assignment := range assignments {
+		labels[assignment.ContainerName] = AssignmentLabels(assignment)
+	}
+	return labels, nil
+}
+
+// AssignmentLabels returns the labels an assignment sets.
+func AssignmentLabels(assignment storage.ScriptAssignment) map[string]string {
+	labels := map[string]string{
+		PreUpdateCheckLabel: assignment.ScriptPath,
+		IgnoreLabel:         "",
+		AllowLatestLabel:    "",
+	}
+	if assignment.Ignore {
+		labels[IgnoreLabel] = "true"
+	}
+	if assignment.AllowLatest {
+		labels[AllowLatestLabel] = "true"
+	}
+	return labels
+}
+
 // GetAssignment retrieves the assignment for a specific container.
 func (m *Manager) GetAssignment(ctx context.Context, containerName string) (Assignment, bool, error) {
 	dbAssignment, found, err := m.storage.GetScriptAssignment(ctx, containerName)
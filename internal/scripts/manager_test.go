@@ -159,6 +159,274 @@ func (m *mockStorage) GetQueuedUpdates(ctx context.Context) ([]storage.UpdateQue
 	return nil, nil
 }
 
+func (m *mockStorage) QueryUpdateOperations(ctx context.Context, opts storage.OperationQueryOptions) (storage.OperationQueryResult, error) {
+	return storage.OperationQueryResult{}, nil
+}
+
+func (m *mockStorage) DeleteAllHistory(ctx context.Context) (int64, error) {
+	return 0, nil
+}
+
+func (m *mockStorage) DeleteHistoryBefore(ctx context.Context, before time.Time) (int64, error) {
+	return 0, nil
+}
+
+func (m *mockStorage) CreateUser(ctx context.Context, user storage.User) (storage.User, error) {
+	return user, nil
+}
+
+func (m *mockStorage) GetUser(ctx context.Context, username string) (storage.User, bool, error) {
+	return storage.User{}, false, nil
+}
+
+func (m *mockStorage) GetUserByID(ctx context.Context, id int64) (storage.User, bool, error) {
+	return storage.User{}, false, nil
+}
+
+func (m *mockStorage) ListUsers(ctx context.Context) ([]storage.User, error) {
+	return nil, nil
+}
+
+func (m *mockStorage) UpdateUser(ctx context.Context, user storage.User) error {
+	return nil
+}
+
+func (m *mockStorage) DeleteUser(ctx context.Context, username string) error {
+	return nil
+}
+
+func (m *mockStorage) SaveSession(ctx context.Context, session storage.Session) error {
+	return nil
+}
+
+func (m *mockStorage) GetSession(ctx context.Context, tokenHash string) (storage.Session, bool, error) {
+	return storage.Session{}, false, nil
+}
+
+func (m *mockStorage) DeleteSession(ctx context.Context, tokenHash string) error {
+	return nil
+}
+
+func (m *mockStorage) DeleteExpiredSessions(ctx context.Context, now time.Time) (int64, error) {
+	return 0, nil
+}
+
+func (m *mockStorage) SaveApproval(ctx context.Context, approval storage.Approval) error {
+	return nil
+}
+
+func (m *mockStorage) GetApproval(ctx context.Context, id string) (storage.Approval, bool, error) {
+	return storage.Approval{}, false, nil
+}
+
+func (m *mockStorage) GetLatestApproval(ctx context.Context, containerName string) (storage.Approval, bool, error) {
+	return storage.Approval{}, false, nil
+}
+
+func (m *mockStorage) ListApprovals(ctx context.Context, status string, limit int) ([]storage.Approval, error) {
+	return nil, nil
+}
+
+func (m *mockStorage) ExpireApprovals(ctx context.Context, now time.Time) (int64, error) {
+	return 0, nil
+}
+
+func (m *mockStorage) SaveProposal(ctx context.Context, proposal storage.Proposal) error {
+	return nil
+}
+
+func (m *mockStorage) GetProposal(ctx context.Context, id string) (storage.Proposal, bool, error) {
+	return storage.Proposal{}, false, nil
+}
+
+func (m *mockStorage) GetLatestProposal(ctx context.Context, containerName string) (storage.Proposal, bool, error) {
+	return storage.Proposal{}, false, nil
+}
+
+func (m *mockStorage) ListProposals(ctx context.Context, status string, limit int) ([]storage.Proposal, error) {
+	return nil, nil
+}
+
+func (m *mockStorage) GetTagCache(ctx context.Context, imageRef string) (storage.TagCacheEntry, bool, error) {
+	return storage.TagCacheEntry{}, false, nil
+}
+
+func (m *mockStorage) SaveTagCache(ctx context.Context, entry storage.TagCacheEntry) error {
+	return nil
+}
+
+func (m *mockStorage) GetCheckFingerprint(ctx context.Context, containerName string) (storage.CheckFingerprint, bool, error) {
+	return storage.CheckFingerprint{}, false, nil
+}
+
+func (m *mockStorage) SaveCheckFingerprint(ctx context.Context, fingerprint storage.CheckFingerprint) error {
+	return nil
+}
+
+func (m *mockStorage) QueryCheckHistory(ctx context.Context, opts storage.CheckHistoryQueryOptions) ([]storage.CheckHistoryEntry, error) {
+	return nil, nil
+}
+
+func (m *mockStorage) ListRollbackPolicies(ctx context.Context) ([]storage.RollbackPolicy, error) {
+	return nil, nil
+}
+
+func (m *mockStorage) Backup(ctx context.Context, destPath string) error {
+	return nil
+}
+
+func (m *mockStorage) Vacuum(ctx context.Context) (storage.VacuumResult, error) {
+	return storage.VacuumResult{}, nil
+}
+
+func (m *mockStorage) PruneHistory(ctx context.Context, opts storage.PruneOptions) (storage.PruneResult, error) {
+	return storage.PruneResult{}, nil
+}
+
+func (m *mockStorage) ListComposeBackups(ctx context.Context) ([]storage.ComposeBackup, error) {
+	return nil, nil
+}
+
+func (m *mockStorage) DeleteComposeBackups(ctx context.Context, ids []int64) (int64, error) {
+	return 0, nil
+}
+
+func (m *mockStorage) GetUnfinishedOperations(ctx context.Context) ([]storage.UpdateOperation, error) {
+	return nil, nil
+}
+
+func (m *mockStorage) SaveScriptRevision(ctx context.Context, revision storage.ScriptRevision) error {
+	return nil
+}
+
+func (m *mockStorage) GetScriptRevision(ctx context.Context, name string, revision int) (storage.ScriptRevision, bool, error) {
+	return storage.ScriptRevision{}, false, nil
+}
+
+func (m *mockStorage) ListScriptRevisions(ctx context.Context, name string) ([]storage.ScriptRevision, error) {
+	return nil, nil
+}
+
+func (m *mockStorage) SaveVolumeSnapshot(ctx context.Context, snapshot storage.VolumeSnapshot) error {
+	return nil
+}
+
+func (m *mockStorage) GetVolumeSnapshots(ctx context.Context, operationID string) ([]storage.VolumeSnapshot, error) {
+	return nil, nil
+}
+
+func (m *mockStorage) ListVolumeSnapshots(ctx context.Context, volumeName string) ([]storage.VolumeSnapshot, error) {
+	return nil, nil
+}
+
+func (m *mockStorage) DeleteVolumeSnapshot(ctx context.Context, id int64) error {
+	return nil
+}
+
+func (m *mockStorage) GetApprovalPolicy(ctx context.Context, entityType, entityID string) (storage.ApprovalPolicy, bool, error) {
+	return storage.ApprovalPolicy{}, false, nil
+}
+
+func (m *mockStorage) SetApprovalPolicy(ctx context.Context, policy storage.ApprovalPolicy) error {
+	return nil
+}
+
+func (m *mockStorage) ListApprovalPolicies(ctx context.Context) ([]storage.ApprovalPolicy, error) {
+	return nil, nil
+}
+
+func (m *mockStorage) DeleteApprovalPolicy(ctx context.Context, entityType, entityID string) error {
+	return nil
+}
+
+func (m *mockStorage) AppendOperationLog(ctx context.Context, entry storage.OperationLogEntry) (int64, error) {
+	return 0, nil
+}
+
+func (m *mockStorage) GetOperationLogs(ctx context.Context, operationID string, afterID int64) ([]storage.OperationLogEntry, error) {
+	return nil, nil
+}
+
+func (m *mockStorage) AppendComposeOutput(ctx context.Context, operationID string, output string) error {
+	return nil
+}
+
+func (m *mockStorage) ListIgnoreRules(ctx context.Context) ([]storage.IgnoreRule, error) {
+	return nil, nil
+}
+
+func (m *mockStorage) SaveIgnoreRule(ctx context.Context, rule storage.IgnoreRule) (storage.IgnoreRule, error) {
+	return rule, nil
+}
+
+func (m *mockStorage) DeleteIgnoreRule(ctx context.Context, id int64) (bool, error) {
+	return false, nil
+}
+
+func (m *mockStorage) GetStackEnv(ctx context.Context, stack string) (storage.StackEnv, bool, error) {
+	return storage.StackEnv{}, false, nil
+}
+
+func (m *mockStorage) SetStackEnv(ctx context.Context, env storage.StackEnv) error {
+	return nil
+}
+
+func (m *mockStorage) ListStackEnvs(ctx context.Context) ([]storage.StackEnv, error) {
+	return nil, nil
+}
+
+func (m *mockStorage) DeleteStackEnv(ctx context.Context, stack string) (bool, error) {
+	return false, nil
+}
+
+func (m *mockStorage) ListOwnerships(ctx context.Context) ([]storage.Ownership, error) {
+	return nil, nil
+}
+
+func (m *mockStorage) AddOwnership(ctx context.Context, ownership storage.Ownership) error {
+	return nil
+}
+
+func (m *mockStorage) RemoveOwnership(ctx context.Context, entityType, entityID, owner string) (bool, error) {
+	return false, nil
+}
+
+func (m *mockStorage) ListTeamMembers(ctx context.Context) ([]storage.TeamMember, error) {
+	return nil, nil
+}
+
+func (m *mockStorage) AddTeamMember(ctx context.Context, team, username string) error {
+	return nil
+}
+
+func (m *mockStorage) RemoveTeamMember(ctx context.Context, team, username string) (bool, error) {
+	return false, nil
+}
+
+func (m *mockStorage) CheckWritable(ctx context.Context) error {
+	return nil
+}
+
+func (m *mockStorage) SaveStackLock(ctx context.Context, lock storage.StackLock) error {
+	return nil
+}
+
+func (m *mockStorage) DeleteStackLock(ctx context.Context, stackName, operationID string) error {
+	return nil
+}
+
+func (m *mockStorage) ListStackLocks(ctx context.Context) ([]storage.StackLock, error) {
+	return nil, nil
+}
+
+func (m *mockStorage) SetQueuePriorities(ctx context.Context, priorities map[string]int) error {
+	return nil
+}
+
+func (m *mockStorage) DeleteQueuedUpdate(ctx context.Context, operationID string) (bool, error) {
+	return false, nil
+}
+
 // TestNewManager tests the Manager constructor
 func TestNewManager(t *testing.T) {
 	mockStore := newMockStorage()
@@ -518,6 +786,8 @@ func TestConstants(t *testing.T) {
 	assert.Equal(t, "docksmith.tag-regex", TagRegexLabel)
 	assert.Equal(t, "docksmith.version-min", VersionMinLabel)
 	assert.Equal(t, "docksmith.version-max", VersionMaxLabel)
+	assert.Equal(t, "docksmith.version-constraint", VersionConstraintLabel)
+	assert.Equal(t, "docksmith.tag-pattern", TagPatternLabel)
 }
 
 // TestAssignmentTimestamps verifies timestamps are set correctly
@@ -539,3 +809,17 @@ func TestAssignmentTimestamps(t *testing.T) {
 	assert.True(t, assignment.UpdatedAt.After(beforeTest) || assignment.UpdatedAt.Equal(beforeTest))
 	assert.True(t, assignment.UpdatedAt.Before(afterTest) || assignment.UpdatedAt.Equal(afterTest))
 }
+
+// TestStoredLabels tests the labels set by stored assignments
+func TestStoredLabels(t *testing.T) {
+	mockStore := newMockStorage()
+	manager := NewManager(mockStore, nil)
+	mockStore.assignments["web"] = storage.ScriptAssignment{ContainerName: "web", Enabled: true, Ignore: true, ScriptPath: "check.sh"}
+	mockStore.assignments["disabled"] = storage.ScriptAssignment{ContainerName: "disabled", Ignore: true}
+
+	labels, err := manager.StoredLabels(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, map[string]map[string]string{
+		"web": {IgnoreLabel: "true", AllowLatestLabel: "", PreUpdateCheckLabel: "check.sh"},
+	}, labels)
+}
@@ -0,0 +1,49 @@
+//go:build linux
+
+package scripts
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"syscall"
+)
+
+// isolateNetwork makes cmd run in a new network namespace, which only has a loopback
+// interface. Without root, the namespace is created in a new user namespace mapped to
+// the current user; either way the kernel must allow Docksmith to create namespaces.
+// Only the network is isolated: Unix sockets on the filesystem, such as the Docker
+// socket, stay reachable.
+func isolateNetwork(cmd *exec.Cmd) error {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Cloneflags: syscall.CLONE_NEWNET}
+	if uid, gid := os.Geteuid(), os.Getegid(); uid != 0 {
+		cmd.SysProcAttr.Cloneflags |= syscall.CLONE_NEWUSER
+		cmd.SysProcAttr.UidMappings = []syscall.SysProcIDMap{{ContainerID: uid, HostID: uid, Size: 1}}
+		cmd.SysProcAttr.GidMappings = []syscall.SysProcIDMap{{ContainerID: gid, HostID: gid, Size: 1}}
+	}
+	return nil
+}
+
+// limitResources makes cmd run with address space and CPU time limits. They are
+// set with ulimit by a shell that then execs the script, so they are in place
+// before its first instruction and are inherited by every process it starts.
+// Zero leaves a limit unset.
+func limitResources(cmd *exec.Cmd, memoryLimitMB, cpuSeconds int) error {
+	var limits []string
+	if memoryLimitMB > 0 {
+		limits = append(limits, fmt.Sprintf("ulimit -v %d", memoryLimitMB*1024)) // KiB
+	}
+	if cpuSeconds > 0 {
+		limits = append(limits, fmt.Sprintf("ulimit -t %d", cpuSeconds))
+	}
+	if len(limits) == 0 {
+		return nil
+	}
+
+	// The script becomes $0 of the shell, and its arguments $1...
+	script := strings.Join(limits, " && ") + ` && exec "$0" "$@"`
+	cmd.Args = append([]string{"sh", "-c", script, cmd.Path}, cmd.Args[1:]...)
+	cmd.Path = "/bin/sh"
+	return nil
+}
@@ -0,0 +1,19 @@
+//go:build !linux
+
+package scripts
+
+import (
+	"errors"
+	"os/exec"
+)
+
+func isolateNetwork(cmd *exec.Cmd) error {
+	return errors.New("running scripts without network access is only supported on Linux")
+}
+
+func limitResources(cmd *exec.Cmd, memoryLimitMB, cpuSeconds int) error {
+	if memoryLimitMB > 0 || cpuSeconds > 0 {
+		return errors.New("script resource limits are only supported on Linux")
+	}
+	return nil
+}
@@ -0,0 +1,327 @@
+// Package secrets stores tokens and credentials encrypted with AES-256-GCM, so
+// they are not kept in plaintext in the database. Settings refer to a secret by
+// a reference, "secret:NAME", which is resolved where the setting is used.
+// Secret values are never returned by the API, only their references.
+package secrets
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chis/docksmith/internal/storage"
+)
+
+// secretsConfigKey is the config table key holding the JSON map of encrypted secrets.
+const secretsConfigKey = "secrets"
+
+// RefPrefix starts a reference to a secret in a setting.
+const RefPrefix = "secret:"
+
+// KeySize is the size of the encryption key: 32 bytes for AES-256.
+const KeySize = 32
+
+// Sentinel errors
+var (
+	ErrDisabled    = errors.New("secrets store is disabled (set SECRETS_KEY or SECRETS_KEY_FILE)")
+	ErrNotFound    = errors.New("secret not found")
+	ErrInvalidName = errors.New("invalid secret name (letters, digits, '.', '_' and '-', up to 64 characters)")
+)
+
+var namePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{0,63}$`)
+
+// Secret describes a stored secret without its value.
+type Secret struct {
+	Name      string    `json:"name"`
+	Reference string    `json:"reference"` // Use in settings in place of the value
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// entry is a stored secret. Value is the base64 nonce and ciphertext.
+type entry struct {
+	Value     string    `json:"value"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Store encrypts secrets into the config table of a storage.
+type Store struct {
+	storage storage.Storage
+	aead    cipher.AEAD
+	mu      sync.Mutex
+}
+
+// NewStore creates a store encrypting with key, which must be KeySize bytes.
+// A nil key creates a disabled store, which only resolves settings that are not
+// references.
+func NewStore(store storage.Storage, key []byte) (*Store, error) {
+	s := &Store{storage: store}
+	if key == nil {
+		return s, nil
+	}
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("secrets key must be %d bytes, got %d", KeySize, len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	if s.aead, err = cipher.NewGCM(block); err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	return s, nil
+}
+
+// KeyFromEnv reads the base64 encryption key from SECRETS_KEY, or from the file
+// named by SECRETS_KEY_FILE (e.g. a Docker secret). Returns nil when neither is set.
+func KeyFromEnv() ([]byte, error) {
+	value := os.Getenv("SECRETS_KEY")
+	if value == "" {
+		path := os.Getenv("SECRETS_KEY_FILE")
+		if path == "" {
+			return nil, nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read SECRETS_KEY_FILE: %w", err)
+		}
+		value = string(data)
+	}
+	return ParseKey(value)
+}
+
+// ParseKey decodes a base64 encryption key, as generated by `openssl rand -base64 32`.
+func ParseKey(value string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(value))
+	if err != nil {
+		return nil, fmt.Errorf("secrets key is not valid base64: %w", err)
+	}
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("secrets key must be %d bytes, got %d", KeySize, len(key))
+	}
+	return key, nil
+}
+
+// NewStoreFromEnv creates a store with the key of KeyFromEnv. The store is
+// disabled when no key is set or the key is invalid.
+func NewStoreFromEnv(store storage.Storage) *Store {
+	key, err := KeyFromEnv()
+	if err != nil {
+		log.Printf("Warning: Invalid secrets key, secrets store disabled: %v", err)
+		key = nil
+	}
+	s, err := NewStore(store, key)
+	if err != nil {
+		log.Printf("Warning: Secrets store disabled: %v", err)
+		s, _ = NewStore(store, nil)
+	}
+	return s
+}
+
+// Reference returns the reference to the secret name.
+func Reference(name string) string {
+	return RefPrefix + name
+}
+
+// ParseReference returns the secret name of a reference.
+func ParseReference(value string) (string, bool) {
+	name, ok := strings.CutPrefix(strings.TrimSpace(value), RefPrefix)
+	return name, ok && name != ""
+}
+
+// ValidateName checks that name can be used for a secret.
+func ValidateName(name string) error {
+	if !namePattern.MatchString(name) {
+		return ErrInvalidName
+	}
+	return nil
+}
+
+// Enabled reports whether the store has an encryption key.
+func (s *Store) Enabled() bool {
+	return s != nil && s.aead != nil
+}
+
+// List returns the stored secrets, sorted by name.
+func (s *Store) List(ctx context.Context) ([]Secret, error) {
+	if !s.Enabled() {
+		return nil, ErrDisabled
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries, err := s.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	list := make([]Secret, 0, len(entries))
+	for name, e := range entries {
+		list = append(list, describe(name, e))
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list, nil
+}
+
+// Set encrypts and stores a secret, replacing the value of an existing one.
+func (s *Store) Set(ctx context.Context, name, value string) (Secret, error) {
+	if !s.Enabled() {
+		return Secret{}, ErrDisabled
+	}
+	if err := ValidateName(name); err != nil {
+		return Secret{}, err
+	}
+	if value == "" {
+		return Secret{}, fmt.Errorf("secret value is required")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries, err := s.load(ctx)
+	if err != nil {
+		return Secret{}, err
+	}
+	sealed, err := s.seal(name, value)
+	if err != nil {
+		return Secret{}, err
+	}
+
+	now := time.Now().UTC()
+	e, ok := entries[name]
+	if !ok {
+		e.CreatedAt = now
+	}
+	e.Value = sealed
+	e.UpdatedAt = now
+	entries[name] = e
+
+	if err := s.save(ctx, entries); err != nil {
+		return Secret{}, err
+	}
+	return describe(name, e), nil
+}
+
+// Delete removes a secret. Settings still referring to it fail to resolve.
+func (s *Store) Delete(ctx context.Context, name string) error {
+	if !s.Enabled() {
+		return ErrDisabled
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries, err := s.load(ctx)
+	if err != nil {
+		return err
+	}
+	if _, ok := entries[name]; !ok {
+		return ErrNotFound
+	}
+	delete(entries, name)
+	return s.save(ctx, entries)
+}
+
+// Get decrypts the value of a secret.
+func (s *Store) Get(ctx context.Context, name string) (string, error) {
+	if !s.Enabled() {
+		return "", ErrDisabled
+	}
+	s.mu.Lock()
+	entries, err := s.load(ctx)
+	s.mu.Unlock()
+	if err != nil {
+		return "", err
+	}
+
+	e, ok := entries[name]
+	if !ok {
+		return "", ErrNotFound
+	}
+	return s.open(name, e.Value)
+}
+
+// Resolve returns the value of the secret a setting refers to, or the setting
+// itself when it is not a reference. A nil store resolves settings that are not
+// references.
+func (s *Store) Resolve(ctx context.Context, value string) (string, error) {
+	name, ok := ParseReference(value)
+	if !ok {
+		return value, nil
+	}
+	secret, err := s.Get(ctx, name)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s: %w", Reference(name), err)
+	}
+	return secret, nil
+}
+
+// load reads the encrypted secrets. Caller must hold s.mu.
+func (s *Store) load(ctx context.Context) (map[string]entry, error) {
+	if s.storage == nil {
+		return nil, fmt.Errorf("storage not available")
+	}
+	value, found, err := s.storage.GetConfig(ctx, secretsConfigKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load secrets: %w", err)
+	}
+	entries := make(map[string]entry)
+	if found && value != "" {
+		if err := json.Unmarshal([]byte(value), &entries); err != nil {
+			return nil, fmt.Errorf("failed to parse secrets: %w", err)
+		}
+	}
+	return entries, nil
+}
+
+// save writes the encrypted secrets. Caller must hold s.mu.
+func (s *Store) save(ctx context.Context, entries map[string]entry) error {
+	data, err := json.Marshal(entries)
+	if err != nil {
+		return fmt.Errorf("failed to serialize secrets: %w", err)
+	}
+	if err := s.storage.SetConfig(ctx, secretsConfigKey, string(data)); err != nil {
+		return fmt.Errorf("failed to save secrets: %w", err)
+	}
+	return nil
+}
+
+// seal encrypts a value, binding it to the secret's name so stored values
+// cannot be swapped between secrets.
+func (s *Store) seal(name, value string) (string, error) {
+	nonce := make([]byte, s.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	sealed := s.aead.Seal(nonce, nonce, []byte(value), []byte(name))
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// open decrypts a value sealed by seal.
+func (s *Store) open(name, sealed string) (string, error) {
+	data, err := base64.StdEncoding.DecodeString(sealed)
+	if err != nil || len(data) < s.aead.NonceSize() {
+		return "", fmt.Errorf("secret %s is corrupt", name)
+	}
+	nonce, ciphertext := data[:s.aead.NonceSize()], data[s.aead.NonceSize():]
+	value, err := s.aead.Open(nil, nonce, ciphertext, []byte(name))
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt secret %s (was SECRETS_KEY changed?)", name)
+	}
+	return string(value), nil
+}
+
+func describe(name string, e entry) Secret {
+	return Secret{Name: name, Reference: Reference(name), CreatedAt: e.CreatedAt, UpdatedAt: e.UpdatedAt}
+}
@@ -0,0 +1,119 @@
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/chis/docksmith/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestStore(t *testing.T, store storage.Storage) *Store {
+	t.Helper()
+	s, err := NewStore(store, bytes.Repeat([]byte{7}, KeySize))
+	require.NoError(t, err)
+	return s
+}
+
+func TestStore(t *testing.T) {
+	ctx := context.Background()
+	backend := storage.NewMemoryStorage()
+	s := newTestStore(t, backend)
+
+	secret, err := s.Set(ctx, "gotify", "A1b2C3")
+	require.NoError(t, err)
+	assert.Equal(t, "secret:gotify", secret.Reference)
+	_, err = s.Set(ctx, "github_token", "ghp_first")
+	require.NoError(t, err)
+	_, err = s.Set(ctx, "github_token", "ghp_second")
+	require.NoError(t, err)
+
+	value, err := s.Get(ctx, "github_token")
+	require.NoError(t, err)
+	assert.Equal(t, "ghp_second", value)
+
+	list, err := s.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+	assert.Equal(t, "github_token", list[0].Name)
+	assert.Equal(t, "gotify", list[1].Name)
+
+	// Values are stored encrypted
+	stored, _, err := backend.GetConfig(ctx, secretsConfigKey)
+	require.NoError(t, err)
+	assert.NotContains(t, stored, "ghp_second")
+	assert.NotContains(t, stored, "A1b2C3")
+
+	// A different key cannot decrypt them
+	other, err := NewStore(backend, bytes.Repeat([]byte{8}, KeySize))
+	require.NoError(t, err)
+	_, err = other.Get(ctx, "gotify")
+	assert.ErrorContains(t, err, "failed to decrypt")
+
+	require.NoError(t, s.Delete(ctx, "gotify"))
+	assert.ErrorIs(t, s.Delete(ctx, "gotify"), ErrNotFound)
+	_, err = s.Get(ctx, "gotify")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	_, err = s.Set(ctx, "bad name", "value")
+	assert.ErrorIs(t, err, ErrInvalidName)
+	_, err = s.Set(ctx, "empty", "")
+	assert.Error(t, err)
+}
+
+func TestStore_Resolve(t *testing.T) {
+	ctx := context.Background()
+	s := newTestStore(t, storage.NewMemoryStorage())
+	_, err := s.Set(ctx, "ntfy", "tk_123")
+	require.NoError(t, err)
+
+	value, err := s.Resolve(ctx, "secret:ntfy")
+	require.NoError(t, err)
+	assert.Equal(t, "tk_123", value)
+
+	value, err = s.Resolve(ctx, "https://ntfy.sh/docksmith")
+	require.NoError(t, err)
+	assert.Equal(t, "https://ntfy.sh/docksmith", value)
+
+	_, err = s.Resolve(ctx, "secret:missing")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	// Without a key only plain settings resolve
+	var disabled *Store
+	value, err = disabled.Resolve(ctx, "plain")
+	require.NoError(t, err)
+	assert.Equal(t, "plain", value)
+	_, err = disabled.Resolve(ctx, "secret:ntfy")
+	assert.ErrorIs(t, err, ErrDisabled)
+}
+
+func TestKeyFromEnv(t *testing.T) {
+	encoded := base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{1}, KeySize))
+
+	t.Setenv("SECRETS_KEY", "")
+	t.Setenv("SECRETS_KEY_FILE", "")
+	key, err := KeyFromEnv()
+	require.NoError(t, err)
+	assert.Nil(t, key)
+
+	path := filepath.Join(t.TempDir(), "secrets.key")
+	require.NoError(t, os.WriteFile(path, []byte(encoded+"\n"), 0o600))
+	t.Setenv("SECRETS_KEY_FILE", path)
+	key, err = KeyFromEnv()
+	require.NoError(t, err)
+	assert.Len(t, key, KeySize)
+
+	t.Setenv("SECRETS_KEY", base64.StdEncoding.EncodeToString([]byte(strings.Repeat("x", 16))))
+	_, err = KeyFromEnv()
+	assert.ErrorContains(t, err, "must be 32 bytes")
+
+	t.Setenv("SECRETS_KEY", "not base64!")
+	_, err = KeyFromEnv()
+	assert.Error(t, err)
+}
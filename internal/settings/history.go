@@ -0,0 +1,418 @@
+package settings
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/chis/docksmith/internal/notify"
+	"github.com/chis/docksmith/internal/secrets"
+	"github.com/chis/docksmith/internal/storage"
+	"github.com/chis/docksmith/internal/update"
+)
+
+// GroupSchedulesConfigKey is the config table key of the scheduled group updates,
+// kept by the API server.
+const GroupSchedulesConfigKey = "group_schedules"
+
+// historyFormatKey marks config history snapshots recorded by RecordSnapshot.
+// Other snapshots hold raw config values and cannot be reverted to with Revert.
+const historyFormatKey = "history_format"
+
+// historyFormat is the current format of recorded snapshots.
+const historyFormat = "1"
+
+// Key prefixes of the recorded state.
+const (
+	containerPrefix      = "container/"
+	rollbackPolicyPrefix = "rollback_policy/"
+	approvalPolicyPrefix = "approval_policy/"
+	ignoreRulePrefix     = "ignore_rule/"
+	configPrefix         = "config/"
+)
+
+// ErrNotRevertible is returned by Revert for snapshots not recorded by RecordSnapshot.
+var ErrNotRevertible = errors.New("snapshot was not recorded by the configuration history and cannot be reverted to")
+
+// fingerprintPrefix starts the recorded form of a sensitive value.
+const fingerprintPrefix = "sha256:"
+
+// sensitiveConfigKeys are recorded as fingerprints, so the history shows that
+// they changed without storing tokens and webhook URLs in another place.
+var sensitiveConfigKeys = map[string]bool{
+	notify.WebhookURLConfigKey:      true,
+	notify.SlackWebhookURLConfigKey: true,
+	notify.GotifyTokenConfigKey:     true,
+	notify.NtfyURLConfigKey:         true,
+	notify.NtfyTokenConfigKey:       true,
+	notify.PushoverTokenConfigKey:   true,
+	notify.PushoverUserConfigKey:    true,
+}
+
+// Change is a difference between two recorded states.
+type Change struct {
+	Key  string `json:"key"`
+	Type string `json:"type"` // added, removed, or changed
+	Old  string `json:"old,omitempty"`
+	New  string `json:"new,omitempty"`
+}
+
+// RevertResult reports what reverting to a snapshot changed.
+type RevertResult struct {
+	Changes []Change `json:"changes"`
+	Skipped []string `json:"skipped,omitempty"` // Keys that could not be restored
+}
+
+// historyConfigKeys returns the config values recorded in the history: the UI
+// settings, the check and group schedules, and the notification settings.
+func historyConfigKeys() []string {
+	keys := append([]string{}, Keys...)
+	keys = append(keys, update.CheckerPausedConfigKey, GroupSchedulesConfigKey, notify.TemplatesConfigKey)
+	for _, s := range envSettings {
+		keys = append(keys, s.key)
+	}
+	return keys
+}
+
+// State returns the configuration tracked by the history as flat key/value pairs:
+// script assignments, rollback and approval policies, ignore rules, and the
+// config values of historyConfigKeys. Unlike Collect, only values stored in the
+// database are included, not those set by environment variables.
+func State(ctx context.Context, store storage.Storage) (map[string]string, error) {
+	state := map[string]string{historyFormatKey: historyFormat}
+
+	assignments, err := store.ListScriptAssignments(ctx, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list script assignments: %w", err)
+	}
+	for _, a := range assignments {
+		prefix := containerPrefix + a.ContainerName + "/"
+		state[prefix+"script"] = a.ScriptPath
+		state[prefix+"enabled"] = strconv.FormatBool(a.Enabled)
+		state[prefix+"ignore"] = strconv.FormatBool(a.Ignore)
+		state[prefix+"allow_latest"] = strconv.FormatBool(a.AllowLatest)
+	}
+
+	rollbackPolicies, err := store.ListRollbackPolicies(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range rollbackPolicies {
+		prefix := rollbackPolicyPrefix + policyEntity(p.EntityType, p.EntityID) + "/"
+		state[prefix+"auto_rollback"] = strconv.FormatBool(p.AutoRollbackEnabled)
+		state[prefix+"health_check_required"] = strconv.FormatBool(p.HealthCheckRequired)
+	}
+
+	approvalPolicies, err := store.ListApprovalPolicies(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range approvalPolicies {
+		state[approvalPolicyPrefix+policyEntity(p.EntityType, p.EntityID)] = p.Mode
+	}
+
+	ignoreRules, err := store.ListIgnoreRules(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, rule := range ignoreRules {
+		state[ignoreRulePrefix+rule.Pattern] = rule.Reason
+	}
+
+	for _, key := range historyConfigKeys() {
+		value, found, err := store.GetConfig(ctx, key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read setting %s: %w", key, err)
+		}
+		if found && value != "" {
+			state[configPrefix+key] = recordedValue(key, value)
+		}
+	}
+	return state, nil
+}
+
+// RecordSnapshot saves the current state to the config history when it differs
+// from the latest snapshot. Returns whether a snapshot was saved.
+func RecordSnapshot(ctx context.Context, store storage.Storage, changedBy string) (bool, error) {
+	state, err := State(ctx, store)
+	if err != nil {
+		return false, err
+	}
+
+	latest, err := store.GetConfigHistory(ctx, 1)
+	if err != nil {
+		return false, fmt.Errorf("failed to read config history: %w", err)
+	}
+	if len(latest) > 0 && len(Diff(latest[0].ConfigData, state)) == 0 {
+		return false, nil
+	}
+
+	snapshot := storage.ConfigSnapshot{
+		SnapshotTime: time.Now(),
+		ConfigData:   state,
+		ChangedBy:    changedBy,
+	}
+	if err := store.SaveConfigSnapshot(ctx, snapshot); err != nil {
+		return false, fmt.Errorf("failed to save config snapshot: %w", err)
+	}
+	return true, nil
+}
+
+// Diff returns the changes from one recorded state to another, sorted by key.
+func Diff(from, to map[string]string) []Change {
+	var changes []Change
+	for key, old := range from {
+		if key == historyFormatKey {
+			continue
+		}
+		value, ok := to[key]
+		switch {
+		case !ok:
+			changes = append(changes, Change{Key: key, Type: "removed", Old: old})
+		case value != old:
+			changes = append(changes, Change{Key: key, Type: "changed", Old: old, New: value})
+		}
+	}
+	for key, value := range to {
+		if _, ok := from[key]; !ok && key != historyFormatKey {
+			changes = append(changes, Change{Key: key, Type: "added", New: value})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Key < changes[j].Key })
+	return changes
+}
+
+// Revert restores the configuration recorded in a snapshot and records the
+// result as a new snapshot. Script assignments, approval policies, and ignore
+// rules added since are removed. Rollback policies cannot be removed and
+// sensitive values are only recorded as fingerprints, so those are reported as
+// skipped when they differ. Schedule and notification changes take effect when
+// the server restarts, like an import.
+func Revert(ctx context.Context, store storage.Storage, snapshotID int64, changedBy string) (RevertResult, error) {
+	snapshot, found, err := store.GetConfigSnapshotByID(ctx, snapshotID)
+	if err != nil {
+		return RevertResult{}, fmt.Errorf("failed to read config snapshot: %w", err)
+	}
+	if !found {
+		return RevertResult{}, fmt.Errorf("snapshot %d not found", snapshotID)
+	}
+	if snapshot.ConfigData[historyFormatKey] != historyFormat {
+		return RevertResult{}, fmt.Errorf("snapshot %d: %w", snapshotID, ErrNotRevertible)
+	}
+
+	before, err := State(ctx, store)
+	if err != nil {
+		return RevertResult{}, err
+	}
+	target := snapshot.ConfigData
+	var result RevertResult
+
+	if err := revertContainers(ctx, store, before, target, changedBy); err != nil {
+		return result, err
+	}
+	skipped, err := revertPolicies(ctx, store, before, target)
+	if err != nil {
+		return result, err
+	}
+	result.Skipped = append(result.Skipped, skipped...)
+	if err := revertIgnoreRules(ctx, store, before, target); err != nil {
+		return result, err
+	}
+
+	for _, key := range historyConfigKeys() {
+		old, value := before[configPrefix+key], target[configPrefix+key]
+		if old == value {
+			continue
+		}
+		if sensitiveConfigKeys[key] && strings.HasPrefix(value, fingerprintPrefix) {
+			result.Skipped = append(result.Skipped, configPrefix+key)
+			continue
+		}
+		if err := store.SetConfig(ctx, key, value); err != nil {
+			return result, fmt.Errorf("failed to restore setting %s: %w", key, err)
+		}
+	}
+
+	sort.Strings(result.Skipped)
+
+	after, err := State(ctx, store)
+	if err != nil {
+		return result, err
+	}
+	result.Changes = Diff(before, after)
+	if _, err := RecordSnapshot(ctx, store, fmt.Sprintf("%s (revert to snapshot %d)", changedBy, snapshotID)); err != nil {
+		log.Printf("CONFIG: Failed to record revert to snapshot %d: %v", snapshotID, err)
+	}
+	return result, nil
+}
+
+// revertContainers restores the script assignments of a recorded state.
+func revertContainers(ctx context.Context, store storage.Storage, before, target map[string]string, changedBy string) error {
+	current, wanted := containerNames(before), containerNames(target)
+	for name := range current {
+		if !wanted[name] {
+			if err := store.DeleteScriptAssignment(ctx, name); err != nil {
+				return fmt.Errorf("failed to remove settings of %s: %w", name, err)
+			}
+		}
+	}
+	for name := range wanted {
+		prefix := containerPrefix + name + "/"
+		fields := []string{"script", "enabled", "ignore", "allow_latest"}
+		unchanged := current[name]
+		for _, field := range fields {
+			unchanged = unchanged && before[prefix+field] == target[prefix+field]
+		}
+		if unchanged {
+			continue
+		}
+		assignment := storage.ScriptAssignment{
+			ContainerName: name,
+			ScriptPath:    target[prefix+"script"],
+			Enabled:       target[prefix+"enabled"] == "true",
+			Ignore:        target[prefix+"ignore"] == "true",
+			AllowLatest:   target[prefix+"allow_latest"] == "true",
+			AssignedBy:    changedBy,
+		}
+		if err := store.SaveScriptAssignment(ctx, assignment); err != nil {
+			return fmt.Errorf("failed to restore settings of %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// revertPolicies restores the rollback and approval policies of a recorded state.
+// Returns the rollback policies that cannot be removed.
+func revertPolicies(ctx context.Context, store storage.Storage, before, target map[string]string) ([]string, error) {
+	var skipped []string
+	current, wanted := rollbackEntities(before), rollbackEntities(target)
+	for entity := range current {
+		if !wanted[entity] {
+			skipped = append(skipped, rollbackPolicyPrefix+entity)
+		}
+	}
+	for entity := range wanted {
+		prefix := rollbackPolicyPrefix + entity + "/"
+		if current[entity] && before[prefix+"auto_rollback"] == target[prefix+"auto_rollback"] &&
+			before[prefix+"health_check_required"] == target[prefix+"health_check_required"] {
+			continue
+		}
+		scope, name := splitEntity(entity)
+		policy := storage.RollbackPolicy{
+			EntityType:          scope,
+			EntityID:            name,
+			AutoRollbackEnabled: target[prefix+"auto_rollback"] == "true",
+			HealthCheckRequired: target[prefix+"health_check_required"] == "true",
+		}
+		if err := store.SetRollbackPolicy(ctx, policy); err != nil {
+			return skipped, err
+		}
+	}
+
+	for key := range before {
+		entity, ok := strings.CutPrefix(key, approvalPolicyPrefix)
+		if _, keep := target[key]; ok && !keep {
+			scope, name := splitEntity(entity)
+			if err := store.DeleteApprovalPolicy(ctx, scope, name); err != nil {
+				return skipped, err
+			}
+		}
+	}
+	for key, mode := range target {
+		if entity, ok := strings.CutPrefix(key, approvalPolicyPrefix); ok && before[key] != mode {
+			scope, name := splitEntity(entity)
+			if err := store.SetApprovalPolicy(ctx, storage.ApprovalPolicy{EntityType: scope, EntityID: name, Mode: mode}); err != nil {
+				return skipped, err
+			}
+		}
+	}
+	return skipped, nil
+}
+
+// revertIgnoreRules restores the ignore rules of a recorded state.
+func revertIgnoreRules(ctx context.Context, store storage.Storage, before, target map[string]string) error {
+	rules, err := store.ListIgnoreRules(ctx)
+	if err != nil {
+		return err
+	}
+	for _, rule := range rules {
+		if _, keep := target[ignoreRulePrefix+rule.Pattern]; !keep {
+			if _, err := store.DeleteIgnoreRule(ctx, rule.ID); err != nil {
+				return err
+			}
+		}
+	}
+	for key, reason := range target {
+		pattern, ok := strings.CutPrefix(key, ignoreRulePrefix)
+		if !ok {
+			continue
+		}
+		if old, exists := before[key]; exists && old == reason {
+			continue
+		}
+		if _, err := store.SaveIgnoreRule(ctx, storage.IgnoreRule{Pattern: pattern, Reason: reason}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// recordedValue returns how a config value is recorded: sensitive values that
+// are not secret references are replaced by a fingerprint.
+func recordedValue(key, value string) string {
+	if !sensitiveConfigKeys[key] {
+		return value
+	}
+	if _, ok := secrets.ParseReference(value); ok {
+		return value
+	}
+	sum := sha256.Sum256([]byte(value))
+	return fingerprintPrefix + hex.EncodeToString(sum[:])[:12]
+}
+
+// policyEntity names the entity of a policy in recorded keys: "global" or "stack/media".
+func policyEntity(scope, name string) string {
+	if name == "" {
+		return scope
+	}
+	return scope + "/" + name
+}
+
+// splitEntity is the reverse of policyEntity.
+func splitEntity(entity string) (scope, name string) {
+	scope, name, _ = strings.Cut(entity, "/")
+	return scope, name
+}
+
+// containerNames returns the containers with settings in a recorded state.
+func containerNames(state map[string]string) map[string]bool {
+	names := make(map[string]bool)
+	for key := range state {
+		if rest, ok := strings.CutPrefix(key, containerPrefix); ok {
+			if i := strings.LastIndex(rest, "/"); i > 0 {
+				names[rest[:i]] = true
+			}
+		}
+	}
+	return names
+}
+
+// rollbackEntities returns the entities with rollback policies in a recorded state.
+func rollbackEntities(state map[string]string) map[string]bool {
+	entities := make(map[string]bool)
+	for key := range state {
+		if rest, ok := strings.CutPrefix(key, rollbackPolicyPrefix); ok {
+			if i := strings.LastIndex(rest, "/"); i > 0 {
+				entities[rest[:i]] = true
+			}
+		}
+	}
+	return entities
+}
@@ -0,0 +1,126 @@
+package settings
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/chis/docksmith/internal/notify"
+	"github.com/chis/docksmith/internal/storage"
+	"github.com/chis/docksmith/internal/update"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordSnapshot(t *testing.T) {
+	ctx := context.Background()
+	store := newStore(t)
+
+	recorded, err := RecordSnapshot(ctx, store, "user:alice")
+	require.NoError(t, err)
+	assert.True(t, recorded)
+
+	// Nothing changed since
+	recorded, err = RecordSnapshot(ctx, store, "user:alice")
+	require.NoError(t, err)
+	assert.False(t, recorded)
+
+	require.NoError(t, store.SetApprovalPolicy(ctx, storage.ApprovalPolicy{EntityType: "stack", EntityID: "media", Mode: storage.ApprovalModeMajor}))
+	require.NoError(t, store.SetConfig(ctx, notify.GotifyTokenConfigKey, "A1b2C3"))
+	recorded, err = RecordSnapshot(ctx, store, "apikey:ci")
+	require.NoError(t, err)
+	assert.True(t, recorded)
+
+	history, err := store.GetConfigHistory(ctx, 2)
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+	assert.Equal(t, "apikey:ci", history[0].ChangedBy)
+
+	changes := Diff(history[1].ConfigData, history[0].ConfigData)
+	require.Len(t, changes, 2)
+	assert.Equal(t, Change{Key: "approval_policy/stack/media", Type: "added", New: storage.ApprovalModeMajor}, changes[0])
+	assert.Equal(t, "config/"+notify.GotifyTokenConfigKey, changes[1].Key)
+
+	// Tokens are recorded as fingerprints, secret references as is
+	assert.NotContains(t, changes[1].New, "A1b2C3")
+	assert.Contains(t, changes[1].New, fingerprintPrefix)
+	assert.Equal(t, "secret:gotify", recordedValue(notify.GotifyTokenConfigKey, "secret:gotify"))
+}
+
+func TestRevert(t *testing.T) {
+	ctx := context.Background()
+	store := newStore(t)
+
+	require.NoError(t, store.SaveScriptAssignment(ctx, storage.ScriptAssignment{ContainerName: "plex", ScriptPath: "check-plex.sh", Enabled: true}))
+	require.NoError(t, store.SetApprovalPolicy(ctx, storage.ApprovalPolicy{EntityType: "global", Mode: storage.ApprovalModeMajor}))
+	_, err := store.SaveIgnoreRule(ctx, storage.IgnoreRule{Pattern: "*:nightly", Reason: "unstable builds"})
+	require.NoError(t, err)
+	require.NoError(t, store.SetConfig(ctx, update.CheckIntervalConfigKey, "1h"))
+	require.NoError(t, store.SetConfig(ctx, notify.NtfyTokenConfigKey, "tk_old"))
+	_, err = RecordSnapshot(ctx, store, "user:alice")
+	require.NoError(t, err)
+	history, err := store.GetConfigHistory(ctx, 1)
+	require.NoError(t, err)
+	snapshotID := history[0].ID
+
+	// Change everything
+	require.NoError(t, store.SaveScriptAssignment(ctx, storage.ScriptAssignment{ContainerName: "plex", Ignore: true}))
+	require.NoError(t, store.SaveScriptAssignment(ctx, storage.ScriptAssignment{ContainerName: "sonarr", AllowLatest: true}))
+	require.NoError(t, store.DeleteApprovalPolicy(ctx, "global", ""))
+	require.NoError(t, store.SetApprovalPolicy(ctx, storage.ApprovalPolicy{EntityType: "container", EntityID: "plex", Mode: storage.ApprovalModeAll}))
+	rules, err := store.ListIgnoreRules(ctx)
+	require.NoError(t, err)
+	_, err = store.DeleteIgnoreRule(ctx, rules[0].ID)
+	require.NoError(t, err)
+	require.NoError(t, store.SetRollbackPolicy(ctx, storage.RollbackPolicy{EntityType: "stack", EntityID: "media", AutoRollbackEnabled: true}))
+	require.NoError(t, store.SetConfig(ctx, update.CheckIntervalConfigKey, "15m"))
+	require.NoError(t, store.SetConfig(ctx, update.CheckerPausedConfigKey, "true"))
+	require.NoError(t, store.SetConfig(ctx, notify.NtfyTokenConfigKey, "tk_new"))
+	_, err = RecordSnapshot(ctx, store, "user:bob")
+	require.NoError(t, err)
+
+	result, err := Revert(ctx, store, snapshotID, "user:alice")
+	require.NoError(t, err)
+	assert.NotEmpty(t, result.Changes)
+	assert.Equal(t, []string{"config/" + notify.NtfyTokenConfigKey, "rollback_policy/stack/media"}, result.Skipped)
+
+	assignments, err := store.ListScriptAssignments(ctx, false)
+	require.NoError(t, err)
+	require.Len(t, assignments, 1)
+	assert.Equal(t, "plex", assignments[0].ContainerName)
+	assert.Equal(t, "check-plex.sh", assignments[0].ScriptPath)
+	assert.False(t, assignments[0].Ignore)
+
+	policies, err := store.ListApprovalPolicies(ctx)
+	require.NoError(t, err)
+	require.Len(t, policies, 1)
+	assert.Equal(t, "global", policies[0].EntityType)
+
+	rules, err = store.ListIgnoreRules(ctx)
+	require.NoError(t, err)
+	require.Len(t, rules, 1)
+	assert.Equal(t, "*:nightly", rules[0].Pattern)
+
+	interval, _, err := store.GetConfig(ctx, update.CheckIntervalConfigKey)
+	require.NoError(t, err)
+	assert.Equal(t, "1h", interval)
+	paused, _, err := store.GetConfig(ctx, update.CheckerPausedConfigKey)
+	require.NoError(t, err)
+	assert.Equal(t, "", paused)
+	token, _, err := store.GetConfig(ctx, notify.NtfyTokenConfigKey)
+	require.NoError(t, err)
+	assert.Equal(t, "tk_new", token)
+
+	// The revert is recorded too
+	history, err = store.GetConfigHistory(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, fmt.Sprintf("user:alice (revert to snapshot %d)", snapshotID), history[0].ChangedBy)
+
+	// Snapshots of the legacy config service cannot be reverted to
+	require.NoError(t, store.SaveConfigSnapshot(ctx, storage.ConfigSnapshot{SnapshotTime: time.Now(), ConfigData: map[string]string{"cache_ttl": "1h"}, ChangedBy: "system-init"}))
+	history, err = store.GetConfigHistory(ctx, 1)
+	require.NoError(t, err)
+	_, err = Revert(ctx, store, history[0].ID, "user:alice")
+	assert.ErrorIs(t, err, ErrNotRevertible)
+}
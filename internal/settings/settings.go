@@ -0,0 +1,448 @@
+// Package settings exports docksmith's configuration to a single YAML document
+// and imports it again, to back it up or to replicate settings to another host.
+//
+// The document holds per-container settings (script assignment, ignore and
+// allow-latest), rollback and approval policies, image ignore rules, the settings editable in the UI, the check
+// schedule, and the notification config and message templates. Schedule and notification values are
+// exported as currently in effect, whether they come from environment variables
+// or an earlier import. On import they are stored in the database and used
+// wherever the environment variable is not set.
+package settings
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/chis/docksmith/internal/approval"
+	"github.com/chis/docksmith/internal/notify"
+	"github.com/chis/docksmith/internal/proposal"
+	"github.com/chis/docksmith/internal/storage"
+	"github.com/chis/docksmith/internal/update"
+	"gopkg.in/yaml.v3"
+)
+
+// FormatVersion is the version of the export document format.
+const FormatVersion = 1
+
+// Keys lists the UI settings included in an export.
+var Keys = []string{
+	"history_retention_days",
+	storage.CheckHistoryRetentionConfigKey,
+	storage.UpdateLogRetentionConfigKey,
+	approval.RequiredConfigKey,
+	proposal.EnabledConfigKey,
+}
+
+// Export is an exported docksmith configuration.
+type Export struct {
+	Version          int                 `yaml:"version"`
+	ExportedAt       time.Time           `yaml:"exported_at"`
+	Containers       []ContainerSettings `yaml:"containers,omitempty"`
+	RollbackPolicies []RollbackPolicy    `yaml:"rollback_policies,omitempty"`
+	ApprovalPolicies []ApprovalPolicy    `yaml:"approval_policies,omitempty"`
+	IgnoreRules      []IgnoreRule        `yaml:"ignore_rules,omitempty"`
+	Settings         map[string]string   `yaml:"settings,omitempty"`
+	Schedule         Schedule            `yaml:"schedule"`
+	Notifications    Notifications       `yaml:"notifications"`
+}
+
+// ContainerSettings is a container's script assignment and check settings.
+type ContainerSettings struct {
+	Name        string `yaml:"name"`
+	Script      string `yaml:"script,omitempty"` // path relative to the scripts folder
+	Enabled     bool   `yaml:"enabled"`
+	Ignore      bool   `yaml:"ignore,omitempty"`
+	AllowLatest bool   `yaml:"allow_latest,omitempty"`
+}
+
+// RollbackPolicy is the automatic rollback policy of the host, a stack, or a container.
+type RollbackPolicy struct {
+	Scope               string `yaml:"scope"`          // global, stack, or container
+	Name                string `yaml:"name,omitempty"` // stack or container name
+	AutoRollback        bool   `yaml:"auto_rollback"`
+	HealthCheckRequired bool   `yaml:"health_check_required"`
+}
+
+// ApprovalPolicy decides which updates of the host, a stack, or a container need approval.
+type ApprovalPolicy struct {
+	Scope string `yaml:"scope"`          // global, stack, or container
+	Name  string `yaml:"name,omitempty"` // stack or container name
+	Mode  string `yaml:"mode"`           // none, major, or all
+}
+
+// IgnoreRule excludes containers whose image matches a pattern from update checks.
+type IgnoreRule struct {
+	Pattern string `yaml:"pattern"`
+	Reason  string `yaml:"reason,omitempty"`
+}
+
+// Schedule is the background check schedule.
+type Schedule struct {
+	CheckInterval string `yaml:"check_interval,omitempty"` // e.g. "5m"
+	CheckJitter   string `yaml:"check_jitter,omitempty"`
+	Paused        bool   `yaml:"paused"`
+}
+
+// Notifications is the update notification config.
+type Notifications struct {
+	WebhookURL      string `yaml:"webhook_url,omitempty"`
+	SlackWebhookURL string `yaml:"slack_webhook_url,omitempty"`
+	Mode            string `yaml:"mode,omitempty"`           // immediate or digest
+	DigestPeriod    string `yaml:"digest_period,omitempty"`  // daily or weekly
+	DigestTime      string `yaml:"digest_time,omitempty"`    // HH:MM
+	DigestWeekday   string `yaml:"digest_weekday,omitempty"` // for weekly digests
+
+	GotifyURL        string `yaml:"gotify_url,omitempty"`
+	GotifyToken      string `yaml:"gotify_token,omitempty"`
+	GotifyPriority   string `yaml:"gotify_priority,omitempty"` // e.g. "failure=high,digest=low"
+	NtfyURL          string `yaml:"ntfy_url,omitempty"`        // topic URL
+	NtfyToken        string `yaml:"ntfy_token,omitempty"`
+	NtfyPriority     string `yaml:"ntfy_priority,omitempty"`
+	PushoverToken    string `yaml:"pushover_token,omitempty"`
+	PushoverUser     string `yaml:"pushover_user,omitempty"`
+	PushoverPriority string `yaml:"pushover_priority,omitempty"`
+
+	Templates map[string]notify.Template `yaml:"templates,omitempty"` // Message templates by channel name
+}
+
+// Summary counts what an import applied.
+type Summary struct {
+	Containers       int `json:"containers"`
+	RollbackPolicies int `json:"rollback_policies"`
+	ApprovalPolicies int `json:"approval_policies"`
+	IgnoreRules      int `json:"ignore_rules"`
+	Settings         int `json:"settings"`
+}
+
+// envSetting is a setting normally given as an environment variable
+type envSetting struct {
+	env   string
+	key   string
+	value func(e *Export) *string
+}
+
+// envSettings lists the schedule and notification settings with their
+// environment variables and database config keys.
+var envSettings = []envSetting{
+	{"CHECK_INTERVAL", update.CheckIntervalConfigKey, func(e *Export) *string { return &e.Schedule.CheckInterval }},
+	{"CHECK_JITTER", update.CheckJitterConfigKey, func(e *Export) *string { return &e.Schedule.CheckJitter }},
+	{"NOTIFY_WEBHOOK_URL", notify.WebhookURLConfigKey, func(e *Export) *string { return &e.Notifications.WebhookURL }},
+	{"NOTIFY_SLACK_WEBHOOK_URL", notify.SlackWebhookURLConfigKey, func(e *Export) *string { return &e.Notifications.SlackWebhookURL }},
+	{"NOTIFY_MODE", notify.ModeConfigKey, func(e *Export) *string { return &e.Notifications.Mode }},
+	{"NOTIFY_DIGEST_PERIOD", notify.DigestPeriodConfigKey, func(e *Export) *string { return &e.Notifications.DigestPeriod }},
+	{"NOTIFY_DIGEST_TIME", notify.DigestTimeConfigKey, func(e *Export) *string { return &e.Notifications.DigestTime }},
+	{"NOTIFY_DIGEST_WEEKDAY", notify.DigestWeekdayConfigKey, func(e *Export) *string { return &e.Notifications.DigestWeekday }},
+	{"NOTIFY_GOTIFY_URL", notify.GotifyURLConfigKey, func(e *Export) *string { return &e.Notifications.GotifyURL }},
+	{"NOTIFY_GOTIFY_TOKEN", notify.GotifyTokenConfigKey, func(e *Export) *string { return &e.Notifications.GotifyToken }},
+	{"NOTIFY_GOTIFY_PRIORITY", notify.GotifyPriorityConfigKey, func(e *Export) *string { return &e.Notifications.GotifyPriority }},
+	{"NOTIFY_NTFY_URL", notify.NtfyURLConfigKey, func(e *Export) *string { return &e.Notifications.NtfyURL }},
+	{"NOTIFY_NTFY_TOKEN", notify.NtfyTokenConfigKey, func(e *Export) *string { return &e.Notifications.NtfyToken }},
+	{"NOTIFY_NTFY_PRIORITY", notify.NtfyPriorityConfigKey, func(e *Export) *string { return &e.Notifications.NtfyPriority }},
+	{"NOTIFY_PUSHOVER_TOKEN", notify.PushoverTokenConfigKey, func(e *Export) *string { return &e.Notifications.PushoverToken }},
+	{"NOTIFY_PUSHOVER_USER", notify.PushoverUserConfigKey, func(e *Export) *string { return &e.Notifications.PushoverUser }},
+	{"NOTIFY_PUSHOVER_PRIORITY", notify.PushoverPriorityConfigKey, func(e *Export) *string { return &e.Notifications.PushoverPriority }},
+}
+
+// Collect reads the current configuration for export.
+func Collect(ctx context.Context, store storage.Storage) (*Export, error) {
+	e := &Export{
+		Version:    FormatVersion,
+		ExportedAt: time.Now().UTC().Truncate(time.Second),
+		Settings:   make(map[string]string),
+	}
+
+	assignments, err := store.ListScriptAssignments(ctx, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list script assignments: %w", err)
+	}
+	for _, a := range assignments {
+		e.Containers = append(e.Containers, ContainerSettings{
+			Name:        a.ContainerName,
+			Script:      a.ScriptPath,
+			Enabled:     a.Enabled,
+			Ignore:      a.Ignore,
+			AllowLatest: a.AllowLatest,
+		})
+	}
+
+	policies, err := store.ListRollbackPolicies(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range policies {
+		e.RollbackPolicies = append(e.RollbackPolicies, RollbackPolicy{
+			Scope:               p.EntityType,
+			Name:                p.EntityID,
+			AutoRollback:        p.AutoRollbackEnabled,
+			HealthCheckRequired: p.HealthCheckRequired,
+		})
+	}
+
+	approvalPolicies, err := store.ListApprovalPolicies(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range approvalPolicies {
+		e.ApprovalPolicies = append(e.ApprovalPolicies, ApprovalPolicy{Scope: p.EntityType, Name: p.EntityID, Mode: p.Mode})
+	}
+
+	ignoreRules, err := store.ListIgnoreRules(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, rule := range ignoreRules {
+		e.IgnoreRules = append(e.IgnoreRules, IgnoreRule{Pattern: rule.Pattern, Reason: rule.Reason})
+	}
+
+	for _, key := range Keys {
+		value, found, err := store.GetConfig(ctx, key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read setting %s: %w", key, err)
+		}
+		if found {
+			e.Settings[key] = value
+		}
+	}
+
+	paused, _, err := store.GetConfig(ctx, update.CheckerPausedConfigKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read checker state: %w", err)
+	}
+	e.Schedule.Paused = paused == "true"
+
+	for _, s := range envSettings {
+		*s.value(e) = storage.EnvOrConfig(ctx, store, s.env, s.key)
+	}
+
+	templates, found, err := store.GetConfig(ctx, notify.TemplatesConfigKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read notification templates: %w", err)
+	}
+	if found && templates != "" {
+		if err := json.Unmarshal([]byte(templates), &e.Notifications.Templates); err != nil {
+			return nil, fmt.Errorf("failed to parse notification templates: %w", err)
+		}
+	}
+
+	return e, nil
+}
+
+// Marshal encodes an export as YAML.
+func Marshal(e *Export) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString("# docksmith configuration export\n")
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(e); err != nil {
+		return nil, fmt.Errorf("failed to encode configuration: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return nil, fmt.Errorf("failed to encode configuration: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// Parse decodes and validates an exported configuration. Unknown fields are
+// rejected so typos are not silently ignored.
+func Parse(data []byte) (*Export, error) {
+	var e Export
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&e); err != nil {
+		return nil, fmt.Errorf("invalid configuration file: %w", err)
+	}
+	if err := e.Validate(); err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+// Validate checks that an export can be imported.
+func (e *Export) Validate() error {
+	if e.Version != FormatVersion {
+		return fmt.Errorf("unsupported configuration version %d (expected %d)", e.Version, FormatVersion)
+	}
+
+	seen := make(map[string]bool)
+	for _, c := range e.Containers {
+		if c.Name == "" {
+			return fmt.Errorf("container entry without a name")
+		}
+		if seen[c.Name] {
+			return fmt.Errorf("container %s is listed twice", c.Name)
+		}
+		seen[c.Name] = true
+	}
+
+	for _, p := range e.RollbackPolicies {
+		switch p.Scope {
+		case "global":
+			if p.Name != "" {
+				return fmt.Errorf("global rollback policy cannot have a name")
+			}
+		case "stack", "container":
+			if p.Name == "" {
+				return fmt.Errorf("%s rollback policy needs a name", p.Scope)
+			}
+		default:
+			return fmt.Errorf("invalid rollback policy scope %q (must be global, stack, or container)", p.Scope)
+		}
+	}
+
+	for _, p := range e.ApprovalPolicies {
+		if err := approval.ValidatePolicy(p.Scope, p.Name, p.Mode); err != nil {
+			return err
+		}
+	}
+
+	for _, rule := range e.IgnoreRules {
+		if err := update.ValidateIgnorePattern(rule.Pattern); err != nil {
+			return fmt.Errorf("invalid ignore rule: %w", err)
+		}
+	}
+
+	known := make(map[string]bool, len(Keys))
+	for _, key := range Keys {
+		known[key] = true
+	}
+	for key, value := range e.Settings {
+		if !known[key] {
+			return fmt.Errorf("unknown setting %q", key)
+		}
+		if key == storage.CheckHistoryRetentionConfigKey || key == storage.UpdateLogRetentionConfigKey {
+			if days, err := strconv.Atoi(value); err != nil || days < 0 {
+				return fmt.Errorf("setting %s must be a number of days", key)
+			}
+		}
+	}
+
+	if e.Schedule.CheckInterval != "" {
+		if d, err := time.ParseDuration(e.Schedule.CheckInterval); err != nil || d <= 0 {
+			return fmt.Errorf("invalid check_interval %q", e.Schedule.CheckInterval)
+		}
+	}
+	if e.Schedule.CheckJitter != "" {
+		if d, err := time.ParseDuration(e.Schedule.CheckJitter); err != nil || d < 0 {
+			return fmt.Errorf("invalid check_jitter %q", e.Schedule.CheckJitter)
+		}
+	}
+
+	n := e.Notifications
+	switch n.Mode {
+	case "", notify.ModeImmediate, notify.ModeDigest:
+	default:
+		return fmt.Errorf("invalid notification mode %q (must be immediate or digest)", n.Mode)
+	}
+	if _, err := notify.ParseSchedule(n.DigestPeriod, n.DigestTime, n.DigestWeekday); err != nil {
+		return err
+	}
+	for service, spec := range map[string]string{"gotify": n.GotifyPriority, "ntfy": n.NtfyPriority, "pushover": n.PushoverPriority} {
+		if _, err := notify.ParsePriorities(service, spec); err != nil {
+			return err
+		}
+	}
+	for channel, t := range n.Templates {
+		if !slices.Contains(notify.TemplateChannels, channel) {
+			return fmt.Errorf("unknown notification template channel %q", channel)
+		}
+		if err := t.Validate(); err != nil {
+			return fmt.Errorf("invalid %s notification template: %w", channel, err)
+		}
+	}
+	return nil
+}
+
+// Apply stores an imported configuration. Entries in the file are created or
+// replaced; containers and policies that are not in the file are left alone.
+// Schedule and notification changes take effect when the server restarts.
+func Apply(ctx context.Context, store storage.Storage, e *Export) (Summary, error) {
+	var summary Summary
+
+	names := make([]string, 0, len(e.Containers))
+	byName := make(map[string]ContainerSettings, len(e.Containers))
+	for _, c := range e.Containers {
+		names = append(names, c.Name)
+		byName[c.Name] = c
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		c := byName[name]
+		assignment := storage.ScriptAssignment{
+			ContainerName: c.Name,
+			ScriptPath:    c.Script,
+			Enabled:       c.Enabled,
+			Ignore:        c.Ignore,
+			AllowLatest:   c.AllowLatest,
+			AssignedBy:    "import",
+		}
+		if err := store.SaveScriptAssignment(ctx, assignment); err != nil {
+			return summary, fmt.Errorf("failed to import settings of %s: %w", c.Name, err)
+		}
+		summary.Containers++
+	}
+
+	for _, p := range e.RollbackPolicies {
+		policy := storage.RollbackPolicy{
+			EntityType:          p.Scope,
+			EntityID:            p.Name,
+			AutoRollbackEnabled: p.AutoRollback,
+			HealthCheckRequired: p.HealthCheckRequired,
+		}
+		if err := store.SetRollbackPolicy(ctx, policy); err != nil {
+			return summary, err
+		}
+		summary.RollbackPolicies++
+	}
+
+	for _, p := range e.ApprovalPolicies {
+		policy := storage.ApprovalPolicy{EntityType: p.Scope, EntityID: p.Name, Mode: p.Mode}
+		if err := store.SetApprovalPolicy(ctx, policy); err != nil {
+			return summary, err
+		}
+		summary.ApprovalPolicies++
+	}
+
+	for _, rule := range e.IgnoreRules {
+		if _, err := store.SaveIgnoreRule(ctx, storage.IgnoreRule{Pattern: rule.Pattern, Reason: rule.Reason}); err != nil {
+			return summary, err
+		}
+		summary.IgnoreRules++
+	}
+
+	for _, key := range Keys {
+		value, ok := e.Settings[key]
+		if !ok {
+			continue
+		}
+		if err := store.SetConfig(ctx, key, value); err != nil {
+			return summary, fmt.Errorf("failed to import setting %s: %w", key, err)
+		}
+		summary.Settings++
+	}
+
+	if err := store.SetConfig(ctx, update.CheckerPausedConfigKey, strconv.FormatBool(e.Schedule.Paused)); err != nil {
+		return summary, fmt.Errorf("failed to import checker state: %w", err)
+	}
+	for _, s := range envSettings {
+		if err := store.SetConfig(ctx, s.key, *s.value(e)); err != nil {
+			return summary, fmt.Errorf("failed to import %s: %w", s.key, err)
+		}
+	}
+	if len(e.Notifications.Templates) > 0 {
+		data, err := json.Marshal(e.Notifications.Templates)
+		if err != nil {
+			return summary, fmt.Errorf("failed to encode notification templates: %w", err)
+		}
+		if err := store.SetConfig(ctx, notify.TemplatesConfigKey, string(data)); err != nil {
+			return summary, fmt.Errorf("failed to import notification templates: %w", err)
+		}
+	}
+
+	return summary, nil
+}
@@ -0,0 +1,118 @@
+package settings
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/chis/docksmith/internal/approval"
+	"github.com/chis/docksmith/internal/storage"
+	"github.com/chis/docksmith/internal/update"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newStore(t *testing.T) *storage.SQLiteStorage {
+	t.Helper()
+	store, err := storage.NewSQLiteStorage(filepath.Join(t.TempDir(), "test.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+func TestExportImportRoundTrip(t *testing.T) {
+	for _, env := range []string{"CHECK_INTERVAL", "CHECK_JITTER", "NOTIFY_WEBHOOK_URL", "NOTIFY_SLACK_WEBHOOK_URL", "NOTIFY_MODE", "NOTIFY_DIGEST_PERIOD", "NOTIFY_DIGEST_TIME", "NOTIFY_DIGEST_WEEKDAY"} {
+		t.Setenv(env, "")
+	}
+	t.Setenv("CHECK_INTERVAL", "15m")
+	t.Setenv("NOTIFY_MODE", "digest")
+	t.Setenv("NOTIFY_DIGEST_PERIOD", "weekly")
+
+	ctx := context.Background()
+	source := newStore(t)
+	require.NoError(t, source.SaveScriptAssignment(ctx, storage.ScriptAssignment{ContainerName: "plex", ScriptPath: "check-plex.sh", Enabled: true}))
+	require.NoError(t, source.SaveScriptAssignment(ctx, storage.ScriptAssignment{ContainerName: "watchtower", Ignore: true}))
+	require.NoError(t, source.SetRollbackPolicy(ctx, storage.RollbackPolicy{EntityType: "stack", EntityID: "media", AutoRollbackEnabled: true}))
+	require.NoError(t, source.SetApprovalPolicy(ctx, storage.ApprovalPolicy{EntityType: "container", EntityID: "plex", Mode: storage.ApprovalModeMajor}))
+	_, err := source.SaveIgnoreRule(ctx, storage.IgnoreRule{Pattern: "*:nightly", Reason: "unstable builds"})
+	require.NoError(t, err)
+	require.NoError(t, source.SetConfig(ctx, approval.RequiredConfigKey, "true"))
+	require.NoError(t, source.SetConfig(ctx, update.CheckerPausedConfigKey, "true"))
+	require.NoError(t, source.SetConfig(ctx, "notify_templates", `{"ntfy": {"title": "{{.Kind}}: {{len .Findings}}"}}`))
+
+	exported, err := Collect(ctx, source)
+	require.NoError(t, err)
+	data, err := Marshal(exported)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "check_interval: 15m")
+	assert.Contains(t, string(data), "title: '{{.Kind}}: {{len .Findings}}'")
+
+	parsed, err := Parse(data)
+	require.NoError(t, err)
+
+	// The target host has no environment settings; imported values are used instead
+	t.Setenv("CHECK_INTERVAL", "")
+	t.Setenv("NOTIFY_MODE", "")
+	t.Setenv("NOTIFY_DIGEST_PERIOD", "")
+
+	target := newStore(t)
+	summary, err := Apply(ctx, target, parsed)
+	require.NoError(t, err)
+	assert.Equal(t, Summary{Containers: 2, RollbackPolicies: 2, ApprovalPolicies: 1, IgnoreRules: 1, Settings: 1}, summary)
+
+	plex, found, err := target.GetScriptAssignment(ctx, "plex")
+	require.NoError(t, err)
+	require.True(t, found)
+	assert.Equal(t, "check-plex.sh", plex.ScriptPath)
+	assert.True(t, plex.Enabled)
+
+	policy, found, err := target.GetRollbackPolicy(ctx, "stack", "media")
+	require.NoError(t, err)
+	require.True(t, found)
+	assert.True(t, policy.AutoRollbackEnabled)
+
+	approvalPolicy, found, err := target.GetApprovalPolicy(ctx, "container", "plex")
+	require.NoError(t, err)
+	require.True(t, found)
+	assert.Equal(t, storage.ApprovalModeMajor, approvalPolicy.Mode)
+
+	rules, err := target.ListIgnoreRules(ctx)
+	require.NoError(t, err)
+	require.Len(t, rules, 1)
+	assert.Equal(t, "*:nightly", rules[0].Pattern)
+	assert.Equal(t, "unstable builds", rules[0].Reason)
+
+	assert.Equal(t, "15m", storage.EnvOrConfig(ctx, target, "CHECK_INTERVAL", update.CheckIntervalConfigKey))
+	assert.Equal(t, "digest", storage.EnvOrConfig(ctx, target, "NOTIFY_MODE", "notify_mode"))
+
+	reexported, err := Collect(ctx, target)
+	require.NoError(t, err)
+	assert.Equal(t, exported.Containers, reexported.Containers)
+	assert.Equal(t, exported.Settings, reexported.Settings)
+	assert.Equal(t, exported.Schedule, reexported.Schedule)
+	assert.Equal(t, exported.Notifications, reexported.Notifications)
+}
+
+func TestParse_Invalid(t *testing.T) {
+	tests := map[string]string{
+		"version":        "version: 2\n",
+		"unknown field":  "version: 1\nfoo: bar\n",
+		"unknown key":    "version: 1\nsettings:\n  api_keys: x\n",
+		"policy scope":   "version: 1\nrollback_policies:\n  - scope: host\n",
+		"policy name":    "version: 1\nrollback_policies:\n  - scope: stack\n",
+		"interval":       "version: 1\nschedule:\n  check_interval: soon\n",
+		"mode":           "version: 1\nnotifications:\n  mode: hourly\n",
+		"priority":       "version: 1\nnotifications:\n  ntfy_priority: failure=9\n",
+		"template":       "version: 1\nnotifications:\n  templates:\n    slack:\n      text: \"{{.Findings\"\n",
+		"template name":  "version: 1\nnotifications:\n  templates:\n    email:\n      text: hi\n",
+		"duplicate name": "version: 1\ncontainers:\n  - name: a\n  - name: a\n",
+		"approval mode":  "version: 1\napproval_policies:\n  - scope: global\n    mode: minor\n",
+		"ignore pattern": "version: 1\nignore_rules:\n  - pattern: \"*\"\n",
+	}
+	for name, doc := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := Parse([]byte(doc))
+			assert.Error(t, err)
+		})
+	}
+}
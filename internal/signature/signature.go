@@ -0,0 +1,332 @@
+// Package signature verifies cosign signatures of container images. Images are
+// verified either with a public key (cosign sign --key) or keylessly, against the
+// certificate identity and OIDC issuer of a Sigstore (Fulcio) signing certificate.
+package signature
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/chis/docksmith/internal/registry"
+)
+
+// Policy decides what happens when the signature of an update's image cannot be verified.
+type Policy string
+
+// Signature policies
+const (
+	PolicyOff   Policy = "off"   // Signatures are not checked
+	PolicyWarn  Policy = "warn"  // Failures are logged and recorded, the update continues
+	PolicyBlock Policy = "block" // Failures fail the update before anything is pulled
+)
+
+// Verification modes
+const (
+	ModeKey     = "key"
+	ModeKeyless = "keyless"
+)
+
+// ParsePolicy parses a policy setting. ok is false for unknown values.
+func ParsePolicy(value string) (Policy, bool) {
+	switch Policy(strings.ToLower(strings.TrimSpace(value))) {
+	case PolicyOff, "":
+		return PolicyOff, true
+	case PolicyWarn:
+		return PolicyWarn, true
+	case PolicyBlock:
+		return PolicyBlock, true
+	default:
+		return PolicyOff, false
+	}
+}
+
+// Sentinel errors
+var (
+	ErrUnsigned = errors.New("image is not signed")
+	ErrNoTrust  = errors.New("no public key or trusted roots configured")
+)
+
+// Fulcio certificate extensions holding the OIDC issuer of keyless signatures
+var (
+	oidIssuerV1 = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 57264, 1, 1} // Raw string
+	oidIssuerV2 = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 57264, 1, 8} // DER UTF8String
+)
+
+// Options configures a Verifier. PublicKey selects key verification; otherwise
+// signatures are verified keylessly and Roots is required.
+type Options struct {
+	PublicKey []byte // PEM public key
+	Roots     []byte // PEM Fulcio root and intermediate certificates
+	Identity  string // Regular expression the certificate identity (email or URI) must match
+	Issuer    string // Regular expression the certificate OIDC issuer must match
+}
+
+// Verifier checks cosign signatures of image digests.
+type Verifier struct {
+	publicKey crypto.PublicKey
+	roots     *x509.CertPool
+	identity  *regexp.Regexp
+	issuer    *regexp.Regexp
+}
+
+// NewVerifier creates a Verifier from options.
+func NewVerifier(opts Options) (*Verifier, error) {
+	v := &Verifier{}
+	if len(opts.PublicKey) > 0 {
+		key, err := parsePublicKey(opts.PublicKey)
+		if err != nil {
+			return nil, err
+		}
+		v.publicKey = key
+		return v, nil
+	}
+
+	if len(opts.Roots) > 0 {
+		v.roots = x509.NewCertPool()
+		if !v.roots.AppendCertsFromPEM(opts.Roots) {
+			return nil, fmt.Errorf("no certificates found in trusted roots")
+		}
+	}
+	var err error
+	if opts.Identity != "" {
+		if v.identity, err = regexp.Compile(opts.Identity); err != nil {
+			return nil, fmt.Errorf("invalid certificate identity pattern: %w", err)
+		}
+	}
+	if opts.Issuer != "" {
+		if v.issuer, err = regexp.Compile(opts.Issuer); err != nil {
+			return nil, fmt.Errorf("invalid certificate issuer pattern: %w", err)
+		}
+	}
+	return v, nil
+}
+
+// Mode returns ModeKey or ModeKeyless.
+func (v *Verifier) Mode() string {
+	if v.publicKey != nil {
+		return ModeKey
+	}
+	return ModeKeyless
+}
+
+// Verify checks that one of sigs is a valid signature of the image at digest.
+// It returns the certificate identity of keyless signatures. ErrUnsigned is
+// returned when there are no signatures.
+//
+// Keyless verification checks the signing certificate chains to the trusted
+// roots at the time recorded in its transparency log bundle. The transparency
+// log entry itself is not verified.
+func (v *Verifier) Verify(digest string, sigs []registry.Signature) (string, error) {
+	if len(sigs) == 0 {
+		return "", ErrUnsigned
+	}
+
+	var lastErr error
+	for _, sig := range sigs {
+		signer, err := v.verify(digest, sig)
+		if err == nil {
+			return signer, nil
+		}
+		lastErr = err
+	}
+	if len(sigs) == 1 {
+		return "", lastErr
+	}
+	return "", fmt.Errorf("none of %d signatures is valid: %w", len(sigs), lastErr)
+}
+
+// verify checks a single signature.
+func (v *Verifier) verify(digest string, sig registry.Signature) (string, error) {
+	raw, err := base64.StdEncoding.DecodeString(sig.Signature)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode signature: %w", err)
+	}
+
+	key, signer := v.publicKey, ""
+	if key == nil {
+		cert, err := v.verifyCertificate(sig)
+		if err != nil {
+			return "", err
+		}
+		key, signer = cert.PublicKey, certificateIdentity(cert)
+	}
+	if err := verifySignature(key, sig.Payload, raw); err != nil {
+		return "", err
+	}
+
+	// The signed payload must be for this image, or a signature could be replayed from another one
+	var payload struct {
+		Critical struct {
+			Image struct {
+				Digest string `json:"docker-manifest-digest"`
+			} `json:"image"`
+		} `json:"critical"`
+	}
+	if err := json.Unmarshal(sig.Payload, &payload); err != nil {
+		return "", fmt.Errorf("failed to decode signature payload: %w", err)
+	}
+	if payload.Critical.Image.Digest != digest {
+		return "", fmt.Errorf("signature is for %s, not %s", payload.Critical.Image.Digest, digest)
+	}
+	return signer, nil
+}
+
+// verifyCertificate checks the signing certificate of a keyless signature against
+// the trusted roots and the identity and issuer patterns.
+func (v *Verifier) verifyCertificate(sig registry.Signature) (*x509.Certificate, error) {
+	if v.roots == nil {
+		return nil, ErrNoTrust
+	}
+	if sig.Certificate == "" {
+		return nil, fmt.Errorf("signature has no certificate; configure a public key to verify key-based signatures")
+	}
+	certs, err := parseCertificates([]byte(sig.Certificate))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse signing certificate: %w", err)
+	}
+	if len(certs) == 0 {
+		return nil, fmt.Errorf("no PEM signing certificate found")
+	}
+	cert := certs[0]
+
+	intermediates := x509.NewCertPool()
+	if sig.Chain != "" {
+		chain, err := parseCertificates([]byte(sig.Chain))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse certificate chain: %w", err)
+		}
+		for _, c := range chain {
+			intermediates.AddCert(c)
+		}
+	}
+
+	// Fulcio certificates are valid for minutes, so they are checked at signing time
+	_, err = cert.Verify(x509.VerifyOptions{
+		Roots:         v.roots,
+		Intermediates: intermediates,
+		CurrentTime:   signingTime(sig, cert),
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("untrusted signing certificate: %w", err)
+	}
+
+	identity := certificateIdentity(cert)
+	if v.identity != nil && !v.identity.MatchString(identity) {
+		return nil, fmt.Errorf("certificate identity %q does not match %q", identity, v.identity)
+	}
+	issuer := certificateIssuer(cert)
+	if v.issuer != nil && !v.issuer.MatchString(issuer) {
+		return nil, fmt.Errorf("certificate issuer %q does not match %q", issuer, v.issuer)
+	}
+	return cert, nil
+}
+
+// verifySignature checks raw is a signature of payload by key.
+func verifySignature(key crypto.PublicKey, payload, raw []byte) error {
+	hash := sha256.Sum256(payload)
+	switch k := key.(type) {
+	case *ecdsa.PublicKey:
+		if !ecdsa.VerifyASN1(k, hash[:], raw) {
+			return fmt.Errorf("invalid signature")
+		}
+	case *rsa.PublicKey:
+		if err := rsa.VerifyPKCS1v15(k, crypto.SHA256, hash[:], raw); err != nil {
+			return fmt.Errorf("invalid signature")
+		}
+	case ed25519.PublicKey:
+		if !ed25519.Verify(k, payload, raw) {
+			return fmt.Errorf("invalid signature")
+		}
+	default:
+		return fmt.Errorf("unsupported key type %T", key)
+	}
+	return nil
+}
+
+// signingTime returns when a signature was made: the transparency log time from its
+// bundle, or the start of the certificate's validity when there is no bundle.
+func signingTime(sig registry.Signature, cert *x509.Certificate) time.Time {
+	var bundle struct {
+		Payload struct {
+			IntegratedTime int64 `json:"integratedTime"`
+		} `json:"Payload"`
+	}
+	if sig.Bundle != "" && json.Unmarshal([]byte(sig.Bundle), &bundle) == nil && bundle.Payload.IntegratedTime > 0 {
+		return time.Unix(bundle.Payload.IntegratedTime, 0)
+	}
+	return cert.NotBefore
+}
+
+// certificateIdentity returns the email or URI subject alternative name of a signing certificate.
+func certificateIdentity(cert *x509.Certificate) string {
+	if len(cert.EmailAddresses) > 0 {
+		return cert.EmailAddresses[0]
+	}
+	if len(cert.URIs) > 0 {
+		return cert.URIs[0].String()
+	}
+	return ""
+}
+
+// certificateIssuer returns the OIDC issuer recorded in a Fulcio signing certificate.
+func certificateIssuer(cert *x509.Certificate) string {
+	for _, ext := range cert.Extensions {
+		switch {
+		case ext.Id.Equal(oidIssuerV2):
+			var issuer string
+			if _, err := asn1.Unmarshal(ext.Value, &issuer); err == nil {
+				return issuer
+			}
+		case ext.Id.Equal(oidIssuerV1):
+			return string(ext.Value)
+		}
+	}
+	return ""
+}
+
+// parsePublicKey parses a PEM encoded PKIX public key.
+func parsePublicKey(data []byte) (crypto.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM public key found")
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse public key: %w", err)
+	}
+	return key, nil
+}
+
+// parseCertificates parses every PEM certificate in data.
+func parseCertificates(data []byte) ([]*x509.Certificate, error) {
+	var certs []*x509.Certificate
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			return certs, nil
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		certs = append(certs, cert)
+	}
+}
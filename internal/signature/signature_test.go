@@ -0,0 +1,217 @@
+package signature
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/chis/docksmith/internal/registry"
+)
+
+const testDigest = "sha256:4f53cda18c2baa0c0354bb5f9a3ecbe5ed12ab4d8e11ba873c2f11161202b945"
+
+// signPayload signs the simple signing payload for digest with key.
+func signPayload(t *testing.T, key *ecdsa.PrivateKey, digest string) registry.Signature {
+	t.Helper()
+	payload := []byte(fmt.Sprintf(`{"critical":{"identity":{"docker-reference":"ghcr.io/org/app"},"image":{"docker-manifest-digest":%q},"type":"cosign container image signature"},"optional":null}`, digest))
+	hash := sha256.Sum256(payload)
+	raw, err := ecdsa.SignASN1(rand.Reader, key, hash[:])
+	if err != nil {
+		t.Fatalf("failed to sign: %v", err)
+	}
+	return registry.Signature{Payload: payload, Signature: base64.StdEncoding.EncodeToString(raw)}
+}
+
+func newKey(t *testing.T) *ecdsa.PrivateKey {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	return key
+}
+
+func publicKeyPEM(t *testing.T, key *ecdsa.PrivateKey) []byte {
+	t.Helper()
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatalf("failed to marshal public key: %v", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+}
+
+func TestParsePolicy(t *testing.T) {
+	tests := []struct {
+		value string
+		want  Policy
+		ok    bool
+	}{
+		{"", PolicyOff, true},
+		{"off", PolicyOff, true},
+		{"WARN", PolicyWarn, true},
+		{" block ", PolicyBlock, true},
+		{"enforce", PolicyOff, false},
+	}
+	for _, tt := range tests {
+		got, ok := ParsePolicy(tt.value)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("ParsePolicy(%q) = %q, %v, want %q, %v", tt.value, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestVerify_PublicKey(t *testing.T) {
+	key := newKey(t)
+	verifier, err := NewVerifier(Options{PublicKey: publicKeyPEM(t, key)})
+	if err != nil {
+		t.Fatalf("NewVerifier failed: %v", err)
+	}
+	if verifier.Mode() != ModeKey {
+		t.Errorf("expected key mode, got %s", verifier.Mode())
+	}
+
+	sig := signPayload(t, key, testDigest)
+	if _, err := verifier.Verify(testDigest, []registry.Signature{sig}); err != nil {
+		t.Errorf("expected a valid signature, got %v", err)
+	}
+
+	// A valid signature for another image is not accepted
+	if _, err := verifier.Verify("sha256:other", []registry.Signature{sig}); err == nil {
+		t.Error("expected a signature for another digest to fail")
+	}
+
+	// Signed with a different key
+	other := signPayload(t, newKey(t), testDigest)
+	if _, err := verifier.Verify(testDigest, []registry.Signature{other}); err == nil {
+		t.Error("expected a signature by another key to fail")
+	}
+
+	// One valid signature among several is enough
+	if _, err := verifier.Verify(testDigest, []registry.Signature{other, sig}); err != nil {
+		t.Errorf("expected one valid signature to pass, got %v", err)
+	}
+
+	if _, err := verifier.Verify(testDigest, nil); !errors.Is(err, ErrUnsigned) {
+		t.Errorf("expected ErrUnsigned, got %v", err)
+	}
+}
+
+// newCertificate issues a certificate for key, signed by parent (self-signed when nil).
+func newCertificate(t *testing.T, template *x509.Certificate, key *ecdsa.PrivateKey, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) *x509.Certificate {
+	t.Helper()
+	if parent == nil {
+		parent, parentKey = template, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		t.Fatalf("failed to create certificate: %v", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("failed to parse certificate: %v", err)
+	}
+	return cert
+}
+
+func certPEM(cert *x509.Certificate) string {
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw}))
+}
+
+func TestVerify_Keyless(t *testing.T) {
+	signedAt := time.Now().Add(-24 * time.Hour)
+
+	rootKey := newKey(t)
+	root := newCertificate(t, &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test fulcio root"},
+		NotBefore:             signedAt.Add(-time.Hour),
+		NotAfter:              signedAt.Add(365 * 24 * time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}, rootKey, nil, nil)
+
+	issuer, err := asn1.Marshal("https://token.actions.githubusercontent.com")
+	if err != nil {
+		t.Fatalf("failed to marshal issuer: %v", err)
+	}
+	signingKey := newKey(t)
+	// Fulcio certificates are only valid for a few minutes around signing
+	leaf := newCertificate(t, &x509.Certificate{
+		SerialNumber:    big.NewInt(2),
+		NotBefore:       signedAt,
+		NotAfter:        signedAt.Add(10 * time.Minute),
+		KeyUsage:        x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:     []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning},
+		EmailAddresses:  []string{"release@example.com"},
+		ExtraExtensions: []pkix.Extension{{Id: oidIssuerV2, Value: issuer}},
+	}, signingKey, root, rootKey)
+
+	sig := signPayload(t, signingKey, testDigest)
+	sig.Certificate = certPEM(leaf)
+	sig.Bundle = fmt.Sprintf(`{"SignedEntryTimestamp":"","Payload":{"integratedTime":%d,"logIndex":1}}`, signedAt.Add(time.Minute).Unix())
+
+	verifier, err := NewVerifier(Options{
+		Roots:    []byte(certPEM(root)),
+		Identity: `^release@example\.com$`,
+		Issuer:   `^https://token\.actions\.githubusercontent\.com$`,
+	})
+	if err != nil {
+		t.Fatalf("NewVerifier failed: %v", err)
+	}
+	if verifier.Mode() != ModeKeyless {
+		t.Errorf("expected keyless mode, got %s", verifier.Mode())
+	}
+
+	signer, err := verifier.Verify(testDigest, []registry.Signature{sig})
+	if err != nil {
+		t.Fatalf("expected a valid keyless signature, got %v", err)
+	}
+	if signer != "release@example.com" {
+		t.Errorf("expected signer release@example.com, got %q", signer)
+	}
+
+	wrongIdentity, err := NewVerifier(Options{Roots: []byte(certPEM(root)), Identity: `^someone@example\.com$`})
+	if err != nil {
+		t.Fatalf("NewVerifier failed: %v", err)
+	}
+	if _, err := wrongIdentity.Verify(testDigest, []registry.Signature{sig}); err == nil {
+		t.Error("expected a certificate for another identity to fail")
+	}
+
+	otherRoot := newCertificate(t, &x509.Certificate{
+		SerialNumber:          big.NewInt(3),
+		Subject:               pkix.Name{CommonName: "other root"},
+		NotBefore:             signedAt.Add(-time.Hour),
+		NotAfter:              signedAt.Add(365 * 24 * time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}, newKey(t), nil, nil)
+	untrusted, err := NewVerifier(Options{Roots: []byte(certPEM(otherRoot))})
+	if err != nil {
+		t.Fatalf("NewVerifier failed: %v", err)
+	}
+	if _, err := untrusted.Verify(testDigest, []registry.Signature{sig}); err == nil {
+		t.Error("expected a certificate from an untrusted root to fail")
+	}
+
+	noRoots, err := NewVerifier(Options{})
+	if err != nil {
+		t.Fatalf("NewVerifier failed: %v", err)
+	}
+	if _, err := noRoots.Verify(testDigest, []registry.Signature{sig}); !errors.Is(err, ErrNoTrust) {
+		t.Errorf("expected ErrNoTrust without roots, got %v", err)
+	}
+}
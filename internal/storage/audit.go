@@ -0,0 +1,185 @@
+package storage
+
+import (
+	"cmp"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// Audit log export formats accepted by WriteAuditLog.
+const (
+	AuditFormatCSV   = "csv"
+	AuditFormatJSONL = "jsonl"
+)
+
+// auditPageSize is how many operations QueryAuditLog reads per query.
+const auditPageSize = 500
+
+// AuditRecord is one change in the update audit log: a container changed by a
+// finished update operation, or an entry of the update log.
+type AuditRecord struct {
+	Timestamp    time.Time  `json:"timestamp"`
+	Source       string     `json:"source"` // "operation" or "update_log"
+	OperationID  string     `json:"operation_id,omitempty"`
+	BatchGroupID string     `json:"batch_group_id,omitempty"`
+	Operation    string     `json:"operation"`
+	Container    string     `json:"container"`
+	Stack        string     `json:"stack,omitempty"`
+	FromVersion  string     `json:"from_version,omitempty"`
+	ToVersion    string     `json:"to_version,omitempty"`
+	Status       string     `json:"status"`
+	TriggeredBy  string     `json:"triggered_by,omitempty"`
+	StartedAt    *time.Time `json:"started_at,omitempty"`
+	CompletedAt  *time.Time `json:"completed_at,omitempty"`
+	RolledBack   bool       `json:"rolled_back"`
+	Error        string     `json:"error,omitempty"`
+}
+
+// auditColumns is the CSV header, in the order of AuditRecord's fields.
+var auditColumns = []string{
+	"timestamp", "source", "operation_id", "batch_group_id", "operation", "container", "stack",
+	"from_version", "to_version", "status", "triggered_by", "started_at", "completed_at", "rolled_back", "error",
+}
+
+// QueryAuditLog returns the finished update operations and update log entries
+// between from and to, oldest first. Either bound may be nil. Operations that
+// changed several containers produce one record per container.
+func QueryAuditLog(ctx context.Context, store Storage, from, to *time.Time) ([]AuditRecord, error) {
+	records := []AuditRecord{}
+
+	opts := OperationQueryOptions{DateFrom: from, DateTo: to, Limit: auditPageSize}
+	for {
+		result, err := store.QueryUpdateOperations(ctx, opts)
+		if err != nil {
+			return nil, err
+		}
+		for _, op := range result.Operations {
+			records = append(records, operationAuditRecords(op)...)
+		}
+		if !result.HasMore || result.NextCursor == "" {
+			break
+		}
+		opts.Cursor = result.NextCursor
+	}
+
+	entries, err := store.GetAllUpdateLog(ctx, 0)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		if (from != nil && entry.Timestamp.Before(*from)) || (to != nil && entry.Timestamp.After(*to)) {
+			continue
+		}
+		status := "complete"
+		if !entry.Success {
+			status = "failed"
+		}
+		records = append(records, AuditRecord{
+			Timestamp:   entry.Timestamp,
+			Source:      "update_log",
+			Operation:   entry.Operation,
+			Container:   entry.ContainerName,
+			FromVersion: entry.FromVersion,
+			ToVersion:   entry.ToVersion,
+			Status:      status,
+			Error:       entry.Error,
+		})
+	}
+
+	sort.SliceStable(records, func(i, j int) bool {
+		return records[i].Timestamp.Before(records[j].Timestamp)
+	})
+	return records, nil
+}
+
+// operationAuditRecords converts an update operation to one audit record per container.
+func operationAuditRecords(op UpdateOperation) []AuditRecord {
+	base := AuditRecord{
+		Timestamp:    op.CreatedAt,
+		Source:       "operation",
+		OperationID:  op.OperationID,
+		BatchGroupID: op.BatchGroupID,
+		Operation:    op.OperationType,
+		Container:    op.ContainerName,
+		Stack:        op.StackName,
+		FromVersion:  op.OldVersion,
+		ToVersion:    op.NewVersion,
+		Status:       op.Status,
+		TriggeredBy:  op.TriggeredBy,
+		StartedAt:    op.StartedAt,
+		CompletedAt:  op.CompletedAt,
+		RolledBack:   op.RollbackOccurred,
+		Error:        op.ErrorMessage,
+	}
+	if op.StartedAt != nil {
+		base.Timestamp = *op.StartedAt
+	}
+	if len(op.BatchDetails) == 0 {
+		return []AuditRecord{base}
+	}
+
+	records := make([]AuditRecord, 0, len(op.BatchDetails))
+	for _, detail := range op.BatchDetails {
+		record := base
+		record.Container = detail.ContainerName
+		record.Stack = cmp.Or(detail.StackName, op.StackName)
+		record.FromVersion = detail.OldVersion
+		record.ToVersion = detail.NewVersion
+		record.Status = cmp.Or(detail.Status, op.Status)
+		if detail.Status == "failed" {
+			record.Error = cmp.Or(detail.Message, op.ErrorMessage)
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+// WriteAuditLog writes records as CSV with a header row, or as one JSON object per line.
+func WriteAuditLog(w io.Writer, format string, records []AuditRecord) error {
+	switch format {
+	case AuditFormatCSV:
+		cw := csv.NewWriter(w)
+		if err := cw.Write(auditColumns); err != nil {
+			return fmt.Errorf("failed to write audit log: %w", err)
+		}
+		for _, r := range records {
+			row := []string{
+				r.Timestamp.UTC().Format(time.RFC3339), r.Source, r.OperationID, r.BatchGroupID, r.Operation, r.Container, r.Stack,
+				r.FromVersion, r.ToVersion, r.Status, r.TriggeredBy, formatAuditTime(r.StartedAt), formatAuditTime(r.CompletedAt),
+				strconv.FormatBool(r.RolledBack), r.Error,
+			}
+			if err := cw.Write(row); err != nil {
+				return fmt.Errorf("failed to write audit log: %w", err)
+			}
+		}
+		cw.Flush()
+		if err := cw.Error(); err != nil {
+			return fmt.Errorf("failed to write audit log: %w", err)
+		}
+		return nil
+	case AuditFormatJSONL:
+		enc := json.NewEncoder(w)
+		for _, r := range records {
+			if err := enc.Encode(r); err != nil {
+				return fmt.Errorf("failed to write audit log: %w", err)
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported audit log format %q (must be %s or %s)", format, AuditFormatCSV, AuditFormatJSONL)
+	}
+}
+
+// formatAuditTime formats an optional time for CSV, empty when unset.
+func formatAuditTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
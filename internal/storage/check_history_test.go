@@ -311,6 +311,47 @@ func TestLogCheckBatch(t *testing.T) {
 	}
 }
 
+// TestLogCheckBatchSizes tests that image size data round-trips through check history
+func TestLogCheckBatchSizes(t *testing.T) {
+	tempDir := t.TempDir()
+	dbPath := filepath.Join(tempDir, "test.db")
+
+	storage, err := NewSQLiteStorage(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer storage.Close()
+
+	ctx := context.Background()
+
+	checks := []CheckHistoryEntry{
+		{
+			ContainerName:  "nginx-app",
+			Image:          "docker.io/library/nginx:1.25.0",
+			CurrentVersion: "1.25.0",
+			LatestVersion:  "1.25.3",
+			Status:         "update_available",
+			LatestSize:     72_000_000,
+			SizeDelta:      -3_500_000,
+		},
+	}
+
+	if err := storage.LogCheckBatch(ctx, checks); err != nil {
+		t.Fatalf("LogCheckBatch failed: %v", err)
+	}
+
+	history, err := storage.GetCheckHistory(ctx, "nginx-app", 10)
+	if err != nil {
+		t.Fatalf("GetCheckHistory failed: %v", err)
+	}
+	if len(history) != 1 {
+		t.Fatalf("Expected 1 entry, got %d", len(history))
+	}
+	if history[0].LatestSize != 72_000_000 || history[0].SizeDelta != -3_500_000 {
+		t.Errorf("Expected size 72000000 with delta -3500000, got %d / %d", history[0].LatestSize, history[0].SizeDelta)
+	}
+}
+
 // TestLogCheckBatchRollback tests that batch logging rolls back on error
 func TestLogCheckBatchRollback(t *testing.T) {
 	tempDir := t.TempDir()
@@ -395,3 +436,141 @@ func TestGetCheckHistoryLimit(t *testing.T) {
 		t.Errorf("Expected 5 history entries with limit=5, got %d", len(history))
 	}
 }
+
+// TestQueryCheckHistory tests filtering check history by container, status and date
+func TestQueryCheckHistory(t *testing.T) {
+	tempDir := t.TempDir()
+	dbPath := filepath.Join(tempDir, "test.db")
+
+	storage, err := NewSQLiteStorage(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer storage.Close()
+
+	ctx := context.Background()
+	checks := []CheckHistoryEntry{
+		{ContainerName: "nginx-app", Image: "nginx:1.25.0", CurrentVersion: "1.25.0", LatestVersion: "1.25.3", Status: "update_available"},
+		{ContainerName: "redis-cache", Image: "redis:7", CurrentVersion: "7.2.0", LatestVersion: "7.2.0", Status: "up_to_date"},
+		{ContainerName: "postgres-db", Image: "postgres:15", CurrentVersion: "15.3", LatestVersion: "15.4", Status: "update_available"},
+	}
+	if err := storage.LogCheckBatch(ctx, checks); err != nil {
+		t.Fatalf("LogCheckBatch failed: %v", err)
+	}
+
+	entries, err := storage.QueryCheckHistory(ctx, CheckHistoryQueryOptions{Status: "update_available"})
+	if err != nil {
+		t.Fatalf("QueryCheckHistory failed: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Errorf("Expected 2 update_available entries, got %d", len(entries))
+	}
+
+	entries, err = storage.QueryCheckHistory(ctx, CheckHistoryQueryOptions{
+		Containers: []string{"redis-cache", "postgres-db"},
+		Status:     "update_available",
+	})
+	if err != nil {
+		t.Fatalf("QueryCheckHistory failed: %v", err)
+	}
+	if len(entries) != 1 || entries[0].ContainerName != "postgres-db" {
+		t.Errorf("Expected only postgres-db, got %+v", entries)
+	}
+
+	future := time.Now().Add(time.Hour)
+	entries, err = storage.QueryCheckHistory(ctx, CheckHistoryQueryOptions{DateFrom: &future})
+	if err != nil {
+		t.Fatalf("QueryCheckHistory failed: %v", err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("Expected no entries after %v, got %d", future, len(entries))
+	}
+
+	entries, err = storage.QueryCheckHistory(ctx, CheckHistoryQueryOptions{Limit: 1})
+	if err != nil {
+		t.Fatalf("QueryCheckHistory failed: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("Expected limit of 1 entry, got %d", len(entries))
+	}
+}
+
+func TestQueryTimeline(t *testing.T) {
+	tempDir := t.TempDir()
+	dbPath := filepath.Join(tempDir, "test.db")
+
+	storage, err := NewSQLiteStorage(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer storage.Close()
+
+	ctx := context.Background()
+	checks := []CheckHistoryEntry{
+		{ContainerName: "nginx-app", Image: "nginx:1.25.0", CurrentVersion: "1.25.0", LatestVersion: "1.25.3", Status: "update_available"},
+		{ContainerName: "redis-cache", Image: "redis:7", CurrentVersion: "7.2.0", LatestVersion: "7.2.0", Status: "up_to_date"},
+	}
+	if err := storage.LogCheckBatch(ctx, checks); err != nil {
+		t.Fatalf("LogCheckBatch failed: %v", err)
+	}
+
+	started := time.Now().Add(time.Minute)
+	op := UpdateOperation{
+		OperationID:   "op-1",
+		ContainerName: "nginx-app",
+		StackName:     "web",
+		OperationType: "single",
+		Status:        "complete",
+		OldVersion:    "1.25.0",
+		NewVersion:    "1.25.3",
+		StartedAt:     &started,
+		TriggeredBy:   "schedule:web",
+	}
+	if err := storage.SaveUpdateOperation(ctx, op); err != nil {
+		t.Fatalf("SaveUpdateOperation failed: %v", err)
+	}
+
+	entries, err := QueryTimeline(ctx, storage, TimelineQueryOptions{})
+	if err != nil {
+		t.Fatalf("QueryTimeline failed: %v", err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("Expected 3 entries, got %d", len(entries))
+	}
+	if entries[0].Type != "update" || entries[0].OperationID != "op-1" {
+		t.Errorf("Expected the update first (newest), got %+v", entries[0])
+	}
+	if entries[0].TriggeredBy != "schedule:web" {
+		t.Errorf("Expected the update to be triggered by schedule:web, got %q", entries[0].TriggeredBy)
+	}
+
+	// A stack filter matches the stack's operations and the checks of its containers
+	entries, err = QueryTimeline(ctx, storage, TimelineQueryOptions{Stack: "web"})
+	if err != nil {
+		t.Fatalf("QueryTimeline failed: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Errorf("Expected 2 entries for stack web, got %+v", entries)
+	}
+	for _, e := range entries {
+		if e.ContainerName != "nginx-app" {
+			t.Errorf("Unexpected container %s in stack web", e.ContainerName)
+		}
+	}
+
+	entries, err = QueryTimeline(ctx, storage, TimelineQueryOptions{Type: "check", Status: "up_to_date"})
+	if err != nil {
+		t.Fatalf("QueryTimeline failed: %v", err)
+	}
+	if len(entries) != 1 || entries[0].ContainerName != "redis-cache" {
+		t.Errorf("Expected only the redis-cache check, got %+v", entries)
+	}
+
+	entries, err = QueryTimeline(ctx, storage, TimelineQueryOptions{Stack: "missing"})
+	if err != nil {
+		t.Fatalf("QueryTimeline failed: %v", err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("Expected no entries for unknown stack, got %+v", entries)
+	}
+}
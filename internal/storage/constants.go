@@ -8,12 +8,17 @@ const (
 	StatusValidating    = "validating"
 	StatusBackup        = "backup"
 	StatusPullingImage  = "pulling_image"
+	StatusBuildingImage = "building_image"
 	StatusRecreating    = "recreating"
 	StatusHealthCheck   = "health_check"
 	StatusRollingBack   = "rolling_back"
 	StatusInProgress    = "in_progress"
+	StatusPaused        = "paused"
 )
 
+// MaxComposeOutput caps how much docker compose output is kept on an operation
+const MaxComposeOutput = 64 * 1024
+
 // Check status constants
 const (
 	CheckStatusUpToDate        = "up_to_date"
@@ -0,0 +1,91 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Config keys of the history retention policy. Values are a number of days;
+// "0" or unset keeps rows forever.
+const (
+	CheckHistoryRetentionConfigKey = "check_history_retention_days"
+	UpdateLogRetentionConfigKey    = "update_log_retention_days"
+)
+
+// VacuumResult reports the database size before and after a vacuum.
+type VacuumResult struct {
+	SizeBefore int64 `json:"size_before"` // bytes
+	SizeAfter  int64 `json:"size_after"`
+}
+
+// PruneOptions selects the rows deleted by PruneHistory.
+type PruneOptions struct {
+	CheckHistoryBefore time.Time // zero keeps all check history
+	UpdateLogBefore    time.Time // zero keeps the whole update log
+}
+
+// PruneResult counts the rows deleted by PruneHistory.
+type PruneResult struct {
+	CheckHistory int64 `json:"check_history"`
+	UpdateLog    int64 `json:"update_log"`
+}
+
+// ComposeBackup records a copy of a compose file taken before an update changed it.
+type ComposeBackup struct {
+	ID              int64     `json:"id"`
+	OperationID     string    `json:"operation_id"`
+	ContainerName   string    `json:"container_name"`
+	StackName       string    `json:"stack_name,omitempty"`
+	ComposeFilePath string    `json:"compose_file_path"`
+	BackupFilePath  string    `json:"backup_file_path"`
+	BackupTimestamp time.Time `json:"backup_timestamp"`
+}
+
+// RetentionPolicy is how many days of check history and update log to keep.
+// Zero keeps rows forever.
+type RetentionPolicy struct {
+	CheckHistoryDays int `json:"check_history_days"`
+	UpdateLogDays    int `json:"update_log_days"`
+}
+
+// IsSet reports whether the policy prunes anything.
+func (p RetentionPolicy) IsSet() bool {
+	return p.CheckHistoryDays > 0 || p.UpdateLogDays > 0
+}
+
+// PruneOptions returns the cutoffs of the policy relative to now.
+func (p RetentionPolicy) PruneOptions(now time.Time) PruneOptions {
+	var opts PruneOptions
+	if p.CheckHistoryDays > 0 {
+		opts.CheckHistoryBefore = now.AddDate(0, 0, -p.CheckHistoryDays)
+	}
+	if p.UpdateLogDays > 0 {
+		opts.UpdateLogBefore = now.AddDate(0, 0, -p.UpdateLogDays)
+	}
+	return opts
+}
+
+// GetRetentionPolicy reads the retention policy from the config table.
+func GetRetentionPolicy(ctx context.Context, store Storage) (RetentionPolicy, error) {
+	var policy RetentionPolicy
+	for key, days := range map[string]*int{
+		CheckHistoryRetentionConfigKey: &policy.CheckHistoryDays,
+		UpdateLogRetentionConfigKey:    &policy.UpdateLogDays,
+	} {
+		value, found, err := store.GetConfig(ctx, key)
+		if err != nil {
+			return policy, fmt.Errorf("failed to read %s: %w", key, err)
+		}
+		if !found || value == "" {
+			continue
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return policy, fmt.Errorf("invalid %s %q", key, value)
+		}
+		*days = n
+	}
+	return policy, nil
+}
@@ -0,0 +1,467 @@
+package storage
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"log"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+)
+
+// MemoryStorage implements the Storage interface in process memory.
+// Nothing is written to disk and all state is lost when the process exits,
+// which suits CI jobs and one-shot runs on read-only filesystems.
+type MemoryStorage struct {
+	mu sync.Mutex
+
+	nextID int64
+
+	versionCache     map[versionCacheKey]memoryVersion
+	tagCache         map[string]TagCacheEntry
+	fingerprints     map[string]CheckFingerprint
+	checkHistory     []CheckHistoryEntry
+	updateLog        []UpdateLogEntry
+	config           map[string]string
+	configHistory    []ConfigSnapshot
+	operations       map[string]UpdateOperation
+	rollbackPolicies map[policyKey]RollbackPolicy
+	approvalPolicies map[policyKey]ApprovalPolicy
+	ignoreRules      []IgnoreRule
+	stackEnvs        map[string]StackEnv
+	queue            []UpdateQueue
+	stackLocks       map[string]StackLock
+	scripts          map[string]ScriptAssignment
+	scriptRevisions  []ScriptRevision
+	volumeSnapshots  []VolumeSnapshot
+	operationLogs    []OperationLogEntry
+	users            map[int64]User
+	sessions         map[string]Session
+	ownerships       []Ownership
+	teamMembers      []TeamMember
+	approvals        map[string]Approval
+	proposals        map[string]Proposal
+}
+
+var _ Storage = (*MemoryStorage)(nil)
+
+// versionCacheKey is the composite key of a version cache entry
+type versionCacheKey struct {
+	sha256, imageRef, arch string
+}
+
+// memoryVersion is a cached version resolution
+type memoryVersion struct {
+	version    string
+	resolvedAt time.Time
+}
+
+// policyKey identifies a rollback or approval policy
+type policyKey struct {
+	entityType, entityID string
+}
+
+// NewMemoryStorage creates an empty in-memory storage instance.
+func NewMemoryStorage() *MemoryStorage {
+	log.Println("Using in-memory storage; state will not persist across restarts")
+	return &MemoryStorage{
+		versionCache:     make(map[versionCacheKey]memoryVersion),
+		tagCache:         make(map[string]TagCacheEntry),
+		fingerprints:     make(map[string]CheckFingerprint),
+		config:           make(map[string]string),
+		operations:       make(map[string]UpdateOperation),
+		stackLocks:       make(map[string]StackLock),
+		rollbackPolicies: make(map[policyKey]RollbackPolicy),
+		approvalPolicies: make(map[policyKey]ApprovalPolicy),
+		stackEnvs:        make(map[string]StackEnv),
+		scripts:          make(map[string]ScriptAssignment),
+		users:            make(map[int64]User),
+		sessions:         make(map[string]Session),
+		approvals:        make(map[string]Approval),
+		proposals:        make(map[string]Proposal),
+	}
+}
+
+// Close implements Storage.Close. There is nothing to release.
+func (m *MemoryStorage) Close() error {
+	return nil
+}
+
+// id returns the next row ID. The caller must hold m.mu.
+func (m *MemoryStorage) id() int64 {
+	m.nextID++
+	return m.nextID
+}
+
+// SaveVersionCache implements Storage.SaveVersionCache.
+func (m *MemoryStorage) SaveVersionCache(ctx context.Context, sha256, imageRef, version, arch string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.versionCache[versionCacheKey{sha256, imageRef, arch}] = memoryVersion{version: version, resolvedAt: time.Now()}
+	return nil
+}
+
+// GetVersionCache implements Storage.GetVersionCache.
+// Entries older than CACHE_TTL (default 1 hour) are treated as missing.
+func (m *MemoryStorage) GetVersionCache(ctx context.Context, sha256, imageRef, arch string) (string, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	entry, ok := m.versionCache[versionCacheKey{sha256, imageRef, arch}]
+	if !ok || entry.resolvedAt.Before(time.Now().Add(-versionCacheTTL())) {
+		return "", false, nil
+	}
+	return entry.version, true, nil
+}
+
+// GetTagCache implements Storage.GetTagCache.
+func (m *MemoryStorage) GetTagCache(ctx context.Context, imageRef string) (TagCacheEntry, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	entry, ok := m.tagCache[imageRef]
+	if !ok {
+		return TagCacheEntry{}, false, nil
+	}
+	entry.Tags = slices.Clone(entry.Tags)
+	return entry, true, nil
+}
+
+// SaveTagCache implements Storage.SaveTagCache.
+func (m *MemoryStorage) SaveTagCache(ctx context.Context, entry TagCacheEntry) error {
+	if entry.FetchedAt.IsZero() {
+		entry.FetchedAt = time.Now()
+	}
+	entry.Tags = slices.Clone(entry.Tags)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.tagCache[entry.ImageRef] = entry
+	return nil
+}
+
+// GetCheckFingerprint implements Storage.GetCheckFingerprint.
+func (m *MemoryStorage) GetCheckFingerprint(ctx context.Context, containerName string) (CheckFingerprint, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	fingerprint, ok := m.fingerprints[containerName]
+	return fingerprint, ok, nil
+}
+
+// SaveCheckFingerprint implements Storage.SaveCheckFingerprint.
+func (m *MemoryStorage) SaveCheckFingerprint(ctx context.Context, fingerprint CheckFingerprint) error {
+	if fingerprint.CheckedAt.IsZero() {
+		fingerprint.CheckedAt = time.Now()
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.fingerprints[fingerprint.ContainerName] = fingerprint
+	return nil
+}
+
+// LogCheck implements Storage.LogCheck.
+func (m *MemoryStorage) LogCheck(ctx context.Context, containerName, image, currentVer, latestVer, status string, checkErr error) error {
+	entry := CheckHistoryEntry{
+		ContainerName:  containerName,
+		Image:          image,
+		CurrentVersion: currentVer,
+		LatestVersion:  latestVer,
+		Status:         status,
+	}
+	if checkErr != nil {
+		entry.Error = checkErr.Error()
+	}
+	return m.LogCheckBatch(ctx, []CheckHistoryEntry{entry})
+}
+
+// LogCheckBatch implements Storage.LogCheckBatch.
+func (m *MemoryStorage) LogCheckBatch(ctx context.Context, checks []CheckHistoryEntry) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := time.Now()
+	for _, check := range checks {
+		check.ID = m.id()
+		check.CheckTime = now
+		m.checkHistory = append(m.checkHistory, check)
+	}
+	return nil
+}
+
+// GetCheckHistory implements Storage.GetCheckHistory.
+func (m *MemoryStorage) GetCheckHistory(ctx context.Context, containerName string, limit int) ([]CheckHistoryEntry, error) {
+	return m.QueryCheckHistory(ctx, CheckHistoryQueryOptions{Containers: []string{containerName}, Limit: limit})
+}
+
+// GetAllCheckHistory implements Storage.GetAllCheckHistory.
+func (m *MemoryStorage) GetAllCheckHistory(ctx context.Context, limit int) ([]CheckHistoryEntry, error) {
+	return m.QueryCheckHistory(ctx, CheckHistoryQueryOptions{Limit: limit})
+}
+
+// GetCheckHistorySince implements Storage.GetCheckHistorySince.
+func (m *MemoryStorage) GetCheckHistorySince(ctx context.Context, since time.Time) ([]CheckHistoryEntry, error) {
+	return m.QueryCheckHistory(ctx, CheckHistoryQueryOptions{DateFrom: &since})
+}
+
+// GetCheckHistoryByTimeRange implements Storage.GetCheckHistoryByTimeRange.
+func (m *MemoryStorage) GetCheckHistoryByTimeRange(ctx context.Context, start, end time.Time) ([]CheckHistoryEntry, error) {
+	return m.QueryCheckHistory(ctx, CheckHistoryQueryOptions{DateFrom: &start, DateTo: &end})
+}
+
+// QueryCheckHistory implements Storage.QueryCheckHistory.
+func (m *MemoryStorage) QueryCheckHistory(ctx context.Context, opts CheckHistoryQueryOptions) ([]CheckHistoryEntry, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var history []CheckHistoryEntry
+	for _, entry := range slices.Backward(m.checkHistory) {
+		switch {
+		case len(opts.Containers) > 0 && !slices.Contains(opts.Containers, entry.ContainerName),
+			opts.Status != "" && entry.Status != opts.Status,
+			opts.DateFrom != nil && entry.CheckTime.Before(*opts.DateFrom),
+			opts.DateTo != nil && entry.CheckTime.After(*opts.DateTo):
+			continue
+		}
+		history = append(history, entry)
+		if opts.Limit > 0 && len(history) == opts.Limit {
+			break
+		}
+	}
+	return history, nil
+}
+
+// LogUpdate implements Storage.LogUpdate.
+func (m *MemoryStorage) LogUpdate(ctx context.Context, containerName, operation, fromVer, toVer string, success bool, updateErr error) error {
+	switch operation {
+	case "pull", "restart", "rollback":
+	default:
+		return fmt.Errorf("invalid operation: %s (must be one of: pull, restart, rollback)", operation)
+	}
+
+	entry := UpdateLogEntry{
+		ContainerName: containerName,
+		Operation:     operation,
+		FromVersion:   fromVer,
+		ToVersion:     toVer,
+		Timestamp:     time.Now(),
+		Success:       success,
+	}
+	if updateErr != nil {
+		entry.Error = updateErr.Error()
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	entry.ID = m.id()
+	m.updateLog = append(m.updateLog, entry)
+	return nil
+}
+
+// GetUpdateLog implements Storage.GetUpdateLog.
+func (m *MemoryStorage) GetUpdateLog(ctx context.Context, containerName string, limit int) ([]UpdateLogEntry, error) {
+	return m.updateLogWhere(limit, func(entry UpdateLogEntry) bool { return entry.ContainerName == containerName }), nil
+}
+
+// GetAllUpdateLog implements Storage.GetAllUpdateLog.
+func (m *MemoryStorage) GetAllUpdateLog(ctx context.Context, limit int) ([]UpdateLogEntry, error) {
+	return m.updateLogWhere(limit, func(UpdateLogEntry) bool { return true }), nil
+}
+
+// updateLogWhere returns matching update log entries, most recent first
+func (m *MemoryStorage) updateLogWhere(limit int, match func(UpdateLogEntry) bool) []UpdateLogEntry {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	logs := make([]UpdateLogEntry, 0)
+	for _, entry := range slices.Backward(m.updateLog) {
+		if !match(entry) {
+			continue
+		}
+		logs = append(logs, entry)
+		if limit > 0 && len(logs) == limit {
+			break
+		}
+	}
+	return logs
+}
+
+// GetConfig implements Storage.GetConfig.
+func (m *MemoryStorage) GetConfig(ctx context.Context, key string) (string, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	value, ok := m.config[key]
+	return value, ok, nil
+}
+
+// SetConfig implements Storage.SetConfig.
+func (m *MemoryStorage) SetConfig(ctx context.Context, key, value string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.config[key] = value
+	return nil
+}
+
+// SaveConfigSnapshot implements Storage.SaveConfigSnapshot.
+func (m *MemoryStorage) SaveConfigSnapshot(ctx context.Context, snapshot ConfigSnapshot) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.saveConfigSnapshot(snapshot)
+	return nil
+}
+
+// saveConfigSnapshot appends a snapshot. The caller must hold m.mu.
+func (m *MemoryStorage) saveConfigSnapshot(snapshot ConfigSnapshot) {
+	snapshot.ID = m.id()
+	snapshot.ConfigData = maps.Clone(snapshot.ConfigData)
+	snapshot.CreatedAt = time.Now()
+	m.configHistory = append(m.configHistory, snapshot)
+}
+
+// GetConfigHistory implements Storage.GetConfigHistory.
+func (m *MemoryStorage) GetConfigHistory(ctx context.Context, limit int) ([]ConfigSnapshot, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	history := slices.Clone(m.configHistory)
+	slices.SortStableFunc(history, func(a, b ConfigSnapshot) int {
+		if c := b.SnapshotTime.Compare(a.SnapshotTime); c != 0 {
+			return c
+		}
+		return cmp.Compare(b.ID, a.ID)
+	})
+	if limit > 0 && len(history) > limit {
+		history = history[:limit]
+	}
+	for i := range history {
+		history[i].ConfigData = maps.Clone(history[i].ConfigData)
+	}
+	return history, nil
+}
+
+// GetConfigSnapshotByID implements Storage.GetConfigSnapshotByID.
+func (m *MemoryStorage) GetConfigSnapshotByID(ctx context.Context, snapshotID int64) (ConfigSnapshot, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, snapshot := range m.configHistory {
+		if snapshot.ID == snapshotID {
+			snapshot.ConfigData = maps.Clone(snapshot.ConfigData)
+			return snapshot, true, nil
+		}
+	}
+	return ConfigSnapshot{}, false, nil
+}
+
+// RevertToSnapshot implements Storage.RevertToSnapshot.
+func (m *MemoryStorage) RevertToSnapshot(ctx context.Context, snapshotID int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	index := slices.IndexFunc(m.configHistory, func(s ConfigSnapshot) bool { return s.ID == snapshotID })
+	if index < 0 {
+		return fmt.Errorf("snapshot %d not found", snapshotID)
+	}
+	snapshot := m.configHistory[index]
+
+	m.config = maps.Clone(snapshot.ConfigData)
+	if m.config == nil {
+		m.config = make(map[string]string)
+	}
+	m.saveConfigSnapshot(ConfigSnapshot{
+		SnapshotTime: time.Now(),
+		ConfigData:   snapshot.ConfigData,
+		ChangedBy:    fmt.Sprintf("revert-to-snapshot-%d", snapshotID),
+	})
+	return nil
+}
+
+// Backup implements Storage.Backup.
+func (m *MemoryStorage) Backup(ctx context.Context, destPath string) error {
+	return fmt.Errorf("backups are not supported with the memory driver")
+}
+
+// Vacuum implements Storage.Vacuum. There is nothing to reclaim.
+func (m *MemoryStorage) Vacuum(ctx context.Context) (VacuumResult, error) {
+	return VacuumResult{}, nil
+}
+
+// ListComposeBackups implements Storage.ListComposeBackups. Compose backups are
+// only recorded by the SQLite driver.
+func (m *MemoryStorage) ListComposeBackups(ctx context.Context) ([]ComposeBackup, error) {
+	return nil, nil
+}
+
+// DeleteComposeBackups implements Storage.DeleteComposeBackups.
+func (m *MemoryStorage) DeleteComposeBackups(ctx context.Context, ids []int64) (int64, error) {
+	return 0, nil
+}
+
+// CheckWritable implements Storage.CheckWritable. Memory is always writable.
+func (m *MemoryStorage) CheckWritable(ctx context.Context) error {
+	return nil
+}
+
+// PruneHistory implements Storage.PruneHistory.
+func (m *MemoryStorage) PruneHistory(ctx context.Context, opts PruneOptions) (PruneResult, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var result PruneResult
+	if !opts.CheckHistoryBefore.IsZero() {
+		before := len(m.checkHistory)
+		m.checkHistory = slices.DeleteFunc(m.checkHistory, func(e CheckHistoryEntry) bool {
+			return e.CheckTime.Before(opts.CheckHistoryBefore)
+		})
+		result.CheckHistory = int64(before - len(m.checkHistory))
+	}
+	if !opts.UpdateLogBefore.IsZero() {
+		before := len(m.updateLog)
+		m.updateLog = slices.DeleteFunc(m.updateLog, func(e UpdateLogEntry) bool {
+			return e.Timestamp.Before(opts.UpdateLogBefore)
+		})
+		result.UpdateLog = int64(before - len(m.updateLog))
+	}
+	return result, nil
+}
+
+// DeleteAllHistory implements Storage.DeleteAllHistory.
+func (m *MemoryStorage) DeleteAllHistory(ctx context.Context) (int64, error) {
+	return m.deleteHistory(nil), nil
+}
+
+// DeleteHistoryBefore implements Storage.DeleteHistoryBefore.
+func (m *MemoryStorage) DeleteHistoryBefore(ctx context.Context, before time.Time) (int64, error) {
+	return m.deleteHistory(&before), nil
+}
+
+// deleteHistory deletes finished operations, check history, and the update
+// log, optionally only entries older than before
+func (m *MemoryStorage) deleteHistory(before *time.Time) int64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	older := func(t time.Time) bool { return before == nil || t.Before(*before) }
+
+	var total int64
+	for id, op := range m.operations {
+		if op.Status != StatusComplete && op.Status != StatusFailed {
+			continue
+		}
+		if before == nil || (op.StartedAt != nil && older(*op.StartedAt)) {
+			delete(m.operations, id)
+			total++
+		}
+	}
+
+	// Step logs go with their operations
+	m.operationLogs = slices.DeleteFunc(m.operationLogs, func(e OperationLogEntry) bool {
+		_, found := m.operations[e.OperationID]
+		return !found
+	})
+
+	checks := len(m.checkHistory)
+	m.checkHistory = slices.DeleteFunc(m.checkHistory, func(e CheckHistoryEntry) bool { return older(e.CheckTime) })
+	logs := len(m.updateLog)
+	m.updateLog = slices.DeleteFunc(m.updateLog, func(e UpdateLogEntry) bool { return older(e.Timestamp) })
+
+	return total + int64(checks-len(m.checkHistory)) + int64(logs-len(m.updateLog))
+}
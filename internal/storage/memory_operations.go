@@ -0,0 +1,712 @@
+package storage
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+)
+
+// SaveUpdateOperation implements Storage.SaveUpdateOperation.
+func (m *MemoryStorage) SaveUpdateOperation(ctx context.Context, op UpdateOperation) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	if existing, ok := m.operations[op.OperationID]; ok {
+		op.ID = existing.ID
+		op.CreatedAt = existing.CreatedAt
+		op.ComposeOutput = existing.ComposeOutput
+		op.TriggeredBy = cmp.Or(op.TriggeredBy, existing.TriggeredBy)
+	} else {
+		op.ComposeOutput = ""
+		op.ID = m.id()
+		op.CreatedAt = now
+	}
+	op.UpdatedAt = now
+	m.operations[op.OperationID] = cloneOperation(op)
+	return nil
+}
+
+// GetUpdateOperation implements Storage.GetUpdateOperation.
+func (m *MemoryStorage) GetUpdateOperation(ctx context.Context, operationID string) (UpdateOperation, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	op, ok := m.operations[operationID]
+	if !ok {
+		return UpdateOperation{}, false, nil
+	}
+	return cloneOperation(op), true, nil
+}
+
+// GetUpdateOperations implements Storage.GetUpdateOperations.
+func (m *MemoryStorage) GetUpdateOperations(ctx context.Context, limit int) ([]UpdateOperation, error) {
+	return m.operationsWhere(byStartedDesc, limit, isFinished), nil
+}
+
+// GetUpdateOperationsByContainer implements Storage.GetUpdateOperationsByContainer.
+func (m *MemoryStorage) GetUpdateOperationsByContainer(ctx context.Context, containerName string, limit int) ([]UpdateOperation, error) {
+	return m.operationsWhere(byStartedDesc, limit, func(op UpdateOperation) bool {
+		return op.ContainerName == containerName
+	}), nil
+}
+
+// GetUpdateOperationsByTimeRange implements Storage.GetUpdateOperationsByTimeRange.
+func (m *MemoryStorage) GetUpdateOperationsByTimeRange(ctx context.Context, start, end time.Time) ([]UpdateOperation, error) {
+	return m.operationsWhere(byStartedDesc, 0, func(op UpdateOperation) bool {
+		return op.StartedAt != nil && !op.StartedAt.Before(start) && !op.StartedAt.After(end)
+	}), nil
+}
+
+// GetUpdateOperationsByStatus implements Storage.GetUpdateOperationsByStatus.
+func (m *MemoryStorage) GetUpdateOperationsByStatus(ctx context.Context, status string, limit int) ([]UpdateOperation, error) {
+	byCreatedDesc := func(a, b UpdateOperation) int {
+		return cmp.Or(b.CreatedAt.Compare(a.CreatedAt), cmp.Compare(b.ID, a.ID))
+	}
+	return m.operationsWhere(byCreatedDesc, limit, func(op UpdateOperation) bool {
+		return op.Status == status
+	}), nil
+}
+
+// GetUpdateOperationsByBatchGroup implements Storage.GetUpdateOperationsByBatchGroup.
+func (m *MemoryStorage) GetUpdateOperationsByBatchGroup(ctx context.Context, batchGroupID string) ([]UpdateOperation, error) {
+	byStartedAsc := func(a, b UpdateOperation) int { return byStartedDesc(b, a) }
+	return m.operationsWhere(byStartedAsc, 0, func(op UpdateOperation) bool {
+		return op.BatchGroupID == batchGroupID
+	}), nil
+}
+
+// GetUnfinishedOperations implements Storage.GetUnfinishedOperations.
+func (m *MemoryStorage) GetUnfinishedOperations(ctx context.Context) ([]UpdateOperation, error) {
+	byCreatedAsc := func(a, b UpdateOperation) int {
+		return cmp.Or(a.CreatedAt.Compare(b.CreatedAt), cmp.Compare(a.ID, b.ID))
+	}
+	return m.operationsWhere(byCreatedAsc, 0, func(op UpdateOperation) bool {
+		return !isFinished(op) && op.Status != "cancelled"
+	}), nil
+}
+
+// UpdateOperationStatus implements Storage.UpdateOperationStatus.
+func (m *MemoryStorage) UpdateOperationStatus(ctx context.Context, operationID string, status string, errorMsg string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	op, ok := m.operations[operationID]
+	if !ok {
+		return fmt.Errorf("operation %s not found", operationID)
+	}
+	op.Status = status
+	op.ErrorMessage = errorMsg
+	op.UpdatedAt = time.Now()
+	m.operations[operationID] = op
+	return nil
+}
+
+// AppendComposeOutput implements Storage.AppendComposeOutput.
+func (m *MemoryStorage) AppendComposeOutput(ctx context.Context, operationID string, output string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	op, ok := m.operations[operationID]
+	if !ok {
+		return nil
+	}
+	op.ComposeOutput += output
+	if len(op.ComposeOutput) > MaxComposeOutput {
+		op.ComposeOutput = op.ComposeOutput[len(op.ComposeOutput)-MaxComposeOutput:]
+	}
+	op.UpdatedAt = time.Now()
+	m.operations[operationID] = op
+	return nil
+}
+
+// QueryUpdateOperations implements Storage.QueryUpdateOperations.
+func (m *MemoryStorage) QueryUpdateOperations(ctx context.Context, opts OperationQueryOptions) (OperationQueryResult, error) {
+	var cursor *time.Time
+	if opts.Cursor != "" {
+		if t, err := time.Parse(time.RFC3339Nano, opts.Cursor); err == nil {
+			cursor = &t
+		}
+	}
+
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = 20
+	}
+
+	operations := m.operationsWhere(byStartedDesc, limit+1, func(op UpdateOperation) bool {
+		switch {
+		case opts.Status != "" && op.Status != opts.Status,
+			opts.Status == "" && !isFinished(op),
+			opts.Container != "" && op.ContainerName != opts.Container,
+			opts.Stack != "" && op.StackName != opts.Stack,
+			opts.Type == "updates" && !slices.Contains([]string{"single", "batch", "stack", "pin", "variant"}, op.OperationType),
+			opts.Type != "" && opts.Type != "updates" && op.OperationType != opts.Type:
+			return false
+		}
+		if opts.DateFrom != nil || opts.DateTo != nil || cursor != nil {
+			switch {
+			case op.StartedAt == nil,
+				opts.DateFrom != nil && op.StartedAt.Before(*opts.DateFrom),
+				opts.DateTo != nil && op.StartedAt.After(*opts.DateTo),
+				cursor != nil && !op.StartedAt.Before(*cursor):
+				return false
+			}
+		}
+		return true
+	})
+
+	result := OperationQueryResult{}
+	if len(operations) > limit {
+		result.HasMore = true
+		operations = operations[:limit]
+		if last := operations[limit-1]; last.StartedAt != nil {
+			result.NextCursor = last.StartedAt.Format(time.RFC3339Nano)
+		}
+	}
+	result.Operations = operations
+
+	return result, nil
+}
+
+// operationsWhere returns matching operations in the given order, at most
+// limit of them when limit > 0
+func (m *MemoryStorage) operationsWhere(order func(a, b UpdateOperation) int, limit int, match func(UpdateOperation) bool) []UpdateOperation {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	operations := make([]UpdateOperation, 0)
+	for _, op := range m.operations {
+		if match(op) {
+			operations = append(operations, cloneOperation(op))
+		}
+	}
+	slices.SortFunc(operations, order)
+	if limit > 0 && len(operations) > limit {
+		operations = operations[:limit]
+	}
+	return operations
+}
+
+// isFinished reports whether an operation completed or failed
+func isFinished(op UpdateOperation) bool {
+	return op.Status == StatusComplete || op.Status == StatusFailed
+}
+
+// byStartedDesc orders operations by start time, most recent first, with
+// operations that have not started last
+func byStartedDesc(a, b UpdateOperation) int {
+	switch {
+	case a.StartedAt == nil && b.StartedAt == nil:
+		return cmp.Compare(b.ID, a.ID)
+	case a.StartedAt == nil:
+		return 1
+	case b.StartedAt == nil:
+		return -1
+	}
+	return cmp.Or(b.StartedAt.Compare(*a.StartedAt), cmp.Compare(b.ID, a.ID))
+}
+
+// cloneOperation copies the slices of an operation so callers cannot modify stored state
+func cloneOperation(op UpdateOperation) UpdateOperation {
+	op.DependentsAffected = slices.Clone(op.DependentsAffected)
+	op.BatchDetails = slices.Clone(op.BatchDetails)
+	op.SignatureVerifications = slices.Clone(op.SignatureVerifications)
+	op.Observations = slices.Clone(op.Observations)
+	return op
+}
+
+// GetRollbackPolicy implements Storage.GetRollbackPolicy.
+func (m *MemoryStorage) GetRollbackPolicy(ctx context.Context, entityType, entityID string) (RollbackPolicy, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	policy, ok := m.rollbackPolicies[policyKey{entityType, entityID}]
+	return policy, ok, nil
+}
+
+// SetRollbackPolicy implements Storage.SetRollbackPolicy.
+func (m *MemoryStorage) SetRollbackPolicy(ctx context.Context, policy RollbackPolicy) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := policyKey{policy.EntityType, policy.EntityID}
+	now := time.Now()
+	if existing, ok := m.rollbackPolicies[key]; ok {
+		policy.ID = existing.ID
+		policy.CreatedAt = existing.CreatedAt
+	} else {
+		policy.ID = m.id()
+		policy.CreatedAt = now
+	}
+	policy.UpdatedAt = now
+	m.rollbackPolicies[key] = policy
+	return nil
+}
+
+// ListRollbackPolicies implements Storage.ListRollbackPolicies.
+func (m *MemoryStorage) ListRollbackPolicies(ctx context.Context) ([]RollbackPolicy, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	policies := slices.Collect(maps.Values(m.rollbackPolicies))
+	if policies == nil {
+		policies = []RollbackPolicy{}
+	}
+	slices.SortFunc(policies, func(a, b RollbackPolicy) int {
+		return cmp.Or(
+			cmp.Compare(boolRank(a.EntityType != "global"), boolRank(b.EntityType != "global")),
+			cmp.Compare(a.EntityType, b.EntityType),
+			cmp.Compare(a.EntityID, b.EntityID),
+		)
+	})
+	return policies, nil
+}
+
+// GetApprovalPolicy implements Storage.GetApprovalPolicy.
+func (m *MemoryStorage) GetApprovalPolicy(ctx context.Context, entityType, entityID string) (ApprovalPolicy, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	policy, ok := m.approvalPolicies[policyKey{entityType, entityID}]
+	return policy, ok, nil
+}
+
+// SetApprovalPolicy implements Storage.SetApprovalPolicy.
+func (m *MemoryStorage) SetApprovalPolicy(ctx context.Context, policy ApprovalPolicy) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := policyKey{policy.EntityType, policy.EntityID}
+	now := time.Now()
+	if existing, ok := m.approvalPolicies[key]; ok {
+		policy.ID = existing.ID
+		policy.CreatedAt = existing.CreatedAt
+	} else {
+		policy.ID = m.id()
+		policy.CreatedAt = now
+	}
+	policy.UpdatedAt = now
+	m.approvalPolicies[key] = policy
+	return nil
+}
+
+// ListApprovalPolicies implements Storage.ListApprovalPolicies.
+func (m *MemoryStorage) ListApprovalPolicies(ctx context.Context) ([]ApprovalPolicy, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	policies := slices.Collect(maps.Values(m.approvalPolicies))
+	if policies == nil {
+		policies = []ApprovalPolicy{}
+	}
+	slices.SortFunc(policies, func(a, b ApprovalPolicy) int {
+		return cmp.Or(
+			cmp.Compare(boolRank(a.EntityType != "global"), boolRank(b.EntityType != "global")),
+			cmp.Compare(a.EntityType, b.EntityType),
+			cmp.Compare(a.EntityID, b.EntityID),
+		)
+	})
+	return policies, nil
+}
+
+// DeleteApprovalPolicy implements Storage.DeleteApprovalPolicy.
+func (m *MemoryStorage) DeleteApprovalPolicy(ctx context.Context, entityType, entityID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.approvalPolicies, policyKey{entityType, entityID})
+	return nil
+}
+
+// ListIgnoreRules implements Storage.ListIgnoreRules.
+func (m *MemoryStorage) ListIgnoreRules(ctx context.Context) ([]IgnoreRule, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	rules := slices.Clone(m.ignoreRules)
+	if rules == nil {
+		rules = []IgnoreRule{}
+	}
+	slices.SortFunc(rules, func(a, b IgnoreRule) int { return cmp.Compare(a.Pattern, b.Pattern) })
+	return rules, nil
+}
+
+// SaveIgnoreRule implements Storage.SaveIgnoreRule.
+func (m *MemoryStorage) SaveIgnoreRule(ctx context.Context, rule IgnoreRule) (IgnoreRule, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i, existing := range m.ignoreRules {
+		if existing.Pattern == rule.Pattern {
+			m.ignoreRules[i].Reason = rule.Reason
+			return m.ignoreRules[i], nil
+		}
+	}
+	rule.ID = m.id()
+	rule.CreatedAt = time.Now()
+	m.ignoreRules = append(m.ignoreRules, rule)
+	return rule, nil
+}
+
+// DeleteIgnoreRule implements Storage.DeleteIgnoreRule.
+func (m *MemoryStorage) DeleteIgnoreRule(ctx context.Context, id int64) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	before := len(m.ignoreRules)
+	m.ignoreRules = slices.DeleteFunc(m.ignoreRules, func(rule IgnoreRule) bool { return rule.ID == id })
+	return len(m.ignoreRules) < before, nil
+}
+
+// GetStackEnv implements Storage.GetStackEnv.
+func (m *MemoryStorage) GetStackEnv(ctx context.Context, stack string) (StackEnv, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	env, ok := m.stackEnvs[stack]
+	env.Env = maps.Clone(env.Env)
+	return env, ok, nil
+}
+
+// SetStackEnv implements Storage.SetStackEnv.
+func (m *MemoryStorage) SetStackEnv(ctx context.Context, env StackEnv) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	env.Env = maps.Clone(env.Env)
+	env.UpdatedAt = time.Now()
+	m.stackEnvs[env.Stack] = env
+	return nil
+}
+
+// ListStackEnvs implements Storage.ListStackEnvs.
+func (m *MemoryStorage) ListStackEnvs(ctx context.Context) ([]StackEnv, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	envs := []StackEnv{}
+	for _, stack := range slices.Sorted(maps.Keys(m.stackEnvs)) {
+		env := m.stackEnvs[stack]
+		env.Env = maps.Clone(env.Env)
+		envs = append(envs, env)
+	}
+	return envs, nil
+}
+
+// DeleteStackEnv implements Storage.DeleteStackEnv.
+func (m *MemoryStorage) DeleteStackEnv(ctx context.Context, stack string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	_, ok := m.stackEnvs[stack]
+	delete(m.stackEnvs, stack)
+	return ok, nil
+}
+
+// boolRank sorts false before true
+func boolRank(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// QueueUpdate implements Storage.QueueUpdate.
+func (m *MemoryStorage) QueueUpdate(ctx context.Context, queue UpdateQueue) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, queued := range m.queue {
+		if queued.OperationID == queue.OperationID {
+			return fmt.Errorf("failed to queue update: operation %s is already queued", queue.OperationID)
+		}
+	}
+	queue.ID = m.id()
+	m.queue = append(m.queue, cloneQueue(queue))
+	return nil
+}
+
+// DequeueUpdate implements Storage.DequeueUpdate.
+func (m *MemoryStorage) DequeueUpdate(ctx context.Context, stackName string) (UpdateQueue, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	next := -1
+	for i, queue := range m.queue {
+		if queue.StackName == stackName && (next < 0 || queueOrder(queue, m.queue[next]) < 0) {
+			next = i
+		}
+	}
+	if next < 0 {
+		return UpdateQueue{}, false, nil
+	}
+
+	queue := m.queue[next]
+	m.queue = slices.Delete(m.queue, next, next+1)
+	return queue, true, nil
+}
+
+// GetQueuedUpdates implements Storage.GetQueuedUpdates.
+func (m *MemoryStorage) GetQueuedUpdates(ctx context.Context) ([]UpdateQueue, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var queues []UpdateQueue
+	for _, queue := range m.queue {
+		queues = append(queues, cloneQueue(queue))
+	}
+	slices.SortStableFunc(queues, queueOrder)
+	return queues, nil
+}
+
+// SetQueuePriorities implements Storage.SetQueuePriorities.
+func (m *MemoryStorage) SetQueuePriorities(ctx context.Context, priorities map[string]int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i := range m.queue {
+		if priority, ok := priorities[m.queue[i].OperationID]; ok {
+			m.queue[i].Priority = priority
+		}
+	}
+	return nil
+}
+
+// DeleteQueuedUpdate implements Storage.DeleteQueuedUpdate.
+func (m *MemoryStorage) DeleteQueuedUpdate(ctx context.Context, operationID string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	i := slices.IndexFunc(m.queue, func(queue UpdateQueue) bool { return queue.OperationID == operationID })
+	if i < 0 {
+		return false, nil
+	}
+	m.queue = slices.Delete(m.queue, i, i+1)
+	return true, nil
+}
+
+// SaveStackLock implements Storage.SaveStackLock.
+func (m *MemoryStorage) SaveStackLock(ctx context.Context, lock StackLock) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.stackLocks[lock.StackName] = lock
+	return nil
+}
+
+// DeleteStackLock implements Storage.DeleteStackLock.
+func (m *MemoryStorage) DeleteStackLock(ctx context.Context, stackName, operationID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if lock, ok := m.stackLocks[stackName]; ok && (operationID == "" || lock.OperationID == operationID) {
+		delete(m.stackLocks, stackName)
+	}
+	return nil
+}
+
+// ListStackLocks implements Storage.ListStackLocks.
+func (m *MemoryStorage) ListStackLocks(ctx context.Context) ([]StackLock, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	locks := slices.Collect(maps.Values(m.stackLocks))
+	if locks == nil {
+		locks = []StackLock{}
+	}
+	slices.SortFunc(locks, func(a, b StackLock) int { return cmp.Compare(a.StackName, b.StackName) })
+	return locks, nil
+}
+
+// queueOrder orders queue entries by priority, then FIFO
+func queueOrder(a, b UpdateQueue) int {
+	return cmp.Or(cmp.Compare(b.Priority, a.Priority), a.QueuedAt.Compare(b.QueuedAt))
+}
+
+// cloneQueue copies the containers and target versions of a queue entry
+func cloneQueue(queue UpdateQueue) UpdateQueue {
+	queue.Containers = slices.Clone(queue.Containers)
+	if len(queue.TargetVersions) == 0 {
+		queue.TargetVersions = nil
+	} else {
+		queue.TargetVersions = maps.Clone(queue.TargetVersions)
+	}
+	return queue
+}
+
+// SaveScriptAssignment implements Storage.SaveScriptAssignment.
+func (m *MemoryStorage) SaveScriptAssignment(ctx context.Context, assignment ScriptAssignment) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	if existing, ok := m.scripts[assignment.ContainerName]; ok {
+		assignment.ID = existing.ID
+		assignment.AssignedAt = existing.AssignedAt
+	} else {
+		assignment.ID = m.id()
+		assignment.AssignedAt = now
+	}
+	assignment.UpdatedAt = now
+	m.scripts[assignment.ContainerName] = assignment
+	return nil
+}
+
+// GetScriptAssignment implements Storage.GetScriptAssignment.
+func (m *MemoryStorage) GetScriptAssignment(ctx context.Context, containerName string) (ScriptAssignment, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	assignment, ok := m.scripts[containerName]
+	return assignment, ok, nil
+}
+
+// ListScriptAssignments implements Storage.ListScriptAssignments.
+func (m *MemoryStorage) ListScriptAssignments(ctx context.Context, enabledOnly bool) ([]ScriptAssignment, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	assignments := make([]ScriptAssignment, 0, len(m.scripts))
+	for _, assignment := range m.scripts {
+		if !enabledOnly || assignment.Enabled {
+			assignments = append(assignments, assignment)
+		}
+	}
+	slices.SortFunc(assignments, func(a, b ScriptAssignment) int {
+		return cmp.Compare(a.ContainerName, b.ContainerName)
+	})
+	return assignments, nil
+}
+
+// DeleteScriptAssignment implements Storage.DeleteScriptAssignment.
+func (m *MemoryStorage) DeleteScriptAssignment(ctx context.Context, containerName string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.scripts[containerName]; !ok {
+		return fmt.Errorf("no script assignment found for container %s", containerName)
+	}
+	delete(m.scripts, containerName)
+	return nil
+}
+
+// SaveScriptRevision implements Storage.SaveScriptRevision.
+func (m *MemoryStorage) SaveScriptRevision(ctx context.Context, revision ScriptRevision) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, existing := range m.scriptRevisions {
+		if existing.Name == revision.Name && existing.Revision == revision.Revision {
+			return fmt.Errorf("failed to save script revision: %s@%d already exists", revision.Name, revision.Revision)
+		}
+	}
+	revision.ID = m.id()
+	revision.CreatedAt = time.Now()
+	m.scriptRevisions = append(m.scriptRevisions, revision)
+	return nil
+}
+
+// GetScriptRevision implements Storage.GetScriptRevision.
+func (m *MemoryStorage) GetScriptRevision(ctx context.Context, name string, revision int) (ScriptRevision, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var found *ScriptRevision
+	for i, rev := range m.scriptRevisions {
+		if rev.Name != name || (revision != 0 && rev.Revision != revision) {
+			continue
+		}
+		if found == nil || rev.Revision > found.Revision {
+			found = &m.scriptRevisions[i]
+		}
+	}
+	if found == nil {
+		return ScriptRevision{}, false, nil
+	}
+	return *found, true, nil
+}
+
+// ListScriptRevisions implements Storage.ListScriptRevisions.
+func (m *MemoryStorage) ListScriptRevisions(ctx context.Context, name string) ([]ScriptRevision, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	revisions := make([]ScriptRevision, 0)
+	for _, rev := range m.scriptRevisions {
+		if name == "" || rev.Name == name {
+			revisions = append(revisions, rev)
+		}
+	}
+	slices.SortFunc(revisions, func(a, b ScriptRevision) int {
+		return cmp.Or(cmp.Compare(a.Name, b.Name), cmp.Compare(b.Revision, a.Revision))
+	})
+	return revisions, nil
+}
+
+// SaveVolumeSnapshot implements Storage.SaveVolumeSnapshot.
+func (m *MemoryStorage) SaveVolumeSnapshot(ctx context.Context, snapshot VolumeSnapshot) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	snapshot.ID = m.id()
+	if snapshot.CreatedAt.IsZero() {
+		snapshot.CreatedAt = time.Now()
+	}
+	m.volumeSnapshots = append(m.volumeSnapshots, snapshot)
+	return nil
+}
+
+// GetVolumeSnapshots implements Storage.GetVolumeSnapshots.
+func (m *MemoryStorage) GetVolumeSnapshots(ctx context.Context, operationID string) ([]VolumeSnapshot, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	snapshots := make([]VolumeSnapshot, 0)
+	for _, snapshot := range m.volumeSnapshots {
+		if snapshot.OperationID == operationID {
+			snapshots = append(snapshots, snapshot)
+		}
+	}
+	return snapshots, nil
+}
+
+// ListVolumeSnapshots implements Storage.ListVolumeSnapshots.
+func (m *MemoryStorage) ListVolumeSnapshots(ctx context.Context, volumeName string) ([]VolumeSnapshot, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	snapshots := make([]VolumeSnapshot, 0)
+	for _, snapshot := range m.volumeSnapshots {
+		if volumeName == "" || snapshot.VolumeName == volumeName {
+			snapshots = append(snapshots, snapshot)
+		}
+	}
+	slices.SortFunc(snapshots, func(a, b VolumeSnapshot) int {
+		return cmp.Or(b.CreatedAt.Compare(a.CreatedAt), cmp.Compare(b.ID, a.ID))
+	})
+	return snapshots, nil
+}
+
+// DeleteVolumeSnapshot implements Storage.DeleteVolumeSnapshot.
+func (m *MemoryStorage) DeleteVolumeSnapshot(ctx context.Context, id int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.volumeSnapshots = slices.DeleteFunc(m.volumeSnapshots, func(snapshot VolumeSnapshot) bool {
+		return snapshot.ID == id
+	})
+	return nil
+}
+
+// AppendOperationLog implements Storage.AppendOperationLog.
+func (m *MemoryStorage) AppendOperationLog(ctx context.Context, entry OperationLogEntry) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	entry.ID = m.id()
+	if entry.CreatedAt.IsZero() {
+		entry.CreatedAt = time.Now()
+	}
+	m.operationLogs = append(m.operationLogs, entry)
+	return entry.ID, nil
+}
+
+// GetOperationLogs implements Storage.GetOperationLogs.
+func (m *MemoryStorage) GetOperationLogs(ctx context.Context, operationID string, afterID int64) ([]OperationLogEntry, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entries := make([]OperationLogEntry, 0)
+	for _, entry := range m.operationLogs {
+		if entry.OperationID == operationID && entry.ID > afterID {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
@@ -0,0 +1,174 @@
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMemoryStorageConfigAndSnapshots(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStorage()
+
+	if err := store.SetConfig(ctx, "check_interval", "5m"); err != nil {
+		t.Fatalf("SetConfig failed: %v", err)
+	}
+	if err := store.SaveConfigSnapshot(ctx, ConfigSnapshot{
+		SnapshotTime: time.Now(),
+		ConfigData:   map[string]string{"check_interval": "5m"},
+		ChangedBy:    "test",
+	}); err != nil {
+		t.Fatalf("SaveConfigSnapshot failed: %v", err)
+	}
+	store.SetConfig(ctx, "check_interval", "1h")
+
+	history, err := store.GetConfigHistory(ctx, 10)
+	if err != nil || len(history) != 1 {
+		t.Fatalf("Expected 1 snapshot, got %d (%v)", len(history), err)
+	}
+	if err := store.RevertToSnapshot(ctx, history[0].ID); err != nil {
+		t.Fatalf("RevertToSnapshot failed: %v", err)
+	}
+
+	value, found, _ := store.GetConfig(ctx, "check_interval")
+	if !found || value != "5m" {
+		t.Errorf("Expected reverted value 5m, got %q (found=%v)", value, found)
+	}
+	if history, _ := store.GetConfigHistory(ctx, 10); len(history) != 2 {
+		t.Errorf("Expected revert to record a snapshot, got %d snapshots", len(history))
+	}
+	if err := store.RevertToSnapshot(ctx, 999); err == nil {
+		t.Error("Expected error reverting to unknown snapshot")
+	}
+}
+
+func TestMemoryStorageCheckHistory(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStorage()
+
+	store.LogCheck(ctx, "nginx", "nginx:1.25", "1.25", "1.25", CheckStatusUpToDate, nil)
+	store.LogCheckBatch(ctx, []CheckHistoryEntry{
+		{ContainerName: "redis", Image: "redis:7", Status: CheckStatusUpdateAvailable},
+		{ContainerName: "nginx", Image: "nginx:1.25", Status: CheckStatusUpdateAvailable},
+	})
+
+	history, err := store.GetCheckHistory(ctx, "nginx", 10)
+	if err != nil {
+		t.Fatalf("GetCheckHistory failed: %v", err)
+	}
+	if len(history) != 2 || history[0].Status != CheckStatusUpdateAvailable {
+		t.Errorf("Expected 2 nginx entries, newest first, got %+v", history)
+	}
+
+	all, _ := store.GetAllCheckHistory(ctx, 2)
+	if len(all) != 2 {
+		t.Errorf("Expected limit of 2, got %d", len(all))
+	}
+
+	result, err := store.PruneHistory(ctx, PruneOptions{CheckHistoryBefore: time.Now().Add(time.Minute)})
+	if err != nil || result.CheckHistory != 3 {
+		t.Errorf("Expected 3 pruned checks, got %+v (%v)", result, err)
+	}
+}
+
+func TestMemoryStorageUpdateOperations(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStorage()
+
+	base := time.Now().Add(-time.Hour)
+	for i, id := range []string{"op-1", "op-2", "op-3"} {
+		started := base.Add(time.Duration(i) * time.Minute)
+		store.SaveUpdateOperation(ctx, UpdateOperation{
+			OperationID:   id,
+			ContainerName: "nginx",
+			OperationType: "single",
+			Status:        StatusComplete,
+			StartedAt:     &started,
+		})
+	}
+
+	first, _, _ := store.GetUpdateOperation(ctx, "op-1")
+	if err := store.UpdateOperationStatus(ctx, "op-1", StatusFailed, "boom"); err != nil {
+		t.Fatalf("UpdateOperationStatus failed: %v", err)
+	}
+	updated, found, _ := store.GetUpdateOperation(ctx, "op-1")
+	if !found || updated.Status != StatusFailed || updated.ID != first.ID || !updated.CreatedAt.Equal(first.CreatedAt) {
+		t.Errorf("Expected status update to keep identity, got %+v", updated)
+	}
+
+	page, err := store.QueryUpdateOperations(ctx, OperationQueryOptions{Limit: 2})
+	if err != nil {
+		t.Fatalf("QueryUpdateOperations failed: %v", err)
+	}
+	if len(page.Operations) != 2 || !page.HasMore || page.Operations[0].OperationID != "op-3" {
+		t.Fatalf("Unexpected first page: %+v", page)
+	}
+	next, _ := store.QueryUpdateOperations(ctx, OperationQueryOptions{Limit: 2, Cursor: page.NextCursor})
+	if len(next.Operations) != 1 || next.HasMore || next.Operations[0].OperationID != "op-1" {
+		t.Errorf("Unexpected second page: %+v", next)
+	}
+
+	if err := store.UpdateOperationStatus(ctx, "missing", StatusFailed, ""); err == nil {
+		t.Error("Expected error for unknown operation")
+	}
+}
+
+func TestMemoryStorageQueue(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStorage()
+
+	now := time.Now()
+	store.QueueUpdate(ctx, UpdateQueue{OperationID: "low", StackName: "media", QueuedAt: now})
+	store.QueueUpdate(ctx, UpdateQueue{OperationID: "high", StackName: "media", Priority: 5, QueuedAt: now.Add(time.Second)})
+	if err := store.QueueUpdate(ctx, UpdateQueue{OperationID: "low", StackName: "media"}); err == nil {
+		t.Error("Expected error queueing a duplicate operation")
+	}
+
+	for _, want := range []string{"high", "low"} {
+		queue, found, err := store.DequeueUpdate(ctx, "media")
+		if err != nil || !found || queue.OperationID != want {
+			t.Fatalf("Expected to dequeue %s, got %+v (found=%v, err=%v)", want, queue, found, err)
+		}
+	}
+	if _, found, _ := store.DequeueUpdate(ctx, "media"); found {
+		t.Error("Expected empty queue")
+	}
+}
+
+func TestMemoryStorageUsersAndApprovals(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStorage()
+
+	user, err := store.CreateUser(ctx, User{Username: "alice", Role: "admin"})
+	if err != nil {
+		t.Fatalf("CreateUser failed: %v", err)
+	}
+	if _, err := store.CreateUser(ctx, User{Username: "alice", Role: "viewer"}); err == nil {
+		t.Error("Expected error creating duplicate user")
+	}
+
+	store.SaveSession(ctx, Session{TokenHash: "token", UserID: user.ID, ExpiresAt: time.Now().Add(time.Hour)})
+	if err := store.DeleteUser(ctx, "alice"); err != nil {
+		t.Fatalf("DeleteUser failed: %v", err)
+	}
+	if _, found, _ := store.GetSession(ctx, "token"); found {
+		t.Error("Expected sessions to be deleted with their user")
+	}
+
+	now := time.Now()
+	store.SaveApproval(ctx, Approval{ID: "a1", ContainerName: "nginx", TargetVersion: "1.26", Status: ApprovalPending, RequestedAt: now, ExpiresAt: now.Add(-time.Minute)})
+	store.SaveApproval(ctx, Approval{ID: "a2", ContainerName: "nginx", TargetVersion: "1.27", Status: ApprovalPending, RequestedAt: now.Add(time.Second), ExpiresAt: now.Add(time.Hour)})
+
+	expired, _ := store.ExpireApprovals(ctx, now)
+	if expired != 1 {
+		t.Errorf("Expected 1 expired approval, got %d", expired)
+	}
+	latest, found, _ := store.GetLatestApproval(ctx, "nginx")
+	if !found || latest.ID != "a2" {
+		t.Errorf("Expected latest approval a2, got %+v", latest)
+	}
+	pending, _ := store.ListApprovals(ctx, ApprovalPending, 0)
+	if len(pending) != 1 {
+		t.Errorf("Expected 1 pending approval, got %d", len(pending))
+	}
+}
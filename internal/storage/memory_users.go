@@ -0,0 +1,377 @@
+package storage
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// CreateUser implements Storage.CreateUser.
+func (m *MemoryStorage) CreateUser(ctx context.Context, user User) (User, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, existing := range m.users {
+		if existing.Username == user.Username {
+			return User{}, fmt.Errorf("failed to create user: username %s is already taken", user.Username)
+		}
+	}
+
+	user.ID = m.id()
+	user.CreatedAt = time.Now()
+	user.UpdatedAt = user.CreatedAt
+	m.users[user.ID] = user
+	return user, nil
+}
+
+// GetUser implements Storage.GetUser.
+func (m *MemoryStorage) GetUser(ctx context.Context, username string) (User, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	user, ok := m.userByName(username)
+	return user, ok, nil
+}
+
+// GetUserByID implements Storage.GetUserByID.
+func (m *MemoryStorage) GetUserByID(ctx context.Context, id int64) (User, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	user, ok := m.users[id]
+	return user, ok, nil
+}
+
+// ListUsers implements Storage.ListUsers.
+func (m *MemoryStorage) ListUsers(ctx context.Context) ([]User, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	users := make([]User, 0, len(m.users))
+	for _, user := range m.users {
+		users = append(users, user)
+	}
+	slices.SortFunc(users, func(a, b User) int { return cmp.Compare(a.Username, b.Username) })
+	return users, nil
+}
+
+// UpdateUser implements Storage.UpdateUser.
+func (m *MemoryStorage) UpdateUser(ctx context.Context, user User) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	existing, ok := m.userByName(user.Username)
+	if !ok {
+		return fmt.Errorf("user %s not found", user.Username)
+	}
+	existing.Role = user.Role
+	existing.PasswordHash = user.PasswordHash
+	existing.UpdatedAt = time.Now()
+	m.users[existing.ID] = existing
+	return nil
+}
+
+// DeleteUser implements Storage.DeleteUser.
+func (m *MemoryStorage) DeleteUser(ctx context.Context, username string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	user, ok := m.userByName(username)
+	if !ok {
+		return fmt.Errorf("user %s not found", username)
+	}
+	delete(m.users, user.ID)
+	for hash, session := range m.sessions {
+		if session.UserID == user.ID {
+			delete(m.sessions, hash)
+		}
+	}
+	m.teamMembers = slices.DeleteFunc(m.teamMembers, func(member TeamMember) bool {
+		return member.Username == username
+	})
+	m.ownerships = slices.DeleteFunc(m.ownerships, func(ownership Ownership) bool {
+		return ownership.Owner == "user:"+username
+	})
+	return nil
+}
+
+// userByName finds a user by username. The caller must hold m.mu.
+func (m *MemoryStorage) userByName(username string) (User, bool) {
+	for _, user := range m.users {
+		if user.Username == username {
+			return user, true
+		}
+	}
+	return User{}, false
+}
+
+// SaveSession implements Storage.SaveSession.
+func (m *MemoryStorage) SaveSession(ctx context.Context, session Session) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	session.CreatedAt = time.Now()
+	m.sessions[session.TokenHash] = session
+	return nil
+}
+
+// GetSession implements Storage.GetSession.
+// Returns false for unknown or expired sessions.
+func (m *MemoryStorage) GetSession(ctx context.Context, tokenHash string) (Session, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	session, ok := m.sessions[tokenHash]
+	if !ok || time.Now().After(session.ExpiresAt) {
+		return Session{}, false, nil
+	}
+	return session, true, nil
+}
+
+// DeleteSession implements Storage.DeleteSession.
+func (m *MemoryStorage) DeleteSession(ctx context.Context, tokenHash string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.sessions, tokenHash)
+	return nil
+}
+
+// DeleteExpiredSessions implements Storage.DeleteExpiredSessions.
+func (m *MemoryStorage) DeleteExpiredSessions(ctx context.Context, now time.Time) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var deleted int64
+	for hash, session := range m.sessions {
+		if session.ExpiresAt.Before(now) {
+			delete(m.sessions, hash)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+// ListOwnerships implements Storage.ListOwnerships.
+func (m *MemoryStorage) ListOwnerships(ctx context.Context) ([]Ownership, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	ownerships := append(make([]Ownership, 0, len(m.ownerships)), m.ownerships...)
+	slices.SortFunc(ownerships, func(a, b Ownership) int {
+		return cmp.Or(
+			cmp.Compare(a.EntityType, b.EntityType),
+			cmp.Compare(a.EntityID, b.EntityID),
+			cmp.Compare(a.Owner, b.Owner),
+		)
+	})
+	return ownerships, nil
+}
+
+// AddOwnership implements Storage.AddOwnership.
+func (m *MemoryStorage) AddOwnership(ctx context.Context, ownership Ownership) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, existing := range m.ownerships {
+		if existing.EntityType == ownership.EntityType && existing.EntityID == ownership.EntityID && existing.Owner == ownership.Owner {
+			return nil
+		}
+	}
+	ownership.CreatedAt = time.Now()
+	m.ownerships = append(m.ownerships, ownership)
+	return nil
+}
+
+// RemoveOwnership implements Storage.RemoveOwnership.
+func (m *MemoryStorage) RemoveOwnership(ctx context.Context, entityType, entityID, owner string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	before := len(m.ownerships)
+	m.ownerships = slices.DeleteFunc(m.ownerships, func(o Ownership) bool {
+		return o.EntityType == entityType && o.EntityID == entityID && o.Owner == owner
+	})
+	return len(m.ownerships) < before, nil
+}
+
+// ListTeamMembers implements Storage.ListTeamMembers.
+func (m *MemoryStorage) ListTeamMembers(ctx context.Context) ([]TeamMember, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	members := append(make([]TeamMember, 0, len(m.teamMembers)), m.teamMembers...)
+	slices.SortFunc(members, func(a, b TeamMember) int {
+		return cmp.Or(cmp.Compare(a.Team, b.Team), cmp.Compare(a.Username, b.Username))
+	})
+	return members, nil
+}
+
+// AddTeamMember implements Storage.AddTeamMember.
+func (m *MemoryStorage) AddTeamMember(ctx context.Context, team, username string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, member := range m.teamMembers {
+		if member.Team == team && member.Username == username {
+			return nil
+		}
+	}
+	m.teamMembers = append(m.teamMembers, TeamMember{Team: team, Username: username, CreatedAt: time.Now()})
+	return nil
+}
+
+// RemoveTeamMember implements Storage.RemoveTeamMember.
+func (m *MemoryStorage) RemoveTeamMember(ctx context.Context, team, username string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	before := len(m.teamMembers)
+	m.teamMembers = slices.DeleteFunc(m.teamMembers, func(member TeamMember) bool {
+		return member.Team == team && member.Username == username
+	})
+	return len(m.teamMembers) < before, nil
+}
+
+// SaveApproval implements Storage.SaveApproval.
+func (m *MemoryStorage) SaveApproval(ctx context.Context, approval Approval) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if existing, ok := m.approvals[approval.ID]; ok {
+		// Like the SQL backends, only the decision fields of an existing approval change
+		existing.Status = approval.Status
+		existing.TargetVersion = approval.TargetVersion
+		existing.ExpiresAt = approval.ExpiresAt
+		existing.DecidedBy = approval.DecidedBy
+		existing.DecidedAt = approval.DecidedAt
+		existing.OperationID = approval.OperationID
+		approval = existing
+	}
+	m.approvals[approval.ID] = approval
+	return nil
+}
+
+// GetApproval implements Storage.GetApproval.
+func (m *MemoryStorage) GetApproval(ctx context.Context, id string) (Approval, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	approval, ok := m.approvals[id]
+	return approval, ok, nil
+}
+
+// GetLatestApproval implements Storage.GetLatestApproval.
+func (m *MemoryStorage) GetLatestApproval(ctx context.Context, containerName string) (Approval, bool, error) {
+	approvals := m.approvalsWhere(1, func(a Approval) bool { return a.ContainerName == containerName })
+	if len(approvals) == 0 {
+		return Approval{}, false, nil
+	}
+	return approvals[0], true, nil
+}
+
+// ListApprovals implements Storage.ListApprovals.
+func (m *MemoryStorage) ListApprovals(ctx context.Context, status string, limit int) ([]Approval, error) {
+	if limit <= 0 {
+		limit = 100
+	}
+	return m.approvalsWhere(limit, func(a Approval) bool { return status == "" || a.Status == status }), nil
+}
+
+// ExpireApprovals implements Storage.ExpireApprovals.
+func (m *MemoryStorage) ExpireApprovals(ctx context.Context, now time.Time) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var expired int64
+	for id, approval := range m.approvals {
+		if approval.Status == ApprovalPending && approval.ExpiresAt.Before(now) {
+			approval.Status = ApprovalExpired
+			m.approvals[id] = approval
+			expired++
+		}
+	}
+	return expired, nil
+}
+
+// approvalsWhere returns matching approvals newest first
+func (m *MemoryStorage) approvalsWhere(limit int, match func(Approval) bool) []Approval {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	approvals := make([]Approval, 0)
+	for _, approval := range m.approvals {
+		if match(approval) {
+			approvals = append(approvals, approval)
+		}
+	}
+	slices.SortFunc(approvals, func(a, b Approval) int {
+		return cmp.Or(b.RequestedAt.Compare(a.RequestedAt), cmp.Compare(b.ID, a.ID))
+	})
+	if len(approvals) > limit {
+		approvals = approvals[:limit]
+	}
+	return approvals
+}
+
+// SaveProposal implements Storage.SaveProposal.
+func (m *MemoryStorage) SaveProposal(ctx context.Context, proposal Proposal) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	if existing, ok := m.proposals[proposal.ID]; ok {
+		// Like the SQL backends, only the publishing fields of an existing proposal change
+		existing.PatchFile = proposal.PatchFile
+		existing.Branch = proposal.Branch
+		existing.PullRequestURL = proposal.PullRequestURL
+		existing.Status = proposal.Status
+		proposal = existing
+	} else if proposal.CreatedAt.IsZero() {
+		proposal.CreatedAt = now
+	}
+	proposal.UpdatedAt = now
+	m.proposals[proposal.ID] = proposal
+	return nil
+}
+
+// GetProposal implements Storage.GetProposal.
+func (m *MemoryStorage) GetProposal(ctx context.Context, id string) (Proposal, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	proposal, ok := m.proposals[id]
+	return proposal, ok, nil
+}
+
+// GetLatestProposal implements Storage.GetLatestProposal.
+func (m *MemoryStorage) GetLatestProposal(ctx context.Context, containerName string) (Proposal, bool, error) {
+	proposals := m.proposalsWhere(1, func(p Proposal) bool { return p.ContainerName == containerName })
+	if len(proposals) == 0 {
+		return Proposal{}, false, nil
+	}
+	return proposals[0], true, nil
+}
+
+// ListProposals implements Storage.ListProposals.
+func (m *MemoryStorage) ListProposals(ctx context.Context, status string, limit int) ([]Proposal, error) {
+	if limit <= 0 {
+		limit = 100
+	}
+	return m.proposalsWhere(limit, func(p Proposal) bool { return status == "" || p.Status == status }), nil
+}
+
+// proposalsWhere returns matching proposals newest first
+func (m *MemoryStorage) proposalsWhere(limit int, match func(Proposal) bool) []Proposal {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	proposals := make([]Proposal, 0)
+	for _, proposal := range m.proposals {
+		if match(proposal) {
+			proposals = append(proposals, proposal)
+		}
+	}
+	slices.SortFunc(proposals, func(a, b Proposal) int {
+		return cmp.Or(b.CreatedAt.Compare(a.CreatedAt), cmp.Compare(b.ID, a.ID))
+	})
+	if len(proposals) > limit {
+		proposals = proposals[:limit]
+	}
+	return proposals
+}
@@ -0,0 +1,4 @@
+-- Rollback users and sessions tables
+DROP INDEX IF EXISTS idx_sessions_expires_at;
+DROP TABLE IF EXISTS sessions;
+DROP TABLE IF EXISTS users;
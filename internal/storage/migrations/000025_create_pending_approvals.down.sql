@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_pending_approvals_status;
+DROP INDEX IF EXISTS idx_pending_approvals_container;
+DROP TABLE IF EXISTS pending_approvals;
@@ -0,0 +1,17 @@
+-- Updates awaiting operator approval (containers with the approval-required policy)
+CREATE TABLE IF NOT EXISTS pending_approvals (
+    id TEXT PRIMARY KEY,
+    container_name TEXT NOT NULL,
+    stack_name TEXT NOT NULL DEFAULT '',
+    current_version TEXT NOT NULL DEFAULT '',
+    target_version TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending' CHECK(status IN ('pending', 'approved', 'rejected', 'expired', 'superseded')),
+    requested_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at DATETIME NOT NULL,
+    decided_by TEXT NOT NULL DEFAULT '',
+    decided_at DATETIME,
+    operation_id TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX IF NOT EXISTS idx_pending_approvals_container ON pending_approvals(container_name, requested_at);
+CREATE INDEX IF NOT EXISTS idx_pending_approvals_status ON pending_approvals(status);
@@ -0,0 +1,3 @@
+-- Add all_or_nothing column to update_queue table
+-- Preserves transactional batch mode for operations waiting on a stack lock
+ALTER TABLE update_queue ADD COLUMN all_or_nothing INTEGER NOT NULL DEFAULT 0;
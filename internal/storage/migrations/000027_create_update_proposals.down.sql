@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_update_proposals_status;
+DROP INDEX IF EXISTS idx_update_proposals_container;
+DROP TABLE IF EXISTS update_proposals;
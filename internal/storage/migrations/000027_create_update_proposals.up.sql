@@ -0,0 +1,19 @@
+-- Compose file change proposals emitted instead of applying updates (propose-only mode)
+CREATE TABLE IF NOT EXISTS update_proposals (
+    id TEXT PRIMARY KEY,
+    container_name TEXT NOT NULL,
+    stack_name TEXT NOT NULL DEFAULT '',
+    current_version TEXT NOT NULL DEFAULT '',
+    target_version TEXT NOT NULL,
+    compose_file TEXT NOT NULL,
+    patch TEXT NOT NULL,
+    patch_file TEXT NOT NULL DEFAULT '',
+    branch TEXT NOT NULL DEFAULT '',
+    pull_request_url TEXT NOT NULL DEFAULT '',
+    status TEXT NOT NULL DEFAULT 'open' CHECK(status IN ('open', 'applied', 'superseded')),
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_update_proposals_container ON update_proposals(container_name, created_at);
+CREATE INDEX IF NOT EXISTS idx_update_proposals_status ON update_proposals(status);
@@ -0,0 +1,5 @@
+-- Remove image size columns from check_history
+-- SQLite doesn't support DROP COLUMN directly in older versions,
+-- but the columns have defaults and are ignored by older code
+-- ALTER TABLE check_history DROP COLUMN latest_size;
+-- ALTER TABLE check_history DROP COLUMN size_delta;
@@ -0,0 +1,4 @@
+-- Add image size columns to check_history table
+-- Records how much a detected update would download (compressed bytes)
+ALTER TABLE check_history ADD COLUMN latest_size INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE check_history ADD COLUMN size_delta INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,3 @@
+-- Rollback migration for registry_tag_cache table
+
+DROP TABLE IF EXISTS registry_tag_cache;
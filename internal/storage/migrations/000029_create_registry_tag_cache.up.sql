@@ -0,0 +1,11 @@
+-- Create registry_tag_cache table for persisting registry tag lists
+-- Lets tag lists survive restarts and be revalidated with conditional requests
+-- (ETag / Last-Modified) instead of refetched on every check
+
+CREATE TABLE IF NOT EXISTS registry_tag_cache (
+    image_ref TEXT PRIMARY KEY,
+    tags TEXT NOT NULL,              -- JSON array of tag names
+    etag TEXT NOT NULL DEFAULT '',
+    last_modified TEXT NOT NULL DEFAULT '',
+    fetched_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,2 @@
+-- Store post-update check script output on update operations
+ALTER TABLE update_operations ADD COLUMN check_output TEXT;
@@ -0,0 +1,15 @@
+-- Revisions of scripts managed through the API. The content is stored on disk.
+CREATE TABLE IF NOT EXISTS script_revisions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL,
+    revision INTEGER NOT NULL,
+    sha256 TEXT NOT NULL,
+    size INTEGER NOT NULL DEFAULT 0,
+    timeout_seconds INTEGER NOT NULL DEFAULT 0,
+    no_network BOOLEAN NOT NULL DEFAULT 0,
+    memory_limit_mb INTEGER NOT NULL DEFAULT 0,
+    cpu_seconds INTEGER NOT NULL DEFAULT 0,
+    created_by TEXT NOT NULL DEFAULT '',
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(name, revision)
+);
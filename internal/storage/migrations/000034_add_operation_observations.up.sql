@@ -0,0 +1,2 @@
+-- Store post-update restart monitoring results on update operations
+ALTER TABLE update_operations ADD COLUMN observations TEXT;
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_volume_snapshots_volume;
+DROP INDEX IF EXISTS idx_volume_snapshots_operation;
+DROP TABLE IF EXISTS volume_snapshots;
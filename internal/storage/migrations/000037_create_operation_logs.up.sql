@@ -0,0 +1,11 @@
+-- Step log of update operations: compose output, pull layers, health checks, scripts.
+CREATE TABLE IF NOT EXISTS operation_logs (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    operation_id TEXT NOT NULL,
+    container_name TEXT NOT NULL DEFAULT '',
+    source TEXT NOT NULL,
+    message TEXT NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_operation_logs_operation ON operation_logs(operation_id, id);
@@ -0,0 +1,14 @@
+-- Create check_fingerprints table for differential checks
+-- Records the registry digest of each container's tag and a hash of its
+-- repository's tag list when it was last checked, with the result, so
+-- containers whose images have not changed reuse their last result
+
+CREATE TABLE IF NOT EXISTS check_fingerprints (
+    container_name TEXT PRIMARY KEY,
+    image TEXT NOT NULL,
+    local_hash TEXT NOT NULL,
+    head_digest TEXT NOT NULL,
+    tags_hash TEXT NOT NULL,
+    result TEXT NOT NULL,
+    checked_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
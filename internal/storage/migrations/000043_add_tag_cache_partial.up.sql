@@ -0,0 +1,2 @@
+-- Mark tag lists from listings that stopped paginating early
+ALTER TABLE registry_tag_cache ADD COLUMN partial INTEGER NOT NULL DEFAULT 0;
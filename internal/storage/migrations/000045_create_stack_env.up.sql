@@ -0,0 +1,10 @@
+-- Create stack_env table for per-stack compose environments
+-- Compose commands of a stack run with the variables of env_file and env
+-- (a JSON object) added to docksmith's environment
+
+CREATE TABLE IF NOT EXISTS stack_env (
+    stack TEXT PRIMARY KEY,
+    env_file TEXT NOT NULL DEFAULT '',
+    env TEXT NOT NULL DEFAULT '{}',
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
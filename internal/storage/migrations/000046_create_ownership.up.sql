@@ -0,0 +1,20 @@
+-- Create ownership tables for scoping stacks and containers to their owners
+-- An owner is a user ("user:NAME"), an API key ("api_key:NAME"), or a team
+-- ("team:NAME") whose members are listed in team_members
+
+CREATE TABLE IF NOT EXISTS ownership (
+    entity_type TEXT NOT NULL CHECK (entity_type IN ('stack', 'container')),
+    entity_id TEXT NOT NULL,
+    owner TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (entity_type, entity_id, owner)
+);
+
+CREATE TABLE IF NOT EXISTS team_members (
+    team TEXT NOT NULL,
+    username TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (team, username)
+);
+
+CREATE INDEX IF NOT EXISTS idx_team_members_username ON team_members(username);
@@ -0,0 +1,59 @@
+-- Revert: Remove the 'pin' operation type
+
+-- Step 1: Create table without it
+CREATE TABLE update_operations_new (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    operation_id TEXT NOT NULL UNIQUE,
+    container_id TEXT,
+    container_name TEXT NOT NULL,
+    stack_name TEXT,
+    operation_type TEXT NOT NULL CHECK(operation_type IN ('single', 'batch', 'stack', 'rollback', 'restart', 'label_change', 'stop', 'remove', 'fix_mismatch', 'start', 'variant')),
+    status TEXT NOT NULL CHECK(status IN ('queued', 'validating', 'backup', 'updating_compose', 'pulling_image', 'stopping', 'starting', 'health_check', 'restarting_dependents', 'complete', 'failed', 'rolling_back', 'cancelled', 'in_progress', 'pending_restart', 'paused')),
+    old_version TEXT,
+    new_version TEXT,
+    started_at TIMESTAMP,
+    completed_at TIMESTAMP,
+    error_message TEXT,
+    dependents_affected TEXT,
+    rollback_occurred BOOLEAN NOT NULL DEFAULT 0,
+    batch_details TEXT,
+    batch_group_id TEXT,
+    check_output TEXT,
+    all_or_nothing BOOLEAN NOT NULL DEFAULT 0,
+    signature_verifications TEXT,
+    observations TEXT,
+    compose_output TEXT,
+    triggered_by TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Step 2: Copy data (excluding operations of that type)
+INSERT INTO update_operations_new (id, operation_id, container_id, container_name, stack_name, operation_type, status, old_version, new_version, started_at, completed_at, error_message, dependents_affected, rollback_occurred, batch_details, batch_group_id, check_output, all_or_nothing, signature_verifications, observations, compose_output, triggered_by, created_at, updated_at)
+SELECT id, operation_id, container_id, container_name, stack_name, operation_type, status, old_version, new_version, started_at, completed_at, error_message, dependents_affected, rollback_occurred, batch_details, batch_group_id, check_output, all_or_nothing, signature_verifications, observations, compose_output, triggered_by, created_at, updated_at FROM update_operations
+WHERE operation_type NOT IN ('pin');
+
+-- Step 3: Drop old table
+DROP TABLE update_operations;
+
+-- Step 4: Rename new table
+ALTER TABLE update_operations_new RENAME TO update_operations;
+
+-- Step 5: Recreate indexes
+CREATE UNIQUE INDEX IF NOT EXISTS idx_update_operations_operation_id
+ON update_operations(operation_id);
+
+CREATE INDEX IF NOT EXISTS idx_update_operations_container_name
+ON update_operations(container_name, started_at DESC);
+
+CREATE INDEX IF NOT EXISTS idx_update_operations_stack_name
+ON update_operations(stack_name, started_at DESC);
+
+CREATE INDEX IF NOT EXISTS idx_update_operations_status
+ON update_operations(status, created_at);
+
+CREATE INDEX IF NOT EXISTS idx_update_operations_started_at
+ON update_operations(started_at DESC);
+
+CREATE INDEX IF NOT EXISTS idx_update_operations_batch_group_id
+ON update_operations(batch_group_id);
@@ -0,0 +1,16 @@
+-- Rollback migration for the initial PostgreSQL schema
+
+DROP TABLE IF EXISTS update_proposals;
+DROP TABLE IF EXISTS pending_approvals;
+DROP TABLE IF EXISTS sessions;
+DROP TABLE IF EXISTS users;
+DROP TABLE IF EXISTS script_assignments;
+DROP TABLE IF EXISTS rollback_policies;
+DROP TABLE IF EXISTS update_queue;
+DROP TABLE IF EXISTS update_operations;
+DROP TABLE IF EXISTS config_history;
+DROP TABLE IF EXISTS config;
+DROP TABLE IF EXISTS update_log;
+DROP TABLE IF EXISTS check_history;
+DROP TABLE IF EXISTS registry_tag_cache;
+DROP TABLE IF EXISTS version_cache;
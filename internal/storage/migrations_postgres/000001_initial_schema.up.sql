@@ -0,0 +1,189 @@
+-- Initial PostgreSQL schema
+-- Matches the SQLite schema as of migration 000029. Later schema changes need
+-- a migration in both migrations/ and migrations_postgres/.
+
+CREATE TABLE IF NOT EXISTS version_cache (
+    sha256 TEXT NOT NULL,
+    image_ref TEXT NOT NULL,
+    resolved_version TEXT NOT NULL,
+    architecture TEXT NOT NULL DEFAULT 'amd64',
+    resolved_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (sha256, image_ref, architecture)
+);
+
+CREATE INDEX IF NOT EXISTS idx_version_cache_resolved_at ON version_cache(resolved_at);
+
+CREATE TABLE IF NOT EXISTS registry_tag_cache (
+    image_ref TEXT PRIMARY KEY,
+    tags TEXT NOT NULL,              -- JSON array of tag names
+    etag TEXT NOT NULL DEFAULT '',
+    last_modified TEXT NOT NULL DEFAULT '',
+    fetched_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS check_history (
+    id BIGSERIAL PRIMARY KEY,
+    container_name TEXT NOT NULL,
+    image TEXT NOT NULL,
+    check_time TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    current_version TEXT NOT NULL,
+    latest_version TEXT NOT NULL,
+    status TEXT NOT NULL,
+    error TEXT,
+    latest_size BIGINT NOT NULL DEFAULT 0,
+    size_delta BIGINT NOT NULL DEFAULT 0
+);
+
+CREATE INDEX IF NOT EXISTS idx_check_history_check_time ON check_history(check_time DESC);
+CREATE INDEX IF NOT EXISTS idx_check_history_container_time ON check_history(container_name, check_time DESC);
+
+CREATE TABLE IF NOT EXISTS update_log (
+    id BIGSERIAL PRIMARY KEY,
+    container_name TEXT NOT NULL,
+    operation TEXT NOT NULL CHECK(operation IN ('pull', 'restart', 'rollback')),
+    from_version TEXT NOT NULL,
+    to_version TEXT NOT NULL,
+    timestamp TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    success BOOLEAN NOT NULL,
+    error TEXT
+);
+
+CREATE INDEX IF NOT EXISTS idx_update_log_container_name ON update_log(container_name, timestamp DESC);
+
+CREATE TABLE IF NOT EXISTS config (
+    key TEXT PRIMARY KEY,
+    value TEXT NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS config_history (
+    id BIGSERIAL PRIMARY KEY,
+    snapshot_time TIMESTAMPTZ NOT NULL,
+    config_snapshot TEXT NOT NULL,
+    changed_by TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_config_history_snapshot_time ON config_history(snapshot_time DESC);
+
+CREATE TABLE IF NOT EXISTS update_operations (
+    id BIGSERIAL PRIMARY KEY,
+    operation_id TEXT NOT NULL UNIQUE,
+    container_id TEXT,
+    container_name TEXT NOT NULL,
+    stack_name TEXT,
+    operation_type TEXT NOT NULL CHECK(operation_type IN ('single', 'batch', 'stack', 'rollback', 'restart', 'label_change', 'stop', 'remove', 'fix_mismatch', 'start')),
+    status TEXT NOT NULL CHECK(status IN ('queued', 'validating', 'backup', 'updating_compose', 'pulling_image', 'stopping', 'starting', 'health_check', 'restarting_dependents', 'complete', 'failed', 'rolling_back', 'cancelled', 'in_progress', 'pending_restart')),
+    old_version TEXT,
+    new_version TEXT,
+    started_at TIMESTAMPTZ,
+    completed_at TIMESTAMPTZ,
+    error_message TEXT,
+    dependents_affected TEXT,        -- JSON array of container names
+    rollback_occurred BOOLEAN NOT NULL DEFAULT FALSE,
+    batch_details TEXT,              -- JSON array of per-container results
+    batch_group_id TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_update_operations_container_name ON update_operations(container_name, started_at DESC);
+CREATE INDEX IF NOT EXISTS idx_update_operations_stack_name ON update_operations(stack_name, started_at DESC);
+CREATE INDEX IF NOT EXISTS idx_update_operations_started_at ON update_operations(started_at DESC);
+CREATE INDEX IF NOT EXISTS idx_update_operations_status ON update_operations(status, created_at);
+CREATE INDEX IF NOT EXISTS idx_update_operations_batch_group_id ON update_operations(batch_group_id);
+
+CREATE TABLE IF NOT EXISTS update_queue (
+    id BIGSERIAL PRIMARY KEY,
+    operation_id TEXT NOT NULL UNIQUE,
+    stack_name TEXT NOT NULL,
+    containers TEXT NOT NULL,        -- JSON array of container names
+    operation_type TEXT NOT NULL DEFAULT 'single',
+    target_versions TEXT NOT NULL DEFAULT '{}',
+    all_or_nothing BOOLEAN NOT NULL DEFAULT FALSE,
+    priority INTEGER NOT NULL DEFAULT 0,
+    queued_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    estimated_start_time TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_update_queue_priority ON update_queue(priority DESC, queued_at);
+CREATE INDEX IF NOT EXISTS idx_update_queue_stack_name ON update_queue(stack_name, queued_at);
+
+CREATE TABLE IF NOT EXISTS rollback_policies (
+    id BIGSERIAL PRIMARY KEY,
+    entity_type TEXT NOT NULL CHECK(entity_type IN ('global', 'container', 'stack')),
+    entity_id TEXT NOT NULL DEFAULT '', -- empty for the global policy
+    auto_rollback_enabled BOOLEAN NOT NULL DEFAULT FALSE,
+    health_check_required BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(entity_type, entity_id)
+);
+
+CREATE TABLE IF NOT EXISTS script_assignments (
+    id BIGSERIAL PRIMARY KEY,
+    container_name TEXT NOT NULL UNIQUE,
+    script_path TEXT NOT NULL,
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    ignore BOOLEAN NOT NULL DEFAULT FALSE,
+    allow_latest BOOLEAN NOT NULL DEFAULT FALSE,
+    assigned_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    assigned_by TEXT,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_script_assignments_enabled ON script_assignments(enabled, container_name);
+
+CREATE TABLE IF NOT EXISTS users (
+    id BIGSERIAL PRIMARY KEY,
+    username TEXT NOT NULL UNIQUE,
+    password_hash TEXT NOT NULL DEFAULT '',
+    role TEXT NOT NULL CHECK(role IN ('viewer', 'operator', 'admin')),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS sessions (
+    token_hash TEXT PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_sessions_expires_at ON sessions(expires_at);
+
+CREATE TABLE IF NOT EXISTS pending_approvals (
+    id TEXT PRIMARY KEY,
+    container_name TEXT NOT NULL,
+    stack_name TEXT NOT NULL DEFAULT '',
+    current_version TEXT NOT NULL DEFAULT '',
+    target_version TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending' CHECK(status IN ('pending', 'approved', 'rejected', 'expired', 'superseded')),
+    requested_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMPTZ NOT NULL,
+    decided_by TEXT NOT NULL DEFAULT '',
+    decided_at TIMESTAMPTZ,
+    operation_id TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX IF NOT EXISTS idx_pending_approvals_container ON pending_approvals(container_name, requested_at);
+CREATE INDEX IF NOT EXISTS idx_pending_approvals_status ON pending_approvals(status);
+
+CREATE TABLE IF NOT EXISTS update_proposals (
+    id TEXT PRIMARY KEY,
+    container_name TEXT NOT NULL,
+    stack_name TEXT NOT NULL DEFAULT '',
+    current_version TEXT NOT NULL DEFAULT '',
+    target_version TEXT NOT NULL,
+    compose_file TEXT NOT NULL,
+    patch TEXT NOT NULL,
+    patch_file TEXT NOT NULL DEFAULT '',
+    branch TEXT NOT NULL DEFAULT '',
+    pull_request_url TEXT NOT NULL DEFAULT '',
+    status TEXT NOT NULL DEFAULT 'open' CHECK(status IN ('open', 'applied', 'superseded')),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_update_proposals_container ON update_proposals(container_name, created_at);
+CREATE INDEX IF NOT EXISTS idx_update_proposals_status ON update_proposals(status);
@@ -0,0 +1,2 @@
+-- Store post-update check script output on update operations
+ALTER TABLE update_operations ADD COLUMN IF NOT EXISTS check_output TEXT;
@@ -0,0 +1,5 @@
+UPDATE update_operations SET status = 'cancelled' WHERE status = 'paused';
+ALTER TABLE update_operations DROP CONSTRAINT IF EXISTS update_operations_status_check;
+ALTER TABLE update_operations ADD CONSTRAINT update_operations_status_check
+    CHECK(status IN ('queued', 'validating', 'backup', 'updating_compose', 'pulling_image', 'stopping', 'starting', 'health_check', 'restarting_dependents', 'complete', 'failed', 'rolling_back', 'cancelled', 'in_progress', 'pending_restart'));
+ALTER TABLE update_operations DROP COLUMN IF EXISTS all_or_nothing;
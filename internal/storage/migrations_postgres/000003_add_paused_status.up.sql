@@ -0,0 +1,6 @@
+-- Add 'paused' status for operations paused between pull and recreate,
+-- and all_or_nothing so a paused batch resumes with the same rollback mode
+ALTER TABLE update_operations DROP CONSTRAINT IF EXISTS update_operations_status_check;
+ALTER TABLE update_operations ADD CONSTRAINT update_operations_status_check
+    CHECK(status IN ('queued', 'validating', 'backup', 'updating_compose', 'pulling_image', 'stopping', 'starting', 'health_check', 'restarting_dependents', 'complete', 'failed', 'rolling_back', 'cancelled', 'in_progress', 'pending_restart', 'paused'));
+ALTER TABLE update_operations ADD COLUMN IF NOT EXISTS all_or_nothing BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,2 @@
+-- Store image signature verification results on update operations
+ALTER TABLE update_operations ADD COLUMN IF NOT EXISTS signature_verifications TEXT;
@@ -0,0 +1,14 @@
+-- Snapshots of named volumes taken before updates, restored on rollback.
+CREATE TABLE IF NOT EXISTS volume_snapshots (
+    id BIGSERIAL PRIMARY KEY,
+    operation_id TEXT NOT NULL,
+    container_name TEXT NOT NULL,
+    volume_name TEXT NOT NULL,
+    method TEXT NOT NULL,
+    location TEXT NOT NULL,
+    size_bytes BIGINT NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_volume_snapshots_operation ON volume_snapshots(operation_id);
+CREATE INDEX IF NOT EXISTS idx_volume_snapshots_volume ON volume_snapshots(volume_name, created_at);
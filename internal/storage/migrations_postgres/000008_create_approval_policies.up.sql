@@ -0,0 +1,11 @@
+-- Which updates of the host, a stack, or a container need approval (none, major, all).
+-- The global policy is stored with an empty entity_id.
+CREATE TABLE IF NOT EXISTS approval_policies (
+    id BIGSERIAL PRIMARY KEY,
+    entity_type TEXT NOT NULL CHECK(entity_type IN ('global', 'container', 'stack')),
+    entity_id TEXT NOT NULL DEFAULT '',
+    mode TEXT NOT NULL CHECK(mode IN ('none', 'major', 'all')),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(entity_type, entity_id)
+);
@@ -0,0 +1,2 @@
+-- Store the output of the docker compose commands run by update operations
+ALTER TABLE update_operations ADD COLUMN IF NOT EXISTS compose_output TEXT;
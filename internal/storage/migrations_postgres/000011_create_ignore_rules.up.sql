@@ -0,0 +1,7 @@
+-- Image patterns excluded from update checks, e.g. ghcr.io/home-assistant/* or *:nightly.
+CREATE TABLE IF NOT EXISTS ignore_rules (
+    id BIGSERIAL PRIMARY KEY,
+    pattern TEXT NOT NULL UNIQUE,
+    reason TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
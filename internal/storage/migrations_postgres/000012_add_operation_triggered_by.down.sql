@@ -0,0 +1 @@
+ALTER TABLE update_operations DROP COLUMN IF EXISTS triggered_by;
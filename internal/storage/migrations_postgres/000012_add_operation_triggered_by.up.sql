@@ -0,0 +1,2 @@
+-- Record who or what started an update operation (user, API key, scheduler)
+ALTER TABLE update_operations ADD COLUMN IF NOT EXISTS triggered_by TEXT;
@@ -0,0 +1,7 @@
+-- Operations holding a stack lock, so held locks can be inspected and
+-- locks left behind by a restart are cleared on startup.
+CREATE TABLE IF NOT EXISTS stack_locks (
+    stack_name TEXT PRIMARY KEY,
+    operation_id TEXT NOT NULL,
+    acquired_at TIMESTAMPTZ NOT NULL
+);
@@ -0,0 +1,11 @@
+-- Registry digest and tag list each container's last check saw, so containers
+-- whose images have not changed reuse their last result.
+CREATE TABLE IF NOT EXISTS check_fingerprints (
+    container_name TEXT PRIMARY KEY,
+    image TEXT NOT NULL,
+    local_hash TEXT NOT NULL,
+    head_digest TEXT NOT NULL,
+    tags_hash TEXT NOT NULL,
+    result TEXT NOT NULL,
+    checked_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
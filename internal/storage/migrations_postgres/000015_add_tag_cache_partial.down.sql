@@ -0,0 +1 @@
+ALTER TABLE registry_tag_cache DROP COLUMN IF EXISTS partial;
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS stack_env;
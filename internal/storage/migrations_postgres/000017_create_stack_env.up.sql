@@ -0,0 +1,8 @@
+-- Environment of each stack's compose commands: the variables of env_file and
+-- env (a JSON object) are added to docksmith's environment.
+CREATE TABLE IF NOT EXISTS stack_env (
+    stack TEXT PRIMARY KEY,
+    env_file TEXT NOT NULL DEFAULT '',
+    env TEXT NOT NULL DEFAULT '{}',
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
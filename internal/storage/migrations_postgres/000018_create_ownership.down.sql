@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_team_members_username;
+DROP TABLE IF EXISTS team_members;
+DROP TABLE IF EXISTS ownership;
@@ -0,0 +1,18 @@
+-- Owners of stacks and containers: users ("user:NAME"), API keys
+-- ("api_key:NAME"), or teams ("team:NAME") whose members are in team_members.
+CREATE TABLE IF NOT EXISTS ownership (
+    entity_type TEXT NOT NULL CHECK (entity_type IN ('stack', 'container')),
+    entity_id TEXT NOT NULL,
+    owner TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (entity_type, entity_id, owner)
+);
+
+CREATE TABLE IF NOT EXISTS team_members (
+    team TEXT NOT NULL,
+    username TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (team, username)
+);
+
+CREATE INDEX IF NOT EXISTS idx_team_members_username ON team_members(username);
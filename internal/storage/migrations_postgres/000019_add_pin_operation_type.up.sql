@@ -0,0 +1,5 @@
+-- Add the 'pin' operation type for migrating containers from :latest to a
+-- versioned tag
+ALTER TABLE update_operations DROP CONSTRAINT IF EXISTS update_operations_operation_type_check;
+ALTER TABLE update_operations ADD CONSTRAINT update_operations_operation_type_check
+    CHECK(operation_type IN ('single', 'batch', 'stack', 'rollback', 'restart', 'label_change', 'stop', 'remove', 'fix_mismatch', 'start', 'variant', 'pin'));
@@ -0,0 +1,132 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+)
+
+// SaveApproval implements Storage.SaveApproval.
+func (p *PostgresStorage) SaveApproval(ctx context.Context, approval Approval) error {
+	query := `
+		INSERT INTO pending_approvals (` + approvalColumns + `)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (id) DO UPDATE SET
+			status = excluded.status,
+			target_version = excluded.target_version,
+			expires_at = excluded.expires_at,
+			decided_by = excluded.decided_by,
+			decided_at = excluded.decided_at,
+			operation_id = excluded.operation_id
+	`
+
+	var decidedAt sql.NullTime
+	if approval.DecidedAt != nil {
+		decidedAt = sql.NullTime{Time: *approval.DecidedAt, Valid: true}
+	}
+
+	_, err := p.exec(ctx, query,
+		approval.ID, approval.ContainerName, approval.StackName, approval.CurrentVersion,
+		approval.TargetVersion, approval.Status, approval.RequestedAt, approval.ExpiresAt,
+		approval.DecidedBy, decidedAt, approval.OperationID,
+	)
+	if err != nil {
+		log.Printf("Failed to save approval %s: %v", approval.ID, err)
+		return fmt.Errorf("failed to save approval: %w", err)
+	}
+
+	log.Printf("Saved approval: id=%s, container=%s, target=%s, status=%s",
+		approval.ID, approval.ContainerName, approval.TargetVersion, approval.Status)
+	return nil
+}
+
+// GetApproval implements Storage.GetApproval.
+func (p *PostgresStorage) GetApproval(ctx context.Context, id string) (Approval, bool, error) {
+	return p.queryApproval(ctx, `SELECT `+approvalColumns+` FROM pending_approvals WHERE id = ?`, id)
+}
+
+// GetLatestApproval implements Storage.GetLatestApproval.
+func (p *PostgresStorage) GetLatestApproval(ctx context.Context, containerName string) (Approval, bool, error) {
+	query := `
+		SELECT ` + approvalColumns + `
+		FROM pending_approvals
+		WHERE container_name = ?
+		ORDER BY requested_at DESC
+		LIMIT 1
+	`
+	return p.queryApproval(ctx, query, containerName)
+}
+
+// ListApprovals implements Storage.ListApprovals.
+func (p *PostgresStorage) ListApprovals(ctx context.Context, status string, limit int) ([]Approval, error) {
+	if limit <= 0 {
+		limit = 100
+	}
+
+	query := `SELECT ` + approvalColumns + ` FROM pending_approvals`
+	args := []any{}
+	if status != "" {
+		query += ` WHERE status = ?`
+		args = append(args, status)
+	}
+	query += ` ORDER BY requested_at DESC LIMIT ?`
+	args = append(args, limit)
+
+	rows, err := p.query(ctx, query, args...)
+	if err != nil {
+		log.Printf("Failed to query approvals: %v", err)
+		return nil, fmt.Errorf("failed to query approvals: %w", err)
+	}
+	defer rows.Close()
+
+	approvals := make([]Approval, 0)
+	for rows.Next() {
+		approval, err := scanApproval(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan approval: %w", err)
+		}
+		approvals = append(approvals, approval)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating approval rows: %w", err)
+	}
+
+	return approvals, nil
+}
+
+// ExpireApprovals implements Storage.ExpireApprovals.
+func (p *PostgresStorage) ExpireApprovals(ctx context.Context, now time.Time) (int64, error) {
+	query := `
+		UPDATE pending_approvals
+		SET status = 'expired'
+		WHERE status = 'pending' AND expires_at < ?
+	`
+
+	result, err := p.exec(ctx, query, now)
+	if err != nil {
+		log.Printf("Failed to expire approvals: %v", err)
+		return 0, fmt.Errorf("failed to expire approvals: %w", err)
+	}
+
+	expired, _ := result.RowsAffected()
+	if expired > 0 {
+		log.Printf("Expired %d pending approvals", expired)
+	}
+	return expired, nil
+}
+
+// queryApproval runs a single-row approval query.
+func (p *PostgresStorage) queryApproval(ctx context.Context, query string, arg any) (Approval, bool, error) {
+	approval, err := scanApproval(p.queryRow(ctx, query, arg))
+	if err == sql.ErrNoRows {
+		return Approval{}, false, nil
+	}
+	if err != nil {
+		log.Printf("Failed to query approval %v: %v", arg, err)
+		return Approval{}, false, fmt.Errorf("failed to query approval: %w", err)
+	}
+	return approval, true, nil
+}
@@ -0,0 +1,170 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+)
+
+// SaveVersionCache implements Storage.SaveVersionCache.
+func (p *PostgresStorage) SaveVersionCache(ctx context.Context, sha256, imageRef, version, arch string) error {
+	query := `
+		INSERT INTO version_cache
+		(sha256, image_ref, resolved_version, architecture, resolved_at)
+		VALUES (?, ?, ?, ?, CURRENT_TIMESTAMP)
+		ON CONFLICT (sha256, image_ref, architecture) DO UPDATE SET
+			resolved_version = excluded.resolved_version,
+			resolved_at = excluded.resolved_at
+	`
+
+	if _, err := p.exec(ctx, query, sha256, imageRef, version, arch); err != nil {
+		log.Printf("Failed to save version cache for %s (%s, %s): %v", imageRef, sha256, arch, err)
+		return fmt.Errorf("failed to save version cache: %w", err)
+	}
+
+	log.Printf("Saved version cache: %s -> %s (%s, %s)", sha256, version, imageRef, arch)
+	return nil
+}
+
+// GetVersionCache implements Storage.GetVersionCache.
+// Entries older than CACHE_TTL (default 1 hour) are treated as missing.
+func (p *PostgresStorage) GetVersionCache(ctx context.Context, sha256, imageRef, arch string) (string, bool, error) {
+	ttl := versionCacheTTL()
+
+	var version string
+	var resolvedAt time.Time
+
+	query := `
+		SELECT resolved_version, resolved_at
+		FROM version_cache
+		WHERE sha256 = ? AND image_ref = ? AND architecture = ?
+	`
+
+	err := p.queryRow(ctx, query, sha256, imageRef, arch).Scan(&version, &resolvedAt)
+	if err == sql.ErrNoRows {
+		return "", false, nil
+	}
+	if err != nil {
+		log.Printf("Failed to query version cache for %s (%s, %s): %v", imageRef, sha256, arch, err)
+		return "", false, fmt.Errorf("failed to query version cache: %w", err)
+	}
+
+	if resolvedAt.Before(time.Now().Add(-ttl)) {
+		log.Printf("Version cache expired for %s (%s, %s): resolved at %v", imageRef, sha256, arch, resolvedAt)
+		return "", false, nil
+	}
+
+	log.Printf("Version cache hit: %s -> %s (%s, %s)", sha256, version, imageRef, arch)
+	return version, true, nil
+}
+
+// GetTagCache implements Storage.GetTagCache.
+func (p *PostgresStorage) GetTagCache(ctx context.Context, imageRef string) (TagCacheEntry, bool, error) {
+	entry := TagCacheEntry{ImageRef: imageRef}
+	var tagsJSON string
+
+	query := `
+		SELECT tags, etag, last_modified, partial, fetched_at
+		FROM registry_tag_cache
+		WHERE image_ref = ?
+	`
+
+	err := p.queryRow(ctx, query, imageRef).Scan(&tagsJSON, &entry.ETag, &entry.LastModified, &entry.Partial, &entry.FetchedAt)
+	if err == sql.ErrNoRows {
+		return TagCacheEntry{}, false, nil
+	}
+	if err != nil {
+		log.Printf("Failed to query tag cache for %s: %v", imageRef, err)
+		return TagCacheEntry{}, false, fmt.Errorf("failed to query tag cache: %w", err)
+	}
+
+	if err := json.Unmarshal([]byte(tagsJSON), &entry.Tags); err != nil {
+		return TagCacheEntry{}, false, fmt.Errorf("failed to decode cached tags: %w", err)
+	}
+
+	return entry, true, nil
+}
+
+// SaveTagCache implements Storage.SaveTagCache.
+func (p *PostgresStorage) SaveTagCache(ctx context.Context, entry TagCacheEntry) error {
+	tagsJSON, err := json.Marshal(entry.Tags)
+	if err != nil {
+		return fmt.Errorf("failed to encode tags: %w", err)
+	}
+	if entry.FetchedAt.IsZero() {
+		entry.FetchedAt = time.Now()
+	}
+
+	query := `
+		INSERT INTO registry_tag_cache
+		(image_ref, tags, etag, last_modified, partial, fetched_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT (image_ref) DO UPDATE SET
+			tags = excluded.tags,
+			etag = excluded.etag,
+			last_modified = excluded.last_modified,
+			partial = excluded.partial,
+			fetched_at = excluded.fetched_at
+	`
+
+	_, err = p.exec(ctx, query, entry.ImageRef, string(tagsJSON), entry.ETag, entry.LastModified, entry.Partial, entry.FetchedAt.UTC())
+	if err != nil {
+		log.Printf("Failed to save tag cache for %s: %v", entry.ImageRef, err)
+		return fmt.Errorf("failed to save tag cache: %w", err)
+	}
+	return nil
+}
+
+// GetCheckFingerprint implements Storage.GetCheckFingerprint.
+func (p *PostgresStorage) GetCheckFingerprint(ctx context.Context, containerName string) (CheckFingerprint, bool, error) {
+	fingerprint := CheckFingerprint{ContainerName: containerName}
+
+	query := `
+		SELECT image, local_hash, head_digest, tags_hash, result, checked_at
+		FROM check_fingerprints
+		WHERE container_name = ?
+	`
+
+	err := p.queryRow(ctx, query, containerName).Scan(&fingerprint.Image, &fingerprint.LocalHash,
+		&fingerprint.HeadDigest, &fingerprint.TagsHash, &fingerprint.Result, &fingerprint.CheckedAt)
+	if err == sql.ErrNoRows {
+		return CheckFingerprint{}, false, nil
+	}
+	if err != nil {
+		log.Printf("Failed to query check fingerprint for %s: %v", containerName, err)
+		return CheckFingerprint{}, false, fmt.Errorf("failed to query check fingerprint: %w", err)
+	}
+
+	return fingerprint, true, nil
+}
+
+// SaveCheckFingerprint implements Storage.SaveCheckFingerprint.
+func (p *PostgresStorage) SaveCheckFingerprint(ctx context.Context, fingerprint CheckFingerprint) error {
+	if fingerprint.CheckedAt.IsZero() {
+		fingerprint.CheckedAt = time.Now()
+	}
+
+	query := `
+		INSERT INTO check_fingerprints
+		(container_name, image, local_hash, head_digest, tags_hash, result, checked_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (container_name) DO UPDATE SET
+			image = excluded.image,
+			local_hash = excluded.local_hash,
+			head_digest = excluded.head_digest,
+			tags_hash = excluded.tags_hash,
+			result = excluded.result,
+			checked_at = excluded.checked_at
+	`
+
+	_, err := p.exec(ctx, query, fingerprint.ContainerName, fingerprint.Image, fingerprint.LocalHash,
+		fingerprint.HeadDigest, fingerprint.TagsHash, fingerprint.Result, fingerprint.CheckedAt.UTC())
+	if err != nil {
+		log.Printf("Failed to save check fingerprint for %s: %v", fingerprint.ContainerName, err)
+		return fmt.Errorf("failed to save check fingerprint: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,170 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+// LogCheck implements Storage.LogCheck.
+func (p *PostgresStorage) LogCheck(ctx context.Context, containerName, image, currentVer, latestVer, status string, checkErr error) error {
+	query := `
+		INSERT INTO check_history
+		(container_name, image, current_version, latest_version, status, error)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`
+
+	var errorMsg string
+	if checkErr != nil {
+		errorMsg = checkErr.Error()
+	}
+
+	if _, err := p.exec(ctx, query, containerName, image, currentVer, latestVer, status, errorMsg); err != nil {
+		log.Printf("Failed to log check for %s: %v", containerName, err)
+		return fmt.Errorf("failed to log check: %w", err)
+	}
+
+	log.Printf("Logged check: %s [%s] -> %s", containerName, status, latestVer)
+	return nil
+}
+
+// LogCheckBatch implements Storage.LogCheckBatch.
+// All entries are inserted in one transaction.
+func (p *PostgresStorage) LogCheckBatch(ctx context.Context, checks []CheckHistoryEntry) error {
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("Failed to begin transaction for batch check logging: %v", err)
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, rebind(`
+		INSERT INTO check_history
+		(container_name, image, current_version, latest_version, status, error, latest_size, size_delta)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`))
+	if err != nil {
+		return fmt.Errorf("failed to prepare statement: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, check := range checks {
+		_, err = stmt.ExecContext(ctx, check.ContainerName, check.Image, check.CurrentVersion, check.LatestVersion, check.Status, check.Error, check.LatestSize, check.SizeDelta)
+		if err != nil {
+			log.Printf("Failed to insert check entry for %s: %v", check.ContainerName, err)
+			return fmt.Errorf("failed to insert check entry: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("Failed to commit batch check logging: %v", err)
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	log.Printf("Logged batch check: %d containers", len(checks))
+	return nil
+}
+
+// checkHistoryColumns lists the check_history columns read by scanCheckHistoryRows
+const checkHistoryColumns = `id, container_name, image, check_time, current_version, latest_version, status, error, latest_size, size_delta`
+
+// GetCheckHistory implements Storage.GetCheckHistory.
+func (p *PostgresStorage) GetCheckHistory(ctx context.Context, containerName string, limit int) ([]CheckHistoryEntry, error) {
+	query := `SELECT ` + checkHistoryColumns + ` FROM check_history WHERE container_name = ? ORDER BY check_time DESC LIMIT ?`
+
+	rows, err := p.query(ctx, query, containerName, limit)
+	if err != nil {
+		log.Printf("Failed to query check history for %s: %v", containerName, err)
+		return nil, fmt.Errorf("failed to query check history: %w", err)
+	}
+	defer rows.Close()
+
+	return scanCheckHistoryRows(rows)
+}
+
+// GetCheckHistoryByTimeRange implements Storage.GetCheckHistoryByTimeRange.
+func (p *PostgresStorage) GetCheckHistoryByTimeRange(ctx context.Context, start, end time.Time) ([]CheckHistoryEntry, error) {
+	query := `SELECT ` + checkHistoryColumns + ` FROM check_history WHERE check_time >= ? AND check_time <= ? ORDER BY check_time DESC`
+
+	rows, err := p.query(ctx, query, start, end)
+	if err != nil {
+		log.Printf("Failed to query check history by time range: %v", err)
+		return nil, fmt.Errorf("failed to query check history by time range: %w", err)
+	}
+	defer rows.Close()
+
+	return scanCheckHistoryRows(rows)
+}
+
+// QueryCheckHistory implements Storage.QueryCheckHistory.
+func (p *PostgresStorage) QueryCheckHistory(ctx context.Context, opts CheckHistoryQueryOptions) ([]CheckHistoryEntry, error) {
+	var conditions []string
+	var args []interface{}
+
+	if len(opts.Containers) > 0 {
+		placeholders := strings.TrimSuffix(strings.Repeat("?,", len(opts.Containers)), ",")
+		conditions = append(conditions, "container_name IN ("+placeholders+")")
+		for _, name := range opts.Containers {
+			args = append(args, name)
+		}
+	}
+	if opts.Status != "" {
+		conditions = append(conditions, "status = ?")
+		args = append(args, opts.Status)
+	}
+	if opts.DateFrom != nil {
+		conditions = append(conditions, "check_time >= ?")
+		args = append(args, *opts.DateFrom)
+	}
+	if opts.DateTo != nil {
+		conditions = append(conditions, "check_time <= ?")
+		args = append(args, *opts.DateTo)
+	}
+
+	whereClause := ""
+	if len(conditions) > 0 {
+		whereClause = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	baseQuery := `SELECT ` + checkHistoryColumns + ` FROM check_history ` + whereClause + ` ORDER BY check_time DESC`
+	query, args := withLimit(baseQuery, args, opts.Limit)
+
+	rows, err := p.query(ctx, query, args...)
+	if err != nil {
+		log.Printf("Failed to query check history: %v", err)
+		return nil, fmt.Errorf("failed to query check history: %w", err)
+	}
+	defer rows.Close()
+
+	return scanCheckHistoryRows(rows)
+}
+
+// GetAllCheckHistory implements Storage.GetAllCheckHistory.
+func (p *PostgresStorage) GetAllCheckHistory(ctx context.Context, limit int) ([]CheckHistoryEntry, error) {
+	query, args := withLimit(`SELECT `+checkHistoryColumns+` FROM check_history ORDER BY check_time DESC`, nil, limit)
+
+	rows, err := p.query(ctx, query, args...)
+	if err != nil {
+		log.Printf("Failed to query all check history: %v", err)
+		return nil, fmt.Errorf("failed to query all check history: %w", err)
+	}
+	defer rows.Close()
+
+	return scanCheckHistoryRows(rows)
+}
+
+// GetCheckHistorySince implements Storage.GetCheckHistorySince.
+func (p *PostgresStorage) GetCheckHistorySince(ctx context.Context, since time.Time) ([]CheckHistoryEntry, error) {
+	query := `SELECT ` + checkHistoryColumns + ` FROM check_history WHERE check_time >= ? ORDER BY check_time DESC`
+
+	rows, err := p.query(ctx, query, since)
+	if err != nil {
+		log.Printf("Failed to query check history since %v: %v", since, err)
+		return nil, fmt.Errorf("failed to query check history since: %w", err)
+	}
+	defer rows.Close()
+
+	return scanCheckHistoryRows(rows)
+}
@@ -0,0 +1,173 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+)
+
+// GetConfig implements Storage.GetConfig.
+func (p *PostgresStorage) GetConfig(ctx context.Context, key string) (string, bool, error) {
+	var value string
+	err := p.queryRow(ctx, `SELECT value FROM config WHERE key = ?`, key).Scan(&value)
+	if err == sql.ErrNoRows {
+		return "", false, nil
+	}
+	if err != nil {
+		log.Printf("Failed to query config for key %s: %v", key, err)
+		return "", false, fmt.Errorf("failed to query config: %w", err)
+	}
+	return value, true, nil
+}
+
+// SetConfig implements Storage.SetConfig.
+func (p *PostgresStorage) SetConfig(ctx context.Context, key, value string) error {
+	query := `
+		INSERT INTO config (key, value, updated_at)
+		VALUES (?, ?, CURRENT_TIMESTAMP)
+		ON CONFLICT (key) DO UPDATE SET value = excluded.value, updated_at = excluded.updated_at
+	`
+	if _, err := p.exec(ctx, query, key, value); err != nil {
+		log.Printf("Failed to set config for key %s: %v", key, err)
+		return fmt.Errorf("failed to set config: %w", err)
+	}
+
+	log.Printf("Config updated: %s = %s", key, value)
+	return nil
+}
+
+// SaveConfigSnapshot implements Storage.SaveConfigSnapshot.
+func (p *PostgresStorage) SaveConfigSnapshot(ctx context.Context, snapshot ConfigSnapshot) error {
+	configJSON, err := json.Marshal(snapshot.ConfigData)
+	if err != nil {
+		return fmt.Errorf("failed to serialize config data: %w", err)
+	}
+
+	query := `
+		INSERT INTO config_history (snapshot_time, config_snapshot, changed_by)
+		VALUES (?, ?, ?)
+	`
+	if _, err := p.exec(ctx, query, snapshot.SnapshotTime, string(configJSON), snapshot.ChangedBy); err != nil {
+		log.Printf("Failed to save config snapshot: %v", err)
+		return fmt.Errorf("failed to save config snapshot: %w", err)
+	}
+
+	log.Printf("Saved config snapshot: changed_by=%s, keys=%d", snapshot.ChangedBy, len(snapshot.ConfigData))
+	return nil
+}
+
+// GetConfigHistory implements Storage.GetConfigHistory.
+func (p *PostgresStorage) GetConfigHistory(ctx context.Context, limit int) ([]ConfigSnapshot, error) {
+	query := `
+		SELECT id, snapshot_time, config_snapshot, changed_by, created_at
+		FROM config_history
+		ORDER BY snapshot_time DESC, id DESC
+		LIMIT ?
+	`
+
+	rows, err := p.query(ctx, query, limit)
+	if err != nil {
+		log.Printf("Failed to query config history: %v", err)
+		return nil, fmt.Errorf("failed to query config history: %w", err)
+	}
+	defer rows.Close()
+
+	var history []ConfigSnapshot
+	for rows.Next() {
+		snapshot, err := scanConfigSnapshot(rows)
+		if err != nil {
+			return nil, err
+		}
+		history = append(history, snapshot)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating config history rows: %w", err)
+	}
+
+	return history, nil
+}
+
+// GetConfigSnapshotByID implements Storage.GetConfigSnapshotByID.
+func (p *PostgresStorage) GetConfigSnapshotByID(ctx context.Context, snapshotID int64) (ConfigSnapshot, bool, error) {
+	query := `
+		SELECT id, snapshot_time, config_snapshot, changed_by, created_at
+		FROM config_history
+		WHERE id = ?
+	`
+
+	snapshot, err := scanConfigSnapshot(p.queryRow(ctx, query, snapshotID))
+	if err == sql.ErrNoRows {
+		return ConfigSnapshot{}, false, nil
+	}
+	if err != nil {
+		log.Printf("Failed to query config snapshot %d: %v", snapshotID, err)
+		return ConfigSnapshot{}, false, fmt.Errorf("failed to query config snapshot: %w", err)
+	}
+	return snapshot, true, nil
+}
+
+// RevertToSnapshot implements Storage.RevertToSnapshot.
+// Restores the config table from a snapshot and records the revert as a new snapshot.
+func (p *PostgresStorage) RevertToSnapshot(ctx context.Context, snapshotID int64) error {
+	snapshot, found, err := p.GetConfigSnapshotByID(ctx, snapshotID)
+	if err != nil {
+		return fmt.Errorf("failed to retrieve snapshot: %w", err)
+	}
+	if !found {
+		return fmt.Errorf("snapshot %d not found", snapshotID)
+	}
+
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM config"); err != nil {
+		return fmt.Errorf("failed to delete current config: %w", err)
+	}
+
+	for key, value := range snapshot.ConfigData {
+		_, err := tx.ExecContext(ctx, rebind(`INSERT INTO config (key, value, updated_at) VALUES (?, ?, CURRENT_TIMESTAMP)`), key, value)
+		if err != nil {
+			return fmt.Errorf("failed to restore config key: %w", err)
+		}
+	}
+
+	configJSON, err := json.Marshal(snapshot.ConfigData)
+	if err != nil {
+		return fmt.Errorf("failed to serialize config for revert snapshot: %w", err)
+	}
+	_, err = tx.ExecContext(ctx, rebind(`
+		INSERT INTO config_history (snapshot_time, config_snapshot, changed_by)
+		VALUES (?, ?, ?)
+	`), time.Now(), string(configJSON), fmt.Sprintf("revert-to-snapshot-%d", snapshotID))
+	if err != nil {
+		return fmt.Errorf("failed to create revert snapshot: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	log.Printf("Reverted config to snapshot %d: restored %d keys", snapshotID, len(snapshot.ConfigData))
+	return nil
+}
+
+// scanConfigSnapshot scans a config_history row and decodes its config data.
+func scanConfigSnapshot(row interface{ Scan(...any) error }) (ConfigSnapshot, error) {
+	var snapshot ConfigSnapshot
+	var configJSON string
+	err := row.Scan(&snapshot.ID, &snapshot.SnapshotTime, &configJSON, &snapshot.ChangedBy, &snapshot.CreatedAt)
+	if err != nil {
+		return ConfigSnapshot{}, err
+	}
+	if err := json.Unmarshal([]byte(configJSON), &snapshot.ConfigData); err != nil {
+		return ConfigSnapshot{}, fmt.Errorf("failed to deserialize config data: %w", err)
+	}
+	return snapshot, nil
+}
@@ -0,0 +1,105 @@
+//go:build postgres
+
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newPostgresTestStorage opens the PostgreSQL database of POSTGRES_TEST_DSN in
+// a schema of its own, dropped when the test ends, and runs the migrations.
+// Run with: POSTGRES_TEST_DSN=postgres://... go test -tags postgres ./internal/storage/
+func newPostgresTestStorage(t *testing.T) *PostgresStorage {
+	t.Helper()
+	dsn := os.Getenv("POSTGRES_TEST_DSN")
+	if dsn == "" {
+		t.Skip("POSTGRES_TEST_DSN is not set")
+	}
+
+	admin, err := sql.Open(postgresDriverName, dsn)
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	t.Cleanup(func() { admin.Close() })
+
+	schema := fmt.Sprintf("docksmith_test_%d", time.Now().UnixNano())
+	if _, err := admin.Exec("CREATE SCHEMA " + schema); err != nil {
+		t.Fatalf("Failed to create schema: %v", err)
+	}
+	t.Cleanup(func() {
+		if _, err := admin.Exec("DROP SCHEMA " + schema + " CASCADE"); err != nil {
+			t.Errorf("Failed to drop schema %s: %v", schema, err)
+		}
+	})
+
+	if strings.Contains(dsn, "://") {
+		u, err := url.Parse(dsn)
+		if err != nil {
+			t.Fatalf("Failed to parse POSTGRES_TEST_DSN: %v", err)
+		}
+		query := u.Query()
+		query.Set("search_path", schema)
+		u.RawQuery = query.Encode()
+		dsn = u.String()
+	} else {
+		dsn += " search_path=" + schema
+	}
+
+	store, err := NewPostgresStorage(dsn)
+	if err != nil {
+		t.Fatalf("NewPostgresStorage failed: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+func TestPostgresMigrationsRerun(t *testing.T) {
+	store := newPostgresTestStorage(t)
+
+	if err := store.runMigrations(context.Background()); err != nil {
+		t.Errorf("Running applied migrations again failed: %v", err)
+	}
+}
+
+func TestPostgresOperationTypes(t *testing.T) {
+	store := newPostgresTestStorage(t)
+	ctx := context.Background()
+
+	for _, opType := range postgresOperationTypes(t) {
+		op := UpdateOperation{OperationID: "op-" + opType, ContainerName: "nginx", OperationType: opType, Status: StatusComplete}
+		if err := store.SaveUpdateOperation(ctx, op); err != nil {
+			t.Errorf("SaveUpdateOperation(%s) failed: %v", opType, err)
+			continue
+		}
+		saved, found, err := store.GetUpdateOperation(ctx, op.OperationID)
+		if err != nil || !found || saved.OperationType != opType {
+			t.Errorf("GetUpdateOperation(%s) = %+v, %v, %v", op.OperationID, saved, found, err)
+		}
+	}
+
+	err := store.SaveUpdateOperation(ctx, UpdateOperation{OperationID: "op-bogus", ContainerName: "nginx", OperationType: "bogus", Status: StatusComplete})
+	if err == nil {
+		t.Errorf("Expected the operation type constraint to reject an unknown type")
+	}
+}
+
+func TestPostgresConfig(t *testing.T) {
+	store := newPostgresTestStorage(t)
+	ctx := context.Background()
+
+	// Values are bound, so placeholders in them are not rewritten
+	if err := store.SetConfig(ctx, "check_interval", "what? 'now'"); err != nil {
+		t.Fatalf("SetConfig failed: %v", err)
+	}
+	value, found, err := store.GetConfig(ctx, "check_interval")
+	if err != nil || !found || value != "what? 'now'" {
+		t.Errorf("GetConfig = %q, %v, %v", value, found, err)
+	}
+}
@@ -0,0 +1,93 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"log"
+)
+
+// Backup implements Storage.Backup.
+// PostgreSQL databases are backed up with the server's own tooling.
+func (p *PostgresStorage) Backup(ctx context.Context, destPath string) error {
+	return fmt.Errorf("backups are not supported with the postgres driver; use pg_dump instead")
+}
+
+// Vacuum implements Storage.Vacuum.
+// Runs VACUUM ANALYZE and reports the database size before and after.
+func (p *PostgresStorage) Vacuum(ctx context.Context) (VacuumResult, error) {
+	var result VacuumResult
+	if err := p.queryRow(ctx, "SELECT pg_database_size(current_database())").Scan(&result.SizeBefore); err != nil {
+		return VacuumResult{}, fmt.Errorf("failed to read database size: %w", err)
+	}
+
+	if _, err := p.exec(ctx, "VACUUM ANALYZE"); err != nil {
+		return VacuumResult{}, fmt.Errorf("failed to vacuum database: %w", err)
+	}
+
+	if err := p.queryRow(ctx, "SELECT pg_database_size(current_database())").Scan(&result.SizeAfter); err != nil {
+		return VacuumResult{}, fmt.Errorf("failed to read database size: %w", err)
+	}
+
+	log.Printf("Vacuumed database: %d -> %d bytes", result.SizeBefore, result.SizeAfter)
+	return result, nil
+}
+
+// PruneHistory implements Storage.PruneHistory.
+func (p *PostgresStorage) PruneHistory(ctx context.Context, opts PruneOptions) (PruneResult, error) {
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return PruneResult{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var result PruneResult
+	if !opts.CheckHistoryBefore.IsZero() {
+		r, err := tx.ExecContext(ctx, "DELETE FROM check_history WHERE check_time < $1", opts.CheckHistoryBefore)
+		if err != nil {
+			return PruneResult{}, fmt.Errorf("failed to prune check history: %w", err)
+		}
+		result.CheckHistory, _ = r.RowsAffected()
+	}
+	if !opts.UpdateLogBefore.IsZero() {
+		r, err := tx.ExecContext(ctx, "DELETE FROM update_log WHERE timestamp < $1", opts.UpdateLogBefore)
+		if err != nil {
+			return PruneResult{}, fmt.Errorf("failed to prune update log: %w", err)
+		}
+		result.UpdateLog, _ = r.RowsAffected()
+	}
+
+	if err := tx.Commit(); err != nil {
+		return PruneResult{}, fmt.Errorf("failed to commit: %w", err)
+	}
+
+	if result.CheckHistory > 0 || result.UpdateLog > 0 {
+		log.Printf("Pruned history: %d checks, %d logs", result.CheckHistory, result.UpdateLog)
+	}
+	return result, nil
+}
+
+// ListComposeBackups implements Storage.ListComposeBackups. The PostgreSQL schema
+// has no compose_backups table, so there are never any records.
+func (p *PostgresStorage) ListComposeBackups(ctx context.Context) ([]ComposeBackup, error) {
+	return nil, nil
+}
+
+// DeleteComposeBackups implements Storage.DeleteComposeBackups.
+func (p *PostgresStorage) DeleteComposeBackups(ctx context.Context, ids []int64) (int64, error) {
+	return 0, nil
+}
+
+// CheckWritable implements Storage.CheckWritable.
+// Fails on read-only replicas and read-only transactions.
+func (p *PostgresStorage) CheckWritable(ctx context.Context) error {
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM config WHERE key = $1", "__write_check__"); err != nil {
+		return fmt.Errorf("database is not writable: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,239 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+)
+
+// The global rollback policy is stored with an empty entity_id, since NULLs
+// are distinct in PostgreSQL unique constraints.
+
+// GetRollbackPolicy implements Storage.GetRollbackPolicy.
+func (p *PostgresStorage) GetRollbackPolicy(ctx context.Context, entityType, entityID string) (RollbackPolicy, bool, error) {
+	query := `
+		SELECT id, entity_type, entity_id, auto_rollback_enabled, health_check_required, created_at, updated_at
+		FROM rollback_policies
+		WHERE entity_type = ? AND entity_id = ?
+	`
+
+	var policy RollbackPolicy
+	err := p.queryRow(ctx, query, entityType, entityID).Scan(
+		&policy.ID, &policy.EntityType, &policy.EntityID,
+		&policy.AutoRollbackEnabled, &policy.HealthCheckRequired,
+		&policy.CreatedAt, &policy.UpdatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return RollbackPolicy{}, false, nil
+	}
+	if err != nil {
+		log.Printf("Failed to query rollback policy for %s/%s: %v", entityType, entityID, err)
+		return RollbackPolicy{}, false, fmt.Errorf("failed to query rollback policy: %w", err)
+	}
+
+	return policy, true, nil
+}
+
+// SetRollbackPolicy implements Storage.SetRollbackPolicy.
+func (p *PostgresStorage) SetRollbackPolicy(ctx context.Context, policy RollbackPolicy) error {
+	query := `
+		INSERT INTO rollback_policies
+		(entity_type, entity_id, auto_rollback_enabled, health_check_required, created_at, updated_at)
+		VALUES (?, ?, ?, ?, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+		ON CONFLICT (entity_type, entity_id) DO UPDATE SET
+			auto_rollback_enabled = excluded.auto_rollback_enabled,
+			health_check_required = excluded.health_check_required,
+			updated_at = excluded.updated_at
+	`
+
+	_, err := p.exec(ctx, query, policy.EntityType, policy.EntityID, policy.AutoRollbackEnabled, policy.HealthCheckRequired)
+	if err != nil {
+		log.Printf("Failed to set rollback policy for %s/%s: %v", policy.EntityType, policy.EntityID, err)
+		return fmt.Errorf("failed to set rollback policy: %w", err)
+	}
+
+	log.Printf("Set rollback policy: type=%s, id=%s, enabled=%v", policy.EntityType, policy.EntityID, policy.AutoRollbackEnabled)
+	return nil
+}
+
+// ListRollbackPolicies implements Storage.ListRollbackPolicies.
+// Retrieves all rollback policies, global first.
+func (p *PostgresStorage) ListRollbackPolicies(ctx context.Context) ([]RollbackPolicy, error) {
+	query := `
+		SELECT id, entity_type, entity_id, auto_rollback_enabled, health_check_required, created_at, updated_at
+		FROM rollback_policies
+		ORDER BY CASE entity_type WHEN 'global' THEN 0 ELSE 1 END, entity_type, entity_id
+	`
+
+	rows, err := p.query(ctx, query)
+	if err != nil {
+		log.Printf("Failed to list rollback policies: %v", err)
+		return nil, fmt.Errorf("failed to list rollback policies: %w", err)
+	}
+	defer rows.Close()
+
+	policies := []RollbackPolicy{}
+	for rows.Next() {
+		var policy RollbackPolicy
+		if err := rows.Scan(
+			&policy.ID, &policy.EntityType, &policy.EntityID,
+			&policy.AutoRollbackEnabled, &policy.HealthCheckRequired,
+			&policy.CreatedAt, &policy.UpdatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan rollback policy: %w", err)
+		}
+		policies = append(policies, policy)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate rollback policies: %w", err)
+	}
+
+	return policies, nil
+}
+
+// GetApprovalPolicy implements Storage.GetApprovalPolicy.
+func (p *PostgresStorage) GetApprovalPolicy(ctx context.Context, entityType, entityID string) (ApprovalPolicy, bool, error) {
+	query := `SELECT ` + approvalPolicyColumns + ` FROM approval_policies WHERE entity_type = ? AND entity_id = ?`
+
+	policy, err := scanApprovalPolicy(p.queryRow(ctx, query, entityType, entityID))
+	if err == sql.ErrNoRows {
+		return ApprovalPolicy{}, false, nil
+	}
+	if err != nil {
+		return ApprovalPolicy{}, false, fmt.Errorf("failed to query approval policy: %w", err)
+	}
+	return policy, true, nil
+}
+
+// SetApprovalPolicy implements Storage.SetApprovalPolicy.
+func (p *PostgresStorage) SetApprovalPolicy(ctx context.Context, policy ApprovalPolicy) error {
+	query := `
+		INSERT INTO approval_policies (entity_type, entity_id, mode, created_at, updated_at)
+		VALUES (?, ?, ?, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+		ON CONFLICT (entity_type, entity_id) DO UPDATE SET
+			mode = excluded.mode,
+			updated_at = excluded.updated_at
+	`
+	if _, err := p.exec(ctx, query, policy.EntityType, policy.EntityID, policy.Mode); err != nil {
+		return fmt.Errorf("failed to set approval policy: %w", err)
+	}
+	log.Printf("Set approval policy: type=%s, id=%s, mode=%s", policy.EntityType, policy.EntityID, policy.Mode)
+	return nil
+}
+
+// ListApprovalPolicies implements Storage.ListApprovalPolicies.
+func (p *PostgresStorage) ListApprovalPolicies(ctx context.Context) ([]ApprovalPolicy, error) {
+	query := `
+		SELECT ` + approvalPolicyColumns + `
+		FROM approval_policies
+		ORDER BY CASE entity_type WHEN 'global' THEN 0 ELSE 1 END, entity_type, entity_id
+	`
+
+	rows, err := p.query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list approval policies: %w", err)
+	}
+	defer rows.Close()
+	return scanApprovalPolicyRows(rows)
+}
+
+// DeleteApprovalPolicy implements Storage.DeleteApprovalPolicy.
+func (p *PostgresStorage) DeleteApprovalPolicy(ctx context.Context, entityType, entityID string) error {
+	if _, err := p.exec(ctx, `DELETE FROM approval_policies WHERE entity_type = ? AND entity_id = ?`, entityType, entityID); err != nil {
+		return fmt.Errorf("failed to delete approval policy: %w", err)
+	}
+	return nil
+}
+
+// ListIgnoreRules implements Storage.ListIgnoreRules.
+func (p *PostgresStorage) ListIgnoreRules(ctx context.Context) ([]IgnoreRule, error) {
+	rows, err := p.query(ctx, `SELECT `+ignoreRuleColumns+` FROM ignore_rules ORDER BY pattern`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ignore rules: %w", err)
+	}
+	defer rows.Close()
+	return scanIgnoreRuleRows(rows)
+}
+
+// SaveIgnoreRule implements Storage.SaveIgnoreRule.
+func (p *PostgresStorage) SaveIgnoreRule(ctx context.Context, rule IgnoreRule) (IgnoreRule, error) {
+	query := `
+		INSERT INTO ignore_rules (pattern, reason, created_at)
+		VALUES (?, ?, CURRENT_TIMESTAMP)
+		ON CONFLICT (pattern) DO UPDATE SET reason = excluded.reason
+		RETURNING ` + ignoreRuleColumns
+	saved, err := scanIgnoreRule(p.queryRow(ctx, query, rule.Pattern, rule.Reason))
+	if err != nil {
+		return IgnoreRule{}, fmt.Errorf("failed to save ignore rule: %w", err)
+	}
+	return saved, nil
+}
+
+// DeleteIgnoreRule implements Storage.DeleteIgnoreRule.
+func (p *PostgresStorage) DeleteIgnoreRule(ctx context.Context, id int64) (bool, error) {
+	result, err := p.exec(ctx, `DELETE FROM ignore_rules WHERE id = ?`, id)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete ignore rule: %w", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to delete ignore rule: %w", err)
+	}
+	return n > 0, nil
+}
+
+// GetStackEnv implements Storage.GetStackEnv.
+func (p *PostgresStorage) GetStackEnv(ctx context.Context, stack string) (StackEnv, bool, error) {
+	env, err := scanStackEnv(p.queryRow(ctx, `SELECT `+stackEnvColumns+` FROM stack_env WHERE stack = ?`, stack))
+	if err == sql.ErrNoRows {
+		return StackEnv{}, false, nil
+	}
+	if err != nil {
+		return StackEnv{}, false, fmt.Errorf("failed to query stack environment: %w", err)
+	}
+	return env, true, nil
+}
+
+// SetStackEnv implements Storage.SetStackEnv.
+func (p *PostgresStorage) SetStackEnv(ctx context.Context, env StackEnv) error {
+	vars, err := encodeStackEnvVars(env)
+	if err != nil {
+		return err
+	}
+	query := `
+		INSERT INTO stack_env (stack, env_file, env, updated_at)
+		VALUES (?, ?, ?, CURRENT_TIMESTAMP)
+		ON CONFLICT (stack) DO UPDATE SET
+			env_file = excluded.env_file,
+			env = excluded.env,
+			updated_at = excluded.updated_at
+	`
+	if _, err := p.exec(ctx, query, env.Stack, env.EnvFile, vars); err != nil {
+		return fmt.Errorf("failed to set stack environment: %w", err)
+	}
+	return nil
+}
+
+// ListStackEnvs implements Storage.ListStackEnvs.
+func (p *PostgresStorage) ListStackEnvs(ctx context.Context) ([]StackEnv, error) {
+	rows, err := p.query(ctx, `SELECT `+stackEnvColumns+` FROM stack_env ORDER BY stack`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list stack environments: %w", err)
+	}
+	defer rows.Close()
+	return scanStackEnvRows(rows)
+}
+
+// DeleteStackEnv implements Storage.DeleteStackEnv.
+func (p *PostgresStorage) DeleteStackEnv(ctx context.Context, stack string) (bool, error) {
+	result, err := p.exec(ctx, `DELETE FROM stack_env WHERE stack = ?`, stack)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete stack environment: %w", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to delete stack environment: %w", err)
+	}
+	return n > 0, nil
+}
@@ -0,0 +1,110 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+)
+
+// SaveProposal implements Storage.SaveProposal.
+func (p *PostgresStorage) SaveProposal(ctx context.Context, proposal Proposal) error {
+	query := `
+		INSERT INTO update_proposals (` + proposalColumns + `)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (id) DO UPDATE SET
+			patch_file = excluded.patch_file,
+			branch = excluded.branch,
+			pull_request_url = excluded.pull_request_url,
+			status = excluded.status,
+			updated_at = excluded.updated_at
+	`
+
+	createdAt := proposal.CreatedAt
+	if createdAt.IsZero() {
+		createdAt = time.Now()
+	}
+
+	_, err := p.exec(ctx, query,
+		proposal.ID, proposal.ContainerName, proposal.StackName, proposal.CurrentVersion,
+		proposal.TargetVersion, proposal.ComposeFile, proposal.Patch, proposal.PatchFile,
+		proposal.Branch, proposal.PullRequestURL, proposal.Status, createdAt, time.Now(),
+	)
+	if err != nil {
+		log.Printf("Failed to save proposal %s: %v", proposal.ID, err)
+		return fmt.Errorf("failed to save proposal: %w", err)
+	}
+
+	log.Printf("Saved proposal: id=%s, container=%s, target=%s, status=%s",
+		proposal.ID, proposal.ContainerName, proposal.TargetVersion, proposal.Status)
+	return nil
+}
+
+// GetProposal implements Storage.GetProposal.
+func (p *PostgresStorage) GetProposal(ctx context.Context, id string) (Proposal, bool, error) {
+	return p.queryProposal(ctx, `SELECT `+proposalColumns+` FROM update_proposals WHERE id = ?`, id)
+}
+
+// GetLatestProposal implements Storage.GetLatestProposal.
+func (p *PostgresStorage) GetLatestProposal(ctx context.Context, containerName string) (Proposal, bool, error) {
+	query := `
+		SELECT ` + proposalColumns + `
+		FROM update_proposals
+		WHERE container_name = ?
+		ORDER BY created_at DESC
+		LIMIT 1
+	`
+	return p.queryProposal(ctx, query, containerName)
+}
+
+// ListProposals implements Storage.ListProposals.
+func (p *PostgresStorage) ListProposals(ctx context.Context, status string, limit int) ([]Proposal, error) {
+	if limit <= 0 {
+		limit = 100
+	}
+
+	query := `SELECT ` + proposalColumns + ` FROM update_proposals`
+	args := []any{}
+	if status != "" {
+		query += ` WHERE status = ?`
+		args = append(args, status)
+	}
+	query += ` ORDER BY created_at DESC LIMIT ?`
+	args = append(args, limit)
+
+	rows, err := p.query(ctx, query, args...)
+	if err != nil {
+		log.Printf("Failed to query proposals: %v", err)
+		return nil, fmt.Errorf("failed to query proposals: %w", err)
+	}
+	defer rows.Close()
+
+	proposals := make([]Proposal, 0)
+	for rows.Next() {
+		proposal, err := scanProposal(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan proposal: %w", err)
+		}
+		proposals = append(proposals, proposal)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating proposal rows: %w", err)
+	}
+
+	return proposals, nil
+}
+
+// queryProposal runs a single-row proposal query.
+func (p *PostgresStorage) queryProposal(ctx context.Context, query string, arg any) (Proposal, bool, error) {
+	proposal, err := scanProposal(p.queryRow(ctx, query, arg))
+	if err == sql.ErrNoRows {
+		return Proposal{}, false, nil
+	}
+	if err != nil {
+		log.Printf("Failed to query proposal %v: %v", arg, err)
+		return Proposal{}, false, fmt.Errorf("failed to query proposal: %w", err)
+	}
+	return proposal, true, nil
+}
@@ -0,0 +1,211 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+)
+
+// queueColumns lists the update_queue columns read by scanQueuedUpdate
+const queueColumns = `id, operation_id, stack_name, containers, operation_type, target_versions, all_or_nothing, priority, queued_at, estimated_start_time`
+
+// QueueUpdate implements Storage.QueueUpdate.
+func (p *PostgresStorage) QueueUpdate(ctx context.Context, queue UpdateQueue) error {
+	containersJSON, err := json.Marshal(queue.Containers)
+	if err != nil {
+		log.Printf("Failed to serialize containers: %v", err)
+		return fmt.Errorf("failed to serialize containers: %w", err)
+	}
+
+	targetVersions := queue.TargetVersions
+	if targetVersions == nil {
+		targetVersions = map[string]string{}
+	}
+	targetVersionsJSON, err := json.Marshal(targetVersions)
+	if err != nil {
+		log.Printf("Failed to serialize target versions: %v", err)
+		return fmt.Errorf("failed to serialize target versions: %w", err)
+	}
+
+	query := `
+		INSERT INTO update_queue
+		(operation_id, stack_name, containers, operation_type, target_versions, all_or_nothing, priority, queued_at, estimated_start_time)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	_, err = p.exec(ctx, query,
+		queue.OperationID, queue.StackName, string(containersJSON),
+		queue.OperationType, string(targetVersionsJSON), queue.AllOrNothing, queue.Priority, queue.QueuedAt, queue.EstimatedStartTime)
+	if err != nil {
+		log.Printf("Failed to queue update for operation %s: %v", queue.OperationID, err)
+		return fmt.Errorf("failed to queue update: %w", err)
+	}
+
+	log.Printf("Queued update: operation=%s, stack=%s, containers=%d", queue.OperationID, queue.StackName, len(queue.Containers))
+	return nil
+}
+
+// DequeueUpdate implements Storage.DequeueUpdate.
+// The row is locked with SKIP LOCKED so two instances never dequeue the same entry.
+func (p *PostgresStorage) DequeueUpdate(ctx context.Context, stackName string) (UpdateQueue, bool, error) {
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("Failed to begin transaction for dequeue: %v", err)
+		return UpdateQueue{}, false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		SELECT ` + queueColumns + `
+		FROM update_queue
+		WHERE stack_name = ?
+		ORDER BY priority DESC, queued_at ASC
+		LIMIT 1
+		FOR UPDATE SKIP LOCKED
+	`
+
+	queue, err := scanQueuedUpdate(tx.QueryRowContext(ctx, rebind(query), stackName))
+	if err == sql.ErrNoRows {
+		return UpdateQueue{}, false, nil
+	}
+	if err != nil {
+		log.Printf("Failed to query queued update for stack %s: %v", stackName, err)
+		return UpdateQueue{}, false, fmt.Errorf("failed to query queued update: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, rebind("DELETE FROM update_queue WHERE id = ?"), queue.ID); err != nil {
+		log.Printf("Failed to delete queued update: %v", err)
+		return UpdateQueue{}, false, fmt.Errorf("failed to delete queued update: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("Failed to commit dequeue transaction: %v", err)
+		return UpdateQueue{}, false, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	log.Printf("Dequeued update: operation=%s, stack=%s", queue.OperationID, queue.StackName)
+	return queue, true, nil
+}
+
+// GetQueuedUpdates implements Storage.GetQueuedUpdates.
+func (p *PostgresStorage) GetQueuedUpdates(ctx context.Context) ([]UpdateQueue, error) {
+	rows, err := p.query(ctx, `SELECT `+queueColumns+` FROM update_queue ORDER BY priority DESC, queued_at ASC`)
+	if err != nil {
+		log.Printf("Failed to query queued updates: %v", err)
+		return nil, fmt.Errorf("failed to query queued updates: %w", err)
+	}
+	defer rows.Close()
+
+	var queues []UpdateQueue
+	for rows.Next() {
+		queue, err := scanQueuedUpdate(rows)
+		if err != nil {
+			return nil, err
+		}
+		queues = append(queues, queue)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating queued updates rows: %w", err)
+	}
+
+	return queues, nil
+}
+
+// scanQueuedUpdate scans a row selected with queueColumns and decodes its JSON fields.
+func scanQueuedUpdate(row interface{ Scan(...any) error }) (UpdateQueue, error) {
+	var queue UpdateQueue
+	var containersJSON, targetVersionsJSON string
+	var estimatedStartTime sql.NullTime
+
+	err := row.Scan(
+		&queue.ID, &queue.OperationID, &queue.StackName, &containersJSON,
+		&queue.OperationType, &targetVersionsJSON, &queue.AllOrNothing, &queue.Priority, &queue.QueuedAt, &estimatedStartTime,
+	)
+	if err != nil {
+		return UpdateQueue{}, err
+	}
+
+	if estimatedStartTime.Valid {
+		queue.EstimatedStartTime = &estimatedStartTime.Time
+	}
+	if err := json.Unmarshal([]byte(containersJSON), &queue.Containers); err != nil {
+		return UpdateQueue{}, fmt.Errorf("failed to deserialize containers: %w", err)
+	}
+	if targetVersionsJSON != "" && targetVersionsJSON != "{}" {
+		if err := json.Unmarshal([]byte(targetVersionsJSON), &queue.TargetVersions); err != nil {
+			return UpdateQueue{}, fmt.Errorf("failed to deserialize target versions: %w", err)
+		}
+	}
+	return queue, nil
+}
+
+// SetQueuePriorities implements Storage.SetQueuePriorities.
+func (p *PostgresStorage) SetQueuePriorities(ctx context.Context, priorities map[string]int) error {
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for operationID, priority := range priorities {
+		if _, err := tx.ExecContext(ctx, rebind(`UPDATE update_queue SET priority = ? WHERE operation_id = ?`), priority, operationID); err != nil {
+			return fmt.Errorf("failed to set queue priority: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// DeleteQueuedUpdate implements Storage.DeleteQueuedUpdate.
+func (p *PostgresStorage) DeleteQueuedUpdate(ctx context.Context, operationID string) (bool, error) {
+	result, err := p.exec(ctx, `DELETE FROM update_queue WHERE operation_id = ?`, operationID)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete queued update: %w", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to delete queued update: %w", err)
+	}
+	return n > 0, nil
+}
+
+// SaveStackLock implements Storage.SaveStackLock.
+func (p *PostgresStorage) SaveStackLock(ctx context.Context, lock StackLock) error {
+	query := `
+		INSERT INTO stack_locks (stack_name, operation_id, acquired_at)
+		VALUES (?, ?, ?)
+		ON CONFLICT (stack_name) DO UPDATE SET operation_id = excluded.operation_id, acquired_at = excluded.acquired_at
+	`
+	if _, err := p.exec(ctx, query, lock.StackName, lock.OperationID, lock.AcquiredAt); err != nil {
+		return fmt.Errorf("failed to save stack lock: %w", err)
+	}
+	return nil
+}
+
+// DeleteStackLock implements Storage.DeleteStackLock.
+func (p *PostgresStorage) DeleteStackLock(ctx context.Context, stackName, operationID string) error {
+	query, args := `DELETE FROM stack_locks WHERE stack_name = ?`, []any{stackName}
+	if operationID != "" {
+		query, args = query+` AND operation_id = ?`, append(args, operationID)
+	}
+	if _, err := p.exec(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to delete stack lock: %w", err)
+	}
+	return nil
+}
+
+// ListStackLocks implements Storage.ListStackLocks.
+func (p *PostgresStorage) ListStackLocks(ctx context.Context) ([]StackLock, error) {
+	rows, err := p.query(ctx, `SELECT stack_name, operation_id, acquired_at FROM stack_locks ORDER BY stack_name`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list stack locks: %w", err)
+	}
+	defer rows.Close()
+	return scanStackLocks(rows)
+}
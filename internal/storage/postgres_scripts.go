@@ -0,0 +1,210 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+)
+
+// SaveScriptAssignment implements Storage.SaveScriptAssignment.
+// The original assigned_at is kept when an existing assignment is replaced.
+func (p *PostgresStorage) SaveScriptAssignment(ctx context.Context, assignment ScriptAssignment) error {
+	query := `
+		INSERT INTO script_assignments
+		(container_name, script_path, enabled, ignore, allow_latest, assigned_by, assigned_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+		ON CONFLICT (container_name) DO UPDATE SET
+			script_path = excluded.script_path,
+			enabled = excluded.enabled,
+			ignore = excluded.ignore,
+			allow_latest = excluded.allow_latest,
+			assigned_by = excluded.assigned_by,
+			updated_at = excluded.updated_at
+	`
+
+	_, err := p.exec(ctx, query,
+		assignment.ContainerName, assignment.ScriptPath, assignment.Enabled, assignment.Ignore, assignment.AllowLatest, assignment.AssignedBy)
+	if err != nil {
+		log.Printf("Failed to save container settings for %s: %v", assignment.ContainerName, err)
+		return fmt.Errorf("failed to save container settings: %w", err)
+	}
+
+	log.Printf("Saved container settings: container=%s, script=%s, ignore=%v, allow_latest=%v",
+		assignment.ContainerName, assignment.ScriptPath, assignment.Ignore, assignment.AllowLatest)
+	return nil
+}
+
+// GetScriptAssignment implements Storage.GetScriptAssignment.
+func (p *PostgresStorage) GetScriptAssignment(ctx context.Context, containerName string) (ScriptAssignment, bool, error) {
+	query := `
+		SELECT id, container_name, script_path, enabled, ignore, allow_latest, assigned_at, assigned_by, updated_at
+		FROM script_assignments
+		WHERE container_name = ?
+	`
+
+	var assignment ScriptAssignment
+	var assignedBy sql.NullString
+
+	err := p.queryRow(ctx, query, containerName).Scan(
+		&assignment.ID, &assignment.ContainerName, &assignment.ScriptPath,
+		&assignment.Enabled, &assignment.Ignore, &assignment.AllowLatest, &assignment.AssignedAt, &assignedBy, &assignment.UpdatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return ScriptAssignment{}, false, nil
+	}
+	if err != nil {
+		log.Printf("Failed to query script assignment for %s: %v", containerName, err)
+		return ScriptAssignment{}, false, fmt.Errorf("failed to query script assignment: %w", err)
+	}
+
+	assignment.AssignedBy = assignedBy.String
+	return assignment, true, nil
+}
+
+// ListScriptAssignments implements Storage.ListScriptAssignments.
+func (p *PostgresStorage) ListScriptAssignments(ctx context.Context, enabledOnly bool) ([]ScriptAssignment, error) {
+	query := `
+		SELECT id, container_name, script_path, enabled, ignore, allow_latest, assigned_at, assigned_by, updated_at
+		FROM script_assignments
+	`
+	if enabledOnly {
+		query += ` WHERE enabled`
+	}
+	query += ` ORDER BY container_name`
+
+	rows, err := p.query(ctx, query)
+	if err != nil {
+		log.Printf("Failed to query container settings: %v", err)
+		return nil, fmt.Errorf("failed to query container settings: %w", err)
+	}
+	defer rows.Close()
+
+	return scanScriptAssignmentRows(rows)
+}
+
+// DeleteScriptAssignment implements Storage.DeleteScriptAssignment.
+func (p *PostgresStorage) DeleteScriptAssignment(ctx context.Context, containerName string) error {
+	result, err := p.exec(ctx, `DELETE FROM script_assignments WHERE container_name = ?`, containerName)
+	if err != nil {
+		log.Printf("Failed to delete script assignment for %s: %v", containerName, err)
+		return fmt.Errorf("failed to delete script assignment: %w", err)
+	}
+
+	if rowsAffected, _ := result.RowsAffected(); rowsAffected == 0 {
+		return fmt.Errorf("no script assignment found for container %s", containerName)
+	}
+
+	log.Printf("Deleted script assignment for container: %s", containerName)
+	return nil
+}
+
+// SaveScriptRevision implements Storage.SaveScriptRevision.
+func (p *PostgresStorage) SaveScriptRevision(ctx context.Context, revision ScriptRevision) error {
+	query := `
+		INSERT INTO script_revisions
+		(name, revision, sha256, size, timeout_seconds, no_network, memory_limit_mb, cpu_seconds, created_by)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	_, err := p.exec(ctx, query,
+		revision.Name, revision.Revision, revision.SHA256, revision.Size, revision.TimeoutSeconds,
+		revision.NoNetwork, revision.MemoryLimitMB, revision.CPUSeconds, revision.CreatedBy)
+	if err != nil {
+		log.Printf("Failed to save script revision %s@%d: %v", revision.Name, revision.Revision, err)
+		return fmt.Errorf("failed to save script revision: %w", err)
+	}
+	return nil
+}
+
+// GetScriptRevision implements Storage.GetScriptRevision.
+func (p *PostgresStorage) GetScriptRevision(ctx context.Context, name string, revision int) (ScriptRevision, bool, error) {
+	query := `SELECT ` + scriptRevisionColumns + ` FROM script_revisions
+		WHERE name = ? AND (?::INTEGER = 0 OR revision = ?)
+		ORDER BY revision DESC LIMIT 1`
+
+	rev, err := scanScriptRevision(p.queryRow(ctx, query, name, revision, revision))
+	if err == sql.ErrNoRows {
+		return ScriptRevision{}, false, nil
+	}
+	if err != nil {
+		log.Printf("Failed to query script revision %s@%d: %v", name, revision, err)
+		return ScriptRevision{}, false, fmt.Errorf("failed to query script revision: %w", err)
+	}
+	return rev, true, nil
+}
+
+// ListScriptRevisions implements Storage.ListScriptRevisions.
+func (p *PostgresStorage) ListScriptRevisions(ctx context.Context, name string) ([]ScriptRevision, error) {
+	query := `SELECT ` + scriptRevisionColumns + ` FROM script_revisions
+		WHERE ?::TEXT = '' OR name = ?
+		ORDER BY name, revision DESC`
+
+	rows, err := p.query(ctx, query, name, name)
+	if err != nil {
+		log.Printf("Failed to query script revisions: %v", err)
+		return nil, fmt.Errorf("failed to query script revisions: %w", err)
+	}
+	defer rows.Close()
+
+	return scanScriptRevisionRows(rows)
+}
+
+// SaveVolumeSnapshot implements Storage.SaveVolumeSnapshot.
+func (p *PostgresStorage) SaveVolumeSnapshot(ctx context.Context, snapshot VolumeSnapshot) error {
+	query := `
+		INSERT INTO volume_snapshots
+		(operation_id, container_name, volume_name, method, location, size_bytes)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`
+
+	_, err := p.exec(ctx, query,
+		snapshot.OperationID, snapshot.ContainerName, snapshot.VolumeName,
+		snapshot.Method, snapshot.Location, snapshot.SizeBytes)
+	if err != nil {
+		log.Printf("Failed to save snapshot of volume %s: %v", snapshot.VolumeName, err)
+		return fmt.Errorf("failed to save volume snapshot: %w", err)
+	}
+	return nil
+}
+
+// GetVolumeSnapshots implements Storage.GetVolumeSnapshots.
+func (p *PostgresStorage) GetVolumeSnapshots(ctx context.Context, operationID string) ([]VolumeSnapshot, error) {
+	query := `SELECT ` + volumeSnapshotColumns + ` FROM volume_snapshots
+		WHERE operation_id = ?
+		ORDER BY id`
+
+	rows, err := p.query(ctx, query, operationID)
+	if err != nil {
+		log.Printf("Failed to query volume snapshots of operation %s: %v", operationID, err)
+		return nil, fmt.Errorf("failed to query volume snapshots: %w", err)
+	}
+	defer rows.Close()
+
+	return scanVolumeSnapshotRows(rows)
+}
+
+// ListVolumeSnapshots implements Storage.ListVolumeSnapshots.
+func (p *PostgresStorage) ListVolumeSnapshots(ctx context.Context, volumeName string) ([]VolumeSnapshot, error) {
+	query := `SELECT ` + volumeSnapshotColumns + ` FROM volume_snapshots
+		WHERE ?::TEXT = '' OR volume_name = ?
+		ORDER BY created_at DESC, id DESC`
+
+	rows, err := p.query(ctx, query, volumeName, volumeName)
+	if err != nil {
+		log.Printf("Failed to query volume snapshots: %v", err)
+		return nil, fmt.Errorf("failed to query volume snapshots: %w", err)
+	}
+	defer rows.Close()
+
+	return scanVolumeSnapshotRows(rows)
+}
+
+// DeleteVolumeSnapshot implements Storage.DeleteVolumeSnapshot.
+func (p *PostgresStorage) DeleteVolumeSnapshot(ctx context.Context, id int64) error {
+	if _, err := p.exec(ctx, `DELETE FROM volume_snapshots WHERE id = ?`, id); err != nil {
+		log.Printf("Failed to delete volume snapshot %d: %v", id, err)
+		return fmt.Errorf("failed to delete volume snapshot: %w", err)
+	}
+	return nil
+}
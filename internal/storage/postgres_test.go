@@ -0,0 +1,54 @@
+package storage
+
+import (
+	"database/sql"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestRebind(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"SELECT 1", "SELECT 1"},
+		{"SELECT * FROM config WHERE key = ?", "SELECT * FROM config WHERE key = $1"},
+		{"INSERT INTO t (a, b, c) VALUES (?, ?, ?)", "INSERT INTO t (a, b, c) VALUES ($1, $2, $3)"},
+		{"SELECT * FROM t WHERE a = '?' AND b = ?", "SELECT * FROM t WHERE a = '?' AND b = $1"},
+		{"SELECT * FROM t WHERE a = 'it''s?' AND b = ?", "SELECT * FROM t WHERE a = 'it''s?' AND b = $1"},
+		{`SELECT "col?" FROM t WHERE a = ?`, `SELECT "col?" FROM t WHERE a = $1`},
+	}
+
+	for _, tt := range tests {
+		if got := rebind(tt.query); got != tt.want {
+			t.Errorf("rebind(%q) = %q, want %q", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestNewPostgresStorageErrors(t *testing.T) {
+	if _, err := NewPostgresStorage(""); err == nil || !strings.Contains(err.Error(), "DB_DSN") {
+		t.Errorf("Expected DB_DSN error, got %v", err)
+	}
+	if !slices.Contains(sql.Drivers(), postgresDriverName) {
+		t.Errorf("Expected the %s driver to be registered, got %v", postgresDriverName, sql.Drivers())
+	}
+}
+
+func TestPostgresMigrationsHaveDownFiles(t *testing.T) {
+	entries, err := postgresMigrationsFS.ReadDir("migrations_postgres")
+	if err != nil {
+		t.Fatalf("Failed to read migrations: %v", err)
+	}
+
+	names := make(map[string]bool)
+	for _, entry := range entries {
+		names[entry.Name()] = true
+	}
+	for name := range names {
+		if up, ok := strings.CutSuffix(name, ".up.sql"); ok && !names[up+".down.sql"] {
+			t.Errorf("Migration %s has no down file", name)
+		}
+	}
+}
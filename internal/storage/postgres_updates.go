@@ -0,0 +1,445 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+// updateOperationColumns lists the update_operations columns read by scanUpdateOperationRows
+const updateOperationColumns = `id, operation_id, container_id, container_name, stack_name, operation_type, status,
+	old_version, new_version, started_at, completed_at, error_message,
+	dependents_affected, rollback_occurred, batch_details, batch_group_id, check_output, all_or_nothing, signature_verifications, observations, compose_output, triggered_by, created_at, updated_at`
+
+// LogUpdate implements Storage.LogUpdate.
+func (p *PostgresStorage) LogUpdate(ctx context.Context, containerName, operation, fromVer, toVer string, success bool, updateErr error) error {
+	switch operation {
+	case "pull", "restart", "rollback":
+	default:
+		return fmt.Errorf("invalid operation: %s (must be one of: pull, restart, rollback)", operation)
+	}
+
+	query := `
+		INSERT INTO update_log
+		(container_name, operation, from_version, to_version, success, error)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`
+
+	var errorMsg string
+	if updateErr != nil {
+		errorMsg = updateErr.Error()
+	}
+
+	if _, err := p.exec(ctx, query, containerName, operation, fromVer, toVer, success, errorMsg); err != nil {
+		log.Printf("Failed to log update for %s: %v", containerName, err)
+		return fmt.Errorf("failed to log update: %w", err)
+	}
+
+	log.Printf("Logged update: %s [%s] %s -> %s (success: %v)", containerName, operation, fromVer, toVer, success)
+	return nil
+}
+
+// GetUpdateLog implements Storage.GetUpdateLog.
+func (p *PostgresStorage) GetUpdateLog(ctx context.Context, containerName string, limit int) ([]UpdateLogEntry, error) {
+	query := `
+		SELECT id, container_name, operation, from_version, to_version, timestamp, success, error
+		FROM update_log
+		WHERE container_name = ?
+		ORDER BY timestamp DESC
+		LIMIT ?
+	`
+
+	rows, err := p.query(ctx, query, containerName, limit)
+	if err != nil {
+		log.Printf("Failed to query update log for %s: %v", containerName, err)
+		return nil, fmt.Errorf("failed to query update log: %w", err)
+	}
+	defer rows.Close()
+
+	return scanUpdateLogRows(rows)
+}
+
+// GetAllUpdateLog implements Storage.GetAllUpdateLog.
+func (p *PostgresStorage) GetAllUpdateLog(ctx context.Context, limit int) ([]UpdateLogEntry, error) {
+	baseQuery := `
+		SELECT id, container_name, operation, from_version, to_version, timestamp, success, error
+		FROM update_log
+		ORDER BY timestamp DESC
+	`
+	query, args := withLimit(baseQuery, nil, limit)
+
+	rows, err := p.query(ctx, query, args...)
+	if err != nil {
+		log.Printf("Failed to query all update log: %v", err)
+		return nil, fmt.Errorf("failed to query all update log: %w", err)
+	}
+	defer rows.Close()
+
+	return scanUpdateLogRows(rows)
+}
+
+// SaveUpdateOperation implements Storage.SaveUpdateOperation.
+// Creates or updates an operation, keeping the original created_at.
+func (p *PostgresStorage) SaveUpdateOperation(ctx context.Context, op UpdateOperation) error {
+	dependentsJSON, err := json.Marshal(op.DependentsAffected)
+	if err != nil {
+		log.Printf("Failed to serialize dependents affected: %v", err)
+		return fmt.Errorf("failed to serialize dependents affected: %w", err)
+	}
+
+	var batchDetailsJSON []byte
+	if len(op.BatchDetails) > 0 {
+		batchDetailsJSON, err = json.Marshal(op.BatchDetails)
+		if err != nil {
+			log.Printf("Failed to serialize batch details: %v", err)
+			return fmt.Errorf("failed to serialize batch details: %w", err)
+		}
+	}
+
+	var signaturesJSON []byte
+	if len(op.SignatureVerifications) > 0 {
+		signaturesJSON, err = json.Marshal(op.SignatureVerifications)
+		if err != nil {
+			log.Printf("Failed to serialize signature verifications: %v", err)
+			return fmt.Errorf("failed to serialize signature verifications: %w", err)
+		}
+	}
+
+	var observationsJSON []byte
+	if len(op.Observations) > 0 {
+		observationsJSON, err = json.Marshal(op.Observations)
+		if err != nil {
+			log.Printf("Failed to serialize observations: %v", err)
+			return fmt.Errorf("failed to serialize observations: %w", err)
+		}
+	}
+
+	query := `
+		INSERT INTO update_operations
+		(operation_id, container_id, container_name, stack_name, operation_type, status,
+		 old_version, new_version, started_at, completed_at, error_message,
+		 dependents_affected, rollback_occurred, batch_details, batch_group_id, check_output, all_or_nothing, signature_verifications, observations, triggered_by, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, NULLIF(?, ''), CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+		ON CONFLICT (operation_id) DO UPDATE SET
+			container_id = excluded.container_id,
+			container_name = excluded.container_name,
+			stack_name = excluded.stack_name,
+			operation_type = excluded.operation_type,
+			status = excluded.status,
+			old_version = excluded.old_version,
+			new_version = excluded.new_version,
+			started_at = excluded.started_at,
+			completed_at = excluded.completed_at,
+			error_message = excluded.error_message,
+			dependents_affected = excluded.dependents_affected,
+			rollback_occurred = excluded.rollback_occurred,
+			batch_details = excluded.batch_details,
+			batch_group_id = excluded.batch_group_id,
+			check_output = excluded.check_output,
+			all_or_nothing = excluded.all_or_nothing,
+			signature_verifications = excluded.signature_verifications,
+			observations = excluded.observations,
+			triggered_by = COALESCE(excluded.triggered_by, update_operations.triggered_by),
+			updated_at = excluded.updated_at
+	`
+
+	_, err = p.exec(ctx, query,
+		op.OperationID, op.ContainerID, op.ContainerName, op.StackName, op.OperationType, op.Status,
+		op.OldVersion, op.NewVersion, op.StartedAt, op.CompletedAt, op.ErrorMessage,
+		string(dependentsJSON), op.RollbackOccurred, string(batchDetailsJSON), op.BatchGroupID, op.CheckOutput, op.AllOrNothing, string(signaturesJSON), string(observationsJSON), op.TriggeredBy)
+	if err != nil {
+		log.Printf("Failed to save update operation %s: %v", op.OperationID, err)
+		return fmt.Errorf("failed to save update operation: %w", err)
+	}
+
+	log.Printf("Saved update operation: %s [%s] %s -> %s (status: %s)", op.OperationID, op.ContainerName, op.OldVersion, op.NewVersion, op.Status)
+	return nil
+}
+
+// GetUpdateOperation implements Storage.GetUpdateOperation.
+func (p *PostgresStorage) GetUpdateOperation(ctx context.Context, operationID string) (UpdateOperation, bool, error) {
+	operations, err := p.queryUpdateOperations(ctx, `WHERE operation_id = ?`, operationID)
+	if err != nil {
+		log.Printf("Failed to query update operation %s: %v", operationID, err)
+		return UpdateOperation{}, false, fmt.Errorf("failed to query update operation: %w", err)
+	}
+	if len(operations) == 0 {
+		return UpdateOperation{}, false, nil
+	}
+	return operations[0], true, nil
+}
+
+// GetUpdateOperationsByStatus implements Storage.GetUpdateOperationsByStatus.
+func (p *PostgresStorage) GetUpdateOperationsByStatus(ctx context.Context, status string, limit int) ([]UpdateOperation, error) {
+	clause, args := withLimit(`WHERE status = ? ORDER BY created_at DESC`, []interface{}{status}, limit)
+	operations, err := p.queryUpdateOperations(ctx, clause, args...)
+	if err != nil {
+		log.Printf("Failed to query update operations by status %s: %v", status, err)
+		return nil, fmt.Errorf("failed to query update operations by status: %w", err)
+	}
+	return operations, nil
+}
+
+// GetUnfinishedOperations implements Storage.GetUnfinishedOperations.
+func (p *PostgresStorage) GetUnfinishedOperations(ctx context.Context) ([]UpdateOperation, error) {
+	operations, err := p.queryUpdateOperations(ctx, `WHERE status NOT IN ('complete', 'failed', 'cancelled') ORDER BY created_at ASC`)
+	if err != nil {
+		log.Printf("Failed to query unfinished update operations: %v", err)
+		return nil, fmt.Errorf("failed to query unfinished update operations: %w", err)
+	}
+	return operations, nil
+}
+
+// GetUpdateOperationsByContainer implements Storage.GetUpdateOperationsByContainer.
+func (p *PostgresStorage) GetUpdateOperationsByContainer(ctx context.Context, containerName string, limit int) ([]UpdateOperation, error) {
+	clause, args := withLimit(`WHERE container_name = ? ORDER BY started_at DESC NULLS LAST`, []interface{}{containerName}, limit)
+	operations, err := p.queryUpdateOperations(ctx, clause, args...)
+	if err != nil {
+		log.Printf("Failed to query update operations for %s: %v", containerName, err)
+		return nil, fmt.Errorf("failed to query update operations: %w", err)
+	}
+	return operations, nil
+}
+
+// GetUpdateOperationsByTimeRange implements Storage.GetUpdateOperationsByTimeRange.
+func (p *PostgresStorage) GetUpdateOperationsByTimeRange(ctx context.Context, start, end time.Time) ([]UpdateOperation, error) {
+	operations, err := p.queryUpdateOperations(ctx, `WHERE started_at >= ? AND started_at <= ? ORDER BY started_at DESC`, start, end)
+	if err != nil {
+		log.Printf("Failed to query update operations by time range: %v", err)
+		return nil, fmt.Errorf("failed to query update operations by time range: %w", err)
+	}
+	return operations, nil
+}
+
+// GetUpdateOperationsByBatchGroup implements Storage.GetUpdateOperationsByBatchGroup.
+func (p *PostgresStorage) GetUpdateOperationsByBatchGroup(ctx context.Context, batchGroupID string) ([]UpdateOperation, error) {
+	operations, err := p.queryUpdateOperations(ctx, `WHERE batch_group_id = ? ORDER BY started_at ASC NULLS FIRST`, batchGroupID)
+	if err != nil {
+		log.Printf("Failed to query update operations by batch group %s: %v", batchGroupID, err)
+		return nil, fmt.Errorf("failed to query update operations by batch group: %w", err)
+	}
+	return operations, nil
+}
+
+// UpdateOperationStatus implements Storage.UpdateOperationStatus.
+func (p *PostgresStorage) UpdateOperationStatus(ctx context.Context, operationID string, status string, errorMsg string) error {
+	query := `
+		UPDATE update_operations
+		SET status = ?, error_message = ?, updated_at = CURRENT_TIMESTAMP
+		WHERE operation_id = ?
+	`
+
+	result, err := p.exec(ctx, query, status, errorMsg, operationID)
+	if err != nil {
+		log.Printf("Failed to update operation status for %s: %v", operationID, err)
+		return fmt.Errorf("failed to update operation status: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if affected == 0 {
+		return fmt.Errorf("operation %s not found", operationID)
+	}
+
+	log.Printf("Updated operation status: %s -> %s", operationID, status)
+	return nil
+}
+
+// AppendComposeOutput implements Storage.AppendComposeOutput.
+func (p *PostgresStorage) AppendComposeOutput(ctx context.Context, operationID string, output string) error {
+	query := `
+		UPDATE update_operations
+		SET compose_output = right(COALESCE(compose_output, '') || ?, ?), updated_at = CURRENT_TIMESTAMP
+		WHERE operation_id = ?
+	`
+
+	if _, err := p.exec(ctx, query, output, MaxComposeOutput, operationID); err != nil {
+		log.Printf("Failed to append compose output for %s: %v", operationID, err)
+		return fmt.Errorf("failed to append compose output: %w", err)
+	}
+	return nil
+}
+
+// GetUpdateOperations implements Storage.GetUpdateOperations.
+// Only completed or failed operations are returned.
+func (p *PostgresStorage) GetUpdateOperations(ctx context.Context, limit int) ([]UpdateOperation, error) {
+	clause, args := withLimit(`WHERE status IN ('complete', 'failed') ORDER BY started_at DESC NULLS LAST`, nil, limit)
+	operations, err := p.queryUpdateOperations(ctx, clause, args...)
+	if err != nil {
+		log.Printf("Failed to query update operations: %v", err)
+		return nil, fmt.Errorf("failed to query update operations: %w", err)
+	}
+	return operations, nil
+}
+
+// QueryUpdateOperations implements Storage.QueryUpdateOperations.
+func (p *PostgresStorage) QueryUpdateOperations(ctx context.Context, opts OperationQueryOptions) (OperationQueryResult, error) {
+	var conditions []string
+	var args []interface{}
+
+	if opts.Status != "" {
+		conditions = append(conditions, "status = ?")
+		args = append(args, opts.Status)
+	} else {
+		conditions = append(conditions, "status IN ('complete', 'failed')")
+	}
+	if opts.Container != "" {
+		conditions = append(conditions, "container_name = ?")
+		args = append(args, opts.Container)
+	}
+	if opts.Stack != "" {
+		conditions = append(conditions, "stack_name = ?")
+		args = append(args, opts.Stack)
+	}
+	if opts.Type != "" {
+		if opts.Type == "updates" {
+			conditions = append(conditions, "operation_type IN ('single', 'batch', 'stack', 'pin', 'variant')")
+		} else {
+			conditions = append(conditions, "operation_type = ?")
+			args = append(args, opts.Type)
+		}
+	}
+	if opts.DateFrom != nil {
+		conditions = append(conditions, "started_at >= ?")
+		args = append(args, *opts.DateFrom)
+	}
+	if opts.DateTo != nil {
+		conditions = append(conditions, "started_at <= ?")
+		args = append(args, *opts.DateTo)
+	}
+	if opts.Cursor != "" {
+		if cursorTime, err := time.Parse(time.RFC3339Nano, opts.Cursor); err == nil {
+			conditions = append(conditions, "started_at < ?")
+			args = append(args, cursorTime)
+		}
+	}
+
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = 20
+	}
+
+	clause := "WHERE " + strings.Join(conditions, " AND ") + " ORDER BY started_at DESC NULLS LAST LIMIT ?"
+	args = append(args, limit+1) // Fetch one extra to detect hasMore
+
+	operations, err := p.queryUpdateOperations(ctx, clause, args...)
+	if err != nil {
+		log.Printf("Failed to query update operations: %v", err)
+		return OperationQueryResult{}, fmt.Errorf("failed to query update operations: %w", err)
+	}
+
+	result := OperationQueryResult{}
+	if len(operations) > limit {
+		result.HasMore = true
+		operations = operations[:limit]
+		if last := operations[limit-1]; last.StartedAt != nil {
+			result.NextCursor = last.StartedAt.Format(time.RFC3339Nano)
+		}
+	}
+	result.Operations = operations
+
+	return result, nil
+}
+
+// DeleteAllHistory implements Storage.DeleteAllHistory.
+func (p *PostgresStorage) DeleteAllHistory(ctx context.Context) (int64, error) {
+	return p.deleteHistory(ctx, "", nil)
+}
+
+// DeleteHistoryBefore implements Storage.DeleteHistoryBefore.
+func (p *PostgresStorage) DeleteHistoryBefore(ctx context.Context, before time.Time) (int64, error) {
+	return p.deleteHistory(ctx, before.Format(time.RFC3339), &before)
+}
+
+// deleteHistory deletes finished operations, check history, and the update
+// log in one transaction, optionally only rows older than before
+func (p *PostgresStorage) deleteHistory(ctx context.Context, label string, before *time.Time) (int64, error) {
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	statements := []struct{ query, column, what string }{
+		{"DELETE FROM update_operations WHERE status IN ('complete', 'failed')", "started_at", "operations"},
+		{"DELETE FROM check_history WHERE TRUE", "check_time", "check history"},
+		{"DELETE FROM update_log WHERE TRUE", "timestamp", "update log"},
+	}
+
+	counts := make([]int64, len(statements))
+	for i, stmt := range statements {
+		query, args := stmt.query, []any{}
+		if before != nil {
+			query += " AND " + stmt.column + " < $1"
+			args = append(args, *before)
+		}
+		result, err := tx.ExecContext(ctx, query, args...)
+		if err != nil {
+			return 0, fmt.Errorf("failed to delete %s: %w", stmt.what, err)
+		}
+		counts[i], _ = result.RowsAffected()
+	}
+
+	// Step logs go with their operations
+	if _, err := tx.ExecContext(ctx, deleteOrphanedOperationLogs); err != nil {
+		return 0, fmt.Errorf("failed to delete operation logs: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit: %w", err)
+	}
+
+	if before != nil {
+		log.Printf("Deleted history before %s: %d operations, %d checks, %d logs", label, counts[0], counts[1], counts[2])
+	} else {
+		log.Printf("Deleted all history: %d operations, %d checks, %d logs", counts[0], counts[1], counts[2])
+	}
+	return counts[0] + counts[1] + counts[2], nil
+}
+
+// queryUpdateOperations selects update operations with the given WHERE/ORDER clause
+func (p *PostgresStorage) queryUpdateOperations(ctx context.Context, clause string, args ...any) ([]UpdateOperation, error) {
+	rows, err := p.query(ctx, `SELECT `+updateOperationColumns+` FROM update_operations `+clause, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanUpdateOperationRows(rows)
+}
+
+// AppendOperationLog implements Storage.AppendOperationLog.
+func (p *PostgresStorage) AppendOperationLog(ctx context.Context, entry OperationLogEntry) (int64, error) {
+	query := `
+		INSERT INTO operation_logs (operation_id, container_name, source, message)
+		VALUES (?, ?, ?, ?)
+		RETURNING id
+	`
+
+	var id int64
+	if err := p.queryRow(ctx, query, entry.OperationID, entry.ContainerName, entry.Source, entry.Message).Scan(&id); err != nil {
+		return 0, fmt.Errorf("failed to append operation log: %w", err)
+	}
+	return id, nil
+}
+
+// GetOperationLogs implements Storage.GetOperationLogs.
+func (p *PostgresStorage) GetOperationLogs(ctx context.Context, operationID string, afterID int64) ([]OperationLogEntry, error) {
+	query := `SELECT ` + operationLogColumns + ` FROM operation_logs
+		WHERE operation_id = ? AND id > ?
+		ORDER BY id`
+
+	rows, err := p.query(ctx, query, operationID, afterID)
+	if err != nil {
+		log.Printf("Failed to query logs of operation %s: %v", operationID, err)
+		return nil, fmt.Errorf("failed to query operation logs: %w", err)
+	}
+	defer rows.Close()
+
+	return scanOperationLogRows(rows)
+}
@@ -0,0 +1,198 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+)
+
+// CreateUser implements Storage.CreateUser.
+func (p *PostgresStorage) CreateUser(ctx context.Context, user User) (User, error) {
+	query := `
+		INSERT INTO users (username, password_hash, role, created_at, updated_at)
+		VALUES (?, ?, ?, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+		RETURNING id
+	`
+
+	if err := p.queryRow(ctx, query, user.Username, user.PasswordHash, user.Role).Scan(&user.ID); err != nil {
+		log.Printf("Failed to create user %s: %v", user.Username, err)
+		return User{}, fmt.Errorf("failed to create user: %w", err)
+	}
+
+	log.Printf("Created user: username=%s, role=%s", user.Username, user.Role)
+	created, _, err := p.GetUserByID(ctx, user.ID)
+	return created, err
+}
+
+// GetUser implements Storage.GetUser.
+func (p *PostgresStorage) GetUser(ctx context.Context, username string) (User, bool, error) {
+	return p.queryUser(ctx, `SELECT id, username, password_hash, role, created_at, updated_at FROM users WHERE username = ?`, username)
+}
+
+// GetUserByID implements Storage.GetUserByID.
+func (p *PostgresStorage) GetUserByID(ctx context.Context, id int64) (User, bool, error) {
+	return p.queryUser(ctx, `SELECT id, username, password_hash, role, created_at, updated_at FROM users WHERE id = ?`, id)
+}
+
+// queryUser runs a single-row user query.
+func (p *PostgresStorage) queryUser(ctx context.Context, query string, arg any) (User, bool, error) {
+	var user User
+	err := p.queryRow(ctx, query, arg).Scan(
+		&user.ID, &user.Username, &user.PasswordHash, &user.Role, &user.CreatedAt, &user.UpdatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return User{}, false, nil
+	}
+	if err != nil {
+		log.Printf("Failed to query user %v: %v", arg, err)
+		return User{}, false, fmt.Errorf("failed to query user: %w", err)
+	}
+	return user, true, nil
+}
+
+// ListUsers implements Storage.ListUsers.
+func (p *PostgresStorage) ListUsers(ctx context.Context) ([]User, error) {
+	rows, err := p.query(ctx, `SELECT id, username, password_hash, role, created_at, updated_at FROM users ORDER BY username`)
+	if err != nil {
+		log.Printf("Failed to query users: %v", err)
+		return nil, fmt.Errorf("failed to query users: %w", err)
+	}
+	defer rows.Close()
+
+	users := make([]User, 0)
+	for rows.Next() {
+		var user User
+		if err := rows.Scan(&user.ID, &user.Username, &user.PasswordHash, &user.Role, &user.CreatedAt, &user.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan user: %w", err)
+		}
+		users = append(users, user)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating user rows: %w", err)
+	}
+
+	return users, nil
+}
+
+// UpdateUser implements Storage.UpdateUser.
+func (p *PostgresStorage) UpdateUser(ctx context.Context, user User) error {
+	query := `
+		UPDATE users
+		SET role = ?, password_hash = ?, updated_at = CURRENT_TIMESTAMP
+		WHERE username = ?
+	`
+
+	result, err := p.exec(ctx, query, user.Role, user.PasswordHash, user.Username)
+	if err != nil {
+		log.Printf("Failed to update user %s: %v", user.Username, err)
+		return fmt.Errorf("failed to update user: %w", err)
+	}
+
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return fmt.Errorf("user %s not found", user.Username)
+	}
+
+	log.Printf("Updated user: username=%s, role=%s", user.Username, user.Role)
+	return nil
+}
+
+// DeleteUser implements Storage.DeleteUser.
+// Sessions are removed by the ON DELETE CASCADE on sessions.user_id; team
+// memberships and ownerships are removed in the same transaction.
+func (p *PostgresStorage) DeleteUser(ctx context.Context, username string) error {
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, rebind(`DELETE FROM team_members WHERE username = ?`), username); err != nil {
+		return fmt.Errorf("failed to delete user team memberships: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, rebind(`DELETE FROM ownership WHERE owner = ?`), "user:"+username); err != nil {
+		return fmt.Errorf("failed to delete user ownerships: %w", err)
+	}
+
+	result, err := tx.ExecContext(ctx, rebind(`DELETE FROM users WHERE username = ?`), username)
+	if err != nil {
+		log.Printf("Failed to delete user %s: %v", username, err)
+		return fmt.Errorf("failed to delete user: %w", err)
+	}
+
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return fmt.Errorf("user %s not found", username)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	log.Printf("Deleted user: %s", username)
+	return nil
+}
+
+// SaveSession implements Storage.SaveSession.
+func (p *PostgresStorage) SaveSession(ctx context.Context, session Session) error {
+	query := `
+		INSERT INTO sessions (token_hash, user_id, created_at, expires_at)
+		VALUES (?, ?, CURRENT_TIMESTAMP, ?)
+		ON CONFLICT (token_hash) DO UPDATE SET
+			user_id = excluded.user_id,
+			created_at = excluded.created_at,
+			expires_at = excluded.expires_at
+	`
+
+	if _, err := p.exec(ctx, query, session.TokenHash, session.UserID, session.ExpiresAt); err != nil {
+		log.Printf("Failed to save session for user %d: %v", session.UserID, err)
+		return fmt.Errorf("failed to save session: %w", err)
+	}
+	return nil
+}
+
+// GetSession implements Storage.GetSession.
+// Returns false for unknown or expired sessions.
+func (p *PostgresStorage) GetSession(ctx context.Context, tokenHash string) (Session, bool, error) {
+	query := `
+		SELECT token_hash, user_id, created_at, expires_at
+		FROM sessions
+		WHERE token_hash = ?
+	`
+
+	var session Session
+	err := p.queryRow(ctx, query, tokenHash).Scan(
+		&session.TokenHash, &session.UserID, &session.CreatedAt, &session.ExpiresAt,
+	)
+	if err == sql.ErrNoRows {
+		return Session{}, false, nil
+	}
+	if err != nil {
+		log.Printf("Failed to query session: %v", err)
+		return Session{}, false, fmt.Errorf("failed to query session: %w", err)
+	}
+
+	if time.Now().After(session.ExpiresAt) {
+		return Session{}, false, nil
+	}
+	return session, true, nil
+}
+
+// DeleteSession implements Storage.DeleteSession.
+func (p *PostgresStorage) DeleteSession(ctx context.Context, tokenHash string) error {
+	if _, err := p.exec(ctx, `DELETE FROM sessions WHERE token_hash = ?`, tokenHash); err != nil {
+		return fmt.Errorf("failed to delete session: %w", err)
+	}
+	return nil
+}
+
+// DeleteExpiredSessions implements Storage.DeleteExpiredSessions.
+func (p *PostgresStorage) DeleteExpiredSessions(ctx context.Context, now time.Time) (int64, error) {
+	result, err := p.exec(ctx, `DELETE FROM sessions WHERE expires_at < ?`, now)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired sessions: %w", err)
+	}
+	deleted, _ := result.RowsAffected()
+	return deleted, nil
+}
@@ -27,6 +27,17 @@ type SQLiteStorage struct {
 // Default cache TTL for version resolution cache
 const defaultVersionCacheTTL = 1 * time.Hour
 
+// versionCacheTTL returns the version cache TTL from CACHE_TTL, or the default
+func versionCacheTTL() time.Duration {
+	if ttlEnv := os.Getenv("CACHE_TTL"); ttlEnv != "" {
+		if parsed, err := time.ParseDuration(ttlEnv); err == nil && parsed > 0 {
+			return parsed
+		}
+		log.Printf("Warning: Invalid CACHE_TTL '%s', using default %v", ttlEnv, defaultVersionCacheTTL)
+	}
+	return defaultVersionCacheTTL
+}
+
 // NewSQLiteStorage creates a new SQLite storage instance.
 // Initializes the database connection, enables WAL mode, and runs migrations.
 // Returns nil and an error if initialization fails (graceful degradation).
@@ -0,0 +1,166 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+)
+
+// approvalColumns is the column list shared by approval queries.
+const approvalColumns = `id, container_name, stack_name, current_version, target_version, status,
+	requested_at, expires_at, decided_by, decided_at, operation_id`
+
+// SaveApproval implements Storage.SaveApproval.
+// Inserts a new approval or replaces an existing one with the same ID.
+func (s *SQLiteStorage) SaveApproval(ctx context.Context, approval Approval) error {
+	return s.retryWithBackoff(ctx, func() error {
+		query := `
+			INSERT INTO pending_approvals (` + approvalColumns + `)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			ON CONFLICT(id) DO UPDATE SET
+				status = excluded.status,
+				target_version = excluded.target_version,
+				expires_at = excluded.expires_at,
+				decided_by = excluded.decided_by,
+				decided_at = excluded.decided_at,
+				operation_id = excluded.operation_id
+		`
+
+		var decidedAt sql.NullTime
+		if approval.DecidedAt != nil {
+			decidedAt = sql.NullTime{Time: *approval.DecidedAt, Valid: true}
+		}
+
+		_, err := s.db.ExecContext(ctx, query,
+			approval.ID, approval.ContainerName, approval.StackName, approval.CurrentVersion,
+			approval.TargetVersion, approval.Status, approval.RequestedAt, approval.ExpiresAt,
+			approval.DecidedBy, decidedAt, approval.OperationID,
+		)
+		if err != nil {
+			log.Printf("Failed to save approval %s: %v", approval.ID, err)
+			return fmt.Errorf("failed to save approval: %w", err)
+		}
+
+		log.Printf("Saved approval: id=%s, container=%s, target=%s, status=%s",
+			approval.ID, approval.ContainerName, approval.TargetVersion, approval.Status)
+		return nil
+	})
+}
+
+// GetApproval implements Storage.GetApproval.
+// Retrieves an approval by ID. Returns false if it does not exist.
+func (s *SQLiteStorage) GetApproval(ctx context.Context, id string) (Approval, bool, error) {
+	query := `SELECT ` + approvalColumns + ` FROM pending_approvals WHERE id = ?`
+	return s.queryApproval(ctx, query, id)
+}
+
+// GetLatestApproval implements Storage.GetLatestApproval.
+// Retrieves the most recently requested approval for a container, in any status.
+func (s *SQLiteStorage) GetLatestApproval(ctx context.Context, containerName string) (Approval, bool, error) {
+	query := `
+		SELECT ` + approvalColumns + `
+		FROM pending_approvals
+		WHERE container_name = ?
+		ORDER BY requested_at DESC, rowid DESC
+		LIMIT 1
+	`
+	return s.queryApproval(ctx, query, containerName)
+}
+
+// ListApprovals implements Storage.ListApprovals.
+// Retrieves approvals newest first, optionally filtered by status.
+func (s *SQLiteStorage) ListApprovals(ctx context.Context, status string, limit int) ([]Approval, error) {
+	if limit <= 0 {
+		limit = 100
+	}
+
+	query := `SELECT ` + approvalColumns + ` FROM pending_approvals`
+	args := []any{}
+	if status != "" {
+		query += ` WHERE status = ?`
+		args = append(args, status)
+	}
+	query += ` ORDER BY requested_at DESC, rowid DESC LIMIT ?`
+	args = append(args, limit)
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		log.Printf("Failed to query approvals: %v", err)
+		return nil, fmt.Errorf("failed to query approvals: %w", err)
+	}
+	defer rows.Close()
+
+	approvals := make([]Approval, 0)
+	for rows.Next() {
+		approval, err := scanApproval(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan approval: %w", err)
+		}
+		approvals = append(approvals, approval)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating approval rows: %w", err)
+	}
+
+	return approvals, nil
+}
+
+// ExpireApprovals implements Storage.ExpireApprovals.
+// Marks pending approvals whose deadline has passed as expired.
+func (s *SQLiteStorage) ExpireApprovals(ctx context.Context, now time.Time) (int64, error) {
+	var expired int64
+	err := s.retryWithBackoff(ctx, func() error {
+		query := `
+			UPDATE pending_approvals
+			SET status = 'expired'
+			WHERE status = 'pending' AND expires_at < ?
+		`
+
+		result, err := s.db.ExecContext(ctx, query, now)
+		if err != nil {
+			log.Printf("Failed to expire approvals: %v", err)
+			return fmt.Errorf("failed to expire approvals: %w", err)
+		}
+
+		expired, _ = result.RowsAffected()
+		if expired > 0 {
+			log.Printf("Expired %d pending approvals", expired)
+		}
+		return nil
+	})
+	return expired, err
+}
+
+// queryApproval runs a single-row approval query.
+func (s *SQLiteStorage) queryApproval(ctx context.Context, query string, arg any) (Approval, bool, error) {
+	approval, err := scanApproval(s.db.QueryRowContext(ctx, query, arg))
+	if err == sql.ErrNoRows {
+		return Approval{}, false, nil
+	}
+	if err != nil {
+		log.Printf("Failed to query approval %v: %v", arg, err)
+		return Approval{}, false, fmt.Errorf("failed to query approval: %w", err)
+	}
+	return approval, true, nil
+}
+
+// scanApproval scans a row selected with approvalColumns.
+func scanApproval(row interface{ Scan(...any) error }) (Approval, error) {
+	var approval Approval
+	var decidedAt sql.NullTime
+	err := row.Scan(
+		&approval.ID, &approval.ContainerName, &approval.StackName, &approval.CurrentVersion,
+		&approval.TargetVersion, &approval.Status, &approval.RequestedAt, &approval.ExpiresAt,
+		&approval.DecidedBy, &decidedAt, &approval.OperationID,
+	)
+	if err != nil {
+		return Approval{}, err
+	}
+	if decidedAt.Valid {
+		approval.DecidedAt = &decidedAt.Time
+	}
+	return approval, nil
+}
@@ -3,9 +3,9 @@ package storage
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"log"
-	"os"
 	"time"
 )
 
@@ -37,14 +37,7 @@ func (s *SQLiteStorage) SaveVersionCache(ctx context.Context, sha256, imageRef,
 // Returns empty string and false if not found or expired.
 func (s *SQLiteStorage) GetVersionCache(ctx context.Context, sha256, imageRef, arch string) (string, bool, error) {
 	// Get TTL from environment or use default
-	ttl := defaultVersionCacheTTL
-	if ttlEnv := os.Getenv("CACHE_TTL"); ttlEnv != "" {
-		if parsed, err := time.ParseDuration(ttlEnv); err == nil && parsed > 0 {
-			ttl = parsed
-		} else {
-			log.Printf("Warning: Invalid CACHE_TTL '%s', using default %v", ttlEnv, defaultVersionCacheTTL)
-		}
-	}
+	ttl := versionCacheTTL()
 
 	var version string
 	var resolvedAt time.Time
@@ -107,3 +100,102 @@ func (s *SQLiteStorage) CleanExpiredCache(ctx context.Context, ttlDays int) (int
 
 	return rowsDeleted, err
 }
+
+// GetTagCache implements Storage.GetTagCache.
+func (s *SQLiteStorage) GetTagCache(ctx context.Context, imageRef string) (TagCacheEntry, bool, error) {
+	entry := TagCacheEntry{ImageRef: imageRef}
+	var tagsJSON string
+
+	query := `
+		SELECT tags, etag, last_modified, partial, fetched_at
+		FROM registry_tag_cache
+		WHERE image_ref = ?
+	`
+
+	err := s.db.QueryRowContext(ctx, query, imageRef).Scan(&tagsJSON, &entry.ETag, &entry.LastModified, &entry.Partial, &entry.FetchedAt)
+	if err == sql.ErrNoRows {
+		return TagCacheEntry{}, false, nil
+	}
+	if err != nil {
+		log.Printf("Failed to query tag cache for %s: %v", imageRef, err)
+		return TagCacheEntry{}, false, fmt.Errorf("failed to query tag cache: %w", err)
+	}
+
+	if err := json.Unmarshal([]byte(tagsJSON), &entry.Tags); err != nil {
+		return TagCacheEntry{}, false, fmt.Errorf("failed to decode cached tags: %w", err)
+	}
+
+	return entry, true, nil
+}
+
+// SaveTagCache implements Storage.SaveTagCache.
+func (s *SQLiteStorage) SaveTagCache(ctx context.Context, entry TagCacheEntry) error {
+	tagsJSON, err := json.Marshal(entry.Tags)
+	if err != nil {
+		return fmt.Errorf("failed to encode tags: %w", err)
+	}
+	if entry.FetchedAt.IsZero() {
+		entry.FetchedAt = time.Now()
+	}
+
+	return s.retryWithBackoff(ctx, func() error {
+		query := `
+			INSERT OR REPLACE INTO registry_tag_cache
+			(image_ref, tags, etag, last_modified, partial, fetched_at)
+			VALUES (?, ?, ?, ?, ?, ?)
+		`
+
+		_, err := s.db.ExecContext(ctx, query, entry.ImageRef, string(tagsJSON), entry.ETag, entry.LastModified, entry.Partial, entry.FetchedAt.UTC())
+		if err != nil {
+			log.Printf("Failed to save tag cache for %s: %v", entry.ImageRef, err)
+			return fmt.Errorf("failed to save tag cache: %w", err)
+		}
+		return nil
+	})
+}
+
+// GetCheckFingerprint implements Storage.GetCheckFingerprint.
+func (s *SQLiteStorage) GetCheckFingerprint(ctx context.Context, containerName string) (CheckFingerprint, bool, error) {
+	fingerprint := CheckFingerprint{ContainerName: containerName}
+
+	query := `
+		SELECT image, local_hash, head_digest, tags_hash, result, checked_at
+		FROM check_fingerprints
+		WHERE container_name = ?
+	`
+
+	err := s.db.QueryRowContext(ctx, query, containerName).Scan(&fingerprint.Image, &fingerprint.LocalHash,
+		&fingerprint.HeadDigest, &fingerprint.TagsHash, &fingerprint.Result, &fingerprint.CheckedAt)
+	if err == sql.ErrNoRows {
+		return CheckFingerprint{}, false, nil
+	}
+	if err != nil {
+		log.Printf("Failed to query check fingerprint for %s: %v", containerName, err)
+		return CheckFingerprint{}, false, fmt.Errorf("failed to query check fingerprint: %w", err)
+	}
+
+	return fingerprint, true, nil
+}
+
+// SaveCheckFingerprint implements Storage.SaveCheckFingerprint.
+func (s *SQLiteStorage) SaveCheckFingerprint(ctx context.Context, fingerprint CheckFingerprint) error {
+	if fingerprint.CheckedAt.IsZero() {
+		fingerprint.CheckedAt = time.Now()
+	}
+
+	return s.retryWithBackoff(ctx, func() error {
+		query := `
+			INSERT OR REPLACE INTO check_fingerprints
+			(container_name, image, local_hash, head_digest, tags_hash, result, checked_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?)
+		`
+
+		_, err := s.db.ExecContext(ctx, query, fingerprint.ContainerName, fingerprint.Image, fingerprint.LocalHash,
+			fingerprint.HeadDigest, fingerprint.TagsHash, fingerprint.Result, fingerprint.CheckedAt.UTC())
+		if err != nil {
+			log.Printf("Failed to save check fingerprint for %s: %v", fingerprint.ContainerName, err)
+			return fmt.Errorf("failed to save check fingerprint: %w", err)
+		}
+		return nil
+	})
+}
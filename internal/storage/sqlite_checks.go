@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"strings"
 	"time"
 )
 
@@ -48,8 +49,8 @@ func (s *SQLiteStorage) LogCheckBatch(ctx context.Context, checks []CheckHistory
 		// Prepare statement for efficiency
 		stmt, err := tx.PrepareContext(ctx, `
 			INSERT INTO check_history
-			(container_name, image, current_version, latest_version, status, error)
-			VALUES (?, ?, ?, ?, ?, ?)
+			(container_name, image, current_version, latest_version, status, error, latest_size, size_delta)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?)
 		`)
 		if err != nil {
 			tx.Rollback()
@@ -59,7 +60,7 @@ func (s *SQLiteStorage) LogCheckBatch(ctx context.Context, checks []CheckHistory
 
 		// Insert each check entry
 		for _, check := range checks {
-			_, err = stmt.ExecContext(ctx, check.ContainerName, check.Image, check.CurrentVersion, check.LatestVersion, check.Status, check.Error)
+			_, err = stmt.ExecContext(ctx, check.ContainerName, check.Image, check.CurrentVersion, check.LatestVersion, check.Status, check.Error, check.LatestSize, check.SizeDelta)
 			if err != nil {
 				tx.Rollback()
 				log.Printf("Failed to insert check entry for %s: %v", check.ContainerName, err)
@@ -83,7 +84,7 @@ func (s *SQLiteStorage) LogCheckBatch(ctx context.Context, checks []CheckHistory
 // Supports pagination via limit parameter.
 func (s *SQLiteStorage) GetCheckHistory(ctx context.Context, containerName string, limit int) ([]CheckHistoryEntry, error) {
 	query := `
-		SELECT id, container_name, image, check_time, current_version, latest_version, status, error
+		SELECT id, container_name, image, check_time, current_version, latest_version, status, error, latest_size, size_delta
 		FROM check_history
 		WHERE container_name = ?
 		ORDER BY check_time DESC
@@ -104,7 +105,7 @@ func (s *SQLiteStorage) GetCheckHistory(ctx context.Context, containerName strin
 // Returns entries ordered by check_time DESC (most recent first).
 func (s *SQLiteStorage) GetCheckHistoryByTimeRange(ctx context.Context, start, end time.Time) ([]CheckHistoryEntry, error) {
 	query := `
-		SELECT id, container_name, image, check_time, current_version, latest_version, status, error
+		SELECT id, container_name, image, check_time, current_version, latest_version, status, error, latest_size, size_delta
 		FROM check_history
 		WHERE check_time >= ? AND check_time <= ?
 		ORDER BY check_time DESC
@@ -120,11 +121,60 @@ func (s *SQLiteStorage) GetCheckHistoryByTimeRange(ctx context.Context, start, e
 	return scanCheckHistoryRows(rows)
 }
 
+// QueryCheckHistory implements Storage.QueryCheckHistory.
+// Returns entries ordered by check_time DESC (most recent first).
+func (s *SQLiteStorage) QueryCheckHistory(ctx context.Context, opts CheckHistoryQueryOptions) ([]CheckHistoryEntry, error) {
+	var conditions []string
+	var args []interface{}
+
+	if len(opts.Containers) > 0 {
+		placeholders := strings.TrimSuffix(strings.Repeat("?,", len(opts.Containers)), ",")
+		conditions = append(conditions, "container_name IN ("+placeholders+")")
+		for _, name := range opts.Containers {
+			args = append(args, name)
+		}
+	}
+	if opts.Status != "" {
+		conditions = append(conditions, "status = ?")
+		args = append(args, opts.Status)
+	}
+	if opts.DateFrom != nil {
+		conditions = append(conditions, "check_time >= ?")
+		args = append(args, *opts.DateFrom)
+	}
+	if opts.DateTo != nil {
+		conditions = append(conditions, "check_time <= ?")
+		args = append(args, *opts.DateTo)
+	}
+
+	whereClause := ""
+	if len(conditions) > 0 {
+		whereClause = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	baseQuery := fmt.Sprintf(`
+		SELECT id, container_name, image, check_time, current_version, latest_version, status, error, latest_size, size_delta
+		FROM check_history
+		%s
+		ORDER BY check_time DESC
+	`, whereClause)
+	query, args := withLimit(baseQuery, args, opts.Limit)
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		log.Printf("Failed to query check history: %v", err)
+		return nil, fmt.Errorf("failed to query check history: %w", err)
+	}
+	defer rows.Close()
+
+	return scanCheckHistoryRows(rows)
+}
+
 // GetAllCheckHistory retrieves check history for all containers.
 // Returns entries ordered by check_time DESC (most recent first).
 func (s *SQLiteStorage) GetAllCheckHistory(ctx context.Context, limit int) ([]CheckHistoryEntry, error) {
 	baseQuery := `
-		SELECT id, container_name, image, check_time, current_version, latest_version, status, error
+		SELECT id, container_name, image, check_time, current_version, latest_version, status, error, latest_size, size_delta
 		FROM check_history
 		ORDER BY check_time DESC
 	`
@@ -144,7 +194,7 @@ func (s *SQLiteStorage) GetAllCheckHistory(ctx context.Context, limit int) ([]Ch
 // Returns entries ordered by check_time DESC (most recent first).
 func (s *SQLiteStorage) GetCheckHistorySince(ctx context.Context, since time.Time) ([]CheckHistoryEntry, error) {
 	query := `
-		SELECT id, container_name, image, check_time, current_version, latest_version, status, error
+		SELECT id, container_name, image, check_time, current_version, latest_version, status, error, latest_size, size_delta
 		FROM check_history
 		WHERE check_time >= ?
 		ORDER BY check_time DESC
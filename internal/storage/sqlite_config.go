@@ -92,7 +92,7 @@ func (s *SQLiteStorage) GetConfigHistory(ctx context.Context, limit int) ([]Conf
 	query := `
 		SELECT id, snapshot_time, config_snapshot, changed_by, created_at
 		FROM config_history
-		ORDER BY snapshot_time DESC
+		ORDER BY snapshot_time DESC, id DESC
 		LIMIT ?
 	`
 
@@ -24,6 +24,8 @@ func scanCheckHistoryRows(rows *sql.Rows) ([]CheckHistoryEntry, error) {
 			&entry.LatestVersion,
 			&entry.Status,
 			&errorMsg,
+			&entry.LatestSize,
+			&entry.SizeDelta,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan check history entry: %w", err)
@@ -51,15 +53,15 @@ func scanUpdateOperationRows(rows *sql.Rows) ([]UpdateOperation, error) {
 	for rows.Next() {
 		var op UpdateOperation
 		var dependentsJSON sql.NullString
-		var batchDetailsJSON sql.NullString
-		var batchGroupID sql.NullString
+		var batchDetailsJSON, signaturesJSON, observationsJSON sql.NullString
+		var batchGroupID, checkOutput, composeOutput, triggeredBy sql.NullString
 		var startedAt, completedAt sql.NullTime
 		var containerID, stackName, oldVersion, newVersion, errorMessage sql.NullString
 
 		err := rows.Scan(
 			&op.ID, &op.OperationID, &containerID, &op.ContainerName, &stackName, &op.OperationType, &op.Status,
 			&oldVersion, &newVersion, &startedAt, &completedAt, &errorMessage,
-			&dependentsJSON, &op.RollbackOccurred, &batchDetailsJSON, &batchGroupID, &op.CreatedAt, &op.UpdatedAt,
+			&dependentsJSON, &op.RollbackOccurred, &batchDetailsJSON, &batchGroupID, &checkOutput, &op.AllOrNothing, &signaturesJSON, &observationsJSON, &composeOutput, &triggeredBy, &op.CreatedAt, &op.UpdatedAt,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan update operation: %w", err)
@@ -90,6 +92,9 @@ func scanUpdateOperationRows(rows *sql.Rows) ([]UpdateOperation, error) {
 		if batchGroupID.Valid {
 			op.BatchGroupID = batchGroupID.String
 		}
+		op.CheckOutput = checkOutput.String
+		op.ComposeOutput = composeOutput.String
+		op.TriggeredBy = triggeredBy.String
 
 		// Deserialize dependents affected from JSON
 		if dependentsJSON.Valid && dependentsJSON.String != "" {
@@ -109,6 +114,24 @@ func scanUpdateOperationRows(rows *sql.Rows) ([]UpdateOperation, error) {
 			}
 		}
 
+		// Deserialize signature verifications from JSON
+		if signaturesJSON.Valid && signaturesJSON.String != "" {
+			err = json.Unmarshal([]byte(signaturesJSON.String), &op.SignatureVerifications)
+			if err != nil {
+				log.Printf("Failed to deserialize signature verifications: %v", err)
+				return nil, fmt.Errorf("failed to deserialize signature verifications: %w", err)
+			}
+		}
+
+		// Deserialize observations from JSON
+		if observationsJSON.Valid && observationsJSON.String != "" {
+			err = json.Unmarshal([]byte(observationsJSON.String), &op.Observations)
+			if err != nil {
+				log.Printf("Failed to deserialize observations: %v", err)
+				return nil, fmt.Errorf("failed to deserialize observations: %w", err)
+			}
+		}
+
 		operations = append(operations, op)
 	}
 
@@ -182,6 +205,56 @@ func scanScriptAssignmentRows(rows *sql.Rows) ([]ScriptAssignment, error) {
 	return assignments, nil
 }
 
+// rowScanner is implemented by *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// scanScriptRevision scans a row of scriptRevisionColumns.
+func scanScriptRevision(row rowScanner) (ScriptRevision, error) {
+	var rev ScriptRevision
+	err := row.Scan(
+		&rev.ID, &rev.Name, &rev.Revision, &rev.SHA256, &rev.Size, &rev.TimeoutSeconds,
+		&rev.NoNetwork, &rev.MemoryLimitMB, &rev.CPUSeconds, &rev.CreatedBy, &rev.CreatedAt,
+	)
+	return rev, err
+}
+
+// scanScriptRevisionRows scans multiple ScriptRevision rows.
+func scanScriptRevisionRows(rows *sql.Rows) ([]ScriptRevision, error) {
+	revisions := make([]ScriptRevision, 0)
+	for rows.Next() {
+		rev, err := scanScriptRevision(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan script revision: %w", err)
+		}
+		revisions = append(revisions, rev)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating script revision rows: %w", err)
+	}
+	return revisions, nil
+}
+
+// scanVolumeSnapshotRows scans multiple rows of volumeSnapshotColumns.
+func scanVolumeSnapshotRows(rows *sql.Rows) ([]VolumeSnapshot, error) {
+	snapshots := make([]VolumeSnapshot, 0)
+	for rows.Next() {
+		var snapshot VolumeSnapshot
+		if err := rows.Scan(
+			&snapshot.ID, &snapshot.OperationID, &snapshot.ContainerName, &snapshot.VolumeName,
+			&snapshot.Method, &snapshot.Location, &snapshot.SizeBytes, &snapshot.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan volume snapshot: %w", err)
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating volume snapshot rows: %w", err)
+	}
+	return snapshots, nil
+}
+
 // withLimit creates a query with an optional LIMIT clause using parameterized queries.
 // If limit <= 0, no LIMIT clause is added.
 // Returns the query string and arguments slice for use with QueryContext.
@@ -191,3 +264,21 @@ func withLimit(baseQuery string, baseArgs []interface{}, limit int) (string, []i
 	}
 	return baseQuery, baseArgs
 }
+
+// scanOperationLogRows scans multiple rows of operationLogColumns.
+func scanOperationLogRows(rows *sql.Rows) ([]OperationLogEntry, error) {
+	entries := make([]OperationLogEntry, 0)
+	for rows.Next() {
+		var entry OperationLogEntry
+		if err := rows.Scan(
+			&entry.ID, &entry.OperationID, &entry.ContainerName, &entry.Source, &entry.Message, &entry.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan operation log: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating operation log rows: %w", err)
+	}
+	return entries, nil
+}
@@ -0,0 +1,202 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"modernc.org/sqlite"
+)
+
+// backuper is implemented by the modernc SQLite driver connection
+type backuper interface {
+	NewBackup(dstUri string) (*sqlite.Backup, error)
+}
+
+// Backup implements Storage.Backup using the SQLite online backup API, so the
+// snapshot is consistent even while the server keeps writing.
+func (s *SQLiteStorage) Backup(ctx context.Context, destPath string) error {
+	if _, err := os.Stat(destPath); err == nil {
+		return fmt.Errorf("backup file already exists: %s", destPath)
+	}
+
+	conn, err := s.db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get database connection: %w", err)
+	}
+	defer conn.Close()
+
+	err = conn.Raw(func(driverConn any) error {
+		b, ok := driverConn.(backuper)
+		if !ok {
+			return fmt.Errorf("database driver does not support backups")
+		}
+		backup, err := b.NewBackup(destPath)
+		if err != nil {
+			return err
+		}
+		if _, err := backup.Step(-1); err != nil {
+			backup.Finish()
+			return err
+		}
+		return backup.Finish()
+	})
+	if err != nil {
+		os.Remove(destPath)
+		return fmt.Errorf("failed to back up database: %w", err)
+	}
+
+	log.Printf("Backed up database to %s", destPath)
+	return nil
+}
+
+// Vacuum implements Storage.Vacuum.
+// The WAL is checkpointed afterwards so the freed space is returned to the file system.
+func (s *SQLiteStorage) Vacuum(ctx context.Context) (VacuumResult, error) {
+	var result VacuumResult
+	var err error
+
+	if result.SizeBefore, err = s.size(ctx); err != nil {
+		return result, err
+	}
+	err = s.retryWithBackoff(ctx, func() error {
+		_, err := s.db.ExecContext(ctx, "VACUUM")
+		return err
+	})
+	if err != nil {
+		return result, fmt.Errorf("failed to vacuum database: %w", err)
+	}
+	if _, err := s.db.ExecContext(ctx, "PRAGMA wal_checkpoint(TRUNCATE)"); err != nil {
+		return result, fmt.Errorf("failed to checkpoint database: %w", err)
+	}
+	if result.SizeAfter, err = s.size(ctx); err != nil {
+		return result, err
+	}
+
+	log.Printf("Vacuumed database: %d -> %d bytes", result.SizeBefore, result.SizeAfter)
+	return result, nil
+}
+
+// size returns the size of the database in bytes
+func (s *SQLiteStorage) size(ctx context.Context) (int64, error) {
+	var pages, pageSize int64
+	if err := s.db.QueryRowContext(ctx, "PRAGMA page_count").Scan(&pages); err != nil {
+		return 0, fmt.Errorf("failed to read page count: %w", err)
+	}
+	if err := s.db.QueryRowContext(ctx, "PRAGMA page_size").Scan(&pageSize); err != nil {
+		return 0, fmt.Errorf("failed to read page size: %w", err)
+	}
+	return pages * pageSize, nil
+}
+
+// PruneHistory implements Storage.PruneHistory.
+func (s *SQLiteStorage) PruneHistory(ctx context.Context, opts PruneOptions) (PruneResult, error) {
+	var result PruneResult
+	err := s.retryWithBackoff(ctx, func() error {
+		tx, err := s.db.BeginTx(ctx, nil)
+		if err != nil {
+			return fmt.Errorf("failed to begin transaction: %w", err)
+		}
+		defer tx.Rollback()
+
+		result = PruneResult{}
+		if !opts.CheckHistoryBefore.IsZero() {
+			r, err := tx.ExecContext(ctx, "DELETE FROM check_history WHERE check_time < ?", opts.CheckHistoryBefore)
+			if err != nil {
+				return fmt.Errorf("failed to prune check history: %w", err)
+			}
+			result.CheckHistory, _ = r.RowsAffected()
+		}
+		if !opts.UpdateLogBefore.IsZero() {
+			r, err := tx.ExecContext(ctx, "DELETE FROM update_log WHERE timestamp < ?", opts.UpdateLogBefore)
+			if err != nil {
+				return fmt.Errorf("failed to prune update log: %w", err)
+			}
+			result.UpdateLog, _ = r.RowsAffected()
+		}
+
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("failed to commit: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return PruneResult{}, err
+	}
+
+	if result.CheckHistory > 0 || result.UpdateLog > 0 {
+		log.Printf("Pruned history: %d checks, %d logs", result.CheckHistory, result.UpdateLog)
+	}
+	return result, nil
+}
+
+// ListComposeBackups implements Storage.ListComposeBackups.
+func (s *SQLiteStorage) ListComposeBackups(ctx context.Context) ([]ComposeBackup, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, operation_id, container_name, stack_name, compose_file_path, backup_file_path, backup_timestamp
+		FROM compose_backups
+		ORDER BY backup_timestamp ASC, id ASC
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query compose backups: %w", err)
+	}
+	defer rows.Close()
+
+	var backups []ComposeBackup
+	for rows.Next() {
+		var backup ComposeBackup
+		var stackName sql.NullString
+		if err := rows.Scan(&backup.ID, &backup.OperationID, &backup.ContainerName, &stackName,
+			&backup.ComposeFilePath, &backup.BackupFilePath, &backup.BackupTimestamp); err != nil {
+			return nil, fmt.Errorf("failed to scan compose backup: %w", err)
+		}
+		backup.StackName = stackName.String
+		backups = append(backups, backup)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate compose backups: %w", err)
+	}
+	return backups, nil
+}
+
+// DeleteComposeBackups implements Storage.DeleteComposeBackups.
+func (s *SQLiteStorage) DeleteComposeBackups(ctx context.Context, ids []int64) (int64, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(ids)), ",")
+	args := make([]interface{}, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+
+	var deleted int64
+	err := s.retryWithBackoff(ctx, func() error {
+		result, err := s.db.ExecContext(ctx, "DELETE FROM compose_backups WHERE id IN ("+placeholders+")", args...)
+		if err != nil {
+			return fmt.Errorf("failed to delete compose backups: %w", err)
+		}
+		deleted, _ = result.RowsAffected()
+		return nil
+	})
+	return deleted, err
+}
+
+// CheckWritable implements Storage.CheckWritable.
+// The delete matches no rows but still takes the write lock, so a read-only
+// database file or a locked database fails here.
+func (s *SQLiteStorage) CheckWritable(ctx context.Context) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM config WHERE key = ?", "__write_check__"); err != nil {
+		return fmt.Errorf("database is not writable: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"log"
+)
+
+// ListOwnerships implements Storage.ListOwnerships.
+func (s *SQLiteStorage) ListOwnerships(ctx context.Context) ([]Ownership, error) {
+	query := `
+		SELECT entity_type, entity_id, owner, created_at
+		FROM ownership
+		ORDER BY entity_type, entity_id, owner
+	`
+
+	rows, err := s.db.QueryContext(ctx, query)
+	if err != nil {
+		log.Printf("Failed to query ownership: %v", err)
+		return nil, fmt.Errorf("failed to query ownership: %w", err)
+	}
+	defer rows.Close()
+
+	ownerships := make([]Ownership, 0)
+	for rows.Next() {
+		var ownership Ownership
+		if err := rows.Scan(&ownership.EntityType, &ownership.EntityID, &ownership.Owner, &ownership.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan ownership: %w", err)
+		}
+		ownerships = append(ownerships, ownership)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating ownership rows: %w", err)
+	}
+
+	return ownerships, nil
+}
+
+// AddOwnership implements Storage.AddOwnership.
+func (s *SQLiteStorage) AddOwnership(ctx context.Context, ownership Ownership) error {
+	return s.retryWithBackoff(ctx, func() error {
+		query := `
+			INSERT OR IGNORE INTO ownership (entity_type, entity_id, owner, created_at)
+			VALUES (?, ?, ?, CURRENT_TIMESTAMP)
+		`
+
+		_, err := s.db.ExecContext(ctx, query, ownership.EntityType, ownership.EntityID, ownership.Owner)
+		if err != nil {
+			log.Printf("Failed to add owner %s to %s %s: %v", ownership.Owner, ownership.EntityType, ownership.EntityID, err)
+			return fmt.Errorf("failed to add ownership: %w", err)
+		}
+
+		log.Printf("Added owner: %s %s -> %s", ownership.EntityType, ownership.EntityID, ownership.Owner)
+		return nil
+	})
+}
+
+// RemoveOwnership implements Storage.RemoveOwnership.
+func (s *SQLiteStorage) RemoveOwnership(ctx context.Context, entityType, entityID, owner string) (bool, error) {
+	var removed bool
+	err := s.retryWithBackoff(ctx, func() error {
+		query := `DELETE FROM ownership WHERE entity_type = ? AND entity_id = ? AND owner = ?`
+
+		result, err := s.db.ExecContext(ctx, query, entityType, entityID, owner)
+		if err != nil {
+			log.Printf("Failed to remove owner %s from %s %s: %v", owner, entityType, entityID, err)
+			return fmt.Errorf("failed to remove ownership: %w", err)
+		}
+
+		rows, _ := result.RowsAffected()
+		removed = rows > 0
+		return nil
+	})
+	return removed, err
+}
+
+// ListTeamMembers implements Storage.ListTeamMembers.
+func (s *SQLiteStorage) ListTeamMembers(ctx context.Context) ([]TeamMember, error) {
+	query := `
+		SELECT team, username, created_at
+		FROM team_members
+		ORDER BY team, username
+	`
+
+	rows, err := s.db.QueryContext(ctx, query)
+	if err != nil {
+		log.Printf("Failed to query team members: %v", err)
+		return nil, fmt.Errorf("failed to query team members: %w", err)
+	}
+	defer rows.Close()
+
+	members := make([]TeamMember, 0)
+	for rows.Next() {
+		var member TeamMember
+		if err := rows.Scan(&member.Team, &member.Username, &member.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan team member: %w", err)
+		}
+		members = append(members, member)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating team member rows: %w", err)
+	}
+
+	return members, nil
+}
+
+// AddTeamMember implements Storage.AddTeamMember.
+func (s *SQLiteStorage) AddTeamMember(ctx context.Context, team, username string) error {
+	return s.retryWithBackoff(ctx, func() error {
+		query := `
+			INSERT OR IGNORE INTO team_members (team, username, created_at)
+			VALUES (?, ?, CURRENT_TIMESTAMP)
+		`
+
+		if _, err := s.db.ExecContext(ctx, query, team, username); err != nil {
+			log.Printf("Failed to add %s to team %s: %v", username, team, err)
+			return fmt.Errorf("failed to add team member: %w", err)
+		}
+
+		log.Printf("Added team member: team=%s, username=%s", team, username)
+		return nil
+	})
+}
+
+// RemoveTeamMember implements Storage.RemoveTeamMember.
+func (s *SQLiteStorage) RemoveTeamMember(ctx context.Context, team, username string) (bool, error) {
+	var removed bool
+	err := s.retryWithBackoff(ctx, func() error {
+		result, err := s.db.ExecContext(ctx, `DELETE FROM team_members WHERE team = ? AND username = ?`, team, username)
+		if err != nil {
+			log.Printf("Failed to remove %s from team %s: %v", username, team, err)
+			return fmt.Errorf("failed to remove team member: %w", err)
+		}
+
+		rows, _ := result.RowsAffected()
+		removed = rows > 0
+		return nil
+	})
+	return removed, err
+}
@@ -3,6 +3,7 @@ package storage
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"log"
 )
@@ -73,3 +74,310 @@ func (s *SQLiteStorage) SetRollbackPolicy(ctx context.Context, policy RollbackPo
 		return nil
 	})
 }
+
+// ListRollbackPolicies implements Storage.ListRollbackPolicies.
+// Retrieves all rollback policies, global first.
+func (s *SQLiteStorage) ListRollbackPolicies(ctx context.Context) ([]RollbackPolicy, error) {
+	query := `
+		SELECT id, entity_type, entity_id, auto_rollback_enabled, health_check_required, created_at, updated_at
+		FROM rollback_policies
+		ORDER BY CASE entity_type WHEN 'global' THEN 0 ELSE 1 END, entity_type, entity_id
+	`
+
+	rows, err := s.db.QueryContext(ctx, query)
+	if err != nil {
+		log.Printf("Failed to list rollback policies: %v", err)
+		return nil, fmt.Errorf("failed to list rollback policies: %w", err)
+	}
+	defer rows.Close()
+
+	policies := []RollbackPolicy{}
+	for rows.Next() {
+		var policy RollbackPolicy
+		var entityIDNull sql.NullString
+		if err := rows.Scan(
+			&policy.ID, &policy.EntityType, &entityIDNull,
+			&policy.AutoRollbackEnabled, &policy.HealthCheckRequired,
+			&policy.CreatedAt, &policy.UpdatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan rollback policy: %w", err)
+		}
+		if entityIDNull.Valid {
+			policy.EntityID = entityIDNull.String
+		}
+		policies = append(policies, policy)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate rollback policies: %w", err)
+	}
+
+	return policies, nil
+}
+
+// approvalPolicyColumns are the columns scanned by scanApprovalPolicy.
+const approvalPolicyColumns = `id, entity_type, entity_id, mode, created_at, updated_at`
+
+// scanApprovalPolicy scans one approval policy row.
+func scanApprovalPolicy(row interface{ Scan(...any) error }) (ApprovalPolicy, error) {
+	var policy ApprovalPolicy
+	err := row.Scan(&policy.ID, &policy.EntityType, &policy.EntityID, &policy.Mode, &policy.CreatedAt, &policy.UpdatedAt)
+	return policy, err
+}
+
+// GetApprovalPolicy implements Storage.GetApprovalPolicy.
+func (s *SQLiteStorage) GetApprovalPolicy(ctx context.Context, entityType, entityID string) (ApprovalPolicy, bool, error) {
+	query := `SELECT ` + approvalPolicyColumns + ` FROM approval_policies WHERE entity_type = ? AND entity_id = ?`
+
+	policy, err := scanApprovalPolicy(s.db.QueryRowContext(ctx, query, entityType, entityID))
+	if err == sql.ErrNoRows {
+		return ApprovalPolicy{}, false, nil
+	}
+	if err != nil {
+		return ApprovalPolicy{}, false, fmt.Errorf("failed to query approval policy: %w", err)
+	}
+	return policy, true, nil
+}
+
+// SetApprovalPolicy implements Storage.SetApprovalPolicy.
+func (s *SQLiteStorage) SetApprovalPolicy(ctx context.Context, policy ApprovalPolicy) error {
+	return s.retryWithBackoff(ctx, func() error {
+		query := `
+			INSERT INTO approval_policies (entity_type, entity_id, mode, created_at, updated_at)
+			VALUES (?, ?, ?, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+			ON CONFLICT (entity_type, entity_id) DO UPDATE SET
+				mode = excluded.mode,
+				updated_at = excluded.updated_at
+		`
+		if _, err := s.db.ExecContext(ctx, query, policy.EntityType, policy.EntityID, policy.Mode); err != nil {
+			return fmt.Errorf("failed to set approval policy: %w", err)
+		}
+		log.Printf("Set approval policy: type=%s, id=%s, mode=%s", policy.EntityType, policy.EntityID, policy.Mode)
+		return nil
+	})
+}
+
+// ListApprovalPolicies implements Storage.ListApprovalPolicies.
+func (s *SQLiteStorage) ListApprovalPolicies(ctx context.Context) ([]ApprovalPolicy, error) {
+	query := `
+		SELECT ` + approvalPolicyColumns + `
+		FROM approval_policies
+		ORDER BY CASE entity_type WHEN 'global' THEN 0 ELSE 1 END, entity_type, entity_id
+	`
+
+	rows, err := s.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list approval policies: %w", err)
+	}
+	defer rows.Close()
+	return scanApprovalPolicyRows(rows)
+}
+
+// DeleteApprovalPolicy implements Storage.DeleteApprovalPolicy.
+func (s *SQLiteStorage) DeleteApprovalPolicy(ctx context.Context, entityType, entityID string) error {
+	return s.retryWithBackoff(ctx, func() error {
+		_, err := s.db.ExecContext(ctx, `DELETE FROM approval_policies WHERE entity_type = ? AND entity_id = ?`, entityType, entityID)
+		if err != nil {
+			return fmt.Errorf("failed to delete approval policy: %w", err)
+		}
+		return nil
+	})
+}
+
+// scanApprovalPolicyRows scans the rows of an approval policy query.
+func scanApprovalPolicyRows(rows *sql.Rows) ([]ApprovalPolicy, error) {
+	policies := []ApprovalPolicy{}
+	for rows.Next() {
+		policy, err := scanApprovalPolicy(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan approval policy: %w", err)
+		}
+		policies = append(policies, policy)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate approval policies: %w", err)
+	}
+	return policies, nil
+}
+
+// ignoreRuleColumns are the columns scanned by scanIgnoreRule.
+const ignoreRuleColumns = `id, pattern, reason, created_at`
+
+// scanIgnoreRule scans one ignore rule row.
+func scanIgnoreRule(row interface{ Scan(...any) error }) (IgnoreRule, error) {
+	var rule IgnoreRule
+	err := row.Scan(&rule.ID, &rule.Pattern, &rule.Reason, &rule.CreatedAt)
+	return rule, err
+}
+
+// ListIgnoreRules implements Storage.ListIgnoreRules.
+func (s *SQLiteStorage) ListIgnoreRules(ctx context.Context) ([]IgnoreRule, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT `+ignoreRuleColumns+` FROM ignore_rules ORDER BY pattern`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ignore rules: %w", err)
+	}
+	defer rows.Close()
+	return scanIgnoreRuleRows(rows)
+}
+
+// SaveIgnoreRule implements Storage.SaveIgnoreRule.
+func (s *SQLiteStorage) SaveIgnoreRule(ctx context.Context, rule IgnoreRule) (IgnoreRule, error) {
+	var saved IgnoreRule
+	err := s.retryWithBackoff(ctx, func() error {
+		query := `
+			INSERT INTO ignore_rules (pattern, reason, created_at)
+			VALUES (?, ?, CURRENT_TIMESTAMP)
+			ON CONFLICT (pattern) DO UPDATE SET reason = excluded.reason
+		`
+		if _, err := s.db.ExecContext(ctx, query, rule.Pattern, rule.Reason); err != nil {
+			return fmt.Errorf("failed to save ignore rule: %w", err)
+		}
+		var err error
+		saved, err = scanIgnoreRule(s.db.QueryRowContext(ctx, `SELECT `+ignoreRuleColumns+` FROM ignore_rules WHERE pattern = ?`, rule.Pattern))
+		if err != nil {
+			return fmt.Errorf("failed to query ignore rule: %w", err)
+		}
+		return nil
+	})
+	return saved, err
+}
+
+// DeleteIgnoreRule implements Storage.DeleteIgnoreRule.
+func (s *SQLiteStorage) DeleteIgnoreRule(ctx context.Context, id int64) (bool, error) {
+	var deleted bool
+	err := s.retryWithBackoff(ctx, func() error {
+		result, err := s.db.ExecContext(ctx, `DELETE FROM ignore_rules WHERE id = ?`, id)
+		if err != nil {
+			return fmt.Errorf("failed to delete ignore rule: %w", err)
+		}
+		n, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to delete ignore rule: %w", err)
+		}
+		deleted = n > 0
+		return nil
+	})
+	return deleted, err
+}
+
+// scanIgnoreRuleRows scans the rows of an ignore rule query.
+func scanIgnoreRuleRows(rows *sql.Rows) ([]IgnoreRule, error) {
+	rules := []IgnoreRule{}
+	for rows.Next() {
+		rule, err := scanIgnoreRule(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan ignore rule: %w", err)
+		}
+		rules = append(rules, rule)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate ignore rules: %w", err)
+	}
+	return rules, nil
+}
+
+// stackEnvColumns are the columns scanned by scanStackEnv.
+const stackEnvColumns = `stack, env_file, env, updated_at`
+
+// scanStackEnv scans one stack environment row, decoding its JSON variables.
+func scanStackEnv(row interface{ Scan(...any) error }) (StackEnv, error) {
+	var env StackEnv
+	var vars string
+	if err := row.Scan(&env.Stack, &env.EnvFile, &vars, &env.UpdatedAt); err != nil {
+		return StackEnv{}, err
+	}
+	if err := json.Unmarshal([]byte(vars), &env.Env); err != nil {
+		return StackEnv{}, fmt.Errorf("failed to decode environment of stack %s: %w", env.Stack, err)
+	}
+	return env, nil
+}
+
+// encodeStackEnvVars encodes the variables of a stack environment as JSON.
+func encodeStackEnvVars(env StackEnv) (string, error) {
+	vars := env.Env
+	if vars == nil {
+		vars = map[string]string{}
+	}
+	data, err := json.Marshal(vars)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode environment of stack %s: %w", env.Stack, err)
+	}
+	return string(data), nil
+}
+
+// GetStackEnv implements Storage.GetStackEnv.
+func (s *SQLiteStorage) GetStackEnv(ctx context.Context, stack string) (StackEnv, bool, error) {
+	env, err := scanStackEnv(s.db.QueryRowContext(ctx, `SELECT `+stackEnvColumns+` FROM stack_env WHERE stack = ?`, stack))
+	if err == sql.ErrNoRows {
+		return StackEnv{}, false, nil
+	}
+	if err != nil {
+		return StackEnv{}, false, fmt.Errorf("failed to query stack environment: %w", err)
+	}
+	return env, true, nil
+}
+
+// SetStackEnv implements Storage.SetStackEnv.
+func (s *SQLiteStorage) SetStackEnv(ctx context.Context, env StackEnv) error {
+	vars, err := encodeStackEnvVars(env)
+	if err != nil {
+		return err
+	}
+	return s.retryWithBackoff(ctx, func() error {
+		query := `
+			INSERT INTO stack_env (stack, env_file, env, updated_at)
+			VALUES (?, ?, ?, CURRENT_TIMESTAMP)
+			ON CONFLICT (stack) DO UPDATE SET
+				env_file = excluded.env_file,
+				env = excluded.env,
+				updated_at = excluded.updated_at
+		`
+		if _, err := s.db.ExecContext(ctx, query, env.Stack, env.EnvFile, vars); err != nil {
+			return fmt.Errorf("failed to set stack environment: %w", err)
+		}
+		return nil
+	})
+}
+
+// ListStackEnvs implements Storage.ListStackEnvs.
+func (s *SQLiteStorage) ListStackEnvs(ctx context.Context) ([]StackEnv, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT `+stackEnvColumns+` FROM stack_env ORDER BY stack`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list stack environments: %w", err)
+	}
+	defer rows.Close()
+	return scanStackEnvRows(rows)
+}
+
+// DeleteStackEnv implements Storage.DeleteStackEnv.
+func (s *SQLiteStorage) DeleteStackEnv(ctx context.Context, stack string) (bool, error) {
+	var deleted bool
+	err := s.retryWithBackoff(ctx, func() error {
+		result, err := s.db.ExecContext(ctx, `DELETE FROM stack_env WHERE stack = ?`, stack)
+		if err != nil {
+			return fmt.Errorf("failed to delete stack environment: %w", err)
+		}
+		n, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to delete stack environment: %w", err)
+		}
+		deleted = n > 0
+		return nil
+	})
+	return deleted, err
+}
+
+// scanStackEnvRows scans the rows of a stack environment query.
+func scanStackEnvRows(rows *sql.Rows) ([]StackEnv, error) {
+	envs := []StackEnv{}
+	for rows.Next() {
+		env, err := scanStackEnv(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan stack environment: %w", err)
+		}
+		envs = append(envs, env)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate stack environments: %w", err)
+	}
+	return envs, nil
+}
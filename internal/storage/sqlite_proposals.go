@@ -0,0 +1,132 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+)
+
+// proposalColumns is the column list shared by proposal queries.
+const proposalColumns = `id, container_name, stack_name, current_version, target_version, compose_file,
+	patch, patch_file, branch, pull_request_url, status, created_at, updated_at`
+
+// SaveProposal implements Storage.SaveProposal.
+// Inserts a new proposal or updates an existing one with the same ID.
+func (s *SQLiteStorage) SaveProposal(ctx context.Context, proposal Proposal) error {
+	return s.retryWithBackoff(ctx, func() error {
+		query := `
+			INSERT INTO update_proposals (` + proposalColumns + `)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			ON CONFLICT(id) DO UPDATE SET
+				patch_file = excluded.patch_file,
+				branch = excluded.branch,
+				pull_request_url = excluded.pull_request_url,
+				status = excluded.status,
+				updated_at = excluded.updated_at
+		`
+
+		createdAt := proposal.CreatedAt
+		if createdAt.IsZero() {
+			createdAt = time.Now()
+		}
+
+		_, err := s.db.ExecContext(ctx, query,
+			proposal.ID, proposal.ContainerName, proposal.StackName, proposal.CurrentVersion,
+			proposal.TargetVersion, proposal.ComposeFile, proposal.Patch, proposal.PatchFile,
+			proposal.Branch, proposal.PullRequestURL, proposal.Status, createdAt, time.Now(),
+		)
+		if err != nil {
+			log.Printf("Failed to save proposal %s: %v", proposal.ID, err)
+			return fmt.Errorf("failed to save proposal: %w", err)
+		}
+
+		log.Printf("Saved proposal: id=%s, container=%s, target=%s, status=%s",
+			proposal.ID, proposal.ContainerName, proposal.TargetVersion, proposal.Status)
+		return nil
+	})
+}
+
+// GetProposal implements Storage.GetProposal.
+// Retrieves a proposal by ID. Returns false if it does not exist.
+func (s *SQLiteStorage) GetProposal(ctx context.Context, id string) (Proposal, bool, error) {
+	query := `SELECT ` + proposalColumns + ` FROM update_proposals WHERE id = ?`
+	return s.queryProposal(ctx, query, id)
+}
+
+// GetLatestProposal implements Storage.GetLatestProposal.
+// Retrieves the most recently created proposal for a container, in any status.
+func (s *SQLiteStorage) GetLatestProposal(ctx context.Context, containerName string) (Proposal, bool, error) {
+	query := `
+		SELECT ` + proposalColumns + `
+		FROM update_proposals
+		WHERE container_name = ?
+		ORDER BY created_at DESC, rowid DESC
+		LIMIT 1
+	`
+	return s.queryProposal(ctx, query, containerName)
+}
+
+// ListProposals implements Storage.ListProposals.
+// Retrieves proposals newest first, optionally filtered by status.
+func (s *SQLiteStorage) ListProposals(ctx context.Context, status string, limit int) ([]Proposal, error) {
+	if limit <= 0 {
+		limit = 100
+	}
+
+	query := `SELECT ` + proposalColumns + ` FROM update_proposals`
+	args := []any{}
+	if status != "" {
+		query += ` WHERE status = ?`
+		args = append(args, status)
+	}
+	query += ` ORDER BY created_at DESC, rowid DESC LIMIT ?`
+	args = append(args, limit)
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		log.Printf("Failed to query proposals: %v", err)
+		return nil, fmt.Errorf("failed to query proposals: %w", err)
+	}
+	defer rows.Close()
+
+	proposals := make([]Proposal, 0)
+	for rows.Next() {
+		proposal, err := scanProposal(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan proposal: %w", err)
+		}
+		proposals = append(proposals, proposal)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating proposal rows: %w", err)
+	}
+
+	return proposals, nil
+}
+
+// queryProposal runs a single-row proposal query.
+func (s *SQLiteStorage) queryProposal(ctx context.Context, query string, arg any) (Proposal, bool, error) {
+	proposal, err := scanProposal(s.db.QueryRowContext(ctx, query, arg))
+	if err == sql.ErrNoRows {
+		return Proposal{}, false, nil
+	}
+	if err != nil {
+		log.Printf("Failed to query proposal %v: %v", arg, err)
+		return Proposal{}, false, fmt.Errorf("failed to query proposal: %w", err)
+	}
+	return proposal, true, nil
+}
+
+// scanProposal scans a row selected with proposalColumns.
+func scanProposal(row interface{ Scan(...any) error }) (Proposal, error) {
+	var proposal Proposal
+	err := row.Scan(
+		&proposal.ID, &proposal.ContainerName, &proposal.StackName, &proposal.CurrentVersion,
+		&proposal.TargetVersion, &proposal.ComposeFile, &proposal.Patch, &proposal.PatchFile,
+		&proposal.Branch, &proposal.PullRequestURL, &proposal.Status, &proposal.CreatedAt, &proposal.UpdatedAt,
+	)
+	return proposal, err
+}
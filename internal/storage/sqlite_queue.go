@@ -32,13 +32,13 @@ func (s *SQLiteStorage) QueueUpdate(ctx context.Context, queue UpdateQueue) erro
 
 		query := `
 			INSERT INTO update_queue
-			(operation_id, stack_name, containers, operation_type, target_versions, priority, queued_at, estimated_start_time)
-			VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+			(operation_id, stack_name, containers, operation_type, target_versions, all_or_nothing, priority, queued_at, estimated_start_time)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
 		`
 
 		_, err = s.db.ExecContext(ctx, query,
 			queue.OperationID, queue.StackName, string(containersJSON),
-			queue.OperationType, string(targetVersionsJSON), queue.Priority, queue.QueuedAt, queue.EstimatedStartTime)
+			queue.OperationType, string(targetVersionsJSON), queue.AllOrNothing, queue.Priority, queue.QueuedAt, queue.EstimatedStartTime)
 		if err != nil {
 			log.Printf("Failed to queue update for operation %s: %v", queue.OperationID, err)
 			return fmt.Errorf("failed to queue update: %w", err)
@@ -65,7 +65,7 @@ func (s *SQLiteStorage) DequeueUpdate(ctx context.Context, stackName string) (Up
 
 		// Find oldest queued operation for this stack
 		query := `
-			SELECT id, operation_id, stack_name, containers, operation_type, target_versions, priority, queued_at, estimated_start_time
+			SELECT id, operation_id, stack_name, containers, operation_type, target_versions, all_or_nothing, priority, queued_at, estimated_start_time
 			FROM update_queue
 			WHERE stack_name = ?
 			ORDER BY priority DESC, queued_at ASC
@@ -78,7 +78,7 @@ func (s *SQLiteStorage) DequeueUpdate(ctx context.Context, stackName string) (Up
 
 		err = tx.QueryRowContext(ctx, query, stackName).Scan(
 			&queue.ID, &queue.OperationID, &queue.StackName, &containersJSON,
-			&queue.OperationType, &targetVersionsJSON, &queue.Priority, &queue.QueuedAt, &estimatedStartTime,
+			&queue.OperationType, &targetVersionsJSON, &queue.AllOrNothing, &queue.Priority, &queue.QueuedAt, &estimatedStartTime,
 		)
 
 		if err == sql.ErrNoRows {
@@ -145,7 +145,7 @@ func (s *SQLiteStorage) DequeueUpdate(ctx context.Context, stackName string) (Up
 // Retrieves all queued operations ordered by queued_at.
 func (s *SQLiteStorage) GetQueuedUpdates(ctx context.Context) ([]UpdateQueue, error) {
 	query := `
-		SELECT id, operation_id, stack_name, containers, operation_type, target_versions, priority, queued_at, estimated_start_time
+		SELECT id, operation_id, stack_name, containers, operation_type, target_versions, all_or_nothing, priority, queued_at, estimated_start_time
 		FROM update_queue
 		ORDER BY priority DESC, queued_at ASC
 	`
@@ -166,7 +166,7 @@ func (s *SQLiteStorage) GetQueuedUpdates(ctx context.Context) ([]UpdateQueue, er
 
 		err := rows.Scan(
 			&queue.ID, &queue.OperationID, &queue.StackName, &containersJSON,
-			&queue.OperationType, &targetVersionsJSON, &queue.Priority, &queue.QueuedAt, &estimatedStartTime,
+			&queue.OperationType, &targetVersionsJSON, &queue.AllOrNothing, &queue.Priority, &queue.QueuedAt, &estimatedStartTime,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan queued update: %w", err)
@@ -202,3 +202,98 @@ func (s *SQLiteStorage) GetQueuedUpdates(ctx context.Context) ([]UpdateQueue, er
 
 	return queues, nil
 }
+
+// SetQueuePriorities implements Storage.SetQueuePriorities.
+func (s *SQLiteStorage) SetQueuePriorities(ctx context.Context, priorities map[string]int) error {
+	return s.retryWithBackoff(ctx, func() error {
+		tx, err := s.db.BeginTx(ctx, nil)
+		if err != nil {
+			return fmt.Errorf("failed to begin transaction: %w", err)
+		}
+		defer tx.Rollback()
+
+		for operationID, priority := range priorities {
+			if _, err := tx.ExecContext(ctx, `UPDATE update_queue SET priority = ? WHERE operation_id = ?`, priority, operationID); err != nil {
+				return fmt.Errorf("failed to set queue priority: %w", err)
+			}
+		}
+
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("failed to commit transaction: %w", err)
+		}
+		return nil
+	})
+}
+
+// DeleteQueuedUpdate implements Storage.DeleteQueuedUpdate.
+func (s *SQLiteStorage) DeleteQueuedUpdate(ctx context.Context, operationID string) (bool, error) {
+	var deleted bool
+	err := s.retryWithBackoff(ctx, func() error {
+		result, err := s.db.ExecContext(ctx, `DELETE FROM update_queue WHERE operation_id = ?`, operationID)
+		if err != nil {
+			return fmt.Errorf("failed to delete queued update: %w", err)
+		}
+		n, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to delete queued update: %w", err)
+		}
+		deleted = n > 0
+		return nil
+	})
+	return deleted, err
+}
+
+// SaveStackLock implements Storage.SaveStackLock.
+func (s *SQLiteStorage) SaveStackLock(ctx context.Context, lock StackLock) error {
+	return s.retryWithBackoff(ctx, func() error {
+		query := `
+			INSERT INTO stack_locks (stack_name, operation_id, acquired_at)
+			VALUES (?, ?, ?)
+			ON CONFLICT (stack_name) DO UPDATE SET operation_id = excluded.operation_id, acquired_at = excluded.acquired_at
+		`
+		if _, err := s.db.ExecContext(ctx, query, lock.StackName, lock.OperationID, lock.AcquiredAt); err != nil {
+			return fmt.Errorf("failed to save stack lock: %w", err)
+		}
+		return nil
+	})
+}
+
+// DeleteStackLock implements Storage.DeleteStackLock.
+func (s *SQLiteStorage) DeleteStackLock(ctx context.Context, stackName, operationID string) error {
+	return s.retryWithBackoff(ctx, func() error {
+		query, args := `DELETE FROM stack_locks WHERE stack_name = ?`, []any{stackName}
+		if operationID != "" {
+			query, args = query+` AND operation_id = ?`, append(args, operationID)
+		}
+		if _, err := s.db.ExecContext(ctx, query, args...); err != nil {
+			return fmt.Errorf("failed to delete stack lock: %w", err)
+		}
+		return nil
+	})
+}
+
+// ListStackLocks implements Storage.ListStackLocks.
+func (s *SQLiteStorage) ListStackLocks(ctx context.Context) ([]StackLock, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT stack_name, operation_id, acquired_at FROM stack_locks ORDER BY stack_name`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list stack locks: %w", err)
+	}
+	defer rows.Close()
+	return scanStackLocks(rows)
+}
+
+// scanStackLocks scans the rows of a stack lock query.
+func scanStackLocks(rows *sql.Rows) ([]StackLock, error) {
+	locks := []StackLock{}
+	for rows.Next() {
+		var lock StackLock
+		if err := rows.Scan(&lock.StackName, &lock.OperationID, &lock.AcquiredAt); err != nil {
+			return nil, fmt.Errorf("failed to scan stack lock: %w", err)
+		}
+		locks = append(locks, lock)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate stack locks: %w", err)
+	}
+	return locks, nil
+}
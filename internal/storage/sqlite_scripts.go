@@ -113,3 +113,127 @@ func (s *SQLiteStorage) DeleteScriptAssignment(ctx context.Context, containerNam
 		return nil
 	})
 }
+
+// scriptRevisionColumns are the columns scanned by scanScriptRevision.
+const scriptRevisionColumns = `id, name, revision, sha256, size, timeout_seconds, no_network, memory_limit_mb, cpu_seconds, created_by, created_at`
+
+// SaveScriptRevision implements Storage.SaveScriptRevision.
+func (s *SQLiteStorage) SaveScriptRevision(ctx context.Context, revision ScriptRevision) error {
+	return s.retryWithBackoff(ctx, func() error {
+		query := `
+			INSERT INTO script_revisions
+			(name, revision, sha256, size, timeout_seconds, no_network, memory_limit_mb, cpu_seconds, created_by)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		`
+
+		_, err := s.db.ExecContext(ctx, query,
+			revision.Name, revision.Revision, revision.SHA256, revision.Size, revision.TimeoutSeconds,
+			revision.NoNetwork, revision.MemoryLimitMB, revision.CPUSeconds, revision.CreatedBy)
+		if err != nil {
+			log.Printf("Failed to save script revision %s@%d: %v", revision.Name, revision.Revision, err)
+			return fmt.Errorf("failed to save script revision: %w", err)
+		}
+
+		log.Printf("Saved script revision: script=%s, revision=%d", revision.Name, revision.Revision)
+		return nil
+	})
+}
+
+// GetScriptRevision implements Storage.GetScriptRevision.
+func (s *SQLiteStorage) GetScriptRevision(ctx context.Context, name string, revision int) (ScriptRevision, bool, error) {
+	query := `SELECT ` + scriptRevisionColumns + ` FROM script_revisions
+		WHERE name = ? AND (? = 0 OR revision = ?)
+		ORDER BY revision DESC LIMIT 1`
+
+	rev, err := scanScriptRevision(s.db.QueryRowContext(ctx, query, name, revision, revision))
+	if err == sql.ErrNoRows {
+		return ScriptRevision{}, false, nil
+	}
+	if err != nil {
+		log.Printf("Failed to query script revision %s@%d: %v", name, revision, err)
+		return ScriptRevision{}, false, fmt.Errorf("failed to query script revision: %w", err)
+	}
+	return rev, true, nil
+}
+
+// ListScriptRevisions implements Storage.ListScriptRevisions.
+func (s *SQLiteStorage) ListScriptRevisions(ctx context.Context, name string) ([]ScriptRevision, error) {
+	query := `SELECT ` + scriptRevisionColumns + ` FROM script_revisions
+		WHERE ? = '' OR name = ?
+		ORDER BY name, revision DESC`
+
+	rows, err := s.db.QueryContext(ctx, query, name, name)
+	if err != nil {
+		log.Printf("Failed to query script revisions: %v", err)
+		return nil, fmt.Errorf("failed to query script revisions: %w", err)
+	}
+	defer rows.Close()
+
+	return scanScriptRevisionRows(rows)
+}
+
+// volumeSnapshotColumns are the columns scanned by scanVolumeSnapshotRows.
+const volumeSnapshotColumns = `id, operation_id, container_name, volume_name, method, location, size_bytes, created_at`
+
+// SaveVolumeSnapshot implements Storage.SaveVolumeSnapshot.
+func (s *SQLiteStorage) SaveVolumeSnapshot(ctx context.Context, snapshot VolumeSnapshot) error {
+	return s.retryWithBackoff(ctx, func() error {
+		query := `
+			INSERT INTO volume_snapshots
+			(operation_id, container_name, volume_name, method, location, size_bytes)
+			VALUES (?, ?, ?, ?, ?, ?)
+		`
+
+		_, err := s.db.ExecContext(ctx, query,
+			snapshot.OperationID, snapshot.ContainerName, snapshot.VolumeName,
+			snapshot.Method, snapshot.Location, snapshot.SizeBytes)
+		if err != nil {
+			log.Printf("Failed to save snapshot of volume %s: %v", snapshot.VolumeName, err)
+			return fmt.Errorf("failed to save volume snapshot: %w", err)
+		}
+		return nil
+	})
+}
+
+// GetVolumeSnapshots implements Storage.GetVolumeSnapshots.
+func (s *SQLiteStorage) GetVolumeSnapshots(ctx context.Context, operationID string) ([]VolumeSnapshot, error) {
+	query := `SELECT ` + volumeSnapshotColumns + ` FROM volume_snapshots
+		WHERE operation_id = ?
+		ORDER BY id`
+
+	rows, err := s.db.QueryContext(ctx, query, operationID)
+	if err != nil {
+		log.Printf("Failed to query volume snapshots of operation %s: %v", operationID, err)
+		return nil, fmt.Errorf("failed to query volume snapshots: %w", err)
+	}
+	defer rows.Close()
+
+	return scanVolumeSnapshotRows(rows)
+}
+
+// ListVolumeSnapshots implements Storage.ListVolumeSnapshots.
+func (s *SQLiteStorage) ListVolumeSnapshots(ctx context.Context, volumeName string) ([]VolumeSnapshot, error) {
+	query := `SELECT ` + volumeSnapshotColumns + ` FROM volume_snapshots
+		WHERE ? = '' OR volume_name = ?
+		ORDER BY created_at DESC, id DESC`
+
+	rows, err := s.db.QueryContext(ctx, query, volumeName, volumeName)
+	if err != nil {
+		log.Printf("Failed to query volume snapshots: %v", err)
+		return nil, fmt.Errorf("failed to query volume snapshots: %w", err)
+	}
+	defer rows.Close()
+
+	return scanVolumeSnapshotRows(rows)
+}
+
+// DeleteVolumeSnapshot implements Storage.DeleteVolumeSnapshot.
+func (s *SQLiteStorage) DeleteVolumeSnapshot(ctx context.Context, id int64) error {
+	return s.retryWithBackoff(ctx, func() error {
+		if _, err := s.db.ExecContext(ctx, `DELETE FROM volume_snapshots WHERE id = ?`, id); err != nil {
+			log.Printf("Failed to delete volume snapshot %d: %v", id, err)
+			return fmt.Errorf("failed to delete volume snapshot: %w", err)
+		}
+		return nil
+	})
+}
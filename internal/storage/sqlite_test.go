@@ -4,7 +4,11 @@ import (
 	"context"
 	"os"
 	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
 	"testing"
+	"time"
 )
 
 // TestDatabaseInitialization tests that database connection succeeds with valid path
@@ -186,3 +190,409 @@ func TestDatabaseFileCreation(t *testing.T) {
 		t.Error("Database file was not created")
 	}
 }
+
+// TestBackupVacuumAndPrune tests the database maintenance operations
+func TestBackupVacuumAndPrune(t *testing.T) {
+	tempDir := t.TempDir()
+	storage, err := NewSQLiteStorage(filepath.Join(tempDir, "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer storage.Close()
+
+	ctx := context.Background()
+	old := time.Now().AddDate(0, 0, -100)
+	recent := time.Now().AddDate(0, 0, -10)
+	for _, checkTime := range []time.Time{old, recent} {
+		if _, err := storage.db.Exec("INSERT INTO check_history (container_name, image, check_time, current_version, latest_version, status) VALUES ('plex', 'plex:1', ?, '1', '1', 'up_to_date')", checkTime); err != nil {
+			t.Fatalf("Failed to insert check: %v", err)
+		}
+		if _, err := storage.db.Exec("INSERT INTO update_log (container_name, operation, from_version, to_version, timestamp, success) VALUES ('plex', 'pull', '1', '2', ?, 1)", checkTime); err != nil {
+			t.Fatalf("Failed to insert log: %v", err)
+		}
+	}
+
+	// Backup
+	backupPath := filepath.Join(tempDir, "backup.db")
+	if err := storage.Backup(ctx, backupPath); err != nil {
+		t.Fatalf("Backup failed: %v", err)
+	}
+	if err := storage.Backup(ctx, backupPath); err == nil {
+		t.Error("Expected backup to an existing file to fail")
+	}
+	restored, err := NewSQLiteStorage(backupPath)
+	if err != nil {
+		t.Fatalf("Failed to open backup: %v", err)
+	}
+	var count int
+	restored.db.QueryRow("SELECT COUNT(*) FROM check_history").Scan(&count)
+	restored.Close()
+	if count != 2 {
+		t.Errorf("Expected 2 checks in backup, got %d", count)
+	}
+
+	// Prune only the check history
+	result, err := storage.PruneHistory(ctx, PruneOptions{CheckHistoryBefore: time.Now().AddDate(0, 0, -90)})
+	if err != nil {
+		t.Fatalf("PruneHistory failed: %v", err)
+	}
+	if result.CheckHistory != 1 || result.UpdateLog != 0 {
+		t.Errorf("Expected 1 check and 0 logs pruned, got %+v", result)
+	}
+
+	// Prune both tables with a retention policy
+	policy := RetentionPolicy{CheckHistoryDays: 5, UpdateLogDays: 30}
+	result, err = storage.PruneHistory(ctx, policy.PruneOptions(time.Now()))
+	if err != nil {
+		t.Fatalf("PruneHistory failed: %v", err)
+	}
+	if result.CheckHistory != 1 || result.UpdateLog != 1 {
+		t.Errorf("Expected 1 check and 1 log pruned, got %+v", result)
+	}
+
+	// Vacuum
+	vacuum, err := storage.Vacuum(ctx)
+	if err != nil {
+		t.Fatalf("Vacuum failed: %v", err)
+	}
+	if vacuum.SizeAfter <= 0 || vacuum.SizeAfter > vacuum.SizeBefore {
+		t.Errorf("Unexpected vacuum sizes: %+v", vacuum)
+	}
+}
+
+func TestCheckWritable(t *testing.T) {
+	storage, err := NewSQLiteStorage(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+
+	ctx := context.Background()
+	if err := storage.CheckWritable(ctx); err != nil {
+		t.Fatalf("CheckWritable failed: %v", err)
+	}
+	if _, found, _ := storage.GetConfig(ctx, "__write_check__"); found {
+		t.Error("CheckWritable left a config entry behind")
+	}
+
+	storage.Close()
+	if err := storage.CheckWritable(ctx); err == nil {
+		t.Error("Expected CheckWritable to fail on a closed database")
+	}
+}
+
+// TestGetRetentionPolicy tests reading the retention policy from config
+func TestGetRetentionPolicy(t *testing.T) {
+	storage, err := NewSQLiteStorage(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer storage.Close()
+
+	ctx := context.Background()
+	policy, err := GetRetentionPolicy(ctx, storage)
+	if err != nil || policy.IsSet() {
+		t.Fatalf("Expected no policy, got %+v (%v)", policy, err)
+	}
+
+	storage.SetConfig(ctx, CheckHistoryRetentionConfigKey, "30")
+	policy, err = GetRetentionPolicy(ctx, storage)
+	if err != nil || policy != (RetentionPolicy{CheckHistoryDays: 30}) {
+		t.Errorf("Expected 30 days of check history, got %+v (%v)", policy, err)
+	}
+
+	storage.SetConfig(ctx, UpdateLogRetentionConfigKey, "forever")
+	if _, err := GetRetentionPolicy(ctx, storage); err == nil {
+		t.Error("Expected an error for an invalid retention value")
+	}
+}
+
+func TestScriptRevisions(t *testing.T) {
+	storage, err := NewSQLiteStorage(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer storage.Close()
+
+	ctx := context.Background()
+	for rev := 1; rev <= 2; rev++ {
+		if err := storage.SaveScriptRevision(ctx, ScriptRevision{Name: "check.sh", Revision: rev, SHA256: "abc", NoNetwork: rev == 2}); err != nil {
+			t.Fatalf("SaveScriptRevision failed: %v", err)
+		}
+	}
+	if err := storage.SaveScriptRevision(ctx, ScriptRevision{Name: "check.sh", Revision: 2, SHA256: "def"}); err == nil {
+		t.Error("Expected saving an existing revision to fail")
+	}
+
+	latest, found, err := storage.GetScriptRevision(ctx, "check.sh", 0)
+	if err != nil || !found || latest.Revision != 2 || !latest.NoNetwork {
+		t.Errorf("Expected latest revision 2 without network, got %+v (found=%v, err=%v)", latest, found, err)
+	}
+	first, found, _ := storage.GetScriptRevision(ctx, "check.sh", 1)
+	if !found || first.Revision != 1 {
+		t.Errorf("Expected revision 1, got %+v (found=%v)", first, found)
+	}
+	if _, found, _ := storage.GetScriptRevision(ctx, "check.sh", 3); found {
+		t.Error("Expected revision 3 not to exist")
+	}
+
+	revisions, err := storage.ListScriptRevisions(ctx, "check.sh")
+	if err != nil || len(revisions) != 2 || revisions[0].Revision != 2 {
+		t.Errorf("Expected 2 revisions newest first, got %+v (%v)", revisions, err)
+	}
+	if all, _ := storage.ListScriptRevisions(ctx, ""); len(all) != 2 {
+		t.Errorf("Expected 2 revisions in total, got %d", len(all))
+	}
+}
+
+func TestVolumeSnapshots(t *testing.T) {
+	storage, err := NewSQLiteStorage(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer storage.Close()
+
+	ctx := context.Background()
+	for _, snapshot := range []VolumeSnapshot{
+		{OperationID: "op-1", ContainerName: "db", VolumeName: "dbdata", Method: "tar", Location: "/backups/db/dbdata-1.tar.gz", SizeBytes: 1024},
+		{OperationID: "op-2", ContainerName: "db", VolumeName: "dbdata", Method: "hook", Location: "docksmith-2"},
+		{OperationID: "op-2", ContainerName: "db", VolumeName: "dblogs", Method: "hook", Location: "docksmith-2"},
+	} {
+		if err := storage.SaveVolumeSnapshot(ctx, snapshot); err != nil {
+			t.Fatalf("SaveVolumeSnapshot failed: %v", err)
+		}
+	}
+
+	snapshots, err := storage.GetVolumeSnapshots(ctx, "op-1")
+	if err != nil || len(snapshots) != 1 || snapshots[0].SizeBytes != 1024 || snapshots[0].Method != "tar" {
+		t.Errorf("Expected the tar snapshot of op-1, got %+v (%v)", snapshots, err)
+	}
+
+	byVolume, err := storage.ListVolumeSnapshots(ctx, "dbdata")
+	if err != nil || len(byVolume) != 2 || byVolume[0].OperationID != "op-2" {
+		t.Fatalf("Expected 2 dbdata snapshots newest first, got %+v (%v)", byVolume, err)
+	}
+	if all, _ := storage.ListVolumeSnapshots(ctx, ""); len(all) != 3 {
+		t.Errorf("Expected 3 snapshots in total, got %d", len(all))
+	}
+
+	if err := storage.DeleteVolumeSnapshot(ctx, byVolume[1].ID); err != nil {
+		t.Fatalf("DeleteVolumeSnapshot failed: %v", err)
+	}
+	if remaining, _ := storage.GetVolumeSnapshots(ctx, "op-1"); len(remaining) != 0 {
+		t.Errorf("Expected the op-1 snapshot to be deleted, got %+v", remaining)
+	}
+}
+
+func TestStackLocks(t *testing.T) {
+	storage, err := NewSQLiteStorage(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer storage.Close()
+
+	ctx := context.Background()
+	acquired := time.Now().UTC().Truncate(time.Second)
+	for _, lock := range []StackLock{
+		{StackName: "media", OperationID: "op-1", AcquiredAt: acquired},
+		{StackName: "media", OperationID: "op-2", AcquiredAt: acquired},
+		{StackName: "auth", OperationID: "op-3", AcquiredAt: acquired},
+	} {
+		if err := storage.SaveStackLock(ctx, lock); err != nil {
+			t.Fatalf("SaveStackLock failed: %v", err)
+		}
+	}
+
+	locks, err := storage.ListStackLocks(ctx)
+	if err != nil {
+		t.Fatalf("ListStackLocks failed: %v", err)
+	}
+	if len(locks) != 2 || locks[0].StackName != "auth" || locks[1].OperationID != "op-2" || !locks[1].AcquiredAt.Equal(acquired) {
+		t.Fatalf("Unexpected locks: %+v", locks)
+	}
+
+	// Only the holder's record is removed
+	if err := storage.DeleteStackLock(ctx, "media", "op-1"); err != nil {
+		t.Fatalf("DeleteStackLock failed: %v", err)
+	}
+	if locks, _ := storage.ListStackLocks(ctx); len(locks) != 2 {
+		t.Errorf("Expected the media lock to remain, got %+v", locks)
+	}
+	if err := storage.DeleteStackLock(ctx, "media", ""); err != nil {
+		t.Fatalf("DeleteStackLock failed: %v", err)
+	}
+	if locks, _ := storage.ListStackLocks(ctx); len(locks) != 1 || locks[0].StackName != "auth" {
+		t.Errorf("Expected only the auth lock, got %+v", locks)
+	}
+}
+
+func TestComposeBackupsAndUnfinishedOperations(t *testing.T) {
+	storage, err := NewSQLiteStorage(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer storage.Close()
+
+	ctx := context.Background()
+	for _, path := range []string{"/stacks/media/.compose.yaml.backup.1", "/stacks/media/.compose.yaml.backup.2"} {
+		if _, err := storage.db.ExecContext(ctx, `
+			INSERT INTO compose_backups (operation_id, container_name, stack_name, compose_file_path, backup_file_path)
+			VALUES ('op-1', 'plex', 'media', '/stacks/media/compose.yaml', ?)`, path); err != nil {
+			t.Fatalf("Failed to insert compose backup: %v", err)
+		}
+	}
+
+	backups, err := storage.ListComposeBackups(ctx)
+	if err != nil {
+		t.Fatalf("ListComposeBackups failed: %v", err)
+	}
+	if len(backups) != 2 || backups[0].StackName != "media" || backups[1].BackupFilePath != "/stacks/media/.compose.yaml.backup.2" {
+		t.Fatalf("Unexpected backups: %+v", backups)
+	}
+	deleted, err := storage.DeleteComposeBackups(ctx, []int64{backups[0].ID, 999})
+	if err != nil || deleted != 1 {
+		t.Fatalf("Expected 1 backup deleted, got %d (err=%v)", deleted, err)
+	}
+	if backups, _ := storage.ListComposeBackups(ctx); len(backups) != 1 {
+		t.Errorf("Expected 1 backup left, got %+v", backups)
+	}
+
+	for _, op := range []UpdateOperation{
+		{OperationID: "op-1", ContainerName: "plex", OperationType: "single", Status: StatusComplete},
+		{OperationID: "op-2", ContainerName: "plex", OperationType: "single", Status: "cancelled"},
+		{OperationID: "op-3", ContainerName: "plex", OperationType: "single", Status: StatusPaused},
+		{OperationID: "op-4", ContainerName: "plex", OperationType: "single", Status: StatusPullingImage},
+	} {
+		if err := storage.SaveUpdateOperation(ctx, op); err != nil {
+			t.Fatalf("SaveUpdateOperation failed: %v", err)
+		}
+	}
+	unfinished, err := storage.GetUnfinishedOperations(ctx)
+	if err != nil {
+		t.Fatalf("GetUnfinishedOperations failed: %v", err)
+	}
+	if len(unfinished) != 2 || unfinished[0].OperationID != "op-3" || unfinished[1].OperationID != "op-4" {
+		t.Errorf("Expected the paused and pulling operations, got %+v", unfinished)
+	}
+}
+
+func TestQueuePriorities(t *testing.T) {
+	storage, err := NewSQLiteStorage(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer storage.Close()
+
+	ctx := context.Background()
+	queuedAt := time.Now()
+	for i, id := range []string{"op-1", "op-2", "op-3"} {
+		queue := UpdateQueue{OperationID: id, StackName: "media", Containers: []string{"plex"}, QueuedAt: queuedAt.Add(time.Duration(i) * time.Second)}
+		if err := storage.QueueUpdate(ctx, queue); err != nil {
+			t.Fatalf("QueueUpdate failed: %v", err)
+		}
+	}
+
+	if err := storage.SetQueuePriorities(ctx, map[string]int{"op-3": 2, "op-1": 1, "missing": 5}); err != nil {
+		t.Fatalf("SetQueuePriorities failed: %v", err)
+	}
+	queued, err := storage.GetQueuedUpdates(ctx)
+	if err != nil {
+		t.Fatalf("GetQueuedUpdates failed: %v", err)
+	}
+	if len(queued) != 3 || queued[0].OperationID != "op-3" || queued[1].OperationID != "op-1" || queued[0].Priority != 2 {
+		t.Fatalf("Unexpected queue order: %+v", queued)
+	}
+
+	if deleted, err := storage.DeleteQueuedUpdate(ctx, "op-1"); err != nil || !deleted {
+		t.Fatalf("DeleteQueuedUpdate = %v, %v; want true", deleted, err)
+	}
+	if deleted, _ := storage.DeleteQueuedUpdate(ctx, "op-1"); deleted {
+		t.Errorf("Expected op-1 to be gone")
+	}
+	if queued, _ := storage.GetQueuedUpdates(ctx); len(queued) != 2 {
+		t.Errorf("Expected 2 queued updates, got %+v", queued)
+	}
+}
+
+func TestOperationTypes(t *testing.T) {
+	storage, err := NewSQLiteStorage(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer storage.Close()
+
+	ctx := context.Background()
+	for _, opType := range []string{"pin", "rebuild", "simulate", "restore_volumes", "variant"} {
+		op := UpdateOperation{OperationID: "op-" + opType, ContainerName: "nginx", OperationType: opType, Status: StatusComplete}
+		if err := storage.SaveUpdateOperation(ctx, op); err != nil {
+			t.Errorf("SaveUpdateOperation(%s) failed: %v", opType, err)
+		}
+	}
+
+	result, err := storage.QueryUpdateOperations(ctx, OperationQueryOptions{Type: "updates", Limit: 10})
+	if err != nil {
+		t.Fatalf("QueryUpdateOperations failed: %v", err)
+	}
+	if len(result.Operations) != 2 {
+		t.Errorf("Expected the pin and variant operations to count as updates, got %+v", result.Operations)
+	}
+}
+
+// operationTypeCheck matches the operation_type CHECK constraint of a migration.
+var operationTypeCheck = regexp.MustCompile(`CHECK\(operation_type IN \(([^)]*)\)\)`)
+
+// postgresOperationTypes returns the operation types the PostgreSQL migrations
+// leave allowed, from the constraint of the last migration that sets it.
+func postgresOperationTypes(t *testing.T) []string {
+	t.Helper()
+	entries, err := postgresMigrationsFS.ReadDir("migrations_postgres")
+	if err != nil {
+		t.Fatalf("Failed to read migrations: %v", err)
+	}
+
+	var types []string
+	for _, entry := range entries {
+		if !strings.HasSuffix(entry.Name(), ".up.sql") {
+			continue
+		}
+		data, err := postgresMigrationsFS.ReadFile("migrations_postgres/" + entry.Name())
+		if err != nil {
+			t.Fatalf("Failed to read migration %s: %v", entry.Name(), err)
+		}
+		if match := operationTypeCheck.FindSubmatch(data); match != nil {
+			types = nil
+			for _, quoted := range strings.Split(string(match[1]), ",") {
+				types = append(types, strings.Trim(strings.TrimSpace(quoted), "'"))
+			}
+		}
+	}
+	return types
+}
+
+// TestOperationTypeMigrations tests that the migrations of both backends allow
+// the operation types saved outside the original constraint.
+func TestOperationTypeMigrations(t *testing.T) {
+	storage, err := NewSQLiteStorage(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer storage.Close()
+
+	ctx := context.Background()
+	postgresTypes := postgresOperationTypes(t)
+	for _, opType := range []string{"pin", "rebuild", "restore_volumes", "simulate", "variant"} {
+		t.Run(opType, func(t *testing.T) {
+			op := UpdateOperation{OperationID: "op-" + opType, ContainerName: "nginx", OperationType: opType, Status: StatusComplete}
+			if err := storage.SaveUpdateOperation(ctx, op); err != nil {
+				t.Fatalf("SaveUpdateOperation failed: %v", err)
+			}
+			saved, found, err := storage.GetUpdateOperation(ctx, op.OperationID)
+			if err != nil || !found || saved.OperationType != opType {
+				t.Errorf("GetUpdateOperation = %+v, %v, %v; want type %s", saved, found, err, opType)
+			}
+
+			if !slices.Contains(postgresTypes, opType) {
+				t.Errorf("PostgreSQL migrations do not allow %s, only %v", opType, postgresTypes)
+			}
+		})
+	}
+}
@@ -111,18 +111,39 @@ func (s *SQLiteStorage) SaveUpdateOperation(ctx context.Context, op UpdateOperat
 			}
 		}
 
+		var signaturesJSON []byte
+		if len(op.SignatureVerifications) > 0 {
+			signaturesJSON, err = json.Marshal(op.SignatureVerifications)
+			if err != nil {
+				log.Printf("Failed to serialize signature verifications: %v", err)
+				return fmt.Errorf("failed to serialize signature verifications: %w", err)
+			}
+		}
+
+		var observationsJSON []byte
+		if len(op.Observations) > 0 {
+			observationsJSON, err = json.Marshal(op.Observations)
+			if err != nil {
+				log.Printf("Failed to serialize observations: %v", err)
+				return fmt.Errorf("failed to serialize observations: %w", err)
+			}
+		}
+
 		query := `
 			INSERT OR REPLACE INTO update_operations
 			(operation_id, container_id, container_name, stack_name, operation_type, status,
 			 old_version, new_version, started_at, completed_at, error_message,
-			 dependents_affected, rollback_occurred, batch_details, batch_group_id, created_at, updated_at)
-			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, COALESCE((SELECT created_at FROM update_operations WHERE operation_id = ?), CURRENT_TIMESTAMP), CURRENT_TIMESTAMP)
+			 dependents_affected, rollback_occurred, batch_details, batch_group_id, check_output, all_or_nothing, signature_verifications, observations, compose_output, triggered_by, created_at, updated_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?,
+				(SELECT compose_output FROM update_operations WHERE operation_id = ?),
+				COALESCE(NULLIF(?, ''), (SELECT triggered_by FROM update_operations WHERE operation_id = ?)),
+				COALESCE((SELECT created_at FROM update_operations WHERE operation_id = ?), CURRENT_TIMESTAMP), CURRENT_TIMESTAMP)
 		`
 
 		_, err = s.db.ExecContext(ctx, query,
 			op.OperationID, op.ContainerID, op.ContainerName, op.StackName, op.OperationType, op.Status,
 			op.OldVersion, op.NewVersion, op.StartedAt, op.CompletedAt, op.ErrorMessage,
-			string(dependentsJSON), op.RollbackOccurred, string(batchDetailsJSON), op.BatchGroupID, op.OperationID)
+			string(dependentsJSON), op.RollbackOccurred, string(batchDetailsJSON), op.BatchGroupID, op.CheckOutput, op.AllOrNothing, string(signaturesJSON), string(observationsJSON), op.OperationID, op.TriggeredBy, op.OperationID, op.OperationID)
 		if err != nil {
 			log.Printf("Failed to save update operation %s: %v", op.OperationID, err)
 			return fmt.Errorf("failed to save update operation: %w", err)
@@ -140,22 +161,22 @@ func (s *SQLiteStorage) GetUpdateOperation(ctx context.Context, operationID stri
 	query := `
 		SELECT id, operation_id, container_id, container_name, stack_name, operation_type, status,
 		       old_version, new_version, started_at, completed_at, error_message,
-		       dependents_affected, rollback_occurred, batch_details, batch_group_id, created_at, updated_at
+		       dependents_affected, rollback_occurred, batch_details, batch_group_id, check_output, all_or_nothing, signature_verifications, observations, compose_output, triggered_by, created_at, updated_at
 		FROM update_operations
 		WHERE operation_id = ?
 	`
 
 	var op UpdateOperation
 	var dependentsJSON string
-	var batchDetailsJSON sql.NullString
-	var batchGroupID sql.NullString
+	var batchDetailsJSON, signaturesJSON, observationsJSON sql.NullString
+	var batchGroupID, checkOutput, composeOutput, triggeredBy sql.NullString
 	var startedAt, completedAt sql.NullTime
 	var containerID, stackName, oldVersion, newVersion, errorMessage sql.NullString
 
 	err := s.db.QueryRowContext(ctx, query, operationID).Scan(
 		&op.ID, &op.OperationID, &containerID, &op.ContainerName, &stackName, &op.OperationType, &op.Status,
 		&oldVersion, &newVersion, &startedAt, &completedAt, &errorMessage,
-		&dependentsJSON, &op.RollbackOccurred, &batchDetailsJSON, &batchGroupID, &op.CreatedAt, &op.UpdatedAt,
+		&dependentsJSON, &op.RollbackOccurred, &batchDetailsJSON, &batchGroupID, &checkOutput, &op.AllOrNothing, &signaturesJSON, &observationsJSON, &composeOutput, &triggeredBy, &op.CreatedAt, &op.UpdatedAt,
 	)
 
 	if err == sql.ErrNoRows {
@@ -192,6 +213,9 @@ func (s *SQLiteStorage) GetUpdateOperation(ctx context.Context, operationID stri
 	if batchGroupID.Valid {
 		op.BatchGroupID = batchGroupID.String
 	}
+	op.CheckOutput = checkOutput.String
+	op.ComposeOutput = composeOutput.String
+	op.TriggeredBy = triggeredBy.String
 
 	// Deserialize dependents affected from JSON
 	if dependentsJSON != "" {
@@ -211,6 +235,24 @@ func (s *SQLiteStorage) GetUpdateOperation(ctx context.Context, operationID stri
 		}
 	}
 
+	// Deserialize signature verifications from JSON
+	if signaturesJSON.Valid && signaturesJSON.String != "" {
+		err = json.Unmarshal([]byte(signaturesJSON.String), &op.SignatureVerifications)
+		if err != nil {
+			log.Printf("Failed to deserialize signature verifications for operation %s: %v", operationID, err)
+			return UpdateOperation{}, false, fmt.Errorf("failed to deserialize signature verifications: %w", err)
+		}
+	}
+
+	// Deserialize observations from JSON
+	if observationsJSON.Valid && observationsJSON.String != "" {
+		err = json.Unmarshal([]byte(observationsJSON.String), &op.Observations)
+		if err != nil {
+			log.Printf("Failed to deserialize observations for operation %s: %v", operationID, err)
+			return UpdateOperation{}, false, fmt.Errorf("failed to deserialize observations: %w", err)
+		}
+	}
+
 	log.Printf("Retrieved update operation: %s [%s] (status: %s)", op.OperationID, op.ContainerName, op.Status)
 	return op, true, nil
 }
@@ -222,7 +264,7 @@ func (s *SQLiteStorage) GetUpdateOperationsByStatus(ctx context.Context, status
 	baseQuery := `
 		SELECT id, operation_id, container_id, container_name, stack_name, operation_type, status,
 		       old_version, new_version, started_at, completed_at, error_message,
-		       dependents_affected, rollback_occurred, batch_details, batch_group_id, created_at, updated_at
+		       dependents_affected, rollback_occurred, batch_details, batch_group_id, check_output, all_or_nothing, signature_verifications, observations, compose_output, triggered_by, created_at, updated_at
 		FROM update_operations
 		WHERE status = ?
 		ORDER BY created_at DESC
@@ -245,7 +287,7 @@ func (s *SQLiteStorage) GetUpdateOperationsByContainer(ctx context.Context, cont
 	baseQuery := `
 		SELECT id, operation_id, container_id, container_name, stack_name, operation_type, status,
 		       old_version, new_version, started_at, completed_at, error_message,
-		       dependents_affected, rollback_occurred, batch_details, batch_group_id, created_at, updated_at
+		       dependents_affected, rollback_occurred, batch_details, batch_group_id, check_output, all_or_nothing, signature_verifications, observations, compose_output, triggered_by, created_at, updated_at
 		FROM update_operations
 		WHERE container_name = ?
 		ORDER BY started_at DESC
@@ -268,7 +310,7 @@ func (s *SQLiteStorage) GetUpdateOperationsByTimeRange(ctx context.Context, star
 	query := `
 		SELECT id, operation_id, container_id, container_name, stack_name, operation_type, status,
 		       old_version, new_version, started_at, completed_at, error_message,
-		       dependents_affected, rollback_occurred, batch_details, batch_group_id, created_at, updated_at
+		       dependents_affected, rollback_occurred, batch_details, batch_group_id, check_output, all_or_nothing, signature_verifications, observations, compose_output, triggered_by, created_at, updated_at
 		FROM update_operations
 		WHERE started_at >= ? AND started_at <= ?
 		ORDER BY started_at DESC
@@ -315,6 +357,23 @@ func (s *SQLiteStorage) UpdateOperationStatus(ctx context.Context, operationID s
 	})
 }
 
+// AppendComposeOutput implements Storage.AppendComposeOutput.
+func (s *SQLiteStorage) AppendComposeOutput(ctx context.Context, operationID string, output string) error {
+	return s.retryWithBackoff(ctx, func() error {
+		query := `
+			UPDATE update_operations
+			SET compose_output = substr(COALESCE(compose_output, '') || ?, -?), updated_at = CURRENT_TIMESTAMP
+			WHERE operation_id = ?
+		`
+
+		if _, err := s.db.ExecContext(ctx, query, output, MaxComposeOutput, operationID); err != nil {
+			log.Printf("Failed to append compose output for %s: %v", operationID, err)
+			return fmt.Errorf("failed to append compose output: %w", err)
+		}
+		return nil
+	})
+}
+
 // GetUpdateOperations implements Storage.GetUpdateOperations.
 // Retrieves update operations for history display.
 // Returns entries ordered by started_at DESC (most recent first).
@@ -323,7 +382,7 @@ func (s *SQLiteStorage) GetUpdateOperations(ctx context.Context, limit int) ([]U
 	baseQuery := `
 		SELECT id, operation_id, container_id, container_name, stack_name, operation_type, status,
 		       old_version, new_version, started_at, completed_at, error_message,
-		       dependents_affected, rollback_occurred, batch_details, batch_group_id, created_at, updated_at
+		       dependents_affected, rollback_occurred, batch_details, batch_group_id, check_output, all_or_nothing, signature_verifications, observations, compose_output, triggered_by, created_at, updated_at
 		FROM update_operations
 		WHERE status IN ('complete', 'failed')
 		ORDER BY started_at DESC
@@ -360,10 +419,16 @@ func (s *SQLiteStorage) QueryUpdateOperations(ctx context.Context, opts Operatio
 		args = append(args, opts.Container)
 	}
 
+	// Stack filter
+	if opts.Stack != "" {
+		conditions = append(conditions, "stack_name = ?")
+		args = append(args, opts.Stack)
+	}
+
 	// Type filter
 	if opts.Type != "" {
 		if opts.Type == "updates" {
-			conditions = append(conditions, "operation_type IN ('single', 'batch', 'stack')")
+			conditions = append(conditions, "operation_type IN ('single', 'batch', 'stack', 'pin', 'variant')")
 		} else {
 			conditions = append(conditions, "operation_type = ?")
 			args = append(args, opts.Type)
@@ -405,7 +470,7 @@ func (s *SQLiteStorage) QueryUpdateOperations(ctx context.Context, opts Operatio
 	query := fmt.Sprintf(`
 		SELECT id, operation_id, container_id, container_name, stack_name, operation_type, status,
 		       old_version, new_version, started_at, completed_at, error_message,
-		       dependents_affected, rollback_occurred, batch_details, batch_group_id, created_at, updated_at
+		       dependents_affected, rollback_occurred, batch_details, batch_group_id, check_output, all_or_nothing, signature_verifications, observations, compose_output, triggered_by, created_at, updated_at
 		FROM update_operations
 		%s
 		ORDER BY started_at DESC
@@ -456,6 +521,11 @@ func (s *SQLiteStorage) DeleteAllHistory(ctx context.Context) (int64, error) {
 		}
 		n1, _ := r1.RowsAffected()
 
+		// Step logs go with their operations
+		if _, err := tx.ExecContext(ctx, deleteOrphanedOperationLogs); err != nil {
+			return fmt.Errorf("failed to delete operation logs: %w", err)
+		}
+
 		r2, err := tx.ExecContext(ctx, "DELETE FROM check_history")
 		if err != nil {
 			return fmt.Errorf("failed to delete check history: %w", err)
@@ -496,6 +566,11 @@ func (s *SQLiteStorage) DeleteHistoryBefore(ctx context.Context, before time.Tim
 		}
 		n1, _ := r1.RowsAffected()
 
+		// Step logs go with their operations
+		if _, err := tx.ExecContext(ctx, deleteOrphanedOperationLogs); err != nil {
+			return fmt.Errorf("failed to delete operation logs: %w", err)
+		}
+
 		r2, err := tx.ExecContext(ctx, "DELETE FROM check_history WHERE check_time < ?", before)
 		if err != nil {
 			return fmt.Errorf("failed to delete check history: %w", err)
@@ -525,7 +600,7 @@ func (s *SQLiteStorage) GetUpdateOperationsByBatchGroup(ctx context.Context, bat
 	query := `
 		SELECT id, operation_id, container_id, container_name, stack_name, operation_type, status,
 		       old_version, new_version, started_at, completed_at, error_message,
-		       dependents_affected, rollback_occurred, batch_details, batch_group_id, created_at, updated_at
+		       dependents_affected, rollback_occurred, batch_details, batch_group_id, check_output, all_or_nothing, signature_verifications, observations, compose_output, triggered_by, created_at, updated_at
 		FROM update_operations
 		WHERE batch_group_id = ?
 		ORDER BY started_at ASC
@@ -540,3 +615,65 @@ func (s *SQLiteStorage) GetUpdateOperationsByBatchGroup(ctx context.Context, bat
 
 	return scanUpdateOperationRows(rows)
 }
+
+// GetUnfinishedOperations implements Storage.GetUnfinishedOperations.
+func (s *SQLiteStorage) GetUnfinishedOperations(ctx context.Context) ([]UpdateOperation, error) {
+	query := `
+		SELECT id, operation_id, container_id, container_name, stack_name, operation_type, status,
+		       old_version, new_version, started_at, completed_at, error_message,
+		       dependents_affected, rollback_occurred, batch_details, batch_group_id, check_output, all_or_nothing, signature_verifications, observations, compose_output, triggered_by, created_at, updated_at
+		FROM update_operations
+		WHERE status NOT IN ('complete', 'failed', 'cancelled')
+		ORDER BY created_at ASC
+	`
+
+	rows, err := s.db.QueryContext(ctx, query)
+	if err != nil {
+		log.Printf("Failed to query unfinished update operations: %v", err)
+		return nil, fmt.Errorf("failed to query unfinished update operations: %w", err)
+	}
+	defer rows.Close()
+
+	return scanUpdateOperationRows(rows)
+}
+
+// deleteOrphanedOperationLogs deletes the step logs of deleted operations.
+const deleteOrphanedOperationLogs = `DELETE FROM operation_logs WHERE operation_id NOT IN (SELECT operation_id FROM update_operations)`
+
+// operationLogColumns are the columns scanned by scanOperationLogRows.
+const operationLogColumns = `id, operation_id, container_name, source, message, created_at`
+
+// AppendOperationLog implements Storage.AppendOperationLog.
+func (s *SQLiteStorage) AppendOperationLog(ctx context.Context, entry OperationLogEntry) (int64, error) {
+	var id int64
+	err := s.retryWithBackoff(ctx, func() error {
+		result, err := s.db.ExecContext(ctx,
+			`INSERT INTO operation_logs (operation_id, container_name, source, message) VALUES (?, ?, ?, ?)`,
+			entry.OperationID, entry.ContainerName, entry.Source, entry.Message)
+		if err != nil {
+			return fmt.Errorf("failed to append operation log: %w", err)
+		}
+		id, err = result.LastInsertId()
+		if err != nil {
+			return fmt.Errorf("failed to get operation log id: %w", err)
+		}
+		return nil
+	})
+	return id, err
+}
+
+// GetOperationLogs implements Storage.GetOperationLogs.
+func (s *SQLiteStorage) GetOperationLogs(ctx context.Context, operationID string, afterID int64) ([]OperationLogEntry, error) {
+	query := `SELECT ` + operationLogColumns + ` FROM operation_logs
+		WHERE operation_id = ? AND id > ?
+		ORDER BY id`
+
+	rows, err := s.db.QueryContext(ctx, query, operationID, afterID)
+	if err != nil {
+		log.Printf("Failed to query logs of operation %s: %v", operationID, err)
+		return nil, fmt.Errorf("failed to query operation logs: %w", err)
+	}
+	defer rows.Close()
+
+	return scanOperationLogRows(rows)
+}
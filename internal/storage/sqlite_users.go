@@ -0,0 +1,255 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+)
+
+// CreateUser implements Storage.CreateUser.
+// Inserts a new user and returns the stored record.
+func (s *SQLiteStorage) CreateUser(ctx context.Context, user User) (User, error) {
+	err := s.retryWithBackoff(ctx, func() error {
+		query := `
+			INSERT INTO users (username, password_hash, role, created_at, updated_at)
+			VALUES (?, ?, ?, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+		`
+
+		result, err := s.db.ExecContext(ctx, query, user.Username, user.PasswordHash, user.Role)
+		if err != nil {
+			log.Printf("Failed to create user %s: %v", user.Username, err)
+			return fmt.Errorf("failed to create user: %w", err)
+		}
+
+		user.ID, err = result.LastInsertId()
+		if err != nil {
+			return fmt.Errorf("failed to get user id: %w", err)
+		}
+
+		log.Printf("Created user: username=%s, role=%s", user.Username, user.Role)
+		return nil
+	})
+	if err != nil {
+		return User{}, err
+	}
+
+	created, _, err := s.GetUserByID(ctx, user.ID)
+	return created, err
+}
+
+// GetUser implements Storage.GetUser.
+// Retrieves a user by username. Returns false if the user does not exist.
+func (s *SQLiteStorage) GetUser(ctx context.Context, username string) (User, bool, error) {
+	query := `
+		SELECT id, username, password_hash, role, created_at, updated_at
+		FROM users
+		WHERE username = ?
+	`
+	return s.queryUser(ctx, query, username)
+}
+
+// GetUserByID implements Storage.GetUserByID.
+// Retrieves a user by ID. Returns false if the user does not exist.
+func (s *SQLiteStorage) GetUserByID(ctx context.Context, id int64) (User, bool, error) {
+	query := `
+		SELECT id, username, password_hash, role, created_at, updated_at
+		FROM users
+		WHERE id = ?
+	`
+	return s.queryUser(ctx, query, id)
+}
+
+// queryUser runs a single-row user query.
+func (s *SQLiteStorage) queryUser(ctx context.Context, query string, arg any) (User, bool, error) {
+	var user User
+	err := s.db.QueryRowContext(ctx, query, arg).Scan(
+		&user.ID, &user.Username, &user.PasswordHash, &user.Role, &user.CreatedAt, &user.UpdatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return User{}, false, nil
+	}
+	if err != nil {
+		log.Printf("Failed to query user %v: %v", arg, err)
+		return User{}, false, fmt.Errorf("failed to query user: %w", err)
+	}
+	return user, true, nil
+}
+
+// ListUsers implements Storage.ListUsers.
+// Retrieves all users ordered by username.
+func (s *SQLiteStorage) ListUsers(ctx context.Context) ([]User, error) {
+	query := `
+		SELECT id, username, password_hash, role, created_at, updated_at
+		FROM users
+		ORDER BY username
+	`
+
+	rows, err := s.db.QueryContext(ctx, query)
+	if err != nil {
+		log.Printf("Failed to query users: %v", err)
+		return nil, fmt.Errorf("failed to query users: %w", err)
+	}
+	defer rows.Close()
+
+	users := make([]User, 0)
+	for rows.Next() {
+		var user User
+		if err := rows.Scan(&user.ID, &user.Username, &user.PasswordHash, &user.Role, &user.CreatedAt, &user.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan user: %w", err)
+		}
+		users = append(users, user)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating user rows: %w", err)
+	}
+
+	return users, nil
+}
+
+// UpdateUser implements Storage.UpdateUser.
+// Updates role and password hash for an existing user.
+func (s *SQLiteStorage) UpdateUser(ctx context.Context, user User) error {
+	return s.retryWithBackoff(ctx, func() error {
+		query := `
+			UPDATE users
+			SET role = ?, password_hash = ?, updated_at = CURRENT_TIMESTAMP
+			WHERE username = ?
+		`
+
+		result, err := s.db.ExecContext(ctx, query, user.Role, user.PasswordHash, user.Username)
+		if err != nil {
+			log.Printf("Failed to update user %s: %v", user.Username, err)
+			return fmt.Errorf("failed to update user: %w", err)
+		}
+
+		rows, _ := result.RowsAffected()
+		if rows == 0 {
+			return fmt.Errorf("user %s not found", user.Username)
+		}
+
+		log.Printf("Updated user: username=%s, role=%s", user.Username, user.Role)
+		return nil
+	})
+}
+
+// DeleteUser implements Storage.DeleteUser.
+// Removes the user, their sessions, team memberships, and ownerships in a
+// single transaction.
+func (s *SQLiteStorage) DeleteUser(ctx context.Context, username string) error {
+	return s.retryWithBackoff(ctx, func() error {
+		tx, err := s.db.BeginTx(ctx, nil)
+		if err != nil {
+			return fmt.Errorf("failed to begin transaction: %w", err)
+		}
+
+		_, err = tx.ExecContext(ctx, `DELETE FROM sessions WHERE user_id = (SELECT id FROM users WHERE username = ?)`, username)
+		if err != nil {
+			tx.Rollback()
+			return fmt.Errorf("failed to delete user sessions: %w", err)
+		}
+
+		_, err = tx.ExecContext(ctx, `DELETE FROM team_members WHERE username = ?`, username)
+		if err != nil {
+			tx.Rollback()
+			return fmt.Errorf("failed to delete user team memberships: %w", err)
+		}
+
+		_, err = tx.ExecContext(ctx, `DELETE FROM ownership WHERE owner = ?`, "user:"+username)
+		if err != nil {
+			tx.Rollback()
+			return fmt.Errorf("failed to delete user ownerships: %w", err)
+		}
+
+		result, err := tx.ExecContext(ctx, `DELETE FROM users WHERE username = ?`, username)
+		if err != nil {
+			tx.Rollback()
+			log.Printf("Failed to delete user %s: %v", username, err)
+			return fmt.Errorf("failed to delete user: %w", err)
+		}
+
+		rows, _ := result.RowsAffected()
+		if rows == 0 {
+			tx.Rollback()
+			return fmt.Errorf("user %s not found", username)
+		}
+
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("failed to commit transaction: %w", err)
+		}
+
+		log.Printf("Deleted user: %s", username)
+		return nil
+	})
+}
+
+// SaveSession implements Storage.SaveSession.
+func (s *SQLiteStorage) SaveSession(ctx context.Context, session Session) error {
+	return s.retryWithBackoff(ctx, func() error {
+		query := `
+			INSERT OR REPLACE INTO sessions (token_hash, user_id, created_at, expires_at)
+			VALUES (?, ?, CURRENT_TIMESTAMP, ?)
+		`
+
+		_, err := s.db.ExecContext(ctx, query, session.TokenHash, session.UserID, session.ExpiresAt)
+		if err != nil {
+			log.Printf("Failed to save session for user %d: %v", session.UserID, err)
+			return fmt.Errorf("failed to save session: %w", err)
+		}
+		return nil
+	})
+}
+
+// GetSession implements Storage.GetSession.
+// Returns false for unknown or expired sessions.
+func (s *SQLiteStorage) GetSession(ctx context.Context, tokenHash string) (Session, bool, error) {
+	query := `
+		SELECT token_hash, user_id, created_at, expires_at
+		FROM sessions
+		WHERE token_hash = ?
+	`
+
+	var session Session
+	err := s.db.QueryRowContext(ctx, query, tokenHash).Scan(
+		&session.TokenHash, &session.UserID, &session.CreatedAt, &session.ExpiresAt,
+	)
+	if err == sql.ErrNoRows {
+		return Session{}, false, nil
+	}
+	if err != nil {
+		log.Printf("Failed to query session: %v", err)
+		return Session{}, false, fmt.Errorf("failed to query session: %w", err)
+	}
+
+	if time.Now().After(session.ExpiresAt) {
+		return Session{}, false, nil
+	}
+	return session, true, nil
+}
+
+// DeleteSession implements Storage.DeleteSession.
+func (s *SQLiteStorage) DeleteSession(ctx context.Context, tokenHash string) error {
+	return s.retryWithBackoff(ctx, func() error {
+		_, err := s.db.ExecContext(ctx, `DELETE FROM sessions WHERE token_hash = ?`, tokenHash)
+		if err != nil {
+			return fmt.Errorf("failed to delete session: %w", err)
+		}
+		return nil
+	})
+}
+
+// DeleteExpiredSessions implements Storage.DeleteExpiredSessions.
+func (s *SQLiteStorage) DeleteExpiredSessions(ctx context.Context, now time.Time) (int64, error) {
+	var deleted int64
+	err := s.retryWithBackoff(ctx, func() error {
+		result, err := s.db.ExecContext(ctx, `DELETE FROM sessions WHERE expires_at < ?`, now)
+		if err != nil {
+			return fmt.Errorf("failed to delete expired sessions: %w", err)
+		}
+		deleted, _ = result.RowsAffected()
+		return nil
+	})
+	return deleted, err
+}
@@ -2,6 +2,7 @@ package storage
 
 import (
 	"context"
+	"os"
 	"time"
 )
 
@@ -23,6 +24,21 @@ type Storage interface {
 	//   - err: Any error that occurred during lookup
 	GetVersionCache(ctx context.Context, sha256, imageRef, arch string) (version string, found bool, err error)
 
+	// GetTagCache retrieves the persisted tag list for an image reference.
+	// Freshness is decided by the caller using FetchedAt.
+	// Returns found=false if no entry exists.
+	GetTagCache(ctx context.Context, imageRef string) (entry TagCacheEntry, found bool, err error)
+
+	// SaveTagCache stores or replaces the persisted tag list for an image reference.
+	SaveTagCache(ctx context.Context, entry TagCacheEntry) error
+
+	// GetCheckFingerprint retrieves what a container's last check was based on,
+	// with its result. Returns found=false if the container was never checked.
+	GetCheckFingerprint(ctx context.Context, containerName string) (fingerprint CheckFingerprint, found bool, err error)
+
+	// SaveCheckFingerprint stores or replaces the check fingerprint of a container.
+	SaveCheckFingerprint(ctx context.Context, fingerprint CheckFingerprint) error
+
 	// LogCheck records a check operation in the history.
 	// Parameters:
 	//   - containerName: Name of the container checked
@@ -65,6 +81,10 @@ type Storage interface {
 	//   - end: End of time range (inclusive)
 	GetCheckHistoryByTimeRange(ctx context.Context, start, end time.Time) ([]CheckHistoryEntry, error)
 
+	// QueryCheckHistory retrieves check history matching all given filters.
+	// Returns entries ordered by check_time DESC (most recent first).
+	QueryCheckHistory(ctx context.Context, opts CheckHistoryQueryOptions) ([]CheckHistoryEntry, error)
+
 	// LogUpdate records an update operation in the audit log.
 	// Parameters:
 	//   - containerName: Name of the container being updated
@@ -169,6 +189,11 @@ type Storage interface {
 	//   - batchGroupID: The batch group identifier linking related operations
 	GetUpdateOperationsByBatchGroup(ctx context.Context, batchGroupID string) ([]UpdateOperation, error)
 
+	// GetUnfinishedOperations retrieves operations that have not completed, failed,
+	// or been cancelled, including queued and paused ones.
+	// Returns entries ordered by created_at ASC (oldest first).
+	GetUnfinishedOperations(ctx context.Context) ([]UpdateOperation, error)
+
 	// UpdateOperationStatus updates the status and error message of an operation.
 	// Also updates the updated_at timestamp automatically.
 	// Parameters:
@@ -177,6 +202,10 @@ type Storage interface {
 	//   - errorMsg: Error message (empty string if no error)
 	UpdateOperationStatus(ctx context.Context, operationID string, status string, errorMsg string) error
 
+	// AppendComposeOutput appends docker compose output to an operation, keeping
+	// the last MaxComposeOutput bytes. SaveUpdateOperation leaves it unchanged.
+	AppendComposeOutput(ctx context.Context, operationID string, output string) error
+
 	// GetRollbackPolicy retrieves the rollback policy for an entity.
 	// Parameters:
 	//   - entityType: Type of entity (global, container, stack)
@@ -193,6 +222,51 @@ type Storage interface {
 	//   - policy: RollbackPolicy containing policy configuration
 	SetRollbackPolicy(ctx context.Context, policy RollbackPolicy) error
 
+	// ListRollbackPolicies retrieves all rollback policies.
+	// Returns the global policy first, then stack and container policies ordered by entity.
+	ListRollbackPolicies(ctx context.Context) ([]RollbackPolicy, error)
+
+	// GetApprovalPolicy retrieves the approval policy for an entity.
+	// Parameters:
+	//   - entityType: Type of entity (global, container, stack)
+	//   - entityID: ID of entity (container/stack name, empty for global)
+	GetApprovalPolicy(ctx context.Context, entityType, entityID string) (ApprovalPolicy, bool, error)
+
+	// SetApprovalPolicy creates or updates an approval policy.
+	SetApprovalPolicy(ctx context.Context, policy ApprovalPolicy) error
+
+	// ListApprovalPolicies retrieves all approval policies.
+	// Returns the global policy first, then stack and container policies ordered by entity.
+	ListApprovalPolicies(ctx context.Context) ([]ApprovalPolicy, error)
+
+	// DeleteApprovalPolicy removes the approval policy of an entity, so the next
+	// level of the hierarchy applies. Deleting a missing policy is not an error.
+	DeleteApprovalPolicy(ctx context.Context, entityType, entityID string) error
+
+	// ListIgnoreRules retrieves all image ignore rules, ordered by pattern.
+	ListIgnoreRules(ctx context.Context) ([]IgnoreRule, error)
+
+	// SaveIgnoreRule creates an image ignore rule, or updates the reason of the
+	// rule with the same pattern. Returns the saved rule.
+	SaveIgnoreRule(ctx context.Context, rule IgnoreRule) (IgnoreRule, error)
+
+	// DeleteIgnoreRule removes an image ignore rule.
+	// Returns false if the rule does not exist.
+	DeleteIgnoreRule(ctx context.Context, id int64) (bool, error)
+
+	// GetStackEnv retrieves the environment settings of a stack's compose commands.
+	GetStackEnv(ctx context.Context, stack string) (StackEnv, bool, error)
+
+	// SetStackEnv creates or replaces the environment settings of a stack.
+	SetStackEnv(ctx context.Context, env StackEnv) error
+
+	// ListStackEnvs retrieves the environment settings of all stacks, ordered by stack.
+	ListStackEnvs(ctx context.Context) ([]StackEnv, error)
+
+	// DeleteStackEnv removes the environment settings of a stack.
+	// Returns false if the stack has none.
+	DeleteStackEnv(ctx context.Context, stack string) (bool, error)
+
 	// QueueUpdate adds an update operation to the queue.
 	// Used when a stack is locked and operation must wait.
 	// Parameters:
@@ -210,6 +284,26 @@ type Storage interface {
 	// Returns entries in FIFO order (oldest first).
 	GetQueuedUpdates(ctx context.Context) ([]UpdateQueue, error)
 
+	// SetQueuePriorities sets the priority of queued operations in one transaction.
+	// Parameters:
+	//   - priorities: Operation ID -> priority; IDs that are no longer queued are skipped
+	SetQueuePriorities(ctx context.Context, priorities map[string]int) error
+
+	// DeleteQueuedUpdate removes a queued operation.
+	// Returns false if the operation is not queued.
+	DeleteQueuedUpdate(ctx context.Context, operationID string) (bool, error)
+
+	// SaveStackLock records the operation holding a stack's lock, replacing any
+	// previous holder.
+	SaveStackLock(ctx context.Context, lock StackLock) error
+
+	// DeleteStackLock removes the lock record of a stack if operationID holds it.
+	// An empty operationID removes the record whatever the holder.
+	DeleteStackLock(ctx context.Context, stackName, operationID string) error
+
+	// ListStackLocks retrieves the recorded stack locks, ordered by stack name.
+	ListStackLocks(ctx context.Context) ([]StackLock, error)
+
 	// SaveScriptAssignment creates or updates a script assignment for a container.
 	// Uses INSERT OR REPLACE to handle both create and update cases.
 	// Parameters:
@@ -234,6 +328,43 @@ type Storage interface {
 	//   - containerName: Name of the container to remove assignment from
 	DeleteScriptAssignment(ctx context.Context, containerName string) error
 
+	// SaveScriptRevision records a revision of a script managed through the API.
+	// Fails if the revision already exists, so concurrent saves cannot share a number.
+	SaveScriptRevision(ctx context.Context, revision ScriptRevision) error
+
+	// GetScriptRevision retrieves a revision of a managed script.
+	// A revision of 0 retrieves the latest revision.
+	// Returns:
+	//   - revision: The revision record
+	//   - found: True if the revision exists
+	//   - err: Any error that occurred during lookup
+	GetScriptRevision(ctx context.Context, name string, revision int) (ScriptRevision, bool, error)
+
+	// ListScriptRevisions retrieves the revisions of a managed script, newest first.
+	// An empty name lists the revisions of all managed scripts, ordered by name.
+	ListScriptRevisions(ctx context.Context, name string) ([]ScriptRevision, error)
+
+	// SaveVolumeSnapshot records a snapshot of a volume taken before an update.
+	SaveVolumeSnapshot(ctx context.Context, snapshot VolumeSnapshot) error
+
+	// GetVolumeSnapshots retrieves the volume snapshots taken by an update operation.
+	GetVolumeSnapshots(ctx context.Context, operationID string) ([]VolumeSnapshot, error)
+
+	// ListVolumeSnapshots retrieves the snapshots of a volume, newest first.
+	// An empty volume name lists the snapshots of all volumes.
+	ListVolumeSnapshots(ctx context.Context, volumeName string) ([]VolumeSnapshot, error)
+
+	// DeleteVolumeSnapshot removes the record of a volume snapshot.
+	DeleteVolumeSnapshot(ctx context.Context, id int64) error
+
+	// AppendOperationLog appends a line to the step log of an update operation.
+	// Returns the ID of the stored line.
+	AppendOperationLog(ctx context.Context, entry OperationLogEntry) (int64, error)
+
+	// GetOperationLogs retrieves the step log of an update operation, oldest first.
+	// Only lines with an ID greater than afterID are returned.
+	GetOperationLogs(ctx context.Context, operationID string, afterID int64) ([]OperationLogEntry, error)
+
 	// QueryUpdateOperations retrieves update operations with flexible filtering
 	// and cursor-based pagination.
 	QueryUpdateOperations(ctx context.Context, opts OperationQueryOptions) (OperationQueryResult, error)
@@ -244,6 +375,133 @@ type Storage interface {
 	// DeleteHistoryBefore deletes history entries older than the given time.
 	DeleteHistoryBefore(ctx context.Context, before time.Time) (int64, error)
 
+	// CreateUser inserts a new user and returns it with its assigned ID.
+	// Returns an error if the username is already taken.
+	CreateUser(ctx context.Context, user User) (User, error)
+
+	// GetUser retrieves a user by username.
+	// Returns:
+	//   - user: The user record
+	//   - found: True if the user exists
+	//   - err: Any error that occurred during lookup
+	GetUser(ctx context.Context, username string) (User, bool, error)
+
+	// GetUserByID retrieves a user by numeric ID.
+	GetUserByID(ctx context.Context, id int64) (User, bool, error)
+
+	// ListUsers retrieves all users ordered by username.
+	ListUsers(ctx context.Context) ([]User, error)
+
+	// UpdateUser updates the role and password hash of an existing user.
+	// Updates the updated_at timestamp automatically.
+	UpdateUser(ctx context.Context, user User) error
+
+	// DeleteUser removes a user and all of their sessions, team memberships,
+	// and ownerships.
+	DeleteUser(ctx context.Context, username string) error
+
+	// SaveSession stores a login session.
+	// Parameters:
+	//   - session: Session containing the hashed token, user ID, and expiry
+	SaveSession(ctx context.Context, session Session) error
+
+	// GetSession retrieves a session by token hash.
+	// Expired sessions are returned as not found.
+	GetSession(ctx context.Context, tokenHash string) (Session, bool, error)
+
+	// DeleteSession removes a single session (logout).
+	DeleteSession(ctx context.Context, tokenHash string) error
+
+	// DeleteExpiredSessions removes sessions that expired before now.
+	DeleteExpiredSessions(ctx context.Context, now time.Time) (int64, error)
+
+	// ListOwnerships retrieves the owners of all stacks and containers, ordered
+	// by entity type, entity ID, and owner.
+	ListOwnerships(ctx context.Context) ([]Ownership, error)
+
+	// AddOwnership assigns a stack or container to an owner.
+	// Adding an existing assignment is a no-op.
+	AddOwnership(ctx context.Context, ownership Ownership) error
+
+	// RemoveOwnership removes an owner from a stack or container.
+	// Returns false if the assignment does not exist.
+	RemoveOwnership(ctx context.Context, entityType, entityID, owner string) (bool, error)
+
+	// ListTeamMembers retrieves all team memberships ordered by team and username.
+	ListTeamMembers(ctx context.Context) ([]TeamMember, error)
+
+	// AddTeamMember adds a user to a team; a team exists while it has members.
+	// Adding an existing member is a no-op.
+	AddTeamMember(ctx context.Context, team, username string) error
+
+	// RemoveTeamMember removes a user from a team.
+	// Returns false if the user is not a member.
+	RemoveTeamMember(ctx context.Context, team, username string) (bool, error)
+
+	// SaveApproval inserts or updates an update approval request.
+	// Parameters:
+	//   - approval: Approval with ID, container, target version, status, and deadline
+	SaveApproval(ctx context.Context, approval Approval) error
+
+	// GetApproval retrieves an approval by ID.
+	// Returns (approval, found, error) where found is false if it does not exist.
+	GetApproval(ctx context.Context, id string) (Approval, bool, error)
+
+	// GetLatestApproval retrieves the most recent approval for a container in any status.
+	// Returns (approval, found, error) where found is false if none exist.
+	GetLatestApproval(ctx context.Context, containerName string) (Approval, bool, error)
+
+	// ListApprovals retrieves approvals newest first.
+	// Parameters:
+	//   - status: Filter by status (empty for all)
+	//   - limit: Maximum number of approvals to return (defaults to 100 if <= 0)
+	ListApprovals(ctx context.Context, status string, limit int) ([]Approval, error)
+
+	// ExpireApprovals marks pending approvals past their deadline as expired.
+	// Returns the number of approvals expired.
+	ExpireApprovals(ctx context.Context, now time.Time) (int64, error)
+
+	// SaveProposal inserts or updates a compose change proposal.
+	// Parameters:
+	//   - proposal: Proposal with ID, container, target version, patch, and status
+	SaveProposal(ctx context.Context, proposal Proposal) error
+
+	// GetProposal retrieves a proposal by ID.
+	// Returns (proposal, found, error) where found is false if it does not exist.
+	GetProposal(ctx context.Context, id string) (Proposal, bool, error)
+
+	// GetLatestProposal retrieves the most recent proposal for a container in any status.
+	// Returns (proposal, found, error) where found is false if none exist.
+	GetLatestProposal(ctx context.Context, containerName string) (Proposal, bool, error)
+
+	// ListProposals retrieves proposals newest first.
+	// Parameters:
+	//   - status: Filter by status (empty for all)
+	//   - limit: Maximum number of proposals to return (defaults to 100 if <= 0)
+	ListProposals(ctx context.Context, status string, limit int) ([]Proposal, error)
+
+	// Backup writes a consistent snapshot of the database to destPath while it
+	// stays in use. Fails if destPath already exists.
+	Backup(ctx context.Context, destPath string) error
+
+	// Vacuum rebuilds the database to reclaim space left by deleted rows.
+	Vacuum(ctx context.Context) (VacuumResult, error)
+
+	// PruneHistory deletes check history and update log rows older than the
+	// cutoffs in opts. A zero cutoff leaves that table alone.
+	PruneHistory(ctx context.Context, opts PruneOptions) (PruneResult, error)
+
+	// ListComposeBackups retrieves the recorded compose file backups, oldest first.
+	ListComposeBackups(ctx context.Context) ([]ComposeBackup, error)
+
+	// DeleteComposeBackups removes compose file backup records by ID.
+	// Returns the number of records deleted.
+	DeleteComposeBackups(ctx context.Context, ids []int64) (int64, error)
+
+	// CheckWritable verifies the database accepts writes without changing any
+	// data, by starting a write in a transaction that is rolled back.
+	CheckWritable(ctx context.Context) error
+
 	// Close closes the database connection and releases resources.
 	// Should be called when the storage is no longer needed.
 	Close() error
@@ -255,11 +513,22 @@ type OperationQueryOptions struct {
 	Cursor    string     // ISO timestamp — return operations before this time
 	Status    string     // "complete", "failed", or "" for both
 	Container string
-	Type      string     // operation_type filter; "updates" maps to single/batch/stack
+	Stack     string
+	Type      string     // operation_type filter; "updates" maps to single/batch/stack/pin/variant
 	DateFrom  *time.Time
 	DateTo    *time.Time
 }
 
+// CheckHistoryQueryOptions specifies filtering for check history queries.
+// Zero values match everything.
+type CheckHistoryQueryOptions struct {
+	Containers []string // match any of these containers
+	Status     string
+	DateFrom   *time.Time
+	DateTo     *time.Time
+	Limit      int // 0 for no limit
+}
+
 // OperationQueryResult contains paginated operation results.
 type OperationQueryResult struct {
 	Operations []UpdateOperation
@@ -267,6 +536,29 @@ type OperationQueryResult struct {
 	HasMore    bool
 }
 
+// TagCacheEntry is a registry tag list persisted between checks, with the
+// validators needed to revalidate it using a conditional request.
+type TagCacheEntry struct {
+	ImageRef     string
+	Tags         []string
+	ETag         string
+	LastModified string
+	Partial      bool      // The listing stopped paginating early, see registry.WithTagListStop
+	FetchedAt    time.Time // When the list was last fetched or revalidated
+}
+
+// CheckFingerprint records what a container's last update check was based on,
+// so a differential check can reuse its result while nothing has changed.
+type CheckFingerprint struct {
+	ContainerName string
+	Image         string
+	LocalHash     string    // Hash of the image reference, local image digest, and labels
+	HeadDigest    string    // Registry digest of the container's tag
+	TagsHash      string    // Hash of the repository's tag list
+	Result        string    // JSON encoded check result
+	CheckedAt     time.Time // When the full check ran
+}
+
 // CheckHistoryEntry represents a single check operation result.
 type CheckHistoryEntry struct {
 	ID             int64     `json:"id"`
@@ -277,6 +569,8 @@ type CheckHistoryEntry struct {
 	LatestVersion  string    `json:"latest_version,omitempty"`
 	Status         string    `json:"status"`
 	Error          string    `json:"error,omitempty"`
+	LatestSize     int64     `json:"latest_size,omitempty"` // Compressed size of the update candidate in bytes
+	SizeDelta      int64     `json:"size_delta,omitempty"`  // Download size change versus the current image
 }
 
 // UpdateLogEntry represents a single update operation result.
@@ -305,37 +599,73 @@ type ConfigSnapshot struct {
 // Tracks progress through all stages of the update workflow.
 // BatchContainerDetail stores version info for a single container in a batch update
 type BatchContainerDetail struct {
-	ContainerName      string `json:"container_name"`
-	StackName          string `json:"stack_name,omitempty"`
-	OldVersion         string `json:"old_version"`
-	NewVersion         string `json:"new_version"`
-	ChangeType         *int   `json:"change_type,omitempty"`          // version.ChangeType (0=rebuild, 1=patch, 2=minor, 3=major)
-	OldResolvedVersion string `json:"old_resolved_version,omitempty"` // Resolved version at time of update
-	NewResolvedVersion string `json:"new_resolved_version,omitempty"` // Resolved version at time of update
-	OldDigest          string `json:"old_digest,omitempty"`           // Image digest at time of update (for digest-based rollback)
-	Status             string `json:"status,omitempty"`               // Per-container status: pending, restarting, complete, failed
-	Message            string `json:"message,omitempty"`              // Human-readable status message
+	ContainerName        string `json:"container_name"`
+	StackName            string `json:"stack_name,omitempty"`
+	OldVersion           string `json:"old_version"`
+	NewVersion           string `json:"new_version"`
+	ChangeType           *int   `json:"change_type,omitempty"`            // version.ChangeType (0=rebuild, 1=patch, 2=minor, 3=major)
+	OldResolvedVersion   string `json:"old_resolved_version,omitempty"`   // Resolved version at time of update
+	NewResolvedVersion   string `json:"new_resolved_version,omitempty"`   // Resolved version at time of update
+	OldDigest            string `json:"old_digest,omitempty"`             // Image digest at time of update (for digest-based rollback)
+	Status               string `json:"status,omitempty"`                 // Per-container status: pending, restarting, complete, failed
+	Message              string `json:"message,omitempty"`                // Human-readable status message
+	RestoreSnapshotsFrom string `json:"restore_snapshots_from,omitempty"` // Rollbacks: operation whose volume snapshots are restored
 }
 
 type UpdateOperation struct {
-	ID                 int64                   `json:"id"`
-	OperationID        string                  `json:"operation_id"`
-	ContainerID        string                  `json:"container_id"`
-	ContainerName      string                  `json:"container_name"`
-	StackName          string                  `json:"stack_name,omitempty"`
-	OperationType      string                  `json:"operation_type"` // single, batch, stack
-	Status             string                  `json:"status"`         // queued, validating, backup, updating_compose, pulling_image, stopping, starting, health_check, restarting_dependents, complete, failed, rolling_back, cancelled
-	OldVersion         string                  `json:"old_version,omitempty"`
-	NewVersion         string                  `json:"new_version"`
-	StartedAt          *time.Time              `json:"started_at,omitempty"`
-	CompletedAt        *time.Time              `json:"completed_at,omitempty"`
-	ErrorMessage       string                  `json:"error_message,omitempty"`
-	DependentsAffected []string                `json:"dependents_affected,omitempty"` // JSON array of container names
-	RollbackOccurred   bool                    `json:"rollback_occurred"`
-	BatchDetails       []BatchContainerDetail  `json:"batch_details,omitempty"` // Details for batch operations
-	BatchGroupID       string                  `json:"batch_group_id,omitempty"` // Links operations from a single user action
-	CreatedAt          time.Time               `json:"created_at"`
-	UpdatedAt          time.Time               `json:"updated_at"`
+	ID                     int64                   `json:"id"`
+	OperationID            string                  `json:"operation_id"`
+	ContainerID            string                  `json:"container_id"`
+	ContainerName          string                  `json:"container_name"`
+	StackName              string                  `json:"stack_name,omitempty"`
+	OperationType          string                  `json:"operation_type"` // single, batch, stack, pin, variant
+	Status                 string                  `json:"status"`         // queued, validating, backup, updating_compose, pulling_image, building_image, stopping, starting, health_check, restarting_dependents, complete, failed, rolling_back, cancelled
+	OldVersion             string                  `json:"old_version,omitempty"`
+	NewVersion             string                  `json:"new_version"`
+	StartedAt              *time.Time              `json:"started_at,omitempty"`
+	CompletedAt            *time.Time              `json:"completed_at,omitempty"`
+	ErrorMessage           string                  `json:"error_message,omitempty"`
+	DependentsAffected     []string                `json:"dependents_affected,omitempty"` // JSON array of container names
+	RollbackOccurred       bool                    `json:"rollback_occurred"`
+	BatchDetails           []BatchContainerDetail  `json:"batch_details,omitempty"`           // Details for batch operations
+	BatchGroupID           string                  `json:"batch_group_id,omitempty"`          // Links operations from a single user action
+	CheckOutput            string                  `json:"check_output,omitempty"`            // Output of the post-update check script
+	AllOrNothing           bool                    `json:"all_or_nothing,omitempty"`          // Batch rolls back entirely if any container fails
+	SignatureVerifications []SignatureVerification `json:"signature_verifications,omitempty"` // Signature checks of the target images
+	Observations           []PostUpdateObservation `json:"observations,omitempty"`            // Restart monitoring of the updated containers
+	ComposeOutput          string                  `json:"compose_output,omitempty"`          // Redacted output of the docker compose commands run, see AppendComposeOutput
+	TriggeredBy            string                  `json:"triggered_by,omitempty"`            // Who or what started the operation, e.g. user:alice, api_key:ci, or schedule:media
+	CreatedAt              time.Time               `json:"created_at"`
+	UpdatedAt              time.Time               `json:"updated_at"`
+}
+
+// SignatureVerification is the result of verifying the signature of an update's
+// target image before it is pulled.
+type SignatureVerification struct {
+	ContainerName string    `json:"container_name"`
+	Image         string    `json:"image"`            // Target image reference (image:tag)
+	Digest        string    `json:"digest,omitempty"` // Digest the signature was checked against
+	Policy        string    `json:"policy"`           // warn or block
+	Mode          string    `json:"mode,omitempty"`   // key or keyless
+	Verified      bool      `json:"verified"`
+	Signer        string    `json:"signer,omitempty"`       // Certificate identity of keyless signatures
+	Attestations  []string  `json:"attestations,omitempty"` // Artifact types of attestations found for the digest
+	Error         string    `json:"error,omitempty"`
+	CheckedAt     time.Time `json:"checked_at"`
+}
+
+// PostUpdateObservation is the result of watching an updated container for
+// restarts during the observation window after the update.
+type PostUpdateObservation struct {
+	ContainerName string     `json:"container_name"`
+	Status        string     `json:"status"`       // observing, passed, crash_loop, interrupted
+	Restarts      int        `json:"restarts"`     // Restarts seen so far
+	MaxRestarts   int        `json:"max_restarts"` // Restarts tolerated within the window
+	WindowSeconds int        `json:"window_seconds"`
+	StartedAt     time.Time  `json:"started_at"`
+	CompletedAt   *time.Time `json:"completed_at,omitempty"`
+	RolledBack    bool       `json:"rolled_back,omitempty"` // A crash loop started an automatic rollback
+	Message       string     `json:"message,omitempty"`
 }
 
 // RollbackPolicy represents auto-rollback configuration at various levels.
@@ -350,6 +680,44 @@ type RollbackPolicy struct {
 	UpdatedAt            time.Time `json:"updated_at"`
 }
 
+// Approval policy modes
+const (
+	ApprovalModeNone  = "none"  // Updates are applied without approval
+	ApprovalModeMajor = "major" // Patch and minor updates are applied automatically, major updates need approval
+	ApprovalModeAll   = "all"   // Every update needs approval
+)
+
+// ApprovalPolicy decides which updates of the host, a stack, or a container must
+// be approved before they are applied. Resolved like RollbackPolicy: container >
+// stack > global.
+type ApprovalPolicy struct {
+	ID         int64     `json:"id"`
+	EntityType string    `json:"entity_type"`         // global, container, stack
+	EntityID   string    `json:"entity_id,omitempty"` // container or stack name, empty for global
+	Mode       string    `json:"mode"`                // none, major, or all
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// IgnoreRule excludes every container whose image matches Pattern from update
+// checks, like a docksmith.ignore label on each of them.
+type IgnoreRule struct {
+	ID        int64     `json:"id"`
+	Pattern   string    `json:"pattern"` // Image glob, e.g. ghcr.io/home-assistant/* or *:nightly
+	Reason    string    `json:"reason,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// StackEnv is the environment docker compose commands of a stack run with, for
+// stacks whose compose files interpolate variables from an env file or shell
+// environment that docksmith does not have. Env overrides variables of EnvFile.
+type StackEnv struct {
+	Stack     string            `json:"stack"`
+	EnvFile   string            `json:"env_file,omitempty"` // Path of a KEY=value file, as seen by docksmith
+	Env       map[string]string `json:"env,omitempty"`
+	UpdatedAt time.Time         `json:"updated_at"`
+}
+
 // UpdateQueue represents a queued update operation waiting for stack lock.
 // Implements FIFO queue with persistence across restarts.
 type UpdateQueue struct {
@@ -359,11 +727,19 @@ type UpdateQueue struct {
 	Containers         []string          `json:"containers"` // JSON array of container names
 	OperationType      string            `json:"operation_type"`
 	TargetVersions     map[string]string `json:"target_versions,omitempty"` // container name -> target version
+	AllOrNothing       bool              `json:"all_or_nothing,omitempty"`  // Roll back the whole batch if any container fails
 	Priority           int               `json:"priority"`
 	QueuedAt           time.Time         `json:"queued_at"`
 	EstimatedStartTime *time.Time        `json:"estimated_start_time,omitempty"`
 }
 
+// StackLock records the operation holding a stack's lock.
+type StackLock struct {
+	StackName   string    `json:"stack_name"`
+	OperationID string    `json:"operation_id"`
+	AcquiredAt  time.Time `json:"acquired_at"`
+}
+
 // ScriptAssignment represents container settings including script, ignore, and allow-latest.
 // Database-only, no compose file modifications needed. Changes apply on next check.
 type ScriptAssignment struct {
@@ -377,3 +753,153 @@ type ScriptAssignment struct {
 	AssignedBy    string    `json:"assigned_by,omitempty"`     // 'cli' or 'ui'
 	UpdatedAt     time.Time `json:"updated_at"`
 }
+
+// ScriptRevision is a revision of a script managed through the API. The content is
+// stored on disk; the sandbox fields restrict how the revision is executed.
+type ScriptRevision struct {
+	ID             int64     `json:"id"`
+	Name           string    `json:"name"`
+	Revision       int       `json:"revision"`
+	SHA256         string    `json:"sha256"`
+	Size           int64     `json:"size"`
+	TimeoutSeconds int       `json:"timeout_seconds,omitempty"` // 0 = the check's default timeout
+	NoNetwork      bool      `json:"no_network"`                // Run without network access
+	MemoryLimitMB  int       `json:"memory_limit_mb,omitempty"` // Address space limit, 0 = unlimited
+	CPUSeconds     int       `json:"cpu_seconds,omitempty"`     // CPU time limit, 0 = unlimited
+	CreatedBy      string    `json:"created_by,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// VolumeSnapshot is a snapshot of a named volume taken before an update, so a
+// rollback can restore the data the previous version wrote.
+type VolumeSnapshot struct {
+	ID            int64     `json:"id"`
+	OperationID   string    `json:"operation_id"`
+	ContainerName string    `json:"container_name"`
+	VolumeName    string    `json:"volume_name"`
+	Method        string    `json:"method"`   // tar or hook
+	Location      string    `json:"location"` // Archive path (tar) or snapshot ID passed to the hook
+	SizeBytes     int64     `json:"size_bytes,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// OperationLogEntry is a line of the step log of an update operation: compose
+// command output, image pull layers, health check attempts, and script output.
+type OperationLogEntry struct {
+	ID            int64     `json:"id"`
+	OperationID   string    `json:"operation_id"`
+	ContainerName string    `json:"container_name,omitempty"`
+	Source        string    `json:"source"` // progress, compose, pull, health, or script
+	Message       string    `json:"message"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// User represents a dashboard/API user with a role.
+// Roles: viewer (read-only), operator (can trigger updates), admin (full access).
+type User struct {
+	ID           int64     `json:"id"`
+	Username     string    `json:"username"`
+	PasswordHash string    `json:"-"`
+	Role         string    `json:"role"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// Ownership entity types
+const (
+	OwnershipStack     = "stack"
+	OwnershipContainer = "container"
+)
+
+// Ownership assigns a stack or container to an owner: a user ("user:NAME"), an
+// API key ("api_key:NAME"), or a team ("team:NAME"). Stacks and containers
+// without owners are shared by everyone.
+type Ownership struct {
+	EntityType string    `json:"entity_type"` // "stack" or "container"
+	EntityID   string    `json:"entity_id"`   // Stack or container name
+	Owner      string    `json:"owner"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// TeamMember is the membership of a user in a team.
+type TeamMember struct {
+	Team      string    `json:"team"`
+	Username  string    `json:"username"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Approval status values
+const (
+	ApprovalPending    = "pending"
+	ApprovalApproved   = "approved"
+	ApprovalRejected   = "rejected"
+	ApprovalExpired    = "expired"
+	ApprovalSuperseded = "superseded" // A newer version was detected before a decision
+)
+
+// Approval represents a detected update waiting for (or decided by) an operator.
+type Approval struct {
+	ID             string     `json:"id"`
+	ContainerName  string     `json:"container_name"`
+	StackName      string     `json:"stack_name,omitempty"`
+	CurrentVersion string     `json:"current_version,omitempty"`
+	TargetVersion  string     `json:"target_version"`
+	Status         string     `json:"status"`
+	RequestedAt    time.Time  `json:"requested_at"`
+	ExpiresAt      time.Time  `json:"expires_at"`
+	DecidedBy      string     `json:"decided_by,omitempty"`
+	DecidedAt      *time.Time `json:"decided_at,omitempty"`
+	OperationID    string     `json:"operation_id,omitempty"`
+}
+
+// Proposal status values
+const (
+	ProposalOpen       = "open"
+	ProposalApplied    = "applied"    // The container now runs the proposed version
+	ProposalSuperseded = "superseded" // A newer version was detected, or the update went away
+)
+
+// Proposal is a compose file change emitted for an available update instead of
+// applying it (propose-only mode).
+type Proposal struct {
+	ID             string    `json:"id"`
+	ContainerName  string    `json:"container_name"`
+	StackName      string    `json:"stack_name,omitempty"`
+	CurrentVersion string    `json:"current_version,omitempty"`
+	TargetVersion  string    `json:"target_version"`
+	ComposeFile    string    `json:"compose_file"`
+	Patch          string    `json:"patch"`                      // Unified diff of the compose file change
+	PatchFile      string    `json:"patch_file,omitempty"`       // Where the patch was written on disk
+	Branch         string    `json:"branch,omitempty"`           // Git branch the change was pushed to
+	PullRequestURL string    `json:"pull_request_url,omitempty"` // Pull request opened for the branch
+	Status         string    `json:"status"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// Session represents a logged-in browser session.
+// Only the SHA-256 hash of the session token is stored.
+type Session struct {
+	TokenHash string    `json:"-"`
+	UserID    int64     `json:"user_id"`
+	CreatedAt time.Time `json:"created_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// EnvOrConfig returns the value of the environment variable env, or the database
+// config value stored under key when the variable is not set. Settings normally
+// given as environment variables are stored this way by a configuration import.
+// store may be nil.
+func EnvOrConfig(ctx context.Context, store Storage, env, key string) string {
+	if value := os.Getenv(env); value != "" {
+		return value
+	}
+	if store == nil {
+		return ""
+	}
+	value, found, err := store.GetConfig(ctx, key)
+	if err != nil || !found {
+		return ""
+	}
+	return value
+}
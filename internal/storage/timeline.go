@@ -0,0 +1,135 @@
+package storage
+
+import (
+	"context"
+	"sort"
+	"time"
+)
+
+// TimelineEntry is one event in the check and update timeline: a check result or an update operation.
+type TimelineEntry struct {
+	Timestamp     time.Time `json:"timestamp"`
+	Type          string    `json:"type"` // "check" or "update"
+	ContainerName string    `json:"container_name"`
+	Stack         string    `json:"stack,omitempty"`
+	Image         string    `json:"image,omitempty"`
+	Operation     string    `json:"operation,omitempty"` // operation type for updates
+	OperationID   string    `json:"operation_id,omitempty"`
+	FromVersion   string    `json:"from_version,omitempty"`
+	ToVersion     string    `json:"to_version,omitempty"`
+	Status        string    `json:"status"`
+	TriggeredBy   string    `json:"triggered_by,omitempty"` // who or what started an update, see UpdateOperation
+	Error         string    `json:"error,omitempty"`
+}
+
+// TimelineQueryOptions specifies filtering for QueryTimeline.
+// Zero values match everything.
+type TimelineQueryOptions struct {
+	Container string
+	Stack     string
+	Status    string
+	Type      string // "check", "update", or empty for both
+	DateFrom  *time.Time
+	DateTo    *time.Time
+	Limit     int // 0 for no limit
+}
+
+// QueryTimeline merges check history and finished update operations into one
+// timeline, newest first. Check history has no stack column, so a stack filter
+// matches the checks of containers that have operations in that stack.
+func QueryTimeline(ctx context.Context, store Storage, opts TimelineQueryOptions) ([]TimelineEntry, error) {
+	entries := []TimelineEntry{}
+
+	containers := []string{}
+	if opts.Container != "" {
+		containers = append(containers, opts.Container)
+	}
+
+	if opts.Type != "check" || opts.Stack != "" {
+		// The status filter is applied here so stack membership can use all operations
+		limit := opts.Limit
+		if limit <= 0 || opts.Stack != "" {
+			limit = 10000
+		}
+		result, err := store.QueryUpdateOperations(ctx, OperationQueryOptions{
+			Container: opts.Container,
+			Stack:     opts.Stack,
+			DateFrom:  opts.DateFrom,
+			DateTo:    opts.DateTo,
+			Limit:     limit,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		seen := make(map[string]bool)
+		for _, op := range result.Operations {
+			if opts.Stack != "" && opts.Container == "" && !seen[op.ContainerName] {
+				seen[op.ContainerName] = true
+				containers = append(containers, op.ContainerName)
+			}
+			if opts.Type == "check" || (opts.Status != "" && op.Status != opts.Status) {
+				continue
+			}
+			entries = append(entries, operationTimelineEntry(op))
+		}
+		if opts.Stack != "" && len(containers) == 0 {
+			return entries, nil
+		}
+	}
+
+	if opts.Type != "update" {
+		checks, err := store.QueryCheckHistory(ctx, CheckHistoryQueryOptions{
+			Containers: containers,
+			Status:     opts.Status,
+			DateFrom:   opts.DateFrom,
+			DateTo:     opts.DateTo,
+			Limit:      opts.Limit,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, check := range checks {
+			entries = append(entries, TimelineEntry{
+				Timestamp:     check.CheckTime,
+				Type:          "check",
+				ContainerName: check.ContainerName,
+				Stack:         opts.Stack,
+				Image:         check.Image,
+				FromVersion:   check.CurrentVersion,
+				ToVersion:     check.LatestVersion,
+				Status:        check.Status,
+				Error:         check.Error,
+			})
+		}
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Timestamp.After(entries[j].Timestamp)
+	})
+	if opts.Limit > 0 && len(entries) > opts.Limit {
+		entries = entries[:opts.Limit]
+	}
+	return entries, nil
+}
+
+// operationTimelineEntry converts an update operation to a timeline entry.
+func operationTimelineEntry(op UpdateOperation) TimelineEntry {
+	timestamp := op.CreatedAt
+	if op.StartedAt != nil {
+		timestamp = *op.StartedAt
+	}
+	return TimelineEntry{
+		Timestamp:     timestamp,
+		Type:          "update",
+		ContainerName: op.ContainerName,
+		Stack:         op.StackName,
+		Operation:     op.OperationType,
+		OperationID:   op.OperationID,
+		FromVersion:   op.OldVersion,
+		ToVersion:     op.NewVersion,
+		Status:        op.Status,
+		TriggeredBy:   op.TriggeredBy,
+		Error:         op.ErrorMessage,
+	}
+}
@@ -3,6 +3,7 @@ package storage
 import (
 	"context"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 )
@@ -34,6 +35,14 @@ func TestSaveAndGetUpdateOperation(t *testing.T) {
 		StartedAt:          &now,
 		DependentsAffected: []string{"dependent-1", "dependent-2"},
 		RollbackOccurred:   false,
+		CheckOutput:        "smoke test passed\n",
+		AllOrNothing:       true,
+		SignatureVerifications: []SignatureVerification{
+			{ContainerName: "test-container", Image: "nginx:1.21", Digest: "sha256:abc", Policy: "block", Mode: "key", Verified: true},
+		},
+		Observations: []PostUpdateObservation{
+			{ContainerName: "test-container", Status: "crash_loop", Restarts: 4, MaxRestarts: 3, WindowSeconds: 900},
+		},
 	}
 
 	err = storage.SaveUpdateOperation(ctx, op)
@@ -63,6 +72,27 @@ func TestSaveAndGetUpdateOperation(t *testing.T) {
 	if len(retrieved.DependentsAffected) != 2 {
 		t.Errorf("Expected 2 dependents affected, got %d", len(retrieved.DependentsAffected))
 	}
+	if retrieved.CheckOutput != op.CheckOutput {
+		t.Errorf("Expected check output %q, got %q", op.CheckOutput, retrieved.CheckOutput)
+	}
+	if len(retrieved.SignatureVerifications) != 1 || !retrieved.SignatureVerifications[0].Verified {
+		t.Errorf("Expected a verified signature verification, got %+v", retrieved.SignatureVerifications)
+	}
+	if len(retrieved.Observations) != 1 || retrieved.Observations[0].Status != "crash_loop" || retrieved.Observations[0].Restarts != 4 {
+		t.Errorf("Expected a crash loop observation, got %+v", retrieved.Observations)
+	}
+	if !retrieved.AllOrNothing {
+		t.Error("Expected all_or_nothing to be preserved")
+	}
+
+	// Paused is a persisted status
+	if err := storage.UpdateOperationStatus(ctx, "test-op-001", StatusPaused, ""); err != nil {
+		t.Fatalf("Failed to pause operation: %v", err)
+	}
+	retrieved, _, _ = storage.GetUpdateOperation(ctx, "test-op-001")
+	if retrieved.Status != StatusPaused {
+		t.Errorf("Expected status %s, got %s", StatusPaused, retrieved.Status)
+	}
 }
 
 // TestUpdateOperationStatus tests updating operation status
@@ -233,6 +263,244 @@ func TestGetAndSetRollbackPolicy(t *testing.T) {
 	if !stackRetrieved.AutoRollbackEnabled {
 		t.Error("Expected auto-rollback to be enabled for stack policy")
 	}
+
+	// List all policies, global first
+	policies, err := storage.ListRollbackPolicies(ctx)
+	if err != nil {
+		t.Fatalf("Failed to list rollback policies: %v", err)
+	}
+	if len(policies) != 3 {
+		t.Fatalf("Expected 3 policies, got %d", len(policies))
+	}
+	if policies[0].EntityType != "global" || policies[1].EntityID != "test-container" || policies[2].EntityID != "test-stack" {
+		t.Errorf("Unexpected policy order: %+v", policies)
+	}
+}
+
+// TestApprovalPolicies tests approval policy CRUD operations
+func TestApprovalPolicies(t *testing.T) {
+	storage, err := NewSQLiteStorage(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer storage.Close()
+
+	ctx := context.Background()
+
+	if _, found, err := storage.GetApprovalPolicy(ctx, "global", ""); err != nil || found {
+		t.Fatalf("Expected no global approval policy, found=%v err=%v", found, err)
+	}
+
+	for _, policy := range []ApprovalPolicy{
+		{EntityType: "stack", EntityID: "db", Mode: ApprovalModeAll},
+		{EntityType: "global", Mode: ApprovalModeNone},
+		{EntityType: "global", Mode: ApprovalModeMajor},
+	} {
+		if err := storage.SetApprovalPolicy(ctx, policy); err != nil {
+			t.Fatalf("Failed to set approval policy: %v", err)
+		}
+	}
+
+	global, found, err := storage.GetApprovalPolicy(ctx, "global", "")
+	if err != nil || !found {
+		t.Fatalf("Expected global approval policy, found=%v err=%v", found, err)
+	}
+	if global.Mode != ApprovalModeMajor {
+		t.Errorf("Expected the global policy to be replaced, got mode %q", global.Mode)
+	}
+
+	if err := storage.SetApprovalPolicy(ctx, ApprovalPolicy{EntityType: "global", Mode: "sometimes"}); err == nil {
+		t.Error("Expected an invalid mode to be rejected")
+	}
+
+	policies, err := storage.ListApprovalPolicies(ctx)
+	if err != nil {
+		t.Fatalf("Failed to list approval policies: %v", err)
+	}
+	if len(policies) != 2 || policies[0].EntityType != "global" || policies[1].EntityID != "db" {
+		t.Errorf("Unexpected policies: %+v", policies)
+	}
+
+	if err := storage.DeleteApprovalPolicy(ctx, "stack", "db"); err != nil {
+		t.Fatalf("Failed to delete approval policy: %v", err)
+	}
+	if _, found, _ := storage.GetApprovalPolicy(ctx, "stack", "db"); found {
+		t.Error("Expected the stack approval policy to be deleted")
+	}
+}
+
+func TestIgnoreRules(t *testing.T) {
+	storage, err := NewSQLiteStorage(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer storage.Close()
+
+	ctx := context.Background()
+
+	first, err := storage.SaveIgnoreRule(ctx, IgnoreRule{Pattern: "*:nightly"})
+	if err != nil {
+		t.Fatalf("Failed to save ignore rule: %v", err)
+	}
+	if first.ID == 0 || first.CreatedAt.IsZero() {
+		t.Errorf("Expected the saved rule to have an ID and creation time, got %+v", first)
+	}
+
+	updated, err := storage.SaveIgnoreRule(ctx, IgnoreRule{Pattern: "*:nightly", Reason: "unstable"})
+	if err != nil {
+		t.Fatalf("Failed to save ignore rule: %v", err)
+	}
+	if updated.ID != first.ID || updated.Reason != "unstable" {
+		t.Errorf("Expected the reason of rule %d to be updated, got %+v", first.ID, updated)
+	}
+
+	if _, err := storage.SaveIgnoreRule(ctx, IgnoreRule{Pattern: "ghcr.io/home-assistant/*"}); err != nil {
+		t.Fatalf("Failed to save ignore rule: %v", err)
+	}
+	rules, err := storage.ListIgnoreRules(ctx)
+	if err != nil {
+		t.Fatalf("Failed to list ignore rules: %v", err)
+	}
+	if len(rules) != 2 || rules[0].Pattern != "*:nightly" {
+		t.Errorf("Unexpected rules: %+v", rules)
+	}
+
+	if deleted, err := storage.DeleteIgnoreRule(ctx, first.ID); err != nil || !deleted {
+		t.Fatalf("Expected rule %d to be deleted, deleted=%v err=%v", first.ID, deleted, err)
+	}
+	if deleted, _ := storage.DeleteIgnoreRule(ctx, first.ID); deleted {
+		t.Error("Expected deleting a missing rule to report nothing deleted")
+	}
+}
+
+func TestStackEnvs(t *testing.T) {
+	sqlite, err := NewSQLiteStorage(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer sqlite.Close()
+
+	for name, storage := range map[string]Storage{"sqlite": sqlite, "memory": NewMemoryStorage()} {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+
+			if _, found, err := storage.GetStackEnv(ctx, "media"); err != nil || found {
+				t.Fatalf("Expected no environment for media, found=%v err=%v", found, err)
+			}
+
+			if err := storage.SetStackEnv(ctx, StackEnv{Stack: "media", EnvFile: "/stacks/media/.env", Env: map[string]string{"TZ": "UTC"}}); err != nil {
+				t.Fatalf("Failed to set stack environment: %v", err)
+			}
+			if err := storage.SetStackEnv(ctx, StackEnv{Stack: "media", Env: map[string]string{"PUID": "1000"}}); err != nil {
+				t.Fatalf("Failed to replace stack environment: %v", err)
+			}
+			if err := storage.SetStackEnv(ctx, StackEnv{Stack: "infra"}); err != nil {
+				t.Fatalf("Failed to set stack environment: %v", err)
+			}
+
+			env, found, err := storage.GetStackEnv(ctx, "media")
+			if err != nil || !found {
+				t.Fatalf("Expected environment for media, found=%v err=%v", found, err)
+			}
+			if env.EnvFile != "" || len(env.Env) != 1 || env.Env["PUID"] != "1000" || env.UpdatedAt.IsZero() {
+				t.Errorf("Expected the settings to be replaced, got %+v", env)
+			}
+
+			envs, err := storage.ListStackEnvs(ctx)
+			if err != nil {
+				t.Fatalf("Failed to list stack environments: %v", err)
+			}
+			if len(envs) != 2 || envs[0].Stack != "infra" || envs[1].Stack != "media" {
+				t.Errorf("Unexpected stack environments: %+v", envs)
+			}
+
+			if deleted, err := storage.DeleteStackEnv(ctx, "media"); err != nil || !deleted {
+				t.Fatalf("Expected media to be deleted, deleted=%v err=%v", deleted, err)
+			}
+			if deleted, _ := storage.DeleteStackEnv(ctx, "media"); deleted {
+				t.Error("Expected deleting missing settings to report nothing deleted")
+			}
+		})
+	}
+}
+
+func TestOwnership(t *testing.T) {
+	sqlite, err := NewSQLiteStorage(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer sqlite.Close()
+
+	for name, storage := range map[string]Storage{"sqlite": sqlite, "memory": NewMemoryStorage()} {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+
+			for _, ownership := range []Ownership{
+				{EntityType: OwnershipStack, EntityID: "media", Owner: "team:family"},
+				{EntityType: OwnershipStack, EntityID: "media", Owner: "user:alice"},
+				{EntityType: OwnershipContainer, EntityID: "plex", Owner: "user:bob"},
+				{EntityType: OwnershipStack, EntityID: "media", Owner: "team:family"},
+			} {
+				if err := storage.AddOwnership(ctx, ownership); err != nil {
+					t.Fatalf("Failed to add ownership: %v", err)
+				}
+			}
+
+			ownerships, err := storage.ListOwnerships(ctx)
+			if err != nil {
+				t.Fatalf("Failed to list ownership: %v", err)
+			}
+			if len(ownerships) != 3 || ownerships[0].EntityID != "plex" || ownerships[1].Owner != "team:family" || ownerships[2].Owner != "user:alice" {
+				t.Errorf("Unexpected ownership: %+v", ownerships)
+			}
+
+			if removed, err := storage.RemoveOwnership(ctx, OwnershipStack, "media", "team:family"); err != nil || !removed {
+				t.Fatalf("Expected the owner to be removed, removed=%v err=%v", removed, err)
+			}
+			if removed, _ := storage.RemoveOwnership(ctx, OwnershipStack, "media", "team:family"); removed {
+				t.Error("Expected removing a missing owner to report nothing removed")
+			}
+
+			if err := storage.AddTeamMember(ctx, "family", "carol"); err != nil {
+				t.Fatalf("Failed to add team member: %v", err)
+			}
+			if err := storage.AddTeamMember(ctx, "family", "alice"); err != nil {
+				t.Fatalf("Failed to add team member: %v", err)
+			}
+			if err := storage.AddTeamMember(ctx, "family", "alice"); err != nil {
+				t.Fatalf("Failed to add existing team member: %v", err)
+			}
+
+			members, err := storage.ListTeamMembers(ctx)
+			if err != nil {
+				t.Fatalf("Failed to list team members: %v", err)
+			}
+			if len(members) != 2 || members[0].Username != "alice" || members[1].Username != "carol" {
+				t.Errorf("Unexpected team members: %+v", members)
+			}
+
+			if removed, err := storage.RemoveTeamMember(ctx, "family", "carol"); err != nil || !removed {
+				t.Fatalf("Expected carol to be removed, removed=%v err=%v", removed, err)
+			}
+			if removed, _ := storage.RemoveTeamMember(ctx, "family", "carol"); removed {
+				t.Error("Expected removing a missing member to report nothing removed")
+			}
+
+			// Deleting a user drops their memberships and ownerships
+			if _, err := storage.CreateUser(ctx, User{Username: "alice", PasswordHash: "x", Role: "viewer"}); err != nil {
+				t.Fatalf("Failed to create user: %v", err)
+			}
+			if err := storage.DeleteUser(ctx, "alice"); err != nil {
+				t.Fatalf("Failed to delete user: %v", err)
+			}
+			if members, _ := storage.ListTeamMembers(ctx); len(members) != 0 {
+				t.Errorf("Expected no team members, got %+v", members)
+			}
+			if ownerships, _ := storage.ListOwnerships(ctx); len(ownerships) != 1 || ownerships[0].Owner != "user:bob" {
+				t.Errorf("Expected only bob's ownership, got %+v", ownerships)
+			}
+		})
+	}
 }
 
 // TestQueueAndDequeueUpdate tests queue operations
@@ -254,6 +522,7 @@ func TestQueueAndDequeueUpdate(t *testing.T) {
 		StackName:     "test-stack",
 		Containers:    []string{"container-1", "container-2"},
 		OperationType: "single",
+		AllOrNothing:  true,
 		Priority:      0,
 		QueuedAt:      time.Now(),
 	}
@@ -289,6 +558,9 @@ func TestQueueAndDequeueUpdate(t *testing.T) {
 	if len(dequeued.Containers) != 2 {
 		t.Errorf("Expected 2 containers, got %d", len(dequeued.Containers))
 	}
+	if !dequeued.AllOrNothing {
+		t.Error("Expected all_or_nothing to survive the queue")
+	}
 
 	// Verify queue is now empty
 	queued, err = storage.GetQueuedUpdates(ctx)
@@ -859,3 +1131,162 @@ func TestRollbackOccurredTracking(t *testing.T) {
 		t.Error("Expected RollbackOccurred=true after update")
 	}
 }
+
+func TestOperationLogs(t *testing.T) {
+	storage, err := NewSQLiteStorage(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer storage.Close()
+
+	ctx := context.Background()
+	startedAt := time.Now().Add(-time.Hour)
+	if err := storage.SaveUpdateOperation(ctx, UpdateOperation{OperationID: "op-1", ContainerName: "web", OperationType: "single", Status: StatusComplete, StartedAt: &startedAt}); err != nil {
+		t.Fatalf("Failed to save operation: %v", err)
+	}
+
+	var ids []int64
+	for _, message := range []string{"Pulling nginx:1.27", "$ docker compose up -d web", "Health check exited with 0: ok"} {
+		id, err := storage.AppendOperationLog(ctx, OperationLogEntry{OperationID: "op-1", ContainerName: "web", Source: "progress", Message: message})
+		if err != nil {
+			t.Fatalf("Failed to append operation log: %v", err)
+		}
+		ids = append(ids, id)
+	}
+	if _, err := storage.AppendOperationLog(ctx, OperationLogEntry{OperationID: "op-2", Source: "progress", Message: "other"}); err != nil {
+		t.Fatalf("Failed to append operation log: %v", err)
+	}
+
+	logs, err := storage.GetOperationLogs(ctx, "op-1", 0)
+	if err != nil {
+		t.Fatalf("Failed to get operation logs: %v", err)
+	}
+	if len(logs) != 3 || logs[0].Message != "Pulling nginx:1.27" || logs[2].ID != ids[2] {
+		t.Errorf("Unexpected logs: %+v", logs)
+	}
+
+	logs, err = storage.GetOperationLogs(ctx, "op-1", ids[0])
+	if err != nil || len(logs) != 2 {
+		t.Errorf("Expected the 2 lines after the first, got %d (err=%v)", len(logs), err)
+	}
+
+	// Step logs are deleted with their operations
+	if _, err := storage.DeleteAllHistory(ctx); err != nil {
+		t.Fatalf("Failed to delete history: %v", err)
+	}
+	if logs, _ := storage.GetOperationLogs(ctx, "op-1", 0); len(logs) != 0 {
+		t.Errorf("Expected the logs of deleted operations to be removed, got %d", len(logs))
+	}
+}
+
+func TestAppendComposeOutput(t *testing.T) {
+	storage, err := NewSQLiteStorage(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer storage.Close()
+
+	ctx := context.Background()
+	op := UpdateOperation{OperationID: "op-1", ContainerName: "web", OperationType: "single", Status: StatusPullingImage}
+	if err := storage.SaveUpdateOperation(ctx, op); err != nil {
+		t.Fatalf("Failed to save operation: %v", err)
+	}
+
+	for _, output := range []string{"$ docker compose up -d web\n", "Container web  Started\n"} {
+		if err := storage.AppendComposeOutput(ctx, "op-1", output); err != nil {
+			t.Fatalf("Failed to append compose output: %v", err)
+		}
+	}
+
+	// Saving the operation does not overwrite the output
+	op.Status = StatusComplete
+	if err := storage.SaveUpdateOperation(ctx, op); err != nil {
+		t.Fatalf("Failed to save operation: %v", err)
+	}
+	got, _, err := storage.GetUpdateOperation(ctx, "op-1")
+	if err != nil {
+		t.Fatalf("Failed to get operation: %v", err)
+	}
+	if want := "$ docker compose up -d web\nContainer web  Started\n"; got.ComposeOutput != want || got.Status != StatusComplete {
+		t.Errorf("Expected compose output %q with status complete, got %q (%s)", want, got.ComposeOutput, got.Status)
+	}
+
+	// Only the end of long output is kept
+	if err := storage.AppendComposeOutput(ctx, "op-1", strings.Repeat("x", MaxComposeOutput)+"end"); err != nil {
+		t.Fatalf("Failed to append compose output: %v", err)
+	}
+	got, _, _ = storage.GetUpdateOperation(ctx, "op-1")
+	if len(got.ComposeOutput) != MaxComposeOutput || !strings.HasSuffix(got.ComposeOutput, "end") {
+		t.Errorf("Expected the last %d bytes to be kept, got %d", MaxComposeOutput, len(got.ComposeOutput))
+	}
+}
+
+func TestQueryAuditLog(t *testing.T) {
+	storage, err := NewSQLiteStorage(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer storage.Close()
+
+	ctx := context.Background()
+	old := time.Now().Add(-48 * time.Hour)
+	recent := time.Now().Add(-time.Hour)
+	for _, op := range []UpdateOperation{
+		{OperationID: "op-old", ContainerName: "web", OperationType: "single", Status: "complete", StartedAt: &old, TriggeredBy: "user:alice"},
+		{OperationID: "op-batch", ContainerName: "2 containers", OperationType: "batch", Status: "failed", StartedAt: &recent, TriggeredBy: "schedule:media",
+			BatchDetails: []BatchContainerDetail{
+				{ContainerName: "plex", OldVersion: "1.0", NewVersion: "1.1", Status: "complete"},
+				{ContainerName: "sonarr", OldVersion: "4.0", NewVersion: "4.1", Status: "failed", Message: "health check failed"},
+			}},
+	} {
+		if err := storage.SaveUpdateOperation(ctx, op); err != nil {
+			t.Fatalf("Failed to save operation: %v", err)
+		}
+	}
+
+	// Saving without a trigger keeps the recorded one
+	if err := storage.SaveUpdateOperation(ctx, UpdateOperation{OperationID: "op-old", ContainerName: "web", OperationType: "single", Status: "complete", StartedAt: &old}); err != nil {
+		t.Fatalf("Failed to save operation: %v", err)
+	}
+	if op, _, _ := storage.GetUpdateOperation(ctx, "op-old"); op.TriggeredBy != "user:alice" {
+		t.Errorf("Expected the trigger to be kept, got %q", op.TriggeredBy)
+	}
+
+	records, err := QueryAuditLog(ctx, storage, nil, nil)
+	if err != nil {
+		t.Fatalf("QueryAuditLog failed: %v", err)
+	}
+	if len(records) != 3 || records[0].OperationID != "op-old" || records[0].TriggeredBy != "user:alice" {
+		t.Fatalf("Expected 3 records oldest first, got %+v", records)
+	}
+
+	since := time.Now().Add(-24 * time.Hour)
+	records, err = QueryAuditLog(ctx, storage, &since, nil)
+	if err != nil {
+		t.Fatalf("QueryAuditLog failed: %v", err)
+	}
+	if len(records) != 2 || records[1].Container != "sonarr" || records[1].Status != "failed" || records[1].Error != "health check failed" {
+		t.Fatalf("Expected one record per batch container, got %+v", records)
+	}
+
+	var csvOut strings.Builder
+	if err := WriteAuditLog(&csvOut, AuditFormatCSV, records); err != nil {
+		t.Fatalf("WriteAuditLog failed: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(csvOut.String()), "\n")
+	if len(lines) != 3 || !strings.HasPrefix(lines[0], "timestamp,source,operation_id") || !strings.Contains(lines[1], ",plex,,1.0,1.1,complete,schedule:media,") {
+		t.Errorf("Unexpected CSV:\n%s", csvOut.String())
+	}
+
+	var jsonOut strings.Builder
+	if err := WriteAuditLog(&jsonOut, AuditFormatJSONL, records); err != nil {
+		t.Fatalf("WriteAuditLog failed: %v", err)
+	}
+	if lines := strings.Split(strings.TrimSpace(jsonOut.String()), "\n"); len(lines) != 2 || !strings.Contains(lines[0], `"triggered_by":"schedule:media"`) {
+		t.Errorf("Unexpected JSON lines:\n%s", jsonOut.String())
+	}
+
+	if err := WriteAuditLog(&jsonOut, "xml", records); err == nil {
+		t.Error("Expected an unsupported format to be rejected")
+	}
+}
@@ -380,3 +380,112 @@ func TestGetVersionCacheWithCustomTTL(t *testing.T) {
 		t.Error("Expected to find entry with 7-day TTL (entry is 5 days old)")
 	}
 }
+
+// TestTagCacheRoundTrip verifies tag lists and validators are persisted and replaced.
+func TestTagCacheRoundTrip(t *testing.T) {
+	tempDir := t.TempDir()
+	dbPath := filepath.Join(tempDir, "test.db")
+
+	storage, err := NewSQLiteStorage(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer storage.Close()
+
+	ctx := context.Background()
+
+	if _, found, err := storage.GetTagCache(ctx, "ghcr.io/org/app"); err != nil || found {
+		t.Fatalf("expected no entry, got found=%v err=%v", found, err)
+	}
+
+	fetchedAt := time.Now().Add(-10 * time.Minute).Truncate(time.Second)
+	entry := TagCacheEntry{
+		ImageRef:     "ghcr.io/org/app",
+		Tags:         []string{"1.0.0", "1.1.0", "latest"},
+		ETag:         `"abc123"`,
+		LastModified: "Wed, 14 Oct 2026 10:00:00 GMT",
+		FetchedAt:    fetchedAt,
+	}
+	if err := storage.SaveTagCache(ctx, entry); err != nil {
+		t.Fatalf("SaveTagCache failed: %v", err)
+	}
+
+	got, found, err := storage.GetTagCache(ctx, "ghcr.io/org/app")
+	if err != nil || !found {
+		t.Fatalf("expected entry, got found=%v err=%v", found, err)
+	}
+	if len(got.Tags) != 3 || got.Tags[2] != "latest" {
+		t.Errorf("unexpected tags: %v", got.Tags)
+	}
+	if got.ETag != entry.ETag || got.LastModified != entry.LastModified {
+		t.Errorf("validators not persisted: %+v", got)
+	}
+	if !got.FetchedAt.Equal(fetchedAt) {
+		t.Errorf("FetchedAt = %v, want %v", got.FetchedAt, fetchedAt)
+	}
+
+	// Saving again replaces the entry
+	entry.Tags = []string{"2.0.0"}
+	entry.ETag = ""
+	if err := storage.SaveTagCache(ctx, entry); err != nil {
+		t.Fatalf("SaveTagCache failed: %v", err)
+	}
+	got, _, _ = storage.GetTagCache(ctx, "ghcr.io/org/app")
+	if len(got.Tags) != 1 || got.Tags[0] != "2.0.0" || got.ETag != "" {
+		t.Errorf("entry not replaced: %+v", got)
+	}
+}
+
+// TestCheckFingerprintRoundTrip verifies check fingerprints are persisted and replaced.
+func TestCheckFingerprintRoundTrip(t *testing.T) {
+	tempDir := t.TempDir()
+	dbPath := filepath.Join(tempDir, "test.db")
+
+	storage, err := NewSQLiteStorage(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer storage.Close()
+
+	ctx := context.Background()
+
+	if _, found, err := storage.GetCheckFingerprint(ctx, "web"); err != nil || found {
+		t.Fatalf("expected no fingerprint, got found=%v err=%v", found, err)
+	}
+
+	checkedAt := time.Now().Add(-time.Hour).Truncate(time.Second)
+	fingerprint := CheckFingerprint{
+		ContainerName: "web",
+		Image:         "nginx:1.24.0",
+		LocalHash:     "local1",
+		HeadDigest:    "sha256:abc",
+		TagsHash:      "tags1",
+		Result:        `{"status":"UP_TO_DATE"}`,
+		CheckedAt:     checkedAt,
+	}
+	if err := storage.SaveCheckFingerprint(ctx, fingerprint); err != nil {
+		t.Fatalf("SaveCheckFingerprint failed: %v", err)
+	}
+
+	got, found, err := storage.GetCheckFingerprint(ctx, "web")
+	if err != nil || !found {
+		t.Fatalf("expected fingerprint, got found=%v err=%v", found, err)
+	}
+	if got.Image != fingerprint.Image || got.LocalHash != "local1" || got.HeadDigest != "sha256:abc" ||
+		got.TagsHash != "tags1" || got.Result != fingerprint.Result {
+		t.Errorf("fingerprint not persisted: %+v", got)
+	}
+	if !got.CheckedAt.Equal(checkedAt) {
+		t.Errorf("CheckedAt = %v, want %v", got.CheckedAt, checkedAt)
+	}
+
+	// Saving again replaces the fingerprint
+	fingerprint.HeadDigest = "sha256:def"
+	if err := storage.SaveCheckFingerprint(ctx, fingerprint); err != nil {
+		t.Fatalf("SaveCheckFingerprint failed: %v", err)
+	}
+	got, _, _ = storage.GetCheckFingerprint(ctx, "web")
+	if got.HeadDigest != "sha256:def" {
+		t.Errorf("fingerprint not replaced: %+v", got)
+	}
+}
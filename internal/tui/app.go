@@ -0,0 +1,276 @@
+// Package tui implements the interactive terminal dashboard started by `docksmith tui`.
+package tui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/chis/docksmith/internal/events"
+	"github.com/chis/docksmith/internal/update"
+)
+
+// Checker runs update checks for all containers.
+type Checker interface {
+	DiscoverAndCheck(ctx context.Context) (*update.DiscoveryResult, error)
+}
+
+// Updater starts container updates and reports their progress on the event bus.
+type Updater interface {
+	UpdateSingleContainer(ctx context.Context, containerName, targetVersion string) (string, error)
+	UpdateBatchContainers(ctx context.Context, containerNames []string, targetVersions map[string]string) (string, error)
+	PreviewComposeChange(ctx context.Context, containerName, targetVersion string) update.ComposePreview
+}
+
+// checkResult is the outcome of a background check.
+type checkResult struct {
+	result *update.DiscoveryResult
+	err    error
+}
+
+// busEvent is an event received from the event bus.
+type busEvent struct {
+	event events.Event
+}
+
+// App runs the dashboard on the process's terminal. It is the bubbletea model of
+// the program; the dashboard state and rendering are in Model.
+type App struct {
+	checker Checker
+	updater Updater
+	bus     *events.Bus
+	model   *Model
+
+	ctx           context.Context
+	progress      events.Subscriber
+	checkProgress events.Subscriber
+
+	width, height int
+	checkRunning  bool
+	quitRequested bool
+}
+
+// NewApp creates a dashboard using checker for checks and updater for updates.
+// Progress is read from bus, which must be the bus the updater publishes to.
+func NewApp(checker Checker, updater Updater, bus *events.Bus) *App {
+	return &App{
+		checker: checker,
+		updater: updater,
+		bus:     bus,
+		model:   NewModel(),
+		ctx:     context.Background(),
+	}
+}
+
+// Run shows the dashboard until the user quits or ctx is cancelled.
+func (a *App) Run(ctx context.Context) error {
+	progress, unsubscribeProgress := a.bus.Subscribe(events.EventUpdateProgress)
+	defer unsubscribeProgress()
+	checkProgress, unsubscribeCheck := a.bus.Subscribe(events.EventCheckProgress)
+	defer unsubscribeCheck()
+	a.ctx, a.progress, a.checkProgress = ctx, progress, checkProgress
+
+	_, err := tea.NewProgram(a, tea.WithAltScreen(), tea.WithContext(ctx)).Run()
+	if err != nil && !(errors.Is(err, tea.ErrProgramKilled) && ctx.Err() != nil) {
+		return fmt.Errorf("failed to run dashboard (is stdin a terminal?): %w", err)
+	}
+	return nil
+}
+
+// Init starts the first check and listens for progress events.
+func (a *App) Init() tea.Cmd {
+	return tea.Batch(a.check(), listen(a.progress), listen(a.checkProgress))
+}
+
+// Update applies a message: a key press, a terminal resize, a finished check,
+// or a progress event.
+func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		a.width, a.height = msg.Width, msg.Height
+	case tea.KeyMsg:
+		return a, a.handleKey(keyFor(msg))
+	case checkResult:
+		a.checkRunning = false
+		if msg.err != nil {
+			a.model.checking = false
+			a.model.SetStatus("Check failed: %v", msg.err)
+		} else {
+			a.model.SetResult(msg.result)
+		}
+	case busEvent:
+		switch msg.event.Type {
+		case events.EventCheckProgress:
+			a.model.ApplyCheckProgress(msg.event.Payload)
+			return a, listen(a.checkProgress)
+		case events.EventUpdateProgress:
+			cmds := []tea.Cmd{listen(a.progress)}
+			if a.model.ApplyProgress(msg.event.Payload) {
+				cmds = append(cmds, a.check())
+			}
+			return a, tea.Batch(cmds...)
+		}
+	}
+	return a, nil
+}
+
+// View renders the dashboard for the current terminal size.
+func (a *App) View() string {
+	width, height := a.width, a.height
+	if width == 0 {
+		width, height = 80, 24
+	}
+	return a.model.View(width, height)
+}
+
+// listen waits for the next event of a bus subscription.
+func listen(events events.Subscriber) tea.Cmd {
+	return func() tea.Msg {
+		event, ok := <-events
+		if !ok {
+			return nil
+		}
+		return busEvent{event: event}
+	}
+}
+
+// handleKey applies a key press and returns the command to run, tea.Quit to exit.
+func (a *App) handleKey(key Key) tea.Cmd {
+	if key != KeyQuit {
+		a.quitRequested = false
+	}
+	if a.model.PickerOpen() {
+		a.handlePickerKey(key)
+		return nil
+	}
+	if a.model.Confirming() {
+		a.handleConfirmKey(key)
+		return nil
+	}
+
+	switch key {
+	case KeyUp:
+		a.model.Move(-1)
+	case KeyDown:
+		a.model.Move(1)
+	case KeyPageUp:
+		a.model.Move(-10)
+	case KeyPageDown:
+		a.model.Move(10)
+	case KeySelect:
+		a.model.ToggleSelected()
+	case KeySelectAll:
+		a.model.SelectAll()
+	case KeyTags:
+		a.model.OpenTagPicker()
+	case KeyRefresh:
+		return a.check()
+	case KeyUpdate:
+		a.update()
+	case KeyQuit:
+		// Updates run in this process, so quitting would interrupt them
+		if a.model.Busy() && !a.quitRequested {
+			a.quitRequested = true
+			a.model.SetStatus("Updates are still running; press q again to quit anyway")
+			return nil
+		}
+		return tea.Quit
+	}
+	return nil
+}
+
+// handlePickerKey applies a key press while the tag picker is open.
+func (a *App) handlePickerKey(key Key) {
+	switch key {
+	case KeyUp:
+		a.model.MovePicker(-1)
+	case KeyDown:
+		a.model.MovePicker(1)
+	case KeyPageUp:
+		a.model.MovePicker(-10)
+	case KeyPageDown:
+		a.model.MovePicker(10)
+	case KeySelectAll:
+		a.model.ToggleAllTags()
+	case KeySelect, KeyUpdate:
+		a.model.ChooseTag()
+	case KeyTags, KeyQuit:
+		a.model.ClosePicker()
+	}
+}
+
+// handleConfirmKey applies a key press while the confirmation screen is shown.
+func (a *App) handleConfirmKey(key Key) {
+	switch key {
+	case KeyUp:
+		a.model.ScrollConfirm(-1)
+	case KeyDown:
+		a.model.ScrollConfirm(1)
+	case KeyPageUp:
+		a.model.ScrollConfirm(-10)
+	case KeyPageDown:
+		a.model.ScrollConfirm(10)
+	case KeyUpdate:
+		a.start(a.model.ConfirmedTargets())
+	case KeyQuit:
+		a.model.CancelConfirm()
+	}
+}
+
+// check returns a command running a check in the background, or nil if one is
+// already running.
+func (a *App) check() tea.Cmd {
+	if a.checkRunning {
+		return nil
+	}
+	a.checkRunning = true
+	a.model.SetChecking()
+
+	ctx := a.ctx
+	return func() tea.Msg {
+		result, err := a.checker.DiscoverAndCheck(ctx)
+		return checkResult{result: result, err: err}
+	}
+}
+
+// update shows the compose file changes of the selected containers' updates for confirmation.
+func (a *App) update() {
+	targets := a.model.Targets()
+	if len(targets) == 0 {
+		a.model.SetStatus("Nothing to update: select containers with an update available")
+		return
+	}
+
+	previews := make([]update.ComposePreview, 0, len(targets))
+	for name, version := range targets {
+		previews = append(previews, a.updater.PreviewComposeChange(a.ctx, name, version))
+	}
+	a.model.Confirm(targets, previews)
+}
+
+// start starts updates for the confirmed containers.
+func (a *App) start(targets map[string]string) {
+	names := make([]string, 0, len(targets))
+	for name := range targets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	ctx := update.WithTrigger(a.ctx, "tui")
+	var operationID string
+	var err error
+	if len(names) == 1 {
+		operationID, err = a.updater.UpdateSingleContainer(ctx, names[0], targets[names[0]])
+	} else {
+		operationID, err = a.updater.UpdateBatchContainers(ctx, names, targets)
+	}
+	if err != nil {
+		a.model.SetStatus("Failed to start update: %v", err)
+		return
+	}
+
+	a.model.StartOperation(operationID, names)
+	a.model.SetStatus("Updating %d container(s)...", len(names))
+}
@@ -0,0 +1,94 @@
+package tui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/chis/docksmith/internal/update"
+)
+
+// confirmation shows the compose file changes of the updates about to start.
+type confirmation struct {
+	targets  map[string]string
+	previews []update.ComposePreview
+	scroll   int // first line shown
+}
+
+// Confirm shows the compose file diffs of targets for confirmation before they are updated.
+func (m *Model) Confirm(targets map[string]string, previews []update.ComposePreview) {
+	sort.Slice(previews, func(i, j int) bool { return previews[i].ContainerName < previews[j].ContainerName })
+	m.confirm = &confirmation{targets: targets, previews: previews}
+}
+
+// Confirming reports whether the confirmation screen is shown.
+func (m *Model) Confirming() bool {
+	return m.confirm != nil
+}
+
+// ConfirmedTargets closes the confirmation screen and returns the containers to
+// update with their target versions.
+func (m *Model) ConfirmedTargets() map[string]string {
+	if m.confirm == nil {
+		return nil
+	}
+	targets := m.confirm.targets
+	m.confirm = nil
+	return targets
+}
+
+// CancelConfirm closes the confirmation screen without updating.
+func (m *Model) CancelConfirm() {
+	m.confirm = nil
+	m.SetStatus("Update cancelled")
+}
+
+// ScrollConfirm scrolls the confirmation screen by delta lines.
+func (m *Model) ScrollConfirm(delta int) {
+	if m.confirm == nil {
+		return
+	}
+	m.confirm.scroll = min(max(m.confirm.scroll+delta, 0), max(len(m.confirm.lines())-1, 0))
+}
+
+// lines returns the lines of the confirmation screen below its title.
+func (c *confirmation) lines() []string {
+	var lines []string
+	for i, p := range c.previews {
+		if i > 0 {
+			lines = append(lines, "")
+		}
+		lines = append(lines, fmt.Sprintf("%s -> %s", p.ContainerName, p.TargetVersion))
+		if p.Error != "" {
+			lines = append(lines, "  No compose file change: "+p.Error)
+			continue
+		}
+		lines = append(lines, strings.Split(strings.TrimSuffix(p.Diff, "\n"), "\n")...)
+	}
+	return lines
+}
+
+// viewConfirm renders the confirmation screen.
+func (m *Model) viewConfirm(width, height int) string {
+	c := m.confirm
+	var b strings.Builder
+	b.WriteString("docksmith  ↑/↓ scroll  enter update  esc cancel\n\n")
+	b.WriteString(truncate(fmt.Sprintf("Update %d container(s)? These compose file changes will be written:", len(c.targets)), width) + "\n")
+
+	lines := c.lines()
+	end := min(c.scroll+max(height-5, 1), len(lines))
+	for _, line := range lines[c.scroll:end] {
+		line = truncate(line, width)
+		switch {
+		case strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "---"):
+		case strings.HasPrefix(line, "+"):
+			line = "\x1b[32m" + line + "\x1b[0m"
+		case strings.HasPrefix(line, "-"):
+			line = "\x1b[31m" + line + "\x1b[0m"
+		}
+		b.WriteString(line + "\n")
+	}
+
+	b.WriteString("\n" + truncate(m.status, width))
+	return b.String()
+}
@@ -0,0 +1,47 @@
+package tui
+
+import tea "github.com/charmbracelet/bubbletea"
+
+// Key is a dashboard command read from the keyboard.
+type Key int
+
+// Keys understood by the dashboard
+const (
+	KeyUp Key = iota + 1
+	KeyDown
+	KeyPageUp
+	KeyPageDown
+	KeySelect
+	KeySelectAll
+	KeyTags
+	KeyUpdate
+	KeyRefresh
+	KeyQuit
+)
+
+// keyFor maps a key press to a dashboard command, or 0 for keys without one.
+func keyFor(msg tea.KeyMsg) Key {
+	switch msg.String() {
+	case "up", "k":
+		return KeyUp
+	case "down", "j":
+		return KeyDown
+	case "pgup":
+		return KeyPageUp
+	case "pgdown":
+		return KeyPageDown
+	case " ":
+		return KeySelect
+	case "a":
+		return KeySelectAll
+	case "t":
+		return KeyTags
+	case "enter", "u":
+		return KeyUpdate
+	case "r":
+		return KeyRefresh
+	case "q", "esc", "ctrl+c":
+		return KeyQuit
+	}
+	return 0
+}
@@ -0,0 +1,381 @@
+package tui
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/chis/docksmith/internal/update"
+)
+
+// Row is one container in the dashboard table.
+type Row struct {
+	Name     string
+	Stack    string
+	Current  string
+	Latest   string
+	Target   string // Tag chosen in the tag picker; empty to update to Latest
+	Status   update.UpdateStatus
+	Selected bool
+
+	// Progress of an update started from the dashboard
+	Busy     bool
+	Progress int
+	Stage    string
+	Message  string
+
+	container update.ContainerInfo // check result, for the tag picker
+}
+
+// TargetVersion returns the tag the row is updated to.
+func (r Row) TargetVersion() string {
+	if r.Target != "" {
+		return r.Target
+	}
+	return r.Latest
+}
+
+// Updatable reports whether the row has an update that can be applied.
+func (r Row) Updatable() bool {
+	return r.Status == update.UpdateAvailable && !r.Busy
+}
+
+// Model holds the dashboard state. It has no I/O so key handling,
+// progress tracking and rendering can be tested without a terminal.
+type Model struct {
+	rows     []Row
+	cursor   int
+	status   string
+	checking bool
+
+	// operations in flight, with the containers each one updates
+	operations map[string][]string
+
+	picker  *tagPicker    // open tag picker, or nil
+	confirm *confirmation // updates waiting for confirmation, or nil
+}
+
+// NewModel creates an empty dashboard.
+func NewModel() *Model {
+	return &Model{
+		checking:   true,
+		status:     "Checking for updates...",
+		operations: make(map[string][]string),
+	}
+}
+
+// Rows returns the table rows.
+func (m *Model) Rows() []Row {
+	return m.rows
+}
+
+// Cursor returns the index of the highlighted row.
+func (m *Model) Cursor() int {
+	return m.cursor
+}
+
+// SetStatus sets the message shown below the table.
+func (m *Model) SetStatus(format string, args ...any) {
+	m.status = fmt.Sprintf(format, args...)
+}
+
+// Busy reports whether updates started from the dashboard are still running.
+func (m *Model) Busy() bool {
+	return len(m.operations) > 0
+}
+
+// SetResult replaces the rows with a check result, keeping selection and
+// in-flight progress for containers that are still present.
+func (m *Model) SetResult(result *update.DiscoveryResult) {
+	previous := make(map[string]Row, len(m.rows))
+	for _, row := range m.rows {
+		previous[row.Name] = row
+	}
+	current := ""
+	if m.cursor < len(m.rows) {
+		current = m.rows[m.cursor].Name
+	}
+
+	rows := make([]Row, 0, len(result.Containers))
+	for _, c := range result.Containers {
+		row := Row{
+			Name:    c.ContainerName,
+			Stack:   c.Stack,
+			Current: c.CurrentVersion,
+			Latest:  c.LatestVersion,
+			Status:  c.Status,
+
+			container: c,
+		}
+		if row.Current == "" {
+			row.Current = c.CurrentTag
+		}
+		if prev, ok := previous[row.Name]; ok {
+			row.Selected = prev.Selected && row.Status == update.UpdateAvailable
+			if row.Status == update.UpdateAvailable && slices.Contains(c.AvailableTags, prev.Target) {
+				row.Target = prev.Target
+			}
+			if prev.Busy {
+				row.Busy, row.Progress, row.Stage, row.Message = true, prev.Progress, prev.Stage, prev.Message
+			}
+		}
+		rows = append(rows, row)
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		if rows[i].Stack != rows[j].Stack {
+			return rows[i].Stack < rows[j].Stack
+		}
+		return rows[i].Name < rows[j].Name
+	})
+
+	m.rows = rows
+	m.cursor = 0
+	for i, row := range rows {
+		if row.Name == current {
+			m.cursor = i
+		}
+	}
+	m.checking = false
+	m.status = fmt.Sprintf("%d containers, %d updates available", len(rows), result.UpdatesFound)
+}
+
+// SetChecking marks a check as started.
+func (m *Model) SetChecking() {
+	m.checking = true
+	m.status = "Checking for updates..."
+}
+
+// Checking reports whether a check is running.
+func (m *Model) Checking() bool {
+	return m.checking
+}
+
+// Move moves the cursor by delta rows, clamped to the table.
+func (m *Model) Move(delta int) {
+	m.cursor += delta
+	if m.cursor >= len(m.rows) {
+		m.cursor = len(m.rows) - 1
+	}
+	if m.cursor < 0 {
+		m.cursor = 0
+	}
+}
+
+// ToggleSelected selects or deselects the highlighted row if it has an update.
+func (m *Model) ToggleSelected() {
+	if m.cursor >= len(m.rows) {
+		return
+	}
+	row := &m.rows[m.cursor]
+	if !row.Updatable() {
+		m.SetStatus("%s has no update to apply", row.Name)
+		return
+	}
+	row.Selected = !row.Selected
+}
+
+// SelectAll selects every row with an update, or clears the selection if all are selected.
+func (m *Model) SelectAll() {
+	all := true
+	for _, row := range m.rows {
+		if row.Updatable() && !row.Selected {
+			all = false
+		}
+	}
+	for i := range m.rows {
+		m.rows[i].Selected = !all && m.rows[i].Updatable()
+	}
+}
+
+// Targets returns the containers to update with their target versions: the selected
+// rows, or the highlighted row when nothing is selected.
+func (m *Model) Targets() map[string]string {
+	targets := make(map[string]string)
+	for _, row := range m.rows {
+		if row.Selected && row.Updatable() {
+			targets[row.Name] = row.TargetVersion()
+		}
+	}
+	if len(targets) == 0 && m.cursor < len(m.rows) && m.rows[m.cursor].Updatable() {
+		row := m.rows[m.cursor]
+		targets[row.Name] = row.TargetVersion()
+	}
+	return targets
+}
+
+// StartOperation records an update operation for the given containers.
+func (m *Model) StartOperation(operationID string, names []string) {
+	m.operations[operationID] = names
+	for i := range m.rows {
+		for _, name := range names {
+			if m.rows[i].Name == name {
+				m.rows[i].Selected = false
+				m.rows[i].Busy = true
+				m.rows[i].Progress = 0
+				m.rows[i].Stage = "queued"
+				m.rows[i].Message = ""
+			}
+		}
+	}
+}
+
+// ApplyProgress applies an update.progress event payload. It returns true when
+// the event finished the last operation in flight.
+func (m *Model) ApplyProgress(payload map[string]any) bool {
+	operationID, _ := payload["operation_id"].(string)
+	names, ok := m.operations[operationID]
+	if !ok {
+		return false
+	}
+
+	stage, _ := payload["stage"].(string)
+	message, _ := payload["message"].(string)
+	percent, _ := payload["progress"].(int)
+
+	// Batch-level events have no container name and apply to the whole operation
+	if name, _ := payload["container_name"].(string); name != "" {
+		names = []string{name}
+	}
+
+	done := stage == "complete" || stage == "failed"
+	for i := range m.rows {
+		for _, name := range names {
+			if m.rows[i].Name != name {
+				continue
+			}
+			row := &m.rows[i]
+			row.Progress, row.Stage, row.Message = percent, stage, message
+			if done {
+				row.Busy = false
+			}
+		}
+	}
+
+	if !done {
+		return false
+	}
+
+	// Container events can complete one container of a batch; the operation ends when none are busy
+	finished := true
+	for _, name := range m.operations[operationID] {
+		for _, row := range m.rows {
+			if row.Name == name && row.Busy {
+				finished = false
+			}
+		}
+	}
+	if finished {
+		delete(m.operations, operationID)
+		if stage == "failed" {
+			m.SetStatus("Update failed: %s", message)
+		} else {
+			m.SetStatus("Update complete")
+		}
+	}
+	return finished && len(m.operations) == 0
+}
+
+// ApplyCheckProgress applies a check.progress event payload.
+func (m *Model) ApplyCheckProgress(payload map[string]any) {
+	if !m.checking {
+		return
+	}
+	checked, _ := payload["checked"].(int)
+	total, _ := payload["total"].(int)
+	if total > 0 {
+		m.status = fmt.Sprintf("Checking for updates... %d/%d", checked, total)
+	}
+}
+
+// View renders the dashboard for a terminal of the given size.
+func (m *Model) View(width, height int) string {
+	if m.picker != nil {
+		return m.viewPicker(width, height)
+	}
+	if m.confirm != nil {
+		return m.viewConfirm(width, height)
+	}
+
+	var b strings.Builder
+	b.WriteString("docksmith  ↑/↓ move  space select  a all  t tag  enter update  r refresh  q quit\n\n")
+
+	nameWidth, stackWidth, versionWidth := 4, 5, 7
+	for _, row := range m.rows {
+		nameWidth = max(nameWidth, len(row.Name))
+		stackWidth = max(stackWidth, len(row.Stack))
+		versionWidth = max(versionWidth, len(row.Current), len(row.TargetVersion()))
+	}
+	nameWidth, stackWidth, versionWidth = min(nameWidth, 30), min(stackWidth, 20), min(versionWidth, 24)
+
+	header := fmt.Sprintf("     %-*s  %-*s  %-*s  %-*s  %s", nameWidth, "NAME", stackWidth, "STACK", versionWidth, "CURRENT", versionWidth, "TARGET", "STATUS")
+	b.WriteString(truncate(header, width) + "\n")
+
+	// Keep the cursor visible when there are more rows than lines
+	visible := max(height-5, 1)
+	start := 0
+	if m.cursor >= visible {
+		start = m.cursor - visible + 1
+	}
+	end := min(start+visible, len(m.rows))
+
+	for i := start; i < end; i++ {
+		row := m.rows[i]
+		pointer, mark := " ", "[ ]"
+		if i == m.cursor {
+			pointer = ">"
+		}
+		if row.Selected {
+			mark = "[x]"
+		} else if !row.Updatable() {
+			mark = "   "
+		}
+
+		line := fmt.Sprintf("%s%s %-*s  %-*s  %-*s  %-*s  %s", pointer, mark,
+			nameWidth, truncate(row.Name, nameWidth),
+			stackWidth, truncate(row.Stack, stackWidth),
+			versionWidth, truncate(row.Current, versionWidth),
+			versionWidth, truncate(row.TargetVersion(), versionWidth),
+			rowStatus(row))
+		line = truncate(line, width)
+		if i == m.cursor {
+			line = "\x1b[7m" + line + "\x1b[0m"
+		}
+		b.WriteString(line + "\n")
+	}
+	if len(m.rows) == 0 && !m.checking {
+		b.WriteString("     No containers found\n")
+	}
+
+	b.WriteString("\n" + truncate(m.status, width))
+	return b.String()
+}
+
+// rowStatus describes a row's update status or the progress of its update.
+func rowStatus(row Row) string {
+	if row.Busy || row.Stage == "complete" || row.Stage == "failed" {
+		const barWidth = 20
+		filled := row.Progress * barWidth / 100
+		status := fmt.Sprintf("[%s%s] %3d%% %s", strings.Repeat("#", filled), strings.Repeat("-", barWidth-filled), row.Progress, row.Stage)
+		if row.Stage == "failed" && row.Message != "" {
+			status += ": " + row.Message
+		}
+		return status
+	}
+	return strings.ToLower(strings.ReplaceAll(string(row.Status), "_", " "))
+}
+
+// truncate shortens s to at most n runes.
+func truncate(s string, n int) string {
+	if n <= 0 {
+		return s
+	}
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	if n == 1 {
+		return string(runes[:1])
+	}
+	return string(runes[:n-1]) + "…"
+}
@@ -0,0 +1,224 @@
+package tui
+
+import (
+	"context"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/chis/docksmith/internal/events"
+	"github.com/chis/docksmith/internal/update"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func container(name, stack, current, latest string, status update.UpdateStatus) update.ContainerInfo {
+	c := update.ContainerInfo{Stack: stack}
+	c.ContainerName = name
+	c.CurrentVersion = current
+	c.LatestVersion = latest
+	c.Status = status
+	return c
+}
+
+func testModel() *Model {
+	m := NewModel()
+	m.SetResult(&update.DiscoveryResult{
+		Containers: []update.ContainerInfo{
+			container("web", "media", "1.0", "1.1", update.UpdateAvailable),
+			container("db", "media", "15.1", "15.1", update.UpToDate),
+			container("cache", "infra", "7.0", "7.2", update.UpdateAvailable),
+		},
+		UpdatesFound: 2,
+	})
+	return m
+}
+
+func TestModel_SelectionAndTargets(t *testing.T) {
+	m := testModel()
+	rows := m.Rows()
+	require.Len(t, rows, 3)
+	assert.Equal(t, "cache", rows[0].Name, "rows are sorted by stack and name")
+
+	// Nothing selected: the highlighted row is the target
+	assert.Equal(t, map[string]string{"cache": "7.2"}, m.Targets())
+
+	// Up-to-date rows cannot be selected
+	m.Move(1)
+	assert.Equal(t, "db", m.Rows()[m.Cursor()].Name)
+	m.ToggleSelected()
+	assert.False(t, m.Rows()[1].Selected)
+	assert.Empty(t, m.Targets())
+
+	m.SelectAll()
+	assert.Equal(t, map[string]string{"cache": "7.2", "web": "1.1"}, m.Targets())
+	m.SelectAll()
+	assert.Equal(t, map[string]string{}, m.Targets())
+
+	// Cursor stays in range
+	m.Move(10)
+	assert.Equal(t, 2, m.Cursor())
+	m.Move(-10)
+	assert.Equal(t, 0, m.Cursor())
+}
+
+func TestModel_BatchProgress(t *testing.T) {
+	m := testModel()
+	m.StartOperation("op-1", []string{"cache", "web"})
+	assert.True(t, m.Busy())
+	assert.Empty(t, m.Targets(), "busy rows are not updatable")
+
+	// Events for other operations are ignored
+	assert.False(t, m.ApplyProgress(map[string]any{"operation_id": "other", "stage": "complete"}))
+
+	// Batch-level event without a container applies to every container of the operation
+	assert.False(t, m.ApplyProgress(map[string]any{"operation_id": "op-1", "stage": "pulling", "progress": 30}))
+	for _, row := range m.Rows() {
+		if row.Name != "db" {
+			assert.Equal(t, 30, row.Progress)
+			assert.Contains(t, rowStatus(row), "[######--------------]  30% pulling")
+		}
+	}
+
+	// One container finishing does not end the batch
+	assert.False(t, m.ApplyProgress(map[string]any{"operation_id": "op-1", "container_name": "cache", "stage": "complete", "progress": 100}))
+	assert.True(t, m.Busy())
+
+	assert.True(t, m.ApplyProgress(map[string]any{"operation_id": "op-1", "container_name": "web", "stage": "failed", "message": "pull failed"}))
+	assert.False(t, m.Busy())
+	assert.Contains(t, m.View(120, 24), "failed: pull failed")
+}
+
+func TestModel_ViewScrollsToCursor(t *testing.T) {
+	m := NewModel()
+	var containers []update.ContainerInfo
+	for _, name := range []string{"a", "b", "c", "d", "e", "f", "g", "h"} {
+		containers = append(containers, container(name, "", "1", "2", update.UpdateAvailable))
+	}
+	m.SetResult(&update.DiscoveryResult{Containers: containers})
+
+	m.Move(7)
+	view := m.View(80, 8) // three visible rows
+	assert.Contains(t, view, ">[ ] h")
+	assert.NotContains(t, view, "[ ] a ")
+}
+
+func TestKeyFor(t *testing.T) {
+	runes := func(s string) tea.KeyMsg { return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)} }
+	assert.Equal(t, KeyUp, keyFor(tea.KeyMsg{Type: tea.KeyUp}))
+	assert.Equal(t, KeyDown, keyFor(runes("j")))
+	assert.Equal(t, KeyPageDown, keyFor(tea.KeyMsg{Type: tea.KeyPgDown}))
+	assert.Equal(t, KeySelect, keyFor(tea.KeyMsg{Type: tea.KeySpace, Runes: []rune(" ")}))
+	assert.Equal(t, KeyUpdate, keyFor(tea.KeyMsg{Type: tea.KeyEnter}))
+	assert.Equal(t, KeyQuit, keyFor(tea.KeyMsg{Type: tea.KeyCtrlC}))
+	assert.Equal(t, KeyQuit, keyFor(tea.KeyMsg{Type: tea.KeyEsc}))
+	assert.Equal(t, Key(0), keyFor(runes("x")))
+}
+
+func TestModel_TagPicker(t *testing.T) {
+	web := container("web", "media", "1.0.0", "1.2.0", update.UpdateAvailable)
+	web.Image = "example/web:1.0.0"
+	web.CurrentTag = "1.0.0"
+	web.AvailableTags = []string{"0.9.0", "1.0.0", "1.1.0", "1.2.0", "1.3.0-rc1", "latest"}
+	m := NewModel()
+	m.SetResult(&update.DiscoveryResult{Containers: []update.ContainerInfo{web}})
+
+	m.OpenTagPicker()
+	require.True(t, m.PickerOpen())
+	assert.Equal(t, []string{"1.2.0", "1.1.0"}, m.picker.tags, "newer tags passing the filters")
+	assert.Contains(t, m.View(80, 24), "> 1.2.0  (latest)")
+
+	// All tags include prereleases, older, and unversioned tags
+	m.ToggleAllTags()
+	assert.Equal(t, []string{"1.3.0-rc1", "1.2.0", "1.1.0", "1.0.0", "0.9.0", "latest"}, m.picker.tags)
+	assert.Equal(t, 1, m.picker.cursor, "cursor stays on the highlighted tag")
+	m.ToggleAllTags()
+
+	m.MovePicker(5)
+	m.ChooseTag()
+	assert.False(t, m.PickerOpen())
+	row := m.Rows()[0]
+	assert.True(t, row.Selected)
+	assert.Equal(t, "1.1.0", row.TargetVersion())
+	assert.Equal(t, map[string]string{"web": "1.1.0"}, m.Targets())
+	assert.Contains(t, m.View(80, 24), "1.1.0")
+
+	// The chosen tag survives a refresh
+	m.SetResult(&update.DiscoveryResult{Containers: []update.ContainerInfo{web}})
+	assert.Equal(t, "1.1.0", m.Rows()[0].TargetVersion())
+
+	// Cancelling keeps the target
+	m.OpenTagPicker()
+	assert.Equal(t, 1, m.picker.cursor)
+	m.ClosePicker()
+	assert.Equal(t, "1.1.0", m.Rows()[0].TargetVersion())
+}
+
+func TestModel_TagPickerNeedsUpdate(t *testing.T) {
+	m := testModel()
+	m.Move(1) // db is up to date
+	m.OpenTagPicker()
+	assert.False(t, m.PickerOpen())
+	assert.Contains(t, m.View(80, 24), "db has no update to apply")
+}
+
+func TestModel_Confirm(t *testing.T) {
+	m := testModel()
+	targets := m.Targets()
+	m.Confirm(targets, []update.ComposePreview{
+		{ContainerName: "web", TargetVersion: "1.1", Error: "image for web is set by an environment variable (${WEB_IMAGE})"},
+		{ContainerName: "cache", TargetVersion: "7.2", Diff: "--- a/srv/infra/compose.yml\n+++ b/srv/infra/compose.yml\n@@ -1,2 +1,2 @@\n services:\n-  image: redis:7.0\n+  image: redis:7.2\n"},
+	})
+	require.True(t, m.Confirming())
+
+	view := m.View(120, 24)
+	assert.Contains(t, view, "Update 1 container(s)?")
+	assert.Contains(t, view, "cache -> 7.2\n--- a/srv/infra/compose.yml")
+	assert.Contains(t, view, "\x1b[31m-  image: redis:7.0\x1b[0m")
+	assert.Contains(t, view, "\x1b[32m+  image: redis:7.2\x1b[0m")
+	assert.Contains(t, view, "No compose file change: image for web is set by an environment variable")
+
+	m.ScrollConfirm(100)
+	assert.Equal(t, 9, m.confirm.scroll, "scrolling stops at the last line")
+
+	assert.Equal(t, targets, m.ConfirmedTargets())
+	assert.False(t, m.Confirming())
+
+	m.Confirm(targets, nil)
+	m.CancelConfirm()
+	assert.False(t, m.Confirming())
+	assert.Contains(t, m.View(120, 24), "Update cancelled")
+}
+
+type fakeChecker struct {
+	result *update.DiscoveryResult
+}
+
+func (c *fakeChecker) DiscoverAndCheck(ctx context.Context) (*update.DiscoveryResult, error) {
+	return c.result, nil
+}
+
+func TestApp_Update(t *testing.T) {
+	checker := &fakeChecker{result: &update.DiscoveryResult{
+		Containers: []update.ContainerInfo{container("web", "media", "1.0", "1.1", update.UpdateAvailable)},
+	}}
+	app := NewApp(checker, nil, events.NewBus())
+
+	// The first check runs in the background and its result is delivered as a message
+	cmd := app.Init()
+	require.NotNil(t, cmd)
+	assert.Nil(t, app.check(), "a second check does not start while one runs")
+	app.Update(checkResult{result: checker.result})
+	require.Len(t, app.model.Rows(), 1)
+
+	app.Update(tea.WindowSizeMsg{Width: 100, Height: 10})
+	assert.Contains(t, app.View(), "web")
+
+	// Quitting asks for confirmation while updates run
+	app.model.StartOperation("op-1", []string{"web"})
+	_, cmd = app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("q")})
+	assert.Nil(t, cmd)
+	assert.Contains(t, app.View(), "press q again")
+	_, cmd = app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("q")})
+	require.NotNil(t, cmd)
+	assert.Equal(t, tea.Quit(), cmd())
+}
@@ -0,0 +1,167 @@
+package tui
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/chis/docksmith/internal/update"
+)
+
+// tagPicker chooses the tag a container is updated to.
+type tagPicker struct {
+	name   string // container the tag is chosen for
+	tags   []string
+	all    bool // showing every available tag instead of the update candidates
+	cursor int
+}
+
+// PickerOpen reports whether the tag picker is shown.
+func (m *Model) PickerOpen() bool {
+	return m.picker != nil
+}
+
+// OpenTagPicker opens the tag picker for the highlighted row. It lists the tags
+// the container can be updated to, or every available tag when there are none.
+func (m *Model) OpenTagPicker() {
+	if m.cursor >= len(m.rows) {
+		return
+	}
+	row := m.rows[m.cursor]
+	if !row.Updatable() {
+		m.SetStatus("%s has no update to apply", row.Name)
+		return
+	}
+
+	p := &tagPicker{name: row.Name}
+	p.setTags(candidateTags(row), row.TargetVersion())
+	if len(p.tags) == 0 {
+		p.all = true
+		p.setTags(update.SortedTags(row.container), row.TargetVersion())
+	}
+	if len(p.tags) == 0 {
+		m.SetStatus("No tags available for %s", row.Name)
+		return
+	}
+	m.picker = p
+}
+
+// candidateTags returns the update candidates of a row, including its latest
+// version when the update was found by digest.
+func candidateTags(row Row) []string {
+	tags := update.CandidateTags(row.container)
+	if row.Latest != "" && !slices.Contains(tags, row.Latest) {
+		tags = append([]string{row.Latest}, tags...)
+	}
+	return tags
+}
+
+// setTags replaces the listed tags, keeping the cursor on current if it is listed.
+func (p *tagPicker) setTags(tags []string, current string) {
+	p.tags = tags
+	p.cursor = max(slices.Index(tags, current), 0)
+}
+
+// MovePicker moves the tag picker's cursor by delta tags, clamped to the list.
+func (m *Model) MovePicker(delta int) {
+	if m.picker == nil {
+		return
+	}
+	m.picker.cursor = min(max(m.picker.cursor+delta, 0), len(m.picker.tags)-1)
+}
+
+// ToggleAllTags switches the tag picker between the update candidates and every available tag.
+func (m *Model) ToggleAllTags() {
+	p := m.picker
+	if p == nil {
+		return
+	}
+	i := slices.IndexFunc(m.rows, func(r Row) bool { return r.Name == p.name })
+	if i < 0 {
+		return
+	}
+	current := p.tags[p.cursor]
+	tags := update.SortedTags(m.rows[i].container)
+	if p.all {
+		tags = candidateTags(m.rows[i])
+	}
+	if len(tags) == 0 {
+		return
+	}
+	p.all = !p.all
+	p.setTags(tags, current)
+}
+
+// ChooseTag sets the highlighted tag as the target of the picker's container,
+// selects the container for updating, and closes the picker.
+func (m *Model) ChooseTag() {
+	p := m.picker
+	if p == nil {
+		return
+	}
+	m.picker = nil
+
+	i := slices.IndexFunc(m.rows, func(r Row) bool { return r.Name == p.name })
+	if i < 0 || !m.rows[i].Updatable() {
+		m.SetStatus("%s has no update to apply", p.name)
+		return
+	}
+	row := &m.rows[i]
+	row.Target = p.tags[p.cursor]
+	if row.Target == row.Latest {
+		row.Target = ""
+	}
+	row.Selected = true
+	m.SetStatus("%s will be updated to %s", row.Name, row.TargetVersion())
+}
+
+// ClosePicker closes the tag picker without changing the target.
+func (m *Model) ClosePicker() {
+	m.picker = nil
+}
+
+// viewPicker renders the tag picker.
+func (m *Model) viewPicker(width, height int) string {
+	p := m.picker
+	var b strings.Builder
+	b.WriteString("docksmith  ↑/↓ move  enter choose  a all tags  esc cancel\n\n")
+
+	var current, latest string
+	if i := slices.IndexFunc(m.rows, func(r Row) bool { return r.Name == p.name }); i >= 0 {
+		current, latest = m.rows[i].Current, m.rows[i].Latest
+	}
+	title := fmt.Sprintf("Update %s to (%d update candidates)", p.name, len(p.tags))
+	if p.all {
+		title = fmt.Sprintf("Update %s to (all %d tags)", p.name, len(p.tags))
+	}
+	b.WriteString(truncate(title, width) + "\n")
+
+	visible := max(height-5, 1)
+	start := 0
+	if p.cursor >= visible {
+		start = p.cursor - visible + 1
+	}
+	end := min(start+visible, len(p.tags))
+
+	for i := start; i < end; i++ {
+		tag := p.tags[i]
+		line := "  " + tag
+		if i == p.cursor {
+			line = "> " + tag
+		}
+		switch tag {
+		case latest:
+			line += "  (latest)"
+		case current:
+			line += "  (current)"
+		}
+		line = truncate(line, width)
+		if i == p.cursor {
+			line = "\x1b[7m" + line + "\x1b[0m"
+		}
+		b.WriteString(line + "\n")
+	}
+
+	b.WriteString("\n" + truncate(m.status, width))
+	return b.String()
+}
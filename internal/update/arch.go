@@ -0,0 +1,128 @@
+package update
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/chis/docksmith/internal/docker"
+	"github.com/chis/docksmith/internal/registry"
+	"github.com/chis/docksmith/internal/scripts"
+	"github.com/chis/docksmith/internal/version"
+)
+
+// maxArchFallbackTags bounds how many older candidates are probed for the host
+// architecture, since each probe is a manifest request.
+const maxArchFallbackTags = 5
+
+// SetArchFallback sets whether checks fall back to the newest tag that supports
+// the host architecture when the latest one does not. The docksmith.arch-fallback
+// label overrides it per container.
+func (o *Orchestrator) SetArchFallback(enabled bool) {
+	o.checker.archFallback = enabled
+}
+
+// ArchFallbackFromEnv reads the architecture fallback setting from ARCH_FALLBACK.
+// Returns false when unset or invalid.
+func ArchFallbackFromEnv() bool {
+	value := os.Getenv("ARCH_FALLBACK")
+	if value == "" {
+		return false
+	}
+	enabled, ok := parseLabelBool(value)
+	if !ok {
+		log.Printf("Warning: Invalid ARCH_FALLBACK '%s', not falling back to older tags", value)
+		return false
+	}
+	log.Printf("Using ARCH_FALLBACK: %v", enabled)
+	return enabled
+}
+
+// archFallbackEnabled reports whether a container falls back to older tags for its architecture.
+func (c *Checker) archFallbackEnabled(container docker.Container) bool {
+	if enabled, ok := parseLabelBool(container.Labels[scripts.ArchFallbackLabel]); ok {
+		return enabled
+	}
+	return c.archFallback
+}
+
+// verifyArchitecture checks that the update candidate has an image for the host
+// architecture. If it has none, nextCandidate (when set) is asked for the newest
+// remaining version, skipping the tags already found unsupported, and the update
+// switches to the first one that supports the architecture. Otherwise the update
+// is marked UpdateUnavailableArch. Registry errors leave the update unchanged.
+func (c *Checker) verifyArchitecture(ctx context.Context, update *ContainerUpdate, imageRef string, currentVer *version.Version, nextCandidate func(exclude map[string]bool) string) {
+	arch := registry.HostArchitecture
+	target := update.LatestVersion
+	if target == "" {
+		return
+	}
+
+	supported, err := c.supportsArchitecture(ctx, imageRef, target, arch)
+	if err != nil {
+		log.Printf("checkContainer %s: Skipping architecture check of %s: %v", update.ContainerName, target, err)
+		return
+	}
+	if supported {
+		return
+	}
+	log.Printf("checkContainer %s: %s has no image for linux/%s", update.ContainerName, target, arch)
+
+	if nextCandidate != nil {
+		exclude := map[string]bool{target: true}
+		for i := 0; i < maxArchFallbackTags; i++ {
+			candidate := nextCandidate(exclude)
+			if candidate == "" {
+				break
+			}
+			supported, err := c.supportsArchitecture(ctx, imageRef, candidate, arch)
+			if err != nil {
+				log.Printf("checkContainer %s: Stopping architecture fallback at %s: %v", update.ContainerName, candidate, err)
+				break
+			}
+			if supported {
+				log.Printf("checkContainer %s: Falling back to %s for linux/%s", update.ContainerName, candidate, arch)
+				update.LatestVersion = candidate
+				if update.LatestResolvedVersion == target {
+					update.LatestResolvedVersion = candidate
+				}
+				if update.RecommendedTag == target {
+					update.RecommendedTag = candidate
+				}
+				if candidateVer := c.versionParser.ParseTag(candidate); currentVer != nil && candidateVer != nil {
+					update.ChangeType = c.versionComp.GetChangeType(currentVer, candidateVer)
+				}
+				update.Note = fmt.Sprintf("%s has no image for linux/%s, using %s", target, arch, candidate)
+				return
+			}
+			exclude[candidate] = true
+		}
+	}
+
+	update.Status = UpdateUnavailableArch
+	update.Error = fmt.Sprintf("%s has no image for linux/%s", target, arch)
+}
+
+// supportsArchitecture reports whether the image at reference has an image for arch.
+func (c *Checker) supportsArchitecture(ctx context.Context, imageRef, reference, arch string) (bool, error) {
+	platforms, err := c.registry(ctx).GetImagePlatforms(ctx, imageRef, reference)
+	if err != nil {
+		return false, err
+	}
+	return registry.SupportsArchitecture(platforms, arch), nil
+}
+
+// parseLabelBool parses a true/false label value as the other boolean labels do.
+// ok is false when the value is empty or not a boolean.
+func parseLabelBool(value string) (enabled bool, ok bool) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "true", "1", "yes":
+		return true, true
+	case "false", "0", "no":
+		return false, true
+	default:
+		return false, false
+	}
+}
@@ -0,0 +1,123 @@
+package update
+
+import (
+	"context"
+	"testing"
+
+	"github.com/chis/docksmith/internal/docker"
+	"github.com/chis/docksmith/internal/registry"
+	"github.com/chis/docksmith/internal/scripts"
+	"github.com/chis/docksmith/internal/version"
+)
+
+// newArchTestChecker returns a checker for an nginx 1.24.0 container whose newer
+// tags have images for amd64 only, except 1.25.0
+func newArchTestChecker(labels map[string]string) *Checker {
+	mockDocker := &mockDockerClient{
+		containers: []docker.Container{
+			{ID: "test-container", Name: "test", Image: "docker.io/library/nginx:1.24.0", Labels: labels},
+		},
+		imageDigests:  map[string]string{"docker.io/library/nginx:1.24.0": "sha256:abc123"},
+		imageVersions: map[string]string{},
+		localImages:   map[string]bool{},
+	}
+	mockRegistry := &mockRegistryClient{
+		tags: map[string][]string{
+			"docker.io/library/nginx": {"1.27.0", "1.26.0", "1.25.0", "1.24.0"},
+		},
+		tagDigests:     map[string]string{},
+		digestMappings: map[string]map[string][]string{},
+		platforms: map[string][]string{
+			"docker.io/library/nginx:1.27.0": {"linux/amd64"},
+			"docker.io/library/nginx:1.26.0": {"linux/amd64"},
+			"docker.io/library/nginx:1.25.0": {"linux/amd64", "linux/arm64/v8"},
+		},
+	}
+	return NewChecker(mockDocker, mockRegistry, nil)
+}
+
+func setHostArchitecture(t *testing.T, arch string) {
+	previous := registry.HostArchitecture
+	registry.HostArchitecture = arch
+	t.Cleanup(func() { registry.HostArchitecture = previous })
+}
+
+func TestCheckerMarksUpdateUnavailableForArch(t *testing.T) {
+	setHostArchitecture(t, "arm64")
+
+	result, err := newArchTestChecker(nil).CheckForUpdates(context.Background())
+	if err != nil {
+		t.Fatalf("CheckForUpdates failed: %v", err)
+	}
+
+	update := result.Updates[0]
+	if update.Status != UpdateUnavailableArch {
+		t.Fatalf("Expected UPDATE_UNAVAILABLE_ARCH, got %s", update.Status)
+	}
+	if update.Error != "1.27.0 has no image for linux/arm64" {
+		t.Errorf("Unexpected error: %s", update.Error)
+	}
+}
+
+func TestCheckerFallsBackToSupportedTag(t *testing.T) {
+	setHostArchitecture(t, "arm64")
+
+	checker := newArchTestChecker(nil)
+	checker.archFallback = true
+	result, err := checker.CheckForUpdates(context.Background())
+	if err != nil {
+		t.Fatalf("CheckForUpdates failed: %v", err)
+	}
+
+	update := result.Updates[0]
+	if update.Status != UpdateAvailable {
+		t.Fatalf("Expected UPDATE_AVAILABLE, got %s", update.Status)
+	}
+	if update.LatestVersion != "1.25.0" {
+		t.Errorf("Expected fallback to 1.25.0, got %s", update.LatestVersion)
+	}
+	if update.ChangeType != version.MinorChange {
+		t.Errorf("Expected minor change, got %s", update.ChangeType)
+	}
+	if update.Note == "" {
+		t.Error("Expected a note about the fallback")
+	}
+
+	// The label turns the fallback off for one container
+	checker = newArchTestChecker(map[string]string{scripts.ArchFallbackLabel: "false"})
+	checker.archFallback = true
+	result, err = checker.CheckForUpdates(context.Background())
+	if err != nil {
+		t.Fatalf("CheckForUpdates failed: %v", err)
+	}
+	if result.Updates[0].Status != UpdateUnavailableArch {
+		t.Errorf("Expected UPDATE_UNAVAILABLE_ARCH with the label off, got %s", result.Updates[0].Status)
+	}
+}
+
+func TestCheckerArchFallbackWithoutSupportedTag(t *testing.T) {
+	setHostArchitecture(t, "s390x")
+
+	checker := newArchTestChecker(map[string]string{scripts.ArchFallbackLabel: "true"})
+	result, err := checker.CheckForUpdates(context.Background())
+	if err != nil {
+		t.Fatalf("CheckForUpdates failed: %v", err)
+	}
+	if result.Updates[0].Status != UpdateUnavailableArch {
+		t.Errorf("Expected UPDATE_UNAVAILABLE_ARCH, got %s", result.Updates[0].Status)
+	}
+}
+
+func TestCheckerAssumesUnknownPlatformsSupported(t *testing.T) {
+	setHostArchitecture(t, "arm64")
+
+	checker := newArchTestChecker(nil)
+	checker.registryManager.(*mockRegistryClient).platforms = nil
+	result, err := checker.CheckForUpdates(context.Background())
+	if err != nil {
+		t.Fatalf("CheckForUpdates failed: %v", err)
+	}
+	if result.Updates[0].Status != UpdateAvailable || result.Updates[0].LatestVersion != "1.27.0" {
+		t.Errorf("Expected update to 1.27.0, got %s %s", result.Updates[0].Status, result.Updates[0].LatestVersion)
+	}
+}
@@ -3,6 +3,7 @@ package update
 import (
 	"context"
 	"log"
+	"math/rand/v2"
 	"strconv"
 	"sync"
 	"time"
@@ -19,6 +20,7 @@ type BackgroundChecker struct {
 	eventBus        *events.Bus
 	storage         storage.Storage
 	interval        time.Duration
+	jitter          time.Duration    // Random delay added to each interval
 	cache           *CheckResultCache
 	stopChan        chan struct{}
 	runningMu       sync.Mutex
@@ -26,8 +28,36 @@ type BackgroundChecker struct {
 	unsubscribe     func()           // Unsubscribe from event bus
 	refreshTimer    *time.Timer      // Debounce timer for container update refreshes
 	refreshTimerMu  sync.Mutex       // Protects refreshTimer
+	scheduleMu      sync.RWMutex     // Protects paused and nextRun
+	paused          bool             // Scheduled checks are skipped while paused
+	nextRun         time.Time        // When the next scheduled check fires
+	resultHandlers  []ResultHandler  // Called after each successful check
 }
 
+// ResultHandler receives the results of each completed background check
+type ResultHandler func(ctx context.Context, result *DiscoveryResult)
+
+// CheckerStatus describes the background checker's schedule
+type CheckerStatus struct {
+	Running  bool   `json:"running"`
+	Paused   bool   `json:"paused"`
+	Checking bool   `json:"checking"`
+	Interval string `json:"interval"`
+	Jitter   string `json:"jitter"`
+	LastRun  string `json:"last_run,omitempty"`
+	NextRun  string `json:"next_run,omitempty"`
+}
+
+// CheckerPausedConfigKey persists the paused state across restarts
+const CheckerPausedConfigKey = "background_checker_paused"
+
+// Config keys holding the check schedule used when CHECK_INTERVAL and
+// CHECK_JITTER are not set, e.g. after a configuration import
+const (
+	CheckIntervalConfigKey = "check_interval"
+	CheckJitterConfigKey   = "check_jitter"
+)
+
 // CheckResultCache stores the latest check results
 type CheckResultCache struct {
 	mu                 sync.RWMutex
@@ -41,8 +71,9 @@ type CheckResultCache struct {
 
 // NewBackgroundChecker creates a new background checker
 func NewBackgroundChecker(orchestrator *Orchestrator, dockerClient docker.Client, eventBus *events.Bus, storage storage.Storage, interval time.Duration) *BackgroundChecker {
-	// Try to load last_cache_refresh from database
+	// Try to load last_cache_refresh and paused state from database
 	var lastCacheRefresh time.Time
+	var paused bool
 	if storage != nil {
 		ctx := context.Background()
 		if timestampStr, found, err := storage.GetConfig(ctx, "last_cache_refresh"); err == nil && found {
@@ -51,6 +82,12 @@ func NewBackgroundChecker(orchestrator *Orchestrator, dockerClient docker.Client
 				log.Printf("BACKGROUND_CHECKER: Loaded last_cache_refresh from database: %s", timestampStr)
 			}
 		}
+		if pausedStr, found, err := storage.GetConfig(ctx, CheckerPausedConfigKey); err == nil && found {
+			paused = pausedStr == "true"
+			if paused {
+				log.Printf("BACKGROUND_CHECKER: Scheduled checks are paused")
+			}
+		}
 	}
 
 	return &BackgroundChecker{
@@ -66,9 +103,26 @@ func NewBackgroundChecker(orchestrator *Orchestrator, dockerClient docker.Client
 			cacheCleared:      false,
 		},
 		stopChan: make(chan struct{}),
+		paused:   paused,
 	}
 }
 
+// SetJitter sets the maximum random delay added to each check interval.
+// Spreading checks out avoids many instances hitting registries at the same moment.
+// Must be called before Start.
+func (bc *BackgroundChecker) SetJitter(jitter time.Duration) {
+	if jitter < 0 {
+		jitter = 0
+	}
+	bc.jitter = jitter
+}
+
+// AddResultHandler registers a function called after each successful check.
+// Handlers run sequentially on the checker goroutine. Must be called before Start.
+func (bc *BackgroundChecker) AddResultHandler(handler ResultHandler) {
+	bc.resultHandlers = append(bc.resultHandlers, handler)
+}
+
 // Start begins the background checking loop
 func (bc *BackgroundChecker) Start() {
 	bc.runningMu.Lock()
@@ -80,7 +134,7 @@ func (bc *BackgroundChecker) Start() {
 	bc.stopChan = make(chan struct{})
 	bc.runningMu.Unlock()
 
-	log.Printf("BACKGROUND_CHECKER: Starting with interval %v", bc.interval)
+	log.Printf("BACKGROUND_CHECKER: Starting with interval %v (jitter up to %v)", bc.interval, bc.jitter)
 
 	// Subscribe to container update events to refresh cache after updates/rollbacks
 	if bc.eventBus != nil {
@@ -89,10 +143,12 @@ func (bc *BackgroundChecker) Start() {
 		go bc.handleContainerUpdates(eventChan)
 	}
 
-	// Run initial check immediately
-	go bc.runCheck()
+	// Run initial check immediately (unless paused)
+	if !bc.IsPaused() {
+		go bc.runCheck()
+	}
 
-	// Start ticker for periodic checks
+	// Start timer loop for periodic checks
 	go bc.checkLoop()
 }
 
@@ -159,21 +215,105 @@ func (bc *BackgroundChecker) Stop() {
 	bc.running = false
 }
 
-// checkLoop runs the periodic check loop
+// checkLoop runs the periodic check loop, waiting interval plus a random jitter
+// between runs. Scheduled runs are skipped while paused.
 func (bc *BackgroundChecker) checkLoop() {
-	ticker := time.NewTicker(bc.interval)
-	defer ticker.Stop()
-
 	for {
+		timer := time.NewTimer(bc.scheduleNext())
+
 		select {
 		case <-bc.stopChan:
+			timer.Stop()
 			return
-		case <-ticker.C:
+		case <-timer.C:
+			if bc.IsPaused() {
+				log.Printf("BACKGROUND_CHECKER: Paused, skipping scheduled check")
+				continue
+			}
 			bc.runCheck()
 		}
 	}
 }
 
+// scheduleNext computes the delay until the next scheduled check and records the run time
+func (bc *BackgroundChecker) scheduleNext() time.Duration {
+	delay := bc.interval
+	if bc.jitter > 0 {
+		delay += rand.N(bc.jitter)
+	}
+
+	bc.scheduleMu.Lock()
+	bc.nextRun = time.Now().Add(delay)
+	bc.scheduleMu.Unlock()
+
+	return delay
+}
+
+// Pause stops scheduled checks until Resume is called. Manual triggers still run.
+// The paused state is persisted so it survives restarts.
+func (bc *BackgroundChecker) Pause() {
+	bc.setPaused(true)
+}
+
+// Resume re-enables scheduled checks
+func (bc *BackgroundChecker) Resume() {
+	bc.setPaused(false)
+}
+
+// IsPaused reports whether scheduled checks are paused
+func (bc *BackgroundChecker) IsPaused() bool {
+	bc.scheduleMu.RLock()
+	defer bc.scheduleMu.RUnlock()
+	return bc.paused
+}
+
+// setPaused updates and persists the paused state
+func (bc *BackgroundChecker) setPaused(paused bool) {
+	bc.scheduleMu.Lock()
+	bc.paused = paused
+	bc.scheduleMu.Unlock()
+
+	log.Printf("BACKGROUND_CHECKER: Scheduled checks paused=%v", paused)
+
+	if bc.storage != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := bc.storage.SetConfig(ctx, CheckerPausedConfigKey, strconv.FormatBool(paused)); err != nil {
+			log.Printf("BACKGROUND_CHECKER: Failed to persist paused state: %v", err)
+		}
+	}
+}
+
+// Status returns the checker's schedule and last/next run times
+func (bc *BackgroundChecker) Status() CheckerStatus {
+	bc.runningMu.Lock()
+	running := bc.running
+	bc.runningMu.Unlock()
+
+	bc.scheduleMu.RLock()
+	paused, nextRun := bc.paused, bc.nextRun
+	bc.scheduleMu.RUnlock()
+
+	bc.cache.mu.RLock()
+	lastRun, checking := bc.cache.lastBackgroundRun, bc.cache.checking
+	bc.cache.mu.RUnlock()
+
+	status := CheckerStatus{
+		Running:  running,
+		Paused:   paused,
+		Checking: checking,
+		Interval: bc.interval.String(),
+		Jitter:   bc.jitter.String(),
+	}
+	if !lastRun.IsZero() {
+		status.LastRun = lastRun.Format(time.RFC3339)
+	}
+	if running && !paused && !nextRun.IsZero() {
+		status.NextRun = nextRun.Format(time.RFC3339)
+	}
+	return status
+}
+
 // updateLastCacheRefreshIfNeeded updates lastCacheRefresh when fresh registry data was fetched
 // Must be called while holding bc.cache.mu lock
 func (bc *BackgroundChecker) updateLastCacheRefreshIfNeeded(now time.Time, cacheRefreshed bool) {
@@ -276,6 +416,13 @@ func (bc *BackgroundChecker) runCheck() {
 		})
 	}
 
+	// Let subscribers (e.g. approvals) act on the fresh results
+	for _, handler := range bc.resultHandlers {
+		handlerCtx, handlerCancel := context.WithTimeout(context.Background(), 30*time.Second)
+		handler(handlerCtx, result)
+		handlerCancel()
+	}
+
 	// Auto-clear old history based on retention policy
 	if bc.storage != nil {
 		clearCtx, clearCancel := context.WithTimeout(context.Background(), 10*time.Second)
@@ -289,6 +436,15 @@ func (bc *BackgroundChecker) runCheck() {
 				}
 			}
 		}
+
+		// Per-table retention for check history and the update log
+		if policy, err := storage.GetRetentionPolicy(clearCtx, bc.storage); err != nil {
+			log.Printf("BACKGROUND_CHECKER: %v", err)
+		} else if policy.IsSet() {
+			if _, err := bc.storage.PruneHistory(clearCtx, policy.PruneOptions(time.Now())); err != nil {
+				log.Printf("BACKGROUND_CHECKER: Failed to prune history: %v", err)
+			}
+		}
 	}
 }
 
@@ -175,6 +175,274 @@ func (m *bgCheckerMockStorage) Close() error {
 	return nil
 }
 
+func (m *bgCheckerMockStorage) QueryUpdateOperations(ctx context.Context, opts storage.OperationQueryOptions) (storage.OperationQueryResult, error) {
+	return storage.OperationQueryResult{}, nil
+}
+
+func (m *bgCheckerMockStorage) DeleteAllHistory(ctx context.Context) (int64, error) {
+	return 0, nil
+}
+
+func (m *bgCheckerMockStorage) DeleteHistoryBefore(ctx context.Context, before time.Time) (int64, error) {
+	return 0, nil
+}
+
+func (m *bgCheckerMockStorage) CreateUser(ctx context.Context, user storage.User) (storage.User, error) {
+	return user, nil
+}
+
+func (m *bgCheckerMockStorage) GetUser(ctx context.Context, username string) (storage.User, bool, error) {
+	return storage.User{}, false, nil
+}
+
+func (m *bgCheckerMockStorage) GetUserByID(ctx context.Context, id int64) (storage.User, bool, error) {
+	return storage.User{}, false, nil
+}
+
+func (m *bgCheckerMockStorage) ListUsers(ctx context.Context) ([]storage.User, error) {
+	return nil, nil
+}
+
+func (m *bgCheckerMockStorage) UpdateUser(ctx context.Context, user storage.User) error {
+	return nil
+}
+
+func (m *bgCheckerMockStorage) DeleteUser(ctx context.Context, username string) error {
+	return nil
+}
+
+func (m *bgCheckerMockStorage) SaveSession(ctx context.Context, session storage.Session) error {
+	return nil
+}
+
+func (m *bgCheckerMockStorage) GetSession(ctx context.Context, tokenHash string) (storage.Session, bool, error) {
+	return storage.Session{}, false, nil
+}
+
+func (m *bgCheckerMockStorage) DeleteSession(ctx context.Context, tokenHash string) error {
+	return nil
+}
+
+func (m *bgCheckerMockStorage) DeleteExpiredSessions(ctx context.Context, now time.Time) (int64, error) {
+	return 0, nil
+}
+
+func (m *bgCheckerMockStorage) SaveApproval(ctx context.Context, approval storage.Approval) error {
+	return nil
+}
+
+func (m *bgCheckerMockStorage) GetApproval(ctx context.Context, id string) (storage.Approval, bool, error) {
+	return storage.Approval{}, false, nil
+}
+
+func (m *bgCheckerMockStorage) GetLatestApproval(ctx context.Context, containerName string) (storage.Approval, bool, error) {
+	return storage.Approval{}, false, nil
+}
+
+func (m *bgCheckerMockStorage) ListApprovals(ctx context.Context, status string, limit int) ([]storage.Approval, error) {
+	return nil, nil
+}
+
+func (m *bgCheckerMockStorage) ExpireApprovals(ctx context.Context, now time.Time) (int64, error) {
+	return 0, nil
+}
+
+func (m *bgCheckerMockStorage) SaveProposal(ctx context.Context, proposal storage.Proposal) error {
+	return nil
+}
+
+func (m *bgCheckerMockStorage) GetProposal(ctx context.Context, id string) (storage.Proposal, bool, error) {
+	return storage.Proposal{}, false, nil
+}
+
+func (m *bgCheckerMockStorage) GetLatestProposal(ctx context.Context, containerName string) (storage.Proposal, bool, error) {
+	return storage.Proposal{}, false, nil
+}
+
+func (m *bgCheckerMockStorage) ListProposals(ctx context.Context, status string, limit int) ([]storage.Proposal, error) {
+	return nil, nil
+}
+
+func (m *bgCheckerMockStorage) GetTagCache(ctx context.Context, imageRef string) (storage.TagCacheEntry, bool, error) {
+	return storage.TagCacheEntry{}, false, nil
+}
+
+func (m *bgCheckerMockStorage) SaveTagCache(ctx context.Context, entry storage.TagCacheEntry) error {
+	return nil
+}
+
+func (m *bgCheckerMockStorage) GetCheckFingerprint(ctx context.Context, containerName string) (storage.CheckFingerprint, bool, error) {
+	return storage.CheckFingerprint{}, false, nil
+}
+
+func (m *bgCheckerMockStorage) SaveCheckFingerprint(ctx context.Context, fingerprint storage.CheckFingerprint) error {
+	return nil
+}
+
+func (m *bgCheckerMockStorage) QueryCheckHistory(ctx context.Context, opts storage.CheckHistoryQueryOptions) ([]storage.CheckHistoryEntry, error) {
+	return nil, nil
+}
+
+func (m *bgCheckerMockStorage) ListRollbackPolicies(ctx context.Context) ([]storage.RollbackPolicy, error) {
+	return nil, nil
+}
+
+func (m *bgCheckerMockStorage) Backup(ctx context.Context, destPath string) error {
+	return nil
+}
+
+func (m *bgCheckerMockStorage) Vacuum(ctx context.Context) (storage.VacuumResult, error) {
+	return storage.VacuumResult{}, nil
+}
+
+func (m *bgCheckerMockStorage) PruneHistory(ctx context.Context, opts storage.PruneOptions) (storage.PruneResult, error) {
+	return storage.PruneResult{}, nil
+}
+
+func (m *bgCheckerMockStorage) ListComposeBackups(ctx context.Context) ([]storage.ComposeBackup, error) {
+	return nil, nil
+}
+
+func (m *bgCheckerMockStorage) DeleteComposeBackups(ctx context.Context, ids []int64) (int64, error) {
+	return 0, nil
+}
+
+func (m *bgCheckerMockStorage) GetUnfinishedOperations(ctx context.Context) ([]storage.UpdateOperation, error) {
+	return nil, nil
+}
+
+func (m *bgCheckerMockStorage) SaveScriptRevision(ctx context.Context, revision storage.ScriptRevision) error {
+	return nil
+}
+
+func (m *bgCheckerMockStorage) GetScriptRevision(ctx context.Context, name string, revision int) (storage.ScriptRevision, bool, error) {
+	return storage.ScriptRevision{}, false, nil
+}
+
+func (m *bgCheckerMockStorage) ListScriptRevisions(ctx context.Context, name string) ([]storage.ScriptRevision, error) {
+	return nil, nil
+}
+
+func (m *bgCheckerMockStorage) SaveVolumeSnapshot(ctx context.Context, snapshot storage.VolumeSnapshot) error {
+	return nil
+}
+
+func (m *bgCheckerMockStorage) GetVolumeSnapshots(ctx context.Context, operationID string) ([]storage.VolumeSnapshot, error) {
+	return nil, nil
+}
+
+func (m *bgCheckerMockStorage) ListVolumeSnapshots(ctx context.Context, volumeName string) ([]storage.VolumeSnapshot, error) {
+	return nil, nil
+}
+
+func (m *bgCheckerMockStorage) DeleteVolumeSnapshot(ctx context.Context, id int64) error {
+	return nil
+}
+
+func (m *bgCheckerMockStorage) GetApprovalPolicy(ctx context.Context, entityType, entityID string) (storage.ApprovalPolicy, bool, error) {
+	return storage.ApprovalPolicy{}, false, nil
+}
+
+func (m *bgCheckerMockStorage) SetApprovalPolicy(ctx context.Context, policy storage.ApprovalPolicy) error {
+	return nil
+}
+
+func (m *bgCheckerMockStorage) ListApprovalPolicies(ctx context.Context) ([]storage.ApprovalPolicy, error) {
+	return nil, nil
+}
+
+func (m *bgCheckerMockStorage) DeleteApprovalPolicy(ctx context.Context, entityType, entityID string) error {
+	return nil
+}
+
+func (m *bgCheckerMockStorage) AppendOperationLog(ctx context.Context, entry storage.OperationLogEntry) (int64, error) {
+	return 0, nil
+}
+
+func (m *bgCheckerMockStorage) GetOperationLogs(ctx context.Context, operationID string, afterID int64) ([]storage.OperationLogEntry, error) {
+	return nil, nil
+}
+
+func (m *bgCheckerMockStorage) AppendComposeOutput(ctx context.Context, operationID string, output string) error {
+	return nil
+}
+
+func (m *bgCheckerMockStorage) ListIgnoreRules(ctx context.Context) ([]storage.IgnoreRule, error) {
+	return nil, nil
+}
+
+func (m *bgCheckerMockStorage) SaveIgnoreRule(ctx context.Context, rule storage.IgnoreRule) (storage.IgnoreRule, error) {
+	return rule, nil
+}
+
+func (m *bgCheckerMockStorage) DeleteIgnoreRule(ctx context.Context, id int64) (bool, error) {
+	return false, nil
+}
+
+func (m *bgCheckerMockStorage) GetStackEnv(ctx context.Context, stack string) (storage.StackEnv, bool, error) {
+	return storage.StackEnv{}, false, nil
+}
+
+func (m *bgCheckerMockStorage) SetStackEnv(ctx context.Context, env storage.StackEnv) error {
+	return nil
+}
+
+func (m *bgCheckerMockStorage) ListStackEnvs(ctx context.Context) ([]storage.StackEnv, error) {
+	return nil, nil
+}
+
+func (m *bgCheckerMockStorage) DeleteStackEnv(ctx context.Context, stack string) (bool, error) {
+	return false, nil
+}
+
+func (m *bgCheckerMockStorage) ListOwnerships(ctx context.Context) ([]storage.Ownership, error) {
+	return nil, nil
+}
+
+func (m *bgCheckerMockStorage) AddOwnership(ctx context.Context, ownership storage.Ownership) error {
+	return nil
+}
+
+func (m *bgCheckerMockStorage) RemoveOwnership(ctx context.Context, entityType, entityID, owner string) (bool, error) {
+	return false, nil
+}
+
+func (m *bgCheckerMockStorage) ListTeamMembers(ctx context.Context) ([]storage.TeamMember, error) {
+	return nil, nil
+}
+
+func (m *bgCheckerMockStorage) AddTeamMember(ctx context.Context, team, username string) error {
+	return nil
+}
+
+func (m *bgCheckerMockStorage) RemoveTeamMember(ctx context.Context, team, username string) (bool, error) {
+	return false, nil
+}
+
+func (m *bgCheckerMockStorage) CheckWritable(ctx context.Context) error {
+	return nil
+}
+
+func (m *bgCheckerMockStorage) SaveStackLock(ctx context.Context, lock storage.StackLock) error {
+	return nil
+}
+
+func (m *bgCheckerMockStorage) DeleteStackLock(ctx context.Context, stackName, operationID string) error {
+	return nil
+}
+
+func (m *bgCheckerMockStorage) ListStackLocks(ctx context.Context) ([]storage.StackLock, error) {
+	return nil, nil
+}
+
+func (m *bgCheckerMockStorage) SetQueuePriorities(ctx context.Context, priorities map[string]int) error {
+	return nil
+}
+
+func (m *bgCheckerMockStorage) DeleteQueuedUpdate(ctx context.Context, operationID string) (bool, error) {
+	return false, nil
+}
+
 // ============================================================================
 // BackgroundChecker Tests
 // ============================================================================
@@ -579,3 +847,56 @@ func TestLastCacheRefreshUpdatesWithStaggeredEntries(t *testing.T) {
 		assert.WithinDuration(t, now, lastRefresh, time.Second)
 	})
 }
+
+func TestBackgroundChecker_PauseResume(t *testing.T) {
+	t.Run("pause state is persisted and reloaded", func(t *testing.T) {
+		mockStorage := newBGCheckerMockStorage()
+		bc := NewBackgroundChecker(nil, nil, nil, mockStorage, time.Hour)
+		assert.False(t, bc.IsPaused())
+
+		bc.Pause()
+		assert.True(t, bc.IsPaused())
+		assert.Equal(t, "true", mockStorage.configs[CheckerPausedConfigKey])
+
+		// A new checker (e.g. after restart) stays paused
+		reloaded := NewBackgroundChecker(nil, nil, nil, mockStorage, time.Hour)
+		assert.True(t, reloaded.IsPaused())
+
+		reloaded.Resume()
+		assert.False(t, reloaded.IsPaused())
+		assert.Equal(t, "false", mockStorage.configs[CheckerPausedConfigKey])
+	})
+
+	t.Run("status hides next run while paused", func(t *testing.T) {
+		bc := NewBackgroundChecker(nil, nil, nil, nil, time.Hour)
+		bc.runningMu.Lock()
+		bc.running = true
+		bc.runningMu.Unlock()
+		bc.scheduleNext()
+
+		status := bc.Status()
+		assert.True(t, status.Running)
+		assert.NotEmpty(t, status.NextRun)
+		assert.Equal(t, "1h0m0s", status.Interval)
+
+		bc.Pause()
+		status = bc.Status()
+		assert.True(t, status.Paused)
+		assert.Empty(t, status.NextRun)
+	})
+}
+
+func TestBackgroundChecker_Jitter(t *testing.T) {
+	bc := NewBackgroundChecker(nil, nil, nil, nil, time.Minute)
+	assert.Equal(t, time.Minute, bc.scheduleNext(), "no jitter by default")
+
+	bc.SetJitter(10 * time.Second)
+	for i := 0; i < 50; i++ {
+		delay := bc.scheduleNext()
+		assert.GreaterOrEqual(t, delay, time.Minute)
+		assert.Less(t, delay, time.Minute+10*time.Second)
+	}
+
+	bc.SetJitter(-time.Second)
+	assert.Equal(t, time.Duration(0), bc.jitter)
+}
@@ -0,0 +1,191 @@
+package update
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/chis/docksmith/internal/compose"
+	"github.com/chis/docksmith/internal/docker"
+	"github.com/chis/docksmith/internal/scripts"
+)
+
+// baseImageFromDockerfile as the docksmith.base-image value reads the base image from the Dockerfile.
+const baseImageFromDockerfile = "dockerfile"
+
+// tracksBaseImage reports whether a locally built container is checked through its base image.
+func tracksBaseImage(container *docker.Container) bool {
+	return strings.TrimSpace(container.Labels[scripts.BaseImageLabel]) != ""
+}
+
+// rebuildsOnBaseUpdate reports whether updating a container rebuilds it on a newer base image.
+func rebuildsOnBaseUpdate(container *docker.Container) bool {
+	if !tracksBaseImage(container) {
+		return false
+	}
+	rebuild, _ := parseLabelBool(container.Labels[scripts.RebuildOnBaseUpdateLabel])
+	return rebuild
+}
+
+// serviceDockerfile returns the Dockerfile the compose service of a container is built from.
+func serviceDockerfile(container *docker.Container, composeFilePath string) (string, error) {
+	serviceName := container.Labels["com.docker.compose.service"]
+	if serviceName == "" {
+		serviceName = container.Name
+	}
+	composeFile, err := compose.LoadComposeFileOrIncluded(composeFilePath, serviceName)
+	if err != nil {
+		return "", fmt.Errorf("failed to load compose file: %w", err)
+	}
+	service, err := composeFile.FindServiceByContainerName(serviceName)
+	if err != nil {
+		return "", fmt.Errorf("failed to find service %s: %w", serviceName, err)
+	}
+	dockerfile, ok := composeFile.ServiceBuild(service)
+	if !ok {
+		return "", fmt.Errorf("service %s has no build section", serviceName)
+	}
+	return dockerfile, nil
+}
+
+// baseImage returns the base image tracked for a container, from its docksmith.base-image
+// label or the Dockerfile of its compose service.
+func baseImage(container *docker.Container, composeFilePath string) (string, error) {
+	value := strings.TrimSpace(container.Labels[scripts.BaseImageLabel])
+	if !strings.EqualFold(value, baseImageFromDockerfile) {
+		return value, nil
+	}
+	if composeFilePath == "" {
+		return "", fmt.Errorf("container is not managed by docker compose")
+	}
+	dockerfile, err := serviceDockerfile(container, composeFilePath)
+	if err != nil {
+		return "", err
+	}
+	return compose.ReadBaseImage(dockerfile)
+}
+
+// checkBaseImage checks the base image of a locally built container in place of its
+// image. Updates of the base image are only actionable when the container rebuilds
+// on base image updates; otherwise they are reported in the note of a LOCAL_IMAGE result.
+func (c *Checker) checkBaseImage(ctx context.Context, container docker.Container, update ContainerUpdate) ContainerUpdate {
+	composeFilePath := ""
+	if files := container.Labels["com.docker.compose.project.config_files"]; files != "" {
+		composeFilePath = strings.TrimSpace(strings.Split(files, ",")[0])
+	}
+	base, err := baseImage(&container, composeFilePath)
+	if err != nil {
+		log.Printf("checkContainer %s: Cannot determine base image: %v", container.Name, err)
+		update.Note = fmt.Sprintf("Base image unknown: %v", err)
+		return update
+	}
+	log.Printf("checkContainer %s: Checking base image %s", container.Name, base)
+
+	// Check the base image as if the container ran it, keeping the docksmith labels
+	// (version constraints, tag pattern, pre-update check) that apply to it
+	baseContainer := docker.Container{
+		ID:     container.ID,
+		Name:   container.Name,
+		Image:  base,
+		State:  container.State,
+		Labels: make(map[string]string),
+	}
+	for key, value := range container.Labels {
+		if strings.HasPrefix(key, "docksmith.") && key != scripts.BaseImageLabel {
+			baseContainer.Labels[key] = value
+		}
+	}
+
+	result := c.checkContainerStatus(ctx, baseContainer)
+	result.ContainerName = container.Name
+	result.Image = container.Image
+	result.HealthStatus = container.HealthStatus
+	result.IsLocal = true
+	result.BaseImage = base
+
+	if result.Status == LocalImage {
+		result.Note = fmt.Sprintf("Base image %s is also built locally", base)
+		return result
+	}
+	if !rebuildsOnBaseUpdate(&container) {
+		switch result.Status {
+		case UpdateAvailable, UpdateAvailableBlocked:
+			result.Note = fmt.Sprintf("Base image %s has an update (%s); set %s=true to rebuild",
+				base, result.LatestVersion, scripts.RebuildOnBaseUpdateLabel)
+			result.Status = LocalImage
+		case UpToDatePinnable:
+			result.Status = LocalImage
+		}
+	}
+	return result
+}
+
+// rebuildFromBaseImage rebuilds a locally built service on a newer base image. When
+// targetVersion is a new tag of the base image, the FROM instruction of the service's
+// Dockerfile is changed to it first, and restored if the build fails. The image is
+// rebuilt with docker compose build --pull, so unchanged tags pick up new digests.
+func (o *UpdateOrchestrator) rebuildFromBaseImage(ctx context.Context, operationID string, container *docker.Container, targetVersion, stackName string) (buildErr error) {
+	composeFilePath := o.getComposeFilePath(container)
+	if composeFilePath == "" {
+		return fmt.Errorf("container %s is not managed by docker compose", container.Name)
+	}
+	resolvedPath, err := o.resolveComposeFile(composeFilePath)
+	if err != nil {
+		return fmt.Errorf("failed to resolve compose file: %w", err)
+	}
+
+	base, err := baseImage(container, resolvedPath)
+	if err != nil {
+		return fmt.Errorf("failed to determine base image: %w", err)
+	}
+
+	if _, baseTag := splitImageRef(base); targetVersion != "" && targetVersion != baseTag {
+		o.publishProgress(operationID, container.Name, stackName, "updating_compose", 20, "Updating base image in Dockerfile")
+
+		dockerfile, original, err := setBaseImage(container, resolvedPath, base, replaceImageTag(base, targetVersion))
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if buildErr != nil {
+				if restoreErr := os.WriteFile(dockerfile, original, 0644); restoreErr != nil {
+					log.Printf("UPDATE: Failed to restore %s: %v", dockerfile, restoreErr)
+				}
+			}
+		}()
+	}
+
+	o.publishProgress(operationID, container.Name, stackName, "building_image", 30, fmt.Sprintf("Rebuilding %s", container.Name))
+	recreator := compose.NewRecreator(o.dockerClient)
+	return o.withUpdateSlot(ctx, func() error {
+		return recreator.BuildWithCompose(ctx, container, o.getComposeFilePathForHost(container), composeFilePath)
+	})
+}
+
+// setBaseImage changes the base image in the Dockerfile of a container's compose
+// service from base to newBase. Returns the Dockerfile and its previous content.
+func setBaseImage(container *docker.Container, composeFilePath, base, newBase string) (string, []byte, error) {
+	dockerfile, err := serviceDockerfile(container, composeFilePath)
+	if err != nil {
+		return "", nil, err
+	}
+	current, err := compose.ReadBaseImage(dockerfile)
+	if err != nil {
+		return "", nil, err
+	}
+	if current != base {
+		return "", nil, fmt.Errorf("cannot change base image to %s: the Dockerfile is built from %s, not %s", newBase, current, base)
+	}
+	original, err := os.ReadFile(dockerfile)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to read Dockerfile: %w", err)
+	}
+
+	if err := compose.SetBaseImage(dockerfile, newBase); err != nil {
+		return "", nil, err
+	}
+	log.Printf("UPDATE: Changed base image of %s from %s to %s", container.Name, base, newBase)
+	return dockerfile, original, nil
+}
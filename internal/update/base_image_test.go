@@ -0,0 +1,131 @@
+package update
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/chis/docksmith/internal/docker"
+	"github.com/chis/docksmith/internal/scripts"
+)
+
+// newBaseImageTestChecker returns a checker for a locally built myapp:latest container
+// with labels, whose node base image has newer tags
+func newBaseImageTestChecker(labels map[string]string) *Checker {
+	mockDocker := &mockDockerClient{
+		containers: []docker.Container{
+			{ID: "app-container", Name: "app", Image: "myapp:latest", Labels: labels},
+		},
+		imageDigests:  map[string]string{},
+		imageVersions: map[string]string{},
+		localImages:   map[string]bool{"myapp:latest": true},
+	}
+	mockRegistry := &mockRegistryClient{
+		tags: map[string][]string{
+			"docker.io/library/node": {"22.1.0", "20.12.0", "20.11.0"},
+		},
+		tagDigests:     map[string]string{},
+		digestMappings: map[string]map[string][]string{},
+	}
+	return NewChecker(mockDocker, mockRegistry, nil)
+}
+
+func checkBaseImageContainer(t *testing.T, labels map[string]string) ContainerUpdate {
+	t.Helper()
+	result, err := newBaseImageTestChecker(labels).CheckForUpdates(context.Background())
+	if err != nil {
+		t.Fatalf("CheckForUpdates failed: %v", err)
+	}
+	return result.Updates[0]
+}
+
+func TestCheckerSkipsLocalImageWithoutBaseImage(t *testing.T) {
+	update := checkBaseImageContainer(t, nil)
+	if update.Status != LocalImage || update.BaseImage != "" {
+		t.Errorf("Expected untracked LOCAL_IMAGE, got %s (base %q)", update.Status, update.BaseImage)
+	}
+}
+
+func TestCheckerReportsBaseImageUpdate(t *testing.T) {
+	update := checkBaseImageContainer(t, map[string]string{
+		scripts.BaseImageLabel:       "node:20.11.0",
+		scripts.VersionPinMajorLabel: "true",
+	})
+
+	if update.Status != LocalImage {
+		t.Fatalf("Expected LOCAL_IMAGE without rebuilds, got %s", update.Status)
+	}
+	if update.BaseImage != "node:20.11.0" || update.Image != "myapp:latest" || !update.IsLocal {
+		t.Errorf("Unexpected result: base %q, image %q, local %v", update.BaseImage, update.Image, update.IsLocal)
+	}
+	if update.LatestVersion != "20.12.0" {
+		t.Errorf("Expected pinned base update 20.12.0, got %s", update.LatestVersion)
+	}
+	if !strings.Contains(update.Note, "has an update (20.12.0)") {
+		t.Errorf("Expected a note about the base image update, got %q", update.Note)
+	}
+}
+
+func TestCheckerOffersRebuildOnBaseImageUpdate(t *testing.T) {
+	update := checkBaseImageContainer(t, map[string]string{
+		scripts.BaseImageLabel:           "node:20.11.0",
+		scripts.RebuildOnBaseUpdateLabel: "true",
+	})
+
+	if update.Status != UpdateAvailable {
+		t.Fatalf("Expected UPDATE_AVAILABLE, got %s", update.Status)
+	}
+	if update.LatestVersion != "22.1.0" {
+		t.Errorf("Expected base update 22.1.0, got %s", update.LatestVersion)
+	}
+}
+
+func TestCheckerReadsBaseImageFromDockerfile(t *testing.T) {
+	dir := t.TempDir()
+	composePath := filepath.Join(dir, "docker-compose.yml")
+	writeFile := func(path, content string) {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeFile(composePath, "services:\n  app:\n    build:\n      context: ./app\n      dockerfile: Dockerfile.prod\n    image: myapp:latest\n")
+	writeFile(filepath.Join(dir, "app", "Dockerfile.prod"), "ARG NODE=20.11.0\nFROM node:${NODE} AS build\nRUN npm ci\n\nFROM build\nCMD [\"node\", \"server.js\"]\n")
+
+	labels := map[string]string{
+		scripts.BaseImageLabel:                    "dockerfile",
+		scripts.RebuildOnBaseUpdateLabel:          "true",
+		"com.docker.compose.service":              "app",
+		"com.docker.compose.project.config_files": composePath,
+	}
+	update := checkBaseImageContainer(t, labels)
+	if update.BaseImage != "node:20.11.0" {
+		t.Fatalf("Expected base image node:20.11.0 from the Dockerfile, got %q (note %q)", update.BaseImage, update.Note)
+	}
+	if update.Status != UpdateAvailable {
+		t.Errorf("Expected UPDATE_AVAILABLE, got %s", update.Status)
+	}
+
+	// A FROM set by a build argument cannot be changed to a new tag
+	container := &docker.Container{Name: "app", Labels: labels}
+	if _, _, err := setBaseImage(container, composePath, "node:20.11.0", "node:22.1.0"); err == nil || !strings.Contains(err.Error(), "build argument") {
+		t.Errorf("Expected build argument error, got %v", err)
+	}
+
+	writeFile(filepath.Join(dir, "app", "Dockerfile.prod"), "FROM node:20.11.0 AS build\nRUN npm ci\n\nFROM build\n")
+	dockerfile, original, err := setBaseImage(container, composePath, "node:20.11.0", "node:22.1.0")
+	if err != nil {
+		t.Fatalf("setBaseImage failed: %v", err)
+	}
+	data, _ := os.ReadFile(dockerfile)
+	if string(data) != "FROM node:22.1.0 AS build\nRUN npm ci\n\nFROM build\n" {
+		t.Errorf("Unexpected Dockerfile:\n%s", data)
+	}
+	if !strings.HasPrefix(string(original), "FROM node:20.11.0") {
+		t.Errorf("Expected the previous content, got:\n%s", original)
+	}
+}
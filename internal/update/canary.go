@@ -0,0 +1,175 @@
+package update
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/chis/docksmith/internal/compose"
+	"github.com/chis/docksmith/internal/docker"
+	"github.com/chis/docksmith/internal/scripts"
+)
+
+// usesCanary reports whether a container's service is updated with the canary
+// strategy. Canary updates only apply to compose services with more than one replica.
+func usesCanary(container *docker.Container, replicas []*docker.Container) bool {
+	return strings.EqualFold(strings.TrimSpace(container.Labels[scripts.UpdateStrategyLabel]), "canary") && len(replicas) > 1
+}
+
+// findServiceReplicas returns the containers of the same compose project and
+// service as container, sorted by name. One-off containers (docker compose run)
+// are not replicas.
+func findServiceReplicas(containers []docker.Container, container *docker.Container) []*docker.Container {
+	project := container.Labels["com.docker.compose.project"]
+	service := container.Labels["com.docker.compose.service"]
+	if project == "" || service == "" {
+		return nil
+	}
+
+	var replicas []*docker.Container
+	for i := range containers {
+		c := &containers[i]
+		if c.Labels["com.docker.compose.project"] != project || c.Labels["com.docker.compose.service"] != service {
+			continue
+		}
+		if strings.EqualFold(c.Labels["com.docker.compose.oneoff"], "true") {
+			continue
+		}
+		replicas = append(replicas, c)
+	}
+	sort.Slice(replicas, func(i, j int) bool { return replicas[i].Name < replicas[j].Name })
+	return replicas
+}
+
+// serviceReplicas lists the current replicas of a container's compose service.
+func (o *UpdateOrchestrator) serviceReplicas(ctx context.Context, container *docker.Container) ([]*docker.Container, error) {
+	containers, err := o.dockerClient.ListContainers(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list containers: %w", err)
+	}
+	return findServiceReplicas(containers, container), nil
+}
+
+// canaryUpdate updates a scaled service one replica first. The compose file must
+// already point at the new image. The canary replica is recreated and has to pass
+// its health check and post-update check before the remaining replicas are rolled.
+// If the canary fails, the compose file is reverted to oldTag and the canary is
+// recreated on the old image.
+func (o *UpdateOrchestrator) canaryUpdate(ctx context.Context, operationID string, container *docker.Container, replicas []*docker.Container, stackName, oldTag string) error {
+	hostComposePath := o.getComposeFilePathForHost(container)
+	containerComposePath := o.getComposeFilePath(container)
+	if hostComposePath == "" || containerComposePath == "" {
+		return fmt.Errorf("canary updates require a compose file")
+	}
+
+	recreator := compose.NewRecreator(o.dockerClient)
+	count := len(replicas)
+
+	oldIDs := make(map[string]bool, count)
+	for _, r := range replicas {
+		oldIDs[r.ID] = true
+	}
+
+	log.Printf("CANARY: Updating %s as canary for %d replicas (operation=%s)", container.Name, count, operationID)
+	o.publishProgress(operationID, container.Name, stackName, "recreating", 60,
+		fmt.Sprintf("Updating canary replica (1 of %d)", count))
+
+	err := o.withUpdateSlot(ctx, func() error {
+		return recreator.ReplaceReplica(ctx, container, hostComposePath, containerComposePath, count)
+	})
+	if err != nil {
+		o.rollbackCanary(ctx, operationID, container, nil, count, stackName, oldTag)
+		return fmt.Errorf("canary recreation failed: %w", err)
+	}
+
+	current, err := o.serviceReplicas(ctx, container)
+	if err != nil {
+		return err
+	}
+	var canary *docker.Container
+	for _, r := range current {
+		if !oldIDs[r.ID] {
+			canary = r
+			break
+		}
+	}
+	if canary == nil {
+		o.rollbackCanary(ctx, operationID, container, nil, count, stackName, oldTag)
+		return fmt.Errorf("canary replica for %s not found after recreation", container.Name)
+	}
+
+	o.publishProgress(operationID, canary.Name, stackName, "health_check", 70,
+		fmt.Sprintf("Checking health of canary %s", canary.Name))
+
+	if err := o.waitForHealthy(ctx, canary.Name, o.healthCheckCfg.Timeout); err != nil {
+		o.rollbackCanary(ctx, operationID, container, canary, count, stackName, oldTag)
+		return fmt.Errorf("canary %s health check failed: %w", canary.Name, err)
+	}
+	if err := o.runPostUpdateCheck(ctx, operationID, canary); err != nil {
+		o.rollbackCanary(ctx, operationID, container, canary, count, stackName, oldTag)
+		return fmt.Errorf("canary %s post-update check failed: %w", canary.Name, err)
+	}
+
+	log.Printf("CANARY: Canary %s passed, updating remaining %d replicas", canary.Name, count-1)
+	o.publishProgress(operationID, container.Name, stackName, "recreating", 75,
+		fmt.Sprintf("Canary healthy, updating remaining %d replicas", count-1))
+
+	err = o.withUpdateSlot(ctx, func() error {
+		return recreator.ScaleWithCompose(ctx, container, hostComposePath, containerComposePath, count, false)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update remaining replicas: %w", err)
+	}
+
+	current, err = o.serviceReplicas(ctx, container)
+	if err != nil {
+		return err
+	}
+	for _, r := range current {
+		if r.ID == canary.ID {
+			continue
+		}
+		o.publishProgress(operationID, r.Name, stackName, "health_check", 85,
+			fmt.Sprintf("Checking health of %s", r.Name))
+		if err := o.waitForHealthy(ctx, r.Name, o.healthCheckCfg.Timeout); err != nil {
+			return fmt.Errorf("replica %s health check failed: %w", r.Name, err)
+		}
+	}
+
+	log.Printf("CANARY: All %d replicas of %s updated", count, container.Name)
+	return nil
+}
+
+// rollbackCanary reverts the compose file to oldTag and recreates the canary
+// replica (if one was created) on the old image, restoring the original replica count.
+func (o *UpdateOrchestrator) rollbackCanary(ctx context.Context, operationID string, container, canary *docker.Container, replicas int, stackName, oldTag string) {
+	log.Printf("CANARY: Rolling back canary for %s to %s", container.Name, oldTag)
+	o.publishProgress(operationID, container.Name, stackName, "rolling_back", 90, "Rolling back canary replica")
+
+	if oldTag != "" {
+		o.revertComposeTag(ctx, container, oldTag, "canary failure")
+	}
+
+	recreator := compose.NewRecreator(o.dockerClient)
+	hostComposePath := o.getComposeFilePathForHost(container)
+	containerComposePath := o.getComposeFilePath(container)
+
+	err := o.withUpdateSlot(ctx, func() error {
+		if canary != nil {
+			return recreator.ReplaceReplica(ctx, canary, hostComposePath, containerComposePath, replicas)
+		}
+		return recreator.ScaleWithCompose(ctx, container, hostComposePath, containerComposePath, replicas, true)
+	})
+	if err != nil {
+		log.Printf("CANARY: Failed to roll back canary for %s: %v", container.Name, err)
+		return
+	}
+
+	if op, found, _ := o.storage.GetUpdateOperation(ctx, operationID); found {
+		op.RollbackOccurred = true
+		o.storage.SaveUpdateOperation(ctx, op)
+	}
+	log.Printf("CANARY: Canary for %s rolled back to %s", container.Name, oldTag)
+}
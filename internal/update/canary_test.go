@@ -0,0 +1,50 @@
+package update
+
+import (
+	"testing"
+
+	"github.com/chis/docksmith/internal/docker"
+	"github.com/chis/docksmith/internal/scripts"
+	"github.com/stretchr/testify/assert"
+)
+
+func replica(name, project, service string, extra map[string]string) docker.Container {
+	labels := map[string]string{
+		"com.docker.compose.project": project,
+		"com.docker.compose.service": service,
+	}
+	for k, v := range extra {
+		labels[k] = v
+	}
+	return docker.Container{ID: name + "-id", Name: name, Labels: labels}
+}
+
+func TestFindServiceReplicas(t *testing.T) {
+	containers := []docker.Container{
+		replica("web-web-2", "web", "web", nil),
+		replica("web-web-1", "web", "web", nil),
+		replica("web-db-1", "web", "db", nil),
+		replica("other-web-1", "other", "web", nil),
+		replica("web-web-run-abc", "web", "web", map[string]string{"com.docker.compose.oneoff": "True"}),
+	}
+
+	replicas := findServiceReplicas(containers, &containers[0])
+	var names []string
+	for _, r := range replicas {
+		names = append(names, r.Name)
+	}
+	assert.Equal(t, []string{"web-web-1", "web-web-2"}, names)
+
+	standalone := docker.Container{Name: "standalone"}
+	assert.Empty(t, findServiceReplicas(containers, &standalone))
+}
+
+func TestUsesCanary(t *testing.T) {
+	canary := replica("web-web-1", "web", "web", map[string]string{scripts.UpdateStrategyLabel: "canary"})
+	plain := replica("web-web-1", "web", "web", nil)
+	second := replica("web-web-2", "web", "web", nil)
+
+	assert.True(t, usesCanary(&canary, []*docker.Container{&canary, &second}))
+	assert.False(t, usesCanary(&canary, []*docker.Container{&canary}), "a single replica has nothing to canary")
+	assert.False(t, usesCanary(&plain, []*docker.Container{&plain, &second}), "canary is opt-in")
+}
@@ -0,0 +1,80 @@
+package update
+
+import (
+	"sort"
+
+	"github.com/chis/docksmith/internal/scripts"
+	"github.com/chis/docksmith/internal/version"
+)
+
+// CandidateTags returns the tags a checked container can be updated to, newest
+// first: its available tags that are newer than the current version and pass the
+// same filters as the latest version (suffix, tag channel, prereleases, and the
+// version constraint labels). LatestVersion is the first tag unless the update was
+// found by digest.
+func CandidateTags(c ContainerInfo) []string {
+	if len(c.AvailableTags) == 0 {
+		return nil
+	}
+	checker := NewChecker(nil, nil, nil)
+	parser := tagParserFor(checker, c)
+	currentVer := checker.versionParser.ParseTag(c.CurrentVersion)
+
+	candidates := checker.candidateTags(parser, c.AvailableTags, c.CurrentSuffix, currentVer, c.Labels, c.CurrentTag)
+	if currentVer == nil {
+		return candidates
+	}
+	newer := candidates[:0]
+	for _, tag := range candidates {
+		if v := parser.ParseTag(tag); v != nil && checker.versionComp.IsNewer(currentVer, v) {
+			newer = append(newer, tag)
+		}
+	}
+	return newer
+}
+
+// SortedTags returns all available tags of a checked container without filtering:
+// versioned tags newest first, then the other tags in alphabetical order.
+func SortedTags(c ContainerInfo) []string {
+	checker := NewChecker(nil, nil, nil)
+	parser := tagParserFor(checker, c)
+
+	type parsedTag struct {
+		tag     string
+		version *version.Version
+	}
+	tags := make([]parsedTag, 0, len(c.AvailableTags))
+	for _, tag := range c.AvailableTags {
+		var v *version.Version
+		if info := parser.ParseImageTag("dummy:" + tag); info != nil && info.IsVersioned {
+			v = info.Version
+		}
+		tags = append(tags, parsedTag{tag, v})
+	}
+	sort.SliceStable(tags, func(i, j int) bool {
+		a, b := tags[i], tags[j]
+		switch {
+		case a.version != nil && b.version != nil:
+			if cmp := checker.versionComp.Compare(a.version, b.version); cmp != 0 {
+				return cmp > 0
+			}
+			return a.tag < b.tag
+		case a.version != nil || b.version != nil:
+			return a.version != nil
+		default:
+			return a.tag < b.tag
+		}
+	})
+
+	sorted := make([]string, 0, len(tags))
+	for _, t := range tags {
+		sorted = append(sorted, t.tag)
+	}
+	return sorted
+}
+
+// tagParserFor returns a tag parser applying the container image's tag pattern.
+func tagParserFor(checker *Checker, c ContainerInfo) *version.Parser {
+	imgInfo, _ := checker.extractor.ExtractFromImageWithPattern(c.Image, c.Labels[scripts.TagPatternLabel])
+	return version.NewParserWithPattern(imgInfo.TagPattern)
+}
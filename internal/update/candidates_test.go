@@ -0,0 +1,53 @@
+package update
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/chis/docksmith/internal/scripts"
+	"github.com/chis/docksmith/internal/version"
+)
+
+func candidateContainer(current, suffix string, tags []string, labels map[string]string) ContainerInfo {
+	c := ContainerInfo{Labels: labels}
+	c.Image = "ghcr.io/example/app:" + current
+	c.CurrentTag = current
+	c.CurrentVersion = current
+	c.CurrentSuffix = suffix
+	c.AvailableTags = tags
+	return c
+}
+
+func TestCandidateTags(t *testing.T) {
+	tags := []string{"1.9.0", "1.10.0", "1.10.0-alpine", "2.0.0", "2.1.0-rc1", "1.8.0", "latest"}
+
+	c := candidateContainer("1.9.0", "", tags, nil)
+	assert.Equal(t, []string{"2.0.0", "1.10.0"}, CandidateTags(c), "newer stable tags with the same suffix")
+
+	c = candidateContainer("1.9.0", "", tags, map[string]string{scripts.VersionPinMajorLabel: "true"})
+	assert.Equal(t, []string{"1.10.0"}, CandidateTags(c))
+
+	c = candidateContainer("1.9.0-alpine", "alpine", tags, nil)
+	assert.Equal(t, []string{"1.10.0-alpine"}, CandidateTags(c))
+
+	assert.Empty(t, CandidateTags(candidateContainer("2.0.0", "", tags, nil)))
+}
+
+func TestSortedTags(t *testing.T) {
+	c := candidateContainer("1.9.0", "", []string{"latest", "1.9.0", "edge", "1.10.0", "2.1.0-rc1"}, nil)
+	assert.Equal(t, []string{"2.1.0-rc1", "1.10.0", "1.9.0", "edge", "latest"}, SortedTags(c))
+}
+
+func TestEnoughTags(t *testing.T) {
+	checker := NewChecker(nil, nil, nil)
+	parser := version.NewParser()
+	current := parser.ParseTag("16.1")
+	enough := checker.enoughTags(parser, "16.1", "", current)
+
+	newer := []string{"17.0", "16.9", "16.8", "16.7", "16.6", "16.5", "16.4", "16.3", "16.2"}
+	assert.False(t, enough(newer), "the running tag has not been listed")
+	assert.False(t, enough(append(newer, "16.1")), "nine newer releases are not enough")
+	assert.False(t, enough(append(newer, "17.1-alpine", "18.0-rc1", "16.1")), "other suffixes and prereleases do not count")
+	assert.True(t, enough(append(newer, "17.1", "16.1")))
+}
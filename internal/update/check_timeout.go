@@ -0,0 +1,82 @@
+package update
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/chis/docksmith/internal/docker"
+	"github.com/chis/docksmith/internal/scripts"
+)
+
+// defaultCheckTimeout is how long the check of one container may take.
+const defaultCheckTimeout = 20 * time.Second
+
+// errCheckTimedOut is the cause of a check context whose time ran out.
+var errCheckTimedOut = errors.New("check timed out")
+
+// SetCheckTimeout sets how long the check of one container may take before it is
+// reported as failed, so a slow registry cannot hold up the other checks. Zero
+// disables the limit. The docksmith.check-timeout label overrides it per container.
+func (o *Orchestrator) SetCheckTimeout(timeout time.Duration) {
+	o.checker.checkTimeout = timeout
+}
+
+// CheckTimeoutFromEnv reads the per-container check timeout from CHECK_TIMEOUT.
+// Returns the 20s default when unset or invalid; "0" disables the limit.
+func CheckTimeoutFromEnv() time.Duration {
+	value := os.Getenv("CHECK_TIMEOUT")
+	if value == "" {
+		return defaultCheckTimeout
+	}
+	timeout, err := time.ParseDuration(value)
+	if value == "0" {
+		timeout, err = 0, nil
+	}
+	if err != nil || timeout < 0 {
+		log.Printf("Warning: Invalid CHECK_TIMEOUT '%s', using default %v", value, defaultCheckTimeout)
+		return defaultCheckTimeout
+	}
+	log.Printf("Using CHECK_TIMEOUT: %v", timeout)
+	return timeout
+}
+
+// checkTimeoutFor returns how long the check of a container may take. The
+// docksmith.check-timeout label overrides the configured timeout.
+func (c *Checker) checkTimeoutFor(container docker.Container) time.Duration {
+	value := strings.TrimSpace(container.Labels[scripts.CheckTimeoutLabel])
+	if value == "" {
+		return c.checkTimeout
+	}
+	if value == "0" {
+		return 0
+	}
+	timeout, err := time.ParseDuration(value)
+	if err != nil || timeout < 0 {
+		log.Printf("checkContainer %s: Invalid %s %q, using %v", container.Name, scripts.CheckTimeoutLabel, value, c.checkTimeout)
+		return c.checkTimeout
+	}
+	return timeout
+}
+
+// timedOut marks the failed check of a container whose time ran out. A result
+// found before the deadline is kept as is.
+func timedOut(update ContainerUpdate, timeout time.Duration) ContainerUpdate {
+	switch update.Status {
+	case MetadataUnavailable, CheckFailed, Unknown, "":
+	default:
+		return update
+	}
+	log.Printf("checkContainer %s: Check timed out after %v", update.ContainerName, timeout)
+	reason := fmt.Sprintf("Check timed out after %v", timeout)
+	if update.Error != "" {
+		reason += ": " + update.Error
+	}
+	update.Status = MetadataUnavailable
+	update.Error = reason
+	update.TimedOut = true
+	return update
+}
@@ -0,0 +1,76 @@
+package update
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/chis/docksmith/internal/docker"
+)
+
+// slowRegistry hangs on tag listings of slowRepo until the request is canceled.
+type slowRegistry struct {
+	*mockRegistryClient
+	slowRepo string
+}
+
+func (r *slowRegistry) ListTags(ctx context.Context, imageRef string) ([]string, error) {
+	if imageRef == r.slowRepo {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	return r.mockRegistryClient.ListTags(ctx, imageRef)
+}
+
+func TestCheckForUpdates_TimesOutSlowContainers(t *testing.T) {
+	mockDocker := &mockDockerClient{
+		containers: []docker.Container{
+			{ID: "1", Name: "web", Image: "docker.io/library/nginx:1.24.0"},
+			{ID: "2", Name: "app", Image: "registry.example.com/app:1.0.0"},
+		},
+		imageDigests:  map[string]string{},
+		imageVersions: map[string]string{},
+		localImages:   map[string]bool{},
+	}
+	reg := &slowRegistry{
+		mockRegistryClient: &mockRegistryClient{
+			tags:           map[string][]string{"docker.io/library/nginx": {"1.25.0", "1.24.0"}},
+			tagDigests:     map[string]string{},
+			digestMappings: map[string]map[string][]string{},
+		},
+		slowRepo: "registry.example.com/app",
+	}
+	checker := NewChecker(mockDocker, reg, nil)
+	checker.checkTimeout = 50 * time.Millisecond
+
+	start := time.Now()
+	result, err := checker.CheckForUpdates(context.Background())
+	assert.NoError(t, err)
+	assert.Less(t, time.Since(start), 5*time.Second)
+	assert.Equal(t, 1, result.UpdatesFound)
+	assert.Equal(t, 1, result.Failed)
+
+	for _, update := range result.Updates {
+		if update.ContainerName != "app" {
+			assert.False(t, update.TimedOut)
+			continue
+		}
+		assert.True(t, update.TimedOut)
+		assert.Equal(t, MetadataUnavailable, update.Status)
+		assert.Contains(t, update.Error, "Check timed out after 50ms")
+	}
+}
+
+func TestCheckTimeoutFor(t *testing.T) {
+	checker := &Checker{checkTimeout: defaultCheckTimeout}
+	label := func(value string) docker.Container {
+		return docker.Container{Name: "app", Labels: map[string]string{"docksmith.check-timeout": value}}
+	}
+
+	assert.Equal(t, defaultCheckTimeout, checker.checkTimeoutFor(docker.Container{Name: "app"}))
+	assert.Equal(t, time.Minute, checker.checkTimeoutFor(label("1m")))
+	assert.Equal(t, time.Duration(0), checker.checkTimeoutFor(label("0")))
+	assert.Equal(t, defaultCheckTimeout, checker.checkTimeoutFor(label("soon")))
+}
@@ -3,18 +3,25 @@ package update
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"sort"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/chis/docksmith/internal/compose"
 	"github.com/chis/docksmith/internal/docker"
+	"github.com/chis/docksmith/internal/eol"
+	"github.com/chis/docksmith/internal/registry"
 	"github.com/chis/docksmith/internal/scripts"
+	"github.com/chis/docksmith/internal/scripts/builtin"
 	"github.com/chis/docksmith/internal/storage"
 	"gopkg.in/yaml.v3"
 	"github.com/chis/docksmith/internal/version"
@@ -27,8 +34,22 @@ type RegistryClient interface {
 	GetLatestTag(ctx context.Context, imageRef string) (string, error)
 	ListTagsWithDigests(ctx context.Context, imageRef string) (map[string][]string, error)
 	GetGhostTags(imageRef string) []string
+	GetImageSize(ctx context.Context, imageRef, reference string) (int64, error)
+	GetImageLayers(ctx context.Context, imageRef, reference string) ([]registry.ImageLayer, error)
+	GetImagePlatforms(ctx context.Context, imageRef, reference string) ([]string, error)
+	GetPlatformDigest(ctx context.Context, imageRef, reference string) (string, error)
+	GetSignatures(ctx context.Context, imageRef, digest string) (*registry.ImageSignatures, error)
 }
 
+// quotaReporter is implemented by registry clients that track rate limit quotas.
+type quotaReporter interface {
+	QuotaLow(imageRef string) bool
+}
+
+// enoughTagCandidates is how many releases newer than the running one a tag listing
+// must include before it may stop paginating.
+const enoughTagCandidates = 10
+
 // Checker checks for available container updates.
 type Checker struct {
 	dockerClient    docker.Client
@@ -37,6 +58,13 @@ type Checker struct {
 	versionParser   *version.Parser
 	versionComp     *version.Comparator
 	extractor       *version.Extractor
+	archFallback    bool          // Fall back to older tags when the latest has no image for the host architecture
+	differential    bool          // Reuse the last result of containers whose digest and tags are unchanged
+	checkTimeout    time.Duration // Longest check of one container, 0 = no limit
+	eolClient       *eol.Client   // Looks up end-of-life dates of known products, nil = off
+
+	conditionsMu   sync.Mutex
+	lastConditions map[string][]Condition // Container name → conditions of its last check
 }
 
 // NewChecker creates a new update checker.
@@ -49,6 +77,7 @@ func NewChecker(dockerClient docker.Client, registryManager RegistryClient, stor
 		versionParser:   version.NewParser(),
 		versionComp:     version.NewComparator(),
 		extractor:       version.NewExtractor(),
+		checkTimeout:    defaultCheckTimeout,
 	}
 }
 
@@ -67,6 +96,9 @@ func (c *Checker) CheckForUpdates(ctx context.Context) (*CheckResult, error) {
 	}
 
 	result.TotalChecked = len(containers)
+	ignoreRules := c.loadIgnoreRules(ctx)
+	ctx, lookups := withSharedLookups(ctx)
+	defer logSharedLookups(lookups)
 
 	for _, container := range containers {
 		// Check context for cancellation
@@ -78,7 +110,7 @@ func (c *Checker) CheckForUpdates(ctx context.Context) (*CheckResult, error) {
 		}
 
 		// Check if container should be ignored
-		if c.shouldIgnoreContainer(container) {
+		if c.shouldIgnoreContainer(container, ignoreRules) {
 			update := ContainerUpdate{
 				ContainerName: container.Name,
 				Image:         container.Image,
@@ -135,6 +167,8 @@ func (c *Checker) logCheckResults(ctx context.Context, updates []ContainerUpdate
 			LatestVersion:  update.LatestVersion,
 			Status:         status,
 			Error:          update.Error,
+			LatestSize:     update.LatestSize,
+			SizeDelta:      update.SizeDelta,
 		}
 
 		entries = append(entries, entry)
@@ -144,6 +178,42 @@ func (c *Checker) logCheckResults(ctx context.Context, updates []ContainerUpdate
 	return c.storage.LogCheckBatch(ctx, entries)
 }
 
+// populateImageSizes records the compressed size of the current and candidate images
+// so users can see how much an update will download. Sizes are best-effort: registry
+// failures leave them unset without affecting the check result.
+func (c *Checker) populateImageSizes(ctx context.Context, update *ContainerUpdate) {
+	imgInfo := c.extractor.ExtractFromImage(update.Image)
+	imageRef := imgInfo.Registry + "/" + imgInfo.Repository
+
+	// The current image is addressed by its digest since its tag may have moved on
+	currentRef := update.CurrentDigest
+	if !strings.HasPrefix(currentRef, "sha256:") {
+		currentRef = update.CurrentTag
+	}
+	latestRef := update.LatestVersion
+	if latestRef == "" || latestRef == update.CurrentTag {
+		latestRef = update.LatestDigest
+	}
+	if currentRef == "" || latestRef == "" {
+		return
+	}
+
+	latestSize, err := c.registry(ctx).GetImageSize(ctx, imageRef, latestRef)
+	if err != nil {
+		log.Printf("checkContainer %s: Failed to get size of %s: %v", update.ContainerName, latestRef, err)
+		return
+	}
+	update.LatestSize = latestSize
+
+	currentSize, err := c.registry(ctx).GetImageSize(ctx, imageRef, currentRef)
+	if err != nil {
+		log.Printf("checkContainer %s: Failed to get size of current image: %v", update.ContainerName, err)
+		return
+	}
+	update.CurrentSize = currentSize
+	update.SizeDelta = latestSize - currentSize
+}
+
 // mapStatusToString converts UpdateStatus to storage-compatible string
 func (c *Checker) mapStatusToString(status UpdateStatus) string {
 	switch status {
@@ -161,14 +231,17 @@ func (c *Checker) mapStatusToString(status UpdateStatus) string {
 		return "metadata_unavailable"
 	case Ignored:
 		return "ignored"
+	case UpdateUnavailableArch:
+		return "unavailable_arch"
 	default:
 		return "unknown"
 	}
 }
 
-// shouldIgnoreContainer checks if a container should be ignored from update checks.
-// Only checks container labels (source of truth from compose file).
-func (c *Checker) shouldIgnoreContainer(container docker.Container) bool {
+// shouldIgnoreContainer checks if a container should be ignored from update checks,
+// by its docksmith.ignore label (source of truth from compose file) or an image
+// ignore rule.
+func (c *Checker) shouldIgnoreContainer(container docker.Container, rules []storage.IgnoreRule) bool {
 	if ignoreValue, ok := container.Labels[scripts.IgnoreLabel]; ok {
 		ignore := ignoreValue == "true" || ignoreValue == "1" || ignoreValue == "yes"
 		if ignore {
@@ -177,6 +250,11 @@ func (c *Checker) shouldIgnoreContainer(container docker.Container) bool {
 		}
 	}
 
+	if rule, ok := matchIgnoreRule(rules, container); ok {
+		log.Printf("Container %s: Image %s matches ignore rule %q", container.Name, container.Image, rule.Pattern)
+		return true
+	}
+
 	return false
 }
 
@@ -352,8 +430,105 @@ func (c *Checker) checkEnvControlled(container docker.Container, update *Contain
 }
 
 // checkContainer checks a single container for updates.
+// Available updates also carry the download size of the new image, and versions
+// of known products the end-of-life date of their release cycle.
 func (c *Checker) checkContainer(ctx context.Context, container docker.Container) ContainerUpdate {
+	update := c.checkContainerResult(ctx, container)
+	c.setEndOfLife(ctx, container, &update)
+	c.setConditions(&update)
+	return update
+}
+
+// checkContainerResult checks a container, reusing its last result or deferring
+// the check when possible.
+func (c *Checker) checkContainerResult(ctx context.Context, container docker.Container) ContainerUpdate {
+	ctx = withImageLookups(ctx, container.Image)
+	timeout := c.checkTimeoutFor(container)
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeoutCause(ctx, timeout, errCheckTimedOut)
+		defer cancel()
+	}
+	quotaLow := c.quotaLow(container.Image)
+
+	// Stopped containers are low priority: defer them until the registry quota recovers
+	if quotaLow && container.State != "" && container.State != "running" {
+		log.Printf("checkContainer %s: Deferring check, registry rate limit nearly exhausted", container.Name)
+		return ContainerUpdate{
+			ContainerName: container.Name,
+			Image:         container.Image,
+			HealthStatus:  container.HealthStatus,
+			Status:        MetadataUnavailable,
+			Error:         "Check deferred: registry rate limit nearly exhausted",
+			Deferred:      true,
+		}
+	}
+
+	// A differential check only checks in full when the fingerprint changed
+	var fingerprint storage.CheckFingerprint
+	differential := false
+	if c.differential && c.storage != nil {
+		fingerprint, differential = c.checkFingerprint(ctx, container)
+		if differential {
+			if update, ok := c.lastResult(ctx, container, fingerprint); ok {
+				return update
+			}
+		}
+	}
+
+	update := c.checkContainerStatus(ctx, container)
+	if errors.Is(context.Cause(ctx), errCheckTimedOut) {
+		return timedOut(update, timeout)
+	}
+	// Image sizes cost manifest pulls, so skip them when the quota is low
+	// Sizes are of registry images, which a locally built image is not
+	if (update.Status == UpdateAvailable || update.Status == UpdateAvailableBlocked) && !quotaLow && !update.IsLocal {
+		c.populateImageSizes(ctx, &update)
+	}
+	if differential {
+		c.saveFingerprint(context.WithoutCancel(ctx), fingerprint, update)
+	}
+	return update
+}
+
+// enoughTags returns the stop condition of a versioned tag's listing: the running tag
+// and enoughTagCandidates newer releases with the same suffix have been listed.
+// Registries listing recently pushed tags first stop paginating there.
+func (c *Checker) enoughTags(parser *version.Parser, currentTag, suffix string, current *version.Version) func(tags []string) bool {
+	return func(tags []string) bool {
+		if !slices.Contains(tags, currentTag) {
+			return false
+		}
+		newer := 0
+		for _, tag := range tags {
+			info := parser.ParseImageTag("dummy:" + tag)
+			if info == nil || !info.IsVersioned || info.Version == nil || info.Suffix != suffix || info.Version.Prerelease != "" {
+				continue
+			}
+			if c.versionComp.IsNewer(current, info.Version) {
+				if newer++; newer >= enoughTagCandidates {
+					return true
+				}
+			}
+		}
+		return false
+	}
+}
+
+// quotaLow reports whether the registry serving image is close to its rate limit.
+func (c *Checker) quotaLow(image string) bool {
+	reporter, ok := c.registryManager.(quotaReporter)
+	if !ok {
+		return false
+	}
+	imgInfo := c.extractor.ExtractFromImage(image)
+	return reporter.QuotaLow(imgInfo.Registry + "/" + imgInfo.Repository)
+}
+
+// checkContainerStatus determines the update status of a single container.
+func (c *Checker) checkContainerStatus(ctx context.Context, container docker.Container) ContainerUpdate {
 	log.Printf("checkContainer: Starting check for %s (image: %s)", container.Name, container.Image)
+	ctx = withImageLookups(ctx, container.Image)
 	update := ContainerUpdate{
 		ContainerName: container.Name,
 		Image:         container.Image,
@@ -387,11 +562,18 @@ func (c *Checker) checkContainer(ctx context.Context, container docker.Container
 	if err == nil && isLocal {
 		update.IsLocal = true
 		update.Status = LocalImage
+		if tracksBaseImage(&container) {
+			return c.checkBaseImage(ctx, container, update)
+		}
 		return update
 	}
 
-	// Extract registry info and parse tag for suffix
-	imgInfo := c.extractor.ExtractFromImage(container.Image)
+	// Extract registry info and parse tag for suffix, applying the image's tag pattern
+	imgInfo, patternErr := c.extractor.ExtractFromImageWithPattern(container.Image, container.Labels[scripts.TagPatternLabel])
+	if patternErr != nil {
+		log.Printf("checkContainer %s: Ignoring %v", container.Name, patternErr)
+	}
+	tagParser := version.NewParserWithPattern(imgInfo.TagPattern)
 
 	// Store the current tag being used (extract from image string)
 	// Format: registry/repository:tag or repository:tag
@@ -495,9 +677,20 @@ func (c *Checker) checkContainer(ctx context.Context, container docker.Container
 
 	// Query registry for available tags
 	log.Printf("checkContainer %s: Querying registry for tags at %s", container.Name, imageRef)
-	tags, err := c.registryManager.ListTags(ctx, imageRef)
+	listCtx := ctx
+	if current := c.versionParser.ParseTag(currentVersion); tagParsed != nil && current != nil {
+		listCtx = registry.WithTagListStop(ctx, c.enoughTags(tagParser, checkTag, currentSuffix, current))
+	}
+	tags, err := c.registry(ctx).ListTags(listCtx, imageRef)
 	if err != nil {
 		log.Printf("checkContainer %s: ListTags error: %v", container.Name, err)
+		// Registry quota exhausted - retry on a later check instead of failing
+		if errors.Is(err, registry.ErrQuotaExhausted) {
+			update.Status = MetadataUnavailable
+			update.Error = "Check deferred: " + err.Error()
+			update.Deferred = true
+			return update
+		}
 		// Check if this is a registry metadata error (not a critical failure)
 		if c.isRegistryMetadataError(err) {
 			update.Status = MetadataUnavailable
@@ -512,6 +705,9 @@ func (c *Checker) checkContainer(ctx context.Context, container docker.Container
 
 	update.AvailableTags = tags
 	log.Printf("Container %s: Got %d available tags from registry", container.Name, len(tags))
+	if tagParsed != nil {
+		update.Variants = variantTags(tagParser, checkTag, tags)
+	}
 
 	// For non-versioned, non-meta tags (e.g., "server-cuda"), the label-derived version
 	// may come from a base image (e.g., Ubuntu "22.04") rather than the application.
@@ -561,11 +757,11 @@ func (c *Checker) checkContainer(ctx context.Context, container docker.Container
 				var bestCandidate string
 				var bestCandidateVer *version.Version
 				for _, t := range tags {
-					tagInfo := c.versionParser.ParseImageTag("dummy:" + t)
+					tagInfo := tagParser.ParseImageTag("dummy:" + t)
 					if tagInfo == nil || !tagInfo.IsVersioned || tagInfo.Version == nil {
 						continue
 					}
-					if tagInfo.Suffix != currentSuffix {
+					if tagInfo.Suffix != currentSuffix || tagInfo.Prefix != imgInfo.Tag.Prefix {
 						continue
 					}
 					if tagInfo.Version.Major != tagParsed.Major {
@@ -587,11 +783,11 @@ func (c *Checker) checkContainer(ctx context.Context, container docker.Container
 				}
 				if bestCandidate != "" {
 					// Verify this candidate's digest matches our current digest
-					candidateDigest, err := c.registryManager.GetTagDigest(ctx, imageRef, bestCandidate)
+					candidateDigest, err := c.registry(ctx).GetTagDigest(ctx, imageRef, bestCandidate)
 					if err == nil {
 						candidateSHA := strings.TrimPrefix(candidateDigest, "sha256:")
 						currentSHA := strings.TrimPrefix(currentDigest, "sha256:")
-						if candidateSHA == currentSHA {
+						if c.sameImage(ctx, imageRef, bestCandidate, currentDigest, candidateDigest) {
 							log.Printf("checkContainer %s: Resolved floating tag '%s' to '%s' via tag scan fallback", container.Name, checkTag, bestCandidate)
 							currentVersion = bestCandidate
 							update.CurrentVersion = currentVersion
@@ -618,15 +814,11 @@ func (c *Checker) checkContainer(ctx context.Context, container docker.Container
 		if currentDigest != "" {
 			log.Printf("Container %s: Using :latest tag, checking digest first", container.Name)
 			// Query registry for the digest of the tag we're tracking
-			latestDigest, err := c.registryManager.GetTagDigest(ctx, imageRef, checkTag)
+			latestDigest, err := c.registry(ctx).GetTagDigest(ctx, imageRef, checkTag)
 			if err == nil {
 				update.LatestDigest = latestDigest
 
-				// Compare digests (normalize format)
-				currentSHA := strings.TrimPrefix(currentDigest, "sha256:")
-				latestSHA := strings.TrimPrefix(latestDigest, "sha256:")
-
-				if currentSHA != latestSHA {
+				if !c.sameImage(ctx, imageRef, checkTag, currentDigest, latestDigest) {
 					update.Status = UpdateAvailable
 					update.ChangeType = version.UnknownChange
 					// Set the tag we're tracking as the "latest version" (what to update to)
@@ -709,14 +901,14 @@ func (c *Checker) checkContainer(ctx context.Context, container docker.Container
 	// Find the latest version from tags (filtered by suffix)
 	// Used for semver comparison when not using meta tags
 	log.Printf("Container %s: Calling findLatestVersion with suffix='%s', currentVer=%v, currentTag='%s'", container.Name, currentSuffix, currentVer, checkTag)
-	latestVersion := c.findLatestVersion(tags, currentSuffix, currentVer, container.Labels, checkTag)
+	latestVersion := c.findLatestVersion(tagParser, tags, currentSuffix, currentVer, container.Labels, checkTag)
 	log.Printf("Container %s: findLatestVersion returned: '%s'", container.Name, latestVersion)
 
 	// Probe for missing suffixed tags when no newer version found with current suffix.
 	// This handles repos (e.g., Frigate) where release tags (v0.17.0) are fetched via
 	// Releases API but hardware variant tags (0.17.0-tensorrt) are buried in deep pagination.
 	if currentSuffix != "" && latestVersion == "" && currentVer != nil {
-		latestUnsuffixed := c.findLatestVersion(tags, "", currentVer, container.Labels, checkTag)
+		latestUnsuffixed := c.findLatestVersion(tagParser, tags, "", currentVer, container.Labels, checkTag)
 		if latestUnsuffixed != "" {
 			unsuffixedVer := c.versionParser.ParseTag(latestUnsuffixed)
 			if unsuffixedVer != nil && c.versionComp.IsNewer(currentVer, unsuffixedVer) {
@@ -745,7 +937,7 @@ func (c *Checker) checkContainer(ctx context.Context, container docker.Container
 					if tagSet[candidate] {
 						continue
 					}
-					digest, err := c.registryManager.GetTagDigest(ctx, imageRef, candidate)
+					digest, err := c.registry(ctx).GetTagDigest(ctx, imageRef, candidate)
 					if err == nil && digest != "" {
 						log.Printf("Container %s: Discovered missing suffixed tag: %s", container.Name, candidate)
 						tags = append(tags, candidate)
@@ -754,7 +946,7 @@ func (c *Checker) checkContainer(ctx context.Context, container docker.Container
 					}
 				}
 
-				latestVersion = c.findLatestVersion(tags, currentSuffix, currentVer, container.Labels, checkTag)
+				latestVersion = c.findLatestVersion(tagParser, tags, currentSuffix, currentVer, container.Labels, checkTag)
 				log.Printf("Container %s: findLatestVersion after suffix probe returned: '%s'", container.Name, latestVersion)
 			}
 		}
@@ -846,15 +1038,11 @@ func (c *Checker) checkContainer(ctx context.Context, container docker.Container
 	if update.Status == Unknown || (update.Status == UpToDate && latestVersion == "") {
 		if currentDigest != "" {
 			// Query registry for the digest of the tag we're tracking
-			latestDigest, err := c.registryManager.GetTagDigest(ctx, imageRef, checkTag)
+			latestDigest, err := c.registry(ctx).GetTagDigest(ctx, imageRef, checkTag)
 			if err == nil {
 				update.LatestDigest = latestDigest
 
-				// Compare digests (normalize format)
-				currentSHA := strings.TrimPrefix(currentDigest, "sha256:")
-				latestSHA := strings.TrimPrefix(latestDigest, "sha256:")
-
-				if currentSHA != latestSHA {
+				if !c.sameImage(ctx, imageRef, checkTag, currentDigest, latestDigest) {
 					update.Status = UpdateAvailable
 					update.ChangeType = version.UnknownChange
 					// Set the tag we're tracking as the "latest version"
@@ -932,6 +1120,29 @@ func (c *Checker) checkContainer(ctx context.Context, container docker.Container
 		}
 	}
 
+	// Verify the update has an image for the host architecture. Registry quota is
+	// spared when low; meta tags have no older version to fall back to.
+	if update.Status == UpdateAvailable && !c.quotaLow(container.Image) {
+		var nextCandidate func(exclude map[string]bool) string
+		if c.archFallbackEnabled(container) && !isMetaTag(update.LatestVersion) {
+			nextCandidate = func(exclude map[string]bool) string {
+				var remaining []string
+				for _, t := range tags {
+					if !exclude[t] {
+						remaining = append(remaining, t)
+					}
+				}
+				candidate := c.findLatestVersion(tagParser, remaining, currentSuffix, currentVer, container.Labels, checkTag)
+				candidateVer := c.versionParser.ParseTag(candidate)
+				if currentVer == nil || candidateVer == nil || !c.versionComp.IsNewer(currentVer, candidateVer) {
+					return ""
+				}
+				return candidate
+			}
+		}
+		c.verifyArchitecture(ctx, &update, imageRef, currentVer, nextCandidate)
+	}
+
 	// Run pre-update check if configured (only from labels)
 	log.Printf("Container %s: Checking pre-update conditions - status=%s", container.Name, update.Status)
 	checkScript := ""
@@ -946,7 +1157,7 @@ func (c *Checker) checkContainer(ctx context.Context, container docker.Container
 		update.PreUpdateCheck = checkScript
 		log.Printf("Container %s: Running pre-update check: %s", container.Name, checkScript)
 
-		success, reason := c.runPreUpdateCheck(ctx, checkScript, container.Name)
+		success, reason := c.runPreUpdateCheck(ctx, checkScript, &container)
 		if !success {
 			log.Printf("Container %s: Pre-update check failed: %s", container.Name, reason)
 			update.PreUpdateCheckFail = reason
@@ -964,7 +1175,7 @@ func (c *Checker) checkContainer(ctx context.Context, container docker.Container
 	if currentVer != nil {
 		ghostTags := c.registryManager.GetGhostTags(imageRef)
 		for _, gt := range ghostTags {
-			tagInfo := c.versionParser.ParseImageTag("dummy:" + gt)
+			tagInfo := tagParser.ParseImageTag("dummy:" + gt)
 			if tagInfo == nil || !tagInfo.IsVersioned || tagInfo.Version == nil {
 				continue
 			}
@@ -982,6 +1193,28 @@ func (c *Checker) checkContainer(ctx context.Context, container docker.Container
 	return update
 }
 
+// sameImage reports whether the local image digest and the registry digest of
+// reference are the same image. The registry reports the digest of a multi-arch
+// index, while the local image may record the child manifest it pulled for the
+// host platform, so a mismatch is checked again against the platform digest.
+func (c *Checker) sameImage(ctx context.Context, imageRef, reference, currentDigest, registryDigest string) bool {
+	currentSHA := strings.TrimPrefix(currentDigest, "sha256:")
+	if currentSHA == strings.TrimPrefix(registryDigest, "sha256:") {
+		return true
+	}
+
+	platformDigest, err := c.registry(ctx).GetPlatformDigest(ctx, imageRef, reference)
+	if err != nil {
+		log.Printf("checkContainer: Failed to resolve platform digest of %s:%s: %v", imageRef, reference, err)
+		return false
+	}
+	if currentSHA == strings.TrimPrefix(platformDigest, "sha256:") {
+		log.Printf("checkContainer: %s:%s matches the local image through its linux/%s manifest", imageRef, reference, registry.HostArchitecture)
+		return true
+	}
+	return false
+}
+
 // isRegistryMetadataError checks if an error is a registry metadata lookup failure
 // (like 404 on old SHAs) rather than a critical failure
 func (c *Checker) isRegistryMetadataError(err error) bool {
@@ -1012,7 +1245,29 @@ func (c *Checker) isRegistryMetadataError(err error) bool {
 // runPreUpdateCheck executes a pre-update check script and returns success status and reason.
 // The script should exit 0 for success (safe to update) and non-zero for failure (blocked).
 // Output from the script (stdout/stderr) is captured and returned as the reason.
-func (c *Checker) runPreUpdateCheck(ctx context.Context, scriptPath, containerName string) (bool, string) {
+// Built-in checks ("builtin:plex") report the app's activity as the reason, and managed
+// scripts ("managed:check.sh@2") run in their sandbox.
+func (c *Checker) runPreUpdateCheck(ctx context.Context, scriptPath string, container *docker.Container) (bool, string) {
+	if builtin.IsCheck(scriptPath) {
+		if err := scripts.RunBuiltinCheck(ctx, container, scriptPath); err != nil {
+			return false, err.Error()
+		}
+		return true, "Check passed"
+	}
+	if scripts.IsManagedScript(scriptPath) {
+		output, err := scripts.RunManagedScript(ctx, container, scriptPath, scripts.PreUpdateCheckTimeout)
+		if _, ok := err.(*exec.ExitError); ok {
+			if reason := strings.TrimSpace(string(output)); reason != "" {
+				return false, reason
+			}
+		}
+		if err != nil {
+			return false, err.Error()
+		}
+		return true, "Check passed"
+	}
+	containerName := container.Name
+
 	// Construct full path if not already absolute
 	fullPath := scriptPath
 	if !filepath.IsAbs(scriptPath) {
@@ -1070,10 +1325,20 @@ func (c *Checker) getCurrentVersion(ctx context.Context, imageName string) strin
 // findLatestVersion finds the newest semantic version from a list of tags.
 // Only considers tags that match the given suffix (variant filter).
 // If currentVersion is stable (no prerelease), skips prerelease versions.
-// Applies custom filters from container labels (regex, min/max versions, minor pinning).
+// Applies custom filters from container labels (regex, min/max versions, semver range, minor pinning).
 // currentTag is used to prefer tags with matching format (e.g., prefer "8.1.0" over "v8.1.0"
-// when the current tag is "8.0.1" without a v-prefix).
-func (c *Checker) findLatestVersion(tags []string, requiredSuffix string, currentVersion *version.Version, labels map[string]string, currentTag string) string {
+// when the current tag is "8.0.1" without a v-prefix) and to stay on the same tag channel
+// (e.g., LinuxServer "version-" aliases). parser applies the image's tag pattern.
+func (c *Checker) findLatestVersion(parser *version.Parser, tags []string, requiredSuffix string, currentVersion *version.Version, labels map[string]string, currentTag string) string {
+	candidates := c.candidateTags(parser, tags, requiredSuffix, currentVersion, labels, currentTag)
+	if len(candidates) == 0 {
+		return ""
+	}
+	return candidates[0]
+}
+
+// candidateTags returns the tags findLatestVersion chooses from, newest first.
+func (c *Checker) candidateTags(parser *version.Parser, tags []string, requiredSuffix string, currentVersion *version.Version, labels map[string]string, currentTag string) []string {
 	// Apply regex filter first (if specified)
 	if regexPattern := labels[scripts.TagRegexLabel]; regexPattern != "" {
 		tags = filterTagsByRegex(tags, regexPattern)
@@ -1110,6 +1375,18 @@ func (c *Checker) findLatestVersion(tags []string, requiredSuffix string, curren
 		}
 	}
 
+	// Parse semver range constraint (invalid constraints are ignored)
+	var constraint *version.Constraint
+	if constraintStr := labels[scripts.VersionConstraintLabel]; constraintStr != "" {
+		parsed, err := version.ParseConstraint(constraintStr)
+		if err != nil {
+			log.Printf("findLatestVersion: Ignoring %v", err)
+		} else {
+			constraint = parsed
+			log.Printf("findLatestVersion: Version constraint: %s", constraint.String())
+		}
+	}
+
 	// Check if major version pinning is enabled
 	pinMajor := labels[scripts.VersionPinMajorLabel] == "true"
 	if pinMajor && currentVersion != nil {
@@ -1128,6 +1405,12 @@ func (c *Checker) findLatestVersion(tags []string, requiredSuffix string, curren
 		log.Printf("findLatestVersion: Patch version pinning enabled (current: %d.%d.%d)", currentVersion.Major, currentVersion.Minor, currentVersion.Patch)
 	}
 
+	// Tags with a channel prefix (e.g., "version-1.32.3") only update to the same channel
+	requiredPrefix := ""
+	if currentTag != "" {
+		requiredPrefix = parser.ParseImageTag("dummy:" + currentTag).Prefix
+	}
+
 	log.Printf("findLatestVersion: Looking for tags with suffix='%s', currentVersion=%v, skipPrereleases=%v, allowPrerelease=%v", requiredSuffix, currentVersion, skipPrereleases, allowPrerelease)
 
 	for _, tag := range tags {
@@ -1136,11 +1419,15 @@ func (c *Checker) findLatestVersion(tags []string, requiredSuffix string, curren
 		}
 
 		// Parse the tag to extract version and suffix
-		tagInfo := c.versionParser.ParseImageTag("dummy:" + tag)
+		tagInfo := parser.ParseImageTag("dummy:" + tag)
 		if tagInfo == nil || !tagInfo.IsVersioned || tagInfo.Version == nil {
 			continue // Skip tags without semantic versions
 		}
 
+		if tagInfo.Prefix != requiredPrefix {
+			continue // Different tag channel
+		}
+
 		// Skip tags whose version type doesn't match the current version's type
 		// This prevents cross-comparison of semantic versions (3.23.3) with date tags (20260127)
 		if currentVersion != nil && currentVersion.Type != "" && tagInfo.Version.Type != currentVersion.Type {
@@ -1195,6 +1482,12 @@ func (c *Checker) findLatestVersion(tags []string, requiredSuffix string, curren
 			continue
 		}
 
+		// Apply semver range constraint
+		if constraint != nil && !constraint.Check(tagInfo.Version) {
+			log.Printf("  Skipping tag %s: outside version constraint %s", tag, constraint.String())
+			continue
+		}
+
 		log.Printf("  Accepted tag %s: version=%s, suffix='%s', buildNum=%d", tag, tagInfo.Version.String(), tagInfo.Suffix, tagInfo.Version.BuildNumber)
 		versions = append(versions, tagInfo.Version)
 		// Use Original (the full tag) as key since String() doesn't include build number
@@ -1202,7 +1495,7 @@ func (c *Checker) findLatestVersion(tags []string, requiredSuffix string, curren
 	}
 
 	if len(versions) == 0 {
-		return ""
+		return nil
 	}
 
 	// Determine if current tag uses a v-prefix (e.g., "v8.0.1" vs "8.0.1")
@@ -1226,8 +1519,11 @@ func (c *Checker) findLatestVersion(tags []string, requiredSuffix string, curren
 		return false
 	})
 
-	latest := versions[0]
-	return versionToTag[latest.Original]
+	candidates := make([]string, 0, len(versions))
+	for _, v := range versions {
+		candidates = append(candidates, versionToTag[v.Original])
+	}
+	return candidates
 }
 
 // resolveVersionFromDigest attempts to find which semantic version tag corresponds
@@ -1245,7 +1541,7 @@ func (c *Checker) resolveVersionFromDigest(ctx context.Context, imageRef, curren
 
 	// Check cache first if storage is available
 	if c.storage != nil {
-		cachedVersion, found, err := c.storage.GetVersionCache(ctx, currentDigest, imageRef, "amd64")
+		cachedVersion, found, err := c.storage.GetVersionCache(ctx, currentDigest, imageRef, registry.HostArchitecture)
 		if err != nil {
 			// Log error but continue with registry lookup
 			log.Printf("Cache lookup error for %s (%s): %v", imageRef, currentDigest, err)
@@ -1260,7 +1556,7 @@ func (c *Checker) resolveVersionFromDigest(ctx context.Context, imageRef, curren
 	}
 
 	// Get tag→digest mappings from registry
-	tagDigests, err := c.registryManager.ListTagsWithDigests(ctx, imageRef)
+	tagDigests, err := c.registry(ctx).ListTagsWithDigests(ctx, imageRef)
 	if err != nil {
 		// Failed to get mappings, can't resolve
 		// But this is not critical - just means we can't resolve version
@@ -1363,7 +1659,7 @@ func (c *Checker) resolveVersionFromDigest(ctx context.Context, imageRef, curren
 
 		// Save to cache if storage is available
 		if c.storage != nil {
-			err := c.storage.SaveVersionCache(ctx, currentDigest, imageRef, resolvedVersion, "amd64")
+			err := c.storage.SaveVersionCache(ctx, currentDigest, imageRef, resolvedVersion, registry.HostArchitecture)
 			if err != nil {
 				// Log error but don't fail the resolution
 				log.Printf("Failed to save to cache: %v", err)
@@ -7,6 +7,8 @@ import (
 	"time"
 
 	"github.com/chis/docksmith/internal/docker"
+	"github.com/chis/docksmith/internal/registry"
+	"github.com/chis/docksmith/internal/scripts"
 	"github.com/chis/docksmith/internal/storage"
 )
 
@@ -99,7 +101,6 @@ func (m *mockStorage) SetConfig(ctx context.Context, key, value string) error {
 	return nil
 }
 
-
 func (m *mockStorage) SaveConfigSnapshot(ctx context.Context, snapshot storage.ConfigSnapshot) error {
 	return nil
 }
@@ -188,6 +189,274 @@ func (m *mockStorage) Close() error {
 	return nil
 }
 
+func (m *mockStorage) QueryUpdateOperations(ctx context.Context, opts storage.OperationQueryOptions) (storage.OperationQueryResult, error) {
+	return storage.OperationQueryResult{}, nil
+}
+
+func (m *mockStorage) DeleteAllHistory(ctx context.Context) (int64, error) {
+	return 0, nil
+}
+
+func (m *mockStorage) DeleteHistoryBefore(ctx context.Context, before time.Time) (int64, error) {
+	return 0, nil
+}
+
+func (m *mockStorage) CreateUser(ctx context.Context, user storage.User) (storage.User, error) {
+	return user, nil
+}
+
+func (m *mockStorage) GetUser(ctx context.Context, username string) (storage.User, bool, error) {
+	return storage.User{}, false, nil
+}
+
+func (m *mockStorage) GetUserByID(ctx context.Context, id int64) (storage.User, bool, error) {
+	return storage.User{}, false, nil
+}
+
+func (m *mockStorage) ListUsers(ctx context.Context) ([]storage.User, error) {
+	return nil, nil
+}
+
+func (m *mockStorage) UpdateUser(ctx context.Context, user storage.User) error {
+	return nil
+}
+
+func (m *mockStorage) DeleteUser(ctx context.Context, username string) error {
+	return nil
+}
+
+func (m *mockStorage) SaveSession(ctx context.Context, session storage.Session) error {
+	return nil
+}
+
+func (m *mockStorage) GetSession(ctx context.Context, tokenHash string) (storage.Session, bool, error) {
+	return storage.Session{}, false, nil
+}
+
+func (m *mockStorage) DeleteSession(ctx context.Context, tokenHash string) error {
+	return nil
+}
+
+func (m *mockStorage) DeleteExpiredSessions(ctx context.Context, now time.Time) (int64, error) {
+	return 0, nil
+}
+
+func (m *mockStorage) SaveApproval(ctx context.Context, approval storage.Approval) error {
+	return nil
+}
+
+func (m *mockStorage) GetApproval(ctx context.Context, id string) (storage.Approval, bool, error) {
+	return storage.Approval{}, false, nil
+}
+
+func (m *mockStorage) GetLatestApproval(ctx context.Context, containerName string) (storage.Approval, bool, error) {
+	return storage.Approval{}, false, nil
+}
+
+func (m *mockStorage) ListApprovals(ctx context.Context, status string, limit int) ([]storage.Approval, error) {
+	return nil, nil
+}
+
+func (m *mockStorage) ExpireApprovals(ctx context.Context, now time.Time) (int64, error) {
+	return 0, nil
+}
+
+func (m *mockStorage) SaveProposal(ctx context.Context, proposal storage.Proposal) error {
+	return nil
+}
+
+func (m *mockStorage) GetProposal(ctx context.Context, id string) (storage.Proposal, bool, error) {
+	return storage.Proposal{}, false, nil
+}
+
+func (m *mockStorage) GetLatestProposal(ctx context.Context, containerName string) (storage.Proposal, bool, error) {
+	return storage.Proposal{}, false, nil
+}
+
+func (m *mockStorage) ListProposals(ctx context.Context, status string, limit int) ([]storage.Proposal, error) {
+	return nil, nil
+}
+
+func (m *mockStorage) GetTagCache(ctx context.Context, imageRef string) (storage.TagCacheEntry, bool, error) {
+	return storage.TagCacheEntry{}, false, nil
+}
+
+func (m *mockStorage) SaveTagCache(ctx context.Context, entry storage.TagCacheEntry) error {
+	return nil
+}
+
+func (m *mockStorage) GetCheckFingerprint(ctx context.Context, containerName string) (storage.CheckFingerprint, bool, error) {
+	return storage.CheckFingerprint{}, false, nil
+}
+
+func (m *mockStorage) SaveCheckFingerprint(ctx context.Context, fingerprint storage.CheckFingerprint) error {
+	return nil
+}
+
+func (m *mockStorage) QueryCheckHistory(ctx context.Context, opts storage.CheckHistoryQueryOptions) ([]storage.CheckHistoryEntry, error) {
+	return nil, nil
+}
+
+func (m *mockStorage) ListRollbackPolicies(ctx context.Context) ([]storage.RollbackPolicy, error) {
+	return nil, nil
+}
+
+func (m *mockStorage) Backup(ctx context.Context, destPath string) error {
+	return nil
+}
+
+func (m *mockStorage) Vacuum(ctx context.Context) (storage.VacuumResult, error) {
+	return storage.VacuumResult{}, nil
+}
+
+func (m *mockStorage) PruneHistory(ctx context.Context, opts storage.PruneOptions) (storage.PruneResult, error) {
+	return storage.PruneResult{}, nil
+}
+
+func (m *mockStorage) ListComposeBackups(ctx context.Context) ([]storage.ComposeBackup, error) {
+	return nil, nil
+}
+
+func (m *mockStorage) DeleteComposeBackups(ctx context.Context, ids []int64) (int64, error) {
+	return 0, nil
+}
+
+func (m *mockStorage) GetUnfinishedOperations(ctx context.Context) ([]storage.UpdateOperation, error) {
+	return nil, nil
+}
+
+func (m *mockStorage) SaveScriptRevision(ctx context.Context, revision storage.ScriptRevision) error {
+	return nil
+}
+
+func (m *mockStorage) GetScriptRevision(ctx context.Context, name string, revision int) (storage.ScriptRevision, bool, error) {
+	return storage.ScriptRevision{}, false, nil
+}
+
+func (m *mockStorage) ListScriptRevisions(ctx context.Context, name string) ([]storage.ScriptRevision, error) {
+	return nil, nil
+}
+
+func (m *mockStorage) SaveVolumeSnapshot(ctx context.Context, snapshot storage.VolumeSnapshot) error {
+	return nil
+}
+
+func (m *mockStorage) GetVolumeSnapshots(ctx context.Context, operationID string) ([]storage.VolumeSnapshot, error) {
+	return nil, nil
+}
+
+func (m *mockStorage) ListVolumeSnapshots(ctx context.Context, volumeName string) ([]storage.VolumeSnapshot, error) {
+	return nil, nil
+}
+
+func (m *mockStorage) DeleteVolumeSnapshot(ctx context.Context, id int64) error {
+	return nil
+}
+
+func (m *mockStorage) GetApprovalPolicy(ctx context.Context, entityType, entityID string) (storage.ApprovalPolicy, bool, error) {
+	return storage.ApprovalPolicy{}, false, nil
+}
+
+func (m *mockStorage) SetApprovalPolicy(ctx context.Context, policy storage.ApprovalPolicy) error {
+	return nil
+}
+
+func (m *mockStorage) ListApprovalPolicies(ctx context.Context) ([]storage.ApprovalPolicy, error) {
+	return nil, nil
+}
+
+func (m *mockStorage) DeleteApprovalPolicy(ctx context.Context, entityType, entityID string) error {
+	return nil
+}
+
+func (m *mockStorage) AppendOperationLog(ctx context.Context, entry storage.OperationLogEntry) (int64, error) {
+	return 0, nil
+}
+
+func (m *mockStorage) GetOperationLogs(ctx context.Context, operationID string, afterID int64) ([]storage.OperationLogEntry, error) {
+	return nil, nil
+}
+
+func (m *mockStorage) AppendComposeOutput(ctx context.Context, operationID string, output string) error {
+	return nil
+}
+
+func (m *mockStorage) ListIgnoreRules(ctx context.Context) ([]storage.IgnoreRule, error) {
+	return nil, nil
+}
+
+func (m *mockStorage) SaveIgnoreRule(ctx context.Context, rule storage.IgnoreRule) (storage.IgnoreRule, error) {
+	return rule, nil
+}
+
+func (m *mockStorage) DeleteIgnoreRule(ctx context.Context, id int64) (bool, error) {
+	return false, nil
+}
+
+func (m *mockStorage) GetStackEnv(ctx context.Context, stack string) (storage.StackEnv, bool, error) {
+	return storage.StackEnv{}, false, nil
+}
+
+func (m *mockStorage) SetStackEnv(ctx context.Context, env storage.StackEnv) error {
+	return nil
+}
+
+func (m *mockStorage) ListStackEnvs(ctx context.Context) ([]storage.StackEnv, error) {
+	return nil, nil
+}
+
+func (m *mockStorage) DeleteStackEnv(ctx context.Context, stack string) (bool, error) {
+	return false, nil
+}
+
+func (m *mockStorage) ListOwnerships(ctx context.Context) ([]storage.Ownership, error) {
+	return nil, nil
+}
+
+func (m *mockStorage) AddOwnership(ctx context.Context, ownership storage.Ownership) error {
+	return nil
+}
+
+func (m *mockStorage) RemoveOwnership(ctx context.Context, entityType, entityID, owner string) (bool, error) {
+	return false, nil
+}
+
+func (m *mockStorage) ListTeamMembers(ctx context.Context) ([]storage.TeamMember, error) {
+	return nil, nil
+}
+
+func (m *mockStorage) AddTeamMember(ctx context.Context, team, username string) error {
+	return nil
+}
+
+func (m *mockStorage) RemoveTeamMember(ctx context.Context, team, username string) (bool, error) {
+	return false, nil
+}
+
+func (m *mockStorage) CheckWritable(ctx context.Context) error {
+	return nil
+}
+
+func (m *mockStorage) SaveStackLock(ctx context.Context, lock storage.StackLock) error {
+	return nil
+}
+
+func (m *mockStorage) DeleteStackLock(ctx context.Context, stackName, operationID string) error {
+	return nil
+}
+
+func (m *mockStorage) ListStackLocks(ctx context.Context) ([]storage.StackLock, error) {
+	return nil, nil
+}
+
+func (m *mockStorage) SetQueuePriorities(ctx context.Context, priorities map[string]int) error {
+	return nil
+}
+
+func (m *mockStorage) DeleteQueuedUpdate(ctx context.Context, operationID string) (bool, error) {
+	return false, nil
+}
+
 // TestCheckerUseCacheBeforeRegistryAPICall tests that checker queries cache before making registry API calls
 func TestCheckerUseCacheBeforeRegistryAPICall(t *testing.T) {
 	mockDocker := &mockDockerClient{
@@ -245,6 +514,115 @@ func TestCheckerUseCacheBeforeRegistryAPICall(t *testing.T) {
 	}
 }
 
+// TestCheckerRecordsImageSizeDelta tests that detected updates include the download size change
+func TestCheckerRecordsImageSizeDelta(t *testing.T) {
+	mockDocker := &mockDockerClient{
+		containers: []docker.Container{
+			{
+				ID:    "test-container",
+				Name:  "test",
+				Image: "docker.io/library/nginx:1.24.0",
+			},
+		},
+		imageDigests: map[string]string{
+			"docker.io/library/nginx:1.24.0": "sha256:abc123",
+		},
+		imageVersions: map[string]string{},
+		localImages:   map[string]bool{},
+	}
+
+	mockRegistry := &mockRegistryClient{
+		tags: map[string][]string{
+			"docker.io/library/nginx": {"1.25.0", "1.24.0"},
+		},
+		tagDigests:     map[string]string{},
+		digestMappings: map[string]map[string][]string{},
+		sizes: map[string]int64{
+			"docker.io/library/nginx:sha256:abc123": 50_000_000,
+			"docker.io/library/nginx:1.25.0":        62_000_000,
+		},
+	}
+
+	checker := NewChecker(mockDocker, mockRegistry, nil)
+	result, err := checker.CheckForUpdates(context.Background())
+	if err != nil {
+		t.Fatalf("CheckForUpdates failed: %v", err)
+	}
+
+	update := result.Updates[0]
+	if update.Status != UpdateAvailable {
+		t.Fatalf("Expected UPDATE_AVAILABLE, got %s", update.Status)
+	}
+	if update.LatestSize != 62_000_000 || update.CurrentSize != 50_000_000 {
+		t.Errorf("Expected sizes 50000000 -> 62000000, got %d -> %d", update.CurrentSize, update.LatestSize)
+	}
+	if update.SizeDelta != 12_000_000 {
+		t.Errorf("Expected size delta 12000000, got %d", update.SizeDelta)
+	}
+
+	// Size lookups are best-effort and never fail the check
+	mockRegistry.sizes = nil
+	result, err = checker.CheckForUpdates(context.Background())
+	if err != nil {
+		t.Fatalf("CheckForUpdates failed: %v", err)
+	}
+	if result.Updates[0].Status != UpdateAvailable || result.Updates[0].SizeDelta != 0 {
+		t.Errorf("Expected update without size data, got %+v", result.Updates[0])
+	}
+}
+
+// quotaLowRegistryClient reports a nearly exhausted registry quota
+type quotaLowRegistryClient struct {
+	*mockRegistryClient
+}
+
+func (m *quotaLowRegistryClient) QuotaLow(imageRef string) bool { return true }
+
+// TestCheckerDefersChecksWhenQuotaLow tests that stopped containers are deferred and
+// optional size lookups are skipped while the registry quota is nearly exhausted
+func TestCheckerDefersChecksWhenQuotaLow(t *testing.T) {
+	mockDocker := &mockDockerClient{
+		containers: []docker.Container{
+			{ID: "running", Name: "web", Image: "docker.io/library/nginx:1.24.0", State: "running"},
+			{ID: "stopped", Name: "old", Image: "docker.io/library/nginx:1.24.0", State: "exited"},
+		},
+		imageDigests: map[string]string{
+			"docker.io/library/nginx:1.24.0": "sha256:abc123",
+		},
+		imageVersions: map[string]string{},
+		localImages:   map[string]bool{},
+	}
+
+	mockRegistry := &quotaLowRegistryClient{&mockRegistryClient{
+		tags: map[string][]string{
+			"docker.io/library/nginx": {"1.25.0", "1.24.0"},
+		},
+		tagDigests:     map[string]string{},
+		digestMappings: map[string]map[string][]string{},
+		sizes: map[string]int64{
+			"docker.io/library/nginx:sha256:abc123": 50_000_000,
+			"docker.io/library/nginx:1.25.0":        62_000_000,
+		},
+	}}
+
+	checker := NewChecker(mockDocker, mockRegistry, nil)
+	result, err := checker.CheckForUpdates(context.Background())
+	if err != nil {
+		t.Fatalf("CheckForUpdates failed: %v", err)
+	}
+
+	running, stopped := result.Updates[0], result.Updates[1]
+	if running.Status != UpdateAvailable || running.Deferred {
+		t.Errorf("Expected running container to be checked, got %s (deferred=%v)", running.Status, running.Deferred)
+	}
+	if running.SizeDelta != 0 {
+		t.Errorf("Expected size lookup to be skipped, got delta %d", running.SizeDelta)
+	}
+	if stopped.Status != MetadataUnavailable || !stopped.Deferred {
+		t.Errorf("Expected stopped container to be deferred, got %s (deferred=%v)", stopped.Status, stopped.Deferred)
+	}
+}
+
 // TestCheckerSavesSuccessfulResolutionToCache tests that checker saves successful registry resolutions to cache
 func TestCheckerSavesSuccessfulResolutionToCache(t *testing.T) {
 	mockDocker := &mockDockerClient{
@@ -578,7 +956,6 @@ func (f *failingStorage) SetConfig(ctx context.Context, key, value string) error
 	return errors.New("storage error")
 }
 
-
 func (f *failingStorage) SaveConfigSnapshot(ctx context.Context, snapshot storage.ConfigSnapshot) error {
 	return errors.New("storage error")
 }
@@ -667,6 +1044,274 @@ func (f *failingStorage) Close() error {
 	return errors.New("storage error")
 }
 
+func (f *failingStorage) QueryUpdateOperations(ctx context.Context, opts storage.OperationQueryOptions) (storage.OperationQueryResult, error) {
+	return storage.OperationQueryResult{}, errors.New("storage error")
+}
+
+func (f *failingStorage) DeleteAllHistory(ctx context.Context) (int64, error) {
+	return 0, errors.New("storage error")
+}
+
+func (f *failingStorage) DeleteHistoryBefore(ctx context.Context, before time.Time) (int64, error) {
+	return 0, errors.New("storage error")
+}
+
+func (f *failingStorage) CreateUser(ctx context.Context, user storage.User) (storage.User, error) {
+	return user, errors.New("storage error")
+}
+
+func (f *failingStorage) GetUser(ctx context.Context, username string) (storage.User, bool, error) {
+	return storage.User{}, false, errors.New("storage error")
+}
+
+func (f *failingStorage) GetUserByID(ctx context.Context, id int64) (storage.User, bool, error) {
+	return storage.User{}, false, errors.New("storage error")
+}
+
+func (f *failingStorage) ListUsers(ctx context.Context) ([]storage.User, error) {
+	return nil, errors.New("storage error")
+}
+
+func (f *failingStorage) UpdateUser(ctx context.Context, user storage.User) error {
+	return errors.New("storage error")
+}
+
+func (f *failingStorage) DeleteUser(ctx context.Context, username string) error {
+	return errors.New("storage error")
+}
+
+func (f *failingStorage) SaveSession(ctx context.Context, session storage.Session) error {
+	return errors.New("storage error")
+}
+
+func (f *failingStorage) GetSession(ctx context.Context, tokenHash string) (storage.Session, bool, error) {
+	return storage.Session{}, false, errors.New("storage error")
+}
+
+func (f *failingStorage) DeleteSession(ctx context.Context, tokenHash string) error {
+	return errors.New("storage error")
+}
+
+func (f *failingStorage) DeleteExpiredSessions(ctx context.Context, now time.Time) (int64, error) {
+	return 0, errors.New("storage error")
+}
+
+func (f *failingStorage) SaveApproval(ctx context.Context, approval storage.Approval) error {
+	return errors.New("storage error")
+}
+
+func (f *failingStorage) GetApproval(ctx context.Context, id string) (storage.Approval, bool, error) {
+	return storage.Approval{}, false, errors.New("storage error")
+}
+
+func (f *failingStorage) GetLatestApproval(ctx context.Context, containerName string) (storage.Approval, bool, error) {
+	return storage.Approval{}, false, errors.New("storage error")
+}
+
+func (f *failingStorage) ListApprovals(ctx context.Context, status string, limit int) ([]storage.Approval, error) {
+	return nil, errors.New("storage error")
+}
+
+func (f *failingStorage) ExpireApprovals(ctx context.Context, now time.Time) (int64, error) {
+	return 0, errors.New("storage error")
+}
+
+func (f *failingStorage) SaveProposal(ctx context.Context, proposal storage.Proposal) error {
+	return errors.New("storage error")
+}
+
+func (f *failingStorage) GetProposal(ctx context.Context, id string) (storage.Proposal, bool, error) {
+	return storage.Proposal{}, false, errors.New("storage error")
+}
+
+func (f *failingStorage) GetLatestProposal(ctx context.Context, containerName string) (storage.Proposal, bool, error) {
+	return storage.Proposal{}, false, errors.New("storage error")
+}
+
+func (f *failingStorage) ListProposals(ctx context.Context, status string, limit int) ([]storage.Proposal, error) {
+	return nil, errors.New("storage error")
+}
+
+func (f *failingStorage) GetTagCache(ctx context.Context, imageRef string) (storage.TagCacheEntry, bool, error) {
+	return storage.TagCacheEntry{}, false, errors.New("storage error")
+}
+
+func (f *failingStorage) SaveTagCache(ctx context.Context, entry storage.TagCacheEntry) error {
+	return errors.New("storage error")
+}
+
+func (f *failingStorage) GetCheckFingerprint(ctx context.Context, containerName string) (storage.CheckFingerprint, bool, error) {
+	return storage.CheckFingerprint{}, false, errors.New("storage error")
+}
+
+func (f *failingStorage) SaveCheckFingerprint(ctx context.Context, fingerprint storage.CheckFingerprint) error {
+	return errors.New("storage error")
+}
+
+func (f *failingStorage) QueryCheckHistory(ctx context.Context, opts storage.CheckHistoryQueryOptions) ([]storage.CheckHistoryEntry, error) {
+	return nil, errors.New("storage error")
+}
+
+func (f *failingStorage) ListRollbackPolicies(ctx context.Context) ([]storage.RollbackPolicy, error) {
+	return nil, errors.New("storage error")
+}
+
+func (f *failingStorage) Backup(ctx context.Context, destPath string) error {
+	return errors.New("storage error")
+}
+
+func (f *failingStorage) Vacuum(ctx context.Context) (storage.VacuumResult, error) {
+	return storage.VacuumResult{}, errors.New("storage error")
+}
+
+func (f *failingStorage) PruneHistory(ctx context.Context, opts storage.PruneOptions) (storage.PruneResult, error) {
+	return storage.PruneResult{}, errors.New("storage error")
+}
+
+func (f *failingStorage) ListComposeBackups(ctx context.Context) ([]storage.ComposeBackup, error) {
+	return nil, errors.New("storage error")
+}
+
+func (f *failingStorage) DeleteComposeBackups(ctx context.Context, ids []int64) (int64, error) {
+	return 0, errors.New("storage error")
+}
+
+func (f *failingStorage) GetUnfinishedOperations(ctx context.Context) ([]storage.UpdateOperation, error) {
+	return nil, errors.New("storage error")
+}
+
+func (f *failingStorage) SaveScriptRevision(ctx context.Context, revision storage.ScriptRevision) error {
+	return errors.New("storage error")
+}
+
+func (f *failingStorage) GetScriptRevision(ctx context.Context, name string, revision int) (storage.ScriptRevision, bool, error) {
+	return storage.ScriptRevision{}, false, errors.New("storage error")
+}
+
+func (f *failingStorage) ListScriptRevisions(ctx context.Context, name string) ([]storage.ScriptRevision, error) {
+	return nil, errors.New("storage error")
+}
+
+func (f *failingStorage) SaveVolumeSnapshot(ctx context.Context, snapshot storage.VolumeSnapshot) error {
+	return errors.New("storage error")
+}
+
+func (f *failingStorage) GetVolumeSnapshots(ctx context.Context, operationID string) ([]storage.VolumeSnapshot, error) {
+	return nil, errors.New("storage error")
+}
+
+func (f *failingStorage) ListVolumeSnapshots(ctx context.Context, volumeName string) ([]storage.VolumeSnapshot, error) {
+	return nil, errors.New("storage error")
+}
+
+func (f *failingStorage) DeleteVolumeSnapshot(ctx context.Context, id int64) error {
+	return errors.New("storage error")
+}
+
+func (f *failingStorage) GetApprovalPolicy(ctx context.Context, entityType, entityID string) (storage.ApprovalPolicy, bool, error) {
+	return storage.ApprovalPolicy{}, false, errors.New("storage error")
+}
+
+func (f *failingStorage) SetApprovalPolicy(ctx context.Context, policy storage.ApprovalPolicy) error {
+	return errors.New("storage error")
+}
+
+func (f *failingStorage) ListApprovalPolicies(ctx context.Context) ([]storage.ApprovalPolicy, error) {
+	return nil, errors.New("storage error")
+}
+
+func (f *failingStorage) DeleteApprovalPolicy(ctx context.Context, entityType, entityID string) error {
+	return errors.New("storage error")
+}
+
+func (f *failingStorage) AppendOperationLog(ctx context.Context, entry storage.OperationLogEntry) (int64, error) {
+	return 0, errors.New("storage error")
+}
+
+func (f *failingStorage) GetOperationLogs(ctx context.Context, operationID string, afterID int64) ([]storage.OperationLogEntry, error) {
+	return nil, errors.New("storage error")
+}
+
+func (f *failingStorage) AppendComposeOutput(ctx context.Context, operationID string, output string) error {
+	return errors.New("storage error")
+}
+
+func (f *failingStorage) ListIgnoreRules(ctx context.Context) ([]storage.IgnoreRule, error) {
+	return nil, errors.New("storage error")
+}
+
+func (f *failingStorage) SaveIgnoreRule(ctx context.Context, rule storage.IgnoreRule) (storage.IgnoreRule, error) {
+	return rule, errors.New("storage error")
+}
+
+func (f *failingStorage) DeleteIgnoreRule(ctx context.Context, id int64) (bool, error) {
+	return false, errors.New("storage error")
+}
+
+func (f *failingStorage) GetStackEnv(ctx context.Context, stack string) (storage.StackEnv, bool, error) {
+	return storage.StackEnv{}, false, errors.New("storage error")
+}
+
+func (f *failingStorage) SetStackEnv(ctx context.Context, env storage.StackEnv) error {
+	return errors.New("storage error")
+}
+
+func (f *failingStorage) ListStackEnvs(ctx context.Context) ([]storage.StackEnv, error) {
+	return nil, errors.New("storage error")
+}
+
+func (f *failingStorage) DeleteStackEnv(ctx context.Context, stack string) (bool, error) {
+	return false, errors.New("storage error")
+}
+
+func (f *failingStorage) ListOwnerships(ctx context.Context) ([]storage.Ownership, error) {
+	return nil, errors.New("storage error")
+}
+
+func (f *failingStorage) AddOwnership(ctx context.Context, ownership storage.Ownership) error {
+	return errors.New("storage error")
+}
+
+func (f *failingStorage) RemoveOwnership(ctx context.Context, entityType, entityID, owner string) (bool, error) {
+	return false, errors.New("storage error")
+}
+
+func (f *failingStorage) ListTeamMembers(ctx context.Context) ([]storage.TeamMember, error) {
+	return nil, errors.New("storage error")
+}
+
+func (f *failingStorage) AddTeamMember(ctx context.Context, team, username string) error {
+	return errors.New("storage error")
+}
+
+func (f *failingStorage) RemoveTeamMember(ctx context.Context, team, username string) (bool, error) {
+	return false, errors.New("storage error")
+}
+
+func (f *failingStorage) CheckWritable(ctx context.Context) error {
+	return errors.New("storage error")
+}
+
+func (f *failingStorage) SaveStackLock(ctx context.Context, lock storage.StackLock) error {
+	return errors.New("storage error")
+}
+
+func (f *failingStorage) DeleteStackLock(ctx context.Context, stackName, operationID string) error {
+	return errors.New("storage error")
+}
+
+func (f *failingStorage) ListStackLocks(ctx context.Context) ([]storage.StackLock, error) {
+	return nil, errors.New("storage error")
+}
+
+func (f *failingStorage) SetQueuePriorities(ctx context.Context, priorities map[string]int) error {
+	return errors.New("storage error")
+}
+
+func (f *failingStorage) DeleteQueuedUpdate(ctx context.Context, operationID string) (bool, error) {
+	return false, errors.New("storage error")
+}
+
 // mockDockerClient is a mock implementation for testing
 type mockDockerClient struct {
 	containers    []docker.Container
@@ -711,7 +1356,11 @@ func (m *mockDockerClient) Close() error {
 type mockRegistryClient struct {
 	tags                     map[string][]string
 	tagDigests               map[string]string
-	digestMappings           map[string]map[string][]string // imageRef -> tag -> []digests
+	digestMappings           map[string]map[string][]string       // imageRef -> tag -> []digests
+	sizes                    map[string]int64                     // imageRef:reference -> compressed size
+	platforms                map[string][]string                  // imageRef:reference -> platforms
+	platformDigests          map[string]string                    // imageRef:reference -> host platform manifest digest
+	signatures               map[string]*registry.ImageSignatures // imageRef@digest -> signatures
 	listTagsWithDigestsCalls int
 }
 
@@ -738,6 +1387,38 @@ func (m *mockRegistryClient) GetLatestTag(ctx context.Context, imageRef string)
 
 func (m *mockRegistryClient) GetGhostTags(imageRef string) []string { return nil }
 
+func (m *mockRegistryClient) GetImageSize(ctx context.Context, imageRef, reference string) (int64, error) {
+	size, ok := m.sizes[imageRef+":"+reference]
+	if !ok {
+		return 0, errors.New("image size not found")
+	}
+	return size, nil
+}
+
+func (m *mockRegistryClient) GetImageLayers(ctx context.Context, imageRef, reference string) ([]registry.ImageLayer, error) {
+	return nil, errors.New("image layers not available")
+}
+
+func (m *mockRegistryClient) GetImagePlatforms(ctx context.Context, imageRef, reference string) ([]string, error) {
+	return m.platforms[imageRef+":"+reference], nil
+}
+
+func (m *mockRegistryClient) GetPlatformDigest(ctx context.Context, imageRef, reference string) (string, error) {
+	digest, ok := m.platformDigests[imageRef+":"+reference]
+	if !ok {
+		return "", errors.New("platform digest not found")
+	}
+	return digest, nil
+}
+
+func (m *mockRegistryClient) GetSignatures(ctx context.Context, imageRef, digest string) (*registry.ImageSignatures, error) {
+	sigs, ok := m.signatures[imageRef+"@"+digest]
+	if !ok {
+		return &registry.ImageSignatures{}, nil
+	}
+	return sigs, nil
+}
+
 func (m *mockRegistryClient) ListTagsWithDigests(ctx context.Context, imageRef string) (map[string][]string, error) {
 	m.listTagsWithDigestsCalls++
 	mappings, ok := m.digestMappings[imageRef]
@@ -812,3 +1493,55 @@ func TestLatestContainerDateTagResolution(t *testing.T) {
 			update.LatestResolvedVersion, "2026.2.9")
 	}
 }
+
+// TestCheckerMatchesPlatformDigest tests that a local image recording the host
+// platform's child manifest is not reported as outdated against the index digest
+func TestCheckerMatchesPlatformDigest(t *testing.T) {
+	mockDocker := &mockDockerClient{
+		containers: []docker.Container{
+			{
+				ID:     "test-container",
+				Name:   "test",
+				Image:  "docker.io/library/nginx:latest",
+				Labels: map[string]string{scripts.AllowLatestLabel: "true"},
+			},
+		},
+		imageDigests: map[string]string{
+			"docker.io/library/nginx:latest": "sha256:armchild",
+		},
+		imageVersions: map[string]string{},
+		localImages:   map[string]bool{},
+	}
+
+	mockRegistry := &mockRegistryClient{
+		tags: map[string][]string{
+			"docker.io/library/nginx": {"latest"},
+		},
+		tagDigests: map[string]string{
+			"docker.io/library/nginx:latest": "sha256:index",
+		},
+		digestMappings: map[string]map[string][]string{},
+		platformDigests: map[string]string{
+			"docker.io/library/nginx:latest": "sha256:armchild",
+		},
+	}
+
+	checker := NewChecker(mockDocker, mockRegistry, nil)
+	result, err := checker.CheckForUpdates(context.Background())
+	if err != nil {
+		t.Fatalf("CheckForUpdates failed: %v", err)
+	}
+	if result.Updates[0].Status != UpToDate {
+		t.Errorf("Expected UP_TO_DATE, got %s", result.Updates[0].Status)
+	}
+
+	// A new image for the host platform is still an update
+	mockRegistry.platformDigests["docker.io/library/nginx:latest"] = "sha256:newarmchild"
+	result, err = checker.CheckForUpdates(context.Background())
+	if err != nil {
+		t.Fatalf("CheckForUpdates failed: %v", err)
+	}
+	if result.Updates[0].Status != UpdateAvailable {
+		t.Errorf("Expected UPDATE_AVAILABLE, got %s", result.Updates[0].Status)
+	}
+}
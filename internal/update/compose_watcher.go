@@ -0,0 +1,240 @@
+package update
+
+import (
+	"context"
+	"crypto/sha256"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chis/docksmith/internal/compose"
+	"github.com/chis/docksmith/internal/docker"
+	"github.com/chis/docksmith/internal/events"
+)
+
+const (
+	composeWatchRefreshInterval = time.Minute // How often the watched files are updated from the containers
+	composeChangeDebounce       = time.Second // Editors can write a file several times when saving it
+)
+
+// dirWatcher reports the paths of files written or moved into watched directories.
+// Directories are watched rather than files so atomic saves (write and rename) are seen.
+type dirWatcher interface {
+	Add(dir string) error
+	Remove(dir string) error
+	Events() <-chan string
+	Close() error
+}
+
+// ComposeWatcher watches the compose files of the containers and, when one is edited
+// outside Docksmith, drops the cached check results of its containers, publishes an
+// EventComposeChanged event, and triggers a check, so compose mismatches and manual
+// edits show up without waiting for the next scheduled check.
+type ComposeWatcher struct {
+	dockerClient   docker.Client
+	orchestrator   *Orchestrator
+	checker        *BackgroundChecker
+	eventBus       *events.Bus
+	pathTranslator *docker.PathTranslator
+
+	refreshInterval time.Duration
+	debounce        time.Duration
+
+	watcher  dirWatcher
+	stopChan chan struct{}
+
+	mu     sync.Mutex
+	files  map[string][]docker.Container // Watched compose file -> containers defined in it
+	sums   map[string][sha256.Size]byte  // Last seen content of each watched file
+	dirs   map[string]bool               // Watched directories
+	timers map[string]*time.Timer        // Pending debounced changes
+}
+
+// NewComposeWatcher creates a compose file watcher. checker, eventBus, and
+// pathTranslator may be nil.
+func NewComposeWatcher(dockerClient docker.Client, orchestrator *Orchestrator, checker *BackgroundChecker, eventBus *events.Bus, pathTranslator *docker.PathTranslator) *ComposeWatcher {
+	return &ComposeWatcher{
+		dockerClient:    dockerClient,
+		orchestrator:    orchestrator,
+		checker:         checker,
+		eventBus:        eventBus,
+		pathTranslator:  pathTranslator,
+		refreshInterval: composeWatchRefreshInterval,
+		debounce:        composeChangeDebounce,
+		files:           make(map[string][]docker.Container),
+		sums:            make(map[string][sha256.Size]byte),
+		dirs:            make(map[string]bool),
+		timers:          make(map[string]*time.Timer),
+	}
+}
+
+// Start begins watching. Returns an error if file watching is not available.
+func (w *ComposeWatcher) Start() error {
+	watcher, err := newDirWatcher()
+	if err != nil {
+		return err
+	}
+	w.watcher = watcher
+	w.stopChan = make(chan struct{})
+
+	w.refresh()
+	go w.watchLoop()
+	return nil
+}
+
+// Stop stops watching.
+func (w *ComposeWatcher) Stop() {
+	if w.watcher == nil {
+		return
+	}
+	close(w.stopChan)
+	w.watcher.Close()
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for path, timer := range w.timers {
+		timer.Stop()
+		delete(w.timers, path)
+	}
+}
+
+// watchLoop handles file events and periodically updates the watched files.
+func (w *ComposeWatcher) watchLoop() {
+	ticker := time.NewTicker(w.refreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.stopChan:
+			return
+		case <-ticker.C:
+			w.refresh()
+		case path, ok := <-w.watcher.Events():
+			if !ok {
+				return
+			}
+			w.scheduleChange(path)
+		}
+	}
+}
+
+// refresh watches the compose files named in the containers' compose labels.
+func (w *ComposeWatcher) refresh() {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	containers, err := w.dockerClient.ListContainers(ctx)
+	if err != nil {
+		log.Printf("COMPOSE_WATCHER: Failed to list containers: %v", err)
+		return
+	}
+
+	files := make(map[string][]docker.Container)
+	for _, c := range containers {
+		for _, file := range strings.Split(c.Labels["com.docker.compose.project.config_files"], ",") {
+			if file = strings.TrimSpace(file); file == "" {
+				continue
+			}
+			if w.pathTranslator != nil {
+				file = w.pathTranslator.TranslateToContainer(file)
+			}
+			files[file] = append(files[file], c)
+		}
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	dirs := make(map[string]bool)
+	for path := range files {
+		dirs[filepath.Dir(path)] = true
+		if _, known := w.sums[path]; !known {
+			if data, err := os.ReadFile(path); err == nil {
+				w.sums[path] = sha256.Sum256(data)
+			}
+		}
+	}
+	for path := range w.sums {
+		if _, ok := files[path]; !ok {
+			delete(w.sums, path)
+		}
+	}
+	for dir := range dirs {
+		if w.dirs[dir] {
+			continue
+		}
+		if err := w.watcher.Add(dir); err != nil {
+			log.Printf("COMPOSE_WATCHER: %v", err)
+			delete(dirs, dir)
+		}
+	}
+	for dir := range w.dirs {
+		if !dirs[dir] {
+			w.watcher.Remove(dir)
+		}
+	}
+	w.files, w.dirs = files, dirs
+}
+
+// scheduleChange handles a change to a watched file once it has not changed for the debounce period.
+func (w *ComposeWatcher) scheduleChange(path string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if _, watched := w.files[path]; !watched {
+		return
+	}
+	if timer, ok := w.timers[path]; ok {
+		timer.Stop()
+	}
+	w.timers[path] = time.AfterFunc(w.debounce, func() { w.handleChange(path) })
+}
+
+// handleChange re-checks the containers of a compose file whose content was changed
+// outside Docksmith. Docksmith's own edits are already followed by a check.
+func (w *ComposeWatcher) handleChange(path string) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return // Removed or being replaced; the next write is seen again
+	}
+	sum := sha256.Sum256(data)
+
+	w.mu.Lock()
+	delete(w.timers, path)
+	containers := w.files[path]
+	unchanged := w.sums[path] == sum
+	w.sums[path] = sum
+	w.mu.Unlock()
+
+	if unchanged || len(containers) == 0 || compose.WrittenByDocksmith(path, data) {
+		return
+	}
+
+	names := make([]string, 0, len(containers))
+	for _, c := range containers {
+		names = append(names, c.Name)
+	}
+	stack := containers[0].Labels["com.docker.compose.project"]
+	log.Printf("COMPOSE_WATCHER: %s changed outside Docksmith, re-checking %s", path, strings.Join(names, ", "))
+
+	w.orchestrator.InvalidateContainers(containers)
+
+	if w.eventBus != nil {
+		w.eventBus.Publish(events.Event{
+			Type: events.EventComposeChanged,
+			Payload: map[string]interface{}{
+				"compose_file": path,
+				"stack":        stack,
+				"containers":   names,
+				"timestamp":    time.Now().Unix(),
+			},
+		})
+	}
+
+	if w.checker != nil && !w.checker.IsPaused() {
+		w.checker.TriggerCheck()
+	}
+}
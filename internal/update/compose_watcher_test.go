@@ -0,0 +1,100 @@
+package update
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/chis/docksmith/internal/compose"
+	"github.com/chis/docksmith/internal/docker"
+	"github.com/chis/docksmith/internal/events"
+)
+
+func TestComposeWatcher(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "docker-compose.yml")
+	if err := os.WriteFile(path, []byte("services:\n  web:\n    image: nginx:1.25\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	mockDocker := &MockDockerClient{containers: []docker.Container{{
+		ID:    "abc123def4567890",
+		Name:  "web",
+		Image: "nginx:1.25",
+		Labels: map[string]string{
+			"com.docker.compose.project":              "site",
+			"com.docker.compose.project.config_files": path,
+		},
+	}}}
+	bus := events.NewBus()
+	eventChan, unsub := bus.Subscribe(events.EventComposeChanged)
+	defer unsub()
+
+	watcher := NewComposeWatcher(mockDocker, NewOrchestrator(mockDocker, &mockRegistryManager{}), nil, bus, nil)
+	watcher.debounce = 10 * time.Millisecond
+	if err := watcher.Start(); err != nil {
+		t.Skipf("file watching not available: %v", err)
+	}
+	defer watcher.Stop()
+
+	expectEvent := func(want bool) {
+		t.Helper()
+		select {
+		case event := <-eventChan:
+			if !want {
+				t.Fatalf("unexpected event: %v", event.Payload)
+			}
+			if event.Payload["stack"] != "site" || event.Payload["compose_file"] != path {
+				t.Errorf("unexpected payload: %v", event.Payload)
+			}
+		case <-time.After(500 * time.Millisecond):
+			if want {
+				t.Fatal("expected a compose.changed event")
+			}
+		}
+	}
+
+	t.Run("external edit", func(t *testing.T) {
+		if err := os.WriteFile(path, []byte("services:\n  web:\n    image: nginx:1.26\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		expectEvent(true)
+	})
+
+	t.Run("unchanged content", func(t *testing.T) {
+		if err := os.WriteFile(path, []byte("services:\n  web:\n    image: nginx:1.26\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		expectEvent(false)
+	})
+
+	t.Run("atomic save", func(t *testing.T) {
+		tmp := filepath.Join(dir, ".docker-compose.yml.swp")
+		if err := os.WriteFile(tmp, []byte("services:\n  web:\n    image: nginx:1.27\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Rename(tmp, path); err != nil {
+			t.Fatal(err)
+		}
+		expectEvent(true)
+	})
+
+	t.Run("docksmith edit", func(t *testing.T) {
+		cf, err := compose.LoadComposeFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		svc, err := cf.FindServiceByContainerName("web")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := svc.SetLabel("docksmith.ignore", "true"); err != nil {
+			t.Fatal(err)
+		}
+		if err := cf.Save(); err != nil {
+			t.Fatal(err)
+		}
+		expectEvent(false)
+	})
+}
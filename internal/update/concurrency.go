@@ -0,0 +1,70 @@
+package update
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+)
+
+// SetMaxConcurrent limits how many image pulls and container recreations run at
+// once across all stacks. Zero or less removes the limit. Must be called before
+// any update starts.
+func (o *UpdateOrchestrator) SetMaxConcurrent(limit int) {
+	if limit <= 0 {
+		o.updateSlots = nil
+		return
+	}
+	o.updateSlots = make(chan struct{}, limit)
+}
+
+// MaxConcurrentFromEnv reads the global update concurrency limit from
+// MAX_CONCURRENT_UPDATES. Returns 0 (unlimited) when unset or invalid.
+func MaxConcurrentFromEnv() int {
+	value := os.Getenv("MAX_CONCURRENT_UPDATES")
+	if value == "" {
+		return 0
+	}
+	limit, err := strconv.Atoi(value)
+	if err != nil || limit < 0 {
+		log.Printf("Warning: Invalid MAX_CONCURRENT_UPDATES '%s', not limiting concurrent updates", value)
+		return 0
+	}
+	log.Printf("Using MAX_CONCURRENT_UPDATES: %d", limit)
+	return limit
+}
+
+// acquireUpdateSlot blocks until a global update slot is free or ctx is done.
+// The returned function releases the slot.
+func (o *UpdateOrchestrator) acquireUpdateSlot(ctx context.Context) (func(), error) {
+	slots := o.updateSlots
+	if slots == nil {
+		return func() {}, nil
+	}
+	release := func() { <-slots }
+
+	select {
+	case slots <- struct{}{}:
+		return release, nil
+	default:
+	}
+
+	log.Printf("UPDATE: All %d update slots in use, waiting", cap(slots))
+	select {
+	case slots <- struct{}{}:
+		return release, nil
+	case <-ctx.Done():
+		return nil, fmt.Errorf("failed waiting for an update slot: %w", ctx.Err())
+	}
+}
+
+// withUpdateSlot runs fn while holding a global update slot.
+func (o *UpdateOrchestrator) withUpdateSlot(ctx context.Context, fn func() error) error {
+	release, err := o.acquireUpdateSlot(ctx)
+	if err != nil {
+		return err
+	}
+	defer release()
+	return fn()
+}
@@ -0,0 +1,80 @@
+package update
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMaxConcurrentFromEnv(t *testing.T) {
+	t.Setenv("MAX_CONCURRENT_UPDATES", "")
+	assert.Equal(t, 0, MaxConcurrentFromEnv())
+
+	t.Setenv("MAX_CONCURRENT_UPDATES", "3")
+	assert.Equal(t, 3, MaxConcurrentFromEnv())
+
+	t.Setenv("MAX_CONCURRENT_UPDATES", "many")
+	assert.Equal(t, 0, MaxConcurrentFromEnv())
+
+	t.Setenv("MAX_CONCURRENT_UPDATES", "-1")
+	assert.Equal(t, 0, MaxConcurrentFromEnv())
+}
+
+func TestWithUpdateSlot_LimitsConcurrency(t *testing.T) {
+	orch := &UpdateOrchestrator{}
+	orch.SetMaxConcurrent(2)
+
+	var running, peak int32
+	var wg sync.WaitGroup
+	for i := 0; i < 6; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := orch.withUpdateSlot(context.Background(), func() error {
+				n := atomic.AddInt32(&running, 1)
+				for {
+					p := atomic.LoadInt32(&peak)
+					if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+						break
+					}
+				}
+				time.Sleep(20 * time.Millisecond)
+				atomic.AddInt32(&running, -1)
+				return nil
+			})
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(2), peak)
+}
+
+func TestAcquireUpdateSlot(t *testing.T) {
+	orch := &UpdateOrchestrator{}
+
+	// Unlimited by default
+	for i := 0; i < 10; i++ {
+		_, err := orch.acquireUpdateSlot(context.Background())
+		require.NoError(t, err)
+	}
+
+	orch.SetMaxConcurrent(1)
+	release, err := orch.acquireUpdateSlot(context.Background())
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err = orch.acquireUpdateSlot(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded, "a cancelled wait gives up")
+
+	release()
+	release, err = orch.acquireUpdateSlot(context.Background())
+	require.NoError(t, err, "released slot can be reused")
+	release()
+}
@@ -0,0 +1,155 @@
+package update
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/chis/docksmith/internal/version"
+)
+
+// ConditionType names an aspect of a container's check result.
+type ConditionType string
+
+const (
+	ConditionUpdateAvailable ConditionType = "UpdateAvailable" // A newer version or image is available
+	ConditionPinRecommended  ConditionType = "PinRecommended"  // The container runs :latest and a version tag can be pinned
+	ConditionPreCheckBlocked ConditionType = "PreCheckBlocked" // The pre-update check blocks the update
+	ConditionArchUnsupported ConditionType = "ArchUnsupported" // The newer version has no image for the host architecture
+	ConditionMetadataStale   ConditionType = "MetadataStale"   // Registry metadata could not be refreshed
+	ConditionComposeMismatch ConditionType = "ComposeMismatch" // The running image differs from the compose file
+	ConditionEndOfLife       ConditionType = "EndOfLife"       // The release cycle of the current version reached its end of life
+)
+
+// ConditionStatus is whether a condition holds.
+type ConditionStatus string
+
+const (
+	ConditionTrue    ConditionStatus = "True"
+	ConditionFalse   ConditionStatus = "False"
+	ConditionUnknown ConditionStatus = "Unknown" // The check could not tell
+)
+
+// Condition is one typed aspect of a container's check result, like the status
+// conditions of Kubernetes objects. Every checked container reports each type, so
+// scripts can select on type and status instead of parsing Status.
+type Condition struct {
+	Type               ConditionType   `json:"type"`
+	Status             ConditionStatus `json:"status"`
+	Reason             string          `json:"reason"` // CamelCase cause of the status, e.g. NewerVersion
+	Message            string          `json:"message,omitempty"`
+	LastTransitionTime time.Time       `json:"last_transition_time"` // When the status last changed, as seen by this process
+}
+
+// setConditions sets the conditions of a check result. Conditions whose status did
+// not change since the container's previous check keep their transition time.
+func (c *Checker) setConditions(update *ContainerUpdate) {
+	now := time.Now()
+	conditions := buildConditions(*update, now)
+
+	c.conditionsMu.Lock()
+	defer c.conditionsMu.Unlock()
+	if c.lastConditions == nil {
+		c.lastConditions = make(map[string][]Condition)
+	}
+	for i, condition := range conditions {
+		for _, previous := range c.lastConditions[update.ContainerName] {
+			if previous.Type == condition.Type && previous.Status == condition.Status && !previous.LastTransitionTime.IsZero() {
+				conditions[i].LastTransitionTime = previous.LastTransitionTime
+			}
+		}
+	}
+	c.lastConditions[update.ContainerName] = conditions
+	update.Conditions = conditions
+}
+
+// buildConditions derives the conditions of a check result.
+func buildConditions(update ContainerUpdate, now time.Time) []Condition {
+	condition := func(t ConditionType, status ConditionStatus, reason, message string) Condition {
+		return Condition{Type: t, Status: status, Reason: reason, Message: message, LastTransitionTime: now}
+	}
+	failed := update.Status == MetadataUnavailable || update.Status == CheckFailed || update.Status == Unknown
+
+	var available Condition
+	switch update.Status {
+	case UpdateAvailable, UpdateAvailableBlocked:
+		if update.LatestVersion != "" && update.LatestVersion != update.CurrentVersion {
+			message := update.LatestVersion + " is available"
+			switch update.ChangeType {
+			case version.PatchChange, version.MinorChange, version.MajorChange:
+				message += fmt.Sprintf(" (%s)", update.ChangeType)
+			}
+			available = condition(ConditionUpdateAvailable, ConditionTrue, "NewerVersion", message)
+		} else {
+			available = condition(ConditionUpdateAvailable, ConditionTrue, "NewerImage", "A newer image was published for "+update.CurrentTag)
+		}
+	case UpdateUnavailableArch:
+		available = condition(ConditionUpdateAvailable, ConditionFalse, "ArchUnsupported", update.Error)
+	case LocalImage:
+		available = condition(ConditionUpdateAvailable, ConditionFalse, "LocalImage", "The image is not from a registry")
+	case ComposeMismatch:
+		available = condition(ConditionUpdateAvailable, ConditionUnknown, "ComposeMismatch", "Not checked until the running image matches the compose file")
+	default:
+		if failed {
+			available = condition(ConditionUpdateAvailable, ConditionUnknown, "CheckFailed", update.Error)
+		} else {
+			available = condition(ConditionUpdateAvailable, ConditionFalse, "UpToDate", "")
+		}
+	}
+
+	pin := condition(ConditionPinRecommended, ConditionFalse, "VersionTag", "")
+	switch {
+	case update.UsingLatestTag && update.RecommendedTag != "":
+		pin = condition(ConditionPinRecommended, ConditionTrue, "UsingLatestTag", "Pin to "+update.RecommendedTag)
+	case update.UsingLatestTag:
+		pin.Reason = "NoVersionTag"
+	}
+
+	blocked := condition(ConditionPreCheckBlocked, ConditionFalse, "NoPreUpdateCheck", "")
+	switch {
+	case update.Status == UpdateAvailableBlocked:
+		blocked = condition(ConditionPreCheckBlocked, ConditionTrue, "PreUpdateCheckFailed", update.PreUpdateCheckFail)
+	case update.PreUpdateCheckPass:
+		blocked.Reason = "PreUpdateCheckPassed"
+	case update.PreUpdateCheck != "":
+		blocked.Reason = "PreUpdateCheckNotRun"
+	}
+
+	arch := condition(ConditionArchUnsupported, ConditionFalse, "Supported", "")
+	if update.Status == UpdateUnavailableArch {
+		arch = condition(ConditionArchUnsupported, ConditionTrue, "NoImageForArchitecture", update.Error)
+	}
+
+	stale := condition(ConditionMetadataStale, ConditionFalse, "Refreshed", "")
+	switch {
+	case update.TimedOut:
+		stale = condition(ConditionMetadataStale, ConditionTrue, "CheckTimedOut", update.Error)
+	case update.Deferred:
+		stale = condition(ConditionMetadataStale, ConditionTrue, "RateLimited", update.Error)
+	case update.Status == CheckFailed:
+		stale = condition(ConditionMetadataStale, ConditionTrue, "CheckFailed", update.Error)
+	case failed:
+		stale = condition(ConditionMetadataStale, ConditionTrue, "RegistryUnavailable", update.Error)
+	}
+
+	mismatch := condition(ConditionComposeMismatch, ConditionFalse, "Matches", "")
+	if update.Status == ComposeMismatch {
+		mismatch = condition(ConditionComposeMismatch, ConditionTrue, "ImageDiffers", update.Error)
+	}
+
+	endOfLife := condition(ConditionEndOfLife, ConditionUnknown, "NoLifecycleData", "")
+	if lifecycle := update.EndOfLife; lifecycle != nil {
+		name := lifecycle.Product + " " + lifecycle.Cycle
+		switch {
+		case lifecycle.EOL && lifecycle.EOLDate != "":
+			endOfLife = condition(ConditionEndOfLife, ConditionTrue, "CycleEnded", fmt.Sprintf("%s reached end of life on %s (newest: %s)", name, lifecycle.EOLDate, lifecycle.LatestCycle))
+		case lifecycle.EOL:
+			endOfLife = condition(ConditionEndOfLife, ConditionTrue, "CycleEnded", fmt.Sprintf("%s reached end of life (newest: %s)", name, lifecycle.LatestCycle))
+		case lifecycle.EOLDate != "":
+			endOfLife = condition(ConditionEndOfLife, ConditionFalse, "Supported", fmt.Sprintf("%s is supported until %s", name, lifecycle.EOLDate))
+		default:
+			endOfLife = condition(ConditionEndOfLife, ConditionFalse, "Supported", name+" is supported")
+		}
+	}
+
+	return []Condition{available, pin, blocked, arch, stale, mismatch, endOfLife}
+}
@@ -0,0 +1,126 @@
+package update
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/chis/docksmith/internal/version"
+)
+
+// conditionOf returns the condition of the given type.
+func conditionOf(t *testing.T, conditions []Condition, conditionType ConditionType) Condition {
+	t.Helper()
+	for _, condition := range conditions {
+		if condition.Type == conditionType {
+			return condition
+		}
+	}
+	t.Fatalf("no %s condition in %+v", conditionType, conditions)
+	return Condition{}
+}
+
+func TestBuildConditions(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name   string
+		update ContainerUpdate
+		want   map[ConditionType]string // type → status/reason
+	}{
+		{
+			name:   "update available",
+			update: ContainerUpdate{Status: UpdateAvailable, CurrentVersion: "1.24.0", LatestVersion: "1.25.0", ChangeType: version.MinorChange},
+			want: map[ConditionType]string{
+				ConditionUpdateAvailable: "True/NewerVersion",
+				ConditionPreCheckBlocked: "False/NoPreUpdateCheck",
+				ConditionMetadataStale:   "False/Refreshed",
+			},
+		},
+		{
+			name:   "blocked by the pre-update check",
+			update: ContainerUpdate{Status: UpdateAvailableBlocked, CurrentVersion: "1.24.0", LatestVersion: "1.25.0", PreUpdateCheck: "/scripts/check.sh", PreUpdateCheckFail: "users are streaming"},
+			want: map[ConditionType]string{
+				ConditionUpdateAvailable: "True/NewerVersion",
+				ConditionPreCheckBlocked: "True/PreUpdateCheckFailed",
+			},
+		},
+		{
+			name:   "pinnable",
+			update: ContainerUpdate{Status: UpToDatePinnable, UsingLatestTag: true, RecommendedTag: "1.25.0"},
+			want: map[ConditionType]string{
+				ConditionUpdateAvailable: "False/UpToDate",
+				ConditionPinRecommended:  "True/UsingLatestTag",
+			},
+		},
+		{
+			name:   "architecture unsupported",
+			update: ContainerUpdate{Status: UpdateUnavailableArch, Error: "2.0.0 has no image for linux/arm64"},
+			want: map[ConditionType]string{
+				ConditionUpdateAvailable: "False/ArchUnsupported",
+				ConditionArchUnsupported: "True/NoImageForArchitecture",
+			},
+		},
+		{
+			name:   "timed out",
+			update: ContainerUpdate{Status: MetadataUnavailable, TimedOut: true, Error: "Check timed out after 20s"},
+			want: map[ConditionType]string{
+				ConditionUpdateAvailable: "Unknown/CheckFailed",
+				ConditionMetadataStale:   "True/CheckTimedOut",
+			},
+		},
+		{
+			name: "end of life",
+			update: ContainerUpdate{Status: UpToDate, CurrentVersion: "12.22",
+				EndOfLife: &EndOfLife{Product: "postgresql", Cycle: "12", EOL: true, EOLDate: "2024-11-21", LatestCycle: "17"}},
+			want: map[ConditionType]string{
+				ConditionUpdateAvailable: "False/UpToDate",
+				ConditionEndOfLife:       "True/CycleEnded",
+			},
+		},
+		{
+			name:   "compose mismatch",
+			update: ContainerUpdate{Status: ComposeMismatch, ComposeImage: "nginx:1.25.0"},
+			want: map[ConditionType]string{
+				ConditionUpdateAvailable: "Unknown/ComposeMismatch",
+				ConditionComposeMismatch: "True/ImageDiffers",
+				ConditionEndOfLife:       "Unknown/NoLifecycleData",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conditions := buildConditions(tt.update, now)
+			assert.Len(t, conditions, 7)
+			for conditionType, want := range tt.want {
+				condition := conditionOf(t, conditions, conditionType)
+				assert.Equal(t, want, string(condition.Status)+"/"+condition.Reason, conditionType)
+				assert.Equal(t, now, condition.LastTransitionTime)
+			}
+		})
+	}
+}
+
+func TestSetConditions_KeepsTransitionTimes(t *testing.T) {
+	checker := &Checker{}
+
+	first := ContainerUpdate{ContainerName: "web", Status: UpToDate}
+	checker.setConditions(&first)
+	upToDateSince := conditionOf(t, first.Conditions, ConditionUpdateAvailable).LastTransitionTime
+	time.Sleep(time.Millisecond)
+
+	second := ContainerUpdate{ContainerName: "web", Status: UpdateAvailable, LatestVersion: "1.25.0"}
+	checker.setConditions(&second)
+	available := conditionOf(t, second.Conditions, ConditionUpdateAvailable)
+	require.Equal(t, ConditionTrue, available.Status)
+	assert.True(t, available.LastTransitionTime.After(upToDateSince), "the status changed")
+	assert.Equal(t, conditionOf(t, first.Conditions, ConditionMetadataStale).LastTransitionTime,
+		conditionOf(t, second.Conditions, ConditionMetadataStale).LastTransitionTime, "the status did not change")
+
+	// Containers are tracked separately
+	other := ContainerUpdate{ContainerName: "db", Status: UpdateAvailable, LatestVersion: "17.1"}
+	checker.setConditions(&other)
+	assert.NotEqual(t, available.LastTransitionTime, conditionOf(t, other.Conditions, ConditionUpdateAvailable).LastTransitionTime)
+}
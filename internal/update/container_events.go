@@ -0,0 +1,264 @@
+package update
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+
+	dockerevents "github.com/docker/docker/api/types/events"
+	"github.com/docker/docker/api/types/filters"
+
+	"github.com/chis/docksmith/internal/docker"
+	"github.com/chis/docksmith/internal/events"
+)
+
+const (
+	containerEventDebounce   = 3 * time.Second  // Compose creates and removes whole stacks at once
+	containerEventMaxBackoff = 30 * time.Second // Longest wait before reconnecting to the event stream
+)
+
+// ErrContainerRemoved is the cause of a canceled operation whose target container
+// was removed before it was recreated.
+var ErrContainerRemoved = errors.New("container was removed")
+
+// containerEventSource streams Docker events, implemented by the Docker SDK client.
+type containerEventSource interface {
+	Events(ctx context.Context, options dockerevents.ListOptions) (<-chan dockerevents.Message, <-chan error)
+}
+
+// ContainerEventWatcher follows the Docker events of containers (create, start, die,
+// destroy) and publishes an EventContainerChanged event for each, so the dashboard
+// is refreshed as containers change instead of on its next poll. Created and removed
+// containers drop their cached check results and trigger a check, which updates the
+// container list and dependency graph. Running updates whose container is removed
+// fail instead of recreating it.
+type ContainerEventWatcher struct {
+	source       containerEventSource
+	orchestrator *Orchestrator
+	checker      *BackgroundChecker
+	updates      *UpdateOrchestrator
+	eventBus     *events.Bus
+
+	debounce   time.Duration
+	maxBackoff time.Duration
+
+	stopChan chan struct{}
+	done     chan struct{}
+
+	mu           sync.Mutex
+	refreshTimer *time.Timer
+	lastEvent    time.Time // Time of the last event, to resume the stream after a reconnect
+}
+
+// NewContainerEventWatcher creates a container event watcher. checker, updates, and
+// eventBus may be nil.
+func NewContainerEventWatcher(source containerEventSource, orchestrator *Orchestrator, checker *BackgroundChecker, updates *UpdateOrchestrator, eventBus *events.Bus) *ContainerEventWatcher {
+	return &ContainerEventWatcher{
+		source:       source,
+		orchestrator: orchestrator,
+		checker:      checker,
+		updates:      updates,
+		eventBus:     eventBus,
+		debounce:     containerEventDebounce,
+		maxBackoff:   containerEventMaxBackoff,
+	}
+}
+
+// Start begins following container events.
+func (w *ContainerEventWatcher) Start() {
+	w.stopChan = make(chan struct{})
+	w.done = make(chan struct{})
+	go w.watchLoop()
+}
+
+// Stop stops following container events.
+func (w *ContainerEventWatcher) Stop() {
+	if w.stopChan == nil {
+		return
+	}
+	close(w.stopChan)
+	<-w.done
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.refreshTimer != nil {
+		w.refreshTimer.Stop()
+		w.refreshTimer = nil
+	}
+}
+
+// watchLoop follows the event stream, reconnecting with a growing backoff when it
+// fails. A reconnected stream resumes from the last event seen.
+func (w *ContainerEventWatcher) watchLoop() {
+	defer close(w.done)
+
+	backoff := time.Second
+	for {
+		received, err := w.follow()
+		if err == nil {
+			return // Stopped
+		}
+		if received {
+			backoff = time.Second
+		}
+		log.Printf("CONTAINER_EVENTS: Event stream interrupted, reconnecting in %v: %v", backoff, err)
+		select {
+		case <-w.stopChan:
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, w.maxBackoff)
+	}
+}
+
+// follow handles events until the stream fails or the watcher is stopped, which
+// returns a nil error. received reports whether any event was handled.
+func (w *ContainerEventWatcher) follow() (received bool, err error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	options := dockerevents.ListOptions{
+		Filters: filters.NewArgs(
+			filters.Arg("type", string(dockerevents.ContainerEventType)),
+			filters.Arg("event", string(dockerevents.ActionCreate)),
+			filters.Arg("event", string(dockerevents.ActionStart)),
+			filters.Arg("event", string(dockerevents.ActionDie)),
+			filters.Arg("event", string(dockerevents.ActionDestroy)),
+		),
+	}
+	w.mu.Lock()
+	if !w.lastEvent.IsZero() {
+		options.Since = strconv.FormatInt(w.lastEvent.Unix(), 10)
+	}
+	w.mu.Unlock()
+
+	messages, errs := w.source.Events(ctx, options)
+	for {
+		select {
+		case <-w.stopChan:
+			return received, nil
+		case err := <-errs:
+			if err == nil {
+				err = errors.New("event stream closed")
+			}
+			return received, err
+		case msg := <-messages:
+			received = true
+			w.handleEvent(msg)
+		}
+	}
+}
+
+// handleEvent refreshes what a container event changed.
+func (w *ContainerEventWatcher) handleEvent(msg dockerevents.Message) {
+	name := msg.Actor.Attributes["name"]
+	image := msg.Actor.Attributes["image"]
+
+	w.mu.Lock()
+	if at := time.Unix(0, msg.TimeNano); at.After(w.lastEvent) {
+		w.lastEvent = at
+	}
+	w.mu.Unlock()
+
+	if w.eventBus != nil {
+		w.eventBus.Publish(events.Event{
+			Type: events.EventContainerChanged,
+			Payload: map[string]interface{}{
+				"container_id":   msg.Actor.ID,
+				"container_name": name,
+				"image":          image,
+				"action":         string(msg.Action),
+				"timestamp":      time.Now().Unix(),
+			},
+		})
+	}
+
+	switch msg.Action {
+	case dockerevents.ActionDestroy:
+		log.Printf("CONTAINER_EVENTS: Container %s was removed", name)
+		w.orchestrator.InvalidateContainers([]docker.Container{{ID: msg.Actor.ID, Name: name, Image: image}})
+		if w.updates != nil {
+			w.updates.containerRemoved(name)
+		}
+		w.scheduleRefresh()
+	case dockerevents.ActionCreate:
+		log.Printf("CONTAINER_EVENTS: Container %s was created", name)
+		w.scheduleRefresh()
+	}
+}
+
+// scheduleRefresh triggers a check once no container was created or removed for
+// the debounce period.
+func (w *ContainerEventWatcher) scheduleRefresh() {
+	if w.checker == nil {
+		return
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.refreshTimer != nil {
+		w.refreshTimer.Stop()
+	}
+	w.refreshTimer = time.AfterFunc(w.debounce, func() {
+		if !w.checker.IsPaused() {
+			w.checker.TriggerCheck()
+		}
+	})
+}
+
+// watchTargets returns a context of an update that is canceled, with a cause
+// wrapping ErrContainerRemoved, if one of containers is removed before the update
+// reaches its pause point. The returned function must be called when the update ends.
+func (o *UpdateOrchestrator) watchTargets(ctx context.Context, operationID string, containers []*docker.Container) (context.Context, func()) {
+	ctx, cancel := context.WithCancelCause(ctx)
+
+	o.targetsMu.Lock()
+	if o.targets == nil {
+		o.targets = make(map[string]map[string]context.CancelCauseFunc)
+	}
+	for _, c := range containers {
+		if o.targets[c.Name] == nil {
+			o.targets[c.Name] = make(map[string]context.CancelCauseFunc)
+		}
+		o.targets[c.Name][operationID] = cancel
+	}
+	o.targetsMu.Unlock()
+
+	return ctx, func() {
+		o.unwatchTargets(ctx, operationID)
+		cancel(nil)
+	}
+}
+
+// unwatchTargets stops failing an update when its containers are removed, since it
+// is about to recreate them. Returns the removal that canceled ctx, if any.
+func (o *UpdateOrchestrator) unwatchTargets(ctx context.Context, operationID string) error {
+	o.targetsMu.Lock()
+	for name, operations := range o.targets {
+		delete(operations, operationID)
+		if len(operations) == 0 {
+			delete(o.targets, name)
+		}
+	}
+	o.targetsMu.Unlock()
+
+	if cause := context.Cause(ctx); errors.Is(cause, ErrContainerRemoved) {
+		return cause
+	}
+	return nil
+}
+
+// containerRemoved cancels the updates whose container was removed before they
+// recreated it.
+func (o *UpdateOrchestrator) containerRemoved(name string) {
+	o.targetsMu.Lock()
+	defer o.targetsMu.Unlock()
+	for operationID, cancel := range o.targets[name] {
+		log.Printf("UPDATE: Container %s was removed, failing operation=%s", name, operationID)
+		cancel(fmt.Errorf("%w during the update: %s", ErrContainerRemoved, name))
+	}
+	delete(o.targets, name)
+}
@@ -0,0 +1,131 @@
+package update
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	dockerevents "github.com/docker/docker/api/types/events"
+
+	"github.com/chis/docksmith/internal/docker"
+	"github.com/chis/docksmith/internal/events"
+)
+
+// fakeEventSource streams the events sent on its channels, one stream per Events call.
+type fakeEventSource struct {
+	mu       sync.Mutex
+	messages chan dockerevents.Message
+	errs     chan error
+	since    []string
+}
+
+func newFakeEventSource() *fakeEventSource {
+	return &fakeEventSource{messages: make(chan dockerevents.Message), errs: make(chan error, 1)}
+}
+
+func (f *fakeEventSource) Events(ctx context.Context, options dockerevents.ListOptions) (<-chan dockerevents.Message, <-chan error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.since = append(f.since, options.Since)
+	return f.messages, f.errs
+}
+
+func (f *fakeEventSource) streams() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.since...)
+}
+
+func containerEvent(action dockerevents.Action, name string, at time.Time) dockerevents.Message {
+	return dockerevents.Message{
+		Type:     dockerevents.ContainerEventType,
+		Action:   action,
+		Actor:    dockerevents.Actor{ID: name + "-id", Attributes: map[string]string{"name": name, "image": "nginx:1.25"}},
+		TimeNano: at.UnixNano(),
+	}
+}
+
+func TestContainerEventWatcher(t *testing.T) {
+	mockDocker := &MockDockerClient{}
+	source := newFakeEventSource()
+	bus := events.NewBus()
+	eventChan, unsub := bus.Subscribe(events.EventContainerChanged)
+	defer unsub()
+
+	orch := NewOrchestrator(mockDocker, &mockRegistryManager{})
+	orch.cache.Set(checkCacheKey(docker.Container{ID: "web-id", Image: "nginx:1.25"}), ContainerUpdate{ContainerName: "web"}, time.Hour)
+
+	watcher := NewContainerEventWatcher(source, orch, nil, nil, bus)
+	watcher.Start()
+	defer watcher.Stop()
+
+	at := time.Unix(1700000000, 0)
+	source.messages <- containerEvent(dockerevents.ActionDestroy, "web", at)
+
+	select {
+	case event := <-eventChan:
+		if event.Payload["container_name"] != "web" || event.Payload["action"] != "destroy" {
+			t.Errorf("unexpected payload: %v", event.Payload)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected a container.changed event")
+	}
+	if _, found := orch.cache.Get(checkCacheKey(docker.Container{ID: "web-id", Image: "nginx:1.25"})); found {
+		t.Error("expected the removed container's check result to be dropped")
+	}
+
+	// A failed stream is reconnected, resuming from the last event
+	watcher.maxBackoff = 0
+	source.errs <- errors.New("connection reset")
+	deadline := time.Now().Add(3 * time.Second)
+	for len(source.streams()) < 2 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	streams := source.streams()
+	if len(streams) != 2 || streams[0] != "" || streams[1] != "1700000000" {
+		t.Errorf("expected a reconnect since the last event, got streams %q", streams)
+	}
+}
+
+func TestWatchTargets_FailsOnRemoval(t *testing.T) {
+	o := &UpdateOrchestrator{}
+	web := &docker.Container{Name: "web"}
+
+	ctx, stop := o.watchTargets(context.Background(), "op-1", []*docker.Container{web})
+	defer stop()
+	o.containerRemoved("db")
+	if ctx.Err() != nil {
+		t.Fatal("removing another container must not cancel the update")
+	}
+
+	o.containerRemoved("web")
+	if ctx.Err() == nil {
+		t.Fatal("expected the update to be canceled")
+	}
+	err := o.unwatchTargets(ctx, "op-1")
+	if !errors.Is(err, ErrContainerRemoved) || err.Error() != "container was removed during the update: web" {
+		t.Errorf("unexpected cause: %v", err)
+	}
+}
+
+func TestWatchTargets_IgnoresRemovalAfterPausePoint(t *testing.T) {
+	o := &UpdateOrchestrator{}
+	web := &docker.Container{Name: "web"}
+
+	ctx, stop := o.watchTargets(context.Background(), "op-1", []*docker.Container{web})
+	if err := o.unwatchTargets(ctx, "op-1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Recreating the container removes it
+	o.containerRemoved("web")
+	if ctx.Err() != nil {
+		t.Error("a recreated container must not cancel the update")
+	}
+	stop()
+	if len(o.targets) != 0 {
+		t.Errorf("expected no watched targets, got %v", o.targets)
+	}
+}
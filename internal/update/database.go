@@ -0,0 +1,178 @@
+package update
+
+import (
+	"cmp"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/chis/docksmith/internal/docker"
+	"github.com/chis/docksmith/internal/scripts"
+	"github.com/chis/docksmith/internal/version"
+)
+
+const (
+	defaultDatabaseDumpDir = "/data/db-dumps"
+	databaseDumpTimeout    = 30 * time.Minute
+)
+
+// databaseDumpScripts are the shell commands run in a database container to dump
+// all of its databases to stdout, with the credentials of the official images.
+var databaseDumpScripts = map[string]string{
+	"postgres": `exec pg_dumpall -U "${POSTGRES_USER:-postgres}"`,
+	"mysql":    `MYSQL_PWD="$MYSQL_ROOT_PASSWORD" exec mysqldump --all-databases --single-transaction --routines --events -uroot`,
+	"mariadb": `if command -v mariadb-dump >/dev/null; then dump=mariadb-dump; else dump=mysqldump; fi; ` +
+		`MYSQL_PWD="${MARIADB_ROOT_PASSWORD:-$MYSQL_ROOT_PASSWORD}" exec $dump --all-databases --single-transaction --routines --events -uroot`,
+}
+
+// SetDatabaseDumpDir sets where databases are dumped before major version updates.
+// Must be called before any update starts.
+func (o *UpdateOrchestrator) SetDatabaseDumpDir(dir string) {
+	o.databaseDumpDir = dir
+}
+
+// DatabaseDumpDirFromEnv reads where databases are dumped from DB_DUMP_DIR
+// (default /data/db-dumps).
+func DatabaseDumpDirFromEnv() string {
+	if value := os.Getenv("DB_DUMP_DIR"); value != "" {
+		log.Printf("Using DB_DUMP_DIR: %s", value)
+		return value
+	}
+	return defaultDatabaseDumpDir
+}
+
+// databaseEngine returns the engine of a container labeled docksmith.database: postgres,
+// mysql, or mariadb. "true" detects the engine from the image name. Returns "" for
+// containers without the label.
+func databaseEngine(cont *docker.Container) (string, error) {
+	value := strings.ToLower(strings.TrimSpace(cont.Labels[scripts.DatabaseLabel]))
+	switch value {
+	case "", "false", "0", "no", "off":
+		return "", nil
+	case "postgres", "postgresql":
+		return "postgres", nil
+	case "mysql", "mariadb":
+		return value, nil
+	case "true", "1", "yes", "on":
+		repo, _ := splitImageRef(cont.Image)
+		name := repo[strings.LastIndex(repo, "/")+1:]
+		switch {
+		case strings.Contains(name, "postgres"), strings.Contains(name, "postgis"), strings.Contains(name, "timescale"):
+			return "postgres", nil
+		case strings.Contains(name, "mariadb"):
+			return "mariadb", nil
+		case strings.Contains(name, "mysql"), strings.Contains(name, "percona"):
+			return "mysql", nil
+		}
+		return "", fmt.Errorf("cannot detect the database engine of %s from its image %s; set %s to postgres, mysql, or mariadb",
+			cont.Name, cont.Image, scripts.DatabaseLabel)
+	}
+	return "", fmt.Errorf("unsupported %s value %q on %s; use postgres, mysql, or mariadb", scripts.DatabaseLabel, value, cont.Name)
+}
+
+// isMajorUpdate reports whether updating from currentTag to targetTag changes the
+// major version. Tags without a version (latest, digests) may hide a major version
+// change, so they count as one.
+func isMajorUpdate(currentTag, targetTag string) bool {
+	parser := version.NewParser()
+	current, target := parser.ParseTag(currentTag), parser.ParseTag(targetTag)
+	if current == nil || target == nil || current.Type != target.Type {
+		return true
+	}
+	if current.Type == "date" {
+		return false
+	}
+	return current.Major != target.Major
+}
+
+// dumpDatabases is the pre-flight stage that dumps the databases of containers
+// labeled docksmith.database before a major version update, since the new version
+// may upgrade the on-disk format in a way the old version cannot read. An update
+// is blocked unless the dump succeeds. Rollbacks are not dumped.
+func (o *UpdateOrchestrator) dumpDatabases(ctx context.Context, operationID string, containers []*docker.Container, targetVersions map[string]string) error {
+	if o.storage != nil {
+		if op, found, _ := o.storage.GetUpdateOperation(ctx, operationID); found && op.OperationType == "rollback" {
+			return nil
+		}
+	}
+
+	for _, c := range containers {
+		engine, err := databaseEngine(c)
+		if err != nil {
+			return err
+		}
+		if engine == "" {
+			continue
+		}
+		_, currentTag := splitImageRef(c.Image)
+		target := targetVersions[c.Name]
+		if target == "" || !isMajorUpdate(currentTag, target) {
+			continue
+		}
+
+		o.publishProgress(operationID, c.Name, c.Labels["com.docker.compose.project"], "backup", 18,
+			fmt.Sprintf("Dumping %s databases of %s before the major version update", engine, c.Name))
+		path, err := o.dumpDatabase(ctx, c, engine, currentTag, target)
+		if err != nil {
+			return fmt.Errorf("major version update of %s blocked, database dump failed: %w (remove the %s label to update without a dump)",
+				c.Name, err, scripts.DatabaseLabel)
+		}
+		log.Printf("PREFLIGHT: Dumped %s databases of %s to %s before updating %s to %s", engine, c.Name, path, currentTag, target)
+	}
+	return nil
+}
+
+// dumpDatabase dumps all databases of a running container to a gzipped SQL file.
+// Returns the path of the dump.
+func (o *UpdateOrchestrator) dumpDatabase(ctx context.Context, cont *docker.Container, engine, currentTag, targetTag string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, databaseDumpTimeout)
+	defer cancel()
+
+	dir := filepath.Join(cmp.Or(o.databaseDumpDir, defaultDatabaseDumpDir), cont.Name)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", fmt.Errorf("failed to create dump directory: %w", err)
+	}
+	name := fmt.Sprintf("%s-%s-to-%s-%s.sql.gz", cont.Name, currentTag, targetTag, time.Now().UTC().Format("20060102T150405"))
+	path := filepath.Join(dir, strings.ReplaceAll(name, "/", "_"))
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return "", fmt.Errorf("failed to create dump file: %w", err)
+	}
+	gz := gzip.NewWriter(file)
+	counter := &countingWriter{w: gz}
+
+	err = o.runDocker(ctx, nil, counter, "exec", cont.Name, "sh", "-c", databaseDumpScripts[engine])
+	if err == nil && counter.n == 0 {
+		err = fmt.Errorf("dump is empty")
+	}
+	if closeErr := gz.Close(); err == nil {
+		err = closeErr
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(path)
+		return "", err
+	}
+	return path, nil
+}
+
+// countingWriter counts the bytes written through it.
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,109 @@
+package update
+
+import (
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/chis/docksmith/internal/docker"
+	"github.com/chis/docksmith/internal/scripts"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDatabaseEngine(t *testing.T) {
+	tests := []struct {
+		label, image, want string
+		wantErr            bool
+	}{
+		{"", "postgres:16", "", false},
+		{"false", "postgres:16", "", false},
+		{"postgresql", "myorg/db:1", "postgres", false},
+		{"MariaDB", "myorg/db:1", "mariadb", false},
+		{"true", "postgis/postgis:16-3.4", "postgres", false},
+		{"true", "ghcr.io/org/mysql-server:8.0", "mysql", false},
+		{"true", "lscr.io/linuxserver/mariadb:10.11", "mariadb", false},
+		{"true", "redis:7", "", true},
+		{"mongo", "mongo:7", "", true},
+	}
+	for _, tt := range tests {
+		cont := &docker.Container{Name: "db", Image: tt.image, Labels: map[string]string{scripts.DatabaseLabel: tt.label}}
+		got, err := databaseEngine(cont)
+		assert.Equal(t, tt.wantErr, err != nil, "label=%q image=%s: %v", tt.label, tt.image, err)
+		assert.Equal(t, tt.want, got, "label=%q image=%s", tt.label, tt.image)
+	}
+}
+
+func TestIsMajorUpdate(t *testing.T) {
+	assert.True(t, isMajorUpdate("15.4", "16.1"))
+	assert.True(t, isMajorUpdate("10.11-jammy", "11.4-noble"))
+	assert.False(t, isMajorUpdate("16.1", "16.4"))
+	assert.False(t, isMajorUpdate("8.0.35", "8.0.36"))
+	assert.True(t, isMajorUpdate("latest", "latest"), "unversioned tags may hide a major version change")
+}
+
+// fakeDump runs database dumps, writing output for the container named db.
+func fakeDump(output string, err error) func(context.Context, io.Reader, io.Writer, string, ...string) error {
+	return func(ctx context.Context, stdin io.Reader, stdout io.Writer, name string, args ...string) error {
+		if name != "docker" || args[0] != "exec" || args[1] != "db" {
+			return fmt.Errorf("unexpected command %s %v", name, args)
+		}
+		if err != nil {
+			return err
+		}
+		_, werr := io.WriteString(stdout, output)
+		return werr
+	}
+}
+
+func TestDumpDatabasesBeforeMajorUpdate(t *testing.T) {
+	dir := t.TempDir()
+	o := &UpdateOrchestrator{databaseDumpDir: dir, runVolumeCommand: fakeDump("-- PostgreSQL database cluster dump\n", nil)}
+	db := &docker.Container{Name: "db", Image: "postgres:15.4", Labels: map[string]string{scripts.DatabaseLabel: "true"}}
+
+	require.NoError(t, o.dumpDatabases(context.Background(), "op", []*docker.Container{db}, map[string]string{"db": "16.1"}))
+
+	dumps, _ := filepath.Glob(filepath.Join(dir, "db", "db-15.4-to-16.1-*.sql.gz"))
+	require.Len(t, dumps, 1)
+	file, err := os.Open(dumps[0])
+	require.NoError(t, err)
+	defer file.Close()
+	gz, err := gzip.NewReader(file)
+	require.NoError(t, err)
+	content, err := io.ReadAll(gz)
+	require.NoError(t, err)
+	assert.Equal(t, "-- PostgreSQL database cluster dump\n", string(content))
+}
+
+func TestDumpDatabasesBlocksMajorUpdateOnFailure(t *testing.T) {
+	dir := t.TempDir()
+	db := &docker.Container{Name: "db", Image: "mariadb:10.11", Labels: map[string]string{scripts.DatabaseLabel: "mariadb"}}
+
+	for _, run := range []func(context.Context, io.Reader, io.Writer, string, ...string) error{
+		fakeDump("", fmt.Errorf("exit status 2: access denied")),
+		fakeDump("", nil),
+	} {
+		o := &UpdateOrchestrator{databaseDumpDir: dir, runVolumeCommand: run}
+		err := o.dumpDatabases(context.Background(), "op", []*docker.Container{db}, map[string]string{"db": "11.4"})
+		require.Error(t, err)
+		assert.True(t, strings.Contains(err.Error(), "blocked"), err.Error())
+	}
+
+	dumps, _ := filepath.Glob(filepath.Join(dir, "db", "*"))
+	assert.Empty(t, dumps, "failed dumps are removed")
+}
+
+func TestDumpDatabasesSkipsMinorUpdates(t *testing.T) {
+	o := &UpdateOrchestrator{databaseDumpDir: t.TempDir(), runVolumeCommand: fakeDump("", fmt.Errorf("not expected"))}
+	db := &docker.Container{Name: "db", Image: "postgres:16.1", Labels: map[string]string{scripts.DatabaseLabel: "postgres"}}
+	app := &docker.Container{Name: "app", Image: "myapp:1.0"}
+
+	err := o.dumpDatabases(context.Background(), "op", []*docker.Container{db, app},
+		map[string]string{"db": "16.4", "app": "2.0"})
+	assert.NoError(t, err)
+}
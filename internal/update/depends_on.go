@@ -0,0 +1,54 @@
+package update
+
+import (
+	"context"
+	"log"
+
+	"github.com/chis/docksmith/internal/docker"
+	"github.com/chis/docksmith/internal/graph"
+)
+
+// healthyDependencies returns the containers of a batch that cont depends on with
+// the compose depends_on condition service_healthy.
+func healthyDependencies(cont *docker.Container, batch []*docker.Container) []string {
+	dependsOn := cont.Labels[graph.DependsOnLabel]
+	if dependsOn == "" {
+		return nil
+	}
+	conditions := graph.ParseDependsOnConditions(dependsOn)
+
+	var deps []string
+	for _, c := range batch {
+		if c.Name == cont.Name || c.Labels[graph.ProjectLabel] != cont.Labels[graph.ProjectLabel] {
+			continue
+		}
+		if conditions[c.Labels[graph.ServiceLabel]] == graph.ConditionServiceHealthy {
+			deps = append(deps, c.Name)
+		}
+	}
+	return deps
+}
+
+// awaitHealthyDependencies waits for the service_healthy dependencies of a container
+// that are updated in the same batch, so it is not recreated on top of a dependency
+// that is still starting. healthy holds the result of the health checks already run
+// in the batch, so each dependency is waited for at most once.
+// Returns the first dependency that is not healthy, or "".
+func (o *UpdateOrchestrator) awaitHealthyDependencies(ctx context.Context, cont *docker.Container, batch []*docker.Container, healthy map[string]bool) string {
+	for _, dep := range healthyDependencies(cont, batch) {
+		ok, checked := healthy[dep]
+		if !checked {
+			log.Printf("BATCH UPDATE: Waiting for %s to be healthy before updating %s (depends_on condition service_healthy)", dep, cont.Name)
+			err := o.waitForHealthy(ctx, dep, o.healthCheckCfg.Timeout)
+			if err != nil {
+				log.Printf("BATCH UPDATE: %s did not become healthy: %v", dep, err)
+			}
+			ok = err == nil
+			healthy[dep] = ok
+		}
+		if !ok {
+			return dep
+		}
+	}
+	return ""
+}
@@ -0,0 +1,26 @@
+package update
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/chis/docksmith/internal/docker"
+)
+
+func TestHealthyDependencies(t *testing.T) {
+	db := stackContainer("db", "", nil)
+	cache := stackContainer("cache", "", nil)
+	app := stackContainer("app", "db:service_healthy:false,cache:service_started:false", nil)
+	batch := []*docker.Container{db, cache, app}
+
+	assert.Equal(t, []string{"db"}, healthyDependencies(app, batch))
+	assert.Empty(t, healthyDependencies(app, []*docker.Container{cache, app}), "dependencies outside the batch are not waited for")
+	assert.Empty(t, healthyDependencies(db, batch))
+
+	orch := &UpdateOrchestrator{}
+	assert.Equal(t, "", orch.awaitHealthyDependencies(context.Background(), app, batch, map[string]bool{"db": true}))
+	assert.Equal(t, "db", orch.awaitHealthyDependencies(context.Background(), app, batch, map[string]bool{"db": false}),
+		"a dependency that failed its health check holds back its dependents")
+}
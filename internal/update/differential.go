@@ -0,0 +1,154 @@
+package update
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/chis/docksmith/internal/docker"
+	"github.com/chis/docksmith/internal/storage"
+)
+
+// differentialMaxAge bounds how long a check result is reused. Older results are
+// checked in full again, picking up what the digest and tag list do not show,
+// such as architectures added to a tag's manifest list.
+const differentialMaxAge = 24 * time.Hour
+
+// SetDifferentialCheck sets whether checks reuse a container's last result while
+// its image, labels, the registry digest of its tag, and the repository's tag list
+// are unchanged. It requires storage, where the last results are kept.
+func (o *Orchestrator) SetDifferentialCheck(enabled bool) {
+	o.checker.differential = enabled
+}
+
+// DifferentialCheckFromEnv reads the differential check setting from DIFFERENTIAL_CHECK.
+// Returns false when unset or invalid.
+func DifferentialCheckFromEnv() bool {
+	value := os.Getenv("DIFFERENTIAL_CHECK")
+	if value == "" {
+		return false
+	}
+	enabled, ok := parseLabelBool(value)
+	if !ok {
+		log.Printf("Warning: Invalid DIFFERENTIAL_CHECK '%s', checking every container in full", value)
+		return false
+	}
+	log.Printf("Using DIFFERENTIAL_CHECK: %v", enabled)
+	return enabled
+}
+
+// checkFingerprint returns what a check of container depends on: its image
+// reference, local image digest, and labels, the registry digest of its tag (a
+// HEAD request), and a hash of the repository's tag list (revalidated with a
+// conditional request when the tag cache is enabled). ok is false for containers
+// that are always checked in full: local images, images pinned by digest, and
+// containers whose compose file names another image.
+func (c *Checker) checkFingerprint(ctx context.Context, container docker.Container) (storage.CheckFingerprint, bool) {
+	if strings.Contains(container.Image, "@") {
+		return storage.CheckFingerprint{}, false
+	}
+	if isLocal, err := c.dockerClient.IsLocalImage(ctx, container.Image); err != nil || isLocal {
+		return storage.CheckFingerprint{}, false
+	}
+	if mismatch, _ := c.checkComposeMismatch(container); mismatch {
+		return storage.CheckFingerprint{}, false
+	}
+	localDigest, err := c.dockerClient.GetImageDigest(ctx, container.Image)
+	if err != nil || localDigest == "" {
+		return storage.CheckFingerprint{}, false
+	}
+
+	imgInfo := c.extractor.ExtractFromImage(container.Image)
+	imageRef := imgInfo.Registry + "/" + imgInfo.Repository
+	tag := "latest"
+	if i := strings.LastIndex(container.Image, ":"); i > strings.LastIndex(container.Image, "/") {
+		tag = container.Image[i+1:]
+	}
+
+	headDigest, err := c.registry(ctx).GetTagDigest(ctx, imageRef, tag)
+	if err != nil || headDigest == "" {
+		log.Printf("checkContainer %s: Checking in full, failed to get digest of %s: %v", container.Name, tag, err)
+		return storage.CheckFingerprint{}, false
+	}
+	tags, err := c.registry(ctx).ListTags(ctx, imageRef)
+	if err != nil {
+		log.Printf("checkContainer %s: Checking in full, failed to list tags: %v", container.Name, err)
+		return storage.CheckFingerprint{}, false
+	}
+
+	labels := make([]string, 0, len(container.Labels))
+	for _, key := range slices.Sorted(maps.Keys(container.Labels)) {
+		labels = append(labels, key+"="+container.Labels[key])
+	}
+	return storage.CheckFingerprint{
+		ContainerName: container.Name,
+		Image:         container.Image,
+		LocalHash:     hashLines(append([]string{container.Image, localDigest}, labels...)),
+		HeadDigest:    headDigest,
+		TagsHash:      hashLines(slices.Sorted(slices.Values(tags))),
+	}, true
+}
+
+// lastResult returns the stored result of container's last check when it was
+// based on the same fingerprint and is younger than differentialMaxAge.
+func (c *Checker) lastResult(ctx context.Context, container docker.Container, fingerprint storage.CheckFingerprint) (ContainerUpdate, bool) {
+	last, found, err := c.storage.GetCheckFingerprint(ctx, container.Name)
+	if err != nil || !found {
+		return ContainerUpdate{}, false
+	}
+	if last.LocalHash != fingerprint.LocalHash || last.HeadDigest != fingerprint.HeadDigest ||
+		last.TagsHash != fingerprint.TagsHash || time.Since(last.CheckedAt) > differentialMaxAge {
+		return ContainerUpdate{}, false
+	}
+
+	var update ContainerUpdate
+	if err := json.Unmarshal([]byte(last.Result), &update); err != nil {
+		log.Printf("checkContainer %s: Failed to decode last check result: %v", container.Name, err)
+		return ContainerUpdate{}, false
+	}
+	// Local state is read again, it may have changed without the image changing
+	update.HealthStatus = container.HealthStatus
+	update.EnvControlled, update.EnvVarName = false, ""
+	c.checkEnvControlled(container, &update)
+
+	log.Printf("checkContainer %s: Digest and tags unchanged since %s, reusing last result (%s)",
+		container.Name, last.CheckedAt.Format(time.RFC3339), update.Status)
+	return update, true
+}
+
+// saveFingerprint stores the result of a full check with the fingerprint it was
+// based on. Results that depend on more than the fingerprint, such as those of
+// containers with a pre-update check, or that a later check could resolve, are
+// not stored.
+func (c *Checker) saveFingerprint(ctx context.Context, fingerprint storage.CheckFingerprint, update ContainerUpdate) {
+	if update.PreUpdateCheck != "" {
+		return
+	}
+	switch update.Status {
+	case UpToDate, UpToDatePinnable, UpdateAvailable, UpdateUnavailableArch:
+	default:
+		return
+	}
+	result, err := json.Marshal(update)
+	if err != nil {
+		return
+	}
+	fingerprint.Result = string(result)
+	fingerprint.CheckedAt = time.Now()
+	if err := c.storage.SaveCheckFingerprint(ctx, fingerprint); err != nil {
+		log.Printf("checkContainer %s: Failed to save check fingerprint: %v", update.ContainerName, err)
+	}
+}
+
+// hashLines returns the SHA256 of lines joined by newlines.
+func hashLines(lines []string) string {
+	sum := sha256.Sum256([]byte(strings.Join(lines, "\n")))
+	return hex.EncodeToString(sum[:])
+}
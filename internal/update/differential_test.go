@@ -0,0 +1,120 @@
+package update
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/chis/docksmith/internal/docker"
+	"github.com/chis/docksmith/internal/storage"
+)
+
+// newDifferentialTestChecker returns a differential checker for an nginx 1.24.0
+// container with 1.25.0 available, and its storage
+func newDifferentialTestChecker() (*Checker, *mockRegistryClient, storage.Storage) {
+	mockDocker := &mockDockerClient{
+		containers: []docker.Container{
+			{ID: "test-container", Name: "web", Image: "docker.io/library/nginx:1.24.0"},
+		},
+		imageDigests:  map[string]string{"docker.io/library/nginx:1.24.0": "sha256:abc123"},
+		imageVersions: map[string]string{},
+		localImages:   map[string]bool{},
+	}
+	mockRegistry := &mockRegistryClient{
+		tags: map[string][]string{
+			"docker.io/library/nginx": {"1.25.0", "1.24.0"},
+		},
+		tagDigests:     map[string]string{"docker.io/library/nginx:1.24.0": "sha256:abc123"},
+		digestMappings: map[string]map[string][]string{},
+	}
+	store := storage.NewMemoryStorage()
+	checker := NewChecker(mockDocker, mockRegistry, store)
+	checker.differential = true
+	return checker, mockRegistry, store
+}
+
+// markStoredResult changes the stored result of web, so a check reusing it can be told apart
+func markStoredResult(t *testing.T, store storage.Storage) {
+	ctx := context.Background()
+	fingerprint, found, err := store.GetCheckFingerprint(ctx, "web")
+	if err != nil || !found {
+		t.Fatalf("expected a stored fingerprint, got found=%v err=%v", found, err)
+	}
+	fingerprint.Result = strings.Replace(fingerprint.Result, `"latest_version":"1.25.0"`, `"latest_version":"stored"`, 1)
+	if err := store.SaveCheckFingerprint(ctx, fingerprint); err != nil {
+		t.Fatalf("SaveCheckFingerprint failed: %v", err)
+	}
+}
+
+func TestDifferentialCheck_ReusesUnchangedResult(t *testing.T) {
+	checker, _, store := newDifferentialTestChecker()
+	container := checker.dockerClient.(*mockDockerClient).containers[0]
+
+	first := checker.checkContainer(context.Background(), container)
+	if first.Status != UpdateAvailable || first.LatestVersion != "1.25.0" {
+		t.Fatalf("expected update to 1.25.0, got %s %q", first.Status, first.LatestVersion)
+	}
+
+	markStoredResult(t, store)
+	container.HealthStatus = "healthy"
+	second := checker.checkContainer(context.Background(), container)
+	if second.LatestVersion != "stored" {
+		t.Errorf("expected the stored result to be reused, got %q", second.LatestVersion)
+	}
+	if second.HealthStatus != "healthy" {
+		t.Errorf("expected the health status to be read again, got %q", second.HealthStatus)
+	}
+}
+
+func TestDifferentialCheck_RechecksChangedTags(t *testing.T) {
+	checker, mockRegistry, store := newDifferentialTestChecker()
+	container := checker.dockerClient.(*mockDockerClient).containers[0]
+
+	checker.checkContainer(context.Background(), container)
+	markStoredResult(t, store)
+	mockRegistry.tags["docker.io/library/nginx"] = []string{"1.26.0", "1.25.0", "1.24.0"}
+
+	update := checker.checkContainer(context.Background(), container)
+	if update.LatestVersion != "1.26.0" {
+		t.Errorf("expected a full check finding 1.26.0, got %q", update.LatestVersion)
+	}
+}
+
+func TestDifferentialCheck_RechecksChangedDigest(t *testing.T) {
+	checker, mockRegistry, store := newDifferentialTestChecker()
+	container := checker.dockerClient.(*mockDockerClient).containers[0]
+
+	checker.checkContainer(context.Background(), container)
+	markStoredResult(t, store)
+	mockRegistry.tagDigests["docker.io/library/nginx:1.24.0"] = "sha256:rebuilt"
+
+	update := checker.checkContainer(context.Background(), container)
+	if update.LatestVersion != "1.25.0" {
+		t.Errorf("expected a full check, got %q", update.LatestVersion)
+	}
+}
+
+func TestDifferentialCheck_RechecksChangedLabels(t *testing.T) {
+	checker, _, store := newDifferentialTestChecker()
+	container := checker.dockerClient.(*mockDockerClient).containers[0]
+
+	checker.checkContainer(context.Background(), container)
+	markStoredResult(t, store)
+	container.Labels = map[string]string{"docksmith.version-pin-minor": "true"}
+
+	update := checker.checkContainer(context.Background(), container)
+	if update.LatestVersion == "stored" {
+		t.Error("expected a full check after the labels changed")
+	}
+}
+
+func TestDifferentialCheck_Disabled(t *testing.T) {
+	checker, _, store := newDifferentialTestChecker()
+	checker.differential = false
+	container := checker.dockerClient.(*mockDockerClient).containers[0]
+
+	checker.checkContainer(context.Background(), container)
+	if _, found, _ := store.GetCheckFingerprint(context.Background(), "web"); found {
+		t.Error("expected no fingerprint to be stored")
+	}
+}
@@ -0,0 +1,82 @@
+package update
+
+import (
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// fsnotifyWatcher watches directories with fsnotify (inotify on Linux, kqueue
+// on the BSDs and macOS, ReadDirectoryChangesW on Windows).
+type fsnotifyWatcher struct {
+	watcher *fsnotify.Watcher
+	events  chan string
+	done    chan struct{}
+}
+
+func newDirWatcher() (dirWatcher, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize file watcher: %w", err)
+	}
+	w := &fsnotifyWatcher{
+		watcher: watcher,
+		events:  make(chan string, 64),
+		done:    make(chan struct{}),
+	}
+	go w.forwardEvents()
+	return w, nil
+}
+
+func (w *fsnotifyWatcher) Add(dir string) error {
+	if err := w.watcher.Add(dir); err != nil {
+		return fmt.Errorf("failed to watch %s: %w", dir, err)
+	}
+	return nil
+}
+
+func (w *fsnotifyWatcher) Remove(dir string) error {
+	if err := w.watcher.Remove(dir); err != nil && !errors.Is(err, fsnotify.ErrNonExistentWatch) {
+		return fmt.Errorf("failed to stop watching %s: %w", dir, err)
+	}
+	return nil
+}
+
+func (w *fsnotifyWatcher) Events() <-chan string {
+	return w.events
+}
+
+func (w *fsnotifyWatcher) Close() error {
+	close(w.done)
+	return w.watcher.Close()
+}
+
+// forwardEvents sends the path of each file written or created, which includes
+// files renamed into a watched directory, until the watcher is closed. Editors
+// often save by writing a temporary file and renaming it.
+func (w *fsnotifyWatcher) forwardEvents() {
+	defer close(w.events)
+	for {
+		select {
+		case event, ok := <-w.watcher.Events:
+			if !ok {
+				return
+			}
+			if !event.Has(fsnotify.Write) && !event.Has(fsnotify.Create) {
+				continue
+			}
+			select {
+			case w.events <- event.Name:
+			case <-w.done:
+				return
+			}
+		case err, ok := <-w.watcher.Errors:
+			if !ok {
+				return
+			}
+			log.Printf("COMPOSE_WATCHER: File watcher error: %v", err)
+		}
+	}
+}
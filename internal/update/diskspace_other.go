@@ -0,0 +1,9 @@
+//go:build !linux
+
+package update
+
+import "errors"
+
+func freeDiskSpace(path string) (uint64, error) {
+	return 0, errors.New("disk space checks are only supported on Linux")
+}
@@ -0,0 +1,76 @@
+package update
+
+import (
+	"context"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/chis/docksmith/internal/docker"
+	"github.com/chis/docksmith/internal/eol"
+	"github.com/chis/docksmith/internal/scripts"
+)
+
+// EndOfLife is the lifecycle of the release cycle a container runs, from endoflife.date.
+type EndOfLife struct {
+	Product     string `json:"product"`                // endoflife.date product, e.g. "postgresql"
+	Cycle       string `json:"cycle"`                  // Release cycle of the current version, e.g. "12"
+	EOL         bool   `json:"eol"`                    // The cycle no longer receives fixes
+	EOLDate     string `json:"eol_date,omitempty"`     // When the cycle reaches or reached end of life (YYYY-MM-DD)
+	LatestCycle string `json:"latest_cycle,omitempty"` // Newest release cycle of the product
+}
+
+// SetEOLClient sets the endoflife.date client checks look up the lifecycle of
+// known products with. nil disables the lookups.
+func (o *Orchestrator) SetEOLClient(client *eol.Client) {
+	o.checker.eolClient = client
+}
+
+// eolProduct returns the endoflife.date product of a container. The docksmith.eol
+// label names the product or opts the container out; otherwise known images are
+// detected by name.
+func (c *Checker) eolProduct(container docker.Container) (string, bool) {
+	value := strings.ToLower(strings.TrimSpace(container.Labels[scripts.EOLLabel]))
+	if enabled, ok := parseLabelBool(value); ok && !enabled {
+		return "", false
+	}
+	if value != "" && value != "true" && value != "1" && value != "yes" {
+		return value, true
+	}
+	return eol.ProductForImage(c.extractor.ExtractFromImage(container.Image).Repository)
+}
+
+// setEndOfLife looks up the release cycle of the container's current version and
+// records its end of life. Lookup failures leave the result without lifecycle data.
+func (c *Checker) setEndOfLife(ctx context.Context, container docker.Container, update *ContainerUpdate) {
+	if c.eolClient == nil || update.CurrentVersion == "" || update.IsLocal {
+		return
+	}
+	product, ok := c.eolProduct(container)
+	if !ok {
+		return
+	}
+
+	cycles, err := c.eolClient.Cycles(ctx, product)
+	if err != nil {
+		log.Printf("checkContainer %s: Skipping end-of-life check: %v", container.Name, err)
+		return
+	}
+	cycle, ok := eol.MatchCycle(cycles, update.CurrentVersion)
+	if !ok {
+		return
+	}
+
+	update.EndOfLife = &EndOfLife{
+		Product:     product,
+		Cycle:       cycle.Cycle,
+		EOL:         cycle.EOL.Reached,
+		LatestCycle: cycles[0].Cycle,
+	}
+	if !cycle.EOL.Time.IsZero() {
+		update.EndOfLife.EOLDate = cycle.EOL.Time.Format(time.DateOnly)
+	}
+	if cycle.EOL.Reached {
+		log.Printf("checkContainer %s: %s %s is past end of life (newest cycle %s)", container.Name, product, cycle.Cycle, cycles[0].Cycle)
+	}
+}
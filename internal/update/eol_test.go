@@ -0,0 +1,63 @@
+package update
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/chis/docksmith/internal/docker"
+	"github.com/chis/docksmith/internal/eol"
+	"github.com/chis/docksmith/internal/scripts"
+)
+
+func TestSetEndOfLife(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/postgresql.json":
+			w.Write([]byte(`[{"cycle": "17", "eol": "2029-11-08"}, {"cycle": "12", "eol": "2024-11-21"}]`))
+		case "/nginx.json":
+			w.Write([]byte(`[{"cycle": "1.27", "eol": false}, {"cycle": "1.26", "eol": false}]`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	checker := NewChecker(&MockDockerClient{}, &mockRegistryManager{}, nil)
+	checker.eolClient = eol.NewClient(server.URL)
+	ctx := context.Background()
+
+	postgres := docker.Container{Name: "db", Image: "postgres:12-alpine"}
+	update := ContainerUpdate{ContainerName: "db", CurrentVersion: "12.22"}
+	checker.setEndOfLife(ctx, postgres, &update)
+	require.NotNil(t, update.EndOfLife)
+	assert.Equal(t, EndOfLife{Product: "postgresql", Cycle: "12", EOL: true, EOLDate: "2024-11-21", LatestCycle: "17"}, *update.EndOfLife)
+
+	nginx := ContainerUpdate{ContainerName: "web", CurrentVersion: "1.26.2"}
+	checker.setEndOfLife(ctx, docker.Container{Name: "web", Image: "nginx:1.26"}, &nginx)
+	require.NotNil(t, nginx.EndOfLife)
+	assert.False(t, nginx.EndOfLife.EOL)
+	assert.Empty(t, nginx.EndOfLife.EOLDate)
+
+	// Opted out with the label
+	update = ContainerUpdate{ContainerName: "db", CurrentVersion: "12.22"}
+	postgres.Labels = map[string]string{scripts.EOLLabel: "false"}
+	checker.setEndOfLife(ctx, postgres, &update)
+	assert.Nil(t, update.EndOfLife)
+
+	// The label names the product of an image not detected by name
+	custom := docker.Container{Name: "db", Image: "registry.example.com/team/pg:12.22", Labels: map[string]string{scripts.EOLLabel: "postgresql"}}
+	checker.setEndOfLife(ctx, custom, &update)
+	require.NotNil(t, update.EndOfLife)
+	assert.True(t, update.EndOfLife.EOL)
+
+	// Unknown images and failed lookups leave the result without lifecycle data
+	unknown := ContainerUpdate{ContainerName: "app", CurrentVersion: "2.0"}
+	checker.setEndOfLife(ctx, docker.Container{Name: "app", Image: "linuxserver/sonarr:2.0"}, &unknown)
+	checker.setEndOfLife(ctx, docker.Container{Name: "app", Image: "app:2.0", Labels: map[string]string{scripts.EOLLabel: "missing"}}, &unknown)
+	assert.Nil(t, unknown.EndOfLife)
+}
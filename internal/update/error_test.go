@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/chis/docksmith/internal/docker"
+	"github.com/chis/docksmith/internal/registry"
 )
 
 // MockFailingDockerService simulates Docker daemon failures
@@ -108,6 +109,29 @@ func (m *MockFailingRegistryManager) ListTagsWithDigests(ctx context.Context, im
 
 func (m *MockFailingRegistryManager) GetGhostTags(imageRef string) []string { return nil }
 
+func (m *MockFailingRegistryManager) GetImageSize(ctx context.Context, imageRef, reference string) (int64, error) {
+	if m.shouldTimeout || m.shouldFail {
+		return 0, errors.New("failed to get image size")
+	}
+	return 0, nil
+}
+
+func (m *MockFailingRegistryManager) GetImageLayers(ctx context.Context, imageRef, reference string) ([]registry.ImageLayer, error) {
+	return nil, errors.New("image layers not available")
+}
+
+func (m *MockFailingRegistryManager) GetImagePlatforms(ctx context.Context, imageRef, reference string) ([]string, error) {
+	return nil, nil
+}
+
+func (m *MockFailingRegistryManager) GetPlatformDigest(ctx context.Context, imageRef, reference string) (string, error) {
+	return "", errors.New("platform digest not available")
+}
+
+func (m *MockFailingRegistryManager) GetSignatures(ctx context.Context, imageRef, digest string) (*registry.ImageSignatures, error) {
+	return &registry.ImageSignatures{}, nil
+}
+
 // TestDockerDaemonUnavailable tests handling of Docker daemon failures
 func TestDockerDaemonUnavailable(t *testing.T) {
 	dockerService := &MockFailingDockerService{shouldFail: true}
@@ -404,3 +428,23 @@ func (m *MockSuccessRegistryManager) ListTagsWithDigests(ctx context.Context, im
 }
 
 func (m *MockSuccessRegistryManager) GetGhostTags(imageRef string) []string { return nil }
+
+func (m *MockSuccessRegistryManager) GetImageSize(ctx context.Context, imageRef, reference string) (int64, error) {
+	return 0, nil
+}
+
+func (m *MockSuccessRegistryManager) GetImageLayers(ctx context.Context, imageRef, reference string) ([]registry.ImageLayer, error) {
+	return nil, errors.New("image layers not available")
+}
+
+func (m *MockSuccessRegistryManager) GetImagePlatforms(ctx context.Context, imageRef, reference string) ([]string, error) {
+	return nil, nil
+}
+
+func (m *MockSuccessRegistryManager) GetPlatformDigest(ctx context.Context, imageRef, reference string) (string, error) {
+	return "", errors.New("platform digest not available")
+}
+
+func (m *MockSuccessRegistryManager) GetSignatures(ctx context.Context, imageRef, digest string) (*registry.ImageSignatures, error) {
+	return &registry.ImageSignatures{}, nil
+}
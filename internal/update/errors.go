@@ -9,6 +9,7 @@ import (
 var (
 	ErrContainerNotFound = errors.New("container not found")
 	ErrNoTargetVersion   = errors.New("no target version specified")
+	ErrReadOnly          = errors.New("docksmith is in read-only mode (DOCKSMITH_READ_ONLY)")
 )
 
 // NotFoundError wraps an error to indicate a resource was not found (404).
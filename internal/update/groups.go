@@ -0,0 +1,79 @@
+package update
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/chis/docksmith/internal/scripts"
+)
+
+// Group summarizes the containers sharing a docksmith.group label.
+type Group struct {
+	Name             string   `json:"name"`
+	Containers       []string `json:"containers"`
+	UpdatesAvailable int      `json:"updates_available"`
+	UpToDate         int      `json:"up_to_date"`
+	Failed           int      `json:"failed"`
+	Ignored          int      `json:"ignored"`
+}
+
+// ParseGroups returns the groups named by a container's docksmith.group label,
+// in label order without duplicates.
+func ParseGroups(labels map[string]string) []string {
+	value := labels[scripts.GroupLabel]
+	if value == "" {
+		return nil
+	}
+
+	var groups []string
+	seen := make(map[string]bool)
+	for _, name := range strings.Split(value, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		groups = append(groups, name)
+	}
+	return groups
+}
+
+// InGroup reports whether a checked container belongs to group.
+func (c ContainerInfo) InGroup(group string) bool {
+	for _, g := range c.Groups {
+		if g == group {
+			return true
+		}
+	}
+	return false
+}
+
+// BuildGroups summarizes checked containers by group.
+func BuildGroups(containers []ContainerInfo) map[string]*Group {
+	groups := make(map[string]*Group)
+	for _, c := range containers {
+		for _, name := range c.Groups {
+			group, ok := groups[name]
+			if !ok {
+				group = &Group{Name: name, Containers: []string{}}
+				groups[name] = group
+			}
+			group.Containers = append(group.Containers, c.ContainerName)
+
+			switch c.Status {
+			case UpdateAvailable, UpdateAvailableBlocked:
+				group.UpdatesAvailable++
+			case UpToDate, UpToDatePinnable:
+				group.UpToDate++
+			case CheckFailed, MetadataUnavailable:
+				group.Failed++
+			case Ignored:
+				group.Ignored++
+			}
+		}
+	}
+	for _, group := range groups {
+		sort.Strings(group.Containers)
+	}
+	return groups
+}
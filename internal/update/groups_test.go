@@ -0,0 +1,41 @@
+package update
+
+import (
+	"testing"
+
+	"github.com/chis/docksmith/internal/scripts"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseGroups(t *testing.T) {
+	assert.Nil(t, ParseGroups(map[string]string{}))
+	assert.Equal(t, []string{"media"}, ParseGroups(map[string]string{scripts.GroupLabel: "media"}))
+	assert.Equal(t, []string{"media", "critical"},
+		ParseGroups(map[string]string{scripts.GroupLabel: " media, critical,,media "}))
+}
+
+func TestBuildGroups(t *testing.T) {
+	containers := []ContainerInfo{
+		{ContainerUpdate: ContainerUpdate{ContainerName: "sonarr", Status: UpdateAvailable}, Groups: []string{"media"}},
+		{ContainerUpdate: ContainerUpdate{ContainerName: "plex", Status: UpToDate}, Groups: []string{"media", "critical"}},
+		{ContainerUpdate: ContainerUpdate{ContainerName: "radarr", Status: Ignored}, Groups: []string{"media"}},
+		{ContainerUpdate: ContainerUpdate{ContainerName: "postgres", Status: CheckFailed}, Groups: []string{"critical"}},
+		{ContainerUpdate: ContainerUpdate{ContainerName: "nginx", Status: UpdateAvailable}},
+	}
+
+	groups := BuildGroups(containers)
+	assert.Len(t, groups, 2)
+
+	media := groups["media"]
+	assert.Equal(t, []string{"plex", "radarr", "sonarr"}, media.Containers)
+	assert.Equal(t, 1, media.UpdatesAvailable)
+	assert.Equal(t, 1, media.UpToDate)
+	assert.Equal(t, 1, media.Ignored)
+
+	critical := groups["critical"]
+	assert.Equal(t, []string{"plex", "postgres"}, critical.Containers)
+	assert.Equal(t, 1, critical.Failed)
+
+	assert.True(t, containers[1].InGroup("critical"))
+	assert.False(t, containers[4].InGroup("media"))
+}
@@ -0,0 +1,199 @@
+package update
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/chis/docksmith/internal/scripts"
+)
+
+const (
+	defaultProbeTimeout = 5 * time.Second
+	defaultProbeRetries = 3
+	probeRetryInterval  = 2 * time.Second
+	maxProbeBodyBytes   = 1 << 20
+)
+
+// HealthProbe is a post-update verification probe configured with
+// docksmith.healthcheck.* labels. It runs after the Docker health check (or
+// running check) passes, and the update only succeeds if the probe does.
+type HealthProbe struct {
+	HTTPURL    string         // URL for an HTTP GET probe
+	TCPAddress string         // Port or host:port for a TCP connect probe
+	Statuses   [][2]int       // Accepted HTTP status ranges (inclusive)
+	Body       *regexp.Regexp // Pattern the HTTP response body must match
+	Timeout    time.Duration  // Timeout of each attempt
+	Retries    int            // Retries after the first failed attempt
+}
+
+// ParseHealthProbe builds a probe from container labels.
+// Returns nil if the container has no HTTP or TCP probe label.
+func ParseHealthProbe(labels map[string]string) (*HealthProbe, error) {
+	httpURL := strings.TrimSpace(labels[scripts.HealthcheckHTTPLabel])
+	tcpAddress := strings.TrimSpace(labels[scripts.HealthcheckTCPLabel])
+	if httpURL == "" && tcpAddress == "" {
+		return nil, nil
+	}
+	if httpURL != "" && tcpAddress != "" {
+		return nil, fmt.Errorf("only one of %s and %s may be set", scripts.HealthcheckHTTPLabel, scripts.HealthcheckTCPLabel)
+	}
+
+	probe := &HealthProbe{
+		HTTPURL:    httpURL,
+		TCPAddress: tcpAddress,
+		Statuses:   [][2]int{{200, 299}},
+		Timeout:    defaultProbeTimeout,
+		Retries:    defaultProbeRetries,
+	}
+
+	if httpURL != "" && !strings.HasPrefix(httpURL, "http://") && !strings.HasPrefix(httpURL, "https://") {
+		return nil, fmt.Errorf("invalid %s %q: must be an http:// or https:// URL", scripts.HealthcheckHTTPLabel, httpURL)
+	}
+
+	if value := labels[scripts.HealthcheckStatusLabel]; value != "" {
+		statuses, err := parseStatusRanges(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", scripts.HealthcheckStatusLabel, err)
+		}
+		probe.Statuses = statuses
+	}
+
+	if value := labels[scripts.HealthcheckBodyLabel]; value != "" {
+		body, err := regexp.Compile(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", scripts.HealthcheckBodyLabel, err)
+		}
+		probe.Body = body
+	}
+
+	if value := labels[scripts.HealthcheckTimeoutLabel]; value != "" {
+		timeout, err := time.ParseDuration(value)
+		if err != nil || timeout <= 0 {
+			return nil, fmt.Errorf("invalid %s %q: must be a positive duration", scripts.HealthcheckTimeoutLabel, value)
+		}
+		probe.Timeout = timeout
+	}
+
+	if value := labels[scripts.HealthcheckRetriesLabel]; value != "" {
+		retries, err := strconv.Atoi(value)
+		if err != nil || retries < 0 {
+			return nil, fmt.Errorf("invalid %s %q: must be a non-negative integer", scripts.HealthcheckRetriesLabel, value)
+		}
+		probe.Retries = retries
+	}
+
+	return probe, nil
+}
+
+// parseStatusRanges parses "200,204" or "200-399" into inclusive ranges
+func parseStatusRanges(value string) ([][2]int, error) {
+	var ranges [][2]int
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		lo, hi, isRange := strings.Cut(part, "-")
+		if !isRange {
+			hi = lo
+		}
+		low, err1 := strconv.Atoi(strings.TrimSpace(lo))
+		high, err2 := strconv.Atoi(strings.TrimSpace(hi))
+		if err1 != nil || err2 != nil || low < 100 || high > 599 || low > high {
+			return nil, fmt.Errorf("%q is not a status code or range", part)
+		}
+		ranges = append(ranges, [2]int{low, high})
+	}
+	return ranges, nil
+}
+
+// String describes the probe target for logs and errors
+func (p *HealthProbe) String() string {
+	if p.HTTPURL != "" {
+		return "HTTP " + p.HTTPURL
+	}
+	return "TCP " + p.TCPAddress
+}
+
+// Run runs the probe until an attempt passes or all retries fail.
+// host is used for TCP probes given only a port.
+func (p *HealthProbe) Run(ctx context.Context, host string) error {
+	var err error
+	for attempt := 0; attempt <= p.Retries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return fmt.Errorf("health probe %s: %w", p, ctx.Err())
+			case <-time.After(probeRetryInterval):
+			}
+		}
+
+		if err = p.attempt(ctx, host); err == nil {
+			return nil
+		}
+		log.Printf("Health probe %s attempt %d/%d failed: %v", p, attempt+1, p.Retries+1, err)
+		logStep(ctx, "", logSourceHealth, "Health probe %s attempt %d/%d failed: %v", p, attempt+1, p.Retries+1, err)
+	}
+	return fmt.Errorf("health probe %s failed: %w", p, err)
+}
+
+// attempt runs a single probe attempt
+func (p *HealthProbe) attempt(ctx context.Context, host string) error {
+	ctx, cancel := context.WithTimeout(ctx, p.Timeout)
+	defer cancel()
+
+	if p.HTTPURL == "" {
+		address := p.TCPAddress
+		if !strings.Contains(address, ":") {
+			if host == "" {
+				return fmt.Errorf("container has no IP address to probe port %s", address)
+			}
+			address = net.JoinHostPort(host, address)
+		}
+		var dialer net.Dialer
+		conn, err := dialer.DialContext(ctx, "tcp", address)
+		if err != nil {
+			return err
+		}
+		return conn.Close()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.HTTPURL, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if !p.statusAccepted(resp.StatusCode) {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	if p.Body != nil {
+		body, err := io.ReadAll(io.LimitReader(resp.Body, maxProbeBodyBytes))
+		if err != nil {
+			return fmt.Errorf("failed to read response: %w", err)
+		}
+		if !p.Body.Match(body) {
+			return fmt.Errorf("response body does not match %q", p.Body)
+		}
+	}
+	return nil
+}
+
+// statusAccepted reports whether an HTTP status is in one of the accepted ranges
+func (p *HealthProbe) statusAccepted(status int) bool {
+	for _, r := range p.Statuses {
+		if status >= r[0] && status <= r[1] {
+			return true
+		}
+	}
+	return false
+}
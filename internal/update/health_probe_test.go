@@ -0,0 +1,105 @@
+package update
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/chis/docksmith/internal/scripts"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseHealthProbe(t *testing.T) {
+	probe, err := ParseHealthProbe(map[string]string{"other": "x"})
+	require.NoError(t, err)
+	assert.Nil(t, probe, "no probe labels means no probe")
+
+	probe, err = ParseHealthProbe(map[string]string{scripts.HealthcheckHTTPLabel: "https://svc:8443/ready"})
+	require.NoError(t, err)
+	require.NotNil(t, probe)
+	assert.Equal(t, [][2]int{{200, 299}}, probe.Statuses)
+	assert.Equal(t, defaultProbeTimeout, probe.Timeout)
+	assert.Equal(t, defaultProbeRetries, probe.Retries)
+
+	probe, err = ParseHealthProbe(map[string]string{
+		scripts.HealthcheckHTTPLabel:    "http://svc/health",
+		scripts.HealthcheckStatusLabel:  "200, 301-302",
+		scripts.HealthcheckBodyLabel:    `"status":\s*"ok"`,
+		scripts.HealthcheckTimeoutLabel: "10s",
+		scripts.HealthcheckRetriesLabel: "0",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, [][2]int{{200, 200}, {301, 302}}, probe.Statuses)
+	assert.NotNil(t, probe.Body)
+	assert.Equal(t, 10*time.Second, probe.Timeout)
+	assert.Equal(t, 0, probe.Retries)
+
+	invalid := []map[string]string{
+		{scripts.HealthcheckHTTPLabel: "http://svc", scripts.HealthcheckTCPLabel: "5432"},
+		{scripts.HealthcheckHTTPLabel: "svc:8080/ready"},
+		{scripts.HealthcheckHTTPLabel: "http://svc", scripts.HealthcheckStatusLabel: "ok"},
+		{scripts.HealthcheckHTTPLabel: "http://svc", scripts.HealthcheckStatusLabel: "299-200"},
+		{scripts.HealthcheckHTTPLabel: "http://svc", scripts.HealthcheckBodyLabel: "("},
+		{scripts.HealthcheckTCPLabel: "5432", scripts.HealthcheckTimeoutLabel: "soon"},
+		{scripts.HealthcheckTCPLabel: "5432", scripts.HealthcheckRetriesLabel: "-1"},
+	}
+	for _, labels := range invalid {
+		_, err := ParseHealthProbe(labels)
+		assert.Error(t, err, "labels %v should be rejected", labels)
+	}
+}
+
+func TestHealthProbe_HTTP(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/ready":
+			w.Write([]byte(`{"status": "ok"}`))
+		case "/starting":
+			w.Write([]byte(`{"status": "starting"}`))
+		default:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	probe := func(path string, extra map[string]string) *HealthProbe {
+		labels := map[string]string{
+			scripts.HealthcheckHTTPLabel:    server.URL + path,
+			scripts.HealthcheckRetriesLabel: "0",
+		}
+		for k, v := range extra {
+			labels[k] = v
+		}
+		p, err := ParseHealthProbe(labels)
+		require.NoError(t, err)
+		return p
+	}
+	body := map[string]string{scripts.HealthcheckBodyLabel: `"status":\s*"ok"`}
+
+	assert.NoError(t, probe("/ready", body).Run(context.Background(), ""))
+	assert.ErrorContains(t, probe("/starting", body).Run(context.Background(), ""), "does not match")
+	assert.ErrorContains(t, probe("/down", nil).Run(context.Background(), ""), "unexpected status 503")
+	assert.NoError(t, probe("/down", map[string]string{scripts.HealthcheckStatusLabel: "503"}).Run(context.Background(), ""))
+}
+
+func TestHealthProbe_TCP(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	_, port, _ := net.SplitHostPort(listener.Addr().String())
+
+	p, err := ParseHealthProbe(map[string]string{scripts.HealthcheckTCPLabel: port, scripts.HealthcheckRetriesLabel: "0"})
+	require.NoError(t, err)
+	assert.NoError(t, p.Run(context.Background(), "127.0.0.1"), "port-only probe should use the container host")
+	assert.ErrorContains(t, p.Run(context.Background(), ""), "no IP address")
+
+	p, err = ParseHealthProbe(map[string]string{scripts.HealthcheckTCPLabel: listener.Addr().String(), scripts.HealthcheckRetriesLabel: "0"})
+	require.NoError(t, err)
+	assert.NoError(t, p.Run(context.Background(), "10.0.0.1"), "host:port probe should ignore the container host")
+
+	listener.Close()
+	assert.Error(t, p.Run(context.Background(), ""))
+}
@@ -0,0 +1,106 @@
+package update
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+
+	"github.com/chis/docksmith/internal/docker"
+	"github.com/chis/docksmith/internal/storage"
+)
+
+// ValidateIgnorePattern checks that an image ignore rule pattern can be stored.
+func ValidateIgnorePattern(pattern string) error {
+	if strings.TrimSpace(pattern) == "" {
+		return fmt.Errorf("pattern is required")
+	}
+	if strings.ContainsAny(pattern, " \t\n") {
+		return fmt.Errorf("pattern %q cannot contain whitespace", pattern)
+	}
+	if strings.Trim(pattern, "*?") == "" {
+		return fmt.Errorf("pattern %q would ignore every image", pattern)
+	}
+	return nil
+}
+
+// MatchImagePattern reports whether an image matches an ignore rule pattern.
+// "*" matches any run of characters, including "/" and ":", and "?" matches one
+// character. A pattern without a tag also matches every tag of the repository, so
+// "postgres" matches "postgres:16". Docker Hub images also match with their
+// docker.io/ and docker.io/library/ prefixes.
+func MatchImagePattern(pattern, image string) bool {
+	re := globRegexp(pattern)
+	_, patternTag := splitImageRef(strings.SplitN(pattern, "@", 2)[0])
+	tagged := patternTag != "" || strings.Contains(pattern, "@")
+
+	for _, name := range imageNames(image) {
+		if re.MatchString(name) {
+			return true
+		}
+		if !tagged {
+			repo, _ := splitImageRef(strings.SplitN(name, "@", 2)[0])
+			if re.MatchString(repo) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// globRegexp compiles an ignore rule pattern to an anchored regular expression.
+func globRegexp(pattern string) *regexp.Regexp {
+	var b strings.Builder
+	b.WriteString("^")
+	for _, r := range pattern {
+		switch r {
+		case '*':
+			b.WriteString(".*")
+		case '?':
+			b.WriteString(".")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	b.WriteString("$")
+	return regexp.MustCompile(b.String())
+}
+
+// imageNames returns the names an image is matched by: as given and, for Docker
+// Hub images, with and without the registry and library prefixes.
+func imageNames(image string) []string {
+	first, _, hasSlash := strings.Cut(image, "/")
+	if hasSlash && first != "docker.io" && (strings.ContainsAny(first, ".:") || first == "localhost") {
+		return []string{image} // Other registry
+	}
+	short := strings.TrimPrefix(strings.TrimPrefix(image, "docker.io/"), "library/")
+	names := []string{short, "docker.io/" + short}
+	if !strings.Contains(short, "/") {
+		names = append(names, "library/"+short, "docker.io/library/"+short)
+	}
+	return names
+}
+
+// loadIgnoreRules returns the image ignore rules, or none without storage.
+func (c *Checker) loadIgnoreRules(ctx context.Context) []storage.IgnoreRule {
+	if c.storage == nil {
+		return nil
+	}
+	rules, err := c.storage.ListIgnoreRules(ctx)
+	if err != nil {
+		log.Printf("Failed to load image ignore rules: %v", err)
+		return nil
+	}
+	return rules
+}
+
+// matchIgnoreRule returns the first ignore rule matching a container's image.
+func matchIgnoreRule(rules []storage.IgnoreRule, container docker.Container) (storage.IgnoreRule, bool) {
+	for _, rule := range rules {
+		if MatchImagePattern(rule.Pattern, container.Image) {
+			return rule, true
+		}
+	}
+	return storage.IgnoreRule{}, false
+}
@@ -0,0 +1,47 @@
+package update
+
+import (
+	"testing"
+
+	"github.com/chis/docksmith/internal/docker"
+	"github.com/chis/docksmith/internal/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatchImagePattern(t *testing.T) {
+	tests := []struct {
+		pattern, image string
+		want           bool
+	}{
+		{"ghcr.io/home-assistant/*", "ghcr.io/home-assistant/home-assistant:stable", true},
+		{"ghcr.io/home-assistant/*", "ghcr.io/linuxserver/plex:latest", false},
+		{"*:nightly", "ghcr.io/org/app:nightly", true},
+		{"*:nightly", "app:nightly-2024", false},
+		{"postgres", "postgres:16", true},
+		{"postgres", "docker.io/library/postgres:16", true},
+		{"library/postgres:16", "postgres:16", true},
+		{"docker.io/linuxserver/*", "linuxserver/sonarr:4", true},
+		{"postgres:1?", "postgres:16", true},
+		{"postgres:1?", "postgres:9", false},
+		{"plex", "plexinc/pms-docker:latest", false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, MatchImagePattern(tt.pattern, tt.image), "pattern=%s image=%s", tt.pattern, tt.image)
+	}
+}
+
+func TestValidateIgnorePattern(t *testing.T) {
+	assert.NoError(t, ValidateIgnorePattern("*:nightly"))
+	for _, pattern := range []string{"", " ", "*", "**?", "my image"} {
+		assert.Error(t, ValidateIgnorePattern(pattern), "pattern=%q", pattern)
+	}
+}
+
+func TestShouldIgnoreContainerByRule(t *testing.T) {
+	c := &Checker{}
+	rules := []storage.IgnoreRule{{Pattern: "*:nightly"}}
+
+	assert.True(t, c.shouldIgnoreContainer(docker.Container{Name: "app", Image: "org/app:nightly"}, rules))
+	assert.False(t, c.shouldIgnoreContainer(docker.Container{Name: "app", Image: "org/app:1.0"}, rules))
+	assert.False(t, c.shouldIgnoreContainer(docker.Container{Name: "app", Image: "org/app:nightly"}, nil))
+}
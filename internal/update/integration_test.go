@@ -412,7 +412,7 @@ func TestIntegration_QueueProcessingWithConcurrentStacks(t *testing.T) {
 	go orch.processQueue(ctx)
 
 	// Lock stack1
-	acquired := orch.acquireStackLock("stack1")
+	acquired := orch.acquireStackLock("stack1", "holder")
 	assert.True(t, acquired)
 
 	// Try to update app1 (should be queued)
@@ -433,7 +433,7 @@ func TestIntegration_QueueProcessingWithConcurrentStacks(t *testing.T) {
 	assert.NotEmpty(t, op2ID)
 
 	// Release stack1 lock
-	orch.releaseStackLock("stack1")
+	orch.releaseStackLock("stack1", "holder")
 
 	// Wait for queue to process
 	time.Sleep(1 * time.Second)
@@ -0,0 +1,203 @@
+package update
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/chis/docksmith/internal/docker"
+	"github.com/chis/docksmith/internal/scripts"
+)
+
+const (
+	defaultLoadRetryInterval = 30 * time.Second // How often busy containers are checked again
+	defaultLoadMaxWait       = 30 * time.Minute
+)
+
+// LoadDeferralConfig defers updates of containers under heavy load.
+type LoadDeferralConfig struct {
+	CPUPercent    float64       // Defer while CPU usage is above this percent of one CPU, 0 = never
+	MemoryPercent float64       // Defer while memory usage is above this percent of the limit, 0 = never
+	MaxWait       time.Duration // Fail the update if the containers are still busy by then, 0 = 30m
+}
+
+// enabled reports whether a threshold is configured.
+func (c LoadDeferralConfig) enabled() bool {
+	return c.CPUPercent > 0 || c.MemoryPercent > 0
+}
+
+// SetLoadDeferral configures waiting for containers to be below the load thresholds
+// before they are updated. Must be called before any update starts.
+func (o *UpdateOrchestrator) SetLoadDeferral(cfg LoadDeferralConfig) {
+	o.loadDeferral = cfg
+}
+
+// LoadDeferralFromEnv reads the load thresholds from DEFER_UPDATE_CPU_PERCENT and
+// DEFER_UPDATE_MEMORY_PERCENT, and how long to wait for the load to drop from
+// DEFER_UPDATE_MAX_WAIT (default 30m). Unset or invalid thresholds disable deferral.
+func LoadDeferralFromEnv() LoadDeferralConfig {
+	cfg := LoadDeferralConfig{MaxWait: defaultLoadMaxWait}
+	cfg.CPUPercent = percentFromEnv("DEFER_UPDATE_CPU_PERCENT")
+	cfg.MemoryPercent = percentFromEnv("DEFER_UPDATE_MEMORY_PERCENT")
+
+	if value := os.Getenv("DEFER_UPDATE_MAX_WAIT"); value != "" {
+		wait, err := time.ParseDuration(value)
+		if err != nil || wait < 0 {
+			log.Printf("Warning: Invalid DEFER_UPDATE_MAX_WAIT '%s', using %v", value, cfg.MaxWait)
+		} else {
+			log.Printf("Using DEFER_UPDATE_MAX_WAIT: %v", wait)
+			cfg.MaxWait = wait
+		}
+	}
+	return cfg
+}
+
+// percentFromEnv reads a positive percentage from an environment variable. Returns 0 when unset or invalid.
+func percentFromEnv(name string) float64 {
+	value := os.Getenv(name)
+	if value == "" {
+		return 0
+	}
+	percent, err := strconv.ParseFloat(value, 64)
+	if err != nil || percent <= 0 {
+		log.Printf("Warning: Invalid %s '%s', not deferring updates on it", name, value)
+		return 0
+	}
+	log.Printf("Using %s: %g", name, percent)
+	return percent
+}
+
+// defersUnderLoad reports whether a container's updates wait for its load to drop.
+// docksmith.defer-under-load=false opts a container out.
+func (o *UpdateOrchestrator) defersUnderLoad(cont *docker.Container) bool {
+	if !o.loadDeferral.enabled() || cont.State != "running" {
+		return false
+	}
+	value := strings.TrimSpace(cont.Labels[scripts.DeferUnderLoadLabel])
+	if value == "" {
+		return true
+	}
+	enabled, err := strconv.ParseBool(value)
+	if err != nil {
+		log.Printf("UPDATE: Invalid %s %q on %s, deferring under load", scripts.DeferUnderLoadLabel, value, cont.Name)
+		return true
+	}
+	return enabled
+}
+
+// connectionLimit returns how many established connections a container's updates
+// tolerate from its docksmith.defer-connections label, or -1 when they are not checked.
+func (o *UpdateOrchestrator) connectionLimit(cont *docker.Container) int {
+	value := strings.TrimSpace(cont.Labels[scripts.DeferConnectionsLabel])
+	if value == "" || cont.State != "running" {
+		return -1
+	}
+	limit, err := strconv.Atoi(value)
+	if err != nil || limit < 0 {
+		log.Printf("UPDATE: Invalid %s %q on %s, not checking connections", scripts.DeferConnectionsLabel, value, cont.Name)
+		return -1
+	}
+	return limit
+}
+
+// resourceUsage samples a container's load. Tests replace o.sampleUsage.
+func (o *UpdateOrchestrator) resourceUsage(ctx context.Context, cont *docker.Container) (*docker.ResourceUsage, error) {
+	if o.sampleUsage != nil {
+		return o.sampleUsage(ctx, cont)
+	}
+	if o.dockerSDK == nil {
+		return nil, fmt.Errorf("docker client not available")
+	}
+	return docker.GetResourceUsage(ctx, o.dockerSDK, cont.ID)
+}
+
+// connections counts a container's established connections. Tests replace o.countConnections.
+func (o *UpdateOrchestrator) connections(ctx context.Context, cont *docker.Container) (int, error) {
+	if o.countConnections != nil {
+		return o.countConnections(ctx, cont)
+	}
+	if o.dockerSDK == nil {
+		return 0, fmt.Errorf("docker client not available")
+	}
+	return docker.CountConnections(ctx, o.dockerSDK, cont.ID)
+}
+
+// busyContainers returns a description of each container above the load thresholds
+// or its connection limit. Containers that cannot be sampled are not considered busy.
+func (o *UpdateOrchestrator) busyContainers(ctx context.Context, containers []*docker.Container) []string {
+	var busy []string
+	for _, c := range containers {
+		if limit := o.connectionLimit(c); limit >= 0 {
+			count, err := o.connections(ctx, c)
+			if err != nil {
+				log.Printf("UPDATE: Not deferring %s, failed to count its connections: %v", c.Name, err)
+			} else if count > limit {
+				busy = append(busy, fmt.Sprintf("%s (%d active connections)", c.Name, count))
+				continue
+			}
+		}
+
+		if !o.defersUnderLoad(c) {
+			continue
+		}
+		usage, err := o.resourceUsage(ctx, c)
+		if err != nil {
+			log.Printf("UPDATE: Not deferring %s, failed to sample its load: %v", c.Name, err)
+			continue
+		}
+		switch {
+		case o.loadDeferral.CPUPercent > 0 && usage.CPUPercent > o.loadDeferral.CPUPercent:
+			busy = append(busy, fmt.Sprintf("%s (CPU %.0f%%)", c.Name, usage.CPUPercent))
+		case o.loadDeferral.MemoryPercent > 0 && usage.MemoryPercent > o.loadDeferral.MemoryPercent:
+			busy = append(busy, fmt.Sprintf("%s (memory %.0f%%)", c.Name, usage.MemoryPercent))
+		}
+	}
+	return busy
+}
+
+// waitForLowLoad defers an update while any of its containers is above the load
+// thresholds or has more active connections than its docksmith.defer-connections
+// limit, checking again every retry interval. It fails once the containers are
+// still busy after the configured maximum wait.
+func (o *UpdateOrchestrator) waitForLowLoad(ctx context.Context, operationID, containerName, stackName string, containers []*docker.Container) error {
+	checked := false
+	for _, c := range containers {
+		checked = checked || o.defersUnderLoad(c) || o.connectionLimit(c) >= 0
+	}
+	if !checked {
+		return nil
+	}
+	interval := o.loadRetryInterval
+	if interval <= 0 {
+		interval = defaultLoadRetryInterval
+	}
+	maxWait := o.loadDeferral.MaxWait
+	if maxWait <= 0 {
+		maxWait = defaultLoadMaxWait
+	}
+
+	deadline := time.Now().Add(maxWait)
+	for {
+		busy := o.busyContainers(ctx, containers)
+		if len(busy) == 0 {
+			return nil
+		}
+		if !time.Now().Add(interval).Before(deadline) {
+			return fmt.Errorf("still busy after %v: %s", maxWait, strings.Join(busy, ", "))
+		}
+
+		log.Printf("UPDATE: Deferring operation=%s, busy: %s", operationID, strings.Join(busy, ", "))
+		o.publishProgress(operationID, containerName, stackName, "deferred", 0,
+			fmt.Sprintf("Busy (%s), retrying in %v", strings.Join(busy, ", "), interval))
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(interval):
+		}
+	}
+}
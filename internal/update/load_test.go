@@ -0,0 +1,101 @@
+package update
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/chis/docksmith/internal/docker"
+	"github.com/chis/docksmith/internal/scripts"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newLoadTestOrchestrator returns an orchestrator whose containers report the CPU
+// usages in cpu, one sample per call; the last sample repeats.
+func newLoadTestOrchestrator(cpu map[string][]float64) (*UpdateOrchestrator, map[string]int) {
+	calls := make(map[string]int)
+	o := &UpdateOrchestrator{
+		loadDeferral:      LoadDeferralConfig{CPUPercent: 80, MaxWait: 50 * time.Millisecond},
+		loadRetryInterval: 10 * time.Millisecond,
+	}
+	o.sampleUsage = func(ctx context.Context, c *docker.Container) (*docker.ResourceUsage, error) {
+		samples, ok := cpu[c.Name]
+		if !ok {
+			return nil, fmt.Errorf("no stats")
+		}
+		i := min(calls[c.Name], len(samples)-1)
+		calls[c.Name]++
+		return &docker.ResourceUsage{CPUPercent: samples[i]}, nil
+	}
+	return o, calls
+}
+
+func TestWaitForLowLoad(t *testing.T) {
+	ctx := context.Background()
+	app := &docker.Container{Name: "app", State: "running"}
+
+	// The update waits until the load drops
+	o, calls := newLoadTestOrchestrator(map[string][]float64{"app": {95, 90, 20}})
+	require.NoError(t, o.waitForLowLoad(ctx, "op", "app", "", []*docker.Container{app}))
+	assert.Equal(t, 3, calls["app"])
+
+	// And fails when it does not drop in time
+	o, _ = newLoadTestOrchestrator(map[string][]float64{"app": {95}})
+	err := o.waitForLowLoad(ctx, "op", "app", "", []*docker.Container{app})
+	assert.EqualError(t, err, "still busy after 50ms: app (CPU 95%)")
+
+	// Containers that opt out, are stopped, or cannot be sampled are not waited for
+	optedOut := &docker.Container{Name: "app", State: "running", Labels: map[string]string{scripts.DeferUnderLoadLabel: "false"}}
+	stopped := &docker.Container{Name: "app", State: "exited"}
+	unknown := &docker.Container{Name: "other", State: "running"}
+	o, calls = newLoadTestOrchestrator(map[string][]float64{"app": {95}})
+	assert.NoError(t, o.waitForLowLoad(ctx, "op", "", "stack", []*docker.Container{optedOut, stopped, unknown}))
+	assert.Zero(t, calls["app"])
+
+	// Nothing is sampled without a threshold
+	o, calls = newLoadTestOrchestrator(map[string][]float64{"app": {95}})
+	o.loadDeferral = LoadDeferralConfig{}
+	assert.NoError(t, o.waitForLowLoad(ctx, "op", "app", "", []*docker.Container{app}))
+	assert.Zero(t, calls["app"])
+}
+
+func TestBusyContainers_Memory(t *testing.T) {
+	o := &UpdateOrchestrator{loadDeferral: LoadDeferralConfig{MemoryPercent: 90}}
+	o.sampleUsage = func(ctx context.Context, c *docker.Container) (*docker.ResourceUsage, error) {
+		return &docker.ResourceUsage{CPUPercent: 300, MemoryPercent: 97}, nil
+	}
+	busy := o.busyContainers(context.Background(), []*docker.Container{{Name: "db", State: "running"}})
+	assert.Equal(t, []string{"db (memory 97%)"}, busy)
+}
+
+func TestWaitForLowLoad_Connections(t *testing.T) {
+	ctx := context.Background()
+	plex := &docker.Container{Name: "plex", State: "running", Labels: map[string]string{scripts.DeferConnectionsLabel: "0"}}
+
+	// Connections are checked without load thresholds, until the streams end
+	counts := []int{2, 1, 0}
+	calls := 0
+	o := &UpdateOrchestrator{loadRetryInterval: 10 * time.Millisecond}
+	o.countConnections = func(ctx context.Context, c *docker.Container) (int, error) {
+		count := counts[min(calls, len(counts)-1)]
+		calls++
+		return count, nil
+	}
+	require.NoError(t, o.waitForLowLoad(ctx, "op", "plex", "", []*docker.Container{plex}))
+	assert.Equal(t, 3, calls)
+
+	// More connections than the label allows keep the update waiting
+	o.loadDeferral.MaxWait = 30 * time.Millisecond
+	counts, calls = []int{3}, 0
+	plex.Labels[scripts.DeferConnectionsLabel] = "2"
+	err := o.waitForLowLoad(ctx, "op", "plex", "", []*docker.Container{plex})
+	assert.EqualError(t, err, "still busy after 30ms: plex (3 active connections)")
+
+	// Invalid limits are ignored
+	calls = 0
+	plex.Labels[scripts.DeferConnectionsLabel] = "none"
+	assert.NoError(t, o.waitForLowLoad(ctx, "op", "plex", "", []*docker.Container{plex}))
+	assert.Zero(t, calls)
+}
@@ -0,0 +1,331 @@
+package update
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/chis/docksmith/internal/storage"
+)
+
+// defaultLockTimeout is how long an operation may hold a stack lock before it expires.
+const defaultLockTimeout = 2 * time.Hour
+
+// orphanGrace is how long a lock may outlive its operation before it is released.
+const orphanGrace = 5 * time.Minute
+
+// ErrStackLockInUse is returned when releasing a lock whose operation is still running without force.
+var ErrStackLockInUse = errors.New("stack lock is held by a running operation")
+
+// StackLock describes a held stack lock.
+type StackLock struct {
+	Stack           string    `json:"stack"`
+	OperationID     string    `json:"operation_id"`
+	OperationStatus string    `json:"operation_status,omitempty"` // Status of the holding operation, if recorded
+	AcquiredAt      time.Time `json:"acquired_at"`
+	HeldFor         string    `json:"held_for"`
+	ExpiresAt       string    `json:"expires_at,omitempty"` // ISO 8601, unless locks never expire
+}
+
+// SetLockTimeout sets how long an operation may hold a stack lock before it is
+// released automatically. Zero keeps locks until their operation releases them.
+func (o *UpdateOrchestrator) SetLockTimeout(timeout time.Duration) {
+	o.locksMu.Lock()
+	defer o.locksMu.Unlock()
+	o.lockTimeout = timeout
+}
+
+// LockTimeoutFromEnv reads the stack lock timeout from STACK_LOCK_TIMEOUT.
+// Returns the 2h default when unset or invalid; "0" disables expiry.
+func LockTimeoutFromEnv() time.Duration {
+	value := os.Getenv("STACK_LOCK_TIMEOUT")
+	if value == "" {
+		return defaultLockTimeout
+	}
+	timeout, err := time.ParseDuration(value)
+	if value == "0" {
+		timeout, err = 0, nil
+	}
+	if err != nil || timeout < 0 {
+		log.Printf("Warning: Invalid STACK_LOCK_TIMEOUT '%s', using default %v", value, defaultLockTimeout)
+		return defaultLockTimeout
+	}
+	log.Printf("Using STACK_LOCK_TIMEOUT: %v", timeout)
+	return timeout
+}
+
+// acquireStackLock attempts to acquire a stack's lock for an operation.
+func (o *UpdateOrchestrator) acquireStackLock(stackName, operationID string) bool {
+	o.locksMu.Lock()
+	defer o.locksMu.Unlock()
+
+	entry, exists := o.stackLocks[stackName]
+	if !exists {
+		entry = &stackLockEntry{}
+		o.stackLocks[stackName] = entry
+	}
+	if entry.held {
+		return false
+	}
+
+	now := time.Now()
+	entry.held = true
+	entry.operationID = operationID
+	entry.acquiredAt = now
+	entry.lastUsed = now
+	o.persistStackLock(storage.StackLock{StackName: stackName, OperationID: operationID, AcquiredAt: now})
+	return true
+}
+
+// releaseStackLock releases a stack's lock if operationID still holds it. A lock
+// that was force-released or expired may have been taken by another operation
+// since, which keeps it.
+func (o *UpdateOrchestrator) releaseStackLock(stackName, operationID string) {
+	o.locksMu.Lock()
+	defer o.locksMu.Unlock()
+
+	entry, exists := o.stackLocks[stackName]
+	if !exists || !entry.held {
+		return
+	}
+	if entry.operationID != operationID {
+		log.Printf("LOCK: Operation %s no longer holds the lock on stack %s (held by %s)", operationID, stackName, entry.operationID)
+		return
+	}
+	o.unlockEntry(stackName, entry)
+}
+
+// unlockEntry marks a lock released and removes its record. The caller must hold locksMu.
+func (o *UpdateOrchestrator) unlockEntry(stackName string, entry *stackLockEntry) {
+	operationID := entry.operationID
+	entry.held = false
+	entry.operationID = ""
+	entry.acquiredAt = time.Time{}
+	entry.lastUsed = time.Now()
+
+	if o.storage != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := o.storage.DeleteStackLock(ctx, stackName, operationID); err != nil {
+			log.Printf("LOCK: Failed to remove lock record of stack %s: %v", stackName, err)
+		}
+	}
+}
+
+// persistStackLock records a lock holder. The caller must hold locksMu, so
+// records are written in the order locks change hands.
+func (o *UpdateOrchestrator) persistStackLock(lock storage.StackLock) {
+	if o.storage == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := o.storage.SaveStackLock(ctx, lock); err != nil {
+		log.Printf("LOCK: Failed to record lock of stack %s: %v", lock.StackName, err)
+	}
+}
+
+// IsStackLocked reports whether an operation currently holds a stack's lock.
+func (o *UpdateOrchestrator) IsStackLocked(stackName string) bool {
+	o.locksMu.Lock()
+	defer o.locksMu.Unlock()
+	entry, exists := o.stackLocks[stackName]
+	return exists && entry.held
+}
+
+// StackLocks returns the held stack locks, ordered by stack name.
+func (o *UpdateOrchestrator) StackLocks(ctx context.Context) []StackLock {
+	o.locksMu.Lock()
+	now := time.Now()
+	var locks []StackLock
+	for stackName, entry := range o.stackLocks {
+		if !entry.held {
+			continue
+		}
+		lock := StackLock{
+			Stack:       stackName,
+			OperationID: entry.operationID,
+			AcquiredAt:  entry.acquiredAt,
+			HeldFor:     now.Sub(entry.acquiredAt).Round(time.Second).String(),
+		}
+		if o.lockTimeout > 0 {
+			lock.ExpiresAt = entry.acquiredAt.Add(o.lockTimeout).Format(time.RFC3339)
+		}
+		locks = append(locks, lock)
+	}
+	o.locksMu.Unlock()
+
+	for i := range locks {
+		locks[i].OperationStatus = o.operationStatus(ctx, locks[i].OperationID)
+	}
+	slices.SortFunc(locks, func(a, b StackLock) int { return strings.Compare(a.Stack, b.Stack) })
+	return locks
+}
+
+// ReleaseStackLock releases a stack's lock on request. Without force the lock is
+// only released when its operation has finished or was never recorded; with force
+// it is released even while the operation runs, which lets the next operation on
+// the stack start alongside it.
+func (o *UpdateOrchestrator) ReleaseStackLock(ctx context.Context, stackName string, force bool) (StackLock, error) {
+	o.locksMu.Lock()
+	entry, exists := o.stackLocks[stackName]
+	if !exists || !entry.held {
+		o.locksMu.Unlock()
+		return StackLock{}, NewNotFoundError("stack %s is not locked", stackName)
+	}
+	lock := StackLock{
+		Stack:       stackName,
+		OperationID: entry.operationID,
+		AcquiredAt:  entry.acquiredAt,
+		HeldFor:     time.Since(entry.acquiredAt).Round(time.Second).String(),
+	}
+	o.locksMu.Unlock()
+
+	lock.OperationStatus = o.operationStatus(ctx, lock.OperationID)
+	if !force && !operationFinished(lock.OperationStatus) {
+		return lock, fmt.Errorf("%w: operation %s is %s; release with force to override", ErrStackLockInUse, lock.OperationID, lock.OperationStatus)
+	}
+
+	o.locksMu.Lock()
+	defer o.locksMu.Unlock()
+	// The lock may have changed hands while the operation was looked up
+	if !entry.held || entry.operationID != lock.OperationID {
+		return lock, nil
+	}
+	o.unlockEntry(stackName, entry)
+	log.Printf("LOCK: Released lock on stack %s held by operation %s for %s (force=%v)", stackName, lock.OperationID, lock.HeldFor, force)
+	return lock, nil
+}
+
+// operationStatus returns the recorded status of an operation, or "" when it is not recorded.
+func (o *UpdateOrchestrator) operationStatus(ctx context.Context, operationID string) string {
+	if o.storage == nil || operationID == "" {
+		return ""
+	}
+	op, found, err := o.storage.GetUpdateOperation(ctx, operationID)
+	if err != nil || !found {
+		return ""
+	}
+	return op.Status
+}
+
+// operationFinished reports whether an operation with status no longer needs its lock.
+// Operations that were never recorded count as finished.
+func operationFinished(status string) bool {
+	switch status {
+	case "", storage.StatusComplete, storage.StatusFailed, "cancelled":
+		return true
+	}
+	return false
+}
+
+// clearLockRecords removes the lock records left by a previous run. Locks only
+// live in memory, so every record predates the restart.
+func (o *UpdateOrchestrator) clearLockRecords(ctx context.Context) {
+	if o.storage == nil {
+		return
+	}
+	records, err := o.storage.ListStackLocks(ctx)
+	if err != nil {
+		log.Printf("LOCK: Failed to read lock records: %v", err)
+		return
+	}
+	for _, record := range records {
+		log.Printf("LOCK: Clearing lock on stack %s held by operation %s since %s before restart",
+			record.StackName, record.OperationID, record.AcquiredAt.Format(time.RFC3339))
+		if err := o.storage.DeleteStackLock(ctx, record.StackName, record.OperationID); err != nil {
+			log.Printf("LOCK: Failed to remove lock record of stack %s: %v", record.StackName, err)
+		}
+	}
+}
+
+// expireStaleLocks releases locks held longer than the lock timeout, and locks
+// whose operation finished more than orphanGrace ago without releasing them.
+func (o *UpdateOrchestrator) expireStaleLocks(ctx context.Context) {
+	now := time.Now()
+	o.locksMu.Lock()
+	type heldLock struct {
+		stack, operationID string
+		acquiredAt         time.Time
+	}
+	var held []heldLock
+	for stackName, entry := range o.stackLocks {
+		if entry.held {
+			held = append(held, heldLock{stackName, entry.operationID, entry.acquiredAt})
+		}
+	}
+	timeout := o.lockTimeout
+	o.locksMu.Unlock()
+
+	for _, lock := range held {
+		age := now.Sub(lock.acquiredAt)
+		var reason string
+		switch {
+		case timeout > 0 && age > timeout:
+			reason = fmt.Sprintf("held for %s, longer than the %s timeout", age.Round(time.Second), timeout)
+		case age > orphanGrace && o.orphaned(ctx, lock.operationID, now):
+			reason = "its operation has finished"
+		default:
+			continue
+		}
+
+		o.locksMu.Lock()
+		if entry := o.stackLocks[lock.stack]; entry != nil && entry.held && entry.operationID == lock.operationID {
+			o.unlockEntry(lock.stack, entry)
+			log.Printf("LOCK: Expired lock on stack %s held by operation %s: %s", lock.stack, lock.operationID, reason)
+		}
+		o.locksMu.Unlock()
+	}
+}
+
+// orphaned reports whether a recorded operation finished more than orphanGrace ago.
+func (o *UpdateOrchestrator) orphaned(ctx context.Context, operationID string, now time.Time) bool {
+	if o.storage == nil {
+		return false
+	}
+	op, found, err := o.storage.GetUpdateOperation(ctx, operationID)
+	if err != nil || !found || !operationFinished(op.Status) {
+		return false
+	}
+	return op.CompletedAt != nil && now.Sub(*op.CompletedAt) > orphanGrace
+}
+
+// cleanupStaleLocks clears the lock records of the previous run, then periodically
+// expires stale locks and removes unused entries. This prevents unbounded memory
+// growth from accumulating locks for stacks that no longer exist.
+func (o *UpdateOrchestrator) cleanupStaleLocks(ctx context.Context) {
+	const (
+		cleanupInterval = time.Minute
+		staleThreshold  = 30 * time.Minute
+	)
+
+	o.clearLockRecords(ctx)
+
+	ticker := time.NewTicker(cleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			o.expireStaleLocks(ctx)
+
+			o.locksMu.Lock()
+			now := time.Now()
+			for stackName, entry := range o.stackLocks {
+				// Only remove if lock is not held and hasn't been used recently
+				if !entry.held && now.Sub(entry.lastUsed) > staleThreshold {
+					delete(o.stackLocks, stackName)
+					log.Printf("CLEANUP: Removed stale stack lock for %s", stackName)
+				}
+			}
+			o.locksMu.Unlock()
+		}
+	}
+}
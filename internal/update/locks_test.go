@@ -0,0 +1,137 @@
+package update
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/chis/docksmith/internal/storage"
+)
+
+func newLockTestOrchestrator(t *testing.T) (*UpdateOrchestrator, storage.Storage) {
+	t.Helper()
+	store := storage.NewMemoryStorage()
+	return &UpdateOrchestrator{
+		storage:     store,
+		stackLocks:  make(map[string]*stackLockEntry),
+		lockTimeout: defaultLockTimeout,
+	}, store
+}
+
+func TestStackLocks_RecordsHolders(t *testing.T) {
+	orch, store := newLockTestOrchestrator(t)
+	ctx := context.Background()
+	require.NoError(t, store.SaveUpdateOperation(ctx, storage.UpdateOperation{OperationID: "op-1", Status: storage.StatusPullingImage}))
+
+	assert.True(t, orch.acquireStackLock("media", "op-1"))
+	assert.False(t, orch.acquireStackLock("media", "op-2"))
+
+	locks := orch.StackLocks(ctx)
+	require.Len(t, locks, 1)
+	assert.Equal(t, "media", locks[0].Stack)
+	assert.Equal(t, "op-1", locks[0].OperationID)
+	assert.Equal(t, storage.StatusPullingImage, locks[0].OperationStatus)
+	assert.NotEmpty(t, locks[0].ExpiresAt)
+
+	records, err := store.ListStackLocks(ctx)
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	assert.Equal(t, "op-1", records[0].OperationID)
+
+	orch.releaseStackLock("media", "op-1")
+	assert.Empty(t, orch.StackLocks(ctx))
+	records, _ = store.ListStackLocks(ctx)
+	assert.Empty(t, records)
+}
+
+func TestReleaseStackLock(t *testing.T) {
+	orch, store := newLockTestOrchestrator(t)
+	ctx := context.Background()
+	require.NoError(t, store.SaveUpdateOperation(ctx, storage.UpdateOperation{OperationID: "op-1", Status: storage.StatusHealthCheck}))
+
+	_, err := orch.ReleaseStackLock(ctx, "media", false)
+	var notFound *NotFoundError
+	assert.ErrorAs(t, err, &notFound)
+
+	orch.acquireStackLock("media", "op-1")
+
+	// A running operation keeps its lock unless forced
+	_, err = orch.ReleaseStackLock(ctx, "media", false)
+	assert.ErrorIs(t, err, ErrStackLockInUse)
+	assert.True(t, orch.IsStackLocked("media"))
+
+	lock, err := orch.ReleaseStackLock(ctx, "media", true)
+	require.NoError(t, err)
+	assert.Equal(t, "op-1", lock.OperationID)
+	assert.False(t, orch.IsStackLocked("media"))
+
+	// The next operation takes the lock, and the hung one finishing later leaves it alone
+	assert.True(t, orch.acquireStackLock("media", "op-2"))
+	orch.releaseStackLock("media", "op-1")
+	assert.True(t, orch.IsStackLocked("media"))
+
+	// A finished operation's lock is released without force
+	require.NoError(t, store.SaveUpdateOperation(ctx, storage.UpdateOperation{OperationID: "op-2", Status: storage.StatusFailed}))
+	_, err = orch.ReleaseStackLock(ctx, "media", false)
+	require.NoError(t, err)
+	assert.False(t, orch.IsStackLocked("media"))
+}
+
+func TestExpireStaleLocks(t *testing.T) {
+	orch, store := newLockTestOrchestrator(t)
+	ctx := context.Background()
+	finished := time.Now().Add(-10 * time.Minute)
+	require.NoError(t, store.SaveUpdateOperation(ctx, storage.UpdateOperation{OperationID: "done", Status: storage.StatusComplete, CompletedAt: &finished}))
+	require.NoError(t, store.SaveUpdateOperation(ctx, storage.UpdateOperation{OperationID: "running", Status: storage.StatusPullingImage}))
+
+	orch.SetLockTimeout(time.Hour)
+	orch.acquireStackLock("orphaned", "done")
+	orch.acquireStackLock("timed-out", "running")
+	orch.acquireStackLock("recent", "running")
+	orch.acquireStackLock("long-running", "running")
+	orch.stackLocks["orphaned"].acquiredAt = time.Now().Add(-20 * time.Minute)
+	orch.stackLocks["timed-out"].acquiredAt = time.Now().Add(-2 * time.Hour)
+	orch.stackLocks["long-running"].acquiredAt = time.Now().Add(-30 * time.Minute)
+
+	orch.expireStaleLocks(ctx)
+
+	assert.False(t, orch.IsStackLocked("orphaned"))
+	assert.False(t, orch.IsStackLocked("timed-out"))
+	assert.True(t, orch.IsStackLocked("recent"))
+	assert.True(t, orch.IsStackLocked("long-running"))
+
+	// Without a timeout only orphaned locks expire
+	orch.SetLockTimeout(0)
+	orch.stackLocks["long-running"].acquiredAt = time.Now().Add(-48 * time.Hour)
+	orch.expireStaleLocks(ctx)
+	assert.True(t, orch.IsStackLocked("long-running"))
+}
+
+func TestClearLockRecords(t *testing.T) {
+	orch, store := newLockTestOrchestrator(t)
+	ctx := context.Background()
+	require.NoError(t, store.SaveStackLock(ctx, storage.StackLock{StackName: "media", OperationID: "op-1", AcquiredAt: time.Now()}))
+
+	orch.clearLockRecords(ctx)
+
+	records, err := store.ListStackLocks(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, records)
+}
+
+func TestLockTimeoutFromEnv(t *testing.T) {
+	tests := map[string]time.Duration{
+		"":        defaultLockTimeout,
+		"30m":     30 * time.Minute,
+		"0":       0,
+		"invalid": defaultLockTimeout,
+		"-1h":     defaultLockTimeout,
+	}
+	for value, want := range tests {
+		t.Setenv("STACK_LOCK_TIMEOUT", value)
+		assert.Equal(t, want, LockTimeoutFromEnv(), value)
+	}
+}
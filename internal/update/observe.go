@@ -0,0 +1,253 @@
+package update
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/chis/docksmith/internal/docker"
+	"github.com/chis/docksmith/internal/events"
+	"github.com/chis/docksmith/internal/scripts"
+	"github.com/chis/docksmith/internal/storage"
+)
+
+const (
+	defaultObserveMaxRestarts = 3
+	defaultObserveInterval    = 30 * time.Second // How often observed containers are inspected
+)
+
+// ObservationConfig watches containers for restarts after they are updated, since
+// many failures only show once the health check has passed.
+type ObservationConfig struct {
+	Window      time.Duration // How long updated containers are watched, 0 = not watched
+	MaxRestarts int           // Restarts tolerated within the window before a crash loop is flagged
+}
+
+// SetObservation configures watching updated containers for crash loops.
+// Must be called before any update starts.
+func (o *UpdateOrchestrator) SetObservation(cfg ObservationConfig) {
+	o.observation = cfg
+}
+
+// ObservationFromEnv reads the observation window from POST_UPDATE_OBSERVE_WINDOW
+// (default 0, not watched) and the restarts tolerated within it from
+// POST_UPDATE_MAX_RESTARTS (default 3).
+func ObservationFromEnv() ObservationConfig {
+	cfg := ObservationConfig{MaxRestarts: defaultObserveMaxRestarts}
+
+	if value := os.Getenv("POST_UPDATE_OBSERVE_WINDOW"); value != "" {
+		window, err := time.ParseDuration(value)
+		if err != nil || window < 0 {
+			log.Printf("Warning: Invalid POST_UPDATE_OBSERVE_WINDOW '%s', not watching updated containers", value)
+		} else {
+			log.Printf("Using POST_UPDATE_OBSERVE_WINDOW: %v", window)
+			cfg.Window = window
+		}
+	}
+	if value := os.Getenv("POST_UPDATE_MAX_RESTARTS"); value != "" {
+		restarts, err := strconv.Atoi(value)
+		if err != nil || restarts < 0 {
+			log.Printf("Warning: Invalid POST_UPDATE_MAX_RESTARTS '%s', using default %d", value, cfg.MaxRestarts)
+		} else {
+			log.Printf("Using POST_UPDATE_MAX_RESTARTS: %d", restarts)
+			cfg.MaxRestarts = restarts
+		}
+	}
+	return cfg
+}
+
+// observeWindowFor returns how long a container is watched after an update. The
+// docksmith.observe-window label overrides the configured window.
+func (o *UpdateOrchestrator) observeWindowFor(cont *docker.Container) time.Duration {
+	value := strings.TrimSpace(cont.Labels[scripts.ObserveWindowLabel])
+	if value == "" {
+		return o.observation.Window
+	}
+	if value == "0" {
+		return 0
+	}
+	window, err := time.ParseDuration(value)
+	if err != nil || window < 0 {
+		log.Printf("UPDATE: Invalid %s %q on %s, using %v", scripts.ObserveWindowLabel, value, cont.Name, o.observation.Window)
+		return o.observation.Window
+	}
+	return window
+}
+
+// containerRestarts returns the ID and restart count of a container. Tests replace o.inspectRestarts.
+func (o *UpdateOrchestrator) containerRestarts(ctx context.Context, containerName string) (string, int, error) {
+	if o.inspectRestarts != nil {
+		return o.inspectRestarts(ctx, containerName)
+	}
+	if o.dockerSDK == nil {
+		return "", 0, fmt.Errorf("docker client not available")
+	}
+	inspect, err := o.dockerSDK.ContainerInspect(ctx, containerName)
+	if err != nil {
+		return "", 0, err
+	}
+	return inspect.ID, inspect.RestartCount, nil
+}
+
+// observeUpdated starts watching the containers of a completed update for restarts.
+// Rollbacks are not watched, so a crash loop cannot roll back its own rollback.
+func (o *UpdateOrchestrator) observeUpdated(operationID string, containers []*docker.Container) {
+	ctx := o.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if op, found, _ := o.storage.GetUpdateOperation(ctx, operationID); found && op.OperationType == "rollback" {
+		return
+	}
+
+	for _, cont := range containers {
+		window := o.observeWindowFor(cont)
+		if window <= 0 {
+			continue
+		}
+		containerID, baseline, err := o.containerRestarts(ctx, cont.Name)
+		if err != nil {
+			log.Printf("UPDATE: Not watching %s for restarts: %v", cont.Name, err)
+			continue
+		}
+
+		obs := storage.PostUpdateObservation{
+			ContainerName: cont.Name,
+			Status:        "observing",
+			MaxRestarts:   o.observation.MaxRestarts,
+			WindowSeconds: int(window.Seconds()),
+			StartedAt:     time.Now(),
+		}
+		o.saveObservation(ctx, operationID, obs)
+		log.Printf("UPDATE: Watching %s for restarts for %v (operation=%s)", cont.Name, window, operationID)
+
+		go o.observeContainer(ctx, operationID, containerID, baseline, window, obs)
+	}
+}
+
+// observeContainer samples a container's restart count until its observation window
+// ends, flagging a crash loop once it restarts more than the tolerated number of times.
+func (o *UpdateOrchestrator) observeContainer(ctx context.Context, operationID, containerID string, baseline int, window time.Duration, obs storage.PostUpdateObservation) {
+	interval := o.observeInterval
+	if interval <= 0 {
+		interval = defaultObserveInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	deadline := time.NewTimer(window)
+	defer deadline.Stop()
+
+	finish := func(status, message string) {
+		now := time.Now()
+		obs.Status = status
+		obs.Message = message
+		obs.CompletedAt = &now
+		// The orchestrator context may be cancelled already
+		o.saveObservation(context.Background(), operationID, obs)
+	}
+
+	for {
+		done := false
+		select {
+		case <-ctx.Done():
+			finish("interrupted", "Docksmith stopped before the observation window ended")
+			return
+		case <-ticker.C:
+		case <-deadline.C:
+			done = true
+		}
+
+		id, count, err := o.containerRestarts(ctx, obs.ContainerName)
+		switch {
+		case err != nil:
+			log.Printf("UPDATE: Failed to inspect %s for restarts: %v", obs.ContainerName, err)
+		case id != containerID:
+			finish("interrupted", "Container was recreated during the observation window")
+			return
+		case count-baseline != obs.Restarts:
+			obs.Restarts = count - baseline
+			if obs.Restarts > obs.MaxRestarts {
+				message := fmt.Sprintf("Restarted %d times within %v of the update", obs.Restarts, window)
+				obs.RolledBack = o.handleCrashLoop(ctx, operationID, obs)
+				finish("crash_loop", message)
+				return
+			}
+			o.saveObservation(ctx, operationID, obs)
+		}
+
+		if done {
+			finish("passed", fmt.Sprintf("%d restart(s) within %v of the update", obs.Restarts, window))
+			return
+		}
+	}
+}
+
+// handleCrashLoop publishes an EventCrashLoop event for a container that restarted too
+// often after an update, and rolls it back when its rollback policy enables automatic
+// rollbacks. Returns whether a rollback was started.
+func (o *UpdateOrchestrator) handleCrashLoop(ctx context.Context, operationID string, obs storage.PostUpdateObservation) bool {
+	log.Printf("UPDATE: %s restarted %d times after operation=%s, crash loop detected", obs.ContainerName, obs.Restarts, operationID)
+
+	rolledBack := false
+	if enabled, err := o.shouldAutoRollback(ctx, obs.ContainerName); err != nil {
+		log.Printf("UPDATE: Failed to resolve rollback policy for %s: %v", obs.ContainerName, err)
+	} else if enabled {
+		ctx := WithTrigger(ctx, TriggerCrashLoop)
+		var rollbackOpID string
+		op, found, _ := o.storage.GetUpdateOperation(ctx, operationID)
+		if found && len(op.BatchDetails) > 1 {
+			rollbackOpID, err = o.RollbackContainers(ctx, operationID, []string{obs.ContainerName}, true)
+		} else {
+			rollbackOpID, err = o.RollbackOperation(ctx, operationID, true)
+		}
+		if err != nil {
+			log.Printf("UPDATE: Rollback of crash looping %s failed: %v", obs.ContainerName, err)
+		} else {
+			log.Printf("UPDATE: Started rollback operation=%s for crash looping %s", rollbackOpID, obs.ContainerName)
+			rolledBack = true
+		}
+	}
+
+	if o.eventBus != nil {
+		o.eventBus.Publish(events.Event{
+			Type: events.EventCrashLoop,
+			Payload: map[string]interface{}{
+				"operation_id":   operationID,
+				"container_name": obs.ContainerName,
+				"restarts":       obs.Restarts,
+				"max_restarts":   obs.MaxRestarts,
+				"rolled_back":    rolledBack,
+				"timestamp":      time.Now().Unix(),
+			},
+		})
+	}
+	return rolledBack
+}
+
+// saveObservation records the observation of a container on its operation.
+func (o *UpdateOrchestrator) saveObservation(ctx context.Context, operationID string, obs storage.PostUpdateObservation) {
+	o.batchDetailMu.Lock()
+	defer o.batchDetailMu.Unlock()
+
+	op, found, err := o.storage.GetUpdateOperation(ctx, operationID)
+	if err != nil || !found {
+		return
+	}
+	replaced := false
+	for i := range op.Observations {
+		if op.Observations[i].ContainerName == obs.ContainerName {
+			op.Observations[i] = obs
+			replaced = true
+		}
+	}
+	if !replaced {
+		op.Observations = append(op.Observations, obs)
+	}
+	if err := o.storage.SaveUpdateOperation(ctx, op); err != nil {
+		log.Printf("UPDATE: Failed to save observation of %s: %v", obs.ContainerName, err)
+	}
+}
@@ -0,0 +1,137 @@
+package update
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/chis/docksmith/internal/docker"
+	"github.com/chis/docksmith/internal/events"
+	"github.com/chis/docksmith/internal/scripts"
+	"github.com/chis/docksmith/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newObserveTestOrchestrator returns an orchestrator watching the app container,
+// whose restart count (and container ID, when given) advances through restarts, one
+// sample per inspect; the last sample repeats.
+func newObserveTestOrchestrator(t *testing.T, restarts []int, containerIDs ...string) (*UpdateOrchestrator, storage.Storage) {
+	t.Helper()
+	store := storage.NewMemoryStorage()
+	require.NoError(t, store.SaveUpdateOperation(context.Background(), storage.UpdateOperation{
+		OperationID: "op", ContainerName: "app", OperationType: "single", Status: "complete",
+	}))
+
+	var mu sync.Mutex
+	calls := 0
+	o := &UpdateOrchestrator{
+		dockerClient: &mockDockerClient{containers: []docker.Container{
+			{ID: "app-1", Name: "app", Labels: map[string]string{"docksmith.auto_rollback": "false"}},
+		}},
+		storage:         store,
+		eventBus:        events.NewBus(),
+		stackManager:    docker.NewStackManager(),
+		observation:     ObservationConfig{Window: 200 * time.Millisecond, MaxRestarts: 2},
+		observeInterval: 10 * time.Millisecond,
+	}
+	o.inspectRestarts = func(ctx context.Context, name string) (string, int, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		id := "app-1"
+		if len(containerIDs) > 0 {
+			id = containerIDs[min(calls, len(containerIDs)-1)]
+		}
+		count := restarts[min(calls, len(restarts)-1)]
+		calls++
+		return id, count, nil
+	}
+	return o, store
+}
+
+// waitForObservation waits for the observation of the app container to finish.
+func waitForObservation(t *testing.T, store storage.Storage) storage.PostUpdateObservation {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		op, _, _ := store.GetUpdateOperation(context.Background(), "op")
+		if len(op.Observations) == 1 && op.Observations[0].Status != "observing" {
+			return op.Observations[0]
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("observation did not finish")
+	return storage.PostUpdateObservation{}
+}
+
+func TestObserveUpdatedFlagsCrashLoop(t *testing.T) {
+	o, store := newObserveTestOrchestrator(t, []int{5, 6, 7, 8})
+	crashLoops, unsubscribe := o.eventBus.Subscribe(events.EventCrashLoop)
+	defer unsubscribe()
+
+	o.observeUpdated("op", []*docker.Container{{Name: "app"}})
+
+	obs := waitForObservation(t, store)
+	assert.Equal(t, "crash_loop", obs.Status)
+	assert.Equal(t, 3, obs.Restarts)
+	assert.Equal(t, 2, obs.MaxRestarts)
+	assert.False(t, obs.RolledBack, "auto-rollback is disabled for the container")
+
+	select {
+	case event := <-crashLoops:
+		assert.Equal(t, "app", event.Payload["container_name"])
+		assert.Equal(t, 3, event.Payload["restarts"])
+	case <-time.After(time.Second):
+		t.Fatal("expected a container.crash_loop event")
+	}
+}
+
+func TestObserveUpdatedPasses(t *testing.T) {
+	o, store := newObserveTestOrchestrator(t, []int{0, 1, 1, 2})
+	o.observeUpdated("op", []*docker.Container{{Name: "app"}})
+
+	obs := waitForObservation(t, store)
+	assert.Equal(t, "passed", obs.Status)
+	assert.Equal(t, 2, obs.Restarts)
+	assert.NotNil(t, obs.CompletedAt)
+}
+
+func TestObserveUpdatedStopsWhenRecreated(t *testing.T) {
+	o, store := newObserveTestOrchestrator(t, []int{0}, "app-1", "app-1", "app-2")
+	o.observeUpdated("op", []*docker.Container{{Name: "app"}})
+
+	obs := waitForObservation(t, store)
+	assert.Equal(t, "interrupted", obs.Status)
+}
+
+func TestObserveWindowFor(t *testing.T) {
+	o := &UpdateOrchestrator{observation: ObservationConfig{Window: 15 * time.Minute}}
+	withLabel := func(value string) *docker.Container {
+		return &docker.Container{Name: "app", Labels: map[string]string{scripts.ObserveWindowLabel: value}}
+	}
+
+	assert.Equal(t, 15*time.Minute, o.observeWindowFor(&docker.Container{Name: "app"}))
+	assert.Equal(t, time.Hour, o.observeWindowFor(withLabel("1h")))
+	assert.Zero(t, o.observeWindowFor(withLabel("0")))
+	assert.Equal(t, 15*time.Minute, o.observeWindowFor(withLabel("soon")))
+
+	// Rollbacks are never watched
+	o, store := newObserveTestOrchestrator(t, []int{0, 10})
+	op, _, _ := store.GetUpdateOperation(context.Background(), "op")
+	op.OperationType = "rollback"
+	require.NoError(t, store.SaveUpdateOperation(context.Background(), op))
+	o.observeUpdated("op", []*docker.Container{{Name: "app"}})
+	op, _, _ = store.GetUpdateOperation(context.Background(), "op")
+	assert.Empty(t, op.Observations)
+}
+
+func TestObservationFromEnv(t *testing.T) {
+	t.Setenv("POST_UPDATE_OBSERVE_WINDOW", "15m")
+	t.Setenv("POST_UPDATE_MAX_RESTARTS", "5")
+	assert.Equal(t, ObservationConfig{Window: 15 * time.Minute, MaxRestarts: 5}, ObservationFromEnv())
+
+	t.Setenv("POST_UPDATE_OBSERVE_WINDOW", "often")
+	t.Setenv("POST_UPDATE_MAX_RESTARTS", "-1")
+	assert.Equal(t, ObservationConfig{MaxRestarts: defaultObserveMaxRestarts}, ObservationFromEnv())
+}
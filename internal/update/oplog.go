@@ -0,0 +1,134 @@
+package update
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/chis/docksmith/internal/compose"
+	"github.com/chis/docksmith/internal/events"
+	"github.com/chis/docksmith/internal/storage"
+	dockerContainer "github.com/docker/docker/api/types/container"
+)
+
+// Sources of the lines in the step log of an operation.
+const (
+	logSourceProgress = "progress"
+	logSourceCompose  = "compose"
+	logSourcePull     = "pull"
+	logSourceHealth   = "health"
+	logSourceScript   = "script"
+)
+
+type operationLogKey struct{}
+
+// stepLogger records a line in the step log of an operation.
+type stepLogger func(containerName, source, message string)
+
+// withOperationLog returns a context whose steps are recorded in the step log of an
+// operation. The output of the compose commands run with it is also kept on the
+// operation record, and the commands get the environment settings of their stack.
+func (o *UpdateOrchestrator) withOperationLog(ctx context.Context, operationID string) context.Context {
+	logger := stepLogger(func(containerName, source, message string) {
+		o.appendOperationLog(operationID, containerName, source, message)
+	})
+	ctx = context.WithValue(ctx, operationLogKey{}, logger)
+	ctx = WithStackEnv(ctx, o.storage)
+	return compose.WithOutput(ctx, func(containerName, command string, output []byte, err error) {
+		message := commandLog(command, output, err)
+		logger(containerName, logSourceCompose, message)
+		if o.storage != nil {
+			if err := o.storage.AppendComposeOutput(context.Background(), operationID, message+"\n"); err != nil {
+				log.Printf("UPDATE: Failed to save compose output of operation=%s: %v", operationID, err)
+			}
+		}
+	})
+}
+
+// logStep records a line in the step log of the operation of ctx. Does nothing
+// outside an operation.
+func logStep(ctx context.Context, containerName, source, format string, args ...any) {
+	if logger, ok := ctx.Value(operationLogKey{}).(stepLogger); ok {
+		logger(containerName, source, fmt.Sprintf(format, args...))
+	}
+}
+
+// commandLog formats a command with its output and error for the step log.
+func commandLog(command string, output []byte, err error) string {
+	message := "$ " + command
+	if text := strings.TrimSpace(string(output)); text != "" {
+		message += "\n" + text
+	}
+	if err != nil {
+		message += "\n" + err.Error()
+	}
+	return message
+}
+
+// logProgress records a progress message in the step log of an operation. Image pull
+// progress is only logged when the pull starts, pullImage logs its layers.
+func (o *UpdateOrchestrator) logProgress(operationID, containerName, stage, message string) {
+	if stage == "complete" || stage == "failed" {
+		defer o.loggedStages.Delete(operationID)
+	}
+	if message == "" {
+		return
+	}
+	key := containerName + "/" + stage
+	if last, _ := o.loggedStages.Swap(operationID, key); last == key && stage == "pulling_image" {
+		return
+	}
+	o.appendOperationLog(operationID, containerName, logSourceProgress, message)
+}
+
+// logHealthChecks records the Docker health check results of a container finished
+// after since in the step log. Returns the end of the newest result.
+func logHealthChecks(ctx context.Context, containerName string, health *dockerContainer.Health, since time.Time) time.Time {
+	if health == nil {
+		return since
+	}
+	for _, result := range health.Log {
+		if result == nil || !result.End.After(since) {
+			continue
+		}
+		logStep(ctx, containerName, logSourceHealth, "Health check exited with %d: %s", result.ExitCode, strings.TrimSpace(result.Output))
+		since = result.End
+	}
+	return since
+}
+
+// appendOperationLog stores a line of the step log of an operation and publishes
+// it as an EventOperationLog event.
+func (o *UpdateOrchestrator) appendOperationLog(operationID, containerName, source, message string) {
+	if o.storage == nil || operationID == "" {
+		return
+	}
+	entry := storage.OperationLogEntry{
+		OperationID:   operationID,
+		ContainerName: containerName,
+		Source:        source,
+		Message:       message,
+		CreatedAt:     time.Now(),
+	}
+	id, err := o.storage.AppendOperationLog(context.Background(), entry)
+	if err != nil {
+		log.Printf("UPDATE: Failed to save step log of operation=%s: %v", operationID, err)
+		return
+	}
+
+	if o.eventBus != nil {
+		o.eventBus.Publish(events.Event{
+			Type: events.EventOperationLog,
+			Payload: map[string]interface{}{
+				"operation_id":   operationID,
+				"log_id":         id,
+				"container_name": containerName,
+				"source":         source,
+				"message":        message,
+				"timestamp":      entry.CreatedAt.Unix(),
+			},
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package update
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/chis/docksmith/internal/compose"
+	"github.com/chis/docksmith/internal/docker"
+	"github.com/chis/docksmith/internal/events"
+	"github.com/chis/docksmith/internal/storage"
+	dockerContainer "github.com/docker/docker/api/types/container"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// operationLog returns the step log of an operation as "source: message" lines.
+func operationLog(t *testing.T, store storage.Storage, operationID string) []string {
+	t.Helper()
+	logs, err := store.GetOperationLogs(context.Background(), operationID, 0)
+	require.NoError(t, err)
+	lines := make([]string, 0, len(logs))
+	for _, entry := range logs {
+		lines = append(lines, entry.Source+": "+entry.Message)
+	}
+	return lines
+}
+
+func TestLogStep(t *testing.T) {
+	bus := events.NewBus()
+	sub, unsubscribe := bus.Subscribe(events.EventOperationLog)
+	defer unsubscribe()
+	o := &UpdateOrchestrator{storage: storage.NewMemoryStorage(), eventBus: bus}
+	ctx := o.withOperationLog(context.Background(), "op-1")
+
+	logStep(ctx, "web", logSourcePull, "%s: %s", "abc123", "Pull complete")
+	logStep(context.Background(), "web", logSourcePull, "outside an operation")
+
+	assert.Equal(t, []string{"pull: abc123: Pull complete"}, operationLog(t, o.storage, "op-1"))
+	select {
+	case event := <-sub:
+		assert.Equal(t, "op-1", event.Payload["operation_id"])
+		assert.Equal(t, "web", event.Payload["container_name"])
+		assert.Equal(t, "abc123: Pull complete", event.Payload["message"])
+	case <-time.After(time.Second):
+		t.Fatal("expected an operation.log event")
+	}
+}
+
+func TestLogProgressSkipsRepeatedPullProgress(t *testing.T) {
+	o := &UpdateOrchestrator{storage: storage.NewMemoryStorage()}
+
+	o.publishProgress("op-1", "web", "", "pulling_image", 30, "Pulling nginx:1.27")
+	o.publishProgress("op-1", "web", "", "pulling_image", 40, "Downloading")
+	o.publishProgress("op-1", "web", "", "pulling_image", 50, "Extracting")
+	o.publishProgress("op-1", "web", "", "recreating", 60, "Recreating web")
+	o.publishProgress("op-1", "web", "", "health_check", 80, "")
+	o.publishProgress("op-1", "", "", "complete", 100, "Update complete")
+
+	assert.Equal(t, []string{
+		"progress: Pulling nginx:1.27",
+		"progress: Recreating web",
+		"progress: Update complete",
+	}, operationLog(t, o.storage, "op-1"))
+}
+
+func TestLogHealthChecks(t *testing.T) {
+	o := &UpdateOrchestrator{storage: storage.NewMemoryStorage()}
+	ctx := o.withOperationLog(context.Background(), "op-1")
+	start := time.Now()
+	health := &dockerContainer.Health{Log: []*dockerContainer.HealthcheckResult{
+		{End: start.Add(time.Second), ExitCode: 1, Output: "connection refused\n"},
+	}}
+
+	seen := logHealthChecks(ctx, "web", health, time.Time{})
+	health.Log = append(health.Log, &dockerContainer.HealthcheckResult{End: start.Add(2 * time.Second), Output: "ok"})
+	logHealthChecks(ctx, "web", health, seen)
+
+	assert.Equal(t, []string{
+		"health: Health check exited with 1: connection refused",
+		"health: Health check exited with 0: ok",
+	}, operationLog(t, o.storage, "op-1"))
+}
+
+func TestCommandLog(t *testing.T) {
+	assert.Equal(t, "$ docker compose up -d web\nContainer web Started",
+		commandLog("docker compose up -d web", []byte("Container web Started\n"), nil))
+	assert.Equal(t, "$ /scripts/check.sh\nexit status 1",
+		commandLog("/scripts/check.sh", nil, errors.New("exit status 1")))
+}
+
+func TestComposeOutputIsKeptOnOperation(t *testing.T) {
+	// A fake docker CLI that leaks a secret in its output
+	bin := t.TempDir()
+	script := "#!/bin/sh\necho \"Container web  Started (DB_PASSWORD=hunter2)\"\n"
+	require.NoError(t, os.WriteFile(filepath.Join(bin, "docker"), []byte(script), 0755))
+	t.Setenv("PATH", bin)
+
+	ctx := context.Background()
+	o := &UpdateOrchestrator{storage: storage.NewMemoryStorage()}
+	require.NoError(t, o.storage.SaveUpdateOperation(ctx, storage.UpdateOperation{OperationID: "op-1", Status: storage.StatusRecreating}))
+	web := &docker.Container{Name: "web", Labels: map[string]string{"com.docker.compose.service": "web"}}
+
+	opCtx := o.withOperationLog(ctx, "op-1")
+	require.NoError(t, compose.NewRecreator(nil).StartWithCompose(opCtx, web, "/srv/web/compose.yaml", "/compose/compose.yaml"))
+
+	// Saving the operation keeps the output
+	op, _, _ := o.storage.GetUpdateOperation(ctx, "op-1")
+	op.Status = storage.StatusComplete
+	op.ComposeOutput = ""
+	require.NoError(t, o.storage.SaveUpdateOperation(ctx, op))
+
+	op, _, _ = o.storage.GetUpdateOperation(ctx, "op-1")
+	assert.Equal(t, "$ docker compose --project-directory /srv/web -f /compose/compose.yaml start web\n"+
+		"Container web  Started (DB_PASSWORD=[REDACTED])\n", op.ComposeOutput)
+	assert.Equal(t, []string{"compose: " + op.ComposeOutput[:len(op.ComposeOutput)-1]}, operationLog(t, o.storage, "op-1"))
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os/exec"
 	"path/filepath"
+	"slices"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -14,6 +15,8 @@ import (
 	"github.com/chis/docksmith/internal/docker"
 	"github.com/chis/docksmith/internal/events"
 	"github.com/chis/docksmith/internal/graph"
+	"github.com/chis/docksmith/internal/scripts"
+	"github.com/chis/docksmith/internal/scripts/builtin"
 	"github.com/chis/docksmith/internal/storage"
 	"github.com/chis/docksmith/internal/version"
 )
@@ -30,6 +33,8 @@ type DiscoveryResult struct {
 	LocalImages        int                 `json:"local_images"`
 	Failed             int                 `json:"failed"`
 	Ignored            int                 `json:"ignored"`
+	Groups             map[string]*Group   `json:"groups,omitempty"` // Containers grouped by docksmith.group
+	DependencyCycles   []graph.Cycle       `json:"dependency_cycles,omitempty"` // Circular dependencies, including docksmith.restart-after labels
 	// Status endpoint specific fields (populated by background checker)
 	LastCacheRefresh   string `json:"last_cache_refresh,omitempty"`   // ISO 8601 timestamp of when cache was last cleared (cache refresh)
 	LastBackgroundRun  string `json:"last_background_run,omitempty"`  // ISO 8601 timestamp of when background check last ran
@@ -47,8 +52,11 @@ type ContainerInfo struct {
 	Service         string            `json:"service,omitempty"`
 	Dependencies    []string          `json:"dependencies,omitempty"`
 	Labels          map[string]string `json:"labels,omitempty"`
+	Groups          []string          `json:"groups,omitempty"`            // Custom groups from docksmith.group
 	ComposeLabels   map[string]string `json:"compose_labels,omitempty"`   // Docksmith labels from compose file
 	LabelsOutOfSync bool              `json:"labels_out_of_sync,omitempty"` // True if compose labels differ from running container
+	DependencyCycle []string          `json:"dependency_cycle,omitempty"`   // Containers in a circular dependency with this one
+	RestartLoop     bool              `json:"restart_loop,omitempty"`       // The cycle includes restart-after labels, so restarts would loop endlessly
 }
 
 // Stack represents a group of related containers
@@ -130,6 +138,23 @@ func (o *Orchestrator) ClearCache() {
 	o.cache.Clear()
 }
 
+// InvalidateContainers removes the cached check results of containers, so the next
+// check re-reads their compose files and registries.
+func (o *Orchestrator) InvalidateContainers(containers []docker.Container) {
+	for _, c := range containers {
+		o.cache.Delete(checkCacheKey(c))
+	}
+}
+
+// checkCacheKey returns the cache key of a container's check result.
+func checkCacheKey(container docker.Container) string {
+	containerIDSuffix := container.ID
+	if len(containerIDSuffix) > 12 {
+		containerIDSuffix = containerIDSuffix[:12]
+	}
+	return fmt.Sprintf("%s:%s", container.Image, containerIDSuffix)
+}
+
 // SetMaxConcurrency sets the maximum number of concurrent registry queries
 func (o *Orchestrator) SetMaxConcurrency(max int) {
 	o.maxConcurrency = max
@@ -168,6 +193,8 @@ func (o *Orchestrator) DiscoverAndCheck(ctx context.Context) (*DiscoveryResult,
 	}
 
 	// Step 2: Check for updates with concurrency control
+	ignoreRules := o.checker.loadIgnoreRules(ctx)
+	checkCtx, lookups := withSharedLookups(ctx)
 	sem := make(chan struct{}, o.maxConcurrency)
 	var wg sync.WaitGroup
 	var mu sync.Mutex
@@ -187,7 +214,7 @@ func (o *Orchestrator) DiscoverAndCheck(ctx context.Context) (*DiscoveryResult,
 			o.publishCheckProgress("checking", len(containers), int(atomic.LoadInt32(&checkedCount)), c.Name, fmt.Sprintf("Checking %s...", c.Name))
 
 			// Check if container should be ignored
-			if o.checker.shouldIgnoreContainer(c) {
+			if o.checker.shouldIgnoreContainer(c, ignoreRules) {
 				info := ContainerInfo{
 					ContainerUpdate: ContainerUpdate{
 						ContainerName: c.Name,
@@ -196,6 +223,7 @@ func (o *Orchestrator) DiscoverAndCheck(ctx context.Context) (*DiscoveryResult,
 					},
 					ID:     c.ID,
 					Labels: c.Labels,
+					Groups: ParseGroups(c.Labels),
 				}
 				// Determine stack for ignored container
 				info.Stack = o.stackManager.DetermineStack(ctx, c)
@@ -214,7 +242,7 @@ func (o *Orchestrator) DiscoverAndCheck(ctx context.Context) (*DiscoveryResult,
 			}
 
 			// Check for updates
-			info := o.checkContainer(ctx, c)
+			info := o.checkContainer(checkCtx, c)
 
 			// Store result
 			mu.Lock()
@@ -245,19 +273,25 @@ func (o *Orchestrator) DiscoverAndCheck(ctx context.Context) (*DiscoveryResult,
 	}
 
 	wg.Wait()
+	logSharedLookups(lookups)
 	result.Containers = containerInfos
 
-	// Step 3: Group into stacks
+	// Step 3: Build dependency graph and flag circular dependencies
+	depGraph := o.graphBuilder.BuildFromContainers(containers)
+	result.DependencyCycles = depGraph.Cycles()
+	markDependencyCycles(result.Containers, result.DependencyCycles)
+
+	// Step 4: Group into stacks and custom groups
 	o.groupIntoStacks(result)
+	result.Groups = BuildGroups(result.Containers)
 
-	// Step 4: Build dependency graph and get update order
-	depGraph := o.graphBuilder.BuildFromContainers(containers)
+	// Step 5: Get update order
 	if !depGraph.HasCycles() {
 		updateOrder, _ := depGraph.GetUpdateOrder()
 		result.UpdateOrder = updateOrder
 	}
 
-	// Step 5: Run pre-update checks if configured
+	// Step 6: Run pre-update checks if configured
 	for i, info := range result.Containers {
 		if info.PreUpdateCheck != "" && info.Status == UpdateAvailable {
 			canUpdate, err := o.safetyChecker.CheckContainer(ctx, info)
@@ -277,6 +311,25 @@ func (o *Orchestrator) DiscoverAndCheck(ctx context.Context) (*DiscoveryResult,
 	return result, nil
 }
 
+// markDependencyCycles records on each container the other containers of its
+// dependency cycle. Cycles never share containers.
+func markDependencyCycles(containers []ContainerInfo, cycles []graph.Cycle) {
+	for _, cycle := range cycles {
+		for i := range containers {
+			if !slices.Contains(cycle.Containers, containers[i].ContainerName) {
+				continue
+			}
+			for _, name := range cycle.Containers {
+				// A container depending on itself lists itself
+				if name != containers[i].ContainerName || len(cycle.Containers) == 1 {
+					containers[i].DependencyCycle = append(containers[i].DependencyCycle, name)
+				}
+			}
+			containers[i].RestartLoop = cycle.RestartLoop
+		}
+	}
+}
+
 // DiscoverAndCheckSingle performs a synchronous check for a single container by name.
 // This bypasses the cache and always runs fresh checks including pre-update scripts.
 func (o *Orchestrator) DiscoverAndCheckSingle(ctx context.Context, containerName string) (*ContainerInfo, error) {
@@ -308,6 +361,7 @@ func (o *Orchestrator) DiscoverAndCheckSingle(ctx context.Context, containerName
 		ID:              targetContainer.ID,
 		Stack:           targetContainer.Stack,
 		Labels:          targetContainer.Labels,
+		Groups:          ParseGroups(targetContainer.Labels),
 	}
 
 	return &info, nil
@@ -342,25 +396,21 @@ func (o *Orchestrator) checkContainer(ctx context.Context, container docker.Cont
 	// Check cache first if enabled (only for update check results, not container metadata)
 	var update ContainerUpdate
 	if o.cacheEnabled {
-		containerIDSuffix := container.ID
-		if len(containerIDSuffix) > 12 {
-			containerIDSuffix = containerIDSuffix[:12]
-		}
-		cacheKey := fmt.Sprintf("%s:%s", container.Image, containerIDSuffix)
+		cacheKey := checkCacheKey(container)
 		if cached, found := o.cache.Get(cacheKey); found {
 			if cachedUpdate, ok := cached.(ContainerUpdate); ok {
 				update = cachedUpdate
 			} else {
 				// Cache miss or wrong type, do fresh check
 				update = o.checker.checkContainer(ctx, container)
-				if update.Status != LocalImage {
+				if update.Status != LocalImage && !update.Deferred {
 					o.cache.Set(cacheKey, update, o.cacheTTL)
 				}
 			}
 		} else {
 			// Cache miss, do fresh check
 			update = o.checker.checkContainer(ctx, container)
-			if update.Status != LocalImage {
+			if update.Status != LocalImage && !update.Deferred {
 				o.cache.Set(cacheKey, update, o.cacheTTL)
 			}
 		}
@@ -374,6 +424,7 @@ func (o *Orchestrator) checkContainer(ctx context.Context, container docker.Cont
 		ContainerUpdate: update,
 		ID:             container.ID,
 		Labels:         container.Labels,
+		Groups:         ParseGroups(container.Labels),
 	}
 
 	// Determine stack (container-specific, always fresh)
@@ -552,6 +603,23 @@ func (s *SafetyChecker) CheckContainer(ctx context.Context, container ContainerI
 		return true, nil // No check configured, allow update
 	}
 
+	if builtin.IsCheck(container.PreUpdateCheck) {
+		target := &docker.Container{ID: container.ID, Name: container.ContainerName, Labels: container.Labels}
+		// Like a failing script, a busy or unreachable app blocks the update
+		return scripts.RunBuiltinCheck(ctx, target, container.PreUpdateCheck) == nil, nil
+	}
+	if scripts.IsManagedScript(container.PreUpdateCheck) {
+		target := &docker.Container{ID: container.ID, Name: container.ContainerName, Labels: container.Labels}
+		_, err := scripts.RunManagedScript(ctx, target, container.PreUpdateCheck, scripts.PreUpdateCheckTimeout)
+		if _, ok := err.(*exec.ExitError); ok {
+			return false, nil // Non-zero exit code means check failed (don't update)
+		}
+		if err != nil {
+			return false, fmt.Errorf("failed to execute pre-update check: %w", err)
+		}
+		return true, nil
+	}
+
 	// Construct full path if not already absolute
 	scriptPath := container.PreUpdateCheck
 	if !filepath.IsAbs(scriptPath) {
@@ -634,6 +702,14 @@ func (c *Cache) Set(key string, value interface{}, ttl time.Duration) {
 	}
 }
 
+// Delete removes an item from cache
+func (c *Cache) Delete(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.entries, key)
+}
+
 // Clear removes all items from cache
 func (c *Cache) Clear() {
 	c.mu.Lock()
@@ -8,6 +8,8 @@ import (
 	"time"
 
 	"github.com/chis/docksmith/internal/docker"
+	"github.com/chis/docksmith/internal/registry"
+	"github.com/chis/docksmith/internal/scripts"
 	"github.com/chis/docksmith/internal/version"
 )
 
@@ -77,6 +79,26 @@ func (m *mockRegistryManager) ListTags(ctx context.Context, imageRef string) ([]
 
 func (m *mockRegistryManager) GetGhostTags(imageRef string) []string { return nil }
 
+func (m *mockRegistryManager) GetImageSize(ctx context.Context, imageRef, reference string) (int64, error) {
+	return 0, nil
+}
+
+func (m *mockRegistryManager) GetImageLayers(ctx context.Context, imageRef, reference string) ([]registry.ImageLayer, error) {
+	return nil, errors.New("image layers not available")
+}
+
+func (m *mockRegistryManager) GetImagePlatforms(ctx context.Context, imageRef, reference string) ([]string, error) {
+	return nil, nil
+}
+
+func (m *mockRegistryManager) GetPlatformDigest(ctx context.Context, imageRef, reference string) (string, error) {
+	return "", errors.New("platform digest not available")
+}
+
+func (m *mockRegistryManager) GetSignatures(ctx context.Context, imageRef, digest string) (*registry.ImageSignatures, error) {
+	return &registry.ImageSignatures{}, nil
+}
+
 func (m *mockRegistryManager) GetTagDigest(ctx context.Context, imageRef, tag string) (string, error) {
 	if m.getDigestError != nil {
 		return "", m.getDigestError
@@ -226,6 +248,67 @@ func TestOrchestratorStackGrouping(t *testing.T) {
 	}
 }
 
+// TestOrchestratorDependencyCycles tests that cycles through restart-after labels are flagged
+func TestOrchestratorDependencyCycles(t *testing.T) {
+	mockDocker := &MockDockerClient{
+		containers: []docker.Container{
+			{
+				ID:    "container1",
+				Name:  "gluetun",
+				Image: "qmcgaw/gluetun:v3.39.0",
+				Labels: map[string]string{
+					"com.docker.compose.project": "media",
+					"com.docker.compose.service": "gluetun",
+					scripts.RestartAfterLabel:    "torrent",
+				},
+			},
+			{
+				ID:    "container2",
+				Name:  "torrent",
+				Image: "linuxserver/qbittorrent:4.6.5",
+				Labels: map[string]string{
+					"com.docker.compose.project":      "media",
+					"com.docker.compose.service":      "torrent",
+					"com.docker.compose.network_mode": "service:gluetun",
+				},
+			},
+			{
+				ID:    "container3",
+				Name:  "redis",
+				Image: "redis:7.2.0",
+			},
+		},
+	}
+
+	orchestrator := NewOrchestrator(mockDocker, &mockRegistryManager{})
+	result, err := orchestrator.DiscoverAndCheck(context.Background())
+	if err != nil {
+		t.Fatalf("Discovery failed: %v", err)
+	}
+
+	if len(result.DependencyCycles) != 1 || !result.DependencyCycles[0].RestartLoop {
+		t.Fatalf("Expected 1 restart loop, got %+v", result.DependencyCycles)
+	}
+
+	for _, c := range result.Containers {
+		switch c.ContainerName {
+		case "gluetun":
+			if !c.RestartLoop || len(c.DependencyCycle) != 1 || c.DependencyCycle[0] != "torrent" {
+				t.Errorf("Expected gluetun in a restart loop with torrent, got %v", c.DependencyCycle)
+			}
+		case "redis":
+			if c.RestartLoop || len(c.DependencyCycle) != 0 {
+				t.Errorf("Expected redis outside any cycle, got %v", c.DependencyCycle)
+			}
+		}
+	}
+
+	// The compose dependencies alone have no cycle, so the update order is kept
+	if len(result.UpdateOrder) != 3 {
+		t.Errorf("Expected update order of 3 containers, got %v", result.UpdateOrder)
+	}
+}
+
 // TestOrchestratorPreUpdateCheckExecution tests pre-update check execution flow
 func TestOrchestratorPreUpdateCheckExecution(t *testing.T) {
 	mockDocker := &MockDockerClient{
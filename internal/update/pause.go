@@ -0,0 +1,177 @@
+package update
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/chis/docksmith/internal/docker"
+	"github.com/chis/docksmith/internal/selfupdate"
+	"github.com/chis/docksmith/internal/storage"
+)
+
+// registerPausable marks a running update as pausable until it reaches its pause point.
+func (o *UpdateOrchestrator) registerPausable(operationID string) {
+	o.pauseMu.Lock()
+	defer o.pauseMu.Unlock()
+	if o.pausable == nil {
+		o.pausable = make(map[string]bool)
+	}
+	o.pausable[operationID] = false
+}
+
+// unregisterPausable removes an update from the pausable set.
+func (o *UpdateOrchestrator) unregisterPausable(operationID string) {
+	o.pauseMu.Lock()
+	defer o.pauseMu.Unlock()
+	delete(o.pausable, operationID)
+}
+
+// pauseAtPausePoint is called once an update's images are pulled, before anything
+// is recreated. If a pause was requested, the operation is persisted as paused and
+// true is returned; the caller must then stop (releasing its stack lock).
+// Past this point the operation can no longer be paused, and its containers are
+// expected to be removed as they are recreated. An operation whose container was
+// removed before this point is failed here, and true is returned as well.
+func (o *UpdateOrchestrator) pauseAtPausePoint(ctx context.Context, operationID, containerName, stackName string) bool {
+	if err := o.unwatchTargets(ctx, operationID); err != nil {
+		o.failOperation(ctx, operationID, "pulling_image", err.Error())
+		return true
+	}
+
+	o.pauseMu.Lock()
+	requested := o.pausable[operationID]
+	delete(o.pausable, operationID)
+	o.pauseMu.Unlock()
+
+	if !requested {
+		return false
+	}
+
+	if err := o.storage.UpdateOperationStatus(ctx, operationID, storage.StatusPaused, ""); err != nil {
+		log.Printf("UPDATE: Failed to persist paused state for operation=%s: %v", operationID, err)
+		return false
+	}
+
+	log.Printf("UPDATE: Operation=%s paused after pulling images", operationID)
+	o.publishProgress(operationID, containerName, stackName, "paused", 60, "Paused after pulling images, resume to recreate")
+	return true
+}
+
+// PauseOperation requests that a running update pause after its images are
+// pulled and before any container is recreated. The pause takes effect when the
+// operation reaches that point; its status then becomes "paused".
+func (o *UpdateOrchestrator) PauseOperation(ctx context.Context, operationID string) error {
+	op, found, err := o.storage.GetUpdateOperation(ctx, operationID)
+	if err != nil {
+		return fmt.Errorf("failed to get operation: %w", err)
+	}
+	if !found {
+		return NewNotFoundError("operation not found: %s", operationID)
+	}
+	if op.Status == storage.StatusPaused {
+		return NewBadRequestError("operation %s is already paused", operationID)
+	}
+
+	o.pauseMu.Lock()
+	defer o.pauseMu.Unlock()
+	if _, ok := o.pausable[operationID]; !ok {
+		return NewBadRequestError("operation %s cannot be paused: not running or already past the pull stage", operationID)
+	}
+	o.pausable[operationID] = true
+
+	log.Printf("UPDATE: Pause requested for operation=%s", operationID)
+	return nil
+}
+
+// ResumeOperation continues a paused update from its pause point: containers are
+// recreated, verified, and the operation is completed in the background.
+func (o *UpdateOrchestrator) ResumeOperation(ctx context.Context, operationID string) error {
+	op, found, err := o.storage.GetUpdateOperation(ctx, operationID)
+	if err != nil {
+		return fmt.Errorf("failed to get operation: %w", err)
+	}
+	if !found {
+		return NewNotFoundError("operation not found: %s", operationID)
+	}
+	if op.Status != storage.StatusPaused {
+		return NewBadRequestError("operation %s is not paused (status: %s)", operationID, op.Status)
+	}
+	if o.dockerSDK == nil {
+		return fmt.Errorf("docker SDK not initialized")
+	}
+
+	containers, err := o.dockerClient.ListContainers(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list containers: %w", err)
+	}
+	byName := make(map[string]*docker.Container, len(containers))
+	for i := range containers {
+		byName[containers[i].Name] = &containers[i]
+	}
+
+	stackName := op.StackName
+	if !o.acquireStackLock(stackName, operationID) {
+		return NewBadRequestError("stack %s has an update in progress, try again later", stackName)
+	}
+
+	if op.OperationType == "single" {
+		container, ok := byName[op.ContainerName]
+		if !ok {
+			o.releaseStackLock(stackName, operationID)
+			return NewNotFoundError("container not found: %s", op.ContainerName)
+		}
+		if err := o.storage.UpdateOperationStatus(ctx, operationID, storage.StatusInProgress, ""); err != nil {
+			o.releaseStackLock(stackName, operationID)
+			return fmt.Errorf("failed to update operation status: %w", err)
+		}
+
+		log.Printf("UPDATE: Resuming operation=%s for %s", operationID, container.Name)
+		go func() {
+			defer o.releaseStackLock(stackName, operationID)
+			o.finishSingleUpdate(context.Background(), operationID, container, op.NewVersion, stackName)
+		}()
+		return nil
+	}
+
+	// Rebuild the batch state from the persisted details, which are stored in
+	// dependency order. Containers whose pull failed were already reverted and
+	// are skipped as they would have been without the pause.
+	var updateContainers []*docker.Container
+	var selfContainer *docker.Container
+	targetVersions := make(map[string]string)
+	oldTags := make(map[string]string)
+	pullFailed := make(map[string]bool)
+	for _, detail := range op.BatchDetails {
+		container, ok := byName[detail.ContainerName]
+		if !ok {
+			o.releaseStackLock(stackName, operationID)
+			return NewNotFoundError("container not found: %s", detail.ContainerName)
+		}
+		targetVersions[container.Name] = detail.NewVersion
+		if detail.Status == "failed" {
+			pullFailed[container.Name] = true
+		}
+		if selfupdate.IsSelfContainer(container.ID, container.Image, container.Name) {
+			selfContainer = container
+			continue
+		}
+		if _, tag := splitImageRef(container.Image); tag != "" {
+			oldTags[container.Name] = tag
+		}
+		updateContainers = append(updateContainers, container)
+	}
+
+	if err := o.storage.UpdateOperationStatus(ctx, operationID, storage.StatusInProgress, ""); err != nil {
+		o.releaseStackLock(stackName, operationID)
+		return fmt.Errorf("failed to update operation status: %w", err)
+	}
+
+	log.Printf("BATCH UPDATE: Resuming operation=%s with %d containers", operationID, len(updateContainers))
+	go func() {
+		defer o.releaseStackLock(stackName, operationID)
+		o.finishBatchUpdate(context.Background(), operationID, updateContainers, selfContainer,
+			targetVersions, oldTags, pullFailed, stackName, op.AllOrNothing)
+	}()
+	return nil
+}
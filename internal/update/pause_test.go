@@ -0,0 +1,72 @@
+package update
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/chis/docksmith/internal/events"
+	"github.com/chis/docksmith/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPauseOperation(t *testing.T) {
+	ctx := context.Background()
+	mockStorage := NewTestMockStorage()
+	orch := &UpdateOrchestrator{storage: mockStorage, eventBus: events.NewBus()}
+
+	require.NoError(t, mockStorage.SaveUpdateOperation(ctx, storage.UpdateOperation{
+		OperationID: "op-1", OperationType: "single", Status: "validating",
+	}))
+
+	var notFound *NotFoundError
+	assert.True(t, errors.As(orch.PauseOperation(ctx, "missing"), &notFound))
+
+	var badRequest *BadRequestError
+	assert.True(t, errors.As(orch.PauseOperation(ctx, "op-1"), &badRequest), "operation not running in this process")
+
+	orch.registerPausable("op-1")
+	require.NoError(t, orch.PauseOperation(ctx, "op-1"))
+
+	assert.True(t, orch.pauseAtPausePoint(ctx, "op-1", "app", "stack"))
+	op, _, _ := mockStorage.GetUpdateOperation(ctx, "op-1")
+	assert.Equal(t, storage.StatusPaused, op.Status)
+
+	assert.True(t, errors.As(orch.PauseOperation(ctx, "op-1"), &badRequest), "past the pause point")
+}
+
+func TestPauseAtPausePoint_NotRequested(t *testing.T) {
+	ctx := context.Background()
+	mockStorage := NewTestMockStorage()
+	orch := &UpdateOrchestrator{storage: mockStorage}
+
+	require.NoError(t, mockStorage.SaveUpdateOperation(ctx, storage.UpdateOperation{
+		OperationID: "op-1", Status: "validating",
+	}))
+
+	orch.registerPausable("op-1")
+	assert.False(t, orch.pauseAtPausePoint(ctx, "op-1", "app", "stack"))
+
+	var badRequest *BadRequestError
+	assert.True(t, errors.As(orch.PauseOperation(ctx, "op-1"), &badRequest), "cannot pause once past the pull stage")
+
+	op, _, _ := mockStorage.GetUpdateOperation(ctx, "op-1")
+	assert.Equal(t, "validating", op.Status)
+}
+
+func TestResumeOperation_RequiresPaused(t *testing.T) {
+	ctx := context.Background()
+	mockStorage := NewTestMockStorage()
+	orch := &UpdateOrchestrator{storage: mockStorage}
+
+	require.NoError(t, mockStorage.SaveUpdateOperation(ctx, storage.UpdateOperation{
+		OperationID: "op-1", Status: "complete",
+	}))
+
+	var notFound *NotFoundError
+	assert.True(t, errors.As(orch.ResumeOperation(ctx, "missing"), &notFound))
+
+	var badRequest *BadRequestError
+	assert.True(t, errors.As(orch.ResumeOperation(ctx, "op-1"), &badRequest))
+}
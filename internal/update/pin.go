@@ -0,0 +1,126 @@
+package update
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+
+	"github.com/chis/docksmith/internal/docker"
+	"github.com/chis/docksmith/internal/storage"
+	"github.com/chis/docksmith/internal/version"
+)
+
+// PinResult is one pin operation started by PinContainers.
+type PinResult struct {
+	Stack       string   `json:"stack"`
+	Containers  []string `json:"containers"`
+	OperationID string   `json:"operation_id,omitempty"`
+	Error       string   `json:"error,omitempty"`
+}
+
+// PinContainers migrates containers from :latest to a versioned tag. The compose
+// image tag is rewritten and the container recreated, like an update to the same
+// image under its versioned tag. tags maps container names to the tag to pin to,
+// usually the RecommendedTag of an UpToDatePinnable check result.
+// One "pin" operation is started per stack, all linked by batchGroupID.
+func (o *UpdateOrchestrator) PinContainers(ctx context.Context, tags map[string]string, batchGroupID string) ([]PinResult, error) {
+	if err := o.checkWritable(); err != nil {
+		return nil, err
+	}
+	if len(tags) == 0 {
+		return nil, NewBadRequestError("no containers to pin")
+	}
+
+	containers, err := o.dockerClient.ListContainers(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list containers: %w", err)
+	}
+	byName := make(map[string]docker.Container, len(containers))
+	for _, c := range containers {
+		byName[c.Name] = c
+	}
+
+	names := make([]string, 0, len(tags))
+	for name := range tags {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	// Validate everything before starting anything
+	stacks := make(map[string][]string)
+	containerMeta := make(map[string]storage.BatchContainerDetail, len(tags))
+	noChange := int(version.NoChange)
+	for _, name := range names {
+		c, ok := byName[name]
+		if !ok {
+			return nil, NewNotFoundError("container not found: %s", name)
+		}
+		if tags[name] == "" {
+			return nil, NewBadRequestError("no tag to pin %s to", name)
+		}
+		if _, tag := splitImageRef(c.Image); tag != "" && tag != "latest" {
+			return nil, NewBadRequestError("cannot pin %s: image %s already uses a versioned tag", name, c.Image)
+		}
+
+		stack := o.stackManager.DetermineStack(ctx, c)
+		stacks[stack] = append(stacks[stack], name)
+		containerMeta[name] = storage.BatchContainerDetail{
+			ChangeType:         &noChange,
+			NewResolvedVersion: tags[name],
+		}
+	}
+
+	stackNames := make([]string, 0, len(stacks))
+	for stack := range stacks {
+		stackNames = append(stackNames, stack)
+	}
+	sort.Strings(stackNames)
+
+	results := make([]PinResult, 0, len(stacks))
+	for _, stack := range stackNames {
+		result := PinResult{Stack: stack, Containers: stacks[stack]}
+		operationID, err := o.updateBatchContainersInternal(ctx, stacks[stack], tags, "pin", batchGroupID, containerMeta, nil, false)
+		if err != nil {
+			log.Printf("PIN: Failed to start pin of %v: %v", stacks[stack], err)
+			result.Error = err.Error()
+		} else {
+			log.Printf("PIN: Pinning %v to versioned tags (operation %s)", stacks[stack], operationID)
+			result.OperationID = operationID
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// PinTags returns the recommended tag of every checked container that can be
+// pinned from :latest, keyed by container name. When names is non-empty only
+// those containers are considered, and each must be pinnable.
+func PinTags(containers []ContainerInfo, names []string) (map[string]string, error) {
+	byName := make(map[string]ContainerInfo, len(containers))
+	for _, c := range containers {
+		byName[c.ContainerName] = c
+	}
+
+	tags := make(map[string]string)
+	if len(names) == 0 {
+		for _, c := range containers {
+			if c.Status == UpToDatePinnable && c.RecommendedTag != "" {
+				tags[c.ContainerName] = c.RecommendedTag
+			}
+		}
+		return tags, nil
+	}
+
+	for _, name := range names {
+		c, ok := byName[name]
+		if !ok {
+			return nil, NewNotFoundError("container not found: %s", name)
+		}
+		if c.Status != UpToDatePinnable || c.RecommendedTag == "" {
+			return nil, NewBadRequestError("%s cannot be pinned (status %s)", name, c.Status)
+		}
+		tags[name] = c.RecommendedTag
+	}
+	return tags, nil
+}
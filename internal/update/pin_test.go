@@ -0,0 +1,59 @@
+package update
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/chis/docksmith/internal/docker"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPinTags(t *testing.T) {
+	containers := []ContainerInfo{
+		{ContainerUpdate: ContainerUpdate{ContainerName: "plex", Status: UpToDatePinnable, RecommendedTag: "1.40.2"}},
+		{ContainerUpdate: ContainerUpdate{ContainerName: "sonarr", Status: UpToDatePinnable, RecommendedTag: "4.0.5"}},
+		{ContainerUpdate: ContainerUpdate{ContainerName: "nginx", Status: UpToDate}},
+	}
+
+	tags, err := PinTags(containers, nil)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"plex": "1.40.2", "sonarr": "4.0.5"}, tags)
+
+	tags, err = PinTags(containers, []string{"plex"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"plex": "1.40.2"}, tags)
+
+	var badRequest *BadRequestError
+	_, err = PinTags(containers, []string{"nginx"})
+	assert.True(t, errors.As(err, &badRequest))
+
+	var notFound *NotFoundError
+	_, err = PinTags(containers, []string{"missing"})
+	assert.True(t, errors.As(err, &notFound))
+}
+
+func TestPinContainers_Validation(t *testing.T) {
+	orch := &UpdateOrchestrator{
+		dockerClient: &MockDockerClient{
+			containers: []docker.Container{
+				{Name: "plex", Image: "plexinc/pms-docker:latest"},
+				{Name: "postgres", Image: "postgres:16.4"},
+			},
+		},
+		stackManager: docker.NewStackManager(),
+	}
+	ctx := context.Background()
+
+	var badRequest *BadRequestError
+	_, err := orch.PinContainers(ctx, nil, "")
+	assert.True(t, errors.As(err, &badRequest))
+
+	_, err = orch.PinContainers(ctx, map[string]string{"postgres": "16.4"}, "")
+	assert.True(t, errors.As(err, &badRequest), "already on a versioned tag")
+
+	var notFound *NotFoundError
+	_, err = orch.PinContainers(ctx, map[string]string{"plex": "1.40.2", "missing": "1.0"}, "")
+	assert.True(t, errors.As(err, &notFound))
+}
@@ -121,6 +121,7 @@ func (h *PostUpdateHandler) executeRestart(ctx context.Context, containerNames [
 		// Use docker CLI for restart
 		cmd := exec.CommandContext(ctx, "docker", "restart", name)
 		output, err := cmd.CombinedOutput()
+		logStep(ctx, name, logSourceScript, "%s", commandLog("docker restart "+name, output, err))
 		if err != nil {
 			return fmt.Errorf("failed to restart container %s: %w (output: %s)", name, err, output)
 		}
@@ -144,6 +145,7 @@ func (h *PostUpdateHandler) executeComposeRestart(ctx context.Context, composePa
 
 	cmd := exec.CommandContext(ctx, "docker", args...)
 	output, err := cmd.CombinedOutput()
+	logStep(ctx, "", logSourceScript, "%s", commandLog("docker "+strings.Join(args, " "), output, err))
 	if err != nil {
 		return fmt.Errorf("failed to compose-restart services %v: %w (output: %s)", serviceNames, err, output)
 	}
@@ -168,6 +170,7 @@ func (h *PostUpdateHandler) executeScript(ctx context.Context, scriptPath string
 
 	cmd := exec.CommandContext(scriptCtx, fullPath, container.ID, container.Name)
 	output, err := cmd.CombinedOutput()
+	logStep(ctx, container.Name, logSourceScript, "%s", commandLog(fullPath, output, err))
 
 	if err != nil {
 		return fmt.Errorf("post-update script failed: %w (output: %s)", err, output)
@@ -187,6 +190,7 @@ func (h *PostUpdateHandler) executeCommand(ctx context.Context, command string)
 
 	cmd := exec.CommandContext(cmdCtx, "sh", "-c", command)
 	output, err := cmd.CombinedOutput()
+	logStep(ctx, "", logSourceScript, "%s", commandLog(command, output, err))
 
 	if err != nil {
 		return fmt.Errorf("post-update command failed: %w (output: %s)", err, output)
@@ -0,0 +1,148 @@
+package update
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/chis/docksmith/internal/docker"
+	"github.com/chis/docksmith/internal/registry"
+)
+
+// layerExtractionFactor approximates how much disk a pulled layer needs relative
+// to its compressed size: the download is stored while it is extracted.
+const layerExtractionFactor = 2
+
+// preflight runs the checks before images are pulled and compose files changed:
+// the target images must support the host architecture, pass the signature
+// policy, and fit on disk. Databases are then dumped before major version updates.
+func (o *UpdateOrchestrator) preflight(ctx context.Context, operationID string, containers []*docker.Container, targetVersions map[string]string) error {
+	if err := o.checkArchitecture(ctx, containers, targetVersions); err != nil {
+		return err
+	}
+	if err := o.checkSignatures(ctx, operationID, containers, targetVersions); err != nil {
+		return err
+	}
+	if err := o.checkDiskSpace(ctx, containers, targetVersions); err != nil {
+		return err
+	}
+	return o.dumpDatabases(ctx, operationID, containers, targetVersions)
+}
+
+// checkArchitecture is the pre-flight stage that fails an update whose target tag
+// has no image for the host architecture, which Docker would only report once the
+// compose file was already changed. Registry errors skip the check.
+func (o *UpdateOrchestrator) checkArchitecture(ctx context.Context, containers []*docker.Container, targetVersions map[string]string) error {
+	if o.checker == nil || o.checker.registryManager == nil {
+		return nil
+	}
+
+	for _, c := range containers {
+		target := targetVersions[c.Name]
+		if target == "" {
+			continue
+		}
+		imgInfo := o.checker.extractor.ExtractFromImage(c.Image)
+		imageRef := imgInfo.Registry + "/" + imgInfo.Repository
+
+		supported, err := o.checker.supportsArchitecture(ctx, imageRef, target, registry.HostArchitecture)
+		if err != nil {
+			log.Printf("PREFLIGHT: Skipping architecture check of %s:%s: %v", imageRef, target, err)
+			continue
+		}
+		if !supported {
+			return fmt.Errorf("%s:%s has no image for linux/%s", imageRef, target, registry.HostArchitecture)
+		}
+	}
+	return nil
+}
+
+// checkDiskSpace is the pre-flight stage before images are pulled. It estimates the
+// space the pulls need from the candidate manifests, counting only layers the
+// current images do not already have, and fails if the Docker data root has less
+// free space. The check is skipped (with a log line) when sizes or free space
+// cannot be determined, so it never blocks an update on its own errors.
+func (o *UpdateOrchestrator) checkDiskSpace(ctx context.Context, containers []*docker.Container, targetVersions map[string]string) error {
+	if o.checker == nil || o.checker.registryManager == nil || o.dockerSDK == nil {
+		return nil
+	}
+
+	root := os.Getenv("DOCKER_DATA_ROOT")
+	if root == "" {
+		info, err := o.dockerSDK.Info(ctx)
+		if err != nil {
+			log.Printf("PREFLIGHT: Skipping disk space check, failed to get Docker info: %v", err)
+			return nil
+		}
+		root = info.DockerRootDir
+	}
+	free, err := freeDiskSpace(root)
+	if err != nil {
+		log.Printf("PREFLIGHT: Skipping disk space check, cannot stat Docker data root %s: %v", root, err)
+		return nil
+	}
+
+	var current, candidate [][]registry.ImageLayer
+	for _, c := range containers {
+		imgInfo := o.checker.extractor.ExtractFromImage(c.Image)
+		imageRef := imgInfo.Registry + "/" + imgInfo.Repository
+
+		// The current image is addressed by its digest since its tag may have moved on
+		_, currentRef := splitImageRef(c.Image)
+		if digest, err := o.dockerClient.GetImageDigest(ctx, c.Image); err == nil && strings.HasPrefix(digest, "sha256:") {
+			currentRef = digest
+		}
+		if layers, err := o.checker.registryManager.GetImageLayers(ctx, imageRef, currentRef); err == nil {
+			current = append(current, layers)
+		}
+
+		layers, err := o.checker.registryManager.GetImageLayers(ctx, imageRef, targetVersions[c.Name])
+		if err != nil {
+			log.Printf("PREFLIGHT: Skipping disk space check, failed to get layers of %s:%s: %v", imageRef, targetVersions[c.Name], err)
+			return nil
+		}
+		candidate = append(candidate, layers)
+	}
+
+	required := requiredPullSpace(current, candidate)
+	log.Printf("PREFLIGHT: Pulls need about %s, %s free on %s", formatDiskSize(required), formatDiskSize(free), root)
+	if required > free {
+		return fmt.Errorf("insufficient disk space: pulling needs about %s but only %s is free on the Docker data root (%s)",
+			formatDiskSize(required), formatDiskSize(free), root)
+	}
+	return nil
+}
+
+// requiredPullSpace estimates the bytes needed to pull the candidate images.
+// Layers already present in a current image, or shared between candidates, are counted once or not at all.
+func requiredPullSpace(current, candidate [][]registry.ImageLayer) uint64 {
+	seen := make(map[string]bool)
+	for _, layers := range current {
+		for _, layer := range layers {
+			seen[layer.Digest] = true
+		}
+	}
+
+	var total uint64
+	for _, layers := range candidate {
+		for _, layer := range layers {
+			if seen[layer.Digest] || layer.Size <= 0 {
+				continue
+			}
+			seen[layer.Digest] = true
+			total += uint64(layer.Size)
+		}
+	}
+	return total * layerExtractionFactor
+}
+
+// formatDiskSize formats a byte count for error messages.
+func formatDiskSize(bytes uint64) string {
+	const gb = 1024 * 1024 * 1024
+	if bytes >= gb {
+		return fmt.Sprintf("%.1f GB", float64(bytes)/gb)
+	}
+	return fmt.Sprintf("%.1f MB", float64(bytes)/(1024*1024))
+}
@@ -0,0 +1,56 @@
+package update
+
+import (
+	"context"
+	"testing"
+
+	"github.com/chis/docksmith/internal/docker"
+	"github.com/chis/docksmith/internal/registry"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequiredPullSpace(t *testing.T) {
+	current := [][]registry.ImageLayer{
+		{{Digest: "sha256:base", Size: 100}, {Digest: "sha256:app-1", Size: 50}},
+	}
+	candidate := [][]registry.ImageLayer{
+		{{Digest: "sha256:base", Size: 100}, {Digest: "sha256:app-2", Size: 60}},
+		{{Digest: "sha256:base", Size: 100}, {Digest: "sha256:app-2", Size: 60}, {Digest: "sha256:worker", Size: 40}},
+	}
+
+	// Only app-2 and worker are missing, and app-2 is shared between candidates
+	assert.Equal(t, uint64((60+40)*layerExtractionFactor), requiredPullSpace(current, candidate))
+	assert.Equal(t, uint64((100+60+40)*layerExtractionFactor), requiredPullSpace(nil, candidate))
+	assert.Equal(t, uint64(0), requiredPullSpace(candidate, candidate))
+}
+
+func TestFormatDiskSize(t *testing.T) {
+	assert.Equal(t, "512.0 MB", formatDiskSize(512*1024*1024))
+	assert.Equal(t, "2.5 GB", formatDiskSize(5*1024*1024*1024/2))
+}
+
+func TestFreeDiskSpace(t *testing.T) {
+	free, err := freeDiskSpace(t.TempDir())
+	if err != nil {
+		t.Skipf("disk space not supported here: %v", err)
+	}
+	assert.Greater(t, free, uint64(0))
+
+	_, err = freeDiskSpace("/nonexistent/docker/root")
+	assert.Error(t, err)
+}
+
+func TestCheckArchitecture(t *testing.T) {
+	setHostArchitecture(t, "arm64")
+
+	checker := newArchTestChecker(nil)
+	o := &UpdateOrchestrator{checker: checker}
+	containers := []*docker.Container{{Name: "test", Image: "docker.io/library/nginx:1.24.0"}}
+
+	err := o.checkArchitecture(context.Background(), containers, map[string]string{"test": "1.27.0"})
+	assert.EqualError(t, err, "docker.io/library/nginx:1.27.0 has no image for linux/arm64")
+
+	assert.NoError(t, o.checkArchitecture(context.Background(), containers, map[string]string{"test": "1.25.0"}))
+	// Tags without a platform list are assumed to support the host
+	assert.NoError(t, o.checkArchitecture(context.Background(), containers, map[string]string{"test": "1.24.0"}))
+}
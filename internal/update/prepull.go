@@ -0,0 +1,196 @@
+package update
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/chis/docksmith/internal/docker"
+)
+
+// PrepulledImagesConfigKey stores the images pulled ahead of scheduled updates as a JSON list.
+const PrepulledImagesConfigKey = "prepulled_images"
+
+// prepullRecordTTL is how long a pre-pulled image is remembered. Older records are
+// dropped, so the image is pulled normally at update time.
+const prepullRecordTTL = 7 * 24 * time.Hour
+
+// PrepulledImage is an update's target image pulled ahead of the update.
+type PrepulledImage struct {
+	Image         string    `json:"image"`
+	Digest        string    `json:"digest"` // Digest of the pulled image, compared with the registry at update time
+	ContainerName string    `json:"container_name"`
+	PulledAt      time.Time `json:"pulled_at"`
+	Error         string    `json:"error,omitempty"` // Why the pull failed; failed pulls are not recorded
+}
+
+// PrepullImages pulls the target images of updates ahead of time, so the update
+// only has to recreate the containers. targets maps container names to the version
+// they will be updated to, like the targetVersions of a batch update. Pulled images
+// are recorded with their digest; at update time a recorded image whose digest still
+// matches the registry is not pulled again. Returns one entry per container.
+func (o *UpdateOrchestrator) PrepullImages(ctx context.Context, targets map[string]string) ([]PrepulledImage, error) {
+	if err := o.checkWritable(); err != nil {
+		return nil, err
+	}
+	if len(targets) == 0 {
+		return []PrepulledImage{}, nil
+	}
+
+	containers, err := o.dockerClient.ListContainers(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list containers: %w", err)
+	}
+	byName := make(map[string]docker.Container, len(containers))
+	for _, c := range containers {
+		byName[c.Name] = c
+	}
+
+	names := make([]string, 0, len(targets))
+	for name := range targets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	results := make([]PrepulledImage, 0, len(names))
+	var pulled []PrepulledImage
+	for _, name := range names {
+		c, ok := byName[name]
+		if !ok || rebuildsOnBaseUpdate(&c) {
+			continue
+		}
+		target := targets[name]
+		if _, tag := splitImageRef(c.Image); target == "" && tag == "latest" {
+			target = "latest"
+		}
+		if target == "" {
+			continue
+		}
+
+		imageRef := replaceImageTag(c.Image, target)
+		result := PrepulledImage{Image: imageRef, ContainerName: name}
+		log.Printf("PREPULL: Pulling %s for %s", imageRef, name)
+		if err := o.pullImage(ctx, imageRef, nil); err != nil {
+			log.Printf("PREPULL: Failed to pull %s: %v", imageRef, err)
+			result.Error = err.Error()
+			results = append(results, result)
+			continue
+		}
+		digest, err := o.dockerClient.GetImageDigest(ctx, imageRef)
+		if err != nil {
+			log.Printf("PREPULL: Failed to get digest of %s: %v", imageRef, err)
+			result.Error = err.Error()
+			results = append(results, result)
+			continue
+		}
+		result.Digest = digest
+		result.PulledAt = time.Now()
+		results = append(results, result)
+		pulled = append(pulled, result)
+	}
+
+	if err := o.recordPrepulled(ctx, pulled); err != nil {
+		log.Printf("PREPULL: Warning: %v", err)
+	}
+	return results, nil
+}
+
+// PrepulledImages returns the recorded pre-pulled images, newest first.
+func (o *UpdateOrchestrator) PrepulledImages(ctx context.Context) ([]PrepulledImage, error) {
+	images, err := o.loadPrepulled(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(images, func(i, j int) bool { return images[i].PulledAt.After(images[j].PulledAt) })
+	return images, nil
+}
+
+// prepulledCurrent reports whether imageRef was pre-pulled and is still what the
+// registry serves for its tag, so pulling it again can be skipped. Any doubt, such
+// as a failed registry lookup or a local image that changed, means it is pulled.
+func (o *UpdateOrchestrator) prepulledCurrent(ctx context.Context, imageRef string) bool {
+	if o.storage == nil || o.checker == nil || o.checker.registryManager == nil {
+		return false
+	}
+	images, err := o.loadPrepulled(ctx)
+	if err != nil {
+		return false
+	}
+	idx := slices.IndexFunc(images, func(p PrepulledImage) bool { return p.Image == imageRef })
+	if idx < 0 {
+		return false
+	}
+	record := images[idx]
+
+	if local, err := o.dockerClient.GetImageDigest(ctx, imageRef); err != nil || local != record.Digest {
+		logStep(ctx, "", logSourcePull, "Pre-pulled %s is no longer the local image, pulling it", imageRef)
+		return false
+	}
+	repo, tag := splitImageRef(imageRef)
+	if tag == "" || strings.Contains(repo, "@") {
+		return false
+	}
+	imgInfo := o.checker.extractor.ExtractFromImage(imageRef)
+	remote, err := o.checker.registryManager.GetTagDigest(ctx, imgInfo.Registry+"/"+imgInfo.Repository, tag)
+	if err != nil {
+		log.Printf("UPDATE: Could not verify pre-pulled %s, pulling it: %v", imageRef, err)
+		return false
+	}
+	if remote != record.Digest {
+		log.Printf("UPDATE: Pre-pulled %s is stale (registry has %s), pulling it", imageRef, remote[:min(19, len(remote))])
+		logStep(ctx, "", logSourcePull, "Pre-pulled %s is stale, pulling it again", imageRef)
+		return false
+	}
+
+	log.Printf("UPDATE: Using %s pre-pulled at %s, digest %s is current", imageRef, record.PulledAt.Format(time.RFC3339), remote[:min(19, len(remote))])
+	logStep(ctx, "", logSourcePull, "Using %s pre-pulled at %s, its digest is current", imageRef, record.PulledAt.Local().Format("2006-01-02 15:04"))
+	return true
+}
+
+// loadPrepulled reads the pre-pulled image records, leaving out expired ones.
+func (o *UpdateOrchestrator) loadPrepulled(ctx context.Context) ([]PrepulledImage, error) {
+	if o.storage == nil {
+		return []PrepulledImage{}, nil
+	}
+	value, found, err := o.storage.GetConfig(ctx, PrepulledImagesConfigKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load pre-pulled images: %w", err)
+	}
+	images := []PrepulledImage{}
+	if !found || value == "" {
+		return images, nil
+	}
+	if err := json.Unmarshal([]byte(value), &images); err != nil {
+		return nil, fmt.Errorf("failed to decode pre-pulled images: %w", err)
+	}
+	return slices.DeleteFunc(images, func(p PrepulledImage) bool { return time.Since(p.PulledAt) > prepullRecordTTL }), nil
+}
+
+// recordPrepulled adds pulled images to the records, replacing older records of the same image.
+func (o *UpdateOrchestrator) recordPrepulled(ctx context.Context, pulled []PrepulledImage) error {
+	if o.storage == nil || len(pulled) == 0 {
+		return nil
+	}
+	images, err := o.loadPrepulled(ctx)
+	if err != nil {
+		images = []PrepulledImage{}
+	}
+	for _, p := range pulled {
+		images = slices.DeleteFunc(images, func(existing PrepulledImage) bool { return existing.Image == p.Image })
+		images = append(images, p)
+	}
+
+	data, err := json.Marshal(images)
+	if err != nil {
+		return fmt.Errorf("failed to encode pre-pulled images: %w", err)
+	}
+	if err := o.storage.SetConfig(ctx, PrepulledImagesConfigKey, string(data)); err != nil {
+		return fmt.Errorf("failed to save pre-pulled images: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package update
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/chis/docksmith/internal/storage"
+)
+
+func TestPrepulledCurrent(t *testing.T) {
+	ctx := context.Background()
+	dockerClient := &MockDockerClient{imageDigests: map[string]string{"nginx:1.26": "sha256:aaa"}}
+	registryManager := &mockRegistryManager{tagDigests: map[string]string{"docker.io/library/nginx:1.26": "sha256:aaa"}}
+	o := &UpdateOrchestrator{
+		dockerClient: dockerClient,
+		storage:      storage.NewMemoryStorage(),
+		checker:      NewChecker(dockerClient, registryManager, nil),
+	}
+
+	assert.False(t, o.prepulledCurrent(ctx, "nginx:1.26"), "not pre-pulled")
+
+	require.NoError(t, o.recordPrepulled(ctx, []PrepulledImage{{Image: "nginx:1.26", Digest: "sha256:aaa", PulledAt: time.Now()}}))
+	assert.True(t, o.prepulledCurrent(ctx, "nginx:1.26"))
+	assert.False(t, o.prepulledCurrent(ctx, "nginx:1.27"))
+
+	// A new image pushed to the tag since the pre-pull
+	registryManager.tagDigests["docker.io/library/nginx:1.26"] = "sha256:bbb"
+	assert.False(t, o.prepulledCurrent(ctx, "nginx:1.26"))
+
+	// The registry can't be reached
+	registryManager.getDigestError = errors.New("connection refused")
+	assert.False(t, o.prepulledCurrent(ctx, "nginx:1.26"))
+	registryManager.getDigestError = nil
+	registryManager.tagDigests["docker.io/library/nginx:1.26"] = "sha256:aaa"
+
+	// The local image was replaced
+	dockerClient.imageDigests["nginx:1.26"] = "sha256:ccc"
+	assert.False(t, o.prepulledCurrent(ctx, "nginx:1.26"))
+}
+
+func TestRecordPrepulled(t *testing.T) {
+	ctx := context.Background()
+	o := &UpdateOrchestrator{storage: storage.NewMemoryStorage()}
+	now := time.Now()
+
+	require.NoError(t, o.recordPrepulled(ctx, []PrepulledImage{
+		{Image: "nginx:1.26", Digest: "sha256:old", PulledAt: now.Add(-time.Hour)},
+		{Image: "redis:7.4", Digest: "sha256:redis", PulledAt: now.Add(-8 * 24 * time.Hour)},
+	}))
+	require.NoError(t, o.recordPrepulled(ctx, []PrepulledImage{
+		{Image: "nginx:1.26", Digest: "sha256:new", PulledAt: now},
+		{Image: "postgres:17", Digest: "sha256:pg", PulledAt: now.Add(-time.Minute)},
+	}))
+
+	images, err := o.PrepulledImages(ctx)
+	require.NoError(t, err)
+	require.Len(t, images, 2, "expired records are dropped")
+	assert.Equal(t, "nginx:1.26", images[0].Image)
+	assert.Equal(t, "sha256:new", images[0].Digest, "records of the same image are replaced")
+	assert.Equal(t, "postgres:17", images[1].Image)
+}
@@ -0,0 +1,131 @@
+package update
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/chis/docksmith/internal/compose"
+	"github.com/chis/docksmith/internal/docker"
+)
+
+// ComposeChange describes the compose file edit that would update a container,
+// without applying it.
+type ComposeChange struct {
+	ContainerName string
+	Service       string
+	Path          string // Compose file containing the service (as seen by docksmith)
+	OldImage      string
+	NewImage      string
+	Before        []byte
+	After         []byte
+}
+
+// Diff renders the change as a unified diff of the compose file.
+func (c *ComposeChange) Diff() (string, error) {
+	return compose.UnifiedDiff(strings.TrimPrefix(filepath.ToSlash(c.Path), "/"), c.Before, c.After)
+}
+
+// ProposeComposeChange computes the compose file change for updating a
+// container to targetVersion. Nothing is written and no container is touched.
+func (o *UpdateOrchestrator) ProposeComposeChange(ctx context.Context, containerName, targetVersion string) (*ComposeChange, error) {
+	if targetVersion == "" {
+		return nil, fmt.Errorf("target version is empty")
+	}
+
+	containers, err := o.dockerClient.ListContainers(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list containers: %w", err)
+	}
+
+	var container *docker.Container
+	for _, c := range containers {
+		if c.Name == containerName || c.ID == containerName {
+			container = &c
+			break
+		}
+	}
+	if container == nil {
+		return nil, NewNotFoundError("container not found: %s", containerName)
+	}
+
+	composeFilePath := o.getComposeFilePath(container)
+	if composeFilePath == "" {
+		return nil, fmt.Errorf("container %s is not managed by compose", containerName)
+	}
+	resolvedPath, err := o.resolveComposeFile(composeFilePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve compose file: %w", err)
+	}
+
+	serviceName := container.Labels["com.docker.compose.service"]
+	if serviceName == "" {
+		return nil, fmt.Errorf("container has no service label")
+	}
+
+	composeFile, err := compose.LoadComposeFileOrIncluded(resolvedPath, serviceName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load compose file: %w", err)
+	}
+	service, err := composeFile.FindServiceByContainerName(serviceName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find service %s: %w", serviceName, err)
+	}
+
+	oldImage := compose.GetServiceImage(service)
+	if compose.ContainsEnvVar(oldImage) {
+		return nil, fmt.Errorf("image for %s is set by an environment variable (%s)", serviceName, oldImage)
+	}
+	newImage := replaceImageTag(oldImage, targetVersion)
+	if newImage == oldImage {
+		return nil, fmt.Errorf("compose file already references %s", newImage)
+	}
+
+	before, after, err := composeFile.SetImageInPlace(service, newImage)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ComposeChange{
+		ContainerName: container.Name,
+		Service:       serviceName,
+		Path:          composeFile.Path,
+		OldImage:      oldImage,
+		NewImage:      newImage,
+		Before:        before,
+		After:         after,
+	}, nil
+}
+
+// ComposePreview is the compose file change an update would write, for review
+// before the update is applied.
+type ComposePreview struct {
+	ContainerName string `json:"container_name"`
+	TargetVersion string `json:"target_version"`
+	ComposeFile   string `json:"compose_file,omitempty"`
+	Service       string `json:"service,omitempty"`
+	OldImage      string `json:"old_image,omitempty"`
+	NewImage      string `json:"new_image,omitempty"`
+	Diff          string `json:"diff,omitempty"`  // Unified diff of the compose file
+	Error         string `json:"error,omitempty"` // Why there is no diff, e.g. the image is set by an environment variable
+}
+
+// PreviewComposeChange returns the compose file change updating a container to
+// targetVersion would write. Failures are reported in the preview's Error.
+func (o *UpdateOrchestrator) PreviewComposeChange(ctx context.Context, containerName, targetVersion string) ComposePreview {
+	preview := ComposePreview{ContainerName: containerName, TargetVersion: targetVersion}
+	change, err := o.ProposeComposeChange(ctx, containerName, targetVersion)
+	if err != nil {
+		preview.Error = err.Error()
+		return preview
+	}
+	preview.ComposeFile = change.Path
+	preview.Service = change.Service
+	preview.OldImage = change.OldImage
+	preview.NewImage = change.NewImage
+	if preview.Diff, err = change.Diff(); err != nil {
+		preview.Error = fmt.Sprintf("failed to build diff: %v", err)
+	}
+	return preview
+}
@@ -0,0 +1,47 @@
+package update
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/chis/docksmith/internal/docker"
+)
+
+func TestPreviewComposeChange(t *testing.T) {
+	composePath := filepath.Join(t.TempDir(), "docker-compose.yml")
+	before := "services:\n  web:\n    image: nginx:1.25\n    restart: always\n"
+	require.NoError(t, os.WriteFile(composePath, []byte(before), 0644))
+
+	orch := &UpdateOrchestrator{dockerClient: &mockDockerClient{containers: []docker.Container{{
+		Name:  "web",
+		Image: "nginx:1.25",
+		Labels: map[string]string{
+			"com.docker.compose.service":              "web",
+			"com.docker.compose.project.config_files": composePath,
+		},
+	}}}}
+
+	preview := orch.PreviewComposeChange(context.Background(), "web", "1.27")
+	require.Empty(t, preview.Error)
+	assert.Equal(t, "nginx:1.25", preview.OldImage)
+	assert.Equal(t, "nginx:1.27", preview.NewImage)
+	assert.Contains(t, preview.Diff, "-    image: nginx:1.25\n+    image: nginx:1.27\n")
+	assert.Contains(t, preview.Diff, "+++ b/"+filepath.ToSlash(composePath)[1:])
+
+	// Nothing is written
+	data, err := os.ReadFile(composePath)
+	require.NoError(t, err)
+	assert.Equal(t, before, string(data))
+
+	preview = orch.PreviewComposeChange(context.Background(), "web", "1.25")
+	assert.Contains(t, preview.Error, "already references nginx:1.25")
+	assert.Empty(t, preview.Diff)
+
+	preview = orch.PreviewComposeChange(context.Background(), "missing", "1.27")
+	assert.Contains(t, preview.Error, "container not found")
+}
@@ -0,0 +1,248 @@
+package update
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+	"time"
+
+	"github.com/chis/docksmith/internal/events"
+	"github.com/chis/docksmith/internal/storage"
+)
+
+// etaSampleSize is how many recent completed operations the queue ETA is averaged over.
+const etaSampleSize = 50
+
+// Queue change actions, published as the action of EventQueueChanged.
+const (
+	QueueActionQueued      = "queued"      // An operation was queued behind a stack lock
+	QueueActionStarted     = "started"     // A queued operation took the stack lock
+	QueueActionRemoved     = "removed"     // A queued operation was removed from the queue
+	QueueActionPrioritized = "prioritized" // The priority of a queued operation changed
+	QueueActionReordered   = "reordered"   // The queue of a stack was reordered
+)
+
+// QueuedOperation is an operation waiting for its stack's lock.
+type QueuedOperation struct {
+	storage.UpdateQueue
+	Position int `json:"position"` // 1-based position in the stack's queue
+}
+
+// Queue returns the queued operations in the order they will start, with their
+// position in their stack's queue. Estimated start times are based on the average
+// duration of recent operations and are left out when there is no history.
+func (o *UpdateOrchestrator) Queue(ctx context.Context) ([]QueuedOperation, error) {
+	if o.storage == nil {
+		return nil, nil
+	}
+	queued, err := o.storage.GetQueuedUpdates(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get queued updates: %w", err)
+	}
+
+	avg := o.averageOperationDuration(ctx)
+	now := time.Now()
+	positions := make(map[string]int)
+	result := make([]QueuedOperation, 0, len(queued))
+	for _, q := range queued {
+		positions[q.StackName]++
+		op := QueuedOperation{UpdateQueue: q, Position: positions[q.StackName]}
+		if avg > 0 {
+			start := now.Add(o.lockRemaining(q.StackName, avg, now) + time.Duration(op.Position-1)*avg)
+			op.EstimatedStartTime = &start
+		}
+		result = append(result, op)
+	}
+	return result, nil
+}
+
+// averageOperationDuration returns the average duration of recent completed
+// operations, or zero when none were recorded.
+func (o *UpdateOrchestrator) averageOperationDuration(ctx context.Context) time.Duration {
+	ops, err := o.storage.GetUpdateOperationsByStatus(ctx, storage.StatusComplete, etaSampleSize)
+	if err != nil {
+		return 0
+	}
+	var total time.Duration
+	var count int
+	for _, op := range ops {
+		if op.StartedAt == nil || op.CompletedAt == nil || op.CompletedAt.Before(*op.StartedAt) {
+			continue
+		}
+		total += op.CompletedAt.Sub(*op.StartedAt)
+		count++
+	}
+	if count == 0 {
+		return 0
+	}
+	return total / time.Duration(count)
+}
+
+// lockRemaining estimates how long the current holder of a stack's lock keeps it,
+// assuming operations take avg. Returns zero when the stack is not locked.
+func (o *UpdateOrchestrator) lockRemaining(stackName string, avg time.Duration, now time.Time) time.Duration {
+	o.locksMu.Lock()
+	defer o.locksMu.Unlock()
+	entry, exists := o.stackLocks[stackName]
+	if !exists || !entry.held {
+		return 0
+	}
+	return max(avg-now.Sub(entry.acquiredAt), 0)
+}
+
+// SetQueuePriority changes the priority of a queued operation. Operations with a
+// higher priority start first on their stack; equal priorities start in queue order.
+func (o *UpdateOrchestrator) SetQueuePriority(ctx context.Context, operationID string, priority int) error {
+	q, err := o.queuedOperation(ctx, operationID)
+	if err != nil {
+		return err
+	}
+	if err := o.storage.SetQueuePriorities(ctx, map[string]int{operationID: priority}); err != nil {
+		return err
+	}
+	log.Printf("QUEUE: Set priority of operation %s on stack %s to %d", operationID, q.StackName, priority)
+	o.publishQueueChanged(QueueActionPrioritized, q.StackName, operationID)
+	return nil
+}
+
+// ReorderQueue reorders the queue of a stack. The given operations move to the
+// front in the given order and the others follow in their current order.
+func (o *UpdateOrchestrator) ReorderQueue(ctx context.Context, stackName string, operationIDs []string) error {
+	if len(operationIDs) == 0 {
+		return NewBadRequestError("no operations to reorder")
+	}
+	if o.storage == nil {
+		return NewNotFoundError("stack %s has no queued operations", stackName)
+	}
+	queued, err := o.storage.GetQueuedUpdates(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get queued updates: %w", err)
+	}
+
+	var current []string
+	for _, q := range queued {
+		if q.StackName == stackName {
+			current = append(current, q.OperationID)
+		}
+	}
+	if len(current) == 0 {
+		return NewNotFoundError("stack %s has no queued operations", stackName)
+	}
+
+	order := make([]string, 0, len(current))
+	for _, id := range operationIDs {
+		if !slices.Contains(current, id) {
+			return NewBadRequestError("operation %s is not queued on stack %s", id, stackName)
+		}
+		if slices.Contains(order, id) {
+			return NewBadRequestError("operation %s is listed more than once", id)
+		}
+		order = append(order, id)
+	}
+	for _, id := range current {
+		if !slices.Contains(order, id) {
+			order = append(order, id)
+		}
+	}
+
+	// Priorities count down to zero, so the stack's queue runs in the new order
+	priorities := make(map[string]int, len(order))
+	for i, id := range order {
+		priorities[id] = len(order) - 1 - i
+	}
+	if err := o.storage.SetQueuePriorities(ctx, priorities); err != nil {
+		return err
+	}
+	log.Printf("QUEUE: Reordered queue of stack %s: %v", stackName, order)
+	o.publishQueueChanged(QueueActionReordered, stackName, "")
+	return nil
+}
+
+// CancelQueuedOperation removes an operation from the queue and marks it cancelled.
+func (o *UpdateOrchestrator) CancelQueuedOperation(ctx context.Context, operationID string) error {
+	q, err := o.queuedOperation(ctx, operationID)
+	if err != nil {
+		return err
+	}
+	// The queue processor may have started the operation since it was looked up
+	removed, err := o.storage.DeleteQueuedUpdate(ctx, operationID)
+	if err != nil {
+		return err
+	}
+	if !removed {
+		return NewNotFoundError("operation %s is not queued", operationID)
+	}
+
+	if err := o.storage.UpdateOperationStatus(ctx, operationID, "cancelled", "Removed from queue"); err != nil {
+		log.Printf("QUEUE: Failed to mark operation %s cancelled: %v", operationID, err)
+	}
+	log.Printf("QUEUE: Removed operation %s from the queue of stack %s", operationID, q.StackName)
+	o.publishQueueChanged(QueueActionRemoved, q.StackName, operationID)
+	return nil
+}
+
+// queuedOperation returns the queue entry of an operation.
+func (o *UpdateOrchestrator) queuedOperation(ctx context.Context, operationID string) (storage.UpdateQueue, error) {
+	if o.storage != nil {
+		queued, err := o.storage.GetQueuedUpdates(ctx)
+		if err != nil {
+			return storage.UpdateQueue{}, fmt.Errorf("failed to get queued updates: %w", err)
+		}
+		for _, q := range queued {
+			if q.OperationID == operationID {
+				return q, nil
+			}
+		}
+	}
+	return storage.UpdateQueue{}, NewNotFoundError("operation %s is not queued", operationID)
+}
+
+// queueOperation adds an operation to the queue.
+func (o *UpdateOrchestrator) queueOperation(ctx context.Context, operationID, stackName string, containers []string, operationType string, targetVersions map[string]string) error {
+	queue := storage.UpdateQueue{
+		OperationID:    operationID,
+		StackName:      stackName,
+		Containers:     containers,
+		OperationType:  operationType,
+		TargetVersions: targetVersions,
+		QueuedAt:       time.Now(),
+	}
+
+	if err := o.storage.QueueUpdate(ctx, queue); err != nil {
+		return err
+	}
+	o.publishQueueChanged(QueueActionQueued, stackName, operationID)
+
+	// Check if an operation record already exists (e.g., saved by the caller with full details).
+	// Only create a sparse record if none exists, to avoid overwriting detailed metadata.
+	if existing, found, _ := o.storage.GetUpdateOperation(ctx, operationID); found {
+		existing.Status = "queued"
+		return o.storage.SaveUpdateOperation(ctx, existing)
+	}
+
+	op := storage.UpdateOperation{
+		OperationID:   operationID,
+		TriggeredBy:   TriggerFromContext(ctx),
+		StackName:     stackName,
+		OperationType: operationType,
+		Status:        "queued",
+	}
+	return o.storage.SaveUpdateOperation(ctx, op)
+}
+
+// publishQueueChanged publishes a change to the queue of a stack.
+func (o *UpdateOrchestrator) publishQueueChanged(action, stackName, operationID string) {
+	if o.eventBus == nil {
+		return
+	}
+	o.eventBus.Publish(events.Event{
+		Type: events.EventQueueChanged,
+		Payload: map[string]interface{}{
+			"action":       action,
+			"stack_name":   stackName,
+			"operation_id": operationID,
+			"timestamp":    time.Now().Unix(),
+		},
+	})
+}
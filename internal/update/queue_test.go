@@ -0,0 +1,126 @@
+package update
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/chis/docksmith/internal/events"
+	"github.com/chis/docksmith/internal/storage"
+)
+
+func newQueueTestOrchestrator(t *testing.T, ops ...string) (*UpdateOrchestrator, storage.Storage, events.Subscriber) {
+	t.Helper()
+	orch, store := newLockTestOrchestrator(t)
+	bus := events.NewBus()
+	orch.eventBus = bus
+	ch, unsubscribe := bus.Subscribe(events.EventQueueChanged)
+	t.Cleanup(unsubscribe)
+
+	ctx := context.Background()
+	queuedAt := time.Now().Add(-time.Hour)
+	for i, id := range ops {
+		require.NoError(t, store.QueueUpdate(ctx, storage.UpdateQueue{OperationID: id, StackName: "media", QueuedAt: queuedAt.Add(time.Duration(i) * time.Minute)}))
+		require.NoError(t, store.SaveUpdateOperation(ctx, storage.UpdateOperation{OperationID: id, StackName: "media", Status: storage.StatusQueued}))
+	}
+	return orch, store, ch
+}
+
+func queueOrderOf(t *testing.T, orch *UpdateOrchestrator) []string {
+	t.Helper()
+	queue, err := orch.Queue(context.Background())
+	require.NoError(t, err)
+	var ids []string
+	for _, q := range queue {
+		ids = append(ids, q.OperationID)
+	}
+	return ids
+}
+
+func TestQueue_PositionsAndETA(t *testing.T) {
+	orch, store, _ := newQueueTestOrchestrator(t, "a", "b")
+	ctx := context.Background()
+	require.NoError(t, store.QueueUpdate(ctx, storage.UpdateQueue{OperationID: "c", StackName: "db", QueuedAt: time.Now()}))
+
+	// Without history there is no estimate
+	queue, err := orch.Queue(ctx)
+	require.NoError(t, err)
+	require.Len(t, queue, 3)
+	assert.Nil(t, queue[0].EstimatedStartTime)
+
+	started := time.Now().Add(-time.Hour)
+	completed := started.Add(10 * time.Minute)
+	require.NoError(t, store.SaveUpdateOperation(ctx, storage.UpdateOperation{OperationID: "done", Status: storage.StatusComplete, StartedAt: &started, CompletedAt: &completed}))
+	orch.acquireStackLock("media", "running")
+	orch.stackLocks["media"].acquiredAt = time.Now().Add(-4 * time.Minute)
+
+	queue, err = orch.Queue(ctx)
+	require.NoError(t, err)
+	positions := make(map[string]QueuedOperation)
+	for _, q := range queue {
+		positions[q.OperationID] = q
+	}
+	assert.Equal(t, 1, positions["a"].Position)
+	assert.Equal(t, 2, positions["b"].Position)
+	assert.Equal(t, 1, positions["c"].Position)
+
+	require.NotNil(t, positions["a"].EstimatedStartTime)
+	assert.WithinDuration(t, time.Now().Add(6*time.Minute), *positions["a"].EstimatedStartTime, 5*time.Second)
+	assert.WithinDuration(t, time.Now().Add(16*time.Minute), *positions["b"].EstimatedStartTime, 5*time.Second)
+	assert.WithinDuration(t, time.Now(), *positions["c"].EstimatedStartTime, 5*time.Second)
+}
+
+func TestSetQueuePriority(t *testing.T) {
+	orch, _, ch := newQueueTestOrchestrator(t, "a", "b", "c")
+	ctx := context.Background()
+
+	require.NoError(t, orch.SetQueuePriority(ctx, "c", 5))
+	assert.Equal(t, []string{"c", "a", "b"}, queueOrderOf(t, orch))
+
+	event := <-ch
+	assert.Equal(t, QueueActionPrioritized, event.Payload["action"])
+	assert.Equal(t, "c", event.Payload["operation_id"])
+
+	var notFound *NotFoundError
+	assert.ErrorAs(t, orch.SetQueuePriority(ctx, "missing", 1), &notFound)
+}
+
+func TestReorderQueue(t *testing.T) {
+	orch, _, ch := newQueueTestOrchestrator(t, "a", "b", "c", "d")
+	ctx := context.Background()
+
+	require.NoError(t, orch.ReorderQueue(ctx, "media", []string{"c", "a"}))
+	assert.Equal(t, []string{"c", "a", "b", "d"}, queueOrderOf(t, orch))
+	assert.Equal(t, QueueActionReordered, (<-ch).Payload["action"])
+
+	var badRequest *BadRequestError
+	assert.ErrorAs(t, orch.ReorderQueue(ctx, "media", []string{"x"}), &badRequest)
+	assert.ErrorAs(t, orch.ReorderQueue(ctx, "media", []string{"a", "a"}), &badRequest)
+	assert.ErrorAs(t, orch.ReorderQueue(ctx, "media", nil), &badRequest)
+
+	var notFound *NotFoundError
+	assert.ErrorAs(t, orch.ReorderQueue(ctx, "db", []string{"a"}), &notFound)
+}
+
+func TestCancelQueuedOperation(t *testing.T) {
+	orch, store, ch := newQueueTestOrchestrator(t, "a", "b")
+	ctx := context.Background()
+
+	require.NoError(t, orch.CancelQueuedOperation(ctx, "a"))
+	assert.Equal(t, []string{"b"}, queueOrderOf(t, orch))
+
+	op, found, err := store.GetUpdateOperation(ctx, "a")
+	require.NoError(t, err)
+	require.True(t, found)
+	assert.Equal(t, "cancelled", op.Status)
+
+	event := <-ch
+	assert.Equal(t, QueueActionRemoved, event.Payload["action"])
+	assert.Equal(t, "media", event.Payload["stack_name"])
+
+	var notFound *NotFoundError
+	assert.ErrorAs(t, orch.CancelQueuedOperation(ctx, "a"), &notFound)
+}
@@ -0,0 +1,44 @@
+package update
+
+import (
+	"log"
+	"os"
+	"strconv"
+)
+
+// SetReadOnly enables read-only mode, in which the orchestrator refuses every
+// operation that would change containers, so the dashboard can be shared for
+// visibility only. Must be called before any update starts.
+func (o *UpdateOrchestrator) SetReadOnly(readOnly bool) {
+	o.readOnly = readOnly
+}
+
+// ReadOnly reports whether read-only mode is enabled.
+func (o *UpdateOrchestrator) ReadOnly() bool {
+	return o.readOnly
+}
+
+// ReadOnlyFromEnv reads read-only mode from DOCKSMITH_READ_ONLY (default false).
+func ReadOnlyFromEnv() bool {
+	value := os.Getenv("DOCKSMITH_READ_ONLY")
+	if value == "" {
+		return false
+	}
+	readOnly, err := strconv.ParseBool(value)
+	if err != nil {
+		log.Printf("Warning: Invalid DOCKSMITH_READ_ONLY '%s', read-only mode disabled", value)
+		return false
+	}
+	if readOnly {
+		log.Println("Using DOCKSMITH_READ_ONLY: updates, rollbacks, and restarts are disabled")
+	}
+	return readOnly
+}
+
+// checkWritable returns ErrReadOnly while read-only mode is enabled.
+func (o *UpdateOrchestrator) checkWritable() error {
+	if o.readOnly {
+		return ErrReadOnly
+	}
+	return nil
+}
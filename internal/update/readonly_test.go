@@ -0,0 +1,46 @@
+package update
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/chis/docksmith/internal/storage"
+)
+
+func TestReadOnlyRefusesChanges(t *testing.T) {
+	ctx := context.Background()
+	o := &UpdateOrchestrator{storage: storage.NewMemoryStorage()}
+	o.SetReadOnly(true)
+
+	calls := map[string]func() error{
+		"update": func() error { _, err := o.UpdateSingleContainer(ctx, "web", "2.0"); return err },
+		"batch": func() error {
+			_, err := o.UpdateBatchContainers(ctx, []string{"web"}, map[string]string{"web": "2.0"})
+			return err
+		},
+		"stack":    func() error { _, err := o.UpdateStack(ctx, "media"); return err },
+		"rollback": func() error { _, err := o.RollbackOperation(ctx, "op", true); return err },
+		"restart":  func() error { _, err := o.RestartSingleContainer(ctx, "web", true); return err },
+		"rebuild":  func() error { _, err := o.RebuildContainer(ctx, "web", true); return err },
+		"pin":      func() error { _, err := o.PinContainers(ctx, map[string]string{"web": "2.0"}, ""); return err },
+		"restore":  func() error { _, err := o.RestoreVolumes(ctx, "op"); return err },
+		"fix":      func() error { _, err := o.FixComposeMismatch(ctx, "web"); return err },
+	}
+	for name, call := range calls {
+		if err := call(); !errors.Is(err, ErrReadOnly) {
+			t.Errorf("%s: expected ErrReadOnly, got %v", name, err)
+		}
+	}
+}
+
+func TestReadOnlyFromEnv(t *testing.T) {
+	t.Setenv("DOCKSMITH_READ_ONLY", "true")
+	if !ReadOnlyFromEnv() {
+		t.Error("DOCKSMITH_READ_ONLY=true should enable read-only mode")
+	}
+	t.Setenv("DOCKSMITH_READ_ONLY", "maybe")
+	if ReadOnlyFromEnv() {
+		t.Error("invalid DOCKSMITH_READ_ONLY should leave read-only mode disabled")
+	}
+}
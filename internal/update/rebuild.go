@@ -0,0 +1,320 @@
+package update
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/chis/docksmith/internal/compose"
+	"github.com/chis/docksmith/internal/docker"
+	"github.com/chis/docksmith/internal/events"
+	"github.com/chis/docksmith/internal/scripts"
+	"github.com/chis/docksmith/internal/selfupdate"
+	"github.com/chis/docksmith/internal/storage"
+	"github.com/google/uuid"
+)
+
+// rebuildRollbackTag is the tag the previous image of a rebuilt service is kept
+// under, so pruning dangling images does not remove the image to roll back to.
+const rebuildRollbackTag = "docksmith-rollback"
+
+// shortImageID returns the 12 character form of an image ID, as shown by docker images.
+func shortImageID(id string) string {
+	id = strings.TrimPrefix(id, "sha256:")
+	if len(id) > 12 {
+		id = id[:12]
+	}
+	return id
+}
+
+// RebuildContainer rebuilds the image of a locally built compose service with
+// docker compose build --pull and recreates its container, with the health checks
+// and rollback of an update. The operation records the previous and the rebuilt
+// image IDs; rolling it back recreates the container on the previous image.
+func (o *UpdateOrchestrator) RebuildContainer(ctx context.Context, containerName string, force bool) (string, error) {
+	if err := o.checkWritable(); err != nil {
+		return "", err
+	}
+	operationID := uuid.New().String()
+
+	containers, err := o.dockerClient.ListContainers(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to list containers: %w", err)
+	}
+
+	var targetContainer *docker.Container
+	for _, c := range containers {
+		if c.Name == containerName {
+			targetContainer = &c
+			break
+		}
+	}
+	if targetContainer == nil {
+		return "", NewNotFoundError("container not found: %s", containerName)
+	}
+
+	if selfupdate.IsSelfContainer(targetContainer.ID, targetContainer.Image, targetContainer.Name) {
+		return "", NewBadRequestError("cannot rebuild the docksmith container")
+	}
+	composeFilePath := o.getComposeFilePath(targetContainer)
+	if composeFilePath == "" {
+		return "", NewBadRequestError("container %s is not managed by docker compose", containerName)
+	}
+	resolvedPath, err := o.resolveComposeFile(composeFilePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve compose file: %w", err)
+	}
+	if _, err := serviceDockerfile(targetContainer, resolvedPath); err != nil {
+		return "", NewBadRequestError("cannot rebuild %s: %v", containerName, err)
+	}
+
+	stackName := o.stackManager.DetermineStack(ctx, *targetContainer)
+
+	op := storage.UpdateOperation{
+		OperationID:        operationID,
+		TriggeredBy:        TriggerFromContext(ctx),
+		ContainerID:        targetContainer.ID,
+		ContainerName:      containerName,
+		StackName:          stackName,
+		OperationType:      "rebuild",
+		Status:             "validating",
+		DependentsAffected: o.findDependentContainerNames(containers, containerName),
+		CreatedAt:          time.Now(),
+	}
+	if err := o.storage.SaveUpdateOperation(ctx, op); err != nil {
+		return "", fmt.Errorf("failed to save operation: %w", err)
+	}
+
+	// Check if stack is locked
+	if stackName != "" && !o.acquireStackLock(stackName, operationID) {
+		if err := o.queueOperation(ctx, operationID, stackName, []string{containerName}, "rebuild", nil); err != nil {
+			return "", fmt.Errorf("failed to queue operation: %w", err)
+		}
+		o.publishProgress(operationID, containerName, stackName, "queued", 0, "Operation queued - stack is busy")
+		return operationID, nil
+	}
+
+	// Start rebuild in background with a fresh context (not tied to HTTP request)
+	go o.executeRebuild(context.Background(), operationID, targetContainer, stackName, force)
+
+	return operationID, nil
+}
+
+// executeRebuild builds the image of a container's service and recreates it.
+func (o *UpdateOrchestrator) executeRebuild(ctx context.Context, operationID string, container *docker.Container, stackName string, force bool) {
+	ctx = o.withOperationLog(ctx, operationID)
+
+	if stackName != "" {
+		defer o.releaseStackLock(stackName, operationID)
+	}
+
+	log.Printf("REBUILD: Starting executeRebuild for operation=%s container=%s", operationID, container.Name)
+
+	now := time.Now()
+	if op, found, _ := o.storage.GetUpdateOperation(ctx, operationID); found {
+		op.StartedAt = &now
+		o.storage.SaveUpdateOperation(ctx, op)
+	}
+
+	// Stage 1: Validating (0-20%)
+	o.publishProgress(operationID, container.Name, stackName, "validating", 0, "Validating permissions")
+
+	if o.dockerSDK == nil {
+		o.failOperation(ctx, operationID, "validating", "Docker API not available")
+		return
+	}
+	if err := o.checkPermissions(ctx, container); err != nil {
+		o.failOperation(ctx, operationID, "validating", fmt.Sprintf("Permission check failed: %v", err))
+		return
+	}
+
+	if scriptPath, ok := container.Labels[scripts.PreUpdateCheckLabel]; ok && scriptPath != "" {
+		if !force {
+			o.publishProgress(operationID, container.Name, stackName, "validating", 10, "Running pre-update check")
+			if err := runPreUpdateCheck(ctx, container, scriptPath); err != nil {
+				o.failOperation(ctx, operationID, "validating", fmt.Sprintf("Pre-update check failed: %v", err))
+				return
+			}
+			log.Printf("REBUILD: Pre-update check passed for container %s", container.Name)
+		} else {
+			log.Printf("REBUILD: Skipping pre-update check (force=true) for %s", container.Name)
+		}
+	}
+
+	// Keep the current image so the rebuild can be rolled back
+	oldImageID, err := o.keepRollbackImage(ctx, container)
+	if err != nil {
+		o.failOperation(ctx, operationID, "validating", fmt.Sprintf("Failed to keep the current image: %v", err))
+		return
+	}
+
+	// Stage 2: Building (30-60%)
+	o.publishProgress(operationID, container.Name, stackName, "building_image", 30, fmt.Sprintf("Rebuilding %s", container.Name))
+
+	recreator := compose.NewRecreator(o.dockerClient)
+	err = o.withUpdateSlot(ctx, func() error {
+		return recreator.BuildWithCompose(ctx, container, o.getComposeFilePathForHost(container), o.getComposeFilePath(container))
+	})
+	if err != nil {
+		o.failOperation(ctx, operationID, "building_image", fmt.Sprintf("Build failed: %v", err))
+		return
+	}
+
+	inspect, err := o.dockerSDK.ImageInspect(ctx, container.Image)
+	if err != nil {
+		o.failOperation(ctx, operationID, "building_image", fmt.Sprintf("Failed to inspect rebuilt image: %v", err))
+		return
+	}
+	newImageID := shortImageID(inspect.ID)
+
+	if op, found, _ := o.storage.GetUpdateOperation(ctx, operationID); found {
+		op.OldVersion = oldImageID
+		op.NewVersion = newImageID
+		o.storage.SaveUpdateOperation(ctx, op)
+	}
+	log.Printf("REBUILD: Built %s for container %s (previous image %s)", newImageID, container.Name, oldImageID)
+
+	if newImageID == oldImageID {
+		completedNow := time.Now()
+		if op, found, _ := o.storage.GetUpdateOperation(ctx, operationID); found {
+			op.Status = "complete"
+			op.CompletedAt = &completedNow
+			o.storage.SaveUpdateOperation(ctx, op)
+		}
+		o.publishProgress(operationID, container.Name, stackName, "complete", 100, "Image unchanged, container not recreated")
+		return
+	}
+
+	// Stage 3: Recreate, health check, and post-update check (60-100%)
+	_, currentTag := splitImageRef(container.Image)
+	o.finishSingleUpdate(ctx, operationID, container, currentTag, stackName)
+}
+
+// keepRollbackImage tags the image a container runs with rebuildRollbackTag and
+// returns its short ID.
+func (o *UpdateOrchestrator) keepRollbackImage(ctx context.Context, container *docker.Container) (string, error) {
+	inspect, err := o.dockerSDK.ContainerInspect(ctx, container.Name)
+	if err != nil {
+		return "", fmt.Errorf("failed to inspect container: %w", err)
+	}
+	repo, _ := splitImageRef(container.Image)
+	if err := o.dockerSDK.ImageTag(ctx, inspect.Image, repo+":"+rebuildRollbackTag); err != nil {
+		return "", fmt.Errorf("failed to tag image: %w", err)
+	}
+	return shortImageID(inspect.Image), nil
+}
+
+// rollbackRebuild starts the rollback of a rebuild operation, recreating the
+// container on the image it ran before the rebuild.
+func (o *UpdateOrchestrator) rollbackRebuild(ctx context.Context, origOp storage.UpdateOperation, container *docker.Container) (string, error) {
+	if origOp.OldVersion == "" {
+		return "", fmt.Errorf("no previous image recorded in operation %s", origOp.OperationID)
+	}
+
+	log.Printf("ROLLBACK: Rolling back rebuild of %s from image %s to %s", origOp.ContainerName, origOp.NewVersion, origOp.OldVersion)
+
+	rollbackOpID := uuid.New().String()
+	rollbackOp := storage.UpdateOperation{
+		OperationID:   rollbackOpID,
+		TriggeredBy:   TriggerFromContext(ctx),
+		ContainerID:   container.ID,
+		ContainerName: origOp.ContainerName,
+		StackName:     origOp.StackName,
+		OperationType: "rollback",
+		Status:        "in_progress",
+		OldVersion:    origOp.NewVersion,
+		NewVersion:    origOp.OldVersion,
+		CreatedAt:     time.Now(),
+		UpdatedAt:     time.Now(),
+		StartedAt:     func() *time.Time { t := time.Now(); return &t }(),
+	}
+	if err := o.storage.SaveUpdateOperation(ctx, rollbackOp); err != nil {
+		return "", fmt.Errorf("failed to save rollback operation: %w", err)
+	}
+
+	origOp.RollbackOccurred = true
+	if err := o.storage.SaveUpdateOperation(ctx, origOp); err != nil {
+		log.Printf("ROLLBACK: Failed to mark original operation as rolled back: %v", err)
+	}
+
+	go o.executeRebuildRollback(context.Background(), rollbackOpID, origOp.OperationID, container, origOp.OldVersion)
+
+	return rollbackOpID, nil
+}
+
+// executeRebuildRollback tags the previous image of a rebuilt service with the
+// service's image name and recreates the container on it.
+func (o *UpdateOrchestrator) executeRebuildRollback(ctx context.Context, rollbackOpID, originalOpID string, container *docker.Container, oldImageID string) {
+	ctx = o.withOperationLog(ctx, rollbackOpID)
+
+	stackName := container.Labels["com.docker.compose.project"]
+
+	// Stage 1: Re-tag the previous image (10-60%)
+	o.publishProgress(rollbackOpID, container.Name, stackName, "validating", 10, fmt.Sprintf("Restoring image %s as %s", oldImageID, container.Image))
+
+	if o.dockerSDK == nil {
+		o.failOperation(ctx, rollbackOpID, "validating", "Docker API not available")
+		return
+	}
+	if err := o.dockerSDK.ImageTag(ctx, oldImageID, container.Image); err != nil {
+		o.failOperation(ctx, rollbackOpID, "validating", fmt.Sprintf("Previous image %s is no longer available: %v", oldImageID, err))
+		return
+	}
+
+	if err := o.restoreVolumes(ctx, rollbackOpID, originalOpID, container, stackName); err != nil {
+		o.failOperation(ctx, rollbackOpID, "restoring_volumes", fmt.Sprintf("Volume restore failed: %v", err))
+		return
+	}
+
+	// Stage 2: Recreate container (60-80%) — compose uses the local image without building
+	o.publishProgress(rollbackOpID, container.Name, stackName, "recreating", 60, "Recreating container with previous image")
+
+	if _, err := o.restartContainerWithDependents(ctx, rollbackOpID, container.Name, stackName, container.Image); err != nil {
+		o.failOperation(ctx, rollbackOpID, "recreating", fmt.Sprintf("Failed to recreate container: %v", err))
+		return
+	}
+
+	// Stage 3: Health check (80-95%)
+	o.publishProgress(rollbackOpID, container.Name, stackName, "health_check", 80, "Verifying container health")
+
+	if err := o.waitForHealthy(ctx, container.Name, o.healthCheckCfg.Timeout); err != nil {
+		log.Printf("ROLLBACK: Warning - health check failed: %v", err)
+		o.publishProgress(rollbackOpID, container.Name, stackName, "health_check", 90, fmt.Sprintf("Health check warning: %v", err))
+	} else {
+		o.publishProgress(rollbackOpID, container.Name, stackName, "health_check", 95, "Health check passed")
+	}
+
+	depResult, depErr := o.restartDependentContainers(ctx, container.Name, true)
+	if depErr != nil {
+		log.Printf("ROLLBACK: Warning - failed to restart dependent containers for %s: %v", container.Name, depErr)
+	} else if depResult != nil && len(depResult.Restarted) > 0 {
+		log.Printf("ROLLBACK: Restarted dependents for %s: %v", container.Name, depResult.Restarted)
+	}
+
+	// Stage 4: Complete (100%)
+	now := time.Now()
+	if op, found, _ := o.storage.GetUpdateOperation(ctx, rollbackOpID); found {
+		op.Status = "complete"
+		op.CompletedAt = &now
+		o.storage.SaveUpdateOperation(ctx, op)
+	}
+
+	o.publishProgress(rollbackOpID, container.Name, stackName, "complete", 100, "Rebuild rolled back successfully")
+
+	if o.eventBus != nil {
+		o.eventBus.Publish(events.Event{
+			Type: events.EventContainerUpdated,
+			Payload: map[string]interface{}{
+				"operation_id":   rollbackOpID,
+				"container_id":   container.ID,
+				"container_name": container.Name,
+				"stack_name":     stackName,
+				"status":         "complete",
+			},
+		})
+	}
+
+	log.Printf("ROLLBACK: Rolled back rebuild of container %s to image %s", container.Name, oldImageID)
+}
@@ -0,0 +1,121 @@
+package update
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/chis/docksmith/internal/docker"
+	"github.com/chis/docksmith/internal/storage"
+)
+
+// newRebuildTestOrchestrator returns an orchestrator for an app container defined
+// in a compose file with the given content
+func newRebuildTestOrchestrator(t *testing.T, composeContent string) (*UpdateOrchestrator, storage.Storage) {
+	t.Helper()
+	dir := t.TempDir()
+	composePath := filepath.Join(dir, "docker-compose.yml")
+	if err := os.WriteFile(composePath, []byte(composeContent), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	store := storage.NewMemoryStorage()
+	mockDocker := &mockDockerClient{
+		containers: []docker.Container{{
+			ID:    "app-container",
+			Name:  "app",
+			Image: "myapp:latest",
+			Labels: map[string]string{
+				"com.docker.compose.project":              "web",
+				"com.docker.compose.service":              "app",
+				"com.docker.compose.project.config_files": composePath,
+			},
+		}},
+	}
+	return &UpdateOrchestrator{
+		dockerClient: mockDocker,
+		storage:      store,
+		stackManager: docker.NewStackManager(),
+		stackLocks:   make(map[string]*stackLockEntry),
+	}, store
+}
+
+func TestRebuildContainer(t *testing.T) {
+	ctx := context.Background()
+	orch, store := newRebuildTestOrchestrator(t, "services:\n  app:\n    build: ./app\n    image: myapp:latest\n")
+
+	operationID, err := orch.RebuildContainer(ctx, "app", false)
+	if err != nil {
+		t.Fatalf("RebuildContainer failed: %v", err)
+	}
+
+	// Without a Docker API connection the operation fails while validating
+	deadline := time.Now().Add(2 * time.Second)
+	var op storage.UpdateOperation
+	for time.Now().Before(deadline) {
+		op, _, _ = store.GetUpdateOperation(ctx, operationID)
+		if op.Status == "failed" {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if op.OperationType != "rebuild" || op.StackName != "web" {
+		t.Errorf("Unexpected operation: type %q, stack %q", op.OperationType, op.StackName)
+	}
+	if op.Status != "failed" || op.ErrorMessage != "Docker API not available" {
+		t.Errorf("Expected validation failure, got status %q (%s)", op.Status, op.ErrorMessage)
+	}
+
+	var notFound *NotFoundError
+	if _, err := orch.RebuildContainer(ctx, "missing", false); !errors.As(err, &notFound) {
+		t.Errorf("Expected not found error, got %v", err)
+	}
+}
+
+func TestRebuildContainerRequiresBuild(t *testing.T) {
+	orch, _ := newRebuildTestOrchestrator(t, "services:\n  app:\n    image: myapp:latest\n")
+
+	var badRequest *BadRequestError
+	if _, err := orch.RebuildContainer(context.Background(), "app", false); !errors.As(err, &badRequest) {
+		t.Errorf("Expected bad request error for a service without build, got %v", err)
+	}
+}
+
+func TestRollbackRebuildOperation(t *testing.T) {
+	ctx := context.Background()
+	orch, store := newRebuildTestOrchestrator(t, "services:\n  app:\n    build: ./app\n    image: myapp:latest\n")
+
+	if err := store.SaveUpdateOperation(ctx, storage.UpdateOperation{
+		OperationID:   "rebuild-op",
+		ContainerName: "app",
+		StackName:     "web",
+		OperationType: "rebuild",
+		Status:        "complete",
+		OldVersion:    "0123456789ab",
+		NewVersion:    "ba9876543210",
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	rollbackOpID, err := orch.RollbackOperation(WithTrigger(ctx, "user:alice"), "rebuild-op", false)
+	if err != nil {
+		t.Fatalf("RollbackOperation failed: %v", err)
+	}
+
+	rollbackOp, found, _ := store.GetUpdateOperation(ctx, rollbackOpID)
+	if !found || rollbackOp.OperationType != "rollback" {
+		t.Fatalf("Expected a rollback operation, got %+v", rollbackOp)
+	}
+	if rollbackOp.TriggeredBy != "user:alice" {
+		t.Errorf("Expected the rollback to be triggered by user:alice, got %q", rollbackOp.TriggeredBy)
+	}
+	if rollbackOp.OldVersion != "ba9876543210" || rollbackOp.NewVersion != "0123456789ab" {
+		t.Errorf("Expected rollback from the rebuilt to the previous image, got %s -> %s", rollbackOp.OldVersion, rollbackOp.NewVersion)
+	}
+	if origOp, _, _ := store.GetUpdateOperation(ctx, "rebuild-op"); !origOp.RollbackOccurred {
+		t.Error("Expected the rebuild to be marked as rolled back")
+	}
+}
@@ -0,0 +1,255 @@
+package update
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/chis/docksmith/internal/storage"
+)
+
+// defaultReconcileInterval is how often operation records are reconciled after startup.
+const defaultReconcileInterval = time.Hour
+
+// ReconcileReport summarizes a reconciliation of the operation records with the
+// containers and files they refer to.
+type ReconcileReport struct {
+	RanAt             time.Time `json:"ran_at"`
+	Startup           bool      `json:"startup"`             // The first run after a restart, which also fails interrupted operations
+	Interrupted       []string  `json:"interrupted"`         // Operations that were running when Docksmith stopped
+	Orphaned          []string  `json:"orphaned"`            // Queued or paused operations whose containers or queue entry are gone
+	StaleQueueEntries []string  `json:"stale_queue_entries"` // Queue entries removed, by operation ID
+	ComposeBackups    int64     `json:"compose_backups"`     // Records of deleted compose backup files removed
+	Errors            []string  `json:"errors,omitempty"`
+}
+
+// Changed reports whether the reconciliation changed any record.
+func (r ReconcileReport) Changed() bool {
+	return len(r.Interrupted) > 0 || len(r.Orphaned) > 0 || len(r.StaleQueueEntries) > 0 || r.ComposeBackups > 0
+}
+
+// ReconcileIntervalFromEnv reads how often records are reconciled from
+// RECONCILE_INTERVAL. Returns the 1h default when unset or invalid; "0" only
+// reconciles at startup.
+func ReconcileIntervalFromEnv() time.Duration {
+	value := os.Getenv("RECONCILE_INTERVAL")
+	if value == "" {
+		return defaultReconcileInterval
+	}
+	interval, err := time.ParseDuration(value)
+	if value == "0" {
+		interval, err = 0, nil
+	}
+	if err != nil || interval < 0 {
+		log.Printf("Warning: Invalid RECONCILE_INTERVAL '%s', using default %v", value, defaultReconcileInterval)
+		return defaultReconcileInterval
+	}
+	log.Printf("Using RECONCILE_INTERVAL: %v", interval)
+	return interval
+}
+
+// StartReconciler reconciles the records left by the previous run, then every
+// interval until Shutdown. Zero only reconciles at startup. Only operations
+// recorded before the call are failed as interrupted, so it must be called by the
+// process that runs updates, before it accepts any.
+func (o *UpdateOrchestrator) StartReconciler(interval time.Duration) {
+	if o.storage == nil {
+		return
+	}
+	startedAt := time.Now()
+	go func() {
+		o.reconcile(o.ctx, startedAt)
+		if interval <= 0 {
+			return
+		}
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-o.ctx.Done():
+				return
+			case <-ticker.C:
+				o.reconcile(o.ctx, time.Time{})
+			}
+		}
+	}()
+}
+
+// Reconcile reconciles the operation records now and returns the report.
+func (o *UpdateOrchestrator) Reconcile(ctx context.Context) ReconcileReport {
+	if o.storage == nil {
+		return ReconcileReport{RanAt: time.Now()}
+	}
+	return o.reconcile(ctx, time.Time{})
+}
+
+// LastReconcile returns the report of the last reconciliation, if one ran.
+func (o *UpdateOrchestrator) LastReconcile() (ReconcileReport, bool) {
+	o.reconcileMu.Lock()
+	defer o.reconcileMu.Unlock()
+	if o.lastReconcile == nil {
+		return ReconcileReport{}, false
+	}
+	return *o.lastReconcile, true
+}
+
+// reconcile fails queued and paused operations whose containers no longer exist,
+// removes queue entries that can never start, and removes the records of compose
+// backups whose file was deleted. With a non-zero interruptedBefore, operations
+// recorded before it that are still running are failed as interrupted by a restart.
+// Queue entries and queued operations younger than orphanGrace are left alone,
+// since the queue processor may be moving them.
+func (o *UpdateOrchestrator) reconcile(ctx context.Context, interruptedBefore time.Time) ReconcileReport {
+	o.reconcileMu.Lock()
+	defer o.reconcileMu.Unlock()
+
+	report := ReconcileReport{
+		RanAt:             time.Now(),
+		Startup:           !interruptedBefore.IsZero(),
+		Interrupted:       []string{},
+		Orphaned:          []string{},
+		StaleQueueEntries: []string{},
+	}
+	fail := func(format string, args ...any) {
+		msg := fmt.Sprintf(format, args...)
+		log.Printf("RECONCILE: Warning: %s", msg)
+		report.Errors = append(report.Errors, msg)
+	}
+
+	// Containers that exist, nil when they could not be listed
+	var existing map[string]bool
+	if containers, err := o.dockerClient.ListContainers(ctx); err != nil {
+		fail("failed to list containers: %v", err)
+	} else {
+		existing = make(map[string]bool, len(containers))
+		for _, c := range containers {
+			existing[c.Name] = true
+		}
+	}
+	gone := func(names []string) bool {
+		if existing == nil || len(names) == 0 {
+			return false
+		}
+		return !slices.ContainsFunc(names, func(name string) bool { return existing[name] })
+	}
+
+	queued, queueErr := o.storage.GetQueuedUpdates(ctx)
+	if queueErr != nil {
+		fail("failed to get queued updates: %v", queueErr)
+	}
+	entries := make(map[string]bool, len(queued))
+	for _, q := range queued {
+		entries[q.OperationID] = true
+	}
+
+	ops, opsErr := o.storage.GetUnfinishedOperations(ctx)
+	if opsErr != nil {
+		fail("failed to get unfinished operations: %v", opsErr)
+	}
+	unfinished := make(map[string]bool, len(ops))
+	for _, op := range ops {
+		unfinished[op.OperationID] = true
+
+		var reason string
+		switch op.Status {
+		case storage.StatusQueued:
+			if queueErr != nil || entries[op.OperationID] || report.RanAt.Sub(op.UpdatedAt) < orphanGrace {
+				continue
+			}
+			reason = "Queue entry was lost"
+			report.Orphaned = append(report.Orphaned, op.OperationID)
+		case storage.StatusPaused:
+			if !gone(operationContainers(op)) {
+				continue
+			}
+			reason = "Paused containers no longer exist"
+			report.Orphaned = append(report.Orphaned, op.OperationID)
+		case "pending_restart":
+			continue // Completed by the self-update on startup
+		default:
+			if interruptedBefore.IsZero() || !op.CreatedAt.Before(interruptedBefore) {
+				continue
+			}
+			reason = "Interrupted by a Docksmith restart"
+			report.Interrupted = append(report.Interrupted, op.OperationID)
+		}
+		log.Printf("RECONCILE: Failing operation %s (%s): %s", op.OperationID, op.Status, reason)
+		o.failOperation(ctx, op.OperationID, op.Status, reason)
+	}
+
+	if queueErr == nil && opsErr == nil {
+		for _, q := range queued {
+			if report.RanAt.Sub(q.QueuedAt) < orphanGrace {
+				continue
+			}
+			var reason string
+			switch {
+			case !unfinished[q.OperationID]:
+				reason = "its operation has finished or is not recorded"
+			case gone(q.Containers):
+				reason = "its containers no longer exist"
+			default:
+				continue
+			}
+
+			// The queue processor may have started the operation since it was listed
+			removed, err := o.storage.DeleteQueuedUpdate(ctx, q.OperationID)
+			if err != nil {
+				fail("failed to remove queue entry of operation %s: %v", q.OperationID, err)
+				continue
+			}
+			if !removed {
+				continue
+			}
+			log.Printf("RECONCILE: Removed queue entry of operation %s on stack %s: %s", q.OperationID, q.StackName, reason)
+			report.StaleQueueEntries = append(report.StaleQueueEntries, q.OperationID)
+			if unfinished[q.OperationID] {
+				o.failOperation(ctx, q.OperationID, storage.StatusQueued, "Queued containers no longer exist")
+			}
+			o.publishQueueChanged(QueueActionRemoved, q.StackName, q.OperationID)
+		}
+	}
+
+	backups, err := o.storage.ListComposeBackups(ctx)
+	if err != nil {
+		fail("failed to list compose backups: %v", err)
+	}
+	var missing []int64
+	for _, backup := range backups {
+		if _, err := os.Stat(backup.BackupFilePath); errors.Is(err, fs.ErrNotExist) {
+			missing = append(missing, backup.ID)
+		}
+	}
+	if report.ComposeBackups, err = o.storage.DeleteComposeBackups(ctx, missing); err != nil {
+		fail("failed to remove compose backup records: %v", err)
+	}
+
+	if report.Changed() {
+		log.Printf("RECONCILE: Failed %d interrupted and %d orphaned operations, removed %d stale queue entries and %d compose backup records",
+			len(report.Interrupted), len(report.Orphaned), len(report.StaleQueueEntries), report.ComposeBackups)
+	} else if report.Startup {
+		log.Printf("RECONCILE: No orphaned operations, queue entries, or compose backup records")
+	}
+	o.lastReconcile = &report
+	return report
+}
+
+// operationContainers returns the names of the containers an operation updates.
+func operationContainers(op storage.UpdateOperation) []string {
+	var names []string
+	if op.ContainerName != "" {
+		names = append(names, op.ContainerName)
+	}
+	for _, d := range op.BatchDetails {
+		if d.ContainerName != "" && !slices.Contains(names, d.ContainerName) {
+			names = append(names, d.ContainerName)
+		}
+	}
+	return names
+}
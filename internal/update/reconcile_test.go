@@ -0,0 +1,130 @@
+package update
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/chis/docksmith/internal/docker"
+	"github.com/chis/docksmith/internal/storage"
+)
+
+// backupStore records compose backups on top of the memory storage.
+type backupStore struct {
+	storage.Storage
+	backups []storage.ComposeBackup
+}
+
+func (s *backupStore) ListComposeBackups(ctx context.Context) ([]storage.ComposeBackup, error) {
+	return s.backups, nil
+}
+
+func (s *backupStore) DeleteComposeBackups(ctx context.Context, ids []int64) (int64, error) {
+	var deleted int64
+	for _, id := range ids {
+		for i, backup := range s.backups {
+			if backup.ID == id {
+				s.backups = append(s.backups[:i], s.backups[i+1:]...)
+				deleted++
+				break
+			}
+		}
+	}
+	return deleted, nil
+}
+
+func TestReconcile(t *testing.T) {
+	ctx := context.Background()
+	existingBackup := filepath.Join(t.TempDir(), ".compose.yaml.backup.20250101-000000")
+	require.NoError(t, os.WriteFile(existingBackup, []byte("services: {}"), 0644))
+	store := &backupStore{
+		Storage: storage.NewMemoryStorage(),
+		backups: []storage.ComposeBackup{
+			{ID: 1, OperationID: "op-old", BackupFilePath: existingBackup},
+			{ID: 2, OperationID: "op-old", BackupFilePath: filepath.Join(t.TempDir(), "deleted.backup")},
+		},
+	}
+	o := &UpdateOrchestrator{
+		dockerClient: &MockDockerClient{containers: []docker.Container{{Name: "web"}}},
+		storage:      store,
+	}
+
+	for _, op := range []storage.UpdateOperation{
+		{OperationID: "op-running", ContainerName: "web", Status: storage.StatusPullingImage},
+		{OperationID: "op-paused", ContainerName: "web", Status: storage.StatusPaused},
+		{OperationID: "op-paused-gone", ContainerName: "old", Status: storage.StatusPaused},
+		{OperationID: "op-self", ContainerName: "docksmith", Status: "pending_restart"},
+		{OperationID: "op-queued-gone", StackName: "media", Status: storage.StatusQueued},
+		{OperationID: "op-queued-new", StackName: "media", Status: storage.StatusQueued},
+		{OperationID: "op-done", StackName: "media", Status: storage.StatusComplete},
+	} {
+		require.NoError(t, store.SaveUpdateOperation(ctx, op))
+	}
+	hourAgo := time.Now().Add(-time.Hour)
+	for _, q := range []storage.UpdateQueue{
+		{OperationID: "op-queued-gone", StackName: "media", Containers: []string{"old"}, QueuedAt: hourAgo},
+		{OperationID: "op-done", StackName: "media", Containers: []string{"web"}, QueuedAt: hourAgo},
+		{OperationID: "op-queued-new", StackName: "media", Containers: []string{"old"}, QueuedAt: time.Now()},
+	} {
+		require.NoError(t, store.QueueUpdate(ctx, q))
+	}
+
+	report := o.reconcile(ctx, time.Now())
+	assert.True(t, report.Startup)
+	assert.Equal(t, []string{"op-running"}, report.Interrupted)
+	assert.Equal(t, []string{"op-paused-gone"}, report.Orphaned)
+	assert.ElementsMatch(t, []string{"op-queued-gone", "op-done"}, report.StaleQueueEntries)
+	assert.Equal(t, int64(1), report.ComposeBackups)
+	assert.Empty(t, report.Errors)
+
+	status := func(operationID string) string {
+		op, _, _ := store.GetUpdateOperation(ctx, operationID)
+		return op.Status
+	}
+	assert.Equal(t, storage.StatusFailed, status("op-running"))
+	assert.Equal(t, storage.StatusFailed, status("op-paused-gone"))
+	assert.Equal(t, storage.StatusFailed, status("op-queued-gone"))
+	assert.Equal(t, storage.StatusPaused, status("op-paused"))
+	assert.Equal(t, "pending_restart", status("op-self"))
+	assert.Equal(t, storage.StatusComplete, status("op-done"))
+	// Recently queued operations may still be moving through the queue
+	assert.Equal(t, storage.StatusQueued, status("op-queued-new"))
+	queued, _ := store.GetQueuedUpdates(ctx)
+	require.Len(t, queued, 1)
+	assert.Equal(t, "op-queued-new", queued[0].OperationID)
+	require.Len(t, store.backups, 1)
+	assert.Equal(t, existingBackup, store.backups[0].BackupFilePath)
+
+	last, found := o.LastReconcile()
+	require.True(t, found)
+	assert.Equal(t, report.RanAt, last.RanAt)
+
+	// Periodic runs leave running operations alone
+	require.NoError(t, store.SaveUpdateOperation(ctx, storage.UpdateOperation{OperationID: "op-running-2", ContainerName: "web", Status: storage.StatusHealthCheck}))
+	report = o.Reconcile(ctx)
+	assert.False(t, report.Startup)
+	assert.False(t, report.Changed())
+	assert.Equal(t, storage.StatusHealthCheck, status("op-running-2"))
+}
+
+func TestReconcile_KeepsWaitingOperationsWhenContainersCannotBeListed(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewMemoryStorage()
+	o := &UpdateOrchestrator{
+		dockerClient: &MockDockerClient{listError: assert.AnError},
+		storage:      store,
+	}
+	require.NoError(t, store.SaveUpdateOperation(ctx, storage.UpdateOperation{OperationID: "op-paused", ContainerName: "old", Status: storage.StatusPaused}))
+	require.NoError(t, store.QueueUpdate(ctx, storage.UpdateQueue{OperationID: "op-paused", StackName: "media", Containers: []string{"old"}, QueuedAt: time.Now().Add(-time.Hour)}))
+
+	report := o.Reconcile(ctx)
+	assert.False(t, report.Changed())
+	assert.Len(t, report.Errors, 1)
+	op, _, _ := store.GetUpdateOperation(ctx, "op-paused")
+	assert.Equal(t, storage.StatusPaused, op.Status)
+}
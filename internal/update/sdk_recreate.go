@@ -0,0 +1,286 @@
+package update
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"maps"
+	"slices"
+	"strings"
+
+	dockerContainer "github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/docker/api/types/network"
+	dockerclient "github.com/docker/docker/client"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+
+	"github.com/chis/docksmith/internal/compose"
+	"github.com/chis/docksmith/internal/docker"
+)
+
+// oldContainerSuffix is appended to the name of a standalone container while its
+// replacement is created, so it can be restored if the replacement fails.
+const oldContainerSuffix = "_docksmith_old"
+
+// containerAPI is the part of the Docker SDK client used to recreate standalone
+// containers. Tests replace it.
+type containerAPI interface {
+	ContainerInspect(ctx context.Context, containerID string) (dockerContainer.InspectResponse, error)
+	ImageInspect(ctx context.Context, imageID string, opts ...dockerclient.ImageInspectOption) (image.InspectResponse, error)
+	ContainerStop(ctx context.Context, containerID string, options dockerContainer.StopOptions) error
+	ContainerRename(ctx context.Context, containerID, newContainerName string) error
+	ContainerCreate(ctx context.Context, config *dockerContainer.Config, hostConfig *dockerContainer.HostConfig, networkingConfig *network.NetworkingConfig, platform *ocispec.Platform, containerName string) (dockerContainer.CreateResponse, error)
+	NetworkConnect(ctx context.Context, networkID, containerID string, config *network.EndpointSettings) error
+	ContainerStart(ctx context.Context, containerID string, options dockerContainer.StartOptions) error
+	ContainerRemove(ctx context.Context, containerID string, options dockerContainer.RemoveOptions) error
+}
+
+// restartContainerWithSDK recreates a container started with docker run, which has
+// no compose file, with the Docker API. The new container keeps the old one's
+// configuration; see recreateConfig. newImageRef "" keeps the current image.
+func (o *UpdateOrchestrator) restartContainerWithSDK(ctx context.Context, operationID, containerName, stackName, newImageRef string) ([]string, error) {
+	api := o.containerAPI
+	if api == nil {
+		if o.dockerSDK == nil {
+			return nil, fmt.Errorf("docker client not available")
+		}
+		api = o.dockerSDK
+	}
+
+	o.publishProgress(operationID, containerName, stackName, "recreating", 65, "Recreating with the Docker API")
+	err := o.withUpdateSlot(ctx, func() error {
+		return recreateStandalone(ctx, api, containerName, newImageRef)
+	})
+	if err != nil {
+		return nil, err
+	}
+	o.publishProgress(operationID, containerName, stackName, "recreating", 70, "Container recreated")
+	log.Printf("UPDATE: Successfully recreated %s with the Docker API", containerName)
+	return nil, nil
+}
+
+// recreateStandalone replaces a container with one created from the same
+// configuration and imageRef. The old container is stopped and renamed, not removed,
+// until the new one is running, and is restored if creating or starting it fails.
+func recreateStandalone(ctx context.Context, api containerAPI, containerName, imageRef string) error {
+	inspect, err := api.ContainerInspect(ctx, containerName)
+	if err != nil {
+		return fmt.Errorf("failed to inspect container: %w", err)
+	}
+	if inspect.ContainerJSONBase == nil || inspect.Config == nil || inspect.HostConfig == nil {
+		return fmt.Errorf("incomplete inspect data for container %s", containerName)
+	}
+	if imageRef == "" {
+		imageRef = inspect.Config.Image
+	}
+
+	// The old image's defaults are left out so the new image's apply
+	var imageConfig *image.InspectResponse
+	if img, err := api.ImageInspect(ctx, inspect.Image); err == nil {
+		imageConfig = &img
+	} else {
+		log.Printf("UPDATE: Failed to inspect image of %s, keeping all of its settings: %v", containerName, err)
+	}
+	config, hostConfig, networks := recreateConfig(inspect, imageConfig, imageRef)
+
+	wasRunning := inspect.State != nil && inspect.State.Running
+	stop := compose.StopSettingsFor(&docker.Container{Name: containerName, Labels: inspect.Config.Labels}, "")
+	stopOptions := dockerContainer.StopOptions{Signal: stop.Signal}
+	if stop.Timeout > 0 {
+		seconds := int(stop.Timeout.Seconds())
+		stopOptions.Timeout = &seconds
+	}
+
+	log.Printf("UPDATE: Stopping %s to recreate it with %s", containerName, imageRef)
+	if err := api.ContainerStop(ctx, inspect.ID, stopOptions); err != nil {
+		return fmt.Errorf("failed to stop container: %w", err)
+	}
+	oldName := containerName + oldContainerSuffix
+	if err := api.ContainerRename(ctx, inspect.ID, oldName); err != nil {
+		restoreStandalone(ctx, api, inspect.ID, "", containerName, wasRunning)
+		return fmt.Errorf("failed to rename container: %w", err)
+	}
+
+	created, err := api.ContainerCreate(ctx, config, hostConfig, networks.create, nil, containerName)
+	if err != nil {
+		restoreStandalone(ctx, api, inspect.ID, "", containerName, wasRunning)
+		return fmt.Errorf("failed to create container: %w", err)
+	}
+	for _, name := range slices.Sorted(maps.Keys(networks.connect)) {
+		if err := api.NetworkConnect(ctx, name, created.ID, networks.connect[name]); err != nil {
+			restoreStandalone(ctx, api, inspect.ID, created.ID, containerName, wasRunning)
+			return fmt.Errorf("failed to connect container to network %s: %w", name, err)
+		}
+	}
+	if err := api.ContainerStart(ctx, created.ID, dockerContainer.StartOptions{}); err != nil {
+		restoreStandalone(ctx, api, inspect.ID, created.ID, containerName, wasRunning)
+		return fmt.Errorf("failed to start container: %w", err)
+	}
+
+	started, err := api.ContainerInspect(ctx, created.ID)
+	if err != nil || started.ContainerJSONBase == nil || started.State == nil || !(started.State.Running || started.State.Restarting) {
+		reason := "it is not running"
+		if err != nil {
+			reason = err.Error()
+		} else if started.ContainerJSONBase != nil && started.State != nil && started.State.Error != "" {
+			reason = started.State.Error
+		}
+		restoreStandalone(ctx, api, inspect.ID, created.ID, containerName, wasRunning)
+		return fmt.Errorf("recreated container did not start: %s", reason)
+	}
+
+	if err := api.ContainerRemove(ctx, inspect.ID, dockerContainer.RemoveOptions{}); err != nil {
+		log.Printf("UPDATE: Failed to remove old container %s of %s: %v", oldName, containerName, err)
+	}
+	return nil
+}
+
+// restoreStandalone puts back the old container of a failed recreation, removing
+// the new one (if created) first.
+func restoreStandalone(ctx context.Context, api containerAPI, oldID, newID, containerName string, start bool) {
+	if newID != "" {
+		if err := api.ContainerRemove(ctx, newID, dockerContainer.RemoveOptions{Force: true}); err != nil {
+			log.Printf("UPDATE: Failed to remove failed replacement of %s: %v", containerName, err)
+		}
+	}
+	if err := api.ContainerRename(ctx, oldID, containerName); err != nil {
+		log.Printf("UPDATE: Failed to restore the name of %s: %v", containerName, err)
+	}
+	if !start {
+		return
+	}
+	if err := api.ContainerStart(ctx, oldID, dockerContainer.StartOptions{}); err != nil {
+		log.Printf("UPDATE: Failed to restart old container %s: %v", containerName, err)
+	}
+}
+
+// recreateNetworks are the networks of a recreated container: the one it is
+// created on and the others it is connected to afterwards.
+type recreateNetworks struct {
+	create  *network.NetworkingConfig
+	connect map[string]*network.EndpointSettings
+}
+
+// recreateConfig returns the configuration of a container recreated from inspect
+// with imageRef. Everything set on the container is kept: mounts (including its
+// anonymous volumes), port bindings, networks with their aliases and static
+// addresses, environment, restart policy, capabilities, devices, and resources.
+// Environment variables, labels, command, entrypoint, working directory, user,
+// exposed ports, and volumes that only repeat the old image's defaults (oldImage,
+// nil if unknown) are left out so the new image's defaults apply.
+func recreateConfig(inspect dockerContainer.InspectResponse, oldImage *image.InspectResponse, imageRef string) (*dockerContainer.Config, *dockerContainer.HostConfig, recreateNetworks) {
+	config := *inspect.Config
+	config.Image = imageRef
+	if inspect.ID != "" && strings.HasPrefix(inspect.ID, config.Hostname) && len(config.Hostname) == 12 {
+		config.Hostname = "" // Docker's default hostname is the container ID
+	}
+
+	if oldImage != nil && oldImage.Config != nil {
+		defaults := oldImage.Config
+		config.Env = slices.DeleteFunc(slices.Clone(config.Env), func(env string) bool {
+			return slices.Contains(defaults.Env, env)
+		})
+		config.Labels = maps.Clone(config.Labels)
+		maps.DeleteFunc(config.Labels, func(key, value string) bool {
+			imageValue, ok := defaults.Labels[key]
+			return ok && imageValue == value
+		})
+		if slices.Equal(config.Cmd, defaults.Cmd) {
+			config.Cmd = nil
+		}
+		if slices.Equal(config.Entrypoint, defaults.Entrypoint) {
+			config.Entrypoint = nil
+		}
+		if config.WorkingDir == defaults.WorkingDir {
+			config.WorkingDir = ""
+		}
+		if config.User == defaults.User {
+			config.User = ""
+		}
+		config.ExposedPorts = withoutKeys(config.ExposedPorts, defaults.ExposedPorts)
+		config.Volumes = withoutKeys(config.Volumes, defaults.Volumes)
+	}
+
+	hostConfig := *inspect.HostConfig
+	hostConfig.Binds = append(slices.Clone(hostConfig.Binds), anonymousVolumeBinds(inspect)...)
+
+	return &config, &hostConfig, recreateEndpoints(inspect)
+}
+
+// withoutKeys returns a copy of m without the keys of exclude.
+func withoutKeys[K ~string, V any](m map[K]V, exclude map[string]struct{}) map[K]V {
+	result := maps.Clone(m)
+	maps.DeleteFunc(result, func(key K, _ V) bool {
+		_, ok := exclude[string(key)]
+		return ok
+	})
+	return result
+}
+
+// anonymousVolumeBinds returns binds reattaching the volumes of a container that
+// were not mounted explicitly, such as those created for the image's VOLUME
+// instructions, so the recreated container keeps their data.
+func anonymousVolumeBinds(inspect dockerContainer.InspectResponse) []string {
+	explicit := make(map[string]bool)
+	for _, bind := range inspect.HostConfig.Binds {
+		if parts := strings.Split(bind, ":"); len(parts) >= 2 {
+			explicit[parts[1]] = true
+		}
+	}
+	for _, m := range inspect.HostConfig.Mounts {
+		explicit[m.Target] = true
+	}
+
+	var binds []string
+	for _, m := range inspect.Mounts {
+		if m.Type != mount.TypeVolume || m.Name == "" || explicit[m.Destination] {
+			continue
+		}
+		bind := m.Name + ":" + m.Destination
+		if !m.RW {
+			bind += ":ro"
+		}
+		binds = append(binds, bind)
+	}
+	return binds
+}
+
+// recreateEndpoints returns the network endpoints of a recreated container with the
+// settings that were configured for it; addresses assigned by Docker are left out.
+// Containers sharing the host's or another container's network stack have none.
+func recreateEndpoints(inspect dockerContainer.InspectResponse) recreateNetworks {
+	networks := recreateNetworks{connect: make(map[string]*network.EndpointSettings)}
+	mode := inspect.HostConfig.NetworkMode
+	if mode.IsHost() || mode.IsNone() || mode.IsContainer() || inspect.NetworkSettings == nil {
+		return networks
+	}
+
+	primary := mode.NetworkName()
+	shortID := inspect.ID
+	if len(shortID) > 12 {
+		shortID = shortID[:12]
+	}
+	for name, endpoint := range inspect.NetworkSettings.Networks {
+		if endpoint == nil {
+			continue
+		}
+		settings := &network.EndpointSettings{
+			IPAMConfig: endpoint.IPAMConfig,
+			Links:      endpoint.Links,
+			Aliases: slices.DeleteFunc(slices.Clone(endpoint.Aliases), func(alias string) bool {
+				return alias == shortID
+			}),
+			DriverOpts: endpoint.DriverOpts,
+			GwPriority: endpoint.GwPriority,
+		}
+		if name == primary {
+			networks.create = &network.NetworkingConfig{
+				EndpointsConfig: map[string]*network.EndpointSettings{name: settings},
+			}
+		} else {
+			networks.connect[name] = settings
+		}
+	}
+	return networks
+}
@@ -0,0 +1,247 @@
+package update
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	dockerContainer "github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/docker/api/types/network"
+	dockerclient "github.com/docker/docker/client"
+	"github.com/docker/go-connections/nat"
+	dockerspec "github.com/moby/docker-image-spec/specs-go/v1"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeContainerAPI records the Docker API calls of a standalone recreation.
+type fakeContainerAPI struct {
+	containers map[string]dockerContainer.InspectResponse
+	images     map[string]image.InspectResponse
+	calls      []string
+
+	createdConfig     *dockerContainer.Config
+	createdHostConfig *dockerContainer.HostConfig
+	createdNetworking *network.NetworkingConfig
+	connected         map[string]*network.EndpointSettings
+	startErr          error
+}
+
+func (f *fakeContainerAPI) ContainerInspect(ctx context.Context, containerID string) (dockerContainer.InspectResponse, error) {
+	inspect, ok := f.containers[containerID]
+	if !ok {
+		return dockerContainer.InspectResponse{}, fmt.Errorf("No such container: %s", containerID)
+	}
+	return inspect, nil
+}
+
+func (f *fakeContainerAPI) ImageInspect(ctx context.Context, imageID string, opts ...dockerclient.ImageInspectOption) (image.InspectResponse, error) {
+	img, ok := f.images[imageID]
+	if !ok {
+		return image.InspectResponse{}, fmt.Errorf("No such image: %s", imageID)
+	}
+	return img, nil
+}
+
+func (f *fakeContainerAPI) ContainerStop(ctx context.Context, containerID string, options dockerContainer.StopOptions) error {
+	f.calls = append(f.calls, "stop "+containerID)
+	return nil
+}
+
+func (f *fakeContainerAPI) ContainerRename(ctx context.Context, containerID, newContainerName string) error {
+	f.calls = append(f.calls, "rename "+containerID+" "+newContainerName)
+	return nil
+}
+
+func (f *fakeContainerAPI) ContainerCreate(ctx context.Context, config *dockerContainer.Config, hostConfig *dockerContainer.HostConfig, networkingConfig *network.NetworkingConfig, platform *ocispec.Platform, containerName string) (dockerContainer.CreateResponse, error) {
+	f.calls = append(f.calls, "create "+containerName)
+	f.createdConfig, f.createdHostConfig, f.createdNetworking = config, hostConfig, networkingConfig
+	f.containers["new-id"] = dockerContainer.InspectResponse{
+		ContainerJSONBase: &dockerContainer.ContainerJSONBase{ID: "new-id", State: &dockerContainer.State{Running: f.startErr == nil}},
+		NetworkSettings: &dockerContainer.NetworkSettings{
+			Networks: map[string]*network.EndpointSettings{"frontend": {IPAddress: "127.0.0.1"}},
+		},
+	}
+	return dockerContainer.CreateResponse{ID: "new-id"}, nil
+}
+
+func (f *fakeContainerAPI) NetworkConnect(ctx context.Context, networkID, containerID string, config *network.EndpointSettings) error {
+	f.calls = append(f.calls, "connect "+networkID)
+	if f.connected == nil {
+		f.connected = make(map[string]*network.EndpointSettings)
+	}
+	f.connected[networkID] = config
+	return nil
+}
+
+func (f *fakeContainerAPI) ContainerStart(ctx context.Context, containerID string, options dockerContainer.StartOptions) error {
+	f.calls = append(f.calls, "start "+containerID)
+	if containerID == "new-id" {
+		return f.startErr
+	}
+	return nil
+}
+
+func (f *fakeContainerAPI) ContainerRemove(ctx context.Context, containerID string, options dockerContainer.RemoveOptions) error {
+	f.calls = append(f.calls, "remove "+containerID)
+	return nil
+}
+
+// complexStandalone returns a docker run container with mounts, ports, several
+// networks, capabilities, and settings inherited from its image.
+func complexStandalone() *fakeContainerAPI {
+	const id = "0123456789abcdef0123"
+	inspect := dockerContainer.InspectResponse{
+		ContainerJSONBase: &dockerContainer.ContainerJSONBase{
+			ID:    id,
+			Name:  "/pihole",
+			Image: "sha256:old",
+			State: &dockerContainer.State{Running: true},
+			HostConfig: &dockerContainer.HostConfig{
+				Binds:         []string{"/srv/pihole/etc:/etc/pihole:rw"},
+				Mounts:        []mount.Mount{{Type: mount.TypeTmpfs, Target: "/run"}},
+				NetworkMode:   "frontend",
+				PortBindings:  nat.PortMap{"53/udp": {{HostPort: "53"}}, "80/tcp": {{HostIP: "127.0.0.1", HostPort: "8053"}}},
+				RestartPolicy: dockerContainer.RestartPolicy{Name: dockerContainer.RestartPolicyUnlessStopped},
+				CapAdd:        []string{"NET_ADMIN"},
+				Resources: dockerContainer.Resources{
+					Memory:  512 << 20,
+					Devices: []dockerContainer.DeviceMapping{{PathOnHost: "/dev/net/tun", PathInContainer: "/dev/net/tun", CgroupPermissions: "rwm"}},
+				},
+			},
+		},
+		Config: &dockerContainer.Config{
+			Hostname:     id[:12],
+			Image:        "pihole/pihole:2024.07.0",
+			Env:          []string{"TZ=Europe/Berlin", "PATH=/usr/bin:/bin", "PIHOLE_VERSION=2024.07.0"},
+			Labels:       map[string]string{"docksmith.stop-timeout": "30s", "org.opencontainers.image.version": "2024.07.0"},
+			Cmd:          []string{"start.sh"},
+			ExposedPorts: nat.PortSet{"53/udp": {}, "80/tcp": {}, "9000/tcp": {}},
+			Volumes:      map[string]struct{}{"/etc/dnsmasq.d": {}},
+		},
+		Mounts: []dockerContainer.MountPoint{
+			{Type: mount.TypeBind, Source: "/srv/pihole/etc", Destination: "/etc/pihole", RW: true},
+			{Type: mount.TypeVolume, Name: "3f2a9c", Destination: "/etc/dnsmasq.d", RW: true},
+		},
+		NetworkSettings: &dockerContainer.NetworkSettings{
+			Networks: map[string]*network.EndpointSettings{
+				"frontend": {
+					Aliases:    []string{"dns", id[:12]},
+					IPAMConfig: &network.EndpointIPAMConfig{IPv4Address: "172.20.0.53"},
+					IPAddress:  "172.20.0.53",
+					EndpointID: "ep1",
+				},
+				"monitoring": {Aliases: []string{"pihole-metrics"}, IPAddress: "172.21.0.4"},
+			},
+		},
+	}
+
+	oldImage := image.InspectResponse{Config: &dockerspec.DockerOCIImageConfig{
+		ImageConfig: ocispec.ImageConfig{
+			Env:          []string{"PATH=/usr/bin:/bin", "PIHOLE_VERSION=2024.07.0"},
+			Labels:       map[string]string{"org.opencontainers.image.version": "2024.07.0"},
+			Cmd:          []string{"start.sh"},
+			ExposedPorts: map[string]struct{}{"53/udp": {}, "80/tcp": {}},
+			Volumes:      map[string]struct{}{"/etc/dnsmasq.d": {}},
+		},
+	}}
+
+	return &fakeContainerAPI{
+		containers: map[string]dockerContainer.InspectResponse{"pihole": inspect, id: inspect},
+		images:     map[string]image.InspectResponse{"sha256:old": oldImage},
+	}
+}
+
+func TestRecreateStandalone_PreservesConfig(t *testing.T) {
+	api := complexStandalone()
+
+	require.NoError(t, recreateStandalone(context.Background(), api, "pihole", "pihole/pihole:2024.08.0"))
+
+	assert.Equal(t, []string{
+		"stop 0123456789abcdef0123",
+		"rename 0123456789abcdef0123 pihole_docksmith_old",
+		"create pihole",
+		"connect monitoring",
+		"start new-id",
+		"remove 0123456789abcdef0123",
+	}, api.calls)
+
+	config := api.createdConfig
+	assert.Equal(t, "pihole/pihole:2024.08.0", config.Image)
+	assert.Empty(t, config.Hostname, "Docker's generated hostname is not carried over")
+	assert.Equal(t, []string{"TZ=Europe/Berlin"}, config.Env, "the old image's environment is left to the new image")
+	assert.Equal(t, map[string]string{"docksmith.stop-timeout": "30s"}, config.Labels)
+	assert.Nil(t, config.Cmd)
+	assert.Equal(t, nat.PortSet{"9000/tcp": {}}, config.ExposedPorts)
+	assert.Empty(t, config.Volumes)
+
+	host := api.createdHostConfig
+	assert.Equal(t, []string{"/srv/pihole/etc:/etc/pihole:rw", "3f2a9c:/etc/dnsmasq.d"}, host.Binds, "anonymous volumes are reattached")
+	assert.Equal(t, []mount.Mount{{Type: mount.TypeTmpfs, Target: "/run"}}, host.Mounts)
+	assert.Equal(t, "8053", host.PortBindings["80/tcp"][0].HostPort)
+	assert.Equal(t, dockerContainer.RestartPolicyUnlessStopped, host.RestartPolicy.Name)
+	assert.Equal(t, []string{"NET_ADMIN"}, []string(host.CapAdd))
+	assert.Equal(t, int64(512<<20), host.Memory)
+	assert.Len(t, host.Devices, 1)
+
+	require.NotNil(t, api.createdNetworking)
+	frontend := api.createdNetworking.EndpointsConfig["frontend"]
+	require.NotNil(t, frontend)
+	assert.Equal(t, []string{"dns"}, frontend.Aliases)
+	assert.Equal(t, "172.20.0.53", frontend.IPAMConfig.IPv4Address)
+	assert.Empty(t, frontend.EndpointID)
+	assert.Equal(t, []string{"pihole-metrics"}, api.connected["monitoring"].Aliases)
+}
+
+func TestRecreateStandalone_RestoresOnFailure(t *testing.T) {
+	api := complexStandalone()
+	api.startErr = errors.New("port is already allocated")
+
+	err := recreateStandalone(context.Background(), api, "pihole", "pihole/pihole:2024.08.0")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "port is already allocated")
+
+	assert.Equal(t, []string{
+		"stop 0123456789abcdef0123",
+		"rename 0123456789abcdef0123 pihole_docksmith_old",
+		"create pihole",
+		"connect monitoring",
+		"start new-id",
+		"remove new-id",
+		"rename 0123456789abcdef0123 pihole",
+		"start 0123456789abcdef0123",
+	}, api.calls)
+}
+
+func TestRecreateConfig_SharedNetworkStack(t *testing.T) {
+	inspect := dockerContainer.InspectResponse{
+		ContainerJSONBase: &dockerContainer.ContainerJSONBase{
+			ID:         "abc",
+			HostConfig: &dockerContainer.HostConfig{NetworkMode: "container:vpn"},
+		},
+		Config: &dockerContainer.Config{Image: "qbittorrent:4.6", Env: []string{"PUID=1000"}},
+		NetworkSettings: &dockerContainer.NetworkSettings{
+			Networks: map[string]*network.EndpointSettings{},
+		},
+	}
+
+	config, host, networks := recreateConfig(inspect, nil, "qbittorrent:5.0")
+	assert.Equal(t, "qbittorrent:5.0", config.Image)
+	assert.Equal(t, []string{"PUID=1000"}, config.Env, "everything is kept when the old image is unknown")
+	assert.Equal(t, dockerContainer.NetworkMode("container:vpn"), host.NetworkMode)
+	assert.Nil(t, networks.create)
+	assert.Empty(t, networks.connect)
+}
+
+func TestRestartContainerWithSDK_UsesContainerAPI(t *testing.T) {
+	api := complexStandalone()
+	orch := &UpdateOrchestrator{containerAPI: api}
+
+	_, err := orch.restartContainerWithSDK(context.Background(), "op-1", "pihole", "", "")
+	require.NoError(t, err)
+	assert.Equal(t, "pihole/pihole:2024.07.0", api.createdConfig.Image, "an empty image reference keeps the current image")
+}
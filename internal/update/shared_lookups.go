@@ -0,0 +1,155 @@
+package update
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"sync/atomic"
+
+	"github.com/chis/docksmith/internal/registry"
+)
+
+// sharedLookups shares registry lookups between the containers of one check run
+// that run the same image reference, so ten containers on nginx:1.25 cost one
+// lookup even when checked concurrently. Across runs, lookups are served by the
+// registry cache.
+type sharedLookups struct {
+	mu     sync.Mutex
+	images map[string]*imageLookups
+	shared atomic.Int64
+}
+
+// imageLookups holds the lookups of one image reference, in flight or done.
+type imageLookups struct {
+	run   *sharedLookups
+	mu    sync.Mutex
+	calls map[string]*sharedCall
+}
+
+type sharedCall struct {
+	done  chan struct{}
+	value any
+	err   error
+}
+
+type sharedLookupsKey struct{}
+type imageLookupsKey struct{}
+
+// withSharedLookups starts sharing registry lookups for the check run of ctx.
+func withSharedLookups(ctx context.Context) (context.Context, *sharedLookups) {
+	run := &sharedLookups{images: make(map[string]*imageLookups)}
+	return context.WithValue(ctx, sharedLookupsKey{}, run), run
+}
+
+// withImageLookups returns a context sharing lookups with the other containers of
+// the check run running image. Lookups are shared per image reference, not per
+// repository, since a tag listing may stop early depending on the running tag.
+func withImageLookups(ctx context.Context, image string) context.Context {
+	run, ok := ctx.Value(sharedLookupsKey{}).(*sharedLookups)
+	if !ok {
+		return ctx
+	}
+	run.mu.Lock()
+	lookups, ok := run.images[image]
+	if !ok {
+		lookups = &imageLookups{run: run, calls: make(map[string]*sharedCall)}
+		run.images[image] = lookups
+	}
+	run.mu.Unlock()
+	return context.WithValue(ctx, imageLookupsKey{}, lookups)
+}
+
+// logSharedLookups logs how many registry lookups a check run saved by sharing them.
+func logSharedLookups(lookups *sharedLookups) {
+	if shared := lookups.shared.Load(); shared > 0 {
+		log.Printf("Shared %d registry lookups between containers running the same image", shared)
+	}
+}
+
+// shareLookup returns the result of the lookup named key, calling fetch only if no
+// other container of the check run running the same image did. Failed lookups are
+// shared too, so a failing registry is not asked again within the run.
+func shareLookup[T any](ctx context.Context, key string, fetch func() (T, error)) (T, error) {
+	lookups, ok := ctx.Value(imageLookupsKey{}).(*imageLookups)
+	if !ok {
+		return fetch()
+	}
+
+	lookups.mu.Lock()
+	if call, ok := lookups.calls[key]; ok {
+		lookups.mu.Unlock()
+		select {
+		case <-call.done:
+		case <-ctx.Done():
+			var zero T
+			return zero, ctx.Err()
+		}
+		lookups.run.shared.Add(1)
+		value, _ := call.value.(T)
+		return value, call.err
+	}
+	call := &sharedCall{done: make(chan struct{})}
+	lookups.calls[key] = call
+	lookups.mu.Unlock()
+
+	value, err := fetch()
+	call.value, call.err = value, err
+	close(call.done)
+	return value, err
+}
+
+// registry returns the registry client of the check, sharing lookups with the other
+// containers of the check run when ctx is part of one.
+func (c *Checker) registry(ctx context.Context) RegistryClient {
+	if _, ok := ctx.Value(imageLookupsKey{}).(*imageLookups); !ok {
+		return c.registryManager
+	}
+	return sharedRegistry{c.registryManager}
+}
+
+// sharedRegistry shares the lookups of a check through the context of each call.
+type sharedRegistry struct {
+	RegistryClient
+}
+
+// ListTags shares complete tag lists and those that may stop early separately.
+func (r sharedRegistry) ListTags(ctx context.Context, imageRef string) ([]string, error) {
+	key := "tags:" + imageRef
+	if registry.HasTagListStop(ctx) {
+		key = "partialtags:" + imageRef
+	}
+	return shareLookup(ctx, key, func() ([]string, error) {
+		return r.RegistryClient.ListTags(ctx, imageRef)
+	})
+}
+
+func (r sharedRegistry) GetTagDigest(ctx context.Context, imageRef, tag string) (string, error) {
+	return shareLookup(ctx, fmt.Sprintf("digest:%s:%s", imageRef, tag), func() (string, error) {
+		return r.RegistryClient.GetTagDigest(ctx, imageRef, tag)
+	})
+}
+
+func (r sharedRegistry) ListTagsWithDigests(ctx context.Context, imageRef string) (map[string][]string, error) {
+	return shareLookup(ctx, "tagdigests:"+imageRef, func() (map[string][]string, error) {
+		return r.RegistryClient.ListTagsWithDigests(ctx, imageRef)
+	})
+}
+
+func (r sharedRegistry) GetImageSize(ctx context.Context, imageRef, reference string) (int64, error) {
+	return shareLookup(ctx, fmt.Sprintf("size:%s:%s", imageRef, reference), func() (int64, error) {
+		return r.RegistryClient.GetImageSize(ctx, imageRef, reference)
+	})
+}
+
+func (r sharedRegistry) GetImagePlatforms(ctx context.Context, imageRef, reference string) ([]string, error) {
+	return shareLookup(ctx, fmt.Sprintf("platforms:%s:%s", imageRef, reference), func() ([]string, error) {
+		return r.RegistryClient.GetImagePlatforms(ctx, imageRef, reference)
+	})
+}
+
+func (r sharedRegistry) GetPlatformDigest(ctx context.Context, imageRef, reference string) (string, error) {
+	return shareLookup(ctx, fmt.Sprintf("platformdigest:%s:%s", imageRef, reference), func() (string, error) {
+		return r.RegistryClient.GetPlatformDigest(ctx, imageRef, reference)
+	})
+}
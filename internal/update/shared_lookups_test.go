@@ -0,0 +1,104 @@
+package update
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/chis/docksmith/internal/docker"
+)
+
+// countingRegistry counts the tag listings and digest lookups reaching the registry.
+type countingRegistry struct {
+	*mockRegistryClient
+	listTags   atomic.Int32
+	tagDigests atomic.Int32
+}
+
+func (r *countingRegistry) ListTags(ctx context.Context, imageRef string) ([]string, error) {
+	r.listTags.Add(1)
+	return r.mockRegistryClient.ListTags(ctx, imageRef)
+}
+
+func (r *countingRegistry) GetTagDigest(ctx context.Context, imageRef, tag string) (string, error) {
+	r.tagDigests.Add(1)
+	return r.mockRegistryClient.GetTagDigest(ctx, imageRef, tag)
+}
+
+func TestCheckForUpdates_SharesLookupsPerImage(t *testing.T) {
+	mockDocker := &mockDockerClient{
+		containers: []docker.Container{
+			{ID: "1", Name: "web-1", Image: "docker.io/library/nginx:1.24.0"},
+			{ID: "2", Name: "web-2", Image: "docker.io/library/nginx:1.24.0"},
+			{ID: "3", Name: "web-3", Image: "docker.io/library/nginx:1.24.0"},
+			{ID: "4", Name: "proxy", Image: "docker.io/library/nginx:1.25.0"},
+		},
+		imageDigests:  map[string]string{},
+		imageVersions: map[string]string{},
+		localImages:   map[string]bool{},
+	}
+	reg := &countingRegistry{mockRegistryClient: &mockRegistryClient{
+		tags:           map[string][]string{"docker.io/library/nginx": {"1.25.0", "1.24.0"}},
+		tagDigests:     map[string]string{"docker.io/library/nginx:1.25.0": "sha256:new"},
+		digestMappings: map[string]map[string][]string{},
+	}}
+	checker := NewChecker(mockDocker, reg, nil)
+
+	result, err := checker.CheckForUpdates(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 3, result.UpdatesFound)
+	assert.Equal(t, int32(2), reg.listTags.Load(), "one listing per image reference")
+
+	// Without a check run, every container looks up on its own
+	reg.listTags.Store(0)
+	for _, container := range mockDocker.containers {
+		checker.checkContainer(context.Background(), container)
+	}
+	assert.Equal(t, int32(4), reg.listTags.Load())
+}
+
+func TestShareLookup_Concurrent(t *testing.T) {
+	ctx, _ := withSharedLookups(context.Background())
+	nginx := withImageLookups(ctx, "nginx:1.25")
+
+	var calls atomic.Int32
+	started, release := make(chan struct{}), make(chan struct{})
+	fetch := func() (string, error) {
+		if calls.Add(1) == 1 {
+			close(started)
+		}
+		<-release
+		return "sha256:abc", nil
+	}
+
+	var wg sync.WaitGroup
+	results := make([]string, 10)
+	for i := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i], _ = shareLookup(nginx, "digest:nginx:1.25", fetch)
+		}()
+	}
+	<-started
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), calls.Load())
+	for _, result := range results {
+		assert.Equal(t, "sha256:abc", result)
+	}
+
+	// Other images look up on their own, and failures are shared within the run
+	failing := func() (string, error) { calls.Add(1); return "", errors.New("unauthorized") }
+	alpine := withImageLookups(ctx, "alpine:3.20")
+	_, err := shareLookup(alpine, "digest:nginx:1.25", failing)
+	assert.Error(t, err)
+	_, err = shareLookup(withImageLookups(ctx, "alpine:3.20"), "digest:nginx:1.25", failing)
+	assert.Error(t, err)
+	assert.Equal(t, int32(2), calls.Load())
+}
@@ -0,0 +1,199 @@
+package update
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/chis/docksmith/internal/docker"
+	"github.com/chis/docksmith/internal/scripts"
+	"github.com/chis/docksmith/internal/signature"
+	"github.com/chis/docksmith/internal/storage"
+)
+
+// SignatureConfig configures verification of image signatures before updates.
+type SignatureConfig struct {
+	Policy signature.Policy
+	// Options select key or keyless verification. The docksmith.signature-key
+	// label replaces PublicKey per container.
+	Options signature.Options
+}
+
+// SetSignatureVerification sets the global signature policy and how signatures are
+// verified. The docksmith.signature-policy label overrides the policy per container.
+func (o *UpdateOrchestrator) SetSignatureVerification(cfg SignatureConfig) {
+	o.signaturePolicy = cfg.Policy
+	o.signatureVerifier, o.signatureVerifierErr = signature.NewVerifier(cfg.Options)
+	if o.signatureVerifierErr != nil {
+		log.Printf("Warning: Invalid signature verification settings, signatures cannot be verified: %v", o.signatureVerifierErr)
+	}
+}
+
+// SignatureConfigFromEnv reads the signature settings from SIGNATURE_POLICY
+// (off, warn, or block), SIGNATURE_PUBLIC_KEY (PEM file), and for keyless
+// verification SIGNATURE_ROOTS (PEM file), SIGNATURE_IDENTITY, and SIGNATURE_ISSUER.
+func SignatureConfigFromEnv() SignatureConfig {
+	var cfg SignatureConfig
+	value := os.Getenv("SIGNATURE_POLICY")
+	policy, ok := signature.ParsePolicy(value)
+	if !ok {
+		log.Printf("Warning: Invalid SIGNATURE_POLICY '%s', not verifying signatures", value)
+	} else if value != "" {
+		log.Printf("Using SIGNATURE_POLICY: %s", policy)
+	}
+	cfg.Policy = policy
+
+	if path := os.Getenv("SIGNATURE_PUBLIC_KEY"); path != "" {
+		key, err := os.ReadFile(path)
+		if err != nil {
+			log.Printf("Warning: Failed to read SIGNATURE_PUBLIC_KEY '%s': %v", path, err)
+		} else {
+			log.Printf("Using SIGNATURE_PUBLIC_KEY: %s", path)
+			cfg.Options.PublicKey = key
+		}
+	}
+	if path := os.Getenv("SIGNATURE_ROOTS"); path != "" {
+		roots, err := os.ReadFile(path)
+		if err != nil {
+			log.Printf("Warning: Failed to read SIGNATURE_ROOTS '%s': %v", path, err)
+		} else {
+			cfg.Options.Roots = roots
+		}
+	}
+	cfg.Options.Identity = os.Getenv("SIGNATURE_IDENTITY")
+	cfg.Options.Issuer = os.Getenv("SIGNATURE_ISSUER")
+	return cfg
+}
+
+// signaturePolicyFor returns the signature policy of a container. The
+// docksmith.signature-policy label overrides the global policy.
+func (o *UpdateOrchestrator) signaturePolicyFor(cont *docker.Container) signature.Policy {
+	global := o.signaturePolicy
+	if global == "" {
+		global = signature.PolicyOff
+	}
+	value := cont.Labels[scripts.SignaturePolicyLabel]
+	if value == "" {
+		return global
+	}
+	policy, ok := signature.ParsePolicy(value)
+	if !ok {
+		log.Printf("PREFLIGHT: Invalid %s %q on %s, using %s", scripts.SignaturePolicyLabel, value, cont.Name, global)
+		return global
+	}
+	return policy
+}
+
+// signatureVerifierFor returns the verifier for a container's images: one for the
+// public key in its docksmith.signature-key label, or the global verifier.
+func (o *UpdateOrchestrator) signatureVerifierFor(cont *docker.Container) (*signature.Verifier, error) {
+	path := strings.TrimSpace(cont.Labels[scripts.SignatureKeyLabel])
+	if path == "" {
+		if o.signatureVerifier == nil && o.signatureVerifierErr == nil {
+			return nil, signature.ErrNoTrust
+		}
+		return o.signatureVerifier, o.signatureVerifierErr
+	}
+
+	key, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read public key %s: %w", path, err)
+	}
+	return signature.NewVerifier(signature.Options{PublicKey: key})
+}
+
+// checkSignatures is the pre-flight stage that verifies the cosign signatures of the
+// target images of containers whose signature policy is warn or block. Results are
+// stored on the operation. A failure under the block policy fails the update;
+// under warn it is only logged and recorded.
+func (o *UpdateOrchestrator) checkSignatures(ctx context.Context, operationID string, containers []*docker.Container, targetVersions map[string]string) error {
+	if o.checker == nil || o.checker.registryManager == nil {
+		return nil
+	}
+
+	var results []storage.SignatureVerification
+	var blocked []string
+	for _, c := range containers {
+		target := targetVersions[c.Name]
+		policy := o.signaturePolicyFor(c)
+		if target == "" || policy == signature.PolicyOff {
+			continue
+		}
+
+		imgInfo := o.checker.extractor.ExtractFromImage(c.Image)
+		imageRef := imgInfo.Registry + "/" + imgInfo.Repository
+		result := storage.SignatureVerification{
+			ContainerName: c.Name,
+			Image:         imageRef + ":" + target,
+			Policy:        string(policy),
+			CheckedAt:     time.Now(),
+		}
+		if strings.HasPrefix(target, "sha256:") {
+			result.Image = imageRef + "@" + target
+		}
+
+		if err := o.verifyImageSignature(ctx, c, imageRef, target, &result); err != nil {
+			result.Error = err.Error()
+			if policy == signature.PolicyBlock {
+				log.Printf("PREFLIGHT: Blocking update of %s, signature of %s not verified: %v", c.Name, result.Image, err)
+				blocked = append(blocked, fmt.Sprintf("%s: %v", result.Image, err))
+			} else {
+				log.Printf("PREFLIGHT: Warning: Signature of %s not verified, updating %s anyway: %v", result.Image, c.Name, err)
+			}
+		} else {
+			result.Verified = true
+			log.Printf("PREFLIGHT: Verified signature of %s (%s)", result.Image, result.Mode)
+		}
+		results = append(results, result)
+	}
+
+	if len(results) > 0 {
+		o.batchDetailMu.Lock()
+		if op, found, err := o.storage.GetUpdateOperation(ctx, operationID); err == nil && found {
+			op.SignatureVerifications = results
+			if err := o.storage.SaveUpdateOperation(ctx, op); err != nil {
+				log.Printf("PREFLIGHT: Failed to save signature verifications of operation %s: %v", operationID, err)
+			}
+		}
+		o.batchDetailMu.Unlock()
+	}
+
+	if len(blocked) > 0 {
+		return fmt.Errorf("signature verification failed: %s", strings.Join(blocked, "; "))
+	}
+	return nil
+}
+
+// verifyImageSignature verifies the signature of the image at target (tag or
+// digest), filling in the digest, mode, signer, and attestations of result.
+func (o *UpdateOrchestrator) verifyImageSignature(ctx context.Context, cont *docker.Container, imageRef, target string, result *storage.SignatureVerification) error {
+	verifier, err := o.signatureVerifierFor(cont)
+	if err != nil {
+		return err
+	}
+	result.Mode = verifier.Mode()
+
+	digest := target
+	if !strings.HasPrefix(digest, "sha256:") {
+		if digest, err = o.checker.registryManager.GetTagDigest(ctx, imageRef, target); err != nil {
+			return fmt.Errorf("failed to resolve digest: %w", err)
+		}
+	}
+	result.Digest = digest
+
+	sigs, err := o.checker.registryManager.GetSignatures(ctx, imageRef, digest)
+	if err != nil {
+		return fmt.Errorf("failed to get signatures: %w", err)
+	}
+	result.Attestations = sigs.Attestations
+
+	signer, err := verifier.Verify(digest, sigs.Signatures)
+	if err != nil {
+		return err
+	}
+	result.Signer = signer
+	return nil
+}
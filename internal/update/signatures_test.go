@@ -0,0 +1,113 @@
+package update
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"testing"
+
+	"github.com/chis/docksmith/internal/docker"
+	"github.com/chis/docksmith/internal/registry"
+	"github.com/chis/docksmith/internal/scripts"
+	"github.com/chis/docksmith/internal/signature"
+	"github.com/chis/docksmith/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newSignatureTestOrchestrator returns an orchestrator whose registry has 1.27.0
+// signed by the returned key and 1.26.0 unsigned, with a saved operation "op".
+func newSignatureTestOrchestrator(t *testing.T) (*UpdateOrchestrator, *ecdsa.PrivateKey) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	digest := "sha256:1270"
+	payload := []byte(fmt.Sprintf(`{"critical":{"image":{"docker-manifest-digest":%q},"type":"cosign container image signature"}}`, digest))
+	hash := sha256.Sum256(payload)
+	raw, err := ecdsa.SignASN1(rand.Reader, key, hash[:])
+	require.NoError(t, err)
+
+	checker := newArchTestChecker(nil)
+	mockRegistry := checker.registryManager.(*mockRegistryClient)
+	mockRegistry.tagDigests = map[string]string{
+		"docker.io/library/nginx:1.27.0": digest,
+		"docker.io/library/nginx:1.26.0": "sha256:1260",
+	}
+	mockRegistry.signatures = map[string]*registry.ImageSignatures{
+		"docker.io/library/nginx@" + digest: {
+			Signatures:   []registry.Signature{{Payload: payload, Signature: base64.StdEncoding.EncodeToString(raw)}},
+			Attestations: []string{"application/vnd.in-toto+json"},
+		},
+	}
+
+	store := storage.NewMemoryStorage()
+	require.NoError(t, store.SaveUpdateOperation(context.Background(), storage.UpdateOperation{OperationID: "op", ContainerName: "test", Status: "validating"}))
+	return &UpdateOrchestrator{checker: checker, storage: store}, key
+}
+
+func publicKeyPEM(t *testing.T, key *ecdsa.PrivateKey) []byte {
+	t.Helper()
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	require.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+}
+
+func TestCheckSignatures(t *testing.T) {
+	ctx := context.Background()
+	o, key := newSignatureTestOrchestrator(t)
+	o.SetSignatureVerification(SignatureConfig{
+		Policy:  signature.PolicyBlock,
+		Options: signature.Options{PublicKey: publicKeyPEM(t, key)},
+	})
+	containers := []*docker.Container{{Name: "test", Image: "docker.io/library/nginx:1.24.0"}}
+
+	require.NoError(t, o.checkSignatures(ctx, "op", containers, map[string]string{"test": "1.27.0"}))
+	op, _, _ := o.storage.GetUpdateOperation(ctx, "op")
+	require.Len(t, op.SignatureVerifications, 1)
+	result := op.SignatureVerifications[0]
+	assert.True(t, result.Verified)
+	assert.Equal(t, "docker.io/library/nginx:1.27.0", result.Image)
+	assert.Equal(t, "sha256:1270", result.Digest)
+	assert.Equal(t, signature.ModeKey, result.Mode)
+	assert.Equal(t, []string{"application/vnd.in-toto+json"}, result.Attestations)
+
+	// Unsigned images are blocked, and the failure is recorded
+	err := o.checkSignatures(ctx, "op", containers, map[string]string{"test": "1.26.0"})
+	assert.EqualError(t, err, "signature verification failed: docker.io/library/nginx:1.26.0: image is not signed")
+	op, _, _ = o.storage.GetUpdateOperation(ctx, "op")
+	require.Len(t, op.SignatureVerifications, 1)
+	assert.False(t, op.SignatureVerifications[0].Verified)
+	assert.Equal(t, "image is not signed", op.SignatureVerifications[0].Error)
+
+	// The label downgrades the policy to warn
+	containers[0].Labels = map[string]string{scripts.SignaturePolicyLabel: "warn"}
+	assert.NoError(t, o.checkSignatures(ctx, "op", containers, map[string]string{"test": "1.26.0"}))
+	op, _, _ = o.storage.GetUpdateOperation(ctx, "op")
+	assert.Equal(t, "warn", op.SignatureVerifications[0].Policy)
+
+	// Or turns verification off
+	containers[0].Labels = map[string]string{scripts.SignaturePolicyLabel: "off"}
+	assert.NoError(t, o.checkSignatures(ctx, "op", containers, map[string]string{"test": "1.26.0"}))
+}
+
+func TestCheckSignatures_PolicyOff(t *testing.T) {
+	o, _ := newSignatureTestOrchestrator(t)
+	containers := []*docker.Container{{Name: "test", Image: "docker.io/library/nginx:1.24.0"}}
+
+	// Without a policy nothing is checked or recorded
+	assert.NoError(t, o.checkSignatures(context.Background(), "op", containers, map[string]string{"test": "1.26.0"}))
+	op, _, _ := o.storage.GetUpdateOperation(context.Background(), "op")
+	assert.Empty(t, op.SignatureVerifications)
+
+	// A block label without a configured key or roots cannot verify anything
+	containers[0].Labels = map[string]string{scripts.SignaturePolicyLabel: "block"}
+	err := o.checkSignatures(context.Background(), "op", containers, map[string]string{"test": "1.27.0"})
+	assert.EqualError(t, err, "signature verification failed: docker.io/library/nginx:1.27.0: no public key or trusted roots configured")
+}
@@ -0,0 +1,440 @@
+package update
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"maps"
+	"net"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	dockerContainer "github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/docker/api/types/network"
+	"github.com/google/uuid"
+
+	"github.com/chis/docksmith/internal/docker"
+	"github.com/chis/docksmith/internal/scripts"
+	"github.com/chis/docksmith/internal/selfupdate"
+	"github.com/chis/docksmith/internal/storage"
+)
+
+const (
+	// SimulationLabel marks the temporary clones started by update simulations.
+	// Its value is the operation ID of the simulation.
+	SimulationLabel = "docksmith.simulation"
+
+	// simulationNameSuffix is put between a container's name and the operation ID
+	// to name its clone.
+	simulationNameSuffix = "_docksmith_sim_"
+
+	// simulationPollInterval is how often the clone's state is checked.
+	simulationPollInterval = time.Second
+)
+
+// simulationSettings are the health check timeouts of a simulation.
+type simulationSettings struct {
+	operationID string
+	timeout     time.Duration // How long the clone may take to become healthy
+	settle      time.Duration // How long a clone without a health check must keep running
+}
+
+// SimulateUpdate starts a "simulate" operation that tries targetVersion on a
+// temporary clone of a container and reports whether the clone becomes healthy,
+// without touching the container itself. An empty targetVersion is only allowed
+// for :latest images.
+func (o *UpdateOrchestrator) SimulateUpdate(ctx context.Context, containerName, targetVersion string) (string, error) {
+	if err := o.checkWritable(); err != nil {
+		return "", err
+	}
+	operationID := uuid.New().String()
+
+	containers, err := o.dockerClient.ListContainers(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to list containers: %w", err)
+	}
+	var target *docker.Container
+	for _, c := range containers {
+		if c.Name == containerName || c.ID == containerName {
+			target = &c
+			break
+		}
+	}
+	if target == nil {
+		return "", NewNotFoundError("container not found: %s", containerName)
+	}
+	if selfupdate.IsSelfContainer(target.ID, target.Image, target.Name) {
+		return "", NewBadRequestError("cannot simulate updates of the docksmith container")
+	}
+	if target.Labels[SimulationLabel] != "" {
+		return "", NewBadRequestError("container %s is a simulation clone", containerName)
+	}
+
+	_, currentVersion := splitImageRef(target.Image)
+	if targetVersion == "" {
+		if currentVersion != "latest" {
+			return "", NewBadRequestError("cannot simulate an update of %s: no target version specified and current version is '%s' (not :latest)", containerName, currentVersion)
+		}
+		targetVersion = "latest"
+	}
+
+	stackName := o.stackManager.DetermineStack(ctx, *target)
+	op := storage.UpdateOperation{
+		OperationID:   operationID,
+		TriggeredBy:   TriggerFromContext(ctx),
+		ContainerID:   target.ID,
+		ContainerName: target.Name,
+		StackName:     stackName,
+		OperationType: "simulate",
+		Status:        "validating",
+		OldVersion:    currentVersion,
+		NewVersion:    targetVersion,
+		CreatedAt:     time.Now(),
+	}
+	if err := o.storage.SaveUpdateOperation(ctx, op); err != nil {
+		return "", fmt.Errorf("failed to save operation: %w", err)
+	}
+
+	// The container itself is not changed, so the stack is not locked
+	go o.executeSimulation(context.Background(), operationID, target, targetVersion, stackName)
+
+	return operationID, nil
+}
+
+// executeSimulation pulls the target image and runs the simulation. The report of
+// the clone's checks is saved as the operation's check output.
+func (o *UpdateOrchestrator) executeSimulation(ctx context.Context, operationID string, container *docker.Container, targetVersion, stackName string) {
+	ctx = o.withOperationLog(ctx, operationID)
+	log.Printf("SIMULATE: Starting simulation of %s for operation=%s container=%s", targetVersion, operationID, container.Name)
+
+	now := time.Now()
+	if op, found, _ := o.storage.GetUpdateOperation(ctx, operationID); found {
+		op.StartedAt = &now
+		o.storage.SaveUpdateOperation(ctx, op)
+	}
+
+	api := o.containerAPI
+	if api == nil {
+		if o.dockerSDK == nil {
+			o.failOperation(ctx, operationID, "validating", "Docker API not available")
+			return
+		}
+		api = o.dockerSDK
+	}
+
+	imageRef := o.buildImageRef(container.Image, targetVersion)
+	o.publishProgress(operationID, container.Name, stackName, "pulling_image", 10, fmt.Sprintf("Pulling %s", imageRef))
+	progressChan := make(chan PullProgress, 10)
+	go func() {
+		for progress := range progressChan {
+			percent := 10 + (progress.Percent * 30 / 100)
+			o.publishProgress(operationID, container.Name, stackName, "pulling_image", percent, progress.Status)
+		}
+	}()
+	err := o.pullImage(ctx, imageRef, progressChan)
+	close(progressChan)
+	if err != nil {
+		o.failOperation(ctx, operationID, "pulling_image", fmt.Sprintf("Image pull failed: %v", err))
+		return
+	}
+
+	o.publishProgress(operationID, container.Name, stackName, "health_check", 50, "Starting a clone on the new image")
+	settings := simulationSettings{
+		operationID: operationID,
+		timeout:     o.healthCheckCfg.Timeout,
+		settle:      o.healthCheckCfg.FallbackWait,
+	}
+	report, err := simulateClone(ctx, api, container.Name, imageRef, settings)
+
+	if op, found, _ := o.storage.GetUpdateOperation(ctx, operationID); found {
+		op.CheckOutput = strings.Join(report, "\n")
+		o.storage.SaveUpdateOperation(ctx, op)
+	}
+	if err != nil {
+		log.Printf("SIMULATE: Simulation of %s for %s failed: %v", imageRef, container.Name, err)
+		o.failOperation(ctx, operationID, "health_check", fmt.Sprintf("Simulation failed: %v", err))
+		return
+	}
+
+	completedNow := time.Now()
+	if op, found, _ := o.storage.GetUpdateOperation(ctx, operationID); found {
+		op.Status = "complete"
+		op.CompletedAt = &completedNow
+		o.storage.SaveUpdateOperation(ctx, op)
+	}
+	log.Printf("SIMULATE: Simulation of %s for %s passed", imageRef, container.Name)
+	o.publishProgress(operationID, container.Name, stackName, "complete", 100, fmt.Sprintf("%s passed its checks on a clone", imageRef))
+}
+
+// simulateClone starts a clone of a container on imageRef, waits for it to become
+// healthy, runs the container's docksmith.healthcheck.* probe against it, and
+// removes it. It returns a line per step for the report.
+func simulateClone(ctx context.Context, api containerAPI, containerName, imageRef string, settings simulationSettings) (report []string, err error) {
+	step := func(format string, args ...any) {
+		line := fmt.Sprintf(format, args...)
+		report = append(report, line)
+		logStep(ctx, containerName, logSourceHealth, "%s", line)
+	}
+
+	inspect, err := api.ContainerInspect(ctx, containerName)
+	if err != nil {
+		return report, fmt.Errorf("failed to inspect container: %w", err)
+	}
+	if inspect.ContainerJSONBase == nil || inspect.Config == nil || inspect.HostConfig == nil {
+		return report, fmt.Errorf("incomplete inspect data for container %s", containerName)
+	}
+	if inspect.HostConfig.NetworkMode.IsHost() {
+		return report, fmt.Errorf("containers on the host network cannot be cloned without their ports conflicting")
+	}
+	probe, err := ParseHealthProbe(inspect.Config.Labels)
+	if err != nil {
+		return report, err
+	}
+
+	var imageConfig *image.InspectResponse
+	if img, err := api.ImageInspect(ctx, inspect.Image); err == nil {
+		imageConfig = &img
+	}
+	config, hostConfig, networks := simulationConfig(inspect, imageConfig, imageRef, settings.operationID)
+
+	cloneName := containerName + simulationNameSuffix + shortOperationID(settings.operationID)
+	created, err := api.ContainerCreate(ctx, config, hostConfig, networks.create, nil, cloneName)
+	if err != nil {
+		return report, fmt.Errorf("failed to create clone: %w", err)
+	}
+	step("Created clone %s from %s", cloneName, imageRef)
+	defer func() {
+		// Remove the clone even if the operation was cancelled
+		removeCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		if removeErr := api.ContainerRemove(removeCtx, created.ID, dockerContainer.RemoveOptions{Force: true, RemoveVolumes: true}); removeErr != nil {
+			log.Printf("SIMULATE: Failed to remove clone %s: %v", cloneName, removeErr)
+			step("Failed to remove clone %s: %v", cloneName, removeErr)
+			return
+		}
+		step("Removed clone %s", cloneName)
+	}()
+
+	for _, name := range slices.Sorted(maps.Keys(networks.connect)) {
+		if err := api.NetworkConnect(ctx, name, created.ID, networks.connect[name]); err != nil {
+			return report, fmt.Errorf("failed to connect clone to network %s: %w", name, err)
+		}
+	}
+	started := time.Now()
+	if err := api.ContainerStart(ctx, created.ID, dockerContainer.StartOptions{}); err != nil {
+		step("Clone failed to start: %v", err)
+		return report, fmt.Errorf("failed to start clone: %w", err)
+	}
+
+	clone, err := waitForClone(ctx, api, created.ID, settings)
+	if err != nil {
+		step("Clone did not become healthy: %v", err)
+		return report, err
+	}
+	if clone.State.Health != nil {
+		step("Clone became healthy after %s", time.Since(started).Round(time.Second))
+	} else {
+		step("Clone has no health check and kept running for %s", settings.settle)
+	}
+
+	if probe == nil {
+		return report, nil
+	}
+	host := containerIP(clone)
+	probe = simulationProbe(probe, inspect, host)
+	if err := probe.Run(ctx, host); err != nil {
+		step("Health probe %s failed: %v", probe, err)
+		return report, err
+	}
+	step("Health probe %s passed", probe)
+	return report, nil
+}
+
+// waitForClone waits for a started clone to become healthy or, without a health
+// check, to keep running for settings.settle. It fails as soon as the clone exits
+// or restarts.
+func waitForClone(ctx context.Context, api containerAPI, id string, settings simulationSettings) (dockerContainer.InspectResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, settings.timeout)
+	defer cancel()
+
+	var runningSince time.Time
+	for {
+		inspect, err := api.ContainerInspect(ctx, id)
+		if err != nil {
+			return inspect, fmt.Errorf("failed to inspect clone: %w", err)
+		}
+		state := inspect.State
+		switch {
+		case state == nil:
+			return inspect, fmt.Errorf("clone has no state")
+		case state.Restarting || !state.Running:
+			return inspect, fmt.Errorf("clone exited with code %d%s", state.ExitCode, stateError(state))
+		case state.Health != nil && state.Health.Status == dockerContainer.Healthy:
+			return inspect, nil
+		case state.Health != nil && state.Health.Status == dockerContainer.Unhealthy:
+			return inspect, fmt.Errorf("clone is unhealthy")
+		case state.Health == nil:
+			if runningSince.IsZero() {
+				runningSince = time.Now()
+			}
+			if time.Since(runningSince) >= settings.settle {
+				return inspect, nil
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return inspect, fmt.Errorf("clone did not become healthy within %v", settings.timeout)
+		case <-time.After(simulationPollInterval):
+		}
+	}
+}
+
+// stateError formats the error Docker recorded for a stopped container
+func stateError(state *dockerContainer.State) string {
+	if state.Error == "" {
+		return ""
+	}
+	return ": " + state.Error
+}
+
+// simulationConfig returns the configuration of a clone of a container on imageRef.
+// It is the configuration of a recreation (see recreateConfig) that stays out of
+// the production container's way: no published ports, no restart policy, mounts
+// read-only (except tmpfs), no compose labels, and no network aliases or static
+// addresses. A clone of a container sharing another container's network stack is
+// put on the default bridge network instead.
+func simulationConfig(inspect dockerContainer.InspectResponse, oldImage *image.InspectResponse, imageRef, operationID string) (*dockerContainer.Config, *dockerContainer.HostConfig, recreateNetworks) {
+	config, hostConfig, networks := recreateConfig(inspect, oldImage, imageRef)
+
+	config.Hostname = ""
+	config.Labels = maps.Clone(config.Labels)
+	maps.DeleteFunc(config.Labels, func(key, _ string) bool {
+		return strings.HasPrefix(key, "com.docker.compose.")
+	})
+	if config.Labels == nil {
+		config.Labels = make(map[string]string)
+	}
+	config.Labels[SimulationLabel] = operationID
+	config.Labels[scripts.IgnoreLabel] = "true"
+
+	hostConfig.PortBindings = nil
+	hostConfig.PublishAllPorts = false
+	hostConfig.RestartPolicy = dockerContainer.RestartPolicy{Name: dockerContainer.RestartPolicyDisabled}
+	hostConfig.AutoRemove = false
+	hostConfig.Binds = slices.Clone(hostConfig.Binds)
+	for i, bind := range hostConfig.Binds {
+		hostConfig.Binds[i] = readOnlyBind(bind)
+	}
+	hostConfig.Mounts = slices.Clone(hostConfig.Mounts)
+	for i := range hostConfig.Mounts {
+		if hostConfig.Mounts[i].Type != mount.TypeTmpfs {
+			hostConfig.Mounts[i].ReadOnly = true
+		}
+	}
+	if hostConfig.NetworkMode.IsContainer() {
+		hostConfig.NetworkMode = network.NetworkBridge
+	}
+
+	strip := func(endpoint *network.EndpointSettings) *network.EndpointSettings {
+		clone := *endpoint
+		clone.Aliases = nil
+		clone.IPAMConfig = nil
+		clone.Links = nil
+		return &clone
+	}
+	if networks.create != nil {
+		for name, endpoint := range networks.create.EndpointsConfig {
+			networks.create.EndpointsConfig[name] = strip(endpoint)
+		}
+	}
+	for name, endpoint := range networks.connect {
+		networks.connect[name] = strip(endpoint)
+	}
+	return config, hostConfig, networks
+}
+
+// readOnlyBind returns a bind mount (source:target[:options]) with the ro option
+func readOnlyBind(bind string) string {
+	parts := strings.Split(bind, ":")
+	if len(parts) < 2 {
+		return bind
+	}
+	if len(parts) == 2 {
+		return bind + ":ro"
+	}
+	options := slices.DeleteFunc(strings.Split(parts[2], ","), func(option string) bool {
+		return option == "rw" || option == "ro"
+	})
+	parts[2] = strings.Join(append(options, "ro"), ",")
+	return strings.Join(parts[:3], ":")
+}
+
+// simulationProbe returns the probe of a container aimed at its clone at host.
+// HTTP URLs and host:port TCP addresses are pointed at the clone; a published
+// port of the container is translated back to its container port.
+func simulationProbe(probe *HealthProbe, inspect dockerContainer.InspectResponse, host string) *HealthProbe {
+	clone := *probe
+	if host == "" {
+		return &clone
+	}
+	if probe.HTTPURL != "" {
+		if u, err := url.Parse(probe.HTTPURL); err == nil {
+			port := u.Port()
+			if port == "" {
+				port = "80"
+				if u.Scheme == "https" {
+					port = "443"
+				}
+			}
+			u.Host = net.JoinHostPort(host, containerPort(inspect, port))
+			clone.HTTPURL = u.String()
+		}
+		return &clone
+	}
+	if _, port, err := net.SplitHostPort(probe.TCPAddress); err == nil {
+		clone.TCPAddress = net.JoinHostPort(host, containerPort(inspect, port))
+	}
+	return &clone
+}
+
+// containerPort returns the container port a host port is published from, or
+// port itself if it is not a published port.
+func containerPort(inspect dockerContainer.InspectResponse, port string) string {
+	if inspect.HostConfig == nil {
+		return port
+	}
+	for containerPort, bindings := range inspect.HostConfig.PortBindings {
+		for _, binding := range bindings {
+			if binding.HostPort == port {
+				return containerPort.Port()
+			}
+		}
+	}
+	return port
+}
+
+// containerIP returns the first IP address of a container
+func containerIP(inspect dockerContainer.InspectResponse) string {
+	if inspect.NetworkSettings == nil {
+		return ""
+	}
+	for _, name := range slices.Sorted(maps.Keys(inspect.NetworkSettings.Networks)) {
+		if endpoint := inspect.NetworkSettings.Networks[name]; endpoint != nil && endpoint.IPAddress != "" {
+			return endpoint.IPAddress
+		}
+	}
+	return ""
+}
+
+// shortOperationID returns the first 8 characters of an operation ID
+func shortOperationID(operationID string) string {
+	if len(operationID) > 8 {
+		return operationID[:8]
+	}
+	return operationID
+}
@@ -0,0 +1,111 @@
+package update
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	dockerContainer "github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/go-connections/nat"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/chis/docksmith/internal/scripts"
+)
+
+func TestSimulateClone_LeavesContainerAlone(t *testing.T) {
+	api := complexStandalone()
+	settings := simulationSettings{operationID: "0f1e2d3c-4b5a", timeout: time.Second}
+
+	report, err := simulateClone(context.Background(), api, "pihole", "pihole/pihole:2024.08.0", settings)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"create pihole_docksmith_sim_0f1e2d3c",
+		"connect monitoring",
+		"start new-id",
+		"remove new-id",
+	}, api.calls, "the production container is never stopped or renamed")
+	assert.Equal(t, []string{
+		"Created clone pihole_docksmith_sim_0f1e2d3c from pihole/pihole:2024.08.0",
+		"Clone has no health check and kept running for 0s",
+		"Removed clone pihole_docksmith_sim_0f1e2d3c",
+	}, report)
+
+	config := api.createdConfig
+	assert.Equal(t, "pihole/pihole:2024.08.0", config.Image)
+	assert.Equal(t, "0f1e2d3c-4b5a", config.Labels[SimulationLabel])
+	assert.Equal(t, "true", config.Labels[scripts.IgnoreLabel])
+
+	host := api.createdHostConfig
+	assert.Empty(t, host.PortBindings)
+	assert.Equal(t, dockerContainer.RestartPolicyDisabled, host.RestartPolicy.Name)
+	assert.Equal(t, []string{"/srv/pihole/etc:/etc/pihole:ro", "3f2a9c:/etc/dnsmasq.d:ro"}, host.Binds)
+	assert.Equal(t, []mount.Mount{{Type: mount.TypeTmpfs, Target: "/run"}}, host.Mounts, "tmpfs mounts stay writable")
+
+	frontend := api.createdNetworking.EndpointsConfig["frontend"]
+	assert.Empty(t, frontend.Aliases, "the clone must not answer for the container's aliases")
+	assert.Nil(t, frontend.IPAMConfig, "the container keeps its static address")
+	assert.Empty(t, api.connected["monitoring"].Aliases)
+}
+
+func TestSimulateClone_ProbesClone(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+	_, port, _ := net.SplitHostPort(listener.Addr().String())
+
+	api := complexStandalone()
+	inspect := api.containers["pihole"]
+	inspect.Config.Labels = map[string]string{scripts.HealthcheckTCPLabel: "nas.local:" + port, scripts.HealthcheckRetriesLabel: "0"}
+	api.containers["pihole"] = inspect
+
+	report, err := simulateClone(context.Background(), api, "pihole", "pihole/pihole:2024.08.0", simulationSettings{operationID: "op", timeout: time.Second})
+	require.NoError(t, err)
+	assert.Contains(t, report, "Health probe TCP 127.0.0.1:"+port+" passed")
+	assert.Equal(t, "remove new-id", api.calls[len(api.calls)-1])
+}
+
+func TestSimulateClone_RemovesFailedClone(t *testing.T) {
+	api := complexStandalone()
+	api.startErr = assert.AnError
+
+	report, err := simulateClone(context.Background(), api, "pihole", "pihole/pihole:2024.08.0", simulationSettings{operationID: "op", timeout: time.Second})
+	require.Error(t, err)
+	assert.Equal(t, "remove new-id", api.calls[len(api.calls)-1])
+	assert.Contains(t, report, "Removed clone pihole_docksmith_sim_op")
+}
+
+func TestSimulateClone_RejectsHostNetwork(t *testing.T) {
+	api := complexStandalone()
+	api.containers["pihole"].HostConfig.NetworkMode = "host"
+
+	_, err := simulateClone(context.Background(), api, "pihole", "pihole/pihole:2024.08.0", simulationSettings{operationID: "op", timeout: time.Second})
+	require.Error(t, err)
+	assert.Empty(t, api.calls)
+}
+
+func TestSimulationProbe(t *testing.T) {
+	inspect := dockerContainer.InspectResponse{
+		ContainerJSONBase: &dockerContainer.ContainerJSONBase{
+			HostConfig: &dockerContainer.HostConfig{PortBindings: nat.PortMap{"80/tcp": {{HostPort: "8053"}}}},
+		},
+	}
+
+	probe := simulationProbe(&HealthProbe{HTTPURL: "http://localhost:8053/admin/"}, inspect, "172.20.0.9")
+	assert.Equal(t, "http://172.20.0.9:80/admin/", probe.HTTPURL, "published ports are translated to the container port")
+
+	probe = simulationProbe(&HealthProbe{HTTPURL: "https://vaultwarden/alive"}, inspect, "172.20.0.9")
+	assert.Equal(t, "https://172.20.0.9:443/alive", probe.HTTPURL)
+
+	probe = simulationProbe(&HealthProbe{TCPAddress: "5432"}, inspect, "172.20.0.9")
+	assert.Equal(t, "5432", probe.TCPAddress, "port-only probes already target the probed host")
+}
+
+func TestReadOnlyBind(t *testing.T) {
+	assert.Equal(t, "/srv/data:/data:ro", readOnlyBind("/srv/data:/data"))
+	assert.Equal(t, "/srv/data:/data:z,ro", readOnlyBind("/srv/data:/data:rw,z"))
+	assert.Equal(t, "/data", readOnlyBind("/data"))
+}
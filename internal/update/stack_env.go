@@ -0,0 +1,74 @@
+package update
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+
+	"github.com/chis/docksmith/internal/compose"
+	"github.com/chis/docksmith/internal/docker"
+	"github.com/chis/docksmith/internal/storage"
+)
+
+// envNamePattern matches the names compose interpolates.
+var envNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// WithStackEnv returns a context whose docker compose commands run with the
+// environment settings of their container's stack, for stacks whose compose files
+// interpolate variables from an env file or shell that docksmith does not have.
+func WithStackEnv(ctx context.Context, store storage.Storage) context.Context {
+	if store == nil {
+		return ctx
+	}
+	return compose.WithEnv(ctx, func(ctx context.Context, container *docker.Container) ([]string, error) {
+		stack := container.Labels["com.docker.compose.project"]
+		if stack == "" {
+			return nil, nil
+		}
+		env, found, err := store.GetStackEnv(ctx, stack)
+		if err != nil || !found {
+			return nil, err
+		}
+		return StackEnviron(env)
+	})
+}
+
+// StackEnviron returns a stack's environment settings as KEY=value entries: the
+// variables of its env file, overridden by its own variables. A configured env
+// file that cannot be read is an error, so compose does not run without it.
+func StackEnviron(env storage.StackEnv) ([]string, error) {
+	vars := make(map[string]string)
+	if env.EnvFile != "" {
+		fileVars, err := compose.LoadEnvFile(env.EnvFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read env file of stack %s: %w", env.Stack, err)
+		}
+		vars = fileVars
+	}
+	maps.Copy(vars, env.Env)
+
+	environ := make([]string, 0, len(vars))
+	for _, key := range slices.Sorted(maps.Keys(vars)) {
+		environ = append(environ, key+"="+vars[key])
+	}
+	return environ, nil
+}
+
+// ValidateStackEnv checks that a stack's variable names are valid and that its
+// env file can be read.
+func ValidateStackEnv(env storage.StackEnv) error {
+	if env.Stack == "" {
+		return NewBadRequestError("stack is required")
+	}
+	for name := range env.Env {
+		if !envNamePattern.MatchString(name) {
+			return NewBadRequestError("invalid variable name %q", name)
+		}
+	}
+	if _, err := StackEnviron(env); err != nil {
+		return NewBadRequestError("%v", err)
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package update
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/chis/docksmith/internal/storage"
+)
+
+func TestStackEnviron(t *testing.T) {
+	envFile := filepath.Join(t.TempDir(), ".env")
+	require.NoError(t, os.WriteFile(envFile, []byte("TZ=UTC\nPUID=1000\n"), 0o644))
+
+	environ, err := StackEnviron(storage.StackEnv{
+		Stack:   "media",
+		EnvFile: envFile,
+		Env:     map[string]string{"PUID": "1001", "MEDIA_ROOT": "/mnt/media"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"MEDIA_ROOT=/mnt/media", "PUID=1001", "TZ=UTC"}, environ)
+
+	_, err = StackEnviron(storage.StackEnv{Stack: "media", EnvFile: filepath.Join(t.TempDir(), "missing.env")})
+	assert.ErrorContains(t, err, "failed to read env file of stack media")
+}
+
+func TestValidateStackEnv(t *testing.T) {
+	assert.NoError(t, ValidateStackEnv(storage.StackEnv{Stack: "media", Env: map[string]string{"_TZ": "UTC"}}))
+
+	for _, env := range []storage.StackEnv{
+		{Env: map[string]string{"TZ": "UTC"}},
+		{Stack: "media", Env: map[string]string{"1TZ": "UTC"}},
+		{Stack: "media", Env: map[string]string{"TZ=": "UTC"}},
+		{Stack: "media", EnvFile: filepath.Join(t.TempDir(), "missing.env")},
+	} {
+		err := ValidateStackEnv(env)
+		var badRequest *BadRequestError
+		assert.ErrorAs(t, err, &badRequest, "%+v", env)
+	}
+}
@@ -0,0 +1,95 @@
+package update
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/chis/docksmith/internal/docker"
+	"github.com/chis/docksmith/internal/scripts"
+)
+
+// SetLevelDelay sets how long batch updates wait after a dependency level is
+// healthy before updating the next level. Zero disables staggering unless a
+// container sets docksmith.update-delay.
+func (o *UpdateOrchestrator) SetLevelDelay(delay time.Duration) {
+	o.healthCheckCfg.LevelDelay = delay
+}
+
+// LevelDelayFromEnv reads the batch update level delay from STACK_LEVEL_DELAY.
+// Returns 0 when unset or invalid.
+func LevelDelayFromEnv() time.Duration {
+	value := os.Getenv("STACK_LEVEL_DELAY")
+	if value == "" {
+		return 0
+	}
+	delay, err := time.ParseDuration(value)
+	if err != nil || delay < 0 {
+		log.Printf("Warning: Invalid STACK_LEVEL_DELAY '%s', updating dependency levels without delay", value)
+		return 0
+	}
+	log.Printf("Using STACK_LEVEL_DELAY: %v", delay)
+	return delay
+}
+
+// levelDelayFor returns the delay to wait after a container is updated before
+// moving to the next dependency level. The docksmith.update-delay label
+// overrides the configured level delay.
+func (o *UpdateOrchestrator) levelDelayFor(cont *docker.Container) time.Duration {
+	value := strings.TrimSpace(cont.Labels[scripts.UpdateDelayLabel])
+	if value == "" {
+		return o.healthCheckCfg.LevelDelay
+	}
+	delay, err := time.ParseDuration(value)
+	if err != nil || delay < 0 {
+		log.Printf("BATCH UPDATE: Invalid %s %q on %s, using %v", scripts.UpdateDelayLabel, value, cont.Name, o.healthCheckCfg.LevelDelay)
+		return o.healthCheckCfg.LevelDelay
+	}
+	return delay
+}
+
+// isStaggered reports whether a batch update should gate and delay between
+// dependency levels: either a level delay is configured or a container in the
+// batch sets docksmith.update-delay.
+func (o *UpdateOrchestrator) isStaggered(containers []*docker.Container) bool {
+	if o.healthCheckCfg.LevelDelay > 0 {
+		return true
+	}
+	for _, c := range containers {
+		if c.Labels[scripts.UpdateDelayLabel] != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// orderByLevel stably sorts containers by dependency level and returns each
+// container's level. Containers already in dependency order keep their relative order.
+func (o *UpdateOrchestrator) orderByLevel(containers []*docker.Container) map[string]int {
+	levelOf := make(map[string]int, len(containers))
+	for i, level := range o.computeStackRestartLevels(containers) {
+		for _, name := range level {
+			levelOf[name] = i
+		}
+	}
+	sort.SliceStable(containers, func(i, j int) bool {
+		return levelOf[containers[i].Name] < levelOf[containers[j].Name]
+	})
+	return levelOf
+}
+
+// waitForNextLevel pauses a staggered batch update between dependency levels.
+func (o *UpdateOrchestrator) waitForNextLevel(ctx context.Context, operationID, stackName string, delay time.Duration, progress int) {
+	log.Printf("BATCH UPDATE: Waiting %v before updating the next dependency level (operation=%s)", delay, operationID)
+	o.publishProgress(operationID, "", stackName, "waiting", progress,
+		fmt.Sprintf("Waiting %v before updating the next dependency level", delay))
+
+	select {
+	case <-ctx.Done():
+	case <-time.After(delay):
+	}
+}
@@ -0,0 +1,71 @@
+package update
+
+import (
+	"testing"
+	"time"
+
+	"github.com/chis/docksmith/internal/docker"
+	"github.com/chis/docksmith/internal/graph"
+	"github.com/chis/docksmith/internal/scripts"
+	"github.com/stretchr/testify/assert"
+)
+
+func stackContainer(name, dependsOn string, extra map[string]string) *docker.Container {
+	labels := map[string]string{graph.ServiceLabel: name}
+	if dependsOn != "" {
+		labels[graph.DependsOnLabel] = dependsOn
+	}
+	for k, v := range extra {
+		labels[k] = v
+	}
+	return &docker.Container{Name: name, Labels: labels}
+}
+
+func TestOrderByLevel(t *testing.T) {
+	orch := &UpdateOrchestrator{}
+	containers := []*docker.Container{
+		stackContainer("web", "api", nil),
+		stackContainer("db", "", nil),
+		stackContainer("api", "db", nil),
+		stackContainer("cache", "", nil),
+	}
+
+	levelOf := orch.orderByLevel(containers)
+
+	assert.Equal(t, 0, levelOf["db"])
+	assert.Equal(t, 0, levelOf["cache"])
+	assert.Equal(t, 1, levelOf["api"])
+	assert.Equal(t, 2, levelOf["web"])
+
+	var names []string
+	for _, c := range containers {
+		names = append(names, c.Name)
+	}
+	assert.Equal(t, []string{"db", "cache", "api", "web"}, names, "sort keeps relative order within a level")
+}
+
+func TestLevelDelay(t *testing.T) {
+	orch := &UpdateOrchestrator{}
+	db := stackContainer("db", "", map[string]string{scripts.UpdateDelayLabel: "30s"})
+	api := stackContainer("api", "db", nil)
+	bad := stackContainer("bad", "", map[string]string{scripts.UpdateDelayLabel: "soon"})
+
+	assert.False(t, orch.isStaggered([]*docker.Container{api}))
+	assert.True(t, orch.isStaggered([]*docker.Container{db, api}), "a delay label enables staggering")
+	assert.Equal(t, 30*time.Second, orch.levelDelayFor(db))
+	assert.Equal(t, time.Duration(0), orch.levelDelayFor(api))
+
+	orch.SetLevelDelay(10 * time.Second)
+	assert.True(t, orch.isStaggered([]*docker.Container{api}))
+	assert.Equal(t, 10*time.Second, orch.levelDelayFor(api))
+	assert.Equal(t, 30*time.Second, orch.levelDelayFor(db), "label overrides the configured delay")
+	assert.Equal(t, 10*time.Second, orch.levelDelayFor(bad), "invalid label falls back to the configured delay")
+}
+
+func TestLevelDelayFromEnv(t *testing.T) {
+	t.Setenv("STACK_LEVEL_DELAY", "45s")
+	assert.Equal(t, 45*time.Second, LevelDelayFromEnv())
+
+	t.Setenv("STACK_LEVEL_DELAY", "later")
+	assert.Equal(t, time.Duration(0), LevelDelayFromEnv())
+}
@@ -0,0 +1,59 @@
+package update
+
+import (
+	"testing"
+
+	"github.com/chis/docksmith/internal/version"
+)
+
+func TestFindLatestVersionLinuxServerPattern(t *testing.T) {
+	parser := version.NewParserWithPattern(version.LinuxServerPattern{})
+
+	tests := []struct {
+		name           string
+		currentTag     string
+		availableTags  []string
+		expectedLatest string
+	}{
+		{
+			name:           "newer build of the same version",
+			currentTag:     "1.32.3-ls123",
+			availableTags:  []string{"1.32.3-ls123", "1.32.3-ls124", "version-1.32.3"},
+			expectedLatest: "1.32.3-ls124",
+		},
+		{
+			name:           "newer version wins over newer build",
+			currentTag:     "1.32.3-ls123",
+			availableTags:  []string{"1.32.3-ls130", "1.32.4-ls125", "version-1.32.4"},
+			expectedLatest: "1.32.4-ls125",
+		},
+		{
+			name:           "version- alias stays on aliases",
+			currentTag:     "version-1.32.3",
+			availableTags:  []string{"1.32.4-ls125", "version-1.32.3", "version-1.32.4"},
+			expectedLatest: "version-1.32.4",
+		},
+		{
+			name:           "alpine package revision is not a variant",
+			currentTag:     "5.9.0-r0-ls108",
+			availableTags:  []string{"5.9.0-r0-ls108", "5.9.1-r1-ls110", "5.9.1-alpine"},
+			expectedLatest: "5.9.1-r1-ls110",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			currentVer := parser.ParseTag(tt.currentTag)
+			if currentVer == nil {
+				t.Fatalf("Failed to parse current tag: %s", tt.currentTag)
+			}
+
+			checker := &Checker{versionParser: version.NewParser()}
+			result := checker.findLatestVersion(parser, tt.availableTags, "", currentVer, nil, tt.currentTag)
+
+			if result != tt.expectedLatest {
+				t.Errorf("Expected latest '%s', got '%s'", tt.expectedLatest, result)
+			}
+		})
+	}
+}
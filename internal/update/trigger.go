@@ -0,0 +1,24 @@
+package update
+
+import "context"
+
+// Triggers recorded on operations started by docksmith itself rather than a caller.
+const (
+	TriggerAutoRollback = "auto-rollback" // Rollback of a failed update, see autoRollback
+	TriggerCrashLoop    = "crash-loop"    // Rollback of a container that restarted too often, see handleCrashLoop
+)
+
+type triggerKey struct{}
+
+// WithTrigger returns a context that records who or what starts the operations
+// created with it, such as "user:alice", "api_key:ci", or "schedule:media". The
+// trigger is stored on the operation for audits.
+func WithTrigger(ctx context.Context, trigger string) context.Context {
+	return context.WithValue(ctx, triggerKey{}, trigger)
+}
+
+// TriggerFromContext returns the trigger recorded by WithTrigger, or "" when none was.
+func TriggerFromContext(ctx context.Context) string {
+	trigger, _ := ctx.Value(triggerKey{}).(string)
+	return trigger
+}
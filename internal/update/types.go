@@ -8,6 +8,7 @@ type UpdateStatus string
 const (
 	UpdateAvailable        UpdateStatus = "UPDATE_AVAILABLE"
 	UpdateAvailableBlocked UpdateStatus = "UPDATE_AVAILABLE_BLOCKED" // Update available but blocked by pre-update check
+	UpdateUnavailableArch  UpdateStatus = "UPDATE_UNAVAILABLE_ARCH"  // Newer version has no image for the host architecture
 	UpToDate               UpdateStatus = "UP_TO_DATE"
 	UpToDatePinnable       UpdateStatus = "UP_TO_DATE_PINNABLE" // Up to date but using :latest, should migrate to semver
 	LocalImage             UpdateStatus = "LOCAL_IMAGE"
@@ -30,6 +31,7 @@ type ContainerUpdate struct {
 	CurrentDigest         string              `json:"current_digest,omitempty"`          // SHA256 digest of current image
 	LatestDigest          string              `json:"latest_digest,omitempty"`           // SHA256 digest of latest (for non-versioned fallback)
 	AvailableTags      []string            `json:"available_tags,omitempty"`
+	Variants           []string            `json:"variants,omitempty"` // Tags of the current version with another variant suffix (e.g., "1.25" for "1.25-alpine")
 	ChangeType         version.ChangeType  `json:"change_type"`
 	Status             UpdateStatus        `json:"status"`
 	Error              string              `json:"error,omitempty"`
@@ -44,6 +46,14 @@ type ContainerUpdate struct {
 	EnvControlled      bool                `json:"env_controlled,omitempty"`        // True if image is controlled by .env variable
 	EnvVarName         string              `json:"env_var_name,omitempty"`          // Name of the controlling env var (e.g., "OPENCLAW_IMAGE")
 	Note               string              `json:"note,omitempty"`                  // Informational note (e.g., ghost tag warning)
+	CurrentSize        int64               `json:"current_size,omitempty"`          // Compressed size of the current image in bytes
+	LatestSize         int64               `json:"latest_size,omitempty"`           // Compressed size of the update candidate in bytes
+	SizeDelta          int64               `json:"size_delta,omitempty"`            // LatestSize - CurrentSize (set only when both are known)
+	Deferred           bool                `json:"deferred,omitempty"`              // Check postponed because the registry rate limit is nearly exhausted
+	BaseImage          string              `json:"base_image,omitempty"`            // Base image checked in place of a locally built image (docksmith.base-image)
+	TimedOut           bool                `json:"timed_out,omitempty"`             // Check failed because it took longer than its timeout (docksmith.check-timeout)
+	EndOfLife          *EndOfLife          `json:"end_of_life,omitempty"`           // Lifecycle of the current version's release cycle (docksmith.eol)
+	Conditions         []Condition         `json:"conditions,omitempty"`            // Typed aspects of the result, derived from Status and the fields above
 }
 
 // CheckResult contains the results of checking for updates.
@@ -20,12 +20,13 @@ import (
 	"github.com/chis/docksmith/internal/graph"
 	"github.com/chis/docksmith/internal/scripts"
 	"github.com/chis/docksmith/internal/selfupdate"
+	"github.com/chis/docksmith/internal/signature"
 	"github.com/chis/docksmith/internal/storage"
 	dockerContainer "github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/api/types/image"
+	dockerregistry "github.com/docker/docker/api/types/registry"
 	dockerclient "github.com/docker/docker/client"
 	"github.com/google/uuid"
-	"gopkg.in/yaml.v3"
 )
 
 // splitImageRef splits a Docker image reference into repository and tag.
@@ -66,22 +67,58 @@ type UpdateOrchestrator struct {
 	healthCheckCfg HealthCheckConfig
 	stackLocks     map[string]*stackLockEntry
 	locksMu        sync.Mutex
+	lockTimeout    time.Duration // Held locks older than this expire, 0 = never
 	batchDetailMu  sync.Mutex // protects read-modify-write on BatchDetails
+	pauseMu        sync.Mutex
+	pausable       map[string]bool // running operations that can still pause → pause requested
+	targetsMu      sync.Mutex
+	targets        map[string]map[string]context.CancelCauseFunc // container name → operation ID → cancels the operation if the container is removed
+	updateSlots    chan struct{}   // global limit on concurrent pulls and recreations, nil = unlimited
 	pathTranslator *docker.PathTranslator
 	ctx            context.Context    // orchestrator lifecycle context
 	cancelFn       context.CancelFunc // cancels ctx on shutdown
+
+	signaturePolicy      signature.Policy    // Global policy for unverified image signatures
+	signatureVerifier    *signature.Verifier // nil until SetSignatureVerification
+	signatureVerifierErr error               // Invalid signature settings
+
+	loadDeferral      LoadDeferralConfig
+	loadRetryInterval time.Duration                                                           // 0 = defaultLoadRetryInterval
+	sampleUsage       func(context.Context, *docker.Container) (*docker.ResourceUsage, error) // nil = Docker stats
+	countConnections  func(context.Context, *docker.Container) (int, error)                   // nil = socket tables
+
+	observation     ObservationConfig
+	observeInterval time.Duration                                      // 0 = defaultObserveInterval
+	inspectRestarts func(context.Context, string) (string, int, error) // nil = Docker inspect
+
+	containerAPI containerAPI // Recreates standalone containers, nil = dockerSDK
+
+	volumeBackup     VolumeBackupConfig
+	runVolumeCommand func(ctx context.Context, stdin io.Reader, stdout io.Writer, name string, args ...string) error // nil = exec
+	databaseDumpDir  string                                                                                          // "" = defaultDatabaseDumpDir
+
+	loggedStages sync.Map // operation ID → container/stage of its last logged progress message
+
+	readOnly bool // Refuse operations that change containers
+
+	reconcileMu   sync.Mutex       // Serializes reconciliation runs
+	lastReconcile *ReconcileReport // nil until the first run
 }
 
-// stackLockEntry tracks a stack lock with its last usage time for cleanup.
+// stackLockEntry tracks a stack lock, the operation holding it, and its last
+// usage time for cleanup. Guarded by locksMu.
 type stackLockEntry struct {
-	mu       sync.Mutex
-	lastUsed time.Time
+	lastUsed    time.Time
+	held        bool
+	operationID string    // Operation holding the lock
+	acquiredAt  time.Time // When the lock was acquired
 }
 
 // HealthCheckConfig holds health check configuration.
 type HealthCheckConfig struct {
 	Timeout      time.Duration
 	FallbackWait time.Duration
+	LevelDelay   time.Duration // Wait between dependency levels in batch updates
 }
 
 // PullProgress represents image pull progress for streaming to UI.
@@ -114,6 +151,7 @@ func NewUpdateOrchestrator(
 			FallbackWait: 3 * time.Second, // Containers without health checks just need to be "running"
 		},
 		stackLocks:     make(map[string]*stackLockEntry),
+		lockTimeout:    defaultLockTimeout,
 		pathTranslator: pathTranslator,
 		ctx:            ctx,
 		cancelFn:       cancel,
@@ -140,6 +178,9 @@ func (o *UpdateOrchestrator) GetStorage() storage.Storage {
 
 // UpdateSingleContainer initiates an update for a single container.
 func (o *UpdateOrchestrator) UpdateSingleContainer(ctx context.Context, containerName, targetVersion string) (string, error) {
+	if err := o.checkWritable(); err != nil {
+		return "", err
+	}
 	operationID := uuid.New().String()
 
 	containers, err := o.dockerClient.ListContainers(ctx)
@@ -161,7 +202,7 @@ func (o *UpdateOrchestrator) UpdateSingleContainer(ctx context.Context, containe
 
 	stackName := o.stackManager.DetermineStack(ctx, *targetContainer)
 
-	if !o.acquireStackLock(stackName) {
+	if !o.acquireStackLock(stackName, operationID) {
 		if err := o.queueOperation(ctx, operationID, stackName, []string{containerName}, "single", map[string]string{containerName: targetVersion}); err != nil {
 			return "", fmt.Errorf("failed to queue operation: %w", err)
 		}
@@ -182,13 +223,14 @@ func (o *UpdateOrchestrator) UpdateSingleContainer(ctx context.Context, containe
 			targetVersion = "latest"
 			log.Printf("UPDATE: Empty target version for :latest image %s, using 'latest' as target", containerName)
 		} else {
-			o.releaseStackLock(stackName)
+			o.releaseStackLock(stackName, operationID)
 			return "", NewBadRequestError("cannot update container %s: no target version specified and current version is '%s' (not :latest)", containerName, currentVersion)
 		}
 	}
 
 	op := storage.UpdateOperation{
 		OperationID:   operationID,
+		TriggeredBy:   TriggerFromContext(ctx),
 		ContainerID:   targetContainer.ID,
 		ContainerName: containerName,
 		StackName:     stackName,
@@ -201,7 +243,7 @@ func (o *UpdateOrchestrator) UpdateSingleContainer(ctx context.Context, containe
 	// Only save to storage if available
 	if o.storage != nil {
 		if err := o.storage.SaveUpdateOperation(ctx, op); err != nil {
-			o.releaseStackLock(stackName)
+			o.releaseStackLock(stackName, operationID)
 			return "", fmt.Errorf("failed to save operation: %w", err)
 		}
 	}
@@ -213,6 +255,9 @@ func (o *UpdateOrchestrator) UpdateSingleContainer(ctx context.Context, containe
 
 // UpdateSingleContainerInGroup initiates an update for a single container as part of a batch group.
 func (o *UpdateOrchestrator) UpdateSingleContainerInGroup(ctx context.Context, containerName, targetVersion, batchGroupID string, containerMeta map[string]storage.BatchContainerDetail, forceContainers map[string]bool) (string, error) {
+	if err := o.checkWritable(); err != nil {
+		return "", err
+	}
 	operationID := uuid.New().String()
 
 	containers, err := o.dockerClient.ListContainers(ctx)
@@ -234,7 +279,7 @@ func (o *UpdateOrchestrator) UpdateSingleContainerInGroup(ctx context.Context, c
 
 	stackName := o.stackManager.DetermineStack(ctx, *targetContainer)
 
-	if !o.acquireStackLock(stackName) {
+	if !o.acquireStackLock(stackName, operationID) {
 		if err := o.queueOperation(ctx, operationID, stackName, []string{containerName}, "single", map[string]string{containerName: targetVersion}); err != nil {
 			return "", fmt.Errorf("failed to queue operation: %w", err)
 		}
@@ -250,7 +295,7 @@ func (o *UpdateOrchestrator) UpdateSingleContainerInGroup(ctx context.Context, c
 		if currentVersion == "latest" {
 			targetVersion = "latest"
 		} else {
-			o.releaseStackLock(stackName)
+			o.releaseStackLock(stackName, operationID)
 			return "", NewBadRequestError("cannot update container %s: no target version specified and current version is '%s' (not :latest)", containerName, currentVersion)
 		}
 	}
@@ -270,6 +315,7 @@ func (o *UpdateOrchestrator) UpdateSingleContainerInGroup(ctx context.Context, c
 
 	op := storage.UpdateOperation{
 		OperationID:   operationID,
+		TriggeredBy:   TriggerFromContext(ctx),
 		ContainerID:   targetContainer.ID,
 		ContainerName: containerName,
 		StackName:     stackName,
@@ -283,7 +329,7 @@ func (o *UpdateOrchestrator) UpdateSingleContainerInGroup(ctx context.Context, c
 
 	if o.storage != nil {
 		if err := o.storage.SaveUpdateOperation(ctx, op); err != nil {
-			o.releaseStackLock(stackName)
+			o.releaseStackLock(stackName, operationID)
 			return "", fmt.Errorf("failed to save operation: %w", err)
 		}
 	}
@@ -296,15 +342,19 @@ func (o *UpdateOrchestrator) UpdateSingleContainerInGroup(ctx context.Context, c
 
 // UpdateBatchContainers initiates batch updates for multiple containers.
 func (o *UpdateOrchestrator) UpdateBatchContainers(ctx context.Context, containerNames []string, targetVersions map[string]string) (string, error) {
-	return o.updateBatchContainersInternal(ctx, containerNames, targetVersions, "batch", "", nil, nil)
+	return o.updateBatchContainersInternal(ctx, containerNames, targetVersions, "batch", "", nil, nil, false)
 }
 
 // UpdateBatchContainersInGroup initiates batch updates as part of a batch group.
-func (o *UpdateOrchestrator) UpdateBatchContainersInGroup(ctx context.Context, containerNames []string, targetVersions map[string]string, batchGroupID string, containerMeta map[string]storage.BatchContainerDetail, forceContainers map[string]bool) (string, error) {
-	return o.updateBatchContainersInternal(ctx, containerNames, targetVersions, "batch", batchGroupID, containerMeta, forceContainers)
+// When allOrNothing is set, a failure of any container rolls back the whole batch.
+func (o *UpdateOrchestrator) UpdateBatchContainersInGroup(ctx context.Context, containerNames []string, targetVersions map[string]string, batchGroupID string, containerMeta map[string]storage.BatchContainerDetail, forceContainers map[string]bool, allOrNothing bool) (string, error) {
+	return o.updateBatchContainersInternal(ctx, containerNames, targetVersions, "batch", batchGroupID, containerMeta, forceContainers, allOrNothing)
 }
 
-func (o *UpdateOrchestrator) updateBatchContainersInternal(ctx context.Context, containerNames []string, targetVersions map[string]string, operationType string, batchGroupID string, containerMeta map[string]storage.BatchContainerDetail, forceContainers map[string]bool) (string, error) {
+func (o *UpdateOrchestrator) updateBatchContainersInternal(ctx context.Context, containerNames []string, targetVersions map[string]string, operationType string, batchGroupID string, containerMeta map[string]storage.BatchContainerDetail, forceContainers map[string]bool, allOrNothing bool) (string, error) {
+	if err := o.checkWritable(); err != nil {
+		return "", err
+	}
 	operationID := uuid.New().String()
 
 	containers, err := o.dockerClient.ListContainers(ctx)
@@ -392,6 +442,7 @@ func (o *UpdateOrchestrator) updateBatchContainersInternal(ctx context.Context,
 				detail.ChangeType = meta.ChangeType
 				detail.OldResolvedVersion = meta.OldResolvedVersion
 				detail.NewResolvedVersion = meta.NewResolvedVersion
+				detail.RestoreSnapshotsFrom = meta.RestoreSnapshotsFrom
 			}
 		}
 
@@ -409,10 +460,12 @@ func (o *UpdateOrchestrator) updateBatchContainersInternal(ctx context.Context,
 	// Build operation record with full details
 	op := storage.UpdateOperation{
 		OperationID:   operationID,
+		TriggeredBy:   TriggerFromContext(ctx),
 		StackName:     stackName,
 		OperationType: operationType,
 		BatchGroupID:  batchGroupID,
 		BatchDetails:  batchDetails,
+		AllOrNothing:  allOrNothing,
 	}
 
 	// Populate container name fields
@@ -426,7 +479,7 @@ func (o *UpdateOrchestrator) updateBatchContainersInternal(ctx context.Context,
 		op.ContainerName = fmt.Sprintf("%d containers", len(orderedContainers))
 	}
 
-	if !o.acquireStackLock(stackName) {
+	if !o.acquireStackLock(stackName, operationID) {
 		op.Status = "queued"
 		if err := o.storage.SaveUpdateOperation(ctx, op); err != nil {
 			return "", fmt.Errorf("failed to save queued operation: %w", err)
@@ -437,27 +490,32 @@ func (o *UpdateOrchestrator) updateBatchContainersInternal(ctx context.Context,
 			Containers:     containerNames,
 			OperationType:  operationType,
 			TargetVersions: targetVersions,
+			AllOrNothing:   allOrNothing,
 			QueuedAt:       time.Now(),
 		}
 		if err := o.storage.QueueUpdate(ctx, queue); err != nil {
 			return "", fmt.Errorf("failed to queue operation: %w", err)
 		}
+		o.publishQueueChanged(QueueActionQueued, stackName, operationID)
 		return operationID, nil
 	}
 
 	op.Status = "validating"
 	if err := o.storage.SaveUpdateOperation(ctx, op); err != nil {
-		o.releaseStackLock(stackName)
+		o.releaseStackLock(stackName, operationID)
 		return "", fmt.Errorf("failed to save operation: %w", err)
 	}
 
-	go o.executeBatchUpdate(context.Background(), operationID, orderedContainers, targetVersions, stackName, forceContainers)
+	go o.executeBatchUpdate(context.Background(), operationID, orderedContainers, targetVersions, stackName, forceContainers, allOrNothing)
 
 	return operationID, nil
 }
 
 // UpdateStack initiates an update for all containers in a stack.
 func (o *UpdateOrchestrator) UpdateStack(ctx context.Context, stackName string) (string, error) {
+	if err := o.checkWritable(); err != nil {
+		return "", err
+	}
 	operationID := uuid.New().String()
 
 	containers, err := o.dockerClient.ListContainers(ctx)
@@ -479,8 +537,9 @@ func (o *UpdateOrchestrator) UpdateStack(ctx context.Context, stackName string)
 	// Check for updates to determine target versions for each container
 	targetVersions := make(map[string]string)
 	if o.checker != nil {
+		checkCtx, _ := withSharedLookups(ctx)
 		for _, container := range stackContainers {
-			update := o.checker.checkContainer(ctx, container)
+			update := o.checker.checkContainer(checkCtx, container)
 			if update.Status == UpdateAvailable && update.LatestVersion != "" {
 				targetVersions[container.Name] = update.LatestVersion
 			}
@@ -503,7 +562,7 @@ func (o *UpdateOrchestrator) UpdateStack(ctx context.Context, stackName string)
 		}
 	}
 
-	if !o.acquireStackLock(stackName) {
+	if !o.acquireStackLock(stackName, operationID) {
 		containerNames := make([]string, len(stackContainers))
 		for i, c := range stackContainers {
 			containerNames[i] = c.Name
@@ -516,24 +575,31 @@ func (o *UpdateOrchestrator) UpdateStack(ctx context.Context, stackName string)
 
 	op := storage.UpdateOperation{
 		OperationID:   operationID,
+		TriggeredBy:   TriggerFromContext(ctx),
 		StackName:     stackName,
 		OperationType: "stack",
 		Status:        "validating",
 	}
 
 	if err := o.storage.SaveUpdateOperation(ctx, op); err != nil {
-		o.releaseStackLock(stackName)
+		o.releaseStackLock(stackName, operationID)
 		return "", fmt.Errorf("failed to save operation: %w", err)
 	}
 
-	go o.executeBatchUpdate(context.Background(), operationID, orderedContainers, targetVersions, stackName, nil)
+	go o.executeBatchUpdate(context.Background(), operationID, orderedContainers, targetVersions, stackName, nil, false)
 
 	return operationID, nil
 }
 
 // executeSingleUpdate executes the update workflow for a single container.
 func (o *UpdateOrchestrator) executeSingleUpdate(ctx context.Context, operationID string, container *docker.Container, targetVersion, stackName string, force bool) {
-	defer o.releaseStackLock(stackName)
+	ctx = o.withOperationLog(ctx, operationID)
+
+	defer o.releaseStackLock(stackName, operationID)
+	o.registerPausable(operationID)
+	defer o.unregisterPausable(operationID)
+	ctx, stopWatching := o.watchTargets(ctx, operationID, []*docker.Container{container})
+	defer stopWatching()
 
 	log.Printf("UPDATE: Starting executeSingleUpdate for operation=%s container=%s target=%s", operationID, container.Name, targetVersion)
 
@@ -594,6 +660,30 @@ func (o *UpdateOrchestrator) executeSingleUpdate(ctx context.Context, operationI
 		}
 	}
 
+	if err := o.waitForLowLoad(ctx, operationID, container.Name, stackName, []*docker.Container{container}); err != nil {
+		o.failOperation(ctx, operationID, "validating", fmt.Sprintf("Update deferred: %v", err))
+		return
+	}
+
+	o.publishProgress(operationID, container.Name, stackName, "validating", 15, "Running pre-flight checks")
+	if err := o.preflight(ctx, operationID, []*docker.Container{container}, map[string]string{container.Name: targetVersion}); err != nil {
+		o.failOperation(ctx, operationID, "validating", fmt.Sprintf("Pre-flight check failed: %v", err))
+		return
+	}
+
+	// Locally built services are rebuilt on the new base image instead of pulled
+	if rebuildsOnBaseUpdate(container) {
+		if err := o.rebuildFromBaseImage(ctx, operationID, container, targetVersion, stackName); err != nil {
+			o.failOperation(ctx, operationID, "building_image", fmt.Sprintf("Rebuild failed: %v", err))
+			return
+		}
+		if o.pauseAtPausePoint(ctx, operationID, container.Name, stackName) {
+			return
+		}
+		o.finishSingleUpdate(ctx, operationID, container, targetVersion, stackName)
+		return
+	}
+
 	o.publishProgress(operationID, container.Name, stackName, "updating_compose", 20, "Updating compose file")
 
 	composeFilePath := o.getComposeFilePath(container)
@@ -629,23 +719,59 @@ func (o *UpdateOrchestrator) executeSingleUpdate(ctx context.Context, operationI
 	}
 	close(progressChan)
 
+	if o.pauseAtPausePoint(ctx, operationID, container.Name, stackName) {
+		return
+	}
+
+	o.finishSingleUpdate(ctx, operationID, container, targetVersion, stackName)
+}
+
+// finishSingleUpdate recreates a container whose new image has been pulled, verifies it,
+// marks the operation complete, and restarts dependents. Also used to resume paused updates.
+func (o *UpdateOrchestrator) finishSingleUpdate(ctx context.Context, operationID string, container *docker.Container, targetVersion, stackName string) {
 	log.Printf("UPDATE: Image pulled for operation=%s, recreating container", operationID)
 	o.publishProgress(operationID, container.Name, stackName, "recreating", 60, "Recreating container and dependents")
 
 	// Build the full image reference with new version
 	newImageRef := replaceImageTag(container.Image, targetVersion)
 
-	if _, err := o.restartContainerWithDependents(ctx, operationID, container.Name, stackName, newImageRef); err != nil {
-		o.failOperation(ctx, operationID, "recreating", fmt.Sprintf("Recreation failed: %v", err))
-		return
+	replicas, err := o.serviceReplicas(ctx, container)
+	if err != nil {
+		log.Printf("UPDATE: Failed to list replicas of %s: %v", container.Name, err)
 	}
 
-	o.publishProgress(operationID, container.Name, stackName, "health_check", 80,
-		fmt.Sprintf("Checking health of %s", container.Name))
+	if usesCanary(container, replicas) {
+		// Canary updates health check each replica and roll back the canary themselves
+		_, oldTag := splitImageRef(container.Image)
+		if err := o.canaryUpdate(ctx, operationID, container, replicas, stackName, oldTag); err != nil {
+			o.failOperation(ctx, operationID, "canary", fmt.Sprintf("Canary update failed: %v", err))
+			return
+		}
+	} else {
+		if err := o.snapshotVolumes(ctx, operationID, container, stackName); err != nil {
+			o.failOperation(ctx, operationID, "backup", fmt.Sprintf("Volume snapshot failed: %v", err))
+			return
+		}
 
-	if err := o.waitForHealthy(ctx, container.Name, o.healthCheckCfg.Timeout); err != nil {
-		o.failOperation(ctx, operationID, "health_check", fmt.Sprintf("Health check failed: %v", err))
-		return
+		if _, err := o.restartContainerWithDependents(ctx, operationID, container.Name, stackName, newImageRef); err != nil {
+			o.failOperation(ctx, operationID, "recreating", fmt.Sprintf("Recreation failed: %v", err))
+			return
+		}
+
+		o.publishProgress(operationID, container.Name, stackName, "health_check", 80,
+			fmt.Sprintf("Checking health of %s", container.Name))
+
+		if err := o.waitForHealthy(ctx, container.Name, o.healthCheckCfg.Timeout); err != nil {
+			o.failOperation(ctx, operationID, "health_check", fmt.Sprintf("Health check failed: %v", err))
+			o.autoRollback(ctx, operationID, container.Name)
+			return
+		}
+
+		if err := o.runPostUpdateCheck(ctx, operationID, container); err != nil {
+			o.failOperation(ctx, operationID, "post_update_check", fmt.Sprintf("Post-update check failed: %v", err))
+			o.autoRollback(ctx, operationID, container.Name)
+			return
+		}
 	}
 
 	log.Printf("UPDATE: Health check passed for operation=%s, marking complete", operationID)
@@ -663,6 +789,8 @@ func (o *UpdateOrchestrator) executeSingleUpdate(ctx context.Context, operationI
 		o.storage.SaveUpdateOperation(ctx, completedOp)
 	}
 
+	o.observeUpdated(operationID, []*docker.Container{container})
+
 	// Restart dependent containers (those with docksmith.restart-after label)
 	// For regular updates, run pre-update checks on dependents
 	depResult, depErr := o.restartDependentContainers(ctx, container.Name, false)
@@ -710,6 +838,8 @@ func (o *UpdateOrchestrator) executeSingleUpdate(ctx context.Context, operationI
 // 4. Trigger docker compose up -d to restart with new image
 // 5. On next startup, the operation is marked complete by resumePendingSelfUpdates()
 func (o *UpdateOrchestrator) executeSelfUpdate(ctx context.Context, operationID string, container *docker.Container, targetVersion, stackName string) {
+	ctx = o.withOperationLog(ctx, operationID)
+
 	log.Printf("SELF-UPDATE: Starting self-update for operation=%s container=%s target=%s", operationID, container.Name, targetVersion)
 
 	o.publishProgress(operationID, container.Name, stackName, "validating", 0, "Preparing self-update")
@@ -737,7 +867,31 @@ func (o *UpdateOrchestrator) executeSelfUpdate(ctx context.Context, operationID
 		o.storage.SaveUpdateOperation(ctx, op)
 	}
 
+	if err := o.waitForLowLoad(ctx, operationID, container.Name, stackName, []*docker.Container{container}); err != nil {
+		o.failOperation(ctx, operationID, "validating", fmt.Sprintf("Update deferred: %v", err))
+		return
+	}
+
+	o.publishProgress(operationID, container.Name, stackName, "validating", 15, "Running pre-flight checks")
+	if err := o.preflight(ctx, operationID, []*docker.Container{container}, map[string]string{container.Name: targetVersion}); err != nil {
+		o.failOperation(ctx, operationID, "validating", fmt.Sprintf("Pre-flight check failed: %v", err))
+		return
+	}
+
 	// Step 1: Update compose file with new version
+	// Locally built services are rebuilt on the new base image instead of pulled
+	if rebuildsOnBaseUpdate(container) {
+		if err := o.rebuildFromBaseImage(ctx, operationID, container, targetVersion, stackName); err != nil {
+			o.failOperation(ctx, operationID, "building_image", fmt.Sprintf("Rebuild failed: %v", err))
+			return
+		}
+		if o.pauseAtPausePoint(ctx, operationID, container.Name, stackName) {
+			return
+		}
+		o.finishSingleUpdate(ctx, operationID, container, targetVersion, stackName)
+		return
+	}
+
 	o.publishProgress(operationID, container.Name, stackName, "updating_compose", 20, "Updating compose file")
 
 	composeFilePath := o.getComposeFilePath(container)
@@ -838,6 +992,8 @@ func (o *UpdateOrchestrator) executeSelfUpdate(ctx context.Context, operationID
 // Since restarting kills the docksmith process, we mark the operation as pending_restart
 // and complete it on the next startup.
 func (o *UpdateOrchestrator) executeSelfRestart(ctx context.Context, operationID string, container *docker.Container, stackName string) {
+	ctx = o.withOperationLog(ctx, operationID)
+
 	log.Printf("SELF-RESTART: Starting self-restart for operation=%s container=%s", operationID, container.Name)
 
 	o.publishProgress(operationID, container.Name, stackName, "stopping", 20, "Preparing to restart docksmith...")
@@ -913,8 +1069,16 @@ func (o *UpdateOrchestrator) executeSelfRestart(ctx context.Context, operationID
 }
 
 // executeBatchUpdate executes batch update workflow.
-func (o *UpdateOrchestrator) executeBatchUpdate(ctx context.Context, operationID string, containers []*docker.Container, targetVersions map[string]string, stackName string, forceContainers map[string]bool) {
-	defer o.releaseStackLock(stackName)
+// By default failures are isolated per container. With allOrNothing, a pull,
+// recreation, or health check failure rolls back the entire batch.
+func (o *UpdateOrchestrator) executeBatchUpdate(ctx context.Context, operationID string, containers []*docker.Container, targetVersions map[string]string, stackName string, forceContainers map[string]bool, allOrNothing bool) {
+	ctx = o.withOperationLog(ctx, operationID)
+
+	defer o.releaseStackLock(stackName, operationID)
+	o.registerPausable(operationID)
+	defer o.unregisterPausable(operationID)
+	ctx, stopWatching := o.watchTargets(ctx, operationID, containers)
+	defer stopWatching()
 
 	// Check if Docker SDK is initialized (required for container operations)
 	if o.dockerSDK == nil {
@@ -974,17 +1138,34 @@ func (o *UpdateOrchestrator) executeBatchUpdate(ctx context.Context, operationID
 	// Build a map of container name → old tag for compose revert on failure
 	oldTags := make(map[string]string)
 	for _, container := range updateContainers {
+		if rebuildsOnBaseUpdate(container) {
+			continue // Rebuilt in place; their compose files are not changed
+		}
 		_, tag := splitImageRef(container.Image)
 		if tag != "" {
 			oldTags[container.Name] = tag
 		}
 	}
 
+	if err := o.waitForLowLoad(ctx, operationID, "", stackName, updateContainers); err != nil {
+		o.failOperation(ctx, operationID, "validating", fmt.Sprintf("Update deferred: %v", err))
+		return
+	}
+
+	o.publishProgress(operationID, "", stackName, "validating", 5, "Running pre-flight checks")
+	if err := o.preflight(ctx, operationID, updateContainers, targetVersions); err != nil {
+		o.failOperation(ctx, operationID, "validating", fmt.Sprintf("Pre-flight check failed: %v", err))
+		return
+	}
+
 	// Phase 1: Update all compose files first (10-30%)
 	o.publishProgress(operationID, "", stackName, "updating_compose", 10, fmt.Sprintf("Updating %d compose files", len(updateContainers)))
 
 	for i, container := range updateContainers {
 		progress := 10 + (i * 20 / len(updateContainers))
+		if rebuildsOnBaseUpdate(container) {
+			continue // The Dockerfile is updated when the image is rebuilt
+		}
 		o.publishProgress(operationID, container.Name, stackName, "updating_compose", progress, fmt.Sprintf("Updating compose for %s", container.Name))
 
 		// Update compose file with new version
@@ -1011,6 +1192,17 @@ func (o *UpdateOrchestrator) executeBatchUpdate(ctx context.Context, operationID
 
 	for i, container := range updateContainers {
 		targetVersion := targetVersions[container.Name]
+
+		// Locally built services are rebuilt on the new base image instead of pulled
+		if rebuildsOnBaseUpdate(container) {
+			if err := o.rebuildFromBaseImage(ctx, operationID, container, targetVersion, stackName); err != nil {
+				log.Printf("BATCH UPDATE: Failed to rebuild %s: %v", container.Name, err)
+				pullFailed[container.Name] = true
+				o.updateBatchDetailStatus(ctx, operationID, container.Name, "failed", fmt.Sprintf("Failed to rebuild image: %v", err))
+			}
+			continue
+		}
+
 		newImageRef := replaceImageTag(container.Image, targetVersion)
 
 		baseProgress := 30 + (i * 30 / len(updateContainers))
@@ -1039,20 +1231,36 @@ func (o *UpdateOrchestrator) executeBatchUpdate(ctx context.Context, operationID
 
 			// Revert compose file to old tag so the container doesn't have a mismatch
 			if oldTag, ok := oldTags[container.Name]; ok {
-				composeFilePath := o.getComposeFilePath(container)
-				if composeFilePath != "" {
-					if resolvedPath, err := o.resolveComposeFile(composeFilePath); err == nil {
-						if revertErr := o.updateComposeFile(ctx, resolvedPath, container, oldTag); revertErr != nil {
-							log.Printf("BATCH UPDATE: Failed to revert compose for %s: %v", container.Name, revertErr)
-						} else {
-							log.Printf("BATCH UPDATE: Reverted compose for %s to %s after pull failure", container.Name, oldTag)
-						}
-					}
-				}
+				o.revertComposeTag(ctx, container, oldTag, "pull failure")
 			}
 		}
 	}
 
+	// In all-or-nothing mode nothing has been recreated yet, so restoring the
+	// compose files is the whole rollback
+	if allOrNothing && len(pullFailed) > 0 {
+		failed := make([]string, 0, len(pullFailed))
+		for _, container := range updateContainers {
+			if pullFailed[container.Name] {
+				failed = append(failed, container.Name)
+			}
+		}
+		o.rollbackBatch(ctx, operationID, stackName, updateContainers, nil, oldTags,
+			fmt.Sprintf("image pull failed for %s", strings.Join(failed, ", ")))
+		return
+	}
+
+	if o.pauseAtPausePoint(ctx, operationID, "", stackName) {
+		return
+	}
+
+	o.finishBatchUpdate(ctx, operationID, updateContainers, selfContainer, targetVersions, oldTags, pullFailed, stackName, allOrNothing)
+}
+
+// finishBatchUpdate recreates and verifies the containers of a batch whose images
+// have been pulled, then restarts dependents and completes the operation (or hands
+// off to the docksmith self-update). Also used to resume paused batch updates.
+func (o *UpdateOrchestrator) finishBatchUpdate(ctx context.Context, operationID string, updateContainers []*docker.Container, selfContainer *docker.Container, targetVersions, oldTags map[string]string, pullFailed map[string]bool, stackName string, allOrNothing bool) {
 	// Phase 3: Recreate all containers respecting dependency order (60-90%)
 	o.publishProgress(operationID, "", stackName, "recreating", 60, "Recreating containers in dependency order")
 
@@ -1090,13 +1298,58 @@ func (o *UpdateOrchestrator) executeBatchUpdate(ctx context.Context, operationID
 		}
 	}
 
+	// Rollbacks restore the volume snapshots of the update they undo instead of taking new ones
+	details := make(map[string]storage.BatchContainerDetail)
+	isRollback := false
+	if op, found, _ := o.storage.GetUpdateOperation(ctx, operationID); found {
+		isRollback = op.OperationType == "rollback"
+		for _, d := range op.BatchDetails {
+			details[d.ContainerName] = d
+		}
+	}
+
 	// Recreate, health-check, and mark each container individually (60-95%)
 	// All phases merged into one per-container loop so the frontend gets continuous SSE events.
 	successCount := 0
 	failCount := 0
 	failedContainers := make(map[string]bool)
+	var recreated []*docker.Container // successfully recreated, in order (all-or-nothing rollback)
+	var updated []*docker.Container   // passed their health and post-update checks
+
+	// Staggered updates finish each dependency level (healthy, then a delay)
+	// before starting the next, and stop at the first level with a failure.
+	staggered := o.isStaggered(orderedContainers)
+	var levelOf map[string]int
+	if staggered {
+		levelOf = o.orderByLevel(orderedContainers)
+	}
+	currentLevel := -1
+	var levelDelay time.Duration
+	var levelFailure, gateFailure string
+	healthy := make(map[string]bool) // health check results, for depends_on service_healthy
 
 	for i, cont := range orderedContainers {
+		if staggered && levelOf[cont.Name] != currentLevel {
+			if levelFailure != "" {
+				gateFailure = levelFailure
+			} else if currentLevel >= 0 && levelDelay > 0 {
+				o.waitForNextLevel(ctx, operationID, stackName, levelDelay, 60+(i*35/len(orderedContainers)))
+			}
+			currentLevel = levelOf[cont.Name]
+			levelDelay = 0
+		}
+
+		if gateFailure != "" {
+			failCount++
+			failedContainers[cont.Name] = true
+			o.updateBatchDetailStatus(ctx, operationID, cont.Name, "failed",
+				fmt.Sprintf("Not updated: %s failed in an earlier dependency level", gateFailure))
+			if oldTag, ok := oldTags[cont.Name]; ok {
+				o.revertComposeTag(ctx, cont, oldTag, "dependency level failure")
+			}
+			continue
+		}
+
 		// Skip containers whose image pull failed — they are already marked failed
 		if pullFailed[cont.Name] {
 			failCount++
@@ -1105,6 +1358,24 @@ func (o *UpdateOrchestrator) executeBatchUpdate(ctx context.Context, operationID
 			continue
 		}
 
+		// depends_on condition service_healthy: not recreated until its dependencies are healthy
+		if dep := o.awaitHealthyDependencies(ctx, cont, orderedContainers, healthy); dep != "" {
+			failCount++
+			failedContainers[cont.Name] = true
+			o.updateBatchDetailStatus(ctx, operationID, cont.Name, "failed",
+				fmt.Sprintf("Not updated: dependency %s is not healthy (depends_on condition service_healthy)", dep))
+			if oldTag, ok := oldTags[cont.Name]; ok {
+				o.revertComposeTag(ctx, cont, oldTag, "unhealthy dependency")
+			}
+			if allOrNothing {
+				o.rollbackBatch(ctx, operationID, stackName, orderedContainers, recreated, oldTags,
+					fmt.Sprintf("dependency %s of %s is not healthy", dep, cont.Name))
+				return
+			}
+			levelFailure = cont.Name
+			continue
+		}
+
 		baseProgress := 60 + (i * 35 / len(orderedContainers))
 
 		var failReason string // tracks why a container failed for the batch detail message
@@ -1112,13 +1383,32 @@ func (o *UpdateOrchestrator) executeBatchUpdate(ctx context.Context, operationID
 		// Update DB status so poller can report progress even if SSE drops
 		o.updateBatchDetailStatus(ctx, operationID, cont.Name, "in_progress", fmt.Sprintf("Updating %s", cont.Name))
 
+		if snapshotOpID := details[cont.Name].RestoreSnapshotsFrom; snapshotOpID != "" {
+			// A failed restore leaves the container stopped rather than relaunching it
+			if err := o.restoreVolumes(ctx, operationID, snapshotOpID, cont, stackName); err != nil {
+				log.Printf("BATCH UPDATE: Volume restore failed for %s: %v", cont.Name, err)
+				failCount++
+				failedContainers[cont.Name] = true
+				o.updateBatchDetailStatus(ctx, operationID, cont.Name, "failed", fmt.Sprintf("Volume restore failed: %v", err))
+				levelFailure = cont.Name
+				continue
+			}
+		} else if !isRollback {
+			if err := o.snapshotVolumes(ctx, operationID, cont, stackName); err != nil {
+				log.Printf("BATCH UPDATE: Volume snapshot failed for %s: %v", cont.Name, err)
+				failReason = fmt.Sprintf("Volume snapshot failed: %v", err)
+			}
+		}
+
 		// Use compose-based recreation (consistent with single-container update path)
-		o.publishProgress(operationID, cont.Name, stackName, "recreating", baseProgress, fmt.Sprintf("Recreating %s", cont.Name))
-		if err := o.recreateContainerWithCompose(ctx, cont); err != nil {
-			log.Printf("BATCH UPDATE: Compose recreation failed for %s: %v", cont.Name, err)
-			failReason = fmt.Sprintf("Compose recreation failed: %v", err)
-		} else {
-			log.Printf("BATCH UPDATE: Successfully recreated %s with compose", cont.Name)
+		if failReason == "" {
+			o.publishProgress(operationID, cont.Name, stackName, "recreating", baseProgress, fmt.Sprintf("Recreating %s", cont.Name))
+			if err := o.recreateContainerWithCompose(ctx, cont); err != nil {
+				log.Printf("BATCH UPDATE: Compose recreation failed for %s: %v", cont.Name, err)
+				failReason = fmt.Sprintf("Compose recreation failed: %v", err)
+			} else {
+				log.Printf("BATCH UPDATE: Successfully recreated %s with compose", cont.Name)
+			}
 		}
 
 		if failReason != "" {
@@ -1128,16 +1418,7 @@ func (o *UpdateOrchestrator) executeBatchUpdate(ctx context.Context, operationID
 
 			// Revert compose file to old tag so the container doesn't have a mismatch
 			if oldTag, ok := oldTags[cont.Name]; ok {
-				composeFilePath := o.getComposeFilePath(cont)
-				if composeFilePath != "" {
-					if resolvedPath, err := o.resolveComposeFile(composeFilePath); err == nil {
-						if revertErr := o.updateComposeFile(ctx, resolvedPath, cont, oldTag); revertErr != nil {
-							log.Printf("BATCH UPDATE: Failed to revert compose for %s: %v", cont.Name, revertErr)
-						} else {
-							log.Printf("BATCH UPDATE: Reverted compose for %s to %s after recreation failure", cont.Name, oldTag)
-						}
-					}
-				}
+				o.revertComposeTag(ctx, cont, oldTag, "recreation failure")
 
 				// If the container is not running (killed during failed recreation), relaunch it
 				// with the old tag via compose up (compose file is now reverted)
@@ -1155,6 +1436,13 @@ func (o *UpdateOrchestrator) executeBatchUpdate(ctx context.Context, operationID
 				}
 			}
 
+			if allOrNothing {
+				o.rollbackBatch(ctx, operationID, stackName, orderedContainers, recreated, oldTags,
+					fmt.Sprintf("recreation failed for %s", cont.Name))
+				return
+			}
+
+			levelFailure = cont.Name
 			continue
 		}
 
@@ -1163,13 +1451,51 @@ func (o *UpdateOrchestrator) executeBatchUpdate(ctx context.Context, operationID
 		o.publishProgress(operationID, cont.Name, stackName, "health_check", healthProgress,
 			fmt.Sprintf("Checking health of %s", cont.Name))
 
-		if err := o.waitForHealthy(ctx, cont.Name, o.healthCheckCfg.Timeout); err != nil {
+		recreated = append(recreated, cont)
+		err := o.waitForHealthy(ctx, cont.Name, o.healthCheckCfg.Timeout)
+		healthy[cont.Name] = err == nil
+		if err != nil {
+			if allOrNothing {
+				log.Printf("BATCH UPDATE: Health check failed for %s: %v", cont.Name, err)
+				o.updateBatchDetailStatus(ctx, operationID, cont.Name, "failed", fmt.Sprintf("Health check failed: %v", err))
+				o.rollbackBatch(ctx, operationID, stackName, orderedContainers, recreated, oldTags,
+					fmt.Sprintf("health check failed for %s", cont.Name))
+				return
+			}
+			if staggered {
+				// The health gate: later levels are not updated on top of an unhealthy dependency
+				log.Printf("BATCH UPDATE: Health check failed for %s, closing dependency gate: %v", cont.Name, err)
+				failCount++
+				failedContainers[cont.Name] = true
+				o.updateBatchDetailStatus(ctx, operationID, cont.Name, "failed", fmt.Sprintf("Health check failed: %v", err))
+				levelFailure = cont.Name
+				continue
+			}
 			log.Printf("BATCH UPDATE: Health check warning for %s: %v", cont.Name, err)
 		}
 
+		if err := o.runPostUpdateCheck(ctx, operationID, cont); err != nil {
+			log.Printf("BATCH UPDATE: Post-update check failed for %s: %v", cont.Name, err)
+			failCount++
+			failedContainers[cont.Name] = true
+			o.updateBatchDetailStatus(ctx, operationID, cont.Name, "failed", fmt.Sprintf("Post-update check failed: %v", err))
+			if allOrNothing {
+				o.rollbackBatch(ctx, operationID, stackName, orderedContainers, recreated, oldTags,
+					fmt.Sprintf("post-update check failed for %s", cont.Name))
+				return
+			}
+			levelFailure = cont.Name
+			continue
+		}
+
 		// Mark this container as complete (DB + SSE)
 		o.updateBatchDetailStatus(ctx, operationID, cont.Name, "complete", fmt.Sprintf("Updated %s", cont.Name))
 		successCount++
+		updated = append(updated, cont)
+
+		if delay := o.levelDelayFor(cont); delay > levelDelay {
+			levelDelay = delay
+		}
 	}
 
 	// Phase 5: Restart dependent containers (95-99%)
@@ -1249,9 +1575,141 @@ func (o *UpdateOrchestrator) executeBatchUpdate(ctx context.Context, operationID
 		o.storage.SaveUpdateOperation(ctx, completedOp)
 	}
 
+	o.observeUpdated(operationID, updated)
+
 	o.publishProgress(operationID, "", stackName, status, 100, message)
 }
 
+// revertComposeTag restores a container's compose file to its pre-update tag.
+func (o *UpdateOrchestrator) revertComposeTag(ctx context.Context, cont *docker.Container, oldTag, reason string) {
+	composeFilePath := o.getComposeFilePath(cont)
+	if composeFilePath == "" {
+		return
+	}
+	resolvedPath, err := o.resolveComposeFile(composeFilePath)
+	if err != nil {
+		return
+	}
+	if revertErr := o.updateComposeFile(ctx, resolvedPath, cont, oldTag); revertErr != nil {
+		log.Printf("BATCH UPDATE: Failed to revert compose for %s: %v", cont.Name, revertErr)
+	} else {
+		log.Printf("BATCH UPDATE: Reverted compose for %s to %s after %s", cont.Name, oldTag, reason)
+	}
+}
+
+// rollbackBatch undoes an all-or-nothing batch update. Every compose file in
+// the batch is restored to its old tag, then the containers that were already
+// recreated are recreated on their old images in reverse dependency order.
+// The operation is marked failed with rollback_occurred set.
+func (o *UpdateOrchestrator) rollbackBatch(ctx context.Context, operationID, stackName string, containers, recreated []*docker.Container, oldTags map[string]string, reason string) {
+	log.Printf("BATCH UPDATE: All-or-nothing batch %s failed (%s), rolling back %d recreated containers", operationID, reason, len(recreated))
+	o.publishProgress(operationID, "", stackName, "rolling_back", 95, fmt.Sprintf("Rolling back batch: %s", reason))
+
+	// Restore all compose files first so recreation picks up the old tags
+	for _, cont := range containers {
+		if oldTag, ok := oldTags[cont.Name]; ok {
+			o.revertComposeTag(ctx, cont, oldTag, "batch rollback")
+		}
+	}
+
+	details := make(map[string]storage.BatchContainerDetail)
+	if op, found, _ := o.storage.GetUpdateOperation(ctx, operationID); found {
+		for _, d := range op.BatchDetails {
+			details[d.ContainerName] = d
+		}
+	}
+
+	rollbackFailures := 0
+	handled := make(map[string]bool)
+	for i := len(recreated) - 1; i >= 0; i-- {
+		cont := recreated[i]
+		handled[cont.Name] = true
+		detail := details[cont.Name]
+
+		// Same-tag updates (e.g. :latest) need the old image re-tagged first
+		if detail.OldDigest != "" && detail.OldVersion == detail.NewVersion {
+			if err := o.retagDigest(ctx, cont, detail.OldDigest); err != nil {
+				log.Printf("BATCH UPDATE: Failed to restore old image for %s: %v", cont.Name, err)
+				o.updateBatchDetailStatus(ctx, operationID, cont.Name, "failed", fmt.Sprintf("Rollback failed: %v", err))
+				rollbackFailures++
+				continue
+			}
+		}
+
+		if err := o.restoreVolumes(ctx, operationID, operationID, cont, stackName); err != nil {
+			log.Printf("BATCH UPDATE: Failed to restore volumes of %s: %v", cont.Name, err)
+			o.updateBatchDetailStatus(ctx, operationID, cont.Name, "failed", fmt.Sprintf("Rollback failed: %v", err))
+			rollbackFailures++
+			continue
+		}
+
+		o.publishProgress(operationID, cont.Name, stackName, "rolling_back", 96, fmt.Sprintf("Rolling back %s", cont.Name))
+		if err := o.recreateContainerWithCompose(ctx, cont); err != nil {
+			log.Printf("BATCH UPDATE: Failed to roll back %s: %v", cont.Name, err)
+			o.updateBatchDetailStatus(ctx, operationID, cont.Name, "failed", fmt.Sprintf("Rollback failed: %v", err))
+			rollbackFailures++
+			continue
+		}
+		if err := o.waitForHealthy(ctx, cont.Name, o.healthCheckCfg.Timeout); err != nil {
+			log.Printf("BATCH UPDATE: Health check warning for %s after rollback: %v", cont.Name, err)
+		}
+		if depResult, depErr := o.restartDependentContainers(ctx, cont.Name, true); depErr != nil {
+			log.Printf("BATCH UPDATE: Warning - failed to restart dependents for %s: %v", cont.Name, depErr)
+		} else if depResult != nil && len(depResult.Restarted) > 0 {
+			log.Printf("BATCH UPDATE: Restarted dependents for %s after rollback: %v", cont.Name, depResult.Restarted)
+		}
+
+		o.updateBatchDetailStatus(ctx, operationID, cont.Name, "rolled_back", fmt.Sprintf("Rolled back to %s", detail.OldVersion))
+	}
+
+	// Containers that were never recreated keep their old image
+	for _, cont := range containers {
+		if handled[cont.Name] || details[cont.Name].Status == "failed" {
+			continue
+		}
+		o.updateBatchDetailStatus(ctx, operationID, cont.Name, "failed", fmt.Sprintf("Not updated: %s", reason))
+	}
+
+	message := fmt.Sprintf("Batch rolled back: %s", reason)
+	if rollbackFailures > 0 {
+		message = fmt.Sprintf("%s (%d containers could not be rolled back)", message, rollbackFailures)
+	}
+
+	completedNow := time.Now()
+	if op, found, _ := o.storage.GetUpdateOperation(ctx, operationID); found {
+		op.Status = "failed"
+		op.RollbackOccurred = true
+		op.CompletedAt = &completedNow
+		op.ErrorMessage = message
+		o.storage.SaveUpdateOperation(ctx, op)
+	}
+
+	o.publishProgress(operationID, "", stackName, "failed", 100, message)
+}
+
+// retagDigest pulls an image by digest and tags it with the container's
+// current tag, so compose recreates the container on the old image.
+func (o *UpdateOrchestrator) retagDigest(ctx context.Context, cont *docker.Container, digest string) error {
+	repo, tag := splitImageRef(cont.Image)
+	if tag == "" {
+		tag = "latest"
+	}
+	digestRef := repo + "@" + digest
+
+	progressChan := make(chan PullProgress, 10)
+	go func() {
+		for range progressChan {
+		}
+	}()
+	err := o.pullImage(ctx, digestRef, progressChan)
+	close(progressChan)
+	if err != nil {
+		return fmt.Errorf("old image digest no longer available: %w", err)
+	}
+
+	return o.dockerSDK.ImageTag(ctx, digestRef, repo+":"+tag)
+}
+
 // checkPermissions validates Docker access and file permissions.
 // checkDockerAccess validates only Docker socket connectivity.
 // Use this for operations that don't need compose file access (e.g., restart, stop).
@@ -1351,67 +1809,44 @@ func (o *UpdateOrchestrator) updateComposeFile(ctx context.Context, composeFileP
 		return fmt.Errorf("failed to find service %s: %w", serviceName, err)
 	}
 
-	// Update the image tag in the service node
-	if service.Node.Kind != yaml.MappingNode {
-		return fmt.Errorf("service node is not a mapping")
+	currentImage := compose.GetServiceImage(service)
+	if currentImage == "" {
+		return fmt.Errorf("service %s has no image field", serviceName)
 	}
+	newImage := replaceImageTag(currentImage, newTag)
 
-	// Find and update the image field
-	imageUpdated := false
-	for i := 0; i < len(service.Node.Content); i += 2 {
-		keyNode := service.Node.Content[i]
-		valueNode := service.Node.Content[i+1]
-
-		if keyNode.Value == "image" {
-			currentImage := valueNode.Value
-
-			// Handle env var image specs (e.g., ${OPENCLAW_IMAGE:-openclaw:latest})
-			if compose.ContainsEnvVar(currentImage) {
-				updated, ok := compose.ReplaceTagInEnvVar(currentImage, newTag)
-				if !ok {
-					log.Printf("UPDATE: Cannot update env var image for %s (no default value): %s", serviceName, currentImage)
-					return nil
-				}
-				log.Printf("UPDATE: Updating env var image for %s: %s -> %s", serviceName, currentImage, updated)
-				valueNode.Value = updated
-				imageUpdated = true
-
-				// Also update the .env file if the variable is defined there
-				envVarName := compose.ExtractEnvVarName(currentImage)
-				if envVarName != "" {
-					composeDir := filepath.Dir(composeFile.Path)
-					envVars := compose.LoadDotEnv(composeDir)
-					if _, exists := envVars[envVarName]; exists {
-						if err := compose.UpdateDotEnvVar(composeDir, envVarName, newTag); err != nil {
-							log.Printf("UPDATE: Warning: failed to update .env file for %s: %v", envVarName, err)
-						} else {
-							log.Printf("UPDATE: Updated .env variable %s with new tag %s", envVarName, newTag)
-						}
-					}
+	// Handle env var image specs (e.g., ${OPENCLAW_IMAGE:-openclaw:latest})
+	if compose.ContainsEnvVar(currentImage) {
+		updated, ok := compose.ReplaceTagInEnvVar(currentImage, newTag)
+		if !ok {
+			log.Printf("UPDATE: Cannot update env var image for %s (no default value): %s", serviceName, currentImage)
+			return nil
+		}
+		log.Printf("UPDATE: Updating env var image for %s: %s -> %s", serviceName, currentImage, updated)
+		newImage = updated
+
+		// Also update the .env file if the variable is defined there
+		envVarName := compose.ExtractEnvVarName(currentImage)
+		if envVarName != "" {
+			composeDir := filepath.Dir(composeFile.Path)
+			envVars := compose.LoadDotEnv(composeDir)
+			if _, exists := envVars[envVarName]; exists {
+				if err := compose.UpdateDotEnvVar(composeDir, envVarName, newTag); err != nil {
+					log.Printf("UPDATE: Warning: failed to update .env file for %s: %v", envVarName, err)
+				} else {
+					log.Printf("UPDATE: Updated .env variable %s with new tag %s", envVarName, newTag)
 				}
-				break
 			}
-
-			// Parse current image and update tag
-			parts := strings.Split(currentImage, ":")
-			if len(parts) > 1 {
-				parts[len(parts)-1] = newTag
-			} else {
-				parts = append(parts, newTag)
-			}
-
-			valueNode.Value = strings.Join(parts, ":")
-			imageUpdated = true
-			break
 		}
 	}
 
-	if !imageUpdated {
-		return fmt.Errorf("service %s has no image field", serviceName)
+	if newImage == currentImage {
+		return nil
 	}
 
-	// Save the compose file (uses the actual file path from composeFile.Path)
-	if err := composeFile.Save(); err != nil {
+	// Only the image scalar is rewritten, so comments, anchors, and formatting are kept
+	// (uses the actual file path from composeFile.Path)
+	if err := composeFile.SetImage(service, newImage); err != nil {
 		return fmt.Errorf("failed to save compose file: %w", err)
 	}
 
@@ -1420,10 +1855,20 @@ func (o *UpdateOrchestrator) updateComposeFile(ctx context.Context, composeFileP
 
 // pullImage pulls a Docker image with retry logic.
 // Tracks per-layer progress and reports aggregate percent across all layers.
+// Images pre-pulled ahead of the update are not pulled again while their digest is current.
 func (o *UpdateOrchestrator) pullImage(ctx context.Context, imageRef string, progressChan chan<- PullProgress) error {
 	if o.dockerSDK == nil {
 		return fmt.Errorf("docker SDK not initialized")
 	}
+	if o.prepulledCurrent(ctx, imageRef) {
+		return nil
+	}
+
+	release, err := o.acquireUpdateSlot(ctx)
+	if err != nil {
+		return err
+	}
+	defer release()
 
 	maxRetries := 3
 	backoff := time.Second
@@ -1434,17 +1879,18 @@ func (o *UpdateOrchestrator) pullImage(ctx context.Context, imageRef string, pro
 			backoff *= 2
 		}
 
-		reader, err := o.dockerSDK.ImagePull(ctx, imageRef, image.PullOptions{})
+		reader, err := o.dockerSDK.ImagePull(ctx, imageRef, o.pullOptions(ctx, imageRef))
 		if err != nil {
 			// Don't retry permanent errors — tag/manifest doesn't exist on the registry
 			errStr := err.Error()
 			if strings.Contains(errStr, "manifest unknown") || strings.Contains(errStr, "not found") {
 				return fmt.Errorf("image tag does not exist on the registry: %w", err)
 			}
+			logStep(ctx, "", logSourcePull, "Pull attempt %d/%d of %s failed: %v", attempt+1, maxRetries, imageRef, err)
 			if attempt < maxRetries-1 {
 				continue
 			}
-			return fmt.Errorf("failed to pull image after %d attempts: %w", maxRetries, err)
+			return fmt.Errorf("failed to pull image after %d attempts: %w%s", maxRetries, err, pullNetworkHint(err))
 		}
 
 		err = func() error {
@@ -1469,12 +1915,21 @@ func (o *UpdateOrchestrator) pullImage(ctx context.Context, imageRef string, pro
 					return fmt.Errorf("failed to decode pull progress: %w", err)
 				}
 
+				if message, ok := event["error"].(string); ok {
+					logStep(ctx, "", logSourcePull, "%s: %s", imageRef, message)
+				}
 				status, _ := event["status"].(string)
 				if status == "" {
 					continue
 				}
 
 				layerID, _ := event["id"].(string)
+				switch {
+				case status == "Pull complete" || status == "Already exists":
+					logStep(ctx, "", logSourcePull, "%s: %s", layerID, status)
+				case layerID == "" || strings.HasPrefix(status, "Pulling from"):
+					logStep(ctx, "", logSourcePull, "%s", status)
+				}
 
 				// Track layers with IDs (skip metadata messages like "Pulling from ...")
 				if layerID != "" {
@@ -1549,6 +2004,54 @@ func (o *UpdateOrchestrator) pullImage(ctx context.Context, imageRef string, pro
 	return fmt.Errorf("failed to pull image after retries")
 }
 
+// pullCredentialSource is implemented by registry clients that supply credentials
+// for pulls from registries whose logins expire, such as ECR.
+type pullCredentialSource interface {
+	PullCredentials(ctx context.Context, imageRef string) (username, password string, err error)
+}
+
+// pullOptions returns the options for pulling imageRef, with the registry
+// credentials the registry manager supplies for it, if any. Without them the
+// daemon pulls with its own logins, as before.
+func (o *UpdateOrchestrator) pullOptions(ctx context.Context, imageRef string) image.PullOptions {
+	var opts image.PullOptions
+	if o.checker == nil {
+		return opts
+	}
+	source, ok := o.checker.registryManager.(pullCredentialSource)
+	if !ok {
+		return opts
+	}
+	username, password, err := source.PullCredentials(ctx, imageRef)
+	if err != nil {
+		logStep(ctx, "", logSourcePull, "Failed to get registry credentials for %s, pulling with the daemon's: %v", imageRef, err)
+		return opts
+	}
+	if username == "" {
+		return opts
+	}
+	auth, err := dockerregistry.EncodeAuthConfig(dockerregistry.AuthConfig{Username: username, Password: password})
+	if err != nil {
+		logStep(ctx, "", logSourcePull, "Failed to encode registry credentials for %s: %v", imageRef, err)
+		return opts
+	}
+	opts.RegistryAuth = auth
+	return opts
+}
+
+// pullNetworkHint explains where to configure a proxy when a pull fails to reach
+// the registry. Pulls run inside the Docker daemon, so docksmith's own proxy
+// settings (HTTP_PROXY, REGISTRY_PROXIES) do not apply to them.
+func pullNetworkHint(err error) string {
+	errStr := err.Error()
+	for _, marker := range []string{"proxyconnect", "dial tcp", "i/o timeout", "no such host", "Proxy Authentication Required"} {
+		if strings.Contains(errStr, marker) {
+			return " (image pulls use the Docker daemon's proxy settings, see docs/registries.md#http-proxies)"
+		}
+	}
+	return ""
+}
+
 // restartContainerWithDependents recreates a container using docker compose.
 // Note: This does NOT automatically restart dependent containers.
 // Explicit restart dependencies should use the docksmith.restart-after label.
@@ -1584,7 +2087,10 @@ func (o *UpdateOrchestrator) restartContainerWithDependents(ctx context.Context,
 
 		// Recreate the main service (compose file already updated with new image tag)
 		// Use host path for --project-directory and container path for -f
-		if err := recreator.RecreateWithCompose(ctx, targetContainer, hostComposePath, containerComposePath); err != nil {
+		err := o.withUpdateSlot(ctx, func() error {
+			return recreator.RecreateWithCompose(ctx, targetContainer, hostComposePath, containerComposePath)
+		})
+		if err != nil {
 			return nil, fmt.Errorf("compose recreation failed: %w", err)
 		}
 
@@ -1599,13 +2105,6 @@ func (o *UpdateOrchestrator) restartContainerWithDependents(ctx context.Context,
 	return o.restartContainerWithSDK(ctx, operationID, containerName, stackName, newImageRef)
 }
 
-// restartContainerWithSDK is the old SDK-based recreation method (fallback for non-compose containers)
-func (o *UpdateOrchestrator) restartContainerWithSDK(ctx context.Context, operationID, containerName, stackName, newImageRef string) ([]string, error) {
-	// For now, return an error - we're moving to compose-based updates
-	// This fallback can be implemented later if needed for non-compose containers
-	return nil, fmt.Errorf("SDK-based recreation not supported - container must be managed by docker compose")
-}
-
 // hasNetworkModeDependency checks if a container has network_mode: service:* pointing to
 // another container in the batch. If so, it returns true and the name of the dependency.
 // Containers with network_mode dependencies must use compose-based recreation.
@@ -1631,7 +2130,9 @@ func (o *UpdateOrchestrator) recreateContainerWithCompose(ctx context.Context, c
 	}
 
 	recreator := compose.NewRecreator(o.dockerClient)
-	return recreator.RecreateWithCompose(ctx, cont, hostComposePath, containerComposePath)
+	return o.withUpdateSlot(ctx, func() error {
+		return recreator.RecreateWithCompose(ctx, cont, hostComposePath, containerComposePath)
+	})
 }
 
 // DependentRestartResult contains the results of restarting dependent containers
@@ -1805,7 +2306,9 @@ func (o *UpdateOrchestrator) restartDependentContainers(ctx context.Context, con
 			recreator := compose.NewRecreator(o.dockerClient)
 
 			log.Printf("UPDATE: Using compose-based recreation for dependent %s", depName)
-			restartErr = recreator.RecreateWithCompose(ctx, depContainer, hostComposeFilePath, composeFilePath)
+			restartErr = o.withUpdateSlot(ctx, func() error {
+				return recreator.RecreateWithCompose(ctx, depContainer, hostComposeFilePath, composeFilePath)
+			})
 		} else {
 			// Fallback to docker restart for non-compose containers
 			log.Printf("UPDATE: Using docker restart for dependent %s (no compose file)", depName)
@@ -1845,10 +2348,57 @@ func (o *UpdateOrchestrator) restartDependentContainers(ctx context.Context, con
 	return result, nil
 }
 
-// waitForHealthy waits for a container to become healthy or confirms it's running.
+// waitForHealthy waits for a container to become healthy, then runs its
+// docksmith.healthcheck.* probe if one is configured.
+func (o *UpdateOrchestrator) waitForHealthy(ctx context.Context, containerName string, timeout time.Duration) error {
+	if err := o.waitForContainerHealth(ctx, containerName, timeout); err != nil {
+		return err
+	}
+	return o.runHealthProbe(ctx, containerName)
+}
+
+// runHealthProbe runs the HTTP/TCP probe configured by the container's labels.
+// Containers without probe labels pass immediately.
+func (o *UpdateOrchestrator) runHealthProbe(ctx context.Context, containerName string) error {
+	inspect, err := o.dockerSDK.ContainerInspect(ctx, containerName)
+	if err != nil {
+		return fmt.Errorf("failed to inspect container: %w", err)
+	}
+	if inspect.Config == nil {
+		return nil
+	}
+
+	probe, err := ParseHealthProbe(inspect.Config.Labels)
+	if err != nil {
+		return err
+	}
+	if probe == nil {
+		return nil
+	}
+
+	var host string
+	if inspect.NetworkSettings != nil {
+		for _, endpoint := range inspect.NetworkSettings.Networks {
+			if endpoint != nil && endpoint.IPAddress != "" {
+				host = endpoint.IPAddress
+				break
+			}
+		}
+	}
+
+	log.Printf("Running health probe %s for %s", probe, containerName)
+	if err := probe.Run(ctx, host); err != nil {
+		return err
+	}
+	log.Printf("Health probe %s passed for %s", probe, containerName)
+	logStep(ctx, containerName, logSourceHealth, "Health probe %s passed", probe)
+	return nil
+}
+
+// waitForContainerHealth waits for a container to become healthy or confirms it's running.
 // For containers with health checks, polls until status is "healthy" or times out.
 // For containers without health checks, verifies the container is running (fast path).
-func (o *UpdateOrchestrator) waitForHealthy(ctx context.Context, containerName string, timeout time.Duration) error {
+func (o *UpdateOrchestrator) waitForContainerHealth(ctx context.Context, containerName string, timeout time.Duration) error {
 	ctx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 
@@ -1860,6 +2410,9 @@ func (o *UpdateOrchestrator) waitForHealthy(ctx context.Context, containerName s
 	hasHealthCheck := inspect.State != nil && inspect.State.Health != nil
 
 	if hasHealthCheck {
+		logStep(ctx, containerName, logSourceHealth, "Waiting up to %v for %s to become healthy", timeout, containerName)
+		seen := logHealthChecks(ctx, containerName, inspect.State.Health, time.Time{})
+
 		// Check immediately first - container might already be healthy
 		if inspect.State.Health.Status == "healthy" {
 			return nil
@@ -1875,12 +2428,14 @@ func (o *UpdateOrchestrator) waitForHealthy(ctx context.Context, containerName s
 		for {
 			select {
 			case <-ctx.Done():
+				logStep(ctx, containerName, logSourceHealth, "%s did not become healthy within %v", containerName, timeout)
 				return fmt.Errorf("health check timeout")
 			case <-ticker.C:
 				inspect, err := o.dockerSDK.ContainerInspect(ctx, containerName)
 				if err != nil {
 					return fmt.Errorf("failed to inspect container: %w", err)
 				}
+				seen = logHealthChecks(ctx, containerName, inspect.State.Health, seen)
 
 				if inspect.State.Health.Status == "healthy" {
 					return nil
@@ -1909,6 +2464,7 @@ func (o *UpdateOrchestrator) waitForHealthy(ctx context.Context, containerName s
 		for {
 			select {
 			case <-fallbackCtx.Done():
+				logStep(ctx, containerName, logSourceHealth, "%s has no health check and did not start within %v", containerName, o.healthCheckCfg.FallbackWait)
 				return fmt.Errorf("container did not start within timeout")
 			case <-ticker.C:
 				inspect, err := o.dockerSDK.ContainerInspect(ctx, containerName)
@@ -1997,6 +2553,9 @@ func resolveRollbackVersion(detail storage.BatchContainerDetail) (version string
 // RollbackOperation performs a rollback of a previous update operation.
 // Creates a new rollback operation, updates the compose file, and recreates the container.
 func (o *UpdateOrchestrator) RollbackOperation(ctx context.Context, originalOperationID string, force bool) (string, error) {
+	if err := o.checkWritable(); err != nil {
+		return "", err
+	}
 	// Get original operation
 	origOp, found, err := o.storage.GetUpdateOperation(ctx, originalOperationID)
 	if err != nil {
@@ -2039,7 +2598,7 @@ func (o *UpdateOrchestrator) RollbackOperation(ctx context.Context, originalOper
 		// Handle tag/resolved rollbacks via batch pipeline
 		var rollbackOpID string
 		if len(containerNames) > 0 {
-			rollbackOpID, err = o.updateBatchContainersInternal(ctx, containerNames, targetVersions, "rollback", "", nil, nil)
+			rollbackOpID, err = o.updateBatchContainersInternal(ctx, containerNames, targetVersions, "rollback", "", restoreSnapshotsMeta(originalOperationID, containerNames), nil, false)
 			if err != nil {
 				return "", err
 			}
@@ -2070,6 +2629,7 @@ func (o *UpdateOrchestrator) RollbackOperation(ctx context.Context, originalOper
 
 				digestOp := storage.UpdateOperation{
 					OperationID:   digestOpID,
+					TriggeredBy:   TriggerFromContext(ctx),
 					ContainerID:   targetContainer.ID,
 					ContainerName: name,
 					StackName:     detail.StackName,
@@ -2086,6 +2646,7 @@ func (o *UpdateOrchestrator) RollbackOperation(ctx context.Context, originalOper
 					continue
 				}
 
+				detail.RestoreSnapshotsFrom = originalOperationID
 				go o.executeDigestRollback(context.Background(), digestOpID, targetContainer, detail)
 			}
 		}
@@ -2117,6 +2678,11 @@ func (o *UpdateOrchestrator) RollbackOperation(ctx context.Context, originalOper
 		return "", fmt.Errorf("container %s not found", origOp.ContainerName)
 	}
 
+	// Rebuilds record image IDs rather than tags
+	if origOp.OperationType == "rebuild" {
+		return o.rollbackRebuild(ctx, origOp, targetContainer)
+	}
+
 	// Use the old version from the database - no backup file needed
 	targetVersion := origOp.OldVersion
 	if targetVersion == "" {
@@ -2157,6 +2723,7 @@ func (o *UpdateOrchestrator) RollbackOperation(ctx context.Context, originalOper
 	rollbackOpID := uuid.New().String()
 	rollbackOp := storage.UpdateOperation{
 		OperationID:    rollbackOpID,
+		TriggeredBy:    TriggerFromContext(ctx),
 		ContainerID:    targetContainer.ID,
 		ContainerName:  origOp.ContainerName,
 		StackName:      origOp.StackName,
@@ -2202,6 +2769,9 @@ func (o *UpdateOrchestrator) findDependentContainerNames(containers []docker.Con
 // It filters the original operation's batch_details to only the requested container names,
 // then creates a new rollback operation for those containers.
 func (o *UpdateOrchestrator) RollbackContainers(ctx context.Context, operationID string, containerNames []string, force bool) (string, error) {
+	if err := o.checkWritable(); err != nil {
+		return "", err
+	}
 	if o.storage == nil {
 		return "", fmt.Errorf("storage not available")
 	}
@@ -2262,7 +2832,7 @@ func (o *UpdateOrchestrator) RollbackContainers(ctx context.Context, operationID
 	// Handle tag/resolved rollbacks via batch pipeline
 	var rollbackOpID string
 	if len(rollbackNames) > 0 {
-		rollbackOpID, err = o.updateBatchContainersInternal(ctx, rollbackNames, targetVersions, "rollback", "", nil, nil)
+		rollbackOpID, err = o.updateBatchContainersInternal(ctx, rollbackNames, targetVersions, "rollback", "", restoreSnapshotsMeta(operationID, rollbackNames), nil, false)
 		if err != nil {
 			return "", err
 		}
@@ -2293,6 +2863,7 @@ func (o *UpdateOrchestrator) RollbackContainers(ctx context.Context, operationID
 
 			digestOp := storage.UpdateOperation{
 				OperationID:   digestOpID,
+				TriggeredBy:   TriggerFromContext(ctx),
 				ContainerID:   targetContainer.ID,
 				ContainerName: name,
 				StackName:     detail.StackName,
@@ -2309,6 +2880,7 @@ func (o *UpdateOrchestrator) RollbackContainers(ctx context.Context, operationID
 				continue
 			}
 
+			detail.RestoreSnapshotsFrom = operationID
 			go o.executeDigestRollback(context.Background(), digestOpID, targetContainer, detail)
 		}
 	}
@@ -2324,6 +2896,8 @@ func (o *UpdateOrchestrator) RollbackContainers(ctx context.Context, operationID
 
 // executeRollback performs the actual rollback process using the old version from database
 func (o *UpdateOrchestrator) executeRollback(ctx context.Context, rollbackOpID, originalOpID string, container *docker.Container, oldVersion string, force bool) {
+	ctx = o.withOperationLog(ctx, rollbackOpID)
+
 	stackName := container.Labels["com.docker.compose.project"]
 
 	// Stage 1: Update compose file with old version (10-20%)
@@ -2377,6 +2951,11 @@ func (o *UpdateOrchestrator) executeRollback(ctx context.Context, rollbackOpID,
 		log.Printf("ROLLBACK: Warning - failed to pull old image: %v (may already exist locally)", err)
 	}
 
+	if err := o.restoreVolumes(ctx, rollbackOpID, originalOpID, container, stackName); err != nil {
+		o.failOperation(ctx, rollbackOpID, "restoring_volumes", fmt.Sprintf("Volume restore failed: %v", err))
+		return
+	}
+
 	// Stage 4: Recreate container with old image (60-80%)
 	o.publishProgress(rollbackOpID, container.Name, stackName, "recreating", 60, "Recreating container with old image")
 
@@ -2447,6 +3026,8 @@ func (o *UpdateOrchestrator) executeRollback(ctx context.Context, rollbackOpID,
 // This is used when the tag hasn't changed (e.g., :latest, REBUILD) but we have the old digest.
 // It pulls the old image by digest, re-tags it as the current tag, then recreates the container.
 func (o *UpdateOrchestrator) executeDigestRollback(ctx context.Context, rollbackOpID string, container *docker.Container, detail storage.BatchContainerDetail) {
+	ctx = o.withOperationLog(ctx, rollbackOpID)
+
 	stackName := container.Labels["com.docker.compose.project"]
 
 	// Extract repo and tag from image, handling registry ports correctly
@@ -2495,6 +3076,11 @@ func (o *UpdateOrchestrator) executeDigestRollback(ctx context.Context, rollback
 		return
 	}
 
+	if err := o.restoreVolumes(ctx, rollbackOpID, detail.RestoreSnapshotsFrom, container, stackName); err != nil {
+		o.failOperation(ctx, rollbackOpID, "restoring_volumes", fmt.Sprintf("Volume restore failed: %v", err))
+		return
+	}
+
 	// Stage 3: Recreate container (60-80%) — compose sees the local image with the right tag
 	o.publishProgress(rollbackOpID, container.Name, stackName, "recreating", 60, "Recreating container with old image")
 
@@ -2551,6 +3137,9 @@ func (o *UpdateOrchestrator) executeDigestRollback(ctx context.Context, rollback
 // FixComposeMismatch fixes a container where the running image doesn't match the compose file.
 // This pulls the image specified in the compose file and recreates the container.
 func (o *UpdateOrchestrator) FixComposeMismatch(ctx context.Context, containerName string) (string, error) {
+	if err := o.checkWritable(); err != nil {
+		return "", err
+	}
 	operationID := uuid.New().String()
 
 	containers, err := o.dockerClient.ListContainers(ctx)
@@ -2583,7 +3172,7 @@ func (o *UpdateOrchestrator) FixComposeMismatch(ctx context.Context, containerNa
 
 	stackName := o.stackManager.DetermineStack(ctx, *targetContainer)
 
-	if !o.acquireStackLock(stackName) {
+	if !o.acquireStackLock(stackName, operationID) {
 		if err := o.queueOperation(ctx, operationID, stackName, []string{containerName}, "fix_mismatch", nil); err != nil {
 			return "", fmt.Errorf("failed to queue operation: %w", err)
 		}
@@ -2593,26 +3182,26 @@ func (o *UpdateOrchestrator) FixComposeMismatch(ctx context.Context, containerNa
 	// Get service name from labels
 	serviceName := targetContainer.Labels["com.docker.compose.service"]
 	if serviceName == "" {
-		o.releaseStackLock(stackName)
+		o.releaseStackLock(stackName, operationID)
 		return "", fmt.Errorf("container %s has no compose service label", containerName)
 	}
 
 	// Load compose file (handles include directives for multi-file stacks)
 	cf, err := compose.LoadComposeFileOrIncluded(resolvedPath, serviceName)
 	if err != nil {
-		o.releaseStackLock(stackName)
+		o.releaseStackLock(stackName, operationID)
 		return "", fmt.Errorf("failed to load compose file: %w", err)
 	}
 
 	svc, err := cf.FindServiceByContainerName(serviceName)
 	if err != nil {
-		o.releaseStackLock(stackName)
+		o.releaseStackLock(stackName, operationID)
 		return "", fmt.Errorf("service %s not found in compose file: %w", serviceName, err)
 	}
 
 	expectedImage := compose.GetServiceImage(svc)
 	if expectedImage == "" {
-		o.releaseStackLock(stackName)
+		o.releaseStackLock(stackName, operationID)
 		return "", fmt.Errorf("no image key found for service %s", serviceName)
 	}
 
@@ -2630,6 +3219,7 @@ func (o *UpdateOrchestrator) FixComposeMismatch(ctx context.Context, containerNa
 
 	op := storage.UpdateOperation{
 		OperationID:   operationID,
+		TriggeredBy:   TriggerFromContext(ctx),
 		ContainerID:   targetContainer.ID,
 		ContainerName: containerName,
 		StackName:     stackName,
@@ -2641,7 +3231,7 @@ func (o *UpdateOrchestrator) FixComposeMismatch(ctx context.Context, containerNa
 
 	if o.storage != nil {
 		if err := o.storage.SaveUpdateOperation(ctx, op); err != nil {
-			o.releaseStackLock(stackName)
+			o.releaseStackLock(stackName, operationID)
 			return "", fmt.Errorf("failed to save operation: %w", err)
 		}
 	}
@@ -2655,7 +3245,9 @@ func (o *UpdateOrchestrator) FixComposeMismatch(ctx context.Context, containerNa
 // If the compose tag no longer exists on the registry, it falls back to updating the compose
 // file to match the running container image (resolving the mismatch in the other direction).
 func (o *UpdateOrchestrator) executeFixMismatch(ctx context.Context, operationID string, container *docker.Container, expectedImage, stackName, composeFilePath string) {
-	defer o.releaseStackLock(stackName)
+	ctx = o.withOperationLog(ctx, operationID)
+
+	defer o.releaseStackLock(stackName, operationID)
 
 	log.Printf("FIX_MISMATCH: Starting fix for operation=%s container=%s expected=%s", operationID, container.Name, expectedImage)
 
@@ -2844,8 +3436,10 @@ func (o *UpdateOrchestrator) buildImageRef(currentImage, targetVersion string) s
 	return currentImage + ":" + targetVersion
 }
 
-// publishProgress publishes progress events to the event bus for UI updates.
+// publishProgress publishes progress events to the event bus for UI updates
+// and records them in the step log of the operation.
 func (o *UpdateOrchestrator) publishProgress(operationID, containerName, stackName, stage string, percent int, message string) {
+	o.logProgress(operationID, containerName, stage, message)
 	if o.eventBus == nil {
 		log.Printf("PROGRESS: eventBus is nil, skipping publish for operation=%s stage=%s", operationID, stage)
 		return
@@ -2866,8 +3460,15 @@ func (o *UpdateOrchestrator) publishProgress(operationID, containerName, stackNa
 	})
 }
 
-// failOperation marks an operation as failed.
+// failOperation marks an operation as failed. It is recorded even if ctx was
+// canceled, e.g. because a target container was removed.
 func (o *UpdateOrchestrator) failOperation(ctx context.Context, operationID, stage, errorMsg string) {
+	// A removed container explains a canceled step better than the step's own error
+	if cause := context.Cause(ctx); errors.Is(cause, ErrContainerRemoved) {
+		errorMsg = cause.Error()
+	}
+	ctx = context.WithoutCancel(ctx)
+
 	o.storage.UpdateOperationStatus(ctx, operationID, "failed", errorMsg)
 	o.publishProgress(operationID, "", "", "failed", 0, errorMsg)
 
@@ -2887,107 +3488,15 @@ func (o *UpdateOrchestrator) failOperation(ctx context.Context, operationID, sta
 					"container_id":   op.ContainerID,
 					"container_name": op.ContainerName,
 					"operation_id":   operationID,
+					"operation_type": op.OperationType,
 					"status":         "failed",
+					"error":          errorMsg,
 				},
 			})
 		}
 	}
 }
 
-// acquireStackLock attempts to acquire a lock for a stack.
-func (o *UpdateOrchestrator) acquireStackLock(stackName string) bool {
-	o.locksMu.Lock()
-	entry, exists := o.stackLocks[stackName]
-	if !exists {
-		entry = &stackLockEntry{}
-		o.stackLocks[stackName] = entry
-	}
-	o.locksMu.Unlock()
-
-	locked := entry.mu.TryLock()
-	if locked {
-		o.locksMu.Lock()
-		entry.lastUsed = time.Now()
-		o.locksMu.Unlock()
-	}
-	return locked
-}
-
-// releaseStackLock releases a stack lock.
-func (o *UpdateOrchestrator) releaseStackLock(stackName string) {
-	o.locksMu.Lock()
-	entry, exists := o.stackLocks[stackName]
-	o.locksMu.Unlock()
-
-	if exists {
-		entry.mu.Unlock()
-	}
-}
-
-// cleanupStaleLocks periodically removes stack locks that haven't been used recently.
-// This prevents unbounded memory growth from accumulating locks for stacks that no longer exist.
-func (o *UpdateOrchestrator) cleanupStaleLocks(ctx context.Context) {
-	const (
-		cleanupInterval = 10 * time.Minute
-		staleThreshold  = 30 * time.Minute
-	)
-
-	ticker := time.NewTicker(cleanupInterval)
-	defer ticker.Stop()
-
-	for {
-		select {
-		case <-ctx.Done():
-			return
-		case <-ticker.C:
-			o.locksMu.Lock()
-			now := time.Now()
-			for stackName, entry := range o.stackLocks {
-				// Only remove if lock is not held and hasn't been used recently
-				if entry.mu.TryLock() {
-					if now.Sub(entry.lastUsed) > staleThreshold {
-						delete(o.stackLocks, stackName)
-						log.Printf("CLEANUP: Removed stale stack lock for %s", stackName)
-					}
-					entry.mu.Unlock()
-				}
-			}
-			o.locksMu.Unlock()
-		}
-	}
-}
-
-// queueOperation adds an operation to the queue.
-func (o *UpdateOrchestrator) queueOperation(ctx context.Context, operationID, stackName string, containers []string, operationType string, targetVersions map[string]string) error {
-	queue := storage.UpdateQueue{
-		OperationID:    operationID,
-		StackName:      stackName,
-		Containers:     containers,
-		OperationType:  operationType,
-		TargetVersions: targetVersions,
-		QueuedAt:       time.Now(),
-	}
-
-	if err := o.storage.QueueUpdate(ctx, queue); err != nil {
-		return err
-	}
-
-	// Check if an operation record already exists (e.g., saved by the caller with full details).
-	// Only create a sparse record if none exists, to avoid overwriting detailed metadata.
-	if existing, found, _ := o.storage.GetUpdateOperation(ctx, operationID); found {
-		existing.Status = "queued"
-		return o.storage.SaveUpdateOperation(ctx, existing)
-	}
-
-	op := storage.UpdateOperation{
-		OperationID:   operationID,
-		StackName:     stackName,
-		OperationType: operationType,
-		Status:        "queued",
-	}
-	return o.storage.SaveUpdateOperation(ctx, op)
-}
-
 // processQueue processes queued operations in the background.
 func (o *UpdateOrchestrator) processQueue(ctx context.Context) {
 	// Recover from panics to prevent queue processor from dying silently
@@ -3021,24 +3530,25 @@ func (o *UpdateOrchestrator) processQueue(ctx context.Context) {
 			}
 
 			for _, q := range queued {
-				if o.acquireStackLock(q.StackName) {
+				if o.acquireStackLock(q.StackName, q.OperationID) {
 					if _, dequeued, deqErr := o.storage.DequeueUpdate(ctx, q.StackName); deqErr != nil || !dequeued {
 						log.Printf("QUEUE: Failed to dequeue operation %s (err=%v, dequeued=%v), releasing lock", q.OperationID, deqErr, dequeued)
-						o.releaseStackLock(q.StackName)
+						o.releaseStackLock(q.StackName, q.OperationID)
 						continue
 					}
+					o.publishQueueChanged(QueueActionStarted, q.StackName, q.OperationID)
 
 					_, found, opErr := o.storage.GetUpdateOperation(ctx, q.OperationID)
 					if !found {
 						log.Printf("QUEUE: Operation %s not found (err=%v), releasing stack lock", q.OperationID, opErr)
-						o.releaseStackLock(q.StackName)
+						o.releaseStackLock(q.StackName, q.OperationID)
 						continue
 					}
 					{
 						containers, listErr := o.dockerClient.ListContainers(ctx)
 						if listErr != nil {
 							log.Printf("QUEUE: Failed to list containers for operation %s: %v", q.OperationID, listErr)
-							o.releaseStackLock(q.StackName)
+							o.releaseStackLock(q.StackName, q.OperationID)
 							o.failOperation(ctx, q.OperationID, "queued", fmt.Sprintf("Failed to list containers: %v", listErr))
 							continue
 						}
@@ -3054,7 +3564,7 @@ func (o *UpdateOrchestrator) processQueue(ctx context.Context) {
 
 						if len(targetContainers) == 0 {
 							log.Printf("QUEUE: No matching containers found for operation %s, releasing lock", q.OperationID)
-							o.releaseStackLock(q.StackName)
+							o.releaseStackLock(q.StackName, q.OperationID)
 							o.failOperation(ctx, q.OperationID, "queued", "Queued containers no longer exist")
 							continue
 						}
@@ -3069,12 +3579,20 @@ func (o *UpdateOrchestrator) processQueue(ctx context.Context) {
 								levels := o.computeStackRestartLevels(targetContainers)
 								go o.executeStackRestart(opCtx, q.OperationID, targetContainers, levels, q.StackName, false)
 							}
+						case "rebuild":
+							if len(targetContainers) == 1 {
+								go o.executeRebuild(opCtx, q.OperationID, targetContainers[0], q.StackName, false)
+							} else {
+								log.Printf("QUEUE: rebuild with multiple containers not supported, operation %s", q.OperationID)
+								o.releaseStackLock(q.StackName, q.OperationID)
+								o.failOperation(ctx, q.OperationID, "queued", "rebuild only supports single containers")
+							}
 						case "fix_mismatch":
 							if len(targetContainers) == 1 {
 								expectedImage, err := o.deriveExpectedImage(targetContainers[0])
 								if err != nil {
 									log.Printf("QUEUE: Failed to derive expected image for fix_mismatch %s: %v", q.OperationID, err)
-									o.releaseStackLock(q.StackName)
+									o.releaseStackLock(q.StackName, q.OperationID)
 									o.failOperation(ctx, q.OperationID, "queued", fmt.Sprintf("Failed to derive expected image: %v", err))
 									continue
 								}
@@ -3082,14 +3600,14 @@ func (o *UpdateOrchestrator) processQueue(ctx context.Context) {
 								qResolvedPath, resolveErr := o.resolveComposeFile(qComposePath)
 								if resolveErr != nil {
 									log.Printf("QUEUE: Failed to resolve compose file for fix_mismatch %s: %v", q.OperationID, resolveErr)
-									o.releaseStackLock(q.StackName)
+									o.releaseStackLock(q.StackName, q.OperationID)
 									o.failOperation(ctx, q.OperationID, "queued", fmt.Sprintf("Failed to resolve compose file: %v", resolveErr))
 									continue
 								}
 								go o.executeFixMismatch(opCtx, q.OperationID, targetContainers[0], expectedImage, q.StackName, qResolvedPath)
 							} else {
 								log.Printf("QUEUE: fix_mismatch with multiple containers not supported, operation %s", q.OperationID)
-								o.releaseStackLock(q.StackName)
+								o.releaseStackLock(q.StackName, q.OperationID)
 								o.failOperation(ctx, q.OperationID, "queued", "fix_mismatch only supports single containers")
 							}
 						case "rollback":
@@ -3097,7 +3615,7 @@ func (o *UpdateOrchestrator) processQueue(ctx context.Context) {
 							op, opFound, opErr := o.storage.GetUpdateOperation(ctx, q.OperationID)
 							if opErr != nil || !opFound {
 								log.Printf("QUEUE: Failed to recover rollback operation %s: %v", q.OperationID, opErr)
-								o.releaseStackLock(q.StackName)
+								o.releaseStackLock(q.StackName, q.OperationID)
 								o.failOperation(ctx, q.OperationID, "queued", "Failed to recover rollback details")
 								continue
 							}
@@ -3107,7 +3625,7 @@ func (o *UpdateOrchestrator) processQueue(ctx context.Context) {
 									targetVersions[detail.ContainerName] = detail.NewVersion
 								}
 							}
-							go o.executeBatchUpdate(opCtx, q.OperationID, targetContainers, targetVersions, q.StackName, nil)
+							go o.executeBatchUpdate(opCtx, q.OperationID, targetContainers, targetVersions, q.StackName, nil, false)
 						default: // "single", "batch", "stack"
 							if len(targetContainers) == 1 {
 								tv := "latest"
@@ -3116,7 +3634,7 @@ func (o *UpdateOrchestrator) processQueue(ctx context.Context) {
 								}
 								go o.executeSingleUpdate(opCtx, q.OperationID, targetContainers[0], tv, q.StackName, false)
 							} else {
-								go o.executeBatchUpdate(opCtx, q.OperationID, targetContainers, q.TargetVersions, q.StackName, nil)
+								go o.executeBatchUpdate(opCtx, q.OperationID, targetContainers, q.TargetVersions, q.StackName, nil, q.AllOrNothing)
 							}
 						}
 					}
@@ -3157,27 +3675,12 @@ func (o *UpdateOrchestrator) deriveExpectedImage(container *docker.Container) (s
 	return img, nil
 }
 
-// CancelQueuedOperation cancels a queued operation.
-func (o *UpdateOrchestrator) CancelQueuedOperation(ctx context.Context, operationID string) error {
-	op, found, err := o.storage.GetUpdateOperation(ctx, operationID)
-	if err != nil {
-		return err
-	}
-
-	if !found {
-		return fmt.Errorf("operation not found")
-	}
-
-	if op.Status != "queued" {
-		return fmt.Errorf("operation is not queued (status: %s)", op.Status)
-	}
-
-	return o.storage.UpdateOperationStatus(ctx, operationID, "cancelled", "Cancelled by user")
-}
-
 // RestartSingleContainer initiates a restart for a single container with SSE progress events.
 // This is the main entry point for restarting containers via the API.
 func (o *UpdateOrchestrator) RestartSingleContainer(ctx context.Context, containerName string, force bool) (string, error) {
+	if err := o.checkWritable(); err != nil {
+		return "", err
+	}
 	operationID := uuid.New().String()
 
 	containers, err := o.dockerClient.ListContainers(ctx)
@@ -3205,6 +3708,7 @@ func (o *UpdateOrchestrator) RestartSingleContainer(ctx context.Context, contain
 	// Create operation record
 	op := storage.UpdateOperation{
 		OperationID:        operationID,
+		TriggeredBy:        TriggerFromContext(ctx),
 		ContainerID:        targetContainer.ID,
 		ContainerName:      containerName,
 		StackName:          stackName,
@@ -3219,7 +3723,7 @@ func (o *UpdateOrchestrator) RestartSingleContainer(ctx context.Context, contain
 	}
 
 	// Check if stack is locked
-	if stackName != "" && !o.acquireStackLock(stackName) {
+	if stackName != "" && !o.acquireStackLock(stackName, operationID) {
 		// Queue the operation
 		if err := o.queueOperation(ctx, operationID, stackName, []string{containerName}, "restart", nil); err != nil {
 			return "", fmt.Errorf("failed to queue operation: %w", err)
@@ -3236,8 +3740,10 @@ func (o *UpdateOrchestrator) RestartSingleContainer(ctx context.Context, contain
 
 // executeRestart performs the actual restart process with SSE progress events.
 func (o *UpdateOrchestrator) executeRestart(ctx context.Context, operationID string, container *docker.Container, stackName string, force bool) {
+	ctx = o.withOperationLog(ctx, operationID)
+
 	if stackName != "" {
-		defer o.releaseStackLock(stackName)
+		defer o.releaseStackLock(stackName, operationID)
 	}
 
 	log.Printf("RESTART: Starting executeRestart for operation=%s container=%s", operationID, container.Name)
@@ -3419,6 +3925,9 @@ func (o *UpdateOrchestrator) executeRestart(ctx context.Context, operationID str
 // It builds a dependency graph to determine restart order, groups containers into
 // parallelizable levels, and restarts level by level.
 func (o *UpdateOrchestrator) RestartStack(ctx context.Context, stackName string, containerNames []string, force bool) (string, error) {
+	if err := o.checkWritable(); err != nil {
+		return "", err
+	}
 	operationID := uuid.New().String()
 
 	containers, err := o.dockerClient.ListContainers(ctx)
@@ -3458,6 +3967,7 @@ func (o *UpdateOrchestrator) RestartStack(ctx context.Context, stackName string,
 	// Create a single operation for the entire stack restart
 	op := storage.UpdateOperation{
 		OperationID:   operationID,
+		TriggeredBy:   TriggerFromContext(ctx),
 		ContainerName: stackName,
 		StackName:     stackName,
 		OperationType: "restart",
@@ -3471,7 +3981,7 @@ func (o *UpdateOrchestrator) RestartStack(ctx context.Context, stackName string,
 	}
 
 	// Acquire stack lock
-	if !o.acquireStackLock(stackName) {
+	if !o.acquireStackLock(stackName, operationID) {
 		if err := o.queueOperation(ctx, operationID, stackName, containerNames, "restart", nil); err != nil {
 			return "", fmt.Errorf("failed to queue operation: %w", err)
 		}
@@ -3601,7 +4111,9 @@ func (o *UpdateOrchestrator) computeStackRestartLevels(stackContainers []*docker
 
 // executeStackRestart runs the stack restart in background, level by level.
 func (o *UpdateOrchestrator) executeStackRestart(ctx context.Context, operationID string, containers []*docker.Container, levels [][]string, stackName string, force bool) {
-	defer o.releaseStackLock(stackName)
+	ctx = o.withOperationLog(ctx, operationID)
+
+	defer o.releaseStackLock(stackName, operationID)
 
 	log.Printf("STACK-RESTART: Starting stack restart for %s with %d container(s) in %d level(s)", stackName, len(containers), len(levels))
 
@@ -3826,8 +4338,68 @@ func (o *UpdateOrchestrator) updateBatchDetailStatus(ctx context.Context, operat
 	o.publishProgress(operationID, containerName, stackName, status, 0, message)
 }
 
+// runPostUpdateCheck runs the container's docksmith.post-update-check script, if any,
+// and stores its output on the operation record.
+func (o *UpdateOrchestrator) runPostUpdateCheck(ctx context.Context, operationID string, container *docker.Container) error {
+	scriptPath := container.Labels[scripts.PostUpdateCheckLabel]
+	if scriptPath == "" {
+		return nil
+	}
+
+	log.Printf("UPDATE: Running post-update check for container %s: %s", container.Name, scriptPath)
+	output, err := scripts.ExecutePostUpdateCheck(ctx, container, scriptPath)
+	logStep(ctx, container.Name, logSourceScript, "%s", commandLog(scriptPath, []byte(output), err))
+
+	o.batchDetailMu.Lock()
+	if op, found, _ := o.storage.GetUpdateOperation(ctx, operationID); found {
+		// Batch operations keep the output of every container's script
+		if len(op.BatchDetails) > 1 {
+			output = fmt.Sprintf("==> %s <==\n%s", container.Name, output)
+		}
+		if op.CheckOutput != "" {
+			output = op.CheckOutput + "\n" + output
+		}
+		op.CheckOutput = output
+		o.storage.SaveUpdateOperation(ctx, op)
+	}
+	o.batchDetailMu.Unlock()
+
+	if err != nil {
+		return err
+	}
+	log.Printf("UPDATE: Post-update check passed for container %s", container.Name)
+	return nil
+}
+
+// autoRollback rolls back a failed update when the rollback policy for the
+// container (label, stack or global) enables it.
+func (o *UpdateOrchestrator) autoRollback(ctx context.Context, operationID, containerName string) {
+	enabled, err := o.shouldAutoRollback(ctx, containerName)
+	if err != nil {
+		log.Printf("UPDATE: Failed to resolve rollback policy for %s: %v", containerName, err)
+		return
+	}
+	if !enabled {
+		return
+	}
+
+	log.Printf("UPDATE: Auto-rollback enabled for %s, rolling back operation=%s", containerName, operationID)
+	rollbackOpID, err := o.RollbackOperation(WithTrigger(ctx, TriggerAutoRollback), operationID, true)
+	if err != nil {
+		log.Printf("UPDATE: Auto-rollback failed for %s: %v", containerName, err)
+		return
+	}
+	log.Printf("UPDATE: Started auto-rollback operation=%s for %s", rollbackOpID, containerName)
+}
+
 // runPreUpdateCheck runs a pre-update check script for a container
 func runPreUpdateCheck(ctx context.Context, container *docker.Container, scriptPath string) error {
 	// Use shared implementation with path translation disabled (orchestrator runs in container)
-	return scripts.ExecutePreUpdateCheck(ctx, container, scriptPath, false)
+	err := scripts.ExecutePreUpdateCheck(ctx, container, scriptPath, false)
+	if err != nil {
+		logStep(ctx, container.Name, logSourceScript, "Pre-update check %s failed: %v", scriptPath, err)
+	} else {
+		logStep(ctx, container.Name, logSourceScript, "Pre-update check %s passed", scriptPath)
+	}
+	return err
 }
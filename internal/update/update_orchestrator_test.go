@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -13,7 +14,9 @@ import (
 	"github.com/chis/docksmith/internal/events"
 	"github.com/chis/docksmith/internal/graph"
 	"github.com/chis/docksmith/internal/storage"
+	dockerregistry "github.com/docker/docker/api/types/registry"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // TestMockStorage is a mock storage implementation for orchestrator testing.
@@ -96,6 +99,21 @@ func (m *TestMockStorage) GetUpdateOperationsByBatchGroup(ctx context.Context, b
 	return ops, nil
 }
 
+func (m *TestMockStorage) GetUnfinishedOperations(ctx context.Context) ([]storage.UpdateOperation, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var result []storage.UpdateOperation
+	for _, op := range m.operations {
+		switch op.Status {
+		case storage.StatusComplete, storage.StatusFailed, "cancelled":
+		default:
+			result = append(result, op)
+		}
+	}
+	return result, nil
+}
+
 func (m *TestMockStorage) UpdateOperationStatus(ctx context.Context, operationID string, status string, errorMsg string) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -248,6 +266,258 @@ func (m *TestMockStorage) Close() error {
 	return nil
 }
 
+func (m *TestMockStorage) CreateUser(ctx context.Context, user storage.User) (storage.User, error) {
+	return user, nil
+}
+
+func (m *TestMockStorage) GetUser(ctx context.Context, username string) (storage.User, bool, error) {
+	return storage.User{}, false, nil
+}
+
+func (m *TestMockStorage) GetUserByID(ctx context.Context, id int64) (storage.User, bool, error) {
+	return storage.User{}, false, nil
+}
+
+func (m *TestMockStorage) ListUsers(ctx context.Context) ([]storage.User, error) {
+	return nil, nil
+}
+
+func (m *TestMockStorage) UpdateUser(ctx context.Context, user storage.User) error {
+	return nil
+}
+
+func (m *TestMockStorage) DeleteUser(ctx context.Context, username string) error {
+	return nil
+}
+
+func (m *TestMockStorage) SaveSession(ctx context.Context, session storage.Session) error {
+	return nil
+}
+
+func (m *TestMockStorage) GetSession(ctx context.Context, tokenHash string) (storage.Session, bool, error) {
+	return storage.Session{}, false, nil
+}
+
+func (m *TestMockStorage) DeleteSession(ctx context.Context, tokenHash string) error {
+	return nil
+}
+
+func (m *TestMockStorage) DeleteExpiredSessions(ctx context.Context, now time.Time) (int64, error) {
+	return 0, nil
+}
+
+func (m *TestMockStorage) SaveApproval(ctx context.Context, approval storage.Approval) error {
+	return nil
+}
+
+func (m *TestMockStorage) GetApproval(ctx context.Context, id string) (storage.Approval, bool, error) {
+	return storage.Approval{}, false, nil
+}
+
+func (m *TestMockStorage) GetLatestApproval(ctx context.Context, containerName string) (storage.Approval, bool, error) {
+	return storage.Approval{}, false, nil
+}
+
+func (m *TestMockStorage) ListApprovals(ctx context.Context, status string, limit int) ([]storage.Approval, error) {
+	return nil, nil
+}
+
+func (m *TestMockStorage) ExpireApprovals(ctx context.Context, now time.Time) (int64, error) {
+	return 0, nil
+}
+
+func (m *TestMockStorage) SaveProposal(ctx context.Context, proposal storage.Proposal) error {
+	return nil
+}
+
+func (m *TestMockStorage) GetProposal(ctx context.Context, id string) (storage.Proposal, bool, error) {
+	return storage.Proposal{}, false, nil
+}
+
+func (m *TestMockStorage) GetLatestProposal(ctx context.Context, containerName string) (storage.Proposal, bool, error) {
+	return storage.Proposal{}, false, nil
+}
+
+func (m *TestMockStorage) ListProposals(ctx context.Context, status string, limit int) ([]storage.Proposal, error) {
+	return nil, nil
+}
+
+func (m *TestMockStorage) GetTagCache(ctx context.Context, imageRef string) (storage.TagCacheEntry, bool, error) {
+	return storage.TagCacheEntry{}, false, nil
+}
+
+func (m *TestMockStorage) SaveTagCache(ctx context.Context, entry storage.TagCacheEntry) error {
+	return nil
+}
+
+func (m *TestMockStorage) GetCheckFingerprint(ctx context.Context, containerName string) (storage.CheckFingerprint, bool, error) {
+	return storage.CheckFingerprint{}, false, nil
+}
+
+func (m *TestMockStorage) SaveCheckFingerprint(ctx context.Context, fingerprint storage.CheckFingerprint) error {
+	return nil
+}
+
+func (m *TestMockStorage) QueryCheckHistory(ctx context.Context, opts storage.CheckHistoryQueryOptions) ([]storage.CheckHistoryEntry, error) {
+	return nil, nil
+}
+
+func (m *TestMockStorage) ListRollbackPolicies(ctx context.Context) ([]storage.RollbackPolicy, error) {
+	return nil, nil
+}
+
+func (m *TestMockStorage) Backup(ctx context.Context, destPath string) error {
+	return nil
+}
+
+func (m *TestMockStorage) Vacuum(ctx context.Context) (storage.VacuumResult, error) {
+	return storage.VacuumResult{}, nil
+}
+
+func (m *TestMockStorage) PruneHistory(ctx context.Context, opts storage.PruneOptions) (storage.PruneResult, error) {
+	return storage.PruneResult{}, nil
+}
+
+func (m *TestMockStorage) ListComposeBackups(ctx context.Context) ([]storage.ComposeBackup, error) {
+	return nil, nil
+}
+
+func (m *TestMockStorage) DeleteComposeBackups(ctx context.Context, ids []int64) (int64, error) {
+	return 0, nil
+}
+
+func (m *TestMockStorage) SaveScriptRevision(ctx context.Context, revision storage.ScriptRevision) error {
+	return nil
+}
+
+func (m *TestMockStorage) GetScriptRevision(ctx context.Context, name string, revision int) (storage.ScriptRevision, bool, error) {
+	return storage.ScriptRevision{}, false, nil
+}
+
+func (m *TestMockStorage) ListScriptRevisions(ctx context.Context, name string) ([]storage.ScriptRevision, error) {
+	return nil, nil
+}
+
+func (m *TestMockStorage) SaveVolumeSnapshot(ctx context.Context, snapshot storage.VolumeSnapshot) error {
+	return nil
+}
+
+func (m *TestMockStorage) GetVolumeSnapshots(ctx context.Context, operationID string) ([]storage.VolumeSnapshot, error) {
+	return nil, nil
+}
+
+func (m *TestMockStorage) ListVolumeSnapshots(ctx context.Context, volumeName string) ([]storage.VolumeSnapshot, error) {
+	return nil, nil
+}
+
+func (m *TestMockStorage) DeleteVolumeSnapshot(ctx context.Context, id int64) error {
+	return nil
+}
+
+func (m *TestMockStorage) GetApprovalPolicy(ctx context.Context, entityType, entityID string) (storage.ApprovalPolicy, bool, error) {
+	return storage.ApprovalPolicy{}, false, nil
+}
+
+func (m *TestMockStorage) SetApprovalPolicy(ctx context.Context, policy storage.ApprovalPolicy) error {
+	return nil
+}
+
+func (m *TestMockStorage) ListApprovalPolicies(ctx context.Context) ([]storage.ApprovalPolicy, error) {
+	return nil, nil
+}
+
+func (m *TestMockStorage) DeleteApprovalPolicy(ctx context.Context, entityType, entityID string) error {
+	return nil
+}
+
+func (m *TestMockStorage) AppendOperationLog(ctx context.Context, entry storage.OperationLogEntry) (int64, error) {
+	return 0, nil
+}
+
+func (m *TestMockStorage) GetOperationLogs(ctx context.Context, operationID string, afterID int64) ([]storage.OperationLogEntry, error) {
+	return nil, nil
+}
+
+func (m *TestMockStorage) AppendComposeOutput(ctx context.Context, operationID string, output string) error {
+	return nil
+}
+
+func (m *TestMockStorage) ListIgnoreRules(ctx context.Context) ([]storage.IgnoreRule, error) {
+	return nil, nil
+}
+
+func (m *TestMockStorage) SaveIgnoreRule(ctx context.Context, rule storage.IgnoreRule) (storage.IgnoreRule, error) {
+	return rule, nil
+}
+
+func (m *TestMockStorage) DeleteIgnoreRule(ctx context.Context, id int64) (bool, error) {
+	return false, nil
+}
+
+func (m *TestMockStorage) GetStackEnv(ctx context.Context, stack string) (storage.StackEnv, bool, error) {
+	return storage.StackEnv{}, false, nil
+}
+
+func (m *TestMockStorage) SetStackEnv(ctx context.Context, env storage.StackEnv) error {
+	return nil
+}
+
+func (m *TestMockStorage) ListStackEnvs(ctx context.Context) ([]storage.StackEnv, error) {
+	return nil, nil
+}
+
+func (m *TestMockStorage) DeleteStackEnv(ctx context.Context, stack string) (bool, error) {
+	return false, nil
+}
+
+func (m *TestMockStorage) ListOwnerships(ctx context.Context) ([]storage.Ownership, error) {
+	return nil, nil
+}
+
+func (m *TestMockStorage) AddOwnership(ctx context.Context, ownership storage.Ownership) error {
+	return nil
+}
+
+func (m *TestMockStorage) RemoveOwnership(ctx context.Context, entityType, entityID, owner string) (bool, error) {
+	return false, nil
+}
+
+func (m *TestMockStorage) ListTeamMembers(ctx context.Context) ([]storage.TeamMember, error) {
+	return nil, nil
+}
+
+func (m *TestMockStorage) AddTeamMember(ctx context.Context, team, username string) error {
+	return nil
+}
+
+func (m *TestMockStorage) RemoveTeamMember(ctx context.Context, team, username string) (bool, error) {
+	return false, nil
+}
+
+func (m *TestMockStorage) CheckWritable(ctx context.Context) error {
+	return nil
+}
+
+func (m *TestMockStorage) SaveStackLock(ctx context.Context, lock storage.StackLock) error {
+	return nil
+}
+
+func (m *TestMockStorage) DeleteStackLock(ctx context.Context, stackName, operationID string) error {
+	return nil
+}
+
+func (m *TestMockStorage) ListStackLocks(ctx context.Context) ([]storage.StackLock, error) {
+	return nil, nil
+}
+
+func (m *TestMockStorage) SetQueuePriorities(ctx context.Context, priorities map[string]int) error {
+	return nil
+}
+
+func (m *TestMockStorage) DeleteQueuedUpdate(ctx context.Context, operationID string) (bool, error) {
+	return false, nil
+}
+
 // Test: Single container update happy path
 func TestUpdateSingleContainer_HappyPath(t *testing.T) {
 	mockDocker := &MockDockerClient{
@@ -429,6 +699,36 @@ func TestUpdateComposeFile(t *testing.T) {
 	assert.Contains(t, string(data), "nginx:1.21")
 }
 
+// Test: updateComposeFile only changes the image, leaving comments and formatting alone
+func TestUpdateComposeFile_PreservesFormatting(t *testing.T) {
+	composeFile := filepath.Join(t.TempDir(), "docker-compose.yml")
+	composeContent := `# Media stack
+x-common: &common
+  restart: unless-stopped
+
+services:
+  web:
+    <<: *common
+    image: "nginx:1.20"  # pinned until the config migration
+    ports: ["8080:80"]
+`
+	require.NoError(t, os.WriteFile(composeFile, []byte(composeContent), 0644))
+
+	orch := &UpdateOrchestrator{}
+	container := &docker.Container{
+		Name: "web",
+		Labels: map[string]string{
+			"com.docker.compose.service": "web",
+		},
+	}
+
+	require.NoError(t, orch.updateComposeFile(context.Background(), composeFile, container, "1.21"))
+
+	data, err := os.ReadFile(composeFile)
+	require.NoError(t, err)
+	assert.Equal(t, strings.Replace(composeContent, "nginx:1.20", "nginx:1.21", 1), string(data))
+}
+
 // Test: updateComposeFile correctly handles env var image syntax without corruption.
 // This was the exact bug: ${OPENCLAW_IMAGE:-openclaw:latest} was split by ":" naively,
 // destroying the closing "}" and producing invalid interpolation syntax.
@@ -535,7 +835,7 @@ func TestQueueOperation_WhenStackLocked(t *testing.T) {
 		stackLocks:   make(map[string]*stackLockEntry),
 	}
 
-	orch.acquireStackLock("test-stack")
+	orch.acquireStackLock("test-stack", "holder")
 
 	operationID, err := orch.UpdateSingleContainer(context.Background(), "test-container", "latest")
 
@@ -551,6 +851,80 @@ func TestQueueOperation_WhenStackLocked(t *testing.T) {
 	assert.Equal(t, "single", queued[0].OperationType)
 }
 
+// Test: IsStackLocked follows acquire and release
+func TestIsStackLocked(t *testing.T) {
+	orch := &UpdateOrchestrator{stackLocks: make(map[string]*stackLockEntry)}
+
+	assert.False(t, orch.IsStackLocked("test-stack"))
+
+	assert.True(t, orch.acquireStackLock("test-stack", "holder"))
+	assert.True(t, orch.IsStackLocked("test-stack"))
+	assert.False(t, orch.IsStackLocked("other-stack"))
+
+	orch.releaseStackLock("test-stack", "holder")
+	assert.False(t, orch.IsStackLocked("test-stack"))
+}
+
+// Test: all-or-nothing mode is preserved when a batch has to wait for the stack lock
+func TestQueueOperation_AllOrNothingBatch(t *testing.T) {
+	mockDocker := &MockDockerClient{
+		containers: []docker.Container{
+			{Name: "app", Image: "app:1.0", Labels: map[string]string{"com.docker.compose.project": "mystack"}},
+			{Name: "db", Image: "postgres:13", Labels: map[string]string{"com.docker.compose.project": "mystack"}},
+		},
+	}
+	mockStorage := NewTestMockStorage()
+
+	orch := &UpdateOrchestrator{
+		dockerClient: mockDocker,
+		storage:      mockStorage,
+		graphBuilder: graph.NewBuilder(),
+		stackManager: docker.NewStackManager(),
+		stackLocks:   make(map[string]*stackLockEntry),
+	}
+
+	orch.acquireStackLock("mystack", "holder")
+
+	_, err := orch.UpdateBatchContainersInGroup(context.Background(), []string{"app", "db"},
+		map[string]string{"app": "1.1", "db": "14"}, "group-1", nil, nil, true)
+	assert.NoError(t, err)
+
+	queued, _ := mockStorage.GetQueuedUpdates(context.Background())
+	assert.Len(t, queued, 1)
+	assert.True(t, queued[0].AllOrNothing)
+}
+
+// Test: rollbackBatch marks the operation failed and every container as not updated
+func TestRollbackBatch_MarksOperationRolledBack(t *testing.T) {
+	mockStorage := NewTestMockStorage()
+	orch := &UpdateOrchestrator{
+		storage:  mockStorage,
+		eventBus: events.NewBus(),
+	}
+
+	ctx := context.Background()
+	app := &docker.Container{Name: "app", Image: "app:1.1"}
+	db := &docker.Container{Name: "db", Image: "postgres:14"}
+	mockStorage.SaveUpdateOperation(ctx, storage.UpdateOperation{
+		OperationID: "op-1",
+		Status:      "in_progress",
+		BatchDetails: []storage.BatchContainerDetail{
+			{ContainerName: "app", OldVersion: "1.0", NewVersion: "1.1"},
+			{ContainerName: "db", OldVersion: "13", NewVersion: "14", Status: "failed"},
+		},
+	})
+
+	orch.rollbackBatch(ctx, "op-1", "mystack", []*docker.Container{app, db}, nil, nil, "image pull failed for db")
+
+	op, found, _ := mockStorage.GetUpdateOperation(ctx, "op-1")
+	assert.True(t, found)
+	assert.Equal(t, "failed", op.Status)
+	assert.True(t, op.RollbackOccurred)
+	assert.Contains(t, op.ErrorMessage, "image pull failed for db")
+	assert.Equal(t, "failed", op.BatchDetails[0].Status)
+	assert.Contains(t, op.BatchDetails[0].Message, "Not updated")
+}
+
 // Test: hasNetworkModeDependency correctly identifies network_mode dependencies
 func TestHasNetworkModeDependency(t *testing.T) {
 	orch := &UpdateOrchestrator{}
@@ -709,34 +1083,54 @@ func TestBatchUpdateWithNetworkModeDependency(t *testing.T) {
 
 // Test: Standalone restart (no stack) should not acquire or release a stack lock.
 // Before the fix, executeRestart unconditionally deferred releaseStackLock even when
-// stackName was empty and no lock was acquired. If an empty-string entry existed in
-// the map, this would panic by unlocking an unlocked mutex.
+// stackName was empty and no lock was acquired, which could release a lock it did
+// not hold.
 func TestRestartStandaloneContainer_NoLockCorruption(t *testing.T) {
 	orch := &UpdateOrchestrator{
 		stackLocks: make(map[string]*stackLockEntry),
 	}
 
-	// Pre-populate an empty-string lock entry to simulate worst-case:
-	// another code path created it, or stale data exists.
-	orch.stackLocks[""] = &stackLockEntry{}
+	// Pre-populate an empty-string lock entry held by another operation to
+	// simulate worst-case: another code path created it, or stale data exists.
+	assert.True(t, orch.acquireStackLock("", "other"))
+
+	// Releasing on behalf of an operation that does not hold the lock is a no-op
+	assert.True(t, orch.acquireStackLock("real-stack", "holder"))
+	orch.releaseStackLock("real-stack", "holder")
+	orch.releaseStackLock("", "holder")
+	orch.releaseStackLock("real-stack", "holder") // releasing twice is safe
+
+	assert.True(t, orch.IsStackLocked(""), "empty-string lock should still be held by its operation")
+	assert.False(t, orch.IsStackLocked("real-stack"))
+}
 
-	// acquireStackLock("") would succeed (TryLock on a fresh mutex),
-	// but RestartSingleContainer skips it for empty stack names.
-	// Verify releaseStackLock("") on an unlocked mutex does NOT get called.
-	// After the fix, executeRestart guards the defer with `if stackName != ""`.
 
-	// Directly calling releaseStackLock on an unlocked mutex would panic.
-	// Verify the guard works by confirming releaseStackLock is safe when
-	// stackName is non-empty and was properly acquired.
-	orch.acquireStackLock("real-stack")
-	orch.releaseStackLock("real-stack") // should not panic
+// pullCredentialsRegistryClient supplies pull credentials for ECR images only
+type pullCredentialsRegistryClient struct {
+	*mockRegistryClient
+}
 
-	// Verify empty-string entry's mutex is still in its initial (unlocked) state
-	// by successfully acquiring it — proves no spurious unlock happened.
-	locked := orch.stackLocks[""].mu.TryLock()
-	assert.True(t, locked, "empty-string lock should still be acquirable (never touched)")
-	if locked {
-		orch.stackLocks[""].mu.Unlock()
+func (m *pullCredentialsRegistryClient) PullCredentials(ctx context.Context, imageRef string) (string, string, error) {
+	if strings.Contains(imageRef, ".dkr.ecr.") {
+		return "AWS", "ecr-password", nil
 	}
+	return "", "", nil
 }
 
+// TestPullOptions_RegistryCredentials verifies that pulls carry the credentials
+// the registry manager renews, so the daemon needs no docker login.
+func TestPullOptions_RegistryCredentials(t *testing.T) {
+	orch := &UpdateOrchestrator{checker: NewChecker(nil, &pullCredentialsRegistryClient{&mockRegistryClient{}}, nil)}
+
+	opts := orch.pullOptions(context.Background(), "123456789012.dkr.ecr.us-east-1.amazonaws.com/app:1.0")
+	auth, err := dockerregistry.DecodeAuthConfig(opts.RegistryAuth)
+	require.NoError(t, err)
+	assert.Equal(t, "AWS", auth.Username)
+	assert.Equal(t, "ecr-password", auth.Password)
+
+	assert.Empty(t, orch.pullOptions(context.Background(), "nginx:latest").RegistryAuth)
+
+	// Registry clients without pull credentials leave pulls to the daemon's logins
+	plain := &UpdateOrchestrator{checker: NewChecker(nil, &mockRegistryClient{}, nil)}
+	assert.Empty(t, plain.pullOptions(context.Background(), "123456789012.dkr.ecr.us-east-1.amazonaws.com/app:1.0").RegistryAuth)
+}
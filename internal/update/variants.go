@@ -0,0 +1,96 @@
+package update
+
+import (
+	"context"
+	"log"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/chis/docksmith/internal/storage"
+	"github.com/chis/docksmith/internal/version"
+)
+
+// versionTextPattern matches the version at the start of a tag, as written.
+var versionTextPattern = regexp.MustCompile(`^[vV]?\d+(?:\.\d+)*`)
+
+// variantTags returns the tags publishing the version of currentTag under another
+// variant suffix, e.g. "1.25" and "1.25-bookworm" for "1.25-alpine". The version
+// must be written the same way, so "1.25.0" is not a variant of "1.25-alpine":
+// the floating tag follows new patch releases and the pinned one does not.
+func variantTags(parser *version.Parser, currentTag string, tags []string) []string {
+	current := parser.ParseImageTag("dummy:" + currentTag)
+	if current == nil || !current.IsVersioned || current.Version == nil {
+		return nil
+	}
+	currentText := versionText(parser, currentTag)
+	if currentText == "" {
+		return nil
+	}
+
+	var variants []string
+	for _, tag := range tags {
+		if tag == currentTag {
+			continue
+		}
+		info := parser.ParseImageTag("dummy:" + tag)
+		if info == nil || !info.IsVersioned || info.Version == nil {
+			continue
+		}
+		if info.Suffix == current.Suffix || info.Prefix != current.Prefix || info.Version.Prerelease != current.Version.Prerelease {
+			continue
+		}
+		if versionText(parser, tag) == currentText && !slices.Contains(variants, tag) {
+			variants = append(variants, tag)
+		}
+	}
+	slices.Sort(variants)
+	return variants
+}
+
+// versionText returns the version of tag as written, without the tag pattern's
+// channel prefix and the variant suffix.
+func versionText(parser *version.Parser, tag string) string {
+	if pattern := parser.Pattern(); pattern != nil {
+		tag, _ = pattern.TrimPrefix(tag)
+	}
+	return versionTextPattern.FindString(tag)
+}
+
+// SwitchVariant moves a container to another variant of the version it runs, e.g.
+// from nginx:1.25-alpine to nginx:1.25. tag must be one of the Variants of the
+// container's check result. The compose image tag is rewritten and the container
+// recreated through the update pipeline, so health checks and rollback apply as
+// for any update. Returns the ID of the "variant" operation.
+func (o *UpdateOrchestrator) SwitchVariant(ctx context.Context, info ContainerInfo, tag string) (string, error) {
+	if err := o.checkWritable(); err != nil {
+		return "", err
+	}
+	if tag == "" {
+		return "", NewBadRequestError("no variant tag given for %s", info.ContainerName)
+	}
+	if tag == info.CurrentTag {
+		return "", NewBadRequestError("%s already uses %s", info.ContainerName, info.Image)
+	}
+	if !slices.Contains(info.Variants, tag) {
+		if len(info.Variants) == 0 {
+			return "", NewBadRequestError("%s is not a variant of %s: no other variants of %s were found", tag, info.Image, info.CurrentTag)
+		}
+		return "", NewBadRequestError("%s is not a variant of %s (variants: %s)", tag, info.Image, strings.Join(info.Variants, ", "))
+	}
+
+	noChange := int(version.NoChange)
+	containerMeta := map[string]storage.BatchContainerDetail{
+		info.ContainerName: {
+			ChangeType:         &noChange,
+			OldResolvedVersion: info.CurrentVersion,
+			NewResolvedVersion: info.CurrentVersion,
+		},
+	}
+	operationID, err := o.updateBatchContainersInternal(ctx, []string{info.ContainerName}, map[string]string{info.ContainerName: tag}, "variant", "", containerMeta, nil, false)
+	if err != nil {
+		return "", err
+	}
+	log.Printf("VARIANT: Switching %s from %s to %s (operation %s)", info.ContainerName, info.CurrentTag, tag, operationID)
+	return operationID, nil
+}
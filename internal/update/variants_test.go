@@ -0,0 +1,49 @@
+package update
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/chis/docksmith/internal/version"
+)
+
+func TestVariantTags(t *testing.T) {
+	tags := []string{
+		"1.25", "1.25-alpine", "1.25-alpine-slim", "1.25-bookworm", "1.25-perl",
+		"1.25.0", "1.25.0-alpine", "1.26", "1.26-alpine", "1.25-rc1", "latest", "alpine",
+	}
+	parser := version.NewParser()
+
+	assert.Equal(t, []string{"1.25", "1.25-alpine-slim", "1.25-bookworm", "1.25-perl"}, variantTags(parser, "1.25-alpine", tags))
+	assert.Equal(t, []string{"1.25-alpine", "1.25-alpine-slim", "1.25-bookworm", "1.25-perl"}, variantTags(parser, "1.25", tags))
+	assert.Equal(t, []string{"1.25.0-alpine"}, variantTags(parser, "1.25.0", tags), "the patch version is written out")
+	assert.Empty(t, variantTags(parser, "latest", tags))
+	assert.Empty(t, variantTags(parser, "1.27-alpine", tags))
+
+	// Channel prefixes of a tag pattern are kept
+	linuxServer := version.NewParserWithPattern(version.LinuxServerPattern{})
+	assert.Equal(t, []string{"version-1.40.2-ubuntu"}, variantTags(linuxServer, "version-1.40.2", []string{"1.40.2-ubuntu", "version-1.40.2-ubuntu", "version-1.40.2"}))
+}
+
+func TestSwitchVariant_Validation(t *testing.T) {
+	orch := &UpdateOrchestrator{}
+	info := ContainerInfo{ContainerUpdate: ContainerUpdate{
+		ContainerName: "nginx",
+		Image:         "nginx:1.25-alpine",
+		CurrentTag:    "1.25-alpine",
+		Variants:      []string{"1.25", "1.25-bookworm"},
+	}}
+	ctx := context.Background()
+
+	var badRequest *BadRequestError
+	for _, tag := range []string{"", "1.25-alpine", "1.26"} {
+		_, err := orch.SwitchVariant(ctx, info, tag)
+		assert.True(t, errors.As(err, &badRequest), tag)
+	}
+
+	_, err := orch.SwitchVariant(ctx, ContainerInfo{ContainerUpdate: ContainerUpdate{ContainerName: "app", Image: "app:2.0", CurrentTag: "2.0"}}, "2.0-slim")
+	assert.ErrorContains(t, err, "no other variants of 2.0 were found")
+}
@@ -65,7 +65,7 @@ func TestVersionPinMinor(t *testing.T) {
 
 			// Use the findLatestVersion logic directly
 			checker := &Checker{versionParser: parser}
-			result := checker.findLatestVersion(tt.availableTags, "", currentVer, tt.labels, "")
+			result := checker.findLatestVersion(checker.versionParser, tt.availableTags, "", currentVer, tt.labels, "")
 
 			if result != tt.expectedLatest {
 				t.Errorf("Expected latest %s, got %s", tt.expectedLatest, result)
@@ -120,7 +120,7 @@ func TestVersionPinMajor(t *testing.T) {
 			}
 
 			checker := &Checker{versionParser: parser}
-			result := checker.findLatestVersion(tt.availableTags, "", currentVer, tt.labels, "")
+			result := checker.findLatestVersion(checker.versionParser, tt.availableTags, "", currentVer, tt.labels, "")
 
 			if result != tt.expectedLatest {
 				t.Errorf("Expected latest %s, got %s", tt.expectedLatest, result)
@@ -177,7 +177,7 @@ func TestVersionMin(t *testing.T) {
 			}
 
 			checker := &Checker{versionParser: parser}
-			result := checker.findLatestVersion(tt.availableTags, "", currentVer, tt.labels, "")
+			result := checker.findLatestVersion(checker.versionParser, tt.availableTags, "", currentVer, tt.labels, "")
 
 			if result != tt.expectedLatest {
 				t.Errorf("Expected latest '%s', got '%s'", tt.expectedLatest, result)
@@ -234,7 +234,7 @@ func TestVersionMax(t *testing.T) {
 			}
 
 			checker := &Checker{versionParser: parser}
-			result := checker.findLatestVersion(tt.availableTags, "", currentVer, tt.labels, "")
+			result := checker.findLatestVersion(checker.versionParser, tt.availableTags, "", currentVer, tt.labels, "")
 
 			if result != tt.expectedLatest {
 				t.Errorf("Expected latest '%s', got '%s'", tt.expectedLatest, result)
@@ -302,6 +302,91 @@ func TestTagRegex(t *testing.T) {
 	}
 }
 
+// TestVersionConstraint tests that version-constraint restricts candidates to a semver range
+func TestVersionConstraint(t *testing.T) {
+	parser := version.NewParser()
+
+	tests := []struct {
+		name           string
+		currentVersion string
+		availableTags  []string
+		labels         map[string]string
+		expectedLatest string
+	}{
+		{
+			name:           "caret range stays on major",
+			currentVersion: "2.4.0",
+			availableTags:  []string{"2.3.0", "2.4.0", "2.6.1", "3.0.0"},
+			labels: map[string]string{
+				scripts.VersionConstraintLabel: "^2.4",
+			},
+			expectedLatest: "2.6.1",
+		},
+		{
+			name:           "tilde range stays on minor",
+			currentVersion: "1.20.1",
+			availableTags:  []string{"1.20.1", "1.20.4", "1.21.0", "2.0.0"},
+			labels: map[string]string{
+				scripts.VersionConstraintLabel: "~1.20",
+			},
+			expectedLatest: "1.20.4",
+		},
+		{
+			name:           "comparison range with v-prefixed tags",
+			currentVersion: "v3.1.0",
+			availableTags:  []string{"v3.1.0", "v3.9.2", "v4.0.0"},
+			labels: map[string]string{
+				scripts.VersionConstraintLabel: ">=3 <4",
+			},
+			expectedLatest: "v3.9.2",
+		},
+		{
+			name:           "or range",
+			currentVersion: "16.2",
+			availableTags:  []string{"16.2", "16.4", "17.0", "18.1"},
+			labels: map[string]string{
+				scripts.VersionConstraintLabel: "16.x || 18.x",
+			},
+			expectedLatest: "18.1",
+		},
+		{
+			name:           "combined with version-max",
+			currentVersion: "2.4.0",
+			availableTags:  []string{"2.4.0", "2.5.0", "2.8.0"},
+			labels: map[string]string{
+				scripts.VersionConstraintLabel: "^2",
+				scripts.VersionMaxLabel:        "2.6",
+			},
+			expectedLatest: "2.5.0",
+		},
+		{
+			name:           "invalid constraint is ignored",
+			currentVersion: "1.0.0",
+			availableTags:  []string{"1.0.0", "2.0.0"},
+			labels: map[string]string{
+				scripts.VersionConstraintLabel: "not-a-range",
+			},
+			expectedLatest: "2.0.0",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			currentVer := parser.ParseTag(tt.currentVersion)
+			if currentVer == nil {
+				t.Fatalf("Failed to parse current version: %s", tt.currentVersion)
+			}
+
+			checker := &Checker{versionParser: parser, versionComp: version.NewComparator()}
+			result := checker.findLatestVersion(checker.versionParser, tt.availableTags, "", currentVer, tt.labels, "")
+
+			if result != tt.expectedLatest {
+				t.Errorf("Expected latest '%s', got '%s'", tt.expectedLatest, result)
+			}
+		})
+	}
+}
+
 // TestCombinedConstraints tests multiple constraints working together
 func TestCombinedConstraints(t *testing.T) {
 	parser := version.NewParser()
@@ -353,7 +438,7 @@ func TestCombinedConstraints(t *testing.T) {
 			}
 
 			checker := &Checker{versionParser: parser}
-			result := checker.findLatestVersion(tt.availableTags, "", currentVer, tt.labels, "")
+			result := checker.findLatestVersion(checker.versionParser, tt.availableTags, "", currentVer, tt.labels, "")
 
 			if result != tt.expectedLatest {
 				t.Errorf("Expected latest '%s', got '%s'", tt.expectedLatest, result)
@@ -512,7 +597,7 @@ func TestSuffixMatchingWithConstraints(t *testing.T) {
 			}
 
 			checker := &Checker{versionParser: parser}
-			result := checker.findLatestVersion(tt.availableTags, tt.suffix, currentVer, tt.labels, "")
+			result := checker.findLatestVersion(checker.versionParser, tt.availableTags, tt.suffix, currentVer, tt.labels, "")
 
 			if result != tt.expectedLatest {
 				t.Errorf("Expected latest '%s', got '%s'", tt.expectedLatest, result)
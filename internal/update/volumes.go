@@ -0,0 +1,472 @@
+package update
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/chis/docksmith/internal/compose"
+	"github.com/chis/docksmith/internal/docker"
+	"github.com/chis/docksmith/internal/scripts"
+	"github.com/chis/docksmith/internal/storage"
+	"github.com/google/uuid"
+)
+
+const (
+	defaultVolumeBackupDir   = "/data/volume-backups"
+	defaultVolumeBackupImage = "alpine:3"
+	defaultVolumeBackupKeep  = 3
+	volumeSnapshotTimeout    = 30 * time.Minute // Per volume, archives of large volumes take a while
+)
+
+// VolumeBackupConfig configures the snapshots of named volumes taken before updating
+// containers labeled docksmith.backup-volumes=true. Volumes are archived with tar
+// through a helper container, unless a snapshot hook (e.g. zfs or btrfs snapshots)
+// is configured.
+type VolumeBackupConfig struct {
+	Dir   string // Where tar archives are written, "" = defaultVolumeBackupDir
+	Image string // Helper image that archives and restores volumes, "" = defaultVolumeBackupImage
+	Hook  string // Command run as "hook snapshot|restore|delete <volume> <snapshot-id>", "" = tar archives
+	Keep  int    // Snapshots kept per volume, 0 = all
+}
+
+// volumeMount is a named volume mounted into a container.
+type volumeMount struct {
+	Type        string `json:"Type"`
+	Name        string `json:"Name"`
+	Source      string `json:"Source"` // Mountpoint on the Docker host
+	Destination string `json:"Destination"`
+}
+
+// SetVolumeBackup configures volume snapshots. Must be called before any update starts.
+func (o *UpdateOrchestrator) SetVolumeBackup(cfg VolumeBackupConfig) {
+	o.volumeBackup = cfg
+}
+
+// VolumeBackupFromEnv reads the archive directory from VOLUME_BACKUP_DIR (default
+// /data/volume-backups), the helper image from VOLUME_BACKUP_IMAGE (default alpine:3),
+// the snapshot hook from VOLUME_SNAPSHOT_HOOK, and the snapshots kept per volume
+// from VOLUME_BACKUP_KEEP (default 3, 0 = all).
+func VolumeBackupFromEnv() VolumeBackupConfig {
+	cfg := VolumeBackupConfig{
+		Dir:   defaultVolumeBackupDir,
+		Image: defaultVolumeBackupImage,
+		Keep:  defaultVolumeBackupKeep,
+	}
+
+	if value := os.Getenv("VOLUME_BACKUP_DIR"); value != "" {
+		log.Printf("Using VOLUME_BACKUP_DIR: %s", value)
+		cfg.Dir = value
+	}
+	if value := os.Getenv("VOLUME_BACKUP_IMAGE"); value != "" {
+		log.Printf("Using VOLUME_BACKUP_IMAGE: %s", value)
+		cfg.Image = value
+	}
+	if value := os.Getenv("VOLUME_SNAPSHOT_HOOK"); value != "" {
+		log.Printf("Using VOLUME_SNAPSHOT_HOOK: %s", value)
+		cfg.Hook = value
+	}
+	if value := os.Getenv("VOLUME_BACKUP_KEEP"); value != "" {
+		keep, err := strconv.Atoi(value)
+		if err != nil || keep < 0 {
+			log.Printf("Warning: Invalid VOLUME_BACKUP_KEEP '%s', using default %d", value, cfg.Keep)
+		} else {
+			log.Printf("Using VOLUME_BACKUP_KEEP: %d", keep)
+			cfg.Keep = keep
+		}
+	}
+	return cfg
+}
+
+// backsUpVolumes reports whether the named volumes of a container are snapshotted before updates.
+func backsUpVolumes(cont *docker.Container) bool {
+	enabled, _ := parseLabelBool(cont.Labels[scripts.BackupVolumesLabel])
+	return enabled
+}
+
+// runDocker runs a docker CLI command. Tests replace o.runVolumeCommand.
+func (o *UpdateOrchestrator) runDocker(ctx context.Context, stdin io.Reader, stdout io.Writer, args ...string) error {
+	if o.runVolumeCommand != nil {
+		return o.runVolumeCommand(ctx, stdin, stdout, "docker", args...)
+	}
+	return runCommand(ctx, stdin, stdout, nil, "docker", args...)
+}
+
+// runHook runs the configured snapshot hook. Tests replace o.runVolumeCommand.
+func (o *UpdateOrchestrator) runHook(ctx context.Context, env []string, args ...string) error {
+	if o.runVolumeCommand != nil {
+		return o.runVolumeCommand(ctx, nil, nil, o.volumeBackup.Hook, args...)
+	}
+	return runCommand(ctx, nil, nil, env, o.volumeBackup.Hook, args...)
+}
+
+// runCommand runs a command, including its stderr in the returned error.
+func runCommand(ctx context.Context, stdin io.Reader, stdout io.Writer, env []string, name string, args ...string) error {
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Stdin = stdin
+	cmd.Stdout = stdout
+	cmd.Stderr = &stderr
+	if env != nil {
+		cmd.Env = append(os.Environ(), env...)
+	}
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("%w: %s", err, msg)
+		}
+		return err
+	}
+	return nil
+}
+
+// namedVolumes returns the named volumes mounted into a container. Bind mounts are
+// left out: they are host directories Docksmith cannot assume it may write to.
+func (o *UpdateOrchestrator) namedVolumes(ctx context.Context, containerName string) ([]volumeMount, error) {
+	var out bytes.Buffer
+	if err := o.runDocker(ctx, nil, &out, "inspect", "--format", "{{json .Mounts}}", containerName); err != nil {
+		return nil, fmt.Errorf("failed to inspect mounts: %w", err)
+	}
+	var mounts []volumeMount
+	if err := json.Unmarshal(out.Bytes(), &mounts); err != nil {
+		return nil, fmt.Errorf("failed to parse mounts: %w", err)
+	}
+
+	volumes := make([]volumeMount, 0, len(mounts))
+	for _, m := range mounts {
+		if m.Type == "volume" && m.Name != "" {
+			volumes = append(volumes, m)
+		}
+	}
+	return volumes, nil
+}
+
+// hookEnv returns the environment the snapshot hook runs with.
+func hookEnv(operationID, containerName string, volume volumeMount, snapshotID string) []string {
+	return []string{
+		"OPERATION_ID=" + operationID,
+		"CONTAINER_NAME=" + containerName,
+		"VOLUME_NAME=" + volume.Name,
+		"VOLUME_MOUNTPOINT=" + volume.Source,
+		"SNAPSHOT_ID=" + snapshotID,
+	}
+}
+
+// snapshotVolumes snapshots the named volumes of a container labeled
+// docksmith.backup-volumes=true before it is recreated on a new image. The container
+// is stopped first so the snapshots are consistent, and started again if a snapshot
+// fails. Containers without the label are left alone.
+func (o *UpdateOrchestrator) snapshotVolumes(ctx context.Context, operationID string, cont *docker.Container, stackName string) error {
+	if !backsUpVolumes(cont) || o.storage == nil {
+		return nil
+	}
+	volumes, err := o.namedVolumes(ctx, cont.Name)
+	if err != nil {
+		return err
+	}
+	if len(volumes) == 0 {
+		log.Printf("UPDATE: %s has no named volumes to snapshot", cont.Name)
+		return nil
+	}
+
+	o.publishProgress(operationID, cont.Name, stackName, "backup", 55,
+		fmt.Sprintf("Snapshotting %d volume(s) of %s", len(volumes), cont.Name))
+	if err := o.runDocker(ctx, nil, nil, compose.StopSettingsFor(cont, o.getComposeFilePath(cont)).StopArgs(cont.Name)...); err != nil {
+		return fmt.Errorf("failed to stop container: %w", err)
+	}
+
+	for _, volume := range volumes {
+		snapshot, err := o.snapshotVolume(ctx, operationID, cont.Name, volume)
+		if err != nil {
+			if startErr := o.runDocker(ctx, nil, nil, "start", cont.Name); startErr != nil {
+				log.Printf("UPDATE: Failed to start %s after failed snapshot: %v", cont.Name, startErr)
+			}
+			return fmt.Errorf("failed to snapshot volume %s: %w", volume.Name, err)
+		}
+		if err := o.storage.SaveVolumeSnapshot(ctx, snapshot); err != nil {
+			log.Printf("UPDATE: Failed to record snapshot of volume %s: %v", volume.Name, err)
+		}
+		log.Printf("UPDATE: Snapshotted volume %s of %s (%s %s)", volume.Name, cont.Name, snapshot.Method, snapshot.Location)
+		o.pruneVolumeSnapshots(ctx, volume)
+	}
+	return nil
+}
+
+// snapshotVolume snapshots a volume with the snapshot hook, or archives it with tar.
+func (o *UpdateOrchestrator) snapshotVolume(ctx context.Context, operationID, containerName string, volume volumeMount) (storage.VolumeSnapshot, error) {
+	ctx, cancel := context.WithTimeout(ctx, volumeSnapshotTimeout)
+	defer cancel()
+
+	now := time.Now()
+	snapshot := storage.VolumeSnapshot{
+		OperationID:   operationID,
+		ContainerName: containerName,
+		VolumeName:    volume.Name,
+		CreatedAt:     now,
+	}
+	stamp := now.UTC().Format("20060102T150405")
+
+	if o.volumeBackup.Hook != "" {
+		snapshot.Method = "hook"
+		snapshot.Location = fmt.Sprintf("docksmith-%s-%s", stamp, operationID[:min(8, len(operationID))])
+		env := hookEnv(operationID, containerName, volume, snapshot.Location)
+		if err := o.runHook(ctx, env, "snapshot", volume.Name, snapshot.Location); err != nil {
+			return snapshot, fmt.Errorf("snapshot hook failed: %w", err)
+		}
+		return snapshot, nil
+	}
+
+	dir := filepath.Join(cmp.Or(o.volumeBackup.Dir, defaultVolumeBackupDir), containerName)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return snapshot, fmt.Errorf("failed to create backup directory: %w", err)
+	}
+	snapshot.Method = "tar"
+	snapshot.Location = filepath.Join(dir, fmt.Sprintf("%s-%s.tar.gz", volume.Name, stamp))
+
+	file, err := os.Create(snapshot.Location)
+	if err != nil {
+		return snapshot, fmt.Errorf("failed to create archive: %w", err)
+	}
+	err = o.runDocker(ctx, nil, file, "run", "--rm", "--network", "none",
+		"-v", volume.Name+":/volume:ro", cmp.Or(o.volumeBackup.Image, defaultVolumeBackupImage),
+		"tar", "czf", "-", "-C", "/volume", ".")
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(snapshot.Location)
+		return snapshot, fmt.Errorf("failed to archive volume: %w", err)
+	}
+	if info, err := os.Stat(snapshot.Location); err == nil {
+		snapshot.SizeBytes = info.Size()
+	}
+	return snapshot, nil
+}
+
+// pruneVolumeSnapshots removes the oldest snapshots of a volume beyond the number kept.
+func (o *UpdateOrchestrator) pruneVolumeSnapshots(ctx context.Context, volume volumeMount) {
+	if o.volumeBackup.Keep <= 0 {
+		return
+	}
+	snapshots, err := o.storage.ListVolumeSnapshots(ctx, volume.Name)
+	if err != nil || len(snapshots) <= o.volumeBackup.Keep {
+		return
+	}
+	for _, snapshot := range snapshots[o.volumeBackup.Keep:] {
+		switch snapshot.Method {
+		case "hook":
+			if o.volumeBackup.Hook == "" {
+				continue // Keep records of snapshots no hook can remove
+			}
+			env := hookEnv(snapshot.OperationID, snapshot.ContainerName, volume, snapshot.Location)
+			if err := o.runHook(ctx, env, "delete", volume.Name, snapshot.Location); err != nil {
+				log.Printf("UPDATE: Failed to delete snapshot %s of volume %s: %v", snapshot.Location, volume.Name, err)
+				continue
+			}
+		default:
+			if err := os.Remove(snapshot.Location); err != nil && !os.IsNotExist(err) {
+				log.Printf("UPDATE: Failed to delete archive %s: %v", snapshot.Location, err)
+				continue
+			}
+		}
+		if err := o.storage.DeleteVolumeSnapshot(ctx, snapshot.ID); err != nil {
+			log.Printf("UPDATE: Failed to delete record of snapshot %s: %v", snapshot.Location, err)
+		}
+	}
+}
+
+// restoreVolumes restores the volume snapshots an update took of a container, before
+// a rollback recreates it on its old image. The container is stopped first and left
+// stopped if a restore fails, so the old version never starts on half-restored data.
+// Does nothing if the update took no snapshots of the container.
+func (o *UpdateOrchestrator) restoreVolumes(ctx context.Context, operationID, snapshotOpID string, cont *docker.Container, stackName string) error {
+	if snapshotOpID == "" || o.storage == nil {
+		return nil
+	}
+	all, err := o.storage.GetVolumeSnapshots(ctx, snapshotOpID)
+	if err != nil {
+		return err
+	}
+	var snapshots []storage.VolumeSnapshot
+	for _, snapshot := range all {
+		if snapshot.ContainerName == cont.Name {
+			snapshots = append(snapshots, snapshot)
+		}
+	}
+	if len(snapshots) == 0 {
+		return nil
+	}
+
+	o.publishProgress(operationID, cont.Name, stackName, "restoring_volumes", 50,
+		fmt.Sprintf("Restoring %d volume(s) of %s", len(snapshots), cont.Name))
+	if err := o.runDocker(ctx, nil, nil, compose.StopSettingsFor(cont, o.getComposeFilePath(cont)).StopArgs(cont.Name)...); err != nil {
+		return fmt.Errorf("failed to stop container: %w", err)
+	}
+
+	// Volumes are looked up for the mountpoints the hook is given
+	mountpoints := make(map[string]volumeMount)
+	if volumes, err := o.namedVolumes(ctx, cont.Name); err == nil {
+		for _, volume := range volumes {
+			mountpoints[volume.Name] = volume
+		}
+	}
+
+	for _, snapshot := range snapshots {
+		volume, ok := mountpoints[snapshot.VolumeName]
+		if !ok {
+			volume = volumeMount{Type: "volume", Name: snapshot.VolumeName}
+		}
+		if err := o.restoreVolume(ctx, snapshot, volume); err != nil {
+			return fmt.Errorf("failed to restore volume %s from %s (container left stopped): %w", snapshot.VolumeName, snapshot.Location, err)
+		}
+		log.Printf("ROLLBACK: Restored volume %s of %s from %s", snapshot.VolumeName, cont.Name, snapshot.Location)
+	}
+	return nil
+}
+
+// restoreVolume restores a volume with the snapshot hook, or replaces its content
+// with a tar archive.
+func (o *UpdateOrchestrator) restoreVolume(ctx context.Context, snapshot storage.VolumeSnapshot, volume volumeMount) error {
+	ctx, cancel := context.WithTimeout(ctx, volumeSnapshotTimeout)
+	defer cancel()
+
+	if snapshot.Method == "hook" {
+		if o.volumeBackup.Hook == "" {
+			return fmt.Errorf("snapshot was taken by a hook, but VOLUME_SNAPSHOT_HOOK is not set")
+		}
+		env := hookEnv(snapshot.OperationID, snapshot.ContainerName, volume, snapshot.Location)
+		return o.runHook(ctx, env, "restore", volume.Name, snapshot.Location)
+	}
+
+	file, err := os.Open(snapshot.Location)
+	if err != nil {
+		return fmt.Errorf("failed to open archive: %w", err)
+	}
+	defer file.Close()
+	return o.runDocker(ctx, file, nil, "run", "--rm", "-i", "--network", "none",
+		"-v", volume.Name+":/volume", cmp.Or(o.volumeBackup.Image, defaultVolumeBackupImage),
+		"sh", "-c", "find /volume -mindepth 1 -delete && tar xzf - -C /volume")
+}
+
+// restoreSnapshotsMeta returns the batch details that make a rollback of containers
+// through the batch pipeline restore the volume snapshots taken by operationID.
+func restoreSnapshotsMeta(operationID string, containerNames []string) map[string]storage.BatchContainerDetail {
+	meta := make(map[string]storage.BatchContainerDetail, len(containerNames))
+	for _, name := range containerNames {
+		meta[name] = storage.BatchContainerDetail{RestoreSnapshotsFrom: operationID}
+	}
+	return meta
+}
+
+// RestoreVolumes restores the volume snapshots taken by an update operation and
+// restarts the containers they belong to, for restoring data without rolling back
+// the image. Runs in the background as an operation of type "restore_volumes".
+func (o *UpdateOrchestrator) RestoreVolumes(ctx context.Context, operationID string) (string, error) {
+	if err := o.checkWritable(); err != nil {
+		return "", err
+	}
+	origOp, found, err := o.storage.GetUpdateOperation(ctx, operationID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get operation: %w", err)
+	}
+	if !found {
+		return "", NewNotFoundError("operation not found: %s", operationID)
+	}
+	snapshots, err := o.storage.GetVolumeSnapshots(ctx, operationID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get volume snapshots: %w", err)
+	}
+	if len(snapshots) == 0 {
+		return "", NewBadRequestError("operation %s took no volume snapshots", operationID)
+	}
+
+	containers, err := o.dockerClient.ListContainers(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to list containers: %w", err)
+	}
+	var targets []*docker.Container
+	seen := make(map[string]bool)
+	for _, snapshot := range snapshots {
+		if seen[snapshot.ContainerName] {
+			continue
+		}
+		seen[snapshot.ContainerName] = true
+		var target *docker.Container
+		for i := range containers {
+			if containers[i].Name == snapshot.ContainerName {
+				target = &containers[i]
+				break
+			}
+		}
+		if target == nil {
+			return "", NewNotFoundError("container not found: %s", snapshot.ContainerName)
+		}
+		targets = append(targets, target)
+	}
+
+	stackName := o.stackManager.DetermineStack(ctx, *targets[0])
+	restoreOpID := uuid.New().String()
+	if !o.acquireStackLock(stackName, restoreOpID) {
+		return "", NewBadRequestError("stack %s has an operation in progress", stackName)
+	}
+
+	now := time.Now()
+	op := storage.UpdateOperation{
+		OperationID:   restoreOpID,
+		TriggeredBy:   TriggerFromContext(ctx),
+		ContainerID:   targets[0].ID,
+		ContainerName: origOp.ContainerName,
+		StackName:     stackName,
+		OperationType: "restore_volumes",
+		Status:        "in_progress",
+		NewVersion:    fmt.Sprintf("volumes of %s", operationID),
+		StartedAt:     &now,
+	}
+	if err := o.storage.SaveUpdateOperation(ctx, op); err != nil {
+		o.releaseStackLock(stackName, restoreOpID)
+		return "", fmt.Errorf("failed to save operation: %w", err)
+	}
+
+	go o.executeRestoreVolumes(context.Background(), restoreOpID, operationID, targets, stackName)
+
+	return restoreOpID, nil
+}
+
+// executeRestoreVolumes restores the volumes of each container and starts it again.
+func (o *UpdateOrchestrator) executeRestoreVolumes(ctx context.Context, operationID, snapshotOpID string, containers []*docker.Container, stackName string) {
+	ctx = o.withOperationLog(ctx, operationID)
+
+	defer o.releaseStackLock(stackName, operationID)
+
+	for _, cont := range containers {
+		if err := o.restoreVolumes(ctx, operationID, snapshotOpID, cont, stackName); err != nil {
+			o.failOperation(ctx, operationID, "restoring_volumes", err.Error())
+			return
+		}
+		o.publishProgress(operationID, cont.Name, stackName, "starting", 80, fmt.Sprintf("Starting %s", cont.Name))
+		if err := o.runDocker(ctx, nil, nil, "start", cont.Name); err != nil {
+			o.failOperation(ctx, operationID, "starting", fmt.Sprintf("Failed to start %s: %v", cont.Name, err))
+			return
+		}
+		if err := o.waitForHealthy(ctx, cont.Name, o.healthCheckCfg.Timeout); err != nil {
+			log.Printf("ROLLBACK: Health check warning for %s after restoring volumes: %v", cont.Name, err)
+		}
+	}
+
+	completedNow := time.Now()
+	if op, found, _ := o.storage.GetUpdateOperation(ctx, operationID); found {
+		op.Status = "complete"
+		op.CompletedAt = &completedNow
+		o.storage.SaveUpdateOperation(ctx, op)
+	}
+	o.publishProgress(operationID, "", stackName, "complete", 100, "Volumes restored")
+}
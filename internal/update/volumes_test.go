@@ -0,0 +1,145 @@
+package update
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/chis/docksmith/internal/docker"
+	"github.com/chis/docksmith/internal/events"
+	"github.com/chis/docksmith/internal/scripts"
+	"github.com/chis/docksmith/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeVolumeCommands records the docker and hook commands run for volume snapshots.
+// The db container has the named volume dbdata and a bind mount.
+type fakeVolumeCommands struct {
+	mu       sync.Mutex
+	commands []string
+	restored string // Archive content read by a restore
+	fail     string // Commands starting with this fail
+}
+
+func (f *fakeVolumeCommands) run(ctx context.Context, stdin io.Reader, stdout io.Writer, name string, args ...string) error {
+	command := strings.Join(append([]string{name}, args...), " ")
+	f.mu.Lock()
+	f.commands = append(f.commands, command)
+	f.mu.Unlock()
+
+	if f.fail != "" && strings.HasPrefix(command, f.fail) {
+		return fmt.Errorf("%s failed", f.fail)
+	}
+	switch {
+	case strings.HasPrefix(command, "docker inspect"):
+		_, err := io.WriteString(stdout, `[{"Type":"volume","Name":"dbdata","Source":"/var/lib/docker/volumes/dbdata/_data","Destination":"/data"},`+
+			`{"Type":"bind","Source":"/srv/config","Destination":"/config"}]`)
+		return err
+	case strings.HasPrefix(command, "docker run") && strings.Contains(command, "tar czf"):
+		_, err := io.WriteString(stdout, "archive")
+		return err
+	case strings.HasPrefix(command, "docker run") && strings.Contains(command, "tar xzf"):
+		data, err := io.ReadAll(stdin)
+		f.restored = string(data)
+		return err
+	}
+	return nil
+}
+
+func newVolumeTestOrchestrator(t *testing.T, cfg VolumeBackupConfig) (*UpdateOrchestrator, *fakeVolumeCommands) {
+	t.Helper()
+	fake := &fakeVolumeCommands{}
+	o := &UpdateOrchestrator{
+		storage:          storage.NewMemoryStorage(),
+		eventBus:         events.NewBus(),
+		volumeBackup:     cfg,
+		runVolumeCommand: fake.run,
+	}
+	return o, fake
+}
+
+var backedUpContainer = &docker.Container{Name: "db", Labels: map[string]string{scripts.BackupVolumesLabel: "true"}}
+
+func TestSnapshotAndRestoreVolumes(t *testing.T) {
+	ctx := context.Background()
+	o, fake := newVolumeTestOrchestrator(t, VolumeBackupConfig{Dir: t.TempDir(), Image: "alpine:3"})
+
+	require.NoError(t, o.snapshotVolumes(ctx, "op-update", backedUpContainer, "db"))
+
+	snapshots, err := o.storage.GetVolumeSnapshots(ctx, "op-update")
+	require.NoError(t, err)
+	require.Len(t, snapshots, 1, "only the named volume is snapshotted")
+	assert.Equal(t, "dbdata", snapshots[0].VolumeName)
+	assert.Equal(t, "tar", snapshots[0].Method)
+	assert.Equal(t, int64(len("archive")), snapshots[0].SizeBytes)
+	data, err := os.ReadFile(snapshots[0].Location)
+	require.NoError(t, err)
+	assert.Equal(t, "archive", string(data))
+	assert.Contains(t, fake.commands, "docker stop db")
+
+	require.NoError(t, o.restoreVolumes(ctx, "op-rollback", "op-update", backedUpContainer, "db"))
+	assert.Equal(t, "archive", fake.restored)
+}
+
+func TestSnapshotVolumesRequiresLabel(t *testing.T) {
+	o, fake := newVolumeTestOrchestrator(t, VolumeBackupConfig{Dir: t.TempDir()})
+
+	require.NoError(t, o.snapshotVolumes(context.Background(), "op", &docker.Container{Name: "db"}, "db"))
+	assert.Empty(t, fake.commands)
+}
+
+func TestSnapshotVolumesRestartsContainerOnFailure(t *testing.T) {
+	o, fake := newVolumeTestOrchestrator(t, VolumeBackupConfig{Dir: t.TempDir()})
+	fake.fail = "docker run"
+
+	err := o.snapshotVolumes(context.Background(), "op", backedUpContainer, "db")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "dbdata")
+	assert.Equal(t, "docker start db", fake.commands[len(fake.commands)-1])
+
+	snapshots, _ := o.storage.GetVolumeSnapshots(context.Background(), "op")
+	assert.Empty(t, snapshots)
+}
+
+func TestSnapshotVolumesWithHook(t *testing.T) {
+	ctx := context.Background()
+	o, fake := newVolumeTestOrchestrator(t, VolumeBackupConfig{Hook: "/scripts/zfs-snapshot.sh", Keep: 1})
+
+	require.NoError(t, o.snapshotVolumes(ctx, "op-1", backedUpContainer, "db"))
+	require.NoError(t, o.snapshotVolumes(ctx, "op-2", backedUpContainer, "db"))
+
+	first := fake.commands[2]
+	assert.True(t, strings.HasPrefix(first, "/scripts/zfs-snapshot.sh snapshot dbdata docksmith-"), first)
+
+	// Only the newest snapshot is kept
+	snapshots, err := o.storage.ListVolumeSnapshots(ctx, "dbdata")
+	require.NoError(t, err)
+	require.Len(t, snapshots, 1)
+	assert.Equal(t, "op-2", snapshots[0].OperationID)
+	assert.Contains(t, fake.commands, "/scripts/zfs-snapshot.sh delete dbdata "+strings.Fields(first)[3])
+
+	require.NoError(t, o.restoreVolumes(ctx, "op-rollback", "op-2", backedUpContainer, "db"))
+	assert.Equal(t, "/scripts/zfs-snapshot.sh restore dbdata "+snapshots[0].Location, fake.commands[len(fake.commands)-1])
+}
+
+func TestRestoreVolumesWithoutSnapshots(t *testing.T) {
+	o, fake := newVolumeTestOrchestrator(t, VolumeBackupConfig{})
+
+	require.NoError(t, o.restoreVolumes(context.Background(), "op-rollback", "op-update", backedUpContainer, "db"))
+	assert.Empty(t, fake.commands, "the container is not stopped without snapshots to restore")
+}
+
+func TestVolumeBackupFromEnv(t *testing.T) {
+	t.Setenv("VOLUME_BACKUP_DIR", "/backups")
+	t.Setenv("VOLUME_SNAPSHOT_HOOK", "/scripts/snap.sh")
+	t.Setenv("VOLUME_BACKUP_KEEP", "0")
+	assert.Equal(t, VolumeBackupConfig{Dir: "/backups", Image: defaultVolumeBackupImage, Hook: "/scripts/snap.sh"}, VolumeBackupFromEnv())
+
+	t.Setenv("VOLUME_BACKUP_KEEP", "some")
+	assert.Equal(t, defaultVolumeBackupKeep, VolumeBackupFromEnv().Keep)
+}
@@ -0,0 +1,410 @@
+package version
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Constraint is a parsed semver range such as "^2.4", "~1.20", ">=3 <4" or "1.2 - 1.4 || 2.x".
+//
+// Supported syntax:
+//   - Comparisons: =, !=, >, >=, <, <= against full or partial versions
+//   - Caret (^1.2.3 := >=1.2.3 <2.0.0, ^0.2 := >=0.2.0 <0.3.0)
+//   - Tilde (~1.2.3 := >=1.2.3 <1.3.0, ~1 := >=1.0.0 <2.0.0) and pessimistic ~> (~>1.2 := >=1.2.0 <2.0.0)
+//   - Wildcards (1.2.x, 1.*, *) and hyphen ranges (1.2 - 1.4)
+//   - AND by whitespace or comma, OR by "||"
+//
+// Build numbers (e.g., LinuxServer -ls285) are ignored when matching, so "<=1.2.3"
+// accepts "1.2.3-ls285". Prerelease filtering is left to the caller.
+type Constraint struct {
+	original string
+	sets     [][]comparison // OR of ANDs
+}
+
+// comparison is a single primitive check produced by expanding range syntax.
+type comparison struct {
+	op      string // "=", "!=", ">", ">=", "<", "<="
+	version Version
+	// parts is the number of leading components compared by "=" and "!=" (1-4),
+	// so "=1.2" matches any 1.2.x release.
+	parts int
+}
+
+// partialVersion is a version with possibly missing or wildcard components.
+type partialVersion struct {
+	nums  [4]int
+	parts int // number of specified numeric components (0 = wildcard)
+	pre   string
+}
+
+var partialPattern = regexp.MustCompile(`^[vV]?(\d+|[xX*])(?:\.(\d+|[xX*]))?(?:\.(\d+|[xX*]))?(?:\.(\d+|[xX*]))?(?:-([0-9A-Za-z.-]+))?$`)
+
+// constraintOps are the recognized operators, longest first so prefixes match correctly.
+var constraintOps = []string{"~>", ">=", "<=", "!=", "==", ">", "<", "=", "~", "^"}
+
+// ParseConstraint parses a semver range expression.
+func ParseConstraint(s string) (*Constraint, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, fmt.Errorf("empty version constraint")
+	}
+
+	c := &Constraint{original: s}
+	for _, group := range strings.Split(s, "||") {
+		set, err := parseConstraintSet(group)
+		if err != nil {
+			return nil, fmt.Errorf("invalid version constraint %q: %w", s, err)
+		}
+		c.sets = append(c.sets, set)
+	}
+	return c, nil
+}
+
+// String returns the original constraint expression.
+func (c *Constraint) String() string {
+	return c.original
+}
+
+// Check reports whether v satisfies the constraint.
+func (c *Constraint) Check(v *Version) bool {
+	if v == nil {
+		return false
+	}
+	for _, set := range c.sets {
+		if matchesAll(set, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesAll reports whether v satisfies every comparison in an AND set.
+func matchesAll(set []comparison, v *Version) bool {
+	for _, cmp := range set {
+		if !cmp.matches(v) {
+			return false
+		}
+	}
+	return true
+}
+
+func (cmp comparison) matches(v *Version) bool {
+	switch cmp.op {
+	case "=":
+		return cmp.equals(v)
+	case "!=":
+		return !cmp.equals(v)
+	}
+
+	result := compareRelease(v, &cmp.version)
+	switch cmp.op {
+	case ">":
+		return result > 0
+	case ">=":
+		return result >= 0
+	case "<":
+		return result < 0
+	case "<=":
+		return result <= 0
+	}
+	return false
+}
+
+// equals reports whether v matches the specified components of an "=" comparison.
+// Full versions must also match the prerelease, so "=1.2.3" rejects "1.2.3-rc.1".
+func (cmp comparison) equals(v *Version) bool {
+	if compareNumbers(v, &cmp.version, cmp.parts) != 0 {
+		return false
+	}
+	return cmp.parts < 3 || v.Prerelease == cmp.version.Prerelease
+}
+
+// parseConstraintSet parses one AND group (the text between "||").
+func parseConstraintSet(group string) ([]comparison, error) {
+	tokens := strings.FieldsFunc(group, func(r rune) bool {
+		return r == ' ' || r == '\t' || r == ','
+	})
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("empty range")
+	}
+
+	// Hyphen range: "1.2 - 1.4"
+	if len(tokens) == 3 && tokens[1] == "-" {
+		return hyphenRange(tokens[0], tokens[2])
+	}
+
+	var set []comparison
+	for i := 0; i < len(tokens); i++ {
+		token := tokens[i]
+
+		// Allow whitespace between operator and version (">= 3")
+		if isOperator(token) {
+			if i+1 >= len(tokens) {
+				return nil, fmt.Errorf("operator %q without version", token)
+			}
+			i++
+			token += tokens[i]
+		}
+
+		op, rest := splitOperator(token)
+		p, err := parsePartial(rest)
+		if err != nil {
+			return nil, err
+		}
+		cmps, err := expand(op, p)
+		if err != nil {
+			return nil, err
+		}
+		set = append(set, cmps...)
+	}
+	return set, nil
+}
+
+// expand converts an operator and partial version into primitive comparisons.
+func expand(op string, p partialVersion) ([]comparison, error) {
+	switch op {
+	case "", "=", "==":
+		if p.parts == 0 {
+			return nil, nil // "*" matches everything
+		}
+		return []comparison{{op: "=", version: p.floor(), parts: p.parts}}, nil
+
+	case "!=":
+		if p.parts == 0 {
+			return nil, fmt.Errorf("!= requires a version")
+		}
+		return []comparison{{op: "!=", version: p.floor(), parts: p.parts}}, nil
+
+	case ">":
+		if p.parts == 0 {
+			return nil, fmt.Errorf("> requires a version")
+		}
+		if p.full() {
+			return []comparison{{op: ">", version: p.floor()}}, nil
+		}
+		// ">1.2" means newer than any 1.2.x
+		return []comparison{{op: ">=", version: p.bump(p.parts - 1)}}, nil
+
+	case ">=":
+		if p.parts == 0 {
+			return nil, nil
+		}
+		return []comparison{{op: ">=", version: p.floor()}}, nil
+
+	case "<":
+		if p.parts == 0 {
+			return nil, fmt.Errorf("< requires a version")
+		}
+		if p.full() {
+			return []comparison{{op: "<", version: p.floor()}}, nil
+		}
+		return []comparison{{op: "<", version: p.floorExclusive()}}, nil
+
+	case "<=":
+		if p.parts == 0 {
+			return nil, nil
+		}
+		if p.full() {
+			return []comparison{{op: "<=", version: p.floor()}}, nil
+		}
+		// "<=1.2" includes every 1.2.x
+		return []comparison{{op: "<", version: p.bump(p.parts - 1)}}, nil
+
+	case "~":
+		if p.parts == 0 {
+			return nil, nil
+		}
+		// ~1 := 1.x, ~1.2 and ~1.2.3 := 1.2.x
+		level := 1
+		if p.parts == 1 {
+			level = 0
+		}
+		return between(p.floor(), p.bump(level)), nil
+
+	case "~>":
+		if p.parts == 0 {
+			return nil, nil
+		}
+		// Pessimistic: the last specified component may increase
+		level := p.parts - 2
+		if level < 0 {
+			level = 0
+		}
+		return between(p.floor(), p.bump(level)), nil
+
+	case "^":
+		if p.parts == 0 {
+			return nil, nil
+		}
+		// The left-most non-zero component is fixed
+		var level int
+		switch {
+		case p.nums[0] > 0 || p.parts == 1:
+			level = 0
+		case p.nums[1] > 0 || p.parts == 2:
+			level = 1
+		default:
+			level = 2
+		}
+		return between(p.floor(), p.bump(level)), nil
+	}
+
+	return nil, fmt.Errorf("unknown operator %q", op)
+}
+
+// hyphenRange expands "a - b" into an inclusive range.
+func hyphenRange(low, high string) ([]comparison, error) {
+	lo, err := parsePartial(low)
+	if err != nil {
+		return nil, err
+	}
+	hi, err := parsePartial(high)
+	if err != nil {
+		return nil, err
+	}
+
+	var set []comparison
+	if lo.parts > 0 {
+		set = append(set, comparison{op: ">=", version: lo.floor()})
+	}
+	switch {
+	case hi.parts == 0:
+	case hi.full():
+		set = append(set, comparison{op: "<=", version: hi.floor()})
+	default:
+		set = append(set, comparison{op: "<", version: hi.bump(hi.parts - 1)})
+	}
+	return set, nil
+}
+
+// between returns the comparisons for lower <= v < upper.
+func between(lower, upper Version) []comparison {
+	return []comparison{{op: ">=", version: lower}, {op: "<", version: upper}}
+}
+
+func isOperator(token string) bool {
+	for _, op := range constraintOps {
+		if token == op {
+			return true
+		}
+	}
+	return false
+}
+
+func splitOperator(token string) (op, rest string) {
+	for _, candidate := range constraintOps {
+		if strings.HasPrefix(token, candidate) {
+			return candidate, strings.TrimSpace(token[len(candidate):])
+		}
+	}
+	return "", token
+}
+
+// parsePartial parses versions such as "2", "v1.20", "1.2.x", "*" or "3.0.0-rc.1".
+func parsePartial(s string) (partialVersion, error) {
+	var p partialVersion
+	matches := partialPattern.FindStringSubmatch(s)
+	if matches == nil {
+		return p, fmt.Errorf("invalid version %q", s)
+	}
+
+	for i := 0; i < 4; i++ {
+		part := matches[i+1]
+		if part == "" || part == "x" || part == "X" || part == "*" {
+			break
+		}
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return p, fmt.Errorf("invalid version %q", s)
+		}
+		p.nums[i] = n
+		p.parts++
+	}
+
+	p.pre = matches[5]
+	if p.pre != "" && !p.full() {
+		return p, fmt.Errorf("prerelease requires a full version in %q", s)
+	}
+	return p, nil
+}
+
+// full reports whether major, minor and patch are all specified.
+func (p partialVersion) full() bool {
+	return p.parts >= 3
+}
+
+// floor returns the lowest version matching p (missing components are zero).
+func (p partialVersion) floor() Version {
+	return Version{
+		Major:       p.nums[0],
+		Minor:       p.nums[1],
+		Patch:       p.nums[2],
+		Revision:    p.nums[3],
+		HasRevision: p.parts == 4,
+		Prerelease:  p.pre,
+		Type:        "semantic",
+	}
+}
+
+// floorExclusive is floor as an exclusive upper bound that also excludes its prereleases,
+// so "<2" rejects "2.0.0-beta".
+func (p partialVersion) floorExclusive() Version {
+	v := p.floor()
+	v.Prerelease = "0"
+	return v
+}
+
+// bump increments the component at level (0=major, 1=minor, 2=patch) and zeroes the rest.
+// The result excludes prereleases of the bumped version.
+func (p partialVersion) bump(level int) Version {
+	v := Version{Type: "semantic", Prerelease: "0"}
+	switch level {
+	case 0:
+		v.Major = p.nums[0] + 1
+	case 1:
+		v.Major, v.Minor = p.nums[0], p.nums[1]+1
+	default:
+		v.Major, v.Minor, v.Patch = p.nums[0], p.nums[1], p.nums[2]+1
+	}
+	return v
+}
+
+// compareRelease orders v against a bound by numeric components and prerelease,
+// ignoring build numbers and tag formatting. The revision (4th component) is only
+// compared when the bound specifies one, so "<=1.2.3" accepts "1.2.3.4".
+func compareRelease(v, bound *Version) int {
+	n := 3
+	if bound.HasRevision {
+		n = 4
+	}
+	if c := compareNumbers(v, bound, n); c != 0 {
+		return c
+	}
+	if v.Prerelease == bound.Prerelease {
+		return 0
+	}
+	if v.Prerelease == "" {
+		return 1
+	}
+	if bound.Prerelease == "" {
+		return -1
+	}
+	return strings.Compare(v.Prerelease, bound.Prerelease)
+}
+
+// compareNumbers compares the first n numeric components (major, minor, patch, revision).
+func compareNumbers(a, b *Version, n int) int {
+	aNums := [4]int{a.Major, a.Minor, a.Patch, a.Revision}
+	bNums := [4]int{b.Major, b.Minor, b.Patch, b.Revision}
+	for i := 0; i < n && i < 4; i++ {
+		if aNums[i] != bNums[i] {
+			if aNums[i] < bNums[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
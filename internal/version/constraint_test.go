@@ -0,0 +1,77 @@
+package version
+
+import "testing"
+
+func TestConstraintCheck(t *testing.T) {
+	parser := NewParser()
+
+	tests := []struct {
+		constraint string
+		matches    []string
+		rejects    []string
+	}{
+		{"^2.4", []string{"2.4.0", "2.4.7", "2.9.1"}, []string{"2.3.9", "3.0.0", "3.0.0-beta.1"}},
+		{"^0.2", []string{"0.2.0", "0.2.9"}, []string{"0.3.0", "0.1.9"}},
+		{"^0.0.3", []string{"0.0.3"}, []string{"0.0.4", "0.1.0"}},
+		{"~1.20", []string{"1.20.0", "1.20.5"}, []string{"1.21.0", "1.19.9"}},
+		{"~1", []string{"1.0.0", "1.99.0"}, []string{"2.0.0"}},
+		{"~1.2.3", []string{"1.2.3", "1.2.9"}, []string{"1.2.2", "1.3.0"}},
+		{"~>1.2", []string{"1.2.0", "1.9.0"}, []string{"2.0.0", "1.1.0"}},
+		{">=3 <4", []string{"3.0.0", "3.9.9"}, []string{"2.9.9", "4.0.0", "4.0.0-rc.1"}},
+		{">= 3, < 4", []string{"3.5.0"}, []string{"4.0.0"}},
+		{">1.2", []string{"1.3.0", "2.0.0"}, []string{"1.2.9"}},
+		{"<=1.2", []string{"1.2.9", "1.0.0"}, []string{"1.3.0"}},
+		{"<=1.2.3", []string{"1.2.3", "1.2.3-ls285", "1.2.3.4"}, []string{"1.2.4"}},
+		{"1.2.x", []string{"1.2.0", "1.2.15"}, []string{"1.3.0", "1.1.0"}},
+		{"16", []string{"16.0.0", "16.4.1"}, []string{"17.0.0", "15.9.0"}},
+		{"=1.2.3", []string{"1.2.3", "v1.2.3"}, []string{"1.2.3-rc.1", "1.2.4"}},
+		{"!=1.25.3", []string{"1.25.2", "1.25.4"}, []string{"1.25.3"}},
+		{"1.2 - 1.4", []string{"1.2.0", "1.4.9"}, []string{"1.1.9", "1.5.0"}},
+		{"1.2.3 - 2.0.0", []string{"1.2.3", "2.0.0"}, []string{"2.0.1"}},
+		{"^1.0 || ^3.0", []string{"1.5.0", "3.1.0"}, []string{"2.0.0", "4.0.0"}},
+		{"*", []string{"0.0.1", "99.0.0"}, nil},
+		{"1.42.2.x", []string{"1.42.2.10156"}, []string{"1.42.3.1"}},
+		{">=1.42.2.10000", []string{"1.42.2.10156", "1.43.0"}, []string{"1.42.2.9999"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.constraint, func(t *testing.T) {
+			c, err := ParseConstraint(tt.constraint)
+			if err != nil {
+				t.Fatalf("ParseConstraint(%q) failed: %v", tt.constraint, err)
+			}
+			for _, tag := range tt.matches {
+				if !c.Check(parser.ParseTag(tag)) {
+					t.Errorf("%q should match %s", tt.constraint, tag)
+				}
+			}
+			for _, tag := range tt.rejects {
+				if c.Check(parser.ParseTag(tag)) {
+					t.Errorf("%q should reject %s", tt.constraint, tag)
+				}
+			}
+		})
+	}
+}
+
+func TestParseConstraintInvalid(t *testing.T) {
+	invalid := []string{"", "abc", ">=", "^1.x.3-beta", "1.2 ||", ">*", "1..2"}
+	for _, s := range invalid {
+		if _, err := ParseConstraint(s); err == nil {
+			t.Errorf("ParseConstraint(%q) should fail", s)
+		}
+	}
+}
+
+func TestConstraintCheckNil(t *testing.T) {
+	c, err := ParseConstraint("*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Check(nil) {
+		t.Error("nil version should never match")
+	}
+	if c.String() != "*" {
+		t.Errorf("String() = %q, want *", c.String())
+	}
+}
@@ -29,14 +29,26 @@ type ImageInfo struct {
 
 	// Tag information
 	Tag *TagInfo
+
+	// TagPattern is the publisher tag convention used to parse Tag, or nil
+	TagPattern TagPattern
 }
 
-// ExtractFromImage parses a full Docker image string.
+// ExtractFromImage parses a full Docker image string, applying the built-in
+// tag pattern for the repository (e.g., LinuxServer images) when one exists.
 // Examples:
 //   - "nginx:1.21.3"
 //   - "ghcr.io/linuxserver/plex:latest"
 //   - "docker.io/library/nginx:1.21.3-alpine"
 func (e *Extractor) ExtractFromImage(imageStr string) *ImageInfo {
+	info, _ := e.ExtractFromImageWithPattern(imageStr, "")
+	return info
+}
+
+// ExtractFromImageWithPattern parses a full Docker image string using the named
+// tag pattern (a docksmith.tag-pattern label value; empty means auto-detect).
+// Returns an error for unknown patterns, in which case auto-detection is used.
+func (e *Extractor) ExtractFromImageWithPattern(imageStr, patternName string) (*ImageInfo, error) {
 	info := &ImageInfo{
 		Full: imageStr,
 	}
@@ -86,11 +98,22 @@ func (e *Extractor) ExtractFromImage(imageStr string) *ImageInfo {
 		info.Repository = strings.Join(parts[1:], "/")
 	}
 
+	// Resolve the tag pattern now that the repository is known
+	pattern, err := ResolveTagPattern(patternName, info.Repository)
+	if err != nil {
+		pattern, _ = ResolveTagPattern(TagPatternAuto, info.Repository)
+	}
+	info.TagPattern = pattern
+
 	// Parse tag
 	fullImageTag := imagePath + ":" + tag
-	info.Tag = e.parser.ParseImageTag(fullImageTag)
+	if pattern != nil {
+		info.Tag = NewParserWithPattern(pattern).ParseImageTag(fullImageTag)
+	} else {
+		info.Tag = e.parser.ParseImageTag(fullImageTag)
+	}
 
-	return info
+	return info, err
 }
 
 // CompareImages compares versions of two images and returns the change type.
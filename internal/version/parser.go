@@ -59,13 +59,26 @@ var (
 )
 
 // Parser extracts version information from Docker image tags.
-type Parser struct{}
+type Parser struct {
+	pattern TagPattern // optional publisher-specific tag convention
+}
 
 // NewParser creates a new version parser.
 func NewParser() *Parser {
 	return &Parser{}
 }
 
+// NewParserWithPattern creates a parser that applies a publisher's tag convention
+// on top of the generic rules. A nil pattern behaves like NewParser.
+func NewParserWithPattern(pattern TagPattern) *Parser {
+	return &Parser{pattern: pattern}
+}
+
+// Pattern returns the tag pattern applied by the parser, or nil.
+func (p *Parser) Pattern() TagPattern {
+	return p.pattern
+}
+
 // ParseImageTag extracts version information from a full image tag.
 // Examples:
 //   - "nginx:1.21.3" -> version 1.21.3
@@ -87,7 +100,24 @@ func (p *Parser) ParseImageTag(imageTag string) *TagInfo {
 	}
 
 	tag := parts[len(parts)-1]
+	if p.pattern == nil {
+		return p.parseTagInfo(info, tag)
+	}
 
+	// Parse the tag without the publisher's decoration, keeping the original
+	// tag in Version.Original so callers can map versions back to tags
+	rest, prefix := p.pattern.TrimPrefix(tag)
+	p.parseTagInfo(info, rest)
+	info.Prefix = prefix
+	info.Suffix = p.pattern.NormalizeSuffix(info.Suffix)
+	if info.Version != nil {
+		info.Version.Original = tag
+	}
+	return info
+}
+
+// parseTagInfo fills info from the tag portion of an image reference.
+func (p *Parser) parseTagInfo(info *TagInfo, tag string) *TagInfo {
 	// Check for "latest" tag (with or without suffix)
 	if tag == "latest" || strings.HasPrefix(tag, "latest-") {
 		info.IsLatest = true
@@ -170,6 +200,10 @@ func (p *Parser) ParseImageTag(imageTag string) *TagInfo {
 // ParseTag extracts version information from just the tag portion.
 // Example: "1.21.3-alpine" -> version 1.21.3, suffix "alpine"
 func (p *Parser) ParseTag(tag string) *Version {
+	if p.pattern != nil {
+		tag, _ = p.pattern.TrimPrefix(tag)
+	}
+
 	// Try date-based version FIRST (consistent with ParseImageTag)
 	// Date versions can look like semantic versions (e.g., 2024.01.15 → 2024.1.15)
 	if dateVer := p.extractDateVersion(tag); dateVer != nil {
@@ -0,0 +1,140 @@
+package version
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// TagPattern adapts tag parsing to a publisher's tagging convention.
+// Patterns are applied on top of the generic parser: they remove decoration the
+// generic rules would otherwise reject or mistake for a variant suffix.
+type TagPattern interface {
+	// Name identifies the pattern in the docksmith.tag-pattern label.
+	Name() string
+
+	// Detect reports whether images from repository (e.g., "linuxserver/plex")
+	// follow this convention. Used when no pattern is configured.
+	Detect(repository string) bool
+
+	// TrimPrefix removes a channel prefix from tag (e.g., "version-1.2.3").
+	// Returns the remaining tag and the removed prefix, or the tag unchanged and "".
+	TrimPrefix(tag string) (rest, prefix string)
+
+	// NormalizeSuffix removes build metadata from an already normalized suffix.
+	NormalizeSuffix(suffix string) string
+}
+
+// Tag pattern label values with special meaning.
+const (
+	// TagPatternAuto selects a pattern by repository (the default).
+	TagPatternAuto = "auto"
+	// TagPatternNone disables publisher-specific handling.
+	TagPatternNone = "none"
+)
+
+var (
+	tagPatternsMu sync.RWMutex
+	tagPatterns   = map[string]TagPattern{}
+)
+
+func init() {
+	RegisterTagPattern(LinuxServerPattern{})
+}
+
+// RegisterTagPattern makes a pattern available by name for labels and auto-detection.
+// Registering a pattern with an existing name replaces it.
+func RegisterTagPattern(p TagPattern) {
+	tagPatternsMu.Lock()
+	defer tagPatternsMu.Unlock()
+	tagPatterns[p.Name()] = p
+}
+
+// TagPatternNames returns the accepted docksmith.tag-pattern values.
+func TagPatternNames() []string {
+	tagPatternsMu.RLock()
+	defer tagPatternsMu.RUnlock()
+
+	names := make([]string, 0, len(tagPatterns))
+	for name := range tagPatterns {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return append([]string{TagPatternAuto, TagPatternNone}, names...)
+}
+
+// ValidateTagPattern checks a docksmith.tag-pattern label value. Empty is valid.
+func ValidateTagPattern(name string) error {
+	_, err := ResolveTagPattern(name, "")
+	return err
+}
+
+// ResolveTagPattern returns the pattern for a label value and repository.
+// An empty value or "auto" detects the pattern from the repository; "none"
+// and repositories without a known convention return nil.
+func ResolveTagPattern(name, repository string) (TagPattern, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+
+	tagPatternsMu.RLock()
+	defer tagPatternsMu.RUnlock()
+
+	switch name {
+	case "", TagPatternAuto:
+		// Sorted for deterministic detection when several patterns match
+		names := make([]string, 0, len(tagPatterns))
+		for n := range tagPatterns {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		for _, n := range names {
+			if tagPatterns[n].Detect(repository) {
+				return tagPatterns[n], nil
+			}
+		}
+		return nil, nil
+	case TagPatternNone:
+		return nil, nil
+	}
+
+	if p, ok := tagPatterns[name]; ok {
+		return p, nil
+	}
+	return nil, fmt.Errorf("unknown tag pattern %q", name)
+}
+
+// lsRevisionPattern matches Alpine package revisions (r0, r12) that LinuxServer
+// images carry between the version and the build number, e.g. "5.9.0-r0-ls108".
+var lsRevisionPattern = regexp.MustCompile(`(?:^|-)r\d+(?:-|$)`)
+
+// LinuxServerPattern handles LinuxServer.io tags:
+//   - "1.32.3-ls123": the -lsN build number orders rebuilds of the same version
+//   - "version-1.32.3": a floating alias for the newest build of a version
+//   - "5.9.0-r0-ls108": Alpine package revisions are build metadata, not variants
+type LinuxServerPattern struct{}
+
+// Name returns "linuxserver".
+func (LinuxServerPattern) Name() string {
+	return "linuxserver"
+}
+
+// Detect matches images published under the linuxserver organization
+// (docker.io, ghcr.io and lscr.io all use "linuxserver/<image>").
+func (LinuxServerPattern) Detect(repository string) bool {
+	return strings.HasPrefix(strings.ToLower(repository), "linuxserver/")
+}
+
+// TrimPrefix removes the "version-" alias prefix.
+func (LinuxServerPattern) TrimPrefix(tag string) (string, string) {
+	if rest, ok := strings.CutPrefix(tag, "version-"); ok && rest != "" {
+		return rest, "version"
+	}
+	return tag, ""
+}
+
+// NormalizeSuffix removes Alpine package revisions left after generic normalization.
+func (LinuxServerPattern) NormalizeSuffix(suffix string) string {
+	suffix = lsRevisionPattern.ReplaceAllString(suffix, "-")
+	return strings.Trim(suffix, "-_.")
+}
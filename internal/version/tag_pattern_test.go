@@ -0,0 +1,114 @@
+package version
+
+import "testing"
+
+func TestResolveTagPattern(t *testing.T) {
+	tests := []struct {
+		name       string
+		label      string
+		repository string
+		want       string // pattern name, "" for none
+		wantErr    bool
+	}{
+		{"auto detects linuxserver", "", "linuxserver/plex", "linuxserver", false},
+		{"explicit auto", "auto", "linuxserver/sonarr", "linuxserver", false},
+		{"auto without match", "", "library/nginx", "", false},
+		{"none disables detection", "none", "linuxserver/plex", "", false},
+		{"explicit pattern", "LinuxServer", "myorg/plex", "linuxserver", false},
+		{"unknown pattern", "bitnami", "bitnami/redis", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := ResolveTagPattern(tt.label, tt.repository)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ResolveTagPattern(%q, %q) error = %v, wantErr %v", tt.label, tt.repository, err, tt.wantErr)
+			}
+			got := ""
+			if p != nil {
+				got = p.Name()
+			}
+			if got != tt.want {
+				t.Errorf("ResolveTagPattern(%q, %q) = %q, want %q", tt.label, tt.repository, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseImageTagLinuxServerPattern(t *testing.T) {
+	parser := NewParserWithPattern(LinuxServerPattern{})
+
+	tests := []struct {
+		imageTag     string
+		expectVer    string
+		expectBuild  int
+		expectSuffix string
+		expectPrefix string
+	}{
+		{"lscr.io/linuxserver/plex:1.32.3-ls123", "1.32.3", 123, "", ""},
+		{"lscr.io/linuxserver/plex:version-1.32.3", "1.32.3", 0, "", "version"},
+		{"lscr.io/linuxserver/plex:version-1.43.0.10492-121068a07", "1.43.0.10492", 0, "", "version"},
+		{"lscr.io/linuxserver/mariadb:5.9.0-r0-ls108", "5.9.0", 108, "", ""},
+		{"lscr.io/linuxserver/mariadb:5.9.0-r0-alpine-ls108", "5.9.0", 108, "alpine", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.imageTag, func(t *testing.T) {
+			info := parser.ParseImageTag(tt.imageTag)
+			if info.Version == nil {
+				t.Fatalf("ParseImageTag(%q) returned no version", tt.imageTag)
+			}
+			if got := info.Version.String(); got != tt.expectVer {
+				t.Errorf("Version = %q, want %q", got, tt.expectVer)
+			}
+			if info.Version.BuildNumber != tt.expectBuild {
+				t.Errorf("BuildNumber = %d, want %d", info.Version.BuildNumber, tt.expectBuild)
+			}
+			if info.Suffix != tt.expectSuffix {
+				t.Errorf("Suffix = %q, want %q", info.Suffix, tt.expectSuffix)
+			}
+			if info.Prefix != tt.expectPrefix {
+				t.Errorf("Prefix = %q, want %q", info.Prefix, tt.expectPrefix)
+			}
+		})
+	}
+}
+
+func TestLinuxServerBuildOrdering(t *testing.T) {
+	parser := NewParserWithPattern(LinuxServerPattern{})
+	comp := NewComparator()
+
+	older := parser.ParseImageTag("img:1.32.3-ls123")
+	newer := parser.ParseImageTag("img:1.32.3-ls124")
+	if older.Suffix != newer.Suffix {
+		t.Errorf("builds parsed as different variants: %q vs %q", older.Suffix, newer.Suffix)
+	}
+	if !comp.IsNewer(older.Version, newer.Version) {
+		t.Error("1.32.3-ls124 should be newer than 1.32.3-ls123")
+	}
+}
+
+func TestExtractFromImageDetectsTagPattern(t *testing.T) {
+	extractor := NewExtractor()
+
+	info := extractor.ExtractFromImage("lscr.io/linuxserver/plex:version-1.32.3")
+	if info.TagPattern == nil || info.TagPattern.Name() != "linuxserver" {
+		t.Fatalf("expected linuxserver tag pattern, got %v", info.TagPattern)
+	}
+	if !info.Tag.IsVersioned || info.Tag.Prefix != "version" {
+		t.Errorf("expected versioned tag with version prefix, got %+v", info.Tag)
+	}
+
+	// Explicitly disabled patterns fall back to generic parsing
+	info, err := extractor.ExtractFromImageWithPattern("lscr.io/linuxserver/plex:version-1.32.3", TagPatternNone)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if info.TagPattern != nil || info.Tag.IsVersioned {
+		t.Errorf("expected generic parsing with pattern disabled, got %+v", info.Tag)
+	}
+
+	if _, err := extractor.ExtractFromImageWithPattern("nginx:1.25", "unknown"); err == nil {
+		t.Error("expected error for unknown tag pattern")
+	}
+}
@@ -92,6 +92,10 @@ type TagInfo struct {
 	// Suffix contains any additional info (e.g., "alpine", "slim")
 	Suffix string
 
+	// Prefix is a channel prefix removed by the tag pattern (e.g., "version" from
+	// LinuxServer's "version-1.32.3"). Tags with different prefixes are separate channels.
+	Prefix string
+
 	// VersionType indicates the type of version ("semantic", "date", "hash", "meta")
 	VersionType string
 
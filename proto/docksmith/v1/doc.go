@@ -0,0 +1,5 @@
+// Package docksmithv1 holds the Go code generated from docksmith.proto, the
+// definition of the gRPC API the server serves on GRPC_PORT.
+package docksmithv1
+
+//go:generate sh -c "cd ../.. && buf generate"
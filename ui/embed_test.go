@@ -0,0 +1,34 @@
+//go:build embedui
+
+package ui
+
+import (
+	"io/fs"
+	"path"
+	"testing"
+)
+
+// Run after building the UI: npm run build && go test -tags embedui ./ui/
+func TestDistPrecompressed(t *testing.T) {
+	assets, err := fs.Glob(Dist, "assets/*.js")
+	if err != nil || len(assets) == 0 {
+		t.Fatalf("No JavaScript assets in the embedded UI (%v)", err)
+	}
+	css, _ := fs.Glob(Dist, "assets/*.css")
+	assets = append(assets, css...)
+
+	for _, name := range assets {
+		info, err := fs.Stat(Dist, name)
+		if err != nil {
+			t.Fatalf("Stat(%s) failed: %v", name, err)
+		}
+		if info.Size() < 1024 {
+			continue
+		}
+		for _, ext := range []string{".br", ".gz"} {
+			if _, err := fs.Stat(Dist, name+ext); err != nil {
+				t.Errorf("%s has no precompressed %s file", path.Base(name), ext)
+			}
+		}
+	}
+}
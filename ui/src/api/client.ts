@@ -4,12 +4,22 @@ import type {
   DockerConfigResponse,
   APIResponse,
   ScriptsResponse,
+  ScriptRevision,
+  ManagedScriptResponse,
+  SaveScriptRequest,
+  ScriptTestResult,
   RegistryTagsAPIResponse,
+  RegistryRepositoriesAPIResponse,
+  RegistryManifestAPIResponse,
   SetLabelsRequest,
   SetLabelsResponse,
   ExplorerResponse,
   ContainerInfo,
   LabelOperationResult,
+  IgnoreRule,
+  ContainerVersionsResponse,
+  ConfigSnapshot,
+  ConfigRevertResult,
 } from '../types/api';
 
 const API_BASE = '/api';
@@ -108,6 +118,15 @@ export async function setSetting(key: string, value: string): Promise<APIRespons
   });
 }
 
+// Configuration history
+export async function getConfigHistory(limit = 50): Promise<APIResponse<{ snapshots: ConfigSnapshot[]; count: number }>> {
+  return fetchAPI(`/config/history?limit=${limit}`);
+}
+
+export async function revertConfigSnapshot(id: number): Promise<APIResponse<ConfigRevertResult>> {
+  return fetchAPI(`/config/history/${id}/revert`, { method: 'POST' });
+}
+
 // Trigger batch container update (grouped by stack)
 export async function triggerBatchUpdate(containers: Array<{
   name: string;
@@ -145,6 +164,41 @@ export async function getOperationsByGroup(groupId: string): Promise<APIResponse
   return fetchAPI(`/operations/group/${groupId}`);
 }
 
+// Get the volume snapshots taken by an operation
+export async function getVolumeSnapshots(operationId: string): Promise<APIResponse<{
+  snapshots: import('../types/api').VolumeSnapshot[];
+  count: number;
+}>> {
+  return fetchAPI(`/operations/${operationId}/volume-snapshots`);
+}
+
+// Get the stored step log of an operation, only lines after afterId if given
+export async function getOperationLogs(operationId: string, afterId = 0): Promise<APIResponse<{
+  operation_id: string;
+  status: string;
+  logs: import('../types/api').OperationLogEntry[];
+  count: number;
+}>> {
+  return fetchAPI(`/operations/${operationId}/logs?follow=false&after=${afterId}`);
+}
+
+// Open an SSE stream of the step log of an operation. Lines arrive as "log" events
+// and the stream ends with a "done" event once the operation completes or fails.
+export function streamOperationLogs(operationId: string): EventSource {
+  return new EventSource(`${API_BASE}/operations/${operationId}/logs`);
+}
+
+// Restore the volume snapshots taken by an operation
+export async function restoreVolumes(operationId: string): Promise<APIResponse<{
+  operation_id: string;
+  snapshot_operation_id: string;
+  status: string;
+}>> {
+  return fetchAPI(`/operations/${operationId}/restore-volumes`, {
+    method: 'POST',
+  });
+}
+
 // Rollback specific containers from an operation
 export async function rollbackContainers(
   operationId: string,
@@ -171,6 +225,31 @@ export async function getScripts(): Promise<APIResponse<ScriptsResponse>> {
   return fetchAPI('/scripts');
 }
 
+// Run a pre-update check against a container now
+export async function testScript(container: string, script: string): Promise<APIResponse<ScriptTestResult>> {
+  return fetchAPI('/scripts/test', {
+    method: 'POST',
+    body: JSON.stringify({ container, script }),
+  });
+}
+
+// Get a managed script and its revisions (latest revision unless one is given)
+export async function getManagedScript(name: string, revision?: number): Promise<APIResponse<ManagedScriptResponse>> {
+  const query = revision ? `?revision=${revision}` : '';
+  return fetchAPI(`/scripts/${encodeURIComponent(name)}${query}`);
+}
+
+// Create a managed script or save a new revision of it
+export async function saveManagedScript(name: string, request: SaveScriptRequest): Promise<APIResponse<{
+  script: ScriptRevision;
+  ref: string;
+}>> {
+  return fetchAPI(`/scripts/${encodeURIComponent(name)}`, {
+    method: 'PUT',
+    body: JSON.stringify(request),
+  });
+}
+
 // Label management (atomic: compose + restart)
 
 // Get labels for a container
@@ -214,6 +293,28 @@ export async function removeLabels(
   });
 }
 
+// Version timeline of a container, oldest first
+export async function getContainerVersions(containerName: string): Promise<APIResponse<ContainerVersionsResponse>> {
+  return fetchAPI(`/containers/${encodeURIComponent(containerName)}/versions`);
+}
+
+// Image ignore rules
+
+export async function getIgnoreRules(): Promise<APIResponse<{ rules: IgnoreRule[]; count: number }>> {
+  return fetchAPI('/ignore-rules');
+}
+
+export async function addIgnoreRule(pattern: string, reason?: string): Promise<APIResponse<IgnoreRule>> {
+  return fetchAPI('/ignore-rules', {
+    method: 'POST',
+    body: JSON.stringify({ pattern, reason }),
+  });
+}
+
+export async function deleteIgnoreRule(id: number): Promise<APIResponse<{ id: number; deleted: boolean }>> {
+  return fetchAPI(`/ignore-rules/${id}`, { method: 'DELETE' });
+}
+
 // Start a restart operation via orchestrator (returns operation_id for SSE tracking)
 export interface StartRestartResponse {
   operation_id: string;
@@ -251,6 +352,15 @@ export async function getRegistryTags(imageRef: string): Promise<RegistryTagsAPI
   return fetchAPI(`/registry/tags/${imageRef}`);
 }
 
+// Registry catalog browsing (for the change image/tag picker)
+export async function getRegistryRepositories(registry: string): Promise<RegistryRepositoriesAPIResponse> {
+  return fetchAPI(`/registry/repositories/${encodeURIComponent(registry)}`);
+}
+
+export async function getRegistryManifest(imageRef: string, reference: string): Promise<RegistryManifestAPIResponse> {
+  return fetchAPI(`/registry/manifest/${imageRef}?reference=${encodeURIComponent(reference)}`);
+}
+
 // Explorer data (containers, images, networks, volumes)
 export async function getExplorerData(): Promise<ExplorerResponse> {
   return fetchAPI('/explorer');
@@ -277,6 +387,13 @@ export async function inspectContainer(
   return fetchAPI(`/containers/${encodeURIComponent(name)}/inspect`);
 }
 
+// CPU and memory usage of running containers
+export async function getContainersUsage(): Promise<
+  APIResponse<{ containers: Record<string, import('../types/api').ResourceUsage>; count: number }>
+> {
+  return fetchAPI('/containers/usage');
+}
+
 // Stop a running container
 export async function stopContainer(
   name: string,
@@ -492,6 +609,14 @@ export async function batchRemoveContainers(
   });
 }
 
+// Rebuild a locally built service and recreate its container
+export async function startRebuild(containerName: string, force = false): Promise<APIResponse<StartRestartResponse>> {
+  const url = `/rebuild/${encodeURIComponent(containerName)}`;
+  return fetchAPI(force ? `${url}?force=true` : url, {
+    method: 'POST',
+  });
+}
+
 // Fix compose mismatch - sync container to compose file specification
 export async function fixComposeMismatch(containerName: string): Promise<APIResponse<{
   operation_id: string;
@@ -21,6 +21,7 @@ import type {
 import { ChangeType } from '../types/api';
 import { isUpdatable, isMismatch } from '../utils/status';
 import { parseImageRef } from '../utils/registry';
+import { formatSizeDelta } from '../utils/size';
 import { useToast } from './Toast';
 import {
   SearchBar,
@@ -723,7 +724,8 @@ export function Containers() {
       } else {
         latestDisplay = latestTag;
       }
-      return `${currentDisplay} \u2192 ${latestDisplay}`;
+      const sizeDelta = formatSizeDelta(c.size_delta);
+      return `${currentDisplay} \u2192 ${latestDisplay}${sizeDelta ? ` (${sizeDelta})` : ''}`;
     }
 
     if (c.update_status === 'LOCAL_IMAGE') return 'Local image';
@@ -749,6 +751,7 @@ export function Containers() {
         if (c.change_type === ChangeType.PatchChange) return <span className="status-badge patch" title="Patch update">PATCH</span>;
         return <span className="status-badge rebuild" title="Update available">REBUILD</span>;
       case 'UPDATE_AVAILABLE_BLOCKED': return <span className="status-badge blocked" title="Update blocked">BLOCKED</span>;
+      case 'UPDATE_UNAVAILABLE_ARCH': return <span className="status-badge blocked" title={c.error || 'No image for this architecture'}>NO ARCH</span>;
       case 'UP_TO_DATE':
         if (c.state !== 'running') return <span className="status-badge stopped">{c.state.toUpperCase()}</span>;
         return <span className="status-badge current" title="Up to date">CURRENT</span>;
@@ -813,11 +816,12 @@ export function Containers() {
         >
           <div className="container-info">
             <span className="name">{c.name}</span>
-            <span className="version">{getVersion(c)}</span>
+            <span className="version" title={c.latest_size ? `Download size: ${formatBytes(c.latest_size)}` : undefined}>{getVersion(c)}</span>
           </div>
           {c.pre_update_check_pass && <span className="check" title="Pre-update check passed"><i className="fa-solid fa-check"></i></span>}
           {c.pre_update_check_fail && <span className="warn" title={c.pre_update_check_fail}><i className="fa-solid fa-triangle-exclamation"></i></span>}
           {c.health_status === 'unhealthy' && <span className="warn" title="Container is unhealthy"><i className="fa-solid fa-heart-crack"></i></span>}
+          {c.end_of_life?.eol && <span className="warn" title={`${c.end_of_life.product} ${c.end_of_life.cycle} is past end of life${c.end_of_life.eol_date ? ` since ${c.end_of_life.eol_date}` : ''}`}><i className="fa-solid fa-hourglass-end"></i></span>}
           {c.env_controlled && <span className="label-icon env" title={`Image from .env: $${c.env_var_name || 'ENV'}`}><i className="fa-solid fa-file-code"></i></span>}
           {versionPin && <span className={`label-icon pin-${versionPin}`} title={`Version pinned to ${versionPin}`}><i className="fa-solid fa-thumbtack"></i></span>}
           {hasTagRegex && <span className="label-icon regex" title="Tag regex filter"><i className="fa-solid fa-filter"></i></span>}
@@ -43,7 +43,7 @@ interface DisplayItem {
 }
 
 // Operation types that support rollback
-const ROLLBACK_SUPPORTED_TYPES = ['single', 'batch', 'stack', 'label_change'];
+const ROLLBACK_SUPPORTED_TYPES = ['single', 'batch', 'stack', 'pin', 'variant', 'label_change'];
 
 // Rollback strategy per container
 type RollbackStrategy = 'tag' | 'resolved' | 'digest' | 'none';
@@ -551,6 +551,12 @@ export function History() {
           {op.operation_type === 'fix_mismatch' && (
             <span className="op-type-badge fix">FIX</span>
           )}
+          {op.operation_type === 'pin' && (
+            <span className="op-type-badge pin">PIN</span>
+          )}
+          {op.operation_type === 'variant' && (
+            <span className="op-type-badge pin">VARIANT</span>
+          )}
           {/* Change type badge for single update operations */}
           {(op.operation_type === 'single' || op.operation_type === 'stack') && op.batch_details?.[0] && renderChangeTypeBadge(op.batch_details[0])}
           {op.rollback_occurred && (
@@ -558,7 +564,7 @@ export function History() {
           )}
         </div>
         <div className="op-info">
-          {op.operation_type === 'batch' && (
+          {(op.operation_type === 'batch' || op.operation_type === 'pin') && (
             <span className="op-batch-summary">
               {op.batch_details && op.batch_details.length > 0
                 ? (() => {
@@ -641,6 +647,12 @@ export function History() {
                 <span className="value">{new Date(op.completed_at).toLocaleString()}</span>
               </div>
             )}
+            {op.triggered_by && (
+              <div className="op-detail">
+                <span className="label">Triggered By</span>
+                <span className="value">{op.triggered_by}</span>
+              </div>
+            )}
           </div>
 
           {/* Batch Details - show individual containers and version transitions */}
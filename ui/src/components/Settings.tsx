@@ -1,6 +1,6 @@
 import { useState, useEffect, useCallback } from 'react';
-import { checkContainers, getContainerStatus, getDockerConfig, clearHistory, getSetting, setSetting } from '../api/client';
-import type { DiscoveryResult, DockerRegistryInfo } from '../types/api';
+import { checkContainers, getContainerStatus, getDockerConfig, clearHistory, getSetting, setSetting, getConfigHistory, revertConfigSnapshot } from '../api/client';
+import type { DiscoveryResult, DockerRegistryInfo, ConfigSnapshot, ConfigChange } from '../types/api';
 import { formatTimeAgo } from '../utils/time';
 import { useFocusTrap } from '../hooks/useFocusTrap';
 
@@ -18,8 +18,59 @@ export function Settings() {
   const [clearResult, setClearResult] = useState<string | null>(null);
   const [retentionPolicy, setRetentionPolicy] = useState('0');
 
+  const [configHistory, setConfigHistory] = useState<ConfigSnapshot[]>([]);
+  const [revertTarget, setRevertTarget] = useState<ConfigSnapshot | null>(null);
+  const [reverting, setReverting] = useState(false);
+  const [revertResult, setRevertResult] = useState<string | null>(null);
+
   const cancelClear = useCallback(() => setClearConfirm(false), []);
   const clearDialogRef = useFocusTrap(clearConfirm, cancelClear);
+  const cancelRevert = useCallback(() => setRevertTarget(null), []);
+  const revertDialogRef = useFocusTrap(revertTarget !== null, cancelRevert);
+
+  const fetchConfigHistory = async () => {
+    try {
+      const response = await getConfigHistory(20);
+      if (response.success && response.data) {
+        setConfigHistory(response.data.snapshots);
+      }
+    } catch {
+      // Silently fail - the section shows no changes
+    }
+  };
+
+  const executeRevert = async () => {
+    if (!revertTarget) return;
+    setReverting(true);
+    try {
+      const response = await revertConfigSnapshot(revertTarget.id);
+      if (response.success && response.data) {
+        const skipped = response.data.skipped?.length
+          ? ` (${response.data.skipped.length} could not be restored: ${response.data.skipped.join(', ')})`
+          : '';
+        setRevertResult(`Reverted ${response.data.changes.length} settings${skipped}. Restart to apply schedule and notification changes.`);
+        await fetchConfigHistory();
+      } else {
+        setRevertResult(`Error: ${response.error || 'Failed to revert configuration'}`);
+      }
+    } catch (err) {
+      setRevertResult(`Error: ${err instanceof Error ? err.message : 'Unknown error'}`);
+    } finally {
+      setReverting(false);
+      setRevertTarget(null);
+    }
+  };
+
+  const describeChange = (change: ConfigChange) => {
+    switch (change.type) {
+      case 'added':
+        return `${change.key} = ${change.new}`;
+      case 'removed':
+        return `${change.key} removed`;
+      default:
+        return `${change.key}: ${change.old} → ${change.new}`;
+    }
+  };
 
   const executeClearHistory = async () => {
     setClearingHistory(true);
@@ -53,6 +104,7 @@ export function Settings() {
   useEffect(() => {
     fetchStatus();
     fetchDockerConfigData();
+    fetchConfigHistory();
     getSetting('history_retention_days').then(res => {
       if (res.success && res.data) {
         setRetentionPolicy(res.data.value);
@@ -256,6 +308,30 @@ export function Settings() {
           </div>
         </section>
 
+        {/* Container Groups (docksmith.group label) */}
+        {result?.groups && Object.keys(result.groups).length > 0 && (
+          <section className="settings-section">
+            <h2 className="section-title">
+              <i className="fa-solid fa-layer-group"></i>
+              Container Groups
+            </h2>
+            <div className="settings-card">
+              {Object.values(result.groups)
+                .sort((a, b) => a.name.localeCompare(b.name))
+                .map((group) => (
+                  <div className="setting-row" key={group.name}>
+                    <span className="setting-label">{group.name}</span>
+                    <span className="setting-value">
+                      {group.containers.length} containers · {group.updates_available} updates · {group.up_to_date} up to date
+                      {group.failed > 0 && ` · ${group.failed} failed`}
+                      {group.ignored > 0 && ` · ${group.ignored} ignored`}
+                    </span>
+                  </div>
+                ))}
+            </div>
+          </section>
+        )}
+
         {/* History Management */}
         <section className="settings-section">
           <h2 className="section-title">
@@ -304,6 +380,57 @@ export function Settings() {
           </div>
         </section>
 
+        {/* Configuration History */}
+        <section className="settings-section">
+          <h2 className="section-title">
+            <i className="fa-solid fa-code-compare"></i>
+            Configuration History
+          </h2>
+          <div className="settings-card">
+            {configHistory.length > 0 ? (
+              configHistory.map((snapshot, index) => (
+                <div key={snapshot.id} className="setting-row config-history-row">
+                  <div className="config-history-details">
+                    <span className="setting-label">
+                      {formatTimeAgo(snapshot.snapshot_time)} by <span className="monospace">{snapshot.changed_by || 'unknown'}</span>
+                    </span>
+                    {snapshot.changes.slice(0, 5).map(change => (
+                      <span key={change.key} className="setting-value monospace config-change">{describeChange(change)}</span>
+                    ))}
+                    {snapshot.changes.length > 5 && (
+                      <span className="setting-value">and {snapshot.changes.length - 5} more</span>
+                    )}
+                  </div>
+                  {index > 0 && (
+                    <button
+                      className="config-revert-btn"
+                      onClick={() => setRevertTarget(snapshot)}
+                      disabled={reverting}
+                      title="Restore the configuration as of this change"
+                    >
+                      <i className="fa-solid fa-rotate-left"></i> Revert
+                    </button>
+                  )}
+                </div>
+              ))
+            ) : (
+              <div className="setting-row">
+                <span className="setting-value">No configuration changes recorded</span>
+              </div>
+            )}
+            <div className="setting-info">
+              <i className="fa-solid fa-circle-info"></i>
+              Changes to policies, script assignments, schedules, and notification settings are recorded with who made them.
+            </div>
+            {revertResult && (
+              <div className={`setting-info ${revertResult.startsWith('Error') ? 'setting-info-error' : 'setting-info-success'}`}>
+                <i className={`fa-solid ${revertResult.startsWith('Error') ? 'fa-circle-exclamation' : 'fa-check-circle'}`}></i>
+                {revertResult}
+              </div>
+            )}
+          </div>
+        </section>
+
         {/* Configuration */}
         <section className="settings-section">
           <h2 className="section-title">
@@ -391,6 +518,33 @@ export function Settings() {
           </div>
         </div>
       )}
+
+      {/* Revert Configuration Confirmation Dialog */}
+      {revertTarget && (
+        <div className="confirm-dialog-overlay">
+          <div
+            className="confirm-dialog"
+            ref={revertDialogRef}
+            role="dialog"
+            aria-modal="true"
+            aria-labelledby="revert-config-dialog-title"
+          >
+            <div className="confirm-dialog-header">
+              <h3 id="revert-config-dialog-title">Confirm Revert Configuration</h3>
+            </div>
+            <div className="confirm-dialog-body">
+              <p>This will restore the configuration as of <strong>{new Date(revertTarget.snapshot_time).toLocaleString()}</strong>, undoing every change recorded since.</p>
+              <p className="confirm-warning">The revert is recorded too, so it can be undone the same way.</p>
+            </div>
+            <div className="confirm-dialog-actions">
+              <button className="confirm-cancel" onClick={cancelRevert}>Cancel</button>
+              <button className="confirm-force" onClick={executeRevert} disabled={reverting}>
+                {reverting ? 'Reverting...' : 'Revert'}
+              </button>
+            </div>
+          </div>
+        </div>
+      )}
     </div>
   );
 }
@@ -28,6 +28,11 @@ export const STAGE_INFO: Record<string, StageInfo> = {
     label: 'Pulling Image',
     description: 'Downloading container image...'
   },
+  'building_image': {
+    icon: 'fa-hammer',
+    label: 'Building Image',
+    description: 'Rebuilding image on the new base image...'
+  },
   'recreating': {
     icon: 'fa-rotate',
     label: 'Recreating',
@@ -43,6 +48,11 @@ export const STAGE_INFO: Record<string, StageInfo> = {
     label: 'Rolling Back',
     description: 'Reverting to previous version...'
   },
+  'restoring_volumes': {
+    icon: 'fa-box-archive',
+    label: 'Restoring Volumes',
+    description: 'Restoring volume snapshots taken before the update...'
+  },
   'pending_restart': {
     icon: 'fa-rotate',
     label: 'Restarting',
@@ -90,6 +90,9 @@ function mergeContainerData(
       dependencies: status?.dependencies,
       service: status?.service,
       note: status?.note,
+      latest_size: status?.latest_size,
+      size_delta: status?.size_delta,
+      end_of_life: status?.end_of_life,
       has_update_data: !!status,
     };
   };
@@ -121,7 +124,7 @@ export function useContainersData(): ContainersDataResult {
     return () => { mountedRef.current = false; };
   }, []);
 
-  const { checkProgress, containerUpdated, reconnecting, wasDisconnected, clearWasDisconnected } = useEventStream();
+  const { checkProgress, containerUpdated, containerChanged, reconnecting, wasDisconnected, clearWasDisconnected } = useEventStream();
 
   // Fetch both data sources in parallel
   const fetchData = useCallback(async () => {
@@ -256,6 +259,13 @@ export function useContainersData(): ContainersDataResult {
     }
   }, [containerUpdated, backgroundRefresh, fetchData]);
 
+  // Refresh on Docker container events, once a burst (e.g. compose up) settles
+  useEffect(() => {
+    if (!containerChanged) return;
+    const timer = setTimeout(backgroundRefresh, 1000);
+    return () => clearTimeout(timer);
+  }, [containerChanged, backgroundRefresh]);
+
   // Auto-refresh on reconnection
   useEffect(() => {
     if (wasDisconnected) {
@@ -29,6 +29,14 @@ export interface ContainerUpdatedEvent {
   timestamp?: number;
 }
 
+export interface ContainerChangedEvent {
+  container_id?: string;
+  container_name?: string;
+  image?: string;
+  action?: 'create' | 'start' | 'die' | 'destroy';
+  timestamp?: number;
+}
+
 export interface EventStreamState {
   connected: boolean;
   reconnecting: boolean;
@@ -38,6 +46,7 @@ export interface EventStreamState {
   lastEvent: UpdateProgressEvent | null;
   checkProgress: CheckProgressEvent | null;
   containerUpdated: ContainerUpdatedEvent | null; // Last container update event with full details
+  containerChanged: ContainerChangedEvent | null; // Last Docker container event (created, started, stopped, removed)
 }
 
 export function useEventStreamCore() {
@@ -50,6 +59,7 @@ export function useEventStreamCore() {
     lastEvent: null,
     checkProgress: null,
     containerUpdated: null,
+    containerChanged: null,
   });
 
   // Ref-based event queue: immune to React batching (setState can't lose events)
@@ -61,11 +71,17 @@ export function useEventStreamCore() {
   const reconnectTimeoutRef = useRef<ReturnType<typeof setTimeout> | null>(null);
   const hadConnectionRef = useRef(false);
   const reconnectAttemptRef = useRef(0);
+  // ID of the last event received, so a new connection replays the events missed
+  // in between (EventSource only sends Last-Event-ID when it reconnects itself)
+  const lastEventIdRef = useRef('');
 
   const connect = useCallback(() => {
     if (eventSourceRef.current) return;
 
-    const eventSource = new EventSource('/api/events');
+    const url = lastEventIdRef.current
+      ? `/api/events?last_event_id=${encodeURIComponent(lastEventIdRef.current)}`
+      : '/api/events';
+    const eventSource = new EventSource(url);
     eventSourceRef.current = eventSource;
 
     eventSource.onopen = () => {
@@ -120,6 +136,7 @@ export function useEventStreamCore() {
 
     // Listen for update progress events
     eventSource.addEventListener('update.progress', (e) => {
+      if (e.lastEventId) lastEventIdRef.current = e.lastEventId;
       try {
         const data = JSON.parse(e.data);
         const progressEvent: UpdateProgressEvent = data.payload;
@@ -140,6 +157,7 @@ export function useEventStreamCore() {
 
     // Listen for container updated events
     eventSource.addEventListener('container.updated', (e) => {
+      if (e.lastEventId) lastEventIdRef.current = e.lastEventId;
       try {
         const data = JSON.parse(e.data);
         const event: ContainerUpdatedEvent = {
@@ -160,8 +178,26 @@ export function useEventStreamCore() {
       }
     });
 
+    // Listen for Docker container events
+    eventSource.addEventListener('container.changed', (e) => {
+      if (e.lastEventId) lastEventIdRef.current = e.lastEventId;
+      try {
+        const data = JSON.parse(e.data);
+        setState(prev => ({
+          ...prev,
+          containerChanged: { ...data.payload, timestamp: data.payload?.timestamp || Date.now() },
+        }));
+      } catch {
+        setState(prev => ({
+          ...prev,
+          containerChanged: { timestamp: Date.now() },
+        }));
+      }
+    });
+
     // Listen for check progress events
     eventSource.addEventListener('check.progress', (e) => {
+      if (e.lastEventId) lastEventIdRef.current = e.lastEventId;
       try {
         const data = JSON.parse(e.data);
         const checkEvent: CheckProgressEvent = data.payload;
@@ -156,6 +156,8 @@ export function getPageTitle(operationType: OperationType | null, recoveredOpera
       case 'restart': return 'Restarting Container';
       case 'single': return 'Updating Container';
       case 'batch': return 'Updating Containers';
+      case 'pin': return 'Pinning Versions';
+      case 'variant': return 'Switching Variant';
       case 'rollback': return 'Rolling Back';
       case 'start': return 'Starting Container';
       case 'stop': return 'Stopping Container';
@@ -12,6 +12,7 @@ import { ChangeType, getChangeTypeName } from '../types/api';
 import { getRegistryUrl } from '../utils/registry';
 import { useToast } from '../components/Toast';
 import { ansiToHtml } from '../utils/ansi';
+import { formatSizeDelta } from '../utils/size';
 import '../styles/container-page.css';
 
 type TabId = 'overview' | 'config' | 'logs' | 'inspect';
@@ -450,6 +451,8 @@ export function ContainerPage() {
         return <span className="docksmith-badge update">Update Available</span>;
       case 'UPDATE_AVAILABLE_BLOCKED':
         return <span className="docksmith-badge blocked">Update Blocked</span>;
+      case 'UPDATE_UNAVAILABLE_ARCH':
+        return <span className="docksmith-badge blocked" title={docksmithData.error}>Unavailable for Architecture</span>;
       case 'UP_TO_DATE':
         return <span className="docksmith-badge current">Up to Date</span>;
       case 'UP_TO_DATE_PINNABLE':
@@ -690,6 +693,11 @@ export function ContainerPage() {
                     }
                     return latestTag;
                   })()}</span>
+                  {docksmithData.latest_size ? (
+                    <span className="version-size" title={`Download size: ${formatSize(docksmithData.latest_size)}`}>
+                      {formatSizeDelta(docksmithData.size_delta) || formatSize(docksmithData.latest_size)}
+                    </span>
+                  ) : null}
                 </div>
                 {!hasChanges && (docksmithData.status === 'UPDATE_AVAILABLE' || docksmithData.status === 'UPDATE_AVAILABLE_BLOCKED') && (
                   <button
@@ -795,6 +803,20 @@ export function ContainerPage() {
               </div>
             )}
 
+            {/* Dependency Cycle Warning */}
+            {docksmithData?.dependency_cycle && docksmithData.dependency_cycle.length > 0 && (
+              <div className="sync-warning">
+                <i className="fa-solid fa-arrows-spin"></i>
+                <div className="sync-warning-text">
+                  <strong>{docksmithData.restart_loop ? 'Restart Loop' : 'Circular Dependency'}</strong>
+                  <p>
+                    Depends on itself through {docksmithData.dependency_cycle.join(', ')}.
+                    {docksmithData.restart_loop && ' Restarts would trigger each other endlessly; check docksmith.restart-after labels.'}
+                  </p>
+                </div>
+              </div>
+            )}
+
             {/* Env-Controlled Info */}
             {docksmithData?.env_controlled && docksmithData.env_var_name && (
               <section className="env-info-card">
@@ -1,6 +1,33 @@
 // TypeScript types that match Go JSON structures EXACTLY
 // This ensures zero drift between backend and frontend
 
+// Typed aspect of a container's check result (matches update.Condition)
+export type ConditionType =
+  | 'UpdateAvailable'
+  | 'PinRecommended'
+  | 'PreCheckBlocked'
+  | 'ArchUnsupported'
+  | 'MetadataStale'
+  | 'ComposeMismatch'
+  | 'EndOfLife';
+
+export interface Condition {
+  type: ConditionType;
+  status: 'True' | 'False' | 'Unknown';
+  reason: string; // CamelCase cause of the status, e.g. NewerVersion
+  message?: string;
+  last_transition_time: string; // When the status last changed
+}
+
+// Lifecycle of the release cycle a container runs, from endoflife.date (matches update.EndOfLife)
+export interface EndOfLife {
+  product: string; // e.g. "postgresql"
+  cycle: string; // Release cycle of the current version, e.g. "12"
+  eol: boolean; // The cycle no longer receives fixes
+  eol_date?: string; // YYYY-MM-DD
+  latest_cycle?: string; // Newest release cycle of the product
+}
+
 // API Response wrapper (matches output.Response)
 export interface APIResponse<T> {
   success: boolean;
@@ -22,6 +49,7 @@ export interface ContainerInfo {
   current_digest?: string;
   latest_digest?: string;
   available_tags?: string[];
+  variants?: string[]; // Tags of the current version with another variant suffix (e.g. "1.25" for "1.25-alpine")
   change_type: number; // version.ChangeType
   status: string; // update.UpdateStatus
   error?: string;
@@ -36,6 +64,14 @@ export interface ContainerInfo {
   env_controlled?: boolean; // True if image is controlled by .env variable
   env_var_name?: string; // Name of the controlling env var (e.g., "OPENCLAW_IMAGE")
   note?: string; // Informational note (e.g., ghost tag warning)
+  current_size?: number; // Compressed size of the current image in bytes
+  latest_size?: number; // Compressed size of the update candidate in bytes
+  size_delta?: number; // latest_size - current_size
+  deferred?: boolean; // Check postponed because the registry rate limit is nearly exhausted
+  base_image?: string; // Base image checked in place of a locally built image (docksmith.base-image)
+  timed_out?: boolean; // Check failed because it took longer than its timeout (docksmith.check-timeout)
+  end_of_life?: EndOfLife; // Lifecycle of the current version's release cycle (docksmith.eol)
+  conditions?: Condition[]; // Typed aspects of the check result, one per condition type
   id: string;
   stack?: string;
   service?: string;
@@ -43,6 +79,19 @@ export interface ContainerInfo {
   labels?: Record<string, string>;
   compose_labels?: Record<string, string>; // Docksmith labels from compose file
   labels_out_of_sync?: boolean; // True if compose labels differ from running container
+  groups?: string[]; // Custom groups from the docksmith.group label
+  dependency_cycle?: string[]; // Containers in a circular dependency with this one
+  restart_loop?: boolean; // The cycle includes restart-after labels, so restarts would loop endlessly
+}
+
+// Group (matches update.Group)
+export interface Group {
+  name: string;
+  containers: string[];
+  updates_available: number;
+  up_to_date: number;
+  failed: number;
+  ignored: number;
 }
 
 // Stack (matches update.Stack)
@@ -54,6 +103,49 @@ export interface Stack {
   update_priority?: string;
 }
 
+// Stack summary from GET /api/stacks
+export interface StackSummary {
+  name: string;
+  containers: string[];
+  updates_available: number;
+  update_priority?: string;
+  compose_files: string[];
+  last_check?: string; // ISO timestamp of the check the counts come from
+  locked: boolean; // An update operation holds the stack lock
+  queued_operations: number;
+}
+
+// Dependency graph (matches graph.Export); edges point from a container to its dependency
+export interface GraphNode {
+  id: string;
+  stack?: string;
+  service?: string;
+  image?: string;
+  state?: string;
+  dependencies: string[];
+  missing_dependencies?: string[];
+  blast_radius: string[]; // Containers affected when this one is updated or restarted
+}
+
+export interface GraphEdge {
+  from: string;
+  to: string;
+  type: 'depends_on' | 'network_mode' | 'restart_after';
+}
+
+export interface DependencyCycle {
+  containers: string[];
+  sources: GraphEdge['type'][];
+  restart_loop: boolean; // A restart-after label is part of the cycle
+}
+
+export interface DependencyGraph {
+  nodes: GraphNode[];
+  edges: GraphEdge[];
+  cycles: DependencyCycle[];
+  stacks: Record<string, string[]>;
+}
+
 // Discovery Result (matches update.DiscoveryResult)
 export interface DiscoveryResult {
   containers: ContainerInfo[];
@@ -66,6 +158,8 @@ export interface DiscoveryResult {
   local_images: number;
   failed: number;
   ignored: number;
+  groups?: Record<string, Group>; // Containers grouped by docksmith.group
+  dependency_cycles?: DependencyCycle[]; // Circular dependencies, including restart-after labels
   // Status endpoint specific fields
   last_cache_refresh?: string; // ISO timestamp of when cache was last cleared (cache refresh)
   last_background_run?: string; // ISO timestamp of when background check last ran
@@ -87,6 +181,56 @@ export interface BatchContainerDetail {
   old_digest?: string;
   status?: string;   // Per-container status: pending, restarting, complete, failed
   message?: string;  // Human-readable status message
+  restore_snapshots_from?: string;  // Rollbacks: operation whose volume snapshots are restored
+}
+
+// Signature check of an update's target image (matches storage.SignatureVerification)
+export interface SignatureVerification {
+  container_name: string;
+  image: string;
+  digest?: string;
+  policy: 'warn' | 'block';
+  mode?: 'key' | 'keyless';
+  verified: boolean;
+  signer?: string;
+  attestations?: string[];
+  error?: string;
+  checked_at: string;
+}
+
+// Restart monitoring of an updated container (matches storage.PostUpdateObservation)
+export interface PostUpdateObservation {
+  container_name: string;
+  status: 'observing' | 'passed' | 'crash_loop' | 'interrupted';
+  restarts: number;
+  max_restarts: number;
+  window_seconds: number;
+  started_at: string;
+  completed_at?: string;
+  rolled_back?: boolean;
+  message?: string;
+}
+
+// Volume snapshot taken before an update (matches storage.VolumeSnapshot)
+export interface VolumeSnapshot {
+  id: number;
+  operation_id: string;
+  container_name: string;
+  volume_name: string;
+  method: 'tar' | 'hook';
+  location: string;
+  size_bytes?: number;
+  created_at: string;
+}
+
+// Line of the step log of an operation (matches storage.OperationLogEntry)
+export interface OperationLogEntry {
+  id: number;
+  operation_id: string;
+  container_name?: string;
+  source: 'progress' | 'compose' | 'pull' | 'health' | 'script';
+  message: string;
+  created_at: string;
 }
 
 // Update Operation (matches storage.UpdateOperation)
@@ -107,6 +251,12 @@ export interface UpdateOperation {
   rollback_occurred: boolean;
   batch_details?: BatchContainerDetail[];
   batch_group_id?: string;
+  check_output?: string;
+  compose_output?: string;
+  all_or_nothing?: boolean;
+  signature_verifications?: SignatureVerification[];
+  observations?: PostUpdateObservation[];
+  triggered_by?: string;
   created_at: string;
   updated_at: string;
 }
@@ -133,10 +283,59 @@ export interface ScriptAssignment {
   updated_at: string;
 }
 
+// Managed script revision (matches storage.ScriptRevision)
+export interface ScriptRevision {
+  id: number;
+  name: string;
+  revision: number;
+  sha256: string;
+  size: number;
+  timeout_seconds?: number;
+  no_network: boolean;
+  memory_limit_mb?: number;
+  cpu_seconds?: number;
+  created_by?: string;
+  created_at: string;
+}
+
+// Managed script with content (matches scripts.ManagedScript)
+export interface ManagedScript extends ScriptRevision {
+  ref: string; // "managed:<name>@<revision>"
+  content: string;
+}
+
+// Managed script detail response
+export interface ManagedScriptResponse {
+  script: ManagedScript;
+  revisions: ScriptRevision[];
+}
+
+// Managed script save request; sandbox options are optional
+export interface SaveScriptRequest {
+  content: string;
+  timeout_seconds?: number;
+  no_network?: boolean;
+  memory_limit_mb?: number;
+  cpu_seconds?: number;
+}
+
+// Result of POST /api/scripts/test (matches scripts.TestResult)
+export interface ScriptTestResult {
+  container: string;
+  script: string;
+  passed: boolean;
+  exit_code: number; // -1 when the script could not be run
+  output: string;
+  error?: string;
+  duration_ms: number;
+}
+
 // Scripts Response
 export interface ScriptsResponse {
   scripts: Script[];
   count: number;
+  managed?: ScriptRevision[]; // Latest revision of each managed script
+  builtin?: string[]; // Built-in checks, selected as "builtin:<name>"
 }
 
 // Script Assignments Response
@@ -159,6 +358,20 @@ export interface HistoryEntry {
   operation?: string;
   success?: boolean;
   error?: string;
+  latest_size?: number;
+  size_delta?: number;
+}
+
+// Registry rate limit quota as last reported by the registry
+export interface RegistryQuota {
+  limit: number;
+  remaining: number;
+  window_seconds?: number;
+  source?: string;
+  reset_at?: string;
+  updated_at: string;
+  low: boolean;
+  exhausted: boolean;
 }
 
 // Health Check Response
@@ -168,6 +381,8 @@ export interface HealthResponse {
     docker: boolean;
     storage: boolean;
   };
+  read_only?: boolean;
+  registry_quota?: Record<string, RegistryQuota>;
 }
 
 // Docker Registry Info
@@ -185,6 +400,7 @@ export const UpdateStatus = {
   UpToDatePinnable: 'UP_TO_DATE_PINNABLE',
   UpdateAvailable: 'UPDATE_AVAILABLE',
   UpdateAvailableBlocked: 'UPDATE_AVAILABLE_BLOCKED',
+  UpdateUnavailableArch: 'UPDATE_UNAVAILABLE_ARCH',
   LocalImage: 'LOCAL_IMAGE',
   CheckFailed: 'CHECK_FAILED',
   MetadataUnavailable: 'METADATA_UNAVAILABLE',
@@ -231,8 +447,11 @@ export interface SetLabelsRequest {
   tag_regex?: string;
   version_min?: string;
   version_max?: string;
+  version_constraint?: string;
+  tag_pattern?: string;
   script?: string;
   restart_after?: string;
+  group?: string;
   no_restart?: boolean;
   force?: boolean;
 }
@@ -252,6 +471,39 @@ export interface LabelOperationResult {
   message?: string;
 }
 
+// Image ignore rule with the containers it matches (matches api.ignoreRuleResponse)
+export interface IgnoreRule {
+  id: number;
+  pattern: string;
+  reason?: string;
+  created_at: string;
+  containers: string[];
+}
+
+// A version a container ran (matches api.versionRun)
+export interface VersionRun {
+  version: string;
+  previous_version?: string;
+  change_type?: 'patch' | 'minor' | 'major' | 'downgrade' | 'unknown' | 'no change';
+  first_seen: string;
+  started_at: string;
+  deployed_at?: string;
+  ended_at?: string;
+  duration_seconds: number;
+  source: 'update' | 'rollback' | 'observed';
+  operation_id?: string;
+  rolled_back: boolean;
+  current: boolean;
+}
+
+export interface ContainerVersionsResponse {
+  container: string;
+  current_version: string;
+  versions: VersionRun[];
+  rollbacks: number;
+  count: number;
+}
+
 // Registry Tags Response
 export interface RegistryTagsResponse {
   image_ref: string;
@@ -259,6 +511,41 @@ export interface RegistryTagsResponse {
   count: number;
 }
 
+// Registry Catalog Response
+export interface RegistryRepositoriesResponse {
+  registry: string;
+  repositories: string[];
+  count: number;
+}
+
+export interface ImageLayer {
+  digest: string;
+  size: number;
+}
+
+export interface ManifestDetails {
+  reference: string;
+  digest: string;
+  platform_digest?: string;
+  platforms?: string[];
+  layers: ImageLayer[];
+  size: number;
+}
+
+export interface RegistryManifestResponse {
+  image_ref: string;
+  manifest: ManifestDetails;
+}
+
+// Container resource usage, computed like `docker stats`
+export interface ResourceUsage {
+  cpu_percent: number; // Percent of one CPU
+  online_cpus: number;
+  memory_usage: number; // Bytes, excluding the page cache
+  memory_limit: number;
+  memory_percent: number;
+}
+
 // Explorer Types
 
 // Container item for explorer view (simplified from ContainerInfo)
@@ -454,10 +741,35 @@ export interface UnifiedContainerItem {
   dependencies?: string[];
   service?: string;
   note?: string;
+  latest_size?: number;
+  size_delta?: number;
+  end_of_life?: EndOfLife;
 
   has_update_data: boolean;  // true if matched in /api/status
 }
 
+// Configuration history (GET /api/config/history)
+export interface ConfigChange {
+  key: string;
+  type: 'added' | 'removed' | 'changed';
+  old?: string;
+  new?: string;
+}
+
+export interface ConfigSnapshot {
+  id: number;
+  snapshot_time: string;
+  changed_by: string;
+  changes: ConfigChange[];
+}
+
+export interface ConfigRevertResult {
+  snapshot_id: number;
+  changes: ConfigChange[];
+  skipped?: string[];
+  restart_required: boolean;
+}
+
 // API Response types
 export type CheckResponse = APIResponse<DiscoveryResult>;
 export type OperationsResponse = APIResponse<{ operations: UpdateOperation[]; count: number; next_cursor?: string; has_more?: boolean }>;
@@ -466,4 +778,6 @@ export type HealthCheckResponse = APIResponse<HealthResponse>;
 export type DockerConfigResponse = APIResponse<DockerRegistryInfo>;
 export type SetLabelsResponse = APIResponse<LabelOperationResult>;
 export type RegistryTagsAPIResponse = APIResponse<RegistryTagsResponse>;
+export type RegistryRepositoriesAPIResponse = APIResponse<RegistryRepositoriesResponse>;
+export type RegistryManifestAPIResponse = APIResponse<RegistryManifestResponse>;
 export type ExplorerResponse = APIResponse<ExplorerData>;
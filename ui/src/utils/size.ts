@@ -0,0 +1,27 @@
+/**
+ * Formats a byte count into a human-readable size
+ * @param bytes Size in bytes
+ * @returns Formatted string like "12.3 MB"
+ */
+export function formatBytes(bytes: number): string {
+  const abs = Math.abs(bytes);
+  if (abs < 1024) return `${abs} B`;
+  const units = ['KB', 'MB', 'GB', 'TB'];
+  let value = abs / 1024;
+  let i = 0;
+  while (value >= 1024 && i < units.length - 1) {
+    value /= 1024;
+    i++;
+  }
+  return `${value.toFixed(1)} ${units[i]}`;
+}
+
+/**
+ * Formats how much an update changes the download size versus the current image
+ * @param sizeDelta Compressed size difference in bytes (latest - current)
+ * @returns Signed string like "+12.3 MB" or "-1.0 MB", or '' when unknown
+ */
+export function formatSizeDelta(sizeDelta?: number): string {
+  if (!sizeDelta) return '';
+  return `${sizeDelta > 0 ? '+' : '-'}${formatBytes(sizeDelta)}`;
+}
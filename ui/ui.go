@@ -0,0 +1,9 @@
+// Package ui holds the web dashboard built into the docksmith binary.
+package ui
+
+import "io/fs"
+
+// Dist is the built dashboard (the contents of ui/dist). It is only set in
+// builds with the "embedui" tag, which requires running `npm run build` first;
+// otherwise it is nil and the server serves the UI from STATIC_DIR.
+var Dist fs.FS
@@ -1,9 +1,41 @@
-import { defineConfig } from 'vite'
+import { defineConfig, type Plugin } from 'vite'
 import react from '@vitejs/plugin-react'
+import { readdirSync, readFileSync, writeFileSync } from 'node:fs'
+import { join, resolve } from 'node:path'
+import { brotliCompressSync, constants, gzipSync } from 'node:zlib'
+
+// Assets smaller than this are not worth compressing (minGzipSize in internal/api/static.go)
+const minCompressSize = 1024
+
+// precompress writes .br and .gz files next to the built assets. The server sends
+// them to browsers that accept the encoding, so brotli is available without
+// compressing on each request.
+function precompress(): Plugin {
+  let assetsDir = ''
+  return {
+    name: 'docksmith-precompress',
+    apply: 'build',
+    configResolved(config) {
+      assetsDir = resolve(config.root, config.build.outDir, config.build.assetsDir)
+    },
+    closeBundle() {
+      for (const name of readdirSync(assetsDir)) {
+        if (!/\.(js|css|svg|json)$/.test(name)) continue
+        const file = join(assetsDir, name)
+        const data = readFileSync(file)
+        if (data.length < minCompressSize) continue
+        writeFileSync(`${file}.br`, brotliCompressSync(data, {
+          params: { [constants.BROTLI_PARAM_QUALITY]: constants.BROTLI_MAX_QUALITY },
+        }))
+        writeFileSync(`${file}.gz`, gzipSync(data, { level: 9 }))
+      }
+    },
+  }
+}
 
 // https://vite.dev/config/
 export default defineConfig({
-  plugins: [react()],
+  plugins: [react(), precompress()],
   server: {
     port: 5173,
     proxy: {